@@ -0,0 +1,31 @@
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// admit waits until the query given fits in the limits of concurrent queries set in the global values of the
+// admission control variables, and returns the function to call when it finishes. SET statements are always
+// admitted, so that the limits can be changed when they are reached, and so are the statements of sessions with turns in
+// the WriteQueue, so that the queries waiting for their turns never keep them from finishing their transactions.
+func (e *Engine) admit(ctx *sql.Context, query string, parsed sql.Node) (func(), error) {
+	limits := sql.GlobalAdmissionLimits(e.Catalog.GlobalVariables)
+	if _, ok := parsed.(*plan.Set); ok || !limits.Enabled() {
+		return func() {}, nil
+	}
+	if ctx.Session != nil && e.WriteQueue.Holds(ctx.Session.ID()) {
+		return func() {}, nil
+	}
+
+	var user, digest string
+	if ctx.Session != nil {
+		user = ctx.Client().User
+	}
+	if limits.MaxDigestQueries > 0 {
+		digest, _ = parse.Digest(query)
+	}
+
+	return e.admission.Admit(ctx, user, digest, limits)
+}
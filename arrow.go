@@ -0,0 +1,36 @@
+package sqle
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/arrow"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// QueryArrow executes a query and returns a reader of its rows in Arrow records of at most size rows, or
+// arrow.DefaultRecordSize if it's 0, so embedders that analyze results by column can read the values of each column
+// from its buffers, without converting every value of every row themselves. The reader must be closed once it's read,
+// like the iterators of Query.
+func (e *Engine) QueryArrow(ctx *sql.Context, query string, size int) (*arrow.Reader, error) {
+	schema, iter, err := e.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return arrow.NewReader(schema, iter, size), nil
+}
+
+// WriteArrow executes a query and writes its rows to the writer given in the Arrow IPC streaming format, which the
+// Arrow libraries of any language can read.
+func (e *Engine) WriteArrow(ctx *sql.Context, w io.Writer, query string) (err error) {
+	r, err := e.QueryArrow(ctx, query, 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := r.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return arrow.WriteStream(w, r)
+}
@@ -0,0 +1,377 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Array is a column of the values of a record, in the layout of the Arrow columnar format: fixed-size values are
+// stored one after the other in little-endian order in Values, and the values of variable size are the bytes of
+// Values between consecutive Offsets. The embedders of the engine can read the buffers of arrays directly, or
+// through the methods of Array.
+type Array struct {
+	Type      DataType
+	Len       int
+	NullCount int
+	// Validity is the bitmap of the values that aren't NULL, with the bit of the value i being the bit i%8 of the
+	// byte i/8, or nil if no value is NULL.
+	Validity []byte
+	// Offsets are the Len+1 offsets in Values of the values of Binary and Utf8 arrays.
+	Offsets []int32
+	Values  []byte
+}
+
+// IsNull returns whether the value i is NULL.
+func (a *Array) IsNull(i int) bool {
+	if a.Type.ID == Null {
+		return true
+	}
+	return a.Validity != nil && a.Validity[i/8]&(1<<uint(i%8)) == 0
+}
+
+// Int64 returns the value i of an Int array, or the number a Date, Timestamp or Duration value i is stored as.
+func (a *Array) Int64(i int) int64 {
+	switch a.Type.width() {
+	case 1:
+		return int64(int8(a.Values[i]))
+	case 2:
+		return int64(int16(binary.LittleEndian.Uint16(a.Values[i*2:])))
+	case 4:
+		return int64(int32(binary.LittleEndian.Uint32(a.Values[i*4:])))
+	default:
+		return int64(binary.LittleEndian.Uint64(a.Values[i*8:]))
+	}
+}
+
+// Uint64 returns the value i of an unsigned Int array.
+func (a *Array) Uint64(i int) uint64 {
+	switch a.Type.width() {
+	case 1:
+		return uint64(a.Values[i])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(a.Values[i*2:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(a.Values[i*4:]))
+	default:
+		return binary.LittleEndian.Uint64(a.Values[i*8:])
+	}
+}
+
+// Float64 returns the value i of a FloatingPoint array.
+func (a *Array) Float64(i int) float64 {
+	if a.Type.BitWidth == 32 {
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(a.Values[i*4:])))
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(a.Values[i*8:]))
+}
+
+// Bytes returns the value i of a Binary or Utf8 array, which shares its memory with the array.
+func (a *Array) Bytes(i int) []byte {
+	return a.Values[a.Offsets[i]:a.Offsets[i+1]]
+}
+
+// String returns the value i of a Utf8 or Binary array.
+func (a *Array) String(i int) string {
+	return string(a.Bytes(i))
+}
+
+// Decimal returns the value i of a Decimal array.
+func (a *Array) Decimal(i int) decimal.Decimal {
+	b := a.Values[i*16 : i*16+16]
+	be := make([]byte, 16)
+	for j := range b {
+		be[15-j] = b[j]
+	}
+	unscaled := new(big.Int).SetBytes(be)
+	if b[15]&0x80 != 0 {
+		unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	return decimal.NewFromBigInt(unscaled, -int32(a.Type.Scale))
+}
+
+// Time returns the value i of a Date or Timestamp array, in UTC.
+func (a *Array) Time(i int) time.Time {
+	if a.Type.ID == Date {
+		return time.Unix(a.Int64(i)*secondsPerDay, 0).UTC()
+	}
+	us := a.Int64(i)
+	return time.Unix(us/1e6, us%1e6*1e3).UTC()
+}
+
+// Duration returns the value i of a Duration array.
+func (a *Array) Duration(i int) time.Duration {
+	return time.Duration(a.Int64(i)) * time.Microsecond
+}
+
+// Value returns the value i of the array as the Go value of its type, which is nil for NULL values, an int8, int16,
+// int32, int64, uint8, uint16, uint32 or uint64 for Int values, a float32 or float64 for FloatingPoint values, a
+// decimal.Decimal, a time.Time for Date and Timestamp values, a time.Duration, a []byte for Binary values and a string
+// for Utf8 values.
+func (a *Array) Value(i int) interface{} {
+	if a.IsNull(i) {
+		return nil
+	}
+
+	switch a.Type.ID {
+	case Int:
+		if a.Type.Signed {
+			v := a.Int64(i)
+			switch a.Type.BitWidth {
+			case 8:
+				return int8(v)
+			case 16:
+				return int16(v)
+			case 32:
+				return int32(v)
+			default:
+				return v
+			}
+		}
+		v := a.Uint64(i)
+		switch a.Type.BitWidth {
+		case 8:
+			return uint8(v)
+		case 16:
+			return uint16(v)
+		case 32:
+			return uint32(v)
+		default:
+			return v
+		}
+	case FloatingPoint:
+		if a.Type.BitWidth == 32 {
+			return float32(a.Float64(i))
+		}
+		return a.Float64(i)
+	case Decimal:
+		return a.Decimal(i)
+	case Date, Timestamp:
+		return a.Time(i)
+	case Duration:
+		return a.Duration(i)
+	case Binary:
+		return a.Bytes(i)
+	default:
+		return a.String(i)
+	}
+}
+
+// Record is a batch of rows of a result, stored as a column of each field of its schema.
+type Record struct {
+	Schema  Schema
+	Len     int
+	Columns []*Array
+}
+
+// Row returns the row i of the record, with the values of its columns returned by Array.Value.
+func (r *Record) Row(i int) sql.Row {
+	row := make(sql.Row, len(r.Columns))
+	for j, col := range r.Columns {
+		row[j] = col.Value(i)
+	}
+	return row
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+// builder builds the array of a column of the rows of a schema.
+type builder struct {
+	typ sql.Type
+	arr *Array
+}
+
+func newBuilder(typ sql.Type, dt DataType, capacity int) *builder {
+	arr := &Array{Type: dt}
+	switch {
+	case dt.ID == Binary || dt.ID == Utf8:
+		arr.Offsets = make([]int32, 1, capacity+1)
+	case dt.width() > 0:
+		arr.Values = make([]byte, 0, capacity*dt.width())
+	}
+	return &builder{typ: typ, arr: arr}
+}
+
+// append appends a value of the type of the column to the array.
+func (b *builder) append(v interface{}) error {
+	arr := b.arr
+	if arr.Type.ID == Null {
+		arr.NullCount++
+		arr.Len++
+		return nil
+	}
+	if v == nil {
+		if arr.Validity == nil {
+			arr.Validity = make([]byte, arr.Len/8+1)
+			for i := 0; i < arr.Len; i++ {
+				arr.Validity[i/8] |= 1 << uint(i%8)
+			}
+		}
+		b.grow()
+		arr.NullCount++
+		arr.Len++
+		switch {
+		case arr.Offsets != nil:
+			arr.Offsets = append(arr.Offsets, int32(len(arr.Values)))
+		case arr.Type.width() > 0:
+			arr.Values = append(arr.Values, make([]byte, arr.Type.width())...)
+		}
+		return nil
+	}
+
+	if err := b.appendValue(v); err != nil {
+		return err
+	}
+	if arr.Validity != nil {
+		b.grow()
+		arr.Validity[arr.Len/8] |= 1 << uint(arr.Len%8)
+	}
+	arr.Len++
+	return nil
+}
+
+// grow makes room in the validity bitmap for one more value.
+func (b *builder) grow() {
+	if b.arr.Len/8 >= len(b.arr.Validity) {
+		b.arr.Validity = append(b.arr.Validity, 0)
+	}
+}
+
+func (b *builder) appendValue(v interface{}) error {
+	arr := b.arr
+	switch arr.Type.ID {
+	case Int:
+		v, err := b.typ.Convert(v)
+		if err != nil {
+			return err
+		}
+		b.appendUint(toUint64(v))
+	case FloatingPoint:
+		v, err := b.typ.Convert(v)
+		if err != nil {
+			return err
+		}
+		if f, ok := v.(float32); ok {
+			arr.Values = appendUint32(arr.Values, math.Float32bits(f))
+		} else {
+			arr.Values = appendUint64(arr.Values, math.Float64bits(v.(float64)))
+		}
+	case Decimal:
+		dec, err := b.typ.(sql.DecimalType).ConvertToDecimal(v)
+		if err != nil {
+			return err
+		}
+		arr.Values = appendDecimal(arr.Values, dec.Decimal, arr.Type.Scale)
+	case Date, Timestamp:
+		v, err := b.typ.Convert(v)
+		if err != nil {
+			return err
+		}
+		t := v.(time.Time)
+		if arr.Type.ID == Date {
+			days := t.Unix() / secondsPerDay
+			if t.Unix() < 0 && t.Unix()%secondsPerDay != 0 {
+				days--
+			}
+			arr.Values = appendUint32(arr.Values, uint32(int32(days)))
+		} else {
+			arr.Values = appendUint64(arr.Values, uint64(t.Unix()*1e6+int64(t.Nanosecond()/1e3)))
+		}
+	case Duration:
+		d, err := b.typ.(sql.TimeType).ConvertToTimeDuration(v)
+		if err != nil {
+			return err
+		}
+		arr.Values = appendUint64(arr.Values, uint64(d.Microseconds()))
+	default:
+		switch v := v.(type) {
+		case string:
+			arr.Values = append(arr.Values, v...)
+		case []byte:
+			arr.Values = append(arr.Values, v...)
+		default:
+			value, err := b.typ.SQL(v)
+			if err != nil {
+				return err
+			}
+			arr.Values = append(arr.Values, value.Raw()...)
+		}
+		arr.Offsets = append(arr.Offsets, int32(len(arr.Values)))
+	}
+	return nil
+}
+
+// appendUint appends an integer of the width of the array.
+func (b *builder) appendUint(v uint64) {
+	switch b.arr.Type.BitWidth {
+	case 8:
+		b.arr.Values = append(b.arr.Values, byte(v))
+	case 16:
+		b.arr.Values = append(b.arr.Values, byte(v), byte(v>>8))
+	case 32:
+		b.arr.Values = appendUint32(b.arr.Values, uint32(v))
+	default:
+		b.arr.Values = appendUint64(b.arr.Values, v)
+	}
+}
+
+// toUint64 returns the bits of an integer of any type, sign-extended if it's signed.
+func toUint64(v interface{}) uint64 {
+	switch v := v.(type) {
+	case int8:
+		return uint64(v)
+	case int16:
+		return uint64(v)
+	case int32:
+		return uint64(v)
+	case int64:
+		return uint64(v)
+	case int:
+		return uint64(v)
+	case uint8:
+		return uint64(v)
+	case uint16:
+		return uint64(v)
+	case uint32:
+		return uint64(v)
+	case uint:
+		return uint64(v)
+	case uint64:
+		return v
+	default:
+		return 0
+	}
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return appendUint32(appendUint32(b, uint32(v)), uint32(v>>32))
+}
+
+// appendDecimal appends the 128-bit two's complement little-endian integer that the decimal given is multiplied by
+// 10^scale into.
+func appendDecimal(b []byte, d decimal.Decimal, scale int) []byte {
+	d = d.Shift(int32(scale)).Truncate(0)
+	unscaled := d.Coefficient()
+	if exp := d.Exponent(); exp > 0 {
+		unscaled.Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	}
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+
+	be := unscaled.Bytes()
+	var le [16]byte
+	for j := 0; j < len(be) && j < 16; j++ {
+		le[j] = be[len(be)-1-j]
+	}
+	return append(b, le[:]...)
+}
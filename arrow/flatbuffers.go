@@ -0,0 +1,175 @@
+package arrow
+
+import (
+	"encoding/binary"
+)
+
+// flatBuilder builds a FlatBuffer, the serialization format of the metadata of Arrow messages. Like the builders of
+// the FlatBuffers library, it writes the buffer backwards, from its end, so the objects referenced by a table or a
+// vector are written before it, and the offsets of objects are their distance from the end of the buffer.
+type flatBuilder struct {
+	buf      []byte
+	head     int
+	minAlign int
+	// fields are the offsets of the fields of the table being built, or 0 for the fields it doesn't have, and
+	// tableStart is the offset the table started at.
+	fields     []int
+	tableStart int
+}
+
+func newFlatBuilder() *flatBuilder {
+	return &flatBuilder{buf: make([]byte, 512), head: 512, minAlign: 1}
+}
+
+// offset returns the number of bytes written.
+func (b *flatBuilder) offset() int {
+	return len(b.buf) - b.head
+}
+
+// reserve makes room for n more bytes in the buffer, and returns them.
+func (b *flatBuilder) reserve(n int) []byte {
+	for b.head < n {
+		grown := make([]byte, 2*len(b.buf))
+		copy(grown[len(b.buf)+b.head:], b.buf[b.head:])
+		b.head += len(b.buf)
+		b.buf = grown
+	}
+	b.head -= n
+	return b.buf[b.head : b.head+n]
+}
+
+// prep pads the buffer so that a value of the size given written after the additional bytes given is aligned to its
+// size.
+func (b *flatBuilder) prep(size, additional int) {
+	if size > b.minAlign {
+		b.minAlign = size
+	}
+	pad := (size - (b.offset()+additional)%size) % size
+	p := b.reserve(pad)
+	for i := range p {
+		p[i] = 0
+	}
+}
+
+func (b *flatBuilder) uint8(v uint8) {
+	b.prep(1, 0)
+	b.reserve(1)[0] = v
+}
+
+func (b *flatBuilder) uint16(v uint16) {
+	b.prep(2, 0)
+	binary.LittleEndian.PutUint16(b.reserve(2), v)
+}
+
+func (b *flatBuilder) uint32(v uint32) {
+	b.prep(4, 0)
+	binary.LittleEndian.PutUint32(b.reserve(4), v)
+}
+
+func (b *flatBuilder) uint64(v uint64) {
+	b.prep(8, 0)
+	binary.LittleEndian.PutUint64(b.reserve(8), v)
+}
+
+// uoffset writes a reference to the object at the offset given, which must have been written before.
+func (b *flatBuilder) uoffset(off int) {
+	b.prep(4, 0)
+	rel := b.offset() + 4 - off
+	binary.LittleEndian.PutUint32(b.reserve(4), uint32(rel))
+}
+
+// string writes a string, returning its offset.
+func (b *flatBuilder) string(s string) int {
+	b.prep(4, len(s)+1)
+	p := b.reserve(len(s) + 1)
+	copy(p, s)
+	p[len(s)] = 0
+	b.uint32(uint32(len(s)))
+	return b.offset()
+}
+
+// offsets writes a vector of references to the objects at the offsets given, returning its offset.
+func (b *flatBuilder) offsets(offs []int) int {
+	b.prep(4, 4*len(offs))
+	for i := len(offs) - 1; i >= 0; i-- {
+		b.uoffset(offs[i])
+	}
+	b.uint32(uint32(len(offs)))
+	return b.offset()
+}
+
+// structs writes a vector of structs of two 64-bit fields, returning its offset.
+func (b *flatBuilder) structs(pairs [][2]int64) int {
+	b.prep(4, 16*len(pairs))
+	b.prep(8, 16*len(pairs))
+	for i := len(pairs) - 1; i >= 0; i-- {
+		b.uint64(uint64(pairs[i][1]))
+		b.uint64(uint64(pairs[i][0]))
+	}
+	b.uint32(uint32(len(pairs)))
+	return b.offset()
+}
+
+// startTable starts a table of the number of fields given. The objects its fields reference must be written before.
+func (b *flatBuilder) startTable(fields int) {
+	b.fields = make([]int, fields)
+	b.tableStart = b.offset()
+}
+
+func (b *flatBuilder) addUint8(field int, v uint8) {
+	b.uint8(v)
+	b.fields[field] = b.offset()
+}
+
+func (b *flatBuilder) addInt16(field int, v int16) {
+	b.uint16(uint16(v))
+	b.fields[field] = b.offset()
+}
+
+func (b *flatBuilder) addInt32(field int, v int32) {
+	b.uint32(uint32(v))
+	b.fields[field] = b.offset()
+}
+
+func (b *flatBuilder) addInt64(field int, v int64) {
+	b.uint64(uint64(v))
+	b.fields[field] = b.offset()
+}
+
+func (b *flatBuilder) addOffset(field int, off int) {
+	b.uoffset(off)
+	b.fields[field] = b.offset()
+}
+
+// endTable ends the table being built, writing the vtable with the positions of its fields before it, and returns
+// its offset.
+func (b *flatBuilder) endTable() int {
+	b.uint32(0)
+	table := b.offset()
+
+	n := len(b.fields)
+	for n > 0 && b.fields[n-1] == 0 {
+		n--
+	}
+	for i := n - 1; i >= 0; i-- {
+		var pos uint16
+		if b.fields[i] != 0 {
+			pos = uint16(table - b.fields[i])
+		}
+		b.uint16(pos)
+	}
+	b.uint16(uint16(table - b.tableStart))
+	b.uint16(uint16(2 * (n + 2)))
+
+	// The table starts with the distance back to its vtable, which was just written before it
+	binary.LittleEndian.PutUint32(b.buf[len(b.buf)-table:], uint32(int32(b.offset()-table)))
+	b.fields = nil
+	return table
+}
+
+// finish writes the reference to the root table of the buffer at the offset given, and returns the buffer.
+func (b *flatBuilder) finish(root int) []byte {
+	b.prep(b.minAlign, 4)
+	b.uoffset(root)
+	return b.buf[b.head:]
+}
@@ -0,0 +1,229 @@
+package arrow
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	// metadataVersion is the version V5 of the metadata of the Arrow format.
+	metadataVersion = 4
+	// continuation is the marker every message of a stream starts with.
+	continuation = 0xFFFFFFFF
+
+	headerSchema      = 1
+	headerRecordBatch = 3
+
+	precisionSingle = 1
+	precisionDouble = 2
+	dateUnitDay     = 0
+	timeUnitMicro   = 2
+)
+
+// padding is the padding of the messages and buffers of streams, which are aligned to 8 bytes.
+var padding [8]byte
+
+// StreamWriter writes records in the Arrow IPC streaming format, which can be read by the Arrow libraries of any
+// language, for example with pyarrow.ipc.open_stream. The stream starts with a message of the schema of the records,
+// followed by a message for each record, and ends with the end-of-stream marker written by Close.
+type StreamWriter struct {
+	w       io.Writer
+	schema  Schema
+	started bool
+}
+
+// NewStreamWriter returns a writer of a stream of records of the schema given to the writer given.
+func NewStreamWriter(w io.Writer, schema Schema) *StreamWriter {
+	return &StreamWriter{w: w, schema: schema}
+}
+
+// Write writes a record to the stream, writing the schema of the stream before it if it's the first one.
+func (s *StreamWriter) Write(r *Record) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	var nodes [][2]int64
+	var buffers [][2]int64
+	var body [][]byte
+	var bodyLength int64
+	addBuffer := func(b []byte) {
+		buffers = append(buffers, [2]int64{bodyLength, int64(len(b))})
+		body = append(body, b)
+		bodyLength += int64(paddedLen(len(b)))
+	}
+
+	for _, col := range r.Columns {
+		nodes = append(nodes, [2]int64{int64(col.Len), int64(col.NullCount)})
+		if col.Type.ID == Null {
+			continue
+		}
+
+		if col.NullCount == 0 {
+			addBuffer(nil)
+		} else {
+			addBuffer(col.Validity)
+		}
+		if col.Offsets != nil {
+			offsets := make([]byte, 4*len(col.Offsets))
+			for i, off := range col.Offsets {
+				binary.LittleEndian.PutUint32(offsets[4*i:], uint32(off))
+			}
+			addBuffer(offsets)
+		}
+		addBuffer(col.Values)
+	}
+
+	b := newFlatBuilder()
+	nodesOff := b.structs(nodes)
+	buffersOff := b.structs(buffers)
+	b.startTable(4)
+	b.addInt64(0, int64(r.Len))
+	b.addOffset(1, nodesOff)
+	b.addOffset(2, buffersOff)
+	batch := b.endTable()
+
+	if err := s.writeMessage(b, headerRecordBatch, batch, bodyLength); err != nil {
+		return err
+	}
+	for _, buf := range body {
+		if _, err := s.w.Write(buf); err != nil {
+			return err
+		}
+		if _, err := s.w.Write(padding[:paddedLen(len(buf))-len(buf)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close writes the end of the stream, writing its schema before it if no record was written. The underlying writer
+// isn't closed.
+func (s *StreamWriter) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	var eos [8]byte
+	binary.LittleEndian.PutUint32(eos[:], continuation)
+	_, err := s.w.Write(eos[:])
+	return err
+}
+
+// start writes the schema of the stream if it hasn't been written yet.
+func (s *StreamWriter) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	b := newFlatBuilder()
+	fields := make([]int, len(s.schema))
+	for i, field := range s.schema {
+		fields[i] = writeField(b, field)
+	}
+	fieldsOff := b.offsets(fields)
+	b.startTable(4)
+	b.addInt16(0, 0) // little endian
+	b.addOffset(1, fieldsOff)
+	schema := b.endTable()
+
+	return s.writeMessage(b, headerSchema, schema, 0)
+}
+
+// writeMessage writes a message with the header at the offset given of the builder given, which is followed by a body
+// of the length given.
+func (s *StreamWriter) writeMessage(b *flatBuilder, headerType uint8, header int, bodyLength int64) error {
+	b.startTable(5)
+	b.addInt64(3, bodyLength)
+	b.addOffset(2, header)
+	b.addInt16(0, metadataVersion)
+	b.addUint8(1, headerType)
+	metadata := b.finish(b.endTable())
+
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], continuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(paddedLen(len(metadata))))
+	if _, err := s.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := s.w.Write(metadata); err != nil {
+		return err
+	}
+	_, err := s.w.Write(padding[:paddedLen(len(metadata))-len(metadata)])
+	return err
+}
+
+// writeField writes the Field table of a field of a schema, returning its offset.
+func writeField(b *flatBuilder, field Field) int {
+	name := b.string(field.Name)
+	typ := writeType(b, field.Type)
+	children := b.offsets(nil)
+
+	b.startTable(7)
+	b.addOffset(0, name)
+	b.addOffset(3, typ)
+	b.addOffset(5, children)
+	b.addUint8(1, boolByte(field.Nullable))
+	b.addUint8(2, uint8(field.Type.ID))
+	return b.endTable()
+}
+
+// writeType writes the table of a type of the Type union, returning its offset.
+func writeType(b *flatBuilder, t DataType) int {
+	switch t.ID {
+	case Int:
+		b.startTable(2)
+		b.addInt32(0, int32(t.BitWidth))
+		b.addUint8(1, boolByte(t.Signed))
+	case FloatingPoint:
+		b.startTable(1)
+		if t.BitWidth == 32 {
+			b.addInt16(0, precisionSingle)
+		} else {
+			b.addInt16(0, precisionDouble)
+		}
+	case Decimal:
+		b.startTable(3)
+		b.addInt32(0, int32(t.Precision))
+		b.addInt32(1, int32(t.Scale))
+		b.addInt32(2, int32(t.BitWidth))
+	case Date:
+		b.startTable(1)
+		b.addInt16(0, dateUnitDay)
+	case Timestamp, Duration:
+		b.startTable(1)
+		b.addInt16(0, timeUnitMicro)
+	default:
+		// Null, Binary and Utf8 have no fields
+		b.startTable(0)
+	}
+	return b.endTable()
+}
+
+// WriteStream writes all the records of the reader given to the writer given in the Arrow IPC streaming format.
+func WriteStream(w io.Writer, r *Reader) error {
+	sw := NewStreamWriter(w, r.Schema())
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return sw.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if err := sw.Write(record); err != nil {
+			return err
+		}
+	}
+}
+
+func paddedLen(n int) int {
+	return (n + 7) &^ 7
+}
+
+func boolByte(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
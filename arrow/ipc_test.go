@@ -0,0 +1,246 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// table is a table of a FlatBuffer, read as the Arrow libraries read them.
+type table struct {
+	buf []byte
+	pos int
+}
+
+func rootTable(buf []byte) table {
+	return table{buf, int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of the field given, or -1 if the table doesn't have it.
+func (t table) field(i int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	entry := 4 + 2*i
+	if entry >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return -1
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vtable+entry:]))
+	if off == 0 {
+		return -1
+	}
+	return t.pos + off
+}
+
+func (t table) uint8(i int) uint8 {
+	if p := t.field(i); p >= 0 {
+		return t.buf[p]
+	}
+	return 0
+}
+
+func (t table) int16(i int) int16 {
+	if p := t.field(i); p >= 0 {
+		return int16(binary.LittleEndian.Uint16(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t table) int32(i int) int32 {
+	if p := t.field(i); p >= 0 {
+		return int32(binary.LittleEndian.Uint32(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t table) int64(i int) int64 {
+	if p := t.field(i); p >= 0 {
+		if p%8 != 0 {
+			panic("unaligned field")
+		}
+		return int64(binary.LittleEndian.Uint64(t.buf[p:]))
+	}
+	return 0
+}
+
+func (t table) ref(i int) int {
+	p := t.field(i)
+	return p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t table) table(i int) table {
+	return table{t.buf, t.ref(i)}
+}
+
+func (t table) string(i int) string {
+	p := t.ref(i)
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
+
+func (t table) tables(i int) []table {
+	p := t.ref(i)
+	var tables []table
+	for j := 0; j < int(binary.LittleEndian.Uint32(t.buf[p:])); j++ {
+		elem := p + 4 + 4*j
+		tables = append(tables, table{t.buf, elem + int(binary.LittleEndian.Uint32(t.buf[elem:]))})
+	}
+	return tables
+}
+
+func (t table) structs(i int) [][2]int64 {
+	p := t.ref(i)
+	var structs [][2]int64
+	for j := 0; j < int(binary.LittleEndian.Uint32(t.buf[p:])); j++ {
+		elem := p + 4 + 16*j
+		if elem%8 != 0 {
+			panic("unaligned struct")
+		}
+		structs = append(structs, [2]int64{
+			int64(binary.LittleEndian.Uint64(t.buf[elem:])),
+			int64(binary.LittleEndian.Uint64(t.buf[elem+8:])),
+		})
+	}
+	return structs
+}
+
+type message struct {
+	header     table
+	headerType uint8
+	body       []byte
+}
+
+// readMessages reads the messages of a stream up to its end-of-stream marker.
+func readMessages(t *testing.T, stream []byte) []message {
+	require := require.New(t)
+
+	var messages []message
+	for {
+		require.True(len(stream) >= 8)
+		require.Equal(uint32(continuation), binary.LittleEndian.Uint32(stream))
+		n := int(binary.LittleEndian.Uint32(stream[4:]))
+		if n == 0 {
+			require.Len(stream, 8)
+			return messages
+		}
+		require.Equal(0, n%8)
+
+		// The metadata is copied, so the alignment of its fields is checked relative to its start
+		metadata := append([]byte(nil), stream[8:8+n]...)
+		msg := rootTable(metadata)
+		require.Equal(int16(metadataVersion), msg.int16(0))
+		bodyLength := int(msg.int64(3))
+		require.Equal(0, bodyLength%8)
+
+		messages = append(messages, message{
+			header:     msg.table(2),
+			headerType: msg.uint8(1),
+			body:       stream[8+n : 8+n+bodyLength],
+		})
+		stream = stream[8+n+bodyLength:]
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	r := NewReader(testSchema, sql.RowsToRowIter(testRows()...), 2)
+	require.NoError(WriteStream(&buf, r))
+	require.NoError(r.Close())
+
+	messages := readMessages(t, buf.Bytes())
+	require.Len(messages, 3)
+
+	schema := messages[0]
+	require.Equal(uint8(headerSchema), schema.headerType)
+	require.Empty(schema.body)
+	fields := schema.header.tables(1)
+	require.Len(fields, len(testSchema))
+	for i, field := range fields {
+		require.Equal(testSchema[i].Name, field.string(0))
+		require.Equal(testSchema[i].Nullable, field.uint8(1) == 1)
+		require.Equal(r.Schema()[i].Type.ID, TypeID(field.uint8(2)))
+		require.Empty(field.tables(5))
+	}
+	i8 := fields[0].table(3)
+	require.Equal(int32(8), i8.int32(0))
+	require.Equal(uint8(1), i8.uint8(1))
+	require.Equal(int16(precisionSingle), fields[3].table(3).int16(0))
+	dec := fields[5].table(3)
+	require.Equal([]int32{10, 2, 128}, []int32{dec.int32(0), dec.int32(1), dec.int32(2)})
+	require.Equal(int16(dateUnitDay), fields[6].table(3).int16(0))
+	require.Equal(int16(timeUnitMicro), fields[7].table(3).int16(0))
+
+	batch := messages[1]
+	require.Equal(uint8(headerRecordBatch), batch.headerType)
+	require.Equal(int64(2), batch.header.int64(0))
+	nodes := batch.header.structs(1)
+	require.Len(nodes, len(testSchema))
+	require.Equal([2]int64{2, 1}, nodes[0])
+	require.Equal([2]int64{2, 0}, nodes[1])
+	require.Equal([2]int64{2, 2}, nodes[12])
+
+	// Every column has a validity bitmap and values, and strings have offsets too, except for the NULL column
+	buffers := batch.header.structs(2)
+	require.Len(buffers, 2*12+3)
+	for _, b := range buffers {
+		require.Equal(int64(0), b[0]%8)
+	}
+	value := func(i int) []byte {
+		return batch.body[buffers[i][0] : buffers[i][0]+buffers[i][1]]
+	}
+	require.Equal([]byte{0x01}, value(0))
+	require.Equal([]byte{0xff, 0}, value(1))
+	require.Empty(value(2))
+	require.Equal([]byte{2, 0, 5, 0}, value(3))
+	// s is the 10th column, after 9 columns of two buffers
+	require.Equal([]byte{0x01}, value(18))
+	require.Equal([]byte{0, 0, 0, 0, 4, 0, 0, 0, 4, 0, 0, 0}, value(19))
+	require.Equal("text", string(value(20)))
+
+	require.Equal(int64(1), messages[2].header.int64(0))
+}
+
+func TestStreamWriterNoRecords(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	w := NewStreamWriter(&buf, Schema{{Name: "a", Type: DataType{ID: Utf8}}})
+	require.NoError(w.Close())
+
+	messages := readMessages(t, buf.Bytes())
+	require.Len(messages, 1)
+	require.Equal(uint8(headerSchema), messages[0].headerType)
+	fields := messages[0].header.tables(1)
+	require.Len(fields, 1)
+	require.Equal("a", fields[0].string(0))
+	require.Equal(uint8(Utf8), fields[0].uint8(2))
+}
+
+func TestFlatBuilderGrows(t *testing.T) {
+	require := require.New(t)
+
+	b := newFlatBuilder()
+	var names []int
+	for i := 0; i < 200; i++ {
+		names = append(names, b.string("a long enough name of a field"))
+	}
+	vector := b.offsets(names)
+	b.startTable(1)
+	b.addOffset(0, vector)
+	root := rootTable(b.finish(b.endTable()))
+
+	require.Len(root.tables(0), 200)
+	p := root.ref(0) + 4
+	require.Equal("a long enough name of a field", table{root.buf, p}.stringAt())
+}
+
+// stringAt reads the string referenced from the position of the table.
+func (t table) stringAt() string {
+	p := t.pos + int(binary.LittleEndian.Uint32(t.buf[t.pos:]))
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
@@ -0,0 +1,97 @@
+package arrow
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// DefaultRecordSize is the number of rows of the records of readers that don't set it.
+const DefaultRecordSize = 4096
+
+// Reader reads the rows of a result in records of its columns.
+type Reader struct {
+	schema Schema
+	types  sql.Schema
+	iter   sql.BatchRowIter
+	size   int
+	rows   []sql.Row
+	done   bool
+}
+
+// NewReader returns a reader of the rows of the iterator given, which are of the schema given, in records of at most
+// size rows, or DefaultRecordSize if it's 0. The rows are read from the iterator in batches if it's a
+// sql.BatchRowIter.
+func NewReader(schema sql.Schema, iter sql.RowIter, size int) *Reader {
+	if size <= 0 {
+		size = DefaultRecordSize
+	}
+	batch := sql.BatchSize
+	if size < batch {
+		batch = size
+	}
+	return &Reader{
+		schema: NewSchema(schema),
+		types:  schema,
+		iter:   sql.NewBatchRowIter(iter),
+		size:   size,
+		rows:   make([]sql.Row, batch),
+	}
+}
+
+// Schema returns the schema of the records of the reader.
+func (r *Reader) Schema() Schema {
+	return r.schema
+}
+
+// Next returns the next record of the result, or io.EOF once all the rows have been read. Records have as many rows
+// as the size of the reader, except for the last one, which may have fewer.
+func (r *Reader) Next() (*Record, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	builders := make([]*builder, len(r.schema))
+	for i, field := range r.schema {
+		builders[i] = newBuilder(r.types[i].Type, field.Type, r.size)
+	}
+
+	var n int
+	for n < r.size {
+		rows := r.rows
+		if len(rows) > r.size-n {
+			rows = rows[:r.size-n]
+		}
+		read, err := r.iter.NextBatch(rows)
+		if err == io.EOF {
+			r.done = true
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows[:read] {
+			for i, b := range builders {
+				if err := b.append(row[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		n += read
+	}
+	if n == 0 {
+		return nil, io.EOF
+	}
+
+	record := &Record{Schema: r.schema, Len: n, Columns: make([]*Array, len(builders))}
+	for i, b := range builders {
+		record.Columns[i] = b.arr
+	}
+	return record, nil
+}
+
+// Close closes the iterator of the rows.
+func (r *Reader) Close() error {
+	return r.iter.Close()
+}
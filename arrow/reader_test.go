@@ -0,0 +1,150 @@
+package arrow
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var testSchema = sql.Schema{
+	{Name: "i8", Type: sql.Int8, Nullable: true},
+	{Name: "u16", Type: sql.Uint16},
+	{Name: "i64", Type: sql.Int64, Nullable: true},
+	{Name: "f32", Type: sql.Float32},
+	{Name: "f64", Type: sql.Float64},
+	{Name: "dec", Type: sql.MustCreateDecimalType(10, 2), Nullable: true},
+	{Name: "d", Type: sql.Date},
+	{Name: "dt", Type: sql.Datetime},
+	{Name: "t", Type: sql.Time},
+	{Name: "s", Type: sql.LongText, Nullable: true},
+	{Name: "b", Type: sql.Blob},
+	{Name: "e", Type: sql.MustCreateEnumType([]string{"a", "b"}, sql.Collation_Default)},
+	{Name: "n", Type: sql.Null, Nullable: true},
+}
+
+func testRows() []sql.Row {
+	return []sql.Row{
+		{int8(-1), uint16(2), int64(3), float32(1.5), 2.5, decimal.RequireFromString("-12.34"),
+			time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+			"-01:02:03", "text", "\x00\x01", "b", nil},
+		{nil, uint16(5), nil, float32(-1), 0.0, nil,
+			time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC),
+			"838:59:59", nil, "", "a", nil},
+		{int8(7), uint16(0), int64(-9), float32(0), -3.25, decimal.RequireFromString("99999999.99"),
+			time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC),
+			"00:00:00", "", "x", "a", nil},
+	}
+}
+
+func TestReader(t *testing.T) {
+	require := require.New(t)
+
+	r := NewReader(testSchema, sql.RowsToRowIter(testRows()...), 2)
+	require.Equal(Schema{
+		{Name: "i8", Type: DataType{ID: Int, BitWidth: 8, Signed: true}, Nullable: true},
+		{Name: "u16", Type: DataType{ID: Int, BitWidth: 16}},
+		{Name: "i64", Type: DataType{ID: Int, BitWidth: 64, Signed: true}, Nullable: true},
+		{Name: "f32", Type: DataType{ID: FloatingPoint, BitWidth: 32}},
+		{Name: "f64", Type: DataType{ID: FloatingPoint, BitWidth: 64}},
+		{Name: "dec", Type: DataType{ID: Decimal, BitWidth: 128, Precision: 10, Scale: 2}, Nullable: true},
+		{Name: "d", Type: DataType{ID: Date}},
+		{Name: "dt", Type: DataType{ID: Timestamp}},
+		{Name: "t", Type: DataType{ID: Duration}},
+		{Name: "s", Type: DataType{ID: Utf8}, Nullable: true},
+		{Name: "b", Type: DataType{ID: Binary}},
+		{Name: "e", Type: DataType{ID: Utf8}},
+		{Name: "n", Type: DataType{ID: Null}, Nullable: true},
+	}, r.Schema())
+
+	first, err := r.Next()
+	require.NoError(err)
+	require.Equal(2, first.Len)
+	second, err := r.Next()
+	require.NoError(err)
+	require.Equal(1, second.Len)
+	_, err = r.Next()
+	require.Equal(io.EOF, err)
+	require.NoError(r.Close())
+
+	duration := func(s string) time.Duration {
+		d, err := sql.Time.ConvertToTimeDuration(s)
+		require.NoError(err)
+		return d
+	}
+	dec := func(s string) decimal.Decimal {
+		return decimal.RequireFromString(s)
+	}
+	row := func(r sql.Row) sql.Row {
+		// Decimals are compared by their string, since equal decimals may have different exponents
+		if d, ok := r[5].(decimal.Decimal); ok {
+			r[5] = d.StringFixed(2)
+		}
+		return r
+	}
+	require.Equal(row(sql.Row{int8(-1), uint16(2), int64(3), float32(1.5), 2.5, dec("-12.34"),
+		time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		duration("-01:02:03"), "text", []byte{0, 1}, "b", nil}), row(first.Row(0)))
+	require.Equal(row(sql.Row{nil, uint16(5), nil, float32(-1), 0.0, nil,
+		time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC),
+		duration("838:59:59"), nil, []byte{}, "a", nil}), row(first.Row(1)))
+	require.Equal(row(sql.Row{int8(7), uint16(0), int64(-9), float32(0), -3.25, dec("99999999.99"),
+		time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC),
+		time.Duration(0), "", []byte("x"), "a", nil}), row(second.Row(0)))
+
+	// The columns are in the layout of the Arrow format
+	i8 := first.Columns[0]
+	require.Equal(1, i8.NullCount)
+	require.Equal([]byte{0x01}, i8.Validity)
+	require.Equal([]byte{0xff, 0}, i8.Values)
+	require.Nil(first.Columns[1].Validity)
+	s := first.Columns[9]
+	require.Equal([]int32{0, 4, 4}, s.Offsets)
+	require.Equal("text", string(s.Values))
+	require.Equal([]byte{0x01}, s.Validity)
+	require.Equal(int64(-1), first.Columns[6].Int64(0))
+	require.Equal(first.Len, first.Columns[12].NullCount)
+	require.True(first.Columns[12].IsNull(0))
+}
+
+func TestReaderNoRows(t *testing.T) {
+	require := require.New(t)
+
+	r := NewReader(testSchema, sql.RowsToRowIter(), 0)
+	_, err := r.Next()
+	require.Equal(io.EOF, err)
+	require.NoError(r.Close())
+}
+
+func TestReaderManyRows(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{{Name: "i", Type: sql.Int32, Nullable: true}}
+	var rows []sql.Row
+	for i := 0; i < 1000; i++ {
+		var v interface{} = int32(i)
+		if i%3 == 0 {
+			v = nil
+		}
+		rows = append(rows, sql.Row{v})
+	}
+
+	r := NewReader(schema, sql.RowsToRowIter(rows...), 600)
+	var read []sql.Row
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+		require.True(record.Len <= 600)
+		for i := 0; i < record.Len; i++ {
+			read = append(read, record.Row(i))
+		}
+	}
+	require.Equal(rows, read)
+}
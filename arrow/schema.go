@@ -0,0 +1,120 @@
+package arrow
+
+import (
+	"github.com/dolthub/vitess/go/sqltypes"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// TypeID is the kind of a DataType, whose values are the ones of the Type union of the Arrow format.
+type TypeID uint8
+
+const (
+	// Null values are all NULL, and have no buffers.
+	Null TypeID = 1
+	// Int values are integers of BitWidth bits, which are signed if Signed is true.
+	Int TypeID = 2
+	// FloatingPoint values are floats of BitWidth bits, which is 32 or 64.
+	FloatingPoint TypeID = 3
+	// Binary values are byte strings of any length.
+	Binary TypeID = 4
+	// Utf8 values are strings of any length.
+	Utf8 TypeID = 5
+	// Decimal values are the two's complement 128-bit integers that the decimals of Scale digits after the point
+	// are multiplied by 10^Scale into.
+	Decimal TypeID = 7
+	// Date values are the 32-bit number of days since the UNIX epoch.
+	Date TypeID = 8
+	// Timestamp values are the 64-bit number of microseconds since the UNIX epoch, with no time zone.
+	Timestamp TypeID = 10
+	// Duration values are a 64-bit number of microseconds.
+	Duration TypeID = 18
+)
+
+// DataType is the type of the values of an array.
+type DataType struct {
+	ID TypeID
+	// BitWidth is the size of the values of Int and FloatingPoint types, and of Decimal types, which is 128.
+	BitWidth int
+	Signed   bool
+	// Precision and Scale are the total number of digits and the number of digits after the point of Decimal types.
+	Precision int
+	Scale     int
+}
+
+// width returns the size in bytes of the values of the type, or 0 if they're of variable size or have no buffer.
+func (t DataType) width() int {
+	switch t.ID {
+	case Int, FloatingPoint, Decimal:
+		return t.BitWidth / 8
+	case Date:
+		return 4
+	case Timestamp, Duration:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// Field is a column of a Schema.
+type Field struct {
+	Name     string
+	Type     DataType
+	Nullable bool
+}
+
+// Schema are the columns of the records of a result.
+type Schema []Field
+
+// NewSchema returns the schema of the Arrow records of rows of the schema given. Integers, floats, DATE, DATETIME,
+// TIMESTAMP and TIME columns are Int, FloatingPoint, Date, Timestamp and Duration columns, DECIMAL columns are Decimal
+// columns if they have at most 38 digits, BIT columns are unsigned 64-bit Int columns, and BLOB, BINARY, VARBINARY and
+// GEOMETRY columns are Binary columns. Any other column, such as a string, ENUM, SET, JSON or larger DECIMAL column,
+// is a Utf8 column of the values in the text format of MySQL.
+func NewSchema(schema sql.Schema) Schema {
+	fields := make(Schema, len(schema))
+	for i, col := range schema {
+		fields[i] = Field{Name: col.Name, Type: dataType(col.Type), Nullable: col.Nullable}
+	}
+	return fields
+}
+
+func dataType(typ sql.Type) DataType {
+	switch typ.Type() {
+	case sqltypes.Null:
+		return DataType{ID: Null}
+	case sqltypes.Int8:
+		return DataType{ID: Int, BitWidth: 8, Signed: true}
+	case sqltypes.Uint8:
+		return DataType{ID: Int, BitWidth: 8}
+	case sqltypes.Int16, sqltypes.Year:
+		return DataType{ID: Int, BitWidth: 16, Signed: true}
+	case sqltypes.Uint16:
+		return DataType{ID: Int, BitWidth: 16}
+	case sqltypes.Int24, sqltypes.Int32:
+		return DataType{ID: Int, BitWidth: 32, Signed: true}
+	case sqltypes.Uint24, sqltypes.Uint32:
+		return DataType{ID: Int, BitWidth: 32}
+	case sqltypes.Int64:
+		return DataType{ID: Int, BitWidth: 64, Signed: true}
+	case sqltypes.Uint64, sqltypes.Bit:
+		return DataType{ID: Int, BitWidth: 64}
+	case sqltypes.Float32:
+		return DataType{ID: FloatingPoint, BitWidth: 32}
+	case sqltypes.Float64:
+		return DataType{ID: FloatingPoint, BitWidth: 64}
+	case sqltypes.Decimal:
+		if dt, ok := typ.(sql.DecimalType); ok && dt.Precision() <= 38 {
+			return DataType{ID: Decimal, BitWidth: 128, Precision: int(dt.Precision()), Scale: int(dt.Scale())}
+		}
+	case sqltypes.Date:
+		return DataType{ID: Date}
+	case sqltypes.Datetime, sqltypes.Timestamp:
+		return DataType{ID: Timestamp}
+	case sqltypes.Time:
+		return DataType{ID: Duration}
+	case sqltypes.Blob, sqltypes.Binary, sqltypes.VarBinary, sqltypes.Geometry:
+		return DataType{ID: Binary}
+	}
+	return DataType{ID: Utf8}
+}
@@ -71,6 +71,30 @@ func (a *Audit) Allowed(ctx *sql.Context, permission Permission) error {
 	return err
 }
 
+// Accounts implements the sql.AccountLister interface, listing the accounts of the wrapped Auth if it can.
+func (a *Audit) Accounts() []sql.Account {
+	if l, ok := a.auth.(sql.AccountLister); ok {
+		return l.Accounts()
+	}
+	return nil
+}
+
+// Account implements the sql.AccountLister interface.
+func (a *Audit) Account(user string) (sql.Account, bool) {
+	if l, ok := a.auth.(sql.AccountLister); ok {
+		return l.Account(user)
+	}
+	return sql.Account{}, false
+}
+
+// AlterAccount implements the sql.AccountManager interface, altering the accounts of the wrapped Auth if it can.
+func (a *Audit) AlterAccount(user string, alteration sql.AccountAlteration) error {
+	if m, ok := a.auth.(sql.AccountManager); ok {
+		return m.AlterAccount(user, alteration)
+	}
+	return sql.ErrUserOperationFailed.New("ALTER USER", user, "%")
+}
+
 // Query implements AuditQuery interface.
 func (a *Audit) Query(ctx *sql.Context, d time.Duration, err error) {
 	if q, ok := a.auth.(*Audit); ok {
@@ -17,19 +17,25 @@ const (
 	ReadPerm Permission = 1 << iota
 	// WritePerm means that it writes.
 	WritePerm
+	// UnmaskPerm means that it sees the unmasked values of masked columns.
+	UnmaskPerm
+	// SuperPerm means that it modifies databases while the engine is in read_only mode.
+	SuperPerm
 )
 
 var (
 	// AllPermissions hold all defined permissions.
-	AllPermissions = ReadPerm | WritePerm
+	AllPermissions = ReadPerm | WritePerm | UnmaskPerm | SuperPerm
 	// DefaultPermissions are the permissions granted to a user if not defined.
 	DefaultPermissions = ReadPerm
 
 	// PermissionNames is used to translate from human to machine
 	// representations.
 	PermissionNames = map[string]Permission{
-		"read":  ReadPerm,
-		"write": WritePerm,
+		"read":   ReadPerm,
+		"write":  WritePerm,
+		"unmask": UnmaskPerm,
+		"super":  SuperPerm,
 	}
 
 	// ErrNotAuthorized is returned when the user is not allowed to use a
@@ -51,6 +57,29 @@ func (p Permission) String() string {
 	return strings.Join(str, ", ")
 }
 
+// sqlPrivileges are the names of the MySQL privileges each permission grants. UnmaskPerm has no MySQL equivalent, and
+// is shown as UNMASK.
+var sqlPrivileges = []struct {
+	permission Permission
+	privileges []string
+}{
+	{ReadPerm, []string{"SELECT"}},
+	{WritePerm, []string{"INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "INDEX", "ALTER", "LOCK TABLES", "CREATE VIEW", "TRIGGER"}},
+	{SuperPerm, []string{"SUPER"}},
+	{UnmaskPerm, []string{"UNMASK"}},
+}
+
+// Privileges returns the names of the MySQL privileges the permissions grant, as SHOW GRANTS shows them.
+func (p Permission) Privileges() []string {
+	var privileges []string
+	for _, sp := range sqlPrivileges {
+		if p&sp.permission != 0 {
+			privileges = append(privileges, sp.privileges...)
+		}
+	}
+	return privileges
+}
+
 // Auth interface provides mysql authentication methods and permission checking
 // for users.
 type Auth interface {
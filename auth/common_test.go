@@ -151,10 +151,11 @@ func testAuthorization(
 				sql.WithIndexRegistry(idxReg),
 				sql.WithViewRegistry(sql.NewViewRegistry())).WithCurrentDB("test")
 
-			_, _, err := e.Query(ctx, c.query)
+			_, iter, err := e.Query(ctx, c.query)
 
 			if c.success {
 				req.NoError(err)
+				req.NoError(iter.Close())
 				return
 			}
 
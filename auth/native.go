@@ -6,8 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
 
@@ -26,12 +31,33 @@ var (
 	ErrDuplicateUser = errors.NewKind("duplicate user, %s")
 )
 
+// The codes of the login errors vitess doesn't define.
+const (
+	erAccountHasBeenLocked      = 3118
+	erUserBlockedByPasswordLock = 3955
+)
+
 // nativeUser holds information about credentials and permissions for a user.
 type nativeUser struct {
 	Name            string
 	Password        string
 	JSONPermissions []string `json:"Permissions"`
 	Permissions     Permission
+	// PasswordExpired makes the user change the password before running any other statement.
+	PasswordExpired bool `json:"password_expired"`
+	// Locked prevents the user from logging in.
+	Locked bool `json:"account_locked"`
+	// FailedLoginAttempts is the number of consecutive failed logins that block the user for PasswordLockTime days,
+	// or for good if it's sql.UnboundedPasswordLockTime. Users are never blocked if either is zero.
+	FailedLoginAttempts int `json:"failed_login_attempts"`
+	PasswordLockTime    int `json:"password_lock_time"`
+
+	// failedLogins is the number of consecutive failed logins since the last one that succeeded.
+	failedLogins int
+	// blocked is whether the user is blocked after too many failed logins, until blockedUntil, or for good if it's
+	// zero.
+	blocked      bool
+	blockedUntil time.Time
 }
 
 // Allowed checks if the user has certain permission.
@@ -67,9 +93,16 @@ func NativePassword(password string) string {
 	return fmt.Sprintf("*%s", s)
 }
 
+// isBlocked returns whether the user is blocked after too many failed logins at the time given.
+func (u nativeUser) isBlocked(now time.Time) bool {
+	return u.blocked && (u.blockedUntil.IsZero() || now.Before(u.blockedUntil))
+}
+
 // Native holds mysql_native_password users.
 type Native struct {
-	users map[string]nativeUser
+	mu     sync.RWMutex
+	users  map[string]nativeUser
+	policy PasswordPolicy
 }
 
 // NewNativeSingle creates a NativeAuth with a single user with given
@@ -82,7 +115,7 @@ func NewNativeSingle(name, password string, perm Permission) *Native {
 		Permissions: perm,
 	}
 
-	return &Native{users}
+	return &Native{users: users}
 }
 
 // NewNativeFile creates a NativeAuth and loads users from a JSON file.
@@ -125,31 +158,234 @@ func NewNativeFile(file string) (*Native, error) {
 		users[u.Name] = u
 	}
 
-	return &Native{users}, nil
+	return &Native{users: users}, nil
 }
 
-// Mysql implements Auth interface.
+// Mysql implements Auth interface. The users are looked up on each login, so that the changes of ALTER USER apply to
+// the connections made after them.
 func (s *Native) Mysql() mysql.AuthServer {
-	auth := mysql.NewAuthServerStatic()
-
-	for k, v := range s.users {
-		auth.Entries[k] = []*mysql.AuthServerStaticEntry{
-			{
-				MysqlNativePassword: v.Password,
-				Password:            v.Password},
-		}
-	}
-
-	return auth
+	return &nativeAuthServer{native: s}
 }
 
 // Allowed implements Auth interface.
 func (s *Native) Allowed(ctx *sql.Context, permission Permission) error {
 	name := ctx.Client().User
+	s.mu.RLock()
 	u, ok := s.users[name]
+	s.mu.RUnlock()
 	if !ok {
 		return ErrNotAuthorized.Wrap(ErrNoPermission.New(permission))
 	}
 
 	return u.Allowed(permission)
 }
+
+// Accounts implements the sql.AccountLister interface.
+func (s *Native) Accounts() []sql.Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var accounts []sql.Account
+	now := time.Now()
+	for _, u := range s.users {
+		accounts = append(accounts, u.account(now))
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].User < accounts[j].User })
+	return accounts
+}
+
+// Account implements the sql.AccountLister interface.
+func (s *Native) Account(user string) (sql.Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[user]
+	if !ok {
+		return sql.Account{}, false
+	}
+	return u.account(time.Now()), true
+}
+
+func (u nativeUser) account(now time.Time) sql.Account {
+	return sql.Account{
+		User:                 u.Name,
+		Host:                 "%",
+		Privileges:           u.Permissions.Privileges(),
+		Plugin:               mysql.MysqlNativePassword,
+		AuthenticationString: u.Password,
+		PasswordExpired:      u.PasswordExpired,
+		Locked:               u.Locked || u.isBlocked(now),
+	}
+}
+
+// SetPasswordPolicy sets the policy the new passwords of ALTER USER are validated with. Passwords aren't validated
+// if it's nil, which is the default.
+func (s *Native) SetPasswordPolicy(policy PasswordPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policy = policy
+}
+
+// AlterAccount implements the sql.AccountManager interface. New passwords are validated with the password policy.
+// Unlocking the user, or changing when it's blocked after failed logins, unblocks it.
+func (s *Native) AlterAccount(user string, alteration sql.AccountAlteration) error {
+	s.mu.RLock()
+	policy := s.policy
+	s.mu.RUnlock()
+
+	if alteration.Password != nil && policy != nil {
+		if err := policy(user, *alteration.Password); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[user]
+	if !ok {
+		return sql.ErrUserOperationFailed.New("ALTER USER", user, "%")
+	}
+
+	if alteration.Password != nil {
+		u.Password = NativePassword(*alteration.Password)
+		u.PasswordExpired = false
+	}
+	if alteration.ExpirePassword {
+		u.PasswordExpired = true
+	}
+	if alteration.FailedLoginAttempts != nil {
+		u.FailedLoginAttempts = *alteration.FailedLoginAttempts
+	}
+	if alteration.PasswordLockTime != nil {
+		u.PasswordLockTime = *alteration.PasswordLockTime
+	}
+	if alteration.Locked != nil {
+		u.Locked = *alteration.Locked
+	}
+	if (alteration.Locked != nil && !*alteration.Locked) ||
+		alteration.FailedLoginAttempts != nil || alteration.PasswordLockTime != nil {
+		u.failedLogins, u.blocked = 0, false
+	}
+
+	s.users[user] = u
+	return nil
+}
+
+// login checks whether the user can log in, and validates its credentials with the validate function given, which is
+// handed an AuthServer with the current password of the user. The failed logins of the user are counted, and the
+// user is blocked after too many of them.
+func (s *Native) login(
+	user string,
+	remoteAddr net.Addr,
+	validate func(mysql.AuthServer) (mysql.Getter, error),
+) (mysql.Getter, error) {
+	host := remoteAddr.String()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	s.mu.Lock()
+	u, ok := s.users[user]
+	if ok && u.blocked && !u.isBlocked(time.Now()) {
+		u.failedLogins, u.blocked = 0, false
+		s.users[user] = u
+	}
+	s.mu.Unlock()
+
+	static := mysql.NewAuthServerStatic()
+	if ok {
+		if u.Locked {
+			return &mysql.StaticUserData{}, mysql.NewSQLError(
+				erAccountHasBeenLocked, mysql.SSUnknownSQLState,
+				"Access denied for user '%s'@'%s'. Account is locked.", user, host,
+			)
+		}
+		if u.blocked {
+			return &mysql.StaticUserData{}, u.blockedError(host, time.Now())
+		}
+
+		static.Entries[user] = []*mysql.AuthServerStaticEntry{
+			{
+				MysqlNativePassword: u.Password,
+				Password:            u.Password,
+			},
+		}
+	}
+
+	getter, err := validate(static)
+	if !ok {
+		return getter, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok = s.users[user]
+	if !ok {
+		return getter, err
+	}
+	switch {
+	case err == nil:
+		u.failedLogins = 0
+	case u.FailedLoginAttempts > 0 && u.PasswordLockTime != 0:
+		u.failedLogins++
+		if u.failedLogins >= u.FailedLoginAttempts {
+			u.failedLogins, u.blocked, u.blockedUntil = 0, true, time.Time{}
+			if u.PasswordLockTime != sql.UnboundedPasswordLockTime {
+				u.blockedUntil = time.Now().Add(time.Duration(u.PasswordLockTime) * 24 * time.Hour)
+			}
+		}
+	}
+	s.users[user] = u
+	return getter, err
+}
+
+// blockedError returns the error of the logins of the user while it's blocked after too many failed logins.
+func (u nativeUser) blockedError(host string, now time.Time) error {
+	days, remaining := "unlimited", "unlimited"
+	if !u.blockedUntil.IsZero() {
+		days = strconv.Itoa(u.PasswordLockTime)
+		remaining = strconv.Itoa(int((u.blockedUntil.Sub(now) + 24*time.Hour - 1) / (24 * time.Hour)))
+	}
+	return mysql.NewSQLError(
+		erUserBlockedByPasswordLock, mysql.SSUnknownSQLState,
+		"Access denied for user '%s'@'%s'. Account is blocked for %s day(s) (%s day(s) remaining) due to %d consecutive failed logins.",
+		u.Name, host, days, remaining, u.FailedLoginAttempts,
+	)
+}
+
+// nativeAuthServer is the mysql.AuthServer of the Native users.
+type nativeAuthServer struct {
+	native *Native
+}
+
+// AuthMethod implements the mysql.AuthServer interface.
+func (a *nativeAuthServer) AuthMethod(user string) (string, error) {
+	return mysql.MysqlNativePassword, nil
+}
+
+// Salt implements the mysql.AuthServer interface.
+func (a *nativeAuthServer) Salt() ([]byte, error) {
+	return mysql.NewSalt()
+}
+
+// ValidateHash implements the mysql.AuthServer interface.
+func (a *nativeAuthServer) ValidateHash(
+	salt []byte,
+	user string,
+	authResponse []byte,
+	remoteAddr net.Addr,
+) (mysql.Getter, error) {
+	return a.native.login(user, remoteAddr, func(static mysql.AuthServer) (mysql.Getter, error) {
+		return static.ValidateHash(salt, user, authResponse, remoteAddr)
+	})
+}
+
+// Negotiate implements the mysql.AuthServer interface.
+func (a *nativeAuthServer) Negotiate(c *mysql.Conn, user string, remoteAddr net.Addr) (mysql.Getter, error) {
+	return a.native.login(user, remoteAddr, func(static mysql.AuthServer) (mysql.Getter, error) {
+		return static.Negotiate(c, user, remoteAddr)
+	})
+}
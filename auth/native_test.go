@@ -1,11 +1,13 @@
 package auth_test
 
 import (
+	dsql "database/sql"
 	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/require"
@@ -223,6 +225,145 @@ func TestNativeAuthorization(t *testing.T) {
 	testAuthorization(t, a, tests, nil)
 }
 
+func TestNativeAccounts(t *testing.T) {
+	require := require.New(t)
+
+	conf, err := writeConfig(baseConfig)
+	require.NoError(err)
+	defer os.Remove(conf)
+
+	a, err := auth.NewNativeFile(conf)
+	require.NoError(err)
+
+	var users []string
+	for _, account := range a.Accounts() {
+		users = append(users, account.User)
+	}
+	require.Equal([]string{"empty_password", "no_password", "no_permissions", "root", "user"}, users)
+
+	account, ok := a.Account("user")
+	require.True(ok)
+	require.Equal("%", account.Host)
+	require.Equal([]string{"SELECT"}, account.Privileges)
+	require.Equal("mysql_native_password", account.Plugin)
+	require.Equal(auth.NativePassword("password"), account.AuthenticationString)
+
+	account, ok = a.Account("root")
+	require.True(ok)
+	require.True(account.HasPrivilege("select"))
+	require.True(account.HasPrivilege("LOCK TABLES"))
+	require.False(account.HasPrivilege("SUPER"))
+
+	_, ok = a.Account("nonexistent")
+	require.False(ok)
+}
+
+func TestNativePasswordManagement(t *testing.T) {
+	require := require.New(t)
+
+	conf, err := writeConfig(`[
+		{"name": "admin", "password": "admin", "permissions": ["read", "write", "super"]},
+		{"name": "user", "password": "password"}
+	]`)
+	require.NoError(err)
+	defer os.Remove(conf)
+
+	a, err := auth.NewNativeFile(conf)
+	require.NoError(err)
+
+	s, _, err := authServer(a)
+	require.NoError(err)
+	defer s.Close()
+
+	exec := func(user, password, query string) error {
+		db, err := dsql.Open("mysql", connString(user, password))
+		require.NoError(err)
+		defer db.Close()
+
+		_, err = db.Exec(query)
+		return err
+	}
+
+	// Users change their own passwords, but not the ones of others
+	require.NoError(exec("user", "password", "ALTER USER USER() IDENTIFIED BY 'new_password'"))
+	require.Error(exec("user", "password", "SELECT 1"))
+	require.NoError(exec("user", "new_password", "SELECT 1"))
+	err = exec("user", "new_password", "ALTER USER admin IDENTIFIED BY 'password'")
+	require.Error(err)
+	require.Contains(err.Error(), "not authorized")
+
+	// Expired passwords must be changed before running any other statement
+	require.NoError(exec("admin", "admin", "ALTER USER 'user'@'%' PASSWORD EXPIRE"))
+	err = exec("user", "new_password", "SELECT 1")
+	require.Error(err)
+	require.Contains(err.Error(), "You must reset your password")
+	require.NoError(exec("user", "new_password", "ALTER USER CURRENT_USER IDENTIFIED BY 'password'"))
+	require.NoError(exec("user", "password", "SELECT 1"))
+
+	// Locked accounts can't log in
+	require.NoError(exec("admin", "admin", "ALTER USER user ACCOUNT LOCK"))
+	err = exec("user", "password", "SELECT 1")
+	require.Error(err)
+	require.Contains(err.Error(), "Account is locked")
+	require.NoError(exec("admin", "admin", "ALTER USER user ACCOUNT UNLOCK"))
+	require.NoError(exec("user", "password", "SELECT 1"))
+
+	// Users are blocked after too many consecutive failed logins
+	require.NoError(exec("admin", "admin", "ALTER USER user FAILED_LOGIN_ATTEMPTS 2 PASSWORD_LOCK_TIME UNBOUNDED"))
+	require.Error(exec("user", "wrong", "SELECT 1"))
+	require.NoError(exec("user", "password", "SELECT 1"))
+	require.Error(exec("user", "wrong", "SELECT 1"))
+	require.Error(exec("user", "wrong", "SELECT 1"))
+	err = exec("user", "password", "SELECT 1")
+	require.Error(err)
+	require.Contains(err.Error(), "Account is blocked for unlimited day(s)")
+	account, ok := a.Account("user")
+	require.True(ok)
+	require.True(account.Locked)
+	require.NoError(exec("admin", "admin", "ALTER USER user ACCOUNT UNLOCK"))
+	require.NoError(exec("user", "password", "SELECT 1"))
+
+	// New passwords are validated with the password policy
+	a.SetPasswordPolicy(auth.MediumPasswordRequirements.Validate)
+	err = exec("user", "password", "ALTER USER USER() IDENTIFIED BY 'weak'")
+	require.Error(err)
+	require.Contains(err.Error(), "does not satisfy the current policy requirements")
+	require.NoError(exec("user", "password", "ALTER USER USER() IDENTIFIED BY 'Str0ng!pass'"))
+	require.NoError(exec("user", "Str0ng!pass", "SELECT 1"))
+}
+
+func TestPasswordRequirements(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"Str0ng!pass", true},
+		{"Sh0rt!", false},
+		{"n0upper!case", false},
+		{"N0LOWER!CASE", false},
+		{"No!Numbers", false},
+		{"N0Special", false},
+		{"J0hnny!!", true},
+		{"!!ynnh0J", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			err := auth.MediumPasswordRequirements.Validate("user", tt.password)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.True(t, sql.ErrNotValidPassword.Is(err), "%v", err)
+			}
+		})
+	}
+
+	policy := auth.PasswordRequirements{CheckUserName: true}
+	require.True(t, sql.ErrNotValidPassword.Is(policy.Validate("johnny", "Johnny")))
+	require.True(t, sql.ErrNotValidPassword.Is(policy.Validate("johnny", "ynnhoj")))
+	require.NoError(t, policy.Validate("johnny", "johnn"))
+}
+
 func TestNativeErrors(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -18,3 +18,13 @@ func (n *None) Mysql() mysql.AuthServer {
 func (n *None) Allowed(ctx *sql.Context, permission Permission) error {
 	return nil
 }
+
+// Accounts implements the sql.AccountLister interface. There are no known users, since anyone can connect.
+func (n *None) Accounts() []sql.Account {
+	return nil
+}
+
+// Account implements the sql.AccountLister interface. Every user has all the privileges.
+func (n *None) Account(user string) (sql.Account, bool) {
+	return sql.Account{User: user, Host: "%", Privileges: []string{sql.AllPrivileges}}, true
+}
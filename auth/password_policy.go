@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// PasswordPolicy validates the new password of a user, like the validate_password component of MySQL does. It
+// returns sql.ErrNotValidPassword, or any other error, for the passwords it rejects.
+type PasswordPolicy func(user, password string) error
+
+// PasswordRequirements are the length and the characters passwords must have, and whether they can be the name of
+// their user, as the variables of validate_password define them.
+type PasswordRequirements struct {
+	// Length is the minimum number of characters.
+	Length int
+	// MixedCaseCount is the minimum number of lower case characters, and of upper case characters.
+	MixedCaseCount int
+	// NumberCount is the minimum number of digits.
+	NumberCount int
+	// SpecialCharCount is the minimum number of characters that aren't letters or digits.
+	SpecialCharCount int
+	// CheckUserName rejects the passwords that are the name of their user, or the name reversed.
+	CheckUserName bool
+}
+
+// MediumPasswordRequirements are the requirements of the MEDIUM policy of validate_password, its default one.
+var MediumPasswordRequirements = PasswordRequirements{
+	Length:           8,
+	MixedCaseCount:   1,
+	NumberCount:      1,
+	SpecialCharCount: 1,
+	CheckUserName:    true,
+}
+
+// Validate is a PasswordPolicy that returns sql.ErrNotValidPassword for the passwords that don't satisfy the
+// requirements.
+func (r PasswordRequirements) Validate(user, password string) error {
+	var length, lower, upper, numbers, special int
+	for _, c := range password {
+		length++
+		switch {
+		case unicode.IsLower(c):
+			lower++
+		case unicode.IsUpper(c):
+			upper++
+		case unicode.IsDigit(c):
+			numbers++
+		case !unicode.IsLetter(c):
+			special++
+		}
+	}
+
+	if length < r.Length || lower < r.MixedCaseCount || upper < r.MixedCaseCount ||
+		numbers < r.NumberCount || special < r.SpecialCharCount {
+		return sql.ErrNotValidPassword.New()
+	}
+	if r.CheckUserName && user != "" && (strings.EqualFold(password, user) || strings.EqualFold(password, reverse(user))) {
+		return sql.ErrNotValidPassword.New()
+	}
+	return nil
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
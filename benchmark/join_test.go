@@ -0,0 +1,59 @@
+package benchmark
+
+import (
+	"testing"
+
+	sqle "github.com/dolthub/go-mysql-server"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func BenchmarkIndexedJoin(b *testing.B) {
+	db := memory.NewDatabase("bench")
+	customers, err := memory.NewRandomTable("customers", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "customers", PrimaryKey: true},
+		{Name: "name", Type: sql.Text, Source: "customers"},
+	}, 10000, memory.RandomTableOptions{
+		Seed:    1,
+		Columns: map[string]memory.Distribution{"id": memory.Sequence(0)},
+	}, memory.WithPartitions(4))
+	if err != nil {
+		b.Fatal(err)
+	}
+	customers.EnablePrimaryKeyIndexes()
+	db.AddTable("customers", customers)
+
+	// Orders of a few customers are much more frequent than the rest
+	orders, err := memory.NewRandomTable("orders", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "orders", PrimaryKey: true},
+		{Name: "customer_id", Type: sql.Int64, Source: "orders"},
+		{Name: "amount", Type: sql.Float64, Source: "orders"},
+	}, 100000, memory.RandomTableOptions{
+		Seed: 1,
+		Columns: map[string]memory.Distribution{
+			"customer_id": memory.Zipf(1.1, 9999),
+			"amount":      memory.Normal(100, 20),
+		},
+	}, memory.WithPartitions(4))
+	if err != nil {
+		b.Fatal(err)
+	}
+	db.AddTable("orders", orders)
+
+	e := sqle.NewDefault()
+	e.AddDatabase(db)
+	ctx := sql.NewEmptyContext()
+	ctx.SetCurrentDatabase("bench")
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		_, iter, err := e.Query(ctx, "SELECT c.name, o.amount FROM orders o JOIN customers c ON o.customer_id = c.id WHERE o.amount > 150")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := sql.RowIterToRows(iter); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
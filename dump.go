@@ -0,0 +1,36 @@
+package sqle
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Dump writes a dump of databases of the engine to the writer given, in the format of mysqldump, so they can be
+// recreated in this engine or in MySQL. Databases that are sql.SnapshotDatabases are dumped from a consistent snapshot
+// of their tables. See plan.NewDumpIter.
+func (e *Engine) Dump(ctx *sql.Context, w io.Writer, opts plan.DumpOptions) (err error) {
+	iter, err := plan.NewDumpIter(ctx, e.Catalog, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := iter.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, row[0].(string)+"\n"); err != nil {
+			return err
+		}
+	}
+}
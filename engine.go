@@ -14,6 +14,7 @@ import (
 	"github.com/dolthub/go-mysql-server/sql/expression/function"
 	"github.com/dolthub/go-mysql-server/sql/parse"
 	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/tablefunction"
 )
 
 // Config for the Engine.
@@ -22,6 +23,29 @@ type Config struct {
 	VersionPostfix string
 	// Auth used for authentication and authorization.
 	Auth auth.Auth
+	// ResultCacheSize is the maximum number of query results kept in the result cache. The result cache is disabled
+	// if zero. Sessions choose which queries are cached with the query_cache_type session variable.
+	ResultCacheSize int
+	// ResultCacheTTL is how long query results are kept in the result cache. Zero means results are kept until they
+	// are invalidated.
+	ResultCacheTTL time.Duration
+	// CaseSensitiveNames makes the names of databases, tables and columns case sensitive, like MySQL with
+	// lower_case_table_names set to 0. Names are case insensitive by default.
+	CaseSensitiveNames bool
+	// DeterministicOrder makes queries return their rows in the same order every time, for tests comparing results
+	// with golden outputs. Sorts are stable, keeping equal rows in the order tables return them, and the rows of
+	// queries without an ORDER BY are sorted by all their columns. Queries aren't parallelized when it's set.
+	DeterministicOrder bool
+	// PartialStarExpansion makes `SELECT *` expand to the columns the user is granted in the tables with column
+	// privileges, instead of failing because other columns aren't granted.
+	PartialStarExpansion bool
+	// StatisticsRefreshThreshold is the fraction of the rows of a table that must be inserted, updated or deleted
+	// after its last analysis for it to be analyzed again in the background, like with ANALYZE TABLE. Tables are
+	// only analyzed by ANALYZE TABLE if it's zero.
+	StatisticsRefreshThreshold float64
+	// CostModel has the costs the analyzer estimates the costs of plans with, to choose the cheapest ones, which
+	// EXPLAIN FORMAT=COST shows. The analyzer.DefaultCostModel is used if it's nil.
+	CostModel *analyzer.CostModel
 }
 
 // Engine is a SQL engine.
@@ -30,8 +54,41 @@ type Engine struct {
 	Analyzer *analyzer.Analyzer
 	Auth     auth.Auth
 	LS       *sql.LockSubsystem
+	// ResultCache holds the results of deterministic read-only queries, if enabled in the Config.
+	ResultCache *sql.ResultCache
+	// WriteQueue runs the statements writing to each SingleWriterDatabase one at a time.
+	WriteQueue *sql.WriteQueue
+	// StatisticsRefresher analyzes the tables whose rows changed enough again, if enabled in the Config.
+	StatisticsRefresher *sql.StatisticsRefresher
+
+	// version is the value of the version system variable, which is the one returned by VERSION().
+	version string
+	// admission limits the queries running at the same time, as set in the admission control system variables.
+	admission *sql.AdmissionController
+
+	// prepared are the queries prepared by every session.
+	prepared preparedQueries
+
+	preParseHooks    []PreParseHook
+	postParseHooks   []PostParseHook
+	errorTranslators []ErrorTranslator
 }
 
+// PreParseHook receives the SQL of a query before it's parsed and returns the SQL to parse instead, such as the query
+// with its table names mapped to those of the tenant of the session. Returning an error fails the query.
+type PreParseHook func(ctx *sql.Context, query string) (string, error)
+
+// PostParseHook receives the plan of a query right after it's parsed, before it's analyzed, and returns the plan to
+// analyze instead, such as the plan with a soft-delete filter on some tables. The query is the SQL that was parsed,
+// after any rewrite by the pre-parse hooks. Returning an error fails the query.
+type PostParseHook func(ctx *sql.Context, query string, parsed sql.Node) (sql.Node, error)
+
+// ErrorTranslator receives the error of a query before it's sent to the client and returns the error to send instead,
+// such as a *mysql.SQLError with the MySQL code of an error of the storage backend, or an error without the paths and
+// internal identifiers its message has. Returning nil keeps the error as it is. The context is nil for errors that
+// happen before the query has one.
+type ErrorTranslator func(ctx *sql.Context, err error) error
+
 type ColumnWithRawDefault struct {
 	SqlColumn *sql.Column
 	Default   string
@@ -91,6 +148,7 @@ func New(c *sql.Catalog, a *analyzer.Analyzer, cfg *Config) *Engine {
 
 	c.MustRegister(function.Defaults...)
 	c.MustRegister(function.GetLockingFuncs(ls)...)
+	c.MustRegisterTableFunction(tablefunction.Defaults...)
 
 	// use auth.None if auth is not specified
 	var au auth.Auth
@@ -100,7 +158,52 @@ func New(c *sql.Catalog, a *analyzer.Analyzer, cfg *Config) *Engine {
 		au = cfg.Auth
 	}
 
-	return &Engine{c, a, au, ls}
+	version, _ := function.Version(versionPostfix).Eval(nil, nil)
+	e := &Engine{
+		Catalog:    c,
+		Analyzer:   a,
+		Auth:       au,
+		LS:         ls,
+		version:    version.(string),
+		admission:  sql.NewAdmissionController(),
+		WriteQueue: sql.NewWriteQueue(c.WaitsForGraph),
+	}
+	c.SetUnmaskAuthorizer(func(ctx *sql.Context) bool {
+		return e.Auth.Allowed(ctx, auth.UnmaskPerm) == nil
+	})
+	c.SetReadOnlyAuthorizer(func(ctx *sql.Context) bool {
+		return e.Auth.Allowed(ctx, auth.SuperPerm) == nil
+	})
+	c.SetAllColumnsAuthorizer(func(ctx *sql.Context) bool {
+		return e.Auth.Allowed(ctx, auth.SuperPerm) == nil
+	})
+	if accounts, ok := au.(sql.AccountLister); ok {
+		c.SetAccountLister(accounts)
+	}
+	if cfg != nil && cfg.CaseSensitiveNames {
+		c.SetCaseSensitiveNames(true)
+	}
+	if cfg != nil && cfg.DeterministicOrder {
+		a.DeterministicOrder = true
+	}
+	if cfg != nil && cfg.PartialStarExpansion {
+		a.PartialStarExpansion = true
+	}
+	if cfg != nil && cfg.CostModel != nil {
+		a.CostModel = cfg.CostModel
+	}
+	if cfg != nil && cfg.StatisticsRefreshThreshold > 0 {
+		e.StatisticsRefresher = sql.NewStatisticsRefresher(cfg.StatisticsRefreshThreshold)
+	}
+	if cfg != nil && cfg.ResultCacheSize > 0 {
+		e.ResultCache = sql.NewResultCache(cfg.ResultCacheSize, cfg.ResultCacheTTL)
+		for _, db := range c.AllDatabases() {
+			e.ResultCache.DatabaseAdded(db)
+		}
+		c.AddCatalogChangeListener(e.ResultCache)
+	}
+
+	return e
 }
 
 // NewDefault creates a new default Engine.
@@ -115,6 +218,16 @@ func NewDefault() *Engine {
 func (e *Engine) Query(
 	ctx *sql.Context,
 	query string,
+) (sql.Schema, sql.RowIter, error) {
+	return e.QueryWithBindings(ctx, query, nil)
+}
+
+// QueryWithBindings executes a query with the expressions given bound to its bind variables, keyed by their names.
+// Queries prepared by the session of the context with PrepareQuery aren't parsed again.
+func (e *Engine) QueryWithBindings(
+	ctx *sql.Context,
+	query string,
+	bindings map[string]sql.Expression,
 ) (sql.Schema, sql.RowIter, error) {
 	var (
 		parsed, analyzed sql.Node
@@ -125,22 +238,48 @@ func (e *Engine) Query(
 	finish := observeQuery(ctx, query)
 	defer finish(err)
 
-	parsed, err = parse.Parse(ctx, query)
+	if err = e.setSessionVariables(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	query, parsed, err = e.parsePrepared(ctx, query)
 	if err != nil {
 		return nil, nil, err
 	}
+	if bindings != nil {
+		parsed, err = plan.ApplyBindings(parsed, bindings)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	parsed = e.usePlanBaseline(query, parsed)
+
+	if err = e.checkPasswordExpired(ctx, parsed); err != nil {
+		return nil, nil, err
+	}
 
 	var perm = auth.ReadPerm
 	var typ = sql.QueryProcess
-	switch parsed.(type) {
+	switch node := parsed.(type) {
 	case *plan.CreateIndex:
 		typ = sql.CreateIndexProcess
 		perm = auth.ReadPerm | auth.WritePerm
-	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateView,
-		*plan.DeleteFrom, *plan.DropIndex, *plan.DropView,
+	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint,
+		*plan.AlterIndex, *plan.CreateView,
+		*plan.DeleteFrom, *plan.Truncate, *plan.DropIndex, *plan.DropView,
 		*plan.InsertInto, *plan.LockTables, *plan.UnlockTables,
-		*plan.Update:
+		*plan.Update, *plan.CreateResourceGroup, *plan.AlterResourceGroup, *plan.DropResourceGroup,
+		*plan.PinPlanBaseline, *plan.PurgePlanBaselines:
 		perm = auth.ReadPerm | auth.WritePerm
+	case *plan.DumpDatabase:
+		// Dumps read the tables directly, without their row policies and column masks
+		perm = auth.ReadPerm | auth.UnmaskPerm
+	case *plan.AlterUser:
+		// Users change their own passwords without any permission, but altering anything else needs the super one
+		perm = auth.SuperPerm
+		if node.ChangesOwnPassword(ctx.Client().User) {
+			perm = 0
+		}
 	}
 
 	err = e.Auth.Allowed(ctx, perm)
@@ -148,6 +287,16 @@ func (e *Engine) Query(
 		return nil, nil, err
 	}
 
+	release, err := e.admit(ctx, query, parsed)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			release()
+		}
+	}()
+
 	ctx, err = e.Catalog.AddProcess(ctx, typ, query)
 	defer func() {
 		if err != nil && ctx != nil {
@@ -164,14 +313,187 @@ func (e *Engine) Query(
 		return nil, nil, err
 	}
 
+	if err = e.lockTables(ctx, analyzed); err != nil {
+		return nil, nil, e.lockFailed(ctx, err)
+	}
+	defer func() {
+		if err != nil {
+			e.endTableLocks(ctx)
+		}
+	}()
+
+	if err = e.queueWrites(ctx, analyzed); err != nil {
+		return nil, nil, e.lockFailed(ctx, err)
+	}
+	defer func() {
+		if err != nil {
+			e.endWrites(ctx)
+		}
+	}()
+
+	endQuery := func() {
+		e.endWrites(ctx)
+		e.endTableLocks(ctx)
+		release()
+	}
+
+	// Cached results are only served once the query has been admitted and has taken its locks, like any other query
+	cacheKey, cacheable := e.resultCacheKey(ctx, query, parsed)
+	var cacheVersion uint64
+	if cacheable {
+		if schema, rows, ok := e.ResultCache.Get(cacheKey); ok {
+			iter = &onCloseRowIter{RowIter: sql.RowsToRowIter(rows...), onClose: func() {
+				if qp, ok := analyzed.(*plan.QueryProcess); ok {
+					qp.Notify()
+				}
+				endQuery()
+			}}
+			return schema, newLastQueryInfoRowIter(ctx, analyzed, true, iter), nil
+		}
+		cacheVersion = e.ResultCache.Version()
+	}
+
+	e.trackCommits(ctx, analyzed)
+	defer func() {
+		if err != nil {
+			e.abortCommits(ctx)
+			e.abortTransactions(ctx)
+		}
+	}()
+
+	if err = e.startTransactions(ctx, analyzed); err != nil {
+		return nil, nil, err
+	}
+
 	iter, err = analyzed.RowIter(ctx, nil)
 	if err != nil {
 		return nil, nil, err
 	}
+	// Queries stop between any two of their rows once they're killed or time out, or their client disconnects, even
+	// if their nodes don't check their context
+	iter = sql.NewCancelableRowIter(ctx, iter)
+
+	if cacheable {
+		iter = sql.NewCachingRowIter(e.ResultCache, cacheKey, cacheVersion, queriedTables(analyzed), analyzed.Schema(), iter)
+	} else if invalidate := e.resultCacheInvalidation(parsed, analyzed); invalidate != nil {
+		iter = &onCloseRowIter{RowIter: iter, onClose: invalidate}
+	}
+	if changes := schemaChanges(ctx, analyzed); changes != nil {
+		iter = &onCloseRowIter{RowIter: iter, onClose: e.bumpSchemaVersions(changes)}
+	}
+	iter = e.trackRowChanges(ctx, analyzed, iter)
+	iter = e.endCommits(ctx, parsed, iter)
+	iter = e.endTransactions(ctx, parsed, iter)
+	iter = &onCloseRowIter{RowIter: iter, onClose: endQuery}
+	iter = newLastQueryInfoRowIter(ctx, analyzed, returnsRows(analyzed), iter)
 
 	return analyzed.Schema(), iter, nil
 }
 
+// checkPasswordExpired returns sql.ErrMustChangePassword if the password of the user of the session has expired, unless
+// the statement given changes it, or sets variables.
+func (e *Engine) checkPasswordExpired(ctx *sql.Context, parsed sql.Node) error {
+	user := ctx.Client().User
+	if account, ok := e.Catalog.Account(user); !ok || !account.PasswordExpired {
+		return nil
+	}
+
+	switch n := parsed.(type) {
+	case *plan.Set:
+		return nil
+	case *plan.AlterUser:
+		if n.ChangesOwnPassword(user) {
+			return nil
+		}
+	}
+	return sql.ErrMustChangePassword.New()
+}
+
+// setSessionVariables sets the variables of the context given that depend on the engine: its global variables to the
+// ones of the catalog, which sessions start with, the lower_case_table_names variable to the case sensitivity of the
+// names of the catalog, and the version variable to the version of the engine.
+func (e *Engine) setSessionVariables(ctx *sql.Context) error {
+	ctx.GlobalVariables = e.Catalog.GlobalVariables
+	if ctx.Session == nil {
+		return nil
+	}
+	if s, ok := ctx.Session.(sql.GlobalVariablesSession); ok {
+		s.InitGlobalVariables(e.Catalog.GlobalVariables)
+	}
+
+	value := e.Catalog.LowerCaseTableNames()
+	if _, v := ctx.Get(sql.LowerCaseTableNamesSessionVar); v != value {
+		if err := ctx.Set(ctx, sql.LowerCaseTableNamesSessionVar, sql.Int32, value); err != nil {
+			return err
+		}
+	}
+
+	if _, v := ctx.Get("version"); v != e.version {
+		return ctx.Set(ctx, "version", sql.LongText, e.version)
+	}
+	return nil
+}
+
+// AddPreParseHook adds a hook that can rewrite the SQL of every query before it's parsed. Hooks run in the order they
+// were added, each receiving the result of the previous one. Hooks must be added before the engine runs queries.
+func (e *Engine) AddPreParseHook(hook PreParseHook) {
+	e.preParseHooks = append(e.preParseHooks, hook)
+}
+
+// AddPostParseHook adds a hook that can rewrite the plan of every query after it's parsed and before it's analyzed.
+// Hooks run in the order they were added, each receiving the result of the previous one. Hooks must be added before
+// the engine runs queries.
+func (e *Engine) AddPostParseHook(hook PostParseHook) {
+	e.postParseHooks = append(e.postParseHooks, hook)
+}
+
+// AddErrorTranslator adds a translator for the errors of queries sent to clients. Translators run in the order they
+// were added, each receiving the result of the previous one. Translators must be added before the engine runs queries.
+func (e *Engine) AddErrorTranslator(translator ErrorTranslator) {
+	e.errorTranslators = append(e.errorTranslators, translator)
+}
+
+// TranslateError returns the error to send to the client for an error of a query, as translated by the error
+// translators of the engine.
+func (e *Engine) TranslateError(ctx *sql.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	for _, translate := range e.errorTranslators {
+		if translated := translate(ctx, err); translated != nil {
+			err = translated
+		}
+	}
+	return err
+}
+
+// parse parses the query given, applying the pre-parse and post-parse hooks, and returns the query parsed along with
+// its plan.
+func (e *Engine) parse(ctx *sql.Context, query string) (string, sql.Node, error) {
+	var err error
+	for _, hook := range e.preParseHooks {
+		query, err = hook(ctx, query)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	parsed, err := parse.Parse(ctx, query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, hook := range e.postParseHooks {
+		parsed, err = hook(ctx, query, parsed)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return query, parsed, nil
+}
+
 // ParseDefaults takes in a schema, along with each column's default value in a string form, and returns the schema
 // with the default values parsed and resolved.
 func ResolveDefaults(tableName string, schema []*ColumnWithRawDefault) (sql.Schema, error) {
@@ -258,7 +580,7 @@ func ApplyDefaults(ctx *sql.Context, tblSch sql.Schema, cols []int, row sql.Row)
 // Async returns true if the query is async. If there are any errors with the
 // query it returns false
 func (e *Engine) Async(ctx *sql.Context, query string) bool {
-	parsed, err := parse.Parse(ctx, query)
+	_, parsed, err := e.parse(ctx, query)
 	if err != nil {
 		return false
 	}
@@ -267,7 +589,14 @@ func (e *Engine) Async(ctx *sql.Context, query string) bool {
 	return ok && asyncNode.IsAsync()
 }
 
-// AddDatabase adds the given database to the catalog.
-func (e *Engine) AddDatabase(db sql.Database) {
-	e.Catalog.AddDatabase(db)
+// AddDatabase adds the given database to the catalog. It returns sql.ErrImmutableDatabaseProvider if the provider of
+// the catalog can't add databases.
+func (e *Engine) AddDatabase(db sql.Database) error {
+	return e.Catalog.AddDatabase(db)
+}
+
+// RemoveDatabase removes the database with the name given from the catalog. It's safe to call while queries are
+// running.
+func (e *Engine) RemoveDatabase(name string) error {
+	return e.Catalog.RemoveDatabase(name)
 }
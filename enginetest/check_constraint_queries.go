@@ -0,0 +1,219 @@
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var CheckConstraintTests = []ScriptTest{
+	{
+		Name: "insert and update rows of a table with check constraints",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int check (a > 0), b int, constraint b_lt_a check (b < a))",
+			"insert into t values (1, 5, 1), (2, 3, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into t values (3, 0, null)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:       "insert into t values (3, 2, 2)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "insert into t values (3, null, 7)",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 1}}},
+			},
+			{
+				Query:       "update t set a = -1 where id = 1",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "update t set b = 2 where id = 2",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:       "replace into t values (1, 1, 1)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "replace into t values (1, 10, 9)",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:       "insert into t values (1, 1, 1) on duplicate key update b = 100",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "select * from t order by id",
+				Expected: []sql.Row{{1, 10, 9}, {2, 3, 2}, {3, nil, 7}},
+			},
+		},
+	},
+	{
+		Name: "check constraints with functions",
+		SetUpScript: []string{
+			"create table t (id int primary key, s varchar(20), check (length(s) > 2 and upper(s) = s))",
+			"insert into t values (1, 'ABC')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into t values (2, 'abc')",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:       "insert into t select id + 1, 'AB' from t",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, "ABC"}},
+			},
+		},
+	},
+	{
+		Name: "check constraints not enforced",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int, constraint a_pos check (a > 0) not enforced)",
+			"insert into t values (1, -1)",
+		},
+		Query:    "select * from t",
+		Expected: []sql.Row{{1, -1}},
+	},
+	{
+		Name: "show create table",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int check (a > 0), constraint c check (a < id) not enforced)",
+		},
+		Query: "show create table t",
+		Expected: []sql.Row{{"t", "CREATE TABLE `t` (\n" +
+			"  `id` int NOT NULL,\n" +
+			"  `a` int,\n" +
+			"  PRIMARY KEY (`id`),\n" +
+			"  CONSTRAINT `t_chk_1` CHECK (a > 0),\n" +
+			"  CONSTRAINT `c` CHECK (a < id) NOT ENFORCED\n" +
+			") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"}},
+	},
+	{
+		Name: "information_schema",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int check (a > 0), constraint c check (a < id) not enforced)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select * from information_schema.check_constraints where constraint_schema = 'mydb' order by constraint_name",
+				Expected: []sql.Row{
+					{"def", "mydb", "c", "a < id"},
+					{"def", "mydb", "t_chk_1", "a > 0"},
+				},
+			},
+			{
+				Query: "select constraint_name, table_name, constraint_type, enforced from information_schema.table_constraints where table_schema = 'mydb' order by constraint_name",
+				Expected: []sql.Row{
+					{"c", "t", "CHECK", "NO"},
+					{"t_chk_1", "t", "CHECK", "YES"},
+				},
+			},
+		},
+	},
+	{
+		Name: "check constraints in triggers",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int check (a > 0))",
+			"create table log (id int primary key)",
+			"create trigger log_inserted after insert on log for each row insert into t values (new.id, -new.id)",
+		},
+		Query:       "insert into log values (1)",
+		ExpectedErr: sql.ErrCheckConstraintViolated,
+	},
+	{
+		Name:        "check constraint referencing an unknown column",
+		Query:       "create table t (id int primary key, check (b > 0))",
+		ExpectedErr: sql.ErrCheckConstraintUnknownColumn,
+	},
+	{
+		Name:        "column check constraint referencing another column",
+		Query:       "create table t (id int primary key, a int check (id > 0))",
+		ExpectedErr: sql.ErrCheckConstraintOtherColumn,
+	},
+	{
+		Name:        "check constraints with the same name",
+		Query:       "create table t (id int primary key, constraint c check (id > 0), constraint c check (id < 10))",
+		ExpectedErr: sql.ErrCheckConstraintDuplicateName,
+	},
+	{
+		Name:        "check constraint with a subquery",
+		Query:       "create table t (id int primary key, check (id > (select 1)))",
+		ExpectedErr: sql.ErrCheckConstraintSubquery,
+	},
+	{
+		Name: "add and drop check constraints with alter table",
+		SetUpScript: []string{
+			"create table t (id int primary key, a int, b int, check (a > 0))",
+			"insert into t values (1, 5, 1), (2, 3, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "alter table t add constraint b_lt_a check (b > a)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "alter table t add constraint b_lt_a check (b < a)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "insert into t values (3, 2, 2)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:    "alter table t add check (a < 100)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "alter table t add check (b > 100) not enforced",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "alter table t add constraint b_lt_a check (b < 10)",
+				ExpectedErr: sql.ErrCheckConstraintDuplicateName,
+			},
+			{
+				Query:       "alter table t add check (c > 0)",
+				ExpectedErr: sql.ErrCheckConstraintUnknownColumn,
+			},
+			{
+				Query:       "alter table t add check ((select 1) > a)",
+				ExpectedErr: sql.ErrCheckConstraintSubquery,
+			},
+			{
+				Query: "select constraint_name, check_clause from information_schema.check_constraints order by constraint_name",
+				Expected: []sql.Row{
+					{"b_lt_a", "b < a"},
+					{"t_chk_1", "a > 0"},
+					{"t_chk_2", "a < 100"},
+					{"t_chk_3", "b > 100"},
+				},
+			},
+			{
+				Query:    "alter table t drop check b_lt_a",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "alter table t drop constraint t_chk_1",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:       "alter table t drop check t_chk_1",
+				ExpectedErr: sql.ErrCheckConstraintNotFound,
+			},
+			{
+				Query:    "insert into t values (3, -2, 2)",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:       "insert into t values (4, 200, 2)",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+		},
+	},
+}
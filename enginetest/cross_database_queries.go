@@ -0,0 +1,146 @@
+// Copyright 2020 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// CrossDatabaseQueries are queries of tables of mydb, qualified with the name of the database, run with foo as the
+// current database.
+var CrossDatabaseQueries = []QueryTest{
+	{
+		Query: "SELECT i, s2 FROM mydb.mytable JOIN mydb.othertable ON i = i2 ORDER BY i",
+		Expected: []sql.Row{
+			{int64(1), "third"},
+			{int64(2), "second"},
+			{int64(3), "first"},
+		},
+	},
+	{
+		Query: "SELECT text, s FROM other_table JOIN mydb.mytable ON number = i",
+		Expected: []sql.Row{
+			{"b", "second row"},
+		},
+	},
+	{
+		Query: "SELECT i FROM mydb.mytable WHERE i IN (SELECT number FROM other_table) ORDER BY i",
+		Expected: []sql.Row{
+			{int64(2)},
+		},
+	},
+	{
+		Query: "SELECT i FROM mydb.mytable t WHERE i IN (SELECT o.i2 FROM mydb.othertable o WHERE o.i2 = t.i AND o.s2 = 'second')",
+		Expected: []sql.Row{
+			{int64(2)},
+		},
+	},
+	{
+		Query: "SELECT t.text, (SELECT s FROM mydb.mytable WHERE i = t.number) FROM other_table t ORDER BY 1",
+		Expected: []sql.Row{
+			{"a", nil},
+			{"b", "second row"},
+			{"c", nil},
+		},
+	},
+	{
+		Query: "SELECT mytable.i, other_table.text FROM mydb.mytable, foo.other_table WHERE mytable.i = other_table.number",
+		Expected: []sql.Row{
+			{int64(2), "b"},
+		},
+	},
+}
+
+// CrossDatabasePlanTests are the plans of queries of tables of mydb, run with foo as the current database. Indexes
+// of the tables must be used as if mydb were the current database.
+var CrossDatabasePlanTests = []QueryPlanTest{
+	{
+		Query: "SELECT i, i2, s2 FROM mydb.mytable INNER JOIN mydb.othertable ON i = i2",
+		ExpectedPlan: "IndexedJoin(mytable.i = othertable.i2)\n" +
+			" ├─ Projected table access on [i]\n" +
+			" │   └─ Table(mytable)\n" +
+			" └─ Projected table access on [i2 s2]\n" +
+			"     └─ Table(othertable)\n" +
+			"",
+	},
+}
+
+// CrossDatabaseWriteQueries are statements that modify tables of mydb, qualified with the name of the database, run
+// with foo as the current database.
+var CrossDatabaseWriteQueries = []WriteQueryTest{
+	{
+		WriteQuery:          "INSERT INTO mydb.mytable (i, s) SELECT number + 10, text FROM other_table",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(3)}},
+		SelectQuery:         "SELECT i, s FROM mydb.mytable WHERE i >= 10 ORDER BY i",
+		ExpectedSelect: []sql.Row{
+			{int64(10), "c"},
+			{int64(12), "b"},
+			{int64(14), "a"},
+		},
+	},
+	{
+		WriteQuery:          "UPDATE mydb.mytable SET s = 'updated' WHERE i IN (SELECT number FROM other_table)",
+		ExpectedWriteResult: []sql.Row{{newUpdateResult(1, 1)}},
+		SelectQuery:         "SELECT i, s FROM mydb.mytable ORDER BY i",
+		ExpectedSelect: []sql.Row{
+			{int64(1), "first row"},
+			{int64(2), "updated"},
+			{int64(3), "third row"},
+		},
+	},
+	{
+		WriteQuery:          "DELETE FROM mydb.othertable WHERE i2 IN (SELECT number FROM other_table)",
+		ExpectedWriteResult: []sql.Row{{sql.NewOkResult(1)}},
+		SelectQuery:         "SELECT s2, i2 FROM mydb.othertable ORDER BY i2",
+		ExpectedSelect: []sql.Row{
+			{"third", int64(1)},
+			{"first", int64(3)},
+		},
+	},
+	{
+		WriteQuery:          "CREATE TABLE mydb.newtable (i BIGINT PRIMARY KEY, s TEXT)",
+		ExpectedWriteResult: nil,
+		SelectQuery:         "SELECT table_schema, table_name FROM information_schema.tables WHERE table_name = 'newtable'",
+		ExpectedSelect: []sql.Row{
+			{"mydb", "newtable"},
+		},
+	},
+	{
+		WriteQuery:          "DROP TABLE mydb.othertable",
+		ExpectedWriteResult: nil,
+		SelectQuery:         "SELECT table_name FROM information_schema.tables WHERE table_schema = 'mydb' AND table_name = 'othertable'",
+		ExpectedSelect:      nil,
+	},
+	{
+		WriteQuery:          "ALTER TABLE mydb.mytable ADD COLUMN n INT",
+		ExpectedWriteResult: nil,
+		SelectQuery:         "SELECT column_name FROM information_schema.columns WHERE table_schema = 'mydb' AND table_name = 'mytable' ORDER BY 1",
+		ExpectedSelect: []sql.Row{
+			{"i"},
+			{"n"},
+			{"s"},
+		},
+	},
+	{
+		WriteQuery:          "RENAME TABLE mydb.othertable TO mydb.othertable2",
+		ExpectedWriteResult: nil,
+		SelectQuery:         "SELECT i2 FROM mydb.othertable2 ORDER BY i2",
+		ExpectedSelect: []sql.Row{
+			{int64(1)},
+			{int64(2)},
+			{int64(3)},
+		},
+	},
+}
@@ -15,9 +15,17 @@
 package enginetest_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pmezard/go-difflib/difflib"
@@ -26,12 +34,16 @@ import (
 	"gopkg.in/src-d/go-errors.v1"
 
 	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/arrow"
+	"github.com/dolthub/go-mysql-server/auth"
 	"github.com/dolthub/go-mysql-server/enginetest"
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/analyzer"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 	"github.com/dolthub/go-mysql-server/sql/expression/function"
+	"github.com/dolthub/go-mysql-server/sql/information_schema"
+	"github.com/dolthub/go-mysql-server/sql/mysql_db"
 	"github.com/dolthub/go-mysql-server/sql/parse"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
@@ -72,7 +84,8 @@ func TestClearWarnings(t *testing.T) {
 }
 
 // TODO: this should be expanded and filled in (test of describe for lots of queries), and moved to enginetests, but
-//  first we need to standardize the explain output. Depends too much on integrators right now.
+//
+//	first we need to standardize the explain output. Depends too much on integrators right now.
 func TestDescribe(t *testing.T) {
 	queries := []string{
 		`DESCRIBE FORMAT=TREE SELECT * FROM mytable`,
@@ -86,18 +99,43 @@ func TestDescribe(t *testing.T) {
 	t.Run("sequential", func(t *testing.T) {
 		for _, q := range queries {
 			enginetest.TestQuery(t, harness, e, q, []sql.Row{
-				sql.NewRow("Table(mytable)"),
+				sql.NewRow("Projected table access on [i s]"),
+				sql.NewRow(" └─ Table(mytable)"),
 			})
 		}
 	})
 
+	// The rows modified by data modification statements are read whole, so their columns aren't pruned
+	t.Run("data modification", func(t *testing.T) {
+		enginetest.TestQuery(t, harness, e, "EXPLAIN DELETE FROM mytable WHERE i = 1", []sql.Row{
+			{"Delete"},
+			{" └─ Filter(mytable.i = 1)"},
+			{"     └─ Table(mytable)"},
+		})
+	})
+
+	// The cost format shows the estimated cost and number of rows of every node
+	t.Run("costs", func(t *testing.T) {
+		enginetest.TestQuery(t, harness, e, "EXPLAIN FORMAT=COST SELECT * FROM mytable a JOIN othertable b ON a.s = b.s2 WHERE a.i > 1", []sql.Row{
+			{"InnerJoin(a.s = b.s2) (cost=8.00 rows=1.00)"},
+			{" ├─ Filter(a.i > 1) (cost=4.00 rows=1.00)"},
+			{" │   └─ Projected table access on [i s] (cost=4.00 rows=3.00)"},
+			{" │       └─ TableAlias(a) (cost=4.00 rows=3.00)"},
+			{" │           └─ Table(mytable) (cost=4.00 rows=3.00)"},
+			{" └─ Projected table access on [s2 i2] (cost=4.00 rows=3.00)"},
+			{"     └─ TableAlias(b) (cost=4.00 rows=3.00)"},
+			{"         └─ Table(othertable) (cost=4.00 rows=3.00)"},
+		})
+	})
+
 	parallelHarness := newMemoryHarness("parallel", 2, testNumPartitions, false, nil)
 	ep := enginetest.NewEngine(t, parallelHarness)
 	t.Run("parallel", func(t *testing.T) {
 		for _, q := range queries {
 			enginetest.TestQuery(t, parallelHarness, ep, q, []sql.Row{
-				{"Exchange(parallelism=2)"},
-				{" └─ Table(mytable)"},
+				{"Projected table access on [i s]"},
+				{" └─ Exchange(parallelism=2)"},
+				{"     └─ Table(mytable)"},
 			})
 		}
 	})
@@ -112,9 +150,10 @@ func TestTracing(t *testing.T) {
 }
 
 // TODO: it's not currently possible to test this via harness, because the underlying table implementations are added to
-//  the database, rather than the wrapper tables. We need a better way of inspecting lock state to test this properly.
-//  Also, currently locks are entirely implementation dependent, so there isn't much to test except that lock and unlock
-//  are being called.
+//
+//	the database, rather than the wrapper tables. We need a better way of inspecting lock state to test this properly.
+//	Also, currently locks are entirely implementation dependent, so there isn't much to test except that lock and unlock
+//	are being called.
 func TestLocks(t *testing.T) {
 	require := require.New(t)
 
@@ -151,157 +190,2776 @@ func TestLocks(t *testing.T) {
 	require.Equal(1, t2.unlocks)
 }
 
-type mockSpan struct {
-	opentracing.Span
-	finished bool
-}
+func TestResultCache(t *testing.T) {
+	require := require.New(t)
 
-func (m *mockSpan) Finish() {
-	m.finished = true
-}
+	table := memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}})
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(1))))
+	catalog := sql.NewCatalog()
+	db := memory.NewDatabase("db")
+	db.AddTable("t", table)
+	catalog.AddDatabase(db)
 
-func TestRootSpanFinish(t *testing.T) {
-	harness := newDefaultMemoryHarness()
-	e := enginetest.NewEngine(t, harness)
-	fakeSpan := &mockSpan{Span: opentracing.NoopTracer{}.StartSpan("")}
-	ctx := sql.NewContext(
-		context.Background(),
-		sql.WithRootSpan(fakeSpan))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{ResultCacheSize: 10})
+	ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
 
-	_, iter, err := e.Query(ctx, "SELECT 1")
-	require.NoError(t, err)
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
 
-	_, err = sql.RowIterToRows(iter)
-	require.NoError(t, err)
+	// Only queries marked SQL_CACHE are cached by default
+	query("SELECT i FROM t")
+	require.Equal(0, engine.ResultCache.Len())
+	require.Equal([]sql.Row{{int64(1)}}, query("SELECT SQL_CACHE i FROM t"))
+	require.Equal(1, engine.ResultCache.Len())
+	require.Equal([]sql.Row{{int64(1)}}, query("SELECT SQL_CACHE i FROM t"))
 
-	require.True(t, fakeSpan.finished)
+	// Writes through the engine invalidate the results that read from the table
+	query("INSERT INTO t VALUES (2)")
+	require.Equal(0, engine.ResultCache.Len())
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, query("SELECT SQL_CACHE i FROM t"))
+
+	// Writes outside the engine go unnoticed unless the database notifies them
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3))))
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, query("SELECT SQL_CACHE i FROM t"))
+	engine.ResultCache.TableChanged("db", "t")
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}, {int64(3)}}, query("SELECT SQL_CACHE i FROM t"))
+
+	// Cached rows are copies, which callers can change without changing the cache
+	rows := query("SELECT SQL_CACHE i FROM t WHERE i < 3")
+	rows[0][0] = int64(100)
+	rows = query("SELECT SQL_CACHE i FROM t WHERE i < 3")
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, rows)
+	rows[0][0] = int64(100)
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, query("SELECT SQL_CACHE i FROM t WHERE i < 3"))
+
+	// Truncating a table invalidates the results that read from it too
+	query("TRUNCATE TABLE t")
+	require.Equal(0, engine.ResultCache.Len())
+	require.Equal([]sql.Row(nil), query("SELECT SQL_CACHE i FROM t"))
+	query("INSERT INTO t VALUES (1), (2), (3)")
+
+	// Non-deterministic queries are never cached
+	query("SET query_cache_type = 'ON'")
+	engine.ResultCache.Clear()
+	query("SELECT i, RAND() FROM t")
+	query("SELECT SQL_NO_CACHE i FROM t")
+	require.Equal(0, engine.ResultCache.Len())
+	query("SELECT i FROM t WHERE i > 1")
+	require.Equal(1, engine.ResultCache.Len())
+
+	query("SET query_cache_type = 'OFF'")
+	engine.ResultCache.Clear()
+	query("SELECT SQL_CACHE i FROM t")
+	require.Equal(0, engine.ResultCache.Len())
 }
 
-type lockableTable struct {
-	sql.Table
-	readLocks  int
-	writeLocks int
-	unlocks    int
+func TestResultCacheLocks(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{ResultCacheSize: 10})
+	var pid uint64
+	newContext := func(sess sql.Session) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	alice := sql.NewSession("localhost", "localhost", "alice", 1)
+	bob := sql.NewSession("localhost", "localhost", "bob", 2)
+	query := func(sess sql.Session, q string) ([]sql.Row, error) {
+		_, iter, err := engine.Query(newContext(sess), q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+	mustQuery := func(sess sql.Session, q string) []sql.Row {
+		rows, err := query(sess, q)
+		require.NoError(err, q)
+		return rows
+	}
+
+	mustQuery(alice, "CREATE TABLE t (i BIGINT PRIMARY KEY)")
+	mustQuery(alice, "CREATE TABLE u (i BIGINT PRIMARY KEY)")
+	mustQuery(alice, "INSERT INTO t VALUES (1)")
+	mustQuery(alice, "SET lock_wait_timeout = 0")
+	require.Equal([]sql.Row{{int64(1)}}, mustQuery(alice, "SELECT SQL_CACHE i FROM t"))
+	require.Equal(1, engine.ResultCache.Len())
+
+	// Cached results wait for the locks of the tables they read, like the queries that computed them
+	mustQuery(bob, "LOCK TABLES t WRITE")
+	_, err := query(alice, "SELECT SQL_CACHE i FROM t")
+	require.True(sql.ErrLockWaitTimeout.Is(err), "%v", err)
+	mustQuery(bob, "UNLOCK TABLES")
+	require.Equal([]sql.Row{{int64(1)}}, mustQuery(alice, "SELECT SQL_CACHE i FROM t"))
+
+	// And they're only served to sessions that locked them under LOCK TABLES
+	mustQuery(alice, "LOCK TABLES u READ")
+	_, err = query(alice, "SELECT SQL_CACHE i FROM t")
+	require.True(sql.ErrTableNotLocked.Is(err), "%v", err)
+	mustQuery(alice, "UNLOCK TABLES")
+
+	// Serving them leaves no process or lock behind
+	require.Equal([]sql.Row{{int64(1)}}, mustQuery(alice, "SELECT SQL_CACHE i FROM t"))
+	require.Len(engine.Catalog.Processes(), 0)
+	mustQuery(bob, "SET lock_wait_timeout = 0")
+	mustQuery(bob, "DROP TABLE t")
 }
 
-func newLockableTable(t sql.Table) *lockableTable {
-	return &lockableTable{Table: t}
+func TestFoundRowsAndRowCount(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{ResultCacheSize: 10})
+	ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+	lastQueryInfo := func() sql.Row {
+		return query("SELECT FOUND_ROWS(), ROW_COUNT()")[0]
+	}
+
+	require.Equal(sql.Row{int64(1), int64(0)}, lastQueryInfo())
+
+	query("CREATE TABLE t (i BIGINT PRIMARY KEY)")
+	require.Equal(int64(0), lastQueryInfo()[1])
+	query("INSERT INTO t VALUES (1), (2), (3), (4), (5)")
+	require.Equal(int64(5), lastQueryInfo()[1])
+	query("UPDATE t SET i = i + 10 WHERE i > 3")
+	require.Equal(int64(2), lastQueryInfo()[1])
+	query("DELETE FROM t WHERE i > 10")
+	require.Equal(int64(2), lastQueryInfo()[1])
+	query("INSERT INTO t VALUES (4), (5)")
+
+	// Without SQL_CALC_FOUND_ROWS, the found rows are the rows returned
+	require.Len(query("SELECT * FROM t WHERE i > 1 LIMIT 2"), 2)
+	require.Equal(sql.Row{int64(2), int64(-1)}, lastQueryInfo())
+	require.Equal(sql.Row{int64(1), int64(-1)}, lastQueryInfo())
+
+	// With it, they're the rows that would have been returned without the limit and the offset
+	require.Equal([]sql.Row{{int64(3)}, {int64(4)}}, query("SELECT SQL_CALC_FOUND_ROWS * FROM t WHERE i > 1 ORDER BY i LIMIT 2 OFFSET 1"))
+	require.Equal(sql.Row{int64(4), int64(-1)}, lastQueryInfo())
+	require.Len(query("SELECT DISTINCT SQL_CALC_FOUND_ROWS i FROM t LIMIT 10, 1"), 0)
+	require.Equal(int64(5), lastQueryInfo()[0])
+	require.Len(query("select sql_calc_found_rows * from t"), 5)
+	require.Equal(int64(5), lastQueryInfo()[0])
+
+	// Found rows aren't taken from the result cache
+	query("SELECT SQL_CACHE SQL_CALC_FOUND_ROWS * FROM t LIMIT 1")
+	query("SELECT SQL_CACHE SQL_CALC_FOUND_ROWS * FROM t LIMIT 1")
+	require.Equal(int64(5), lastQueryInfo()[0])
+	require.Equal(0, engine.ResultCache.Len())
 }
 
-var _ sql.Lockable = (*lockableTable)(nil)
+func TestLastInsertId(t *testing.T) {
+	require := require.New(t)
 
-func (l *lockableTable) Lock(ctx *sql.Context, write bool) error {
-	if write {
-		l.writeLocks++
-	} else {
-		l.readLocks++
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+	ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
 	}
-	return nil
+	lastInsertId := func() interface{} {
+		return query("SELECT LAST_INSERT_ID()")[0][0]
+	}
+
+	query("CREATE TABLE t (id BIGINT PRIMARY KEY AUTO_INCREMENT, s TEXT)")
+	require.Equal(uint64(0), lastInsertId())
+
+	// The first value generated by the last insert is both returned in its OK result and kept by the session
+	rows := query("INSERT INTO t (s) VALUES ('a'), ('b'), ('c')")
+	require.Equal(uint64(1), rows[0][0].(sql.OkResult).InsertID)
+	require.Equal(uint64(1), lastInsertId())
+	rows = query("INSERT INTO t VALUES (NULL, 'd')")
+	require.Equal(uint64(4), rows[0][0].(sql.OkResult).InsertID)
+	require.Equal(uint64(4), lastInsertId())
+
+	// Inserts that don't generate values don't change it
+	rows = query("INSERT INTO t VALUES (10, 'e')")
+	require.Equal(uint64(0), rows[0][0].(sql.OkResult).InsertID)
+	require.Equal(uint64(4), lastInsertId())
+	query("UPDATE t SET s = 'f' WHERE id = 10")
+	require.Equal(uint64(4), lastInsertId())
+
+	// With an argument, LAST_INSERT_ID sets the value returned from then on
+	require.Equal([]sql.Row{{uint64(100)}}, query("SELECT LAST_INSERT_ID(100)"))
+	require.Equal(uint64(100), lastInsertId())
+	query("INSERT INTO t (s) VALUES ('g')")
+	require.Equal(uint64(11), lastInsertId())
+
+	// Other sessions have their own
+	other := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+	_, iter, err := engine.Query(other, "SELECT LAST_INSERT_ID()")
+	require.NoError(err)
+	rows, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{uint64(0)}}, rows)
 }
 
-func (l *lockableTable) Unlock(ctx *sql.Context, id uint32) error {
-	l.unlocks++
-	return nil
+func TestHandler(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+	ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+	queryErr := func(q string, kind *errors.Kind) {
+		_, iter, err := engine.Query(ctx, q)
+		if err == nil {
+			_, err = sql.RowIterToRows(iter)
+		}
+		require.Error(err, q)
+		require.True(kind.Is(err), "%s: %s", q, err)
+	}
+
+	query("CREATE TABLE t (i BIGINT PRIMARY KEY, s VARCHAR(10), KEY idx_s (s))")
+	query("INSERT INTO t VALUES (1, 'c'), (2, 'a'), (3, 'b'), (4, 'a'), (5, NULL)")
+	query("HANDLER t OPEN AS h")
+	queryErr("HANDLER t OPEN h", sql.ErrDuplicateAliasOrTable)
+
+	// Reads of an index continue where the last one stopped, in the order of the index
+	require.Equal([]sql.Row{{int64(5), nil}}, query("HANDLER h READ idx_s FIRST"))
+	require.Equal([]sql.Row{{int64(2), "a"}, {int64(4), "a"}}, query("HANDLER h READ idx_s NEXT LIMIT 2"))
+	require.Equal([]sql.Row{{int64(3), "b"}, {int64(1), "c"}}, query("HANDLER h READ idx_s NEXT LIMIT 5"))
+	require.Empty(query("HANDLER h READ idx_s NEXT"))
+	require.Equal([]sql.Row{{int64(3), "b"}}, query("HANDLER h READ idx_s PREV"))
+	require.Equal([]sql.Row{{int64(1), "c"}, {int64(3), "b"}}, query("HANDLER h READ idx_s LAST LIMIT 2"))
+
+	// Comparisons of keys start a read from the first row that matches them, and < and <= read backwards
+	require.Equal([]sql.Row{{int64(3), "b"}, {int64(1), "c"}}, query("HANDLER h READ idx_s > ('a') LIMIT 3"))
+	require.Equal([]sql.Row{{int64(2), "a"}, {int64(4), "a"}}, query("HANDLER h READ idx_s = ('a') LIMIT 2"))
+	require.Equal([]sql.Row{{int64(3), "b"}}, query("HANDLER h READ idx_s NEXT"))
+	require.Equal([]sql.Row{{int64(4), "a"}, {int64(2), "a"}}, query("HANDLER h READ idx_s <= ('a') LIMIT 2"))
+	require.Equal([]sql.Row{{int64(2), "a"}, {int64(5), nil}}, query("HANDLER h READ idx_s < ('b') LIMIT 1, 2"))
+	require.Equal([]sql.Row{{int64(4), "a"}}, query("HANDLER h READ `PRIMARY` >= (4)"))
+
+	// Rows skipped by WHERE move the position of the handler too
+	require.Equal([]sql.Row{{int64(4), "a"}}, query("HANDLER h READ idx_s FIRST WHERE s = 'a' AND i > 3"))
+	require.Equal([]sql.Row{{int64(1), "c"}}, query("HANDLER h READ idx_s NEXT WHERE s <> 'b'"))
+
+	// Without an index, rows are read in their natural order
+	require.Equal([]sql.Row{{int64(1), "c"}, {int64(2), "a"}}, query("HANDLER h READ FIRST LIMIT 2"))
+	require.Equal([]sql.Row{{int64(3), "b"}}, query("HANDLER h READ NEXT"))
+
+	queryErr("HANDLER h READ idx_x FIRST", plan.ErrHandlerIndexNotFound)
+	queryErr("HANDLER h READ idx_s = ('a', 1)", plan.ErrHandlerTooManyKeyParts)
+	queryErr("HANDLER t READ FIRST", plan.ErrUnknownHandler)
+
+	query("HANDLER h CLOSE")
+	queryErr("HANDLER h READ FIRST", plan.ErrUnknownHandler)
+	queryErr("HANDLER h CLOSE", plan.ErrUnknownHandler)
 }
 
-type analyzerTestCase struct {
-	name          string
-	query         string
-	planGenerator func(*testing.T, *sqle.Engine) sql.Node
-	err           *errors.Kind
+func TestSignalAndGetDiagnostics(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+	ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+
+	query := func(q string) ([]sql.Row, error) {
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+	mustQuery := func(q string) []sql.Row {
+		rows, err := query(q)
+		require.NoError(err, q)
+		return rows
+	}
+
+	// Exception conditions are returned as errors and added to the conditions of the session
+	_, err := query("SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'no way', MYSQL_ERRNO = 1001, TABLE_NAME = 't'")
+	require.Error(err)
+	ce, ok := err.(*sql.ConditionError)
+	require.True(ok, "%T", err)
+	require.Equal(sql.Warning{Level: "Error", Message: "no way", Code: 1001, SQLState: "45000", Info: map[string]string{"TABLE_NAME": "t"}}, *ce.Warning)
+
+	mustQuery("GET DIAGNOSTICS @n = NUMBER")
+	mustQuery("GET DIAGNOSTICS CONDITION 1 @state = RETURNED_SQLSTATE, @msg = MESSAGE_TEXT, @errno = MYSQL_ERRNO, @tbl = TABLE_NAME, @col = COLUMN_NAME")
+	require.Equal([]sql.Row{{int64(1), "45000", "no way", int64(1001), "t", ""}}, mustQuery("SELECT @n, @state, @msg, @errno, @tbl, @col"))
+
+	// RESIGNAL passes on the last condition with the changes given
+	_, err = query("RESIGNAL SET MYSQL_ERRNO = 1002")
+	require.Error(err)
+	require.Equal(sql.Warning{Level: "Error", Message: "no way", Code: 1002, SQLState: "45000", Info: map[string]string{"TABLE_NAME": "t"}}, *err.(*sql.ConditionError).Warning)
+
+	// Warnings are just added to the conditions of the session, with the default messages and codes of signals
+	mustQuery("SET @state = '01234'")
+	mustQuery("SIGNAL SQLSTATE VALUE '01000'")
+	require.Equal([]sql.Row{{"Warning", 1642, "Unhandled user-defined warning condition"}}, mustQuery("SHOW WARNINGS"))
+	mustQuery("SIGNAL SQLSTATE '01001' SET MESSAGE_TEXT = @state")
+	mustQuery("GET DIAGNOSTICS @n = NUMBER")
+	mustQuery("SET @i = 2")
+	mustQuery("GET DIAGNOSTICS CONDITION @i @msg = MESSAGE_TEXT")
+	require.Equal([]sql.Row{{int64(2), "Unhandled user-defined warning condition"}}, mustQuery("SELECT @n, @msg"))
+
+	mustQuery("SELECT 1")
+	mustQuery("SELECT 1")
+	mustQuery("GET DIAGNOSTICS @n = NUMBER, @rows = ROW_COUNT")
+	require.Equal([]sql.Row{{int64(0), int64(-1)}}, mustQuery("SELECT @n, @rows"))
+
+	for q, kind := range map[string]*errors.Kind{
+		"GET DIAGNOSTICS CONDITION 1 @msg = MESSAGE_TEXT": plan.ErrInvalidConditionNumber,
+		"RESIGNAL":                plan.ErrResignalWithoutCondition,
+		"SIGNAL SQLSTATE '00000'": plan.ErrSignalBadSQLState,
+		"SIGNAL SQLSTATE '4500'":  plan.ErrSignalBadSQLState,
+		"SIGNAL SQLSTATE '45000' SET MYSQL_ERRNO = 0":   plan.ErrSignalInvalidValue,
+		"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = @x": plan.ErrSignalInvalidValue,
+	} {
+		_, err := query(q)
+		require.Error(err, q)
+		require.True(kind.Is(err), "%s: %s", q, err)
+	}
 }
 
-// Grab bag tests for testing analysis of various nodes that are difficult to verify through other means
-func TestAnalyzer(t *testing.T) {
-	testCases := []analyzerTestCase{
-		{
-			name:  "show tables as of",
-			query: "SHOW TABLES AS OF 'abc123'",
-			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
-				db, err := engine.Catalog.Database("mydb")
-				require.NoError(t, err)
-				return plan.NewShowTables(db, false, expression.NewLiteral("abc123", sql.LongText))
-			},
-		},
-		{
-			name:  "show tables as of, from",
-			query: "SHOW TABLES FROM foo AS OF 'abc123'",
-			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
-				db, err := engine.Catalog.Database("foo")
-				require.NoError(t, err)
-				return plan.NewShowTables(db, false, expression.NewLiteral("abc123", sql.LongText))
-			},
-		},
-		{
-			name:  "show tables as of, function call",
-			query: "SHOW TABLES FROM foo AS OF GREATEST('abc123', 'cde456')",
-			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
-				db, err := engine.Catalog.Database("foo")
-				require.NoError(t, err)
-				greatest, err := function.NewGreatest(
-					expression.NewLiteral("abc123", sql.LongText),
-					expression.NewLiteral("cde456", sql.LongText),
-				)
-				require.NoError(t, err)
-				return plan.NewShowTables(db, false, greatest)
-			},
-		},
-		{
-			name:  "show tables as of, timestamp",
-			query: "SHOW TABLES FROM foo AS OF TIMESTAMP('20200101:120000Z')",
-			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
-				db, err := engine.Catalog.Database("foo")
-				require.NoError(t, err)
-				timestamp, err := function.NewTimestamp(
-					expression.NewLiteral("20200101:120000Z", sql.LongText),
-				)
-				require.NoError(t, err)
-				return plan.NewShowTables(db, false, timestamp)
-			},
-		},
+func TestQueryHooks(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	for name, values := range map[string][]int64{"t_a": {1, 2, 3}, "t_b": {4}} {
+		table := memory.NewTable(name, sql.Schema{{Name: "i", Type: sql.Int64, Source: name}})
+		for _, v := range values {
+			require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(v)))
+		}
+		db.AddTable(name, table)
 	}
 
-	for _, tt := range testCases {
-		t.Run(tt.name, func(t *testing.T) {
-			harness := newDefaultMemoryHarness()
-			e := enginetest.NewEngine(t, harness)
+	engine := sqle.NewDefault()
+	engine.AddDatabase(db)
 
-			ctx := enginetest.NewContext(harness)
-			parsed, err := parse.Parse(ctx, tt.query)
-			require.NoError(t, err)
+	// Map table t to the table of the tenant of the session
+	engine.AddPreParseHook(func(ctx *sql.Context, query string) (string, error) {
+		_, tenant := ctx.Get("tenant")
+		if tenant == nil {
+			return "", fmt.Errorf("no tenant")
+		}
+		return strings.Replace(query, "FROM t", "FROM t_"+tenant.(string), -1), nil
+	})
 
-			analyzed, err := e.Analyzer.Analyze(ctx, parsed, nil)
-			if tt.err != nil {
-				require.Error(t, err)
-				assert.True(t, tt.err.Is(err))
-			} else {
-				assertNodesEqualWithDiff(t, tt.planGenerator(t, e), analyzed)
+	// Hide rows with i = 2, as if they were soft-deleted
+	var queries []string
+	engine.AddPostParseHook(func(ctx *sql.Context, query string, parsed sql.Node) (sql.Node, error) {
+		queries = append(queries, query)
+		return plan.TransformUp(parsed, func(n sql.Node) (sql.Node, error) {
+			if t, ok := n.(*plan.UnresolvedTable); ok {
+				return plan.NewFilter(expression.NewNot(expression.NewEquals(
+					expression.NewUnresolvedQualifiedColumn(t.Name(), "i"),
+					expression.NewLiteral(int64(2), sql.Int64),
+				)), t), nil
 			}
+			return n, nil
 		})
+	})
+
+	query := func(tenant, q string) ([]sql.Row, error) {
+		ctx := enginetest.NewContext(newDefaultMemoryHarness()).WithCurrentDB("db")
+		if tenant != "" {
+			require.NoError(ctx.Set(ctx, "tenant", sql.LongText, tenant))
+		}
+
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
 	}
+
+	rows, err := query("a", "SELECT i FROM t ORDER BY i")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}, {int64(3)}}, rows)
+
+	rows, err = query("b", "SELECT i FROM t ORDER BY i")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(4)}}, rows)
+
+	require.Equal([]string{"SELECT i FROM t_a ORDER BY i", "SELECT i FROM t_b ORDER BY i"}, queries)
+
+	_, err = query("", "SELECT i FROM t")
+	require.EqualError(err, "no tenant")
 }
 
-func assertNodesEqualWithDiff(t *testing.T, expected, actual sql.Node) {
-	if x, ok := actual.(*plan.QueryProcess); ok {
-		actual = x.Child
+func TestRowPolicies(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	table := memory.NewTable("docs", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "docs", PrimaryKey: true},
+		{Name: "owner", Type: sql.LongText, Source: "docs"},
+	})
+	for i, owner := range []string{"alice", "bob", "alice", "carol"} {
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(i+1), owner)))
 	}
+	db.AddTable("docs", table)
 
-	if !assert.Equal(t, expected, actual) {
-		expectedStr := sql.DebugString(expected)
-		actualStr := sql.DebugString(actual)
-		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-			A:        difflib.SplitLines(expectedStr),
-			B:        difflib.SplitLines(actualStr),
-			FromFile: "expected",
-			FromDate: "",
-			ToFile:   "actual",
-			ToDate:   "",
-			Context:  1,
-		})
-		require.NoError(t, err)
+	engine := sqle.NewDefault()
+	engine.AddDatabase(db)
 
-		if len(diff) > 0 {
-			fmt.Println(diff)
+	// Users only see their own documents, except root, who sees all of them
+	engine.Catalog.AddRowPolicy("db", "DOCS", func(ctx *sql.Context, user string) (sql.Expression, error) {
+		if user == "root" {
+			return nil, nil
 		}
+		return expression.NewEquals(
+			expression.NewUnresolvedColumn("owner"),
+			expression.NewLiteral(user, sql.LongText),
+		), nil
+	})
+
+	vr := sql.NewViewRegistry()
+	pid := uint64(0)
+	query := func(user, q string) []sql.Row {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewSession("server", "client", user, 1)),
+			sql.WithViewRegistry(vr),
+		).WithCurrentDB("db")
+
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	require.Equal([]sql.Row{{int64(1)}, {int64(3)}}, query("alice", "SELECT id FROM docs ORDER BY id"))
+	require.Equal([]sql.Row{{int64(2)}}, query("bob", "SELECT d.id FROM docs d WHERE d.id < 3"))
+	require.Equal([]sql.Row{{int64(4)}}, query("root", "SELECT COUNT(*) FROM docs"))
+	require.Equal([]sql.Row{{int64(1)}, {int64(3)}}, query("alice", "SELECT a.id FROM docs a JOIN docs b ON a.id = b.id ORDER BY 1"))
+	require.Equal([]sql.Row{{int64(2)}}, query("bob", "SELECT id FROM (SELECT id FROM docs) s"))
+	require.Equal([]sql.Row{{int64(2)}}, query("bob", "SELECT id FROM docs WHERE id IN (SELECT id FROM docs)"))
+
+	query("root", "CREATE VIEW all_docs AS SELECT id FROM docs")
+	require.Equal([]sql.Row{{int64(1)}, {int64(3)}}, query("alice", "SELECT id FROM all_docs ORDER BY id"))
+
+	// Users can't modify other users' rows
+	query("alice", "UPDATE docs SET owner = 'dave'")
+	query("bob", "DELETE FROM docs")
+	query("carol", "INSERT INTO docs SELECT id + 10, 'carol' FROM docs")
+	require.Equal([]sql.Row{
+		{int64(1), "dave"},
+		{int64(3), "dave"},
+		{int64(4), "carol"},
+		{int64(14), "carol"},
+	}, query("root", "SELECT * FROM docs ORDER BY id"))
+
+	engine.Catalog.ClearRowPolicies("db", "docs")
+	require.Equal([]sql.Row{{int64(4)}}, query("bob", "SELECT COUNT(*) FROM docs"))
+}
+
+func TestColumnMasks(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	table := memory.NewTable("users", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "users", PrimaryKey: true},
+		{Name: "email", Type: sql.LongText, Source: "users"},
+		{Name: "phone", Type: sql.LongText, Source: "users", Nullable: true},
+	})
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(1), "jane@example.org", "555-0123")))
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(2), "joe@example.com", nil)))
+	db.AddTable("users", table)
+
+	usersFile, err := ioutil.TempFile("", "users")
+	require.NoError(err)
+	defer os.Remove(usersFile.Name())
+	_, err = usersFile.WriteString(`[
+		{"name": "admin", "permissions": ["read", "write", "unmask"]},
+		{"name": "support", "permissions": ["read", "write"]}
+	]`)
+	require.NoError(err)
+	require.NoError(usersFile.Close())
+
+	au, err := auth.NewNativeFile(usersFile.Name())
+	require.NoError(err)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: au})
+
+	engine.Catalog.AddColumnMask("db", "users", "email", sql.MaskEmail)
+	engine.Catalog.AddColumnMask("db", "users", "PHONE", sql.MaskPartial(0, "XXX-", 4))
+
+	pid := uint64(0)
+	query := func(user, q string) []sql.Row {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewSession("server", "client", user, 1)),
+		).WithCurrentDB("db")
+
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
 	}
+
+	require.Equal([]sql.Row{
+		{int64(1), "jane@example.org", "555-0123"},
+		{int64(2), "joe@example.com", nil},
+	}, query("admin", "SELECT * FROM users ORDER BY id"))
+
+	require.Equal([]sql.Row{
+		{int64(1), "jXXX@XXXX.org", "XXX-0123"},
+		{int64(2), "jXXX@XXXX.com", nil},
+	}, query("support", "SELECT * FROM users ORDER BY id"))
+
+	// Unmasked values can't be inferred from filters, joins or functions either
+	require.Equal([]sql.Row{{int64(0)}}, query("support", "SELECT COUNT(*) FROM users WHERE email = 'jane@example.org'"))
+	require.Equal([]sql.Row{{"JXXX@XXXX.ORG"}}, query("support", "SELECT UPPER(u.email) FROM users u JOIN users v ON u.email = v.email WHERE u.id = 1"))
+	require.Equal([]sql.Row{{"jXXX@XXXX.org"}}, query("support", "SELECT email FROM (SELECT email FROM users WHERE id = 1) s"))
+
+	// Updates write back unmasked values, but copies of masked values are masked
+	query("support", "UPDATE users SET phone = '555-9876' WHERE id = 2")
+	query("support", "INSERT INTO users SELECT id + 10, email, phone FROM users")
+	require.Equal([]sql.Row{
+		{int64(1), "jane@example.org", "555-0123"},
+		{int64(2), "joe@example.com", "555-9876"},
+		{int64(11), "jXXX@XXXX.org", "XXX-0123"},
+		{int64(12), "jXXX@XXXX.com", "XXX-9876"},
+	}, query("admin", "SELECT * FROM users ORDER BY id"))
+}
+
+func TestColumnPrivileges(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	table := memory.NewTable("employees", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "employees", PrimaryKey: true},
+		{Name: "name", Type: sql.LongText, Source: "employees"},
+		{Name: "salary", Type: sql.Int64, Source: "employees"},
+	})
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(1), "jane", int64(100))))
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(2), "joe", int64(90))))
+	db.AddTable("employees", table)
+
+	usersFile, err := ioutil.TempFile("", "users")
+	require.NoError(err)
+	defer os.Remove(usersFile.Name())
+	_, err = usersFile.WriteString(`[
+		{"name": "admin", "permissions": ["read", "write", "super"]},
+		{"name": "support", "permissions": ["read", "write"]},
+		{"name": "guest", "permissions": ["read"]}
+	]`)
+	require.NoError(err)
+	require.NoError(usersFile.Close())
+
+	au, err := auth.NewNativeFile(usersFile.Name())
+	require.NoError(err)
+
+	newEngine := func(cfg *sqle.Config) *sqle.Engine {
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(db)
+		catalog.GrantColumns("db", "EMPLOYEES", "support", "id", "Name")
+		return sqle.New(catalog, analyzer.NewDefault(catalog), cfg)
+	}
+
+	pid := uint64(0)
+	queryOn := func(engine *sqle.Engine, user, q string) ([]sql.Row, error) {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewSession("server", "client", user, 1)),
+		).WithCurrentDB("db")
+
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	engine := newEngine(&sqle.Config{Auth: au})
+	query := func(user, q string) ([]sql.Row, error) {
+		return queryOn(engine, user, q)
+	}
+
+	rows, err := query("admin", "SELECT * FROM employees ORDER BY id")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), "jane", int64(100)}, {int64(2), "joe", int64(90)}}, rows)
+
+	rows, err = query("support", "SELECT name FROM employees WHERE id = 2")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"joe"}}, rows)
+
+	// Columns that aren't granted can't be read anywhere in the query
+	for _, q := range []string{
+		"SELECT * FROM employees",
+		"SELECT salary FROM employees",
+		"SELECT e.id FROM employees e ORDER BY e.salary",
+		"SELECT id FROM employees WHERE salary > 95",
+		"SELECT id FROM employees WHERE id IN (SELECT id FROM employees WHERE salary > 95)",
+		"SELECT (SELECT e.salary FROM dual) FROM employees e",
+		"SELECT name, s FROM (SELECT name, salary AS s FROM employees) t",
+		"SELECT SUM(salary) FROM employees",
+		"UPDATE employees SET name = 'x' WHERE salary > 95",
+	} {
+		_, err := query("support", q)
+		require.True(sql.ErrColumnAccessDenied.Is(err), "%s: %v", q, err)
+	}
+	_, err = query("support", "SELECT salary FROM employees")
+	require.EqualError(err, "SELECT command denied to user 'support'@'client' for column 'salary' in table 'employees'")
+
+	// Users without grants can't read any column
+	_, err = query("guest", "SELECT id FROM employees")
+	require.True(sql.ErrColumnAccessDenied.Is(err), "%v", err)
+
+	// Columns written aren't read
+	_, err = query("support", "UPDATE employees SET salary = 95 WHERE id = 2")
+	require.NoError(err)
+
+	// With partial star expansion, stars expand to the granted columns
+	partial := newEngine(&sqle.Config{Auth: au, PartialStarExpansion: true})
+	rows, err = queryOn(partial, "support", "SELECT e.* FROM employees e ORDER BY id")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), "jane"}, {int64(2), "joe"}}, rows)
+	rows, err = queryOn(partial, "admin", "SELECT * FROM employees WHERE id = 2")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(2), "joe", int64(95)}}, rows)
+	_, err = queryOn(partial, "guest", "SELECT * FROM employees")
+	require.True(sql.ErrColumnAccessDenied.Is(err), "%v", err)
+
+	// Tables without grants can be read by everyone
+	engine.Catalog.RevokeColumns("db", "employees", "support", "id", "name")
+	rows, err = query("guest", "SELECT salary FROM employees WHERE id = 1")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(100)}}, rows)
+}
+
+func TestShowGrants(t *testing.T) {
+	require := require.New(t)
+
+	usersFile, err := ioutil.TempFile("", "users")
+	require.NoError(err)
+	defer os.Remove(usersFile.Name())
+	_, err = usersFile.WriteString(`[
+		{"name": "admin", "password": "secret", "permissions": ["read", "write", "super"]},
+		{"name": "support"}
+	]`)
+	require.NoError(err)
+	require.NoError(usersFile.Close())
+
+	au, err := auth.NewNativeFile(usersFile.Name())
+	require.NoError(err)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	catalog.AddDatabase(mysql_db.NewMySQLDatabase(catalog))
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	catalog.GrantColumns("db", "employees", "support", "name", "id")
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: au})
+
+	pid := uint64(0)
+	query := func(user, q string) ([]sql.Row, error) {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewSession("server", "client", user, 1)),
+		).WithCurrentDB("db")
+
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	rows, err := query("admin", "SHOW GRANTS")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"GRANT SELECT, INSERT, UPDATE, DELETE, CREATE, DROP, INDEX, ALTER, LOCK TABLES, CREATE VIEW, TRIGGER, SUPER ON *.* TO `admin`@`%`"},
+	}, rows)
+
+	rows, err = query("admin", "SHOW GRANTS FOR 'support'@'%'")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"GRANT SELECT ON *.* TO `support`@`%`"},
+		{"GRANT SELECT (`id`, `name`) ON `db`.`employees` TO `support`@`%`"},
+	}, rows)
+
+	_, err = query("admin", "SHOW GRANTS FOR nobody")
+	require.True(sql.ErrNonExistingGrant.Is(err), "%v", err)
+
+	rows, err = query("admin", "SELECT User, Host, Select_priv, Insert_priv, Super_priv, plugin, authentication_string FROM mysql.user ORDER BY User")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"admin", "%", "Y", "Y", "Y", "mysql_native_password", auth.NativePassword("secret")},
+		{"support", "%", "Y", "N", "N", "mysql_native_password", nil},
+	}, rows)
+
+	rows, err = query("admin", "SELECT * FROM mysql.db")
+	require.NoError(err)
+	require.Empty(rows)
+
+	rows, err = query("admin", "SELECT User, Db, Table_name, Column_name, Column_priv FROM mysql.columns_priv")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"support", "db", "employees", "id", "Select"},
+		{"support", "db", "employees", "name", "Select"},
+	}, rows)
+
+	rows, err = query("admin", "SELECT grantee, privilege_type FROM information_schema.user_privileges WHERE privilege_type = 'SELECT'")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"'admin'@'%'", "SELECT"}, {"'support'@'%'", "SELECT"}}, rows)
+
+	// The mysql tables can't be written
+	_, err = query("admin", "DELETE FROM mysql.user")
+	require.Error(err)
+
+	// With no authentication, every user has all the privileges
+	noAuth := sqle.NewDefault()
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("server", "client", "joe", 1)))
+	_, iter, err := noAuth.Query(ctx, "SHOW GRANTS")
+	require.NoError(err)
+	rows, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{"GRANT ALL PRIVILEGES ON *.* TO `joe`@`%`"}}, rows)
+}
+
+func TestAlterUser(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(mysql_db.NewMySQLDatabase(catalog))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{
+		Auth: auth.NewNativeSingle("admin", "secret", auth.AllPermissions),
+	})
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("server", "client", "admin", 1)))
+	query := func(q string) ([]sql.Row, error) {
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	_, err := query("ALTER USER admin PASSWORD EXPIRE ACCOUNT LOCK")
+	require.NoError(err)
+	_, err = query("SELECT 1")
+	require.True(sql.ErrMustChangePassword.Is(err), "%v", err)
+	_, err = query("ALTER USER admin IDENTIFIED BY 'other' ACCOUNT UNLOCK")
+	require.True(sql.ErrMustChangePassword.Is(err), "%v", err)
+	_, err = query("ALTER USER USER() IDENTIFIED BY 'other'")
+	require.NoError(err)
+
+	rows, err := query("SELECT User, authentication_string, password_expired, account_locked FROM mysql.user")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"admin", auth.NativePassword("other"), "N", "Y"}}, rows)
+
+	_, err = query("ALTER USER nobody ACCOUNT LOCK")
+	require.True(sql.ErrUserOperationFailed.Is(err), "%v", err)
+	_, err = query("ALTER USER IF EXISTS nobody ACCOUNT LOCK")
+	require.NoError(err)
+	require.Equal(uint16(1), ctx.WarningCount())
+
+	// With no authentication there are no accounts to alter
+	noAuth := sqle.NewDefault()
+	_, _, err = noAuth.Query(ctx, "ALTER USER admin IDENTIFIED BY 'other'")
+	require.True(sql.ErrUserOperationFailed.Is(err), "%v", err)
+}
+
+func TestSchemaVersions(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	ctx := sql.NewContext(
+		context.Background(),
+		sql.WithIndexRegistry(sql.NewIndexRegistry()),
+		sql.WithViewRegistry(sql.NewViewRegistry()),
+	).WithCurrentDB("db")
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	query("CREATE TABLE t (a INT PRIMARY KEY)")
+	query("CREATE VIEW v AS SELECT a FROM t")
+	version := catalog.TableSchemaVersion("db", "t")
+	require.NotZero(version)
+
+	// Caches of plans take a snapshot of the tables and views their plans read
+	parsed, err := parse.Parse(ctx, "SELECT * FROM v")
+	require.NoError(err)
+	analyzed, err := engine.Analyzer.Analyze(ctx, parsed, nil)
+	require.NoError(err)
+	objects := plan.SchemaObjects(ctx, parsed, analyzed)
+	require.ElementsMatch([]sql.SchemaObject{{Database: "db", Table: "v"}, {Database: "db", Table: "t"}}, objects)
+	snapshot := catalog.SchemaSnapshot(objects...)
+
+	query("CREATE TABLE u (b INT PRIMARY KEY)")
+	require.True(catalog.IsCurrent(snapshot))
+	require.Equal(version, catalog.TableSchemaVersion("db", "t"))
+
+	query("ALTER TABLE t ADD COLUMN c INT")
+	require.False(catalog.IsCurrent(snapshot))
+	require.Greater(catalog.TableSchemaVersion("db", "t"), version)
+
+	snapshot = catalog.SchemaSnapshot(objects...)
+	query("DROP VIEW v")
+	require.False(catalog.IsCurrent(snapshot))
+
+	rows := query("SELECT table_name, schema_version FROM information_schema.schema_versions WHERE table_schema = 'db' ORDER BY table_name")
+	require.Equal([]sql.Row{
+		{nil, catalog.SchemaVersion("db")},
+		{"t", catalog.TableSchemaVersion("db", "t")},
+		{"u", catalog.TableSchemaVersion("db", "u")},
+		{"v", catalog.TableSchemaVersion("db", "v")},
+	}, rows)
+}
+
+func TestDynamicDatabases(t *testing.T) {
+	require := require.New(t)
+
+	newDatabase := func(name string, i int64) *memory.Database {
+		db := memory.NewDatabase(name)
+		table := memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}})
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(i)))
+		db.AddTable("t", table)
+		return db
+	}
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(newDatabase("db", 1))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{ResultCacheSize: 10})
+
+	query := func(q string) ([]sql.Row, error) {
+		ctx := enginetest.NewContext(newDefaultMemoryHarness())
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	rows, err := query("SELECT SQL_CACHE i FROM db.t")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, rows)
+
+	// Results of removed databases aren't served from the cache
+	require.NoError(engine.RemoveDatabase("db"))
+	_, err = query("SELECT SQL_CACHE i FROM db.t")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+
+	engine.AddDatabase(newDatabase("db", 2))
+	rows, err = query("SELECT SQL_CACHE i FROM db.t")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(2)}}, rows)
+
+	// Databases can be added and removed while other databases are queried
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				rows, err := query("SELECT i FROM db.t")
+				assert.NoError(t, err)
+				assert.Equal(t, []sql.Row{{int64(2)}}, rows)
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("other%d", i%3)
+		engine.AddDatabase(newDatabase(name, int64(i)))
+		require.NoError(engine.RemoveDatabase(name))
+	}
+	wg.Wait()
+
+	require.Len(engine.Catalog.AllDatabases(), 1)
+}
+
+func TestLazyDatabaseProvider(t *testing.T) {
+	require := require.New(t)
+
+	provider := &tenantDatabaseProvider{tenants: 10000, resolved: make(map[string]int)}
+	catalog := sql.NewCatalogWithProvider(provider)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), new(sqle.Config))
+
+	ctx := enginetest.NewContext(newDefaultMemoryHarness())
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	query("USE tenant_9999")
+	require.Equal([]sql.Row{{int64(9999)}}, query("SELECT i FROM t"))
+	require.Equal([]sql.Row{{int64(42)}}, query("SELECT i FROM tenant_42.t"))
+	require.Equal([]sql.Row{{int64(9999)}}, query("SELECT i FROM t"))
+
+	// Databases are only resolved the first time a session uses them, and the rest of the tenants are never loaded
+	require.Equal(map[string]int{"tenant_9999": 1, "tenant_42": 1}, provider.resolved)
+	require.Equal(0, provider.listed)
+
+	_, _, err := engine.Query(ctx, "SELECT i FROM tenant_10000.t")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+}
+
+func TestCaseSensitiveNames(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("Shop")
+	table := memory.NewTable("Orders", sql.Schema{
+		{Name: "ID", Type: sql.Int64, Source: "Orders", PrimaryKey: true},
+		{Name: "total", Type: sql.Int64, Source: "Orders"},
+	})
+	require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(1), int64(10))))
+	db.AddTable("Orders", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{CaseSensitiveNames: true})
+	require.True(engine.Catalog.CaseSensitiveNames())
+
+	vr := sql.NewViewRegistry()
+	pid := uint64(0)
+	query := func(q string) ([]sql.Row, error) {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewBaseSession()),
+			sql.WithViewRegistry(vr),
+		).WithCurrentDB("Shop")
+
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	rows, err := query("SELECT Orders.ID, total FROM Shop.Orders")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), int64(10)}}, rows)
+
+	_, err = query("SELECT ID FROM orders")
+	require.True(sql.ErrTableNotFound.Is(err))
+	_, err = query("SELECT ID FROM shop.Orders")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+	_, err = query("SELECT id FROM Orders")
+	require.True(sql.ErrTableColumnNotFound.Is(err))
+	_, err = query("SHOW COLUMNS FROM ORDERS")
+	require.True(sql.ErrTableNotFound.Is(err))
+	_, err = query("USE shop")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+
+	_, err = query("CREATE VIEW BigOrders AS SELECT ID FROM Orders WHERE total > 5")
+	require.NoError(err)
+	rows, err = query("SELECT ID FROM BigOrders")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, rows)
+	// The parser lowercases the names of views, so they are always case insensitive
+	rows, err = query("SELECT ID FROM bigorders")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, rows)
+
+	// information_schema is case insensitive, as in MySQL
+	rows, err = query("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = 'Shop'")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"Orders"}}, rows)
+
+	rows, err = query("SELECT @@lower_case_table_names")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int32(0)}}, rows)
+}
+
+// tenantDatabaseProvider is a sql.DatabaseProvider whose databases, named tenant_N, are created when they are resolved.
+type tenantDatabaseProvider struct {
+	mu       sync.Mutex
+	tenants  int
+	resolved map[string]int
+	listed   int
+}
+
+func (p *tenantDatabaseProvider) tenant(name string) (int, bool) {
+	name = strings.ToLower(name)
+	if !strings.HasPrefix(name, "tenant_") {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "tenant_"))
+	return n, err == nil && n >= 0 && n < p.tenants
+}
+
+func (p *tenantDatabaseProvider) Database(name string) (sql.Database, error) {
+	n, ok := p.tenant(name)
+	if !ok {
+		return nil, sql.ErrDatabaseNotFound.New(name)
+	}
+
+	p.mu.Lock()
+	p.resolved[strings.ToLower(name)]++
+	p.mu.Unlock()
+
+	db := memory.NewDatabase(strings.ToLower(name))
+	table := memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}})
+	if err := table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(n))); err != nil {
+		return nil, err
+	}
+	db.AddTable("t", table)
+	return db, nil
+}
+
+func (p *tenantDatabaseProvider) HasDatabase(name string) bool {
+	_, ok := p.tenant(name)
+	return ok
+}
+
+func (p *tenantDatabaseProvider) AllDatabases() []sql.Database {
+	p.mu.Lock()
+	p.listed++
+	p.mu.Unlock()
+
+	var dbs []sql.Database
+	for i := 0; i < p.tenants; i++ {
+		db, _ := p.Database(fmt.Sprintf("tenant_%d", i))
+		dbs = append(dbs, db)
+	}
+	return dbs
+}
+
+type mockSpan struct {
+	opentracing.Span
+	finished bool
+}
+
+func (m *mockSpan) Finish() {
+	m.finished = true
+}
+
+func TestRootSpanFinish(t *testing.T) {
+	harness := newDefaultMemoryHarness()
+	e := enginetest.NewEngine(t, harness)
+	fakeSpan := &mockSpan{Span: opentracing.NoopTracer{}.StartSpan("")}
+	ctx := sql.NewContext(
+		context.Background(),
+		sql.WithRootSpan(fakeSpan))
+
+	_, iter, err := e.Query(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(t, err)
+
+	require.True(t, fakeSpan.finished)
+}
+
+type lockableTable struct {
+	sql.Table
+	readLocks  int
+	writeLocks int
+	unlocks    int
+}
+
+func newLockableTable(t sql.Table) *lockableTable {
+	return &lockableTable{Table: t}
+}
+
+var _ sql.Lockable = (*lockableTable)(nil)
+
+func (l *lockableTable) Lock(ctx *sql.Context, write bool) error {
+	if write {
+		l.writeLocks++
+	} else {
+		l.readLocks++
+	}
+	return nil
+}
+
+func (l *lockableTable) Unlock(ctx *sql.Context, id uint32) error {
+	l.unlocks++
+	return nil
+}
+
+type analyzerTestCase struct {
+	name          string
+	query         string
+	planGenerator func(*testing.T, *sqle.Engine) sql.Node
+	err           *errors.Kind
+}
+
+// Grab bag tests for testing analysis of various nodes that are difficult to verify through other means
+func TestAnalyzer(t *testing.T) {
+	testCases := []analyzerTestCase{
+		{
+			name:  "show tables as of",
+			query: "SHOW TABLES AS OF 'abc123'",
+			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
+				db, err := engine.Catalog.Database("mydb")
+				require.NoError(t, err)
+				return plan.NewShowTables(db, false, expression.NewLiteral("abc123", sql.LongText))
+			},
+		},
+		{
+			name:  "show tables as of, from",
+			query: "SHOW TABLES FROM foo AS OF 'abc123'",
+			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
+				db, err := engine.Catalog.Database("foo")
+				require.NoError(t, err)
+				return plan.NewShowTables(db, false, expression.NewLiteral("abc123", sql.LongText))
+			},
+		},
+		{
+			name:  "show tables as of, function call",
+			query: "SHOW TABLES FROM foo AS OF GREATEST('abc123', 'cde456')",
+			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
+				db, err := engine.Catalog.Database("foo")
+				require.NoError(t, err)
+				greatest, err := function.NewGreatest(
+					expression.NewLiteral("abc123", sql.LongText),
+					expression.NewLiteral("cde456", sql.LongText),
+				)
+				require.NoError(t, err)
+				return plan.NewShowTables(db, false, greatest)
+			},
+		},
+		{
+			name:  "show tables as of, timestamp",
+			query: "SHOW TABLES FROM foo AS OF TIMESTAMP('20200101:120000Z')",
+			planGenerator: func(t *testing.T, engine *sqle.Engine) sql.Node {
+				db, err := engine.Catalog.Database("foo")
+				require.NoError(t, err)
+				timestamp, err := function.NewTimestamp(
+					expression.NewLiteral("20200101:120000Z", sql.LongText),
+				)
+				require.NoError(t, err)
+				return plan.NewShowTables(db, false, timestamp)
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			harness := newDefaultMemoryHarness()
+			e := enginetest.NewEngine(t, harness)
+
+			ctx := enginetest.NewContext(harness)
+			parsed, err := parse.Parse(ctx, tt.query)
+			require.NoError(t, err)
+
+			analyzed, err := e.Analyzer.Analyze(ctx, parsed, nil)
+			if tt.err != nil {
+				require.Error(t, err)
+				assert.True(t, tt.err.Is(err))
+			} else {
+				assertNodesEqualWithDiff(t, tt.planGenerator(t, e), analyzed)
+			}
+		})
+	}
+}
+
+func assertNodesEqualWithDiff(t *testing.T, expected, actual sql.Node) {
+	if x, ok := actual.(*plan.QueryProcess); ok {
+		actual = x.Child
+	}
+
+	if !assert.Equal(t, expected, actual) {
+		expectedStr := sql.DebugString(expected)
+		actualStr := sql.DebugString(actual)
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(expectedStr),
+			B:        difflib.SplitLines(actualStr),
+			FromFile: "expected",
+			FromDate: "",
+			ToFile:   "actual",
+			ToDate:   "",
+			Context:  1,
+		})
+		require.NoError(t, err)
+
+		if len(diff) > 0 {
+			fmt.Println(diff)
+		}
+	}
+}
+
+func TestDeterministicOrder(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := memory.NewDatabase("mydb")
+	table := memory.NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t"},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 4)
+	for i, s := range []interface{}{"b", nil, "a", "b", "a", nil, "b", "a"} {
+		require.NoError(table.Insert(ctx, sql.NewRow(int64(7-i), s)))
+	}
+	db.AddTable("t", table)
+
+	partitions, err := table.Partitions(ctx)
+	require.NoError(err)
+	tableRows, err := sql.RowIterToRows(sql.NewTableRowIter(ctx, table, partitions))
+	require.NoError(err)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := analyzer.NewBuilder(catalog).WithParallelism(4).Build()
+	engine := sqle.New(catalog, a, &sqle.Config{DeterministicOrder: true})
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	// Rows without an ORDER BY are sorted by all their columns
+	require.Equal([]sql.Row{
+		{nil, int64(2)}, {nil, int64(6)},
+		{"a", int64(0)}, {"a", int64(3)}, {"a", int64(5)},
+		{"b", int64(1)}, {"b", int64(4)}, {"b", int64(7)},
+	}, query("SELECT s, i FROM t"))
+	require.Equal([]sql.Row{{nil, int64(2)}, {"a", int64(3)}, {"b", int64(3)}}, query("SELECT s, COUNT(*) FROM t GROUP BY s"))
+
+	// Sorts keep equal rows in the order of the table
+	var expected []sql.Row
+	for _, s := range []interface{}{nil, "a", "b"} {
+		for _, row := range tableRows {
+			if row[1] == s {
+				expected = append(expected, sql.NewRow(row[1], row[0]))
+			}
+		}
+	}
+	require.Equal(expected, query("SELECT s, i FROM t ORDER BY s"))
+}
+
+func TestCostModel(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := memory.NewDatabase("mydb")
+	for _, name := range []string{"t1", "t2"} {
+		table := memory.NewTable(name, sql.Schema{
+			{Name: "i", Type: sql.Int64, Source: name, PrimaryKey: true},
+		})
+		table.EnablePrimaryKeyIndexes()
+		for i := 0; i < 10; i++ {
+			require.NoError(table.Insert(ctx, sql.NewRow(int64(i))))
+		}
+		db.AddTable(name, table)
+	}
+
+	explain := func(cfg *sqle.Config) []sql.Row {
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(db)
+		engine := sqle.New(catalog, analyzer.NewDefault(catalog), cfg)
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, "EXPLAIN FORMAT=COST SELECT * FROM t1 JOIN t2 ON t1.i = t2.i")
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	require.Equal([]sql.Row{
+		{"IndexedJoin(t1.i = t2.i) (cost=36.00 rows=10.00)"},
+		{" ├─ Projected table access on [i] (cost=11.00 rows=10.00)"},
+		{" │   └─ Table(t1) (cost=11.00 rows=10.00)"},
+		{" └─ Projected table access on [i] (cost=11.00 rows=10.00)"},
+		{"     └─ Table(t2) (cost=11.00 rows=10.00)"},
+	}, explain(nil))
+
+	// Lookups that cost more than hashing the rows of a table make joins hash it instead
+	model := analyzer.DefaultCostModel()
+	model.LookupRowCost = 100
+	require.Equal([]sql.Row{
+		{"HashJoin(t1.i = t2.i) (cost=52.00 rows=10.00)"},
+		{" ├─ Projected table access on [i] (cost=11.00 rows=10.00)"},
+		{" │   └─ Table(t1) (cost=11.00 rows=10.00)"},
+		{" └─ Projected table access on [i] (cost=11.00 rows=10.00)"},
+		{"     └─ Table(t2) (cost=11.00 rows=10.00)"},
+	}, explain(&sqle.Config{CostModel: &model}))
+}
+
+func TestPlanBaselines(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := memory.NewDatabase("mydb")
+	for _, name := range []string{"t1", "t2"} {
+		table := memory.NewTable(name, sql.Schema{
+			{Name: "i", Type: sql.Int64, Source: name, PrimaryKey: true},
+		})
+		table.EnablePrimaryKeyIndexes()
+		for i := 0; i < 10; i++ {
+			require.NoError(table.Insert(ctx, sql.NewRow(int64(i))))
+		}
+		db.AddTable(name, table)
+	}
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := analyzer.NewDefault(catalog)
+	engine := sqle.New(catalog, a, nil)
+	ctx = sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	indexedJoin := []sql.Row{
+		{"IndexedJoin(t1.i = t2.i)"},
+		{" ├─ Indexed table access on index [t1.i], with multi-range read"},
+		{" │   └─ Filter(t1.i < 5)"},
+		{" │       └─ Projected table access on [i]"},
+		{" │           └─ Table(t1)"},
+		{" └─ Projected table access on [i]"},
+		{"     └─ Table(t2)"},
+	}
+	hashJoin := []sql.Row{
+		{"HashJoin(t1.i = t2.i)"},
+		{" ├─ Indexed table access on index [t1.i], with multi-range read"},
+		{" │   └─ Filter(t1.i < 5)"},
+		{" │       └─ Projected table access on [i]"},
+		{" │           └─ Table(t1)"},
+		{" └─ Projected table access on [i]"},
+		{"     └─ Table(t2)"},
+	}
+	// With costly lookups, the rows of t1 aren't looked up in the index of its filter either
+	scannedHashJoin := []sql.Row{
+		{"HashJoin(t1.i = t2.i)"},
+		{" ├─ Filter(t1.i < 5)"},
+		{" │   └─ Projected table access on [i]"},
+		{" │       └─ Table(t1)"},
+		{" └─ Projected table access on [i]"},
+		{"     └─ Table(t2)"},
+	}
+	// EXPLAIN shows the hints of the plans pinned above the plans
+	hinted := func(hints string, plan []sql.Row) []sql.Row {
+		rows := []sql.Row{{"QueryHints(" + hints + ")"}}
+		for i, row := range plan {
+			prefix := "    "
+			if i == 0 {
+				prefix = " └─ "
+			}
+			rows = append(rows, sql.Row{prefix + row[0].(string)})
+		}
+		return rows
+	}
+	require.Equal(indexedJoin, query("EXPLAIN SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5"))
+
+	query("PIN PLAN BASELINE FOR SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5")
+	pinned := "JOIN_ORDER(t1, t2) NO_HASH_JOIN(t2) INDEX(t1) INDEX(t2, primary)"
+	digest, text := parse.Digest("SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5")
+	require.Equal([]sql.Row{{
+		digest,
+		text,
+		pinned,
+		"IndexedJoin(t1.i = t2.i)\n" +
+			" ├─ Indexed table access on index [t1.i], with multi-range read\n" +
+			" │   └─ Filter(t1.i < 5)\n" +
+			" │       └─ Projected table access on [i]\n" +
+			" │           └─ Table(t1)\n" +
+			" └─ Projected table access on [i]\n" +
+			"     └─ Table(t2)",
+		int64(0),
+	}}, query("SHOW PLAN BASELINES"))
+
+	// Statements with the digest keep the plan pinned when the analyzer would choose another one
+	model := analyzer.DefaultCostModel()
+	model.LookupRowCost = 100
+	a.CostModel = &model
+	require.Equal(hinted(pinned, indexedJoin), query("explain select * from t1 join t2 on t1.i = t2.i where t1.i <  5;"))
+	require.Equal([]sql.Row{{int64(0), int64(0)}, {int64(1), int64(1)}}, query("SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 2"))
+	require.Equal(int64(2), query("SHOW PLAN BASELINES")[0][4])
+
+	query("PURGE PLAN BASELINE '" + digest + "'")
+	require.Empty(query("SHOW PLAN BASELINES"))
+	require.Equal(scannedHashJoin, query("EXPLAIN SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5"))
+
+	// Plans chosen with hints can be forced on the statements without them
+	a.CostModel = nil
+	query("PIN PLAN BASELINE FOR SELECT /*+ HASH_JOIN(t2) */ * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5")
+	require.Equal(hinted("JOIN_ORDER(t1, t2) HASH_JOIN(t2) INDEX(t1) NO_INDEX(t2)", hashJoin), query("EXPLAIN SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5"))
+	query("PURGE PLAN BASELINE FOR SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 1")
+	require.Equal(indexedJoin, query("EXPLAIN SELECT * FROM t1 JOIN t2 ON t1.i = t2.i WHERE t1.i < 5"))
+
+	query("PIN PLAN BASELINE FOR SELECT * FROM t1 WHERE i = 1")
+	query("PIN PLAN BASELINE FOR SELECT * FROM t2")
+	require.Len(query("SHOW PLAN BASELINES"), 2)
+	query("PURGE PLAN BASELINES")
+	require.Empty(query("SHOW PLAN BASELINES"))
+
+	_, _, err := engine.Query(ctx, "PIN PLAN BASELINE FOR DELETE FROM t1")
+	require.True(sql.ErrPlanBaselineStatement.Is(err))
+}
+
+func TestAdmissionControl(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	// Every query gets its own context, since queries of the same process can't run at the same time
+	var pid uint64
+	newContext := func(user string) *sql.Context {
+		pid++
+		sess := sql.NewSession("localhost", "localhost", user, uint32(pid))
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	root, alice, bob := "root", "alice", "bob"
+
+	start := func(user string, q string) sql.RowIter {
+		_, iter, err := engine.Query(newContext(user), q)
+		require.NoError(err, q)
+		return iter
+	}
+	query := func(user string, q string) []sql.Row {
+		rows, err := sql.RowIterToRows(start(user, q))
+		require.NoError(err, q)
+		return rows
+	}
+	queryErr := func(user string, q string) error {
+		_, _, err := engine.Query(newContext(user), q)
+		require.Error(err, q)
+		return err
+	}
+
+	// The limits are global variables, which SET GLOBAL sets without changing the value of existing sessions
+	ctx := newContext(root)
+	_, iter, err := engine.Query(ctx, "SET GLOBAL max_concurrent_queries = 2")
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	_, iter, err = engine.Query(ctx, "SELECT @@global.max_concurrent_queries, @@max_concurrent_queries")
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(2), int64(0)}}, rows)
+	require.Equal([]sql.Row{{int64(2)}}, query(root, "SELECT @@max_concurrent_queries"))
+
+	// Queries are running until their iterators are closed
+	first := start(alice, "SELECT 1")
+	second := start(bob, "SELECT 2")
+	require.True(sql.ErrTooManyConcurrentQueries.Is(queryErr(root, "SELECT 3")))
+	require.NoError(first.Close())
+	require.Equal([]sql.Row{{int8(3)}}, query(root, "SELECT 3"))
+	require.NoError(second.Close())
+
+	// SET statements are always admitted, so that the limits can be changed
+	first, second = start(alice, "SELECT 1"), start(bob, "SELECT 2")
+	query(root, "SET GLOBAL max_concurrent_queries = 0, GLOBAL max_user_concurrent_queries = 1")
+	require.NoError(first.Close())
+	require.NoError(second.Close())
+
+	first = start(alice, "SELECT 1")
+	require.True(sql.ErrTooManyUserQueries.Is(queryErr(alice, "SELECT 2")))
+	require.Equal([]sql.Row{{int8(2)}}, query(bob, "SELECT 2"))
+	require.NoError(first.Close())
+
+	// Queries that only differ in their literals have the same digest
+	query(root, "SET GLOBAL max_user_concurrent_queries = 0, GLOBAL max_digest_concurrent_queries = 1")
+	first = start(alice, "SELECT 1")
+	require.True(sql.ErrTooManyDigestQueries.Is(queryErr(bob, "SELECT 2")))
+	require.Equal([]sql.Row{{int8(2), int8(3)}}, query(bob, "SELECT 2, 3"))
+
+	// With a queue timeout, queries over the limits wait for other queries to finish
+	query(root, "SET GLOBAL admission_queue_timeout = 5")
+	done := make(chan []sql.Row)
+	ctx = newContext(bob)
+	go func() {
+		_, iter, err := engine.Query(ctx, "SELECT 2")
+		if err != nil {
+			done <- nil
+			return
+		}
+		rows, _ := sql.RowIterToRows(iter)
+		done <- rows
+	}()
+	require.NoError(first.Close())
+	require.Equal([]sql.Row{{int8(2)}}, <-done)
+
+	// Queries stop waiting when their context is done
+	first = start(alice, "SELECT 1")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = engine.Query(newContext(bob).WithContext(cancelled), "SELECT 2")
+	require.Equal(context.Canceled, err)
+	require.NoError(first.Close())
+}
+
+func TestResourceGroups(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	table := memory.NewPartitionedTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}, 4)
+	for i := 0; i < 1000; i++ {
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(i))))
+	}
+	db.AddTable("t", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	catalog.AddDatabase(information_schema.NewInformationSchemaDatabase(catalog))
+	catalog.ResourceGroupRegistry = sql.NewResourceGroupRegistry(2)
+	engine := sqle.New(catalog, analyzer.NewBuilder(catalog).WithParallelism(2).Build(), nil)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("", "", "", 7)), sql.WithPid(1)).WithCurrentDB("db")
+	query := func(q string) []sql.Row {
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+	queryErr := func(q string) error {
+		_, iter, err := engine.Query(ctx, q)
+		if err == nil {
+			_, err = sql.RowIterToRows(iter)
+		}
+		require.Error(err, q)
+		return err
+	}
+
+	query("CREATE RESOURCE GROUP batch TYPE = USER VCPU = 1 THREAD_PRIORITY = 19")
+	require.True(sql.ErrResourceGroupExists.Is(queryErr("CREATE RESOURCE GROUP Batch TYPE = USER")))
+	require.True(sql.ErrInvalidVCPUID.Is(queryErr("CREATE RESOURCE GROUP other TYPE = USER VCPU = 2")))
+	require.Equal([]sql.Row{
+		{"SYS_default", "SYSTEM", int8(1), "0-1", int32(0)},
+		{"USR_default", "USER", int8(1), "0-1", int32(0)},
+		{"batch", "USER", int8(1), "1", int32(19)},
+	}, query("SELECT * FROM information_schema.resource_groups ORDER BY resource_group_name"))
+
+	// The partitions of the queries of the session are read in the slots of its group
+	query("SET RESOURCE GROUP batch")
+	require.Equal("batch", catalog.SessionResourceGroup(7).Name)
+	require.Equal([]sql.Row{{int64(1000), float64(499500)}}, query("SELECT COUNT(*), SUM(i) FROM t"))
+
+	query("ALTER RESOURCE GROUP batch THREAD_PRIORITY = 10 DISABLE")
+	require.Equal(sql.DefaultUserResourceGroup, catalog.SessionResourceGroup(7).Name)
+	require.True(sql.ErrResourceGroupDisabled.Is(queryErr("SET RESOURCE GROUP batch FOR 8")))
+	query("ALTER RESOURCE GROUP batch ENABLE")
+	require.Len(query("SELECT i FROM t WHERE i < 100"), 100)
+
+	require.True(sql.ErrResourceGroupBusy.Is(queryErr("DROP RESOURCE GROUP batch")))
+	query("DROP RESOURCE GROUP batch FORCE")
+	require.Equal(sql.DefaultUserResourceGroup, catalog.SessionResourceGroup(7).Name)
+	require.True(sql.ErrResourceGroupNotExists.Is(queryErr("SET RESOURCE GROUP batch")))
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	db.AddTable("t", memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}))
+
+	usersFile, err := ioutil.TempFile("", "users")
+	require.NoError(err)
+	defer os.Remove(usersFile.Name())
+	_, err = usersFile.WriteString(`[
+		{"name": "admin", "permissions": ["read", "write", "super"]},
+		{"name": "replicator", "permissions": ["read", "write"]}
+	]`)
+	require.NoError(err)
+	require.NoError(usersFile.Close())
+
+	au, err := auth.NewNativeFile(usersFile.Name())
+	require.NoError(err)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: au})
+
+	pid := uint64(0)
+	query := func(user, q string) ([]sql.Row, error) {
+		pid++
+		ctx := sql.NewContext(
+			context.Background(),
+			sql.WithPid(pid),
+			sql.WithSession(sql.NewSession("server", "client", user, 1)),
+		).WithCurrentDB("db")
+
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+	run := func(user, q string) error {
+		_, err := query(user, q)
+		return err
+	}
+
+	require.NoError(run("admin", "SET GLOBAL read_only = 1"))
+
+	writes := []string{
+		"INSERT INTO t VALUES (1)",
+		"UPDATE t SET i = 2",
+		"DELETE FROM t",
+		"CREATE TABLE t2 (i BIGINT)",
+		"ALTER TABLE t ADD COLUMN j BIGINT",
+		"CREATE VIEW v AS SELECT * FROM t",
+		"DROP TABLE t",
+	}
+	for _, q := range writes {
+		require.True(sql.ErrReadOnly.Is(run("replicator", q)), q)
+	}
+	require.NoError(run("replicator", "SELECT * FROM t"))
+	require.NoError(run("replicator", "EXPLAIN INSERT INTO t VALUES (1)"))
+
+	// Privileged users can still modify databases in read_only mode, but not in super_read_only mode
+	require.NoError(run("admin", "INSERT INTO t VALUES (1)"))
+	require.NoError(run("admin", "SET GLOBAL super_read_only = 1"))
+	err = run("admin", "INSERT INTO t VALUES (1)")
+	require.True(sql.ErrReadOnly.Is(err))
+	require.Contains(err.Error(), "--super-read-only")
+	require.NoError(run("admin", "SELECT * FROM t"))
+
+	// Sessions read the global values of the read-only variables, which they can't set for themselves only
+	rows, err := query("admin", "SELECT @@super_read_only, @@read_only, @@global.read_only")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int8(1), int8(1), int8(1)}}, rows)
+	rows, err = query("admin", "SHOW VARIABLES LIKE 'super_read_only'")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"super_read_only", int8(1)}}, rows)
+	require.True(sql.ErrGlobalVariable.Is(run("admin", "SET read_only = 0")))
+	require.True(sql.ErrGlobalVariable.Is(run("admin", "SET SESSION super_read_only = 0")))
+
+	// Disabling read_only disables super_read_only, and enabling super_read_only enables read_only
+	require.NoError(run("admin", "SET GLOBAL read_only = 0"))
+	rows, err = query("admin", "SELECT @@global.super_read_only, @@global.read_only")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int8(0), int8(0)}}, rows)
+	require.NoError(run("admin", "SET GLOBAL super_read_only = 1"))
+	rows, err = query("admin", "SELECT @@global.super_read_only, @@global.read_only")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int8(1), int8(1)}}, rows)
+
+	require.NoError(run("admin", "SET GLOBAL super_read_only = 0, GLOBAL read_only = 0"))
+	for _, q := range writes {
+		require.NoError(run("replicator", q), q)
+	}
+}
+
+func TestGlobalVariablesPerEngine(t *testing.T) {
+	require := require.New(t)
+
+	newEngine := func() *sqle.Engine {
+		db := memory.NewDatabase("db")
+		db.AddTable("t", memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}))
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(db)
+		return sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+	}
+	first, second := newEngine(), newEngine()
+
+	var pid uint64
+	query := func(e *sqle.Engine, q string) ([]sql.Row, error) {
+		pid++
+		sess := sql.NewSession("localhost", "localhost", "root", uint32(pid))
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+		_, iter, err := e.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		return sql.RowIterToRows(iter)
+	}
+
+	_, err := query(first, "SET GLOBAL wait_timeout = 60, GLOBAL super_read_only = 1")
+	require.NoError(err)
+
+	// The global variables of an engine are the ones its new sessions start with, and don't change other engines
+	rows, err := query(first, "SELECT @@global.wait_timeout, @@wait_timeout")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(60), int64(60)}}, rows)
+	rows, err = query(second, "SELECT @@global.wait_timeout, @@wait_timeout")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(28800), int64(28800)}}, rows)
+
+	_, err = query(first, "INSERT INTO t VALUES (1)")
+	require.True(sql.ErrReadOnly.Is(err))
+	_, err = query(second, "INSERT INTO t VALUES (1)")
+	require.NoError(err)
+}
+
+func TestAsOfRevisions(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("db")
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	var pid uint64
+	query := func(q string) []sql.Row {
+		pid++
+		ctx := sql.NewContext(context.Background(), sql.WithPid(pid)).WithCurrentDB("db")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	query("CREATE TABLE t (i BIGINT PRIMARY KEY)")
+	query("INSERT INTO t VALUES (1)")
+	db.SaveRevision("v1")
+	query("INSERT INTO t VALUES (2)")
+	query("CREATE TABLE u (i BIGINT PRIMARY KEY)")
+	query("INSERT INTO u VALUES (2)")
+
+	require.Equal([]sql.Row{{int64(1)}}, query("SELECT * FROM t AS OF 'v1'"))
+	require.Equal([]sql.Row{{int64(1)}}, query("SELECT * FROM t AS OF NOW() ORDER BY i"))
+	require.Equal([]sql.Row{{int64(1), int64(2)}}, query("SELECT old.i, t.i FROM t AS OF 'v1' old JOIN t ON t.i > old.i"))
+	require.Equal([]sql.Row{{"t"}}, query("SHOW TABLES AS OF 'v1'"))
+
+	db.SaveRevision("v2")
+	require.Equal([]sql.Row{{int64(2)}}, query("SELECT u.i FROM u AS OF 'v2' JOIN t AS OF 'v1' ON u.i > t.i"))
+
+	pid++
+	ctx := sql.NewContext(context.Background(), sql.WithPid(pid)).WithCurrentDB("db")
+	_, _, err := engine.Query(ctx, "SELECT * FROM u AS OF 'v1'")
+	require.True(sql.ErrTableNotFound.Is(err))
+	_, _, err = engine.Query(ctx, "UPDATE t AS OF 'v1' SET i = 3")
+	require.True(sql.ErrIncompatibleAsOf.Is(err))
+}
+
+func TestDump(t *testing.T) {
+	require := require.New(t)
+
+	// Views are kept in the view registries of sessions, so each engine has one for all of its queries
+	views := make(map[*sqle.Engine]*sql.ViewRegistry)
+	newEngine := func() *sqle.Engine {
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(memory.NewDatabase("db"))
+		e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+		views[e] = sql.NewViewRegistry()
+		return e
+	}
+	var pid uint64
+	newContext := func(e *sqle.Engine) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithPid(pid), sql.WithViewRegistry(views[e])).WithCurrentDB("db")
+	}
+	query := func(e *sqle.Engine, q string) []sql.Row {
+		_, iter, err := e.Query(newContext(e), q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	engine := newEngine()
+	query(engine, "CREATE TABLE t (i BIGINT PRIMARY KEY, s VARCHAR(20), b BLOB, d DATETIME, INDEX idx_s (s))")
+	query(engine, `INSERT INTO t VALUES (1, 'it''s\na "test"', 0x00ff, '2020-01-02 03:04:05'), (2, NULL, NULL, NULL), (3, '', '', NULL)`)
+	query(engine, "CREATE VIEW v AS SELECT i FROM t WHERE s IS NOT NULL")
+	query(engine, "CREATE TRIGGER trig BEFORE INSERT ON t FOR EACH ROW SET new.s = 'x'")
+	// The rows of c are dumped before the rows of t they reference
+	query(engine, "CREATE TABLE c (i BIGINT PRIMARY KEY, ti BIGINT, CONSTRAINT fk FOREIGN KEY (ti) REFERENCES t (i))")
+	query(engine, "INSERT INTO c VALUES (1, 1), (2, 3)")
+
+	var buf bytes.Buffer
+	require.NoError(engine.Dump(newContext(engine), &buf, plan.DumpOptions{RowsPerInsert: 2}))
+	dump := buf.String()
+	require.Contains(dump, "CREATE TABLE `t` (\n  `i` bigint NOT NULL,")
+	require.Contains(dump, "  KEY `idx_s` (`s`)\n")
+	require.Contains(dump, "INSERT INTO `t` VALUES (1,'it\\'s\\na \\\"test\\\"',0x00ff,'2020-01-02 03:04:05'),(2,NULL,NULL,NULL);\n"+
+		"INSERT INTO `t` VALUES (3,'','',NULL);\n")
+	require.Contains(dump, "CREATE VIEW `v` AS SELECT i FROM t WHERE s IS NOT NULL;\n")
+	require.Contains(dump, "\nDELIMITER ;;\nCREATE TRIGGER trig BEFORE INSERT ON t FOR EACH ROW SET new.s = 'x' ;;\nDELIMITER ;\n")
+
+	// Each row of DUMP DATABASE is a statement, so they can be run one by one in a session to load the dump into another
+	// engine
+	restored := newEngine()
+	restoreCtx := newContext(restored)
+	for _, row := range query(engine, "DUMP DATABASE db") {
+		stmt := row[0].(string)
+		if strings.HasPrefix(stmt, "\nDELIMITER ;;\n") {
+			stmt = strings.TrimSuffix(strings.TrimPrefix(stmt, "\nDELIMITER ;;\n"), " ;;\nDELIMITER ;")
+		}
+		_, iter, err := restored.Query(restoreCtx, stmt)
+		require.NoError(err, stmt)
+		_, err = sql.RowIterToRows(iter)
+		require.NoError(err, stmt)
+	}
+	for _, q := range []string{"SELECT * FROM t ORDER BY i", "SELECT * FROM v ORDER BY i", "SHOW CREATE TABLE t", "SELECT * FROM c ORDER BY i"} {
+		require.Equal(query(engine, q), query(restored, q), q)
+	}
+	query(restored, "INSERT INTO t (i) VALUES (4)")
+	require.Equal([]sql.Row{{"x"}}, query(restored, "SELECT s FROM t WHERE i = 4"))
+
+	// Dumps read the databases as they were when they started
+	ctx := newContext(engine)
+	iter, err := plan.NewDumpIter(ctx, engine.Catalog, plan.DumpOptions{})
+	require.NoError(err)
+	query(engine, "INSERT INTO t VALUES (5, 'new', NULL, NULL)")
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.NotContains(fmt.Sprint(rows), "'new'")
+
+	// More than one database are dumped with the statements that create them
+	pid++
+	ctx = sql.NewContext(context.Background(), sql.WithPid(pid))
+	engine.Catalog.AddDatabase(memory.NewDatabase("db2"))
+	buf.Reset()
+	require.NoError(engine.Dump(ctx, &buf, plan.DumpOptions{Databases: []string{"db", "db2"}}))
+	require.Contains(buf.String(), "CREATE DATABASE /*!32312 IF NOT EXISTS*/ `db2`;\n\nUSE `db2`;\n")
+	require.True(sql.ErrNoDatabaseSelected.Is(engine.Dump(ctx, &buf, plan.DumpOptions{})))
+	require.True(sql.ErrDatabaseNotFound.Is(engine.Dump(ctx, &buf, plan.DumpOptions{Databases: []string{"nope"}})))
+
+	// Dumps skip row policies and column masks, so they need the unmask permission
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("db"))
+	engine = sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{Auth: auth.NewNativeSingle("user", "", auth.ReadPerm)})
+	pid++
+	ctx = sql.NewContext(
+		context.Background(),
+		sql.WithPid(pid),
+		sql.WithSession(sql.NewSession("server", "client", "user", 1)),
+	).WithCurrentDB("db")
+	_, _, err = engine.Query(ctx, "DUMP DATABASE")
+	require.True(auth.ErrNotAuthorized.Is(err))
+}
+
+// mysqldumpScript is a dump of mysqldump, of a database with a table, a view and a trigger.
+const mysqldumpScript = "-- MySQL dump 10.13  Distrib 8.0.32, for Linux (x86_64)\n" +
+	"--\n" +
+	"-- Host: localhost    Database: db\n" +
+	"-- ------------------------------------------------------\n" +
+	"-- Server version\t8.0.32\n" +
+	"\n" +
+	"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+	"/*!50503 SET NAMES utf8mb4 */;\n" +
+	"/*!40103 SET @OLD_TIME_ZONE=@@TIME_ZONE */;\n" +
+	"/*!40103 SET TIME_ZONE='+00:00' */;\n" +
+	"/*!40014 SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0 */;\n" +
+	"/*!40014 SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0 */;\n" +
+	"/*!40101 SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='NO_AUTO_VALUE_ON_ZERO' */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Table structure for table `t`\n" +
+	"--\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `t`;\n" +
+	"/*!40101 SET @saved_cs_client     = @@character_set_client */;\n" +
+	"/*!50503 SET character_set_client = utf8mb4 */;\n" +
+	"CREATE TABLE `t` (\n" +
+	"  `id` int NOT NULL AUTO_INCREMENT,\n" +
+	"  `name` varchar(64) DEFAULT NULL,\n" +
+	"  `data` blob,\n" +
+	"  `created` datetime DEFAULT NULL,\n" +
+	"  `score` double DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`),\n" +
+	"  UNIQUE KEY `name` (`name`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=4 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;\n" +
+	"/*!40101 SET character_set_client = @saved_cs_client */;\n" +
+	"\n" +
+	"--\n" +
+	"-- Dumping data for table `t`\n" +
+	"--\n" +
+	"\n" +
+	"LOCK TABLES `t` WRITE;\n" +
+	"/*!40000 ALTER TABLE `t` DISABLE KEYS */;\n" +
+	"INSERT INTO `t` VALUES (1,'a;b',NULL,'2020-01-01 00:00:00',-1.5),(2,'it\\'s',0x00FF,NULL,NULL);\n" +
+	"INSERT INTO `t` VALUES (3,'c',_binary '',NULL,2);\n" +
+	"/*!40000 ALTER TABLE `t` ENABLE KEYS */;\n" +
+	"UNLOCK TABLES;\n" +
+	"\n" +
+	"--\n" +
+	"-- Temporary view structure for view `v`\n" +
+	"--\n" +
+	"\n" +
+	"DROP TABLE IF EXISTS `v`;\n" +
+	"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+	"/*!50001 CREATE VIEW `v` AS SELECT \n" +
+	" 1 AS `id`*/;\n" +
+	"/*!50003 SET @saved_sql_mode       = @@sql_mode */ ;\n" +
+	"DELIMITER ;;\n" +
+	"/*!50003 CREATE*/ /*!50017 DEFINER=`root`@`localhost`*/ /*!50003 TRIGGER `trg` BEFORE INSERT ON `t` FOR EACH ROW SET NEW.name = LOWER(NEW.name) */;;\n" +
+	"DELIMITER ;\n" +
+	"/*!50003 SET sql_mode              = @saved_sql_mode */ ;\n" +
+	"\n" +
+	"--\n" +
+	"-- Final view structure for view `v`\n" +
+	"--\n" +
+	"\n" +
+	"/*!50001 DROP VIEW IF EXISTS `v`*/;\n" +
+	"/*!50001 SET @saved_cs_client          = @@character_set_client */;\n" +
+	"/*!50001 CREATE ALGORITHM=UNDEFINED */\n" +
+	"/*!50013 DEFINER=`root`@`localhost` SQL SECURITY DEFINER */\n" +
+	"/*!50001 VIEW `v` AS select `t`.`id` AS `id` from `t` where (`t`.`score` > 0) */;\n" +
+	"/*!50001 SET character_set_client      = @saved_cs_client */;\n" +
+	"/*!40103 SET TIME_ZONE=@OLD_TIME_ZONE */;\n" +
+	"\n" +
+	"/*!40101 SET SQL_MODE=@OLD_SQL_MODE */;\n" +
+	"/*!40014 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS */;\n" +
+	"/*!40014 SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS */;\n" +
+	"\n" +
+	"-- Dump completed on 2023-01-01 12:00:00\n"
+
+func TestImport(t *testing.T) {
+	require := require.New(t)
+
+	views := make(map[*sqle.Engine]*sql.ViewRegistry)
+	newEngine := func() *sqle.Engine {
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(memory.NewDatabase("db"))
+		e := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+		views[e] = sql.NewViewRegistry()
+		return e
+	}
+	var pid uint64
+	newContext := func(e *sqle.Engine) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithPid(pid), sql.WithViewRegistry(views[e])).WithCurrentDB("db")
+	}
+	query := func(e *sqle.Engine, q string) []sql.Row {
+		_, iter, err := e.Query(newContext(e), q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	engine := newEngine()
+	require.NoError(engine.Import(newContext(engine), strings.NewReader(mysqldumpScript)))
+
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal([]sql.Row{
+		{int32(1), "a;b", nil, created, -1.5},
+		{int32(2), "it's", "\x00\xff", nil, nil},
+		{int32(3), "c", "", nil, float64(2)},
+	}, query(engine, "SELECT * FROM t ORDER BY id"))
+	require.Equal([]sql.Row{{int32(3)}}, query(engine, "SELECT * FROM v"))
+	query(engine, "INSERT INTO t (name) VALUES ('D')")
+	require.Equal([]sql.Row{{int32(4), "d"}}, query(engine, "SELECT id, name FROM t WHERE id = 4"))
+
+	// Dumps of the engine are imported back as they were
+	var buf bytes.Buffer
+	require.NoError(engine.Dump(newContext(engine), &buf, plan.DumpOptions{RowsPerInsert: 2}))
+	restored := newEngine()
+	require.NoError(restored.Import(newContext(restored), &buf))
+	for _, q := range []string{"SELECT * FROM t ORDER BY id", "SELECT * FROM v", "SHOW CREATE TABLE t"} {
+		require.Equal(query(engine, q), query(restored, q), q)
+	}
+
+	// The unique keys of the rows of consecutive INSERT statements of a table are checked after all of them, and the
+	// error has the line of the first one
+	script := "CREATE TABLE u (i BIGINT PRIMARY KEY, s TEXT);\n" +
+		"INSERT INTO u VALUES (1, 'a');\n" +
+		"INSERT INTO u VALUES (2, 'b'),\n(3, 'c');\n" +
+		"INSERT INTO u (s, i) VALUES ('d', 1);\n" +
+		"INSERT INTO t VALUES (10, 'x', NULL, NULL, NULL);\n"
+	err := restored.Import(newContext(restored), strings.NewReader(script))
+	require.True(sql.ErrImportStatement.Is(err))
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+	require.Contains(err.Error(), "statement on line 2 of the import failed")
+	require.Equal([]sql.Row{{int64(0)}}, query(restored, "SELECT COUNT(*) FROM u"))
+	require.Equal([]sql.Row{{int64(4)}}, query(restored, "SELECT COUNT(*) FROM t"))
+
+	err = restored.Import(newContext(restored), strings.NewReader("INSERT INTO u VALUES (1, 'a');\nINSERT INTO nope VALUES (1);"))
+	require.True(sql.ErrTableNotFound.Is(err))
+	require.Contains(err.Error(), "statement on line 2 of the import failed")
+
+	// Foreign keys are enforced on the rows inserted unless foreign_key_checks is turned off, as dumps do
+	script = "CREATE TABLE p (i BIGINT PRIMARY KEY);\n" +
+		"CREATE TABLE c (i BIGINT PRIMARY KEY, pi BIGINT, FOREIGN KEY (pi) REFERENCES p (i));\n" +
+		"INSERT INTO c VALUES (1, 1);\n"
+	err = restored.Import(newContext(restored), strings.NewReader(script))
+	require.True(sql.ErrForeignKeyChildViolation.Is(err))
+	script = "/*!40014 SET FOREIGN_KEY_CHECKS=0 */;\n" +
+		"INSERT INTO c VALUES (1, 1);\n"
+	require.NoError(restored.Import(newContext(restored), strings.NewReader(script)))
+	require.Equal([]sql.Row{{int64(1), int64(1)}}, query(restored, "SELECT * FROM c"))
+}
+
+func TestQueryArrow(t *testing.T) {
+	require := require.New(t)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(memory.NewDatabase("db"))
+	var pid uint64
+	newContext := func() *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	query := func(q string) {
+		_, iter, err := engine.Query(newContext(), q)
+		require.NoError(err, q)
+		_, err = sql.RowIterToRows(iter)
+		require.NoError(err, q)
+	}
+
+	query("CREATE TABLE t (i BIGINT PRIMARY KEY, s VARCHAR(20), f DOUBLE)")
+	var values []string
+	for i := 1; i <= 10; i++ {
+		values = append(values, fmt.Sprintf("(%d, 's%d', %d.5)", i, i, i))
+	}
+	query("INSERT INTO t VALUES " + strings.Join(values, ", ") + ", (11, NULL, NULL)")
+
+	r, err := engine.QueryArrow(newContext(), "SELECT i, s, f FROM t ORDER BY i", 4)
+	require.NoError(err)
+	require.Equal(arrow.Schema{
+		{Name: "i", Type: arrow.DataType{ID: arrow.Int, BitWidth: 64, Signed: true}},
+		{Name: "s", Type: arrow.DataType{ID: arrow.Utf8}, Nullable: true},
+		{Name: "f", Type: arrow.DataType{ID: arrow.FloatingPoint, BitWidth: 64}, Nullable: true},
+	}, r.Schema())
+
+	var lens []int
+	var sum float64
+	var rows int
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+		lens = append(lens, record.Len)
+		for i := 0; i < record.Len; i++ {
+			require.Equal(int64(rows+1), record.Columns[0].Int64(i))
+			if !record.Columns[2].IsNull(i) {
+				sum += record.Columns[2].Float64(i)
+			}
+			rows++
+		}
+	}
+	require.NoError(r.Close())
+	require.Equal([]int{4, 4, 3}, lens)
+	require.Equal(60.0, sum)
+
+	var buf bytes.Buffer
+	require.NoError(engine.WriteArrow(newContext(), &buf, "SELECT s FROM t WHERE i = 3"))
+	require.Equal([]byte{0xff, 0xff, 0xff, 0xff}, buf.Bytes()[:4])
+	require.Contains(buf.String(), "s3")
+	require.Equal([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0}, buf.Bytes()[buf.Len()-8:])
+
+	_, err = engine.QueryArrow(newContext(), "SELECT * FROM nope", 0)
+	require.True(sql.ErrTableNotFound.Is(err))
+}
+
+func TestQueryInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(memory.NewDatabase("db"))
+	var pid uint64
+	newContext := func() *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	query := func(q string) {
+		_, iter, err := engine.Query(newContext(), q)
+		require.NoError(err, q)
+		_, err = sql.RowIterToRows(iter)
+		require.NoError(err, q)
+	}
+
+	query("CREATE TABLE t (i BIGINT PRIMARY KEY)")
+	var values []string
+	for i := 0; i < 2000; i++ {
+		values = append(values, fmt.Sprintf("(%d)", i))
+	}
+	query("INSERT INTO t VALUES " + strings.Join(values, ", "))
+
+	// The join goes through millions of pairs of rows without returning any, so it has to be interrupted mid-flight
+	ctx := newContext()
+	_, iter, err := engine.Query(ctx, "SELECT a.i FROM t a, t b WHERE a.i + b.i < 0")
+	require.NoError(err)
+	time.AfterFunc(50*time.Millisecond, func() {
+		engine.Catalog.KillOnlyQueries(ctx.Session.ID())
+	})
+
+	start := time.Now()
+	_, err = sql.RowIterToRows(iter)
+	require.Equal(context.Canceled, err)
+	require.True(time.Since(start) < 5*time.Second)
+	require.Empty(engine.Catalog.Processes())
+}
+
+// singleWriterDatabase is a memory database whose statements writing to it must run one at a time.
+type singleWriterDatabase struct {
+	*memory.Database
+}
+
+func (singleWriterDatabase) SingleWriter() bool { return true }
+
+// transactionSession is a session that is in a transaction until it's rolled back.
+type transactionSession struct {
+	sql.Session
+	rolledBack bool
+}
+
+func (s *transactionSession) InTransaction() bool { return !s.rolledBack }
+
+func (s *transactionSession) RollbackTransaction(*sql.Context) error {
+	s.rolledBack = true
+	return nil
+}
+
+func TestWriteQueue(t *testing.T) {
+	require := require.New(t)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(singleWriterDatabase{memory.NewDatabase("db")})
+	engine.AddDatabase(memory.NewDatabase("other"))
+	var pid uint64
+	newContext := func(sess sql.Session) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	newSession := func() sql.Session {
+		return sql.NewSession("localhost", "localhost", "root", uint32(pid+1))
+	}
+	query := func(ctx *sql.Context, q string) error {
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query(newContext(newSession()), "CREATE TABLE t (i BIGINT PRIMARY KEY)"))
+	require.NoError(query(newContext(newSession()), "CREATE TABLE other.t (i BIGINT PRIMARY KEY)"))
+
+	// The insert has the turn to write to db until its rows are read
+	_, iter, err := engine.Query(newContext(newSession()), "INSERT INTO t VALUES (1)")
+	require.NoError(err)
+
+	ctx := newContext(newSession())
+	require.NoError(ctx.Set(ctx, "innodb_lock_wait_timeout", sql.Int64, int64(0)))
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "INSERT INTO t VALUES (2)")))
+	require.NoError(query(ctx, "SELECT * FROM t"))
+	require.NoError(query(ctx, "INSERT INTO other.t VALUES (2)"))
+	require.NoError(query(ctx, "DELETE FROM other.t WHERE i = 2"))
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "UPDATE t SET i = 2 WHERE i = 1")))
+
+	inserted := make(chan error)
+	go func() {
+		inserted <- query(newContext(newSession()), "INSERT INTO t VALUES (3)")
+	}()
+	select {
+	case err := <-inserted:
+		require.Fail("the insert didn't wait for its turn", "%v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.NoError(<-inserted)
+
+	// Sessions in a transaction keep their turn after their statements finish, until they release it
+	sess := &transactionSession{Session: newSession()}
+	require.NoError(query(newContext(sess), "INSERT INTO t VALUES (4)"))
+	require.True(engine.WriteQueue.Holds(sess.ID()))
+	require.NoError(query(newContext(sess), "INSERT INTO t VALUES (5)"))
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "INSERT INTO t VALUES (6)")))
+	engine.WriteQueue.Release(sess.ID())
+	require.NoError(query(ctx, "INSERT INTO t VALUES (6)"))
+	require.False(engine.WriteQueue.Holds(ctx.Session.ID()))
+
+	// Truncating tables and changing their indexes writes to their database too
+	require.NoError(query(ctx, "CREATE TABLE u (i BIGINT PRIMARY KEY, j BIGINT)"))
+	require.NoError(query(ctx, "INSERT INTO u VALUES (1, 1), (2, 2)"))
+	_, iter, err = engine.Query(newContext(newSession()), "INSERT INTO t VALUES (7)")
+	require.NoError(err)
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "TRUNCATE TABLE u")))
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "CREATE INDEX idx_j ON u (j)")))
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.NoError(query(ctx, "TRUNCATE TABLE u"))
+	require.NoError(query(ctx, "CREATE INDEX idx_j ON u (j)"))
+	require.NoError(query(ctx, "DROP INDEX idx_j ON u"))
+
+	_, iter, err = engine.Query(newContext(newSession()), "SELECT * FROM u")
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Empty(rows)
+}
+
+func TestTableLocks(t *testing.T) {
+	require := require.New(t)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(memory.NewDatabase("db"))
+	var pid uint64
+	newContext := func(sess sql.Session) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	alice := sql.NewSession("localhost", "localhost", "alice", 1)
+	bob := sql.NewSession("localhost", "localhost", "bob", 2)
+	query := func(sess sql.Session, q string) error {
+		_, iter, err := engine.Query(newContext(sess), q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	require.NoError(query(alice, "CREATE TABLE t (i BIGINT PRIMARY KEY)"))
+	require.NoError(query(alice, "CREATE TABLE u (i BIGINT PRIMARY KEY)"))
+	require.NoError(query(alice, "CREATE TABLE v (i BIGINT PRIMARY KEY)"))
+	ctx := newContext(bob)
+	require.NoError(ctx.Set(ctx, "lock_wait_timeout", sql.Int64, int64(0)))
+
+	// Tables can't be dropped or altered while they're read
+	_, iter, err := engine.Query(newContext(alice), "SELECT * FROM t")
+	require.NoError(err)
+	require.True(sql.ErrLockWaitTimeout.Is(query(bob, "DROP TABLE t")))
+	require.True(sql.ErrLockWaitTimeout.Is(query(bob, "ALTER TABLE t ADD COLUMN j BIGINT")))
+	require.NoError(query(bob, "INSERT INTO t VALUES (1)"))
+	require.NoError(query(bob, "ALTER TABLE u ADD COLUMN j BIGINT"))
+
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.NoError(query(bob, "ALTER TABLE t ADD COLUMN j BIGINT"))
+
+	// Point lookups lock the table they look their row up in like any other read
+	_, iter, err = engine.Query(newContext(alice), "EXPLAIN SELECT * FROM t WHERE i = 1")
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Contains(fmt.Sprint(rows), "PointLookup")
+	_, iter, err = engine.Query(newContext(alice), "SELECT * FROM t WHERE i = 1")
+	require.NoError(err)
+	require.True(sql.ErrLockWaitTimeout.Is(query(bob, "DROP TABLE t")))
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+
+	_, iter, err = engine.Query(newContext(alice), "SELECT * FROM u")
+	require.NoError(err)
+	dropped := make(chan error)
+	go func() {
+		dropped <- query(sql.NewSession("localhost", "localhost", "carol", 3), "DROP TABLE u")
+	}()
+	select {
+	case err := <-dropped:
+		require.Fail("the drop didn't wait for the read", "%v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.NoError(<-dropped)
+
+	// LOCK TABLES ... READ lets other sessions read the table, but not write to it
+	require.NoError(query(alice, "LOCK TABLES t READ"))
+	require.True(sql.ErrLockWaitTimeout.Is(query(bob, "INSERT INTO t VALUES (2, 2)")))
+	require.NoError(query(bob, "SELECT * FROM t"))
+	require.True(sql.ErrTableNotLockedForWrite.Is(query(alice, "INSERT INTO t VALUES (2, 2)")))
+	require.True(sql.ErrTableNotLocked.Is(query(alice, "SELECT * FROM v")))
+	require.True(sql.ErrTableNotLocked.Is(query(alice, "SELECT * FROM v WHERE i = 1")))
+
+	// LOCK TABLES ... WRITE keeps other sessions from using the table
+	require.NoError(query(alice, "LOCK TABLES t WRITE"))
+	require.True(sql.ErrLockWaitTimeout.Is(query(bob, "SELECT * FROM t")))
+	require.NoError(query(alice, "INSERT INTO t VALUES (2, 2)"))
+
+	require.NoError(query(alice, "UNLOCK TABLES"))
+	require.NoError(query(bob, "INSERT INTO t VALUES (3, 3)"))
+}
+
+func TestDeadlocks(t *testing.T) {
+	require := require.New(t)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(singleWriterDatabase{memory.NewDatabase("db")})
+	var pid uint64
+	newContext := func(sess sql.Session) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	query := func(ctx *sql.Context, q string) error {
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		_, err = sql.RowIterToRows(iter)
+		return err
+	}
+
+	root := sql.NewSession("localhost", "localhost", "root", 1)
+	require.NoError(query(newContext(root), "CREATE TABLE t (i BIGINT PRIMARY KEY)"))
+	require.NoError(query(newContext(root), "CREATE TABLE u (i BIGINT PRIMARY KEY)"))
+
+	alice := &transactionSession{Session: sql.NewSession("localhost", "localhost", "alice", 2)}
+	bob := &transactionSession{Session: sql.NewSession("localhost", "localhost", "bob", 3)}
+	require.NoError(query(newContext(alice), "INSERT INTO t VALUES (1)"))
+	require.NoError(query(newContext(bob), "SELECT * FROM u"))
+
+	// Bob waits for the turn of alice to write to db, and alice would wait for bob to stop reading u to drop it
+	inserted := make(chan error)
+	bobCtx := newContext(bob)
+	go func() {
+		inserted <- query(bobCtx, "INSERT INTO u VALUES (1)")
+	}()
+	select {
+	case err := <-inserted:
+		require.Fail("the insert didn't wait for its turn", "%v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.True(sql.ErrLockDeadlock.Is(query(newContext(alice), "DROP TABLE u")))
+	require.True(alice.rolledBack)
+	require.False(engine.WriteQueue.Holds(alice.ID()))
+	require.NoError(<-inserted)
+	require.False(bob.rolledBack)
+
+	// Writes wait for the innodb_lock_wait_timeout of their session, and only their statement fails when it's exceeded
+	ctx := newContext(root)
+	require.NoError(ctx.Set(ctx, "innodb_lock_wait_timeout", sql.Int64, int64(0)))
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "INSERT INTO t VALUES (2)")))
+	ctx = newContext(alice)
+	require.NoError(ctx.Set(ctx, "innodb_lock_wait_timeout", sql.Int64, int64(0)))
+	alice.rolledBack = false
+	require.True(sql.ErrLockWaitTimeout.Is(query(ctx, "INSERT INTO t VALUES (2)")))
+	require.False(alice.rolledBack)
+
+	engine.WriteQueue.Release(bob.ID())
+	engine.Catalog.ReleaseTableLocks(bob.ID(), false)
+	require.NoError(query(ctx, "INSERT INTO t VALUES (2)"))
+}
+
+// twoPhaseCommitDatabase is a database that logs the phases of its commits.
+type twoPhaseCommitDatabase struct {
+	*memory.Database
+	log         *[]string
+	failPrepare *bool
+}
+
+func (d twoPhaseCommitDatabase) PrepareCommit(*sql.Context) error {
+	*d.log = append(*d.log, "prepare "+d.Name())
+	if *d.failPrepare {
+		return fmt.Errorf("disk full")
+	}
+	return nil
+}
+
+func (d twoPhaseCommitDatabase) CommitPrepared(*sql.Context) error {
+	*d.log = append(*d.log, "commit "+d.Name())
+	return nil
+}
+
+func (d twoPhaseCommitDatabase) RollbackPrepared(*sql.Context) error {
+	*d.log = append(*d.log, "rollback "+d.Name())
+	return nil
+}
+
+func TestTwoPhaseCommit(t *testing.T) {
+	require := require.New(t)
+
+	var log []string
+	failPrepare := false
+	engine := sqle.NewDefault()
+	require.NoError(engine.AddDatabase(twoPhaseCommitDatabase{memory.NewDatabase("db"), &log, new(bool)}))
+	require.NoError(engine.AddDatabase(twoPhaseCommitDatabase{memory.NewDatabase("other"), &log, &failPrepare}))
+	require.NoError(engine.AddDatabase(memory.NewDatabase("plain")))
+
+	sess := sql.NewSession("localhost", "localhost", "root", 1)
+	var pid uint64
+	query := func(q string) error {
+		pid++
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return err
+		}
+		if _, err = sql.RowIterToRows(iter); err != nil {
+			_ = iter.Close()
+		}
+		return err
+	}
+	logOf := func(q string) []string {
+		log = nil
+		require.NoError(query(q), q)
+		return log
+	}
+
+	// With autocommit, each statement commits its changes
+	require.NoError(query("SET autocommit = 1"))
+	require.Equal([]string{"prepare db", "commit db"}, logOf("CREATE TABLE t (i BIGINT PRIMARY KEY)"))
+	require.Equal([]string{"prepare other", "commit other"}, logOf("CREATE TABLE other.u (i BIGINT PRIMARY KEY)"))
+	require.Nil(logOf("CREATE TABLE plain.v (i BIGINT PRIMARY KEY)"))
+	require.Nil(logOf("SELECT * FROM t"))
+
+	// Statements writing to several databases commit to all of them once they're all prepared
+	logOf("CREATE TRIGGER copy AFTER INSERT ON t FOR EACH ROW INSERT INTO other.u VALUES (new.i)")
+	require.Equal([]string{"prepare db", "prepare other", "commit db", "commit other"}, logOf("INSERT INTO t VALUES (1)"))
+
+	// And roll back all of them if any fails to prepare
+	failPrepare = true
+	log = nil
+	err := query("INSERT INTO t VALUES (2)")
+	require.True(sql.ErrPrepareCommit.Is(err), "%v", err)
+	require.Equal([]string{"prepare db", "prepare other", "rollback db", "rollback other"}, log)
+	failPrepare = false
+
+	// Failed statements are rolled back
+	log = nil
+	require.Error(query("INSERT INTO t VALUES (1)"))
+	require.Equal([]string{"rollback db", "rollback other"}, log)
+
+	// Without autocommit, the changes are committed or rolled back at the end of the transaction
+	require.NoError(query("SET autocommit = 0"))
+	require.Nil(logOf("INSERT INTO t VALUES (3)"))
+	require.Nil(logOf("INSERT INTO plain.v VALUES (3)"))
+	require.Equal([]string{"prepare db", "prepare other", "commit db", "commit other"}, logOf("COMMIT"))
+	require.Nil(logOf("INSERT INTO other.u VALUES (4)"))
+	require.Equal([]string{"rollback other"}, logOf("ROLLBACK"))
+	require.Nil(logOf("COMMIT"))
+}
+
+func TestXATransactions(t *testing.T) {
+	require := require.New(t)
+
+	var log []string
+	failPrepare := false
+	engine := sqle.NewDefault()
+	require.NoError(engine.AddDatabase(twoPhaseCommitDatabase{memory.NewDatabase("db"), &log, &failPrepare}))
+	require.NoError(engine.AddDatabase(twoPhaseCommitDatabase{memory.NewDatabase("other"), &log, new(bool)}))
+
+	var pid uint64
+	queryAs := func(sess sql.Session, q string) ([]sql.Row, error) {
+		pid++
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sql.RowIterToRows(iter)
+		if err != nil {
+			_ = iter.Close()
+		}
+		return rows, err
+	}
+	alice := sql.NewSession("localhost", "localhost", "alice", 1)
+	bob := sql.NewSession("localhost", "localhost", "bob", 2)
+	logOf := func(sess sql.Session, queries ...string) []string {
+		log = nil
+		for _, q := range queries {
+			_, err := queryAs(sess, q)
+			require.NoError(err, q)
+		}
+		return log
+	}
+
+	for _, sess := range []sql.Session{alice, bob} {
+		logOf(sess, "SET autocommit = 1")
+	}
+	logOf(alice,
+		"CREATE TABLE t (i BIGINT PRIMARY KEY)",
+		"CREATE TABLE other.u (i BIGINT PRIMARY KEY)",
+		"CREATE TRIGGER copy AFTER INSERT ON t FOR EACH ROW INSERT INTO other.u VALUES (new.i)",
+	)
+
+	// The changes of XA transactions are only committed by the XA statements, even with autocommit
+	require.Nil(logOf(alice, "XA START 'a'", "INSERT INTO t VALUES (1)"))
+	_, err := queryAs(alice, "COMMIT")
+	require.True(sql.ErrXARMFail.Is(err), "%v", err)
+	_, err = queryAs(alice, "XA PREPARE 'a'")
+	require.True(sql.ErrXARMFail.Is(err), "%v", err)
+	require.Nil(logOf(alice, "XA END 'a'"))
+	require.Equal([]string{"prepare db", "prepare other"}, logOf(alice, "XA PREPARE 'a'"))
+
+	// Prepared transactions are listed by XA RECOVER, and can be committed by any session
+	rows, err := queryAs(bob, "XA RECOVER")
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), int64(1), int64(0), "a"}}, rows)
+	require.Equal([]string{"commit db", "commit other"}, logOf(bob, "XA COMMIT 'a'"))
+	rows, err = queryAs(bob, "XA RECOVER")
+	require.NoError(err)
+	require.Empty(rows)
+	_, err = queryAs(bob, "XA COMMIT 'a'")
+	require.True(sql.ErrXANotA.Is(err), "%v", err)
+
+	// Ended transactions can be committed in one phase, or rolled back
+	require.Equal(
+		[]string{"prepare db", "prepare other", "commit db", "commit other"},
+		logOf(alice, "XA START 'b'", "INSERT INTO t VALUES (2)", "XA END 'b'", "XA COMMIT 'b' ONE PHASE"),
+	)
+	require.Equal(
+		[]string{"rollback db", "rollback other"},
+		logOf(alice, "XA START 'c', 'branch', 7", "INSERT INTO t VALUES (3)", "XA END 'c', 'branch', 7", "XA ROLLBACK 'c', 'branch', 7"),
+	)
+
+	// Transactions that fail to prepare are rolled back
+	failPrepare = true
+	logOf(alice, "XA START 'd'", "INSERT INTO t VALUES (4)", "XA END 'd'")
+	log = nil
+	_, err = queryAs(alice, "XA PREPARE 'd'")
+	require.True(sql.ErrXARBRollback.Is(err), "%v", err)
+	require.Equal([]string{"prepare db", "rollback db", "rollback other"}, log)
+	failPrepare = false
+
+	// Sessions have one XA transaction at a time, and xids are unique
+	logOf(alice, "XA START X'65'")
+	_, err = queryAs(alice, "XA START 'f'")
+	require.True(sql.ErrXARMFail.Is(err), "%v", err)
+	_, err = queryAs(bob, "XA START 'e'")
+	require.True(sql.ErrXADupID.Is(err), "%v", err)
+	_, err = queryAs(bob, "XA END 'e'")
+	require.True(sql.ErrXANotA.Is(err), "%v", err)
+
+	// XA transactions can't start with changes outside of them
+	logOf(bob, "SET autocommit = 0", "INSERT INTO other.u VALUES (5)")
+	_, err = queryAs(bob, "XA START 'f'")
+	require.True(sql.ErrXAOutside.Is(err), "%v", err)
+}
+
+// transactionalDatabase is a single-writer database that logs the transactions started and ended in it.
+type transactionalDatabase struct {
+	*memory.Database
+	log *[]string
+}
+
+func (d transactionalDatabase) SingleWriter() bool { return true }
+
+func (d transactionalDatabase) StartTransaction(*sql.Context) error {
+	*d.log = append(*d.log, "start "+d.Name())
+	return nil
+}
+
+func (d transactionalDatabase) CommitTransaction(*sql.Context) error {
+	*d.log = append(*d.log, "commit "+d.Name())
+	return nil
+}
+
+func (d transactionalDatabase) RollbackTransaction(*sql.Context) error {
+	*d.log = append(*d.log, "rollback "+d.Name())
+	return nil
+}
+
+func TestTransactions(t *testing.T) {
+	require := require.New(t)
+
+	var log []string
+	engine := sqle.NewDefault()
+	require.NoError(engine.AddDatabase(transactionalDatabase{memory.NewDatabase("db"), &log}))
+	require.NoError(engine.AddDatabase(transactionalDatabase{memory.NewDatabase("other"), &log}))
+	require.NoError(engine.AddDatabase(memory.NewDatabase("plain")))
+
+	var pid uint64
+	newContext := func(sess sql.Session) *sql.Context {
+		pid++
+		return sql.NewContext(context.Background(), sql.WithSession(sess), sql.WithPid(pid)).WithCurrentDB("db")
+	}
+	queryAs := func(sess sql.Session, q string) error {
+		_, iter, err := engine.Query(newContext(sess), q)
+		if err != nil {
+			return err
+		}
+		if _, err = sql.RowIterToRows(iter); err != nil {
+			_ = iter.Close()
+		}
+		return err
+	}
+	sess := sql.NewSession("localhost", "localhost", "root", 1)
+	logOf := func(queries ...string) []string {
+		log = nil
+		for _, q := range queries {
+			require.NoError(queryAs(sess, q), q)
+		}
+		return log
+	}
+
+	// With autocommit, every statement using a database has a transaction of its own
+	require.NoError(queryAs(sess, "SET autocommit = 1"))
+	require.Equal([]string{"start db", "commit db"}, logOf("CREATE TABLE t (i BIGINT PRIMARY KEY)"))
+	require.Equal([]string{"start other", "commit other"}, logOf("CREATE TABLE other.u (i BIGINT PRIMARY KEY)"))
+	require.Nil(logOf("CREATE TABLE plain.v (i BIGINT PRIMARY KEY)", "SELECT 1"))
+	require.Equal([]string{"start db", "start other", "commit db", "commit other"}, logOf("SELECT * FROM t JOIN other.u ON t.i = u.i"))
+	require.Nil(sess.Transaction())
+
+	// Failed statements roll back their transaction
+	log = nil
+	require.NoError(queryAs(sess, "INSERT INTO t VALUES (1)"))
+	log = nil
+	require.Error(queryAs(sess, "INSERT INTO t VALUES (1)"))
+	require.Equal([]string{"start db", "rollback db"}, log)
+
+	// Transactions started with BEGIN last until they're committed or rolled back, or another one begins, even with
+	// autocommit
+	require.Nil(logOf("BEGIN"))
+	require.True(sess.Transaction().Explicit)
+	require.Equal([]string{"start db", "start other"}, logOf("INSERT INTO t VALUES (2)", "SELECT * FROM t", "INSERT INTO other.u VALUES (2)"))
+	require.Equal([]string{"commit db", "commit other"}, logOf("COMMIT"))
+	require.Nil(sess.Transaction())
+	require.Equal([]string{"start db", "rollback db"}, logOf("START TRANSACTION", "INSERT INTO t VALUES (3)", "ROLLBACK"))
+	require.Equal([]string{"start db", "commit db"}, logOf("BEGIN", "INSERT INTO t VALUES (4)", "BEGIN"))
+	require.True(sess.Transaction().Explicit)
+	require.Nil(logOf("COMMIT"))
+
+	// Without autocommit, statements start a transaction that lasts until it's committed, or autocommit is enabled
+	require.Equal([]string{"start db"}, logOf("SET autocommit = 0", "INSERT INTO t VALUES (5)", "SELECT * FROM t"))
+	require.False(sess.Transaction().Explicit)
+	require.Equal([]string{"commit db"}, logOf("COMMIT"))
+	require.Equal([]string{"start db", "commit db"}, logOf("INSERT INTO t VALUES (6)", "SET autocommit = 1"))
+
+	// Transactions keep the turns of their session to write until they end, and are rolled back when it ends
+	require.Nil(logOf("BEGIN"))
+	require.Equal([]string{"start db"}, logOf("INSERT INTO t VALUES (7)"))
+	require.True(engine.WriteQueue.Holds(sess.ID()))
+	other := sql.NewSession("localhost", "localhost", "root", 2)
+	ctx := newContext(other)
+	require.NoError(ctx.Set(ctx, "innodb_lock_wait_timeout", sql.Int64, int64(0)))
+	_, _, err := engine.Query(ctx, "INSERT INTO t VALUES (8)")
+	require.True(sql.ErrLockWaitTimeout.Is(err), "%v", err)
+	log = nil
+	require.NoError(engine.RollbackTransaction(newContext(sess)))
+	require.Equal([]string{"rollback db"}, log)
+	require.False(engine.WriteQueue.Holds(sess.ID()))
+	require.Nil(sess.Transaction())
+}
+
+func TestPreparedQueries(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("mydb")
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	})
+	table.EnablePrimaryKeyIndexes()
+	db.AddTable("t", table)
+
+	engine := sqle.NewDefault()
+	engine.AddDatabase(db)
+
+	var parsed []string
+	engine.AddPreParseHook(func(ctx *sql.Context, query string) (string, error) {
+		parsed = append(parsed, query)
+		return query, nil
+	})
+
+	alice := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("localhost", "localhost", "alice", 1))).WithCurrentDB("mydb")
+	bob := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("localhost", "localhost", "bob", 2))).WithCurrentDB("mydb")
+	query := func(ctx *sql.Context, q string, bindings ...interface{}) ([]sql.Row, error) {
+		exprs := make(map[string]sql.Expression)
+		for i, b := range bindings {
+			exprs["v"+strconv.Itoa(i+1)] = expression.NewLiteral(b, sql.Int64)
+			if s, ok := b.(string); ok {
+				exprs["v"+strconv.Itoa(i+1)] = expression.NewLiteral(s, sql.LongText)
+			}
+		}
+		_, iter, err := engine.QueryWithBindings(ctx, q, exprs)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sql.RowIterToRows(iter)
+		if err != nil {
+			_ = iter.Close()
+		}
+		return rows, err
+	}
+
+	insert := "INSERT INTO t VALUES (?, ?)"
+	schema, err := engine.PrepareQuery(alice, insert)
+	require.NoError(err)
+	require.Nil(schema)
+
+	sel := "SELECT s FROM t WHERE i = ? OR i IN (SELECT i + 1 FROM t WHERE s = ?)"
+	schema, err = engine.PrepareQuery(alice, sel)
+	require.NoError(err)
+	require.Equal(sql.Schema{{Name: "s", Type: sql.Text, Source: "t"}}, schema)
+	require.Equal([]string{insert, sel}, engine.PreparedQueries(alice))
+	require.Empty(engine.PreparedQueries(bob))
+
+	// The types of the parameters are the ones of the columns they're inserted into or compared to
+	require.Equal(map[string]sql.Type{"v1": sql.Int64, "v2": sql.Text}, engine.PreparedQueryParams(alice, insert))
+	require.Equal(map[string]sql.Type{"v1": sql.Int64, "v2": sql.Text}, engine.PreparedQueryParams(alice, sel))
+	require.Nil(engine.PreparedQueryParams(bob, sel))
+
+	// Prepared queries are parsed once, and run with the values bound every time
+	parsed = nil
+	for i, s := range []string{"a", "b", "c"} {
+		_, err := query(alice, insert, int64(i+1), s)
+		require.NoError(err)
+	}
+	rows, err := query(alice, sel, int64(1), "b")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"a"}, {"c"}}, rows)
+	rows, err = query(alice, sel, int64(2), "c")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"b"}}, rows)
+	require.Empty(parsed)
+
+	// Every execution is analyzed with its values, so it can use indexes on them
+	_, err = engine.PrepareQuery(alice, "EXPLAIN SELECT s FROM t WHERE i = ?")
+	require.NoError(err)
+	rows, err = query(alice, "EXPLAIN SELECT s FROM t WHERE i = ?", int64(2))
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"Project(t.s)"},
+		{" └─ PointLookup([t.i] = (2))"},
+		{"     ├─ Filter(t.i = 2)"},
+		{"     └─ Table(t)"},
+	}, rows)
+
+	// Other sessions parse the queries again
+	parsed = nil
+	rows, err = query(bob, sel, int64(3), "z")
+	require.NoError(err)
+	require.Equal([]sql.Row{{"c"}}, rows)
+	require.Equal([]string{sel}, parsed)
+
+	// Parameters without values fail the query
+	_, err = query(alice, sel, int64(1))
+	require.True(expression.ErrUnboundBindVar.Is(err), "%v", err)
+
+	// Queries that can't be analyzed fail to be prepared
+	_, err = engine.PrepareQuery(alice, "SELECT * FROM missing WHERE i = ?")
+	require.True(sql.ErrTableNotFound.Is(err), "%v", err)
+
+	engine.ClosePreparedQuery(alice, insert)
+	require.Equal([]string{"EXPLAIN SELECT s FROM t WHERE i = ?", sel}, engine.PreparedQueries(alice))
+	engine.ClosePreparedQueries(alice)
+	require.Empty(engine.PreparedQueries(alice))
 }
@@ -113,6 +113,33 @@ func TestQueryPlans(t *testing.T, harness Harness) {
 	}
 }
 
+// TestCrossDatabaseQueries tests queries and statements of tables qualified with the name of their database, which
+// isn't the current database.
+func TestCrossDatabaseQueries(t *testing.T, harness Harness) {
+	engine := NewEngine(t, harness)
+	for _, tt := range CrossDatabaseQueries {
+		t.Run(tt.Query, func(t *testing.T) {
+			ctx := NewContextWithEngine(harness, engine).WithCurrentDB("foo")
+			TestQueryWithContext(t, ctx, engine, tt.Query, tt.Expected)
+		})
+	}
+
+	for _, tt := range CrossDatabasePlanTests {
+		t.Run(tt.Query, func(t *testing.T) {
+			TestQueryPlan(t, NewContextWithEngine(harness, engine).WithCurrentDB("foo"), engine, tt.Query, tt.ExpectedPlan)
+		})
+	}
+
+	for _, tt := range CrossDatabaseWriteQueries {
+		t.Run(tt.WriteQuery, func(t *testing.T) {
+			e := NewEngine(t, harness)
+			ctx := NewContextWithEngine(harness, e).WithCurrentDB("foo")
+			TestQueryWithContext(t, ctx, e, tt.WriteQuery, tt.ExpectedWriteResult)
+			TestQueryWithContext(t, ctx, e, tt.SelectQuery, tt.ExpectedSelect)
+		})
+	}
+}
+
 // Tests a variety of queries against databases and tables provided by the given harness.
 func TestVersionedQueries(t *testing.T, harness Harness) {
 	if _, ok := harness.(VersionedDBHarness); !ok {
@@ -542,6 +569,24 @@ func TestTriggers(t *testing.T, harness Harness) {
 	}
 }
 
+func TestForeignKeys(t *testing.T, harness Harness) {
+	for _, script := range ForeignKeyTests {
+		TestScript(t, harness, script)
+	}
+}
+
+func TestCheckConstraints(t *testing.T, harness Harness) {
+	for _, script := range CheckConstraintTests {
+		TestScript(t, harness, script)
+	}
+}
+
+func TestSqlMode(t *testing.T, harness Harness) {
+	for _, script := range SqlModeTests {
+		TestScript(t, harness, script)
+	}
+}
+
 func TestTriggerErrors(t *testing.T, harness Harness) {
 	for _, script := range TriggerErrorTests {
 		TestScript(t, harness, script)
@@ -1757,7 +1802,7 @@ func TestClearWarnings(t *testing.T, harness Harness) {
 	require.NoError(err)
 	require.Equal(1, len(rows))
 
-	_, _, err = e.Query(ctx, "SELECT * FROM mytable LIMIT 1")
+	_, iter, err = e.Query(ctx, "SELECT * FROM mytable LIMIT 1")
 	require.NoError(err)
 	_, err = sql.RowIterToRows(iter)
 	require.NoError(err)
@@ -2265,7 +2310,7 @@ func TestColumnDefaults(t *testing.T, harness Harness) {
 		)
 		TestQuery(t, harness, e,
 			"DESCRIBE t27",
-			[]sql.Row{{"pk", "bigint", "NO", "PRI", "", ""}, {"v1", "double", "YES", "", "-1.1", ""}},
+			[]sql.Row{{"pk", "bigint(20)", "NO", "PRI", nil, ""}, {"v1", "double", "YES", "", "-1.1", ""}},
 		)
 	})
 
@@ -0,0 +1,353 @@
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var ForeignKeyTests = []ScriptTest{
+	{
+		Name: "insert and update child rows",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v varchar(10))",
+			"create table child (id int primary key, pid int, constraint fk_child foreign key (pid) references parent (id))",
+			"insert into parent values (1, 'a'), (2, 'b')",
+			"insert into child values (1, 1), (2, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into child values (3, 3)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "insert into child values (3, 2)",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 1}}},
+			},
+			{
+				Query:       "update child set pid = 5 where id = 1",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "update child set pid = 2 where id = 2",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:       "replace into child values (1, 7)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "select * from child order by id",
+				Expected: []sql.Row{{1, 1}, {2, 2}, {3, 2}},
+			},
+		},
+	},
+	{
+		Name: "restricted parent rows",
+		SetUpScript: []string{
+			"create table parent (id int primary key, v varchar(10))",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id) on delete restrict)",
+			"insert into parent values (1, 'a'), (2, 'b')",
+			"insert into child values (1, 1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "delete from parent where id = 1",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:       "update parent set id = 3 where id = 1",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:    "update parent set v = 'c' where id = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "delete from parent where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:       "truncate table parent",
+				ExpectedErr: sql.ErrTruncateReferencedTable,
+			},
+			{
+				Query:    "select * from parent",
+				Expected: []sql.Row{{1, "c"}},
+			},
+		},
+	},
+	{
+		Name: "on delete cascade",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id) on delete cascade)",
+			"create table grandchild (id int primary key, cid int, foreign key (cid) references child (id) on delete cascade)",
+			"insert into parent values (1), (2)",
+			"insert into child values (1, 1), (2, 1), (3, 2)",
+			"insert into grandchild values (1, 1), (2, 2), (3, 3)",
+			"delete from parent where id = 1",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select * from child",
+				Expected: []sql.Row{{3, 2}},
+			},
+			{
+				Query:    "select * from grandchild",
+				Expected: []sql.Row{{3, 3}},
+			},
+			{
+				Query:    "replace into parent values (2), (3)",
+				Expected: []sql.Row{{sql.NewOkResult(3)}},
+			},
+			{
+				Query:    "select count(*) from child",
+				Expected: []sql.Row{{0}},
+			},
+		},
+	},
+	{
+		Name: "on update cascade",
+		SetUpScript: []string{
+			"create table parent (a int, b int, primary key (a, b))",
+			"create table child (id int primary key, a int, b int, foreign key (a, b) references parent (a, b) on update cascade)",
+			"insert into parent values (1, 1), (1, 2)",
+			"insert into child values (1, 1, 1), (2, 1, 2), (3, 1, null)",
+			"update parent set b = b + 10",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select * from child order by id",
+				Expected: []sql.Row{{1, 1, 11}, {2, 1, 12}, {3, 1, nil}},
+			},
+			{
+				Query:       "delete from parent where b = 11",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+		},
+	},
+	{
+		Name: "on delete and on update set null",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id) on delete set null on update set null)",
+			"insert into parent values (1), (2), (3)",
+			"insert into child values (1, 1), (2, 2), (3, 3)",
+			"delete from parent where id = 1",
+			"update parent set id = 4 where id = 2",
+		},
+		Query:    "select * from child order by id",
+		Expected: []sql.Row{{1, nil}, {2, nil}, {3, 3}},
+	},
+	{
+		Name: "self-referencing table",
+		SetUpScript: []string{
+			"create table node (id int primary key, parent int, foreign key (parent) references node (id) on delete cascade)",
+			"insert into node values (1, 1), (2, 1), (3, 2), (4, null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into node values (5, 6)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "delete from node where id = 2",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from node order by id",
+				Expected: []sql.Row{{1, 1}, {4, nil}},
+			},
+			{
+				Query:    "delete from node",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+		},
+	},
+	{
+		Name: "foreign keys added with alter table",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int)",
+			"alter table child add constraint fk foreign key (pid) references parent (id) on delete cascade",
+			"insert into parent values (1)",
+			"insert into child values (1, 1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into child values (2, 2)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "delete from parent",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select count(*) from child",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "alter table child drop foreign key fk",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "insert into child values (2, 2)",
+				Expected: []sql.Row{{sql.OkResult{RowsAffected: 1}}},
+			},
+		},
+	},
+	{
+		Name: "foreign_key_checks",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id))",
+			"set foreign_key_checks = 0",
+			"insert into child values (1, 1)",
+			"set foreign_key_checks = 1",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select * from child",
+				Expected: []sql.Row{{1, 1}},
+			},
+			{
+				Query:       "insert into child values (2, 2)",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+		},
+	},
+	{
+		Name: "triggers writing child rows",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id))",
+			"create table log (id int primary key)",
+			"create trigger log_inserted after insert on log for each row insert into child values (new.id, new.id)",
+		},
+		Query:       "insert into log values (1)",
+		ExpectedErr: sql.ErrForeignKeyChildViolation,
+	},
+	{
+		Name: "restricted rows are checked before cascading",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, foreign key (pid) references parent (id) on delete cascade on update cascade)",
+			"create table restricted (id int primary key, pid int, foreign key (pid) references parent (id))",
+			"create table grandchild (id int primary key, cid int, foreign key (cid) references child (id))",
+			"insert into parent values (1), (2), (3)",
+			"insert into child values (1, 1), (2, 2), (3, 3)",
+			"insert into restricted values (1, 1)",
+			"insert into grandchild values (1, 2)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "delete from parent where id = 1",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:       "delete from parent where id = 2",
+				ExpectedErr: sql.ErrForeignKeyParentViolation,
+			},
+			{
+				Query:    "select * from child order by id",
+				Expected: []sql.Row{{1, 1}, {2, 2}, {3, 3}},
+			},
+			{
+				Query:    "delete from parent where id = 3",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "select * from child order by id",
+				Expected: []sql.Row{{1, 1}, {2, 2}},
+			},
+		},
+	},
+	{
+		Name: "cascaded updates are checked",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table other (id int primary key)",
+			"create table child (id int primary key, pid int, check (pid < 10), " +
+				"constraint fk_parent foreign key (pid) references parent (id) on update cascade, " +
+				"constraint fk_other foreign key (pid) references other (id))",
+			"insert into parent values (1), (2)",
+			"insert into other values (1), (2), (3), (20)",
+			"insert into child values (1, 1), (2, 2)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "update parent set id = 20 where id = 1",
+				ExpectedErr: sql.ErrCheckConstraintViolated,
+			},
+			{
+				Query:       "update parent set id = 4 where id = 1",
+				ExpectedErr: sql.ErrForeignKeyChildViolation,
+			},
+			{
+				Query:    "select * from parent order by id",
+				Expected: []sql.Row{{1}, {2}},
+			},
+			{
+				Query:    "update parent set id = 3 where id = 1",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "select * from child order by id",
+				Expected: []sql.Row{{1, 3}, {2, 2}},
+			},
+		},
+	},
+	{
+		Name: "referencing rows looked up in an index",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, key (pid), foreign key (pid) references parent (id) on delete cascade)",
+			"insert into parent values (1), (2)",
+			"insert into child values (1, 1), (2, 2), (3, 1)",
+			"delete from parent where id = 1",
+		},
+		Query:    "select * from child order by id",
+		Expected: []sql.Row{{2, 2}},
+	},
+	{
+		Name: "drop referenced tables",
+		SetUpScript: []string{
+			"create table parent (id int primary key)",
+			"create table child (id int primary key, pid int, constraint fk foreign key (pid) references parent (id))",
+			"create table node (id int primary key, parent int, foreign key (parent) references node (id))",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "drop table parent",
+				ExpectedErr: sql.ErrDropReferencedTable,
+			},
+			{
+				Query:    "select count(*) from parent",
+				Expected: []sql.Row{{0}},
+			},
+			{
+				Query:    "drop table node",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "drop table parent, child",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "create table parent (id int primary key)",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "create table child (id int primary key, pid int, constraint fk foreign key (pid) references parent (id))",
+				Expected: []sql.Row{},
+			},
+			{
+				Query:    "set foreign_key_checks = 0",
+				Expected: []sql.Row{{}},
+			},
+			{
+				Query:    "drop table parent",
+				Expected: []sql.Row{},
+			},
+		},
+	},
+}
@@ -318,6 +318,17 @@ var InsertQueries = []WriteQueryTest{
 			{4, "no conflict"},
 		},
 	},
+	{
+		"INSERT INTO mytable (i,s) values (1, 'hello'), (7, 'new') ON DUPLICATE KEY UPDATE s=CONCAT(s, VALUES(s))",
+		[]sql.Row{{sql.NewOkResult(3)}},
+		"SELECT * FROM mytable ORDER BY 1",
+		[]sql.Row{
+			{1, "first rowhello"},
+			{2, "second row"},
+			{3, "third row"},
+			{7, "new"},
+		},
+	},
 	{
 		"INSERT INTO mytable (i,s) values (10, 'hello') ON DUPLICATE KEY UPDATE s='hello'",
 		[]sql.Row{{sql.NewOkResult(1)}},
@@ -331,7 +342,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		"INSERT INTO auto_increment_tbl (c0) values (44)",
-		[]sql.Row{{sql.NewOkResult(1)}},
+		[]sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		"SELECT * FROM auto_increment_tbl ORDER BY pk",
 		[]sql.Row{
 			{1, 11},
@@ -342,7 +353,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		"INSERT INTO auto_increment_tbl (c0) values (44),(55)",
-		[]sql.Row{{sql.NewOkResult(2)}},
+		[]sql.Row{{sql.OkResult{RowsAffected: 2, InsertID: 4}}},
 		"SELECT * FROM auto_increment_tbl ORDER BY pk",
 		[]sql.Row{
 			{1, 11},
@@ -354,7 +365,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		"INSERT INTO auto_increment_tbl values (NULL, 44)",
-		[]sql.Row{{sql.NewOkResult(1)}},
+		[]sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		"SELECT * FROM auto_increment_tbl ORDER BY pk",
 		[]sql.Row{
 			{1, 11},
@@ -365,7 +376,7 @@ var InsertQueries = []WriteQueryTest{
 	},
 	{
 		"INSERT INTO auto_increment_tbl values (0, 44)",
-		[]sql.Row{{sql.NewOkResult(1)}},
+		[]sql.Row{{sql.OkResult{RowsAffected: 1, InsertID: 4}}},
 		"SELECT * FROM auto_increment_tbl ORDER BY pk",
 		[]sql.Row{
 			{1, 11},
@@ -388,7 +399,7 @@ var InsertQueries = []WriteQueryTest{
 	{
 		"INSERT INTO auto_increment_tbl values " +
 			"(NULL, 44), (NULL, 55), (9, 99), (NULL, 110), (NULL, 121)",
-		[]sql.Row{{sql.NewOkResult(5)}},
+		[]sql.Row{{sql.OkResult{RowsAffected: 5, InsertID: 4}}},
 		"SELECT * FROM auto_increment_tbl ORDER BY pk",
 		[]sql.Row{
 			{1, 11},
@@ -436,7 +447,7 @@ var InsertScripts = []ScriptTest{
 			{
 				Query: "select * from auto",
 				Expected: []sql.Row{
-					{1,10}, {2,20}, {3,30},
+					{1, 10}, {2, 20}, {3, 30},
 				},
 			},
 		},
@@ -512,6 +523,10 @@ var InsertErrorTests = []GenericErrorQueryTest{
 		"bad column in on duplicate key update clause",
 		"INSERT INTO mytable values (10, 'b') ON DUPLICATE KEY UPDATE notExist = 1",
 	},
+	{
+		"values outside on duplicate key update clause",
+		"INSERT INTO mytable SELECT i + 10, VALUES(s) FROM mytable",
+	},
 }
 
 var InsertErrorScripts = []ScriptTest{
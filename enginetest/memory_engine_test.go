@@ -15,11 +15,21 @@
 package enginetest_test
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/enginetest"
+	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
 )
 
 // This file is for validating both the engine itself and the in-memory database implementation in the memory package.
@@ -183,6 +193,21 @@ func TestQueryPlans(t *testing.T) {
 	}
 }
 
+func TestCrossDatabaseQueries(t *testing.T) {
+	indexBehaviors := []*indexBehaviorTestParams{
+		{"unmergableIndexes", unmergableIndexDriver, false},
+		{"nativeIndexes", nil, true},
+		{"nativeAndMergable", mergableIndexDriver, true},
+	}
+
+	for _, indexInit := range indexBehaviors {
+		t.Run(indexInit.name, func(t *testing.T) {
+			harness := newMemoryHarness(indexInit.name, 1, 2, indexInit.nativeIndexes, indexInit.driverInitializer)
+			enginetest.TestCrossDatabaseQueries(t, harness)
+		})
+	}
+}
+
 func TestQueryErrors(t *testing.T) {
 	enginetest.TestQueryErrors(t, newDefaultMemoryHarness())
 }
@@ -279,6 +304,18 @@ func TestCreateForeignKeys(t *testing.T) {
 	enginetest.TestCreateForeignKeys(t, newDefaultMemoryHarness())
 }
 
+func TestForeignKeys(t *testing.T) {
+	enginetest.TestForeignKeys(t, newDefaultMemoryHarness())
+}
+
+func TestCheckConstraints(t *testing.T) {
+	enginetest.TestCheckConstraints(t, newDefaultMemoryHarness())
+}
+
+func TestSqlMode(t *testing.T) {
+	enginetest.TestSqlMode(t, newDefaultMemoryHarness())
+}
+
 func TestDropForeignKeys(t *testing.T) {
 	enginetest.TestDropForeignKeys(t, newDefaultMemoryHarness())
 }
@@ -318,3 +355,311 @@ func TestInnerNestedInNaturalJoins(t *testing.T) {
 func TestColumnDefaults(t *testing.T) {
 	enginetest.TestColumnDefaults(t, newDefaultMemoryHarness())
 }
+
+func TestPersistentMemoryDatabase(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "enginetest")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := memory.PersistenceOptions{ParseColumnDefault: parse.StringToColumnDefaultValue}
+	newEngine := func() (*sqle.Engine, *memory.Database) {
+		db, err := memory.NewPersistentDatabase("mydb", dir, opts)
+		require.NoError(err)
+		catalog := sql.NewCatalog()
+		catalog.AddDatabase(db)
+		return sqle.New(catalog, analyzer.NewDefault(catalog), nil), db
+	}
+
+	query := func(engine *sqle.Engine, q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	engine, db := newEngine()
+	for _, q := range []string{
+		"CREATE TABLE products (id BIGINT PRIMARY KEY, name VARCHAR(20) NOT NULL, price DECIMAL(10,2) DEFAULT (1 + 1))",
+		"INSERT INTO products (id, name) VALUES (1, 'apple'), (2, 'pear'), (3, 'plum')",
+		"UPDATE products SET price = 0.5 WHERE id = 2",
+		"DELETE FROM products WHERE id = 3",
+		"CREATE INDEX idx_name ON products (name)",
+		"ALTER TABLE products ADD COLUMN stock INT NOT NULL DEFAULT 10",
+		"REPLACE INTO products (id, name, stock) VALUES (1, 'green apple', 5)",
+	} {
+		query(engine, q)
+	}
+	require.NoError(db.Close())
+
+	engine, db = newEngine()
+	defer db.Close()
+
+	require.Equal([]sql.Row{
+		{int64(1), "green apple", "2.00", int32(5)},
+		{int64(2), "pear", "0.50", int32(10)},
+	}, query(engine, "SELECT id, name, CAST(price AS CHAR), stock FROM products ORDER BY id"))
+
+	rows := query(engine, "SHOW INDEXES FROM products")
+	require.Len(rows, 2)
+	require.Equal("PRIMARY", rows[0][2])
+	require.Equal("idx_name", rows[1][2])
+
+	query(engine, "INSERT INTO products (id, name) VALUES (4, 'fig')")
+	require.Equal([]sql.Row{{int64(4), "2.00", int32(10)}}, query(engine, "SELECT id, CAST(price AS CHAR), stock FROM products WHERE id = 4"))
+}
+
+func TestMemoryIndexMaintenance(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	for _, q := range []string{
+		"CREATE TABLE customers (id BIGINT PRIMARY KEY, name VARCHAR(20) NOT NULL)",
+		"CREATE TABLE orders (id BIGINT PRIMARY KEY, customer BIGINT NOT NULL, total INT NOT NULL)",
+		"CREATE INDEX idx_customer ON orders (customer)",
+		"INSERT INTO customers VALUES (1, 'ann'), (2, 'bob'), (3, 'cid')",
+		"INSERT INTO orders VALUES (1, 1, 10), (2, 1, 20), (3, 2, 30)",
+		"UPDATE orders SET customer = 3 WHERE id = 1",
+		"DELETE FROM orders WHERE id = 3",
+		"ALTER TABLE orders ADD COLUMN note VARCHAR(10) FIRST",
+		"INSERT INTO orders (id, customer, total) VALUES (4, 2, 40)",
+	} {
+		query(q)
+	}
+
+	// The index is used by the join and finds the rows written after it was created
+	join := "SELECT c.name, o.id FROM customers c JOIN orders o ON o.customer = c.id ORDER BY o.id"
+	var plan string
+	for _, row := range query("EXPLAIN " + join) {
+		plan += fmt.Sprintln(row[0])
+	}
+	require.Contains(plan, "IndexedJoin")
+	require.Equal([]sql.Row{{"cid", int64(1)}, {"ann", int64(2)}, {"bob", int64(4)}}, query(join))
+	require.Equal([]sql.Row{{int64(2), int32(40)}}, query("SELECT customer, total FROM orders WHERE customer = 2"))
+}
+
+func TestMemoryPrimaryKeyPointLookup(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	query("CREATE TABLE t (pk BIGINT PRIMARY KEY, v VARCHAR(10))")
+	query("INSERT INTO t VALUES (1, 'a'), (2, 'b')")
+
+	// The primary key of a table created with SQL is a unique index the row is looked up by
+	var plan string
+	for _, row := range query("EXPLAIN SELECT * FROM t WHERE pk = 1") {
+		plan += fmt.Sprintln(row[0])
+	}
+	require.Equal("PointLookup([t.pk] = (1))\n ├─ Filter(t.pk = 1)\n └─ Table(t)\n", plan)
+	require.Equal([]sql.Row{{int64(1), "a"}}, query("SELECT * FROM t WHERE pk = 1"))
+	require.Empty(query("SELECT * FROM t WHERE pk = 3"))
+}
+
+func TestMemoryUniqueConstraints(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	query := func(q string) ([]sql.Row, error) {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := sql.RowIterToRows(iter)
+		if err != nil {
+			_ = iter.Close()
+		}
+		return rows, err
+	}
+
+	for _, q := range []string{
+		"CREATE TABLE users (id BIGINT PRIMARY KEY, email VARCHAR(20), visits INT NOT NULL)",
+		"CREATE UNIQUE INDEX idx_email ON users (email)",
+		"INSERT INTO users VALUES (1, 'a@b.c', 1), (2, NULL, 1), (3, NULL, 1)",
+	} {
+		_, err := query(q)
+		require.NoError(err)
+	}
+
+	for _, q := range []string{
+		"INSERT INTO users VALUES (1, 'd@e.f', 1)",
+		"INSERT INTO users VALUES (4, 'a@b.c', 1)",
+		"UPDATE users SET email = 'a@b.c' WHERE id = 2",
+	} {
+		_, err := query(q)
+		require.True(sql.ErrUniqueKeyViolation.Is(err), "%s: %v", q, err)
+	}
+
+	// Conflicts of the primary key are detected by ON DUPLICATE KEY UPDATE and REPLACE
+	for _, q := range []string{
+		"INSERT INTO users VALUES (1, 'a@b.c', 1) ON DUPLICATE KEY UPDATE visits = visits + 1",
+		"REPLACE INTO users VALUES (2, 'g@h.i', 5)",
+	} {
+		_, err := query(q)
+		require.NoError(err)
+	}
+
+	rows, err := query("SELECT * FROM users ORDER BY id")
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{int64(1), "a@b.c", int32(2)},
+		{int64(2), "g@h.i", int32(5)},
+		{int64(3), nil, int32(1)},
+	}, rows)
+}
+
+func TestMemoryTableMaintenance(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewBuilder(catalog).WithParallelism(2).Build(), nil)
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	for _, q := range []string{
+		"CREATE TABLE a (id BIGINT PRIMARY KEY, name VARCHAR(20))",
+		"CREATE TABLE b (id BIGINT PRIMARY KEY, name VARCHAR(20))",
+		"INSERT INTO a VALUES (1, 'x'), (2, NULL)",
+		"INSERT INTO b VALUES (2, NULL), (1, 'x')",
+	} {
+		query(q)
+	}
+
+	rows := query("CHECKSUM TABLE a, `mydb`.`b`")
+	require.Len(rows, 2)
+	require.Equal("mydb.a", rows[0][0])
+	require.Equal("mydb.b", rows[1][0])
+	require.NotNil(rows[0][1])
+	require.Equal(rows[0][1], rows[1][1])
+	require.Equal([]sql.Row{{"mydb.a", nil}}, query("CHECKSUM TABLE a QUICK"))
+
+	require.Equal([]sql.Row{{"mydb.a", "check", "status", "OK"}}, query("CHECK TABLE a MEDIUM"))
+	require.Equal([]sql.Row{
+		{"mydb.a", "optimize", "status", "OK"},
+		{"mydb.b", "optimize", "status", "OK"},
+	}, query("OPTIMIZE TABLE a, b"))
+
+	// Row policies apply to the rows of checksums
+	catalog.AddRowPolicy("mydb", "a", func(ctx *sql.Context, user string) (sql.Expression, error) {
+		return expression.NewEquals(expression.NewUnresolvedColumn("id"), expression.NewLiteral(int64(1), sql.Int64)), nil
+	})
+	query("DELETE FROM b WHERE id = 2")
+	checksums := query("CHECKSUM TABLE a, b")
+	require.Equal(checksums[0][1], checksums[1][1])
+}
+
+func TestMemoryTableHistograms(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+	explain := func(q string) string {
+		var plan string
+		for _, row := range query("EXPLAIN " + q) {
+			plan += fmt.Sprintln(row[0])
+		}
+		return plan
+	}
+
+	query("CREATE TABLE t (id BIGINT PRIMARY KEY, v INT)")
+	query("CREATE INDEX idx_v ON t (v)")
+	for i := 1; i <= 20; i++ {
+		v := 1
+		if i > 18 {
+			v = i - 17
+		}
+		query(fmt.Sprintf("INSERT INTO t VALUES (%d, %d)", i, v))
+	}
+
+	// Without histograms, every value of v is estimated to have the same number of rows
+	require.Contains(explain("SELECT * FROM t WHERE v = 1"), "Indexed table access")
+
+	require.Equal([]sql.Row{{"mydb.t", "analyze", "status", "OK"}}, query("ANALYZE TABLE t"))
+
+	// Most rows have the first value, so scanning the table costs less than looking them up
+	require.NotContains(explain("SELECT * FROM t WHERE v = 1"), "Indexed table access")
+	require.Contains(explain("SELECT * FROM t WHERE v = 3"), "Indexed table access")
+	require.NotContains(explain("SELECT * FROM t WHERE v < 3"), "Indexed table access")
+	require.Contains(explain("SELECT * FROM t WHERE v > 2"), "Indexed table access")
+	require.Len(query("SELECT * FROM t WHERE v = 1"), 18)
+}
+
+func TestMemoryTableStatisticsRefresh(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("mydb"))
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), &sqle.Config{StatisticsRefreshThreshold: 0.5})
+
+	query := func(q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession())).WithCurrentDB("mydb")
+		_, iter, err := engine.Query(ctx, q)
+		require.NoError(err, q)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err, q)
+		return rows
+	}
+
+	query("CREATE TABLE t (id BIGINT PRIMARY KEY, v INT)")
+	query("INSERT INTO t VALUES (1, 1), (2, 1), (3, 1), (4, 2)")
+	engine.StatisticsRefresher.Wait()
+	require.Equal(uint64(1), engine.StatisticsRefresher.Analyses("mydb", "t"))
+
+	// Rows changed by updates and deletes count too, but not the ones that only matched
+	query("UPDATE t SET v = 2 WHERE id < 3")
+	query("UPDATE t SET v = 2 WHERE id < 3")
+	engine.StatisticsRefresher.Wait()
+	require.Equal(uint64(2), engine.StatisticsRefresher.Changes("mydb", "t"))
+	query("DELETE FROM t WHERE id = 1")
+	engine.StatisticsRefresher.Wait()
+	require.Equal(uint64(2), engine.StatisticsRefresher.Analyses("mydb", "t"))
+	require.Equal(uint64(0), engine.StatisticsRefresher.Changes("mydb", "t"))
+}
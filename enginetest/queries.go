@@ -22,6 +22,8 @@ import (
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
 type QueryTest struct {
@@ -72,6 +74,30 @@ var QueryTests = []QueryTest{
 		"SELECT pk1, SUM(c1) FROM two_pk WHERE pk1 = 0",
 		[]sql.Row{{0, 10.0}},
 	},
+	{
+		"SELECT c1 FROM one_pk WHERE pk = 2",
+		[]sql.Row{{20}},
+	},
+	{
+		"SELECT c1 FROM one_pk WHERE pk = '2'",
+		[]sql.Row{{20}},
+	},
+	{
+		"SELECT c1 FROM one_pk WHERE pk = 2.5",
+		[]sql.Row{},
+	},
+	{
+		"SELECT c1 FROM one_pk WHERE pk = 7",
+		[]sql.Row{},
+	},
+	{
+		"SELECT c1 FROM one_pk WHERE pk = 2 AND c2 > 30",
+		[]sql.Row{},
+	},
+	{
+		"SELECT c1 FROM two_pk WHERE pk2 = 0 AND pk1 = 1",
+		[]sql.Row{{20}},
+	},
 	{
 		"SELECT i FROM mytable;",
 		[]sql.Row{{int64(1)}, {int64(2)}, {int64(3)}},
@@ -300,6 +326,69 @@ var QueryTests = []QueryTest{
 		"SELECT 100 NOT IN (SELECT i2 FROM niltable)",
 		[]sql.Row{{nil}},
 	},
+	{
+		"SELECT i FROM mytable WHERE EXISTS (SELECT * FROM othertable WHERE i2 = i) ORDER BY i",
+		[]sql.Row{{int64(1)}, {int64(2)}, {int64(3)}},
+	},
+	{
+		"SELECT i FROM mytable WHERE NOT EXISTS (SELECT * FROM othertable WHERE i2 = i + 1) ORDER BY i",
+		[]sql.Row{{int64(3)}},
+	},
+	{
+		"SELECT EXISTS (SELECT * FROM emptytable), NOT EXISTS (SELECT * FROM emptytable), EXISTS (SELECT 1 FROM mytable LIMIT 0)",
+		[]sql.Row{{false, true, false}},
+	},
+	{
+		"SELECT i, (SELECT s2 FROM othertable WHERE EXISTS (SELECT * FROM niltable WHERE niltable.i2 = othertable.i2) AND i2 = mytable.i) FROM mytable ORDER BY 1",
+		[]sql.Row{{int64(1), nil}, {int64(2), "second"}, {int64(3), nil}},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM two_pk WHERE pk1 = one_pk.pk AND c1 > 10) ORDER BY pk",
+		[]sql.Row{{int64(1)}},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM two_pk WHERE pk1 = one_pk.pk AND pk1 > 10)",
+		[]sql.Row{},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE NOT EXISTS (SELECT * FROM two_pk WHERE pk1 = one_pk.pk AND pk1 > 10) ORDER BY pk",
+		[]sql.Row{{int64(0)}, {int64(1)}, {int64(2)}, {int64(3)}},
+	},
+	{
+		"SELECT i FROM niltable WHERE NOT EXISTS (SELECT * FROM mytable WHERE mytable.i = niltable.i2) ORDER BY i",
+		[]sql.Row{{int64(1)}, {int64(3)}, {int64(4)}, {int64(5)}, {int64(6)}},
+	},
+	{
+		"SELECT i FROM niltable WHERE EXISTS (SELECT * FROM mytable WHERE mytable.i < niltable.i2) ORDER BY i",
+		[]sql.Row{{int64(2)}, {int64(4)}, {int64(6)}},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM one_pk o WHERE o.pk = one_pk.pk + 1) ORDER BY pk",
+		[]sql.Row{{int64(0)}, {int64(1)}, {int64(2)}},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE pk IN (SELECT pk1 + 1 FROM two_pk WHERE two_pk.pk2 = one_pk.pk - 1) ORDER BY pk",
+		[]sql.Row{{int64(1)}, {int64(2)}},
+	},
+	{
+		"SELECT i FROM niltable WHERE i2 IN (SELECT i FROM mytable WHERE mytable.i <= niltable.i) ORDER BY i",
+		[]sql.Row{{int64(2)}},
+	},
+	{
+		"SELECT a.pk FROM one_pk a JOIN (SELECT pk1, c1 FROM two_pk WHERE pk1 > 10) b ON a.c1 < b.c1",
+		[]sql.Row{},
+	},
+	{
+		"SELECT i, i IN (SELECT i2 FROM niltable), f NOT IN (SELECT i FROM mytable) FROM niltable ORDER BY i",
+		[]sql.Row{
+			{int64(1), nil, nil},
+			{int64(2), true, nil},
+			{int64(3), nil, nil},
+			{int64(4), true, true},
+			{int64(5), nil, true},
+			{int64(6), true, true},
+		},
+	},
 	{
 		"SELECT 1 IN (2,3,4,null)",
 		[]sql.Row{{nil}},
@@ -664,6 +753,10 @@ var QueryTests = []QueryTest{
 	},
 	{
 		"SELECT id FROM typestable WHERE da > '2019-12-31'",
+		nil,
+	},
+	{
+		"SELECT id FROM typestable WHERE da = '2019-12-31'",
 		[]sql.Row{{int64(1)}},
 	},
 	{
@@ -1210,6 +1303,100 @@ var QueryTests = []QueryTest{
 			{int64(1)},
 		},
 	},
+	{
+		`SELECT i % 2 AS i, COUNT(*) FROM mytable GROUP BY 1 ORDER BY 1`,
+		[]sql.Row{
+			{int64(0), int64(1)},
+			{int64(1), int64(2)},
+		},
+	},
+	{
+		`SELECT i % 2 AS x, COUNT(*) AS c FROM mytable GROUP BY 1 HAVING c > 1 ORDER BY 2 DESC`,
+		[]sql.Row{
+			{int64(1), int64(2)},
+		},
+	},
+	{
+		`SELECT COUNT(*) AS i FROM mytable GROUP BY i HAVING i > 1`,
+		[]sql.Row{
+			{int64(1)},
+			{int64(1)},
+		},
+	},
+	{
+		`SELECT i AS x, s AS y FROM mytable WHERE i > 1 GROUP BY x, y HAVING x > 2`,
+		[]sql.Row{
+			{int64(3), "third row"},
+		},
+	},
+	{
+		`SELECT s AS i, -i AS s FROM mytable ORDER BY s`,
+		[]sql.Row{
+			{"third row", int64(-3)},
+			{"second row", int64(-2)},
+			{"first row", int64(-1)},
+		},
+	},
+	{
+		`SELECT COUNT(*) FROM mytable HAVING MAX(i) > 2`,
+		[]sql.Row{
+			{int64(3)},
+		},
+	},
+	{
+		`SELECT COUNT(*) FROM mytable HAVING MAX(i) > 3`,
+		[]sql.Row{},
+	},
+	{
+		`SELECT 1 FROM mytable HAVING COUNT(*) > 2`,
+		[]sql.Row{
+			{int8(1)},
+		},
+	},
+	{
+		`SELECT i FROM mytable HAVING i > 1 ORDER BY i`,
+		[]sql.Row{
+			{int64(2)},
+			{int64(3)},
+		},
+	},
+	{
+		`SELECT s FROM mytable GROUP BY s ORDER BY SUM(i) DESC`,
+		[]sql.Row{
+			{"third row"},
+			{"second row"},
+			{"first row"},
+		},
+	},
+	{
+		`SELECT s, SUM(i) FROM mytable GROUP BY s ORDER BY -SUM(i)`,
+		[]sql.Row{
+			{"third row", float64(3)},
+			{"second row", float64(2)},
+			{"first row", float64(1)},
+		},
+	},
+	{
+		`SELECT s FROM mytable GROUP BY s HAVING SUM(i) > 1 ORDER BY MAX(i) DESC`,
+		[]sql.Row{
+			{"third row"},
+			{"second row"},
+		},
+	},
+	{
+		`SELECT i % 2 AS x FROM mytable GROUP BY x HAVING AVG(i) > 1 ORDER BY SUM(i)`,
+		[]sql.Row{
+			{int64(0)},
+			{int64(1)},
+		},
+	},
+	{
+		`SELECT o.pk, COUNT(*) FROM one_pk o JOIN two_pk t ON o.pk = t.pk1 GROUP BY o.pk HAVING MIN(t.c1) >= 0 ORDER BY SUM(t.c2) DESC, 1`,
+		[]sql.Row{
+			{int8(1), int64(2)},
+			{int8(0), int64(2)},
+		},
+	},
 	{
 		`SELECT CONCAT("a", "b", "c")`,
 		[]sql.Row{
@@ -1383,23 +1570,55 @@ var QueryTests = []QueryTest{
 	{
 		`SHOW VARIABLES`,
 		[]sql.Row{
-			{"autocommit", int64(0)},
+			{"admission_queue_timeout", int64(0)},
 			{"auto_increment_increment", int64(1)},
-			{"time_zone", "SYSTEM"},
-			{"system_time_zone", time.Now().UTC().Location().String()},
-			{"max_allowed_packet", math.MaxInt32},
-			{"sql_mode", ""},
-			{"gtid_mode", int32(0)},
-			{"collation_database", "utf8mb4_0900_ai_ci"},
-			{"ndbinfo_version", ""},
-			{"sql_select_limit", math.MaxInt32},
-			{"transaction_isolation", "READ UNCOMMITTED"},
-			{"version", ""},
-			{"version_comment", ""},
+			{"autocommit", int64(0)},
 			{"character_set_client", sql.Collation_Default.CharacterSet().String()},
 			{"character_set_connection", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_database", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_filesystem", "binary"},
 			{"character_set_results", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_server", sql.Collation_Default.CharacterSet().String()},
+			{"character_set_system", "utf8"},
 			{"collation_connection", sql.Collation_Default.String()},
+			{"collation_database", "utf8mb4_0900_ai_ci"},
+			{"collation_server", "utf8mb4_0900_ai_ci"},
+			{"default_storage_engine", "InnoDB"},
+			{"foreign_key_checks", int8(1)},
+			{"general_log", int8(0)},
+			{"gtid_mode", int32(0)},
+			{"init_connect", ""},
+			{"innodb_lock_wait_timeout", int64(50)},
+			{"interactive_timeout", int64(28800)},
+			{"license", "GPL"},
+			{"lock_wait_timeout", int64(31536000)},
+			{"low_priority_updates", int8(0)},
+			{"lower_case_table_names", int32(2)},
+			{"max_allowed_packet", math.MaxInt32},
+			{"max_concurrent_queries", int64(0)},
+			{"max_digest_concurrent_queries", int64(0)},
+			{"max_user_concurrent_queries", int64(0)},
+			{"ndbinfo_version", ""},
+			{"net_buffer_length", int64(16384)},
+			{"net_read_timeout", int64(30)},
+			{"net_write_timeout", int64(60)},
+			{"performance_schema", int8(0)},
+			{"query_cache_size", int64(1048576)},
+			{"query_cache_type", "DEMAND"},
+			{"read_only", int8(0)},
+			{"sql_auto_is_null", int8(0)},
+			{"sql_mode", "STRICT_TRANS_TABLES"},
+			{"sql_select_limit", math.MaxInt32},
+			{"super_read_only", int8(0)},
+			{"system_time_zone", time.Now().UTC().Location().String()},
+			{"time_zone", "SYSTEM"},
+			{"transaction_isolation", "READ-UNCOMMITTED"},
+			{"transaction_read_only", int8(0)},
+			{"tx_isolation", "READ-UNCOMMITTED"},
+			{"tx_read_only", int8(0)},
+			{"version", "8.0.11"},
+			{"version_comment", ""},
+			{"wait_timeout", int64(28800)},
 		},
 	},
 	{
@@ -1417,7 +1636,7 @@ var QueryTests = []QueryTest{
 	{
 		`SHOW GLOBAL VARIABLES LIKE '%mode`,
 		[]sql.Row{
-			{"sql_mode", ""},
+			{"sql_mode", "STRICT_TRANS_TABLES"},
 			{"gtid_mode", int32(0)},
 		},
 	},
@@ -1655,33 +1874,52 @@ var QueryTests = []QueryTest{
 	{
 		`SHOW COLLATION`,
 		[]sql.Row{
-			{
-				sql.Collation_binary.String(),
-				"binary",
-				sql.CollationToMySQLVals[sql.Collation_binary].ID,
-				sql.CollationToMySQLVals[sql.Collation_binary].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_binary].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_binary].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_binary].PadSpace,
-			},
-			{
-				sql.Collation_utf8_general_ci.String(),
-				"utf8",
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].PadSpace,
-			},
-			{
-				sql.Collation_utf8mb4_0900_ai_ci.String(),
-				"utf8mb4",
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].PadSpace,
-			},
+			{"armscii8_general_ci", "armscii8", int64(32), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"ascii_general_ci", "ascii", int64(11), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"big5_chinese_ci", "big5", int64(1), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"binary", "binary", int64(63), "Yes", "Yes", int64(0), "NO PAD"},
+			{"cp1250_general_ci", "cp1250", int64(26), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1251_general_ci", "cp1251", int64(51), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1256_general_ci", "cp1256", int64(57), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1257_general_ci", "cp1257", int64(59), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp850_general_ci", "cp850", int64(4), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp852_general_ci", "cp852", int64(40), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp866_general_ci", "cp866", int64(36), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp932_japanese_ci", "cp932", int64(95), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"dec8_swedish_ci", "dec8", int64(3), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"eucjpms_japanese_ci", "eucjpms", int64(97), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"euckr_korean_ci", "euckr", int64(19), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"gb18030_chinese_ci", "gb18030", int64(248), "Yes", "Yes", int64(2), "PAD SPACE"},
+			{"gb2312_chinese_ci", "gb2312", int64(24), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"gbk_chinese_ci", "gbk", int64(28), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"geostd8_general_ci", "geostd8", int64(92), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"greek_general_ci", "greek", int64(25), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"hebrew_general_ci", "hebrew", int64(16), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"hp8_english_ci", "hp8", int64(6), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"keybcs2_general_ci", "keybcs2", int64(37), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"koi8r_general_ci", "koi8r", int64(7), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"koi8u_general_ci", "koi8u", int64(22), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin1_bin", "latin1", int64(47), "", "Yes", int64(1), "PAD SPACE"},
+			{"latin1_swedish_ci", "latin1", int64(8), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin2_general_ci", "latin2", int64(9), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin5_turkish_ci", "latin5", int64(30), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin7_general_ci", "latin7", int64(41), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"macce_general_ci", "macce", int64(38), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"macroman_general_ci", "macroman", int64(39), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"sjis_japanese_ci", "sjis", int64(13), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"swe7_swedish_ci", "swe7", int64(10), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"tis620_thai_ci", "tis620", int64(18), "Yes", "Yes", int64(4), "PAD SPACE"},
+			{"ucs2_general_ci", "ucs2", int64(35), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"ujis_japanese_ci", "ujis", int64(12), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf16_general_ci", "utf16", int64(54), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf16le_general_ci", "utf16le", int64(56), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf32_general_ci", "utf32", int64(60), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb3_bin", "utf8mb3", int64(83), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb3_general_ci", "utf8mb3", int64(33), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_0900_ai_ci", "utf8mb4", int64(255), "Yes", "Yes", int64(0), "NO PAD"},
+			{"utf8mb4_bin", "utf8mb4", int64(46), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_general_ci", "utf8mb4", int64(45), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_unicode_ci", "utf8mb4", int64(224), "", "Yes", int64(8), "PAD SPACE"},
 		},
 	},
 	{
@@ -1691,24 +1929,12 @@ var QueryTests = []QueryTest{
 	{
 		`SHOW COLLATION LIKE 'utf8%'`,
 		[]sql.Row{
-			{
-				sql.Collation_utf8_general_ci.String(),
-				"utf8",
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].PadSpace,
-			},
-			{
-				sql.Collation_utf8mb4_0900_ai_ci.String(),
-				"utf8mb4",
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].PadSpace,
-			},
+			{"utf8mb3_bin", "utf8mb3", int64(83), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb3_general_ci", "utf8mb3", int64(33), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_0900_ai_ci", "utf8mb4", int64(255), "Yes", "Yes", int64(0), "NO PAD"},
+			{"utf8mb4_bin", "utf8mb4", int64(46), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_general_ci", "utf8mb4", int64(45), "", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_unicode_ci", "utf8mb4", int64(224), "", "Yes", int64(8), "PAD SPACE"},
 		},
 	},
 	{
@@ -1718,33 +1944,47 @@ var QueryTests = []QueryTest{
 	{
 		"SHOW COLLATION WHERE `Default` = 'Yes'",
 		[]sql.Row{
-			{
-				sql.Collation_binary.String(),
-				"binary",
-				sql.CollationToMySQLVals[sql.Collation_binary].ID,
-				sql.CollationToMySQLVals[sql.Collation_binary].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_binary].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_binary].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_binary].PadSpace,
-			},
-			{
-				sql.Collation_utf8_general_ci.String(),
-				"utf8",
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8_general_ci].PadSpace,
-			},
-			{
-				sql.Collation_utf8mb4_0900_ai_ci.String(),
-				"utf8mb4",
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].ID,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsDefault,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].IsCompiled,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].SortLen,
-				sql.CollationToMySQLVals[sql.Collation_utf8mb4_0900_ai_ci].PadSpace,
-			},
+			{"armscii8_general_ci", "armscii8", int64(32), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"ascii_general_ci", "ascii", int64(11), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"big5_chinese_ci", "big5", int64(1), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"binary", "binary", int64(63), "Yes", "Yes", int64(0), "NO PAD"},
+			{"cp1250_general_ci", "cp1250", int64(26), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1251_general_ci", "cp1251", int64(51), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1256_general_ci", "cp1256", int64(57), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp1257_general_ci", "cp1257", int64(59), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp850_general_ci", "cp850", int64(4), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp852_general_ci", "cp852", int64(40), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp866_general_ci", "cp866", int64(36), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"cp932_japanese_ci", "cp932", int64(95), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"dec8_swedish_ci", "dec8", int64(3), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"eucjpms_japanese_ci", "eucjpms", int64(97), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"euckr_korean_ci", "euckr", int64(19), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"gb18030_chinese_ci", "gb18030", int64(248), "Yes", "Yes", int64(2), "PAD SPACE"},
+			{"gb2312_chinese_ci", "gb2312", int64(24), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"gbk_chinese_ci", "gbk", int64(28), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"geostd8_general_ci", "geostd8", int64(92), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"greek_general_ci", "greek", int64(25), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"hebrew_general_ci", "hebrew", int64(16), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"hp8_english_ci", "hp8", int64(6), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"keybcs2_general_ci", "keybcs2", int64(37), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"koi8r_general_ci", "koi8r", int64(7), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"koi8u_general_ci", "koi8u", int64(22), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin1_swedish_ci", "latin1", int64(8), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin2_general_ci", "latin2", int64(9), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin5_turkish_ci", "latin5", int64(30), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"latin7_general_ci", "latin7", int64(41), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"macce_general_ci", "macce", int64(38), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"macroman_general_ci", "macroman", int64(39), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"sjis_japanese_ci", "sjis", int64(13), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"swe7_swedish_ci", "swe7", int64(10), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"tis620_thai_ci", "tis620", int64(18), "Yes", "Yes", int64(4), "PAD SPACE"},
+			{"ucs2_general_ci", "ucs2", int64(35), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"ujis_japanese_ci", "ujis", int64(12), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf16_general_ci", "utf16", int64(54), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf16le_general_ci", "utf16le", int64(56), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf32_general_ci", "utf32", int64(60), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb3_general_ci", "utf8mb3", int64(33), "Yes", "Yes", int64(1), "PAD SPACE"},
+			{"utf8mb4_0900_ai_ci", "utf8mb4", int64(255), "Yes", "Yes", int64(0), "NO PAD"},
 		},
 	},
 	{
@@ -1979,6 +2219,18 @@ var QueryTests = []QueryTest{
 			{int64(3), nil, nil},
 		},
 	},
+	{
+		"SELECT i, i2, s2 FROM mytable LEFT JOIN othertable ON i = i2 AND s2 <> 'second'",
+		[]sql.Row{
+			{int64(1), int64(1), "third"},
+			{int64(2), nil, nil},
+			{int64(3), int64(3), "first"},
+		},
+	},
+	{
+		"SELECT i, i2 FROM mytable INNER JOIN othertable ON i2 = i + 0.5",
+		[]sql.Row{},
+	},
 	{
 		"SELECT i, i2, s2 FROM mytable RIGHT JOIN othertable ON i = i2 - 1",
 		[]sql.Row{
@@ -2767,6 +3019,44 @@ var QueryTests = []QueryTest{
 			{14, 1, 1},
 		},
 	},
+	{
+		"SELECT a.pk, b.i2, c.i FROM one_pk a JOIN othertable b ON a.pk = b.i2 JOIN mytable c ON b.i2 = c.i ORDER BY 1",
+		[]sql.Row{
+			{1, 1, 1},
+			{2, 2, 2},
+			{3, 3, 3},
+		},
+	},
+	{
+		"SELECT m.i, n.i2, o.pk FROM mytable m JOIN niltable n ON m.i = n.i2 JOIN one_pk o ON o.pk = m.i",
+		[]sql.Row{
+			{2, 2, 2},
+		},
+	},
+	{
+		"SELECT a.pk, b.i, c.i FROM one_pk a LEFT JOIN niltable b ON a.pk = b.i LEFT JOIN mytable c ON b.i = c.i ORDER BY 1",
+		[]sql.Row{
+			{0, nil, nil},
+			{1, 1, 1},
+			{2, 2, 2},
+			{3, 3, 3},
+		},
+	},
+	{
+		"SELECT t1.pk, t2.pk1, t2.pk2, t3.i FROM one_pk t1, two_pk t2 JOIN mytable t3 ON t3.i = t2.pk1 + 1 WHERE t1.pk = t2.pk2 ORDER BY 1, 2",
+		[]sql.Row{
+			{0, 0, 0, 1},
+			{0, 1, 0, 2},
+			{1, 0, 1, 1},
+			{1, 1, 1, 2},
+		},
+	},
+	{
+		"SELECT t1.pk, t2.pk1, t3.i FROM one_pk t1 CROSS JOIN two_pk t2 JOIN mytable t3 ON t3.i = t2.pk1 AND t2.pk1 = t1.pk AND t2.pk2 = t1.pk",
+		[]sql.Row{
+			{1, 1, 1},
+		},
+	},
 	{
 		"SELECT opk.c5,pk1,pk2 FROM one_pk opk JOIN two_pk tpk ON pk=pk1 ORDER BY 1,2,3",
 		[]sql.Row{
@@ -2889,6 +3179,100 @@ var QueryTests = []QueryTest{
 			{3, nil, nil},
 		},
 	},
+	{
+		"SELECT pk,pk1,pk2,two_pk.c1 FROM one_pk LEFT JOIN two_pk ON pk=pk1 AND two_pk.c1 > 10 ORDER BY 1,2,3",
+		[]sql.Row{
+			{0, nil, nil, nil},
+			{1, 1, 0, 20},
+			{1, 1, 1, 30},
+			{2, nil, nil, nil},
+			{3, nil, nil, nil},
+		},
+	},
+	{
+		"SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON one_pk.pk < two_pk.pk1 ORDER BY 1,2,3",
+		[]sql.Row{
+			{0, 1, 0},
+			{0, 1, 1},
+		},
+	},
+	{
+		"SELECT pk1,pk2,pk FROM two_pk LEFT JOIN one_pk ON one_pk.pk BETWEEN two_pk.pk1 AND two_pk.pk2 ORDER BY 1,2,3",
+		[]sql.Row{
+			{0, 0, 0},
+			{0, 1, 0},
+			{0, 1, 1},
+			{1, 0, nil},
+			{1, 1, 1},
+		},
+	},
+	{
+		"SELECT a.pk,b.pk FROM one_pk a JOIN one_pk b ON b.pk > a.pk AND b.pk <= a.pk + 1 ORDER BY 1,2",
+		[]sql.Row{
+			{0, 1},
+			{1, 2},
+			{2, 3},
+		},
+	},
+	{
+		"SELECT i,i2,pk FROM niltable LEFT JOIN one_pk ON one_pk.pk <= niltable.i2 - 3 ORDER BY 1,3",
+		[]sql.Row{
+			{int64(1), nil, nil},
+			{int64(2), int64(2), nil},
+			{int64(3), nil, nil},
+			{int64(4), int64(4), 0},
+			{int64(4), int64(4), 1},
+			{int64(5), nil, nil},
+			{int64(6), int64(6), 0},
+			{int64(6), int64(6), 1},
+			{int64(6), int64(6), 2},
+			{int64(6), int64(6), 3},
+		},
+	},
+	{
+		"SELECT i, i2 FROM niltable ORDER BY i2 DESC, i",
+		[]sql.Row{
+			{int64(6), int64(6)},
+			{int64(4), int64(4)},
+			{int64(2), int64(2)},
+			{int64(1), nil},
+			{int64(3), nil},
+			{int64(5), nil},
+		},
+	},
+	{
+		"SELECT i, i2 FROM niltable ORDER BY i2 IS NULL, i2, i",
+		[]sql.Row{
+			{int64(2), int64(2)},
+			{int64(4), int64(4)},
+			{int64(6), int64(6)},
+			{int64(1), nil},
+			{int64(3), nil},
+			{int64(5), nil},
+		},
+	},
+	{
+		"SELECT i, i2 FROM niltable ORDER BY i2 IS NULL DESC, i2 DESC, i",
+		[]sql.Row{
+			{int64(1), nil},
+			{int64(3), nil},
+			{int64(5), nil},
+			{int64(6), int64(6)},
+			{int64(4), int64(4)},
+			{int64(2), int64(2)},
+		},
+	},
+	{
+		"SELECT i, f FROM niltable ORDER BY f IS NOT NULL, f DESC, i",
+		[]sql.Row{
+			{int64(1), nil},
+			{int64(2), nil},
+			{int64(3), nil},
+			{int64(6), float64(6)},
+			{int64(5), float64(5)},
+			{int64(4), float64(4)},
+		},
+	},
 	{
 		"SELECT pk,i2,f FROM one_pk LEFT JOIN niltable ON pk=i2 ORDER BY 1",
 		[]sql.Row{
@@ -3033,6 +3417,33 @@ var QueryTests = []QueryTest{
 			{1, 0, 1, 10, 10},
 		},
 	},
+	{
+		"SELECT pk,pk1,pk2 FROM one_pk RIGHT JOIN two_pk ON one_pk.c1=two_pk.c1 AND one_pk.c1 < 20 ORDER BY 1,2,3",
+		[]sql.Row{
+			{nil, 1, 0},
+			{nil, 1, 1},
+			{0, 0, 0},
+			{1, 0, 1},
+		},
+	},
+	{
+		"SELECT a.pk,b.pk,c.pk1,c.pk2 FROM one_pk a JOIN one_pk b ON a.pk=b.pk JOIN two_pk c ON b.c1=c.c1 ORDER BY 1,2,3,4",
+		[]sql.Row{
+			{0, 0, 0, 0},
+			{1, 1, 0, 1},
+			{2, 2, 1, 0},
+			{3, 3, 1, 1},
+		},
+	},
+	{
+		"SELECT a.pk,sq.pk1,sq.pk2 FROM one_pk a JOIN (SELECT pk1,pk2,c1 FROM two_pk) sq ON a.c1=sq.c1 ORDER BY 1,2,3",
+		[]sql.Row{
+			{0, 0, 0},
+			{1, 0, 1},
+			{2, 1, 0},
+			{3, 1, 1},
+		},
+	},
 	{
 		"SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON pk1-pk>0 AND pk2<1",
 		[]sql.Row{
@@ -3131,6 +3542,212 @@ var QueryTests = []QueryTest{
 	{"SELECT POW(2,3) FROM dual",
 		[]sql.Row{{float64(8)}},
 	},
+	{
+		"SELECT 1 + 1 AS x FROM DUAL WHERE 1 = 1 ORDER BY x",
+		[]sql.Row{{int64(2)}},
+	},
+	{
+		"SELECT 1 FROM DUAL WHERE 1 = 0",
+		[]sql.Row{},
+	},
+	{
+		"SELECT 'a' WHERE 1 = 1",
+		[]sql.Row{{"a"}},
+	},
+	{
+		"SELECT 'a' WHERE 1 = 0",
+		[]sql.Row{},
+	},
+	{
+		"SELECT COUNT(*) FROM dual",
+		[]sql.Row{{int64(1)}},
+	},
+	{
+		"SELECT 1 FROM dual WHERE EXISTS (SELECT i FROM mytable)",
+		[]sql.Row{{int8(1)}},
+	},
+	{
+		"SELECT 0x41, X'4142', b'1000011'",
+		[]sql.Row{{sql.BinaryLiteral("A"), sql.BinaryLiteral("AB"), sql.BinaryLiteral("C")}},
+	},
+	{
+		"SELECT 0x41 + 0, b'101' + 0, -0x10, 0x41 | 2, CAST(0x0100 AS UNSIGNED)",
+		[]sql.Row{{int64(65), int64(5), int64(-16), int64(67), uint64(256)}},
+	},
+	{
+		"SELECT 0x41 = 'A', X'4142' = 'AB', 0x10 = 16, CONCAT(0x41, 'b'), HEX(b'1111')",
+		[]sql.Row{{true, true, true, "Ab", "0F"}},
+	},
+	{
+		"SELECT i FROM mytable WHERE i = 0x02",
+		[]sql.Row{{int64(2)}},
+	},
+	{
+		"SELECT 1e3, 2.5E-1, 1.5e+2",
+		[]sql.Row{{float64(1000), float64(0.25), float64(150)}},
+	},
+	{
+		"SELECT 18446744073709551615, 18446744073709551616, -9223372036854775809",
+		[]sql.Row{{uint64(18446744073709551615), "18446744073709551616", "-9223372036854775809"}},
+	},
+	{
+		"SELECT 18446744073709551616 > 18446744073709551615, -18446744073709551616",
+		[]sql.Row{{true, "-18446744073709551616"}},
+	},
+	{
+		"SELECT @@version_comment LIMIT 1",
+		[]sql.Row{{""}},
+	},
+	{
+		"SELECT * FROM numbers(3)",
+		[]sql.Row{{int64(0)}, {int64(1)}, {int64(2)}},
+	},
+	{
+		"SELECT n.number FROM numbers(2, ABS(-2)) AS n WHERE n.number > 2",
+		[]sql.Row{{int64(3)}},
+	},
+	{
+		"SELECT i, s.value FROM mytable JOIN string_split('first,second', ',') s ON i = s.ordinal ORDER BY i",
+		[]sql.Row{{int64(1), "first"}, {int64(2), "second"}},
+	},
+	{
+		"SELECT i FROM mytable WHERE i IN (SELECT number FROM numbers(1, 2)) ORDER BY i",
+		[]sql.Row{{int64(1)}, {int64(2)}},
+	},
+	{
+		"SELECT /*+ JOIN_ORDER(two_pk, one_pk) */ pk, pk1, pk2 FROM one_pk JOIN two_pk ON pk = pk1 ORDER BY 1, 2, 3",
+		[]sql.Row{{0, 0, 0}, {0, 0, 1}, {1, 1, 0}, {1, 1, 1}},
+	},
+	{
+		"SELECT /*+ HASH_JOIN(two_pk) */ pk, pk1, pk2 FROM one_pk JOIN two_pk ON pk = pk1 AND pk2 = 1 ORDER BY 1",
+		[]sql.Row{{0, 0, 1}, {1, 1, 1}},
+	},
+	{
+		"SELECT /*+ HASH_JOIN(niltable) */ pk, i FROM one_pk LEFT JOIN niltable ON pk = i ORDER BY 1",
+		[]sql.Row{{0, nil}, {1, 1}, {2, 2}, {3, 3}},
+	},
+	{
+		"SELECT /*+ NO_HASH_JOIN(niltable) */ pk, i2 FROM one_pk LEFT JOIN niltable ON pk = i2 ORDER BY 1",
+		[]sql.Row{{0, nil}, {1, nil}, {2, 2}, {3, nil}},
+	},
+	{
+		"SELECT /*+ JOIN_ORDER(c, b, a) */ a.pk, c.i FROM one_pk a JOIN two_pk b ON a.pk = b.pk1 JOIN niltable c ON c.i = b.pk2 ORDER BY 1",
+		[]sql.Row{{0, 1}, {1, 1}},
+	},
+	{
+		"SELECT /*+ NO_INDEX(one_pk) */ pk, c1 FROM one_pk WHERE pk = 1",
+		[]sql.Row{{1, 10}},
+	},
+	{
+		"SELECT /*+ MERGE(dt) */ pk1, dt.c1 FROM two_pk JOIN (SELECT pk, c1 FROM one_pk WHERE c1 > 0) dt ON dt.pk = two_pk.pk1 ORDER BY 1",
+		[]sql.Row{{1, 10}, {1, 10}},
+	},
+	{
+		"SELECT /*+ MERGE(dt) */ pk, c1 FROM (SELECT * FROM one_pk o WHERE o.c1 > 0) dt WHERE dt.pk > 1 ORDER BY 1",
+		[]sql.Row{{2, 20}, {3, 30}},
+	},
+	{
+		"SELECT dt.pk, two_pk.pk2 FROM (SELECT pk, c1 FROM one_pk) dt JOIN two_pk ON dt.pk = two_pk.pk1 WHERE two_pk.pk1 = 1 ORDER BY 2",
+		[]sql.Row{{1, 0}, {1, 1}},
+	},
+	{
+		"SELECT dt.pk1, dt.n FROM (SELECT pk1, COUNT(*) AS n FROM two_pk GROUP BY pk1) dt WHERE dt.pk1 = 0",
+		[]sql.Row{{0, 2}},
+	},
+	{
+		"SELECT pk FROM (SELECT pk FROM one_pk ORDER BY pk LIMIT 1) dt WHERE dt.pk = 1",
+		[]sql.Row{},
+	},
+	{
+		"SELECT pk, n FROM (SELECT pk, ROW_NUMBER() OVER (ORDER BY pk) AS n FROM one_pk) dt WHERE dt.pk = 2",
+		[]sql.Row{{2, 3}},
+	},
+	{
+		"SELECT pk FROM one_pk WHERE pk = 1 AND EXISTS (SELECT * FROM two_pk WHERE two_pk.pk1 = one_pk.pk)",
+		[]sql.Row{{1}},
+	},
+	{
+		"SELECT /*+ MAX_EXECUTION_TIME(60000) INDEX(mytable) */ i FROM mytable WHERE i > 1 ORDER BY 1",
+		[]sql.Row{{int64(2)}, {int64(3)}},
+	},
+	{
+		"WITH cte AS (SELECT i, s FROM mytable WHERE i > 1) SELECT * FROM cte ORDER BY i",
+		[]sql.Row{{int64(2), "second row"}, {int64(3), "third row"}},
+	},
+	{
+		"with cte (a, b) as (select pk, c1 from one_pk) select b, a from cte where a < 2 order by 1",
+		[]sql.Row{{0, 0}, {10, 1}},
+	},
+	{
+		"WITH a AS (SELECT pk FROM one_pk WHERE pk > 0), b AS (SELECT pk FROM a WHERE pk < 3) SELECT a.pk, b.pk FROM a LEFT JOIN b ON a.pk = b.pk ORDER BY 1",
+		[]sql.Row{{1, 1}, {2, 2}, {3, nil}},
+	},
+	{
+		"WITH cte AS (SELECT i FROM mytable) SELECT x.i, y.i FROM cte x JOIN cte y ON x.i = y.i + 1 ORDER BY 1",
+		[]sql.Row{{int64(2), int64(1)}, {int64(3), int64(2)}},
+	},
+	{
+		"WITH mytable AS (SELECT 10 AS i) SELECT * FROM mytable",
+		[]sql.Row{{int8(10)}},
+	},
+	{
+		"WITH cte AS (SELECT i FROM mytable WHERE i > 2) SELECT s FROM mytable WHERE i IN (SELECT i FROM cte) ORDER BY 1",
+		[]sql.Row{{"third row"}},
+	},
+	{
+		"WITH cte AS (SELECT i FROM mytable) SELECT i, (SELECT MAX(i) FROM cte WHERE cte.i < mytable.i) FROM mytable ORDER BY 1",
+		[]sql.Row{{int64(1), nil}, {int64(2), int64(1)}, {int64(3), int64(2)}},
+	},
+	{
+		"WITH cte AS (WITH inner_cte AS (SELECT COUNT(*) AS n FROM mytable) SELECT n + 1 AS m FROM inner_cte) SELECT m FROM cte",
+		[]sql.Row{{int64(4)}},
+	},
+	{
+		"WITH cte AS (SELECT pk FROM one_pk) SELECT pk FROM (SELECT pk FROM cte WHERE pk > 1) dt ORDER BY 1",
+		[]sql.Row{{2}, {3}},
+	},
+	{
+		"WITH cte AS (SELECT i FROM mytable WHERE i = 1) SELECT i FROM cte UNION SELECT i + 10 FROM cte",
+		[]sql.Row{{int64(1)}, {int64(11)}},
+	},
+	{
+		"SELECT pk1, pk2, ROW_NUMBER() OVER (PARTITION BY pk1 ORDER BY pk2 DESC) AS n FROM two_pk ORDER BY 1, 2",
+		[]sql.Row{{0, 0, int64(2)}, {0, 1, int64(1)}, {1, 0, int64(2)}, {1, 1, int64(1)}},
+	},
+	{
+		"SELECT pk1, pk2, RANK() OVER (ORDER BY pk2), DENSE_RANK() OVER (ORDER BY pk2) FROM two_pk ORDER BY 1, 2",
+		[]sql.Row{
+			{0, 0, int64(1), int64(1)},
+			{0, 1, int64(3), int64(2)},
+			{1, 0, int64(1), int64(1)},
+			{1, 1, int64(3), int64(2)},
+		},
+	},
+	{
+		"SELECT pk1, c1, SUM(c1) OVER (PARTITION BY pk1), SUM(c1) OVER (ORDER BY pk1, pk2) AS running FROM two_pk ORDER BY running",
+		[]sql.Row{
+			{0, 0, float64(10), float64(0)},
+			{0, 10, float64(10), float64(10)},
+			{1, 20, float64(50), float64(30)},
+			{1, 30, float64(50), float64(60)},
+		},
+	},
+	{
+		"SELECT i, COUNT(*) OVER (), AVG(i) OVER (ORDER BY i DESC) FROM mytable ORDER BY i",
+		[]sql.Row{{int64(1), int64(3), float64(2)}, {int64(2), int64(3), float64(2.5)}, {int64(3), int64(3), float64(3)}},
+	},
+	{
+		"SELECT i, ROW_NUMBER() OVER (ORDER BY i) + 10 AS n FROM mytable WHERE i > 1 ORDER BY n DESC",
+		[]sql.Row{{int64(3), int64(12)}, {int64(2), int64(11)}},
+	},
+	{
+		"SELECT i, n FROM (SELECT i, ROW_NUMBER() OVER (ORDER BY i DESC) AS n FROM mytable) t WHERE i > 1 ORDER BY i",
+		[]sql.Row{{int64(2), int64(2)}, {int64(3), int64(1)}},
+	},
+	{
+		"WITH totals AS (SELECT pk1, SUM(c1) AS total FROM two_pk GROUP BY pk1) SELECT pk1, RANK() OVER (ORDER BY total DESC) FROM totals ORDER BY 1",
+		[]sql.Row{{0, int64(2)}, {1, int64(1)}},
+	},
 }
 
 // Queries that are known to be broken in the engine.
@@ -3315,41 +3932,41 @@ var InfoSchemaQueries = []QueryTest{
 	{
 		`SHOW COLUMNS FROM mytable`,
 		[]sql.Row{
-			{"i", "bigint", "NO", "PRI", "", ""},
-			{"s", "varchar(20)", "NO", "UNI", "", ""},
+			{"i", "bigint(20)", "NO", "PRI", nil, ""},
+			{"s", "varchar(20)", "NO", "UNI", nil, ""},
 		},
 	},
 	{
 		`DESCRIBE mytable`,
 		[]sql.Row{
-			{"i", "bigint", "NO", "PRI", "", ""},
-			{"s", "varchar(20)", "NO", "UNI", "", ""},
+			{"i", "bigint(20)", "NO", "PRI", nil, ""},
+			{"s", "varchar(20)", "NO", "UNI", nil, ""},
 		},
 	},
 	{
 		`DESC mytable`,
 		[]sql.Row{
-			{"i", "bigint", "NO", "PRI", "", ""},
-			{"s", "varchar(20)", "NO", "UNI", "", ""},
+			{"i", "bigint(20)", "NO", "PRI", nil, ""},
+			{"s", "varchar(20)", "NO", "UNI", nil, ""},
 		},
 	},
 	{
 		`SHOW COLUMNS FROM mytable WHERE Field = 'i'`,
 		[]sql.Row{
-			{"i", "bigint", "NO", "PRI", "", ""},
+			{"i", "bigint(20)", "NO", "PRI", nil, ""},
 		},
 	},
 	{
 		`SHOW COLUMNS FROM mytable LIKE 'i'`,
 		[]sql.Row{
-			{"i", "bigint", "NO", "PRI", "", ""},
+			{"i", "bigint(20)", "NO", "PRI", nil, ""},
 		},
 	},
 	{
 		`SHOW FULL COLUMNS FROM mytable`,
 		[]sql.Row{
-			{"i", "bigint", nil, "NO", "PRI", "", "", "", ""},
-			{"s", "varchar(20)", "utf8mb4_0900_ai_ci", "NO", "UNI", "", "", "", "column s"},
+			{"i", "bigint(20)", nil, "NO", "PRI", nil, "", "", ""},
+			{"s", "varchar(20)", "utf8mb4_0900_ai_ci", "NO", "UNI", nil, "", "", "column s"},
 		},
 	},
 	{
@@ -3568,6 +4185,30 @@ type QueryErrorTest struct {
 }
 
 var errorQueries = []QueryErrorTest{
+	{
+		Query:       "SELECT * FROM dual",
+		ExpectedErr: sql.ErrNoTablesUsed,
+	},
+	{
+		Query:       "SELECT *",
+		ExpectedErr: sql.ErrNoTablesUsed,
+	},
+	{
+		Query:       "SELECT dummy FROM dual",
+		ExpectedErr: sql.ErrColumnNotFound,
+	},
+	{
+		Query:       "SELECT i FROM mytable GROUP BY 2",
+		ExpectedErr: parse.ErrGroupByColumnIndex,
+	},
+	{
+		Query:       "SELECT i, COUNT(*) FROM mytable GROUP BY 2",
+		ExpectedErr: parse.ErrGroupByAggregate,
+	},
+	{
+		Query:       "SELECT COUNT(*) FROM mytable ORDER BY MAX(x)",
+		ExpectedErr: sql.ErrColumnNotFound,
+	},
 	{
 		Query:       "select foo.i from mytable as a",
 		ExpectedErr: sql.ErrTableNotFound,
@@ -3668,6 +4309,46 @@ var errorQueries = []QueryErrorTest{
 		Query:       "SELECT i FROM myhistorytable AS OF MAX(abc)",
 		ExpectedErr: sql.ErrInvalidAsOfExpression,
 	},
+	{
+		Query:       "SELECT * FROM nonexistent(1)",
+		ExpectedErr: sql.ErrTableFunctionNotFound,
+	},
+	{
+		Query:       "SELECT * FROM numbers(i)",
+		ExpectedErr: sql.ErrInvalidTableFunctionArgument,
+	},
+	{
+		Query:       "WITH cte (a) AS (SELECT i, s FROM mytable) SELECT * FROM cte",
+		ExpectedErr: sql.ErrColumnNamesCount,
+	},
+	{
+		Query:       "WITH cte AS (SELECT 1), cte AS (SELECT 2) SELECT * FROM cte",
+		ExpectedErr: sql.ErrDuplicateAliasOrTable,
+	},
+	{
+		Query:       "WITH a AS (SELECT * FROM b), b AS (SELECT 1) SELECT * FROM a",
+		ExpectedErr: sql.ErrTableNotFound,
+	},
+	{
+		Query:       "SELECT i FROM mytable WHERE ROW_NUMBER() OVER (ORDER BY i) > 1",
+		ExpectedErr: plan.ErrInvalidWindowFunctionUse,
+	},
+	{
+		Query:       "SELECT i FROM mytable ORDER BY RANK() OVER (ORDER BY s)",
+		ExpectedErr: plan.ErrInvalidWindowFunctionUse,
+	},
+	{
+		Query:       "SELECT SUM(ROW_NUMBER() OVER ()) OVER () FROM mytable",
+		ExpectedErr: plan.ErrInvalidWindowFunctionUse,
+	},
+	{
+		Query:       "SELECT i, SUM(i) OVER (ORDER BY i ROWS UNBOUNDED PRECEDING) FROM mytable",
+		ExpectedErr: parse.ErrUnsupportedFeature,
+	},
+	{
+		Query:       "SELECT s, COUNT(*), RANK() OVER (ORDER BY s) FROM mytable GROUP BY s",
+		ExpectedErr: parse.ErrUnsupportedFeature,
+	},
 	// TODO: Bug: the having column must appear in the select list
 	// {
 	// 	Query:       "SELECT pk1, sum(c1) FROM two_pk GROUP BY 1 having c1 > 10;",
@@ -25,9 +25,10 @@ type QueryPlanTest struct {
 var PlanTests = []QueryPlanTest{
 	{
 		Query: "SELECT i, i2, s2 FROM mytable INNER JOIN othertable ON i = i2",
-		ExpectedPlan: "Project(mytable.i, othertable.i2, othertable.s2)\n" +
-			" └─ IndexedJoin(mytable.i = othertable.i2)\n" +
-			"     ├─ Table(mytable)\n" +
+		ExpectedPlan: "IndexedJoin(mytable.i = othertable.i2)\n" +
+			" ├─ Projected table access on [i]\n" +
+			" │   └─ Table(mytable)\n" +
+			" └─ Projected table access on [i2 s2]\n" +
 			"     └─ Table(othertable)\n" +
 			"",
 	},
@@ -35,31 +36,37 @@ var PlanTests = []QueryPlanTest{
 		Query: "SELECT s2, i2, i FROM mytable INNER JOIN othertable ON i = i2",
 		ExpectedPlan: "Project(othertable.s2, othertable.i2, mytable.i)\n" +
 			" └─ IndexedJoin(mytable.i = othertable.i2)\n" +
-			"     ├─ Table(mytable)\n" +
-			"     └─ Table(othertable)\n" +
+			"     ├─ Projected table access on [i]\n" +
+			"     │   └─ Table(mytable)\n" +
+			"     └─ Projected table access on [s2 i2]\n" +
+			"         └─ Table(othertable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT i, i2, s2 FROM othertable JOIN mytable ON i = i2",
 		ExpectedPlan: "Project(mytable.i, othertable.i2, othertable.s2)\n" +
 			" └─ IndexedJoin(mytable.i = othertable.i2)\n" +
-			"     ├─ Table(othertable)\n" +
-			"     └─ Table(mytable)\n" +
+			"     ├─ Projected table access on [i2 s2]\n" +
+			"     │   └─ Table(othertable)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ Table(mytable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT s2, i2, i FROM othertable JOIN mytable ON i = i2",
-		ExpectedPlan: "Project(othertable.s2, othertable.i2, mytable.i)\n" +
-			" └─ IndexedJoin(mytable.i = othertable.i2)\n" +
-			"     ├─ Table(othertable)\n" +
+		ExpectedPlan: "IndexedJoin(mytable.i = othertable.i2)\n" +
+			" ├─ Projected table access on [s2 i2]\n" +
+			" │   └─ Table(othertable)\n" +
+			" └─ Projected table access on [i]\n" +
 			"     └─ Table(mytable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT i, i2, s2 FROM mytable INNER JOIN othertable ON i2 = i",
-		ExpectedPlan: "Project(mytable.i, othertable.i2, othertable.s2)\n" +
-			" └─ IndexedJoin(othertable.i2 = mytable.i)\n" +
-			"     ├─ Table(mytable)\n" +
+		ExpectedPlan: "IndexedJoin(othertable.i2 = mytable.i)\n" +
+			" ├─ Projected table access on [i]\n" +
+			" │   └─ Table(mytable)\n" +
+			" └─ Projected table access on [i2 s2]\n" +
 			"     └─ Table(othertable)\n" +
 			"",
 	},
@@ -67,23 +74,28 @@ var PlanTests = []QueryPlanTest{
 		Query: "SELECT s2, i2, i FROM mytable INNER JOIN othertable ON i2 = i",
 		ExpectedPlan: "Project(othertable.s2, othertable.i2, mytable.i)\n" +
 			" └─ IndexedJoin(othertable.i2 = mytable.i)\n" +
-			"     ├─ Table(mytable)\n" +
-			"     └─ Table(othertable)\n" +
+			"     ├─ Projected table access on [i]\n" +
+			"     │   └─ Table(mytable)\n" +
+			"     └─ Projected table access on [s2 i2]\n" +
+			"         └─ Table(othertable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT i, i2, s2 FROM othertable JOIN mytable ON i2 = i",
 		ExpectedPlan: "Project(mytable.i, othertable.i2, othertable.s2)\n" +
 			" └─ IndexedJoin(othertable.i2 = mytable.i)\n" +
-			"     ├─ Table(othertable)\n" +
-			"     └─ Table(mytable)\n" +
+			"     ├─ Projected table access on [i2 s2]\n" +
+			"     │   └─ Table(othertable)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ Table(mytable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT s2, i2, i FROM othertable JOIN mytable ON i2 = i",
-		ExpectedPlan: "Project(othertable.s2, othertable.i2, mytable.i)\n" +
-			" └─ IndexedJoin(othertable.i2 = mytable.i)\n" +
-			"     ├─ Table(othertable)\n" +
+		ExpectedPlan: "IndexedJoin(othertable.i2 = mytable.i)\n" +
+			" ├─ Projected table access on [s2 i2]\n" +
+			" │   └─ Table(othertable)\n" +
+			" └─ Projected table access on [i]\n" +
 			"     └─ Table(mytable)\n" +
 			"",
 	},
@@ -91,46 +103,67 @@ var PlanTests = []QueryPlanTest{
 		Query: "SELECT * FROM mytable mt INNER JOIN othertable ot ON mt.i = ot.i2 AND mt.i > 2",
 		ExpectedPlan: "IndexedJoin(mt.i = ot.i2)\n" +
 			" ├─ Filter(mt.i > 2)\n" +
-			" │   └─ TableAlias(mt)\n" +
-			" │       └─ Indexed table access on index [mytable.i]\n" +
-			" │           └─ Table(mytable)\n" +
-			" └─ TableAlias(ot)\n" +
-			"     └─ Table(othertable)\n" +
+			" │   └─ Projected table access on [i s]\n" +
+			" │       └─ TableAlias(mt)\n" +
+			" │           └─ Indexed table access on index [mytable.i], with multi-range read\n" +
+			" │               └─ Table(mytable)\n" +
+			" └─ Projected table access on [s2 i2]\n" +
+			"     └─ TableAlias(ot)\n" +
+			"         └─ Table(othertable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT i, i2, s2 FROM mytable RIGHT JOIN othertable ON i = i2 - 1",
 		ExpectedPlan: "Project(mytable.i, othertable.i2, othertable.s2)\n" +
 			" └─ RightIndexedJoin(mytable.i = othertable.i2 - 1)\n" +
-			"     ├─ Table(othertable)\n" +
-			"     └─ Table(mytable)\n" +
+			"     ├─ Projected table access on [i2 s2]\n" +
+			"     │   └─ Table(othertable)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ Table(mytable)\n" +
 			"",
 	},
 	{
-		// Test of case-insensitivity when matching indexes to column expressions
+		// Scanning the single row of the table costs less than looking it up in the index
 		Query: "SELECT t1.timestamp FROM reservedWordsTable t1 JOIN reservedWordsTable t2 ON t1.TIMESTAMP = t2.tImEstamp",
 		ExpectedPlan: "Project(t1.Timestamp)\n" +
-			" └─ IndexedJoin(t1.Timestamp = t2.Timestamp)\n" +
-			"     ├─ TableAlias(t1)\n" +
-			"     │   └─ Table(reservedWordsTable)\n" +
-			"     └─ TableAlias(t2)\n" +
-			"         └─ Table(reservedWordsTable)\n" +
+			" └─ InnerJoin(t1.Timestamp = t2.Timestamp)\n" +
+			"     ├─ Projected table access on [Timestamp]\n" +
+			"     │   └─ TableAlias(t1)\n" +
+			"     │       └─ Table(reservedWordsTable)\n" +
+			"     └─ Projected table access on [Timestamp]\n" +
+			"         └─ TableAlias(t2)\n" +
+			"             └─ Table(reservedWordsTable)\n" +
+			"",
+	},
+	{
+		// Test of case-insensitivity when matching indexes to column expressions
+		Query: "SELECT a.pk FROM one_pk a JOIN one_pk b ON a.PK = b.pK",
+		ExpectedPlan: "Project(a.pk)\n" +
+			" └─ IndexedJoin(a.pk = b.pk)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ TableAlias(b)\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON one_pk.pk=two_pk.pk1 AND one_pk.pk=two_pk.pk2",
-		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			" └─ IndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"     ├─ Table(one_pk)\n" +
+		ExpectedPlan: "IndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ Table(one_pk)\n" +
+			" └─ Projected table access on [pk1 pk2]\n" +
 			"     └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk opk JOIN two_pk tpk ON opk.pk=tpk.pk1 AND opk.pk=tpk.pk2",
-		ExpectedPlan: "Project(opk.pk, tpk.pk1, tpk.pk2)\n" +
-			" └─ IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
-			"     ├─ TableAlias(opk)\n" +
-			"     │   └─ Table(one_pk)\n" +
+		ExpectedPlan: "IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ TableAlias(opk)\n" +
+			" │       └─ Table(one_pk)\n" +
+			" └─ Projected table access on [pk1 pk2]\n" +
 			"     └─ TableAlias(tpk)\n" +
 			"         └─ Table(two_pk)\n" +
 			"",
@@ -139,64 +172,143 @@ var PlanTests = []QueryPlanTest{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk LEFT JOIN two_pk ON one_pk.pk=two_pk.pk1 AND one_pk.pk=two_pk.pk2",
 		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			" └─ LeftIndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Table(two_pk)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
+			"         └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk RIGHT JOIN two_pk ON one_pk.pk=two_pk.pk1 AND one_pk.pk=two_pk.pk2",
 		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			" └─ RightIndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"     ├─ Table(two_pk)\n" +
-			"     └─ Table(one_pk)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT i,pk1,pk2 FROM mytable JOIN two_pk ON i-1=pk1 AND i-2=pk2",
-		ExpectedPlan: "Project(mytable.i, two_pk.pk1, two_pk.pk2)\n" +
-			" └─ IndexedJoin(mytable.i - 1 = two_pk.pk1 AND mytable.i - 2 = two_pk.pk2)\n" +
-			"     ├─ Table(mytable)\n" +
+		ExpectedPlan: "IndexedJoin(mytable.i - 1 = two_pk.pk1 AND mytable.i - 2 = two_pk.pk2)\n" +
+			" ├─ Projected table access on [i]\n" +
+			" │   └─ Table(mytable)\n" +
+			" └─ Projected table access on [pk1 pk2]\n" +
 			"     └─ Table(two_pk)\n" +
 			"",
 	},
+	{
+		Query: "SELECT a.pk, b.i2, c.i FROM one_pk a JOIN othertable b ON a.pk = b.i2 JOIN mytable c ON b.i2 = c.i",
+		ExpectedPlan: "Project(a.pk, b.i2, c.i)\n" +
+			" └─ IndexedJoin(b.i2 = c.i)\n" +
+			"     ├─ IndexedJoin(a.pk = b.i2)\n" +
+			"     │   ├─ Projected table access on [i2]\n" +
+			"     │   │   └─ TableAlias(b)\n" +
+			"     │   │       └─ Table(othertable)\n" +
+			"     │   └─ Projected table access on [pk]\n" +
+			"     │       └─ TableAlias(a)\n" +
+			"     │           └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ TableAlias(c)\n" +
+			"             └─ Table(mytable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT m.i, n.i2, o.pk FROM mytable m JOIN niltable n ON m.i = n.i2 JOIN one_pk o ON o.pk = m.i",
+		ExpectedPlan: "Project(m.i, n.i2, o.pk)\n" +
+			" └─ InnerJoin(m.i = n.i2)\n" +
+			"     ├─ IndexedJoin(o.pk = m.i)\n" +
+			"     │   ├─ Projected table access on [i]\n" +
+			"     │   │   └─ TableAlias(m)\n" +
+			"     │   │       └─ Table(mytable)\n" +
+			"     │   └─ Projected table access on [pk]\n" +
+			"     │       └─ TableAlias(o)\n" +
+			"     │           └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i2]\n" +
+			"         └─ TableAlias(n)\n" +
+			"             └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT a.pk, b.i, c.i FROM one_pk a LEFT JOIN niltable b ON a.pk = b.i LEFT JOIN mytable c ON b.i = c.i",
+		ExpectedPlan: "Project(a.pk, b.i, c.i)\n" +
+			" └─ LeftIndexedJoin(b.i = c.i)\n" +
+			"     ├─ LeftIndexedJoin(a.pk = b.i)\n" +
+			"     │   ├─ Projected table access on [pk]\n" +
+			"     │   │   └─ TableAlias(a)\n" +
+			"     │   │       └─ Table(one_pk)\n" +
+			"     │   └─ Projected table access on [i]\n" +
+			"     │       └─ TableAlias(b)\n" +
+			"     │           └─ Table(niltable)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ TableAlias(c)\n" +
+			"             └─ Table(mytable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT t1.pk, t2.pk1, t3.i FROM one_pk t1 CROSS JOIN two_pk t2 JOIN mytable t3 ON t3.i = t2.pk1 AND t2.pk1 = t1.pk AND t2.pk2 = t1.pk",
+		ExpectedPlan: "Project(t1.pk, t2.pk1, t3.i)\n" +
+			" └─ IndexedJoin(t3.i = t2.pk1)\n" +
+			"     ├─ IndexedJoin(t2.pk1 = t1.pk AND t2.pk2 = t1.pk)\n" +
+			"     │   ├─ Projected table access on [pk]\n" +
+			"     │   │   └─ TableAlias(t1)\n" +
+			"     │   │       └─ Table(one_pk)\n" +
+			"     │   └─ Projected table access on [pk1 pk2]\n" +
+			"     │       └─ TableAlias(t2)\n" +
+			"     │           └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ TableAlias(t3)\n" +
+			"             └─ Table(mytable)\n" +
+			"",
+	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk LEFT JOIN two_pk ON pk=pk1",
 		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			" └─ LeftJoin(one_pk.pk = two_pk.pk1)\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Table(two_pk)\n" +
+			" └─ LeftIndexedJoin(one_pk.pk = two_pk.pk1)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
+			"         └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk LEFT JOIN niltable ON pk=i",
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Table(niltable)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i f]\n" +
+			"         └─ Table(niltable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk RIGHT JOIN niltable ON pk=i",
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"     ├─ Table(niltable)\n" +
-			"     └─ Table(one_pk)\n" +
+			"     ├─ Projected table access on [i f]\n" +
+			"     │   └─ Table(niltable)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk LEFT JOIN niltable ON pk=i AND f IS NOT NULL",
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
-			" └─ LeftJoin(one_pk.pk = niltable.i AND NOT(niltable.f IS NULL))\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Table(niltable)\n" +
+			" └─ LeftIndexedJoin(one_pk.pk = niltable.i AND NOT(niltable.f IS NULL))\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i f]\n" +
+			"         └─ Table(niltable)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk RIGHT JOIN niltable ON pk=i and pk > 0",
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
-			" └─ RightJoin(one_pk.pk = niltable.i AND one_pk.pk > 0)\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Table(niltable)\n" +
+			" └─ RightIndexedJoin(one_pk.pk = niltable.i AND one_pk.pk > 0)\n" +
+			"     ├─ Projected table access on [i f]\n" +
+			"     │   └─ Table(niltable)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -204,8 +316,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ Filter(NOT(niltable.f IS NULL))\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [i f]\n" +
+			"             └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -213,8 +327,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ Filter(niltable.i2 > 1)\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [i f i2]\n" +
+			"             └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -222,8 +338,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ Filter(niltable.i > 1)\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [i f]\n" +
+			"             └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -231,8 +349,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
 			"     ├─ Filter(one_pk.c1 > 10)\n" +
-			"     │   └─ Table(one_pk)\n" +
-			"     └─ Table(niltable)\n" +
+			"     │   └─ Projected table access on [pk c1]\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i f]\n" +
+			"         └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -240,18 +360,22 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
 			"     ├─ Filter(NOT(niltable.f IS NULL))\n" +
-			"     │   └─ Table(niltable)\n" +
-			"     └─ Table(one_pk)\n" +
+			"     │   └─ Projected table access on [i f]\n" +
+			"     │       └─ Table(niltable)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk LEFT JOIN niltable ON pk=i WHERE pk > 1",
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"     ├─ Indexed table access on index [one_pk.pk]\n" +
+			"     ├─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
 			"     │   └─ Filter(one_pk.pk > 1)\n" +
-			"     │       └─ Table(one_pk)\n" +
-			"     └─ Table(niltable)\n" +
+			"     │       └─ Projected table access on [pk]\n" +
+			"     │           └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i f]\n" +
+			"         └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -259,25 +383,30 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			" └─ Filter(one_pk.pk > 0)\n" +
 			"     └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(niltable)\n" +
-			"         └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [i f]\n" +
+			"         │   └─ Table(niltable)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON pk=pk1",
 		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			" └─ IndexedJoin(one_pk.pk = two_pk.pk1)\n" +
-			"     ├─ Table(two_pk)\n" +
-			"     └─ Table(one_pk)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a JOIN two_pk b ON a.pk1=b.pk1 AND a.pk2=b.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ IndexedJoin(a.pk1 = b.pk1 AND a.pk2 = b.pk2)\n" +
-			"         ├─ TableAlias(a)\n" +
-			"         │   └─ Table(two_pk)\n" +
+			" └─ IndexedJoin(a.pk1 = b.pk1 AND a.pk2 = b.pk2)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(b)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -285,10 +414,11 @@ var PlanTests = []QueryPlanTest{
 	{
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a JOIN two_pk b ON a.pk1=b.pk2 AND a.pk2=b.pk1 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ IndexedJoin(a.pk1 = b.pk2 AND a.pk2 = b.pk1)\n" +
-			"         ├─ TableAlias(a)\n" +
-			"         │   └─ Table(two_pk)\n" +
+			" └─ IndexedJoin(a.pk1 = b.pk2 AND a.pk2 = b.pk1)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(b)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -296,10 +426,11 @@ var PlanTests = []QueryPlanTest{
 	{
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a JOIN two_pk b ON b.pk1=a.pk1 AND a.pk2=b.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ IndexedJoin(b.pk1 = a.pk1 AND a.pk2 = b.pk2)\n" +
-			"         ├─ TableAlias(a)\n" +
-			"         │   └─ Table(two_pk)\n" +
+			" └─ IndexedJoin(b.pk1 = a.pk1 AND a.pk2 = b.pk2)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(b)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -307,10 +438,11 @@ var PlanTests = []QueryPlanTest{
 	{
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a JOIN two_pk b ON a.pk1+1=b.pk1 AND a.pk2+1=b.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ IndexedJoin(a.pk1 + 1 = b.pk1 AND a.pk2 + 1 = b.pk2)\n" +
-			"         ├─ TableAlias(a)\n" +
-			"         │   └─ Table(two_pk)\n" +
+			" └─ IndexedJoin(a.pk1 + 1 = b.pk1 AND a.pk2 + 1 = b.pk2)\n" +
+			"     ├─ Projected table access on [pk1 pk2]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(two_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(b)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -319,11 +451,12 @@ var PlanTests = []QueryPlanTest{
 		// TODO: this should use an index. CrossJoin needs to be converted to InnerJoin, where clause to join cond
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a, two_pk b WHERE a.pk1=b.pk1 AND a.pk2=b.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ Filter(a.pk1 = b.pk1 AND a.pk2 = b.pk2)\n" +
-			"         └─ CrossJoin\n" +
-			"             ├─ TableAlias(a)\n" +
-			"             │   └─ Table(two_pk)\n" +
+			" └─ Filter(a.pk1 = b.pk1 AND a.pk2 = b.pk2)\n" +
+			"     └─ CrossJoin\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ TableAlias(a)\n" +
+			"         │       └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2]\n" +
 			"             └─ TableAlias(b)\n" +
 			"                 └─ Table(two_pk)\n" +
 			"",
@@ -332,11 +465,12 @@ var PlanTests = []QueryPlanTest{
 		// TODO: this should use an index. CrossJoin needs to be converted to InnerJoin, where clause to join cond
 		Query: "SELECT a.pk1,a.pk2,b.pk1,b.pk2 FROM two_pk a, two_pk b WHERE a.pk1=b.pk2 AND a.pk2=b.pk1 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(a.pk1 ASC, a.pk2 ASC, b.pk1 ASC)\n" +
-			" └─ Project(a.pk1, a.pk2, b.pk1, b.pk2)\n" +
-			"     └─ Filter(a.pk1 = b.pk2 AND a.pk2 = b.pk1)\n" +
-			"         └─ CrossJoin\n" +
-			"             ├─ TableAlias(a)\n" +
-			"             │   └─ Table(two_pk)\n" +
+			" └─ Filter(a.pk1 = b.pk2 AND a.pk2 = b.pk1)\n" +
+			"     └─ CrossJoin\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ TableAlias(a)\n" +
+			"         │       └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2]\n" +
 			"             └─ TableAlias(b)\n" +
 			"                 └─ Table(two_pk)\n" +
 			"",
@@ -346,8 +480,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(one_pk.c5 ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
 			" └─ Project(one_pk.c5, two_pk.pk1, two_pk.pk2)\n" +
 			"     └─ IndexedJoin(one_pk.pk = two_pk.pk1)\n" +
-			"         ├─ Table(two_pk)\n" +
-			"         └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [c5 pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -355,10 +491,12 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(opk.c5 ASC, tpk.pk1 ASC, tpk.pk2 ASC)\n" +
 			" └─ Project(opk.c5, tpk.pk1, tpk.pk2)\n" +
 			"     └─ IndexedJoin(opk.pk = tpk.pk1)\n" +
-			"         ├─ TableAlias(tpk)\n" +
-			"         │   └─ Table(two_pk)\n" +
-			"         └─ TableAlias(opk)\n" +
-			"             └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ TableAlias(tpk)\n" +
+			"         │       └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [c5 pk]\n" +
+			"             └─ TableAlias(opk)\n" +
+			"                 └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -366,10 +504,12 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(opk.c5 ASC, tpk.pk1 ASC, tpk.pk2 ASC)\n" +
 			" └─ Project(opk.c5, tpk.pk1, tpk.pk2)\n" +
 			"     └─ IndexedJoin(opk.pk = tpk.pk1)\n" +
-			"         ├─ TableAlias(tpk)\n" +
-			"         │   └─ Table(two_pk)\n" +
-			"         └─ TableAlias(opk)\n" +
-			"             └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ TableAlias(tpk)\n" +
+			"         │       └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [c5 pk]\n" +
+			"             └─ TableAlias(opk)\n" +
+			"                 └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -378,10 +518,12 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(opk.c5, tpk.pk1, tpk.pk2)\n" +
 			"     └─ Filter(opk.pk = tpk.pk1)\n" +
 			"         └─ CrossJoin\n" +
-			"             ├─ TableAlias(opk)\n" +
-			"             │   └─ Table(one_pk)\n" +
-			"             └─ TableAlias(tpk)\n" +
-			"                 └─ Table(two_pk)\n" +
+			"             ├─ Projected table access on [c5 pk]\n" +
+			"             │   └─ TableAlias(opk)\n" +
+			"             │       └─ Table(one_pk)\n" +
+			"             └─ Projected table access on [pk1 pk2]\n" +
+			"                 └─ TableAlias(tpk)\n" +
+			"                     └─ Table(two_pk)\n" +
 			"",
 	},
 	{
@@ -391,8 +533,10 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(one_pk.c5, two_pk.pk1, two_pk.pk2)\n" +
 			"     └─ Filter(one_pk.pk = two_pk.pk1)\n" +
 			"         └─ CrossJoin\n" +
-			"             ├─ Table(one_pk)\n" +
-			"             └─ Table(two_pk)\n" +
+			"             ├─ Projected table access on [c5 pk]\n" +
+			"             │   └─ Table(one_pk)\n" +
+			"             └─ Projected table access on [pk1 pk2]\n" +
+			"                 └─ Table(two_pk)\n" +
 			"",
 	},
 	{
@@ -400,8 +544,10 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(one_pk.pk ASC)\n" +
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [i f]\n" +
+			"             └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -410,8 +556,10 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ Filter(NOT(niltable.f IS NULL))\n" +
 			"         └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"             ├─ Table(one_pk)\n" +
-			"             └─ Table(niltable)\n" +
+			"             ├─ Projected table access on [pk]\n" +
+			"             │   └─ Table(one_pk)\n" +
+			"             └─ Projected table access on [i f]\n" +
+			"                 └─ Table(niltable)\n" +
 			"",
 	},
 	{
@@ -419,18 +567,22 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(one_pk.pk ASC)\n" +
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Indexed table access on index [one_pk.pk]\n" +
+			"         ├─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
 			"         │   └─ Filter(one_pk.pk > 1)\n" +
-			"         │       └─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n",
+			"         │       └─ Projected table access on [pk]\n" +
+			"         │           └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [i f]\n" +
+			"             └─ Table(niltable)\n",
 	},
 	{
 		Query: "SELECT pk,i,f FROM one_pk RIGHT JOIN niltable ON pk=i ORDER BY 2,3",
 		ExpectedPlan: "Sort(niltable.i ASC, niltable.f ASC)\n" +
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"         ├─ Table(niltable)\n" +
-			"         └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [i f]\n" +
+			"         │   └─ Table(niltable)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -439,8 +591,10 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
 			"         ├─ Filter(NOT(niltable.f IS NULL))\n" +
-			"         │   └─ Table(niltable)\n" +
-			"         └─ Table(one_pk)\n" +
+			"         │   └─ Projected table access on [i f]\n" +
+			"         │       └─ Table(niltable)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -449,44 +603,50 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
 			"     └─ Filter(one_pk.pk > 0)\n" +
 			"         └─ RightIndexedJoin(one_pk.pk = niltable.i)\n" +
-			"             ├─ Table(niltable)\n" +
-			"             └─ Table(one_pk)\n" +
+			"             ├─ Projected table access on [i f]\n" +
+			"             │   └─ Table(niltable)\n" +
+			"             └─ Projected table access on [pk]\n" +
+			"                 └─ Table(one_pk)\n" +
 			"",
 	},
 	{
-		// TODO: this should use an index. Extra join condition should get moved out of the join clause into a filter
 		Query: "SELECT pk,i,f FROM one_pk RIGHT JOIN niltable ON pk=i and pk > 0 ORDER BY 2,3",
 		ExpectedPlan: "Sort(niltable.i ASC, niltable.f ASC)\n" +
 			" └─ Project(one_pk.pk, niltable.i, niltable.f)\n" +
-			"     └─ RightJoin(one_pk.pk = niltable.i AND one_pk.pk > 0)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(niltable)\n" +
+			"     └─ RightIndexedJoin(one_pk.pk = niltable.i AND one_pk.pk > 0)\n" +
+			"         ├─ Projected table access on [i f]\n" +
+			"         │   └─ Table(niltable)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON one_pk.pk=two_pk.pk1 AND one_pk.pk=two_pk.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
-			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			"     └─ IndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"         ├─ Table(one_pk)\n" +
+			" └─ IndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON pk1-pk>0 AND pk2<1",
-		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			" └─ InnerJoin(two_pk.pk1 - one_pk.pk > 0)\n" +
-			"     ├─ Table(one_pk)\n" +
-			"     └─ Filter(two_pk.pk2 < 1)\n" +
+		ExpectedPlan: "InnerJoin(two_pk.pk1 - one_pk.pk > 0)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ Table(one_pk)\n" +
+			" └─ Filter(two_pk.pk2 < 1)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
-			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			"     └─ CrossJoin\n" +
-			"         ├─ Table(one_pk)\n" +
+			" └─ CrossJoin\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ Table(two_pk)\n" +
 			"",
 	},
@@ -495,17 +655,66 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
 			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			"     └─ LeftIndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(two_pk)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2]\n" +
+			"             └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk LEFT JOIN two_pk ON pk=pk1 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
 			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
-			"     └─ LeftJoin(one_pk.pk = two_pk.pk1)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(two_pk)\n" +
+			"     └─ LeftIndexedJoin(one_pk.pk = two_pk.pk1)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2]\n" +
+			"             └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk,pk1,pk2,two_pk.c1 FROM one_pk LEFT JOIN two_pk ON pk=pk1 AND two_pk.c1 > 10 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
+			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2, two_pk.c1)\n" +
+			"     └─ LeftIndexedJoin(one_pk.pk = two_pk.pk1 AND two_pk.c1 > 10)\n" +
+			"         ├─ Projected table access on [pk]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2 c1]\n" +
+			"             └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk,pk1,pk2 FROM one_pk JOIN two_pk ON one_pk.pk < two_pk.pk1 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
+			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
+			"     └─ IndexedJoin(one_pk.pk < two_pk.pk1)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk1,pk2,pk FROM two_pk LEFT JOIN one_pk ON one_pk.pk BETWEEN two_pk.pk1 AND two_pk.pk2 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(two_pk.pk1 ASC, two_pk.pk2 ASC, one_pk.pk ASC)\n" +
+			" └─ Project(two_pk.pk1, two_pk.pk2, one_pk.pk)\n" +
+			"     └─ LeftIndexedJoin(one_pk.pk BETWEEN two_pk.pk1 AND two_pk.pk2)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT a.pk,b.pk FROM one_pk a JOIN one_pk b ON b.pk > a.pk AND b.pk <= a.pk + 1 ORDER BY 1,2",
+		ExpectedPlan: "Sort(a.pk ASC, b.pk ASC)\n" +
+			" └─ IndexedJoin(b.pk > a.pk AND b.pk <= a.pk + 1)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ TableAlias(b)\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
@@ -513,17 +722,20 @@ var PlanTests = []QueryPlanTest{
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
 			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			"     └─ RightIndexedJoin(one_pk.pk = two_pk.pk1 AND one_pk.pk = two_pk.pk2)\n" +
-			"         ├─ Table(two_pk)\n" +
-			"         └─ Table(one_pk)\n" +
+			"         ├─ Projected table access on [pk1 pk2]\n" +
+			"         │   └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk opk JOIN two_pk tpk ON opk.pk=tpk.pk1 AND opk.pk=tpk.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(opk.pk ASC, tpk.pk1 ASC, tpk.pk2 ASC)\n" +
-			" └─ Project(opk.pk, tpk.pk1, tpk.pk2)\n" +
-			"     └─ IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
-			"         ├─ TableAlias(opk)\n" +
-			"         │   └─ Table(one_pk)\n" +
+			" └─ IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(opk)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(tpk)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -531,10 +743,11 @@ var PlanTests = []QueryPlanTest{
 	{
 		Query: "SELECT pk,pk1,pk2 FROM one_pk opk JOIN two_pk tpk ON pk=tpk.pk1 AND pk=tpk.pk2 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(opk.pk ASC, tpk.pk1 ASC, tpk.pk2 ASC)\n" +
-			" └─ Project(opk.pk, tpk.pk1, tpk.pk2)\n" +
-			"     └─ IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
-			"         ├─ TableAlias(opk)\n" +
-			"         │   └─ Table(one_pk)\n" +
+			" └─ IndexedJoin(opk.pk = tpk.pk1 AND opk.pk = tpk.pk2)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(opk)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2]\n" +
 			"         └─ TableAlias(tpk)\n" +
 			"             └─ Table(two_pk)\n" +
 			"",
@@ -545,41 +758,119 @@ var PlanTests = []QueryPlanTest{
 			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
 			"     └─ Filter(one_pk.c1 = two_pk.c1)\n" +
 			"         └─ CrossJoin\n" +
-			"             ├─ Table(one_pk)\n" +
-			"             └─ Table(two_pk)\n",
+			"             ├─ Projected table access on [pk c1]\n" +
+			"             │   └─ Table(one_pk)\n" +
+			"             └─ Projected table access on [pk1 pk2 c1]\n" +
+			"                 └─ Table(two_pk)\n",
+	},
+	{
+		Query: "SELECT pk,pk1,pk2 FROM one_pk,two_pk WHERE one_pk.c1=two_pk.c1 AND two_pk.c2 > 2 AND one_pk.pk < 3 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
+			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
+			"     └─ Filter(one_pk.c1 = two_pk.c1)\n" +
+			"         └─ CrossJoin\n" +
+			"             ├─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
+			"             │   └─ Filter(one_pk.pk < 3)\n" +
+			"             │       └─ Projected table access on [pk c1]\n" +
+			"             │           └─ Table(one_pk)\n" +
+			"             └─ Filter(two_pk.c2 > 2)\n" +
+			"                 └─ Projected table access on [pk1 pk2 c1 c2]\n" +
+			"                     └─ Table(two_pk)\n" +
+			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2,one_pk.c1 AS foo, two_pk.c1 AS bar FROM one_pk JOIN two_pk ON one_pk.c1=two_pk.c1 ORDER BY 1,2,3",
 		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
 			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2, one_pk.c1 as foo, two_pk.c1 as bar)\n" +
-			"     └─ InnerJoin(one_pk.c1 = two_pk.c1)\n" +
-			"         ├─ Table(one_pk)\n" +
-			"         └─ Table(two_pk)\n" +
+			"     └─ HashJoin(one_pk.c1 = two_pk.c1)\n" +
+			"         ├─ Projected table access on [pk c1]\n" +
+			"         │   └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2 c1]\n" +
+			"             └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk1,pk2,one_pk.c1 AS foo,two_pk.c1 AS bar FROM one_pk JOIN two_pk ON one_pk.c1=two_pk.c1 WHERE one_pk.c1=10",
 		ExpectedPlan: "Project(one_pk.pk, two_pk.pk1, two_pk.pk2, one_pk.c1 as foo, two_pk.c1 as bar)\n" +
-			" └─ InnerJoin(one_pk.c1 = two_pk.c1)\n" +
+			" └─ HashJoin(one_pk.c1 = two_pk.c1)\n" +
 			"     ├─ Filter(one_pk.c1 = 10)\n" +
-			"     │   └─ Table(one_pk)\n" +
-			"     └─ Table(two_pk)\n" +
+			"     │   └─ Projected table access on [pk c1]\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2 c1]\n" +
+			"         └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk,pk1,pk2 FROM one_pk RIGHT JOIN two_pk ON one_pk.c1=two_pk.c1 AND one_pk.c1 < 20 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(one_pk.pk ASC, two_pk.pk1 ASC, two_pk.pk2 ASC)\n" +
+			" └─ Project(one_pk.pk, two_pk.pk1, two_pk.pk2)\n" +
+			"     └─ RightHashJoin(one_pk.c1 = two_pk.c1 AND one_pk.c1 < 20)\n" +
+			"         ├─ Projected table access on [pk1 pk2 c1]\n" +
+			"         │   └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk c1]\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT a.pk,b.pk,c.pk1,c.pk2 FROM one_pk a JOIN one_pk b ON a.pk=b.pk JOIN two_pk c ON b.c1=c.c1 ORDER BY 1,2,3,4",
+		ExpectedPlan: "Sort(a.pk ASC, b.pk ASC, c.pk1 ASC, c.pk2 ASC)\n" +
+			" └─ Project(a.pk, b.pk, c.pk1, c.pk2)\n" +
+			"     └─ HashJoin(b.c1 = c.c1)\n" +
+			"         ├─ IndexedJoin(a.pk = b.pk)\n" +
+			"         │   ├─ Projected table access on [pk]\n" +
+			"         │   │   └─ TableAlias(a)\n" +
+			"         │   │       └─ Table(one_pk)\n" +
+			"         │   └─ Projected table access on [pk c1]\n" +
+			"         │       └─ TableAlias(b)\n" +
+			"         │           └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1 pk2 c1]\n" +
+			"             └─ TableAlias(c)\n" +
+			"                 └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT a.pk,sq.pk1,sq.pk2 FROM one_pk a JOIN (SELECT pk1,pk2,c1 FROM two_pk) sq ON a.c1=sq.c1 ORDER BY 1,2,3",
+		ExpectedPlan: "Sort(a.pk ASC, sq.pk1 ASC, sq.pk2 ASC)\n" +
+			" └─ Project(a.pk, sq.pk1, sq.pk2)\n" +
+			"     └─ HashJoin(a.c1 = sq.c1)\n" +
+			"         ├─ Projected table access on [pk c1]\n" +
+			"         │   └─ TableAlias(a)\n" +
+			"         │       └─ Table(one_pk)\n" +
+			"         └─ SubqueryAlias(sq)\n" +
+			"             └─ Projected table access on [pk1 pk2 c1]\n" +
+			"                 └─ Table(two_pk)\n" +
 			"",
 	},
 	{
 		Query: "SELECT pk,pk2 FROM one_pk t1, two_pk t2 WHERE pk=1 AND pk2=1 ORDER BY 1,2",
 		ExpectedPlan: "Sort(t1.pk ASC, t2.pk2 ASC)\n" +
-			" └─ Project(t1.pk, t2.pk2)\n" +
-			"     └─ CrossJoin\n" +
-			"         ├─ Filter(t1.pk = 1)\n" +
-			"         │   └─ TableAlias(t1)\n" +
-			"         │       └─ Indexed table access on index [one_pk.pk]\n" +
-			"         │           └─ Table(one_pk)\n" +
-			"         └─ Filter(t2.pk2 = 1)\n" +
+			" └─ CrossJoin\n" +
+			"     ├─ Filter(t1.pk = 1)\n" +
+			"     │   └─ Projected table access on [pk]\n" +
+			"     │       └─ TableAlias(t1)\n" +
+			"     │           └─ Indexed table access on index [one_pk.pk]\n" +
+			"     │               └─ Table(one_pk)\n" +
+			"     └─ Filter(t2.pk2 = 1)\n" +
+			"         └─ Projected table access on [pk2]\n" +
 			"             └─ TableAlias(t2)\n" +
 			"                 └─ Table(two_pk)\n" +
 			"",
 	},
+	{
+		Query: "SELECT pk,c1 FROM one_pk WHERE pk = 1",
+		ExpectedPlan: "Project(one_pk.pk, one_pk.c1)\n" +
+			" └─ PointLookup([one_pk.pk] = (1))\n" +
+			"     ├─ Filter(one_pk.pk = 1)\n" +
+			"     └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT * FROM two_pk WHERE pk2 = 1 AND c1 > 0 AND pk1 = 0",
+		ExpectedPlan: "PointLookup([two_pk.pk1,two_pk.pk2] = (0, 1))\n" +
+			" ├─ Filter(two_pk.pk2 = 1 AND two_pk.c1 > 0 AND two_pk.pk1 = 0)\n" +
+			" └─ Table(two_pk)\n" +
+			"",
+	},
 	{
 		Query: "DELETE FROM two_pk WHERE c1 > 1",
 		ExpectedPlan: "Delete\n" +
@@ -612,4 +903,360 @@ var PlanTests = []QueryPlanTest{
 			"             └─ Table(two_pk)\n" +
 			"",
 	},
+	{
+		Query: "EXPLAIN UPDATE two_pk SET c1 = 1 WHERE pk1 = 1 AND pk2 = 2",
+		ExpectedPlan: "DescribeQuery(format=tree)\n" +
+			" └─ Update\n" +
+			"     └─ UpdateSource(SET two_pk.c1 = 1)\n" +
+			"         └─ Indexed table access on index [two_pk.pk1,two_pk.pk2]\n" +
+			"             └─ Filter(two_pk.pk1 = 1 AND two_pk.pk2 = 2)\n" +
+			"                 └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "EXPLAIN DELETE FROM two_pk WHERE pk1 = 1 AND pk2 = 2",
+		ExpectedPlan: "DescribeQuery(format=tree)\n" +
+			" └─ Delete\n" +
+			"     └─ Indexed table access on index [two_pk.pk1,two_pk.pk2]\n" +
+			"         └─ Filter(two_pk.pk1 = 1 AND two_pk.pk2 = 2)\n" +
+			"             └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "DESCRIBE DELETE FROM one_pk WHERE pk > 1 ORDER BY c1 LIMIT 2",
+		ExpectedPlan: "DescribeQuery(format=tree)\n" +
+			" └─ Delete\n" +
+			"     └─ Limit(2)\n" +
+			"         └─ Sort(one_pk.c1 ASC)\n" +
+			"             └─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
+			"                 └─ Filter(one_pk.pk > 1)\n" +
+			"                     └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "EXPLAIN UPDATE mytable INNER JOIN othertable ON i = i2 SET s = s2 WHERE i > 1",
+		ExpectedPlan: "DescribeQuery(format=tree)\n" +
+			" └─ Update\n" +
+			"     └─ UpdateSource(SET mytable.s = othertable.s2)\n" +
+			"         └─ IndexedJoin(mytable.i = othertable.i2)\n" +
+			"             ├─ Indexed table access on index [mytable.i], with multi-range read\n" +
+			"             │   └─ Filter(mytable.i > 1)\n" +
+			"             │       └─ Table(mytable)\n" +
+			"             └─ Table(othertable)\n" +
+			"",
+	},
+	{
+		Query: "EXPLAIN INSERT INTO mytable (i, s) SELECT i2 + 10, s2 FROM othertable WHERE i2 = 1",
+		ExpectedPlan: "DescribeQuery(format=tree)\n" +
+			" └─ Insert(i, s)\n" +
+			"     ├─ Table(mytable)\n" +
+			"     └─ Project(i, s)\n" +
+			"         └─ Project(othertable.i2 + 10, othertable.s2)\n" +
+			"             └─ Filter(othertable.i2 = 1)\n" +
+			"                 └─ Table(othertable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM two_pk WHERE pk1 = pk ORDER BY pk2)",
+		ExpectedPlan: "SemiHashJoin(two_pk.pk1 = one_pk.pk)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ Table(one_pk)\n" +
+			" └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM two_pk WHERE pk1 > 0 ORDER BY pk2)",
+		ExpectedPlan: "Filter(EXISTS (Limit(1)\n" +
+			" └─ Sort(two_pk.pk2 ASC)\n" +
+			"     └─ Filter(two_pk.pk1 > 0)\n" +
+			"         └─ Table(two_pk)\n" +
+			"))\n" +
+			" └─ Projected table access on [pk]\n" +
+			"     └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE NOT EXISTS (SELECT * FROM two_pk WHERE pk1 = one_pk.pk AND c1 > 0)",
+		ExpectedPlan: "AntiHashJoin(two_pk.pk1 = one_pk.pk)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ Table(one_pk)\n" +
+			" └─ Filter(two_pk.c1 > 0)\n" +
+			"     └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE pk IN (SELECT pk1 FROM two_pk WHERE two_pk.c1 = one_pk.c1)",
+		ExpectedPlan: "Project(one_pk.pk)\n" +
+			" └─ SemiHashJoin(two_pk.c1 = one_pk.c1 AND one_pk.pk = two_pk.pk1)\n" +
+			"     ├─ Projected table access on [pk c1]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE pk NOT IN (SELECT pk1 FROM two_pk WHERE two_pk.c1 = one_pk.c1)",
+		ExpectedPlan: "Project(one_pk.pk)\n" +
+			" └─ Filter(NOT(one_pk.pk IN (Project(two_pk.pk1)\n" +
+			"     └─ Filter(two_pk.c1 = one_pk.c1)\n" +
+			"         └─ Table(two_pk)\n" +
+			"    )))\n" +
+			"     └─ Projected table access on [pk c1]\n" +
+			"         └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM one_pk o WHERE o.pk = one_pk.pk + 1)",
+		ExpectedPlan: "SemiIndexedJoin(o.pk = one_pk.pk + 1)\n" +
+			" ├─ Projected table access on [pk]\n" +
+			" │   └─ Table(one_pk)\n" +
+			" └─ TableAlias(o)\n" +
+			"     └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT i FROM niltable WHERE i2 > 0 AND NOT EXISTS (SELECT * FROM mytable WHERE mytable.i + 1 = niltable.i2)",
+		ExpectedPlan: "Project(niltable.i)\n" +
+			" └─ AntiJoin(mytable.i + 1 = niltable.i2)\n" +
+			"     ├─ Filter(niltable.i2 > 0)\n" +
+			"     │   └─ Projected table access on [i i2]\n" +
+			"     │       └─ Table(niltable)\n" +
+			"     └─ Table(mytable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE EXISTS (SELECT * FROM two_pk WHERE pk1 = one_pk.pk) AND EXISTS (SELECT * FROM niltable WHERE niltable.i2 = one_pk.pk)",
+		ExpectedPlan: "SemiHashJoin(niltable.i2 = one_pk.pk)\n" +
+			" ├─ SemiHashJoin(two_pk.pk1 = one_pk.pk)\n" +
+			" │   ├─ Projected table access on [pk]\n" +
+			" │   │   └─ Table(one_pk)\n" +
+			" │   └─ Table(two_pk)\n" +
+			" └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE EXISTS (SELECT pk1 FROM two_pk WHERE pk1 = one_pk.pk GROUP BY pk1)",
+		ExpectedPlan: "Filter(EXISTS (Limit(1)\n" +
+			" └─ GroupBy\n" +
+			"     ├─ SelectedExprs(two_pk.pk1)\n" +
+			"     ├─ Grouping(two_pk.pk1)\n" +
+			"     └─ Filter(two_pk.pk1 = one_pk.pk)\n" +
+			"         └─ Table(two_pk)\n" +
+			"))\n" +
+			" └─ Projected table access on [pk]\n" +
+			"     └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk, (SELECT c3 FROM one_pk WHERE pk < opk.pk ORDER BY 1 DESC LIMIT 1) FROM one_pk opk ORDER BY 1",
+		ExpectedPlan: "Sort(opk.pk ASC)\n" +
+			" └─ Project(opk.pk, (Limit(1)\n" +
+			"     └─ Sort(one_pk.c3 DESC)\n" +
+			"         └─ Project(one_pk.c3)\n" +
+			"             └─ Filter(one_pk.pk < opk.pk)\n" +
+			"                 └─ Table(one_pk)\n" +
+			"    ))\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ TableAlias(opk)\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: `SELECT i, i2 FROM niltable ORDER BY i2 IS NULL, i2`,
+		ExpectedPlan: "Sort(niltable.i2 ASC NULLS LAST)\n" +
+			" └─ Project(niltable.i, niltable.i2)\n" +
+			"     └─ Projected table access on [i2 i]\n" +
+			"         └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: `SELECT i, i2 FROM niltable ORDER BY i2 IS NOT NULL, i2 DESC`,
+		ExpectedPlan: "Sort(niltable.i2 DESC NULLS FIRST)\n" +
+			" └─ Project(niltable.i, niltable.i2)\n" +
+			"     └─ Projected table access on [i2 i]\n" +
+			"         └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ JOIN_ORDER(one_pk, two_pk) */ * FROM one_pk JOIN two_pk ON pk = pk1",
+		ExpectedPlan: "QueryHints(JOIN_ORDER(one_pk, two_pk))\n" +
+			" └─ IndexedJoin(one_pk.pk = two_pk.pk1)\n" +
+			"     ├─ Projected table access on [pk c1 c2 c3 c4 c5]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1 pk2 c1 c2 c3 c4 c5]\n" +
+			"         └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ HASH_JOIN(two_pk) */ pk, pk1, pk2 FROM one_pk JOIN two_pk ON pk = pk1 AND pk2 = 1",
+		ExpectedPlan: "QueryHints(HASH_JOIN(two_pk))\n" +
+			" └─ HashJoin(one_pk.pk = two_pk.pk1)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Filter(two_pk.pk2 = 1)\n" +
+			"         └─ Projected table access on [pk1 pk2]\n" +
+			"             └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ NO_HASH_JOIN(a, b) */ a.pk, b.pk FROM one_pk a JOIN one_pk b ON a.pk + 1 = b.pk + 1",
+		ExpectedPlan: "QueryHints(NO_HASH_JOIN(a, b))\n" +
+			" └─ InnerJoin(a.pk + 1 = b.pk + 1)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ TableAlias(b)\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ NO_INDEX(b) */ a.pk, b.pk FROM one_pk a JOIN one_pk b ON a.pk < b.pk",
+		ExpectedPlan: "QueryHints(NO_INDEX(b))\n" +
+			" └─ InnerJoin(a.pk < b.pk)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ TableAlias(a)\n" +
+			"     │       └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk]\n" +
+			"         └─ TableAlias(b)\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ HASH_JOIN(niltable) */ pk, i FROM one_pk LEFT JOIN niltable ON pk = i",
+		ExpectedPlan: "QueryHints(HASH_JOIN(niltable))\n" +
+			" └─ LeftHashJoin(one_pk.pk = niltable.i)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i]\n" +
+			"         └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ NO_HASH_JOIN(niltable) */ pk, i2 FROM one_pk LEFT JOIN niltable ON pk = i2",
+		ExpectedPlan: "QueryHints(NO_HASH_JOIN(niltable))\n" +
+			" └─ LeftJoin(one_pk.pk = niltable.i2)\n" +
+			"     ├─ Projected table access on [pk]\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [i2]\n" +
+			"         └─ Table(niltable)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ JOIN_ORDER(c, b, a) */ a.pk, c.i FROM one_pk a JOIN two_pk b ON a.pk = b.pk1 JOIN niltable c ON c.i = b.pk2",
+		ExpectedPlan: "QueryHints(JOIN_ORDER(c, b, a))\n" +
+			" └─ Project(a.pk, c.i)\n" +
+			"     └─ IndexedJoin(a.pk = b.pk1)\n" +
+			"         ├─ HashJoin(c.i = b.pk2)\n" +
+			"         │   ├─ Projected table access on [i]\n" +
+			"         │   │   └─ TableAlias(c)\n" +
+			"         │   │       └─ Table(niltable)\n" +
+			"         │   └─ Projected table access on [pk1 pk2]\n" +
+			"         │       └─ TableAlias(b)\n" +
+			"         │           └─ Table(two_pk)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ TableAlias(a)\n" +
+			"                 └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ NO_INDEX(one_pk) */ pk, c1 FROM one_pk WHERE pk = 1",
+		ExpectedPlan: "QueryHints(NO_INDEX(one_pk))\n" +
+			" └─ Filter(one_pk.pk = 1)\n" +
+			"     └─ Projected table access on [pk c1]\n" +
+			"         └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ INDEX(one_pk primary) */ pk FROM one_pk WHERE pk > 0",
+		ExpectedPlan: "QueryHints(INDEX(one_pk, primary))\n" +
+			" └─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
+			"     └─ Filter(one_pk.pk > 0)\n" +
+			"         └─ Projected table access on [pk]\n" +
+			"             └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ MERGE(dt) */ pk1, dt.c1 FROM two_pk JOIN (SELECT pk, c1 FROM one_pk WHERE c1 > 0) dt ON dt.pk = two_pk.pk1",
+		ExpectedPlan: "QueryHints(MERGE(dt))\n" +
+			" └─ Project(two_pk.pk1, dt.c1)\n" +
+			"     └─ IndexedJoin(dt.pk = two_pk.pk1)\n" +
+			"         ├─ Project(dt.pk, dt.c1)\n" +
+			"         │   └─ Filter(dt.c1 > 0)\n" +
+			"         │       └─ Projected table access on [c1 pk]\n" +
+			"         │           └─ TableAlias(dt)\n" +
+			"         │               └─ Table(one_pk)\n" +
+			"         └─ Projected table access on [pk1]\n" +
+			"             └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ MERGE(dt) */ * FROM (SELECT * FROM one_pk o WHERE o.c1 > 0) dt WHERE dt.pk = 2",
+		ExpectedPlan: "QueryHints(MERGE(dt))\n" +
+			" └─ Filter(dt.pk = 2 AND dt.c1 > 0)\n" +
+			"     └─ Projected table access on [pk c1 c2 c3 c4 c5]\n" +
+			"         └─ TableAlias(dt)\n" +
+			"             └─ Indexed table access on index [one_pk.pk]\n" +
+			"                 └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT /*+ NO_MERGE(dt) */ * FROM (SELECT * FROM one_pk o WHERE o.c1 > 0) dt WHERE dt.pk = 2",
+		ExpectedPlan: "QueryHints(NO_MERGE(dt))\n" +
+			" └─ Filter(dt.pk = 2)\n" +
+			"     └─ SubqueryAlias(dt)\n" +
+			"         └─ Filter(o.pk = 2 AND o.c1 > 0)\n" +
+			"             └─ Projected table access on [pk c1 c2 c3 c4 c5]\n" +
+			"                 └─ TableAlias(o)\n" +
+			"                     └─ Indexed table access on index [one_pk.pk]\n" +
+			"                         └─ Table(one_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT * FROM (SELECT pk, c1 FROM one_pk) dt JOIN two_pk ON dt.pk = two_pk.pk1 WHERE two_pk.pk1 = 1",
+		ExpectedPlan: "IndexedJoin(dt.pk = two_pk.pk1)\n" +
+			" ├─ SubqueryAlias(dt)\n" +
+			" │   └─ Indexed table access on index [one_pk.pk]\n" +
+			" │       └─ Filter(one_pk.pk = 1)\n" +
+			" │           └─ Projected table access on [pk c1]\n" +
+			" │               └─ Table(one_pk)\n" +
+			" └─ Filter(two_pk.pk1 = 1)\n" +
+			"     └─ Projected table access on [pk1 pk2 c1 c2 c3 c4 c5]\n" +
+			"         └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk FROM one_pk WHERE pk = 1 AND EXISTS (SELECT * FROM two_pk WHERE two_pk.pk1 = one_pk.pk)",
+		ExpectedPlan: "Project(one_pk.pk)\n" +
+			" └─ SemiJoin(two_pk.pk1 = one_pk.pk)\n" +
+			"     ├─ PointLookup([one_pk.pk] = (1))\n" +
+			"     │   ├─ Filter(one_pk.pk = 1)\n" +
+			"     │   └─ Table(one_pk)\n" +
+			"     └─ Filter(two_pk.pk1 = 1)\n" +
+			"         └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "WITH cte (a, b) AS (SELECT pk, c1 FROM one_pk WHERE pk > 1) SELECT b FROM cte JOIN two_pk ON cte.a = two_pk.pk1",
+		ExpectedPlan: "Project(cte.b)\n" +
+			" └─ IndexedJoin(cte.a = two_pk.pk1)\n" +
+			"     ├─ SubqueryAlias(cte)\n" +
+			"     │   └─ Project(one_pk.pk as a, one_pk.c1 as b)\n" +
+			"     │       └─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
+			"     │           └─ Filter(one_pk.pk > 1)\n" +
+			"     │               └─ Projected table access on [pk c1]\n" +
+			"     │                   └─ Table(one_pk)\n" +
+			"     └─ Projected table access on [pk1]\n" +
+			"         └─ Table(two_pk)\n" +
+			"",
+	},
+	{
+		Query: "SELECT pk, ROW_NUMBER() OVER (PARTITION BY c1 ORDER BY pk DESC) AS n FROM one_pk WHERE pk > 1 ORDER BY n",
+		ExpectedPlan: "Sort(n ASC)\n" +
+			" └─ Window(one_pk.pk, ROW_NUMBER() OVER (PARTITION BY one_pk.c1 ORDER BY one_pk.pk DESC) as n)\n" +
+			"     └─ Indexed table access on index [one_pk.pk], with multi-range read\n" +
+			"         └─ Filter(one_pk.pk > 1)\n" +
+			"             └─ Projected table access on [pk c1]\n" +
+			"                 └─ Table(one_pk)\n" +
+			"",
+	},
 }
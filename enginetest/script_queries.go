@@ -44,6 +44,21 @@ type ScriptTestAssertion struct {
 // Unlike other engine tests, ScriptTests must be self-contained. No other tables are created outside the definition of
 // the tests.
 var ScriptTests = []ScriptTest{
+	{
+		Name: "truncate table",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (x int primary key)",
+			"create trigger a_deleted after delete on a for each row insert into b values (old.x)",
+			"insert into a values (1), (3), (5)",
+			"truncate table a",
+			"insert into a values (2)",
+		},
+		Query: "select (select count(*) from a), (select count(*) from b)",
+		Expected: []sql.Row{
+			{1, 0},
+		},
+	},
 	{
 		Name: "delete with in clause",
 		SetUpScript: []string{
@@ -108,4 +123,185 @@ var ScriptTests = []ScriptTest{
 			{7},
 		},
 	},
+	{
+		Name: "describe shows columns as MySQL does",
+		SetUpScript: []string{
+			"CREATE TABLE parent (id INT PRIMARY KEY)",
+			`CREATE TABLE child (
+				id INT UNSIGNED NOT NULL,
+				code VARCHAR(10) NOT NULL,
+				name VARCHAR(20) CHARACTER SET latin1,
+				kind ENUM('Small','Big') DEFAULT 'Big',
+				flag TINYINT DEFAULT TRUE,
+				price DECIMAL(5,2) DEFAULT 1,
+				created DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated TIMESTAMP DEFAULT NOW() ON UPDATE CURRENT_TIMESTAMP,
+				other DATETIME DEFAULT '2020-01-01',
+				parent_id INT,
+				a INT,
+				b INT,
+				UNIQUE KEY code_idx (code),
+				UNIQUE KEY name_idx (name),
+				UNIQUE KEY a_b_idx (a, b),
+				FOREIGN KEY (parent_id) REFERENCES parent(id)
+			)`,
+			"CREATE TABLE counter (id BIGINT PRIMARY KEY AUTO_INCREMENT, n YEAR)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "DESCRIBE child",
+				Expected: []sql.Row{
+					{"id", "int(10) unsigned", "NO", "", nil, ""},
+					{"code", "varchar(10)", "NO", "PRI", nil, ""},
+					{"name", "varchar(20)", "YES", "UNI", nil, ""},
+					{"kind", "enum('Small','Big')", "YES", "", "Big", ""},
+					{"flag", "tinyint(4)", "YES", "", "1", ""},
+					{"price", "decimal(5,2)", "YES", "", "1.00", ""},
+					{"created", "datetime", "YES", "", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED"},
+					{"updated", "timestamp", "YES", "", "CURRENT_TIMESTAMP", "DEFAULT_GENERATED on update CURRENT_TIMESTAMP"},
+					{"other", "datetime", "YES", "", "2020-01-01 00:00:00", ""},
+					{"parent_id", "int(11)", "YES", "MUL", nil, ""},
+					{"a", "int(11)", "YES", "MUL", nil, ""},
+					{"b", "int(11)", "YES", "", nil, ""},
+				},
+			},
+			{
+				Query: "SHOW FULL COLUMNS FROM child WHERE Field IN ('code', 'name', 'kind', 'flag')",
+				Expected: []sql.Row{
+					{"code", "varchar(10)", "utf8mb4_0900_ai_ci", "NO", "PRI", nil, "", "", ""},
+					{"name", "varchar(20)", "latin1_swedish_ci", "YES", "UNI", nil, "", "", ""},
+					{"kind", "enum('Small','Big')", "utf8mb4_0900_ai_ci", "YES", "", "Big", "", "", ""},
+					{"flag", "tinyint(4)", nil, "YES", "", "1", "", "", ""},
+				},
+			},
+			{
+				Query: "EXPLAIN counter",
+				Expected: []sql.Row{
+					{"id", "bigint(20)", "NO", "PRI", nil, "auto_increment"},
+					{"n", "year(4)", "YES", "", nil, ""},
+				},
+			},
+		},
+	},
+	{
+		Name: "optimizer hints",
+		SetUpScript: []string{
+			"CREATE TABLE hinted (pk BIGINT PRIMARY KEY, v BIGINT)",
+			"INSERT INTO hinted VALUES (1, 10), (2, 20)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "SELECT /*+ INDEX(hinted) BOGUS(hinted) NO_INDEX(hinted) */ v FROM hinted WHERE pk = 2",
+				Expected: []sql.Row{{int64(20)}},
+			},
+			{
+				Query: "SHOW WARNINGS",
+				Expected: []sql.Row{
+					{"Warning", 1064, "Optimizer hint syntax error near 'BOGUS(hinted) NO_INDEX(hinted)'"},
+				},
+			},
+			{
+				Query:    "SELECT v FROM hinted WHERE pk = 1",
+				Expected: []sql.Row{{int64(10)}},
+			},
+			{
+				Query:    "SELECT /*+ HASH_JOIN(nope) */ v FROM hinted WHERE pk = 1",
+				Expected: []sql.Row{{int64(10)}},
+			},
+			{
+				Query: "SHOW WARNINGS",
+				Expected: []sql.Row{
+					{"Warning", 3128, "Unresolved name `nope` for HASH_JOIN hint"},
+				},
+			},
+			{
+				Query:       "SELECT /*+ MAX_EXECUTION_TIME(10) */ SLEEP(1)",
+				ExpectedErr: sql.ErrMaxExecutionTimeExceeded,
+			},
+			{
+				Query:    "SELECT /*+ MAX_EXECUTION_TIME(60000) */ COUNT(*) FROM hinted",
+				Expected: []sql.Row{{int64(2)}},
+			},
+		},
+	},
+	{
+		Name: "subqueries over joins",
+		SetUpScript: []string{
+			"CREATE TABLE a (id BIGINT PRIMARY KEY)",
+			"CREATE TABLE b (id BIGINT PRIMARY KEY, aid BIGINT)",
+			"CREATE TABLE c (id BIGINT PRIMARY KEY, bid BIGINT)",
+			"INSERT INTO a VALUES (1), (2), (3), (4), (5), (6)",
+			"INSERT INTO b VALUES (1, 1), (2, 2), (3, 3), (4, 5), (5, 6)",
+			"INSERT INTO c VALUES (1, 1), (2, 2), (4, 4), (7, 1)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "SELECT (SELECT SUM(b.aid) FROM b JOIN c ON c.bid = b.id)",
+				Expected: []sql.Row{{float64(9)}},
+			},
+			{
+				Query:    "SELECT id FROM a WHERE a.id IN (SELECT b.aid FROM b JOIN c ON c.bid = b.id) ORDER BY id",
+				Expected: []sql.Row{{int64(1)}, {int64(2)}, {int64(5)}},
+			},
+			{
+				Query:    "SELECT id FROM a WHERE a.id = 1 AND a.id IN (SELECT b.aid FROM b JOIN c ON c.bid = b.id)",
+				Expected: []sql.Row{{int64(1)}},
+			},
+			{
+				Query: "SELECT id, (SELECT SUM(c.id) FROM b JOIN c ON c.bid = b.id WHERE b.aid = a.id) FROM a ORDER BY id",
+				Expected: []sql.Row{
+					{int64(1), float64(8)},
+					{int64(2), float64(2)},
+					{int64(3), nil},
+					{int64(4), nil},
+					{int64(5), float64(4)},
+					{int64(6), nil},
+				},
+			},
+			{
+				Query: "SELECT id, (SELECT SUM(c.id) FROM b JOIN c ON c.bid = a.id AND b.id = c.bid) FROM a ORDER BY id",
+				Expected: []sql.Row{
+					{int64(1), float64(8)},
+					{int64(2), float64(2)},
+					{int64(3), nil},
+					{int64(4), float64(4)},
+					{int64(5), nil},
+					{int64(6), nil},
+				},
+			},
+			{
+				Query: "SELECT id, (SELECT COUNT(*) FROM b LEFT JOIN c ON c.bid = b.id WHERE b.aid = a.id) FROM a ORDER BY id",
+				Expected: []sql.Row{
+					{int64(1), int64(2)},
+					{int64(2), int64(1)},
+					{int64(3), int64(1)},
+					{int64(4), int64(0)},
+					{int64(5), int64(1)},
+					{int64(6), int64(1)},
+				},
+			},
+			{
+				Query: "SELECT id, (SELECT SUM(b.id) FROM b RIGHT JOIN c ON c.bid = b.id WHERE c.id <= a.id) FROM a ORDER BY id",
+				Expected: []sql.Row{
+					{int64(1), float64(1)},
+					{int64(2), float64(3)},
+					{int64(3), float64(3)},
+					{int64(4), float64(7)},
+					{int64(5), float64(7)},
+					{int64(6), float64(7)},
+				},
+			},
+			{
+				Query: "SELECT id, (SELECT COUNT(*) FROM b, c WHERE b.aid = a.id) FROM a ORDER BY id",
+				Expected: []sql.Row{
+					{int64(1), int64(4)},
+					{int64(2), int64(4)},
+					{int64(3), int64(4)},
+					{int64(4), int64(0)},
+					{int64(5), int64(4)},
+					{int64(6), int64(4)},
+				},
+			},
+		},
+	},
 }
@@ -0,0 +1,314 @@
+package enginetest
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// SqlModeTests set the sql_mode of their session first, since the session is shared by the scripts of a harness.
+var SqlModeTests = []ScriptTest{
+	{
+		Name: "zero dates are valid values without NO_ZERO_DATE",
+		SetUpScript: []string{
+			"set sql_mode = 'STRICT_TRANS_TABLES'",
+			"create table t (id int primary key, d date, dt datetime)",
+			"insert into t values (1, '0000-00-00', '0000-00-00 00:00:00'), (2, '2020-01-01', '2020-01-01 10:00:00')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select id, cast(d as char), cast(dt as char) from t order by id",
+				Expected: []sql.Row{{1, "0000-00-00 00:00:00", "0000-00-00 00:00:00"}, {2, "2020-01-01 00:00:00", "2020-01-01 10:00:00"}},
+			},
+			{
+				Query:    "select id from t where d = '0000-00-00'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select id from t where dt > '0000-00-00'",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select cast('0000-00-00' as date) is null",
+				Expected: []sql.Row{{false}},
+			},
+			{
+				Query:    "update t set d = '0000-00-00' where id = 2",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+		},
+	},
+	{
+		Name: "zero dates aren't 0000-01-01",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, d date, dt datetime)",
+			"insert into t values (1, '0000-01-01', '0000-01-01 00:00:00'), (2, '0000-00-00', '0000-00-00 00:00:00')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select id, cast(d as char), cast(dt as char) from t order by id",
+				Expected: []sql.Row{{1, "0000-01-01 00:00:00", "0000-01-01 00:00:00"}, {2, "0000-00-00 00:00:00", "0000-00-00 00:00:00"}},
+			},
+			{
+				Query:    "select id from t where d = '0000-00-00'",
+				Expected: []sql.Row{{2}},
+			},
+			{
+				Query:    "select id from t where dt = '0000-01-01'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select id from t order by d",
+				Expected: []sql.Row{{2}, {1}},
+			},
+			{
+				Query:    "select id, year(d), month(d), day(d), dayofyear(d) from t order by id",
+				Expected: []sql.Row{{1, int32(0), int32(1), int32(1), int32(1)}, {2, int32(0), int32(0), int32(0), nil}},
+			},
+			{
+				Query:    "select cast('0000-00-00' as date) = cast('0000-01-01' as date), cast(cast('0000-01-01' as date) as char)",
+				Expected: []sql.Row{{false, "0000-01-01 00:00:00"}},
+			},
+		},
+	},
+	{
+		Name: "strict mode rejects zero dates with NO_ZERO_DATE and invalid temporal values",
+		SetUpScript: []string{
+			"set sql_mode = 'TRADITIONAL'",
+			"create table t (id int primary key, d date, dt datetime)",
+			"insert into t values (1, '2020-01-01', null)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into t values (2, '0000-00-00', null)",
+				ExpectedErr: sql.ErrIncorrectTemporalValue,
+			},
+			{
+				Query:       "insert into t values (2, null, 'garbage')",
+				ExpectedErr: sql.ErrIncorrectTemporalValue,
+			},
+			{
+				Query:       "insert into t values (2, '2020-02-30', null)",
+				ExpectedErr: sql.ErrIncorrectTemporalValue,
+			},
+			{
+				Query:       "update t set d = '0000-00-00'",
+				ExpectedErr: sql.ErrIncorrectTemporalValue,
+			},
+			{
+				Query:    "select id, cast(d as char) from t",
+				Expected: []sql.Row{{1, "2020-01-01 00:00:00"}},
+			},
+		},
+	},
+	{
+		Name: "non-strict mode writes zero dates with warnings with NO_ZERO_DATE",
+		SetUpScript: []string{
+			"set sql_mode = 'NO_ZERO_DATE'",
+			"create table t (id int primary key, d date)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1, '0000-00-00')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1264, "Out of range value for column 'd' at row 1"}},
+			},
+			{
+				Query:    "select id, cast(d as char) from t",
+				Expected: []sql.Row{{1, "0000-00-00 00:00:00"}},
+			},
+		},
+	},
+	{
+		Name: "non-strict mode writes invalid temporal values as zero dates with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, d date)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1, '2020-01-01'), (2, '2020-13-01')",
+				Expected: []sql.Row{{sql.NewOkResult(2)}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1265, "Data truncated for column 'd' at row 2"}},
+			},
+			{
+				Query:    "select id, cast(d as char) from t order by id",
+				Expected: []sql.Row{{1, "2020-01-01 00:00:00"}, {2, "0000-00-00 00:00:00"}},
+			},
+		},
+	},
+	{
+		Name: "casts of zero dates are NULL with warnings with NO_ZERO_DATE",
+		SetUpScript: []string{
+			"set sql_mode = 'NO_ZERO_DATE'",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select cast('0000-00-00' as date)",
+				Expected: []sql.Row{{nil}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1292, "Incorrect date value: '0000-00-00'"}},
+			},
+		},
+	},
+	{
+		Name: "casts of invalid temporal values are NULL with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select cast('garbage' as datetime)",
+				Expected: []sql.Row{{nil}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1292, "Incorrect datetime value: 'garbage'"}},
+			},
+		},
+	},
+	{
+		Name: "comparisons with invalid temporal values compare strings with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, d date)",
+			"insert into t values (1, '2020-01-01')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "select id from t where d = '2020-01-01'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select id from t where d < '2020-01-01 10:00:00'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "select id from t where d < 'garbage'",
+				Expected: []sql.Row{{1}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1292, "Incorrect date value: 'garbage'"}},
+			},
+		},
+	},
+	{
+		Name: "strict mode rejects values out of range, too long or invalid",
+		SetUpScript: []string{
+			"set sql_mode = 'STRICT_ALL_TABLES'",
+			"create table t (id int primary key, i tinyint, u int unsigned, d decimal(4,2), s varchar(3))",
+			"insert into t values (1, 5, 5, 1.5, 'abc')",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:       "insert into t values (2, 300, null, null, null)",
+				ExpectedErr: sql.ErrValueOutOfRange,
+			},
+			{
+				Query:       "insert into t values (2, 'one', null, null, null)",
+				ExpectedErr: sql.ErrIncorrectValue,
+			},
+			{
+				Query:       "insert into t values (2, '12abc', null, null, null)",
+				ExpectedErr: sql.ErrDataTruncated,
+			},
+			{
+				Query:       "insert into t values (2, null, null, 1000, null)",
+				ExpectedErr: sql.ErrValueOutOfRange,
+			},
+			{
+				Query:       "insert into t values (2, null, null, null, 'abcd')",
+				ExpectedErr: sql.ErrDataTooLong,
+			},
+			{
+				Query:       "update t set u = -1",
+				ExpectedErr: sql.ErrValueOutOfRange,
+			},
+			{
+				Query:       "update t set s = 'abcd'",
+				ExpectedErr: sql.ErrDataTooLong,
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, 5, uint64(5), "1.50", "abc"}},
+			},
+		},
+	},
+	{
+		Name: "non-strict mode clamps numbers out of range and truncates strings too long with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, i tinyint, u int unsigned, d decimal(4,2), s varchar(3))",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1, 300, -1, -1000, 'abcd')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query: "show warnings",
+				Expected: []sql.Row{
+					{"Warning", 1265, "Data truncated for column 's' at row 1"},
+					{"Warning", 1264, "Out of range value for column 'd' at row 1"},
+					{"Warning", 1264, "Out of range value for column 'u' at row 1"},
+					{"Warning", 1264, "Out of range value for column 'i' at row 1"},
+				},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, 127, uint64(0), "-99.99", "abc"}},
+			},
+		},
+	},
+	{
+		Name: "strings starting with numbers are truncated to them with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, i tinyint)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "insert into t values (1, '12abc')",
+				Expected: []sql.Row{{sql.NewOkResult(1)}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1265, "Data truncated for column 'i' at row 1"}},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, 12}},
+			},
+		},
+	},
+	{
+		Name: "non-strict mode updates invalid numbers to zero with warnings",
+		SetUpScript: []string{
+			"set sql_mode = ''",
+			"create table t (id int primary key, i tinyint)",
+			"insert into t values (1, 5)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query:    "update t set i = 'one'",
+				Expected: []sql.Row{{newUpdateResult(1, 1)}},
+			},
+			{
+				Query:    "show warnings",
+				Expected: []sql.Row{{"Warning", 1366, "Incorrect integer value: 'one' for column 'i' at row 1"}},
+			},
+			{
+				Query:    "select * from t",
+				Expected: []sql.Row{{1, 0}},
+			},
+		},
+	},
+}
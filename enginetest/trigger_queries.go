@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/parse"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
@@ -1586,6 +1587,133 @@ var TriggerTests = []ScriptTest{
 			},
 		},
 	},
+	// Compound statements
+	{
+		Name: "trigger with a cursor loop",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (y int primary key)",
+			"create table totals (x int primary key, total int, rows_read int)",
+			"insert into b values (1), (2), (3)",
+			`create trigger sum_b after insert on a for each row
+			BEGIN
+				DECLARE done INT DEFAULT FALSE;
+				DECLARE total, val, n INT DEFAULT 0;
+				DECLARE cur CURSOR FOR SELECT b.y FROM b WHERE b.y <= new.x;
+				DECLARE CONTINUE HANDLER FOR NOT FOUND SET done = TRUE;
+				OPEN cur;
+				read_loop: LOOP
+					FETCH cur INTO val;
+					IF done THEN
+						LEAVE read_loop;
+					END IF;
+					SET total = total + val, n = n + 1;
+				END LOOP;
+				CLOSE cur;
+				INSERT INTO totals VALUES (new.x, total, n);
+			END`,
+			"insert into a values (1), (3), (10)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select * from totals order by 1",
+				Expected: []sql.Row{
+					{1, 1, 1}, {3, 6, 3}, {10, 6, 3},
+				},
+			},
+		},
+	},
+	{
+		Name: "trigger with condition handlers",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (y int primary key)",
+			"create table errors (x int primary key, handler varchar(20))",
+			`create trigger handle_errors after insert on a for each row
+			BEGIN
+				DECLARE EXIT HANDLER FOR SQLEXCEPTION INSERT INTO errors VALUES (new.x, 'exit');
+				BEGIN
+					DECLARE CONTINUE HANDLER FOR 1062 INSERT INTO errors VALUES (new.x + 100, 'continue');
+					INSERT INTO b VALUES (new.x);
+					INSERT INTO b VALUES (new.x);
+					INSERT INTO b VALUES (new.x + 1);
+				END;
+				IF new.x > 5 THEN
+					SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'too big';
+				END IF;
+				INSERT INTO b VALUES (new.x + 2);
+			END`,
+			"insert into a values (1), (10)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select y from b order by 1",
+				Expected: []sql.Row{
+					{1}, {2}, {3}, {10}, {11},
+				},
+			},
+			{
+				Query: "select * from errors order by 1",
+				Expected: []sql.Row{
+					{10, "exit"}, {101, "continue"}, {110, "continue"},
+				},
+			},
+		},
+	},
+	{
+		Name: "trigger with while, repeat and if",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table evens (x int primary key)",
+			"create table odds (x int primary key)",
+			"create table counts (x int primary key, n int)",
+			`create trigger numbers after insert on a for each row
+			BEGIN
+				DECLARE i INT DEFAULT 0;
+				DECLARE n INT DEFAULT 0;
+				numbers: WHILE i < new.x DO
+					SET i = i + 1;
+					IF i = 3 THEN
+						ITERATE numbers;
+					ELSEIF i % 2 = 0 THEN
+						INSERT INTO evens VALUES (new.x * 100 + i);
+					ELSE
+						INSERT INTO odds VALUES (new.x * 100 + i);
+					END IF;
+				END WHILE numbers;
+				REPEAT
+					SET i = i - 2, n = n + 1;
+				UNTIL i <= 0 END REPEAT;
+				counting: BEGIN
+					IF n > 2 THEN
+						LEAVE counting;
+					END IF;
+					INSERT INTO counts VALUES (new.x, n);
+				END counting;
+			END`,
+			"insert into a values (1), (4), (6)",
+		},
+		Assertions: []ScriptTestAssertion{
+			{
+				Query: "select x from evens order by 1",
+				Expected: []sql.Row{
+					{402}, {404}, {602}, {604}, {606},
+				},
+			},
+			{
+				Query: "select x from odds order by 1",
+				Expected: []sql.Row{
+					{101}, {401}, {601}, {605},
+				},
+			},
+			{
+				Query: "select * from counts order by 1",
+				Expected: []sql.Row{
+					{1, 1}, {4, 2},
+				},
+			},
+		},
+	},
 }
 
 var TriggerErrorTests = []ScriptTest{
@@ -1696,6 +1824,60 @@ var TriggerErrorTests = []ScriptTest{
 		Query:       "create trigger update_new after update on x for each row BEGIN set new.c = new.a + 1; END",
 		ExpectedErr: sql.ErrInvalidUpdateInAfterTrigger,
 	},
+	{
+		Name: "fetch from a cursor that isn't open",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (y int primary key)",
+			`create trigger fetch_closed after insert on a for each row
+			BEGIN
+				DECLARE val INT;
+				DECLARE cur CURSOR FOR SELECT y FROM b;
+				FETCH cur INTO val;
+			END`,
+		},
+		Query:       "insert into a values (1)",
+		ExpectedErr: plan.ErrCursorNotOpen,
+	},
+	{
+		Name: "fetch into the wrong number of variables",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (y int primary key, z int)",
+			`create trigger fetch_columns after insert on a for each row
+			BEGIN
+				DECLARE val INT;
+				DECLARE cur CURSOR FOR SELECT y, z FROM b;
+				OPEN cur;
+				FETCH cur INTO val;
+			END`,
+		},
+		Query:       "insert into a values (1)",
+		ExpectedErr: plan.ErrFetchVariables,
+	},
+	{
+		Name: "open a cursor twice",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+			"create table b (y int primary key)",
+			`create trigger open_twice after insert on a for each row
+			BEGIN
+				DECLARE cur CURSOR FOR SELECT y FROM b;
+				OPEN cur;
+				OPEN cur;
+			END`,
+		},
+		Query:       "insert into a values (1)",
+		ExpectedErr: plan.ErrCursorAlreadyOpen,
+	},
+	{
+		Name: "undefined cursor",
+		SetUpScript: []string{
+			"create table a (x int primary key)",
+		},
+		Query:       "create trigger undefined_cursor after insert on a for each row BEGIN OPEN cur; END",
+		ExpectedErr: parse.ErrUndefinedCursor,
+	},
 	// TODO: mysql doesn't consider this an error until execution time, but we could catch it earlier
 	// {
 	// 	Name:        "column doesn't exist",
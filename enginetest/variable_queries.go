@@ -114,6 +114,69 @@ var VariableQueries = []ScriptTest{
 			{"utf8mb4", "utf8mb4", "utf8mb4"},
 		},
 	},
+	{
+		Name: "set names sets the collation of the connection",
+		SetUpScript: []string{
+			`set collation_connection = 'binary'`,
+			`set names utf8mb4`,
+		},
+		Query: "SELECT @@collation_connection",
+		Expected: []sql.Row{
+			{"utf8mb4_0900_ai_ci"},
+		},
+	},
+	{
+		Name: "set character set",
+		SetUpScript: []string{
+			`set character set utf8`,
+		},
+		Query: "SELECT @@character_set_client, @@character_set_results, @@character_set_connection, @@collation_connection",
+		Expected: []sql.Row{
+			{"utf8", "utf8", "utf8mb4", "utf8mb4_0900_ai_ci"},
+		},
+	},
+	{
+		Name: "set transaction isolation level",
+		SetUpScript: []string{
+			`SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED`,
+		},
+		Query: "SELECT @@session.transaction_isolation, @@session.tx_isolation",
+		Expected: []sql.Row{
+			{"READ-COMMITTED", "READ-COMMITTED"},
+		},
+	},
+	{
+		Name: "set transaction access mode",
+		SetUpScript: []string{
+			`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, READ ONLY`,
+		},
+		Query: "SELECT @@transaction_isolation, @@transaction_read_only, @@tx_read_only",
+		Expected: []sql.Row{
+			{"SERIALIZABLE", int8(1), int8(1)},
+		},
+	},
+	{
+		Name: "connector bootstrap variables",
+		Query: "SELECT @@character_set_server AS character_set_server, " +
+			"@@collation_server AS collation_server, @@init_connect AS init_connect, @@interactive_timeout AS interactive_timeout, " +
+			"@@license AS license, @@net_write_timeout AS net_write_timeout, @@performance_schema AS performance_schema, " +
+			"@@query_cache_size AS query_cache_size, @@wait_timeout AS wait_timeout, " +
+			"@@sql_auto_is_null, @@version",
+		Expected: []sql.Row{
+			{"utf8mb4", "utf8mb4_0900_ai_ci", "", int64(28800), "GPL", int64(60), int8(0), int64(1048576), int64(28800), int8(0), "8.0.11"},
+		},
+	},
+	{
+		Name: "show variables where",
+		SetUpScript: []string{
+			`SET TRANSACTION ISOLATION LEVEL REPEATABLE READ`,
+		},
+		Query: "SHOW VARIABLES WHERE Variable_name = 'wait_timeout' OR Variable_name = 'tx_isolation' OR Variable_name = 'language'",
+		Expected: []sql.Row{
+			{"tx_isolation", "REPEATABLE-READ"},
+			{"wait_timeout", int64(28800)},
+		},
+	},
 	// TODO: we should validate the character set here
 	{
 		Name: "set names quoted",
@@ -0,0 +1,135 @@
+package files
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// CSV is the format of comma separated files whose first row has the names of their columns.
+var CSV Format = csvFormat{}
+
+type csvFormat struct{}
+
+func (csvFormat) Extensions() []string {
+	return []string{".csv"}
+}
+
+func (csvFormat) Schema(path, source string) (sql.Schema, error) {
+	iter, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	inferences := make([]typeInference, len(iter.header))
+	for n := 0; n < inferenceRows; n++ {
+		record, err := iter.reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range inferences {
+			if i < len(record) {
+				inferences[i].add(textKind(record[i]))
+			}
+		}
+	}
+
+	schema := make(sql.Schema, len(iter.header))
+	for i, name := range iter.header {
+		schema[i] = &sql.Column{
+			Name:     name,
+			Type:     inferences[i].Type(),
+			Nullable: true,
+			Source:   source,
+		}
+	}
+
+	return schema, nil
+}
+
+func (csvFormat) Rows(path string, schema sql.Schema) (sql.RowIter, error) {
+	iter, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+
+	iter.schema = schema
+	iter.columns = make([]int, len(schema))
+	for i, col := range schema {
+		iter.columns[i] = -1
+		for j, name := range iter.header {
+			if strings.EqualFold(name, col.Name) {
+				iter.columns[i] = j
+				break
+			}
+		}
+	}
+
+	return iter, nil
+}
+
+type csvIter struct {
+	file   *os.File
+	reader *csv.Reader
+	header []string
+	schema sql.Schema
+	// columns has the index in the records of each column of the schema, or -1 if it's missing in the file
+	columns []int
+}
+
+func openCSV(path string) (*csvIter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err == io.EOF {
+		header = nil
+	} else if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	r.ReuseRecord = true
+	return &csvIter{file: f, reader: r, header: header}, nil
+}
+
+func (i *csvIter) Next() (sql.Row, error) {
+	if i.header == nil {
+		return nil, io.EOF
+	}
+
+	record, err := i.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(sql.Row, len(i.schema))
+	for j, col := range i.columns {
+		if col == -1 || col >= len(record) {
+			continue
+		}
+
+		row[j], err = convertText(i.schema[j].Type, record[col])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return row, nil
+}
+
+func (i *csvIter) Close() error {
+	return i.file.Close()
+}
@@ -0,0 +1,181 @@
+package files
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Database is a read-only database whose tables are the files of a directory. Every file with the extension of one
+// of its formats is a table, named like the file without its extension. So is every subdirectory whose files all
+// have the format of one of its formats, which are the partitions of the table, so they can be read in parallel.
+//
+// The schema of a table is inferred from the first rows of its first file, every time the table is requested, so
+// changes to the files are seen by the next query.
+type Database struct {
+	name    string
+	dir     string
+	formats []Format
+}
+
+var _ sql.Database = (*Database)(nil)
+
+// NewDatabase creates a new database with the name given for the files in the directory given. If no formats are
+// given, CSV and JSONL files are read.
+func NewDatabase(name, dir string, formats ...Format) *Database {
+	if len(formats) == 0 {
+		formats = []Format{CSV, JSONL}
+	}
+
+	return &Database{
+		name:    name,
+		dir:     dir,
+		formats: formats,
+	}
+}
+
+// Name returns the database name.
+func (d *Database) Name() string {
+	return d.name
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	tables, err := d.tables()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var names []string
+	for _, t := range tables {
+		names = append(names, t.name)
+	}
+
+	name, ok := sql.GetTableNameInsensitive(tblName, names)
+	if !ok {
+		return nil, false, nil
+	}
+
+	for _, t := range tables {
+		if t.name == name {
+			t.schema, err = t.format.Schema(t.paths[0], t.name)
+			if err != nil {
+				return nil, false, err
+			}
+			return t, true, nil
+		}
+	}
+
+	return nil, false, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	tables, err := d.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.name
+	}
+	return names, nil
+}
+
+// tables returns the tables of the directory, without their schema, sorted by name. If a file and a directory would
+// have the same table name, the file is used.
+func (d *Database) tables() ([]*Table, error) {
+	entries, err := ioutil.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*Table
+	seen := make(map[string]bool)
+	add := func(t *Table) {
+		if t != nil && !seen[strings.ToLower(t.name)] {
+			seen[strings.ToLower(t.name)] = true
+			tables = append(tables, t)
+		}
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			add(d.fileTable(e.Name()))
+		}
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			t, err := d.dirTable(e.Name())
+			if err != nil {
+				return nil, err
+			}
+			add(t)
+		}
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].name < tables[j].name
+	})
+
+	return tables, nil
+}
+
+// fileTable returns the table of the file given, or nil if it doesn't have the format of any of the formats.
+func (d *Database) fileTable(file string) *Table {
+	format, name := d.format(file)
+	if format == nil || name == "" {
+		return nil
+	}
+
+	return &Table{name: name, format: format, paths: []string{filepath.Join(d.dir, file)}}
+}
+
+// dirTable returns the table of the directory given, or nil if it doesn't have files or they don't all have the
+// same format.
+func (d *Database) dirTable(dir string) (*Table, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(d.dir, dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var format Format
+	var paths []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		f, _ := d.format(e.Name())
+		if f == nil || (format != nil && f != format) {
+			return nil, nil
+		}
+
+		format = f
+		paths = append(paths, filepath.Join(d.dir, dir, e.Name()))
+	}
+
+	if format == nil {
+		return nil, nil
+	}
+
+	return &Table{name: dir, format: format, paths: paths}, nil
+}
+
+// format returns the format of the file given and its name without extension, or nil if it has none of the formats.
+func (d *Database) format(file string) (Format, string) {
+	ext := strings.ToLower(filepath.Ext(file))
+	for _, f := range d.formats {
+		for _, e := range f.Extensions() {
+			if ext == e {
+				return f, strings.TrimSuffix(file, filepath.Ext(file))
+			}
+		}
+	}
+	return nil, ""
+}
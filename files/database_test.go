@@ -0,0 +1,141 @@
+package files_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/files"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func testDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "files")
+	require.NoError(t, err)
+
+	write := func(name, contents string) {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	write("people.csv", "id,name,score,active\n1,ann,1.5,true\n2,bob,,false\n3,\"cat, jr\",3,TRUE\n")
+	write("events.jsonl", `{"id": 1, "kind": "login", "tags": ["a", "b"]}
+{"id": 2, "kind": "logout", "person": 2}
+{"kind": "login", "id": 3, "person": 1, "tags": {"c": 1}}
+`)
+	write("logs/2020.csv", "person,message\n1,first\n2,second\n")
+	write("logs/2021.csv", "message,person\nthird,1\n")
+	write("mixed/a.csv", "a\n1\n")
+	write("mixed/b.jsonl", `{"a": 1}`)
+	write("notes.txt", "not a table")
+
+	return dir
+}
+
+func TestDatabaseTables(t *testing.T) {
+	require := require.New(t)
+
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	db := files.NewDatabase("files", dir)
+	ctx := sql.NewEmptyContext()
+
+	names, err := db.GetTableNames(ctx)
+	require.NoError(err)
+	require.Equal([]string{"events", "logs", "people"}, names)
+
+	table, ok, err := db.GetTableInsensitive(ctx, "PEOPLE")
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(sql.Schema{
+		{Name: "id", Type: sql.Int64, Nullable: true, Source: "people"},
+		{Name: "name", Type: sql.LongText, Nullable: true, Source: "people"},
+		{Name: "score", Type: sql.Float64, Nullable: true, Source: "people"},
+		{Name: "active", Type: sql.Boolean, Nullable: true, Source: "people"},
+	}, table.Schema())
+
+	table, ok, err = db.GetTableInsensitive(ctx, "events")
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(sql.Schema{
+		{Name: "id", Type: sql.Int64, Nullable: true, Source: "events"},
+		{Name: "kind", Type: sql.LongText, Nullable: true, Source: "events"},
+		{Name: "tags", Type: sql.JSON, Nullable: true, Source: "events"},
+		{Name: "person", Type: sql.Int64, Nullable: true, Source: "events"},
+	}, table.Schema())
+
+	table, ok, err = db.GetTableInsensitive(ctx, "logs")
+	require.NoError(err)
+	require.True(ok)
+
+	count, err := table.(sql.PartitionCounter).PartitionCount(ctx)
+	require.NoError(err)
+	require.Equal(int64(2), count)
+
+	_, ok, err = db.GetTableInsensitive(ctx, "notes")
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestDatabaseQueries(t *testing.T) {
+	dir := testDir(t)
+	defer os.RemoveAll(dir)
+
+	e := sqle.NewDefault()
+	e.AddDatabase(files.NewDatabase("files", dir))
+
+	tests := []struct {
+		query    string
+		expected []sql.Row
+	}{
+		{
+			"SELECT * FROM people ORDER BY id",
+			[]sql.Row{
+				{int64(1), "ann", 1.5, int8(1)},
+				{int64(2), "bob", nil, int8(0)},
+				{int64(3), "cat, jr", float64(3), int8(1)},
+			},
+		},
+		{
+			"SELECT id, kind, tags, person FROM events WHERE person IS NOT NULL ORDER BY id",
+			[]sql.Row{
+				{int64(2), "logout", nil, int64(2)},
+				{int64(3), "login", []byte(`{"c":1}`), int64(1)},
+			},
+		},
+		{
+			"SELECT person, message FROM logs ORDER BY message",
+			[]sql.Row{
+				{int64(1), "first"},
+				{int64(2), "second"},
+				{int64(1), "third"},
+			},
+		},
+		{
+			"SELECT p.name, COUNT(*) FROM people p JOIN logs l ON p.id = l.person GROUP BY p.name ORDER BY p.name",
+			[]sql.Row{
+				{"ann", int64(2)},
+				{"bob", int64(1)},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := sql.NewContext(context.Background(), sql.WithPid(uint64(i+1))).WithCurrentDB("files")
+			_, iter, err := e.Query(ctx, tt.query)
+			require.NoError(t, err)
+
+			rows, err := sql.RowIterToRows(iter)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rows)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package files
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// inferenceRows is the number of rows of a file read to infer its schema.
+const inferenceRows = 100
+
+// Format reads the files of one format as tables.
+type Format interface {
+	// Extensions returns the extensions of the files of this format, such as ".csv".
+	Extensions() []string
+	// Schema infers the schema of the rows of the file at the path given. Its columns have the source given.
+	Schema(path, source string) (sql.Schema, error)
+	// Rows returns an iterator over the rows of the file at the path given, converted to the schema given. Columns
+	// are matched by name, so the files of a table can have their columns in different orders, and columns missing
+	// in a file are NULL.
+	Rows(path string, schema sql.Schema) (sql.RowIter, error)
+}
+
+// valueKind is the kind of a value read from a file, which decides the type of the column it's in.
+type valueKind byte
+
+const (
+	kindNull valueKind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindText
+	kindJSON
+)
+
+// typeInference infers the type of a column from the kinds of the values in it.
+type typeInference struct {
+	kinds map[valueKind]bool
+}
+
+func (i *typeInference) add(k valueKind) {
+	if k == kindNull {
+		return
+	}
+	if i.kinds == nil {
+		i.kinds = make(map[valueKind]bool)
+	}
+	i.kinds[k] = true
+}
+
+// Type returns the narrowest type that can hold all the values seen: integers are widened to floats, and any other
+// mix of kinds is read as text, or as JSON if there are JSON documents. Columns without values are text.
+func (i *typeInference) Type() sql.Type {
+	switch {
+	case i.kinds[kindJSON]:
+		return sql.JSON
+	case i.kinds[kindText]:
+		return sql.LongText
+	case i.kinds[kindBool]:
+		if len(i.kinds) == 1 {
+			return sql.Boolean
+		}
+		return sql.LongText
+	case i.kinds[kindFloat]:
+		return sql.Float64
+	case i.kinds[kindInt]:
+		return sql.Int64
+	default:
+		return sql.LongText
+	}
+}
+
+// textKind returns the kind of a value given as text. Empty values are NULL.
+func textKind(s string) valueKind {
+	if s == "" {
+		return kindNull
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return kindInt
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return kindFloat
+	}
+	if isBool(s) {
+		return kindBool
+	}
+	return kindText
+}
+
+func isBool(s string) bool {
+	return strings.EqualFold(s, "true") || strings.EqualFold(s, "false")
+}
+
+// convertText converts a value given as text to the type given. Empty values are NULL.
+func convertText(typ sql.Type, s string) (interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if typ == sql.Boolean && isBool(s) {
+		if strings.EqualFold(s, "true") {
+			return int8(1), nil
+		}
+		return int8(0), nil
+	}
+
+	return typ.Convert(s)
+}
+
+// jsonKind returns the kind of a value decoded from JSON with numbers kept as json.Number.
+func jsonKind(v interface{}) valueKind {
+	switch v := v.(type) {
+	case nil:
+		return kindNull
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return kindInt
+		}
+		return kindFloat
+	case bool:
+		return kindBool
+	case string:
+		return kindText
+	default:
+		return kindJSON
+	}
+}
+
+// convertJSON converts a value decoded from JSON to the type given.
+func convertJSON(typ sql.Type, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		return typ.Convert(v.String())
+	case bool:
+		if typ == sql.Boolean {
+			if v {
+				return int8(1), nil
+			}
+			return int8(0), nil
+		}
+		return typ.Convert(strconv.FormatBool(v))
+	case string:
+		return typ.Convert(v)
+	default:
+		if typ == sql.JSON {
+			return typ.Convert(v)
+		}
+
+		doc, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return typ.Convert(string(doc))
+	}
+}
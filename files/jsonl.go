@@ -0,0 +1,170 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// JSONL is the format of files with a JSON object in every line, also known as newline delimited JSON. The columns
+// of a table are the keys of its objects, in the order they're first seen.
+var JSONL Format = jsonlFormat{}
+
+type jsonlFormat struct{}
+
+func (jsonlFormat) Extensions() []string {
+	return []string{".jsonl", ".ndjson"}
+}
+
+func (jsonlFormat) Schema(path, source string) (sql.Schema, error) {
+	iter, err := openJSONL(path)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var names []string
+	inferences := make(map[string]*typeInference)
+	for n := 0; n < inferenceRows; n++ {
+		keys, values, err := iter.nextObject()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i, key := range keys {
+			inference, ok := inferences[key]
+			if !ok {
+				inference = &typeInference{}
+				inferences[key] = inference
+				names = append(names, key)
+			}
+			inference.add(jsonKind(values[i]))
+		}
+	}
+
+	schema := make(sql.Schema, len(names))
+	for i, name := range names {
+		schema[i] = &sql.Column{
+			Name:     name,
+			Type:     inferences[name].Type(),
+			Nullable: true,
+			Source:   source,
+		}
+	}
+
+	return schema, nil
+}
+
+func (jsonlFormat) Rows(path string, schema sql.Schema) (sql.RowIter, error) {
+	iter, err := openJSONL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	iter.schema = schema
+	return iter, nil
+}
+
+type jsonlIter struct {
+	file    *os.File
+	decoder *json.Decoder
+	schema  sql.Schema
+}
+
+func openJSONL(path string) (*jsonlIter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bufio.NewReader(f))
+	decoder.UseNumber()
+	return &jsonlIter{file: f, decoder: decoder}, nil
+}
+
+// nextObject returns the keys of the next object of the file, in order, and their values.
+func (i *jsonlIter) nextObject() ([]string, []interface{}, error) {
+	tok, err := i.decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected JSON object in %s, found %v", i.file.Name(), tok)
+	}
+
+	var keys []string
+	var values []interface{}
+	for i.decoder.More() {
+		tok, err := i.decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var raw json.RawMessage
+		if err := i.decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		value, err := decodeJSON(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys = append(keys, tok.(string))
+		values = append(values, value)
+	}
+
+	// Consume the closing brace of the object
+	if _, err := i.decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return keys, values, nil
+}
+
+func decodeJSON(raw json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (i *jsonlIter) Next() (sql.Row, error) {
+	keys, values, err := i.nextObject()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(sql.Row, len(i.schema))
+	for j, col := range i.schema {
+		for k, key := range keys {
+			if strings.EqualFold(key, col.Name) {
+				row[j], err = convertJSON(col.Type, values[k])
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+
+	return row, nil
+}
+
+func (i *jsonlIter) Close() error {
+	return i.file.Close()
+}
@@ -0,0 +1,84 @@
+package files
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Table is a table whose rows are read from files, each of which is a partition of the table.
+type Table struct {
+	name   string
+	format Format
+	paths  []string
+	schema sql.Schema
+}
+
+var _ sql.Table = (*Table)(nil)
+var _ sql.PartitionCounter = (*Table)(nil)
+
+// Name implements the sql.Nameable interface.
+func (t *Table) Name() string {
+	return t.name
+}
+
+func (t *Table) String() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *Table) Schema() sql.Schema {
+	return t.schema
+}
+
+// Paths returns the paths of the files of this table.
+func (t *Table) Paths() []string {
+	return t.paths
+}
+
+// Partitions implements the sql.Table interface.
+func (t *Table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{paths: t.paths}, nil
+}
+
+// PartitionCount implements the sql.PartitionCounter interface.
+func (t *Table) PartitionCount(*sql.Context) (int64, error) {
+	return int64(len(t.paths)), nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *Table) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	path := string(partition.Key())
+	for _, p := range t.paths {
+		if p == path {
+			return t.format.Rows(path, t.schema)
+		}
+	}
+
+	return nil, fmt.Errorf("partition not found: %q", partition.Key())
+}
+
+type partition string
+
+func (p partition) Key() []byte {
+	return []byte(p)
+}
+
+type partitionIter struct {
+	paths []string
+	pos   int
+}
+
+func (i *partitionIter) Next(*sql.Context) (sql.Partition, error) {
+	if i.pos >= len(i.paths) {
+		return nil, io.EOF
+	}
+
+	i.pos++
+	return partition(i.paths[i.pos-1]), nil
+}
+
+func (i *partitionIter) Close() error {
+	return nil
+}
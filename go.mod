@@ -23,6 +23,7 @@ require (
 	github.com/src-d/go-oniguruma v1.1.0
 	github.com/stretchr/testify v1.4.0
 	github.com/tebeka/strftime v0.1.4 // indirect
+	golang.org/x/text v0.3.2
 	google.golang.org/appengine v1.6.5 // indirect
 	google.golang.org/grpc v1.27.0 // indirect
 	gopkg.in/src-d/go-errors.v1 v1.0.0
@@ -0,0 +1,337 @@
+package sqle
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	// keysRegex matches the ALTER TABLE ... DISABLE KEYS and ENABLE KEYS statements mysqldump writes around the rows
+	// of tables. Imports defer the checks of keys by themselves, so they're left out.
+	keysRegex = regexp.MustCompile(`(?is)^alter\s+table\s+\S+\s+(?:disable|enable)\s+keys$`)
+	// definerRegex matches the clauses of the CREATE VIEW and CREATE TRIGGER statements of mysqldump that name the
+	// account they run as and how views are run, which are left out, since the engine has no accounts.
+	definerRegex = regexp.MustCompile("(?is)^(create\\s+(?:or\\s+replace\\s+)?)(?:algorithm\\s*=\\s*\\w+\\s+)?" +
+		"(?:definer\\s*=\\s*(?:`[^`]*`|'[^']*'|[^\\s@]+)(?:@(?:`[^`]*`|'[^']*'|\\S+))?\\s+)?" +
+		"(?:sql\\s+security\\s+\\w+\\s+)?((?:view|trigger)\\s)")
+)
+
+// Import runs the statements of a script in the format of mysqldump read from the reader given, such as a dump of
+// Engine.Dump or of mysqldump, in the session of the context given. Scripts are read like the mysql client reads
+// them: their conditional comments, like /*!40101 SET NAMES utf8mb4 */, run if their version isn't newer than the
+// one of the engine, and DELIMITER lines change the delimiter of the statements. The DEFINER, ALGORITHM and SQL
+// SECURITY clauses of views and triggers are ignored.
+//
+// Imports are faster than running the statements of the script one at a time. INSERT statements of the values of all
+// the columns of tables without INSERT triggers insert their rows directly, without analyzing the statements, unless the
+// tables have foreign keys and the session didn't turn off foreign_key_checks, as dumps do. The rows
+// of consecutive INSERT statements of the same table are inserted in bulk if the table is a sql.BulkInsertableTable,
+// so the checks of its unique keys are deferred until the next statement of another table. The rest of the
+// statements run like queries. Imports stop at the first statement that fails, returning an ErrImportStatement error
+// with the line of the script it starts on.
+func (e *Engine) Import(ctx *sql.Context, r io.Reader) error {
+	if err := e.Auth.Allowed(ctx, auth.ReadPerm|auth.WritePerm); err != nil {
+		return err
+	}
+
+	imp := &importer{engine: e, ctx: ctx}
+	scanner := parse.NewScriptScanner(r)
+	for scanner.Scan() {
+		if err := imp.run(scanner.Statement(), scanner.Line()); err != nil {
+			_ = imp.flush()
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = imp.flush()
+		return err
+	}
+	return imp.flush()
+}
+
+// importer runs the statements of an import.
+type importer struct {
+	engine *Engine
+	ctx    *sql.Context
+
+	// table is the table whose rows are being inserted by inserter, and line is the line of the first INSERT
+	// statement of the rows.
+	table    sql.Table
+	inserter sql.RowInserter
+	line     int
+	// triggers are the names of the tables with INSERT triggers of each database, in lower case.
+	triggers map[string]map[string]bool
+}
+
+// run runs the statement on the line given of the script.
+func (i *importer) run(query string, line int) error {
+	if keysRegex.MatchString(query) {
+		return nil
+	}
+	query = definerRegex.ReplaceAllString(query, "$1$2")
+
+	if len(query) > len("insert") && strings.EqualFold(query[:len("insert")], "insert") {
+		ok, err := i.insert(query, line)
+		if err != nil {
+			// The checks of rows inserted in bulk fail with the line of their first statement
+			if sql.ErrImportStatement.Is(err) {
+				return err
+			}
+			return sql.ErrImportStatement.Wrap(err, line)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	if err := i.flush(); err != nil {
+		return err
+	}
+
+	// The statement may create or drop triggers
+	i.triggers = nil
+	_, iter, err := i.engine.Query(i.ctx, query)
+	if err == nil {
+		_, err = sql.RowIterToRows(iter)
+	}
+	if err != nil {
+		return sql.ErrImportStatement.Wrap(err, line)
+	}
+	return nil
+}
+
+// insert inserts the rows of the INSERT statement given directly into its table, returning false if the statement
+// must run as a query instead because it isn't an INSERT of values of all the columns of a table without INSERT
+// triggers, or foreign keys to enforce on its rows.
+func (i *importer) insert(query string, line int) (bool, error) {
+	_, parsed, err := i.engine.parse(i.ctx, query)
+	if err != nil {
+		return false, err
+	}
+
+	insert, ok := parsed.(*plan.InsertInto)
+	if !ok || insert.IsReplace || len(insert.OnDupExprs) > 0 {
+		return false, nil
+	}
+	dst, ok := insert.Left.(*plan.UnresolvedTable)
+	if !ok || dst.AsOf != nil {
+		return false, nil
+	}
+	values, ok := insert.Right.(*plan.Values)
+	if !ok {
+		return false, nil
+	}
+
+	dbName := dst.Database
+	if dbName == "" {
+		dbName = i.ctx.GetCurrentDatabase()
+	}
+	db, err := i.engine.Catalog.SessionDatabase(i.ctx, dbName)
+	if err != nil {
+		return false, err
+	}
+	table, err := i.engine.Catalog.Table(i.ctx, dbName, dst.Name())
+	if err != nil {
+		return false, err
+	}
+	if triggered, err := i.hasInsertTriggers(db, table.Name()); err != nil || triggered {
+		return false, err
+	}
+	if fkt, ok := table.(sql.ForeignKeyTable); ok && sql.ForeignKeyChecks(i.ctx) {
+		if fks, err := fkt.GetForeignKeys(i.ctx); err != nil || len(fks) > 0 {
+			return false, err
+		}
+	}
+
+	schema := table.Schema()
+	columns, ok := insertColumns(schema, insert.ColumnNames)
+	if !ok || !insertableValues(schema, columns, values) {
+		return false, nil
+	}
+
+	if err := i.engine.Catalog.CheckWritable(i.ctx); err != nil {
+		return false, err
+	}
+	inserter, err := i.inserterOf(table, line)
+	if err != nil {
+		return false, err
+	}
+
+	for _, tuple := range values.ExpressionTuples {
+		row := make(sql.Row, len(schema))
+		for j, expr := range tuple {
+			col := schema[columns[j]]
+			v, err := expr.Eval(i.ctx, nil)
+			if err != nil {
+				return false, err
+			}
+			if v == nil && !col.Nullable {
+				return false, plan.ErrInsertIntoNonNullableProvidedNull.New(col.Name)
+			}
+			if v != nil {
+				if v, err = col.Type.Convert(v); err != nil {
+					return false, err
+				}
+			}
+			row[columns[j]] = v
+		}
+
+		if err := inserter.Insert(i.ctx, row); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// hasInsertTriggers returns whether the table of the database given with the name given has INSERT triggers.
+func (i *importer) hasInsertTriggers(db sql.Database, table string) (bool, error) {
+	tdb, ok := db.(sql.TriggerDatabase)
+	if !ok {
+		return false, nil
+	}
+
+	if i.triggers == nil {
+		i.triggers = make(map[string]map[string]bool)
+	}
+	tables, ok := i.triggers[db.Name()]
+	if !ok {
+		triggers, err := tdb.GetTriggers(i.ctx)
+		if err != nil {
+			return false, err
+		}
+
+		tables = make(map[string]bool)
+		for _, trigger := range triggers {
+			parsed, err := parse.Parse(i.ctx, trigger.CreateStatement)
+			if err != nil {
+				return false, err
+			}
+			ct, ok := parsed.(*plan.CreateTrigger)
+			if !ok {
+				return false, sql.ErrTriggerCreateStatementInvalid.New(trigger.CreateStatement)
+			}
+			if nameable, ok := ct.Table.(sql.Nameable); ok && strings.EqualFold(ct.TriggerEvent, sqlparser.InsertStr) {
+				tables[strings.ToLower(nameable.Name())] = true
+			}
+		}
+		i.triggers[db.Name()] = tables
+	}
+	return tables[strings.ToLower(table)], nil
+}
+
+// insertColumns returns the index in the schema given of each of the columns of an INSERT, or false if the INSERT
+// doesn't have a value for every column.
+func insertColumns(schema sql.Schema, names []string) ([]int, bool) {
+	columns := make([]int, len(schema))
+	if len(names) == 0 {
+		for j := range schema {
+			columns[j] = j
+		}
+		return columns, true
+	}
+
+	if len(names) != len(schema) {
+		return nil, false
+	}
+	seen := make(map[int]bool, len(names))
+	for j, name := range names {
+		idx := -1
+		for k, col := range schema {
+			if col.Name == name {
+				idx = k
+				break
+			}
+		}
+		if idx < 0 || seen[idx] {
+			return nil, false
+		}
+		seen[idx] = true
+		columns[j] = idx
+	}
+	return columns, true
+}
+
+// insertableValues returns whether the values of an INSERT can be inserted without analyzing it: every tuple has a
+// value for every column that can be evaluated by itself, and values of AUTO_INCREMENT columns aren't generated.
+func insertableValues(schema sql.Schema, columns []int, values *plan.Values) bool {
+	for _, tuple := range values.ExpressionTuples {
+		if len(tuple) != len(columns) {
+			return false
+		}
+		for j, expr := range tuple {
+			if !isLiteral(expr) {
+				return false
+			}
+			if schema[columns[j]].AutoIncrement {
+				v, err := expr.Eval(nil, nil)
+				if err != nil || v == nil {
+					return false
+				}
+				if cmp, err := schema[columns[j]].Type.Compare(v, schema[columns[j]].Type.Zero()); err != nil || cmp == 0 {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// isLiteral returns whether the expression given is a literal, or a negative number.
+func isLiteral(expr sql.Expression) bool {
+	if minus, ok := expr.(*expression.UnaryMinus); ok {
+		expr = minus.Child
+	}
+	_, ok := expr.(*expression.Literal)
+	return ok
+}
+
+// inserterOf returns the inserter of the rows of the table given, which is the one of the rows of the previous
+// statement if they were inserted into the same table.
+func (i *importer) inserterOf(table sql.Table, line int) (sql.RowInserter, error) {
+	if i.inserter != nil && i.table == table {
+		return i.inserter, nil
+	}
+	if err := i.flush(); err != nil {
+		return nil, err
+	}
+
+	insertable, err := plan.GetInsertable(plan.NewResolvedTable(table))
+	if err != nil {
+		return nil, err
+	}
+	if bulk, ok := insertable.(sql.BulkInsertableTable); ok {
+		i.inserter = bulk.BulkInserter(i.ctx)
+	} else {
+		i.inserter = insertable.Inserter(i.ctx)
+	}
+	i.table = table
+	i.line = line
+	return i.inserter, nil
+}
+
+// flush closes the inserter of the rows being inserted, if any, which checks their constraints if they were inserted
+// in bulk.
+func (i *importer) flush() error {
+	if i.inserter == nil {
+		return nil
+	}
+
+	err := i.inserter.Close(i.ctx)
+	if i.engine.ResultCache != nil {
+		i.engine.ResultCache.InvalidateTable(i.table.Name())
+	}
+	i.inserter, i.table = nil, nil
+	if err != nil {
+		return sql.ErrImportStatement.Wrap(err, i.line)
+	}
+	return nil
+}
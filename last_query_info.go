@@ -0,0 +1,89 @@
+package sqle
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// lastQueryInfoRowIter records the rows a query returns or affects as the last query info of the session of its
+// context, once all of them are read. Queries that return rows record how many of them they return as their found
+// rows, unless they calculate their found rows themselves with SQL_CALC_FOUND_ROWS, and -1 as their row count.
+// Queries that return an OkResult record the rows they affect as their row count, and other queries record 0.
+type lastQueryInfoRowIter struct {
+	sql.RowIter
+	ctx           *sql.Context
+	returnsRows   bool
+	calcFoundRows bool
+	rows          int64
+	okResult      bool
+	done          bool
+}
+
+// newLastQueryInfoRowIter returns the iterator given recording the last query info of the query with the plan given,
+// which returns rows if returnsRows is true.
+func newLastQueryInfoRowIter(ctx *sql.Context, node sql.Node, returnsRows bool, iter sql.RowIter) sql.RowIter {
+	calcFoundRows := false
+	plan.Inspect(node, func(n sql.Node) bool {
+		if limit, ok := n.(*plan.Limit); ok && limit.CalcFoundRows {
+			calcFoundRows = true
+		}
+		return !calcFoundRows
+	})
+
+	return &lastQueryInfoRowIter{RowIter: iter, ctx: ctx, returnsRows: returnsRows, calcFoundRows: calcFoundRows}
+}
+
+func (i *lastQueryInfoRowIter) Next() (sql.Row, error) {
+	row, err := i.RowIter.Next()
+	if err == io.EOF && !i.done && i.ctx.Session != nil {
+		i.done = true
+		i.record()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Clients may not read past the OkResult, which comes once the statement is done anyway
+	if len(row) == 1 {
+		if ok, isOk := row[0].(sql.OkResult); isOk {
+			if i.ctx.Session != nil {
+				i.ctx.SetLastQueryInfo(sql.RowCount, int64(ok.RowsAffected))
+			}
+			i.okResult = true
+			return row, nil
+		}
+	}
+	i.rows++
+	return row, nil
+}
+
+func (i *lastQueryInfoRowIter) record() {
+	switch {
+	case i.okResult:
+	case !i.returnsRows:
+		i.ctx.SetLastQueryInfo(sql.RowCount, 0)
+	default:
+		i.ctx.SetLastQueryInfo(sql.RowCount, -1)
+		if !i.calcFoundRows {
+			i.ctx.SetLastQueryInfo(sql.FoundRows, i.rows)
+		}
+	}
+}
+
+// returnsRows returns whether the statement with the analyzed plan given returns rows, rather than an OkResult or
+// nothing at all. Some DDL statements have the schema of the table they create or change, but return nothing.
+func returnsRows(analyzed sql.Node) bool {
+	node := analyzed
+	if qp, ok := node.(*plan.QueryProcess); ok {
+		node = qp.Child
+	}
+
+	switch node.(type) {
+	case *plan.CreateTable, *plan.AddColumn, *plan.ModifyColumn:
+		return false
+	default:
+		return len(node.Schema()) > 0
+	}
+}
@@ -17,7 +17,7 @@ var _ memoryIndexLookup = (*AscendIndexLookup)(nil)
 func (l *AscendIndexLookup) ID() string     { return l.id }
 func (l *AscendIndexLookup) String() string { return l.id }
 
-func (l *AscendIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (l *AscendIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	return &indexValIter{
 		tbl:             l.Index.MemTable(),
 		partition:       p,
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var _ sql.BulkInsertableTable = (*Table)(nil)
+
+// BulkInserter implements the sql.BulkInsertableTable interface. Rows are inserted without checking their unique
+// keys, which are checked once for all of them when the inserter is closed. If a key is duplicated, the table is
+// restored to the rows it had when the inserter was created, so it must not be changed by other inserters while rows
+// are inserted in bulk.
+func (t *Table) BulkInserter(*sql.Context) sql.RowInserter {
+	return &bulkInserter{editor: &tableEditor{table: t}, snapshot: t.Snapshot()}
+}
+
+// bulkInserter inserts rows into a table, deferring the checks of their unique keys until it's closed.
+type bulkInserter struct {
+	editor *tableEditor
+	// snapshot is the state of the table before any row was inserted, which it's restored to if a check fails.
+	snapshot *TableSnapshot
+	inserted bool
+}
+
+var _ sql.RowInserter = (*bulkInserter)(nil)
+
+// Insert implements the sql.RowInserter interface.
+func (b *bulkInserter) Insert(ctx *sql.Context, row sql.Row) error {
+	if err := b.editor.insert(row, false); err != nil {
+		return err
+	}
+	b.inserted = true
+	return nil
+}
+
+// Close implements the sql.RowInserter interface. It returns ErrUniqueKeyViolation if any unique key of the table is
+// duplicated, and none of the rows inserted are kept.
+func (b *bulkInserter) Close(ctx *sql.Context) error {
+	t := b.editor.table
+
+	var err error
+	if b.inserted {
+		if err = t.data.snapshot().checkUniqueKeys(); err != nil {
+			t.restore(b.snapshot)
+			b.editor.changes = nil
+		}
+	}
+
+	if cerr := b.editor.Close(ctx); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// checkUniqueKeys returns ErrUniqueKeyViolation if any two rows of the version have the same key in any of the unique
+// indexes of the table, checking the primary key first and then the other indexes by name.
+func (v *partitionsVersion) checkUniqueKeys() error {
+	for _, name := range v.uniqueIndexNames() {
+		entries := v.indexes[name]
+		if !entries.broken {
+			if row, ok := v.duplicateEntry(entries); ok {
+				return sql.ErrUniqueKeyViolation.New(indexEntry(entries.exprs, row), name)
+			}
+			continue
+		}
+
+		// The keys of broken entries can't be compared, so every row is compared with the others
+		for p, rows := range v.partitions {
+			for pos, row := range rows {
+				key, ok, err := indexRowKey(entries.exprs, row)
+				if err != nil {
+					return err
+				}
+				if ok && v.hasKey(entries, key, p, pos) {
+					return sql.ErrUniqueKeyViolation.New(indexEntry(entries.exprs, row), name)
+				}
+			}
+		}
+	}
+	return nil
+}
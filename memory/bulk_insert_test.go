@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestBulkInserter(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 2)
+	require.NoError(table.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), "a")))
+
+	inserter := table.BulkInserter(ctx)
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(2), "b")))
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(3), nil)))
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(4), nil)))
+	require.NoError(inserter.Close(ctx))
+	require.Len(testFlatRows(t, table), 4)
+	requireIndexEntriesConsistent(t, table)
+
+	// Duplicated keys are found when the inserter is closed, and none of its rows are kept
+	for _, row := range []sql.Row{{int64(1), "c"}, {int64(5), "a"}} {
+		inserter = table.BulkInserter(ctx)
+		require.NoError(inserter.Insert(ctx, sql.NewRow(int64(6), "x")))
+		require.NoError(inserter.Insert(ctx, row))
+		require.True(sql.ErrUniqueKeyViolation.Is(inserter.Close(ctx)))
+		require.Len(testFlatRows(t, table), 4)
+		require.Empty(testIndexLookup(t, table, "idx_s", "x"))
+		requireIndexEntriesConsistent(t, table)
+	}
+
+	inserter = table.BulkInserter(ctx)
+	require.NoError(inserter.Close(ctx))
+	require.Len(testFlatRows(t, table), 4)
+}
@@ -11,6 +11,9 @@ type Database struct {
 	name     string
 	tables   map[string]sql.Table
 	triggers []sql.TriggerDefinition
+	journal  *journal
+	// revisions are the states of the database saved to be read AS OF them.
+	revisions *revisions
 }
 
 var _ sql.Database = (*Database)(nil)
@@ -22,8 +25,9 @@ var _ sql.TriggerDatabase = (*Database)(nil)
 // NewDatabase creates a new database with the given name.
 func NewDatabase(name string) *Database {
 	return &Database{
-		name:   name,
-		tables: map[string]sql.Table{},
+		name:      name,
+		tables:    map[string]sql.Table{},
+		revisions: &revisions{},
 	}
 }
 
@@ -102,9 +106,18 @@ func (db *HistoryDatabase) AddTableAsOf(name string, t sql.Table, asOf interface
 	db.tables[name] = t
 }
 
-// AddTable adds a new table to the database.
+// AddTable adds a new table to the database. Tables added to a persistent database must be tables of this package.
 func (d *Database) AddTable(name string, t sql.Table) {
 	d.tables[name] = t
+	if d.journal == nil {
+		return
+	}
+
+	if mt, ok := memoryTable(t); ok {
+		mt.journal = d.journal
+	}
+	// A snapshot that fails is retried when the next changes are committed
+	_ = d.persist()
 }
 
 // CreateTable creates a table with the given name and schema
@@ -114,8 +127,12 @@ func (d *Database) CreateTable(ctx *sql.Context, name string, schema sql.Schema)
 		return sql.ErrTableAlreadyExists.New(name)
 	}
 
-	d.tables[name] = NewTable(name, schema)
-	return nil
+	table := NewTable(name, schema)
+	// The primary keys of tables created with SQL are unique indexes, so they can be used to look up their rows
+	table.EnablePrimaryKeyIndexes()
+	table.journal = d.journal
+	d.tables[name] = table
+	return d.persist()
 }
 
 // DropTable drops the table with the given name
@@ -126,7 +143,7 @@ func (d *Database) DropTable(ctx *sql.Context, name string) error {
 	}
 
 	delete(d.tables, name)
-	return nil
+	return d.persist()
 }
 
 func (d *Database) RenameTable(ctx *sql.Context, oldName, newName string) error {
@@ -145,7 +162,7 @@ func (d *Database) RenameTable(ctx *sql.Context, oldName, newName string) error
 	d.tables[newName] = tbl
 	delete(d.tables, oldName)
 
-	return nil
+	return d.persist()
 }
 
 func (d *Database) GetTriggers(ctx *sql.Context) ([]sql.TriggerDefinition, error) {
@@ -158,7 +175,7 @@ func (d *Database) GetTriggers(ctx *sql.Context) ([]sql.TriggerDefinition, error
 
 func (d *Database) CreateTrigger(ctx *sql.Context, definition sql.TriggerDefinition) error {
 	d.triggers = append(d.triggers, definition)
-	return nil
+	return d.persist()
 }
 
 func (d *Database) DropTrigger(ctx *sql.Context, name string) error {
@@ -173,5 +190,5 @@ func (d *Database) DropTrigger(ctx *sql.Context, name string) error {
 	if !found {
 		return sql.ErrTriggerDoesNotExist.New(name)
 	}
-	return nil
+	return d.persist()
 }
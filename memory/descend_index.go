@@ -18,7 +18,7 @@ var _ sql.IndexLookup = (*DescendIndexLookup)(nil)
 func (l *DescendIndexLookup) ID() string     { return l.id }
 func (l *DescendIndexLookup) String() string { return l.id }
 
-func (l *DescendIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (l *DescendIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	return &indexValIter{
 		tbl:             l.Index.MemTable(),
 		partition:       p,
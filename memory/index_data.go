@@ -0,0 +1,364 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// primaryKeyIndexName is the name of the index of the primary key of a table.
+const primaryKeyIndexName = "PRIMARY"
+
+// indexEntries are the entries of an index of a table in a version of its partitions: the positions of the rows of each
+// partition, keyed by the values of the expressions of the index for the rows. Rows with a NULL value for any of the
+// expressions aren't in the index, since no lookup matches them. The positions of each key are sorted.
+//
+// The entries are maintained by the editors of the table as they change rows, so lookups of the index find the rows
+// of the version without evaluating the expressions of the index on every one of them.
+type indexEntries struct {
+	exprs []sql.Expression
+	// unique is whether the index is unique, so rows with the same key can't be written to the table.
+	unique     bool
+	partitions map[string]map[string][]int
+	// broken is whether the key of a row couldn't be computed, in which case lookups of the index evaluate the
+	// expressions of the index on every row instead, until the entries are rebuilt.
+	broken bool
+}
+
+// indexDefinition is the definition of an index of a table whose entries are maintained by its editors.
+type indexDefinition struct {
+	exprs  []sql.Expression
+	unique bool
+}
+
+// newIndexEntries returns the entries of the index with the definition given for the partitions of the version given.
+func newIndexEntries(index indexDefinition, version *partitionsVersion) *indexEntries {
+	entries := &indexEntries{
+		exprs:      index.exprs,
+		unique:     index.unique,
+		partitions: make(map[string]map[string][]int, len(version.partitions)),
+	}
+	for key, rows := range version.partitions {
+		entries.partitions[key] = map[string][]int{}
+		for pos, row := range rows {
+			entries.add(key, row, pos)
+		}
+	}
+	return entries
+}
+
+// copy returns a copy of the entries for a new version, which shares the entries of every partition with them until
+// the partition is copied by copyPartition.
+func (e *indexEntries) copy() *indexEntries {
+	partitions := make(map[string]map[string][]int, len(e.partitions))
+	for key, entries := range e.partitions {
+		partitions[key] = entries
+	}
+	return &indexEntries{exprs: e.exprs, unique: e.unique, partitions: partitions, broken: e.broken}
+}
+
+// copyPartition copies the entries of the partition with the key given, so they can be changed in place.
+func (e *indexEntries) copyPartition(key string) {
+	entries := make(map[string][]int, len(e.partitions[key]))
+	for k, positions := range e.partitions[key] {
+		entries[k] = append([]int(nil), positions...)
+	}
+	e.partitions[key] = entries
+}
+
+// add adds the row given, at the position given of the partition with the key given.
+func (e *indexEntries) add(partition string, row sql.Row, pos int) {
+	key, ok, err := indexRowKey(e.exprs, row)
+	if err != nil {
+		e.broken = true
+		return
+	}
+	if !ok {
+		return
+	}
+
+	entries := e.partitions[partition]
+	if entries == nil {
+		entries = map[string][]int{}
+		e.partitions[partition] = entries
+	}
+
+	positions := entries[key]
+	i := len(positions)
+	for i > 0 && positions[i-1] > pos {
+		i--
+	}
+	positions = append(positions, 0)
+	copy(positions[i+1:], positions[i:])
+	positions[i] = pos
+	entries[key] = positions
+}
+
+// remove removes the row given, at the position given of the partition with the key given. If deleted is true, the row
+// was deleted from the partition, so the positions of the rows after it are moved back by one.
+func (e *indexEntries) remove(partition string, row sql.Row, pos int, deleted bool) {
+	entries := e.partitions[partition]
+	if key, ok, err := indexRowKey(e.exprs, row); err != nil {
+		e.broken = true
+	} else if ok {
+		positions := entries[key]
+		for i, p := range positions {
+			if p == pos {
+				positions = append(positions[:i], positions[i+1:]...)
+				break
+			}
+		}
+		if len(positions) == 0 {
+			delete(entries, key)
+		} else {
+			entries[key] = positions
+		}
+	}
+
+	if deleted {
+		for _, positions := range entries {
+			for i, p := range positions {
+				if p > pos {
+					positions[i] = p - 1
+				}
+			}
+		}
+	}
+}
+
+// lookup returns the positions of the rows of the partition with the key given whose values for the expressions of
+// the index are the values given. It returns false if the rows can't be found with the entries, because they're
+// broken or the values given can't be compared with the entries.
+func (e *indexEntries) lookup(partition string, values []interface{}) ([]int, bool) {
+	if e.broken || len(values) != len(e.exprs) {
+		return nil, false
+	}
+
+	row := make(sql.Row, len(values))
+	for i, v := range values {
+		if v == nil {
+			return nil, true
+		}
+		if !indexComparable(e.exprs[i].Type(), v) {
+			return nil, false
+		}
+		row[i] = v
+	}
+
+	key, err := indexKey(e.exprs, row)
+	if err != nil {
+		return nil, false
+	}
+	return e.partitions[partition][key], true
+}
+
+// indexContext is the context the expressions of indexes are evaluated with. They're the columns of their tables,
+// which don't use it, and creating a context for every row would make inserts in bulk much slower.
+var indexContext = sql.NewEmptyContext()
+
+// indexRowKey returns the key of the row given for the index with the expressions given, or false if the row isn't in
+// the index because any of its values for the expressions is NULL.
+func indexRowKey(exprs []sql.Expression, row sql.Row) (string, bool, error) {
+	ctx := indexContext
+	values := make(sql.Row, len(exprs))
+	for i, expr := range exprs {
+		v, err := expr.Eval(ctx, row)
+		if err != nil {
+			return "", false, err
+		}
+		if v == nil {
+			return "", false, nil
+		}
+		values[i] = v
+	}
+
+	key, err := indexKey(exprs, values)
+	return key, true, err
+}
+
+// indexKey returns the key of the values given for the index with the expressions given. Values are converted to the
+// types of the expressions first, so that equal values of different go types have the same key.
+func indexKey(exprs []sql.Expression, values sql.Row) (string, error) {
+	var key []byte
+	for i, expr := range exprs {
+		v, err := expr.Type().Convert(values[i])
+		if err != nil {
+			return "", err
+		}
+
+		value, err := expr.Type().SQL(v)
+		if err != nil {
+			return "", err
+		}
+
+		raw := value.Raw()
+		key = strconv.AppendInt(key, int64(len(raw)), 10)
+		key = append(key, ':')
+		key = append(key, raw...)
+	}
+	return string(key), nil
+}
+
+// indexEntry returns the values of the row given for the index with the expressions given as they're shown in errors,
+// separated by dashes.
+func indexEntry(exprs []sql.Expression, row sql.Row) string {
+	ctx := sql.NewEmptyContext()
+	values := make([]string, len(exprs))
+	for i, expr := range exprs {
+		v, err := expr.Eval(ctx, row)
+		if err == nil {
+			v, err = expr.Type().Convert(v)
+		}
+		if err != nil {
+			values[i] = fmt.Sprint(v)
+			continue
+		}
+
+		value, err := expr.Type().SQL(v)
+		if err != nil {
+			values[i] = fmt.Sprint(v)
+			continue
+		}
+		values[i] = value.ToString()
+	}
+	return strings.Join(values, "-")
+}
+
+// indexComparable returns whether the value given of a lookup can be compared with the entries of an index expression
+// of the type given, which is when the value equals a value of the expression only if they have the same key.
+func indexComparable(typ sql.Type, v interface{}) bool {
+	if sql.IsInteger(typ) {
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		default:
+			return false
+		}
+	}
+
+	converted, err := typ.Convert(v)
+	return err == nil && reflect.TypeOf(converted) == reflect.TypeOf(v)
+}
+
+// reindex replaces the entries of the indexes of a writable version with new ones for the indexes with the names and
+// definitions given.
+func (v *partitionsVersion) reindex(indexes map[string]indexDefinition) {
+	v.indexes = make(map[string]*indexEntries, len(indexes))
+	for name, index := range indexes {
+		v.indexes[name] = newIndexEntries(index, v)
+	}
+}
+
+// checkUnique returns ErrUniqueKeyViolation if another row of the version has the same key as the row given in any of
+// the unique indexes of the table. The row at the position given of the partition with the key given is the one being
+// replaced by the row given, so it's ignored. The primary key is checked first, then the other indexes by name, so the
+// same key is reported for the same conflicts.
+func (v *partitionsVersion) checkUnique(row sql.Row, partition string, pos int) error {
+	for _, name := range v.uniqueIndexNames() {
+		entries := v.indexes[name]
+
+		key, ok, err := indexRowKey(entries.exprs, row)
+		if err != nil {
+			return err
+		}
+		if ok && v.hasKey(entries, key, partition, pos) {
+			return sql.ErrUniqueKeyViolation.New(indexEntry(entries.exprs, row), name)
+		}
+	}
+	return nil
+}
+
+// uniqueIndexNames returns the names of the unique indexes of the version, with the primary key first and the other
+// indexes by name.
+func (v *partitionsVersion) uniqueIndexNames() []string {
+	var names []string
+	for name, entries := range v.indexes {
+		if entries.unique {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == primaryKeyIndexName || names[j] == primaryKeyIndexName {
+			return names[i] == primaryKeyIndexName
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// hasKey returns whether any row of the version has the key given in the index with the entries given, other than the
+// row at the position given of the partition with the key given.
+func (v *partitionsVersion) hasKey(entries *indexEntries, key string, partition string, pos int) bool {
+	for p, rows := range v.partitions {
+		if !entries.broken {
+			for _, i := range entries.partitions[p][key] {
+				if p != partition || i != pos {
+					return true
+				}
+			}
+			continue
+		}
+
+		for i, row := range rows {
+			if p == partition && i == pos {
+				continue
+			}
+			if k, ok, err := indexRowKey(entries.exprs, row); err == nil && ok && k == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// duplicateEntry returns a row of the version that has the same key in the index with the entries given as another
+// row, if there's any.
+func (v *partitionsVersion) duplicateEntry(entries *indexEntries) (sql.Row, bool) {
+	counts := make(map[string]int)
+	for p, partition := range entries.partitions {
+		for key, positions := range partition {
+			counts[key] += len(positions)
+			if counts[key] > 1 {
+				return v.partitions[p][positions[0]], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// insertRow appends the row given to the partition with the key given of a writable version, and adds it to the
+// entries of its indexes.
+func (v *partitionsVersion) insertRow(key string, row sql.Row) {
+	rows := append(v.writablePartition(key), row)
+	v.partitions[key] = rows
+	for _, entries := range v.indexes {
+		entries.add(key, row, len(rows)-1)
+	}
+}
+
+// deleteRow deletes the row at the position given of the partition with the key given of a writable version, and
+// removes it from the entries of its indexes.
+func (v *partitionsVersion) deleteRow(key string, pos int) {
+	rows := v.writablePartition(key)
+	row := rows[pos]
+	v.partitions[key] = append(rows[:pos], rows[pos+1:]...)
+	for _, entries := range v.indexes {
+		entries.remove(key, row, pos, true)
+	}
+}
+
+// updateRow replaces the row at the position given of the partition with the key given of a writable version with the
+// row given, and updates the entries of its indexes.
+func (v *partitionsVersion) updateRow(key string, pos int, row sql.Row) {
+	rows := v.writablePartition(key)
+	oldRow := rows[pos]
+	rows[pos] = row
+	for _, entries := range v.indexes {
+		entries.remove(key, oldRow, pos, false)
+		entries.add(key, row, pos)
+	}
+}
@@ -0,0 +1,224 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestIndexMaintenance(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 3)
+	table.EnablePrimaryKeyIndexes()
+	for i := int64(1); i <= 6; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, []string{"a", "b"}[i%2])))
+	}
+	require.NoError(table.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+
+	require.ElementsMatch([]sql.Row{{int64(2), "a"}, {int64(4), "a"}, {int64(6), "a"}}, testIndexLookup(t, table, "idx_s", "a"))
+	require.Equal([]sql.Row{{int64(3), "b"}}, testIndexLookup(t, table, "PRIMARY", 3))
+	requireIndexEntriesConsistent(t, table)
+
+	// Index lookups find the rows written after the indexes were created
+	editor := table.Updater(ctx).(*tableEditor)
+	require.NoError(editor.Update(ctx, sql.NewRow(int64(2), "a"), sql.NewRow(int64(2), "b")))
+	require.NoError(editor.Delete(ctx, sql.NewRow(int64(4), "a")))
+	require.NoError(editor.Insert(ctx, sql.NewRow(int64(7), "a")))
+	require.NoError(editor.Insert(ctx, sql.NewRow(int64(8), nil)))
+	require.NoError(editor.Update(ctx, sql.NewRow(int64(3), "b"), sql.NewRow(int64(9), "c")))
+	require.NoError(editor.Close(ctx))
+
+	require.ElementsMatch([]sql.Row{{int64(6), "a"}, {int64(7), "a"}}, testIndexLookup(t, table, "idx_s", "a"))
+	require.ElementsMatch([]sql.Row{{int64(1), "b"}, {int64(2), "b"}, {int64(5), "b"}}, testIndexLookup(t, table, "idx_s", "b"))
+	require.Equal([]sql.Row{{int64(9), "c"}}, testIndexLookup(t, table, "idx_s", "c"))
+	require.Empty(testIndexLookup(t, table, "idx_s", nil))
+	require.Empty(testIndexLookup(t, table, "PRIMARY", 3))
+	require.Empty(testIndexLookup(t, table, "PRIMARY", 4))
+	require.Equal([]sql.Row{{int64(9), "c"}}, testIndexLookup(t, table, "PRIMARY", int8(9)))
+	requireIndexEntriesConsistent(t, table)
+
+	// Keys that can't be compared with the entries of the index are looked up by evaluating them on every row
+	version := table.data.snapshot()
+	_, ok := version.indexes["PRIMARY"].lookup("0", []interface{}{int8(7)})
+	require.True(ok)
+	_, ok = version.indexes["PRIMARY"].lookup("0", []interface{}{"7"})
+	require.False(ok)
+	require.Equal([]sql.Row{{int64(7), "a"}}, testIndexLookup(t, table, "PRIMARY", "7"))
+
+	// Indexes follow the changes to the schema of the table
+	require.NoError(table.AddColumn(ctx, &sql.Column{Name: "f", Type: sql.Int64, Nullable: true}, &sql.ColumnOrder{First: true}))
+	require.ElementsMatch([]sql.Row{{nil, int64(6), "a"}, {nil, int64(7), "a"}}, testIndexLookup(t, table, "idx_s", "a"))
+	require.Equal([]sql.Row{{nil, int64(9), "c"}}, testIndexLookup(t, table, "PRIMARY", 9))
+
+	require.NoError(table.ModifyColumn(ctx, "s", &sql.Column{Name: "s2", Type: sql.Text, Nullable: true}, &sql.ColumnOrder{First: true}))
+	require.NoError(table.Insert(ctx, sql.NewRow("c", nil, int64(10))))
+	require.ElementsMatch([]sql.Row{{"c", nil, int64(9)}, {"c", nil, int64(10)}}, testIndexLookup(t, table, "idx_s", "c"))
+	requireIndexEntriesConsistent(t, table)
+
+	require.NoError(table.DropColumn(ctx, "s2"))
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	require.Len(indexes, 1)
+	require.Equal("PRIMARY", indexes[0].ID())
+}
+
+func TestIndexSnapshotLookups(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t"},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	})
+	require.NoError(table.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), "a")))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2), "a")))
+
+	lookup, err := table.indexes["idx_s"].Get("a")
+	require.NoError(err)
+	indexed := table.WithIndexLookup(lookup)
+	partitions, err := indexed.Partitions(ctx)
+	require.NoError(err)
+	p, err := partitions.Next(ctx)
+	require.NoError(err)
+
+	// Lookups of a partition find the rows of the version it was returned with
+	deleter := table.Deleter(ctx)
+	require.NoError(deleter.Delete(ctx, sql.NewRow(int64(1), "a")))
+	require.NoError(deleter.Close(ctx))
+
+	iter, err := indexed.PartitionRows(ctx, p)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1), "a"}, {int64(2), "a"}}, rows)
+
+	require.Equal([]sql.Row{{int64(2), "a"}}, testIndexLookup(t, table, "idx_s", "a"))
+}
+
+func TestIndexPrefixLookups(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "j", Type: sql.Int64, Source: "t", PrimaryKey: true},
+	})
+	table.EnablePrimaryKeyIndexes()
+	for _, row := range []sql.Row{{int64(1), int64(1)}, {int64(1), int64(2)}, {int64(2), int64(1)}} {
+		require.NoError(table.Insert(ctx, row))
+	}
+
+	// Keys of the first columns of an index match the rows with any values of the others
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	lookup, err := indexes[0].(sql.PrefixIndex).GetPrefix(int64(1))
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{{int64(1), int64(1)}, {int64(1), int64(2)}}, testFlatRows(t, table.WithIndexLookup(lookup)))
+	require.Equal([]sql.Row{{int64(1), int64(2)}}, testIndexLookup(t, table, "PRIMARY", int64(1), int64(2)))
+}
+
+func TestUniqueConstraints(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}, 3)
+	require.NoError(table.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), "a")))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2), "b")))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3), nil)))
+
+	// Rows with NULL values for the columns of a unique index don't conflict
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(4), nil)))
+
+	err := table.Insert(ctx, sql.NewRow(int64(1), "c"))
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+	require.Equal("Duplicate entry '1' for key 'PRIMARY'", err.Error())
+
+	err = table.Insert(ctx, sql.NewRow(int64(5), "a"))
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+	require.Equal("Duplicate entry 'a' for key 'idx_s'", err.Error())
+
+	// Rows can be updated to keep their keys, but not to take the keys of other rows
+	updater := table.Updater(ctx)
+	require.NoError(updater.Update(ctx, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "a")))
+	require.NoError(updater.Update(ctx, sql.NewRow(int64(2), "b"), sql.NewRow(int64(6), "b")))
+	require.True(sql.ErrUniqueKeyViolation.Is(updater.Update(ctx, sql.NewRow(int64(6), "b"), sql.NewRow(int64(6), "a"))))
+	require.True(sql.ErrUniqueKeyViolation.Is(updater.Update(ctx, sql.NewRow(int64(6), "b"), sql.NewRow(int64(1), "b"))))
+	require.NoError(updater.Close(ctx))
+	require.ElementsMatch([]sql.Row{{int64(1), "a"}, {int64(6), "b"}, {int64(3), nil}, {int64(4), nil}}, testFlatRows(t, table))
+
+	// Keys are unique across partitions and freed by deletions
+	deleter := table.Deleter(ctx)
+	require.NoError(deleter.Delete(ctx, sql.NewRow(int64(1), "a")))
+	require.NoError(deleter.Close(ctx))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), "a")))
+
+	// Unique indexes can't be created on columns with duplicated values
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(7), "x")))
+	require.NoError(table.AddColumn(ctx, &sql.Column{Name: "n", Type: sql.Int64, Default: testDefault(t, int64(0))}, nil))
+	err = table.CreateIndex(ctx, "idx_n", sql.IndexUsing_Default, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "n"}}, "")
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+	require.Equal("Duplicate entry '0' for key 'idx_n'", err.Error())
+	require.NoError(table.CreateIndex(ctx, "idx_i_n", sql.IndexUsing_Default, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "i"}, {Name: "n"}}, ""))
+
+	err = table.Insert(ctx, sql.NewRow(int64(7), "y", int64(0)))
+	require.Equal("Duplicate entry '7' for key 'PRIMARY'", err.Error())
+}
+
+// testIndexLookup returns the rows of the table given found by looking up the key given in the index with the name
+// given.
+func testIndexLookup(t *testing.T, table *Table, name string, key ...interface{}) []sql.Row {
+	t.Helper()
+	indexes, err := table.GetIndexes(sql.NewEmptyContext())
+	require.NoError(t, err)
+
+	for _, index := range indexes {
+		if index.ID() == name {
+			lookup, err := index.Get(key...)
+			require.NoError(t, err)
+			return testFlatRows(t, table.WithIndexLookup(lookup))
+		}
+	}
+
+	require.Failf(t, "index not found", "index %s", name)
+	return nil
+}
+
+// testDefault returns the literal column default with the value given.
+func testDefault(t *testing.T, v interface{}) *sql.ColumnDefaultValue {
+	t.Helper()
+	_, typ := getType(v)
+	defaultValue, err := sql.NewColumnDefaultValue(expression.NewLiteral(v, typ), typ, true, false)
+	require.NoError(t, err)
+	return defaultValue
+}
+
+// requireIndexEntriesConsistent checks that the entries of the indexes of the table given, as maintained by its
+// editors, are the same as the entries built from its rows.
+func requireIndexEntriesConsistent(t *testing.T, table *Table) {
+	t.Helper()
+	version := table.data.snapshot()
+	for name, entries := range version.indexes {
+		require.False(t, entries.broken, name)
+		expected := newIndexEntries(indexDefinition{exprs: entries.exprs}, version)
+		for key, partition := range expected.partitions {
+			if len(partition) == 0 {
+				require.Empty(t, entries.partitions[key], name)
+			} else {
+				require.Equal(t, partition, entries.partitions[key], name)
+			}
+		}
+	}
+}
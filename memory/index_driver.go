@@ -34,10 +34,10 @@ func (d *TestIndexDriver) Save(*sql.Context, sql.DriverIndex, sql.PartitionIndex
 	panic("not implemented")
 }
 
-func (d *TestIndexDriver) Delete(sql.DriverIndex, sql.PartitionIter) error {
+func (d *TestIndexDriver) Delete(*sql.Context, sql.DriverIndex, sql.PartitionIter) error {
 	panic("not implemented")
 }
 
-func (d *TestIndexDriver) Create(db, table, id string, expressions []sql.Expression, config map[string]string) (sql.DriverIndex, error) {
+func (d *TestIndexDriver) Create(ctx *sql.Context, db, table, id string, expressions []sql.Expression, config map[string]string) (sql.DriverIndex, error) {
 	panic("not implemented")
 }
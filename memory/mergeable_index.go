@@ -23,6 +23,7 @@ var _ sql.Index = (*MergeableIndex)(nil)
 var _ sql.AscendIndex = (*MergeableIndex)(nil)
 var _ sql.DescendIndex = (*MergeableIndex)(nil)
 var _ sql.NegateIndex = (*MergeableIndex)(nil)
+var _ sql.PrefixIndex = (*MergeableIndex)(nil)
 
 func (i *MergeableIndex) Database() string                    { return i.DB }
 func (i *MergeableIndex) Driver() string                      { return i.DriverName }
@@ -90,6 +91,11 @@ func (i *MergeableIndex) Get(key ...interface{}) (sql.IndexLookup, error) {
 	return &MergeableIndexLookup{Key: key, Index: i}, nil
 }
 
+// GetPrefix implements the sql.PrefixIndex interface.
+func (i *MergeableIndex) GetPrefix(key ...interface{}) (sql.IndexLookup, error) {
+	return &MergeableIndexLookup{Key: key, Index: i}, nil
+}
+
 func (i *MergeableIndex) Has(sql.Partition, ...interface{}) (bool, error) {
 	panic("not implemented")
 }
@@ -146,9 +152,10 @@ func (i *MergeableIndexLookup) IsMergeable(lookup sql.IndexLookup) bool {
 	return ok
 }
 
-func (i *MergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (i *MergeableIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	var exprs []sql.Expression
-	for exprI, expr := range i.Index.ColumnExpressions() {
+	// Keys of a prefix of the expressions only match them
+	for exprI, expr := range i.Index.ColumnExpressions()[:len(i.Key)] {
 		lit, typ := getType(i.Key[exprI])
 		exprs = append(exprs, expression.NewEquals(expr, expression.NewLiteral(lit, typ)))
 	}
@@ -157,12 +164,14 @@ func (i *MergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, erro
 		tbl:             i.Index.MemTable(),
 		partition:       p,
 		matchExpression: and(exprs...),
+		lookup:          newIndexKeyLookup(i.Index, i.Key),
 	}, nil
 }
 
 func (i *MergeableIndexLookup) EvalExpression() sql.Expression {
 	var exprs []sql.Expression
-	for exprI, expr := range i.Index.ColumnExpressions() {
+	// Keys of a prefix of the expressions only match them
+	for exprI, expr := range i.Index.ColumnExpressions()[:len(i.Key)] {
 		lit, typ := getType(i.Key[exprI])
 		exprs = append(exprs, expression.NewEquals(expr, expression.NewLiteral(lit, typ)))
 	}
@@ -272,7 +281,7 @@ func (m *MergedIndexLookup) IsMergeable(lookup sql.IndexLookup) bool {
 	return ok
 }
 
-func (m *MergedIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (m *MergedIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	return &indexValIter{
 		tbl:             m.Index.MemTable(),
 		partition:       p,
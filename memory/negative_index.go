@@ -16,7 +16,7 @@ var _ sql.IndexLookup = (*NegateIndexLookup)(nil)
 func (l *NegateIndexLookup) ID() string     { return "not " + l.Lookup.ID() }
 func (l *NegateIndexLookup) String() string { return "not " + l.Lookup.ID() }
 
-func (l *NegateIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (l *NegateIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	return &indexValIter{
 		tbl:             l.Index.MemTable(),
 		partition:       p,
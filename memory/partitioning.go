@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"hash/fnv"
+	"strings"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// ErrInvalidPartition is returned when the PartitionFunc of a table returns a partition the table doesn't have.
+var ErrInvalidPartition = errors.NewKind("invalid partition %d for a row of table %s, which has %d partitions")
+
+// PartitionFunc returns the partition of a table with the schema and the number of partitions given that the row given
+// is stored in, between 0 and numPartitions - 1. It must only depend on the values of the row, so that the partition
+// of a row can be found from its values.
+type PartitionFunc func(schema sql.Schema, row sql.Row, numPartitions int) (int, error)
+
+// HashPrimaryKey is the PartitionFunc that hashes the values of the primary key of the rows, or of all their columns
+// if the table has no primary key.
+func HashPrimaryKey(schema sql.Schema, row sql.Row, numPartitions int) (int, error) {
+	var columns []int
+	for i, col := range schema {
+		if col.PrimaryKey {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) == 0 {
+		for i := range schema {
+			columns = append(columns, i)
+		}
+	}
+	return hashColumns(schema, row, columns, numPartitions)
+}
+
+// HashColumns returns the PartitionFunc that hashes the values of the columns with the names given.
+func HashColumns(names ...string) PartitionFunc {
+	return func(schema sql.Schema, row sql.Row, numPartitions int) (int, error) {
+		columns := make([]int, len(names))
+		for i, name := range names {
+			columns[i] = -1
+			for j, col := range schema {
+				if strings.EqualFold(col.Name, name) {
+					columns[i] = j
+					break
+				}
+			}
+			if columns[i] == -1 {
+				return 0, sql.ErrColumnNotFound.New(name)
+			}
+		}
+		return hashColumns(schema, row, columns, numPartitions)
+	}
+}
+
+// hashColumns returns the partition of the row given by hashing the values of the columns with the indexes given.
+// Values are hashed as keys of indexes, so equal values of different go types are in the same partition.
+func hashColumns(schema sql.Schema, row sql.Row, columns []int, numPartitions int) (int, error) {
+	exprs := make([]sql.Expression, len(columns))
+	values := make(sql.Row, len(columns))
+	for i, column := range columns {
+		col := schema[column]
+		exprs[i] = expression.NewGetField(column, col.Type, col.Name, col.Nullable)
+		values[i] = row[column]
+	}
+
+	h := fnv.New64a()
+	for i := range exprs {
+		// NULL values have no key, so they're hashed as a marker that no key starts with
+		if values[i] == nil {
+			_, _ = h.Write([]byte{'-'})
+			continue
+		}
+
+		key, err := indexKey(exprs[i:i+1], values[i:i+1])
+		if err != nil {
+			return 0, err
+		}
+		_, _ = h.Write([]byte(key))
+	}
+	return int(h.Sum64() % uint64(numPartitions)), nil
+}
+
+// TableOption configures the tables created by NewTable and NewPushdownTable.
+type TableOption func(*Table)
+
+// WithPartitions sets the number of partitions of a table, which is 1 by default.
+func WithPartitions(numPartitions int) TableOption {
+	return func(t *Table) {
+		t.data = newTableData(numPartitions)
+	}
+}
+
+// WithPartitionFunc sets the PartitionFunc that chooses the partition each row of a table is inserted into. By
+// default, rows of tables with a primary key are partitioned with HashPrimaryKey, and rows of tables without one are
+// inserted into each partition in turn.
+func WithPartitionFunc(fn PartitionFunc) TableOption {
+	return func(t *Table) {
+		t.partitionFunc = fn
+	}
+}
+
+// PartitionKey returns the key of the partition the row given is stored in, which is the only partition that needs to
+// be read to find it. It returns false if the partition of the row can't be known from its values, because the table
+// has neither a PartitionFunc nor a primary key, so its rows are inserted into each partition in turn.
+func (t *Table) PartitionKey(row sql.Row) ([]byte, bool, error) {
+	t.data.mu.Lock()
+	keys := t.data.current.keys
+	t.data.mu.Unlock()
+
+	i, ok, err := t.partitionOf(row, len(keys))
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return keys[i], true, nil
+}
+
+// partitionOf returns the index of the partition the row given is stored in, of the number of partitions given, or
+// false if the rows of the table are inserted into each partition in turn.
+func (t *Table) partitionOf(row sql.Row, numPartitions int) (int, bool, error) {
+	fn := t.partitionFunc
+	if fn == nil {
+		for _, col := range t.schema {
+			if col.PrimaryKey {
+				fn = HashPrimaryKey
+				break
+			}
+		}
+		if fn == nil {
+			return 0, false, nil
+		}
+	}
+
+	i, err := fn(t.schema, row, numPartitions)
+	if err != nil {
+		return 0, false, err
+	}
+	if i < 0 || i >= numPartitions {
+		return 0, false, ErrInvalidPartition.New(i, t.name, numPartitions)
+	}
+	return i, true, nil
+}
+
+// insertPartition returns the key of the partition of a writable version the row given is inserted into. The writer
+// must hold the lock of the data of the table.
+func (t *Table) insertPartition(version *partitionsVersion, row sql.Row) (string, error) {
+	i, ok, err := t.partitionOf(row, len(version.keys))
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		i = t.data.insert
+		t.data.insert++
+		if t.data.insert == len(version.keys) {
+			t.data.insert = 0
+		}
+	}
+	return string(version.keys[i]), nil
+}
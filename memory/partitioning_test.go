@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestHashPrimaryKeyPartitioning(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}, WithPartitions(4))
+	for i := int64(0); i < 100; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "a")))
+	}
+
+	// Every row is in the partition reported for it, and the rows are spread over all the partitions
+	requireRowsInPartitions(t, table)
+	partitions := testPartitionRows(t, table)
+	require.Len(partitions, 4)
+	for _, rows := range partitions {
+		require.NotEmpty(rows)
+	}
+
+	// Keys of other go types are in the same partition
+	key, ok, err := table.PartitionKey(sql.NewRow(int64(7), "b"))
+	require.NoError(err)
+	require.True(ok)
+	key2, _, err := table.PartitionKey(sql.NewRow(int8(7), "c"))
+	require.NoError(err)
+	require.Equal(key, key2)
+
+	// Rows are moved to the partition of their new key when it's updated
+	updater := table.Updater(ctx)
+	for i := int64(0); i < 100; i++ {
+		require.NoError(updater.Update(ctx, sql.NewRow(i, "a"), sql.NewRow(i+1000, "b")))
+	}
+	require.NoError(updater.Close(ctx))
+	requireRowsInPartitions(t, table)
+	require.Len(testFlatRows(t, table), 100)
+}
+
+func TestRoundRobinPartitioning(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	// Rows of tables without a primary key are inserted into each partition in turn
+	table := NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}, WithPartitions(3))
+	for i := int64(0); i < 6; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i)))
+	}
+	require.Equal(map[string][]sql.Row{
+		"0": {{int64(0)}, {int64(3)}},
+		"1": {{int64(1)}, {int64(4)}},
+		"2": {{int64(2)}, {int64(5)}},
+	}, testPartitionRows(t, table))
+
+	_, ok, err := table.PartitionKey(sql.NewRow(int64(1)))
+	require.NoError(err)
+	require.False(ok)
+}
+
+func TestPartitionFunc(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "country", Type: sql.Text, Source: "t"},
+	}
+	byCountry := func(schema sql.Schema, row sql.Row, numPartitions int) (int, error) {
+		if row[1] == "es" {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	table := NewPushdownTable("t", schema, WithPartitions(2), WithPartitionFunc(byCountry))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1), "es")))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2), "fr")))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3), "es")))
+	require.Equal(map[string][]sql.Row{
+		"0": {{int64(1), "es"}, {int64(3), "es"}},
+		"1": {{int64(2), "fr"}},
+	}, testPartitionRows(t, &table.Table))
+
+	updater := table.Updater(ctx)
+	require.NoError(updater.Update(ctx, sql.NewRow(int64(3), "es"), sql.NewRow(int64(3), "fr")))
+	require.NoError(updater.Close(ctx))
+	requireRowsInPartitions(t, &table.Table)
+
+	invalid := NewTable("t", schema, WithPartitionFunc(byCountry))
+	err := invalid.Insert(ctx, sql.NewRow(int64(1), "fr"))
+	require.True(ErrInvalidPartition.Is(err))
+
+	hashed := NewTable("t", schema, WithPartitions(8), WithPartitionFunc(HashColumns("COUNTRY")))
+	for i := int64(0); i < 20; i++ {
+		require.NoError(hashed.Insert(ctx, sql.NewRow(i, []string{"es", "fr"}[i%2])))
+	}
+	countries := make(map[interface{}]map[string]bool)
+	for key, rows := range testPartitionRows(t, hashed) {
+		for _, row := range rows {
+			if countries[row[1]] == nil {
+				countries[row[1]] = make(map[string]bool)
+			}
+			countries[row[1]][key] = true
+		}
+	}
+	require.Len(countries["es"], 1)
+	require.Len(countries["fr"], 1)
+	require.True(sql.ErrColumnNotFound.Is(NewTable("t", schema, WithPartitionFunc(HashColumns("x"))).Insert(ctx, sql.NewRow(int64(1), "es"))))
+
+	// Partition functions are given back to the tables of persistent databases when they're restored
+	dir, err := ioutil.TempDir("", "memory")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	opts := PersistenceOptions{PartitionFuncs: map[string]PartitionFunc{"t": byCountry}}
+	db, err := NewPersistentDatabase("mydb", dir, opts)
+	require.NoError(err)
+	db.AddTable("t", table)
+	require.NoError(db.Close())
+
+	db, err = NewPersistentDatabase("mydb", dir, opts)
+	require.NoError(err)
+	defer db.Close()
+	restored := db.Tables()["t"].(*PushdownTable)
+	require.NoError(restored.Insert(ctx, sql.NewRow(int64(4), "es")))
+	require.Len(testPartitionRows(t, &restored.Table)["0"], 2)
+}
+
+// testPartitionRows returns the rows of each partition of the table given, keyed by the key of the partition.
+func testPartitionRows(t *testing.T, table *Table) map[string][]sql.Row {
+	t.Helper()
+	ctx := sql.NewEmptyContext()
+	partitions, err := table.Partitions(ctx)
+	require.NoError(t, err)
+
+	result := make(map[string][]sql.Row)
+	for {
+		p, err := partitions.Next(ctx)
+		if err != nil {
+			break
+		}
+		iter, err := table.PartitionRows(ctx, p)
+		require.NoError(t, err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(t, err)
+		result[string(p.Key())] = rows
+	}
+	return result
+}
+
+// requireRowsInPartitions checks that every row of the table given is in the partition reported by its PartitionKey.
+func requireRowsInPartitions(t *testing.T, table *Table) {
+	t.Helper()
+	for key, rows := range testPartitionRows(t, table) {
+		for _, row := range rows {
+			partition, ok, err := table.PartitionKey(row)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, key, string(partition), "%v", row)
+		}
+	}
+}
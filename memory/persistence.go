@@ -0,0 +1,816 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+const (
+	snapshotFileName = "snapshot"
+	walFileName      = "wal"
+
+	// DefaultSnapshotThreshold is the number of row changes appended to the write-ahead log of a persistent database
+	// after which it's snapshotted again, unless PersistenceOptions.SnapshotThreshold is set.
+	DefaultSnapshotThreshold = 10000
+
+	// walFrameHeaderSize is the size of the header of each record in the write-ahead log: the length of the record and
+	// its CRC-32 checksum.
+	walFrameHeaderSize = 8
+)
+
+var (
+	// ErrNotPersistent is returned when snapshotting a database that isn't persistent.
+	ErrNotPersistent = errors.NewKind("database %s is not persistent")
+
+	// ErrPersistentDatabaseClosed is returned when changing a persistent database that was closed.
+	ErrPersistentDatabaseClosed = errors.NewKind("persistent database %s is closed")
+
+	// ErrUnpersistableTable is returned when snapshotting a persistent database with tables that aren't memory tables.
+	ErrUnpersistableTable = errors.NewKind("table %s of type %T can't be persisted")
+
+	// ErrColumnDefaultNotRestorable is returned when restoring a column whose default is an expression without
+	// PersistenceOptions.ParseColumnDefault.
+	ErrColumnDefaultNotRestorable = errors.NewKind("the default of column %s of table %s is an expression, which can't be restored without a parser of column defaults")
+)
+
+// PersistenceOptions configures how a persistent database is stored on disk.
+type PersistenceOptions struct {
+	// SnapshotThreshold is the number of row changes appended to the write-ahead log after which the database is
+	// snapshotted again and the log truncated. DefaultSnapshotThreshold is used if it's zero.
+	SnapshotThreshold int
+	// NoSync disables syncing the write-ahead log to disk every time changes are committed. Committed changes may be
+	// lost if the machine crashes, but writes are much faster, which is useful for test fixtures.
+	NoSync bool
+	// ParseColumnDefault parses the default values of columns that are expressions rather than literals when the
+	// database is restored, such as parse.StringToColumnDefaultValue. Databases with such columns can't be restored
+	// without it.
+	ParseColumnDefault func(ctx *sql.Context, expr string) (*sql.ColumnDefaultValue, error)
+	// PartitionFuncs are the PartitionFuncs of the tables created with WithPartitionFunc, keyed by the name of the
+	// table, which can't be persisted. Tables restored without theirs are partitioned as if they had none.
+	PartitionFuncs map[string]PartitionFunc
+}
+
+// NewPersistentDatabase returns the database with the given name persisted in the given directory, restoring the
+// tables and triggers it had when it was last used, or a new empty database if the directory doesn't exist or is
+// empty. The database is snapshotted to the directory every time its tables or triggers change, and the row changes
+// of each statement are appended to a write-ahead log when the statement finishes, which is replayed on top of the
+// last snapshot when the database is restored. The database must be closed with Close.
+func NewPersistentDatabase(name, dir string, opts PersistenceOptions) (*Database, error) {
+	if opts.SnapshotThreshold <= 0 {
+		opts.SnapshotThreshold = DefaultSnapshotThreshold
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	db := NewDatabase(name)
+	j := &journal{db: db, dir: dir, opts: opts}
+
+	if err := j.restoreSnapshot(); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.replay(wal); err != nil {
+		wal.Close()
+		return nil, err
+	}
+
+	j.wal = wal
+	db.journal = j
+	for _, t := range db.tables {
+		if mt, ok := memoryTable(t); ok {
+			mt.journal = j
+		}
+	}
+
+	return db, nil
+}
+
+// Checkpoint writes all the tables and triggers of a persistent database to disk and truncates its write-ahead log. If
+// rows are being changed by statements that haven't finished, the snapshot is taken when the last of them finishes.
+func (d *Database) Checkpoint() error {
+	if d.journal == nil {
+		return ErrNotPersistent.New(d.name)
+	}
+	return d.journal.schemaChanged()
+}
+
+// Close snapshots a persistent database and closes its write-ahead log. Changes to the database after it's closed
+// fail with ErrPersistentDatabaseClosed. It does nothing for databases that aren't persistent.
+func (d *Database) Close() error {
+	if d.journal == nil {
+		return nil
+	}
+	return d.journal.close()
+}
+
+// persist snapshots the database if it's persistent, after its tables or triggers changed.
+func (d *Database) persist() error {
+	if d.journal == nil {
+		return nil
+	}
+	return d.journal.schemaChanged()
+}
+
+// persist snapshots the database of the table if it's persistent, after the schema or indexes of the table changed.
+func (t *Table) persist() error {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.schemaChanged()
+}
+
+// memoryTable returns the Table of the table given, if it's a table of this package.
+func memoryTable(t sql.Table) (*Table, bool) {
+	switch t := t.(type) {
+	case *Table:
+		return t, true
+	case *PushdownTable:
+		return &t.Table, true
+	default:
+		return nil, false
+	}
+}
+
+// journal keeps a persistent database on disk, as a snapshot of its tables and a write-ahead log of the row changes
+// committed since the snapshot was taken.
+type journal struct {
+	mu   sync.Mutex
+	db   *Database
+	dir  string
+	opts PersistenceOptions
+	wal  *os.File
+
+	// lsn is the sequence number of the last record appended to the write-ahead log. Snapshots store the lsn of the
+	// last record they include, so records that were already snapshotted are skipped when the log is replayed.
+	lsn uint64
+	// changes is the number of row changes in the write-ahead log.
+	changes int
+	// editors is the number of table editors with row changes that haven't been committed yet. Snapshots include the
+	// rows of all tables, so they're postponed until there are none; otherwise the changes of those editors would be
+	// applied twice when restoring the database.
+	editors int
+	// snapshotPending is whether a snapshot was postponed, or failed and must be retried.
+	snapshotPending bool
+}
+
+// rowChangeKind is the kind of change of a rowChange.
+type rowChangeKind byte
+
+const (
+	rowInserted rowChangeKind = iota
+	rowDeleted
+	rowUpdated
+)
+
+// rowChange is a change to a row of a table made by a table editor.
+type rowChange struct {
+	kind   rowChangeKind
+	row    sql.Row
+	newRow sql.Row
+}
+
+// persistedValue is a value of a row, encoded as the SQL representation of the value for the type of its column.
+type persistedValue struct {
+	Null bool
+	Data []byte
+}
+
+type persistedChange struct {
+	Kind   rowChangeKind
+	Row    []persistedValue
+	NewRow []persistedValue
+}
+
+// walRecord is a record of the write-ahead log, with the row changes of a table committed by a statement.
+type walRecord struct {
+	LSN     uint64
+	Table   string
+	Changes []persistedChange
+}
+
+type persistedDefault struct {
+	Literal    bool
+	Value      persistedValue
+	Expression string
+}
+
+type persistedColumn struct {
+	Name          string
+	Type          string
+	Default       *persistedDefault
+	AutoIncrement bool
+	Nullable      bool
+	PrimaryKey    bool
+	Comment       string
+	Extra         string
+}
+
+type persistedPartition struct {
+	Key  string
+	Rows [][]persistedValue
+}
+
+type persistedIndex struct {
+	Name    string
+	Columns []string
+	Unique  bool
+	Comment string
+}
+
+type persistedTable struct {
+	Key              string
+	Name             string
+	Pushdown         bool
+	Columns          []persistedColumn
+	Partitions       []persistedPartition
+	Insert           int
+	Indexes          []persistedIndex
+	ForeignKeys      []sql.ForeignKeyConstraint
+	Checks           []sql.CheckConstraint
+	PkIndexesEnabled bool
+}
+
+// persistedSnapshot is the snapshot of a database.
+type persistedSnapshot struct {
+	LSN      uint64
+	Tables   []persistedTable
+	Triggers []sql.TriggerDefinition
+}
+
+// editorChanged is called when a table editor makes its first row change.
+func (j *journal) editorChanged() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.editors++
+}
+
+// commit appends the row changes of a table editor, whose statement finished, to the write-ahead log.
+func (j *journal) commit(t *Table, changes []rowChange) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.editors--
+	if j.wal == nil {
+		return ErrPersistentDatabaseClosed.New(j.db.name)
+	}
+
+	record := walRecord{LSN: j.lsn + 1, Table: t.name, Changes: make([]persistedChange, len(changes))}
+	for i, change := range changes {
+		var err error
+		record.Changes[i].Kind = change.kind
+		if record.Changes[i].Row, err = encodeRow(t.schema, change.row); err != nil {
+			return err
+		}
+		if change.newRow != nil {
+			if record.Changes[i].NewRow, err = encodeRow(t.schema, change.newRow); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := j.append(record); err != nil {
+		return err
+	}
+
+	j.lsn = record.LSN
+	j.changes += len(changes)
+	if j.changes >= j.opts.SnapshotThreshold {
+		j.snapshotPending = true
+	}
+
+	if j.snapshotPending && j.editors == 0 {
+		// The changes are already committed to the log, so a snapshot that fails doesn't fail the statement, and it's
+		// retried when the next changes are committed.
+		_ = j.snapshot()
+	}
+	return nil
+}
+
+// schemaChanged snapshots the database, or postpones the snapshot if there are uncommitted row changes.
+func (j *journal) schemaChanged() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.wal == nil {
+		return ErrPersistentDatabaseClosed.New(j.db.name)
+	}
+
+	j.snapshotPending = true
+	if j.editors > 0 {
+		return nil
+	}
+	return j.snapshot()
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.wal == nil {
+		return nil
+	}
+
+	var err error
+	if j.editors == 0 && (j.changes > 0 || j.snapshotPending) {
+		err = j.snapshot()
+	}
+
+	if closeErr := j.wal.Close(); err == nil {
+		err = closeErr
+	}
+	j.wal = nil
+	return err
+}
+
+// append writes a record to the end of the write-ahead log. Each record is preceded by its length and its checksum,
+// so records that were only partially written when the process stopped are detected when the log is replayed.
+func (j *journal) append(record walRecord) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(record); err != nil {
+		return err
+	}
+
+	frame := make([]byte, walFrameHeaderSize+payload.Len())
+	binary.BigEndian.PutUint32(frame[0:4], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload.Bytes()))
+	copy(frame[walFrameHeaderSize:], payload.Bytes())
+
+	if _, err := j.wal.Write(frame); err != nil {
+		return err
+	}
+
+	if j.opts.NoSync {
+		return nil
+	}
+	return j.wal.Sync()
+}
+
+// snapshot writes the snapshot of the database and truncates the write-ahead log. The snapshot is written to a
+// temporary file first, so the previous snapshot is kept if the process stops while it's being written.
+func (j *journal) snapshot() error {
+	snapshot, err := j.db.snapshot()
+	if err != nil {
+		return err
+	}
+	snapshot.LSN = j.lsn
+
+	path := filepath.Join(j.dir, snapshotFileName)
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := gob.NewEncoder(f).Encode(snapshot); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+
+	// Records of the log that were already snapshotted are skipped by their sequence number, so the snapshot is valid
+	// even if the process stops before the log is truncated.
+	if err := j.wal.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := j.wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	j.changes = 0
+	j.snapshotPending = false
+	return nil
+}
+
+// restoreSnapshot restores the tables and triggers of the database from its snapshot, if there is one.
+func (j *journal) restoreSnapshot() error {
+	f, err := os.Open(filepath.Join(j.dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var snapshot persistedSnapshot
+	if err := gob.NewDecoder(f).Decode(&snapshot); err != nil {
+		return err
+	}
+
+	ctx := sql.NewEmptyContext()
+	for _, persisted := range snapshot.Tables {
+		t, err := restoreTable(ctx, persisted, j.opts)
+		if err != nil {
+			return err
+		}
+		j.db.tables[persisted.Key] = t
+	}
+
+	j.db.triggers = snapshot.Triggers
+	j.lsn = snapshot.LSN
+	return nil
+}
+
+// replay applies the records of the write-ahead log that aren't in the snapshot to the tables of the database. A
+// record that was only partially written, which can only be the last one, is discarded and truncated away.
+func (j *journal) replay(wal *os.File) error {
+	data, err := ioutil.ReadAll(wal)
+	if err != nil {
+		return err
+	}
+
+	ctx := sql.NewEmptyContext()
+	var offset int
+	for len(data)-offset >= walFrameHeaderSize {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		checksum := binary.BigEndian.Uint32(data[offset+4 : offset+8])
+		end := offset + walFrameHeaderSize + size
+		if end > len(data) || crc32.ChecksumIEEE(data[offset+walFrameHeaderSize:end]) != checksum {
+			break
+		}
+
+		var record walRecord
+		if err := gob.NewDecoder(bytes.NewReader(data[offset+walFrameHeaderSize : end])).Decode(&record); err != nil {
+			return err
+		}
+
+		if record.LSN > j.lsn {
+			if err := j.apply(ctx, record); err != nil {
+				return err
+			}
+			j.lsn = record.LSN
+			j.changes += len(record.Changes)
+		}
+		offset = end
+	}
+
+	if offset < len(data) {
+		if err := wal.Truncate(int64(offset)); err != nil {
+			return err
+		}
+	}
+
+	_, err = wal.Seek(int64(offset), io.SeekStart)
+	return err
+}
+
+// apply applies the row changes of a record of the write-ahead log to its table.
+func (j *journal) apply(ctx *sql.Context, record walRecord) error {
+	table, ok := j.db.tables[record.Table]
+	if !ok {
+		return sql.ErrTableNotFound.New(record.Table)
+	}
+
+	t, ok := memoryTable(table)
+	if !ok {
+		return ErrUnpersistableTable.New(record.Table, table)
+	}
+
+	editor := &tableEditor{table: t}
+	for _, change := range record.Changes {
+		row, err := decodeRow(t.schema, change.Row)
+		if err != nil {
+			return err
+		}
+
+		switch change.Kind {
+		case rowInserted:
+			err = editor.Insert(ctx, row)
+		case rowDeleted:
+			err = editor.Delete(ctx, row)
+		case rowUpdated:
+			var newRow sql.Row
+			if newRow, err = decodeRow(t.schema, change.NewRow); err == nil {
+				err = editor.Update(ctx, row, newRow)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return editor.Close(ctx)
+}
+
+// snapshot returns the snapshot of the tables and triggers of the database.
+func (d *Database) snapshot() (*persistedSnapshot, error) {
+	snapshot := &persistedSnapshot{Triggers: d.triggers}
+	for key, table := range d.tables {
+		t, ok := memoryTable(table)
+		if !ok {
+			return nil, ErrUnpersistableTable.New(key, table)
+		}
+
+		persisted, err := t.snapshot(key)
+		if err != nil {
+			return nil, err
+		}
+
+		_, persisted.Pushdown = table.(*PushdownTable)
+		snapshot.Tables = append(snapshot.Tables, *persisted)
+	}
+
+	return snapshot, nil
+}
+
+// snapshot returns the snapshot of the table, which is in its database with the key given.
+func (t *Table) snapshot(key string) (*persistedTable, error) {
+	t.data.mu.Lock()
+	insert := t.data.insert
+	t.data.mu.Unlock()
+	version := t.data.snapshot()
+
+	persisted := &persistedTable{
+		Key:              key,
+		Name:             t.name,
+		Insert:           insert,
+		ForeignKeys:      t.foreignKeys,
+		Checks:           t.checks,
+		PkIndexesEnabled: t.pkIndexesEnabled,
+	}
+
+	ctx := sql.NewEmptyContext()
+	for _, col := range t.schema {
+		column, err := persistColumn(ctx, col)
+		if err != nil {
+			return nil, err
+		}
+		persisted.Columns = append(persisted.Columns, *column)
+	}
+
+	for _, key := range version.keys {
+		partition := persistedPartition{Key: string(key)}
+		for _, row := range version.partitions[string(key)] {
+			values, err := encodeRow(t.schema, row)
+			if err != nil {
+				return nil, err
+			}
+			partition.Rows = append(partition.Rows, values)
+		}
+		persisted.Partitions = append(persisted.Partitions, partition)
+	}
+
+	for name, index := range t.indexes {
+		var idx *MergeableIndex
+		switch index := index.(type) {
+		case *MergeableIndex:
+			idx = index
+		case *UnmergeableIndex:
+			idx = &index.MergeableIndex
+		default:
+			continue
+		}
+
+		persistedIdx := persistedIndex{Name: name, Unique: idx.Unique, Comment: idx.CommentStr}
+		for _, expr := range idx.Exprs {
+			if gf, ok := expr.(*expression.GetField); ok {
+				persistedIdx.Columns = append(persistedIdx.Columns, gf.Name())
+			}
+		}
+		persisted.Indexes = append(persisted.Indexes, persistedIdx)
+	}
+
+	return persisted, nil
+}
+
+func persistColumn(ctx *sql.Context, col *sql.Column) (*persistedColumn, error) {
+	column := &persistedColumn{
+		Name:          col.Name,
+		Type:          col.Type.String(),
+		AutoIncrement: col.AutoIncrement,
+		Nullable:      col.Nullable,
+		PrimaryKey:    col.PrimaryKey,
+		Comment:       col.Comment,
+		Extra:         col.Extra,
+	}
+
+	if col.Default == nil {
+		return column, nil
+	}
+
+	if !col.Default.IsLiteral() {
+		column.Default = &persistedDefault{Expression: col.Default.String()}
+		return column, nil
+	}
+
+	v, err := col.Default.Eval(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := encodeValue(col.Type, v)
+	if err != nil {
+		return nil, err
+	}
+
+	column.Default = &persistedDefault{Literal: true, Value: value}
+	return column, nil
+}
+
+// restoreTable returns the table restored from its snapshot.
+func restoreTable(ctx *sql.Context, persisted persistedTable, opts PersistenceOptions) (sql.Table, error) {
+	schema := make(sql.Schema, len(persisted.Columns))
+	for i, column := range persisted.Columns {
+		col, err := restoreColumn(ctx, persisted.Name, column, opts)
+		if err != nil {
+			return nil, err
+		}
+		schema[i] = col
+	}
+
+	version := &partitionsVersion{partitions: map[string][]sql.Row{}, owned: map[string]bool{}}
+	pt := &PushdownTable{
+		Table: Table{
+			name:             persisted.Name,
+			schema:           schema,
+			data:             &tableData{current: version, insert: persisted.Insert},
+			foreignKeys:      persisted.ForeignKeys,
+			checks:           persisted.Checks,
+			pkIndexesEnabled: persisted.PkIndexesEnabled,
+			partitionFunc:    opts.PartitionFuncs[persisted.Name],
+		},
+	}
+	t := &pt.Table
+
+	for _, partition := range persisted.Partitions {
+		rows := make([]sql.Row, len(partition.Rows))
+		for i, values := range partition.Rows {
+			row, err := decodeRow(schema, values)
+			if err != nil {
+				return nil, err
+			}
+			rows[i] = row
+		}
+		version.keys = append(version.keys, []byte(partition.Key))
+		version.partitions[partition.Key] = rows
+		version.owned[partition.Key] = true
+	}
+
+	for _, index := range persisted.Indexes {
+		columns := make([]sql.IndexColumn, len(index.Columns))
+		for i, name := range index.Columns {
+			columns[i] = sql.IndexColumn{Name: name}
+		}
+
+		constraint := sql.IndexConstraint_None
+		if index.Unique {
+			constraint = sql.IndexConstraint_Unique
+		}
+
+		if err := t.CreateIndex(ctx, index.Name, sql.IndexUsing_BTree, constraint, columns, index.Comment); err != nil {
+			return nil, err
+		}
+	}
+
+	t.reindex()
+	if persisted.Pushdown {
+		return pt, nil
+	}
+	return t, nil
+}
+
+func restoreColumn(ctx *sql.Context, table string, column persistedColumn, opts PersistenceOptions) (*sql.Column, error) {
+	typ, err := parseColumnType(column.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	col := &sql.Column{
+		Name:          column.Name,
+		Type:          typ,
+		AutoIncrement: column.AutoIncrement,
+		Nullable:      column.Nullable,
+		Source:        table,
+		PrimaryKey:    column.PrimaryKey,
+		Comment:       column.Comment,
+		Extra:         column.Extra,
+	}
+
+	switch {
+	case column.Default == nil:
+	case column.Default.Literal:
+		v, err := decodeValue(typ, column.Default.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		litType := typ
+		if v == nil {
+			litType = sql.Null
+		}
+
+		col.Default, err = sql.NewColumnDefaultValue(expression.NewLiteral(v, litType), typ, true, col.Nullable)
+		if err != nil {
+			return nil, err
+		}
+	case opts.ParseColumnDefault == nil:
+		return nil, ErrColumnDefaultNotRestorable.New(column.Name, table)
+	default:
+		if col.Default, err = opts.ParseColumnDefault(ctx, column.Default.Expression); err != nil {
+			return nil, err
+		}
+	}
+
+	return col, nil
+}
+
+// parseColumnType returns the type with the name given, as returned by the String method of sql.Type.
+func parseColumnType(name string) (sql.Type, error) {
+	stmt, err := sqlparser.Parse("CREATE TABLE t (c " + name + ")")
+	if err != nil {
+		return nil, err
+	}
+
+	ddl, ok := stmt.(*sqlparser.DDL)
+	if !ok || ddl.TableSpec == nil || len(ddl.TableSpec.Columns) != 1 {
+		return nil, sql.ErrInvalidType.New(name)
+	}
+
+	return sql.ColumnTypeToType(&ddl.TableSpec.Columns[0].Type)
+}
+
+func encodeRow(schema sql.Schema, row sql.Row) ([]persistedValue, error) {
+	values := make([]persistedValue, len(row))
+	for i, v := range row {
+		value, err := encodeValue(schema[i].Type, v)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+func decodeRow(schema sql.Schema, values []persistedValue) (sql.Row, error) {
+	if len(values) != len(schema) {
+		return nil, sql.ErrUnexpectedRowLength.New(len(schema), len(values))
+	}
+
+	row := make(sql.Row, len(values))
+	for i, value := range values {
+		v, err := decodeValue(schema[i].Type, value)
+		if err != nil {
+			return nil, err
+		}
+		row[i] = v
+	}
+	return row, nil
+}
+
+// encodeValue encodes the value given, of the type given, with sql.EncodeValue to be persisted.
+func encodeValue(typ sql.Type, v interface{}) (persistedValue, error) {
+	value, err := sql.EncodeValue(typ, v)
+	if err != nil {
+		return persistedValue{}, err
+	}
+	if value.IsNull() {
+		return persistedValue{Null: true}, nil
+	}
+	return persistedValue{Data: value.Val}, nil
+}
+
+// decodeValue decodes a persisted value of the type given with sql.DecodeValue.
+func decodeValue(typ sql.Type, value persistedValue) (interface{}, error) {
+	if value.Null {
+		return nil, nil
+	}
+
+	data := value.Data
+	// Empty values may be read back as nil, which would be NULL
+	if data == nil {
+		data = []byte{}
+	}
+	return sql.DecodeValue(typ, sql.Value{Typ: typ.Type(), Val: data})
+}
@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+)
+
+func TestPersistentDatabase(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	dir, err := ioutil.TempDir("", "memory")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+
+	defaultValue, err := sql.NewColumnDefaultValue(expression.NewLiteral("none", sql.LongText), sql.LongText, true, false)
+	require.NoError(err)
+
+	schema := sql.Schema{
+		{Name: "id", Type: sql.Int64, PrimaryKey: true, Source: "products"},
+		{Name: "name", Type: sql.LongText, Default: defaultValue, Source: "products"},
+		{Name: "price", Type: sql.MustCreateDecimalType(10, 2), Nullable: true, Source: "products"},
+		{Name: "created", Type: sql.Datetime, Nullable: true, Source: "products"},
+		{Name: "flags", Type: sql.MustCreateBitType(8), Nullable: true, Source: "products"},
+		{Name: "doc", Type: sql.JSON, Nullable: true, Source: "products"},
+	}
+	require.NoError(db.CreateTable(ctx, "products", schema))
+	table := db.Tables()["products"].(*Table)
+	require.NoError(table.CreateIndex(ctx, "idx_name", sql.IndexUsing_BTree, sql.IndexConstraint_Unique, []sql.IndexColumn{{Name: "name"}}, "by name"))
+	require.NoError(db.CreateTrigger(ctx, sql.TriggerDefinition{Name: "trig", CreateStatement: "CREATE TRIGGER trig ..."}))
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []sql.Row{
+		sql.NewRow(int64(1), "apple", convert(t, schema[2].Type, "1.50"), created, uint64(3), convert(t, sql.JSON, `{"a": 1}`)),
+		sql.NewRow(int64(2), "pear", nil, nil, nil, nil),
+		sql.NewRow(int64(3), "plum", convert(t, schema[2].Type, "0.25"), created, uint64(255), nil),
+	}
+
+	inserter := table.Inserter(ctx)
+	for _, row := range rows {
+		require.NoError(inserter.Insert(ctx, row))
+	}
+	require.NoError(inserter.Close(ctx))
+
+	updater := table.Updater(ctx)
+	newRow := sql.NewRow(int64(2), "pear", convert(t, schema[2].Type, "2.00"), nil, nil, nil)
+	require.NoError(updater.Update(ctx, rows[1], newRow))
+	require.NoError(updater.Close(ctx))
+	rows[1] = newRow
+
+	deleter := table.Deleter(ctx)
+	require.NoError(deleter.Delete(ctx, rows[2]))
+	require.NoError(deleter.Close(ctx))
+	rows = rows[:2]
+
+	// The rows changes are in the write-ahead log, so they're replayed even if the database isn't closed
+	require.NoError(db.journal.wal.Close())
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+
+	table, ok := db.Tables()["products"].(*Table)
+	require.True(ok)
+	require.Equal(len(schema), len(table.Schema()))
+	for i, col := range table.Schema() {
+		require.Equal(schema[i].Name, col.Name)
+		require.Equal(schema[i].Type, col.Type)
+		require.Equal(schema[i].PrimaryKey, col.PrimaryKey)
+		require.Equal(schema[i].Nullable, col.Nullable)
+		require.Equal("products", col.Source)
+	}
+
+	v, err := table.Schema()[1].Default.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal("none", v)
+
+	require.ElementsMatch(rows, testFlatRows(t, table))
+
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	require.Len(indexes, 2)
+	require.Equal("PRIMARY", indexes[0].ID())
+	require.Equal("idx_name", indexes[1].ID())
+	require.True(indexes[1].IsUnique())
+	require.Equal("by name", indexes[1].Comment())
+
+	triggers, err := db.GetTriggers(ctx)
+	require.NoError(err)
+	require.Equal([]sql.TriggerDefinition{{Name: "trig", CreateStatement: "CREATE TRIGGER trig ..."}}, triggers)
+
+	// Tables keep being persisted after they're restored
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(4), "fig", nil, nil, nil, nil)))
+	require.NoError(db.Close())
+	err = table.Insert(ctx, sql.NewRow(int64(5), "kiwi", nil, nil, nil, nil))
+	require.True(ErrPersistentDatabaseClosed.Is(err))
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+	defer db.Close()
+
+	require.Len(testFlatRows(t, db.Tables()["products"]), 3)
+	require.Equal(0, fileSize(t, filepath.Join(dir, walFileName)))
+}
+
+func TestPersistentDatabaseSchemaChanges(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	dir, err := ioutil.TempDir("", "memory")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+
+	require.NoError(db.CreateTable(ctx, "a", sql.Schema{{Name: "i", Type: sql.Int64, Source: "a"}}))
+	require.NoError(db.CreateTable(ctx, "b", sql.Schema{{Name: "i", Type: sql.Int64, Source: "b"}}))
+	require.NoError(db.Tables()["a"].(*Table).Insert(ctx, sql.NewRow(int64(1))))
+
+	db.AddTable("c", NewPartitionedPushdownTable("c", sql.Schema{{Name: "s", Type: sql.Text, Source: "c"}}, 3))
+	require.NoError(db.Tables()["c"].(*PushdownTable).Insert(ctx, sql.NewRow("x")))
+
+	require.NoError(db.RenameTable(ctx, "a", "d"))
+	require.NoError(db.DropTable(ctx, "b"))
+
+	defaultValue, err := parse.StringToColumnDefaultValue(ctx, "(2 + 3)")
+	require.NoError(err)
+	require.NoError(db.Tables()["d"].(*Table).AddColumn(ctx, &sql.Column{Name: "j", Type: sql.Int64, Default: defaultValue}, nil))
+	require.NoError(db.journal.wal.Close())
+
+	// Columns whose defaults are expressions can only be restored by parsing them
+	_, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.True(ErrColumnDefaultNotRestorable.Is(err))
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{ParseColumnDefault: parse.StringToColumnDefaultValue})
+	require.NoError(err)
+	defer db.Close()
+
+	tables := db.Tables()
+	require.Len(tables, 2)
+
+	d := tables["d"].(*Table)
+	require.Equal("d", d.Name())
+	require.Equal([]sql.Row{{int64(1), int64(5)}}, testFlatRows(t, d))
+	v, err := d.Schema()[1].Default.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(5), v)
+
+	c := tables["c"].(*PushdownTable)
+	count, err := c.PartitionCount(ctx)
+	require.NoError(err)
+	require.Equal(int64(3), count)
+	require.Equal([]sql.Row{{"x"}}, testFlatRows(t, c))
+}
+
+func TestPersistentDatabaseSnapshotThreshold(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	dir, err := ioutil.TempDir("", "memory")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewPersistentDatabase("mydb", dir, PersistenceOptions{SnapshotThreshold: 3, NoSync: true})
+	require.NoError(err)
+
+	require.NoError(db.CreateTable(ctx, "t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}))
+	table := db.Tables()["t"].(*Table)
+	walPath := filepath.Join(dir, walFileName)
+
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2))))
+	require.NotEqual(0, fileSize(t, walPath))
+	wal, err := ioutil.ReadFile(walPath)
+	require.NoError(err)
+
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3))))
+	require.Equal(0, fileSize(t, walPath))
+	require.NoError(db.journal.wal.Close())
+
+	// Records that were snapshotted are skipped if the log wasn't truncated after the snapshot
+	require.NoError(ioutil.WriteFile(walPath, wal, 0644))
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+	defer db.Close()
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}, {int64(3)}}, testFlatRows(t, db.Tables()["t"]))
+}
+
+func TestPersistentDatabaseTornWrite(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	dir, err := ioutil.TempDir("", "memory")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	db, err := NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+
+	require.NoError(db.CreateTable(ctx, "t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}))
+	table := db.Tables()["t"].(*Table)
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2))))
+	require.NoError(db.journal.wal.Close())
+
+	// The last record was only partially written when the process stopped
+	walPath := filepath.Join(dir, walFileName)
+	size := fileSize(t, walPath)
+	require.NoError(os.Truncate(walPath, int64(size-3)))
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, testFlatRows(t, db.Tables()["t"]))
+
+	// The partial record is truncated, so new records are appended after the last complete one
+	require.NoError(db.Tables()["t"].(*Table).Insert(ctx, sql.NewRow(int64(3))))
+	require.NoError(db.journal.wal.Close())
+
+	db, err = NewPersistentDatabase("mydb", dir, PersistenceOptions{})
+	require.NoError(err)
+	defer db.Close()
+	require.Equal([]sql.Row{{int64(1)}, {int64(3)}}, testFlatRows(t, db.Tables()["t"]))
+}
+
+func TestCheckpointNotPersistent(t *testing.T) {
+	require := require.New(t)
+	db := NewDatabase("mydb")
+	require.True(ErrNotPersistent.Is(db.Checkpoint()))
+	require.NoError(db.Close())
+}
+
+func convert(t *testing.T, typ sql.Type, v interface{}) interface{} {
+	t.Helper()
+	v, err := typ.Convert(v)
+	require.NoError(t, err)
+	return v
+}
+
+func fileSize(t *testing.T, path string) int {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return int(info.Size())
+}
@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var (
+	// ErrInvalidDistribution is returned when a Distribution can't generate values because of its parameters.
+	ErrInvalidDistribution = errors.NewKind("invalid distribution: %s")
+
+	// ErrNoDistribution is returned by NewRandomTable when a column has no Distribution and there's no default one for
+	// its type.
+	ErrNoDistribution = errors.NewKind("no distribution for column %s of type %s")
+)
+
+// Distribution generates the values of a column of the tables returned by NewRandomTable. It's given the source of
+// random numbers of the table and the number of the row being generated, starting at 0, and returns the value of the
+// column for the row, which is converted to the type of the column. Distributions must only use the source given to
+// be random, so the same rows are generated for the same seed.
+type Distribution func(r *rand.Rand, row int) (interface{}, error)
+
+// RandomTableOptions are the options of the values of the rows generated by NewRandomTable.
+type RandomTableOptions struct {
+	// Seed is the seed of the source of random numbers of the table. Tables with the same schema, number of rows and
+	// options have the same rows.
+	Seed int64
+	// Columns are the distributions of the values of the columns with the names given, which are case insensitive.
+	// Columns of the primary key have a Sequence starting at 1 by default, and other columns have uniformly
+	// distributed values: numbers between 0 and 100, strings of 1 to 16 letters, dates and times between 2000 and
+	// 2030, and the values of enums and sets.
+	Columns map[string]Distribution
+}
+
+// NewRandomTable returns a new table with the schema given and the number of rows given, whose values are generated
+// by the distributions of the options given for their columns. It's meant for benchmarks, which need large inputs that
+// are the same every time they're run. The table options given are applied to the table as they're applied by
+// NewTable, before its rows are inserted. Rows are inserted like any other row, so an error is returned if the values
+// generated for a primary key or a unique index are duplicated.
+func NewRandomTable(name string, schema sql.Schema, numRows int, opts RandomTableOptions, tableOpts ...TableOption) (*Table, error) {
+	distributions := make([]Distribution, len(schema))
+	for colName, distribution := range opts.Columns {
+		i := -1
+		for j, col := range schema {
+			if strings.EqualFold(col.Name, colName) {
+				i = j
+				break
+			}
+		}
+		if i == -1 {
+			return nil, sql.ErrColumnNotFound.New(colName)
+		}
+		distributions[i] = distribution
+	}
+
+	for i, col := range schema {
+		if distributions[i] == nil {
+			distribution, ok := defaultDistribution(col)
+			if !ok {
+				return nil, ErrNoDistribution.New(col.Name, col.Type)
+			}
+			distributions[i] = distribution
+		}
+	}
+
+	table := NewTable(name, schema, tableOpts...)
+	ctx := sql.NewEmptyContext()
+	inserter := table.Inserter(ctx)
+	r := rand.New(rand.NewSource(opts.Seed))
+	for i := 0; i < numRows; i++ {
+		row := make(sql.Row, len(schema))
+		for j, col := range schema {
+			v, err := distributions[j](r, i)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				if v, err = col.Type.Convert(v); err != nil {
+					return nil, err
+				}
+			}
+			row[j] = v
+		}
+
+		if err := inserter.Insert(ctx, row); err != nil {
+			_ = inserter.Close(ctx)
+			return nil, err
+		}
+	}
+	if err := inserter.Close(ctx); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// defaultDistribution returns the distribution of the values of the column given when there's none in the options of
+// NewRandomTable, or false if there's no default one for its type.
+func defaultDistribution(col *sql.Column) (Distribution, bool) {
+	if col.PrimaryKey {
+		return Sequence(1), true
+	}
+
+	switch typ := col.Type.(type) {
+	case sql.EnumType:
+		return choice(typ.Values()), true
+	case sql.SetType:
+		return choice(typ.Values()), true
+	case sql.StringType:
+		maxLen := typ.MaxCharacterLength()
+		if maxLen > 16 {
+			maxLen = 16
+		}
+		return RandomStrings(1, int(maxLen)), true
+	case sql.DatetimeType:
+		return UniformTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)), true
+	}
+
+	if sql.IsNumber(col.Type) {
+		return Uniform(0, 100), true
+	}
+	return nil, false
+}
+
+// Sequence returns the Distribution of consecutive integers from the one given, one for each row.
+func Sequence(start int64) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		return start + int64(row), nil
+	}
+}
+
+// Uniform returns the Distribution of integers between min and max, both included, with the same probability.
+func Uniform(min, max int64) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if max < min {
+			return nil, ErrInvalidDistribution.New("max of uniform distribution is less than its min")
+		}
+		return min + r.Int63n(max-min+1), nil
+	}
+}
+
+// UniformFloat returns the Distribution of floating point numbers between min, included, and max, excluded, with the
+// same probability.
+func UniformFloat(min, max float64) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if max < min {
+			return nil, ErrInvalidDistribution.New("max of uniform distribution is less than its min")
+		}
+		return min + r.Float64()*(max-min), nil
+	}
+}
+
+// Normal returns the Distribution of floating point numbers with the normal distribution of the mean and the standard
+// deviation given.
+func Normal(mean, stddev float64) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		return mean + r.NormFloat64()*stddev, nil
+	}
+}
+
+// Zipf returns the Distribution of integers between 0 and max, both included, whose probability is inversely
+// proportional to the power s of their rank, so a few small values are much more frequent than the rest, like the
+// keys of skewed joins. s must be greater than 1.
+func Zipf(s float64, max uint64) Distribution {
+	var zipf *rand.Zipf
+	var source *rand.Rand
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if source != r {
+			zipf = rand.NewZipf(r, s, 1, max)
+			source = r
+		}
+		if zipf == nil {
+			return nil, ErrInvalidDistribution.New("exponent of zipf distribution must be greater than 1")
+		}
+		return int64(zipf.Uint64()), nil
+	}
+}
+
+// Choice returns the Distribution of the values given, which are chosen with the same probability.
+func Choice(values ...interface{}) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if len(values) == 0 {
+			return nil, ErrInvalidDistribution.New("no values to choose from")
+		}
+		return values[r.Intn(len(values))], nil
+	}
+}
+
+func choice(values []string) Distribution {
+	choices := make([]interface{}, len(values))
+	for i, v := range values {
+		choices[i] = v
+	}
+	return Choice(choices...)
+}
+
+// RandomStrings returns the Distribution of strings of lowercase letters, whose length is between minLen and maxLen,
+// both included, with the same probability.
+func RandomStrings(minLen, maxLen int) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if maxLen < minLen || minLen < 0 {
+			return nil, ErrInvalidDistribution.New("invalid lengths of random strings")
+		}
+
+		s := make([]byte, minLen+r.Intn(maxLen-minLen+1))
+		for i := range s {
+			s[i] = byte('a' + r.Intn(26))
+		}
+		return string(s), nil
+	}
+}
+
+// UniformTime returns the Distribution of times between min, included, and max, excluded, with the same probability,
+// truncated to seconds.
+func UniformTime(min, max time.Time) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		seconds := max.Unix() - min.Unix()
+		if seconds <= 0 {
+			return nil, ErrInvalidDistribution.New("max of uniform distribution is not after its min")
+		}
+		return min.Add(time.Duration(r.Int63n(seconds)) * time.Second).UTC(), nil
+	}
+}
+
+// WithNulls returns the Distribution that generates NULL for the fraction of rows given, between 0 and 1, chosen at
+// random, and the values of the distribution given for the rest of the rows.
+func WithNulls(fraction float64, distribution Distribution) Distribution {
+	return func(r *rand.Rand, row int) (interface{}, error) {
+		if r.Float64() < fraction {
+			return nil, nil
+		}
+		return distribution(r, row)
+	}
+}
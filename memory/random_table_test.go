@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestNewRandomTable(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "n", Type: sql.Int32, Source: "t"},
+		{Name: "s", Type: sql.MustCreateStringWithDefaults(sqltypes.VarChar, 4), Source: "t"},
+		{Name: "d", Type: sql.Datetime, Source: "t"},
+		{Name: "e", Type: sql.MustCreateEnumType([]string{"a", "b"}, sql.Collation_Default), Source: "t"},
+		{Name: "f", Type: sql.Float64, Source: "t", Nullable: true},
+		{Name: "k", Type: sql.Int64, Source: "t"},
+	}
+	opts := RandomTableOptions{
+		Seed: 1,
+		Columns: map[string]Distribution{
+			"F": WithNulls(0.5, Normal(10, 1)),
+			"k": Zipf(2, 9),
+		},
+	}
+
+	table, err := NewRandomTable("t", schema, 1000, opts, WithPartitions(4))
+	require.NoError(err)
+	rows := testFlatRows(t, table)
+	require.Len(rows, 1000)
+	require.Len(testPartitionRows(t, table), 4)
+
+	ids := make(map[int64]bool)
+	nulls := 0
+	keys := make(map[int64]int)
+	for _, row := range rows {
+		ids[row[0].(int64)] = true
+		require.True(row[1].(int32) >= 0 && row[1].(int32) <= 100)
+		require.True(len(row[2].(string)) >= 1 && len(row[2].(string)) <= 4)
+		d := row[3].(time.Time)
+		require.True(d.Year() >= 2000 && d.Year() < 2030)
+		require.Contains([]string{"a", "b"}, row[4])
+		if row[5] == nil {
+			nulls++
+		}
+		keys[row[6].(int64)]++
+	}
+	require.Len(ids, 1000)
+	require.True(ids[1] && ids[1000])
+	require.InDelta(500, nulls, 100)
+	require.True(keys[0] > keys[1] && keys[1] > keys[9])
+
+	// The same rows are generated for the same seed, and different ones for another seed
+	same, err := NewRandomTable("t", schema, 1000, opts, WithPartitions(4))
+	require.NoError(err)
+	require.Equal(rows, testFlatRows(t, same))
+
+	opts.Seed = 2
+	other, err := NewRandomTable("t", schema, 1000, opts, WithPartitions(4))
+	require.NoError(err)
+	require.NotEqual(rows, testFlatRows(t, other))
+}
+
+func TestNewRandomTableErrors(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true}}
+	_, err := NewRandomTable("t", schema, 10, RandomTableOptions{Columns: map[string]Distribution{"x": Sequence(0)}})
+	require.True(sql.ErrColumnNotFound.Is(err))
+
+	_, err = NewRandomTable("t", schema, 10, RandomTableOptions{Columns: map[string]Distribution{"i": Uniform(0, 1)}})
+	require.True(sql.ErrUniqueKeyViolation.Is(err))
+
+	_, err = NewRandomTable("t", schema, 10, RandomTableOptions{Columns: map[string]Distribution{"i": Zipf(1, 10)}})
+	require.True(ErrInvalidDistribution.Is(err))
+
+	_, err = NewRandomTable("t", sql.Schema{{Name: "j", Type: sql.JSON, Source: "t"}}, 10, RandomTableOptions{})
+	require.True(ErrNoDistribution.Is(err))
+
+	table, err := NewRandomTable("t", sql.Schema{{Name: "j", Type: sql.JSON, Source: "t"}}, 2, RandomTableOptions{
+		Columns: map[string]Distribution{"j": Choice(`{"a": 1}`)},
+	})
+	require.NoError(err)
+	require.Len(testFlatRows(t, table), 2)
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrRevisionNotFound is returned when a database is read AS OF a revision it doesn't have.
+var ErrRevisionNotFound = errors.NewKind("revision %v of database %s not found")
+
+var _ sql.VersionedDatabase = (*Database)(nil)
+
+// revision is a state of a database saved by Database.SaveRevision.
+type revision struct {
+	name     string
+	time     time.Time
+	snapshot *DatabaseSnapshot
+}
+
+// revisions are the revisions of a database, in the order they were saved.
+type revisions struct {
+	mu        sync.RWMutex
+	revisions []revision
+}
+
+// SaveRevision saves the current state of the database as a revision with the name given, which queries read with
+// AS OF. Tables read AS OF the name of a revision have the rows, schema and indexes they had when it was saved, and
+// tables read AS OF a time are the ones of the last revision saved at or before it. Saving a revision with the name of
+// a previous one hides the previous one from reads by name.
+//
+// Revisions are as cheap as snapshots, since no rows are copied, and they're kept in memory until the database is
+// discarded, even if the database is persistent.
+func (d *Database) SaveRevision(name string) {
+	d.saveRevision(name, time.Now().UTC())
+}
+
+func (d *Database) saveRevision(name string, t time.Time) {
+	snapshot := d.Snapshot()
+
+	d.revisions.mu.Lock()
+	defer d.revisions.mu.Unlock()
+	d.revisions.revisions = append(d.revisions.revisions, revision{name: name, time: t, snapshot: snapshot})
+}
+
+// GetTableInsensitiveAsOf implements the sql.VersionedDatabase interface. Revisions are read by their name, or by a
+// time, which is any value that converts to a DATETIME, such as the result of TIMESTAMP() or NOW(). Tables read AS OF a
+// revision can't be modified.
+func (d *Database) GetTableInsensitiveAsOf(ctx *sql.Context, tblName string, asOf interface{}) (sql.Table, bool, error) {
+	snapshot, err := d.revisionAsOf(asOf)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tbl, ok := sql.GetTableInsensitive(tblName, snapshot.tables)
+	if !ok {
+		return nil, false, nil
+	}
+	return snapshot.tableAsOf(tbl), true, nil
+}
+
+// GetTableNamesAsOf implements the sql.VersionedDatabase interface.
+func (d *Database) GetTableNamesAsOf(ctx *sql.Context, asOf interface{}) ([]string, error) {
+	snapshot, err := d.revisionAsOf(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	tblNames := make([]string, 0, len(snapshot.tables))
+	for name := range snapshot.tables {
+		tblNames = append(tblNames, name)
+	}
+	return tblNames, nil
+}
+
+// revisionAsOf returns the snapshot of the revision with the name given, or of the last revision saved at or before
+// the time given.
+func (d *Database) revisionAsOf(asOf interface{}) (*DatabaseSnapshot, error) {
+	d.revisions.mu.RLock()
+	defer d.revisions.mu.RUnlock()
+	revisions := d.revisions.revisions
+
+	if name, ok := asOf.(string); ok {
+		for i := len(revisions) - 1; i >= 0; i-- {
+			if revisions[i].name == name {
+				return revisions[i].snapshot, nil
+			}
+		}
+	}
+
+	t, err := sql.Datetime.Convert(asOf)
+	if err != nil || t == nil {
+		return nil, ErrRevisionNotFound.New(asOf, d.name)
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if !revisions[i].time.After(t.(time.Time)) {
+			return revisions[i].snapshot, nil
+		}
+	}
+	return nil, ErrRevisionNotFound.New(asOf, d.name)
+}
+
+// tableAsOf returns the table given, which is a table of the snapshot, as it was when the snapshot was taken. Tables
+// that aren't tables of this package are returned as they are.
+func (s *DatabaseSnapshot) tableAsOf(tbl sql.Table) sql.Table {
+	current, ok := memoryTable(tbl)
+	if !ok {
+		return tbl
+	}
+
+	for _, snapshot := range s.snapshots {
+		if snapshot.table == current {
+			t := snapshot.tableAsOf()
+			if _, ok := tbl.(*PushdownTable); ok {
+				return &PushdownTable{Table: *t}
+			}
+			return t
+		}
+	}
+	return tbl
+}
+
+// tableAsOf returns a new table with the rows, schema and indexes of the snapshot, whose storage is detached from the
+// table it was taken of, so it isn't changed by the writes to the table.
+func (s *TableSnapshot) tableAsOf() *Table {
+	t := &Table{
+		name:             s.name,
+		schema:           copySchema(s.schema),
+		indexes:          copyIndexes(s.indexes),
+		foreignKeys:      append([]sql.ForeignKeyConstraint(nil), s.foreignKeys...),
+		checks:           append([]sql.CheckConstraint(nil), s.checks...),
+		pkIndexesEnabled: s.pkIndexesEnabled,
+		partitionFunc:    s.partitionFunc,
+		data:             &tableData{current: s.version, insert: s.insert},
+	}
+
+	// Lookups of indexes read the rows of the table of their index, which must be this one.
+	for name, index := range t.indexes {
+		switch index := index.(type) {
+		case *MergeableIndex:
+			if index.Tbl == s.table {
+				idx := *index
+				idx.Tbl = t
+				t.indexes[name] = &idx
+			}
+		case *UnmergeableIndex:
+			if index.Tbl == s.table {
+				idx := *index
+				idx.Tbl = t
+				t.indexes[name] = &idx
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestDatabaseRevisions(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := NewDatabase("mydb")
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "a"},
+	}
+	require.NoError(db.CreateTable(ctx, "a", schema))
+	a := db.Tables()["a"].(*Table)
+	require.NoError(a.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	require.NoError(a.Insert(ctx, sql.NewRow(int64(1), "1")))
+
+	t1 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	db.saveRevision("v1", t1)
+
+	require.NoError(a.Insert(ctx, sql.NewRow(int64(2), "2")))
+	require.NoError(db.CreateTable(ctx, "b", sql.Schema{{Name: "i", Type: sql.Int64, Source: "b"}}))
+	db.saveRevision("v2", t1.Add(time.Hour))
+
+	require.NoError(a.Insert(ctx, sql.NewRow(int64(3), "3")))
+	require.NoError(a.AddColumn(ctx, &sql.Column{Name: "j", Type: sql.Int64, Nullable: true}, nil))
+
+	tableAsOf := func(name string, asOf interface{}) *Table {
+		tbl, ok, err := db.GetTableInsensitiveAsOf(ctx, name, asOf)
+		require.NoError(err)
+		require.True(ok)
+		return tbl.(*Table)
+	}
+
+	// Revisions are read by name or by time, with the rows, schema and indexes of the table when they were saved
+	v1 := tableAsOf("A", "v1")
+	require.Equal(schema, v1.Schema())
+	require.Equal([]sql.Row{{int64(1), "1"}}, testFlatRows(t, v1))
+	require.Equal([]sql.Row{{int64(1), "1"}}, testIndexLookup(t, v1, "idx_s", "1"))
+	require.Empty(testIndexLookup(t, v1, "idx_s", "2"))
+
+	require.Equal([]sql.Row{{int64(1), "1"}}, testFlatRows(t, tableAsOf("a", "2020-01-01 00:30:00")))
+	require.Equal([]sql.Row{{int64(1), "1"}, {int64(2), "2"}}, testFlatRows(t, tableAsOf("a", t1.Add(2*time.Hour))))
+
+	_, ok, err := db.GetTableInsensitiveAsOf(ctx, "b", "v1")
+	require.NoError(err)
+	require.False(ok)
+	names, err := db.GetTableNamesAsOf(ctx, "v2")
+	require.NoError(err)
+	require.ElementsMatch([]string{"a", "b"}, names)
+
+	_, _, err = db.GetTableInsensitiveAsOf(ctx, "a", "2019-12-31")
+	require.True(ErrRevisionNotFound.Is(err))
+	_, _, err = db.GetTableInsensitiveAsOf(ctx, "a", "v3")
+	require.True(ErrRevisionNotFound.Is(err))
+
+	// Writes to the table don't change its revisions, and writes to the revisions don't change the table
+	require.NoError(v1.Insert(ctx, sql.NewRow(int64(4), "4")))
+	require.Equal([]sql.Row{{int64(1), "1"}}, testFlatRows(t, tableAsOf("a", "v1")))
+	require.Len(testFlatRows(t, a), 3)
+	requireIndexEntriesConsistent(t, a)
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrForeignSnapshot is returned when a snapshot is restored to a table or database other than the one it was
+// taken of.
+var ErrForeignSnapshot = errors.NewKind("snapshot of %s can't be restored to %s")
+
+// TableSnapshot is the state of a table at a point in time, taken by Table.Snapshot, which the table can be reset to
+// with Table.Restore any number of times.
+//
+// Snapshots are cheap: rows are never copied to take or restore them, since the versions of the partitions of a table
+// are never changed once they're read, so they can be shared by the snapshots and the table.
+type TableSnapshot struct {
+	table            *Table
+	name             string
+	schema           sql.Schema
+	indexes          map[string]sql.Index
+	foreignKeys      []sql.ForeignKeyConstraint
+	checks           []sql.CheckConstraint
+	pkIndexesEnabled bool
+	partitionFunc    PartitionFunc
+	version          *partitionsVersion
+	insert           int
+}
+
+// Snapshot returns the current state of the table: its rows, schema, indexes, foreign keys and check constraints.
+func (t *Table) Snapshot() *TableSnapshot {
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+
+	t.data.current.read = true
+	return &TableSnapshot{
+		table:            t,
+		name:             t.name,
+		schema:           copySchema(t.schema),
+		indexes:          copyIndexes(t.indexes),
+		foreignKeys:      append([]sql.ForeignKeyConstraint(nil), t.foreignKeys...),
+		checks:           append([]sql.CheckConstraint(nil), t.checks...),
+		pkIndexesEnabled: t.pkIndexesEnabled,
+		partitionFunc:    t.partitionFunc,
+		version:          t.data.current,
+		insert:           t.data.insert,
+	}
+}
+
+// Restore resets the table to the state of the snapshot given, which must have been taken of this table. Scans that
+// started before the table is restored keep reading the rows they started with. The table must not be changed while
+// it's being restored.
+func (t *Table) Restore(snapshot *TableSnapshot) error {
+	if snapshot.table.data != t.data {
+		return ErrForeignSnapshot.New(snapshot.name, t.name)
+	}
+
+	t.restore(snapshot)
+	return t.persist()
+}
+
+func (t *Table) restore(snapshot *TableSnapshot) {
+	t.name = snapshot.name
+	t.schema = copySchema(snapshot.schema)
+	t.indexes = copyIndexes(snapshot.indexes)
+	t.foreignKeys = append([]sql.ForeignKeyConstraint(nil), snapshot.foreignKeys...)
+	t.checks = append([]sql.CheckConstraint(nil), snapshot.checks...)
+	t.pkIndexesEnabled = snapshot.pkIndexesEnabled
+	t.partitionFunc = snapshot.partitionFunc
+
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+	t.data.current = snapshot.version
+	t.data.insert = snapshot.insert
+}
+
+// DatabaseSnapshot is the state of a database at a point in time, taken by Database.Snapshot, which the database can be
+// reset to with Database.Restore any number of times. It's meant for test suites, which can seed a database once and
+// restore it before every test instead of seeding it again.
+type DatabaseSnapshot struct {
+	db        *Database
+	tables    map[string]sql.Table
+	snapshots []*TableSnapshot
+	triggers  []sql.TriggerDefinition
+}
+
+// Snapshot returns the current state of the database: its tables, with their rows, and its triggers. Tables that
+// aren't tables of this package are restored as they are when the database is restored, with the changes made to them
+// since the snapshot was taken.
+func (d *Database) Snapshot() *DatabaseSnapshot {
+	snapshot := &DatabaseSnapshot{
+		db:       d,
+		tables:   make(map[string]sql.Table, len(d.tables)),
+		triggers: append([]sql.TriggerDefinition(nil), d.triggers...),
+	}
+
+	for name, table := range d.tables {
+		snapshot.tables[name] = table
+		if t, ok := memoryTable(table); ok {
+			snapshot.snapshots = append(snapshot.snapshots, t.Snapshot())
+		}
+	}
+	return snapshot
+}
+
+// Restore resets the database to the state of the snapshot given, which must have been taken of this database: tables
+// created since the snapshot was taken are dropped, tables dropped since then are added back, and every table has the
+// rows, schema and indexes it had. Tables of the snapshot are restored in place, so the tables returned by the
+// database before it's restored can still be used. The database must not be changed while it's being restored.
+func (d *Database) Restore(snapshot *DatabaseSnapshot) error {
+	if snapshot.db != d {
+		return ErrForeignSnapshot.New(snapshot.db.name, d.name)
+	}
+
+	d.tables = make(map[string]sql.Table, len(snapshot.tables))
+	for name, table := range snapshot.tables {
+		d.tables[name] = table
+	}
+	for _, tableSnapshot := range snapshot.snapshots {
+		tableSnapshot.table.restore(tableSnapshot)
+	}
+	d.triggers = append([]sql.TriggerDefinition(nil), snapshot.triggers...)
+	return d.persist()
+}
+
+// copySchema returns a copy of the schema given with copies of its columns, which are changed in place by some
+// changes to the schema of a table.
+func copySchema(schema sql.Schema) sql.Schema {
+	result := make(sql.Schema, len(schema))
+	for i, col := range schema {
+		c := *col
+		result[i] = &c
+	}
+	return result
+}
+
+func copyIndexes(indexes map[string]sql.Index) map[string]sql.Index {
+	if indexes == nil {
+		return nil
+	}
+
+	result := make(map[string]sql.Index, len(indexes))
+	for name, index := range indexes {
+		result[name] = index
+	}
+	return result
+}
+
+var _ sql.SnapshotDatabase = (*Database)(nil)
+
+// StartSnapshotTransaction implements the sql.SnapshotDatabase interface. Transactions read a snapshot of the database
+// taken when they start, which is as cheap as any other snapshot.
+func (d *Database) StartSnapshotTransaction(ctx *sql.Context) (sql.SnapshotTransaction, error) {
+	return &snapshotTransaction{name: d.name, snapshot: d.Snapshot()}, nil
+}
+
+// snapshotTransaction is a read-only transaction of a database, whose tables are the ones of a snapshot of it.
+type snapshotTransaction struct {
+	name     string
+	snapshot *DatabaseSnapshot
+}
+
+var _ sql.SnapshotTransaction = (*snapshotTransaction)(nil)
+
+// Name implements the sql.Database interface.
+func (t *snapshotTransaction) Name() string {
+	return t.name
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (t *snapshotTransaction) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	tbl, ok := sql.GetTableInsensitive(tblName, t.snapshot.tables)
+	if !ok {
+		return nil, false, nil
+	}
+	return t.snapshot.tableAsOf(tbl), true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (t *snapshotTransaction) GetTableNames(ctx *sql.Context) ([]string, error) {
+	tblNames := make([]string, 0, len(t.snapshot.tables))
+	for name := range t.snapshot.tables {
+		tblNames = append(tblNames, name)
+	}
+	return tblNames, nil
+}
+
+// Commit implements the sql.SnapshotTransaction interface.
+func (t *snapshotTransaction) Commit(ctx *sql.Context) error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestDatabaseSnapshot(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := NewDatabase("mydb")
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "a", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "a"},
+	}
+	require.NoError(db.CreateTable(ctx, "a", schema))
+	require.NoError(db.CreateTable(ctx, "b", sql.Schema{{Name: "i", Type: sql.Int64, Source: "b"}}))
+	a := db.Tables()["a"].(*Table)
+	require.NoError(a.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(a.Insert(ctx, sql.NewRow(i, fmt.Sprint(i))))
+	}
+	require.NoError(db.CreateTrigger(ctx, sql.TriggerDefinition{Name: "trig"}))
+
+	snapshot := db.Snapshot()
+	seeded := []sql.Row{{int64(1), "1"}, {int64(2), "2"}, {int64(3), "3"}}
+
+	for n := 0; n < 2; n++ {
+		// Change everything in the database, then restore it
+		updater := a.Updater(ctx)
+		require.NoError(updater.Update(ctx, sql.NewRow(int64(1), "1"), sql.NewRow(int64(1), "x")))
+		require.NoError(updater.Close(ctx))
+		require.NoError(a.Insert(ctx, sql.NewRow(int64(4), "4")))
+		require.NoError(a.AddColumn(ctx, &sql.Column{Name: "j", Type: sql.Int64, Nullable: true}, &sql.ColumnOrder{First: true}))
+		require.NoError(a.DropIndex(ctx, "idx_s"))
+		require.NoError(db.DropTable(ctx, "b"))
+		require.NoError(db.CreateTable(ctx, "c", sql.Schema{{Name: "i", Type: sql.Int64, Source: "c"}}))
+		require.NoError(db.DropTrigger(ctx, "trig"))
+
+		require.NoError(db.Restore(snapshot))
+
+		tables := db.Tables()
+		require.Len(tables, 2)
+		require.True(a == tables["a"])
+		require.Contains(tables, "b")
+		require.Equal(schema, a.Schema())
+		require.ElementsMatch(seeded, testFlatRows(t, a))
+		require.Equal([]sql.Row{{int64(2), "2"}}, testIndexLookup(t, a, "idx_s", "2"))
+		requireIndexEntriesConsistent(t, a)
+
+		triggers, err := db.GetTriggers(ctx)
+		require.NoError(err)
+		require.Len(triggers, 1)
+	}
+
+	// Snapshots can only be restored to the database they were taken of
+	require.True(ErrForeignSnapshot.Is(NewDatabase("mydb").Restore(snapshot)))
+}
+
+func TestTableSnapshot(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPartitionedTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}}, 2)
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(1))))
+	snapshot := table.Snapshot()
+
+	partitions, err := table.Partitions(ctx)
+	require.NoError(err)
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(2))))
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(3))))
+	require.NoError(table.Restore(snapshot))
+
+	// Rows are inserted into the partitions in the same order after the table is restored
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(4))))
+	require.Equal(map[string][]sql.Row{"0": {{int64(1)}}, "1": {{int64(4)}}}, testPartitionRows(t, table))
+
+	// Scans that started before the table was restored keep reading their rows
+	p, err := partitions.Next(ctx)
+	require.NoError(err)
+	iter, err := table.PartitionRows(ctx, p)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, rows)
+
+	other := NewTable("t", table.Schema())
+	require.True(ErrForeignSnapshot.Is(other.Restore(snapshot)))
+}
+
+func BenchmarkDatabaseRestore(b *testing.B) {
+	ctx := sql.NewEmptyContext()
+	db := NewDatabase("mydb")
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("t%d", i)
+		require.NoError(b, db.CreateTable(ctx, name, sql.Schema{{Name: "i", Type: sql.Int64, Source: name, PrimaryKey: true}}))
+		inserter := db.Tables()[name].(*Table).Inserter(ctx)
+		for j := int64(0); j < 10000; j++ {
+			require.NoError(b, inserter.Insert(ctx, sql.NewRow(j)))
+		}
+		require.NoError(b, inserter.Close(ctx))
+	}
+
+	snapshot := db.Snapshot()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		require.NoError(b, db.Tables()["t0"].(*Table).Insert(ctx, sql.NewRow(int64(-1))))
+		b.StartTimer()
+		require.NoError(b, db.Restore(snapshot))
+	}
+}
+
+func TestSnapshotTransaction(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	db := NewDatabase("mydb")
+	require.NoError(db.CreateTable(ctx, "a", sql.Schema{{Name: "i", Type: sql.Int64, Source: "a", PrimaryKey: true}}))
+	a := db.Tables()["a"].(*Table)
+	require.NoError(a.Insert(ctx, sql.NewRow(int64(1))))
+
+	tx, err := db.StartSnapshotTransaction(ctx)
+	require.NoError(err)
+	require.NoError(a.Insert(ctx, sql.NewRow(int64(2))))
+	require.NoError(db.CreateTable(ctx, "b", sql.Schema{{Name: "i", Type: sql.Int64, Source: "b"}}))
+
+	require.Equal("mydb", tx.Name())
+	names, err := tx.GetTableNames(ctx)
+	require.NoError(err)
+	require.Equal([]string{"a"}, names)
+	tbl, ok, err := tx.GetTableInsensitive(ctx, "A")
+	require.NoError(err)
+	require.True(ok)
+	require.Equal([]sql.Row{{int64(1)}}, testFlatRows(t, tbl.(*Table)))
+	require.NoError(tx.Commit(ctx))
+
+	require.ElementsMatch([]sql.Row{{int64(1)}, {int64(2)}}, testFlatRows(t, a))
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// histogramBuckets is the number of buckets of the histograms built by Analyze, the default number of buckets of
+// MySQL histograms.
+const histogramBuckets = 100
+
+var _ sql.StatisticsTable = (*Table)(nil)
+var _ sql.AnalyzableTable = (*Table)(nil)
+
+// Statistics implements the sql.StatisticsTable interface. Statistics are counted on the current rows of the table
+// every time they're asked for. Values are distinct if they'd have different keys in an index, so equal values of
+// different go types are counted once. Histograms are the ones built by the last Analyze of the table.
+func (t *Table) Statistics(ctx *sql.Context) (*sql.TableStatistics, error) {
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+
+	exprs := t.statisticsExprs()
+	distinct := make([]map[string]struct{}, len(t.schema))
+	nulls := make([]uint64, len(t.schema))
+	for i := range t.schema {
+		distinct[i] = make(map[string]struct{})
+	}
+
+	stats := &sql.TableStatistics{Columns: make(map[string]sql.ColumnStatistics, len(t.schema))}
+	err := t.eachValue(ctx, exprs, func(row sql.Row) {
+		stats.RowCount++
+	}, func(i int, v interface{}) error {
+		if v == nil {
+			nulls[i]++
+			return nil
+		}
+
+		k, err := indexKey(exprs[i:i+1], sql.Row{v})
+		if err != nil {
+			return err
+		}
+		distinct[i][k] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, col := range t.schema {
+		name := strings.ToLower(col.Name)
+		stats.Columns[name] = sql.ColumnStatistics{
+			DistinctCount: uint64(len(distinct[i])),
+			NullCount:     nulls[i],
+			Histogram:     t.data.histograms[name],
+		}
+	}
+	return stats, nil
+}
+
+// Analyze implements the sql.AnalyzableTable interface. It builds the histograms of the columns of the table from its
+// current rows, which are kept until the table is analyzed again or its schema changes. Histograms aren't persisted.
+func (t *Table) Analyze(ctx *sql.Context) error {
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+
+	values := make([][]interface{}, len(t.schema))
+	err := t.eachValue(ctx, t.statisticsExprs(), nil, func(i int, v interface{}) error {
+		if v != nil {
+			values[i] = append(values[i], v)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	histograms := make(map[string]*sql.Histogram, len(t.data.histograms)+len(t.schema))
+	for name, h := range t.data.histograms {
+		histograms[name] = h
+	}
+	for i, col := range t.schema {
+		h, err := sql.NewHistogram(col.Type, values[i], histogramBuckets)
+		if err != nil {
+			return err
+		}
+		histograms[strings.ToLower(col.Name)] = h
+	}
+	t.data.histograms = histograms
+	return nil
+}
+
+// statisticsExprs returns the expressions of the values of the columns of the table in its stored rows, which have all
+// the columns of projected tables too.
+func (t *Table) statisticsExprs() []sql.Expression {
+	exprs := make([]sql.Expression, len(t.schema))
+	for i, col := range t.schema {
+		idx := i
+		if len(t.columns) > 0 {
+			idx = t.columns[i]
+		}
+		exprs[i] = expression.NewGetField(idx, col.Type, col.Name, col.Nullable)
+	}
+	return exprs
+}
+
+// eachValue calls the functions given with every current row of the table, which must be locked, and with the values of
+// the expressions given for it. The current version isn't marked as read, since the rows don't outlive the lock.
+func (t *Table) eachValue(ctx *sql.Context, exprs []sql.Expression, rowFn func(sql.Row), valueFn func(int, interface{}) error) error {
+	version := t.data.current
+	for _, key := range version.keys {
+		for _, row := range version.partitions[string(key)] {
+			if rowFn != nil {
+				rowFn(row)
+			}
+			for i, expr := range exprs {
+				v, err := expr.Eval(ctx, row)
+				if err != nil {
+					return err
+				}
+				if err := valueFn(i, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
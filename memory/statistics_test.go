@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestStatistics(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "S", Type: sql.Text, Source: "t", Nullable: true},
+	}, WithPartitions(2))
+	for i, s := range []interface{}{"a", "b", nil, "a", nil} {
+		require.NoError(table.Insert(ctx, sql.NewRow(int64(i), s)))
+	}
+
+	stats, err := table.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(&sql.TableStatistics{
+		RowCount: 5,
+		Columns: map[string]sql.ColumnStatistics{
+			"i": {DistinctCount: 5},
+			"s": {DistinctCount: 2, NullCount: 2},
+		},
+	}, stats)
+
+	c, ok := stats.Column("s")
+	require.True(ok)
+	require.Equal(uint64(2), c.DistinctCount)
+
+	// Analyzed tables have the histograms of their columns, until their schema changes
+	require.NoError(table.Analyze(ctx))
+	stats, err = table.Statistics(ctx)
+	require.NoError(err)
+	require.Equal(&sql.Histogram{Type: sql.Text, Buckets: []sql.HistogramBucket{
+		{LowerBound: "a", UpperBound: "a", RowCount: 2, DistinctCount: 1},
+		{LowerBound: "b", UpperBound: "b", RowCount: 1, DistinctCount: 1},
+	}}, stats.Columns["s"].Histogram)
+	require.Len(stats.Columns["i"].Histogram.Buckets, 5)
+
+	// Projected tables only have the statistics of their columns
+	stats, err = table.WithProjection([]string{"s"}).(*Table).Statistics(ctx)
+	require.NoError(err)
+	require.Equal(&sql.TableStatistics{
+		RowCount: 5,
+		Columns:  map[string]sql.ColumnStatistics{"s": {DistinctCount: 2, NullCount: 2, Histogram: stats.Columns["s"].Histogram}},
+	}, stats)
+	require.NotNil(stats.Columns["s"].Histogram)
+
+	require.NoError(table.AddColumn(ctx, &sql.Column{Name: "j", Type: sql.Int64, Source: "t", Nullable: true}, nil))
+	stats, err = table.Statistics(ctx)
+	require.NoError(err)
+	require.Nil(stats.Columns["s"].Histogram)
+}
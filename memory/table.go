@@ -2,11 +2,11 @@ package memory
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"fmt"
 	"io"
 	"sort"
-	"strconv"
 	"strings"
 
 	errors "gopkg.in/src-d/go-errors.v1"
@@ -21,19 +21,22 @@ type Table struct {
 	name             string
 	schema           sql.Schema
 	columns          []int
+	projection       []string
 	indexes          map[string]sql.Index
 	foreignKeys      []sql.ForeignKeyConstraint
+	checks           []sql.CheckConstraint
 	pkIndexesEnabled bool
+	partitionFunc    PartitionFunc
 
-	// Data storage
-	partitions map[string][]sql.Row
-	keys       [][]byte
+	// Data storage, shared by all the copies of the table
+	data *tableData
 
-	// Insert bookkeeping
-	insert int
+	// Indexed lookups, whose rows are fetched in the order of their positions for multi-range reads
+	lookup         sql.IndexLookup
+	multiRangeRead bool
 
-	// Indexed lookups
-	lookup sql.IndexLookup
+	// Persistence of the database of the table, if it's persistent
+	journal *journal
 }
 
 var _ sql.Table = (*Table)(nil)
@@ -45,78 +48,62 @@ var _ sql.DriverIndexableTable = (*Table)(nil)
 var _ sql.AlterableTable = (*Table)(nil)
 var _ sql.IndexAlterableTable = (*Table)(nil)
 var _ sql.IndexedTable = (*Table)(nil)
+var _ sql.MultiRangeReadTable = (*Table)(nil)
 var _ sql.ForeignKeyAlterableTable = (*Table)(nil)
 var _ sql.ForeignKeyTable = (*Table)(nil)
-
-// PushdownTable is an extension to Table that implements sql.FilteredTable and sql.ProjectedTable. This is mostly just
-// for demonstration and testing purposes -- these new interfaces do not significantly speed up query execution.
-// The implementation is kept separate since it affects the optimization of query plans by the analyzer, and most
-// integrators won't implement these two interfaces.
+var _ sql.CheckAlterableTable = (*Table)(nil)
+var _ sql.CheckTable = (*Table)(nil)
+var _ sql.Table2 = (*Table)(nil)
+var _ sql.ProjectedTable = (*Table)(nil)
+
+// PushdownTable is an extension to Table that also implements sql.FilteredTable, sql.OrderedTable and
+// sql.LimitedTable. This is mostly just for demonstration and testing purposes -- these interfaces do not significantly
+// speed up query execution. The implementation is kept separate since it affects the optimization of query plans by
+// the analyzer, and most integrators won't implement them.
 type PushdownTable struct {
 	Table
-	filters    []sql.Expression
-	projection []string
+	filters            []sql.Expression
+	filterCapabilities sql.FilterCapabilities
+	order              []sql.SortColumn
+	limit              int64
+	limited            bool
 }
 
 var _ sql.FilteredTable = (*PushdownTable)(nil)
+var _ sql.FilterCapableTable = (*PushdownTable)(nil)
+var _ sql.OrderedTable = (*PushdownTable)(nil)
+var _ sql.LimitedTable = (*PushdownTable)(nil)
 var _ sql.ProjectedTable = (*PushdownTable)(nil)
 
-// NewTable creates a new Table with the given name and schema.
-func NewTable(name string, schema sql.Schema) *Table {
-	return NewPartitionedTable(name, schema, 0)
+// NewTable creates a new Table with the given name, schema and options, such as its number of partitions.
+func NewTable(name string, schema sql.Schema, opts ...TableOption) *Table {
+	t := &Table{
+		name:   name,
+		schema: schema,
+		data:   newTableData(1),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.reindex()
+	return t
 }
 
-// NewPushdownTable creates a new PushdownTable with the given name and schema
-func NewPushdownTable(name string, schema sql.Schema) *PushdownTable {
-	return NewPartitionedPushdownTable(name, schema, 0)
+// NewPushdownTable creates a new PushdownTable with the given name, schema and options.
+func NewPushdownTable(name string, schema sql.Schema, opts ...TableOption) *PushdownTable {
+	return &PushdownTable{
+		Table: *NewTable(name, schema, opts...),
+	}
 }
 
 // NewPartitionedTable creates a new Table with the given name, schema and number of partitions.
 func NewPartitionedTable(name string, schema sql.Schema, numPartitions int) *Table {
-	var keys [][]byte
-	var partitions = map[string][]sql.Row{}
-
-	if numPartitions < 1 {
-		numPartitions = 1
-	}
-
-	for i := 0; i < numPartitions; i++ {
-		key := strconv.Itoa(i)
-		keys = append(keys, []byte(key))
-		partitions[key] = []sql.Row{}
-	}
-
-	return &Table{
-		name:       name,
-		schema:     schema,
-		partitions: partitions,
-		keys:       keys,
-	}
+	return NewTable(name, schema, WithPartitions(numPartitions))
 }
 
 // NewPartitionedPushdownTable creates a new PushdownTable with the given name, schema and number of partitions.
 func NewPartitionedPushdownTable(name string, schema sql.Schema, numPartitions int) *PushdownTable {
-	var keys [][]byte
-	var partitions = map[string][]sql.Row{}
-
-	if numPartitions < 1 {
-		numPartitions = 1
-	}
-
-	for i := 0; i < numPartitions; i++ {
-		key := strconv.Itoa(i)
-		keys = append(keys, []byte(key))
-		partitions[key] = []sql.Row{}
-	}
-
-	return &PushdownTable{
-		Table: Table{
-			name:       name,
-			schema:     schema,
-			partitions: partitions,
-			keys:       keys,
-		},
-	}
+	return NewPushdownTable(name, schema, WithPartitions(numPartitions))
 }
 
 // Name implements the sql.Table interface.
@@ -129,133 +116,398 @@ func (t *Table) Schema() sql.Schema {
 	return t.schema
 }
 
-// Partitions implements the sql.Table interface.
+// Partitions implements the sql.Table interface. The partitions returned have the rows of the table as they are when
+// it's called, regardless of the rows written while they're read.
 func (t *Table) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
+	version := t.data.snapshot()
+
 	var keys [][]byte
-	for _, k := range t.keys {
-		if rows, ok := t.partitions[string(k)]; ok && len(rows) > 0 {
+	for _, k := range version.keys {
+		if rows, ok := version.partitions[string(k)]; ok && len(rows) > 0 {
 			keys = append(keys, k)
 		}
 	}
-	return &partitionIter{keys: keys}, nil
+	return &partitionIter{keys: keys, data: t.data, version: version}, nil
 }
 
 // PartitionCount implements the sql.PartitionCounter interface.
 func (t *Table) PartitionCount(ctx *sql.Context) (int64, error) {
-	return int64(len(t.partitions)), nil
+	return int64(len(t.data.snapshot().keys)), nil
 }
 
 // PartitionRows implements the sql.PartitionRows interface.
 func (t *Table) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
-	rows, ok := t.partitions[string(partition.Key())]
-	if !ok {
-		return nil, fmt.Errorf(
-			"partition not found: %q", partition.Key(),
-		)
+	rows, err := t.partitionRows(partition)
+	if err != nil {
+		return nil, err
 	}
 
-	var values sql.IndexValueIter
-	if t.lookup != nil {
-		var err error
-		values, err = t.lookup.(sql.DriverIndexLookup).Values(partition)
-		if err != nil {
-			return nil, err
-		}
+	values, err := t.indexValues(ctx, partition)
+	if err != nil {
+		return nil, err
 	}
 
-	// The slice could be altered by other operations taking place during iteration (such as deletion or insertion), so
-	// make a copy of the values as they exist when execution begins.
-	rowsCopy := make([]sql.Row, len(rows))
-	copy(rowsCopy, rows)
-
 	return &tableIter{
-		rows:        rowsCopy,
+		ctx:         ctx,
+		schema:      t.schema,
+		rows:        rows,
+		columns:     t.columns,
 		indexValues: values,
 	}, nil
 }
 
-func (t *PushdownTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
-	rows, ok := t.partitions[string(partition.Key())]
+// indexValues returns the locations of the rows of the partition given in the index lookup of the table, if it has
+// one, sorted by their positions in the partition for a multi-range read.
+func (t *Table) indexValues(ctx *sql.Context, partition sql.Partition) (sql.IndexValueIter, error) {
+	if t.lookup == nil {
+		return nil, nil
+	}
+
+	values, err := t.lookup.(sql.DriverIndexLookup).Values(ctx, partition)
+	if err != nil || !t.multiRangeRead {
+		return values, err
+	}
+	return sql.SortIndexValues(ctx, values, func(location []byte) ([]byte, error) {
+		value, err := decodeIndexValue(location)
+		if err != nil {
+			return nil, err
+		}
+		key := make([]byte, 8)
+		binary.BigEndian.PutUint64(key, uint64(value.Pos))
+		return key, nil
+	})
+}
+
+// partitionRows returns the rows of the partition given, from the version of the partitions of the table it was
+// returned with, or from the current version if it wasn't returned by the Partitions method of the table. The rows
+// returned must not be changed.
+func (t *Table) partitionRows(p sql.Partition) ([]sql.Row, error) {
+	return t.partitionVersion(p).rows(p)
+}
+
+// partitionVersion returns the version of the partitions of the table the partition given was returned with, or the
+// current version if it wasn't returned by the Partitions method of the table.
+func (t *Table) partitionVersion(p sql.Partition) *partitionsVersion {
+	if mp, ok := p.(*partition); ok && mp.data == t.data && mp.version != nil {
+		return mp.version
+	}
+	return t.data.snapshot()
+}
+
+// rows returns the rows of the partition given in the version, which must not be changed.
+func (v *partitionsVersion) rows(p sql.Partition) ([]sql.Row, error) {
+	rows, ok := v.partitions[string(p.Key())]
 	if !ok {
 		return nil, fmt.Errorf(
-			"partition not found: %q", partition.Key(),
+			"partition not found: %q", p.Key(),
 		)
 	}
+	return rows, nil
+}
 
-	var values sql.IndexValueIter
-	if t.lookup != nil {
-		var err error
-		values, err = t.lookup.(sql.DriverIndexLookup).Values(partition)
+// orderedPartitionKey is the key of the only partition of an ordered PushdownTable, which has the rows of all of its
+// partitions.
+var orderedPartitionKey = []byte("ordered")
+
+// Partitions implements the sql.Table interface. The partitions of an ordered table are merged into one, so that its
+// rows can be returned sorted.
+func (t *PushdownTable) Partitions(ctx *sql.Context) (sql.PartitionIter, error) {
+	if len(t.order) > 0 {
+		return &partitionIter{keys: [][]byte{orderedPartitionKey}}, nil
+	}
+	return t.Table.Partitions(ctx)
+}
+
+// PartitionCount implements the sql.PartitionCounter interface.
+func (t *PushdownTable) PartitionCount(ctx *sql.Context) (int64, error) {
+	if len(t.order) > 0 {
+		return 1, nil
+	}
+	return t.Table.PartitionCount(ctx)
+}
+
+func (t *PushdownTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	if len(t.order) > 0 && bytes.Equal(partition.Key(), orderedPartitionKey) {
+		return t.orderedRows(ctx)
+	}
+
+	iter, err := t.partitionIter(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	iter.columns = t.columns
+	iter.limit, iter.limited = t.limit, t.limited
+	return iter, nil
+}
+
+// partitionIter returns an iterator over the stored rows of the partition given that match the filters of the table.
+func (t *PushdownTable) partitionIter(ctx *sql.Context, partition sql.Partition) (*tableIter, error) {
+	rows, err := t.partitionRows(partition)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := t.indexValues(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tableIter{
+		ctx:         ctx,
+		schema:      t.schema,
+		rows:        rows,
+		filters:     t.filters,
+		indexValues: values,
+	}, nil
+}
+
+// orderedRows returns an iterator over the rows of all the partitions of the table, sorted in the order of the table.
+func (t *PushdownTable) orderedRows(ctx *sql.Context) (sql.RowIter, error) {
+	var rows []sql.Row
+	version := t.data.snapshot()
+	for _, key := range version.keys {
+		iter, err := t.partitionIter(ctx, &partition{key: key, data: t.data, version: version})
 		if err != nil {
 			return nil, err
 		}
+
+		for {
+			row, err := iter.nextMatch()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				iter.Close()
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The stored rows have all the columns of the table, while the schema only has the projected ones
+	schemaIdxs := make([]int, len(t.order))
+	rowIdxs := make([]int, len(t.order))
+	for i, o := range t.order {
+		k := t.schema.IndexOf(o.Column, t.name)
+		if k == -1 {
+			return nil, errColumnNotFound.New(o.Column)
+		}
+
+		schemaIdxs[i], rowIdxs[i] = k, k
+		if len(t.columns) > 0 {
+			rowIdxs[i] = t.columns[k]
+		}
 	}
 
-	// The slice could be altered by other operations taking place during iteration (such as deletion or insertion), so
-	// make a copy of the values as they exist when execution begins.
-	rowsCopy := make([]sql.Row, len(rows))
-	copy(rowsCopy, rows)
+	var sortErr error
+	sort.SliceStable(rows, func(i, j int) bool {
+		for n, o := range t.order {
+			a, b := rows[i][rowIdxs[n]], rows[j][rowIdxs[n]]
+			if o.Descending {
+				a, b = b, a
+			}
+
+			if a == nil || b == nil {
+				if a == nil && b == nil {
+					continue
+				}
+				return (a == nil) != o.NullsLast
+			}
+
+			cmp, err := t.schema[schemaIdxs[n]].Type.Compare(a, b)
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	if sortErr != nil {
+		return nil, sortErr
+	}
 
 	return &tableIter{
-		rows:        rowsCopy,
-		columns:     t.columns,
-		filters:     t.filters,
-		indexValues: values,
+		ctx:     ctx,
+		schema:  t.schema,
+		rows:    rows,
+		columns: t.columns,
+		limit:   t.limit,
+		limited: t.limited,
 	}, nil
 }
 
+// PartitionRows2 implements the sql.Table2 interface.
+func (t *Table) PartitionRows2(ctx *sql.Context, partition sql.Partition) (sql.RowIter2, error) {
+	iter, err := t.PartitionRows(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+	return iter.(*tableIter), nil
+}
+
+// PartitionRows2 implements the sql.Table2 interface.
+func (t *PushdownTable) PartitionRows2(ctx *sql.Context, partition sql.Partition) (sql.RowIter2, error) {
+	iter, err := t.PartitionRows(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+	return iter.(*tableIter), nil
+}
+
 type partition struct {
 	key []byte
+	// data and version are the storage of the table of the partition and the version of its partitions the partition
+	// was returned with.
+	data    *tableData
+	version *partitionsVersion
 }
 
 func (p *partition) Key() []byte { return p.key }
 
 type partitionIter struct {
-	keys [][]byte
-	pos  int
+	keys    [][]byte
+	pos     int
+	data    *tableData
+	version *partitionsVersion
 }
 
-func (p *partitionIter) Next() (sql.Partition, error) {
+func (p *partitionIter) Next(*sql.Context) (sql.Partition, error) {
 	if p.pos >= len(p.keys) {
 		return nil, io.EOF
 	}
 
 	key := p.keys[p.pos]
 	p.pos++
-	return &partition{key}, nil
+	return &partition{key: key, data: p.data, version: p.version}, nil
 }
 
 func (p *partitionIter) Close() error { return nil }
 
 type tableIter struct {
+	ctx *sql.Context
+	// schema is the schema of the returned rows, after applying columns
+	schema  sql.Schema
 	columns []int
 	filters []sql.Expression
+	// limit is the number of rows returned if limited is true
+	limit    int64
+	limited  bool
+	returned int64
 
 	rows        []sql.Row
 	indexValues sql.IndexValueIter
 	pos         int
 }
 
-var _ sql.RowIter = (*tableIter)(nil)
+var _ sql.RowIter2 = (*tableIter)(nil)
+var _ sql.BatchRowIter = (*tableIter)(nil)
 
 func (i *tableIter) Next() (sql.Row, error) {
-	row, err := i.getRow()
+	row, err := i.nextMatch()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, f := range i.filters {
-		result, err := f.Eval(sql.NewEmptyContext(), row)
+	return projectOnRow(i.columns, row), nil
+}
+
+// NextBatch implements the sql.BatchRowIter interface.
+func (i *tableIter) NextBatch(rows []sql.Row) (int, error) {
+	for n := range rows {
+		row, err := i.nextMatch()
+		if err == io.EOF && n > 0 {
+			return n, nil
+		}
 		if err != nil {
-			return nil, err
+			return 0, err
 		}
-		result, _ = sql.ConvertToBool(result)
-		if result != true {
-			return i.Next()
+		rows[n] = projectOnRow(i.columns, row)
+	}
+	return len(rows), nil
+}
+
+// Next2 implements the sql.RowIter2 interface. The values of the row are encoded straight from the stored row,
+// without building a projected row first.
+func (i *tableIter) Next2(frame *sql.RowFrame) error {
+	row, err := i.nextMatch()
+	if err != nil {
+		return err
+	}
+
+	frame.Clear()
+	if len(i.columns) < 1 {
+		for j, v := range row {
+			if err := appendValue(frame, i.schema[j].Type, v); err != nil {
+				return err
+			}
 		}
+		return nil
 	}
 
-	return projectOnRow(i.columns, row), nil
+	for k, j := range i.columns {
+		if err := appendValue(frame, i.schema[k].Type, row[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendValue(frame *sql.RowFrame, typ sql.Type, v interface{}) error {
+	val, err := sql.EncodeValue(typ, v)
+	if err != nil {
+		return err
+	}
+	frame.Append(val)
+	return nil
+}
+
+// nextMatch returns the next stored row matching all the filters of the iterator.
+func (i *tableIter) nextMatch() (sql.Row, error) {
+	if i.limited {
+		if i.returned >= i.limit {
+			return nil, io.EOF
+		}
+		i.returned++
+	}
+
+	for {
+		// The rows that don't match the filters are skipped without returning, so the query may be interrupted
+		// while they're read
+		if err := i.ctx.Interrupted(); err != nil {
+			return nil, err
+		}
+
+		row, err := i.getRow()
+		if err != nil {
+			return nil, err
+		}
+
+		matches := true
+		for _, f := range i.filters {
+			result, err := f.Eval(i.ctx, row)
+			if err != nil {
+				return nil, err
+			}
+			result, _ = sql.ConvertToBool(result)
+			if result != true {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return row, nil
+		}
+	}
 }
 
 func (i *tableIter) Close() error {
@@ -294,7 +546,7 @@ func projectOnRow(columns []int, row sql.Row) sql.Row {
 }
 
 func (i *tableIter) getFromIndex() (sql.Row, error) {
-	data, err := i.indexValues.Next()
+	data, err := i.indexValues.Next(i.ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -334,6 +586,10 @@ func encodeIndexValue(value *indexValue) ([]byte, error) {
 
 type tableEditor struct {
 	table *Table
+	// changes are the row changes made by the editor, which are committed to the journal of the table when the editor
+	// is closed if the table is persistent. started is whether the editor began changing rows of a persistent table.
+	changes []rowChange
+	started bool
 }
 
 var _ sql.RowReplacer = (*tableEditor)(nil)
@@ -341,26 +597,51 @@ var _ sql.RowUpdater = (*tableEditor)(nil)
 var _ sql.RowInserter = (*tableEditor)(nil)
 var _ sql.RowDeleter = (*tableEditor)(nil)
 
-func (t tableEditor) Close(*sql.Context) error {
+func (t *tableEditor) Close(*sql.Context) error {
 	// TODO: it would be nice to apply all pending updates here at once, rather than directly in the Insert / Update
 	//  / Delete methods.
-	return nil
+	if !t.started {
+		return nil
+	}
+
+	changes := t.changes
+	t.changes, t.started = nil, false
+	return t.table.journal.commit(t.table, changes)
+}
+
+// begin is called before the editor changes a row. If the table is persistent, the journal is told that the editor
+// has uncommitted changes before the first one is made, so they're never included in a snapshot before they're
+// committed.
+func (t *tableEditor) begin() {
+	if t.table.journal == nil || t.started {
+		return
+	}
+
+	t.table.journal.editorChanged()
+	t.started = true
+}
+
+// record records a row change made by the editor, if the table is persistent.
+func (t *tableEditor) record(change rowChange) {
+	if t.started {
+		t.changes = append(t.changes, change)
+	}
 }
 
 func (t *Table) Inserter(*sql.Context) sql.RowInserter {
-	return &tableEditor{t}
+	return &tableEditor{table: t}
 }
 
 func (t *Table) Updater(*sql.Context) sql.RowUpdater {
-	return &tableEditor{t}
+	return &tableEditor{table: t}
 }
 
 func (t *Table) Replacer(*sql.Context) sql.RowReplacer {
-	return &tableEditor{t}
+	return &tableEditor{table: t}
 }
 
 func (t *Table) Deleter(*sql.Context) sql.RowDeleter {
-	return &tableEditor{t}
+	return &tableEditor{table: t}
 }
 
 // Convenience method to avoid having to create an inserter in test setup
@@ -374,21 +655,34 @@ func (t *Table) Insert(ctx *sql.Context, row sql.Row) error {
 
 // Insert a new row into the table.
 func (t *tableEditor) Insert(ctx *sql.Context, row sql.Row) error {
+	return t.insert(row, true)
+}
+
+// insert inserts a new row into the table, checking its unique keys first if checkUnique is true.
+func (t *tableEditor) insert(row sql.Row, checkUnique bool) error {
 	if err := checkRow(t.table.schema, row); err != nil {
 		return err
 	}
 
-	if err := t.checkUniquenessConstraints(row); err != nil {
-		return err
+	t.begin()
+	data := t.table.data
+	data.mu.Lock()
+	defer data.mu.Unlock()
+
+	version := data.writable()
+	if checkUnique {
+		if err := version.checkUnique(row, "", -1); err != nil {
+			return err
+		}
 	}
 
-	key := string(t.table.keys[t.table.insert])
-	t.table.insert++
-	if t.table.insert == len(t.table.keys) {
-		t.table.insert = 0
+	key, err := t.table.insertPartition(version, row)
+	if err != nil {
+		return err
 	}
 
-	t.table.partitions[key] = append(t.table.partitions[key], row)
+	version.insertRow(key, row)
+	t.record(rowChange{kind: rowInserted, row: row})
 	return nil
 }
 
@@ -398,8 +692,14 @@ func (t *tableEditor) Delete(ctx *sql.Context, row sql.Row) error {
 		return err
 	}
 
+	t.begin()
+	data := t.table.data
+	data.mu.Lock()
+	defer data.mu.Unlock()
+
+	version := data.writable()
 	matches := false
-	for partitionIndex, partition := range t.table.partitions {
+	for partitionIndex, partition := range version.partitions {
 		for partitionRowIndex, partitionRow := range partition {
 			matches = true
 
@@ -408,7 +708,7 @@ func (t *tableEditor) Delete(ctx *sql.Context, row sql.Row) error {
 			pkColIdxes := t.pkColumnIndexes()
 			if len(pkColIdxes) > 0 {
 				if columnsMatch(pkColIdxes, partitionRow, row) {
-					t.table.partitions[partitionIndex] = append(partition[:partitionRowIndex], partition[partitionRowIndex+1:]...)
+					version.deleteRow(partitionIndex, partitionRowIndex)
 					break
 				}
 			}
@@ -422,7 +722,7 @@ func (t *tableEditor) Delete(ctx *sql.Context, row sql.Row) error {
 			}
 
 			if matches {
-				t.table.partitions[partitionIndex] = append(partition[:partitionRowIndex], partition[partitionRowIndex+1:]...)
+				version.deleteRow(partitionIndex, partitionRowIndex)
 				break
 			}
 		}
@@ -435,6 +735,7 @@ func (t *tableEditor) Delete(ctx *sql.Context, row sql.Row) error {
 		return sql.ErrDeleteRowNotFound.New()
 	}
 
+	t.record(rowChange{kind: rowDeleted, row: row})
 	return nil
 }
 
@@ -446,14 +747,14 @@ func (t *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) e
 		return err
 	}
 
-	if t.pkColsDiffer(oldRow, newRow) {
-		if err := t.checkUniquenessConstraints(newRow); err != nil {
-			return err
-		}
-	}
+	t.begin()
+	data := t.table.data
+	data.mu.Lock()
+	defer data.mu.Unlock()
 
+	version := data.writable()
 	matches := false
-	for partitionIndex, partition := range t.table.partitions {
+	for partitionIndex, partition := range version.partitions {
 		for partitionRowIndex, partitionRow := range partition {
 			matches = true
 			for rIndex, val := range oldRow {
@@ -463,7 +764,22 @@ func (t *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) e
 				}
 			}
 			if matches {
-				t.table.partitions[partitionIndex][partitionRowIndex] = newRow
+				if err := version.checkUnique(newRow, partitionIndex, partitionRowIndex); err != nil {
+					return err
+				}
+
+				// Rows whose new values belong to another partition are moved to it
+				i, ok, err := t.table.partitionOf(newRow, len(version.keys))
+				if err != nil {
+					return err
+				}
+				if newPartition := string(version.keys[i]); ok && newPartition != partitionIndex {
+					version.deleteRow(partitionIndex, partitionRowIndex)
+					version.insertRow(newPartition, newRow)
+				} else {
+					version.updateRow(partitionIndex, partitionRowIndex, newRow)
+				}
+				t.record(rowChange{kind: rowUpdated, row: oldRow, newRow: newRow})
 				break
 			}
 		}
@@ -475,22 +791,6 @@ func (t *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) e
 	return nil
 }
 
-func (t *tableEditor) checkUniquenessConstraints(row sql.Row) error {
-	pkColIdxes := t.pkColumnIndexes()
-
-	if len(pkColIdxes) > 0 {
-		for _, partition := range t.table.partitions {
-			for _, partitionRow := range partition {
-				if columnsMatch(pkColIdxes, partitionRow, row) {
-					return sql.ErrUniqueKeyViolation.New(pkColIdxes)
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
 func (t *tableEditor) pkColumnIndexes() []int {
 	var pkColIdxes []int
 	for _, column := range t.table.schema {
@@ -502,11 +802,6 @@ func (t *tableEditor) pkColumnIndexes() []int {
 	return pkColIdxes
 }
 
-func (t *tableEditor) pkColsDiffer(row, row2 sql.Row) bool {
-	pkColIdxes := t.pkColumnIndexes()
-	return !columnsMatch(pkColIdxes, row, row2)
-}
-
 // Returns whether the values for the columns given match in the two rows provided
 func columnsMatch(colIndexes []int, row sql.Row, row2 sql.Row) bool {
 	for _, i := range colIndexes {
@@ -519,7 +814,11 @@ func columnsMatch(colIndexes []int, row sql.Row, row2 sql.Row) bool {
 
 func (t *Table) AddColumn(ctx *sql.Context, column *sql.Column, order *sql.ColumnOrder) error {
 	newColIdx := t.addColumnToSchema(ctx, column, order)
-	return t.insertValueInRows(ctx, newColIdx, column.Default)
+	if err := t.insertValueInRows(ctx, newColIdx, column.Default); err != nil {
+		return err
+	}
+	t.updateIndexColumns("", "")
+	return t.persist()
 }
 
 // addColumnToSchema adds the given column to the schema and returns the new index
@@ -562,7 +861,11 @@ func (t *Table) addColumnToSchema(ctx *sql.Context, newCol *sql.Column, order *s
 }
 
 func (t *Table) insertValueInRows(ctx *sql.Context, idx int, colDefault *sql.ColumnDefaultValue) error {
-	for k, p := range t.partitions {
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+
+	partitions := make(map[string][]sql.Row, len(t.data.current.partitions))
+	for k, p := range t.data.current.partitions {
 		newP := make([]sql.Row, len(p))
 		for i, row := range p {
 			var newRow sql.Row
@@ -576,14 +879,19 @@ func (t *Table) insertValueInRows(ctx *sql.Context, idx int, colDefault *sql.Col
 			}
 			newP[i] = newRow
 		}
-		t.partitions[k] = newP
+		partitions[k] = newP
 	}
+
+	t.data.replace(partitions)
 	return nil
 }
 
 func (t *Table) DropColumn(ctx *sql.Context, columnName string) error {
 	droppedCol := t.dropColumnFromSchema(ctx, columnName)
-	for k, p := range t.partitions {
+
+	t.data.mu.Lock()
+	partitions := make(map[string][]sql.Row, len(t.data.current.partitions))
+	for k, p := range t.data.current.partitions {
 		newP := make([]sql.Row, len(p))
 		for i, row := range p {
 			var newRow sql.Row
@@ -591,9 +899,13 @@ func (t *Table) DropColumn(ctx *sql.Context, columnName string) error {
 			newRow = append(newRow, row[droppedCol+1:]...)
 			newP[i] = newRow
 		}
-		t.partitions[k] = newP
+		partitions[k] = newP
 	}
-	return nil
+	t.data.replace(partitions)
+	t.data.mu.Unlock()
+
+	t.updateIndexColumns(columnName, "")
+	return t.persist()
 }
 
 // dropColumnFromSchema drops the given column name from the schema and returns its old index.
@@ -641,7 +953,9 @@ func (t *Table) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Co
 		}
 	}
 
-	for k, p := range t.partitions {
+	t.data.mu.Lock()
+	partitions := make(map[string][]sql.Row, len(t.data.current.partitions))
+	for k, p := range t.data.current.partitions {
 		newP := make([]sql.Row, len(p))
 		for i, row := range p {
 			var oldRowWithoutVal sql.Row
@@ -649,6 +963,7 @@ func (t *Table) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Co
 			oldRowWithoutVal = append(oldRowWithoutVal, row[oldIdx+1:]...)
 			newVal, err := column.Type.Convert(row[oldIdx])
 			if err != nil {
+				t.data.mu.Unlock()
 				return err
 			}
 			var newRow sql.Row
@@ -657,12 +972,15 @@ func (t *Table) ModifyColumn(ctx *sql.Context, columnName string, column *sql.Co
 			newRow = append(newRow, oldRowWithoutVal[newIdx:]...)
 			newP[i] = newRow
 		}
-		t.partitions[k] = newP
+		partitions[k] = newP
 	}
+	t.data.replace(partitions)
+	t.data.mu.Unlock()
 
 	_ = t.dropColumnFromSchema(ctx, columnName)
 	t.addColumnToSchema(ctx, column, order)
-	return nil
+	t.updateIndexColumns(columnName, column.Name)
+	return t.persist()
 }
 
 func checkRow(schema sql.Schema, row sql.Row) error {
@@ -722,6 +1040,14 @@ func (t *PushdownTable) String() string {
 		kind += "Indexed "
 	}
 
+	if len(t.order) > 0 {
+		kind += "Ordered "
+	}
+
+	if t.limited {
+		kind += "Limited "
+	}
+
 	if kind != "" {
 		kind = ": " + kind
 	}
@@ -832,6 +1158,21 @@ func (t *PushdownTable) WithFilters(filters []sql.Expression) sql.Table {
 	return &nt
 }
 
+// WithProjection implements the sql.ProjectedTable interface. The rows of the table returned only have the columns
+// given, in that order.
+func (t *Table) WithProjection(colNames []string) sql.Table {
+	if len(colNames) == 0 {
+		return t
+	}
+
+	nt := *t
+	if err := nt.project(colNames); err != nil {
+		panic(err)
+	}
+
+	return &nt
+}
+
 // WithProjection implements the sql.ProjectedTable interface.
 func (t *PushdownTable) WithProjection(colNames []string) sql.Table {
 	if len(colNames) == 0 {
@@ -839,18 +1180,25 @@ func (t *PushdownTable) WithProjection(colNames []string) sql.Table {
 	}
 
 	nt := *t
-	columns, schema, err := nt.newColumnIndexesAndSchema(colNames)
-	if err != nil {
+	if err := nt.project(colNames); err != nil {
 		panic(err)
 	}
 
-	nt.columns = columns
-	nt.projection = colNames
-	nt.schema = schema
-
 	return &nt
 }
 
+func (t *Table) project(colNames []string) error {
+	columns, schema, err := t.newColumnIndexesAndSchema(colNames)
+	if err != nil {
+		return err
+	}
+
+	t.columns = columns
+	t.projection = colNames
+	t.schema = schema
+	return nil
+}
+
 func (t *Table) newColumnIndexesAndSchema(colNames []string) ([]int, sql.Schema, error) {
 	var columns []int
 	var schema []*sql.Column
@@ -887,26 +1235,14 @@ func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 	indexes := make([]sql.Index, 0)
 
 	if t.pkIndexesEnabled {
-		var pkCols []*sql.Column
-		for _, col := range t.schema {
-			if col.PrimaryKey {
-				pkCols = append(pkCols, col)
-			}
-		}
-
-		if len(pkCols) > 0 {
-			exprs := make([]sql.Expression, len(pkCols))
-			for i, column := range pkCols {
-				idx, field := t.getField(column.Name)
-				exprs[i] = expression.NewGetFieldWithTable(idx, field.Type, t.name, field.Name, field.Nullable)
-			}
+		if exprs := t.primaryKeyExpressions(); len(exprs) > 0 {
 			indexes = append(indexes, &MergeableIndex{
 				DB:         "",
 				DriverName: "",
 				Tbl:        t,
 				TableName:  t.name,
 				Exprs:      exprs,
-				Name:       "PRIMARY",
+				Name:       primaryKeyIndexName,
 				Unique:     true,
 			})
 		}
@@ -925,12 +1261,84 @@ func (t *Table) GetIndexes(ctx *sql.Context) ([]sql.Index, error) {
 	return append(indexes, nonPrimaryIndexes...), nil
 }
 
+// primaryKeyExpressions returns the expressions of the index of the primary key of the table, which are empty if the
+// table has no primary key.
+func (t *Table) primaryKeyExpressions() []sql.Expression {
+	var exprs []sql.Expression
+	for i, col := range t.schema {
+		if col.PrimaryKey {
+			idx := i
+			if len(t.columns) > 0 {
+				idx = t.columns[i]
+			}
+			exprs = append(exprs, expression.NewGetFieldWithTable(idx, col.Type, t.name, col.Name, col.Nullable))
+		}
+	}
+	return exprs
+}
+
+// reindex rebuilds the entries of the indexes of the table, including the index of its primary key, for the current
+// version of its partitions. It must be called whenever the indexes or the schema of the table change.
+func (t *Table) reindex() {
+	indexes := make(map[string]indexDefinition, len(t.indexes)+1)
+	if exprs := t.primaryKeyExpressions(); len(exprs) > 0 {
+		indexes[primaryKeyIndexName] = indexDefinition{exprs: exprs, unique: true}
+	}
+	for name, index := range t.indexes {
+		if expressionsIndex, ok := index.(ExpressionsIndex); ok {
+			indexes[name] = indexDefinition{exprs: expressionsIndex.ColumnExpressions(), unique: index.IsUnique()}
+		}
+	}
+
+	t.data.mu.Lock()
+	defer t.data.mu.Unlock()
+	t.data.writable().reindex(indexes)
+}
+
+// updateIndexColumns updates the indexes of the table after a change to its schema, in which the column named from was
+// renamed to the name to, or dropped if to is empty. Indexes left without columns are dropped.
+func (t *Table) updateIndexColumns(from, to string) {
+	for name, index := range t.indexes {
+		index, ok := index.(*UnmergeableIndex)
+		if !ok {
+			continue
+		}
+
+		var columns []sql.IndexColumn
+		for _, expr := range index.Exprs {
+			column := expr.(*expression.GetField).Name()
+			if column == from {
+				column = to
+			}
+			if column != "" {
+				columns = append(columns, sql.IndexColumn{Name: column})
+			}
+		}
+
+		if len(columns) == 0 {
+			delete(t.indexes, name)
+			continue
+		}
+
+		newIndex := *index
+		newIndex.Exprs = nil
+		for _, column := range columns {
+			if idx, field := t.getField(column.Name); field != nil {
+				newIndex.Exprs = append(newIndex.Exprs, expression.NewGetFieldWithTable(idx, field.Type, t.name, field.Name, field.Nullable))
+			}
+		}
+		t.indexes[name] = &newIndex
+	}
+	t.reindex()
+}
+
 // GetForeignKeys implements sql.ForeignKeyTable
 func (t *Table) GetForeignKeys(_ *sql.Context) ([]sql.ForeignKeyConstraint, error) {
 	return t.foreignKeys, nil
 }
 
-// CreateForeignKey implements sql.ForeignKeyAlterableTable. Foreign keys are not enforced on update / delete.
+// CreateForeignKey implements sql.ForeignKeyAlterableTable. Foreign keys are enforced by the engine on the rows written to
+// the table and to the tables they reference.
 func (t *Table) CreateForeignKey(_ *sql.Context, fkName string, columns []string, referencedTable string, referencedColumns []string, onUpdate, onDelete sql.ForeignKeyReferenceOption) error {
 	for _, key := range t.foreignKeys {
 		if key.Name == fkName {
@@ -947,7 +1355,7 @@ func (t *Table) CreateForeignKey(_ *sql.Context, fkName string, columns []string
 		OnDelete:          onDelete,
 	})
 
-	return nil
+	return t.persist()
 }
 
 // DropForeignKey implements sql.ForeignKeyAlterableTable.
@@ -955,7 +1363,36 @@ func (t *Table) DropForeignKey(ctx *sql.Context, fkName string) error {
 	for i, key := range t.foreignKeys {
 		if key.Name == fkName {
 			t.foreignKeys = append(t.foreignKeys[:i], t.foreignKeys[i+1:]...)
-			return nil
+			return t.persist()
+		}
+	}
+	return nil
+}
+
+// GetChecks implements sql.CheckTable
+func (t *Table) GetChecks(_ *sql.Context) ([]sql.CheckConstraint, error) {
+	return t.checks, nil
+}
+
+// CreateCheck implements sql.CheckAlterableTable. Check constraints are enforced by the engine on the rows written to
+// the table.
+func (t *Table) CreateCheck(_ *sql.Context, check sql.CheckConstraint) error {
+	for _, c := range t.checks {
+		if strings.EqualFold(c.Name, check.Name) {
+			return sql.ErrCheckConstraintDuplicateName.New(check.Name)
+		}
+	}
+
+	t.checks = append(t.checks, check)
+	return t.persist()
+}
+
+// DropCheck implements sql.CheckAlterableTable.
+func (t *Table) DropCheck(_ *sql.Context, name string) error {
+	for i, c := range t.checks {
+		if strings.EqualFold(c.Name, name) {
+			t.checks = append(t.checks[:i], t.checks[i+1:]...)
+			return t.persist()
 		}
 	}
 	return nil
@@ -1014,8 +1451,18 @@ func (t *Table) CreateIndex(ctx *sql.Context, indexName string, using sql.IndexU
 		return err
 	}
 
+	// Unique indexes can't be created if the table already has rows with the same key
+	if index.IsUnique() {
+		exprs := index.(ExpressionsIndex).ColumnExpressions()
+		version := t.data.snapshot()
+		if row, ok := version.duplicateEntry(newIndexEntries(indexDefinition{exprs: exprs}, version)); ok {
+			return sql.ErrUniqueKeyViolation.New(indexEntry(exprs, row), indexName)
+		}
+	}
+
 	t.indexes[indexName] = index
-	return nil
+	t.reindex()
+	return t.persist()
 }
 
 // DropIndex implements sql.IndexAlterableTable
@@ -1025,7 +1472,8 @@ func (t *Table) DropIndex(ctx *sql.Context, indexName string) error {
 			delete(t.indexes, name)
 		}
 	}
-	return nil
+	t.reindex()
+	return t.persist()
 }
 
 // RenameIndex implements sql.IndexAlterableTable
@@ -1036,7 +1484,8 @@ func (t *Table) RenameIndex(ctx *sql.Context, fromIndexName string, toIndexName
 			t.indexes[toIndexName] = index
 		}
 	}
-	return nil
+	t.reindex()
+	return t.persist()
 }
 
 // WithIndexLookup implements the sql.IndexAddressableTable interface.
@@ -1047,6 +1496,7 @@ func (t *Table) WithIndexLookup(lookup sql.IndexLookup) sql.Table {
 
 	nt := *t
 	nt.lookup = lookup
+	nt.multiRangeRead = false
 
 	return &nt
 }
@@ -1059,6 +1509,33 @@ func (t *PushdownTable) WithIndexLookup(lookup sql.IndexLookup) sql.Table {
 
 	nt := *t
 	nt.lookup = lookup
+	nt.multiRangeRead = false
+
+	return &nt
+}
+
+// WithMultiRangeRead implements the sql.MultiRangeReadTable interface.
+func (t *Table) WithMultiRangeRead(lookup sql.IndexLookup) sql.Table {
+	if lookup == nil {
+		return t
+	}
+
+	nt := *t
+	nt.lookup = lookup
+	nt.multiRangeRead = true
+
+	return &nt
+}
+
+// WithMultiRangeRead implements the sql.MultiRangeReadTable interface.
+func (t *PushdownTable) WithMultiRangeRead(lookup sql.IndexLookup) sql.Table {
+	if lookup == nil {
+		return t
+	}
+
+	nt := *t
+	nt.lookup = lookup
+	nt.multiRangeRead = true
 
 	return &nt
 }
@@ -1082,12 +1559,11 @@ func (t *Table) IndexKeyValues(
 		table:   t,
 		iter:    iter,
 		columns: columns,
-		ctx:     ctx,
 	}, nil
 }
 
 // Projection implements the sql.ProjectedTable interface.
-func (t *PushdownTable) Projection() []string {
+func (t *Table) Projection() []string {
 	return t.projection
 }
 
@@ -1096,20 +1572,70 @@ func (t *PushdownTable) Filters() []sql.Expression {
 	return t.filters
 }
 
+// CanOrder implements the sql.OrderedTable interface. Rows can be sorted on any of the columns of the table.
+func (t *PushdownTable) CanOrder(order []sql.SortColumn) bool {
+	for _, o := range order {
+		if !t.schema.Contains(o.Column, t.name) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithOrder implements the sql.OrderedTable interface.
+func (t *PushdownTable) WithOrder(order []sql.SortColumn) sql.Table {
+	if len(order) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.order = order
+	return &nt
+}
+
+// Order implements the sql.OrderedTable interface.
+func (t *PushdownTable) Order() []sql.SortColumn {
+	return t.order
+}
+
+// WithLimit implements the sql.LimitedTable interface. Unless the table is ordered, each of its partitions returns up
+// to the limit given.
+func (t *PushdownTable) WithLimit(limit int64) sql.Table {
+	nt := *t
+	nt.limit = limit
+	nt.limited = true
+	return &nt
+}
+
+// Limit implements the sql.LimitedTable interface.
+func (t *PushdownTable) Limit() (int64, bool) {
+	return t.limit, t.limited
+}
+
+// FilterCapabilities implements the sql.FilterCapableTable interface.
+func (t *PushdownTable) FilterCapabilities() sql.FilterCapabilities {
+	return t.filterCapabilities
+}
+
+// SetFilterCapabilities sets the filters this table reports it can evaluate, to mimic backends that only support
+// some of them. By default, any filter is supported.
+func (t *PushdownTable) SetFilterCapabilities(capabilities sql.FilterCapabilities) {
+	t.filterCapabilities = capabilities
+}
+
 type partitionIndexKeyValueIter struct {
 	table   *Table
 	iter    sql.PartitionIter
 	columns []int
-	ctx     *sql.Context
 }
 
-func (i *partitionIndexKeyValueIter) Next() (sql.Partition, sql.IndexKeyValueIter, error) {
-	p, err := i.iter.Next()
+func (i *partitionIndexKeyValueIter) Next(ctx *sql.Context) (sql.Partition, sql.IndexKeyValueIter, error) {
+	p, err := i.iter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	iter, err := i.table.PartitionRows(i.ctx, p)
+	iter, err := i.table.PartitionRows(ctx, p)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1134,7 +1660,7 @@ type indexKeyValueIter struct {
 	pos     int
 }
 
-func (i *indexKeyValueIter) Next() ([]interface{}, []byte, error) {
+func (i *indexKeyValueIter) Next(*sql.Context) ([]interface{}, []byte, error) {
 	row, err := i.iter.Next()
 	if err != nil {
 		return nil, nil, err
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// tableData stores the rows of a table, and is shared by all the copies of the table, such as the projected and
+// indexed copies returned by WithProjection and WithIndexLookup.
+//
+// Rows are stored in versions of the partitions of the table. Scans read the version that was current when they
+// started, which is never changed once a reader took it: writers copy the partitions they change to a new version
+// instead, so scans see a stable snapshot of the whole table while writes proceed. A version that wasn't read yet is
+// changed in place, so a statement that writes many rows copies each partition at most once. Writes are serialized,
+// and every write is visible to the scans that start after it.
+type tableData struct {
+	mu      sync.Mutex
+	current *partitionsVersion
+	// insert is the index of the partition the next inserted row is added to.
+	insert int
+	// histograms are the histograms of the columns built by the last analysis of the table, keyed by the lower case
+	// names of the columns.
+	histograms map[string]*sql.Histogram
+}
+
+// partitionsVersion is a version of the partitions of a table.
+type partitionsVersion struct {
+	keys       [][]byte
+	partitions map[string][]sql.Row
+	// owned are the partitions whose rows were copied for this version, which can be changed in place until the
+	// version is read.
+	owned map[string]bool
+	// indexes are the entries of the indexes of the table for this version, keyed by the name of the index. The entries
+	// of a partition are copied along with its rows.
+	indexes map[string]*indexEntries
+	// read is whether the version was taken by a reader, after which it's never changed.
+	read bool
+}
+
+// newTableData returns the storage of a table with the given number of empty partitions.
+func newTableData(numPartitions int) *tableData {
+	if numPartitions < 1 {
+		numPartitions = 1
+	}
+
+	version := &partitionsVersion{partitions: map[string][]sql.Row{}, owned: map[string]bool{}}
+	for i := 0; i < numPartitions; i++ {
+		key := strconv.Itoa(i)
+		version.keys = append(version.keys, []byte(key))
+		version.partitions[key] = []sql.Row{}
+	}
+
+	return &tableData{current: version}
+}
+
+// snapshot returns the current version of the partitions for a reader. The version is never changed afterwards.
+func (d *tableData) snapshot() *partitionsVersion {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.current.read = true
+	return d.current
+}
+
+// writable returns the current version of the partitions for a writer, which must hold the lock of the data. If the
+// current version was read, it's replaced by a new version with the same partitions, which are copied when they're
+// changed.
+func (d *tableData) writable() *partitionsVersion {
+	if d.current.read {
+		partitions := make(map[string][]sql.Row, len(d.current.partitions))
+		for key, rows := range d.current.partitions {
+			partitions[key] = rows
+		}
+
+		indexes := make(map[string]*indexEntries, len(d.current.indexes))
+		for name, entries := range d.current.indexes {
+			indexes[name] = entries.copy()
+		}
+
+		d.current = &partitionsVersion{keys: d.current.keys, partitions: partitions, owned: map[string]bool{}, indexes: indexes}
+	}
+	return d.current
+}
+
+// replace replaces the rows of all the partitions with the ones given, for changes to the schema of the table. The
+// writer must hold the lock of the data. The new version has no index entries until the indexes of the table are
+// rebuilt for the new schema, and the histograms of the old schema are dropped.
+func (d *tableData) replace(partitions map[string][]sql.Row) {
+	d.histograms = nil
+
+	owned := make(map[string]bool, len(partitions))
+	for key := range partitions {
+		owned[key] = true
+	}
+
+	d.current = &partitionsVersion{keys: d.current.keys, partitions: partitions, owned: owned}
+}
+
+// writablePartition returns the rows of the partition with the key given of a writable version, which can be changed in
+// place.
+func (v *partitionsVersion) writablePartition(key string) []sql.Row {
+	if !v.owned[key] {
+		v.partitions[key] = append([]sql.Row(nil), v.partitions[key]...)
+		for _, entries := range v.indexes {
+			entries.copyPartition(key)
+		}
+		v.owned[key] = true
+	}
+	return v.partitions[key]
+}
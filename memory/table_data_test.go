@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestTableSnapshotScans(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}, 2)
+	for i := int64(1); i <= 4; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "a")))
+	}
+
+	// Scans see the rows as they were when they started, even if rows are written while they're read
+	partitions, err := table.Partitions(ctx)
+	require.NoError(err)
+
+	editor := table.Updater(ctx).(*tableEditor)
+	require.NoError(editor.Update(ctx, sql.NewRow(int64(1), "a"), sql.NewRow(int64(1), "b")))
+	require.NoError(editor.Delete(ctx, sql.NewRow(int64(2), "a")))
+	require.NoError(editor.Insert(ctx, sql.NewRow(int64(5), "a")))
+	require.NoError(editor.Close(ctx))
+
+	var rows []sql.Row
+	for {
+		p, err := partitions.Next(ctx)
+		if err != nil {
+			break
+		}
+		iter, err := table.PartitionRows(ctx, p)
+		require.NoError(err)
+		partitionRows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		rows = append(rows, partitionRows...)
+	}
+	require.ElementsMatch([]sql.Row{{int64(1), "a"}, {int64(2), "a"}, {int64(3), "a"}, {int64(4), "a"}}, rows)
+
+	// Scans that start after rows are written see them
+	require.ElementsMatch([]sql.Row{{int64(1), "b"}, {int64(3), "a"}, {int64(4), "a"}, {int64(5), "a"}}, testFlatRows(t, table))
+
+	// Copies of the table share its rows
+	projected := table.WithProjection([]string{"s", "i"})
+	require.NoError(table.Insert(ctx, sql.NewRow(int64(6), "c")))
+	require.Len(testFlatRows(t, projected), 5)
+}
+
+func TestTableCopyOnWrite(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t"}})
+	inserter := table.Inserter(ctx)
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(1))))
+	version := table.data.current
+
+	// Versions that weren't read are changed in place
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(2))))
+	require.True(version == table.data.current)
+
+	snapshot := table.data.snapshot()
+	require.NoError(inserter.Insert(ctx, sql.NewRow(int64(3))))
+	require.NoError(inserter.Close(ctx))
+	require.False(snapshot == table.data.current)
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, snapshot.partitions["0"])
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}, {int64(3)}}, table.data.current.partitions["0"])
+}
+
+func TestTableConcurrentAccess(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	const numRows = 50
+	table := NewPartitionedTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "v", Type: sql.Int64, Source: "t"},
+	}, 4)
+	for i := int64(0); i < numRows; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, int64(0))))
+	}
+
+	// Every writer updates its own rows, while readers check that every scan sees all the rows once
+	var wg sync.WaitGroup
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			values := make(map[int64]int64)
+			for n := 0; n < 100; n++ {
+				i := int64(w*numRows/5 + n%(numRows/5))
+				editor := table.Updater(ctx)
+				assert.NoError(t, editor.Update(ctx, sql.NewRow(i, values[i]), sql.NewRow(i, values[i]+1)))
+				assert.NoError(t, editor.Close(ctx))
+				values[i]++
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 50; n++ {
+				var ids []int
+				for _, row := range testFlatRows(t, table) {
+					ids = append(ids, int(row[0].(int64)))
+				}
+				sort.Ints(ids)
+				if !assert.Len(t, ids, numRows) {
+					return
+				}
+				for i, id := range ids {
+					assert.Equal(t, i, id)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var sum int64
+	for _, row := range testFlatRows(t, table) {
+		sum += row[1].(int64)
+	}
+	require.Equal(int64(500), sum)
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var _ sql.CheckableTable = (*Table)(nil)
+var _ sql.OptimizableTable = (*Table)(nil)
+
+// Check implements the sql.CheckableTable interface. It checks that the entries of the indexes of the table are the
+// entries of its rows, and that its rows are in the partitions their partition function chooses for them.
+func (t *Table) Check(ctx *sql.Context) ([]string, error) {
+	version := t.data.snapshot()
+
+	var problems []string
+	var names []string
+	for name := range version.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entries := version.indexes[name]
+		// Broken entries aren't used by lookups, so they can't give wrong results
+		if entries.broken {
+			continue
+		}
+
+		expected := newIndexEntries(indexDefinition{exprs: entries.exprs, unique: entries.unique}, version)
+		for key := range version.partitions {
+			if !sameIndexEntries(expected.partitions[key], entries.partitions[key]) {
+				problems = append(problems, fmt.Sprintf("Index '%s' of partition %s has wrong entries", name, key))
+			}
+		}
+	}
+
+	for i, key := range version.keys {
+		for _, row := range version.partitions[string(key)] {
+			j, ok, err := t.partitionOf(row, len(version.keys))
+			if err != nil {
+				return nil, err
+			}
+			if ok && i != j {
+				problems = append(problems, fmt.Sprintf("Row %v is in partition %s instead of partition %s", row, key, version.keys[j]))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// sameIndexEntries returns whether the entries of the indexes of a partition given are the same.
+func sameIndexEntries(a, b map[string][]int) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Optimize implements the sql.OptimizableTable interface. It rebuilds the entries of the indexes of the table, so
+// lookups of indexes whose entries are broken use them again.
+func (t *Table) Optimize(ctx *sql.Context) error {
+	t.reindex()
+	return nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestCheckAndOptimize(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	}, WithPartitions(2))
+	require.NoError(table.CreateIndex(ctx, "idx_s", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "s"}}, ""))
+	for i := int64(0); i < 10; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "a")))
+	}
+
+	problems, err := table.Check(ctx)
+	require.NoError(err)
+	require.Empty(problems)
+
+	// Entries that don't match the rows are reported, and rebuilt by Optimize
+	version := table.data.snapshot()
+	key := string(version.keys[0])
+	version.indexes["idx_s"].partitions[key] = map[string][]int{}
+	problems, err = table.Check(ctx)
+	require.NoError(err)
+	require.Equal([]string{"Index 'idx_s' of partition " + key + " has wrong entries"}, problems)
+
+	version.indexes["idx_s"].broken = true
+	problems, err = table.Check(ctx)
+	require.NoError(err)
+	require.Empty(problems)
+
+	require.NoError(table.Optimize(ctx))
+	require.False(table.data.snapshot().indexes["idx_s"].broken)
+	requireIndexEntriesConsistent(t, table)
+
+	// Rows in the wrong partition are reported too
+	version = table.data.snapshot()
+	row := version.partitions[key][0]
+	other := string(version.keys[1])
+	version.partitions[other] = append(version.partitions[other], row)
+	problems, err = table.Check(ctx)
+	require.NoError(err)
+	require.Contains(problems, fmt.Sprintf("Row %v is in partition %s instead of partition %s", row, other, key))
+}
@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"testing"
@@ -56,7 +57,7 @@ func (i dummyLookup) String() string {
 	panic("index")
 }
 
-func (i *dummyLookup) Values(partition sql.Partition) (sql.IndexValueIter, error) {
+func (i *dummyLookup) Values(ctx *sql.Context, partition sql.Partition) (sql.IndexValueIter, error) {
 	key := string(partition.Key())
 	values, ok := i.values[key]
 	if !ok {
@@ -73,7 +74,7 @@ type dummyLookupIter struct {
 
 var _ sql.IndexValueIter = (*dummyLookupIter)(nil)
 
-func (i *dummyLookupIter) Next() ([]byte, error) {
+func (i *dummyLookupIter) Next(*sql.Context) ([]byte, error) {
 	if i.pos >= len(i.values) {
 		return nil, io.EOF
 	}
@@ -198,7 +199,7 @@ func TestTable(t *testing.T) {
 
 			for i := 0; i < test.numPartitions; i++ {
 				var p sql.Partition
-				p, err = pIter.Next()
+				p, err = pIter.Next(sql.NewEmptyContext())
 				require.NoError(err)
 
 				var iter sql.RowIter
@@ -209,7 +210,7 @@ func TestTable(t *testing.T) {
 				rows, err = sql.RowIterToRows(iter)
 				require.NoError(err)
 
-				expected := table.partitions[string(p.Key())]
+				expected := table.data.snapshot().partitions[string(p.Key())]
 				require.Len(rows, len(expected))
 
 				for i, row := range rows {
@@ -217,7 +218,7 @@ func TestTable(t *testing.T) {
 				}
 			}
 
-			_, err = pIter.Next()
+			_, err = pIter.Next(sql.NewEmptyContext())
 			require.EqualError(err, io.EOF.Error())
 
 		})
@@ -246,23 +247,57 @@ func TestFiltered(t *testing.T) {
 	}
 }
 
+func TestFilteredInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	table := NewPushdownTable("t", sql.Schema{{Name: "a", Type: sql.Int64, Source: "t"}})
+	for i := 0; i < 10; i++ {
+		require.NoError(table.Insert(sql.NewEmptyContext(), sql.NewRow(int64(i))))
+	}
+	// No row matches, so all of them are read by a single call to Next
+	filtered := table.WithFilters([]sql.Expression{
+		expression.NewLessThan(expression.NewGetFieldWithTable(0, sql.Int64, "t", "a", false), expression.NewLiteral(int64(0), sql.Int64)),
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sctx := sql.NewContext(ctx)
+	partitions, err := filtered.Partitions(sctx)
+	require.NoError(err)
+	p, err := partitions.Next(sctx)
+	require.NoError(err)
+	iter, err := filtered.PartitionRows(sctx, p)
+	require.NoError(err)
+
+	cancel()
+	_, err = iter.Next()
+	require.Equal(context.Canceled, err)
+	require.NoError(iter.Close())
+}
+
 func TestProjected(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var require = require.New(t)
 
-			table := NewPartitionedPushdownTable(test.name, test.schema, test.numPartitions)
-			for _, row := range test.rows {
-				require.NoError(table.Insert(sql.NewEmptyContext(), row))
+			tables := []sql.ProjectedTable{
+				NewPartitionedTable(test.name, test.schema, test.numPartitions),
+				NewPartitionedPushdownTable(test.name, test.schema, test.numPartitions),
 			}
 
-			projected := table.WithProjection(test.columns)
-			require.ElementsMatch(projected.Schema(), test.expectedSchema)
+			for _, table := range tables {
+				for _, row := range test.rows {
+					require.NoError(table.(sql.InsertableTable).Inserter(sql.NewEmptyContext()).Insert(sql.NewEmptyContext(), row))
+				}
+
+				projected := table.WithProjection(test.columns)
+				require.ElementsMatch(projected.Schema(), test.expectedSchema)
+				require.Equal(test.columns, projected.(sql.ProjectedTable).Projection())
 
-			projectedRows := testFlatRows(t, projected)
-			require.Len(projectedRows, len(test.expectedProjected))
-			for _, row := range projectedRows {
-				require.Contains(test.expectedProjected, row)
+				projectedRows := testFlatRows(t, projected)
+				require.Len(projectedRows, len(test.expectedProjected))
+				for _, row := range projectedRows {
+					require.Contains(test.expectedProjected, row)
+				}
 			}
 		})
 	}
@@ -291,6 +326,137 @@ func TestFilterAndProject(t *testing.T) {
 	}
 }
 
+func TestFilterAndProjectRow2(t *testing.T) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var require = require.New(t)
+
+			table := NewPartitionedPushdownTable(test.name, test.schema, test.numPartitions)
+			for _, row := range test.rows {
+				require.NoError(table.Insert(sql.NewEmptyContext(), row))
+			}
+
+			filtered := table.WithFilters(test.filters)
+			projected := filtered.(*PushdownTable).WithProjection(test.columns).(sql.Table2)
+
+			pIter, err := projected.Partitions(sql.NewEmptyContext())
+			require.NoError(err)
+
+			frame := sql.NewRowFrame()
+			defer frame.Recycle()
+
+			var rows []sql.Row
+			for {
+				p, err := pIter.Next(sql.NewEmptyContext())
+				if err == io.EOF {
+					break
+				}
+				require.NoError(err)
+
+				iter, err := projected.PartitionRows2(sql.NewEmptyContext(), p)
+				require.NoError(err)
+
+				for {
+					err := iter.Next2(frame)
+					if err == io.EOF {
+						break
+					}
+					require.NoError(err)
+
+					row, err := sql.Row2ToRow(projected.Schema(), frame.Row2())
+					require.NoError(err)
+					rows = append(rows, row)
+				}
+				require.NoError(iter.Close())
+			}
+
+			require.Len(rows, len(test.expectedFiltersAndProjections))
+			for _, row := range rows {
+				require.Contains(test.expectedFiltersAndProjections, row)
+			}
+		})
+	}
+}
+
+func TestOrderedAndLimited(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", Nullable: true},
+		{Name: "b", Type: sql.Text, Source: "t"},
+	}
+
+	table := NewPartitionedPushdownTable("t", schema, 3)
+	for _, row := range []sql.Row{
+		sql.NewRow(int64(3), "c"),
+		sql.NewRow(int64(1), "a"),
+		sql.NewRow(nil, "n"),
+		sql.NewRow(int64(2), "b"),
+		sql.NewRow(int64(1), "z"),
+		sql.NewRow(int64(5), "e"),
+	} {
+		require.NoError(table.Insert(sql.NewEmptyContext(), row))
+	}
+
+	require.True(table.CanOrder([]sql.SortColumn{{Column: "A"}}))
+	require.False(table.CanOrder([]sql.SortColumn{{Column: "c"}}))
+
+	ordered := table.WithOrder([]sql.SortColumn{{Column: "a"}, {Column: "b", Descending: true}}).(*PushdownTable)
+	require.Equal(
+		[]sql.Row{
+			{nil, "n"},
+			{int64(1), "z"},
+			{int64(1), "a"},
+			{int64(2), "b"},
+			{int64(3), "c"},
+			{int64(5), "e"},
+		},
+		testFlatRows(t, ordered),
+	)
+
+	nullsLast := table.WithOrder([]sql.SortColumn{{Column: "a", NullsLast: true}, {Column: "b"}}).(*PushdownTable)
+	require.Equal(
+		[]sql.Row{
+			{int64(1), "a"},
+			{int64(1), "z"},
+			{int64(2), "b"},
+			{int64(3), "c"},
+			{int64(5), "e"},
+			{nil, "n"},
+		},
+		testFlatRows(t, nullsLast),
+	)
+
+	descending := table.WithOrder([]sql.SortColumn{{Column: "a", Descending: true}}).(*PushdownTable)
+	limited := descending.WithLimit(2).(*PushdownTable).WithProjection([]string{"b", "a"})
+	require.Equal(
+		[]sql.Row{
+			{"e", int64(5)},
+			{"c", int64(3)},
+		},
+		testFlatRows(t, limited),
+	)
+
+	filtered := table.WithFilters([]sql.Expression{
+		expression.NewNot(expression.NewIsNull(expression.NewGetFieldWithTable(0, sql.Int64, "t", "a", true))),
+	}).(*PushdownTable).WithOrder([]sql.SortColumn{{Column: "a"}, {Column: "b"}})
+	require.Equal(
+		[]sql.Row{
+			{int64(1), "a"},
+			{int64(1), "z"},
+			{int64(2), "b"},
+			{int64(3), "c"},
+			{int64(5), "e"},
+		},
+		testFlatRows(t, filtered),
+	)
+
+	// Without an order, every partition is limited
+	unordered := table.WithLimit(1)
+	rows := testFlatRows(t, unordered)
+	require.Len(rows, 3)
+}
+
 func TestIndexed(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -328,7 +494,7 @@ func testFlatRows(t *testing.T, table sql.Table) []sql.Row {
 	require.NoError(err)
 	flatRows := []sql.Row{}
 	for {
-		p, err := pIter.Next()
+		p, err := pIter.Next(sql.NewEmptyContext())
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -370,7 +536,7 @@ func TestTableIndexKeyValueIter(t *testing.T) {
 			idxKVs := []*indexKeyValue{}
 			for {
 				if iter == nil {
-					_, iter, err = pIter.Next()
+					_, iter, err = pIter.Next(sql.NewEmptyContext())
 					if err != nil {
 						if err == io.EOF {
 							iter = nil
@@ -381,7 +547,7 @@ func TestTableIndexKeyValueIter(t *testing.T) {
 					}
 				}
 
-				row, data, err := iter.Next()
+				row, data, err := iter.Next(sql.NewEmptyContext())
 				if err != nil {
 					if err == io.EOF {
 						iter = nil
@@ -404,3 +570,80 @@ func TestTableIndexKeyValueIter(t *testing.T) {
 		})
 	}
 }
+
+// reversedLookup is an index lookup returning the row locations of another in reverse, as a secondary index would
+// return them in the order of its keys.
+type reversedLookup struct {
+	sql.DriverIndexLookup
+}
+
+func (l reversedLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
+	iter, err := l.DriverIndexLookup.Values(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var values [][]byte
+	for {
+		value, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append([][]byte{value}, values...)
+	}
+	return &indexValIter{values: values}, iter.Close()
+}
+
+func TestMultiRangeRead(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := NewPushdownTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	})
+	for _, row := range []sql.Row{
+		sql.NewRow(int64(1), int64(40)),
+		sql.NewRow(int64(2), int64(10)),
+		sql.NewRow(int64(3), int64(30)),
+		sql.NewRow(int64(4), int64(20)),
+	} {
+		require.NoError(table.Insert(ctx, row))
+	}
+	require.NoError(table.CreateIndex(ctx, "b", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "b"}}, ""))
+
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	lookup, err := indexes[0].(sql.AscendIndex).AscendGreaterOrEqual(int64(20))
+	require.NoError(err)
+	lookup = reversedLookup{lookup.(sql.DriverIndexLookup)}
+
+	reversed := []sql.Row{
+		{int64(4), int64(20)},
+		{int64(3), int64(30)},
+		{int64(1), int64(40)},
+	}
+	stored := []sql.Row{
+		{int64(1), int64(40)},
+		{int64(3), int64(30)},
+		{int64(4), int64(20)},
+	}
+
+	// The rows are fetched in the order the lookup returns them, or in the order they're stored in with a multi-range
+	// read
+	require.Equal(reversed, testFlatRows(t, table.WithIndexLookup(lookup)))
+	mrr := table.WithMultiRangeRead(lookup)
+	require.Equal(stored, testFlatRows(t, mrr))
+	require.Equal(reversed, testFlatRows(t, mrr.(*PushdownTable).WithIndexLookup(lookup)))
+
+	filtered := mrr.(*PushdownTable).WithFilters([]sql.Expression{
+		expression.NewLessThan(expression.NewGetFieldWithTable(0, sql.Int64, "t", "a", false), expression.NewLiteral(int64(4), sql.Int64)),
+	})
+	require.Equal(stored[:2], testFlatRows(t, filtered))
+
+	require.Equal(stored, testFlatRows(t, table.Table.WithMultiRangeRead(lookup)))
+	require.Equal(reversed, testFlatRows(t, table.Table.WithIndexLookup(lookup)))
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -20,6 +21,7 @@ var _ sql.Index = (*UnmergeableIndex)(nil)
 var _ sql.AscendIndex = (*UnmergeableIndex)(nil)
 var _ sql.DescendIndex = (*UnmergeableIndex)(nil)
 var _ sql.NegateIndex = (*UnmergeableIndex)(nil)
+var _ sql.PrefixIndex = (*UnmergeableIndex)(nil)
 
 func (u *UnmergeableIndex) Get(key ...interface{}) (sql.IndexLookup, error) {
 	return &UnmergeableIndexLookup{
@@ -28,6 +30,11 @@ func (u *UnmergeableIndex) Get(key ...interface{}) (sql.IndexLookup, error) {
 	}, nil
 }
 
+// GetPrefix implements the sql.PrefixIndex interface.
+func (u *UnmergeableIndex) GetPrefix(key ...interface{}) (sql.IndexLookup, error) {
+	return u.Get(key...)
+}
+
 // UnmergeableIndexLookup is the only IndexLookup in this package that doesn't implement Mergeable, and therefore
 // can't be merged with other lookups.
 type UnmergeableIndexLookup struct {
@@ -53,16 +60,46 @@ var _ sql.MergeableIndexLookup = (*UnmergeableIndexLookup)(nil)
 // indexValIter does a very simple and verifiable iteration over the table values for a given index. It does this
 // by iterating over all the table rows for a partition and evaluating each of them for inclusion in the index. This is
 // not an efficient way to store an index, and is only suitable for testing the correctness of index code in the engine.
+//
+// Lookups of a key of the indexes of a table, whose entries are maintained by the editors of the table, only evaluate
+// the match expression on the rows found with the entries of the index.
 type indexValIter struct {
 	tbl             *Table
 	partition       sql.Partition
 	matchExpression sql.Expression
+	lookup          *indexKeyLookup
 	values          [][]byte
 	i               int
 }
 
-func (u *indexValIter) Next() ([]byte, error) {
-	err := u.initValues()
+// indexKeyLookup is a lookup of a key of an index of a table.
+type indexKeyLookup struct {
+	name  string
+	exprs []sql.Expression
+	key   []interface{}
+}
+
+// newIndexKeyLookup returns the lookup of the key given of the index given, or nil if the index isn't an index of its
+// table, such as an index of a driver.
+func newIndexKeyLookup(idx ExpressionsIndex, key []interface{}) *indexKeyLookup {
+	var index *MergeableIndex
+	switch idx := idx.(type) {
+	case *MergeableIndex:
+		index = idx
+	case *UnmergeableIndex:
+		index = &idx.MergeableIndex
+	default:
+		return nil
+	}
+
+	if index.DriverName != "" || index.Tbl == nil {
+		return nil
+	}
+	return &indexKeyLookup{name: index.Name, exprs: index.Exprs, key: key}
+}
+
+func (u *indexValIter) Next(ctx *sql.Context) ([]byte, error) {
+	err := u.initValues(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -76,17 +113,28 @@ func (u *indexValIter) Next() ([]byte, error) {
 	return nil, io.EOF
 }
 
-func (u *indexValIter) initValues() error {
+func (u *indexValIter) initValues(ctx *sql.Context) error {
 	if u.values == nil {
-		rows, ok := u.tbl.partitions[string(u.partition.Key())]
-		if !ok {
-			return fmt.Errorf(
-				"partition not found: %q", u.partition.Key(),
-			)
+		version := u.tbl.partitionVersion(u.partition)
+		rows, err := version.rows(u.partition)
+		if err != nil {
+			return err
 		}
 
-		for i, row := range rows {
-			ok, err := sql.EvaluateCondition(sql.NewEmptyContext(), u.matchExpression, row)
+		positions, indexed := u.indexedPositions(version)
+		if !indexed {
+			positions = make([]int, len(rows))
+			for i := range positions {
+				positions[i] = i
+			}
+		}
+
+		for _, i := range positions {
+			if err := ctx.Interrupted(); err != nil {
+				return err
+			}
+
+			ok, err := sql.EvaluateCondition(ctx, u.matchExpression, rows[i])
 			if err != nil {
 				return err
 			}
@@ -108,6 +156,20 @@ func (u *indexValIter) initValues() error {
 	return nil
 }
 
+// indexedPositions returns the positions of the rows of the partition of the iterator in the version given found with
+// the entries of the index of the lookup, or false if it isn't a lookup of a key of an index of the table.
+func (u *indexValIter) indexedPositions(version *partitionsVersion) ([]int, bool) {
+	if u.lookup == nil {
+		return nil, false
+	}
+
+	entries, ok := version.indexes[u.lookup.name]
+	if !ok || !reflect.DeepEqual(entries.exprs, u.lookup.exprs) {
+		return nil, false
+	}
+	return entries.lookup(string(u.partition.Key()), u.lookup.key)
+}
+
 func getType(val interface{}) (interface{}, sql.Type) {
 	switch val := val.(type) {
 	case int:
@@ -136,6 +198,10 @@ func getType(val interface{}) (interface{}, sql.Type) {
 		return float64(val), sql.Float64
 	case string:
 		return val, sql.LongText
+	case []byte:
+		return val, sql.LongBlob
+	case sql.BinaryLiteral:
+		return val, sql.LongBlob
 	case nil:
 		return nil, sql.Null
 	default:
@@ -147,9 +213,10 @@ func (u *indexValIter) Close() error {
 	return nil
 }
 
-func (u *UnmergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, error) {
+func (u *UnmergeableIndexLookup) Values(ctx *sql.Context, p sql.Partition) (sql.IndexValueIter, error) {
 	var exprs []sql.Expression
-	for exprI, expr := range u.idx.Exprs {
+	// Keys of a prefix of the expressions only match them
+	for exprI, expr := range u.idx.Exprs[:len(u.key)] {
 		lit, typ := getType(u.key[exprI])
 		exprs = append(exprs, expression.NewEquals(expr, expression.NewLiteral(lit, typ)))
 	}
@@ -158,12 +225,13 @@ func (u *UnmergeableIndexLookup) Values(p sql.Partition) (sql.IndexValueIter, er
 		tbl:             u.idx.Tbl,
 		partition:       p,
 		matchExpression: and(exprs...),
+		lookup:          newIndexKeyLookup(u.idx, u.key),
 	}, nil
 }
 
 func (u *UnmergeableIndexLookup) Indexes() []string {
 	var idxes = make([]string, len(u.key))
-	for i, e := range u.idx.Exprs {
+	for i, e := range u.idx.Exprs[:len(u.key)] {
 		idxes[i] = fmt.Sprint(e)
 	}
 	return idxes
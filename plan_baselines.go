@@ -0,0 +1,50 @@
+package sqle
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// explainRegex matches the EXPLAIN keyword of a statement, with its format, before the statement it describes.
+var explainRegex = regexp.MustCompile(`(?is)^\s*(explain|describe|desc)(\s+format\s*=\s*\w+)?\s+`)
+
+// usePlanBaseline returns the statement given with the hints of the plan pinned for its digest before the hints of
+// its outermost query block, if a plan is pinned for it. Statements described by EXPLAIN use the plans pinned for
+// them too, so EXPLAIN describes the plans they're run with. Statements are only digested when some plan is pinned.
+func (e *Engine) usePlanBaseline(query string, parsed sql.Node) sql.Node {
+	if !e.Catalog.HasPlanBaselines() {
+		return parsed
+	}
+
+	statement := parsed
+	describe, explained := parsed.(*plan.DescribeQuery)
+	if explained {
+		loc := explainRegex.FindStringIndex(query)
+		if loc == nil {
+			return parsed
+		}
+		statement, query = describe.Child, query[loc[1]:]
+	}
+
+	digest, _ := parse.Digest(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+	baseline, ok := e.Catalog.UsePlanBaseline(digest)
+	if !ok {
+		return parsed
+	}
+
+	hints := append(sql.Hints{}, baseline.Hints...)
+	if qh, ok := statement.(*plan.QueryHints); ok {
+		statement = plan.NewQueryHints(append(hints, qh.Hints...), qh.Child)
+	} else {
+		statement = plan.NewQueryHints(hints, statement)
+	}
+
+	if explained {
+		return plan.NewDescribeQuery(describe.Format, statement)
+	}
+	return statement
+}
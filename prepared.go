@@ -0,0 +1,137 @@
+package sqle
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// preparedQuery is a query prepared by a session: the query run after the pre-parse hooks rewrote it, its parsed plan,
+// still with the bind variables of its parameters, and the types of its parameters, keyed by the names of their bind
+// variables.
+type preparedQuery struct {
+	query  string
+	parsed sql.Node
+	params map[string]sql.Type
+}
+
+// preparedQueries are the queries prepared by every session, keyed by the ID of the session and the query as it was
+// prepared.
+type preparedQueries struct {
+	mu        sync.Mutex
+	bySession map[uint32]map[string]preparedQuery
+}
+
+func (p *preparedQueries) get(session uint32, query string) (preparedQuery, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	prepared, ok := p.bySession[session][query]
+	return prepared, ok
+}
+
+func (p *preparedQueries) add(session uint32, query string, prepared preparedQuery) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bySession == nil {
+		p.bySession = make(map[uint32]map[string]preparedQuery)
+	}
+	if p.bySession[session] == nil {
+		p.bySession[session] = make(map[string]preparedQuery)
+	}
+	p.bySession[session][query] = prepared
+}
+
+func (p *preparedQueries) remove(session uint32, query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.bySession[session], query)
+	if len(p.bySession[session]) == 0 {
+		delete(p.bySession, session)
+	}
+}
+
+func (p *preparedQueries) removeSession(session uint32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.bySession, session)
+}
+
+func (p *preparedQueries) queries(session uint32) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var queries []string
+	for query := range p.bySession[session] {
+		queries = append(queries, query)
+	}
+	sort.Strings(queries)
+	return queries
+}
+
+// PrepareQuery prepares the query given for the session of the context given, so QueryWithBindings runs it without
+// parsing it again, with the values of its parameters bound to its bind variables. It returns the schema of the rows
+// the query returns, which is nil for statements that don't return rows. The query is analyzed to get its schema, so
+// queries that can't be analyzed, like the ones on tables that don't exist, fail to be prepared. Queries are analyzed
+// again every time they're run, with the values of their parameters.
+func (e *Engine) PrepareQuery(ctx *sql.Context, query string) (sql.Schema, error) {
+	if err := e.setSessionVariables(ctx); err != nil {
+		return nil, err
+	}
+
+	rewritten, parsed, err := e.parse(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	analyzed, err := e.Analyzer.Analyze(ctx, e.usePlanBaseline(rewritten, parsed), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	e.prepared.add(ctx.ID(), query, preparedQuery{query: rewritten, parsed: parsed, params: plan.BindVarTypes(analyzed)})
+
+	schema := analyzed.Schema()
+	if !returnsRows(analyzed) || schema.Equals(sql.OkResultSchema) {
+		return nil, nil
+	}
+	return schema, nil
+}
+
+// PreparedQueryParams returns the types of the parameters of the query given prepared by the session of the context
+// given, keyed by the names of their bind variables, or nil if it didn't prepare it. The types are inferred from the
+// expressions the parameters are compared to, and the columns their values are inserted into or updated in, so clients
+// can bind values of those types. Parameters whose type can't be inferred are strings.
+func (e *Engine) PreparedQueryParams(ctx *sql.Context, query string) map[string]sql.Type {
+	prepared, ok := e.prepared.get(ctx.ID(), query)
+	if !ok {
+		return nil
+	}
+	return prepared.params
+}
+
+// PreparedQueries returns the queries prepared by the session of the context given that haven't been closed, sorted.
+func (e *Engine) PreparedQueries(ctx *sql.Context) []string {
+	return e.prepared.queries(ctx.ID())
+}
+
+// ClosePreparedQuery forgets the query given prepared by the session of the context given, which is parsed again the
+// next time it's run.
+func (e *Engine) ClosePreparedQuery(ctx *sql.Context, query string) {
+	e.prepared.remove(ctx.ID(), query)
+}
+
+// ClosePreparedQueries forgets all the queries prepared by the session of the context given, which must be called when
+// the session ends.
+func (e *Engine) ClosePreparedQueries(ctx *sql.Context) {
+	e.prepared.removeSession(ctx.ID())
+}
+
+// parsePrepared returns the query given as rewritten by the pre-parse hooks and its parsed plan, which are the ones of
+// the prepared query if the session of the context given prepared it.
+func (e *Engine) parsePrepared(ctx *sql.Context, query string) (string, sql.Node, error) {
+	if prepared, ok := e.prepared.get(ctx.ID(), query); ok {
+		return prepared.query, prepared.parsed, nil
+	}
+	return e.parse(ctx, query)
+}
@@ -0,0 +1,263 @@
+package remote
+
+import (
+	"context"
+	dsql "database/sql"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// Database is a database whose tables are the tables of a remote MySQL or PostgreSQL server, which is queried over a
+// connection pool of database/sql. Filters, projections and limits pushed down to its tables, and joins between them,
+// are sent to the server as part of the queries reading their rows, so the engine can be used to federate several
+// servers. The driver of the connection is chosen by the caller, so this package doesn't depend on any of them.
+//
+// Table schemas are read from the server every time a table is requested, so schema changes in the server are seen
+// by the next query. Tables are read-only.
+type Database struct {
+	name    string
+	db      *dsql.DB
+	dialect Dialect
+}
+
+var _ sql.Database = (*Database)(nil)
+var _ sql.JoinPushdownDatabase = (*Database)(nil)
+
+// NewDatabase creates a new database with the name given, whose tables are found in the database that the connection
+// given is connected to.
+func NewDatabase(name string, db *dsql.DB, dialect Dialect) *Database {
+	return &Database{
+		name:    name,
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// Name returns the database name.
+func (d *Database) Name() string {
+	return d.name
+}
+
+// GetTableInsensitive implements the sql.Database interface.
+func (d *Database) GetTableInsensitive(ctx *sql.Context, tblName string) (sql.Table, bool, error) {
+	names, err := d.GetTableNames(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	name, ok := sql.GetTableNameInsensitive(tblName, names)
+	if !ok {
+		return nil, false, nil
+	}
+
+	schema, err := d.tableSchema(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &Table{db: d, name: name, schema: schema}, true, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (d *Database) GetTableNames(ctx *sql.Context) ([]string, error) {
+	rows, err := d.db.QueryContext(queryContext(ctx), d.dialect.TableNamesQuery())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	return names, rows.Err()
+}
+
+// tableSchema returns the schema of the table with the name given, read from the columns of a query on it that
+// returns no rows.
+func (d *Database) tableSchema(ctx *sql.Context, name string) (sql.Schema, error) {
+	rows, err := d.db.QueryContext(queryContext(ctx), "SELECT * FROM "+d.dialect.QuoteIdentifier(name)+" LIMIT 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(sql.Schema, len(types))
+	for i, ct := range types {
+		nullable, ok := ct.Nullable()
+		schema[i] = &sql.Column{
+			Name:     ct.Name(),
+			Type:     columnType(ct),
+			Nullable: nullable || !ok,
+			Source:   name,
+		}
+	}
+
+	return schema, rows.Err()
+}
+
+// queryRows returns an iterator over the rows of the query given, converted to the schema given.
+func (d *Database) queryRows(ctx *sql.Context, schema sql.Schema, query string, args []interface{}) (sql.RowIter, error) {
+	rows, err := d.db.QueryContext(queryContext(ctx), query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRowIter(ctx, rows, schema), nil
+}
+
+// PushdownJoin implements the sql.JoinPushdownDatabase interface. Joins are executed by the server when all their
+// tables are tables of this database, and their conditions and filters can be evaluated by it. Filters are only
+// accepted right above tables, or pushed down to them.
+func (d *Database) PushdownJoin(ctx *sql.Context, join sql.Node) (sql.Table, bool, error) {
+	from := &queryBuilder{dialect: d.dialect, qualify: true}
+	var names []string
+	if !d.writeJoin(from, join, &names) {
+		return nil, false, nil
+	}
+
+	schema := join.Schema()
+	b := &queryBuilder{dialect: d.dialect}
+	b.write("SELECT ")
+	for i, col := range schema {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.writeIdentifier(col.Source)
+		b.write(".")
+		b.writeIdentifier(col.Name)
+	}
+	b.write(" FROM ", from.String())
+
+	return &JoinTable{
+		db:     d,
+		name:   strings.Join(names, ", "),
+		schema: schema,
+		query:  b.String(),
+		args:   from.args,
+	}, true, nil
+}
+
+// writeJoin writes the part of the FROM clause of a query for the join or table given, and adds the names they're
+// referred by to names. It returns false if the node can't be executed by the server.
+func (d *Database) writeJoin(b *queryBuilder, n sql.Node, names *[]string) bool {
+	var op string
+	switch n := n.(type) {
+	case *plan.InnerJoin:
+		op = " INNER JOIN "
+	case *plan.LeftJoin:
+		op = " LEFT JOIN "
+	case *plan.RightJoin:
+		op = " RIGHT JOIN "
+	case *plan.CrossJoin:
+		op = " CROSS JOIN "
+	default:
+		return d.writeJoinTable(b, n, names)
+	}
+
+	// Joins are left associative, so only joins on the right need parentheses
+	children := n.Children()
+	if !d.writeJoin(b, children[0], names) {
+		return false
+	}
+
+	b.write(op)
+	if isJoin(children[1]) {
+		b.write("(")
+	}
+	if !d.writeJoin(b, children[1], names) {
+		return false
+	}
+	if isJoin(children[1]) {
+		b.write(")")
+	}
+
+	if e, ok := n.(sql.Expressioner); ok {
+		b.write(" ON ")
+		if !b.writeExpr(e.Expressions()[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isJoin(n sql.Node) bool {
+	switch n.(type) {
+	case *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.CrossJoin:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeJoinTable writes one of the tables of a join, which may be aliased or filtered. Tables with filters,
+// projections or limits are written as derived tables, so that they're applied before the join.
+func (d *Database) writeJoinTable(b *queryBuilder, n sql.Node, names *[]string) bool {
+	var alias string
+	var filters []sql.Expression
+	var rt *plan.ResolvedTable
+	for rt == nil {
+		switch nn := n.(type) {
+		case *plan.TableAlias:
+			if alias != "" {
+				return false
+			}
+			alias = nn.Name()
+			n = nn.Child
+		case *plan.Filter:
+			filters = append(filters, nn.Expression)
+			n = nn.Child
+		case *plan.DecoratedNode:
+			n = nn.Child
+		case *plan.ResolvedTable:
+			rt = nn
+		default:
+			return false
+		}
+	}
+
+	t, ok := rt.Table.(*Table)
+	if !ok || t.db != d {
+		return false
+	}
+
+	if alias == "" {
+		alias = t.name
+	}
+
+	if len(filters) == 0 && len(t.filters) == 0 && len(t.projection) == 0 && !t.limited {
+		b.writeIdentifier(t.name)
+	} else {
+		b.write("(")
+		if !t.writeQuery(b, alias, filters) {
+			return false
+		}
+		b.write(")")
+	}
+
+	b.write(" AS ")
+	b.writeIdentifier(alias)
+	*names = append(*names, alias)
+	return true
+}
+
+// queryContext returns the context to use for the queries of the context given, which are canceled with it.
+func queryContext(ctx *sql.Context) context.Context {
+	if ctx == nil || ctx.Context == nil {
+		return context.Background()
+	}
+	return ctx.Context
+}
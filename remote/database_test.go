@@ -0,0 +1,242 @@
+package remote_test
+
+import (
+	"context"
+	dsql "database/sql"
+	"fmt"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/auth"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/remote"
+	"github.com/dolthub/go-mysql-server/server"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/analyzer"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+const port = 3337
+
+// remoteServer starts a server for a database with a few tables, which plays the part of the remote server.
+func remoteServer(t *testing.T) *server.Server {
+	db := memory.NewDatabase("mydb")
+
+	people := memory.NewTable("people", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "people", PrimaryKey: true},
+		{Name: "name", Type: sql.LongText, Source: "people"},
+		{Name: "city_id", Type: sql.Int64, Source: "people", Nullable: true},
+	})
+	cities := memory.NewTable("cities", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "cities", PrimaryKey: true},
+		{Name: "city", Type: sql.LongText, Source: "cities"},
+	})
+
+	ctx := sql.NewEmptyContext()
+	for _, row := range []sql.Row{
+		{int64(1), "ann", int64(1)},
+		{int64(2), "bob", int64(2)},
+		{int64(3), "cat", nil},
+		{int64(4), "dan", int64(1)},
+	} {
+		require.NoError(t, people.Insert(ctx, row))
+	}
+	for _, row := range []sql.Row{
+		{int64(1), "paris"},
+		{int64(2), "rome"},
+	} {
+		require.NoError(t, cities.Insert(ctx, row))
+	}
+
+	db.AddTable("people", people)
+	db.AddTable("cities", cities)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	engine := sqle.New(catalog, analyzer.NewDefault(catalog), nil)
+
+	s, err := server.NewDefaultServer(server.Config{
+		Protocol: "tcp",
+		Address:  fmt.Sprintf("localhost:%d", port),
+		Auth:     auth.NewNativeSingle("root", "", auth.AllPermissions),
+	}, engine)
+	require.NoError(t, err)
+
+	go s.Start()
+	return s
+}
+
+func federatedEngine(t *testing.T) (*sqle.Engine, *dsql.DB) {
+	conn, err := dsql.Open("mysql", fmt.Sprintf("root:@tcp(127.0.0.1:%d)/mydb?interpolateParams=true", port))
+	require.NoError(t, err)
+
+	local := memory.NewDatabase("local")
+	scores := memory.NewTable("scores", sql.Schema{
+		{Name: "person_id", Type: sql.Int64, Source: "scores"},
+		{Name: "score", Type: sql.Int64, Source: "scores"},
+	})
+	ctx := sql.NewEmptyContext()
+	require.NoError(t, scores.Insert(ctx, sql.Row{int64(1), int64(10)}))
+	require.NoError(t, scores.Insert(ctx, sql.Row{int64(4), int64(40)}))
+	local.AddTable("scores", scores)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(remote.NewDatabase("fed", conn, remote.MySQL))
+	catalog.AddDatabase(local)
+
+	return sqle.New(catalog, analyzer.NewDefault(catalog), nil), conn
+}
+
+func TestDatabase(t *testing.T) {
+	s := remoteServer(t)
+	defer s.Close()
+
+	e, conn := federatedEngine(t)
+	defer conn.Close()
+
+	tests := []struct {
+		query    string
+		expected []sql.Row
+	}{
+		{
+			"SELECT * FROM people ORDER BY id",
+			[]sql.Row{
+				{int64(1), "ann", int64(1)},
+				{int64(2), "bob", int64(2)},
+				{int64(3), "cat", nil},
+				{int64(4), "dan", int64(1)},
+			},
+		},
+		{
+			"SELECT name FROM people WHERE city_id = 1 AND id > 1",
+			[]sql.Row{{"dan"}},
+		},
+		{
+			"SELECT name FROM people WHERE city_id IS NULL OR id IN (2, 5) ORDER BY name",
+			[]sql.Row{{"bob"}, {"cat"}},
+		},
+		{
+			"SELECT name FROM people WHERE name LIKE 'a%'",
+			[]sql.Row{{"ann"}},
+		},
+		{
+			"SELECT id FROM people LIMIT 2",
+			[]sql.Row{{int64(1)}, {int64(2)}},
+		},
+		{
+			"SELECT p.name, c.city FROM people p INNER JOIN cities c ON p.city_id = c.id WHERE c.city = 'paris' ORDER BY p.name",
+			[]sql.Row{{"ann", "paris"}, {"dan", "paris"}},
+		},
+		{
+			"SELECT p.name, c.city FROM people p LEFT JOIN cities c ON p.city_id = c.id ORDER BY p.name",
+			[]sql.Row{{"ann", "paris"}, {"bob", "rome"}, {"cat", nil}, {"dan", "paris"}},
+		},
+		{
+			"SELECT p.name, s.score FROM people p INNER JOIN local.scores s ON p.id = s.person_id ORDER BY p.name",
+			[]sql.Row{{"ann", int64(10)}, {"dan", int64(40)}},
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := sql.NewContext(context.Background(), sql.WithPid(uint64(i+1))).WithCurrentDB("fed")
+			_, iter, err := e.Query(ctx, tt.query)
+			require.NoError(t, err)
+
+			rows, err := sql.RowIterToRows(iter)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rows)
+		})
+	}
+}
+
+func TestDatabasePushdown(t *testing.T) {
+	s := remoteServer(t)
+	defer s.Close()
+
+	e, conn := federatedEngine(t)
+	defer conn.Close()
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"SELECT name FROM people WHERE city_id = 1 LIMIT 1",
+			"SELECT `name` FROM `people` WHERE (`city_id` = ?) LIMIT 1 [1]",
+		},
+		{
+			"SELECT id FROM people LIMIT 2",
+			"SELECT `id` FROM `people` LIMIT 2",
+		},
+		{
+			"SELECT p.name, c.city FROM people p INNER JOIN cities c ON p.city_id = c.id WHERE c.city = 'paris'",
+			"SELECT `p`.`name`, `p`.`city_id`, `c`.`city`, `c`.`id` FROM (SELECT `name`, `city_id` FROM `people` AS `p`) AS `p` " +
+				"INNER JOIN (SELECT `city`, `id` FROM `cities` AS `c` WHERE (`city` = ?)) AS `c` ON (`p`.`city_id` = `c`.`id`) [paris]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ctx := sql.NewEmptyContext().WithCurrentDB("fed")
+			parsed, err := e.Analyzer.Analyze(ctx, mustParse(t, ctx, tt.query), nil)
+			require.NoError(t, err)
+
+			var queries []string
+			plan.Inspect(parsed, func(n sql.Node) bool {
+				if rt, ok := n.(*plan.ResolvedTable); ok {
+					table := rt.Table
+					if tw, ok := table.(sql.TableWrapper); ok {
+						table = tw.Underlying()
+					}
+					queries = append(queries, sql.DebugString(table))
+				}
+				return true
+			})
+
+			require.Equal(t, []string{tt.expected}, queries)
+		})
+	}
+}
+
+func mustParse(t *testing.T, ctx *sql.Context, query string) sql.Node {
+	n, err := parse.Parse(ctx, query)
+	require.NoError(t, err)
+	return n
+}
+
+func TestDatabaseInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	s := remoteServer(t)
+	defer s.Close()
+
+	conn, err := dsql.Open("mysql", fmt.Sprintf("root:@tcp(127.0.0.1:%d)/mydb?interpolateParams=true", port))
+	require.NoError(err)
+	defer conn.Close()
+
+	db := remote.NewDatabase("fed", conn, remote.MySQL)
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlCtx := sql.NewContext(ctx)
+	table, ok, err := db.GetTableInsensitive(sqlCtx, "people")
+	require.NoError(err)
+	require.True(ok)
+
+	partitions, err := table.Partitions(sqlCtx)
+	require.NoError(err)
+	p, err := partitions.Next(sqlCtx)
+	require.NoError(err)
+	iter, err := table.PartitionRows(sqlCtx, p)
+	require.NoError(err)
+
+	_, err = iter.Next()
+	require.NoError(err)
+	cancel()
+	_, err = iter.Next()
+	require.Equal(context.Canceled, err)
+	require.NoError(iter.Close())
+}
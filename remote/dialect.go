@@ -0,0 +1,117 @@
+package remote
+
+import (
+	dsql "database/sql"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Dialect is the SQL dialect spoken by a remote server. It's used to build the queries sent to it.
+type Dialect interface {
+	// QuoteIdentifier returns the name given quoted as an identifier.
+	QuoteIdentifier(name string) string
+	// Placeholder returns the placeholder of the nth argument of a query, starting at 1.
+	Placeholder(n int) string
+	// TableNamesQuery returns a query whose rows have the name of every table of the remote database.
+	TableNamesQuery() string
+}
+
+// MySQL is the dialect of MySQL servers, and of any server compatible with them.
+var MySQL Dialect = mysqlDialect{}
+
+// Postgres is the dialect of PostgreSQL servers. Tables are read from the current schema of the connection.
+var Postgres Dialect = postgresDialect{}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (mysqlDialect) TableNamesQuery() string {
+	return "SHOW TABLES"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) TableNamesQuery() string {
+	return "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname = current_schema() ORDER BY tablename"
+}
+
+// columnType returns the type of the values of a column of the remote server. Types that aren't known are read as
+// text.
+func columnType(ct *dsql.ColumnType) sql.Type {
+	unsigned := false
+	if st := ct.ScanType(); st != nil {
+		switch st.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			unsigned = true
+		}
+	}
+
+	switch strings.ToUpper(ct.DatabaseTypeName()) {
+	case "TINYINT":
+		if unsigned {
+			return sql.Uint8
+		}
+		return sql.Int8
+	case "BOOL", "BOOLEAN":
+		return sql.Boolean
+	case "SMALLINT", "INT2":
+		if unsigned {
+			return sql.Uint16
+		}
+		return sql.Int16
+	case "MEDIUMINT":
+		if unsigned {
+			return sql.Uint24
+		}
+		return sql.Int24
+	case "INT", "INTEGER", "INT4":
+		if unsigned {
+			return sql.Uint32
+		}
+		return sql.Int32
+	case "BIGINT", "INT8":
+		if unsigned {
+			return sql.Uint64
+		}
+		return sql.Int64
+	case "FLOAT", "FLOAT4", "REAL":
+		return sql.Float32
+	case "DOUBLE", "FLOAT8":
+		return sql.Float64
+	case "DECIMAL", "NUMERIC":
+		if precision, scale, ok := ct.DecimalSize(); ok && precision > 0 && precision <= 65 && scale <= 30 {
+			if t, err := sql.CreateDecimalType(uint8(precision), uint8(scale)); err == nil {
+				return t
+			}
+		}
+		return sql.LongText
+	case "DATE":
+		return sql.Date
+	case "DATETIME", "TIMESTAMP", "TIMESTAMPTZ":
+		return sql.Datetime
+	case "JSON", "JSONB":
+		return sql.JSON
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA":
+		return sql.LongBlob
+	default:
+		return sql.LongText
+	}
+}
@@ -0,0 +1,138 @@
+package remote
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// queryBuilder builds the text of a query for a remote server, along with its arguments.
+type queryBuilder struct {
+	dialect Dialect
+	// qualify is whether columns are qualified with the name of their table
+	qualify bool
+	sb      strings.Builder
+	args    []interface{}
+}
+
+func (b *queryBuilder) write(s ...string) {
+	for _, s := range s {
+		b.sb.WriteString(s)
+	}
+}
+
+func (b *queryBuilder) writeIdentifier(name string) {
+	b.write(b.dialect.QuoteIdentifier(name))
+}
+
+func (b *queryBuilder) writeArg(v interface{}) {
+	b.args = append(b.args, v)
+	b.write(b.dialect.Placeholder(len(b.args)))
+}
+
+func (b *queryBuilder) String() string {
+	return b.sb.String()
+}
+
+// canWriteExpr returns whether the expression given can be evaluated by the remote server.
+func canWriteExpr(e sql.Expression) bool {
+	b := &queryBuilder{dialect: MySQL}
+	return b.writeExpr(e)
+}
+
+// writeExpr writes the expression given, and returns whether it could be written. Only comparisons, logic operators,
+// IS NULL and IN between columns and literals can be written. Nothing is written if false is returned.
+func (b *queryBuilder) writeExpr(e sql.Expression) bool {
+	sub := &queryBuilder{dialect: b.dialect, qualify: b.qualify, args: b.args}
+	if !sub.writeExprTo(e) {
+		return false
+	}
+
+	b.write(sub.String())
+	b.args = sub.args
+	return true
+}
+
+func (b *queryBuilder) writeExprTo(e sql.Expression) bool {
+	switch e := e.(type) {
+	case *expression.GetField:
+		if b.qualify {
+			b.writeIdentifier(e.Table())
+			b.write(".")
+		}
+		b.writeIdentifier(e.Name())
+		return true
+	case *expression.Literal:
+		if e.Value() == nil {
+			b.write("NULL")
+		} else {
+			b.writeArg(e.Value())
+		}
+		return true
+	case *expression.Equals:
+		return b.writeBinary(e.Left(), "=", e.Right())
+	case *expression.GreaterThan:
+		return b.writeBinary(e.Left(), ">", e.Right())
+	case *expression.LessThan:
+		return b.writeBinary(e.Left(), "<", e.Right())
+	case *expression.GreaterThanOrEqual:
+		return b.writeBinary(e.Left(), ">=", e.Right())
+	case *expression.LessThanOrEqual:
+		return b.writeBinary(e.Left(), "<=", e.Right())
+	case *expression.And:
+		return b.writeBinary(e.Left, "AND", e.Right)
+	case *expression.Or:
+		return b.writeBinary(e.Left, "OR", e.Right)
+	case *expression.Not:
+		b.write("(NOT ")
+		if !b.writeExprTo(e.Child) {
+			return false
+		}
+		b.write(")")
+		return true
+	case *expression.IsNull:
+		b.write("(")
+		if !b.writeExprTo(e.Child) {
+			return false
+		}
+		b.write(" IS NULL)")
+		return true
+	case *expression.InTuple:
+		tuple, ok := e.Right().(expression.Tuple)
+		if !ok {
+			return false
+		}
+
+		b.write("(")
+		if !b.writeExprTo(e.Left()) {
+			return false
+		}
+		b.write(" IN (")
+		for i, elem := range tuple {
+			if i > 0 {
+				b.write(", ")
+			}
+			if !b.writeExprTo(elem) {
+				return false
+			}
+		}
+		b.write("))")
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *queryBuilder) writeBinary(left sql.Expression, op string, right sql.Expression) bool {
+	b.write("(")
+	if !b.writeExprTo(left) {
+		return false
+	}
+	b.write(" ", op, " ")
+	if !b.writeExprTo(right) {
+		return false
+	}
+	b.write(")")
+	return true
+}
@@ -0,0 +1,358 @@
+package remote
+
+import (
+	dsql "database/sql"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Table is a table of a remote server. Its rows are read with a query that selects its projected columns, filtered
+// by the filters pushed down to it and limited by its limit, so they're applied by the remote server.
+type Table struct {
+	db         *Database
+	name       string
+	schema     sql.Schema
+	projection []string
+	filters    []sql.Expression
+	limit      int64
+	limited    bool
+}
+
+var _ sql.Table = (*Table)(nil)
+var _ sql.FilteredTable = (*Table)(nil)
+var _ sql.ProjectedTable = (*Table)(nil)
+var _ sql.LimitedTable = (*Table)(nil)
+var _ sql.PartitionCounter = (*Table)(nil)
+var _ sql.RemoteTable = (*Table)(nil)
+
+// Name implements the sql.Nameable interface.
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Database returns the database of this table.
+func (t *Table) Database() *Database {
+	return t.db
+}
+
+// Schema implements the sql.Table interface. If the table is projected, only the projected columns are returned.
+func (t *Table) Schema() sql.Schema {
+	if len(t.projection) == 0 {
+		return t.schema
+	}
+
+	schema := make(sql.Schema, len(t.projection))
+	for i, name := range t.projection {
+		schema[i] = t.schema[t.schema.IndexOf(name, t.name)]
+	}
+	return schema
+}
+
+func (t *Table) String() string {
+	return t.name
+}
+
+// DebugString returns the query used to read the rows of this table.
+func (t *Table) DebugString() string {
+	query, args := t.query()
+	if len(args) == 0 {
+		return query
+	}
+	return fmt.Sprintf("%s %v", query, args)
+}
+
+// Partitions implements the sql.Table interface. Remote tables have a single partition.
+func (t *Table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionCount implements the sql.PartitionCounter interface.
+func (t *Table) PartitionCount(*sql.Context) (int64, error) {
+	return 1, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *Table) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	query, args := t.query()
+	return t.db.queryRows(ctx, t.Schema(), query, args)
+}
+
+// query returns the query that reads the rows of this table, and its arguments.
+func (t *Table) query() (string, []interface{}) {
+	b := &queryBuilder{dialect: t.db.dialect}
+	t.writeQuery(b, "", nil)
+	return b.String(), b.args
+}
+
+// writeQuery writes the query that reads the rows of this table, which is referred to by the alias given, if any,
+// and filtered by the filters given too. It returns false if any of the filters can't be written. Columns aren't
+// qualified, since the query only reads this table, and filters may refer to it by its name or its alias.
+func (t *Table) writeQuery(b *queryBuilder, alias string, filters []sql.Expression) bool {
+	qualify := b.qualify
+	b.qualify = false
+	defer func() {
+		b.qualify = qualify
+	}()
+
+	b.write("SELECT ")
+	for i, col := range t.Schema() {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.writeIdentifier(col.Name)
+	}
+
+	b.write(" FROM ")
+	b.writeIdentifier(t.name)
+	if alias != "" {
+		b.write(" AS ")
+		b.writeIdentifier(alias)
+	}
+
+	// The same filter may be both pushed down to the table and above it, so each is written once
+	written := make(map[string]bool)
+	for _, f := range append(t.filters[:len(t.filters):len(t.filters)], filters...) {
+		fb := &queryBuilder{dialect: b.dialect}
+		if !fb.writeExpr(f) {
+			return false
+		}
+
+		key := fmt.Sprintf("%s %v", fb.String(), fb.args)
+		if written[key] {
+			continue
+		}
+
+		if len(written) == 0 {
+			b.write(" WHERE ")
+		} else {
+			b.write(" AND ")
+		}
+		written[key] = true
+		b.writeExpr(f)
+	}
+
+	if t.limited {
+		b.write(" LIMIT ", strconv.FormatInt(t.limit, 10))
+	}
+
+	return true
+}
+
+// HandledFilters implements the sql.FilteredTable interface. Filters made of comparisons, logic operators, IS NULL
+// and IN between columns and literals are evaluated by the remote server. Note that they're evaluated with the
+// semantics of the remote server, such as the collation of its columns.
+func (t *Table) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var handled []sql.Expression
+	for _, f := range filters {
+		if canWriteExpr(f) {
+			handled = append(handled, f)
+		}
+	}
+	return handled
+}
+
+// WithFilters implements the sql.FilteredTable interface.
+func (t *Table) WithFilters(filters []sql.Expression) sql.Table {
+	handled := t.HandledFilters(filters)
+	if len(handled) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.filters = handled
+	return &nt
+}
+
+// Filters implements the sql.FilteredTable interface.
+func (t *Table) Filters() []sql.Expression {
+	return t.filters
+}
+
+// WithProjection implements the sql.ProjectedTable interface.
+func (t *Table) WithProjection(colNames []string) sql.Table {
+	if len(colNames) == 0 {
+		return t
+	}
+
+	projection := make([]string, len(colNames))
+	for i, name := range colNames {
+		idx := t.schema.IndexOf(name, t.name)
+		if idx == -1 {
+			panic(fmt.Sprintf("column %s not found in table %s", name, t.name))
+		}
+		projection[i] = t.schema[idx].Name
+	}
+
+	nt := *t
+	nt.projection = projection
+	return &nt
+}
+
+// Projection implements the sql.ProjectedTable interface.
+func (t *Table) Projection() []string {
+	return t.projection
+}
+
+// WithLimit implements the sql.LimitedTable interface.
+func (t *Table) WithLimit(limit int64) sql.Table {
+	nt := *t
+	nt.limit = limit
+	nt.limited = true
+	return &nt
+}
+
+// Limit implements the sql.LimitedTable interface.
+func (t *Table) Limit() (int64, bool) {
+	return t.limit, t.limited
+}
+
+// IsRemote implements the sql.RemoteTable interface.
+func (t *Table) IsRemote() bool {
+	return true
+}
+
+// JoinTable is a join between tables of a remote server, which is executed by the server.
+type JoinTable struct {
+	db     *Database
+	name   string
+	schema sql.Schema
+	query  string
+	args   []interface{}
+}
+
+var _ sql.Table = (*JoinTable)(nil)
+var _ sql.RemoteTable = (*JoinTable)(nil)
+
+// Name implements the sql.Nameable interface.
+func (t *JoinTable) Name() string {
+	return t.name
+}
+
+// Schema implements the sql.Table interface.
+func (t *JoinTable) Schema() sql.Schema {
+	return t.schema
+}
+
+func (t *JoinTable) String() string {
+	return t.name
+}
+
+// DebugString returns the query used to read the rows of this join.
+func (t *JoinTable) DebugString() string {
+	if len(t.args) == 0 {
+		return t.query
+	}
+	return fmt.Sprintf("%s %v", t.query, t.args)
+}
+
+// Partitions implements the sql.Table interface. Remote joins have a single partition.
+func (t *JoinTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *JoinTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	return t.db.queryRows(ctx, t.schema, t.query, t.args)
+}
+
+// IsRemote implements the sql.RemoteTable interface.
+func (t *JoinTable) IsRemote() bool {
+	return true
+}
+
+type partition struct{}
+
+func (partition) Key() []byte {
+	return []byte("remote")
+}
+
+type partitionIter struct {
+	done bool
+}
+
+func (i *partitionIter) Next(*sql.Context) (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+	i.done = true
+	return partition{}, nil
+}
+
+func (i *partitionIter) Close() error {
+	return nil
+}
+
+// rowIter is an iterator over the rows of a query to a remote server, which are converted to the types of a schema.
+// The query is canceled with the context it was made with, so the rows stop as soon as it's done.
+type rowIter struct {
+	ctx    *sql.Context
+	rows   *dsql.Rows
+	schema sql.Schema
+	values []interface{}
+	ptrs   []interface{}
+}
+
+func newRowIter(ctx *sql.Context, rows *dsql.Rows, schema sql.Schema) *rowIter {
+	values := make([]interface{}, len(schema))
+	ptrs := make([]interface{}, len(schema))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	return &rowIter{ctx: ctx, rows: rows, schema: schema, values: values, ptrs: ptrs}
+}
+
+func (i *rowIter) Next() (sql.Row, error) {
+	if i.ctx != nil {
+		if err := i.ctx.Interrupted(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !i.rows.Next() {
+		if err := i.rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	if err := i.rows.Scan(i.ptrs...); err != nil {
+		return nil, err
+	}
+
+	row := make(sql.Row, len(i.values))
+	for j, v := range i.values {
+		val, err := convertValue(i.schema[j].Type, v)
+		if err != nil {
+			return nil, err
+		}
+		row[j] = val
+	}
+
+	return row, nil
+}
+
+func (i *rowIter) Close() error {
+	// The rows of canceled queries fail to close with the error of their context, which Next already returned
+	if err := i.rows.Close(); err != nil && (i.ctx == nil || err != i.ctx.Err()) {
+		return err
+	}
+	return nil
+}
+
+// convertValue converts a value read from a remote server to the type given. Drivers return most values as bytes in
+// the text encoding of the server, which types convert from strings.
+func convertValue(typ sql.Type, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+
+	return typ.Convert(v)
+}
@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestTableQuery(t *testing.T) {
+	db := NewDatabase("fed", nil, Postgres)
+	table := &Table{db: db, name: "people", schema: sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "people"},
+		{Name: "name", Type: sql.LongText, Source: "people"},
+	}}
+
+	id := expression.NewGetFieldWithTable(0, sql.Int64, "people", "id", false)
+	name := expression.NewGetFieldWithTable(1, sql.LongText, "people", "name", false)
+
+	query, args := table.query()
+	require.Equal(t, `SELECT "id", "name" FROM "people"`, query)
+	require.Empty(t, args)
+
+	filters := []sql.Expression{
+		expression.NewGreaterThan(id, expression.NewLiteral(int64(1), sql.Int64)),
+		expression.NewOr(
+			expression.NewIsNull(name),
+			expression.NewInTuple(name, expression.NewTuple(
+				expression.NewLiteral("a", sql.LongText),
+				expression.NewLiteral("b", sql.LongText),
+			)),
+		),
+		expression.NewLike(name, expression.NewLiteral("a%", sql.LongText)),
+	}
+
+	require.Equal(t, filters[:2], table.HandledFilters(filters))
+
+	filtered := table.WithFilters(filters).(*Table).WithProjection([]string{"name"}).(*Table).WithLimit(10)
+	query, args = filtered.(*Table).query()
+	require.Equal(t, `SELECT "name" FROM "people" WHERE ("id" > $1) AND (("name" IS NULL) OR ("name" IN ($2, $3))) LIMIT 10`, query)
+	require.Equal(t, []interface{}{int64(1), "a", "b"}, args)
+}
+
+func TestPushdownJoin(t *testing.T) {
+	db := NewDatabase("fed", nil, Postgres)
+	other := NewDatabase("other", nil, Postgres)
+
+	people := &Table{db: db, name: "people", schema: sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "people"},
+		{Name: "city_id", Type: sql.Int64, Source: "people"},
+	}}
+	cities := &Table{db: db, name: "cities", schema: sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "cities"},
+	}}
+	otherCities := &Table{db: other, name: "cities", schema: cities.schema}
+
+	cityID := expression.NewGetFieldWithTable(1, sql.Int64, "p", "city_id", false)
+	id := expression.NewGetFieldWithTable(2, sql.Int64, "cities", "id", false)
+
+	join := plan.NewLeftJoin(
+		plan.NewTableAlias("p", plan.NewResolvedTable(people)),
+		plan.NewFilter(
+			expression.NewLessThan(id, expression.NewLiteral(int64(5), sql.Int64)),
+			plan.NewResolvedTable(cities),
+		),
+		expression.NewEquals(cityID, id),
+	)
+
+	table, ok, err := db.PushdownJoin(sql.NewEmptyContext(), join)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, join.Schema(), table.Schema())
+	require.Equal(t, "p, cities", table.Name())
+	require.Equal(
+		t,
+		`SELECT "p"."id", "p"."city_id", "cities"."id" FROM "people" AS "p" LEFT JOIN `+
+			`(SELECT "id" FROM "cities" AS "cities" WHERE ("id" < $1)) AS "cities" ON ("p"."city_id" = "cities"."id") [5]`,
+		table.(*JoinTable).DebugString(),
+	)
+
+	_, ok, err = db.PushdownJoin(sql.NewEmptyContext(), plan.NewCrossJoin(
+		plan.NewResolvedTable(people),
+		plan.NewResolvedTable(otherCities),
+	))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,204 @@
+package sqle
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var selectCacheHintRegex = regexp.MustCompile(
+	`(?i)^\s*select\s+(?:(?:all|distinct|distinctrow|high_priority|straight_join)\s+)*(sql_cache|sql_no_cache)?`)
+
+// nonDeterministicFunctions are the functions whose results may differ between two executions of the same query
+// over the same data. Queries using them are never cached.
+var nonDeterministicFunctions = map[string]bool{
+	"connection_id":     true,
+	"curdate":           true,
+	"current_date":      true,
+	"current_time":      true,
+	"current_timestamp": true,
+	"current_user":      true,
+	"curtime":           true,
+	"database":          true,
+	"found_rows":        true,
+	"get_lock":          true,
+	"is_free_lock":      true,
+	"is_used_lock":      true,
+	"last_insert_id":    true,
+	"now":               true,
+	"rand":              true,
+	"row_count":         true,
+	"release_all_locks": true,
+	"release_lock":      true,
+	"schema":            true,
+	"sleep":             true,
+	"unix_timestamp":    true,
+	"user":              true,
+	"utc_timestamp":     true,
+}
+
+// resultCacheKey returns the key under which the results of the query given are cached, or false if the query's
+// results must not be cached for this session.
+func (e *Engine) resultCacheKey(ctx *sql.Context, query string, parsed sql.Node) (string, bool) {
+	if e.ResultCache == nil {
+		return "", false
+	}
+
+	match := selectCacheHintRegex.FindStringSubmatch(query)
+	if match == nil {
+		return "", false
+	}
+
+	_, val := ctx.Get(sql.QueryCacheTypeSessionVar)
+	hint := strings.ToLower(match[1])
+	switch sql.QueryCacheTypeFromValue(val) {
+	case sql.QueryCacheOn:
+		if hint == "sql_no_cache" {
+			return "", false
+		}
+	case sql.QueryCacheDemand:
+		if hint != "sql_cache" {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	if !isDeterministicQuery(ctx, parsed) {
+		return "", false
+	}
+
+	return ctx.Client().User + "\x00" + ctx.GetCurrentDatabase() + "\x00" + query, true
+}
+
+// isDeterministicQuery returns whether the parsed query given always returns the same results for the same data. It
+// must be called on the parsed, unanalyzed query, since function names aren't available after analysis.
+func isDeterministicQuery(ctx *sql.Context, parsed sql.Node) bool {
+	deterministic := true
+	plan.Inspect(parsed, func(node sql.Node) bool {
+		if !deterministic {
+			return false
+		}
+
+		switch n := node.(type) {
+		case *plan.UnresolvedTable:
+			db := n.Database
+			if db == "" {
+				db = ctx.GetCurrentDatabase()
+			}
+			if strings.ToLower(db) == "information_schema" {
+				deterministic = false
+			}
+		case *plan.ShowProcessList, *plan.ShowWarnings, *plan.ShowVariables:
+			deterministic = false
+		case *plan.Limit:
+			// Cached results don't record the found rows of the query
+			if n.CalcFoundRows {
+				deterministic = false
+			}
+		}
+
+		if n, ok := node.(sql.Expressioner); ok {
+			for _, e := range n.Expressions() {
+				if !isDeterministicExpression(ctx, e) {
+					deterministic = false
+				}
+			}
+		}
+
+		return deterministic
+	})
+
+	return deterministic
+}
+
+func isDeterministicExpression(ctx *sql.Context, e sql.Expression) bool {
+	deterministic := true
+	sql.Inspect(e, func(e sql.Expression) bool {
+		switch e := e.(type) {
+		case *expression.UnresolvedFunction:
+			if nonDeterministicFunctions[strings.ToLower(e.Name())] {
+				deterministic = false
+			}
+		case *expression.UnresolvedColumn:
+			if strings.HasPrefix(e.Name(), "@") {
+				deterministic = false
+			}
+		case *plan.Subquery:
+			if !isDeterministicQuery(ctx, e.Query) {
+				deterministic = false
+			}
+		case sql.NonDeterministicExpression:
+			if e.IsNonDeterministic() {
+				deterministic = false
+			}
+		}
+		return deterministic
+	})
+	return deterministic
+}
+
+// queriedTables returns the names of all the tables read by the analyzed node given.
+func queriedTables(n sql.Node) []string {
+	var tables []string
+	var inspect func(sql.Node) bool
+	inspect = func(node sql.Node) bool {
+		switch n := node.(type) {
+		case *plan.ResolvedTable:
+			tables = append(tables, n.Name())
+		}
+		if n, ok := node.(sql.Expressioner); ok {
+			for _, e := range n.Expressions() {
+				sql.Inspect(e, func(e sql.Expression) bool {
+					if sq, ok := e.(*plan.Subquery); ok {
+						plan.Inspect(sq.Query, inspect)
+					}
+					return true
+				})
+			}
+		}
+		return true
+	}
+	plan.Inspect(n, inspect)
+	return tables
+}
+
+// resultCacheInvalidation returns a function that invalidates the cached results made stale by the statement given,
+// or nil if the statement doesn't change any data or schema.
+func (e *Engine) resultCacheInvalidation(parsed, analyzed sql.Node) func() {
+	if e.ResultCache == nil {
+		return nil
+	}
+
+	switch parsed.(type) {
+	case *plan.InsertInto, *plan.Update, *plan.DeleteFrom, *plan.Truncate:
+		tables := queriedTables(analyzed)
+		return func() {
+			for _, t := range tables {
+				e.ResultCache.InvalidateTable(t)
+			}
+		}
+	case *plan.CreateTable, *plan.DropTable, *plan.RenameTable, *plan.AddColumn, *plan.DropColumn,
+		*plan.RenameColumn, *plan.ModifyColumn, *plan.CreateView, *plan.DropView, *plan.CreateIndex,
+		*plan.DropIndex, *plan.AlterIndex, *plan.CreateTrigger, *plan.DropTrigger, *plan.CreateForeignKey,
+		*plan.DropForeignKey, *plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint, *plan.BeginEndBlock:
+		return e.ResultCache.Clear
+	default:
+		return nil
+	}
+}
+
+// onCloseRowIter calls a function after its wrapped iterator has been closed.
+type onCloseRowIter struct {
+	sql.RowIter
+	onClose func()
+}
+
+func (i *onCloseRowIter) Close() error {
+	err := i.RowIter.Close()
+	i.onClose()
+	return err
+}
@@ -0,0 +1,101 @@
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// schemaChanges returns the databases and tables whose definition the analyzed statement given changes, or nil if it
+// isn't a DDL statement. Objects with no table only change their database, like the triggers.
+func schemaChanges(ctx *sql.Context, n sql.Node) []sql.SchemaObject {
+	if qp, ok := n.(*plan.QueryProcess); ok {
+		n = qp.Child
+	}
+
+	tables := func(db sql.Database, names ...string) []sql.SchemaObject {
+		objects := make([]sql.SchemaObject, len(names))
+		for i, name := range names {
+			objects[i] = sql.SchemaObject{Database: db.Name(), Table: name}
+		}
+		return objects
+	}
+
+	switch n := n.(type) {
+	case *plan.CreateTable:
+		return tables(n.Database(), n.Name())
+	case *plan.DropTable:
+		return tables(n.Database(), n.TableNames()...)
+	case *plan.RenameTable:
+		return tables(n.Database(), append(n.OldNames(), n.NewNames()...)...)
+	case *plan.AddColumn:
+		return tables(n.Database(), n.TableName())
+	case *plan.DropColumn:
+		return tables(n.Database(), n.TableName())
+	case *plan.RenameColumn:
+		return tables(n.Database(), n.TableName())
+	case *plan.ModifyColumn:
+		return tables(n.Database(), n.TableName())
+	case *plan.CreateView:
+		return tables(n.Database(), n.Name)
+	case *plan.DropView:
+		var objects []sql.SchemaObject
+		for _, child := range n.Children() {
+			if dv, ok := child.(*plan.SingleDropView); ok {
+				objects = append(objects, tables(dv.Database(), dv.ViewName())...)
+			}
+		}
+		return objects
+	case *plan.CreateIndex:
+		return resolvedTableObjects(ctx, n.CurrentDatabase, n.Table)
+	case *plan.DropIndex:
+		return resolvedTableObjects(ctx, n.CurrentDatabase, n.Table)
+	case *plan.AlterIndex:
+		return resolvedTableObjects(ctx, "", n.Table)
+	case *plan.CreateTrigger:
+		// Triggers change the plans of the statements that write their table
+		return resolvedTableObjects(ctx, n.Database().Name(), n.Table)
+	case *plan.DropTrigger:
+		return []sql.SchemaObject{{Database: n.Database().Name()}}
+	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint:
+		return resolvedTableObjects(ctx, "", n.Children()...)
+	default:
+		return nil
+	}
+}
+
+// resolvedTableObjects returns the tables the nodes given resolved to. Tables whose database isn't known are in the
+// database given, or in the current one if it's empty.
+func resolvedTableObjects(ctx *sql.Context, db string, nodes ...sql.Node) []sql.SchemaObject {
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+
+	var objects []sql.SchemaObject
+	for _, n := range nodes {
+		plan.Inspect(n, func(node sql.Node) bool {
+			if rt, ok := node.(*plan.ResolvedTable); ok {
+				o := sql.SchemaObject{Database: rt.Database, Table: rt.Name()}
+				if o.Database == "" {
+					o.Database = db
+				}
+				objects = append(objects, o)
+			}
+			return true
+		})
+	}
+	return objects
+}
+
+// bumpSchemaVersions returns a function that bumps the schema versions of the objects given, for after the statement
+// that changes them.
+func (e *Engine) bumpSchemaVersions(objects []sql.SchemaObject) func() {
+	return func() {
+		for _, o := range objects {
+			if o.Table == "" {
+				e.Catalog.BumpSchemaVersion(o.Database)
+			} else {
+				e.Catalog.BumpSchemaVersion(o.Database, o.Table)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/dolthub/vitess/go/mysql"
@@ -70,13 +71,36 @@ func (s *SessionManager) nextPid() uint64 {
 // NewSession creates a Session for the given connection and saves it to
 // session pool.
 func (s *SessionManager) NewSession(ctx context.Context, conn *mysql.Conn) error {
-	var err error
-
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.sessions[conn.ConnectionID], s.idxRegs[conn.ConnectionID], s.viewRegs[conn.ConnectionID], err = s.builder(ctx, conn, s.addr)
+	sess, ir, vr, err := s.builder(ctx, conn, s.addr)
+	if err != nil {
+		return err
+	}
+	if err := setConnectionCharacterSet(ctx, sess, conn); err != nil {
+		return err
+	}
+
+	s.sessions[conn.ConnectionID], s.idxRegs[conn.ConnectionID], s.viewRegs[conn.ConnectionID] = sess, ir, vr
+	return nil
+}
 
-	return err
+// setConnectionCharacterSet sets the character sets of the client, the connection and the results of the session given
+// to the one the client of the connection declared in its handshake, with the collation of the connection, like MySQL
+// does. Sessions keep the default ones if it isn't known.
+func setConnectionCharacterSet(ctx context.Context, sess sql.Session, conn *mysql.Conn) error {
+	collation, ok := sql.CollationFromID(int64(conn.CharacterSet))
+	if !ok {
+		return nil
+	}
+
+	charset := collation.CharacterSet().String()
+	for _, name := range []string{"character_set_client", "character_set_connection", "character_set_results"} {
+		if err := sess.Set(ctx, name, sql.LongText, charset); err != nil {
+			return err
+		}
+	}
+	return sess.Set(ctx, "collation_connection", sql.LongText, collation.String())
 }
 
 func (s *SessionManager) SetDB(conn *mysql.Conn, db string) error {
@@ -113,8 +137,11 @@ func (s *SessionManager) getOrCreateSession(ctx context.Context, conn *mysql.Con
 	if !ok {
 		var err error
 		sess, ir, vr, err = s.builder(ctx, conn, s.addr)
-
+		if err == nil {
+			err = setConnectionCharacterSet(ctx, sess, conn)
+		}
 		if err != nil {
+			s.mu.Unlock()
 			return nil, nil, nil, err
 		}
 
@@ -151,6 +178,30 @@ func (s *SessionManager) NewContextWithQuery(conn *mysql.Conn, query string) (*s
 	return context, nil
 }
 
+var _ sql.CatalogChangeListener = (*SessionManager)(nil)
+
+// DatabaseAdded implements the sql.CatalogChangeListener interface.
+func (s *SessionManager) DatabaseAdded(sql.Database) {}
+
+// DatabaseRemoved implements the sql.CatalogChangeListener interface. Sessions using the database given no longer
+// have a current database, and the views and indexes of the database are removed from their registries.
+func (s *SessionManager) DatabaseRemoved(db sql.Database) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, sess := range s.sessions {
+		if strings.EqualFold(sess.GetCurrentDatabase(), db.Name()) {
+			sess.SetCurrentDatabase("")
+		}
+		if vr := s.viewRegs[id]; vr != nil {
+			vr.DeleteDatabase(db.Name())
+		}
+		if ir := s.idxRegs[id]; ir != nil {
+			ir.DeleteDatabaseIndexes(db.Name())
+		}
+	}
+}
+
 // CloseConn closes the connection in the session manager and all its
 // associated contexts, which are cancelled.
 func (s *SessionManager) CloseConn(conn *mysql.Conn) {
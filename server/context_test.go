@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestSessionManagerDatabaseRemoved(t *testing.T) {
+	require := require.New(t)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(memory.NewDatabase("a"))
+	catalog.AddDatabase(memory.NewDatabase("b"))
+
+	sm := NewSessionManager(
+		testSessionBuilder,
+		opentracing.NoopTracer{},
+		catalog.HasDB,
+		sql.NewMemoryManager(nil),
+		"foo",
+	)
+	catalog.AddCatalogChangeListener(sm)
+
+	conn1, conn2 := newConn(1), newConn(2)
+	require.NoError(sm.NewSession(context.Background(), conn1))
+	require.NoError(sm.NewSession(context.Background(), conn2))
+	require.NoError(sm.SetDB(conn1, "a"))
+	require.NoError(sm.SetDB(conn2, "b"))
+
+	ctx, err := sm.NewContext(conn1)
+	require.NoError(err)
+	require.NoError(ctx.ViewRegistry.Register("a", sql.NewView("v", nil, "")))
+
+	require.NoError(catalog.RemoveDatabase("a"))
+
+	ctx, err = sm.NewContext(conn1)
+	require.NoError(err)
+	require.Equal("", ctx.GetCurrentDatabase())
+	require.False(ctx.ViewRegistry.Exists("a", "v"))
+
+	ctx, err = sm.NewContext(conn2)
+	require.NoError(err)
+	require.Equal("b", ctx.GetCurrentDatabase())
+
+	require.Error(sm.SetDB(conn1, "a"))
+}
+
+func TestSessionManagerConnectionCharacterSet(t *testing.T) {
+	require := require.New(t)
+
+	sm := NewSessionManager(
+		testSessionBuilder,
+		opentracing.NoopTracer{},
+		func(string) bool { return true },
+		sql.NewMemoryManager(nil),
+		"foo",
+	)
+
+	// Clients declare the character set of their connection with the id of one of its collations
+	latin1, unknown := newConn(1), newConn(2)
+	latin1.CharacterSet = uint8(sql.Collation_latin1_swedish_ci.ID())
+	unknown.CharacterSet = 0
+
+	ctx, err := sm.NewContext(latin1)
+	require.NoError(err)
+	for _, name := range []string{"character_set_client", "character_set_connection", "character_set_results"} {
+		_, v := ctx.Get(name)
+		require.Equal("latin1", v, name)
+	}
+	_, v := ctx.Get("collation_connection")
+	require.Equal("latin1_swedish_ci", v)
+
+	ctx, err = sm.NewContext(unknown)
+	require.NoError(err)
+	_, v = ctx.Get("character_set_results")
+	require.Equal(sql.Collation_Default.CharacterSet().String(), v)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/dolthub/go-mysql-server/auth"
 	"github.com/dolthub/go-mysql-server/internal/sockstate"
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 var regKillCmd = regexp.MustCompile(`^kill (?:(query|connection) )?(\d+)$`)
@@ -50,6 +51,7 @@ type Handler struct {
 	c           map[uint32]conntainer
 	readTimeout time.Duration
 	lc          []*net.Conn
+	queryLog    QueryLogSink
 }
 
 // NewHandler creates a new Handler given a SQLe engine.
@@ -95,21 +97,74 @@ func (h *Handler) ComInitDB(c *mysql.Conn, schemaName string) error {
 	return h.sm.SetDB(c, schemaName)
 }
 
-func (h *Handler) ComPrepare(c *mysql.Conn, query string) ([]*query.Field, error) {
-	panic("prepared statements are not implemented")
+// ComPrepare prepares a statement, returning the fields of the rows it returns. The statements the client closed are
+// forgotten first, since closing them isn't reported to the handler. The types inferred for the parameters of the
+// statement are set in its PrepareData, which the listener decodes the values of the parameters with when the client
+// doesn't send their types. The listener describes every parameter as VARBINARY to the client, whatever its type.
+func (h *Handler) ComPrepare(c *mysql.Conn, query string) (fields []*query.Field, err error) {
+	logrus.Tracef("preparing query %s", query)
+
+	var ctx *sql.Context
+	defer func() {
+		err = castSQLError(h.e.TranslateError(ctx, err))
+	}()
+
+	ctx, err = h.sm.NewContextWithQuery(c, query)
+	if err != nil {
+		return nil, err
+	}
+
+	prepared := make(map[string]bool, len(c.PrepareData))
+	for _, data := range c.PrepareData {
+		prepared[data.PrepareStmt] = true
+	}
+	for _, q := range h.e.PreparedQueries(ctx) {
+		if !prepared[q] {
+			h.e.ClosePreparedQuery(ctx, q)
+		}
+	}
+
+	schema, err := h.e.PrepareQuery(ctx, query)
+	if err != nil {
+		logrus.Tracef("Error preparing query %s: %s", query, err)
+		return nil, err
+	}
+
+	if prepare, ok := c.PrepareData[c.StatementID]; ok && prepare.PrepareStmt == query {
+		params := h.e.PreparedQueryParams(ctx, query)
+		for i := range prepare.ParamsType {
+			if t, ok := params["v"+strconv.Itoa(i+1)]; ok {
+				prepare.ParamsType[i] = int32(t.Type())
+			}
+		}
+	}
+
+	return schemaToFields(schema, resultsCharacterSet(ctx)), nil
 }
 
+// ComStmtExecute executes a prepared statement with the values bound to its parameters.
 func (h *Handler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareData, callback func(*sqltypes.Result) error) error {
-	panic("prepared statements are not implemented")
+	bindings, err := bindingsToExprs(prepare.BindVars)
+	if err != nil {
+		return castSQLError(err)
+	}
+	return h.doQuery(c, prepare.PrepareStmt, bindings, callback)
 }
 
 func (h *Handler) ComResetConnection(c *mysql.Conn) {
-	// TODO: handle reset logic
+	// Resetting the connection closes its prepared statements, and rolls back its transaction
+	if ctx, err := h.sm.NewContextWithQuery(c, ""); err == nil {
+		h.e.ClosePreparedQueries(ctx)
+		if err := h.e.RollbackTransaction(ctx); err != nil {
+			logrus.Errorf("unable to roll back the transaction on connection reset: %s", err)
+		}
+	}
 }
 
 // ConnectionClosed reports that a connection has been closed.
 func (h *Handler) ConnectionClosed(c *mysql.Conn) {
 	ctx, _ := h.sm.NewContextWithQuery(c, "")
+	h.e.ClosePreparedQueries(ctx)
 	h.sm.CloseConn(c)
 
 	h.mu.Lock()
@@ -121,6 +176,15 @@ func (h *Handler) ConnectionClosed(c *mysql.Conn) {
 	if err := h.e.Catalog.UnlockTables(ctx, c.ConnectionID); err != nil {
 		logrus.Errorf("unable to unlock tables on session close: %s", err)
 	}
+	h.e.Catalog.ReleaseTableLocks(c.ConnectionID, false)
+	h.e.Catalog.UnsetSessionResourceGroup(c.ConnectionID)
+	h.e.WriteQueue.Release(c.ConnectionID)
+	if err := h.e.Catalog.TwoPhaseCommitter.Rollback(ctx); err != nil {
+		logrus.Errorf("unable to roll back uncommitted changes on session close: %s", err)
+	}
+	if err := h.e.RollbackTransaction(ctx); err != nil {
+		logrus.Errorf("unable to roll back the transaction on session close: %s", err)
+	}
 
 	logrus.Infof("ConnectionClosed: client %v", c.ConnectionID)
 }
@@ -130,17 +194,37 @@ func (h *Handler) ComQuery(
 	c *mysql.Conn,
 	query string,
 	callback func(*sqltypes.Result) error,
+) error {
+	return h.doQuery(c, query, nil, callback)
+}
+
+// doQuery executes a SQL query on the SQLe engine with the expressions given bound to its bind variables, which are
+// nil for queries that aren't prepared statements.
+func (h *Handler) doQuery(
+	c *mysql.Conn,
+	query string,
+	bindings map[string]sql.Expression,
+	callback func(*sqltypes.Result) error,
 ) (err error) {
 	logrus.Tracef("received query %s", query)
 
-	ctx, err := h.sm.NewContextWithQuery(c, query)
+	var ctx *sql.Context
+	defer func() {
+		err = castSQLError(h.e.TranslateError(ctx, err))
+	}()
+
+	ctx, err = h.sm.NewContextWithQuery(c, query)
 
 	if err != nil {
 		return err
 	}
 
+	// Queries are interrupted when they're done, so nothing keeps running if they fail or the connection is closed
+	// while they run, except for async queries, which keep running after they return
+	cancel := func() {}
 	if !h.e.Async(ctx, query) {
-		newCtx, cancel := context.WithCancel(ctx)
+		var newCtx context.Context
+		newCtx, cancel = context.WithCancel(ctx)
 		ctx = ctx.WithContext(newCtx)
 
 		defer cancel()
@@ -156,6 +240,12 @@ func (h *Handler) ComQuery(
 	}
 
 	start := time.Now()
+	var rowCount uint64
+	if h.queryLog != nil && isGeneralLogOn(ctx) {
+		defer func() {
+			h.logQuery(ctx, query, start, rowCount, err)
+		}()
+	}
 
 	// Parse the query independently of the engine for further analysis. The parser has its own parsing logic for
 	// statements not handled by vitess's parser, so even if there's a parse error here we still pass it to the engine
@@ -163,7 +253,7 @@ func (h *Handler) ComQuery(
 	// TODO: unify parser logic so we don't have to parse twice
 	parsedQuery, parseErr := sqlparser.Parse(query)
 
-	schema, rows, err := h.e.Query(ctx, query)
+	schema, rows, err := h.e.QueryWithBindings(ctx, query, bindings)
 	defer func() {
 		if q, ok := h.e.Auth.(*auth.Audit); ok {
 			q.Query(ctx, time.Since(start), err)
@@ -204,33 +294,62 @@ func (h *Handler) ComQuery(
 	defer timer.Stop()
 
 	// Read rows off the row iterator and send them to the row channel.
+	var reading sync.WaitGroup
+	reading.Add(1)
 	go func() {
+		defer reading.Done()
 		for {
+			row, err := rows.Next()
+			if err != nil {
+				select {
+				case errChan <- err:
+				case <-quit:
+				}
+				return
+			}
 			select {
+			case rowChan <- row:
 			case <-quit:
 				return
-			default:
-				row, err := rows.Next()
-				if err != nil {
-					errChan <- err
-					return
-				}
-				rowChan <- row
 			}
 		}
 	}()
 
+	// closeRows stops the goroutines and closes the row iterator. If the query hasn't read all of its rows, because
+	// it failed, timed out or its connection was closed, it's interrupted first, so the row being read, if any, isn't
+	// waited for.
+	var closed bool
+	closeRows := func(interrupt bool) error {
+		if closed {
+			return nil
+		}
+		closed = true
+
+		close(quit)
+		if interrupt {
+			cancel()
+		}
+		reading.Wait()
+		return rows.Close()
+	}
+	defer func() {
+		if cerr := closeRows(true); err == nil {
+			err = cerr
+		}
+	}()
+
 	go h.pollForClosedConnection(nc, errChan, quit, query)
 
+	results := resultsCharacterSet(ctx)
+
 rowLoop:
 	for {
 		if r == nil {
-			r = &sqltypes.Result{Fields: schemaToFields(schema)}
+			r = &sqltypes.Result{Fields: schemaToFields(schema, results)}
 		}
 
 		if r.RowsAffected == rowsBatch {
 			if err := callback(r); err != nil {
-				close(quit)
 				return err
 			}
 
@@ -246,7 +365,6 @@ rowLoop:
 			}
 
 			logrus.Tracef("got error %s", err.Error())
-			close(quit)
 			return err
 		case row := <-rowChan:
 			if isOkResult(row) {
@@ -254,33 +372,31 @@ rowLoop:
 					panic("Got OkResult mixed with RowResult")
 				}
 				r = resultFromOkResult(row[0].(sql.OkResult))
+				rowCount = r.RowsAffected
 
 				logrus.Tracef("returning OK result %v", r)
 				break rowLoop
 			}
 
-			outputRow, err := rowToSQL(schema, row)
+			outputRow, err := rowToSQL(schema, row, results)
 			if err != nil {
-				close(quit)
 				return err
 			}
 
 			logrus.Tracef("returning result row %s", outputRow)
 			r.Rows = append(r.Rows, outputRow)
 			r.RowsAffected++
+			rowCount++
 		case <-timer.C:
 			if h.readTimeout != 0 {
 				// Cancel and return so Vitess can call the CloseConnection callback
 				logrus.Tracef("got timeout")
-				close(quit)
 				return ErrRowTimeout.New()
 			}
 		}
 		timer.Reset(waitTime)
 	}
-	close(quit)
-
-	if err := rows.Close(); err != nil {
+	if err := closeRows(false); err != nil {
 		return err
 	}
 
@@ -304,6 +420,134 @@ rowLoop:
 	return callback(r)
 }
 
+// The codes and states of the errors vitess doesn't define.
+const (
+	erColumnAccessDenied = 1143
+	ssColumnAccessDenied = "42000"
+
+	erXAErNotA     = 1397
+	erXAErInval    = 1398
+	erXAErRMFail   = 1399
+	erXAErOutside  = 1400
+	erXARBRollback = 1402
+	erXAErDupID    = 1440
+	ssXAErNotA     = "XAE04"
+	ssXAErInval    = "XAE05"
+	ssXAErRMFail   = "XAE07"
+	ssXAErOutside  = "XAE09"
+	ssXARBRollback = "XA100"
+	ssXAErDupID    = "XAE08"
+
+	erCannotUser         = 1396
+	erNotValidPassword   = 1819
+	erMustChangePassword = 1820
+
+	erNoReferencedRow2   = 1452
+	erTruncateIllegalFK  = 1701
+	erFKCannotDropParent = 3730
+
+	erColumnCheckConstraintReferencesOtherColumn = 3813
+	erCheckConstraintViolated                    = 3819
+	erCheckConstraintNotFound                    = 3821
+	erCheckConstraintDupName                     = 3822
+
+	erWarnDataOutOfRange  = 1264
+	erWarnDataTruncated   = 1265
+	ssTruncatedWrongValue = "22007"
+	ssWarning             = "01000"
+
+	erQueryTimeout = 3024
+)
+
+// castSQLError returns the MySQL error with the code and state of the error given, for the errors of the engine that
+// clients tell apart by their code. Other errors are returned as they are, and reported as unknown errors.
+func castSQLError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ce, ok := err.(*sql.ConditionError); ok {
+		return mysql.NewSQLError(ce.Code, ce.SQLState, "%s", ce.Message)
+	}
+
+	switch {
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		return mysql.NewSQLError(mysql.ERQueryInterrupted, mysql.SSUnknownSQLState, "Query execution was interrupted")
+	case sql.ErrUniqueKeyViolation.Is(err):
+		return mysql.NewSQLError(mysql.ERDupEntry, mysql.SSDupKey, "%s", err.Error())
+	case sql.ErrForeignKeyChildViolation.Is(err):
+		return mysql.NewSQLError(erNoReferencedRow2, mysql.SSDupKey, "%s", err.Error())
+	case sql.ErrForeignKeyParentViolation.Is(err):
+		return mysql.NewSQLError(mysql.ERRowIsReferenced2, mysql.SSDupKey, "%s", err.Error())
+	case sql.ErrTruncateReferencedTable.Is(err):
+		return mysql.NewSQLError(erTruncateIllegalFK, "42000", "%s", err.Error())
+	case sql.ErrDropReferencedTable.Is(err):
+		return mysql.NewSQLError(erFKCannotDropParent, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrCheckConstraintViolated.Is(err):
+		return mysql.NewSQLError(erCheckConstraintViolated, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrCheckConstraintNotFound.Is(err):
+		return mysql.NewSQLError(erCheckConstraintNotFound, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrCheckConstraintDuplicateName.Is(err):
+		return mysql.NewSQLError(erCheckConstraintDupName, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrCheckConstraintOtherColumn.Is(err):
+		return mysql.NewSQLError(erColumnCheckConstraintReferencesOtherColumn, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrCheckConstraintUnknownColumn.Is(err):
+		return mysql.NewSQLError(mysql.ERBadFieldError, mysql.SSBadFieldError, "%s", err.Error())
+	case sql.ErrIncorrectTemporalValue.Is(err):
+		return mysql.NewSQLError(mysql.ERTruncatedWrongValue, ssTruncatedWrongValue, "%s", err.Error())
+	case sql.ErrIncorrectValue.Is(err):
+		return mysql.NewSQLError(mysql.ERTruncatedWrongValueForField, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrValueOutOfRange.Is(err):
+		return mysql.NewSQLError(erWarnDataOutOfRange, mysql.SSDataOutOfRange, "%s", err.Error())
+	case sql.ErrDataTruncated.Is(err):
+		return mysql.NewSQLError(erWarnDataTruncated, ssWarning, "%s", err.Error())
+	case sql.ErrDataTooLong.Is(err):
+		return mysql.NewSQLError(mysql.ERDataTooLong, mysql.SSDataTooLong, "%s", err.Error())
+	case sql.ErrNoTablesUsed.Is(err):
+		return mysql.NewSQLError(mysql.ERNoTablesUsed, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrTooManyUserQueries.Is(err):
+		return mysql.NewSQLError(mysql.ERTooManyUserConnections, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrTooManyConcurrentQueries.Is(err):
+		return mysql.NewSQLError(mysql.ERConCount, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrGlobalVariable.Is(err):
+		return mysql.NewSQLError(mysql.ERGlobalVariable, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrReadOnly.Is(err):
+		return mysql.NewSQLError(mysql.EROptionPreventsStatement, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrLockWaitTimeout.Is(err):
+		return mysql.NewSQLError(mysql.ERLockWaitTimeout, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrTableNotLocked.Is(err):
+		return mysql.NewSQLError(mysql.ERTableNotLocked, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrTableNotLockedForWrite.Is(err):
+		return mysql.NewSQLError(mysql.ERTableNotLockedForWrite, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrLockDeadlock.Is(err):
+		return mysql.NewSQLError(mysql.ERLockDeadlock, mysql.SSLockDeadlock, "%s", err.Error())
+	case sql.ErrColumnAccessDenied.Is(err):
+		return mysql.NewSQLError(erColumnAccessDenied, ssColumnAccessDenied, "%s", err.Error())
+	case sql.ErrXANotA.Is(err):
+		return mysql.NewSQLError(erXAErNotA, ssXAErNotA, "%s", err.Error())
+	case sql.ErrXAInval.Is(err):
+		return mysql.NewSQLError(erXAErInval, ssXAErInval, "%s", err.Error())
+	case sql.ErrXARMFail.Is(err):
+		return mysql.NewSQLError(erXAErRMFail, ssXAErRMFail, "%s", err.Error())
+	case sql.ErrXAOutside.Is(err):
+		return mysql.NewSQLError(erXAErOutside, ssXAErOutside, "%s", err.Error())
+	case sql.ErrXARBRollback.Is(err):
+		return mysql.NewSQLError(erXARBRollback, ssXARBRollback, "%s", err.Error())
+	case sql.ErrXADupID.Is(err):
+		return mysql.NewSQLError(erXAErDupID, ssXAErDupID, "%s", err.Error())
+	case sql.ErrUserOperationFailed.Is(err):
+		return mysql.NewSQLError(erCannotUser, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrNotValidPassword.Is(err):
+		return mysql.NewSQLError(erNotValidPassword, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrMustChangePassword.Is(err):
+		return mysql.NewSQLError(erMustChangePassword, mysql.SSUnknownSQLState, "%s", err.Error())
+	case sql.ErrMaxExecutionTimeExceeded.Is(err):
+		return mysql.NewSQLError(erQueryTimeout, mysql.SSUnknownSQLState, "%s", err.Error())
+	default:
+		return err
+	}
+}
+
 // Periodically polls the connection socket to determine if it is has been closed by the client, sending an error on
 // the supplied error channel if it has. Meant to be run in a separate goroutine from the query handler routine.
 // Returns immediately on platforms that can't support TCP socket checks.
@@ -317,7 +561,7 @@ func (h *Handler) pollForClosedConnection(nc conntainer, errChan chan error, qui
 	inode, err := sockstate.GetConnInode(tcpConn)
 	if err != nil || inode == 0 {
 		if !sockstate.ErrSocketCheckNotImplemented.Is(err) {
-			errChan <- err
+			sendError(errChan, quit, err)
 		}
 		return
 	}
@@ -339,10 +583,10 @@ func (h *Handler) pollForClosedConnection(nc conntainer, errChan chan error, qui
 		switch st {
 		case sockstate.Broken:
 			logrus.Tracef("socket state is broken, returning error")
-			errChan <- ErrConnectionWasClosed.New()
+			sendError(errChan, quit, ErrConnectionWasClosed.New())
 			return
 		case sockstate.Error:
-			errChan <- err
+			sendError(errChan, quit, err)
 			return
 		default: // Established
 			// (juanjux) this check is not free, each iteration takes about 9 milliseconds to run on my machine
@@ -352,6 +596,14 @@ func (h *Handler) pollForClosedConnection(nc conntainer, errChan chan error, qui
 	}
 }
 
+// sendError sends an error to the query handler routine, unless it has already stopped waiting for it.
+func sendError(errChan chan error, quit chan struct{}, err error) {
+	select {
+	case errChan <- err:
+	case <-quit:
+	}
+}
+
 func isSessionAutocommit(ctx *sql.Context) bool {
 	typ, autoCommitSessionVar := ctx.Get(sql.AutoCommitSessionVar)
 	autoCommit := false
@@ -459,7 +711,53 @@ func (h *Handler) handleKill(conn *mysql.Conn, query string) (bool, error) {
 	return true, nil
 }
 
-func rowToSQL(s sql.Schema, row sql.Row) ([]sqltypes.Value, error) {
+// bindingsToExprs converts the values bound to the parameters of a prepared statement to the literals bound to its
+// bind variables: numbers to literals of their type, and any other value to a string literal, like the values written
+// in a query. Parameters whose values were reset are left unbound.
+func bindingsToExprs(bindings map[string]*query.BindVariable) (map[string]sql.Expression, error) {
+	exprs := make(map[string]sql.Expression, len(bindings))
+	for name, bv := range bindings {
+		if bv == nil {
+			continue
+		}
+
+		v, err := sqltypes.BindVariableToValue(bv)
+		if err != nil {
+			return nil, err
+		}
+
+		// The values point into the buffer of the packet they were read from, which the connection reuses for the
+		// next packets, so they're copied before they're kept in the literals or in errors
+		s := string(v.Raw())
+		switch {
+		case v.IsNull():
+			exprs[name] = expression.NewLiteral(nil, sql.Null)
+		case v.IsSigned():
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			exprs[name] = expression.NewLiteral(i, sql.Int64)
+		case v.IsUnsigned():
+			u, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			exprs[name] = expression.NewLiteral(u, sql.Uint64)
+		case v.IsFloat():
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, err
+			}
+			exprs[name] = expression.NewLiteral(f, sql.Float64)
+		default:
+			exprs[name] = expression.NewLiteral(s, sql.LongText)
+		}
+	}
+	return exprs, nil
+}
+
+func rowToSQL(s sql.Schema, row sql.Row, results sql.CharacterSet) ([]sqltypes.Value, error) {
 	o := make([]sqltypes.Value, len(row))
 	var err error
 	for i, v := range row {
@@ -468,27 +766,121 @@ func rowToSQL(s sql.Schema, row sql.Row) ([]sqltypes.Value, error) {
 			continue
 		}
 
-		o[i], err = s[i].Type.SQL(v)
+		if bt, ok := s[i].Type.(sql.BitType); ok {
+			o[i], err = bitToSQL(bt, v)
+		} else {
+			o[i], err = s[i].Type.SQL(v)
+		}
 		if err != nil {
 			return nil, err
 		}
+
+		if collation, convert := columnCollation(s[i].Type, results); convert {
+			o[i] = sqltypes.MakeTrusted(o[i].Type(), collation.CharacterSet().EncodeString(o[i].ToString()))
+		}
 	}
 
 	return o, nil
 }
 
-func schemaToFields(s sql.Schema) []*query.Field {
+// bitToSQL returns the value given of the BIT type given as MySQL sends it to clients: its bits in big-endian order, in
+// as many bytes as the type needs.
+func bitToSQL(bt sql.BitType, v interface{}) (sqltypes.Value, error) {
+	v, err := bt.Convert(v)
+	if err != nil {
+		return sqltypes.Value{}, err
+	}
+
+	bits := v.(uint64)
+	raw := make([]byte, (int(bt.NumberOfBits())+7)/8)
+	for i := len(raw) - 1; i >= 0; i-- {
+		raw[i] = byte(bits)
+		bits >>= 8
+	}
+	return sqltypes.MakeTrusted(sqltypes.Bit, raw), nil
+}
+
+// fieldDecimals returns the number of decimals MySQL sends in the definitions of the columns of the type given, which
+// prepared statement clients need to decode the fractional seconds of temporal values. Temporal types keep
+// microseconds, and floats have no fixed number of decimals.
+func fieldDecimals(typ sql.Type) uint32 {
+	if dt, ok := typ.(sql.DecimalType); ok {
+		return uint32(dt.Scale())
+	}
+	switch typ.Type() {
+	case sqltypes.Datetime, sqltypes.Timestamp, sqltypes.Time:
+		return 6
+	case sqltypes.Float32, sqltypes.Float64:
+		return 0x1f
+	default:
+		return 0
+	}
+}
+
+// resultsCharacterSet returns the character set of character_set_results in the session of the context given, which
+// the strings sent to the client are converted to, or an empty one if it's NULL, in which case they're sent in the
+// character set of their column. Strings are sent in UTF-8 if the character set isn't known.
+func resultsCharacterSet(ctx *sql.Context) sql.CharacterSet {
+	_, v := ctx.Get("character_set_results")
+	name, ok := v.(string)
+	if !ok {
+		return ""
+	}
+	charset, err := sql.ParseCharacterSet(strings.ToLower(name))
+	if err != nil {
+		return sql.Collation_Default.CharacterSet()
+	}
+	return charset
+}
+
+// columnCollation returns the collation the values of a column of the type given are sent to the client in, with the
+// character set of the results given, and whether they're converted to it from UTF-8, which strings are kept in.
+// Values that aren't strings and binary strings are sent as they are, in the binary collation. Strings the character
+// set of the results can't be converted to are sent in UTF-8, in the default collation.
+func columnCollation(typ sql.Type, results sql.CharacterSet) (sql.Collation, bool) {
+	st, ok := typ.(interface{ Collation() sql.Collation })
+	if !ok || st.Collation().CharacterSet() == sql.CharacterSet_binary {
+		return sql.Collation_binary, false
+	}
+
+	collation := st.Collation()
+	if results != "" && results != collation.CharacterSet() {
+		collation = results.DefaultCollation()
+	}
+
+	switch charset := collation.CharacterSet(); {
+	case !charset.CanEncode():
+		return sql.Collation_Default, false
+	case charset == sql.CharacterSet_binary, charset == sql.CharacterSet_utf8mb4, charset == sql.CharacterSet_utf8mb3,
+		charset == sql.CharacterSet_utf8:
+		return collation, false
+	default:
+		return collation, true
+	}
+}
+
+func schemaToFields(s sql.Schema, results sql.CharacterSet) []*query.Field {
 	fields := make([]*query.Field, len(s))
 	for i, c := range s {
-		var charset uint32 = mysql.CharacterSetUtf8
-		if sql.IsBlob(c.Type) {
-			charset = mysql.CharacterSetBinary
+		collation, _ := columnCollation(c.Type, results)
+		charset := uint32(collation.ID())
+
+		// Non-zero flags replace the ones of the type, so start from those
+		_, flags := sqltypes.TypeToMySQL(c.Type.Type())
+		if !c.Nullable && c.Type != sql.Null {
+			flags |= int64(query.MySqlFlag_NOT_NULL_FLAG)
+		}
+		if c.PrimaryKey {
+			flags |= int64(query.MySqlFlag_PRI_KEY_FLAG)
 		}
 
 		fields[i] = &query.Field{
-			Name:    c.Name,
-			Type:    c.Type.Type(),
-			Charset: charset,
+			Name:     c.Name,
+			Type:     c.Type.Type(),
+			Table:    c.Source,
+			Charset:  charset,
+			Flags:    uint32(flags),
+			Decimals: fieldDecimals(c.Type),
 		}
 	}
 
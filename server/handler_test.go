@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 
 	sqle "github.com/dolthub/go-mysql-server"
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 func TestHandlerOutput(t *testing.T) {
@@ -203,20 +205,72 @@ func assertNoConnProcesses(t *testing.T, e *sqle.Engine, conn uint32) {
 func TestSchemaToFields(t *testing.T) {
 	require := require.New(t)
 
+	latin1 := sql.MustCreateString(sqltypes.VarChar, 10, sql.Collation_latin1_swedish_ci)
 	schema := sql.Schema{
-		{Name: "foo", Type: sql.Blob},
-		{Name: "bar", Type: sql.Text},
-		{Name: "baz", Type: sql.Int64},
+		{Name: "foo", Type: sql.Blob, Nullable: true},
+		{Name: "bar", Type: sql.Text, Source: "t", Nullable: true},
+		{Name: "baz", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "qux", Type: sql.Uint32},
+		{Name: "NULL", Type: sql.Null},
+		{Name: "dt", Type: sql.Datetime, Nullable: true},
+		{Name: "dec", Type: sql.MustCreateDecimalType(10, 3), Nullable: true},
+		{Name: "f", Type: sql.Float64, Nullable: true},
+		{Name: "l", Type: latin1, Nullable: true},
 	}
 
+	notNull := uint32(query.MySqlFlag_NOT_NULL_FLAG)
+	utf8mb4 := uint32(sql.Collation_utf8mb4_0900_ai_ci.ID())
 	expected := []*query.Field{
-		{Name: "foo", Type: query.Type_BLOB, Charset: mysql.CharacterSetBinary},
-		{Name: "bar", Type: query.Type_TEXT, Charset: mysql.CharacterSetUtf8},
-		{Name: "baz", Type: query.Type_INT64, Charset: mysql.CharacterSetUtf8},
+		{Name: "foo", Type: query.Type_BLOB, Charset: mysql.CharacterSetBinary, Flags: uint32(query.MySqlFlag_BINARY_FLAG)},
+		{Name: "bar", Type: query.Type_TEXT, Table: "t", Charset: utf8mb4},
+		{Name: "baz", Type: query.Type_INT64, Table: "t", Charset: mysql.CharacterSetBinary, Flags: notNull | uint32(query.MySqlFlag_PRI_KEY_FLAG)},
+		{Name: "qux", Type: query.Type_UINT32, Charset: mysql.CharacterSetBinary, Flags: notNull | uint32(query.MySqlFlag_UNSIGNED_FLAG)},
+		{Name: "NULL", Type: query.Type_NULL_TYPE, Charset: mysql.CharacterSetBinary, Flags: uint32(query.MySqlFlag_BINARY_FLAG)},
+		{Name: "dt", Type: query.Type_DATETIME, Charset: mysql.CharacterSetBinary, Flags: uint32(query.MySqlFlag_BINARY_FLAG), Decimals: 6},
+		{Name: "dec", Type: query.Type_DECIMAL, Charset: mysql.CharacterSetBinary, Decimals: 3},
+		{Name: "f", Type: query.Type_FLOAT64, Charset: mysql.CharacterSetBinary, Decimals: 0x1f},
+		{Name: "l", Type: query.Type_VARCHAR, Charset: utf8mb4},
 	}
 
-	fields := schemaToFields(schema)
+	fields := schemaToFields(schema, sql.CharacterSet_utf8mb4)
 	require.Equal(expected, fields)
+
+	// Strings are tagged with the character set of the results, or the one of their column if it's NULL
+	latin1ID := uint32(sql.Collation_latin1_swedish_ci.ID())
+	fields = schemaToFields(schema, sql.CharacterSet_latin1)
+	require.Equal([]uint32{mysql.CharacterSetBinary, latin1ID, latin1ID}, []uint32{fields[0].Charset, fields[1].Charset, fields[8].Charset})
+	fields = schemaToFields(schema, "")
+	require.Equal([]uint32{mysql.CharacterSetBinary, utf8mb4, latin1ID}, []uint32{fields[0].Charset, fields[1].Charset, fields[8].Charset})
+}
+
+func TestRowToSQL(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{
+		{Name: "m", Type: sql.Int24},
+		{Name: "b", Type: sql.MustCreateBitType(10)},
+		{Name: "n", Type: sql.Int64, Nullable: true},
+		{Name: "s", Type: sql.Text},
+		{Name: "v", Type: sql.LongBlob},
+	}
+	row := sql.NewRow(int32(-8000000), uint64(0x2aa), nil, "é€✓", "é")
+
+	values, err := rowToSQL(schema, row, sql.CharacterSet_utf8mb4)
+	require.NoError(err)
+	require.Equal([]sqltypes.Value{
+		sqltypes.MakeTrusted(sqltypes.Int24, []byte("-8000000")),
+		sqltypes.MakeTrusted(sqltypes.Bit, []byte{0x02, 0xaa}),
+		sqltypes.NULL,
+		sqltypes.MakeTrusted(sqltypes.Text, []byte("é€✓")),
+		sqltypes.MakeTrusted(sqltypes.Blob, []byte("é")),
+	}, values)
+
+	// Strings are converted to the character set of the results, with a ? for the characters it doesn't have, but
+	// binary strings aren't
+	values, err = rowToSQL(schema, row, sql.CharacterSet_latin1)
+	require.NoError(err)
+	require.Equal(sqltypes.MakeTrusted(sqltypes.Text, []byte{0xe9, 0x80, '?'}), values[3])
+	require.Equal(sqltypes.MakeTrusted(sqltypes.Blob, []byte("é")), values[4])
 }
 
 func TestHandlerTimeout(t *testing.T) {
@@ -254,6 +308,8 @@ func TestHandlerTimeout(t *testing.T) {
 		return nil
 	})
 	require.EqualError(err, "row read wait bigger than connection timeout")
+	// The query that timed out is interrupted and closed, instead of being left running
+	assertNoConnProcesses(t, e, connTimeout.ConnectionID)
 
 	err = timeOutHandler.ComQuery(connTimeout, "SELECT SLEEP(0.5)", func(res *sqltypes.Result) error {
 		return nil
@@ -304,3 +360,301 @@ func TestOkClosedConnection(t *testing.T) {
 	})
 	require.NoError(err)
 }
+
+func TestHandlerDuplicateEntry(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+	conn := newConn(1)
+	handler.NewConnection(conn)
+	require.NoError(handler.ComInitDB(conn, "test"))
+
+	query := func(q string) error {
+		return handler.ComQuery(conn, q, func(*sqltypes.Result) error { return nil })
+	}
+	require.NoError(query("CREATE TABLE users (id BIGINT PRIMARY KEY, email VARCHAR(20))"))
+	require.NoError(query("INSERT INTO users VALUES (1, 'a@b.c')"))
+
+	err := query("INSERT INTO users VALUES (1, 'd@e.f')")
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(ok, "%T: %v", err, err)
+	require.Equal(mysql.ERDupEntry, sqlErr.Number())
+	require.Equal(mysql.SSDupKey, sqlErr.SQLState())
+	require.Contains(sqlErr.Message, "Duplicate entry '1' for key 'PRIMARY'")
+}
+
+func TestHandlerErrorTranslator(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+	e.AddErrorTranslator(func(ctx *sql.Context, err error) error {
+		if sql.ErrTableNotFound.Is(err) {
+			return mysql.NewSQLError(mysql.ERNoSuchTable, mysql.SSUnknownSQLState, "/data/%s: %s", ctx.GetCurrentDatabase(), err)
+		}
+		return nil
+	})
+	e.AddErrorTranslator(func(ctx *sql.Context, err error) error {
+		if sqlErr, ok := err.(*mysql.SQLError); ok && strings.HasPrefix(sqlErr.Message, "/data/") {
+			return mysql.NewSQLError(sqlErr.Number(), sqlErr.SQLState(), "table not found")
+		}
+		return nil
+	})
+
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+	conn := newConn(1)
+	handler.NewConnection(conn)
+	require.NoError(handler.ComInitDB(conn, "test"))
+
+	query := func(q string) error {
+		return handler.ComQuery(conn, q, func(*sqltypes.Result) error { return nil })
+	}
+
+	err := query("SELECT * FROM secret_table")
+	sqlErr, ok := err.(*mysql.SQLError)
+	require.True(ok, "%T: %v", err, err)
+	require.Equal(mysql.ERNoSuchTable, sqlErr.Number())
+	require.Equal("table not found", sqlErr.Message)
+
+	err = query("SELECT unknown_column FROM test")
+	require.Error(err)
+	require.True(sql.ErrColumnNotFound.Is(err), "%T: %v", err, err)
+
+	require.NoError(query("SELECT * FROM test"))
+}
+
+func TestHandlerPreparedStatements(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+	conn := newConn(1)
+	conn.PrepareData = make(map[uint32]*mysql.PrepareData)
+	handler.NewConnection(conn)
+	require.NoError(handler.ComInitDB(conn, "test"))
+
+	// prepare stores the statement in the connection before preparing it, like the listener does
+	prepare := func(id uint32, q string) ([]*query.Field, error) {
+		params := strings.Count(q, "?")
+		conn.StatementID = id
+		conn.PrepareData[id] = &mysql.PrepareData{StatementID: id, PrepareStmt: q, ParamsCount: uint16(params), ParamsType: make([]int32, params)}
+		return handler.ComPrepare(conn, q)
+	}
+	execute := func(id uint32, bindVars map[string]*query.BindVariable) (*sqltypes.Result, error) {
+		prepare := conn.PrepareData[id]
+		prepare.BindVars = bindVars
+		var result *sqltypes.Result
+		err := handler.ComStmtExecute(conn, prepare, func(r *sqltypes.Result) error {
+			if result == nil {
+				result = r
+			} else {
+				result.Rows = append(result.Rows, r.Rows...)
+			}
+			return nil
+		})
+		return result, err
+	}
+
+	fields, err := prepare(1, "CREATE TABLE users (id BIGINT PRIMARY KEY, email VARCHAR(20))")
+	require.NoError(err)
+	require.Empty(fields)
+	_, err = execute(1, nil)
+	require.NoError(err)
+
+	fields, err = prepare(2, "INSERT INTO users VALUES (?, ?)")
+	require.NoError(err)
+	require.Empty(fields)
+	require.Equal([]int32{int32(query.Type_INT64), int32(query.Type_VARCHAR)}, conn.PrepareData[2].ParamsType)
+	for _, bindVars := range []map[string]*query.BindVariable{
+		{"v1": sqltypes.Int64BindVariable(1), "v2": sqltypes.StringBindVariable("a@b.c")},
+		{"v1": sqltypes.Uint64BindVariable(2), "v2": sqltypes.NullBindVariable},
+		{"v1": sqltypes.Float64BindVariable(3), "v2": sqltypes.BytesBindVariable([]byte("d@e.f"))},
+	} {
+		result, err := execute(2, bindVars)
+		require.NoError(err)
+		require.Equal(uint64(1), result.RowsAffected)
+	}
+
+	// The values bound are read from a buffer the connection reuses for the next packets
+	buf := []byte("hello")
+	result, err := execute(2, map[string]*query.BindVariable{
+		"v1": sqltypes.Int64BindVariable(4),
+		"v2": {Type: query.Type_VARCHAR, Value: buf},
+	})
+	require.NoError(err)
+	require.Equal(uint64(1), result.RowsAffected)
+	copy(buf, "lengt")
+	result, err = execute(2, map[string]*query.BindVariable{
+		"v1": sqltypes.Int64BindVariable(5),
+		"v2": {Type: query.Type_VARCHAR, Value: buf[:1]},
+	})
+	require.NoError(err)
+	require.Equal(uint64(1), result.RowsAffected)
+
+	fields, err = prepare(5, "SELECT email FROM users WHERE id >= ? ORDER BY id")
+	require.NoError(err)
+	result, err = execute(5, map[string]*query.BindVariable{"v1": sqltypes.Int64BindVariable(4)})
+	require.NoError(err)
+	require.Equal([][]sqltypes.Value{{sqltypes.NewVarChar("hello")}, {sqltypes.NewVarChar("l")}}, result.Rows)
+	delete(conn.PrepareData, 5)
+
+	// The columns of the selected expressions are named from the text of the statement
+	fields, err = prepare(6, "SELECT ? + 1")
+	require.NoError(err)
+	result, err = execute(6, map[string]*query.BindVariable{"v1": sqltypes.StringBindVariable("5")})
+	require.NoError(err)
+	require.Equal("? + 1", result.Fields[0].Name)
+	delete(conn.PrepareData, 6)
+
+	fields, err = prepare(3, "SELECT id, email FROM users WHERE id > ? ORDER BY id")
+	require.NoError(err)
+	require.Equal([]string{"id", "email"}, []string{fields[0].Name, fields[1].Name})
+	require.Equal(query.Type_INT64, fields[0].Type)
+	result, err = execute(3, map[string]*query.BindVariable{"v1": sqltypes.Int64BindVariable(1)})
+	require.NoError(err)
+	require.Equal([][]sqltypes.Value{
+		{sqltypes.NewInt64(2), sqltypes.NULL},
+		{sqltypes.NewInt64(3), sqltypes.NewVarChar("d@e.f")},
+		{sqltypes.NewInt64(4), sqltypes.NewVarChar("hello")},
+		{sqltypes.NewInt64(5), sqltypes.NewVarChar("l")},
+	}, result.Rows)
+
+	// Parameters reset or never sent fail the statement
+	_, err = execute(3, map[string]*query.BindVariable{"v1": nil})
+	require.True(expression.ErrUnboundBindVar.Is(err), "%T: %v", err, err)
+
+	ctx, err := handler.sm.NewContextWithQuery(conn, "")
+	require.NoError(err)
+	require.Len(e.PreparedQueries(ctx), 3)
+
+	// Statements closed by the client are forgotten when the next one is prepared
+	delete(conn.PrepareData, 1)
+	delete(conn.PrepareData, 2)
+	_, err = prepare(4, "SELECT * FROM missing")
+	require.Error(err)
+	require.Equal([]string{"SELECT id, email FROM users WHERE id > ? ORDER BY id"}, e.PreparedQueries(ctx))
+
+	handler.ConnectionClosed(conn)
+	require.Empty(e.PreparedQueries(ctx))
+}
+
+func TestCastQueryInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	for _, err := range []error{context.Canceled, context.DeadlineExceeded} {
+		sqlErr, ok := castSQLError(err).(*mysql.SQLError)
+		require.True(ok)
+		require.Equal(mysql.ERQueryInterrupted, sqlErr.Number())
+		require.Equal("Query execution was interrupted", sqlErr.Message)
+	}
+}
+
+func TestCastMaxExecutionTimeExceeded(t *testing.T) {
+	require := require.New(t)
+
+	sqlErr, ok := castSQLError(sql.ErrMaxExecutionTimeExceeded.New()).(*mysql.SQLError)
+	require.True(ok)
+	require.Equal(3024, sqlErr.Number())
+	require.Equal(mysql.SSUnknownSQLState, sqlErr.SQLState())
+}
+
+func TestCastForeignKeyErrors(t *testing.T) {
+	tests := []struct {
+		err   error
+		code  int
+		state string
+	}{
+		{sql.ErrForeignKeyChildViolation.New("mydb", "child", "CONSTRAINT `fk`"), 1452, mysql.SSDupKey},
+		{sql.ErrForeignKeyParentViolation.New("mydb", "child", "CONSTRAINT `fk`"), 1451, mysql.SSDupKey},
+		{sql.ErrTruncateReferencedTable.New("mydb", "child", "CONSTRAINT `fk`"), 1701, "42000"},
+		{sql.ErrDropReferencedTable.New("parent", "fk", "child"), 3730, mysql.SSUnknownSQLState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			require := require.New(t)
+			sqlErr, ok := castSQLError(tt.err).(*mysql.SQLError)
+			require.True(ok)
+			require.Equal(tt.code, sqlErr.Number())
+			require.Equal(tt.state, sqlErr.SQLState())
+			require.Equal(tt.err.Error(), sqlErr.Message)
+		})
+	}
+}
+
+func TestCastCheckConstraintErrors(t *testing.T) {
+	tests := []struct {
+		err   error
+		code  int
+		state string
+	}{
+		{sql.ErrCheckConstraintViolated.New("t_chk_1"), 3819, mysql.SSUnknownSQLState},
+		{sql.ErrCheckConstraintNotFound.New("c"), 3821, mysql.SSUnknownSQLState},
+		{sql.ErrCheckConstraintDuplicateName.New("c"), 3822, mysql.SSUnknownSQLState},
+		{sql.ErrCheckConstraintOtherColumn.New("t_chk_1"), 3813, mysql.SSUnknownSQLState},
+		{sql.ErrCheckConstraintUnknownColumn.New("b", "t_chk_1"), mysql.ERBadFieldError, mysql.SSBadFieldError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			require := require.New(t)
+			sqlErr, ok := castSQLError(tt.err).(*mysql.SQLError)
+			require.True(ok)
+			require.Equal(tt.code, sqlErr.Number())
+			require.Equal(tt.state, sqlErr.SQLState())
+			require.Equal(tt.err.Error(), sqlErr.Message)
+		})
+	}
+}
+
+func TestCastInvalidValueErrors(t *testing.T) {
+	tests := []struct {
+		err   error
+		code  int
+		state string
+	}{
+		{sql.ErrIncorrectTemporalValue.New("date", "0000-00-00", "d", 1), mysql.ERTruncatedWrongValue, "22007"},
+		{sql.ErrIncorrectValue.New("integer", "one", "i", 1), mysql.ERTruncatedWrongValueForField, mysql.SSUnknownSQLState},
+		{sql.ErrValueOutOfRange.New("i", 2), 1264, mysql.SSDataOutOfRange},
+		{sql.ErrDataTruncated.New("i", 1), 1265, "01000"},
+		{sql.ErrDataTooLong.New("s", 1), mysql.ERDataTooLong, mysql.SSDataTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			require := require.New(t)
+			sqlErr, ok := castSQLError(tt.err).(*mysql.SQLError)
+			require.True(ok)
+			require.Equal(tt.code, sqlErr.Number())
+			require.Equal(tt.state, sqlErr.SQLState())
+			require.Equal(tt.err.Error(), sqlErr.Message)
+		})
+	}
+}
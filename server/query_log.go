@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+)
+
+// QueryLogRecord is a record of the general query log, with the execution of a query.
+type QueryLogRecord struct {
+	// Time is when the query started.
+	Time time.Time
+	// ConnectionID is the id of the connection that ran the query.
+	ConnectionID uint32
+	// User that ran the query.
+	User string
+	// Query is the text of the query.
+	Query string
+	// Digest of the query, which is the same for queries that only differ in their literals. See parse.Digest.
+	Digest string
+	// Duration of the query, until all its rows were sent to the client.
+	Duration time.Duration
+	// Rows is the number of rows the query returned, or affected if it doesn't return rows.
+	Rows uint64
+	// Err is the error of the query, or nil if it succeeded.
+	Err error
+}
+
+// QueryLogSink receives the records of the general query log. Sinks are called by the connections running the
+// queries, so they must be safe for concurrent use.
+type QueryLogSink interface {
+	LogQuery(record QueryLogRecord)
+}
+
+// QueryLogFunc is a QueryLogSink that calls the function for every record.
+type QueryLogFunc func(record QueryLogRecord)
+
+// LogQuery implements the QueryLogSink interface.
+func (f QueryLogFunc) LogQuery(record QueryLogRecord) {
+	f(record)
+}
+
+// WriterQueryLog is a QueryLogSink that writes records to a writer as JSON objects, one per line.
+type WriterQueryLog struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterQueryLog returns a query log that writes records to the writer given.
+func NewWriterQueryLog(w io.Writer) *WriterQueryLog {
+	return &WriterQueryLog{w: w}
+}
+
+// NewStdoutQueryLog returns a query log that writes records to the standard output.
+func NewStdoutQueryLog() *WriterQueryLog {
+	return NewWriterQueryLog(os.Stdout)
+}
+
+// OpenFileQueryLog returns a query log that appends records to the file at the path given, creating it if it doesn't
+// exist. The file is closed with Close.
+func OpenFileQueryLog(path string) (*WriterQueryLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterQueryLog{w: f, closer: f}, nil
+}
+
+type queryLogEntry struct {
+	Time         string  `json:"time"`
+	ConnectionID uint32  `json:"connection_id"`
+	User         string  `json:"user"`
+	Query        string  `json:"query"`
+	Digest       string  `json:"digest"`
+	Duration     float64 `json:"duration"`
+	Rows         uint64  `json:"rows"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// LogQuery implements the QueryLogSink interface.
+func (l *WriterQueryLog) LogQuery(record QueryLogRecord) {
+	entry := queryLogEntry{
+		Time:         record.Time.UTC().Format(time.RFC3339Nano),
+		ConnectionID: record.ConnectionID,
+		User:         record.User,
+		Query:        record.Query,
+		Digest:       record.Digest,
+		Duration:     record.Duration.Seconds(),
+		Rows:         record.Rows,
+	}
+	if record.Err != nil {
+		entry.Error = record.Err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := json.NewEncoder(l.w).Encode(entry); err != nil {
+		logrus.Warnf("unable to write to the query log: %s", err)
+	}
+}
+
+// Close closes the file of the query log if it was opened with OpenFileQueryLog.
+func (l *WriterQueryLog) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
+
+// isGeneralLogOn returns whether the queries of the session of the context given go to the general query log.
+func isGeneralLogOn(ctx *sql.Context) bool {
+	_, val := ctx.Get(sql.GeneralLogSessionVar)
+	on, _ := sql.ConvertToBool(val)
+	return on
+}
+
+// logQuery sends the record of a query to the query log of the handler.
+func (h *Handler) logQuery(ctx *sql.Context, query string, start time.Time, rows uint64, err error) {
+	digest, _ := parse.Digest(query)
+	h.queryLog.LogQuery(QueryLogRecord{
+		Time:         start,
+		ConnectionID: ctx.Session.ID(),
+		User:         ctx.Client().User,
+		Query:        query,
+		Digest:       digest,
+		Duration:     time.Since(start),
+		Rows:         rows,
+		Err:          err,
+	})
+}
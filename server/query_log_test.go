@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestHandlerQueryLog(t *testing.T) {
+	require := require.New(t)
+	e := setupMemDB(require)
+	handler := NewHandler(
+		e,
+		NewSessionManager(
+			testSessionBuilder,
+			opentracing.NoopTracer{},
+			func(db string) bool { return db == "test" },
+			sql.NewMemoryManager(nil),
+			"foo",
+		),
+		0,
+	)
+
+	var mu sync.Mutex
+	var records []QueryLogRecord
+	handler.queryLog = QueryLogFunc(func(record QueryLogRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		records = append(records, record)
+	})
+
+	conn := newConn(1)
+	conn.User = "root"
+	handler.NewConnection(conn)
+	require.NoError(handler.ComInitDB(conn, "test"))
+
+	query := func(q string) error {
+		return handler.ComQuery(conn, q, func(*sqltypes.Result) error { return nil })
+	}
+
+	require.NoError(query("SELECT * FROM test LIMIT 1"))
+	require.NoError(query("SET general_log = 1"))
+	require.NoError(query("SELECT * FROM test WHERE c1 < 150"))
+	require.NoError(query("SELECT * FROM test WHERE c1 < 10"))
+	require.NoError(query("INSERT INTO test VALUES (2000), (2001)"))
+	require.Error(query("SELECT * FROM missing"))
+	require.NoError(query("SET general_log = 0"))
+	require.NoError(query("SELECT * FROM test LIMIT 1"))
+
+	require.Len(records, 5)
+	for _, r := range records {
+		require.Equal(uint32(1), r.ConnectionID)
+		require.Equal("root", r.User)
+		require.False(r.Time.IsZero())
+		require.NotEmpty(r.Digest)
+	}
+
+	require.Equal("SELECT * FROM test WHERE c1 < 150", records[0].Query)
+	require.Equal(uint64(150), records[0].Rows)
+	require.Equal(uint64(10), records[1].Rows)
+	require.Equal(records[0].Digest, records[1].Digest)
+	require.NoError(records[1].Err)
+
+	require.Equal(uint64(2), records[2].Rows)
+
+	require.Error(records[3].Err)
+	require.True(sql.ErrTableNotFound.Is(records[3].Err))
+	require.Equal("SET general_log = 0", records[4].Query)
+}
+
+func TestWriterQueryLog(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	log := NewWriterQueryLog(&buf)
+
+	start := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	log.LogQuery(QueryLogRecord{
+		Time:         start,
+		ConnectionID: 3,
+		User:         "root",
+		Query:        "SELECT 1",
+		Digest:       "abc",
+		Duration:     1500 * time.Millisecond,
+		Rows:         1,
+	})
+	log.LogQuery(QueryLogRecord{Time: start, Query: "SELECT x", Err: errors.New("unknown column")})
+	require.NoError(log.Close())
+
+	dec := json.NewDecoder(&buf)
+	var entry map[string]interface{}
+	require.NoError(dec.Decode(&entry))
+	require.Equal(map[string]interface{}{
+		"time":          "2020-10-01T12:00:00Z",
+		"connection_id": float64(3),
+		"user":          "root",
+		"query":         "SELECT 1",
+		"digest":        "abc",
+		"duration":      1.5,
+		"rows":          float64(1),
+	}, entry)
+
+	entry = nil
+	require.NoError(dec.Decode(&entry))
+	require.Equal("unknown column", entry["error"])
+}
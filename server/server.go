@@ -35,6 +35,10 @@ type Config struct {
 	ConnWriteTimeout time.Duration
 	// MaxConnections is the maximum number of simultaneous connections that the server will allow.
 	MaxConnections uint64
+	// QueryLog receives the general query log, with a record for every query of the sessions that have the
+	// general_log variable on. Sessions can turn it on and off at any time with SET general_log = 1 and
+	// SET general_log = 0.
+	QueryLog QueryLogSink
 }
 
 // NewDefaultServer creates a Server with the default session builder.
@@ -64,14 +68,16 @@ func NewServer(cfg Config, e *sqle.Engine, sb SessionBuilder) (*Server, error) {
 		cfg.MaxConnections = 0
 	}
 
-	handler := NewHandler(e,
-		NewSessionManager(
-			sb,
-			tracer,
-			e.Catalog.HasDB,
-			e.Catalog.MemoryManager,
-			cfg.Address),
-		cfg.ConnReadTimeout)
+	sm := NewSessionManager(
+		sb,
+		tracer,
+		e.Catalog.HasDB,
+		e.Catalog.MemoryManager,
+		cfg.Address)
+	e.Catalog.AddCatalogChangeListener(sm)
+
+	handler := NewHandler(e, sm, cfg.ConnReadTimeout)
+	handler.queryLog = cfg.QueryLog
 	a := cfg.Auth.Mysql()
 	l, err := NewListener(cfg.Protocol, cfg.Address, handler)
 	if err != nil {
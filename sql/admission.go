@@ -0,0 +1,152 @@
+package sql
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// MaxConcurrentQueriesVar is the system variable with the maximum number of queries the engine runs at the same
+	// time. Zero means there's no limit.
+	MaxConcurrentQueriesVar = "max_concurrent_queries"
+	// MaxUserConcurrentQueriesVar is the system variable with the maximum number of queries each user runs at the same
+	// time. Zero means there's no limit.
+	MaxUserConcurrentQueriesVar = "max_user_concurrent_queries"
+	// MaxDigestConcurrentQueriesVar is the system variable with the maximum number of queries with the same digest
+	// the engine runs at the same time, which limits queries that only differ in their literals. Zero means there's no
+	// limit.
+	MaxDigestConcurrentQueriesVar = "max_digest_concurrent_queries"
+	// AdmissionQueueTimeoutVar is the system variable with how many seconds queries over the limits of concurrent
+	// queries wait for other queries to finish before they are rejected. Zero means they are rejected right away.
+	AdmissionQueueTimeoutVar = "admission_queue_timeout"
+)
+
+// AdmissionLimits are the limits of concurrent queries of an AdmissionController. Limits of zero are disabled.
+type AdmissionLimits struct {
+	// MaxQueries is the maximum number of queries running at the same time.
+	MaxQueries int64
+	// MaxUserQueries is the maximum number of queries of each user running at the same time.
+	MaxUserQueries int64
+	// MaxDigestQueries is the maximum number of queries with the same digest running at the same time.
+	MaxDigestQueries int64
+	// QueueTimeout is how long queries over the limits wait to be admitted before they are rejected.
+	QueueTimeout time.Duration
+}
+
+// GlobalAdmissionLimits returns the limits set in the global values given of the admission control system variables.
+func GlobalAdmissionLimits(globals *GlobalVariables) AdmissionLimits {
+	return AdmissionLimits{
+		MaxQueries:       globals.Int64(MaxConcurrentQueriesVar),
+		MaxUserQueries:   globals.Int64(MaxUserConcurrentQueriesVar),
+		MaxDigestQueries: globals.Int64(MaxDigestConcurrentQueriesVar),
+		QueueTimeout:     time.Duration(globals.Int64(AdmissionQueueTimeoutVar)) * time.Second,
+	}
+}
+
+// Enabled returns whether any of the limits is set.
+func (l AdmissionLimits) Enabled() bool {
+	return l.MaxQueries > 0 || l.MaxUserQueries > 0 || l.MaxDigestQueries > 0
+}
+
+// AdmissionController limits the number of queries running at the same time, in total, for each user, and for each
+// statement digest. Queries over the limits wait in a queue for other queries to finish, or are rejected.
+type AdmissionController struct {
+	mu       sync.Mutex
+	running  int64
+	users    map[string]int64
+	digests  map[string]int64
+	released chan struct{}
+}
+
+// NewAdmissionController returns a new AdmissionController with no queries running.
+func NewAdmissionController() *AdmissionController {
+	return &AdmissionController{
+		users:    make(map[string]int64),
+		digests:  make(map[string]int64),
+		released: make(chan struct{}),
+	}
+}
+
+// Admit admits a query of the user given, with the digest given, once it fits in the limits. Queries over the limits
+// wait up to the queue timeout of the limits for other queries to finish, and are rejected with an
+// ErrTooManyConcurrentQueries, ErrTooManyUserQueries or ErrTooManyDigestQueries error if they still don't fit, or
+// with the error of the context if it's done first. An empty digest isn't limited by digest. Admitted queries must
+// call the release function returned when they finish, which can be called more than once.
+func (c *AdmissionController) Admit(ctx *Context, user, digest string, limits AdmissionLimits) (release func(), err error) {
+	var timeout <-chan time.Time
+	for {
+		c.mu.Lock()
+		err = c.check(user, digest, limits)
+		if err == nil {
+			c.running++
+			c.users[user]++
+			if digest != "" {
+				c.digests[digest]++
+			}
+			c.mu.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() { c.release(user, digest) })
+			}, nil
+		}
+		released := c.released
+		c.mu.Unlock()
+
+		if limits.QueueTimeout <= 0 {
+			return nil, err
+		}
+		if timeout == nil {
+			timer := time.NewTimer(limits.QueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case <-released:
+		case <-timeout:
+			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Running returns the number of queries admitted that haven't been released yet.
+func (c *AdmissionController) Running() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running
+}
+
+func (c *AdmissionController) check(user, digest string, limits AdmissionLimits) error {
+	if limits.MaxQueries > 0 && c.running >= limits.MaxQueries {
+		return ErrTooManyConcurrentQueries.New(limits.MaxQueries)
+	}
+	if limits.MaxUserQueries > 0 && c.users[user] >= limits.MaxUserQueries {
+		return ErrTooManyUserQueries.New(user, limits.MaxUserQueries)
+	}
+	if digest != "" && limits.MaxDigestQueries > 0 && c.digests[digest] >= limits.MaxDigestQueries {
+		return ErrTooManyDigestQueries.New(limits.MaxDigestQueries)
+	}
+	return nil
+}
+
+func (c *AdmissionController) release(user, digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running--
+	if c.users[user]--; c.users[user] <= 0 {
+		delete(c.users, user)
+	}
+	if digest != "" {
+		if c.digests[digest]--; c.digests[digest] <= 0 {
+			delete(c.digests, digest)
+		}
+	}
+
+	// Wake up the queries waiting to be admitted, which check the limits again.
+	close(c.released)
+	c.released = make(chan struct{})
+}
@@ -0,0 +1,79 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmissionController(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	c := NewAdmissionController()
+
+	limits := AdmissionLimits{MaxQueries: 3, MaxUserQueries: 2, MaxDigestQueries: 1}
+	release1, err := c.Admit(ctx, "alice", "a", limits)
+	require.NoError(err)
+	_, err = c.Admit(ctx, "bob", "a", limits)
+	require.True(ErrTooManyDigestQueries.Is(err))
+	release2, err := c.Admit(ctx, "alice", "b", limits)
+	require.NoError(err)
+	_, err = c.Admit(ctx, "alice", "c", limits)
+	require.True(ErrTooManyUserQueries.Is(err))
+	release3, err := c.Admit(ctx, "bob", "", limits)
+	require.NoError(err)
+	_, err = c.Admit(ctx, "carol", "d", limits)
+	require.True(ErrTooManyConcurrentQueries.Is(err))
+	require.Equal(int64(3), c.Running())
+
+	// Releasing more than once has no effect
+	release1()
+	release1()
+	require.Equal(int64(2), c.Running())
+	release4, err := c.Admit(ctx, "carol", "a", limits)
+	require.NoError(err)
+
+	release2()
+	release3()
+	release4()
+	require.Equal(int64(0), c.Running())
+	require.Empty(c.users)
+	require.Empty(c.digests)
+}
+
+func TestAdmissionControllerQueue(t *testing.T) {
+	require := require.New(t)
+	c := NewAdmissionController()
+
+	limits := AdmissionLimits{MaxQueries: 1, QueueTimeout: time.Minute}
+	release, err := c.Admit(NewEmptyContext(), "alice", "", limits)
+	require.NoError(err)
+
+	admitted := make(chan error)
+	go func() {
+		release, err := c.Admit(NewEmptyContext(), "bob", "", limits)
+		if err == nil {
+			release()
+		}
+		admitted <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	require.NoError(<-admitted)
+
+	release, err = c.Admit(NewEmptyContext(), "alice", "", limits)
+	require.NoError(err)
+	defer release()
+
+	limits.QueueTimeout = 10 * time.Millisecond
+	_, err = c.Admit(NewEmptyContext(), "bob", "", limits)
+	require.True(ErrTooManyConcurrentQueries.Is(err))
+
+	limits.QueueTimeout = time.Minute
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Admit(NewContext(cancelled), "bob", "", limits)
+	require.Equal(context.Canceled, err)
+}
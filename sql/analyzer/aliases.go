@@ -64,12 +64,8 @@ func getTableAliases(n sql.Node) (TableAliases, error) {
 // getExpressionAliases returns a map of all expressions aliased in the SELECT clause, keyed by their alias name
 func getExpressionAliases(node sql.Node) ExprAliases {
 	aliases := make(ExprAliases)
-	var findAliasExpressionsFn func(node sql.Node) bool
-	findAliasExpressionsFn = func(n sql.Node) bool {
-		if n == nil {
-			return true
-		}
-
+	// Inspect walks the children of every node already, so the aliases of every project are found in a single pass
+	plan.Inspect(node, func(n sql.Node) bool {
 		if prj, ok := n.(*plan.Project); ok {
 			for _, ex := range prj.Expressions() {
 				if alias, ok := ex.(*expression.Alias); ok {
@@ -78,16 +74,9 @@ func getExpressionAliases(node sql.Node) ExprAliases {
 					}
 				}
 			}
-		} else {
-			for _, ch := range n.Children() {
-				plan.Inspect(ch, findAliasExpressionsFn)
-			}
 		}
-
 		return true
-	}
-
-	plan.Inspect(node, findAliasExpressionsFn)
+	})
 	return aliases
 }
 
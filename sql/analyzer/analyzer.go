@@ -3,8 +3,8 @@ package analyzer
 import (
 	"fmt"
 	"os"
-	"reflect"
 	"strings"
+	"sync"
 
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pmezard/go-difflib/difflib"
@@ -27,12 +27,42 @@ var ErrInAnalysis = errors.NewKind("error in analysis: %s")
 // ErrInvalidNodeType is thrown when the analyzer can't handle a particular kind of node type
 var ErrInvalidNodeType = errors.NewKind("%s: invalid node of type: %T")
 
+// ErrRuleNotFound is thrown when a rule is added before or after a rule that doesn't exist
+var ErrRuleNotFound = errors.NewKind("analyzer rule not found: %s")
+
+// Phase is a point of the analysis where custom rules can be added.
+type Phase int
+
+const (
+	// PreAnalyze rules run before any default rule, on the plan as it was parsed, until it stops changing.
+	PreAnalyze Phase = iota
+	// PostResolution rules run once after the tables, columns and functions of the plan are resolved, and before the
+	// plan is optimized.
+	PostResolution
+	// PostAnalyze rules run after all the default rules, including optimizations, until the plan stops changing.
+	PostAnalyze
+	// PreValidation rules run once before the plan is validated.
+	PreValidation
+	// PostValidation rules run once after the plan is validated.
+	PostValidation
+)
+
+// ruleInsertion is a rule to add before or after another rule when the analyzer is built.
+type ruleInsertion struct {
+	target string
+	after  bool
+	rule   Rule
+}
+
 // Builder provides an easy way to generate Analyzer with custom rules and options.
 type Builder struct {
 	preAnalyzeRules     []Rule
+	postResolutionRules []Rule
 	postAnalyzeRules    []Rule
 	preValidationRules  []Rule
 	postValidationRules []Rule
+	insertions          []ruleInsertion
+	disabledRules       []string
 	catalog             *sql.Catalog
 	debug               bool
 	parallelism         int
@@ -57,31 +87,72 @@ func (ab *Builder) WithParallelism(parallelism int) *Builder {
 	return ab
 }
 
+// AddRule adds a new rule to the analyzer in the phase given, after any other rule added to that phase.
+func (ab *Builder) AddRule(phase Phase, name string, fn RuleFunc) *Builder {
+	rule := Rule{name, fn}
+	switch phase {
+	case PreAnalyze:
+		ab.preAnalyzeRules = append(ab.preAnalyzeRules, rule)
+	case PostResolution:
+		ab.postResolutionRules = append(ab.postResolutionRules, rule)
+	case PostAnalyze:
+		ab.postAnalyzeRules = append(ab.postAnalyzeRules, rule)
+	case PreValidation:
+		ab.preValidationRules = append(ab.preValidationRules, rule)
+	case PostValidation:
+		ab.postValidationRules = append(ab.postValidationRules, rule)
+	default:
+		panic(fmt.Sprintf("unknown analyzer phase: %d", phase))
+	}
+
+	return ab
+}
+
 // AddPreAnalyzeRule adds a new rule to the analyze before the standard analyzer rules.
 func (ab *Builder) AddPreAnalyzeRule(name string, fn RuleFunc) *Builder {
-	ab.preAnalyzeRules = append(ab.preAnalyzeRules, Rule{name, fn})
+	return ab.AddRule(PreAnalyze, name, fn)
+}
 
-	return ab
+// AddPostResolutionRule adds a new rule to the analyzer after the plan is resolved and before it's optimized.
+func (ab *Builder) AddPostResolutionRule(name string, fn RuleFunc) *Builder {
+	return ab.AddRule(PostResolution, name, fn)
 }
 
 // AddPostAnalyzeRule adds a new rule to the analyzer after standard analyzer rules.
 func (ab *Builder) AddPostAnalyzeRule(name string, fn RuleFunc) *Builder {
-	ab.postAnalyzeRules = append(ab.postAnalyzeRules, Rule{name, fn})
-
-	return ab
+	return ab.AddRule(PostAnalyze, name, fn)
 }
 
 // AddPreValidationRule adds a new rule to the analyzer before standard validation rules.
 func (ab *Builder) AddPreValidationRule(name string, fn RuleFunc) *Builder {
-	ab.preValidationRules = append(ab.preValidationRules, Rule{name, fn})
-
-	return ab
+	return ab.AddRule(PreValidation, name, fn)
 }
 
 // AddPostValidationRule adds a new rule to the analyzer after standard validation rules.
 func (ab *Builder) AddPostValidationRule(name string, fn RuleFunc) *Builder {
-	ab.postValidationRules = append(ab.postValidationRules, Rule{name, fn})
+	return ab.AddRule(PostValidation, name, fn)
+}
+
+// AddRuleBefore adds a new rule to the analyzer right before the rule named target, in the same batch, so it runs
+// in the same phase and as many times. The target can be a default rule, such as pushdown_filters to run an
+// optimization before filters are pushed down to tables, or a custom rule added before this one. Build panics if
+// there's no rule with the target name.
+func (ab *Builder) AddRuleBefore(target, name string, fn RuleFunc) *Builder {
+	ab.insertions = append(ab.insertions, ruleInsertion{target: target, rule: Rule{name, fn}})
+	return ab
+}
+
+// AddRuleAfter adds a new rule to the analyzer right after the rule named target, in the same batch. See
+// AddRuleBefore.
+func (ab *Builder) AddRuleAfter(target, name string, fn RuleFunc) *Builder {
+	ab.insertions = append(ab.insertions, ruleInsertion{target: target, after: true, rule: Rule{name, fn}})
+	return ab
+}
 
+// DisableRules disables the rules with the names given, default or custom, so the analyzer skips them. They can be
+// enabled again with Analyzer.EnableRule.
+func (ab *Builder) DisableRules(names ...string) *Builder {
+	ab.disabledRules = append(ab.disabledRules, names...)
 	return ab
 }
 
@@ -108,6 +179,11 @@ func (ab *Builder) Build() *Analyzer {
 			Iterations: maxAnalysisIterations,
 			Rules:      DefaultRules,
 		},
+		&Batch{
+			Desc:       "post-resolution",
+			Iterations: 1,
+			Rules:      ab.postResolutionRules,
+		},
 		&Batch{
 			Desc:       "once-after",
 			Iterations: 1,
@@ -140,15 +216,51 @@ func (ab *Builder) Build() *Analyzer {
 		},
 	}
 
+	for _, ins := range ab.insertions {
+		if !insertRule(batches, ins) {
+			panic(ErrRuleNotFound.New(ins.target))
+		}
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range ab.disabledRules {
+		disabled[name] = true
+	}
+
 	return &Analyzer{
-		Debug:        debug || ab.debug,
-		contextStack: make([]string, 0),
-		Batches:      batches,
-		Catalog:      ab.catalog,
-		Parallelism:  ab.parallelism,
+		Debug:         debug || ab.debug,
+		contextStack:  make([]string, 0),
+		Batches:       batches,
+		Catalog:       ab.catalog,
+		Parallelism:   ab.parallelism,
+		disabledRules: disabled,
 	}
 }
 
+// insertRule inserts the rule of an insertion next to its target in the batches given, and returns whether the
+// target was found. The rules of the batch are copied, so the default rules aren't modified.
+func insertRule(batches []*Batch, ins ruleInsertion) bool {
+	for _, b := range batches {
+		for i, r := range b.Rules {
+			if r.Name != ins.target {
+				continue
+			}
+
+			if ins.after {
+				i++
+			}
+
+			rules := make([]Rule, 0, len(b.Rules)+1)
+			rules = append(rules, b.Rules[:i]...)
+			rules = append(rules, ins.rule)
+			rules = append(rules, b.Rules[i:]...)
+			b.Rules = rules
+			return true
+		}
+	}
+	return false
+}
+
 // Analyzer analyzes nodes of the execution plan and applies rules and validations
 // to them.
 type Analyzer struct {
@@ -159,10 +271,22 @@ type Analyzer struct {
 	// A stack of debugger context. See PushDebugContext, PopDebugContext
 	contextStack []string
 	Parallelism  int
+	// DeterministicOrder makes queries return their rows in the same order every time they run: they aren't
+	// parallelized, their sorts aren't pushed down to tables, which might not keep the order of equal rows, and
+	// queries without an order are sorted by all their columns.
+	DeterministicOrder bool
+	// PartialStarExpansion makes stars expand only to the columns the user is granted in the tables with column
+	// privileges.
+	PartialStarExpansion bool
+	// CostModel has the costs plans are estimated with. The DefaultCostModel is used if it's nil.
+	CostModel *CostModel
 	// Batches of Rules to apply.
 	Batches []*Batch
 	// Catalog of databases and registered functions.
 	Catalog *sql.Catalog
+
+	mu            sync.RWMutex
+	disabledRules map[string]bool
 }
 
 // NewDefault creates a default Analyzer instance with all default Rules and configuration.
@@ -171,6 +295,32 @@ func NewDefault(c *sql.Catalog) *Analyzer {
 	return NewBuilder(c).Build()
 }
 
+// EnableRule enables the rule with the name given, if it was disabled. It's safe to call while queries are analyzed,
+// and applies to the analyses started after it.
+func (a *Analyzer) EnableRule(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.disabledRules, name)
+}
+
+// DisableRule disables the rule with the name given, so the analyzer skips it. It's safe to call while queries are
+// analyzed, and applies to the rules evaluated after it.
+func (a *Analyzer) DisableRule(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.disabledRules == nil {
+		a.disabledRules = make(map[string]bool)
+	}
+	a.disabledRules[name] = true
+}
+
+// IsRuleEnabled returns whether the rule with the name given is enabled.
+func (a *Analyzer) IsRuleEnabled(name string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return !a.disabledRules[name]
+}
+
 type simpleLogFormatter struct{}
 
 func (s simpleLogFormatter) Format(entry *logrus.Entry) ([]byte, error) {
@@ -225,7 +375,7 @@ func (a *Analyzer) LogNode(n sql.Node) {
 // Only can print a diff when the string representations of the nodes differ, which isn't always the case.
 func (a *Analyzer) LogDiff(prev, next sql.Node) {
 	if a.Debug && a.Verbose {
-		if !reflect.DeepEqual(next, prev) {
+		if !nodesEqual(next, prev) {
 			diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
 				A:        difflib.SplitLines(sql.DebugString(prev)),
 				B:        difflib.SplitLines(sql.DebugString(next)),
@@ -245,9 +395,10 @@ func (a *Analyzer) LogDiff(prev, next sql.Node) {
 	}
 }
 
-// PushDebugContext pushes the given context string onto the context stack, to use when logging debug messages.
+// PushDebugContext pushes the given context string onto the context stack, to use when logging debug messages. The
+// stack is only kept when logging is enabled, since it's shared by all the queries analyzed concurrently.
 func (a *Analyzer) PushDebugContext(msg string) {
-	if a != nil {
+	if a != nil && (a.Debug || a.Verbose) {
 		a.contextStack = append(a.contextStack, msg)
 	}
 }
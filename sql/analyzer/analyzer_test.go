@@ -38,7 +38,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err := a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 	require.Equal(
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTableInDatabase(table, "mydb"),
 		analyzed,
 	)
 
@@ -66,8 +66,8 @@ func TestAnalyzer_Analyze(t *testing.T) {
 		plan.NewUnresolvedTable("mytable", ""),
 	)
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
-	var expected sql.Node = plan.NewDecoratedNode("Projected table access on [i]", plan.NewResolvedTable(
-		table.WithProjection([]string{"i"}),
+	var expected sql.Node = plan.NewDecoratedNode("Projected table access on [i]", plan.NewResolvedTableInDatabase(
+		table.WithProjection([]string{"i"}), "mydb",
 	))
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -77,7 +77,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	expected = plan.NewDescribe(
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTableInDatabase(table, "mydb"),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -89,7 +89,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 
-	expected = plan.NewDecoratedNode("Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})))
+	expected = plan.NewDecoratedNode("Projected table access on [i t]", plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i", "t"}), "mydb"))
 	assertNodesEqualWithDiff(t, expected, analyzed)
 
 	notAnalyzed = plan.NewProject(
@@ -102,7 +102,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	require.NoError(err)
 
-	expected = plan.NewDecoratedNode("Projected table access on [i t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "t"})))
+	expected = plan.NewDecoratedNode("Projected table access on [i t]", plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i", "t"}), "mydb"))
 	assertNodesEqualWithDiff(t, expected, analyzed)
 
 	notAnalyzed = plan.NewProject(
@@ -117,7 +117,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 			expression.NewAlias("foo", expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)),
 		},
 		plan.NewDecoratedNode("Projected table access on [i]",
-			plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
+			plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i"}), "mydb")),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -135,13 +135,13 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	expected = plan.NewDecoratedNode("Filtered table access on [mytable.i = 1]",
 		plan.NewDecoratedNode("Projected table access on [i]",
-			plan.NewResolvedTable(
+			plan.NewResolvedTableInDatabase(
 				table.WithFilters([]sql.Expression{
 					expression.NewEquals(
 						expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false),
 						expression.NewLiteral(int32(1), sql.Int32),
 					),
-				}).(*memory.PushdownTable).WithProjection([]string{"i"}),
+				}).(*memory.PushdownTable).WithProjection([]string{"i"}), "mydb",
 			),
 		),
 	)
@@ -160,8 +160,8 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(ctx, notAnalyzed, nil)
 	expected = plan.NewCrossJoin(
-		plan.NewDecoratedNode("Projected table access on [i]", plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
-		plan.NewDecoratedNode("Projected table access on [i2]", plan.NewResolvedTable(table2.WithProjection([]string{"i2"}))),
+		plan.NewDecoratedNode("Projected table access on [i]", plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i"}), "mydb")),
+		plan.NewDecoratedNode("Projected table access on [i2]", plan.NewResolvedTableInDatabase(table2.WithProjection([]string{"i2"}), "mydb")),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -178,7 +178,8 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	expected = plan.NewLimit(
 		int64(1),
 		plan.NewDecoratedNode("Projected table access on [i]",
-			plan.NewResolvedTable(table.WithProjection([]string{"i"}))),
+			plan.NewDecoratedNode("Limited table access on 1",
+				plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i"}).(*memory.PushdownTable).WithLimit(1), "mydb"))),
 	)
 	require.NoError(err)
 	assertNodesEqualWithDiff(t, expected, analyzed)
@@ -261,6 +262,103 @@ func TestAddPostValidationRule(t *testing.T) {
 	require.Equal(countRules(a.Batches), defRulesCount+1)
 }
 
+func TestAddPostResolutionRule(t *testing.T) {
+	require := require.New(t)
+
+	a := NewBuilder(nil).AddRule(PostResolution, "foo", pushdownFilters).Build()
+
+	var batch *Batch
+	for _, b := range a.Batches {
+		if b.Desc == "post-resolution" {
+			batch = b
+		}
+	}
+	require.NotNil(batch)
+	require.Equal([]string{"foo"}, ruleNames(batch.Rules))
+	require.Equal(countRules(NewDefault(nil).Batches)+1, countRules(a.Batches))
+}
+
+func TestAddRuleBeforeAndAfter(t *testing.T) {
+	require := require.New(t)
+
+	noop := func(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+		return n, nil
+	}
+
+	a := NewBuilder(nil).
+		AddRuleBefore("pushdown_filters", "before_filters", noop).
+		AddRuleAfter("pushdown_filters", "after_filters", noop).
+		AddRuleAfter("after_filters", "after_after_filters", noop).
+		Build()
+
+	var names []string
+	for _, b := range a.Batches {
+		if b.Desc == "once-after" {
+			names = ruleNames(b.Rules)
+		}
+	}
+
+	i := indexOf(names, "pushdown_filters")
+	require.True(i > 0)
+	require.Equal([]string{"before_filters", "pushdown_filters", "after_filters", "after_after_filters"}, names[i-1:i+3])
+
+	// The default rules are not modified
+	require.Len(OnceAfterDefault, len(names)-3)
+	require.Equal(-1, indexOf(ruleNames(OnceAfterDefault), "before_filters"))
+
+	require.Panics(func() {
+		NewBuilder(nil).AddRuleBefore("nonexistent", "foo", noop).Build()
+	})
+}
+
+func TestDisableRules(t *testing.T) {
+	require := require.New(t)
+
+	var applied []string
+	rule := func(name string) RuleFunc {
+		return func(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+			applied = append(applied, name)
+			return n, nil
+		}
+	}
+
+	a := NewBuilder(nil).
+		AddPreValidationRule("a", rule("a")).
+		AddPreValidationRule("b", rule("b")).
+		DisableRules("a").
+		Build()
+
+	batch := &Batch{Desc: "test", Iterations: 1, Rules: []Rule{{"a", rule("a")}, {"b", rule("b")}}}
+	_, err := batch.Eval(sql.NewEmptyContext(), a, plan.NewUnresolvedTable("foo", ""), nil)
+	require.NoError(err)
+	require.Equal([]string{"b"}, applied)
+	require.False(a.IsRuleEnabled("a"))
+
+	applied = nil
+	a.EnableRule("a")
+	a.DisableRule("b")
+	_, err = batch.Eval(sql.NewEmptyContext(), a, plan.NewUnresolvedTable("foo", ""), nil)
+	require.NoError(err)
+	require.Equal([]string{"a"}, applied)
+}
+
+func ruleNames(rules []Rule) []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
 func countRules(batches []*Batch) int {
 	var count int
 	for _, b := range batches {
@@ -329,14 +427,14 @@ func TestMixInnerAndNaturalJoins(t *testing.T) {
 		},
 		plan.NewInnerJoin(
 			plan.NewInnerJoin(
-				plan.NewDecoratedNode("Projected table access on [i f t]", plan.NewResolvedTable(table.WithProjection([]string{"i", "f", "t"}))),
-				plan.NewDecoratedNode("Projected table access on [f2 i2 t2]", plan.NewResolvedTable(table2.WithProjection([]string{"f2", "i2", "t2"}))),
+				plan.NewDecoratedNode("Projected table access on [i f t]", plan.NewResolvedTableInDatabase(table.WithProjection([]string{"i", "f", "t"}), "mydb")),
+				plan.NewDecoratedNode("Projected table access on [f2 i2 t2]", plan.NewResolvedTableInDatabase(table2.WithProjection([]string{"f2", "i2", "t2"}), "mydb")),
 				expression.NewEquals(
 					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false),
 					expression.NewGetFieldWithTable(4, sql.Int32, "mytable2", "i2", false),
 				),
 			),
-			plan.NewDecoratedNode("Projected table access on [t3 i f2]", plan.NewResolvedTable(table3.WithProjection([]string{"t3", "i", "f2"}))),
+			plan.NewDecoratedNode("Projected table access on [t3 i f2]", plan.NewResolvedTableInDatabase(table3.WithProjection([]string{"t3", "i", "f2"}), "mydb")),
 			expression.NewAnd(
 				expression.NewEquals(
 					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false),
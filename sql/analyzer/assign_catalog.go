@@ -19,12 +19,18 @@ func assignCatalog(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 		case *plan.CreateIndex:
 			nc := *node
 			nc.Catalog = a.Catalog
-			nc.CurrentDatabase = ctx.GetCurrentDatabase()
+			nc.CurrentDatabase = getDatabaseName(node.Table)
+			if nc.CurrentDatabase == "" {
+				nc.CurrentDatabase = ctx.GetCurrentDatabase()
+			}
 			return &nc, nil
 		case *plan.DropIndex:
 			nc := *node
 			nc.Catalog = a.Catalog
-			nc.CurrentDatabase = ctx.GetCurrentDatabase()
+			nc.CurrentDatabase = getDatabaseName(node.Table)
+			if nc.CurrentDatabase == "" {
+				nc.CurrentDatabase = ctx.GetCurrentDatabase()
+			}
 			return &nc, nil
 		case *plan.ShowDatabases:
 			nc := *node
@@ -59,6 +65,60 @@ func assignCatalog(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			nc := *node
 			nc.Catalog = a.Catalog
 			return &nc, nil
+		case *plan.DumpDatabase:
+			nc := *node
+			nc.Catalog = a.Catalog
+			return &nc, nil
+		case *plan.CreateResourceGroup:
+			nc := *node
+			nc.ResourceGroups = a.Catalog.ResourceGroupRegistry
+			return &nc, nil
+		case *plan.AlterResourceGroup:
+			nc := *node
+			nc.ResourceGroups = a.Catalog.ResourceGroupRegistry
+			return &nc, nil
+		case *plan.DropResourceGroup:
+			nc := *node
+			nc.ResourceGroups = a.Catalog.ResourceGroupRegistry
+			return &nc, nil
+		case *plan.SetResourceGroup:
+			nc := *node
+			nc.ResourceGroups = a.Catalog.ResourceGroupRegistry
+			return &nc, nil
+		case *plan.PinPlanBaseline:
+			nc := *node
+			nc.Baselines = a.Catalog.PlanBaselineRegistry
+			return &nc, nil
+		case *plan.ShowPlanBaselines:
+			nc := *node
+			nc.Baselines = a.Catalog.PlanBaselineRegistry
+			return &nc, nil
+		case *plan.PurgePlanBaselines:
+			nc := *node
+			nc.Baselines = a.Catalog.PlanBaselineRegistry
+			return &nc, nil
+		case *plan.XATransaction:
+			nc := *node
+			nc.Committer = a.Catalog.TwoPhaseCommitter
+			return &nc, nil
+		case *plan.ShowGrants:
+			nc := *node
+			nc.Catalog = a.Catalog
+			if nc.User == "" {
+				nc.User, nc.Host = ctx.Client().User, "%"
+			}
+			return &nc, nil
+		case *plan.AlterUser:
+			nc := *node
+			nc.Catalog = a.Catalog
+			if nc.User == "" {
+				nc.User, nc.Host = ctx.Client().User, "%"
+			}
+			return &nc, nil
+		case *plan.XARecover:
+			nc := *node
+			nc.Committer = a.Catalog.TwoPhaseCommitter
+			return &nc, nil
 		default:
 			return n, nil
 		}
@@ -43,6 +43,22 @@ func TestAssignCatalog(t *testing.T) {
 	require.Equal(c, di.Catalog)
 	require.Equal("foo", di.CurrentDatabase)
 
+	node, err = f.Apply(ctx, a,
+		plan.NewCreateIndex("", plan.NewResolvedTableInDatabase(tbl, "bar"), nil, "", make(map[string]string)), nil)
+	require.NoError(err)
+
+	ci, ok = node.(*plan.CreateIndex)
+	require.True(ok)
+	require.Equal("bar", ci.CurrentDatabase)
+
+	node, err = f.Apply(ctx, a,
+		plan.NewDropIndex("foo", plan.NewResolvedTableInDatabase(tbl, "bar")), nil)
+	require.NoError(err)
+
+	di, ok = node.(*plan.DropIndex)
+	require.True(ok)
+	require.Equal("bar", di.CurrentDatabase)
+
 	node, err = f.Apply(ctx, a, plan.NewShowProcessList(), nil)
 	require.NoError(err)
 
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
 // RuleFunc is the function to be applied in a rule.
@@ -71,6 +72,11 @@ func (b *Batch) Eval(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 func (b *Batch) evalOnce(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	prev := n
 	for _, rule := range b.Rules {
+		if !a.IsRuleEnabled(rule.Name) {
+			a.Log("Skipping disabled rule %s", rule.Name)
+			continue
+		}
+
 		var err error
 		a.Log("Evaluating rule %s", rule.Name)
 		a.PushDebugContext(rule.Name)
@@ -93,6 +99,11 @@ func (b *Batch) evalOnce(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 }
 
 func nodesEqual(a, b sql.Node) bool {
+	// Rules that don't change anything return the same node they were given, which spares us a deep comparison.
+	if plan.NodeIdentity(a, b) {
+		return true
+	}
+
 	if e, ok := a.(equaler); ok {
 		return e.Equal(b)
 	}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/parse"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyChecks sets the check constraints enforced on the rows that INSERT, REPLACE and UPDATE statements write to
+// tables that declare enforced check constraints, with their expressions resolved on the schemas of the tables. It also
+// names and resolves the check constraints added to tables by ALTER TABLE statements.
+func applyChecks(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("apply_checks")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		switch node := node.(type) {
+		case *plan.CreateCheck:
+			return resolveCreateCheck(ctx, a, node)
+		case *plan.InsertInto:
+			checks, err := enforcedChecks(ctx, a, node.Left)
+			if err != nil || len(checks) == 0 {
+				return node, err
+			}
+			nc := *node
+			nc.Checks = checks
+			return &nc, nil
+		case *plan.Update:
+			checks, err := enforcedChecks(ctx, a, node.Child)
+			if err != nil || len(checks) == 0 {
+				return node, err
+			}
+			nc := *node
+			nc.Checks = checks
+			return &nc, nil
+		default:
+			return node, nil
+		}
+	})
+}
+
+// resolveCreateCheck returns the node given with its check constraint named after its table, like MySQL names them, if
+// it has no name, and its expression resolved on the rows of the table if it's enforced.
+func resolveCreateCheck(ctx *sql.Context, a *Analyzer, node *plan.CreateCheck) (sql.Node, error) {
+	rt, ok := node.Child.(*plan.ResolvedTable)
+	if !ok {
+		return node, nil
+	}
+
+	check := *node.Check
+	if check.Name == "" {
+		generated := 0
+		if ct := plan.GetCheckTable(rt.Table); ct != nil {
+			checks, err := ct.GetChecks(ctx)
+			if err != nil {
+				return nil, err
+			}
+			prefix := strings.ToLower(rt.Name()) + "_chk_"
+			for _, c := range checks {
+				name := strings.ToLower(c.Name)
+				if !strings.HasPrefix(name, prefix) {
+					continue
+				}
+				if n, err := strconv.Atoi(name[len(prefix):]); err == nil && n > generated {
+					generated = n
+				}
+			}
+		}
+		check.Name = fmt.Sprintf("%s_chk_%d", rt.Name(), generated+1)
+	}
+
+	expr, err := checkExpression(ctx, a, rt.Table, check)
+	if err != nil {
+		return nil, err
+	}
+	hasSubquery := false
+	sql.Inspect(expr, func(e sql.Expression) bool {
+		if _, ok := e.(*plan.Subquery); ok {
+			hasSubquery = true
+		}
+		return !hasSubquery
+	})
+	if hasSubquery {
+		return nil, sql.ErrCheckConstraintSubquery.New(check.Name)
+	}
+
+	nc := *node
+	nc.Check = &check
+	if check.Enforced {
+		nc.Expr = expr
+	}
+	return &nc, nil
+}
+
+// enforcedChecks returns the enforced check constraints of the table written by the node given.
+func enforcedChecks(ctx *sql.Context, a *Analyzer, n sql.Node) (plan.CheckConstraints, error) {
+	rt := getResolvedTable(n)
+	if rt == nil {
+		return nil, nil
+	}
+
+	checks, err := tableChecks(ctx, a, rt.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(checks) > 0 {
+		a.Log("enforcing %d check constraints of table %q", len(checks), rt.Name())
+	}
+	return checks, nil
+}
+
+// tableChecks returns the enforced check constraints of the table given, with their expressions resolved on its rows.
+func tableChecks(ctx *sql.Context, a *Analyzer, table sql.Table) (plan.CheckConstraints, error) {
+	ct := plan.GetCheckTable(table)
+	if ct == nil {
+		return nil, nil
+	}
+
+	tableChecks, err := ct.GetChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var checks plan.CheckConstraints
+	for _, check := range tableChecks {
+		if !check.Enforced {
+			continue
+		}
+		expr, err := checkExpression(ctx, a, table, check)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, &plan.CheckConstraint{Name: check.Name, Expr: expr})
+	}
+	return checks, nil
+}
+
+// checkExpression returns the expression of the check constraint given of the table given, with its columns resolved
+// on the rows of the table.
+func checkExpression(ctx *sql.Context, a *Analyzer, table sql.Table, check sql.CheckConstraint) (sql.Expression, error) {
+	expr, err := parse.StringToExpression(ctx, check.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := table.Schema()
+	return expression.TransformUp(expr, func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.UnresolvedColumn:
+			for i, col := range schema {
+				if strings.EqualFold(col.Name, e.Name()) {
+					return expression.NewGetFieldWithTable(i, col.Type, table.Name(), col.Name, col.Nullable), nil
+				}
+			}
+			return nil, sql.ErrCheckConstraintUnknownColumn.New(e.Name(), check.Name)
+		case *expression.UnresolvedFunction:
+			return resolveFunctionsInExpr(a)(e)
+		default:
+			return e, nil
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyColumnMasks resolves the tables with masked columns that are read by the query, for users who aren't
+// authorized to unmask them, to tables whose rows have the values of those columns masked. Masking values as they're
+// read means the rest of the query never sees the unmasked values. The tables modified by UPDATE, DELETE and TRUNCATE
+// statements are left as they are for resolve_tables, since their rows are written back.
+func applyColumnMasks(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil || a.Catalog.ColumnMaskRegistry == nil || !a.Catalog.HasColumnMasks() {
+		return n, nil
+	}
+
+	switch describedNode(n).(type) {
+	case *plan.Update, *plan.DeleteFrom, *plan.Truncate:
+		return n, nil
+	}
+
+	span, _ := ctx.Span("apply_column_masks")
+	defer span.Finish()
+
+	return plan.TransformUpWithParent(n, func(n sql.Node, parent sql.Node, childNum int) (sql.Node, error) {
+		t, ok := n.(*plan.UnresolvedTable)
+		if !ok || !isReadOfTable(parent, childNum) {
+			return n, nil
+		}
+
+		db := t.Database
+		if db == "" {
+			db = ctx.GetCurrentDatabase()
+		}
+
+		masks := a.Catalog.ColumnMasks(ctx, db, t.Name())
+		if len(masks) == 0 {
+			return n, nil
+		}
+
+		resolved, err := resolveTable(ctx, a, t)
+		if err != nil {
+			return nil, err
+		}
+
+		rt, ok := resolved.(*plan.ResolvedTable)
+		if !ok {
+			return resolved, nil
+		}
+
+		a.Log("masking columns of table %s", t.Name())
+		return plan.NewResolvedTableInDatabase(plan.NewMaskedTable(rt.Table, masks), rt.Database), nil
+	})
+}
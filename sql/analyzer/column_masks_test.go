@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestApplyColumnMasks(t *testing.T) {
+	f := getRule("apply_column_masks")
+
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "mytable"},
+		{Name: "s", Type: sql.LongText, Source: "mytable"},
+	})
+	db := memory.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	catalog.AddColumnMask("mydb", "mytable", "s", sql.MaskPartial(0, "XXX", 0))
+	catalog.SetUnmaskAuthorizer(func(ctx *sql.Context) bool {
+		return ctx.Client().User == "root"
+	})
+	a := NewDefault(catalog)
+
+	testCases := []struct {
+		name     string
+		user     string
+		node     sql.Node
+		expected string
+	}{
+		{
+			name:     "table",
+			user:     "joe",
+			node:     plan.NewUnresolvedTable("mytable", ""),
+			expected: "Table(mytable(masked: s))",
+		},
+		{
+			name:     "user authorized to unmask",
+			user:     "root",
+			node:     plan.NewUnresolvedTable("mytable", ""),
+			expected: "UnresolvedTable(mytable)",
+		},
+		{
+			name:     "aliased table",
+			user:     "joe",
+			node:     plan.NewTableAlias("t", plan.NewUnresolvedTable("mytable", "")),
+			expected: "TableAlias(t)\n └─ Table(mytable(masked: s))\n",
+		},
+		{
+			name: "delete",
+			user: "joe",
+			node: plan.NewDeleteFrom(plan.NewFilter(
+				expression.NewEquals(expression.NewUnresolvedColumn("s"), expression.NewLiteral("x", sql.LongText)),
+				plan.NewUnresolvedTable("mytable", ""),
+			)),
+			expected: "Delete\n └─ Filter(s = x (LONGTEXT))\n     └─ UnresolvedTable(mytable)\n",
+		},
+		{
+			name:     "show create table",
+			user:     "joe",
+			node:     plan.NewShowCreateTable(plan.NewUnresolvedTable("mytable", ""), false),
+			expected: "SHOW CREATE TABLE mytable",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := sql.NewContext(
+				context.Background(),
+				sql.WithSession(sql.NewSession("server", "client", tt.user, 1)),
+			).WithCurrentDB("mydb")
+
+			result, err := f.Apply(ctx, a, tt.node, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, sql.DebugString(result))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// columnRestriction has the columns of a table with column privileges the user can read.
+type columnRestriction struct {
+	table   string
+	granted map[string]bool
+}
+
+// checkColumnPrivileges fails the query with ErrColumnAccessDenied if it reads a column the user isn't granted in a
+// table with column privileges. The columns written by UPDATE and INSERT ... ON DUPLICATE KEY UPDATE aren't read, and
+// the queries of subqueries and views are checked when they're analyzed.
+func checkColumnPrivileges(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil || a.Catalog.ColumnPrivilegeRegistry == nil || !a.Catalog.HasColumnPrivileges() {
+		return n, nil
+	}
+
+	restrictions := columnRestrictions(ctx, a, n, scope)
+	if len(restrictions) == 0 {
+		return n, nil
+	}
+
+	span, _ := ctx.Span("check_column_privileges")
+	defer span.Finish()
+
+	var err error
+	var check func(e sql.Expression) bool
+	check = func(e sql.Expression) bool {
+		if err != nil {
+			return false
+		}
+
+		switch e := e.(type) {
+		case *expression.SetField:
+			sql.Inspect(e.Right, check)
+			return false
+		case *expression.GetField:
+			r, ok := restrictions[strings.ToLower(e.Table())]
+			if ok && !r.granted[strings.ToLower(e.Name())] {
+				err = sql.NewColumnAccessDenied(ctx, r.table, e.Name())
+			}
+		}
+		return true
+	}
+
+	plan.Inspect(n, func(node sql.Node) bool {
+		if _, ok := node.(*plan.SubqueryAlias); ok || err != nil {
+			return false
+		}
+		if ex, ok := node.(sql.Expressioner); ok {
+			for _, e := range ex.Expressions() {
+				sql.Inspect(e, check)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// columnRestrictions returns the restrictions of the tables with column privileges of the node given and of its outer
+// scopes, keyed by the lower case names or aliases the columns of the tables are referenced by.
+func columnRestrictions(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) map[string]columnRestriction {
+	restrictions := make(map[string]columnRestriction)
+	add := func(name string, rt *plan.ResolvedTable) {
+		name = strings.ToLower(name)
+		if _, ok := restrictions[name]; ok || rt == nil {
+			return
+		}
+		db := rt.Database
+		if db == "" {
+			db = ctx.GetCurrentDatabase()
+		}
+		if granted, ok := a.Catalog.GrantedColumns(ctx, db, rt.Name()); ok {
+			restrictions[name] = columnRestriction{table: rt.Name(), granted: granted}
+		}
+	}
+
+	for _, node := range append([]sql.Node{n}, scope.InnerToOuter()...) {
+		plan.Inspect(node, func(node sql.Node) bool {
+			switch node := node.(type) {
+			case *plan.SubqueryAlias:
+				return false
+			case *plan.TableAlias:
+				if _, ok := node.Child.(*plan.SubqueryAlias); !ok {
+					add(node.Name(), getResolvedTable(node.Child))
+				}
+				return false
+			case *plan.ResolvedTable:
+				add(node.Name(), node)
+			}
+			return true
+		})
+	}
+	return restrictions
+}
@@ -0,0 +1,289 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// decorrelateSubqueries rewrites the correlated EXISTS and IN subqueries of filters into semi joins, and the
+// correlated NOT EXISTS subqueries into anti joins, of the child of the filter with the tables of the subquery. Their
+// conditions on columns of the outer query become the join condition, so the joins can be optimized like any other
+// instead of the subquery being run again for every row. Only subqueries of a filter's top level conjunctions are
+// rewritten, if they select from tables without grouping or derived tables, and have no tables named like those of
+// the outer query, whose columns couldn't be told apart in the join condition. NOT IN subqueries are left as they
+// are, since they don't match rows with NULL values at all, which anti joins would return.
+func decorrelateSubqueries(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("decorrelate_subqueries")
+	defer span.Finish()
+
+	// Subqueries of subqueries are decorrelated by the analysis of their outermost query
+	if !n.Resolved() || len(scope.Schema()) > 0 {
+		return n, nil
+	}
+
+	// Filters are pushed down to the tables named like their columns, so the tables of the subqueries take names no
+	// other table of the query has
+	tables := tableSet(joinLeafTables(n))
+
+	// Derived tables are analyzed on their own
+	selector := func(parent sql.Node, child sql.Node, childNum int) bool {
+		_, ok := parent.(*plan.SubqueryAlias)
+		return !ok
+	}
+	return plan.TransformUpWithSelector(n, selector, func(node sql.Node) (sql.Node, error) {
+		filter, ok := node.(*plan.Filter)
+		if !ok {
+			return node, nil
+		}
+
+		var semiJoins []*decorrelatedSubquery
+		var residual []sql.Expression
+		for _, cond := range splitConjunction(filter.Expression) {
+			sq, ok := decorrelateSubquery(cond, filter.Child.Schema(), tables)
+			if !ok {
+				residual = append(residual, cond)
+				continue
+			}
+			for _, t := range sq.tables {
+				tables[t] = true
+			}
+			semiJoins = append(semiJoins, sq)
+		}
+
+		if len(semiJoins) == 0 {
+			return node, nil
+		}
+
+		child := filter.Child
+		if len(residual) > 0 {
+			child = plan.NewFilter(expression.JoinAnd(residual...), child)
+		}
+		for _, sq := range semiJoins {
+			cond, err := FixFieldIndexes(append(child.Schema(), sq.node.Schema()...), sq.cond)
+			if err != nil {
+				return nil, err
+			}
+			if sq.anti {
+				a.Log("rewrote NOT EXISTS subquery into an anti join")
+				child = plan.NewAntiJoin(child, sq.node, cond)
+			} else {
+				a.Log("rewrote subquery into a semi join")
+				child = plan.NewSemiJoin(child, sq.node, cond)
+			}
+		}
+		return child, nil
+	})
+}
+
+// decorrelatedSubquery is a correlated subquery rewritten into the right side of a semi or anti join.
+type decorrelatedSubquery struct {
+	// The tables of the subquery, under the conditions that only use their columns.
+	node sql.Node
+	// The join condition, with the conditions of the subquery on columns of the outer query.
+	cond sql.Expression
+	// Whether the subquery is the one of a NOT EXISTS, whose rows are joined with an anti join.
+	anti bool
+	// The lower case names of the tables of the subquery.
+	tables []string
+}
+
+// decorrelateSubquery returns the condition given, a conjunction of a filter with the outer schema given, rewritten
+// into the right side of a semi or anti join, or false if it isn't a correlated EXISTS, NOT EXISTS or IN subquery that
+// can be rewritten. Subqueries with tables named like any of the tables given can't be.
+func decorrelateSubquery(cond sql.Expression, outer sql.Schema, tables map[string]bool) (*decorrelatedSubquery, bool) {
+	var sq *plan.Subquery
+	var value sql.Expression
+	var anti bool
+	switch e := cond.(type) {
+	case *plan.ExistsSubquery:
+		sq = e.Query
+	case *expression.Not:
+		exists, ok := e.Child.(*plan.ExistsSubquery)
+		if !ok {
+			return nil, false
+		}
+		sq, anti = exists.Query, true
+	case *plan.InSubquery:
+		right, ok := e.Right.(*plan.Subquery)
+		if !ok || hasSubquery(e.Left) {
+			return nil, false
+		}
+		sq, value = right, e.Left
+	default:
+		return nil, false
+	}
+
+	return decorrelateQuery(sq.Query, value, anti, outer, tables)
+}
+
+// decorrelateQuery returns the query of a subquery rewritten into the right side of a semi or anti join. For IN
+// subqueries, the value given is the left side of the IN, which is compared with the single projection of the query.
+// Limits of EXISTS subqueries, which return a row at most once it's rewritten, sorts and DISTINCT don't change which
+// rows are matched, so they're left out.
+func decorrelateQuery(n sql.Node, value sql.Expression, anti bool, outer sql.Schema, tables map[string]bool) (*decorrelatedSubquery, bool) {
+	var project *plan.Project
+	var conds []sql.Expression
+Nodes:
+	for {
+		switch node := n.(type) {
+		case *plan.Limit:
+			if value != nil || node.Limit < 1 || node.CalcFoundRows {
+				return nil, false
+			}
+			n = node.Child
+		case *plan.Sort:
+			n = node.Child
+		case *plan.Distinct:
+			n = node.Child
+		case *plan.OrderedDistinct:
+			n = node.Child
+		case *plan.Project:
+			if project != nil || len(conds) > 0 {
+				return nil, false
+			}
+			project, n = node, node.Child
+		case *plan.Filter:
+			conds = append(conds, splitConjunction(node.Expression)...)
+			n = node.Child
+		default:
+			break Nodes
+		}
+	}
+
+	source := n
+	if !isDecorrelatableSource(source) {
+		return nil, false
+	}
+
+	outerTables := make(map[string]bool)
+	for _, col := range outer {
+		outerTables[strings.ToLower(col.Source)] = true
+	}
+	innerTables := make(map[string]bool)
+	for _, col := range source.Schema() {
+		innerTables[strings.ToLower(col.Source)] = true
+	}
+	sqTables := joinLeafTables(source)
+	for _, t := range sqTables {
+		if tables[t] || outerTables[t] {
+			return nil, false
+		}
+	}
+
+	// Columns of the source itself must all be of its own tables
+	sourceColumns := true
+	plan.Inspect(source, func(node sql.Node) bool {
+		if e, ok := node.(sql.Expressioner); ok && sourceColumns {
+			for _, expr := range e.Expressions() {
+				if !onlyUsesTables(expr, innerTables, nil) {
+					sourceColumns = false
+				}
+			}
+		}
+		return sourceColumns
+	})
+	if !sourceColumns {
+		return nil, false
+	}
+
+	var joinConds, localConds []sql.Expression
+	for _, cond := range conds {
+		switch {
+		case hasSubquery(cond) || !onlyUsesTables(cond, innerTables, outerTables):
+			return nil, false
+		case usesTables(cond, outerTables):
+			joinConds = append(joinConds, cond)
+		default:
+			localConds = append(localConds, cond)
+		}
+	}
+
+	// Uncorrelated subqueries are only run once, and their results cached
+	if len(joinConds) == 0 {
+		return nil, false
+	}
+
+	if value != nil {
+		if project == nil || len(project.Projections) != 1 {
+			return nil, false
+		}
+		p := project.Projections[0]
+		if alias, ok := p.(*expression.Alias); ok {
+			p = alias.Child
+		}
+		if hasSubquery(p) || !onlyUsesTables(p, innerTables, outerTables) || !onlyUsesTables(value, outerTables, nil) {
+			return nil, false
+		}
+		joinConds = append(joinConds, expression.NewEquals(value, p))
+	}
+
+	right := source
+	if len(localConds) > 0 {
+		right = plan.NewFilter(expression.JoinAnd(localConds...), source)
+	}
+	right, err := plan.TransformUp(right, FixFieldIndexesForExpressions)
+	if err != nil {
+		return nil, false
+	}
+
+	return &decorrelatedSubquery{
+		node:   right,
+		cond:   expression.JoinAnd(joinConds...),
+		anti:   anti,
+		tables: sqTables,
+	}, true
+}
+
+// isDecorrelatableSource returns whether the node given, the source of the rows of a subquery under its filters and
+// projection, only joins and filters tables, which can be joined to the outer query as they are.
+func isDecorrelatableSource(n sql.Node) bool {
+	ok := true
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node.(type) {
+		case nil, *plan.ResolvedTable, *plan.TableAlias, *plan.IndexedTableAccess, *plan.Filter, *plan.CrossJoin,
+			*plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.IndexedJoin, *plan.HashJoin:
+		default:
+			ok = false
+		}
+		if e, isExpressioner := node.(sql.Expressioner); ok && isExpressioner {
+			for _, expr := range e.Expressions() {
+				if hasSubquery(expr) {
+					ok = false
+				}
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+// usesTables returns whether the expression given has a column of any of the lower case tables given.
+func usesTables(e sql.Expression, tables map[string]bool) bool {
+	found := false
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if gf, ok := e.(*expression.GetField); ok && tables[strings.ToLower(gf.Table())] {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// onlyUsesTables returns whether all the columns of the expression given are of the lower case tables of any of the
+// sets given.
+func onlyUsesTables(e sql.Expression, tables, others map[string]bool) bool {
+	ok := true
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if gf, isField := e.(*expression.GetField); isField {
+			t := strings.ToLower(gf.Table())
+			if !tables[t] && !others[t] {
+				ok = false
+			}
+		}
+		return ok
+	})
+	return ok
+}
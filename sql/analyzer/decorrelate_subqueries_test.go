@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestDecorrelateSubqueries(t *testing.T) {
+	foo := plan.NewResolvedTable(memory.NewTable("foo", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "foo"},
+	}))
+	bar := plan.NewResolvedTable(memory.NewTable("bar", sql.Schema{
+		{Name: "b", Type: sql.Int64, Source: "bar"},
+		{Name: "k", Type: sql.Int64, Source: "bar"},
+	}))
+
+	// Columns of subqueries come after those of the outer query
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "foo", "a", false)
+	b := func(idx int) sql.Expression {
+		return expression.NewGetFieldWithTable(idx, sql.Int64, "bar", "b", false)
+	}
+	k := func(idx int) sql.Expression {
+		return expression.NewGetFieldWithTable(idx, sql.Int64, "bar", "k", false)
+	}
+	one := expression.NewLiteral(int64(1), sql.Int64)
+	exists := func(n sql.Node) sql.Expression {
+		return plan.NewExistsSubquery(plan.NewSubquery(plan.NewLimit(1, n), ""))
+	}
+	in := func(n sql.Node) sql.Expression {
+		return plan.NewInSubquery(a, plan.NewSubquery(n, ""))
+	}
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "exists",
+			node: plan.NewFilter(
+				exists(plan.NewFilter(expression.NewAnd(
+					expression.NewEquals(k(2), a),
+					expression.NewGreaterThan(b(1), one),
+				), bar)),
+				foo,
+			),
+			expected: plan.NewSemiJoin(
+				foo,
+				plan.NewFilter(expression.NewGreaterThan(b(0), one), bar),
+				expression.NewEquals(k(2), a),
+			),
+		},
+		{
+			name: "not exists",
+			node: plan.NewFilter(
+				expression.NewAnd(
+					expression.NewGreaterThan(a, one),
+					expression.NewNot(exists(plan.NewFilter(expression.NewEquals(k(2), a), bar))),
+				),
+				foo,
+			),
+			expected: plan.NewAntiJoin(
+				plan.NewFilter(expression.NewGreaterThan(a, one), foo),
+				bar,
+				expression.NewEquals(k(2), a),
+			),
+		},
+		{
+			name: "in",
+			node: plan.NewFilter(
+				in(plan.NewProject(
+					[]sql.Expression{b(1)},
+					plan.NewFilter(expression.NewEquals(k(2), a), bar),
+				)),
+				foo,
+			),
+			expected: plan.NewSemiJoin(
+				foo,
+				bar,
+				expression.NewAnd(expression.NewEquals(k(2), a), expression.NewEquals(a, b(1))),
+			),
+		},
+		{
+			name: "uncorrelated",
+			node: plan.NewFilter(
+				exists(plan.NewFilter(expression.NewGreaterThan(b(1), one), bar)),
+				foo,
+			),
+		},
+		{
+			name: "not in",
+			node: plan.NewFilter(
+				expression.NewNot(in(plan.NewProject(
+					[]sql.Expression{b(1)},
+					plan.NewFilter(expression.NewEquals(k(2), a), bar),
+				))),
+				foo,
+			),
+		},
+		{
+			name: "table of the outer query",
+			node: plan.NewFilter(
+				exists(plan.NewFilter(
+					expression.NewEquals(expression.NewGetFieldWithTable(1, sql.Int64, "foo", "a", false), a),
+					foo,
+				)),
+				foo,
+			),
+		},
+		{
+			name: "subquery",
+			node: plan.NewFilter(
+				exists(plan.NewFilter(expression.NewEquals(k(2), a), bar)),
+				foo,
+			),
+			scope: newScope(plan.NewProject([]sql.Expression{b(0)}, bar)),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, NewDefault(sql.NewCatalog()), getRule("decorrelate_subqueries"))
+}
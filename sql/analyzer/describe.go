@@ -0,0 +1,29 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// describedNode returns the node described by the node given if it's a DescribeQuery, or the node itself otherwise.
+// Rules that leave data modification statements alone look at the described node, so that EXPLAIN of an INSERT,
+// UPDATE or DELETE shows the plan the statement is executed with.
+func describedNode(n sql.Node) sql.Node {
+	if describe, ok := n.(*plan.DescribeQuery); ok {
+		return describe.Child
+	}
+	return n
+}
+
+// estimateDescribedCosts adds the estimated costs and numbers of rows of the nodes of the plans described with the
+// cost format to their DescribeQuery nodes, once the plans don't change anymore.
+func estimateDescribedCosts(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		describe, ok := n.(*plan.DescribeQuery)
+		if !ok || describe.Format != plan.DescribeFormatCost {
+			return n, nil
+		}
+		costs := newCostEstimator(ctx, a, describe.Child)
+		return describe.WithEstimate(costs.estimate(describe.Child)), nil
+	})
+}
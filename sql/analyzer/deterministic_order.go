@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// sortUnorderedResults sorts the rows of queries without an order by all their columns when the analyzer has
+// DeterministicOrder set, so their results are always returned in the same order. The results of queries with an
+// order are left alone, since sorts are stable and the rows they read come in a deterministic order without
+// parallelism.
+func sortUnorderedResults(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if !a.DeterministicOrder || !n.Resolved() || len(scope.Schema()) > 0 {
+		return n, nil
+	}
+
+	switch n := n.(type) {
+	case *plan.QueryProcess:
+		child, err := sortUnorderedResults(ctx, a, n.Child, scope)
+		if err != nil {
+			return nil, err
+		}
+		return n.WithChildren(child)
+	case *plan.DescribeQuery:
+		child, err := sortUnorderedResults(ctx, a, n.Child, scope)
+		if err != nil {
+			return nil, err
+		}
+		return n.WithChildren(child)
+	}
+
+	ordered, query := isOrderedResult(n)
+	if ordered || !query || len(n.Schema()) == 0 {
+		return n, nil
+	}
+
+	a.Log("sorting unordered results of node of type %T", n)
+
+	var sortFields []plan.SortField
+	for i, col := range n.Schema() {
+		sortFields = append(sortFields, plan.SortField{
+			Column:       expression.NewGetFieldWithTable(i, col.Type, col.Source, col.Name, col.Nullable),
+			Order:        plan.Ascending,
+			NullOrdering: plan.NullsFirst,
+		})
+	}
+	return plan.NewSort(sortFields, n), nil
+}
+
+// isOrderedResult returns whether the rows of the node given come sorted, and whether it's a query at all, following
+// the nodes that keep the order of their child down to a sort or a table.
+func isOrderedResult(n sql.Node) (ordered bool, query bool) {
+	switch n := n.(type) {
+	case *plan.Sort:
+		return true, true
+	case *plan.ResolvedTable:
+		return isOrderedTable(n), true
+	case *plan.Project, *plan.Filter, *plan.Having, *plan.Limit, *plan.Offset, *plan.Distinct, *plan.OrderedDistinct,
+		*plan.SubqueryAlias, *plan.TableAlias, *plan.DecoratedNode, *plan.QueryProcess, *plan.Exchange, *plan.QueryHints:
+		return isOrderedResult(n.Children()[0])
+	case *plan.GroupBy, *plan.Union, *plan.IndexedTableAccess:
+		return false, true
+	default:
+		return false, plan.IsBinary(n)
+	}
+}
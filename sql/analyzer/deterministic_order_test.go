@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestSortUnorderedResults(t *testing.T) {
+	rule := getRuleFrom(OnceAfterAll, "sort_unordered_results")
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.Text, Source: "t", Nullable: true},
+	})
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "t", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.Text, "t", "b", true)
+
+	sortAll := func(child sql.Node) sql.Node {
+		return plan.NewSort([]plan.SortField{
+			{Column: a, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+			{Column: b, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+		}, child)
+	}
+	ordered := memory.NewPushdownTable("t", table.Schema()).WithOrder([]sql.SortColumn{{Column: "a"}})
+	sortByB := plan.NewSort([]plan.SortField{{Column: b, Order: plan.Descending}}, plan.NewResolvedTable(table))
+
+	testCases := []struct {
+		name          string
+		deterministic bool
+		node          sql.Node
+		expected      sql.Node
+	}{
+		{
+			"unordered query",
+			true,
+			plan.NewQueryProcess(plan.NewProject([]sql.Expression{a, b}, plan.NewResolvedTable(table)), nil),
+			plan.NewQueryProcess(sortAll(plan.NewProject([]sql.Expression{a, b}, plan.NewResolvedTable(table))), nil),
+		},
+		{
+			"limit of a group by",
+			true,
+			plan.NewLimit(1, plan.NewGroupBy([]sql.Expression{a, b}, []sql.Expression{a, b}, plan.NewResolvedTable(table))),
+			sortAll(plan.NewLimit(1, plan.NewGroupBy([]sql.Expression{a, b}, []sql.Expression{a, b}, plan.NewResolvedTable(table)))),
+		},
+		{
+			"ordered query",
+			true,
+			plan.NewQueryProcess(plan.NewLimit(1, plan.NewProject([]sql.Expression{a, b}, sortByB)), nil),
+			plan.NewQueryProcess(plan.NewLimit(1, plan.NewProject([]sql.Expression{a, b}, sortByB)), nil),
+		},
+		{
+			"ordered table",
+			true,
+			plan.NewResolvedTable(ordered),
+			plan.NewResolvedTable(ordered),
+		},
+		{
+			"not a query",
+			true,
+			plan.NewQueryProcess(plan.NewShowTables(plan.NewDummyResolvedDB("db"), false, nil), nil),
+			plan.NewQueryProcess(plan.NewShowTables(plan.NewDummyResolvedDB("db"), false, nil), nil),
+		},
+		{
+			"without deterministic order",
+			false,
+			plan.NewProject([]sql.Expression{a, b}, plan.NewResolvedTable(table)),
+			plan.NewProject([]sql.Expression{a, b}, plan.NewResolvedTable(table)),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			result, err := rule.Apply(sql.NewEmptyContext(), &Analyzer{DeterministicOrder: tt.deterministic}, tt.node, nil)
+			require.NoError(err)
+			require.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestParallelizeDeterministicOrder(t *testing.T) {
+	require := require.New(t)
+	rule := getRuleFrom(OnceAfterAll, "parallelize")
+	node := plan.NewProject(nil, plan.NewResolvedTable(memory.NewTable("t", nil)))
+
+	result, err := rule.Apply(sql.NewEmptyContext(), &Analyzer{Parallelism: 2, DeterministicOrder: true}, node, nil)
+	require.NoError(err)
+	require.Equal(node, result)
+}
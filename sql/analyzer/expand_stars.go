@@ -18,6 +18,13 @@ func expandStars(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.N
 		return nil, err
 	}
 
+	// Stars only expand to the columns the user is granted with partial star expansion, otherwise reading the others
+	// fails the query in check_column_privileges
+	var restrictions map[string]columnRestriction
+	if a != nil && a.PartialStarExpansion && a.Catalog != nil && a.Catalog.HasColumnPrivileges() {
+		restrictions = columnRestrictions(ctx, a, n, scope)
+	}
+
 	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		if n.Resolved() {
 			return n, nil
@@ -29,7 +36,7 @@ func expandStars(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.N
 				return n, nil
 			}
 
-			expressions, err := expandStarsForExpressions(a, n.Projections, n.Child.Schema(), tableAliases)
+			expressions, err := expandStarsForExpressions(a, n.Projections, n.Child.Schema(), tableAliases, restrictions)
 			if err != nil {
 				return nil, err
 			}
@@ -40,7 +47,7 @@ func expandStars(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.N
 				return n, nil
 			}
 
-			aggregate, err := expandStarsForExpressions(a, n.SelectedExprs, n.Child.Schema(), tableAliases)
+			aggregate, err := expandStarsForExpressions(a, n.SelectedExprs, n.Child.Schema(), tableAliases, restrictions)
 			if err != nil {
 				return nil, err
 			}
@@ -52,25 +59,35 @@ func expandStars(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.N
 	})
 }
 
-func expandStarsForExpressions(a *Analyzer, exprs []sql.Expression, schema sql.Schema, tableAliases TableAliases) ([]sql.Expression, error) {
+func expandStarsForExpressions(a *Analyzer, exprs []sql.Expression, schema sql.Schema, tableAliases TableAliases, restrictions map[string]columnRestriction) ([]sql.Expression, error) {
 	var expressions []sql.Expression
 	for _, e := range exprs {
 		if s, ok := e.(*expression.Star); ok {
-			var exprs []sql.Expression
+			var exprs, granted []sql.Expression
 			for i, col := range schema {
 				lowerSource := strings.ToLower(col.Source)
 				lowerTable := strings.ToLower(s.Table)
 				if s.Table == "" || lowerTable == lowerSource ||
 					(tableAliases[lowerSource] != nil && strings.ToLower(tableAliases[lowerSource].Name()) == lowerTable) {
-					exprs = append(exprs, expression.NewGetFieldWithTable(
-						i, col.Type, col.Source, col.Name, col.Nullable,
-					))
+					gf := expression.NewGetFieldWithTable(i, col.Type, col.Source, col.Name, col.Nullable)
+					exprs = append(exprs, gf)
+					if r, ok := restrictions[lowerSource]; !ok || r.granted[strings.ToLower(col.Name)] {
+						granted = append(granted, gf)
+					}
 				}
 			}
 
+			// Stars without any granted column aren't expanded partially, so they fail for the columns read
+			if len(granted) > 0 {
+				exprs = granted
+			}
+
 			if len(exprs) == 0 && s.Table != "" {
 				return nil, sql.ErrTableNotFound.New(s.Table)
 			}
+			if len(schema) == 0 {
+				return nil, sql.ErrNoTablesUsed.New()
+			}
 
 			expressions = append(expressions, exprs...)
 		} else {
@@ -1,8 +1,8 @@
 package analyzer
 
 import (
-	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql/plan"
 
@@ -18,64 +18,53 @@ func (f filtersByTable) merge(f2 filtersByTable) {
 	}
 }
 
-// getFiltersByTable returns a map of table name to filter expressions on that table for the node provided. Returns an
-// error only the case that the filters contained in the node given cannot all be separated into tables (some of them
-// have more than one table, or no table)
-func getFiltersByTable(_ *sql.Context, n sql.Node) (filtersByTable, error) {
+// getFiltersByTable returns a map of table name to filter expressions on that table for the node provided. Filter
+// expressions are split at AND, and only the parts that reference the columns of a single table are returned. The
+// other parts, such as join conditions or expressions without columns, are left as residual predicates in their
+// filter nodes.
+func getFiltersByTable(_ *sql.Context, n sql.Node) filtersByTable {
 	filters := make(filtersByTable)
-	var err error
 	plan.Inspect(n, func(node sql.Node) bool {
-		if err != nil {
-			return false
-		}
-
 		switch node := node.(type) {
 		case *plan.Filter:
-			var fs filtersByTable
-			fs, err = exprToTableFilters(node.Expression)
-			if err != nil {
-				return false
-			}
-			filters.merge(fs)
+			filters.merge(exprToTableFilters(node.Expression))
 		}
 		return true
 	})
 
-	if err != nil {
-		return nil, err
-	}
-
-	return filters, err
+	return filters
 }
 
 // exprToTableFilters returns a map of table name to filter expressions on that table for all parts of the expression
-// given, split at AND. Returns an error only the case that the expressions cannot all be separated into tables (some
-// of them have more than one table, or no table)
-func exprToTableFilters(expr sql.Expression) (filtersByTable, error) {
+// given, split at AND. Parts that reference more than one table, or no table, are not returned.
+func exprToTableFilters(expr sql.Expression) filtersByTable {
 	filtersByTable := make(filtersByTable)
 	for _, expr := range splitConjunction(expr) {
-		var seenTables = make(map[string]bool)
-		var lastTable string
-		sql.Inspect(expr, func(e sql.Expression) bool {
-			f, ok := e.(*expression.GetField)
-			if ok {
-				if !seenTables[f.Table()] {
-					seenTables[f.Table()] = true
-					lastTable = f.Table()
-				}
-			}
+		if table, ok := filterTable(expr); ok {
+			filtersByTable[table] = append(filtersByTable[table], expr)
+		}
+	}
 
-			return true
-		})
+	return filtersByTable
+}
 
-		if len(seenTables) == 1 {
-			filtersByTable[lastTable] = append(filtersByTable[lastTable], expr)
-		} else {
-			return nil, fmt.Errorf("didn't find table for expression %s", expr.String())
+// filterTable returns the only table referenced by the filter expression given, and whether there's exactly one.
+func filterTable(expr sql.Expression) (string, bool) {
+	var seenTables = make(map[string]bool)
+	var lastTable string
+	sql.Inspect(expr, func(e sql.Expression) bool {
+		f, ok := e.(*expression.GetField)
+		if ok {
+			if !seenTables[f.Table()] {
+				seenTables[f.Table()] = true
+				lastTable = f.Table()
+			}
 		}
-	}
 
-	return filtersByTable, nil
+		return true
+	})
+
+	return lastTable, len(seenTables) == 1
 }
 
 type filterSet struct {
@@ -106,13 +95,24 @@ func (fs *filterSet) availableFiltersForTable(table string) []sql.Expression {
 	return fs.subtractUsedIndexes(subtractExprSet(filters, fs.handledFilters))
 }
 
-// availableFilters returns the filters that are still available (not previously marked handled)
-func (fs *filterSet) availableFilters() []sql.Expression {
-	var available []sql.Expression
-	for _, es := range fs.filtersByTable {
-		available = append(available, fs.subtractUsedIndexes(subtractExprSet(es, fs.handledFilters))...)
+// unhandledFilters returns the filters given that haven't been marked handled. Filters that couldn't be assigned to a
+// single table are never handled, so they are always returned. The filters given are compared to the handled ones by
+// their string representations, since the field indexes of a filter node may have been fixed after its predicates
+// were collected.
+func (fs *filterSet) unhandledFilters(filters []sql.Expression) []sql.Expression {
+	handled := make(map[string]bool, len(fs.handledFilters))
+	for _, f := range fs.handledFilters {
+		handled[f.String()] = true
 	}
-	return available
+
+	var unhandled []sql.Expression
+	for _, f := range filters {
+		if !handled[f.String()] {
+			unhandled = append(unhandled, f)
+		}
+	}
+
+	return fs.subtractUsedIndexes(unhandled)
 }
 
 // handledCount returns the number of filter expressions that have been marked as handled
@@ -134,6 +134,46 @@ func (fs *filterSet) markIndexesHandled(indexes []sql.Index) {
 	}
 }
 
+// supportedFilters returns the filters given that a table with the capabilities given can evaluate.
+func supportedFilters(capabilities sql.FilterCapabilities, filters []sql.Expression) []sql.Expression {
+	columns := make(map[string]bool, len(capabilities.Columns))
+	for _, c := range capabilities.Columns {
+		columns[strings.ToLower(c)] = true
+	}
+
+	var supported []sql.Expression
+	for _, f := range filters {
+		if isSupportedFilter(capabilities, columns, f) {
+			supported = append(supported, f)
+		}
+	}
+
+	return supported
+}
+
+func isSupportedFilter(capabilities sql.FilterCapabilities, columns map[string]bool, filter sql.Expression) bool {
+	supported := true
+	sql.Inspect(filter, func(e sql.Expression) bool {
+		switch e := e.(type) {
+		case *expression.GetField:
+			if len(columns) > 0 && !columns[strings.ToLower(e.Name())] {
+				supported = false
+			}
+		case nil, *expression.Literal, expression.Tuple,
+			*expression.Equals, *expression.GreaterThan, *expression.LessThan,
+			*expression.GreaterThanOrEqual, *expression.LessThanOrEqual, *expression.InTuple, *expression.IsNull,
+			*expression.And, *expression.Or, *expression.Not:
+		default:
+			if capabilities.ComparisonsOnly {
+				supported = false
+			}
+		}
+		return supported
+	})
+
+	return supported
+}
+
 // splitConjunction breaks AND expressions into their left and right parts, recursively
 func splitConjunction(expr sql.Expression) []sql.Expression {
 	and, ok := expr.(*expression.And)
@@ -164,32 +164,34 @@ func TestExprToTableFilters(t *testing.T) {
 		},
 	}
 
-	filters, err := exprToTableFilters(expr)
-	require.NoError(t, err)
+	filters := exprToTableFilters(expr)
 	assert.Equal(t, expected, filters)
 
-	// Test various error conditions -- anytime we can't neatly split the expressions into tables
-	_, err = exprToTableFilters(expression.NewAnd(
-		lit(0),
-		expression.NewGetFieldWithTable(0, sql.Int64, "mytable", "f", false),
-	))
-	assert.Error(t, err)
+	// Anytime we can't neatly split the expressions into tables, the parts that can't be assigned to a single table
+	// are left out as residual predicates
+	f := expression.NewGetFieldWithTable(0, sql.Int64, "mytable", "f", false)
+	onlyF := filtersByTable{"mytable": []sql.Expression{f}}
 
-	_, err = exprToTableFilters(expression.NewAnd(
-		expression.NewLiteral(nil, sql.Null),
-		expression.NewGetFieldWithTable(0, sql.Int64, "mytable", "f", false),
-	))
-	assert.Error(t, err)
+	filters = exprToTableFilters(expression.NewAnd(lit(0), f))
+	assert.Equal(t, onlyF, filters)
 
-	_, err = exprToTableFilters(expression.NewAnd(
+	filters = exprToTableFilters(expression.NewAnd(expression.NewLiteral(nil, sql.Null), f))
+	assert.Equal(t, onlyF, filters)
+
+	filters = exprToTableFilters(expression.NewAnd(
 		expression.NewEquals(lit(1), mustExpr(function.NewRand())),
-		expression.NewGetFieldWithTable(0, sql.Int64, "mytable", "f", false),
+		f,
 	))
-	assert.Error(t, err)
+	assert.Equal(t, onlyF, filters)
 
-	_, err = exprToTableFilters(expression.NewOr(
-		expression.NewLiteral(nil, sql.Null),
-		expression.NewGetFieldWithTable(0, sql.Int64, "mytable", "f", false),
+	filters = exprToTableFilters(expression.NewAnd(
+		expression.NewEquals(f, expression.NewGetFieldWithTable(0, sql.Int64, "mytable2", "i2", false)),
+		f,
 	))
-	assert.NoError(t, err)
+	assert.Equal(t, onlyF, filters)
+
+	filters = exprToTableFilters(expression.NewOr(expression.NewLiteral(nil, sql.Null), f))
+	assert.Equal(t, filtersByTable{
+		"mytable": []sql.Expression{expression.NewOr(expression.NewLiteral(nil, sql.Null), f)},
+	}, filters)
 }
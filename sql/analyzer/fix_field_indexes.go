@@ -23,33 +23,57 @@ func FixFieldIndexesOnExpressions(schema sql.Schema, expressions ...sql.Expressi
 // according to the schema given. Used when combining multiple tables together into a single join result, or when
 // otherwise changing / combining schemas in the node tree.
 func FixFieldIndexes(schema sql.Schema, exp sql.Expression) (sql.Expression, error) {
-	return expression.TransformUp(exp, func(e sql.Expression) (sql.Expression, error) {
+	e, _, err := fixFieldIndexes(schema, exp)
+	return e, err
+}
+
+// fixFieldIndexes is the implementation of FixFieldIndexes. GetField expressions that already have the right index
+// are left as they are, so the expression is only rebuilt if some index was wrong.
+func fixFieldIndexes(schema sql.Schema, exp sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+	return expression.TransformUpWithIdentity(exp, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
 		switch e := e.(type) {
 		case *expression.GetField:
 			// we need to rewrite the indexes for the table row
 			for i, col := range schema {
 				if e.Name() == col.Name && e.Table() == col.Source {
+					if i == e.Index() {
+						return e, sql.SameTree, nil
+					}
 					return expression.NewGetFieldWithTable(
 						i,
 						e.Type(),
 						e.Table(),
 						e.Name(),
 						e.IsNullable(),
-					), nil
+					), sql.NewTree, nil
 				}
 			}
 
-			return nil, ErrFieldMissing.New(e.Name())
+			return nil, sql.SameTree, ErrFieldMissing.New(e.Name())
 		}
 
-		return e, nil
+		return e, sql.SameTree, nil
 	})
 }
 
 // Transforms the expressions in the Node given, fixing the field indexes.
 func FixFieldIndexesForExpressions(node sql.Node) (sql.Node, error) {
+	n, _, err := fixFieldIndexesForExpressions(node)
+	return n, err
+}
+
+// fixFieldIndexesForExpressions is the implementation of FixFieldIndexesForExpressions. The node is only rebuilt if
+// one of its expressions changed.
+func fixFieldIndexesForExpressions(node sql.Node) (sql.Node, sql.TreeIdentity, error) {
+	switch j := node.(type) {
+	case *plan.IndexedJoin:
+		return fixIndexedJoinFieldIndexes(j)
+	case *plan.HashJoin:
+		return fixHashJoinFieldIndexes(j)
+	}
+
 	if _, ok := node.(sql.Expressioner); !ok {
-		return node, nil
+		return node, sql.SameTree, nil
 	}
 
 	var schemas []sql.Schema
@@ -58,53 +82,172 @@ func FixFieldIndexesForExpressions(node sql.Node) (sql.Node, error) {
 	}
 
 	if len(schemas) < 1 {
-		return node, nil
+		return node, sql.SameTree, nil
 	}
 
-	n, err := plan.TransformExpressions(node, func(e sql.Expression) (sql.Expression, error) {
+	n, identity, err := plan.TransformExpressionsWithIdentity(node, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
 		for _, schema := range schemas {
-			fixed, err := FixFieldIndexes(schema, e)
+			fixed, same, err := fixFieldIndexes(schema, e)
 			if err == nil {
-				return fixed, nil
+				return fixed, same, nil
 			}
 
 			if ErrFieldMissing.Is(err) {
 				continue
 			}
 
-			return nil, err
+			return nil, sql.SameTree, err
 		}
 
-		return e, nil
+		return e, sql.SameTree, nil
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, sql.SameTree, err
 	}
 
 	switch j := n.(type) {
 	case *plan.InnerJoin:
-		cond, err := FixFieldIndexes(j.Schema(), j.Cond)
+		cond, same, err := fixFieldIndexes(j.Schema(), j.Cond)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
 
-		n = plan.NewInnerJoin(j.Left, j.Right, cond)
+		if !same {
+			n, identity = plan.NewInnerJoin(j.Left, j.Right, cond), sql.NewTree
+		}
 	case *plan.RightJoin:
-		cond, err := FixFieldIndexes(j.Schema(), j.Cond)
+		cond, same, err := fixFieldIndexes(j.Schema(), j.Cond)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
 
-		n = plan.NewRightJoin(j.Left, j.Right, cond)
+		if !same {
+			n, identity = plan.NewRightJoin(j.Left, j.Right, cond), sql.NewTree
+		}
 	case *plan.LeftJoin:
-		cond, err := FixFieldIndexes(j.Schema(), j.Cond)
+		cond, same, err := fixFieldIndexes(j.Schema(), j.Cond)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
+		}
+
+		if !same {
+			n, identity = plan.NewLeftJoin(j.Left, j.Right, cond), sql.NewTree
+		}
+	case *plan.SemiJoin:
+		// Semi and anti joins only return the rows of their left side, but their condition is evaluated on the rows of
+		// both sides
+		cond, same, err := fixFieldIndexes(append(j.Left.Schema(), j.Right.Schema()...), j.Cond)
+		if err != nil {
+			return nil, sql.SameTree, err
 		}
 
-		n = plan.NewLeftJoin(j.Left, j.Right, cond)
+		if !same {
+			n, identity = plan.NewSemiJoin(j.Left, j.Right, cond), sql.NewTree
+		}
+	case *plan.AntiJoin:
+		cond, same, err := fixFieldIndexes(append(j.Left.Schema(), j.Right.Schema()...), j.Cond)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+
+		if !same {
+			n, identity = plan.NewAntiJoin(j.Left, j.Right, cond), sql.NewTree
+		}
+	}
+
+	return n, identity, nil
+}
+
+// fixIndexedJoinFieldIndexes fixes the field indexes of an IndexedJoin: its condition and residual condition are
+// evaluated on the rows of both tables, even for semi and anti joins, and its primary table expressions and the bounds
+// of its key range only on the rows of the primary table. As for any other node, expressions with fields missing from
+// those schemas are left untouched.
+func fixIndexedJoinFieldIndexes(j *plan.IndexedJoin) (sql.Node, sql.TreeIdentity, error) {
+	conds := 1
+	if j.Residual() != nil {
+		conds++
+	}
+	exprs := j.Expressions()
+
+	condExprs, identity, err := fixFieldIndexesOfExpressions(append(j.Left.Schema(), j.Right.Schema()...), exprs[:conds])
+	if err != nil {
+		return nil, sql.SameTree, err
+	}
+
+	primaryExprs, primarySame, err := fixFieldIndexesOfExpressions(j.Left.Schema(), exprs[conds:])
+	if err != nil {
+		return nil, sql.SameTree, err
 	}
 
-	return n, nil
+	if identity && primarySame {
+		return j, sql.SameTree, nil
+	}
+
+	node, err := j.WithExpressions(append(condExprs, primaryExprs...)...)
+	if err != nil {
+		return nil, sql.SameTree, err
+	}
+	return node, sql.NewTree, nil
+}
+
+// fixHashJoinFieldIndexes fixes the field indexes of a HashJoin: its condition is evaluated on the rows of both sides,
+// even for semi and anti joins, and its primary and secondary keys only on the rows of the primary and secondary
+// sides. As for any other node, expressions with fields missing from those schemas are left untouched.
+func fixHashJoinFieldIndexes(j *plan.HashJoin) (sql.Node, sql.TreeIdentity, error) {
+	cond, identity, err := fixFieldIndexesIfPresent(append(j.Left.Schema(), j.Right.Schema()...), j.Cond)
+	if err != nil {
+		return nil, sql.SameTree, err
+	}
+
+	primaryKeys, primarySame, err := fixFieldIndexesOfExpressions(j.Left.Schema(), j.PrimaryKeys())
+	if err != nil {
+		return nil, sql.SameTree, err
+	}
+
+	secondaryKeys, secondarySame, err := fixFieldIndexesOfExpressions(j.Right.Schema(), j.SecondaryKeys())
+	if err != nil {
+		return nil, sql.SameTree, err
+	}
+
+	if identity && primarySame && secondarySame {
+		return j, sql.SameTree, nil
+	}
+
+	return plan.NewHashJoin(j.Left, j.Right, j.JoinType(), cond, primaryKeys, secondaryKeys), sql.NewTree, nil
+}
+
+// fixFieldIndexesOfExpressions fixes the field indexes of the expressions given on the schema given, leaving those
+// with fields missing from it untouched. The expressions given are returned if none of them changed.
+func fixFieldIndexesOfExpressions(schema sql.Schema, exprs []sql.Expression) ([]sql.Expression, sql.TreeIdentity, error) {
+	var result []sql.Expression
+	for i, e := range exprs {
+		fixed, same, err := fixFieldIndexesIfPresent(schema, e)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+
+		if !same {
+			if result == nil {
+				result = make([]sql.Expression, len(exprs))
+				copy(result, exprs)
+			}
+			result[i] = fixed
+		}
+	}
+
+	if result == nil {
+		return exprs, sql.SameTree, nil
+	}
+	return result, sql.NewTree, nil
+}
+
+// fixFieldIndexesIfPresent works like fixFieldIndexes, but returns the expression unchanged if any of its fields is
+// missing from the schema given.
+func fixFieldIndexesIfPresent(schema sql.Schema, e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+	fixed, same, err := fixFieldIndexes(schema, e)
+	if ErrFieldMissing.Is(err) {
+		return e, sql.SameTree, nil
+	}
+	return fixed, same, err
 }
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyForeignKeys sets the foreign keys enforced on the rows that INSERT, REPLACE, UPDATE and DELETE statements write
+// to tables that declare foreign keys, or that are referenced by the foreign keys of the tables of their database.
+// TRUNCATE is given the foreign keys of the tables referencing the table it truncates, which it can't truncate then.
+func applyForeignKeys(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("apply_foreign_keys")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		switch node := node.(type) {
+		case *plan.InsertInto:
+			fks, err := enforcedForeignKeys(ctx, a, node.Left)
+			if err != nil || fks == nil {
+				return node, err
+			}
+			nc := *node
+			nc.ForeignKeys = fks
+			return &nc, nil
+		case *plan.Update:
+			fks, err := enforcedForeignKeys(ctx, a, node.Child)
+			if err != nil || fks == nil {
+				return node, err
+			}
+			nc := *node
+			nc.ForeignKeys = fks
+			return &nc, nil
+		case *plan.DeleteFrom:
+			fks, err := enforcedForeignKeys(ctx, a, node.Child)
+			if err != nil || fks == nil {
+				return node, err
+			}
+			nc := *node
+			nc.ForeignKeys = fks
+			return &nc, nil
+		case *plan.Truncate:
+			fks, err := enforcedForeignKeys(ctx, a, node.Child)
+			if err != nil || fks == nil {
+				return node, err
+			}
+			nc := *node
+			nc.ForeignKeys = fks
+			return &nc, nil
+		default:
+			return node, nil
+		}
+	})
+}
+
+// enforcedForeignKeys returns the foreign keys of the database of the table written by the node given, or nil if there
+// are none to enforce on its rows.
+func enforcedForeignKeys(ctx *sql.Context, a *Analyzer, n sql.Node) (*plan.ForeignKeys, error) {
+	rt := getResolvedTable(n)
+	if rt == nil {
+		return nil, nil
+	}
+
+	db := rt.Database
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+	database, err := a.Catalog.SessionDatabase(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	fks := plan.NewForeignKeys(database)
+	enforced, err := fks.Enforced(ctx, rt.Table)
+	if err != nil || !enforced {
+		return nil, err
+	}
+
+	if fks.Checks, err = foreignKeyChecks(ctx, a, database); err != nil {
+		return nil, err
+	}
+
+	a.Log("enforcing the foreign keys of table %q", rt.Name())
+	return fks, nil
+}
+
+// foreignKeyChecks returns the enforced check constraints of the tables of the database given, which the rows the
+// actions of foreign keys update must satisfy, keyed by their lower case names.
+func foreignKeyChecks(ctx *sql.Context, a *Analyzer, db sql.Database) (map[string]plan.CheckConstraints, error) {
+	names, err := db.GetTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make(map[string]plan.CheckConstraints)
+	for _, name := range names {
+		t, ok, err := db.GetTableInsensitive(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		tc, err := tableChecks(ctx, a, t)
+		if err != nil {
+			return nil, err
+		}
+		if len(tc) > 0 {
+			checks[strings.ToLower(t.Name())] = tc
+		}
+	}
+	return checks, nil
+}
@@ -0,0 +1,254 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+const (
+	// warnUnresolvedHintName is the code of the warnings of hints naming tables that aren't in their query block.
+	warnUnresolvedHintName = 3128
+	// warnHintNotAllowed is the code of the warnings of MAX_EXECUTION_TIME hints of query blocks other than the
+	// outermost one.
+	warnHintNotAllowed = 3125
+)
+
+// queryHints returns the optimizer hints of the query blocks of the node given, without those of its derived tables,
+// which are analyzed on their own.
+func queryHints(n sql.Node) sql.Hints {
+	var hints sql.Hints
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.QueryHints:
+			hints = append(hints, node.Hints...)
+		case *plan.SubqueryAlias:
+			return false
+		}
+		return true
+	})
+	return hints
+}
+
+// resolveHints removes the optimizer hints naming tables that aren't in their query block, and the
+// MAX_EXECUTION_TIME hints of query blocks other than the outermost one, with a warning, as MySQL does. Query blocks
+// left without hints lose their QueryHints node. The hints of derived tables are resolved with those of the query
+// block they're selected from, which is the only one that knows they aren't the outermost one.
+func resolveHints(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, ctx := ctx.Span("resolve_hints")
+	defer span.Finish()
+
+	// Queries described by EXPLAIN keep their MAX_EXECUTION_TIME hints, so they're described as they're run
+	if describe, ok := n.(*plan.DescribeQuery); ok {
+		child, err := resolveHints(ctx, a, describe.Child, scope)
+		if err != nil {
+			return nil, err
+		}
+		return describe.WithChildren(child)
+	}
+
+	qh, ok := n.(*plan.QueryHints)
+	if !ok {
+		return resolveNestedHints(ctx, n)
+	}
+
+	child, err := resolveNestedHints(ctx, qh.Child)
+	if err != nil {
+		return nil, err
+	}
+	return resolveQueryHints(ctx, plan.NewQueryHints(qh.Hints, child), scope == nil), nil
+}
+
+// resolveNestedHints resolves the hints of the query blocks of the node given and of its derived tables, none of
+// which is the outermost one.
+func resolveNestedHints(ctx *sql.Context, n sql.Node) (sql.Node, error) {
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		switch node := node.(type) {
+		case *plan.QueryHints:
+			return resolveQueryHints(ctx, node, false), nil
+		case *plan.SubqueryAlias:
+			child, err := resolveNestedHints(ctx, node.Child)
+			if err != nil {
+				return nil, err
+			}
+			if child == node.Child {
+				return node, nil
+			}
+			return node.WithChildren(child)
+		default:
+			return node, nil
+		}
+	})
+}
+
+// resolveQueryHints returns the node given without the hints it can't use, or its child if it can't use any.
+func resolveQueryHints(ctx *sql.Context, qh *plan.QueryHints, outermost bool) sql.Node {
+	tables := tableSet(hintTables(qh.Child))
+
+	var hints sql.Hints
+Hints:
+	for _, hint := range qh.Hints {
+		if hint.Name == sql.MaxExecutionTimeHint && !outermost {
+			ctx.Warn(warnHintNotAllowed, "%s hint is supported by top-level standalone SELECT statements only", hint.Name)
+			continue
+		}
+
+		if def, ok := sql.LookupHint(hint.Name); ok && def.Arguments != sql.HintNumber {
+			for _, t := range hint.Tables() {
+				if !tables[t] {
+					ctx.Warn(warnUnresolvedHintName, "Unresolved name `%s` for %s hint", t, hint.Name)
+					continue Hints
+				}
+			}
+		}
+		hints = append(hints, hint)
+	}
+
+	if len(hints) == 0 {
+		return qh.Child
+	}
+	return plan.NewQueryHints(hints, qh.Child)
+}
+
+// hintTables returns the lower case names of the tables and derived tables of the node given, by which hints name
+// them. The tables of derived tables may not be resolved yet.
+func hintTables(n sql.Node) []string {
+	var tables []string
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.TableAlias, *plan.SubqueryAlias:
+			tables = append(tables, strings.ToLower(node.(sql.Nameable).Name()))
+			return false
+		case *plan.ResolvedTable, *plan.UnresolvedTable:
+			tables = append(tables, strings.ToLower(node.(sql.Nameable).Name()))
+		}
+		return true
+	})
+	return tables
+}
+
+// mergeDerivedTables merges the derived tables that the MERGE hints of their query block name into it, so they're
+// joined like any other table. Only derived tables selecting columns of a single table, optionally filtered, can be
+// merged: their projection and filter are kept above the table, aliased with the name of the derived table. Derived
+// tables are never merged without a hint.
+func mergeDerivedTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("merge_derived_tables")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		qh, ok := node.(*plan.QueryHints)
+		if !ok {
+			return node, nil
+		}
+
+		selector := func(parent sql.Node, child sql.Node, childNum int) bool {
+			_, ok := parent.(*plan.SubqueryAlias)
+			return !ok
+		}
+		child, err := plan.TransformUpWithSelector(qh.Child, selector, func(node sql.Node) (sql.Node, error) {
+			sq, ok := node.(*plan.SubqueryAlias)
+			if !ok {
+				return node, nil
+			}
+			if hint, ok := qh.Hints.ForTable(strings.ToLower(sq.Name()), sql.MergeHint, sql.NoMergeHint); !ok || hint.Name != sql.MergeHint {
+				return node, nil
+			}
+
+			merged, ok, err := mergeDerivedTable(ctx, a, sq)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				a.Log("derived table %q can't be merged", sq.Name())
+				return node, nil
+			}
+			a.Log("merged derived table %q", sq.Name())
+			return merged, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return qh.WithChildren(child)
+	})
+}
+
+// mergeDerivedTable returns the projection and filter of the derived table given above its table, aliased with the
+// name of the derived table, or false if it selects anything else. Derived tables are analyzed on their own, so their
+// table is resolved here.
+func mergeDerivedTable(ctx *sql.Context, a *Analyzer, sq *plan.SubqueryAlias) (sql.Node, bool, error) {
+	project, ok := sq.Child.(*plan.Project)
+	if !ok || len(sq.Columns) > 0 {
+		return nil, false, nil
+	}
+
+	var filter *plan.Filter
+	table := project.Child
+	if f, ok := table.(*plan.Filter); ok {
+		filter, table = f, f.Child
+	}
+
+	name := getTableName(table)
+	if ta, ok := table.(*plan.TableAlias); ok {
+		table = ta.Child
+	}
+	ut, ok := table.(*plan.UnresolvedTable)
+	if !ok {
+		return nil, false, nil
+	}
+
+	// Columns of the table are renamed after the derived table, which the outer query block names them by
+	requalify := func(e sql.Expression) (sql.Expression, bool) {
+		ok := true
+		e, err := expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+			switch e := e.(type) {
+			case *expression.UnresolvedColumn:
+				if e.Table() != "" && !strings.EqualFold(e.Table(), name) {
+					ok = false
+				}
+				return expression.NewUnresolvedQualifiedColumn(sq.Name(), e.Name()), nil
+			case *expression.Star:
+				if e.Table != "" && !strings.EqualFold(e.Table, name) {
+					ok = false
+				}
+				return expression.NewQualifiedStar(sq.Name()), nil
+			case *plan.Subquery:
+				ok = false
+			}
+			return e, nil
+		})
+		return e, ok && err == nil
+	}
+
+	// Only plain columns keep the name of the derived table, so the outer query block can still name them
+	projections := make([]sql.Expression, len(project.Projections))
+	for i, p := range project.Projections {
+		switch p.(type) {
+		case *expression.UnresolvedColumn, *expression.Star:
+		default:
+			return nil, false, nil
+		}
+		if projections[i], ok = requalify(p); !ok {
+			return nil, false, nil
+		}
+	}
+
+	var cond sql.Expression
+	if filter != nil {
+		if cond, ok = requalify(filter.Expression); !ok {
+			return nil, false, nil
+		}
+	}
+
+	rt, err := resolveTable(ctx, a, ut)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var merged sql.Node = plan.NewTableAlias(sq.Name(), rt)
+	if cond != nil {
+		merged = plan.NewFilter(cond, merged)
+	}
+	return plan.NewProject(projections, merged), true, nil
+}
@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestResolveHints(t *testing.T) {
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "mytable"},
+	})
+	rt := plan.NewResolvedTable(table)
+	join := plan.NewCrossJoin(rt, plan.NewTableAlias("t", rt))
+
+	joinOrder := sql.Hint{Name: sql.JoinOrderHint, Args: []string{"t", "mytable"}}
+	maxTime := sql.Hint{Name: sql.MaxExecutionTimeHint, Args: []string{"1000"}}
+	unknownTable := sql.Hint{Name: sql.IndexHint, Args: []string{"other", "primary"}}
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "hints of tables of the query block",
+			node: plan.NewQueryHints(sql.Hints{joinOrder, maxTime}, join),
+		},
+		{
+			name:     "hints of other tables",
+			node:     plan.NewQueryHints(sql.Hints{unknownTable, joinOrder}, join),
+			expected: plan.NewQueryHints(sql.Hints{joinOrder}, join),
+		},
+		{
+			name:     "no hints left",
+			node:     plan.NewQueryHints(sql.Hints{unknownTable}, join),
+			expected: join,
+		},
+		{
+			name: "max execution time of a subquery",
+			node: plan.NewQueryHints(sql.Hints{maxTime, joinOrder}, join),
+			scope: newScope(plan.NewProject(
+				[]sql.Expression{expression.NewLiteral(1, sql.Int64)},
+				plan.NewResolvedTable(dualTable),
+			)),
+			expected: plan.NewQueryHints(sql.Hints{joinOrder}, join),
+		},
+		{
+			name:     "max execution time of a derived table",
+			node:     plan.NewSubqueryAlias("dt", "", plan.NewQueryHints(sql.Hints{maxTime}, rt)),
+			expected: plan.NewSubqueryAlias("dt", "", rt),
+		},
+		{
+			name:     "max execution time of a union",
+			node:     plan.NewUnion(plan.NewQueryHints(sql.Hints{maxTime}, rt), rt),
+			expected: plan.NewUnion(rt, rt),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, NewDefault(sql.NewCatalog()), getRule("resolve_hints"))
+}
+
+func TestResolveHintsWarnings(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "mytable"},
+	})
+	node := plan.NewUnion(
+		plan.NewQueryHints(sql.Hints{
+			{Name: sql.HashJoinHint, Args: []string{"mytable", "other"}},
+			{Name: sql.MaxExecutionTimeHint, Args: []string{"10"}},
+		}, plan.NewResolvedTable(table)),
+		plan.NewResolvedTable(table),
+	)
+
+	ctx := sql.NewEmptyContext()
+	_, err := getRule("resolve_hints").Apply(ctx, NewDefault(sql.NewCatalog()), node, nil)
+	require.NoError(err)
+
+	var messages []string
+	for _, w := range ctx.Warnings() {
+		messages = append(messages, w.Message)
+	}
+	require.ElementsMatch([]string{
+		"Unresolved name `other` for HASH_JOIN hint",
+		"MAX_EXECUTION_TIME hint is supported by top-level standalone SELECT statements only",
+	}, messages)
+}
+
+func TestMergeDerivedTables(t *testing.T) {
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "mytable"},
+		{Name: "s", Type: sql.Text, Source: "mytable"},
+	})
+	db := memory.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := NewDefault(catalog)
+
+	other := plan.NewResolvedTableInDatabase(table, "mydb")
+	derived := func(projections []sql.Expression, filter sql.Expression, child sql.Node) sql.Node {
+		if filter != nil {
+			child = plan.NewFilter(filter, child)
+		}
+		return plan.NewSubqueryAlias("dt", "", plan.NewProject(projections, child))
+	}
+	merge := sql.Hints{{Name: sql.MergeHint, Args: []string{"dt"}}}
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "filtered columns",
+			node: plan.NewQueryHints(merge, plan.NewCrossJoin(
+				other,
+				derived(
+					[]sql.Expression{expression.NewUnresolvedColumn("i"), expression.NewUnresolvedQualifiedColumn("mytable", "s")},
+					expression.NewEquals(expression.NewUnresolvedColumn("i"), expression.NewLiteral(1, sql.Int64)),
+					plan.NewUnresolvedTable("mytable", ""),
+				),
+			)),
+			expected: plan.NewQueryHints(merge, plan.NewCrossJoin(
+				other,
+				plan.NewProject(
+					[]sql.Expression{expression.NewUnresolvedQualifiedColumn("dt", "i"), expression.NewUnresolvedQualifiedColumn("dt", "s")},
+					plan.NewFilter(
+						expression.NewEquals(expression.NewUnresolvedQualifiedColumn("dt", "i"), expression.NewLiteral(1, sql.Int64)),
+						plan.NewTableAlias("dt", other),
+					),
+				),
+			)),
+		},
+		{
+			name: "aliased table",
+			node: plan.NewQueryHints(merge, derived(
+				[]sql.Expression{expression.NewQualifiedStar("t")},
+				nil,
+				plan.NewTableAlias("t", plan.NewUnresolvedTable("mytable", "")),
+			)),
+			expected: plan.NewQueryHints(merge, plan.NewProject(
+				[]sql.Expression{expression.NewQualifiedStar("dt")},
+				plan.NewTableAlias("dt", other),
+			)),
+		},
+	}
+
+	ctx := sql.NewEmptyContext().WithCurrentDB("mydb")
+	runTestCases(t, ctx, tests, a, getRule("merge_derived_tables"))
+
+	// Derived tables that aren't merged keep their projections unresolved, which have no schema yet
+	unmerged := []analyzerFnTestCase{
+		{
+			name: "computed columns",
+			node: plan.NewQueryHints(merge, derived(
+				[]sql.Expression{expression.NewLiteral(1, sql.Int64)},
+				nil,
+				plan.NewUnresolvedTable("mytable", ""),
+			)),
+		},
+		{
+			name: "subquery in the filter",
+			node: plan.NewQueryHints(merge, derived(
+				[]sql.Expression{expression.NewStar()},
+				plan.NewExistsSubquery(plan.NewSubquery(plan.NewUnresolvedTable("mytable", ""), "")),
+				plan.NewUnresolvedTable("mytable", ""),
+			)),
+		},
+		{
+			name: "no merge",
+			node: plan.NewQueryHints(sql.Hints{{Name: sql.NoMergeHint}, {Name: sql.MergeHint}}, derived(
+				[]sql.Expression{expression.NewStar()},
+				nil,
+				plan.NewUnresolvedTable("mytable", ""),
+			)),
+		},
+		{
+			name: "no hint",
+			node: derived(
+				[]sql.Expression{expression.NewStar()},
+				nil,
+				plan.NewUnresolvedTable("mytable", ""),
+			),
+		},
+	}
+
+	for _, tt := range unmerged {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getRule("merge_derived_tables").Apply(ctx, a, tt.node, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.node, result)
+		})
+	}
+}
@@ -15,17 +15,26 @@
 package analyzer
 
 import (
+	"strings"
+
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
 type indexAnalyzer struct {
 	// TODO: these need to be qualified by database name as well to be valid. Otherwise we can't distinguish between two
-	//  tables with the same name in different databases. But right now table nodes aren't qualified by their resolved
-	//  database in the plan, so we can't do this.
+	//  tables with the same name in different databases.
 	indexesByTable map[string][]sql.Index
-	indexRegistry  *sql.IndexRegistry
-	registryIdxes  []sql.Index
+	// databases are the names of the databases of the tables in the node, keyed by the lower case names and aliases
+	// of the tables, so indexes of tables of databases other than the current one can be found.
+	databases map[string]string
+	// tables are the names of the tables in the node, keyed by their lower case names and aliases.
+	tables map[string]string
+	// indexHints are the INDEX and NO_INDEX hints of the tables in the node, keyed by their lower case names.
+	indexHints    map[string]sql.Hint
+	indexRegistry *sql.IndexRegistry
+	registryIdxes []sql.Index
 }
 
 // getIndexesForNode returns an analyzer for indexes available in the node given. These might come from either the
@@ -33,12 +42,26 @@ type indexAnalyzer struct {
 func getIndexesForNode(ctx *sql.Context, a *Analyzer, n sql.Node) (*indexAnalyzer, error) {
 	var analysisErr error
 	indexes := make(map[string][]sql.Index)
+	databases := make(map[string]string)
+	tables := make(map[string]string)
 
 	// Find all of the native indexed tables in the node (those that don't require a driver)
 	if n != nil {
 		plan.Inspect(n, func(node sql.Node) bool {
 			switch x := node.(type) {
+			case *plan.TableAlias:
+				if rt, ok := x.Child.(*plan.ResolvedTable); ok {
+					tables[strings.ToLower(x.Name())] = rt.Name()
+					if rt.Database != "" {
+						databases[strings.ToLower(x.Name())] = rt.Database
+					}
+				}
 			case *plan.ResolvedTable:
+				tables[strings.ToLower(x.Name())] = x.Name()
+				if x.Database != "" {
+					databases[strings.ToLower(x.Name())] = x.Database
+				}
+
 				it, ok := x.Table.(sql.IndexedTable)
 				if !ok {
 					return false
@@ -67,19 +90,118 @@ func getIndexesForNode(ctx *sql.Context, a *Analyzer, n sql.Node) (*indexAnalyze
 
 	return &indexAnalyzer{
 		indexesByTable: indexes,
+		databases:      databases,
+		tables:         tables,
 		indexRegistry:  idxRegistry,
 	}, nil
 }
 
+// useHints makes the analyzer only use the indexes of the tables in the node that their INDEX and NO_INDEX hints
+// given allow. The first of these hints of each table takes precedence over the others.
+func (r *indexAnalyzer) useHints(hints sql.Hints) {
+	r.indexHints = make(map[string]sql.Hint)
+	for _, hint := range hints {
+		if hint.Name != sql.IndexHint && hint.Name != sql.NoIndexHint {
+			continue
+		}
+		for _, name := range hint.Tables() {
+			table, ok := r.tables[name]
+			if !ok {
+				continue
+			}
+			if _, ok := r.indexHints[strings.ToLower(table)]; !ok {
+				r.indexHints[strings.ToLower(table)] = hint
+			}
+		}
+	}
+
+	for table, idxes := range r.indexesByTable {
+		var usable []sql.Index
+		for _, idx := range idxes {
+			if r.usable(idx) {
+				usable = append(usable, idx)
+			}
+		}
+		r.indexesByTable[table] = usable
+	}
+}
+
+// usable returns whether the index hints of the table of the index given allow using it.
+func (r *indexAnalyzer) usable(idx sql.Index) bool {
+	hint, ok := r.indexHints[strings.ToLower(idx.Table())]
+	return !ok || hintAllowsIndex(hint, idx)
+}
+
+// hintAllowsIndex returns whether the INDEX or NO_INDEX hint given allows using the index given of its table.
+func hintAllowsIndex(hint sql.Hint, idx sql.Index) bool {
+	named := len(hint.Indexes()) == 0
+	for _, name := range hint.Indexes() {
+		if strings.EqualFold(name, idx.ID()) {
+			named = true
+			break
+		}
+	}
+	return named == (hint.Name == sql.IndexHint)
+}
+
+// forcesIndex returns whether the table named, by its name or alias, has an INDEX hint, so its indexes are used
+// without comparing their costs with the costs of scanning it.
+func (r *indexAnalyzer) forcesIndex(table string) bool {
+	name, ok := r.tables[strings.ToLower(table)]
+	if !ok {
+		return false
+	}
+	hint, ok := r.indexHints[strings.ToLower(name)]
+	return ok && hint.Name == sql.IndexHint
+}
+
+// database returns the database of the tables referenced by the expressions given, or the database given if they
+// don't reference tables of a single database of the node.
+func (r *indexAnalyzer) database(db string, exprs ...sql.Expression) string {
+	var tableDb string
+	for _, e := range exprs {
+		var ambiguous bool
+		sql.Inspect(e, func(e sql.Expression) bool {
+			gf, ok := e.(*expression.GetField)
+			if !ok {
+				return true
+			}
+
+			d, ok := r.databases[strings.ToLower(gf.Table())]
+			if !ok || (tableDb != "" && !strings.EqualFold(d, tableDb)) {
+				ambiguous = true
+				return false
+			}
+			tableDb = d
+			return true
+		})
+
+		if ambiguous {
+			return db
+		}
+	}
+
+	if tableDb == "" {
+		return db
+	}
+	return tableDb
+}
+
 // IndexesByTable returns all indexes on the table named. The table must be present in the node used to create the
-// analyzer.
+// analyzer. The database given is only used if the database of the table isn't known.
 func (r *indexAnalyzer) IndexesByTable(ctx *sql.Context, db, table string) []sql.Index {
 	indexes := r.indexesByTable[table]
 
+	if d, ok := r.databases[strings.ToLower(table)]; ok {
+		db = d
+	}
+
 	if r.indexRegistry != nil {
 		idxes := r.indexRegistry.IndexesByTable(db, table)
 		for _, idx := range idxes {
-			indexes = append(indexes, idx)
+			if r.usable(idx) {
+				indexes = append(indexes, idx)
+			}
 		}
 	}
 
@@ -87,7 +209,8 @@ func (r *indexAnalyzer) IndexesByTable(ctx *sql.Context, db, table string) []sql
 }
 
 // IndexByExpression returns an index by the given expression. It will return nil if no index is found. If more than
-// one expression is given, all of them must match for the index to be matched.
+// one expression is given, all of them must match for the index to be matched. Indexes of the index registry are looked
+// up in the database of the tables of the expressions, or in the database given if it isn't known.
 func (r *indexAnalyzer) IndexByExpression(ctx *sql.Context, db string, expr ...sql.Expression) sql.Index {
 	exprStrs := make([]string, len(expr))
 	for i, e := range expr {
@@ -103,16 +226,53 @@ func (r *indexAnalyzer) IndexByExpression(ctx *sql.Context, db string, expr ...s
 	}
 
 	if r.indexRegistry != nil {
-		idx := r.indexRegistry.IndexByExpression(ctx, db, expr...)
+		idx := r.indexRegistry.IndexByExpression(ctx, r.database(db, expr...), expr...)
 		r.registryIdxes = append(r.registryIdxes, idx)
+		if idx != nil && !r.usable(idx) {
+			return nil
+		}
 		return idx
 	}
 
 	return nil
 }
 
+// IndexByExpressionPrefix returns the native index that can look up keys of a prefix of its expressions with the
+// longest prefix whose expressions are all given, and the length of the prefix. It returns nil if no index of several
+// expressions starts with any of the expressions given.
+func (r *indexAnalyzer) IndexByExpressionPrefix(expr ...sql.Expression) (sql.Index, int) {
+	exprStrs := make(map[string]bool, len(expr))
+	for _, e := range expr {
+		exprStrs[e.String()] = true
+	}
+
+	var index sql.Index
+	var prefix int
+	for _, idxes := range r.indexesByTable {
+		for _, idx := range idxes {
+			if _, ok := idx.(sql.PrefixIndex); !ok {
+				continue
+			}
+
+			n := 0
+			for _, e := range idx.Expressions() {
+				if !exprStrs[e] {
+					break
+				}
+				n++
+			}
+			if n > prefix && n < len(idx.Expressions()) {
+				index, prefix = idx, n
+			}
+		}
+	}
+
+	return index, prefix
+}
+
 // ExpressionsWithIndexes finds all the combinations of expressions with matching indexes. This only matches
-// multi-column indexes.
+// multi-column indexes. Like IndexByExpression, the database given is only used if the database of the tables of the
+// expressions isn't known.
 func (r *indexAnalyzer) ExpressionsWithIndexes(db string, exprs ...sql.Expression) [][]sql.Expression {
 	var results [][]sql.Expression
 
@@ -150,7 +310,7 @@ func (r *indexAnalyzer) ExpressionsWithIndexes(db string, exprs ...sql.Expressio
 
 	// Expand the search to the index registry if present
 	if r.indexRegistry != nil {
-		indexes := r.indexRegistry.ExpressionsWithIndexes(db, exprs...)
+		indexes := r.indexRegistry.ExpressionsWithIndexes(r.database(db, exprs...), exprs...)
 		results = append(results, indexes...)
 	}
 
@@ -17,6 +17,9 @@ var errInvalidInRightEvaluation = errors.NewKind("expecting evaluation of IN exp
 type indexLookup struct {
 	lookup  sql.IndexLookup
 	indexes []sql.Index
+	// multiRange is whether the lookup is a range or union lookup, rather than one of equal keys, whose rows are worth
+	// fetching with a multi-range read
+	multiRange bool
 }
 
 type indexLookupsByTable map[string]*indexLookup
@@ -32,6 +35,7 @@ func getIndexesByTable(ctx *sql.Context, a *Analyzer, node sql.Node) (indexLooku
 		return nil, err
 	}
 
+	hints := queryHints(node)
 	var indexes indexLookupsByTable
 	cont := true
 	var errInAnalysis error
@@ -51,6 +55,7 @@ func getIndexesByTable(ctx *sql.Context, a *Analyzer, node sql.Node) (indexLooku
 			return false
 		}
 		defer indexAnalyzer.releaseUsedIndexes()
+		indexAnalyzer.useHints(hints)
 
 		var result indexLookupsByTable
 		result, err = getIndexes(ctx, a, indexAnalyzer, filter.Expression, exprAliases, tableAliases)
@@ -121,6 +126,7 @@ func getIndexes(
 						return nil, err
 					}
 					leftIdx.indexes = append(leftIdx.indexes, rightIdx.indexes...)
+					leftIdx.multiRange = true
 					result[table] = leftIdx
 					foundRightIdx = true
 					delete(rightIndexes, table)
@@ -187,8 +193,9 @@ func getIndexes(
 				}
 
 				result[idx.Table()] = &indexLookup{
-					indexes: []sql.Index{idx},
-					lookup:  lookup,
+					indexes:    []sql.Index{idx},
+					lookup:     lookup,
+					multiRange: len(values) > 1,
 				}
 			}
 		}
@@ -202,9 +209,11 @@ func getIndexes(
 			return result, err
 		}
 
+		_, equals := e.(*expression.Equals)
 		result[idx.Table()] = &indexLookup{
-			indexes: []sql.Index{idx},
-			lookup:  lookup,
+			indexes:    []sql.Index{idx},
+			lookup:     lookup,
+			multiRange: !equals,
 		}
 	case *expression.Not:
 		r, err := getNegatedIndexes(ctx, a, ia, e, exprAliases, tableAliases)
@@ -241,8 +250,9 @@ func getIndexes(
 
 				if lookup != nil {
 					result[idx.Table()] = &indexLookup{
-						indexes: []sql.Index{idx},
-						lookup:  lookup,
+						indexes:    []sql.Index{idx},
+						lookup:     lookup,
+						multiRange: true,
 					}
 				}
 			}
@@ -477,8 +487,9 @@ func getNegatedIndexes(
 
 		result := indexLookupsByTable{
 			idx.Table(): {
-				indexes: []sql.Index{idx},
-				lookup:  lookup,
+				indexes:    []sql.Index{idx},
+				lookup:     lookup,
+				multiRange: true,
 			},
 		}
 
@@ -529,8 +540,9 @@ func getNegatedIndexes(
 
 				return indexLookupsByTable{
 					idx.Table(): {
-						indexes: []sql.Index{idx},
-						lookup:  lookup,
+						indexes:    []sql.Index{idx},
+						lookup:     lookup,
+						multiRange: true,
 					},
 				}, nil
 			}
@@ -586,6 +598,7 @@ func indexesIntersection(left, right indexLookupsByTable) (indexLookupsByTable,
 				return nil, err
 			}
 			idx.indexes = append(idx.indexes, idx2.indexes...)
+			idx.multiRange = idx.multiRange || idx2.multiRange
 		}
 
 		result[table] = idx
@@ -639,9 +652,10 @@ func getMultiColumnIndexes(
 				}
 
 				if lookup != nil {
+					_, equals := exps[0].comparison.(*expression.Equals)
 					if _, ok := result[table]; ok {
 						newResult := indexLookupsByTable{
-							table: &indexLookup{lookup, []sql.Index{index}},
+							table: &indexLookup{lookup, []sql.Index{index}, !equals},
 						}
 						if !canMergeIndexLookups(result, newResult) {
 							return nil, nil
@@ -652,7 +666,7 @@ func getMultiColumnIndexes(
 							return nil, err
 						}
 					} else {
-						result[table] = &indexLookup{lookup, []sql.Index{index}}
+						result[table] = &indexLookup{lookup, []sql.Index{index}, !equals}
 					}
 				}
 			}
@@ -853,6 +867,10 @@ func containsColumns(e sql.Expression) bool {
 			result = true
 			return false
 		}
+		if _, ok := e.(*plan.LocalVariable); ok {
+			result = true
+			return false
+		}
 		return true
 	})
 	return result
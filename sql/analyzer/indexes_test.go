@@ -298,6 +298,7 @@ func TestGetIndexes(t *testing.T) {
 				"t1": &indexLookup{
 					mergeableIndexLookup("t1", "bar", 0, int64(1)),
 					[]sql.Index{indexes[0]},
+					false,
 				},
 			},
 			true,
@@ -326,6 +327,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -363,6 +365,7 @@ func TestGetIndexes(t *testing.T) {
 					[]sql.Index{
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -385,6 +388,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					false,
 				},
 			},
 			true,
@@ -430,6 +434,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -466,6 +471,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -479,6 +485,7 @@ func TestGetIndexes(t *testing.T) {
 				"t1": &indexLookup{
 					unionLookupWithKeys("t1", "bar", 0, int64(1), int64(2), int64(3), int64(4)),
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -512,10 +519,12 @@ func TestGetIndexes(t *testing.T) {
 				"t1": &indexLookup{
 					mergeableIndexLookup("t1", "bar", 0, int64(3)),
 					[]sql.Index{indexes[0]},
+					false,
 				},
 				"t2": &indexLookup{
 					mergeableIndexLookup("t2", "bar", 0, int64(4)),
 					[]sql.Index{indexes[2]},
+					false,
 				},
 			},
 			true,
@@ -547,6 +556,7 @@ func TestGetIndexes(t *testing.T) {
 				"t1": &indexLookup{
 					mergeableIndexLookup("t1", "bar", 0, int64(3)),
 					[]sql.Index{indexes[0]},
+					false,
 				},
 				"t2": &indexLookup{
 					&memory.MergeableIndexLookup{
@@ -560,6 +570,7 @@ func TestGetIndexes(t *testing.T) {
 						},
 					},
 					[]sql.Index{indexes[1]},
+					false,
 				},
 			},
 			true,
@@ -608,6 +619,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -624,6 +636,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -640,6 +653,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -656,6 +670,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -681,6 +696,7 @@ func TestGetIndexes(t *testing.T) {
 						},
 					),
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -699,6 +715,7 @@ func TestGetIndexes(t *testing.T) {
 						Index:  mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -718,6 +735,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -737,6 +755,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -756,6 +775,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -775,6 +795,7 @@ func TestGetIndexes(t *testing.T) {
 						Index: mergeableIndex("t1", "bar", 0),
 					},
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -808,6 +829,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -841,6 +863,7 @@ func TestGetIndexes(t *testing.T) {
 						indexes[0],
 						indexes[0],
 					},
+					true,
 				},
 			},
 			true,
@@ -870,6 +893,7 @@ func TestGetIndexes(t *testing.T) {
 					[]sql.Index{
 						indexes[2],
 					},
+					true,
 				},
 			},
 			true,
@@ -905,6 +929,7 @@ func TestGetIndexes(t *testing.T) {
 						},
 					),
 					[]sql.Index{indexes[0]},
+					true,
 				},
 			},
 			true,
@@ -1044,6 +1069,7 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 				Index: indexes[0],
 			},
 			[]sql.Index{indexes[0]},
+			false,
 		},
 		"t2": &indexLookup{
 			&memory.MergeableIndexLookup{
@@ -1051,6 +1077,7 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 				Index: indexes[1],
 			},
 			[]sql.Index{indexes[1]},
+			false,
 		},
 		"t4": &indexLookup{
 			&memory.MergedIndexLookup{
@@ -1069,6 +1096,7 @@ func TestGetMultiColumnIndexes(t *testing.T) {
 				Index: indexes[4],
 			},
 			[]sql.Index{indexes[4]},
+			true,
 		},
 	}
 
@@ -1159,7 +1187,7 @@ var _ sql.IndexLookup = DummyIndexLookup{}
 
 func (DummyIndexLookup) Indexes() []string { return nil }
 
-func (DummyIndexLookup) Values(sql.Partition) (sql.IndexValueIter, error) {
+func (DummyIndexLookup) Values(*sql.Context, sql.Partition) (sql.IndexValueIter, error) {
 	return nil, nil
 }
 
@@ -1169,22 +1197,22 @@ func TestIndexesIntersection(t *testing.T) {
 	idx1, idx2 := &memory.MergeableIndex{TableName: "bar"}, &memory.MergeableIndex{TableName: "foo"}
 
 	left := indexLookupsByTable{
-		"a": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"a"}}, nil},
-		"b": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"b"}}, []sql.Index{idx1}},
-		"c": &indexLookup{new(DummyIndexLookup), nil},
+		"a": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"a"}}, nil, false},
+		"b": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"b"}}, []sql.Index{idx1}, false},
+		"c": &indexLookup{new(DummyIndexLookup), nil, false},
 	}
 
 	right := indexLookupsByTable{
-		"b": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"b2"}}, []sql.Index{idx2}},
-		"c": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"c"}}, nil},
-		"d": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"d"}}, nil},
+		"b": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"b2"}}, []sql.Index{idx2}, false},
+		"c": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"c"}}, nil, false},
+		"d": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"d"}}, nil, false},
 	}
 
 	lookupsByTable, err := indexesIntersection(left, right)
 	require.NoError(err)
 	require.Equal(
 		indexLookupsByTable{
-			"a": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"a"}}, nil},
+			"a": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"a"}}, nil, false},
 			"b": &indexLookup{
 				&memory.MergedIndexLookup{
 					Intersections: []sql.IndexLookup{
@@ -1197,9 +1225,10 @@ func TestIndexesIntersection(t *testing.T) {
 					},
 				},
 				[]sql.Index{idx1, idx2},
+				false,
 			},
-			"c": &indexLookup{new(DummyIndexLookup), nil},
-			"d": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"d"}}, nil},
+			"c": &indexLookup{new(DummyIndexLookup), nil, false},
+			"d": &indexLookup{&memory.MergeableIndexLookup{Key: []interface{}{"d"}}, nil, false},
 		},
 		lookupsByTable,
 	)
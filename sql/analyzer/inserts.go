@@ -24,6 +24,14 @@ import (
 )
 
 func resolveInsertRows(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if describe, ok := n.(*plan.DescribeQuery); ok {
+		child, err := resolveInsertRows(ctx, a, describe.Child, scope)
+		if err != nil {
+			return nil, err
+		}
+		return describe.WithChildren(child)
+	}
+
 	insert, ok := n.(*plan.InsertInto)
 	if !ok {
 		return n, nil
@@ -206,6 +214,11 @@ func makeAutoIncrement(ctx *sql.Context, a *Analyzer, child sql.Expression, colN
 		return nil, err
 	}
 
+	// The process of the subquery is the one of the INSERT, which must not be marked as done when the subquery is
+	if qp, ok := analyzed.(*plan.QueryProcess); ok {
+		analyzed = qp.Child
+	}
+
 	sq := plan.NewSubquery(analyzed, subquery)
 
 	return expression.NewAutoIncrement(sq, child)
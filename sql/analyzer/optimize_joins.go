@@ -1,7 +1,7 @@
 package analyzer
 
 import (
-	"errors"
+	"reflect"
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -9,8 +9,15 @@ import (
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
-// optimizeJoins takes two-table InnerJoins where the join condition is an equality on an index of one of the tables,
-// and replaces it with an equivalent IndexedJoin of the same two tables.
+// optimizeJoins replaces joins where the join condition is an equality on an index of one of the tables with an
+// equivalent IndexedJoin, which looks up the rows of that table in the index for every row of the other side. The
+// tables of a tree of inner joins are first ordered into the chain of joins with the lowest cost estimated from the
+// statistics of the tables, so joins of any number of tables use the indexes of all of them. Indexes are only used
+// when looking up rows in them is estimated to be cheaper than scanning the table. Joins with equalities that can't
+// use an index are replaced with a HashJoin instead, which hashes the rows of one side once, when that's estimated to
+// be cheaper than reading that side again for every row of the other. Joins with comparisons instead of equalities
+// look up the range of values they bound in a single column index, if it's cheaper than a hash join too. The
+// JOIN_ORDER, HASH_JOIN, NO_HASH_JOIN, INDEX and NO_INDEX hints of a query block override these choices for its joins.
 func optimizeJoins(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	span, ctx := ctx.Span("optimize_joins")
 	defer span.Finish()
@@ -20,365 +27,1040 @@ func optimizeJoins(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 	}
 
 	// skip certain queries (list is probably incomplete)
-	switch n.(type) {
-	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateIndex, *plan.InsertInto:
+	switch describedNode(n).(type) {
+	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateIndex, *plan.InsertInto,
+		*plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint:
 		return n, nil
 	}
 
-	numTables := 0
-	plan.Inspect(n, func(node sql.Node) bool {
-		switch node.(type) {
-		case *plan.ResolvedTable:
-			numTables++
-		}
-		return true
-	})
-
-	if numTables > 2 {
-		a.Log("skipping join optimization, more than 2 tables")
-		return n, nil
+	tableAliases, err := getTableAliases(n)
+	if err != nil {
+		return nil, err
 	}
 
-	exprAliases := getExpressionAliases(n)
-	tableAliases, err := getTableAliases(n)
+	indexAnalyzer, err := getIndexesForNode(ctx, a, n)
 	if err != nil {
 		return nil, err
 	}
+	defer indexAnalyzer.releaseUsedIndexes()
+	indexAnalyzer.useHints(queryHints(n))
+
+	o := &joinOptimizer{
+		ctx:          ctx,
+		a:            a,
+		ia:           indexAnalyzer,
+		costs:        newCostEstimator(ctx, a, n),
+		exprAliases:  getExpressionAliases(n),
+		tableAliases: tableAliases,
+	}
 
-	indexes, err := findJoinIndexes(ctx, n, exprAliases, tableAliases, a)
+	node, replacedIndexedJoin, err := o.optimize(n)
 	if err != nil {
 		return nil, err
 	}
 
-	return transformJoins(a, n, indexes, exprAliases, tableAliases)
+	if replacedIndexedJoin && len(scope.Schema()) > 0 {
+		// In subqueries, the rows of the joins start with the row of the outer scope, so the indexes are fixed for it
+		// too, as pruneColumns does
+		return fixRemainingFieldsIndexes(node, scope)
+	}
+
+	if replacedIndexedJoin {
+		// Fix the field indexes as necessary
+		node, _, err = plan.TransformUpWithIdentity(node, func(node sql.Node) (sql.Node, sql.TreeIdentity, error) {
+			// TODO: should we just do this for every query plan as a final part of the analysis?
+			//  This would involve enforcing that every type of Node implement Expressioner.
+			a.Log("transforming node of type: %T", node)
+			return fixFieldIndexesForExpressions(node)
+		})
+	}
+
+	return node, err
 }
 
-func transformJoins(
-	a *Analyzer,
-	n sql.Node,
-	indexes map[string]sql.Index,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-) (sql.Node, error) {
+// joinOptimizer replaces the joins of a node with IndexedJoins and HashJoins.
+type joinOptimizer struct {
+	ctx          *sql.Context
+	a            *Analyzer
+	ia           *indexAnalyzer
+	costs        *costEstimator
+	exprAliases  ExprAliases
+	tableAliases TableAliases
+	// hints are the optimizer hints of the query block of the joins being optimized.
+	hints sql.Hints
+}
 
-	var replacedIndexedJoin bool
-	node, err := plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
-		switch node := node.(type) {
-		case *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin:
-
-			var cond sql.Expression
-			var bnode plan.BinaryNode
-			var joinType plan.JoinType
-
-			switch node := node.(type) {
-			case *plan.InnerJoin:
-				cond = node.Cond
-				bnode = node.BinaryNode
-				joinType = plan.JoinTypeInner
-			case *plan.LeftJoin:
-				cond = node.Cond
-				bnode = node.BinaryNode
-				joinType = plan.JoinTypeLeft
-			case *plan.RightJoin:
-				cond = node.Cond
-				bnode = node.BinaryNode
-				joinType = plan.JoinTypeRight
+// joinLeaf is one of the nodes joined by a tree of inner joins, which may be a table or any other node, like an outer
+// join.
+type joinLeaf struct {
+	node sql.Node
+	// tables are the lower case names of the tables and subquery aliases of the node, which the columns of the join
+	// conditions name.
+	tables []string
+}
+
+// joinLookup is an index of the secondary table of a join, with the expressions evaluated on the rows of the primary
+// side to look up the rows of the secondary table, or the range of keys to look up for range joins, with the
+// conditions it comes from. The key conditions are the equalities of the key that the rows looked up always satisfy,
+// which the join doesn't need to evaluate.
+type joinLookup struct {
+	index            sql.Index
+	primaryTableExpr []sql.Expression
+	keyConds         []sql.Expression
+	keyRange         *plan.IndexedJoinRange
+	rangeConds       []sql.Expression
+}
+
+// optimize returns the node given with its joins replaced by IndexedJoins and HashJoins where possible, and whether it
+// replaced any.
+func (o *joinOptimizer) optimize(n sql.Node) (sql.Node, bool, error) {
+	switch n := n.(type) {
+	case *plan.InnerJoin:
+		return o.optimizeInnerJoins(n)
+	case *plan.LeftJoin:
+		return o.optimizeOuterJoin(n, n.Cond, plan.JoinTypeLeft)
+	case *plan.RightJoin:
+		return o.optimizeOuterJoin(n, n.Cond, plan.JoinTypeRight)
+	case *plan.SemiJoin:
+		return o.optimizeOuterJoin(n, n.Cond, plan.JoinTypeSemi)
+	case *plan.AntiJoin:
+		return o.optimizeOuterJoin(n, n.Cond, plan.JoinTypeAnti)
+	case *plan.QueryHints:
+		hints := o.hints
+		o.hints = n.Hints
+		defer func() {
+			o.hints = hints
+		}()
+		return o.optimizeChildren(n)
+	default:
+		return o.optimizeChildren(n)
+	}
+}
+
+func (o *joinOptimizer) optimizeChildren(n sql.Node) (sql.Node, bool, error) {
+	children := n.Children()
+	if len(children) == 0 {
+		return n, false, nil
+	}
+
+	var replaced bool
+	newChildren := make([]sql.Node, len(children))
+	for i, child := range children {
+		newChild, ok, err := o.optimize(child)
+		if err != nil {
+			return nil, false, err
+		}
+		newChildren[i] = newChild
+		replaced = replaced || ok
+	}
+
+	if !replaced {
+		return n, false, nil
+	}
+
+	node, err := n.WithChildren(newChildren...)
+	if err != nil {
+		return nil, false, err
+	}
+	return node, true, nil
+}
+
+// optimizeOuterJoin replaces the left, right, semi or anti join given with an IndexedJoin if the table on its inner
+// side has an index for the join condition, or else with a HashJoin if the join condition has equalities between both
+// sides. The table on the outer side is always the primary one, because all of its rows are returned, or for semi and
+// anti joins filtered.
+func (o *joinOptimizer) optimizeOuterJoin(n sql.Node, cond sql.Expression, joinType plan.JoinType) (sql.Node, bool, error) {
+	node, replaced, err := o.optimizeChildren(n)
+	if err != nil {
+		return nil, false, err
+	}
+
+	children := node.Children()
+	primary, secondary := children[0], children[1]
+	if joinType == plan.JoinTypeRight {
+		primary, secondary = secondary, primary
+	}
+
+	primaryTables := tableSet(joinLeafTables(primary))
+	conds := splitConjunction(cond)
+	lookup := o.joinLookup(primaryTables, secondary, conds)
+	forceIndex := lookup != nil && o.ia.forcesIndex(getTableName(secondary))
+	forceHash, forbidHash := o.hashJoinHint(secondary)
+	switch {
+	case lookup == nil:
+		o.a.Log("Cannot apply index to %s of %s", joinType, getTableName(secondary))
+	// The secondary table is read once for every row of the primary side either way
+	case !forceIndex && o.lookupCost(secondary, lookup) > o.costs.scanCost(secondary):
+		o.a.Log("scanning %s is cheaper than looking it up in index %s", getTableName(secondary), lookup.index.ID())
+		lookup = nil
+	// Ranges may have many more rows than the keys of a hash join
+	case lookup.keyRange == nil && !forceHash:
+		indexedJoin, err := o.indexedJoin(primary, secondary, joinType, cond, lookup)
+		if err != nil {
+			return nil, false, err
+		}
+		return indexedJoin, true, nil
+	}
+
+	rows := o.costs.rows(primary)
+	cost := rows * o.costs.scanCost(secondary)
+	if lookup != nil {
+		cost = rows * o.lookupCost(secondary, lookup)
+	}
+
+	var join sql.Node
+	var keys *hashJoinKeys
+	if !forbidHash {
+		keys = hashJoinKeysOf(primaryTables, secondary, conds)
+	}
+	switch {
+	case keys != nil && (forceHash || (!forceIndex && o.costs.hashJoinCost(rows, secondary, keys.conds) < cost)):
+		join, err = o.hashJoin(primary, secondary, joinType, cond, keys)
+	case lookup != nil:
+		join, err = o.indexedJoin(primary, secondary, joinType, cond, lookup)
+	case keys != nil:
+		o.a.Log("reading %s for every row of the %s is cheaper than hashing it", getTableName(secondary), joinType)
+		return node, replaced, nil
+	default:
+		return node, replaced, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return join, true, nil
+}
+
+// optimizeInnerJoins orders the nodes joined by the tree of inner and cross joins given into the chain of joins with
+// the lowest estimated cost, where tables are looked up in an index with the rows of the nodes before them when that's
+// cheaper than scanning them, and other nodes are joined to them with a hash join when that's cheaper than reading
+// them for every row. Join conditions are evaluated by the first join that has all the tables they use. If no node is
+// looked up in an index or a hash table, the joins are left in the order of the query, unless a JOIN_ORDER hint
+// orders some of them.
+func (o *joinOptimizer) optimizeInnerJoins(n *plan.InnerJoin) (sql.Node, bool, error) {
+	var leaves []joinLeaf
+	var conds []sql.Expression
+	var collect func(node sql.Node)
+	collect = func(node sql.Node) {
+		switch j := node.(type) {
+		case *plan.InnerJoin:
+			collect(j.Left)
+			collect(j.Right)
+			conds = append(conds, splitConjunction(j.Cond)...)
+			return
+		case *plan.CrossJoin:
+			collect(j.Left)
+			collect(j.Right)
+			return
+		case *plan.Filter:
+			// Filters of the joins of the tree, like the join conditions moved to them, are conditions of the tree
+			switch j.Child.(type) {
+			case *plan.InnerJoin, *plan.CrossJoin:
+				collect(j.Child)
+				conds = append(conds, splitConjunction(j.Expression)...)
+				return
+			}
+		}
+		leaves = append(leaves, joinLeaf{node: node, tables: joinLeafTables(node)})
+	}
+	collect(n)
+
+	condTables := make([][]string, len(conds))
+	for i, cond := range conds {
+		condTables[i] = expressionTables(cond)
+		// Conditions on tables of outer scopes can't be evaluated on the rows of the joins alone
+		for _, t := range condTables[i] {
+			if leafOfTable(leaves, t) < 0 {
+				o.a.Log("Cannot reorder the joins of %s, condition %s uses table %s of an outer scope", n, cond, t)
+				return o.optimizeChildren(n)
 			}
+		}
+	}
+
+	steps, lookups := o.joinOrder(leaves, conds, condTables)
+	if lookups == 0 && o.joinOrderHint(leaves) == nil {
+		return o.optimizeChildren(n)
+	}
 
-			primaryTable, secondaryTable, primaryTableExpr, secondaryTableIndex, err :=
-				analyzeJoinIndexes(bnode, cond, indexes, exprAliases, tableAliases, joinType)
+	for i := range leaves {
+		node, _, err := o.optimize(leaves[i].node)
+		if err != nil {
+			return nil, false, err
+		}
+		leaves[i].node = node
+	}
+
+	node := leaves[steps[0].leaf].node
+	joined := tableSet(leaves[steps[0].leaf].tables)
+	used := make([]bool, len(conds))
+	for _, step := range steps[1:] {
+		leaf := leaves[step.leaf]
+		for _, t := range leaf.tables {
+			joined[t] = true
+		}
 
+		cond := expression.JoinAnd(newJoinConditions(joined, conds, condTables, used)...)
+		switch {
+		case step.lookup != nil:
+			indexedJoin, err := o.indexedJoin(node, leaf.node, plan.JoinTypeInner, cond, step.lookup)
 			if err != nil {
-				a.Log("Cannot apply index to join: %s", err.Error())
-				return node, nil
+				return nil, false, err
 			}
-
-			joinSchema := append(primaryTable.Schema(), secondaryTable.Schema()...)
-			joinCond, err := FixFieldIndexes(joinSchema, cond)
+			node = indexedJoin
+		case step.hash != nil:
+			hashJoin, err := o.hashJoin(node, leaf.node, plan.JoinTypeInner, cond, step.hash)
 			if err != nil {
-				return nil, err
+				return nil, false, err
 			}
-			replacedIndexedJoin = true
+			node = hashJoin
+		case cond != nil:
+			node = plan.NewInnerJoin(node, leaf.node, cond)
+		default:
+			node = plan.NewCrossJoin(node, leaf.node)
+		}
+	}
 
-			secondaryTable, err = plan.TransformUp(secondaryTable, func(node sql.Node) (sql.Node, error) {
-				if rt, ok := node.(*plan.ResolvedTable); ok {
-					a.Log("replacing resolve table %s with IndexedTable", rt.Name())
-					return plan.NewIndexedTable(rt), nil
+	return node, true, nil
+}
+
+// joinStep is one of the leaves of a chain of joins, with the index its rows are looked up in or the keys its rows are
+// hashed by, if any.
+type joinStep struct {
+	leaf   int
+	lookup *joinLookup
+	hash   *hashJoinKeys
+}
+
+// joinOrder returns the chain of joins of the leaves given with the lowest estimated cost, and the number of leaves
+// looked up in an index or a hash table. Each leaf after the first is the one with a join condition on the leaves
+// before it that is the cheapest to join to them, looked up in an index if that's cheaper than a scan, or else in a
+// hash table of its rows if that's cheaper, or else the cheapest one left. Lookups of ranges are replaced by hash
+// joins that are cheaper too. Every leaf is tried as the first one. Ties go to the chain with the most lookups, whose
+// costs grow slower than the costs of scans as tables grow, and then to the chain closest to the order of the query.
+// Leaves are only joined after the leaves whose tables come before theirs in the JOIN_ORDER hint of the query block,
+// and the HASH_JOIN, NO_HASH_JOIN and INDEX hints of their tables choose how they're joined regardless of the costs.
+// Leaves with a HASH_JOIN hint only come first if no other leaf can, since the first leaf is scanned.
+func (o *joinOptimizer) joinOrder(leaves []joinLeaf, conds []sql.Expression, condTables [][]string) ([]joinStep, int) {
+	var bestSteps []joinStep
+	var bestCost float64
+	var bestLookups int
+
+	order := o.joinOrderHint(leaves)
+	var firsts, hashedFirsts []int
+	for i, leaf := range leaves {
+		if !joinOrderAllows(order, leaves, make([]bool, len(leaves)), i) {
+			continue
+		}
+		if hashed, _ := o.hashJoinHint(leaf.node); hashed {
+			hashedFirsts = append(hashedFirsts, i)
+		} else {
+			firsts = append(firsts, i)
+		}
+	}
+	if len(firsts) == 0 {
+		firsts = hashedFirsts
+	}
+
+	for _, first := range firsts {
+		placed := make([]bool, len(leaves))
+		steps := []joinStep{{leaf: first}}
+		joined := tableSet(leaves[first].tables)
+		placed[first] = true
+		used := make([]bool, len(conds))
+		rows := o.costs.rows(leaves[first].node) * o.costs.selectivity(newJoinConditions(joined, conds, condTables, used))
+		cost := o.costs.scanCost(leaves[first].node)
+		lookups := 0
+
+		for len(steps) < len(leaves) {
+			next, connected := -1, false
+			var nextLookup *joinLookup
+			var nextHash *hashJoinKeys
+			var nextCost float64
+			for i, leaf := range leaves {
+				if placed[i] {
+					continue
+				}
+
+				joins := joinsTables(joined, leaf.tables, conds)
+				if (connected && !joins) || !joinOrderAllows(order, leaves, placed, i) {
+					continue
+				}
+
+				// The leaf is read once for every row of the leaves before it
+				stepCost := rows * o.costs.scanCost(leaf.node)
+				lookup := o.joinLookup(joined, leaf.node, conds)
+				forceIndex := lookup != nil && o.ia.forcesIndex(getTableName(leaf.node))
+				if lookup != nil {
+					if lookupCost := rows * o.lookupCost(leaf.node, lookup); lookupCost <= stepCost || forceIndex {
+						stepCost = lookupCost
+					} else {
+						o.a.Log("scanning %s is cheaper than looking it up in index %s", getTableName(leaf.node), lookup.index.ID())
+						lookup = nil
+					}
+				}
+
+				var hash *hashJoinKeys
+				forceHash, forbidHash := o.hashJoinHint(leaf.node)
+				if !forbidHash && (lookup == nil || (lookup.keyRange != nil && !forceIndex) || forceHash) {
+					if keys := hashJoinKeysOf(joined, leaf.node, conds); keys != nil {
+						if hashCost := o.costs.hashJoinCost(rows, leaf.node, keys.conds); hashCost < stepCost || forceHash {
+							stepCost, hash, lookup = hashCost, keys, nil
+						}
+					}
+				}
+
+				if next < 0 || (joins && !connected) || stepCost < nextCost {
+					next, connected, nextLookup, nextHash, nextCost = i, joins, lookup, hash, stepCost
 				}
-				return node, nil
-			})
-			if err != nil {
-				return nil, err
 			}
 
-			return plan.NewIndexedJoin(primaryTable, secondaryTable, joinType, joinCond, primaryTableExpr, secondaryTableIndex), nil
-		default:
-			return node, nil
+			if nextLookup != nil || nextHash != nil {
+				lookups++
+			}
+			steps = append(steps, joinStep{leaf: next, lookup: nextLookup, hash: nextHash})
+			placed[next] = true
+			for _, t := range leaves[next].tables {
+				joined[t] = true
+			}
+			cost += nextCost
+			rows *= o.costs.rows(leaves[next].node) * o.costs.selectivity(newJoinConditions(joined, conds, condTables, used))
 		}
-	})
 
-	if err != nil {
-		return nil, err
+		if bestSteps == nil || (cost < bestCost && !sameCost(cost, bestCost)) ||
+			(sameCost(cost, bestCost) && lookups > bestLookups) {
+			bestSteps, bestCost, bestLookups = steps, cost, lookups
+		}
 	}
 
-	if replacedIndexedJoin {
-		// Fix the field indexes as necessary
-		node, err = plan.TransformUp(node, func(node sql.Node) (sql.Node, error) {
-			// TODO: should we just do this for every query plan as a final part of the analysis?
-			//  This would involve enforcing that every type of Node implement Expressioner.
-			a.Log("transforming node of type: %T", node)
-			return FixFieldIndexesForExpressions(node)
-		})
+	return bestSteps, bestLookups
+}
+
+// joinOrderHint returns the tables of the JOIN_ORDER hint of the query block in the order they're joined, or nil if
+// it has none or it names none of the tables of the leaves given.
+func (o *joinOptimizer) joinOrderHint(leaves []joinLeaf) []string {
+	hint, ok := o.hints.Get(sql.JoinOrderHint)
+	if !ok {
+		return nil
+	}
+	for _, t := range hint.Args {
+		if leafOfTable(leaves, t) >= 0 {
+			return hint.Args
+		}
 	}
+	return nil
+}
 
-	return node, err
+// joinOrderAllows returns whether the leaf given can be joined after the leaves placed, because the tables of the
+// join order given before its first one are all in the leaves placed. Leaves without any of the tables of the join
+// order can be joined at any point.
+func joinOrderAllows(order []string, leaves []joinLeaf, placed []bool, leaf int) bool {
+	tables := tableSet(leaves[leaf].tables)
+	for _, t := range order {
+		if tables[t] {
+			return true
+		}
+		if i := leafOfTable(leaves, t); i >= 0 && !placed[i] {
+			return false
+		}
+	}
+	return true
 }
 
-// Analyzes the join's tables and condition to select a left and right table, and an index to use for lookups in the
-// right table. Returns an error if no suitable index can be found.
-func analyzeJoinIndexes(
-	node plan.BinaryNode,
-	cond sql.Expression,
-	indexes map[string]sql.Index,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-	joinType plan.JoinType,
-) (primary sql.Node, secondary sql.Node, primaryTableExpr []sql.Expression, secondaryTableIndex sql.Index, err error) {
+// hashJoinHint returns whether the HASH_JOIN hints of the query block force joining the node given with a hash join,
+// or its NO_HASH_JOIN hints forbid it. The first of these hints of its tables takes precedence over the others.
+func (o *joinOptimizer) hashJoinHint(n sql.Node) (force bool, forbid bool) {
+	for _, t := range joinLeafTables(n) {
+		if hint, ok := o.hints.ForTable(t, sql.HashJoinHint, sql.NoHashJoinHint); ok {
+			return hint.Name == sql.HashJoinHint, hint.Name == sql.NoHashJoinHint
+		}
+	}
+	return false, false
+}
 
-	leftTableName := getTableName(node.Left)
-	rightTableName := getTableName(node.Right)
+// newJoinConditions returns the conditions given that weren't used yet and only use the tables given, and marks them
+// as used.
+func newJoinConditions(joined map[string]bool, conds []sql.Expression, condTables [][]string, used []bool) []sql.Expression {
+	var result []sql.Expression
+	for i, cond := range conds {
+		if !used[i] && containsTables(joined, condTables[i]) {
+			used[i] = true
+			result = append(result, cond)
+		}
+	}
+	return result
+}
 
-	exprByTable := joinExprsByTable(splitConjunction(cond))
+// joinLookup returns an index of the secondary node given for the conditions given, with the expressions that
+// evaluate the key to look up on the rows of the primary tables given, or nil if the secondary node isn't a table or
+// none of its indexes can be used. Equalities between columns of the secondary table and expressions of columns of a
+// primary table are used, or else comparisons of a column of the secondary table with them, and other conditions are
+// left to the join condition.
+func (o *joinOptimizer) joinLookup(primaryTables map[string]bool, secondary sql.Node, conds []sql.Expression) *joinLookup {
+	table, ok := indexableTable(secondary)
+	if !ok {
+		return nil
+	}
 
-	rightIdx := indexes[normalizeTableName(tableAliases, rightTableName)]
-	leftIdx := indexes[normalizeTableName(tableAliases, leftTableName)]
-	leftTableExprs := exprByTable[leftTableName]
-	rightTableExprs := exprByTable[rightTableName]
+	var secondaryExprs, primaryExprs []*columnExpr
+	for _, cond := range conds {
+		left, right := extractJoinColumnExpr(cond)
+		if left == nil || right == nil {
+			continue
+		}
 
-	// Choose a primary and secondary table based on available indexes. We can't choose the left table as secondary for a
-	// left join, or the right as secondary for a right join.
-	if rightIdx != nil && leftTableExprs != nil && joinType != plan.JoinTypeRight &&
-		indexExpressionPresent(rightIdx, rightTableExprs) {
-		primaryTableExpr, err := FixFieldIndexesOnExpressions(node.Left.Schema(), createPrimaryTableExpr(rightIdx, leftTableExprs, exprAliases, tableAliases)...)
-		if err != nil {
-			return nil, nil, nil, nil, err
+		for _, pair := range [][2]*columnExpr{{left, right}, {right, left}} {
+			s, p := pair[0], pair[1]
+			if strings.EqualFold(s.col.Table(), table) && primaryTables[strings.ToLower(p.col.Table())] {
+				secondaryExprs = append(secondaryExprs, s)
+				primaryExprs = append(primaryExprs, p)
+				break
+			}
 		}
-		return node.Left, node.Right, primaryTableExpr, rightIdx, nil
 	}
 
-	if leftIdx != nil && rightTableExprs != nil && joinType != plan.JoinTypeLeft &&
-		indexExpressionPresent(leftIdx, leftTableExprs) {
-		primaryTableExpr, err := FixFieldIndexesOnExpressions(node.Right.Schema(), createPrimaryTableExpr(leftIdx, rightTableExprs, exprAliases, tableAliases)...)
-		if err != nil {
-			return nil, nil, nil, nil, err
+	if len(secondaryExprs) == 0 {
+		return o.rangeJoinLookup(secondary, table, primaryTables, conds)
+	}
+
+	exprs := make([]sql.Expression, len(secondaryExprs))
+	for i, e := range secondaryExprs {
+		exprs[i] = e.colExpr
+	}
+
+	db := o.ctx.GetCurrentDatabase()
+	normalized := normalizeExpressions(o.exprAliases, o.tableAliases, exprs...)
+	idx := o.ia.IndexByExpression(o.ctx, db, normalized...)
+	var idxExprs []string
+	if idx != nil {
+		idxExprs = idx.Expressions()
+	}
+	if idx == nil || !indexExpressionPresent(idxExprs, secondaryExprs) {
+		// Without an index of all of its columns, the equalities may be looked up in an index of several columns
+		// that starts with some of them
+		var prefix int
+		idx, prefix = o.ia.IndexByExpressionPrefix(normalized...)
+		if idx == nil {
+			return o.rangeJoinLookup(secondary, table, primaryTables, conds)
+		}
+		idxExprs = idx.Expressions()[:prefix]
+		if !indexExpressionPresent(idxExprs, secondaryExprs) {
+			return o.rangeJoinLookup(secondary, table, primaryTables, conds)
 		}
-		return node.Right, node.Left, primaryTableExpr, leftIdx, nil
 	}
 
-	return nil, nil, nil, nil, errors.New("couldn't determine suitable indexes to use for tables")
+	primaryTableExpr, keyConds := createPrimaryTableExpr(idxExprs, primaryExprs, o.exprAliases, o.tableAliases)
+	if primaryTableExpr == nil {
+		return o.rangeJoinLookup(secondary, table, primaryTables, conds)
+	}
+
+	return &joinLookup{index: idx, primaryTableExpr: primaryTableExpr, keyConds: keyConds}
 }
 
-// indexExpressionPresent returns whether the index expression given occurs in the column expressions given. This check
-// is necessary in the case of joining a table to itself, since index expressions always use the original table name,
-// and join expressions use the aliased table name.
-func indexExpressionPresent(index sql.Index, colExprs []*columnExpr) bool {
-	// every expression in the join has to be found in the column expressions being considered (although there could be
-	// other column expressions as well)
-	for _, indexExpr := range index.Expressions() {
-		found := false
-		for _, colExpr := range colExprs {
-			if indexExpressionMatches(indexExpr, colExpr) {
-				found = true
-				break
-			}
+// joinRangeBound is a bound of the values of a column of the secondary table of a join, evaluated on the rows of
+// the primary tables, with the condition it comes from.
+type joinRangeBound struct {
+	column    *expression.GetField
+	value     sql.Expression
+	lower     bool
+	inclusive bool
+	cond      sql.Expression
+}
+
+// rangeJoinLookup returns a single column index of the secondary table given, named as given, with the range of its
+// column bounded by the conditions given to look up, or nil if there is none. Comparisons and BETWEEN conditions of a
+// column of the secondary table with expressions of columns of the primary tables given are used, and for the column
+// with the most of them that has an index with ascending and descending lookups, the first lower and upper bounds.
+// Only the indexes of the table itself are used, since the other revisions of the table a query reads with AS OF have
+// indexes with the same expressions.
+func (o *joinOptimizer) rangeJoinLookup(secondary sql.Node, table string, primaryTables map[string]bool, conds []sql.Expression) *joinLookup {
+	it, ok := getResolvedTable(secondary).Table.(sql.IndexedTable)
+	if !ok {
+		return nil
+	}
+	tableIndexes, err := it.GetIndexes(o.ctx)
+	if err != nil {
+		o.a.Log("Cannot get the indexes of %s: %s", table, err)
+		return nil
+	}
+	var indexes []sql.Index
+	for _, idx := range tableIndexes {
+		if o.ia.usable(idx) {
+			indexes = append(indexes, idx)
 		}
-		if !found {
+	}
+
+	isBound := func(column, value sql.Expression) bool {
+		gf, ok := column.(*expression.GetField)
+		if !ok || !strings.EqualFold(gf.Table(), table) || hasSubquery(value) {
 			return false
 		}
+		tables := expressionTables(value)
+		return len(tables) > 0 && containsTables(primaryTables, tables)
 	}
 
-	return true
-}
+	var bounds []joinRangeBound
+	for _, cond := range conds {
+		switch c := cond.(type) {
+		case *expression.GreaterThan, *expression.GreaterThanOrEqual, *expression.LessThan, *expression.LessThanOrEqual:
+			cmp := c.(expression.Comparer)
+			column, value := cmp.Left(), cmp.Right()
+			if !isBound(column, value) {
+				// if the form is SOMETHING OP {COLUMN}, swap it, so it's {COLUMN} OP SOMETHING
+				column, value, cmp = swapTermsOfExpression(cmp)
+				if !isBound(column, value) {
+					continue
+				}
+			}
 
-func indexExpressionMatches(indexExpr string, expr *columnExpr) bool {
-	// TODO: this string matching on index expressions is pretty fragile, won't handle tables with "." in their names,
-	//  etc. Would be nice to normalize the indexes into a better data structure than just strings to make them easier to
-	//  work with.
-	return strings.ToLower(expr.col.Name()) == strings.ToLower(indexExpr[strings.Index(indexExpr, ".")+1:])
-}
+			bound := joinRangeBound{column: column.(*expression.GetField), value: value, cond: cond}
+			switch cmp.(type) {
+			case *expression.GreaterThan:
+				bound.lower = true
+			case *expression.GreaterThanOrEqual:
+				bound.lower, bound.inclusive = true, true
+			case *expression.LessThanOrEqual:
+				bound.inclusive = true
+			}
+			bounds = append(bounds, bound)
+		case *expression.Between:
+			if isBound(c.Val, c.Lower) && isBound(c.Val, c.Upper) {
+				column := c.Val.(*expression.GetField)
+				bounds = append(bounds,
+					joinRangeBound{column: column, value: c.Lower, lower: true, inclusive: true, cond: cond},
+					joinRangeBound{column: column, value: c.Upper, inclusive: true, cond: cond})
+			}
+		}
+	}
 
-// createPrimaryTableExpr returns a slice of expressions to be used when evaluating a row in the primary table to
-// assemble a lookup key in the secondary table. Column expressions must match the declared column order of the index.
-func createPrimaryTableExpr(
-	idx sql.Index,
-	primaryTableEqualityExprs []*columnExpr,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-) []sql.Expression {
+	var best *joinLookup
+	var bestBounds int
+	tried := make(map[string]bool)
+	for _, b := range bounds {
+		name := strings.ToLower(b.column.Name())
+		if tried[name] {
+			continue
+		}
+		tried[name] = true
 
-	keyExprs := make([]sql.Expression, len(idx.Expressions()))
+		idx := rangeIndex(indexes, normalizeExpression(o.exprAliases, o.tableAliases, b.column).String())
+		if idx == nil {
+			continue
+		}
 
-IndexExpressions:
-	for i, idxExpr := range idx.Expressions() {
-		for j := range primaryTableEqualityExprs {
-			if idxExpr == normalizeExpression(exprAliases, tableAliases, primaryTableEqualityExprs[j].comparand).String() {
-				keyExprs[i] = primaryTableEqualityExprs[j].colExpr
-				continue IndexExpressions
+		lookup := &joinLookup{index: idx, keyRange: &plan.IndexedJoinRange{}}
+		for _, cb := range bounds {
+			if !strings.EqualFold(cb.column.Name(), name) {
+				continue
+			}
+			switch {
+			case cb.lower && lookup.keyRange.Lower == nil:
+				lookup.keyRange.Lower, lookup.keyRange.LowerInclusive = cb.value, cb.inclusive
+			case !cb.lower && lookup.keyRange.Upper == nil:
+				lookup.keyRange.Upper, lookup.keyRange.UpperInclusive = cb.value, cb.inclusive
+			default:
+				continue
+			}
+			if len(lookup.rangeConds) == 0 || lookup.rangeConds[len(lookup.rangeConds)-1] != cb.cond {
+				lookup.rangeConds = append(lookup.rangeConds, cb.cond)
 			}
 		}
 
-		// If we finished the loop, we didn't match this index expression
-		return nil
+		n := 0
+		if lookup.keyRange.Lower != nil {
+			n++
+		}
+		if lookup.keyRange.Upper != nil {
+			n++
+		}
+		if best == nil || n > bestBounds {
+			best, bestBounds = lookup, n
+		}
 	}
 
-	return keyExprs
+	return best
 }
 
-// index munging
+// rangeIndex returns the index of the indexes given of the expression given alone that can look up ranges of values
+// with both ascending and descending lookups, or nil if there is none.
+func rangeIndex(indexes []sql.Index, expr string) sql.Index {
+	for _, idx := range indexes {
+		if exprs := idx.Expressions(); len(exprs) != 1 || !strings.EqualFold(exprs[0], expr) {
+			continue
+		}
+		_, ascend := idx.(sql.AscendIndex)
+		_, descend := idx.(sql.DescendIndex)
+		if ascend && descend {
+			return idx
+		}
+	}
+	return nil
+}
 
-// Assign indexes to the join conditions and returns the sql.Indexes assigned, as well as returning any aliases used by
-// join conditions
-func findJoinIndexes(ctx *sql.Context, node sql.Node, exprAliases ExprAliases, tableAliases TableAliases, a *Analyzer) (map[string]sql.Index, error) {
-	indexSpan, _ := ctx.Span("find_join_indexes")
-	defer indexSpan.Finish()
+// lookupCost returns the estimated cost of looking up the rows of the secondary node given with the lookup given.
+func (o *joinOptimizer) lookupCost(n sql.Node, lookup *joinLookup) float64 {
+	if lookup.keyRange != nil {
+		return o.costs.rangeLookupCost(n, lookup.rangeConds)
+	}
+	return o.costs.lookupCost(n, lookup.index, len(lookup.primaryTableExpr))
+}
 
-	var indexes map[string]sql.Index
+// indexedJoin returns an IndexedJoin of the nodes given, looking up the rows of the secondary table with the lookup
+// given. Joins on keys only evaluate the conditions other than the equalities of the key on the rows they look up.
+func (o *joinOptimizer) indexedJoin(primary, secondary sql.Node, joinType plan.JoinType, cond sql.Expression, lookup *joinLookup) (sql.Node, error) {
+	primaryTableExpr, err := FixFieldIndexesOnExpressions(primary.Schema(), lookup.primaryTableExpr...)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
-	plan.Inspect(node, func(node sql.Node) bool {
-		switch node := node.(type) {
-		case *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin:
-
-			var cond sql.Expression
-			switch node := node.(type) {
-			case *plan.InnerJoin:
-				cond = node.Cond
-			case *plan.LeftJoin:
-				cond = node.Cond
-			case *plan.RightJoin:
-				cond = node.Cond
+	var keyRange *plan.IndexedJoinRange
+	if lookup.keyRange != nil {
+		keyRange = &plan.IndexedJoinRange{
+			LowerInclusive: lookup.keyRange.LowerInclusive,
+			UpperInclusive: lookup.keyRange.UpperInclusive,
+		}
+		if lookup.keyRange.Lower != nil {
+			if keyRange.Lower, err = FixFieldIndexes(primary.Schema(), lookup.keyRange.Lower); err != nil {
+				return nil, err
 			}
+		}
+		if lookup.keyRange.Upper != nil {
+			if keyRange.Upper, err = FixFieldIndexes(primary.Schema(), lookup.keyRange.Upper); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-			var indexAnalyzer *indexAnalyzer
-			indexAnalyzer, err = getIndexesForNode(ctx, a, node)
-			if err != nil {
-				return false
+	joinSchema := append(primary.Schema(), secondary.Schema()...)
+	joinCond, err := FixFieldIndexes(joinSchema, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	secondary, err = plan.TransformUp(secondary, func(node sql.Node) (sql.Node, error) {
+		if rt, ok := node.(*plan.ResolvedTable); ok {
+			o.a.Log("replacing resolve table %s with IndexedTable", rt.Name())
+			return plan.NewIndexedTable(rt), nil
+		}
+		return node, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if keyRange != nil {
+		return plan.NewRangeIndexedJoin(primary, secondary, joinType, joinCond, keyRange, lookup.index), nil
+	}
+
+	var residual sql.Expression
+	if residualConds := subtractConditions(splitConjunction(cond), lookup.keyConds); len(residualConds) > 0 {
+		if residual, err = FixFieldIndexes(joinSchema, expression.JoinAnd(residualConds...)); err != nil {
+			return nil, err
+		}
+	}
+	return plan.NewIndexedJoin(primary, secondary, joinType, joinCond, primaryTableExpr, lookup.index).WithResidual(residual), nil
+}
+
+// subtractConditions returns the conditions given that aren't any of the conditions to subtract given, which must be
+// the same expressions, not just equal ones, as equal conditions may still be different.
+func subtractConditions(conds, toSubtract []sql.Expression) []sql.Expression {
+	var remainder []sql.Expression
+Conditions:
+	for _, cond := range conds {
+		for _, s := range toSubtract {
+			if cond == s {
+				continue Conditions
 			}
-			defer indexAnalyzer.releaseUsedIndexes()
+		}
+		remainder = append(remainder, cond)
+	}
+	return remainder
+}
 
-			indexes, err = getJoinIndexes(ctx, a, indexAnalyzer, cond, exprAliases, tableAliases)
-			if err != nil {
-				return false
+// hashJoinKeys are the expressions evaluated on the rows of the primary and secondary sides of a hash join to get the
+// keys the rows of the secondary side are hashed by, with the equalities they come from.
+type hashJoinKeys struct {
+	primaryExprs   []sql.Expression
+	secondaryExprs []sql.Expression
+	conds          []sql.Expression
+}
+
+// hashJoinKeysOf returns the keys of a hash join of the primary tables given to the secondary node given, from the
+// equalities of the conditions given between expressions of columns of the primary tables and expressions of columns
+// of the secondary node, or nil if there are none. Only equalities whose sides have types with a single representation
+// of equal values are used, which are converted to a common type if they differ, and other conditions are left to the
+// join condition.
+func hashJoinKeysOf(primaryTables map[string]bool, secondary sql.Node, conds []sql.Expression) *hashJoinKeys {
+	secondaryTables := tableSet(joinLeafTables(secondary))
+
+	var keys hashJoinKeys
+	for _, cond := range conds {
+		eq, ok := cond.(*expression.Equals)
+		if !ok || hasSubquery(eq) {
+			continue
+		}
+
+		for _, pair := range [][2]sql.Expression{{eq.Left(), eq.Right()}, {eq.Right(), eq.Left()}} {
+			p, s := pair[0], pair[1]
+			pTables, sTables := expressionTables(p), expressionTables(s)
+			if len(pTables) == 0 || len(sTables) == 0 ||
+				!containsTables(primaryTables, pTables) || !containsTables(secondaryTables, sTables) {
+				continue
 			}
+
+			if p, s, ok := hashKeyExpressions(p, s); ok {
+				keys.primaryExprs = append(keys.primaryExprs, p)
+				keys.secondaryExprs = append(keys.secondaryExprs, s)
+				keys.conds = append(keys.conds, cond)
+			}
+			break
 		}
+	}
 
-		return true
+	if len(keys.conds) == 0 {
+		return nil
+	}
+	return &keys
+}
+
+// hashKeyExpressions returns the sides of an equality given as keys of a hash join, which must have the same type so
+// equal values have the same hash, and false if they can't be keys. Integers and strings of different types are
+// converted to the types they're compared as, and other types must be the same on both sides.
+func hashKeyExpressions(left, right sql.Expression) (sql.Expression, sql.Expression, bool) {
+	lt, rt := left.Type(), right.Type()
+	switch {
+	case lt == rt && (sql.IsInteger(lt) || isHashableText(lt) || sql.IsTime(lt)):
+		return left, right, true
+	case sql.IsSigned(lt) && sql.IsSigned(rt):
+		return expression.NewConvert(left, expression.ConvertToSigned), expression.NewConvert(right, expression.ConvertToSigned), true
+	case sql.IsUnsigned(lt) && sql.IsUnsigned(rt):
+		return expression.NewConvert(left, expression.ConvertToUnsigned), expression.NewConvert(right, expression.ConvertToUnsigned), true
+	case isHashableText(lt) && isHashableText(rt):
+		return expression.NewConvert(left, expression.ConvertToChar), expression.NewConvert(right, expression.ConvertToChar), true
+	}
+	return nil, nil, false
+}
+
+// isHashableText returns whether the type given is a string type, whose values are compared byte by byte.
+func isHashableText(t sql.Type) bool {
+	return sql.IsText(t) && t != sql.JSON
+}
+
+// hasSubquery returns whether the expression given has a subquery.
+func hasSubquery(e sql.Expression) bool {
+	var found bool
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if _, ok := e.(*plan.Subquery); ok {
+			found = true
+		}
+		return !found
 	})
+	return found
+}
+
+// hashJoin returns a HashJoin of the nodes given, hashing the rows of the secondary node by the keys given.
+func (o *joinOptimizer) hashJoin(primary, secondary sql.Node, joinType plan.JoinType, cond sql.Expression, keys *hashJoinKeys) (sql.Node, error) {
+	primaryKeys, err := FixFieldIndexesOnExpressions(primary.Schema(), keys.primaryExprs...)
+	if err != nil {
+		return nil, err
+	}
 
-	return indexes, err
+	secondaryKeys, err := FixFieldIndexesOnExpressions(secondary.Schema(), keys.secondaryExprs...)
+	if err != nil {
+		return nil, err
+	}
+
+	joinSchema := append(primary.Schema(), secondary.Schema()...)
+	joinCond, err := FixFieldIndexes(joinSchema, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	o.a.Log("hashing the rows of %s to join them", getTableName(secondary))
+	return plan.NewHashJoin(primary, secondary, joinType, joinCond, primaryKeys, secondaryKeys), nil
 }
 
-// Returns the left and right indexes for the two sides of the equality expression given.
-func getJoinEqualityIndex(
-	ctx *sql.Context,
-	a *Analyzer,
-	ia *indexAnalyzer,
-	e *expression.Equals,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-) (leftIdx sql.Index, rightIdx sql.Index) {
+// indexableTable returns the name or alias of the table of the node given, if it's a single table that can be looked
+// up in an index, which may be under nodes like filters.
+func indexableTable(n sql.Node) (string, bool) {
+	tables := 0
+	ok := true
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node.(type) {
+		case *plan.ResolvedTable:
+			tables++
+		case *plan.SubqueryAlias, *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.CrossJoin, *plan.IndexedJoin,
+			*plan.HashJoin, *plan.SemiJoin, *plan.AntiJoin:
+			ok = false
+		}
+		return ok
+	})
 
-	// Only handle column expressions for these join indexes. Evaluable expression like `col=literal` will get pushed
-	// down where possible.
-	if isEvaluable(e.Left()) || isEvaluable(e.Right()) {
-		return nil, nil
+	if !ok || tables != 1 {
+		return "", false
 	}
+	return getTableName(n), true
+}
 
-	leftIdx, rightIdx =
-		ia.IndexByExpression(ctx, ctx.GetCurrentDatabase(), normalizeExpressions(exprAliases, tableAliases, e.Left())...),
-		ia.IndexByExpression(ctx, ctx.GetCurrentDatabase(), normalizeExpressions(exprAliases, tableAliases, e.Right())...)
-	return leftIdx, rightIdx
+// joinLeafTables returns the lower case names of the tables and subquery aliases of the node given, by which the
+// columns of join conditions name them.
+func joinLeafTables(n sql.Node) []string {
+	var tables []string
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.TableAlias:
+			tables = append(tables, strings.ToLower(node.Name()))
+			return false
+		case *plan.SubqueryAlias:
+			tables = append(tables, strings.ToLower(node.Name()))
+			return false
+		case *plan.ResolvedTable:
+			tables = append(tables, strings.ToLower(node.Name()))
+		case *plan.IndexedTableAccess:
+			tables = append(tables, strings.ToLower(node.Name()))
+		}
+		return true
+	})
+	return tables
 }
 
-func getJoinIndexes(ctx *sql.Context,
-	a *Analyzer,
-	ia *indexAnalyzer,
-	e sql.Expression,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-) (map[string]sql.Index, error) {
+// expressionTables returns the lower case names of the tables of the columns of the expression given.
+func expressionTables(e sql.Expression) []string {
+	var tables []string
+	seen := make(map[string]bool)
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if gf, ok := e.(*expression.GetField); ok {
+			t := strings.ToLower(gf.Table())
+			if !seen[t] {
+				seen[t] = true
+				tables = append(tables, t)
+			}
+		}
+		return true
+	})
+	return tables
+}
 
-	switch e := e.(type) {
-	case *expression.Equals:
-		result := make(map[string]sql.Index)
-		leftIdx, rightIdx := getJoinEqualityIndex(ctx, a, ia, e, exprAliases, tableAliases)
-		if leftIdx != nil {
-			result[leftIdx.Table()] = leftIdx
-		}
-		if rightIdx != nil {
-			result[rightIdx.Table()] = rightIdx
-		}
-		return result, nil
-	case *expression.And:
-		exprs := splitConjunction(e)
-		for _, expr := range exprs {
-			if _, ok := expr.(*expression.Equals); !ok {
-				return nil, nil
+// leafOfTable returns the index of the leaf with the table given, or -1 if there isn't one.
+func leafOfTable(leaves []joinLeaf, table string) int {
+	for i, leaf := range leaves {
+		for _, t := range leaf.tables {
+			if t == table {
+				return i
 			}
 		}
+	}
+	return -1
+}
 
-		return getMultiColumnJoinIndex(ctx, exprs, a, ia, exprAliases, tableAliases), nil
+// joinsTables returns whether any of the conditions given joins one of the tables given to the tables of a leaf.
+func joinsTables(joined map[string]bool, leafTables []string, conds []sql.Expression) bool {
+	leaf := tableSet(leafTables)
+	for _, cond := range conds {
+		var onLeaf, onJoined, other bool
+		for _, t := range expressionTables(cond) {
+			switch {
+			case leaf[t]:
+				onLeaf = true
+			case joined[t]:
+				onJoined = true
+			default:
+				other = true
+			}
+		}
+		if onLeaf && onJoined && !other {
+			return true
+		}
 	}
+	return false
+}
 
-	return nil, nil
+// containsTables returns whether all of the tables given are in the set of tables given.
+func containsTables(set map[string]bool, tables []string) bool {
+	for _, t := range tables {
+		if !set[t] {
+			return false
+		}
+	}
+	return true
 }
 
-func getMultiColumnJoinIndex(
-	ctx *sql.Context,
-	exprs []sql.Expression,
-	a *Analyzer,
-	ia *indexAnalyzer,
-	exprAliases ExprAliases,
-	tableAliases TableAliases,
-) map[string]sql.Index {
-	result := make(map[string]sql.Index)
+func tableSet(tables []string) map[string]bool {
+	set := make(map[string]bool, len(tables))
+	for _, t := range tables {
+		set[t] = true
+	}
+	return set
+}
 
-	exprsByTable := joinExprsByTable(exprs)
-	for table, cols := range exprsByTable {
-		idx := ia.IndexByExpression(ctx, ctx.GetCurrentDatabase(), normalizeExpressions(exprAliases, tableAliases, extractExpressions(cols)...)...)
-		if idx != nil {
-			result[normalizeTableName(tableAliases, table)] = idx
+// indexExpressionPresent returns whether the index expressions given occur in the column expressions given. This check
+// is necessary in the case of joining a table to itself, since index expressions always use the original table name,
+// and join expressions use the aliased table name.
+func indexExpressionPresent(indexExprs []string, colExprs []*columnExpr) bool {
+	// every expression in the join has to be found in the column expressions being considered (although there could be
+	// other column expressions as well)
+	for _, indexExpr := range indexExprs {
+		found := false
+		for _, colExpr := range colExprs {
+			if indexExpressionMatches(indexExpr, colExpr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
 		}
 	}
 
-	return result
+	return true
 }
 
-// extractExpressions returns the Expressions in the slice of columnExprs given.
-func extractExpressions(colExprs []*columnExpr) []sql.Expression {
-	result := make([]sql.Expression, len(colExprs))
-	for i, expr := range colExprs {
-		result[i] = expr.colExpr
-	}
-	return result
+func indexExpressionMatches(indexExpr string, expr *columnExpr) bool {
+	// TODO: this string matching on index expressions is pretty fragile, won't handle tables with "." in their names,
+	//  etc. Would be nice to normalize the indexes into a better data structure than just strings to make them easier to
+	//  work with.
+	return strings.ToLower(expr.col.Name()) == strings.ToLower(indexExpr[strings.Index(indexExpr, ".")+1:])
 }
 
-// joinExprsByTable returns a map of the expressions given keyed by their table name.
-func joinExprsByTable(exprs []sql.Expression) map[string][]*columnExpr {
-	var result = make(map[string][]*columnExpr)
+// createPrimaryTableExpr returns a slice of expressions to be used when evaluating a row in the primary table to
+// assemble a lookup key in the secondary table. Column expressions must match the declared column order of the index
+// expressions given, which are all of the expressions of the index or a prefix of them. It also returns the
+// equalities of the key whose lookups return exactly the rows that satisfy them.
+func createPrimaryTableExpr(
+	idxExprs []string,
+	primaryTableEqualityExprs []*columnExpr,
+	exprAliases ExprAliases,
+	tableAliases TableAliases,
+) ([]sql.Expression, []sql.Expression) {
 
-	for _, expr := range exprs {
-		leftExpr, rightExpr := extractJoinColumnExpr(expr)
-		if leftExpr != nil {
-			result[leftExpr.col.Table()] = append(result[leftExpr.col.Table()], leftExpr)
-		}
+	keyExprs := make([]sql.Expression, len(idxExprs))
+	var keyConds []sql.Expression
 
-		if rightExpr != nil {
-			result[rightExpr.col.Table()] = append(result[rightExpr.col.Table()], rightExpr)
+IndexExpressions:
+	for i, idxExpr := range idxExprs {
+		for j := range primaryTableEqualityExprs {
+			p := primaryTableEqualityExprs[j]
+			if idxExpr == normalizeExpression(exprAliases, tableAliases, p.comparand).String() {
+				keyExprs[i] = p.colExpr
+				if isExactLookupKey(p.comparand.Type(), p.colExpr.Type()) {
+					keyConds = append(keyConds, p.comparison)
+				}
+				continue IndexExpressions
+			}
 		}
+
+		// If we finished the loop, we didn't match this index expression
+		return nil, nil
 	}
 
-	return result
+	return keyExprs, keyConds
+}
+
+// isExactLookupKey returns whether looking up values of the key type given in an index of a column of the column type
+// given returns exactly the rows whose column is equal to them, which needs the types to be the same. Keys of other
+// types are converted to the type of the column by the index, which may look up values that aren't equal to them, like
+// the integers floats are rounded to or the prefixes longer strings are truncated to, so the equalities of those keys
+// are still evaluated on the rows looked up.
+func isExactLookupKey(column, key sql.Type) bool {
+	return reflect.DeepEqual(column, key)
 }
 
 // Extracts a pair of column expressions from a join condition, which must be an equality on two columns.
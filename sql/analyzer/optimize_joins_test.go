@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestHashJoinKeys(t *testing.T) {
+	require := require.New(t)
+
+	t2 := plan.NewResolvedTable(memory.NewTable("t2", sql.Schema{
+		{Name: "b", Type: sql.Int64, Source: "t2"},
+		{Name: "s", Type: sql.Text, Source: "t2"},
+		{Name: "f", Type: sql.Float64, Source: "t2"},
+	}))
+
+	a := expression.NewGetFieldWithTable(0, sql.Int8, "t1", "a", false)
+	s1 := expression.NewGetFieldWithTable(1, sql.Text, "t1", "s", false)
+	b := expression.NewGetFieldWithTable(2, sql.Int64, "t2", "b", false)
+	s2 := expression.NewGetFieldWithTable(3, sql.Text, "t2", "s", false)
+	f := expression.NewGetFieldWithTable(4, sql.Float64, "t2", "f", false)
+	primary := map[string]bool{"t1": true}
+
+	// Sides of the same type are used as they are, and integers of different types are converted to a common type
+	bEqualsA := expression.NewEquals(b, a)
+	sEqualsS := expression.NewEquals(s1, s2)
+	keys := hashJoinKeysOf(primary, t2, []sql.Expression{bEqualsA, sEqualsS})
+	require.NotNil(keys)
+	require.Equal([]sql.Expression{expression.NewConvert(a, expression.ConvertToSigned), s1}, keys.primaryExprs)
+	require.Equal([]sql.Expression{expression.NewConvert(b, expression.ConvertToSigned), s2}, keys.secondaryExprs)
+	require.Equal([]sql.Expression{bEqualsA, sEqualsS}, keys.conds)
+
+	// Floats, comparisons and equalities on a single side are left to the join condition
+	keys = hashJoinKeysOf(primary, t2, []sql.Expression{
+		expression.NewEquals(a, f),
+		expression.NewLessThan(a, b),
+		expression.NewEquals(b, expression.NewLiteral(int64(1), sql.Int64)),
+		expression.NewEquals(b, expression.NewGetFieldWithTable(5, sql.Int64, "t3", "c", false)),
+	})
+	require.Nil(keys)
+}
+
+func TestCreatePrimaryTableExpr(t *testing.T) {
+	require := require.New(t)
+
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "t1", "a", false)
+	f := expression.NewGetFieldWithTable(1, sql.Float64, "t1", "f", false)
+	b := expression.NewGetFieldWithTable(2, sql.Int64, "t2", "b", false)
+	c := expression.NewGetFieldWithTable(3, sql.Int64, "t2", "c", false)
+
+	// Equalities of keys of the type of their columns are the key conditions, and the others are left to the residual
+	aEqualsB := expression.NewEquals(a, b)
+	fEqualsC := expression.NewEquals(f, c)
+	aCol, _ := extractJoinColumnExpr(aEqualsB)
+	fCol, _ := extractJoinColumnExpr(fEqualsC)
+	keyExprs, keyConds := createPrimaryTableExpr([]string{"t2.c", "t2.b"}, []*columnExpr{aCol, fCol}, nil, nil)
+	require.Equal([]sql.Expression{f, a}, keyExprs)
+	require.Equal([]sql.Expression{aEqualsB}, keyConds)
+
+	// Index expressions without an equality can't be looked up
+	keyExprs, keyConds = createPrimaryTableExpr([]string{"t2.b", "t2.d"}, []*columnExpr{aCol, fCol}, nil, nil)
+	require.Nil(keyExprs)
+	require.Nil(keyConds)
+}
@@ -18,7 +18,9 @@ var (
 func shouldParallelize(node sql.Node) bool {
 	// Do not try to parallelize index operations or schema operations
 	switch node.(type) {
-	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateIndex, *plan.Describe, *plan.DropIndex, *plan.ShowCreateTable:
+	case *plan.CreateForeignKey, *plan.DropForeignKey, *plan.AlterIndex, *plan.CreateIndex, *plan.Describe, *plan.DropIndex, *plan.ShowCreateTable,
+		*plan.ChecksumTable, *plan.CheckTable, *plan.OptimizeTable, *plan.AnalyzeTable, *plan.CreateCheck, *plan.DropCheck,
+		*plan.DropConstraint:
 		return false
 	default:
 		return true
@@ -26,7 +28,7 @@ func shouldParallelize(node sql.Node) bool {
 }
 
 func parallelize(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (sql.Node, error) {
-	if a.Parallelism <= 1 || !node.Resolved() {
+	if a.Parallelism <= 1 || a.DeterministicOrder || !node.Resolved() {
 		return node, nil
 	}
 
@@ -35,13 +37,23 @@ func parallelize(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (sq
 		return node, nil
 	}
 
-	node, err := plan.TransformUp(node, func(node sql.Node) (sql.Node, error) {
+	// Point lookups read their one row directly from their table
+	selector := func(parent sql.Node, child sql.Node, childNum int) bool {
+		_, ok := parent.(*plan.PointLookup)
+		return !ok
+	}
+
+	node, err := plan.TransformUpWithSelector(node, selector, func(node sql.Node) (sql.Node, error) {
 		if !isParallelizable(node) {
 			return node, nil
 		}
 		ParallelQueryCounter.With("parallelism", strconv.Itoa(a.Parallelism)).Add(1)
 
-		return plan.NewExchange(a.Parallelism, node), nil
+		exchange := plan.NewExchange(a.Parallelism, node)
+		if a.Catalog != nil {
+			exchange.ResourceGroups = a.Catalog.ResourceGroupRegistry
+		}
+		return exchange, nil
 	})
 
 	if err != nil {
@@ -59,16 +71,20 @@ func removeRedundantExchanges(node sql.Node) (sql.Node, error) {
 		return node, nil
 	}
 
-	child, err := plan.TransformUp(exchange.Child, func(node sql.Node) (sql.Node, error) {
+	child, same, err := plan.TransformUpWithIdentity(exchange.Child, func(node sql.Node) (sql.Node, sql.TreeIdentity, error) {
 		if exchange, ok := node.(*plan.Exchange); ok {
-			return exchange.Child, nil
+			return exchange.Child, sql.NewTree, nil
 		}
-		return node, nil
+		return node, sql.SameTree, nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	if same {
+		return exchange, nil
+	}
+
 	return exchange.WithChildren(child)
 }
 
@@ -95,7 +111,16 @@ func isParallelizable(node sql.Node) bool {
 			*plan.Project,
 			*plan.TableAlias,
 			*plan.Exchange:
+		case *plan.IndexedTableAccess:
+			// An exchange would read all the partitions of the table instead of the rows looked up in its index
+			ok = false
+			return false
 		case sql.Table:
+			// The rows of the partitions of an ordered table must be read one partition after another
+			if isOrderedTable(node.(sql.Table)) {
+				ok = false
+				return false
+			}
 			lastWasTable = true
 			tableSeen = true
 		default:
@@ -108,3 +133,22 @@ func isParallelizable(node sql.Node) bool {
 
 	return ok && tableSeen && lastWasTable
 }
+
+// isOrderedTable returns whether the table given, or the table it wraps, returns its rows in an order.
+func isOrderedTable(t sql.Table) bool {
+	if rt, ok := t.(*plan.ResolvedTable); ok {
+		t = rt.Table
+	}
+
+	for {
+		if ot, ok := t.(sql.OrderedTable); ok && len(ot.Order()) > 0 {
+			return true
+		}
+
+		w, ok := t.(sql.TableWrapper)
+		if !ok {
+			return false
+		}
+		t = w.Underlying()
+	}
+}
@@ -86,6 +86,22 @@ func TestIsParallelizable(t *testing.T) {
 			plan.NewResolvedTable(table),
 			true,
 		},
+		{
+			"ordered table",
+			plan.NewResolvedTable(
+				memory.NewPushdownTable("t", sql.Schema{{Name: "a", Type: sql.Int64, Source: "t"}}).
+					WithOrder([]sql.SortColumn{{Column: "a"}}),
+			),
+			false,
+		},
+		{
+			"indexed table",
+			plan.NewFilter(
+				expression.NewLiteral(1, sql.Int64),
+				plan.NewIndexedTable(plan.NewResolvedTable(table)),
+			),
+			false,
+		},
 		{
 			"filter",
 			plan.NewFilter(
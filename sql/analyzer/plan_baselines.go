@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// capturePlanBaselines captures the plans of the statements pinned with PIN PLAN BASELINE FOR. The statement is
+// analyzed on its own, as EXPLAIN analyzes it, and its plan is captured as the optimizer hints that make the analyzer
+// choose it again.
+func capturePlanBaselines(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, ctx := ctx.Span("capture_plan_baselines")
+	defer span.Finish()
+
+	pin, ok := n.(*plan.PinPlanBaseline)
+	if !ok || pin.Baseline != nil {
+		return n, nil
+	}
+
+	analyzed, err := a.Analyze(ctx, plan.NewDescribeQuery("", pin.Statement), nil)
+	if err != nil {
+		return nil, err
+	}
+	// The analyzer may wrap the DescribeQuery node, to track the progress of the statement
+	var described sql.Node
+	plan.Inspect(analyzed, func(node sql.Node) bool {
+		if describe, ok := node.(*plan.DescribeQuery); ok {
+			described = describe.Child
+		}
+		return described == nil
+	})
+	if described == nil {
+		return nil, fmt.Errorf("the plan of %s was not described", pin.DigestText)
+	}
+
+	npin := *pin
+	npin.Baseline = &sql.PlanBaseline{
+		Digest:     pin.Digest,
+		DigestText: pin.DigestText,
+		Hints:      planBaselineHints(pin.Statement, described),
+		Plan:       strings.TrimRight(described.String(), "\n"),
+	}
+	a.Log("captured plan baseline %s for %s", npin.Baseline.Hints, pin.DigestText)
+	return &npin, nil
+}
+
+// planBaselineHints returns the optimizer hints of the outermost query block of the statement given that make the
+// analyzer choose the plan given for it again: a JOIN_ORDER hint with its tables in the order they're joined,
+// HASH_JOIN and NO_HASH_JOIN hints for the tables that are and aren't hashed, and for every table an INDEX hint with
+// the index its rows are looked up in for a join or a point lookup, an INDEX hint without indexes if its rows are
+// looked up in the indexes of its filters, or else a NO_INDEX hint. The MERGE and NO_MERGE hints of the statement are
+// kept as they are, since merged derived tables can't be told apart from tables in the plan. Tables of nested query
+// blocks, like those of derived tables and of subqueries rewritten into joins, keep the plans the analyzer chooses for
+// them.
+func planBaselineHints(statement, analyzed sql.Node) sql.Hints {
+	tables := tableSet(hintTables(statement))
+
+	var hints sql.Hints
+	if qh, ok := statement.(*plan.QueryHints); ok {
+		for _, hint := range qh.Hints {
+			if hint.Name == sql.MergeHint || hint.Name == sql.NoMergeHint {
+				hints = append(hints, hint)
+			}
+		}
+	}
+
+	var order []string
+	seen := make(map[string]bool)
+	for _, t := range joinLeafTables(analyzed) {
+		if tables[t] && !seen[t] {
+			seen[t] = true
+			order = append(order, t)
+		}
+	}
+
+	derived := make(map[string]bool)
+	hashed := make(map[string]bool)
+	indexes := make(map[string]sql.Hint)
+	useIndex := func(table string, index ...string) {
+		table = strings.ToLower(table)
+		if _, ok := indexes[table]; !ok {
+			indexes[table] = sql.Hint{Name: sql.IndexHint, Args: append([]string{table}, index...)}
+		}
+	}
+	plan.Inspect(analyzed, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.SubqueryAlias:
+			derived[strings.ToLower(node.Name())] = true
+			return false
+		case *plan.HashJoin:
+			for _, t := range joinLeafTables(node.Right) {
+				hashed[t] = true
+			}
+		case *plan.IndexedJoin:
+			if secondary := joinLeafTables(node.Right); len(secondary) == 1 {
+				useIndex(secondary[0], strings.ToLower(node.Index.ID()))
+			}
+		case *plan.TableAlias:
+			if lookup, ok := node.Child.(*plan.PointLookup); ok {
+				useIndex(node.Name(), strings.ToLower(lookup.Index.ID()))
+			}
+		case *plan.PointLookup:
+			useIndex(node.Table.Name(), strings.ToLower(node.Index.ID()))
+		case *plan.DecoratedNode:
+			if strings.HasPrefix(node.Decoration(), indexedTableAccessDecoration) {
+				useIndex(getTableName(node.Child))
+			}
+		}
+		return true
+	})
+
+	if len(order) > 1 {
+		hints = append(hints, sql.Hint{Name: sql.JoinOrderHint, Args: order})
+
+		var hash, noHash []string
+		for _, t := range order[1:] {
+			if hashed[t] {
+				hash = append(hash, t)
+			} else {
+				noHash = append(noHash, t)
+			}
+		}
+		if len(hash) > 0 {
+			hints = append(hints, sql.Hint{Name: sql.HashJoinHint, Args: hash})
+		}
+		if len(noHash) > 0 {
+			hints = append(hints, sql.Hint{Name: sql.NoHashJoinHint, Args: noHash})
+		}
+	}
+
+	for _, t := range order {
+		if derived[t] {
+			continue
+		}
+		if hint, ok := indexes[t]; ok {
+			hints = append(hints, hint)
+		} else {
+			hints = append(hints, sql.Hint{Name: sql.NoIndexHint, Args: []string{t}})
+		}
+	}
+	return hints
+}
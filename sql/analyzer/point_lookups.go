@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// replacePointLookups replaces filters over a table that fix all the columns of one of its unique indexes to
+// constant values with a plan.PointLookup, which fetches the one row matching that key directly. Only native indexes
+// of tables implementing sql.IndexedTable are considered, if the INDEX and NO_INDEX hints of the table allow them.
+func replacePointLookups(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, ctx := ctx.Span("replace_point_lookups")
+	defer span.Finish()
+
+	if !n.Resolved() || len(scope.Schema()) > 0 {
+		return n, nil
+	}
+
+	// Data modification statements need to find the table they modify among their children, so leave them alone.
+	switch describedNode(n).(type) {
+	case *plan.InsertInto, *plan.Update, *plan.DeleteFrom, *plan.CreateIndex, *plan.CreateTrigger:
+		return n, nil
+	}
+
+	hints := queryHints(n)
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		filter, ok := node.(*plan.Filter)
+		if !ok {
+			return node, nil
+		}
+
+		rt, ok := filter.Child.(*plan.ResolvedTable)
+		if !ok {
+			return node, nil
+		}
+
+		lookup, err := getPointLookup(ctx, rt, filter.Expression, hints)
+		if err != nil {
+			return nil, err
+		}
+
+		if lookup == nil {
+			return node, nil
+		}
+
+		a.Log("replaced filter on table %q with point lookup on index %q", rt.Name(), lookup.Index.ID())
+		return lookup, nil
+	})
+}
+
+// getPointLookup returns a point lookup of the table given for the filter given, or nil if the filter doesn't fix a
+// complete unique key of the table with an index the hints given allow.
+func getPointLookup(ctx *sql.Context, rt *plan.ResolvedTable, filter sql.Expression, hints sql.Hints) (*plan.PointLookup, error) {
+	it, ok := rt.Table.(sql.IndexedTable)
+	if !ok || containsSubquery(filter) {
+		return nil, nil
+	}
+
+	// Collect the constant value every column is compared to for equality
+	values := make(map[string]interface{})
+	for _, e := range splitConjunction(filter) {
+		eq, ok := e.(*expression.Equals)
+		if !ok {
+			continue
+		}
+
+		field, lit := pointLookupOperands(eq)
+		if field == nil || lit == nil {
+			continue
+		}
+
+		v, err := lit.Eval(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// Comparisons to NULL never match any row, and values that can't be represented in the column's type
+		// must be compared as the normal filter would.
+		if v == nil {
+			continue
+		}
+
+		v, err = field.Type().Convert(v)
+		if err != nil {
+			continue
+		}
+
+		values[field.String()] = v
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	indexes, err := it.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hint, hinted := hints.ForTable(strings.ToLower(rt.Name()), sql.IndexHint, sql.NoIndexHint)
+Indexes:
+	for _, idx := range indexes {
+		if !idx.IsUnique() || (hinted && !hintAllowsIndex(hint, idx)) {
+			continue
+		}
+
+		exprs := idx.Expressions()
+		key := make([]interface{}, len(exprs))
+		for i, e := range exprs {
+			v, ok := values[e]
+			if !ok {
+				continue Indexes
+			}
+			key[i] = v
+		}
+
+		return plan.NewPointLookup(rt, idx, key, filter), nil
+	}
+
+	return nil, nil
+}
+
+// pointLookupOperands returns the column and the literal compared by the equality given, in either order.
+func pointLookupOperands(eq *expression.Equals) (*expression.GetField, *expression.Literal) {
+	if field, ok := eq.Left().(*expression.GetField); ok {
+		lit, _ := eq.Right().(*expression.Literal)
+		return field, lit
+	}
+	if field, ok := eq.Right().(*expression.GetField); ok {
+		lit, _ := eq.Left().(*expression.Literal)
+		return field, lit
+	}
+	return nil, nil
+}
@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestReplacePointLookups(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int32, Source: "mytable", PrimaryKey: true},
+		{Name: "f", Type: sql.Float64, Source: "mytable"},
+		{Name: "t", Type: sql.Text, Source: "mytable"},
+	})
+
+	table.EnablePrimaryKeyIndexes()
+	err := table.CreateIndex(sql.NewEmptyContext(), "f", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{
+		{
+			Name:   "f",
+			Length: 0,
+		},
+	}, "")
+	require.NoError(err)
+
+	idxes, err := table.GetIndexes(sql.NewEmptyContext())
+	require.NoError(err)
+	pkIdx := idxes[0]
+
+	db := memory.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := NewDefault(catalog)
+
+	i := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)
+	f := expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", true)
+	rt := plan.NewResolvedTable(table)
+
+	pkFilter := expression.NewAnd(
+		expression.NewEquals(expression.NewLiteral(int64(2), sql.Int64), i),
+		expression.NewGreaterThan(f, expression.NewLiteral(1.0, sql.Float64)),
+	)
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "primary key equality",
+			node: plan.NewProject(
+				[]sql.Expression{f},
+				plan.NewFilter(pkFilter, rt),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{f},
+				plan.NewPointLookup(rt, pkIdx, []interface{}{int32(2)}, pkFilter),
+			),
+		},
+		{
+			name: "non unique index",
+			node: plan.NewFilter(
+				expression.NewEquals(f, expression.NewLiteral(3.14, sql.Float64)),
+				rt,
+			),
+		},
+		{
+			name: "non literal value",
+			node: plan.NewFilter(
+				expression.NewEquals(i, expression.NewArithmetic(f, expression.NewLiteral(1, sql.Int8), "+")),
+				rt,
+			),
+		},
+		{
+			name: "null value",
+			node: plan.NewFilter(
+				expression.NewEquals(i, expression.NewLiteral(nil, sql.Null)),
+				rt,
+			),
+		},
+		{
+			name: "disjunction",
+			node: plan.NewFilter(
+				expression.NewOr(
+					expression.NewEquals(i, expression.NewLiteral(int64(1), sql.Int64)),
+					expression.NewEquals(i, expression.NewLiteral(int64(2), sql.Int64)),
+				),
+				rt,
+			),
+		},
+		{
+			name: "no index hint of another index",
+			node: plan.NewQueryHints(
+				sql.Hints{{Name: sql.NoIndexHint, Args: []string{"mytable", "f"}}},
+				plan.NewFilter(pkFilter, rt),
+			),
+			expected: plan.NewQueryHints(
+				sql.Hints{{Name: sql.NoIndexHint, Args: []string{"mytable", "f"}}},
+				plan.NewPointLookup(rt, pkIdx, []interface{}{int32(2)}, pkFilter),
+			),
+		},
+		{
+			name: "no index hint",
+			node: plan.NewQueryHints(
+				sql.Hints{{Name: sql.NoIndexHint, Args: []string{"mytable", "primary"}}},
+				plan.NewFilter(pkFilter, rt),
+			),
+		},
+		{
+			name: "delete",
+			node: plan.NewDeleteFrom(
+				plan.NewFilter(
+					expression.NewEquals(i, expression.NewLiteral(int64(1), sql.Int64)),
+					rt,
+				),
+			),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("replace_point_lookups"))
+}
@@ -65,7 +65,7 @@ func trackProcess(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 				t = plan.NewProcessTable(table, onPartitionDone, onPartitionStart, onRowNext)
 			}
 
-			return plan.NewResolvedTable(t), nil
+			return plan.NewResolvedTableInDatabase(t, n.Database), nil
 		default:
 			return n, nil
 		}
@@ -0,0 +1,281 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// propagateEqualities filters the derived tables and the subqueries of the WHERE clause of every query block on the
+// constants their columns are equal to in the WHERE clause, directly or through the conditions of its inner joins, so
+// the filters are pushed down and looked up in indexes in them like their own. Given
+// `FROM (SELECT ...) x JOIN t ON x.id = t.id WHERE t.id = 5`, the derived table x is filtered on id = 5, and so is s in
+// the subquery `EXISTS (SELECT * FROM s WHERE s.id = t.id)` of the same WHERE clause. The filters added are implied by
+// the conditions of the query block, so they never change its rows. Only numeric constants are propagated: comparing
+// strings depends on the collations of the columns compared, which aren't known until the query is resolved.
+func propagateEqualities(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("propagate_equalities")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		filter, ok := node.(*plan.Filter)
+		if !ok {
+			return node, nil
+		}
+
+		eqs := newEqualities(filter)
+		if len(eqs.constants) == 0 {
+			return node, nil
+		}
+
+		// Derived tables are only reached through inner joins, whose rows all satisfy the conditions of the block
+		selector := func(parent sql.Node, child sql.Node, childNum int) bool {
+			switch parent.(type) {
+			case *plan.Filter, *plan.InnerJoin, *plan.CrossJoin:
+				return true
+			default:
+				return false
+			}
+		}
+		child, err := plan.TransformUpWithSelector(filter.Child, selector, func(node sql.Node) (sql.Node, error) {
+			sq, ok := node.(*plan.SubqueryAlias)
+			if !ok {
+				return node, nil
+			}
+			return eqs.filterDerivedTable(a, sq)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		cond, err := expression.TransformUp(filter.Expression, func(e sql.Expression) (sql.Expression, error) {
+			sq, ok := e.(*plan.Subquery)
+			if !ok {
+				return e, nil
+			}
+			query, err := eqs.filterSubquery(a, sq.Query)
+			if err != nil {
+				return nil, err
+			}
+			return sq.WithQuery(query), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return plan.NewFilter(cond, child), nil
+	})
+}
+
+// equalities are the numeric constants the columns of a query block are equal to in its WHERE clause, keyed by the
+// lowercase names of their table and column, joined with a dot.
+type equalities struct {
+	constants map[string]*expression.Literal
+	// tables are the schemas of the tables of the block, which qualify its unqualified columns
+	tables map[string]sql.Schema
+}
+
+// newEqualities returns the constants the columns of the query block of the filter given are equal to, through any
+// chain of equalities of its columns in the filter and in the conditions of the inner joins below it.
+func newEqualities(filter *plan.Filter) *equalities {
+	eqs := &equalities{constants: make(map[string]*expression.Literal), tables: make(map[string]sql.Schema)}
+
+	conds := splitConjunction(filter.Expression)
+	var walk func(sql.Node)
+	walk = func(node sql.Node) {
+		switch n := node.(type) {
+		case *plan.InnerJoin:
+			conds = append(conds, splitConjunction(n.Cond)...)
+			walk(n.Left)
+			walk(n.Right)
+		case *plan.CrossJoin:
+			walk(n.Left)
+			walk(n.Right)
+		case *plan.TableAlias:
+			eqs.tables[strings.ToLower(n.Name())] = n.Schema()
+		case *plan.ResolvedTable:
+			eqs.tables[strings.ToLower(n.Name())] = n.Schema()
+		}
+	}
+	walk(filter.Child)
+
+	var columns [][2]string
+	for _, cond := range conds {
+		eq, ok := cond.(*expression.Equals)
+		if !ok {
+			continue
+		}
+		left, leftOk := eqs.columnKey(eq.Left())
+		right, rightOk := eqs.columnKey(eq.Right())
+		switch {
+		case leftOk && rightOk:
+			columns = append(columns, [2]string{left, right})
+		case leftOk:
+			eqs.addConstant(left, eq.Right())
+		case rightOk:
+			eqs.addConstant(right, eq.Left())
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, c := range columns {
+			for i := range c {
+				if lit, ok := eqs.constants[c[i]]; ok {
+					if _, ok := eqs.constants[c[1-i]]; !ok {
+						eqs.constants[c[1-i]] = lit
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	return eqs
+}
+
+func (eqs *equalities) addConstant(key string, e sql.Expression) {
+	lit, ok := e.(*expression.Literal)
+	if !ok || !sql.IsNumber(lit.Type()) {
+		return
+	}
+	if _, ok := eqs.constants[key]; !ok {
+		eqs.constants[key] = lit
+	}
+}
+
+// columnKey returns the key of the unresolved column given, qualified with the only table of the block with a column
+// of its name if it's unqualified, or false if it isn't a column or its table isn't known.
+func (eqs *equalities) columnKey(e sql.Expression) (string, bool) {
+	uc, ok := e.(*expression.UnresolvedColumn)
+	if !ok {
+		return "", false
+	}
+
+	table := strings.ToLower(uc.Table())
+	if table == "" {
+		for name, schema := range eqs.tables {
+			if !schema.Contains(uc.Name(), name) {
+				continue
+			}
+			if table != "" {
+				return "", false
+			}
+			table = name
+		}
+		if table == "" {
+			return "", false
+		}
+	}
+	return table + "." + strings.ToLower(uc.Name()), true
+}
+
+// filterDerivedTable filters the derived table given on the constants its columns are equal to. Derived tables already
+// analyzed, like the ones of views, and the ones that rename their columns are left as they are, as are the ones whose
+// rows would change if they were filtered before their limit, their window functions or the other side of their union.
+func (eqs *equalities) filterDerivedTable(a *Analyzer, sq *plan.SubqueryAlias) (sql.Node, error) {
+	if sq.Child.Resolved() || len(sq.Columns) > 0 || !canFilterDerivedTable(sq.Child) {
+		return sq, nil
+	}
+
+	prefix := strings.ToLower(sq.Name()) + "."
+	var filters []sql.Expression
+	for _, key := range eqs.sortedKeys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		filters = append(filters, expression.NewEquals(
+			expression.NewUnresolvedColumn(strings.TrimPrefix(key, prefix)),
+			eqs.constants[key],
+		))
+	}
+	if len(filters) == 0 {
+		return sq, nil
+	}
+
+	a.Log("filtering derived table %q on the constants its columns are equal to", sq.Name())
+	return sq.WithChildren(plan.NewFilter(expression.JoinAnd(filters...), sq.Child))
+}
+
+// canFilterDerivedTable returns whether the query of a derived table given can be filtered on the columns it selects
+// without changing the rows the filter lets through.
+func canFilterDerivedTable(n sql.Node) bool {
+	switch n := n.(type) {
+	case *plan.Sort:
+		return canFilterDerivedTable(n.Child)
+	case *plan.Distinct:
+		return canFilterDerivedTable(n.Child)
+	case *plan.Having, *plan.GroupBy:
+		return true
+	case *plan.Project:
+		return plan.FindWindowFunction(n.Projections...) == nil
+	default:
+		return false
+	}
+}
+
+// filterSubquery filters the subquery given on the constants the columns of the block its columns are compared to in its
+// WHERE clause are equal to, adding the filters to its WHERE clause.
+func (eqs *equalities) filterSubquery(a *Analyzer, query sql.Node) (sql.Node, error) {
+	// Columns qualified with the names of the tables of the subquery are its own
+	inner := make(map[string]bool)
+	plan.Inspect(query, func(node sql.Node) bool {
+		if nameable, ok := node.(sql.Nameable); ok {
+			switch node.(type) {
+			case *plan.ResolvedTable, *plan.UnresolvedTable, *plan.TableAlias, *plan.SubqueryAlias:
+				inner[strings.ToLower(nameable.Name())] = true
+			}
+		}
+		_, ok := node.(*plan.SubqueryAlias)
+		return !ok
+	})
+
+	outerConstant := func(e sql.Expression) (*expression.Literal, bool) {
+		uc, ok := e.(*expression.UnresolvedColumn)
+		if !ok || uc.Table() == "" || inner[strings.ToLower(uc.Table())] {
+			return nil, false
+		}
+		lit, ok := eqs.constants[strings.ToLower(uc.Table())+"."+strings.ToLower(uc.Name())]
+		return lit, ok
+	}
+
+	return plan.TransformUp(query, func(node sql.Node) (sql.Node, error) {
+		filter, ok := node.(*plan.Filter)
+		if !ok {
+			return node, nil
+		}
+
+		var filters []sql.Expression
+		for _, cond := range splitConjunction(filter.Expression) {
+			eq, ok := cond.(*expression.Equals)
+			if !ok {
+				continue
+			}
+			for _, sides := range [][2]sql.Expression{{eq.Left(), eq.Right()}, {eq.Right(), eq.Left()}} {
+				_, isColumn := sides[0].(*expression.UnresolvedColumn)
+				_, isOuter := outerConstant(sides[0])
+				if lit, ok := outerConstant(sides[1]); ok && isColumn && !isOuter {
+					filters = append(filters, expression.NewEquals(sides[0], lit))
+				}
+			}
+		}
+		if len(filters) == 0 {
+			return node, nil
+		}
+
+		a.Log("filtering subquery on the constants the columns of the outer query are equal to")
+		return plan.NewFilter(expression.JoinAnd(append([]sql.Expression{filter.Expression}, filters...)...), filter.Child), nil
+	})
+}
+
+func (eqs *equalities) sortedKeys() []string {
+	keys := make([]string, 0, len(eqs.constants))
+	for key := range eqs.constants {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestPropagateEqualities(t *testing.T) {
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "t"},
+		{Name: "s", Type: sql.Text, Source: "t"},
+	})
+	resolved := plan.NewResolvedTableInDatabase(table, "mydb")
+	a := NewDefault(sql.NewCatalog())
+
+	// Derived tables are still unresolved when equalities are propagated, and have no schema yet
+	derived := func(child sql.Node) sql.Node {
+		return plan.NewSubqueryAlias("x", "", child)
+	}
+	project := plan.NewProject([]sql.Expression{uc("id")}, plan.NewUnresolvedTable("u", ""))
+	five := expression.NewLiteral(int8(5), sql.Int8)
+
+	tests := []struct {
+		name     string
+		node     sql.Node
+		expected sql.Node
+	}{
+		{
+			name: "through a join condition",
+			node: plan.NewFilter(
+				eq(uqc("t", "id"), five),
+				plan.NewInnerJoin(derived(project), resolved, eq(uqc("x", "id"), uqc("t", "id"))),
+			),
+			expected: plan.NewFilter(
+				eq(uqc("t", "id"), five),
+				plan.NewInnerJoin(
+					derived(plan.NewFilter(eq(uc("id"), five), project)),
+					resolved,
+					eq(uqc("x", "id"), uqc("t", "id")),
+				),
+			),
+		},
+		{
+			name: "unqualified columns of tables",
+			node: plan.NewFilter(
+				and(eq(five, uc("id")), eq(uqc("x", "id"), uc("id"))),
+				plan.NewCrossJoin(resolved, derived(project)),
+			),
+			expected: plan.NewFilter(
+				and(eq(five, uc("id")), eq(uqc("x", "id"), uc("id"))),
+				plan.NewCrossJoin(resolved, derived(plan.NewFilter(eq(uc("id"), five), project))),
+			),
+		},
+		{
+			name: "subquery",
+			node: plan.NewFilter(
+				and(
+					eq(uqc("t", "id"), five),
+					plan.NewExistsSubquery(plan.NewSubquery(plan.NewFilter(
+						eq(uqc("u", "id"), uqc("t", "id")),
+						plan.NewUnresolvedTable("u", ""),
+					), "")),
+				),
+				resolved,
+			),
+			expected: plan.NewFilter(
+				and(
+					eq(uqc("t", "id"), five),
+					plan.NewExistsSubquery(plan.NewSubquery(plan.NewFilter(
+						and(eq(uqc("u", "id"), uqc("t", "id")), eq(uqc("u", "id"), five)),
+						plan.NewUnresolvedTable("u", ""),
+					), "")),
+				),
+				resolved,
+			),
+		},
+		{
+			name: "subquery with a table of the same name",
+			node: plan.NewFilter(
+				and(
+					eq(uqc("t", "id"), five),
+					plan.NewExistsSubquery(plan.NewSubquery(plan.NewFilter(
+						eq(uqc("u", "id"), uqc("t", "id")),
+						plan.NewCrossJoin(plan.NewUnresolvedTable("u", ""), plan.NewUnresolvedTable("t", "")),
+					), "")),
+				),
+				resolved,
+			),
+		},
+		{
+			name: "string constants",
+			node: plan.NewFilter(
+				eq(uqc("x", "id"), expression.NewLiteral("5", sql.LongText)),
+				derived(project),
+			),
+		},
+		{
+			name: "derived table on the right of a left join",
+			node: plan.NewFilter(
+				eq(uqc("t", "id"), five),
+				plan.NewLeftJoin(resolved, derived(project), eq(uqc("x", "id"), uqc("t", "id"))),
+			),
+		},
+		{
+			name: "limit",
+			node: plan.NewFilter(
+				eq(uqc("x", "id"), five),
+				derived(plan.NewLimit(1, project)),
+			),
+		},
+		{
+			name: "window function",
+			node: plan.NewFilter(
+				eq(uqc("x", "id"), five),
+				derived(plan.NewProject([]sql.Expression{
+					uc("id"),
+					plan.NewWindowFunction(expression.NewUnresolvedFunction("row_number", false), nil, nil),
+				}, plan.NewUnresolvedTable("u", ""))),
+			),
+		},
+	}
+
+	ctx := sql.NewEmptyContext().WithCurrentDB("mydb")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			result, err := getRule("propagate_equalities").Apply(ctx, a, tt.node, nil)
+			require.NoError(err)
+
+			expected := tt.expected
+			if expected == nil {
+				expected = tt.node
+			}
+			require.Equal(expected, result)
+		})
+	}
+}
@@ -256,12 +256,36 @@ func fixRemainingFieldsIndexes(n sql.Node, scope *Scope) (sql.Node, error) {
 	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		switch n := n.(type) {
 		case *plan.SubqueryAlias:
-			child, err := fixRemainingFieldsIndexes(n.Child, scope)
+			// Derived tables are analyzed without the scope of the subquery expressions they're in
+			child, err := fixRemainingFieldsIndexes(n.Child, nil)
 			if err != nil {
 				return nil, err
 			}
 
 			return n.WithChildren(child)
+		case *plan.HashJoin:
+			// The keys of each side are evaluated on the rows of that side only, which start with the row of the scope
+			schema := append(scope.Schema(), n.Left.Schema()...)
+			cond, err := fixRemainingFieldIndexes(append(schema, n.Right.Schema()...), n.Cond)
+			if err != nil {
+				return nil, err
+			}
+
+			primaryKeys := make([]sql.Expression, len(n.PrimaryKeys()))
+			for i, e := range n.PrimaryKeys() {
+				if primaryKeys[i], err = fixRemainingFieldIndexes(schema, e); err != nil {
+					return nil, err
+				}
+			}
+
+			secondaryKeys := make([]sql.Expression, len(n.SecondaryKeys()))
+			for i, e := range n.SecondaryKeys() {
+				if secondaryKeys[i], err = fixRemainingFieldIndexes(append(scope.Schema(), n.Right.Schema()...), e); err != nil {
+					return nil, err
+				}
+			}
+
+			return plan.NewHashJoin(n.Left, n.Right, n.JoinType(), cond, primaryKeys, secondaryKeys), nil
 		default:
 			if _, ok := n.(sql.Expressioner); !ok {
 				return n, nil
@@ -276,24 +300,39 @@ func fixRemainingFieldsIndexes(n sql.Node, scope *Scope) (sql.Node, error) {
 				return n, nil
 			}
 
-			indexedCols := make(map[tableCol]int)
-			for i, col := range append(scope.Schema(), schema...) {
-				indexedCols[tableCol{col.Source, col.Name}] = i
-			}
-
+			schema = append(scope.Schema(), schema...)
 			return plan.TransformExpressions(n, func(e sql.Expression) (sql.Expression, error) {
-				gf, ok := e.(*expression.GetField)
-				if !ok {
-					return e, nil
-				}
-
-				idx, ok := indexedCols[tableCol{gf.Table(), gf.Name()}]
-				if !ok {
-					return nil, sql.ErrTableColumnNotFound.New(gf.Table(), gf.Name())
-				}
-
-				return gf.WithIndex(idx), nil
+				return fixRemainingFieldIndex(schema, e)
 			})
 		}
 	})
 }
+
+// fixRemainingFieldIndexes fixes the indexes of the fields of the expression given to the indexes of their columns in
+// the schema given.
+func fixRemainingFieldIndexes(schema sql.Schema, e sql.Expression) (sql.Expression, error) {
+	return expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+		return fixRemainingFieldIndex(schema, e)
+	})
+}
+
+// fixRemainingFieldIndex fixes the index of the expression given if it's a field to the index of its column in the
+// schema given.
+func fixRemainingFieldIndex(schema sql.Schema, e sql.Expression) (sql.Expression, error) {
+	gf, ok := e.(*expression.GetField)
+	if !ok {
+		return e, nil
+	}
+
+	idx := -1
+	for i, col := range schema {
+		if col.Source == gf.Table() && col.Name == gf.Name() {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, sql.ErrTableColumnNotFound.New(gf.Table(), gf.Name())
+	}
+
+	return gf.WithIndex(idx), nil
+}
@@ -21,15 +21,9 @@ func pushdownFilters(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 	}
 
 	// First step is to find all col exprs and group them by the table they mention.
-	// Even if they appear multiple times, only the first one will be used.
-	filtersByTable, err := getFiltersByTable(ctx, n)
-
-	// An error returned by getFiltersByTable means that we can't cleanly separate all the filters into tables.
-	// In that case, skip pushing down the filters.
-	// TODO: we could also handle this by keeping track of the filters we can't handle and re-applying them at the end
-	if err != nil {
-		return n, nil
-	}
+	// Even if they appear multiple times, only the first one will be used. Filter predicates that can't be assigned to
+	// a single table are kept as residual predicates in their filter nodes.
+	filtersByTable := getFiltersByTable(ctx, n)
 
 	indexes, err := getIndexesByTable(ctx, a, n)
 	if err != nil {
@@ -44,7 +38,7 @@ func pushdownFilters(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 
 	filters := newFilterSet(filtersByTable, exprAliases, tableAliases)
 
-	n, err = convertFiltersToIndexedAccess(a, n, filters, indexes)
+	n, err = convertFiltersToIndexedAccess(a, n, filters, indexes, newCostEstimator(ctx, a, n), queryHints(n))
 	if err != nil {
 		return nil, err
 	}
@@ -57,19 +51,24 @@ func pushdownProjections(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 	span, ctx := ctx.Span("pushdown_projections")
 	defer span.Finish()
 
-	if !canDoPushdown(n, scope, a) {
-		return n, nil
-	}
-	if !canProject(n) {
+	if !canProject(n, scope) {
 		return n, nil
 	}
 
 	return transformPushdownProjections(ctx, a, n)
 }
 
-func canProject(n sql.Node) bool {
-	switch n.(type) {
-	case *plan.Update, *plan.RowUpdateAccumulator, *plan.DeleteFrom:
+// canProject returns whether projections can be pushed down in the node given. Unlike filters, projections can be
+// pushed down in queries with subqueries: the subqueries are analyzed again after pushdown, which fixes the field
+// indexes of any references to columns of the outer scope.
+func canProject(n sql.Node, scope *Scope) bool {
+	if !n.Resolved() || len(scope.Schema()) > 0 {
+		return false
+	}
+
+	switch describedNode(n).(type) {
+	case *plan.InsertInto, *plan.CreateIndex, *plan.CreateTrigger, *plan.Update, *plan.RowUpdateAccumulator, *plan.DeleteFrom,
+		*plan.Truncate, *plan.ChecksumTable:
 		return false
 	}
 	return true
@@ -82,7 +81,7 @@ func canDoPushdown(n sql.Node, scope *Scope, a *Analyzer) bool {
 	}
 
 	// don't do pushdown on certain queries
-	switch n.(type) {
+	switch describedNode(n).(type) {
 	case *plan.InsertInto, *plan.CreateIndex, *plan.CreateTrigger:
 		return false
 	}
@@ -92,9 +91,8 @@ func canDoPushdown(n sql.Node, scope *Scope, a *Analyzer) bool {
 		return false
 	}
 
-	// Pushdown of projections interferes with subqueries on the same table: the table gets two different sets of
-	// projected columns pushed down, once for its alias in the subquery and once for its alias outside. For that reason,
-	// skip pushdown for any query with a subquery in it.
+	// Filter pushdown doesn't handle queries with subqueries yet, so skip it for any query with a subquery in it.
+	// Projections are pushed down separately, see canProject.
 	// TODO: fix this
 	containsSubquery := false
 	plan.InspectExpressions(n, func(e sql.Expression) bool {
@@ -124,10 +122,18 @@ func transformPushdownFilters(a *Analyzer, n sql.Node, filters *filterSet, exprA
 				return childNum == 0
 			}
 			return true
+		case *plan.HashJoin:
+			if n.JoinType() == plan.JoinTypeLeft || n.JoinType() == plan.JoinTypeRight {
+				return childNum == 0
+			}
+			return true
 		case *plan.LeftJoin:
 			return childNum == 0
 		case *plan.RightJoin:
 			return childNum == 1
+		case *plan.PointLookup:
+			// Point lookups already evaluate their filter on the one row they look up
+			return false
 		}
 		return true
 	}
@@ -170,22 +176,39 @@ func transformPushdownFilters(a *Analyzer, n sql.Node, filters *filterSet, exprA
 	return node, nil
 }
 
-// convertFiltersToIndexedAccess attempts to replace filter predicates with indexed accesses where possible
-func convertFiltersToIndexedAccess(a *Analyzer, n sql.Node, filters *filterSet, indexes indexLookupsByTable) (sql.Node, error) {
+// convertFiltersToIndexedAccess attempts to replace filter predicates with indexed accesses where possible, and where
+// they're estimated to cost less than scanning the tables or the INDEX hints given force using them
+func convertFiltersToIndexedAccess(
+	a *Analyzer,
+	n sql.Node,
+	filters *filterSet,
+	indexes indexLookupsByTable,
+	costs *costEstimator,
+	hints sql.Hints,
+) (sql.Node, error) {
 	childSelector := func(parent sql.Node, child sql.Node, childNum int) bool {
-		switch parent.(type) {
+		switch parent := parent.(type) {
 		// For IndexedJoins, we already are using indexed access during query execution for the secondary table, so
 		// replacing the secondary table with an indexed lookup will have no effect on the result of the join, but *will*
 		// inappropriately remove the filter from the predicate.
 		// TODO: the analyzer should combine these indexed lookups better
 		case *plan.IndexedJoin:
 			return childNum == 0
+		// The secondary side of a HashJoin is read once, so it can use indexes, but not for left and right ones
+		case *plan.HashJoin:
+			if parent.JoinType() == plan.JoinTypeLeft || parent.JoinType() == plan.JoinTypeRight {
+				return childNum == 0
+			}
+			return true
 		// Left and right joins can push down indexes for the primary table, but not the secondary. See comment
 		// on transformPushdownFilters
 		case *plan.LeftJoin:
 			return childNum == 0
 		case *plan.RightJoin:
 			return childNum == 1
+		case *plan.PointLookup:
+			// Point lookups already evaluate their filter on the one row they look up
+			return false
 		}
 		return true
 	}
@@ -195,13 +218,13 @@ func convertFiltersToIndexedAccess(a *Analyzer, n sql.Node, filters *filterSet,
 		// TODO: some indexes, once pushed down, can be safely removed from the filter. But not all of them, as currently
 		//  implemented -- some indexes return more values than strictly match.
 		case *plan.TableAlias:
-			table, err := pushdownIndexesToTable(a, node, filters, indexes)
+			table, err := pushdownIndexesToTable(a, node, filters, indexes, costs, hints)
 			if err != nil {
 				return nil, err
 			}
 			return FixFieldIndexesForExpressions(table)
 		case *plan.ResolvedTable:
-			table, err := pushdownIndexesToTable(a, node, filters, indexes)
+			table, err := pushdownIndexesToTable(a, node, filters, indexes, costs, hints)
 			if err != nil {
 				return nil, err
 			}
@@ -239,20 +262,28 @@ func pushdownFiltersToTable(
 
 	var newTableNode sql.Node = tableNode
 
-	// First push remaining filters onto the table itself if it's a sql.FilteredTable
+	// First push remaining filters onto the table itself if it's a sql.FilteredTable. If the table reports its
+	// capabilities, it's only offered the filters it can evaluate.
 	if ft, ok := table.(sql.FilteredTable); ok && len(filters.availableFiltersForTable(tableNode.Name())) > 0 {
 		tableFilters := filters.availableFiltersForTable(tableNode.Name())
-		handled := ft.HandledFilters(normalizeExpressions(exprAliases, tableAliases, tableFilters...))
-		filters.markFiltersHandled(handled...)
-		schema := table.Schema()
-
-		handled, err := FixFieldIndexesOnExpressions(schema, handled...)
-		if err != nil {
-			return nil, err
+		offered := normalizeExpressions(exprAliases, tableAliases, tableFilters...)
+		if fct, ok := ft.(sql.FilterCapableTable); ok {
+			offered = supportedFilters(fct.FilterCapabilities(), offered)
 		}
 
-		table = ft.WithFilters(handled)
-		newTableNode = plan.NewDecoratedNode(fmt.Sprintf("Filtered table access on %v", handled), newTableNode)
+		handled := ft.HandledFilters(offered)
+		if len(handled) > 0 {
+			filters.markFiltersHandled(handled...)
+			schema := table.Schema()
+
+			handled, err := FixFieldIndexesOnExpressions(schema, handled...)
+			if err != nil {
+				return nil, err
+			}
+
+			table = ft.WithFilters(handled)
+			newTableNode = plan.NewDecoratedNode(fmt.Sprintf("Filtered table access on %v", handled), newTableNode)
+		}
 
 		a.Log(
 			"table %q transformed with pushdown of filters, %d filters handled of %d",
@@ -300,13 +331,22 @@ func pushdownFiltersToTable(
 	}
 }
 
+// indexedTableAccessDecoration starts the decoration of the tables whose rows are looked up in indexes for filters.
+const indexedTableAccessDecoration = "Indexed table access on "
+
+// multiRangeReadDecoration ends the decoration of the tables whose rows are fetched with a multi-range read.
+const multiRangeReadDecoration = ", with multi-range read"
+
 // pushdownIndexesToTable attempts to convert filter predicates to indexes on tables that implement
-// sql.IndexAddressableTable
+// sql.IndexAddressableTable, unless scanning the table is estimated to cost less and its hints given don't force
+// using its indexes
 func pushdownIndexesToTable(
 	a *Analyzer,
 	tableNode NameableNode,
 	filters *filterSet,
 	indexes map[string]*indexLookup,
+	costs *costEstimator,
+	hints sql.Hints,
 ) (sql.Node, error) {
 
 	table := getTable(tableNode)
@@ -319,18 +359,32 @@ func pushdownIndexesToTable(
 	replacedTable := false
 	if it, ok := table.(sql.IndexAddressableTable); ok {
 		indexLookup, ok := indexes[tableNode.Name()]
+		if hint, forced := hints.ForTable(strings.ToLower(tableNode.Name()), sql.IndexHint, sql.NoIndexHint); ok && !(forced && hint.Name == sql.IndexHint) {
+			lookupCost, estimated := costs.filterLookupCost(tableNode, filters.availableFiltersForTable(tableNode.Name()), indexLookup.indexes)
+			if scanCost := costs.scanCost(tableNode); estimated && lookupCost > scanCost {
+				a.Log("table %q not transformed with pushdown of index, scan cost %v is less than lookup cost %v", tableNode.Name(), scanCost, lookupCost)
+				ok = false
+			}
+		}
 		if ok {
-			table = it.WithIndexLookup(indexLookup.lookup)
 			indexStrs := formatIndexDecoratorString(indexLookup)
 
 			indexNoun := "index"
 			if len(indexStrs) > 1 {
 				indexNoun = "indexes"
 			}
-			newTableNode = plan.NewDecoratedNode(
-				fmt.Sprintf("Indexed table access on %s %s", indexNoun, strings.Join(indexStrs, ", ")),
-				newTableNode)
-			a.Log("table %q transformed with pushdown of index", tableNode.Name())
+			decoration := fmt.Sprintf("%s%s %s", indexedTableAccessDecoration, indexNoun, strings.Join(indexStrs, ", "))
+
+			// The rows of range and union lookups are fetched in the order they're stored in, by tables that can
+			if mt, ok := it.(sql.MultiRangeReadTable); ok && indexLookup.multiRange {
+				table = mt.WithMultiRangeRead(indexLookup.lookup)
+				decoration += multiRangeReadDecoration
+				a.Log("table %q transformed with pushdown of index, with multi-range read", tableNode.Name())
+			} else {
+				table = it.WithIndexLookup(indexLookup.lookup)
+				a.Log("table %q transformed with pushdown of index", tableNode.Name())
+			}
+			newTableNode = plan.NewDecoratedNode(decoration, newTableNode)
 
 			replacedTable = true
 		}
@@ -365,6 +419,16 @@ func formatIndexDecoratorString(indexLookup *indexLookup) []string {
 	return indexStrs
 }
 
+// projectedTableAccessDecoration starts the decoration of the tables projections were pushed down to.
+const projectedTableAccessDecoration = "Projected table access on "
+
+// isProjectedTableAccess returns whether the node given is the decoration of a table projections were pushed down to,
+// which subqueries keep when they're analyzed again.
+func isProjectedTableAccess(n sql.Node) bool {
+	d, ok := n.(*plan.DecoratedNode)
+	return ok && strings.HasPrefix(d.Decoration(), projectedTableAccessDecoration)
+}
+
 // pushdownProjectionsToTable attempts to push projected columns down to tables that implement sql.ProjectedTable.
 func pushdownProjectionsToTable(
 	a *Analyzer,
@@ -389,7 +453,7 @@ func pushdownProjectionsToTable(
 		}
 
 		newTableNode = plan.NewDecoratedNode(
-			fmt.Sprintf("Projected table access on %v",
+			fmt.Sprintf("%s%v", projectedTableAccessDecoration,
 				fieldsByTable[tableNode.Name()]), newTableNode)
 		a.Log("table %q transformed with pushdown of projection", tableNode.Name())
 
@@ -401,7 +465,7 @@ func pushdownProjectionsToTable(
 	}
 
 	switch tableNode.(type) {
-	case *plan.ResolvedTable, *plan.TableAlias:
+	case *plan.ResolvedTable, *plan.TableAlias, *plan.IndexedTableAccess:
 		node, err := withTable(newTableNode, table)
 		if err != nil {
 			return nil, err
@@ -417,7 +481,11 @@ func transformPushdownProjections(ctx *sql.Context, a *Analyzer, n sql.Node) (sq
 	usedFieldsByTable := make(fieldsByTable)
 	fieldsByTable := getFieldsByTable(ctx, n)
 
-	node, err := plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+	node, err := plan.TransformUpWithParent(n, func(node sql.Node, parent sql.Node, childNum int) (sql.Node, error) {
+		if isProjectedTableAccess(parent) {
+			return node, nil
+		}
+
 		switch node := node.(type) {
 		case *plan.TableAlias:
 			table, err := pushdownProjectionsToTable(a, node, fieldsByTable, usedFieldsByTable)
@@ -426,6 +494,18 @@ func transformPushdownProjections(ctx *sql.Context, a *Analyzer, n sql.Node) (sq
 			}
 			return FixFieldIndexesForExpressions(table)
 		case *plan.ResolvedTable:
+			// An aliased table is projected through its alias, with the fields referenced by the alias name, and the
+			// table of a point lookup is looked up by its index with all its columns
+			switch parent.(type) {
+			case *plan.TableAlias, *plan.PointLookup:
+				return node, nil
+			}
+			table, err := pushdownProjectionsToTable(a, node, fieldsByTable, usedFieldsByTable)
+			if err != nil {
+				return nil, err
+			}
+			return FixFieldIndexesForExpressions(table)
+		case *plan.IndexedTableAccess:
 			table, err := pushdownProjectionsToTable(a, node, fieldsByTable, usedFieldsByTable)
 			if err != nil {
 				return nil, err
@@ -444,14 +524,21 @@ func transformPushdownProjections(ctx *sql.Context, a *Analyzer, n sql.Node) (sq
 }
 
 // removePushedDownPredicates removes all handled filter predicates from the filter given and returns. If all
-// predicates have been handled, it replaces the filter with its child.
+// predicates have been handled, it replaces the filter with its child. Residual predicates, which couldn't be pushed
+// down to a single table, are kept in the filter.
 func removePushedDownPredicates(a *Analyzer, node *plan.Filter, filters *filterSet) (sql.Node, error) {
 	if filters.handledCount() == 0 {
 		a.Log("no handled filters, leaving filter untouched")
 		return node, nil
 	}
 
-	unhandled := filters.availableFilters()
+	predicates := splitConjunction(node.Expression)
+	unhandled := filters.unhandledFilters(predicates)
+	if len(unhandled) == len(predicates) {
+		a.Log("no handled filters in filter node, leaving it untouched")
+		return node, nil
+	}
+
 	if len(unhandled) == 0 {
 		a.Log("filter node has no unhandled filters, so it will be removed")
 		return node.Child, nil
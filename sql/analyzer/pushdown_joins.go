@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// ErrInvalidPushedDownJoin is returned when the table returned by a database for a join doesn't have the schema of
+// the join.
+var ErrInvalidPushedDownJoin = errors.NewKind("join pushed down to database %q returned a table with schema %s, expected %s")
+
+// pushdownJoins replaces joins between tables with the table returned by a sql.JoinPushdownDatabase that can execute
+// them. Every database in the catalog that can execute joins is offered the largest joins first, so a database that
+// claims a join gets the whole subtree of it.
+func pushdownJoins(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, ctx := ctx.Span("pushdown_joins")
+	defer span.Finish()
+
+	if !n.Resolved() || !hasPushableJoin(n) {
+		return n, nil
+	}
+
+	var dbs []sql.JoinPushdownDatabase
+	for _, db := range a.Catalog.AllDatabases() {
+		if jdb, ok := db.(sql.JoinPushdownDatabase); ok {
+			dbs = append(dbs, jdb)
+		}
+	}
+
+	if len(dbs) == 0 {
+		return n, nil
+	}
+
+	return pushdownJoinsInNode(ctx, a, dbs, n)
+}
+
+// hasPushableJoin returns whether the node given has any join that could be pushed down, so queries without joins don't
+// need every database of the catalog to be resolved.
+func hasPushableJoin(n sql.Node) bool {
+	found := false
+	plan.Inspect(n, func(n sql.Node) bool {
+		if isJoin(n) && isPushableJoin(logicalJoins(n)) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func pushdownJoinsInNode(ctx *sql.Context, a *Analyzer, dbs []sql.JoinPushdownDatabase, n sql.Node) (sql.Node, error) {
+	// Databases are offered the joins HashJoins compute, since how joins are computed is up to them
+	if isJoin(n) {
+		if join := logicalJoins(n); isPushableJoin(join) {
+			for _, db := range dbs {
+				table, ok, err := db.PushdownJoin(ctx, join)
+				if err != nil {
+					return nil, err
+				}
+
+				if !ok {
+					continue
+				}
+
+				if !sameColumns(table.Schema(), n.Schema()) {
+					return nil, ErrInvalidPushedDownJoin.New(db.Name(), schemaString(table.Schema()), schemaString(n.Schema()))
+				}
+
+				a.Log("join pushed down to database %q", db.Name())
+				return plan.NewDecoratedNode(
+					fmt.Sprintf("Join pushed down to database %s", db.Name()),
+					plan.NewResolvedTable(table),
+				), nil
+			}
+		}
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		return n, nil
+	}
+
+	var changed bool
+	newChildren := make([]sql.Node, len(children))
+	for i, child := range children {
+		newChild, err := pushdownJoinsInNode(ctx, a, dbs, child)
+		if err != nil {
+			return nil, err
+		}
+
+		if newChild != child {
+			changed = true
+		}
+		newChildren[i] = newChild
+	}
+
+	if !changed {
+		return n, nil
+	}
+
+	return n.WithChildren(newChildren...)
+}
+
+func isJoin(n sql.Node) bool {
+	switch n.(type) {
+	case *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.CrossJoin, *plan.HashJoin:
+		return true
+	default:
+		return false
+	}
+}
+
+// logicalJoins returns the node given with its HashJoins replaced by the joins they compute, which databases can
+// execute natively. The primary side of a HashJoin always comes first, so right ones are replaced with left joins.
+func logicalJoins(n sql.Node) sql.Node {
+	node, _ := plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		j, ok := n.(*plan.HashJoin)
+		if !ok {
+			return n, nil
+		}
+		switch j.JoinType() {
+		case plan.JoinTypeInner:
+			return plan.NewInnerJoin(j.Left, j.Right, j.Cond), nil
+		case plan.JoinTypeSemi:
+			return plan.NewSemiJoin(j.Left, j.Right, j.Cond), nil
+		case plan.JoinTypeAnti:
+			return plan.NewAntiJoin(j.Left, j.Right, j.Cond), nil
+		}
+		return plan.NewLeftJoin(j.Left, j.Right, j.Cond), nil
+	})
+	return node
+}
+
+// isPushableJoin returns whether the join given is only made of nodes that a database could execute natively.
+func isPushableJoin(join sql.Node) bool {
+	ok := true
+	plan.Inspect(join, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case nil, *plan.ResolvedTable, *plan.TableAlias, *plan.DecoratedNode, *plan.CrossJoin:
+		case *plan.InnerJoin, *plan.LeftJoin, *plan.RightJoin, *plan.Filter, *plan.Project:
+			if hasSubqueries(n.(sql.Expressioner)) {
+				ok = false
+			}
+		default:
+			ok = false
+		}
+		return ok
+	})
+	return ok
+}
+
+func hasSubqueries(n sql.Expressioner) bool {
+	var found bool
+	for _, e := range n.Expressions() {
+		sql.Inspect(e, func(e sql.Expression) bool {
+			if _, ok := e.(*plan.Subquery); ok {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// sameColumns returns whether both schemas have columns with the same names and sources, in the same order.
+func sameColumns(s1, s2 sql.Schema) bool {
+	if len(s1) != len(s2) {
+		return false
+	}
+
+	for i := range s1 {
+		if !strings.EqualFold(s1[i].Name, s2[i].Name) || !strings.EqualFold(s1[i].Source, s2[i].Source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func schemaString(s sql.Schema) string {
+	cols := make([]string, len(s))
+	for i, c := range s {
+		cols[i] = c.Source + "." + c.Name
+	}
+	return "[" + strings.Join(cols, ", ") + "]"
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// joinPushdownDatabase is a database that claims all the joins between its own tables, returning an empty table with
+// the schema of the join.
+type joinPushdownDatabase struct {
+	*memory.Database
+	// schema, if set, is the schema of the tables returned instead of the schema of the join
+	schema sql.Schema
+}
+
+var _ sql.JoinPushdownDatabase = (*joinPushdownDatabase)(nil)
+
+func (db *joinPushdownDatabase) PushdownJoin(ctx *sql.Context, join sql.Node) (sql.Table, bool, error) {
+	ok := true
+	plan.Inspect(join, func(n sql.Node) bool {
+		if rt, isTable := n.(*plan.ResolvedTable); isTable {
+			if t, found := db.Tables()[rt.Name()]; !found || t != rt.Table {
+				ok = false
+			}
+		}
+		return ok
+	})
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	schema := join.Schema()
+	if db.schema != nil {
+		schema = db.schema
+	}
+
+	return memory.NewTable("pushed_join", schema), true, nil
+}
+
+func TestPushdownJoins(t *testing.T) {
+	t1 := memory.NewTable("t1", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t1"},
+	})
+	t2 := memory.NewTable("t2", sql.Schema{
+		{Name: "b", Type: sql.Int64, Source: "t2"},
+	})
+	t3 := memory.NewTable("t3", sql.Schema{
+		{Name: "c", Type: sql.Int64, Source: "t3"},
+	})
+
+	remote := &joinPushdownDatabase{Database: memory.NewDatabase("remote")}
+	remote.AddTable("t1", t1)
+	remote.AddTable("t2", t2)
+
+	local := memory.NewDatabase("local")
+	local.AddTable("t3", t3)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(remote)
+	catalog.AddDatabase(local)
+	a := NewDefault(catalog)
+
+	a1 := expression.NewGetFieldWithTable(0, sql.Int64, "t1", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.Int64, "t2", "b", false)
+	c := expression.NewGetFieldWithTable(2, sql.Int64, "t3", "c", false)
+
+	joined := plan.NewInnerJoin(
+		plan.NewResolvedTable(t1),
+		plan.NewResolvedTable(t2),
+		expression.NewEquals(a1, b),
+	)
+	filteredJoin := plan.NewLeftJoin(
+		plan.NewTableAlias("x", plan.NewResolvedTable(t1)),
+		plan.NewFilter(
+			expression.NewGreaterThan(b, expression.NewLiteral(int64(1), sql.Int64)),
+			plan.NewResolvedTable(t2),
+		),
+		expression.NewEquals(a1, b),
+	)
+	pushedJoin := func(join sql.Node) sql.Node {
+		return plan.NewDecoratedNode(
+			"Join pushed down to database remote",
+			plan.NewResolvedTable(memory.NewTable("pushed_join", join.Schema())),
+		)
+	}
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "join between tables of the same database is pushed down",
+			node: plan.NewProject(
+				[]sql.Expression{a1},
+				joined,
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{a1},
+				pushedJoin(joined),
+			),
+		},
+		{
+			name:     "join with a filter and an alias is pushed down",
+			node:     filteredJoin,
+			expected: pushedJoin(filteredJoin),
+		},
+		{
+			name: "only the part of a join between tables of the same database is pushed down",
+			node: plan.NewCrossJoin(
+				joined,
+				plan.NewResolvedTable(t3),
+			),
+			expected: plan.NewCrossJoin(
+				pushedJoin(joined),
+				plan.NewResolvedTable(t3),
+			),
+		},
+		{
+			name: "hash join is pushed down as the join it computes",
+			node: plan.NewHashJoin(
+				plan.NewResolvedTable(t2),
+				plan.NewResolvedTable(t1),
+				plan.JoinTypeRight,
+				expression.NewEquals(a1, b),
+				[]sql.Expression{b},
+				[]sql.Expression{a1},
+			),
+			expected: pushedJoin(plan.NewLeftJoin(
+				plan.NewResolvedTable(t2),
+				plan.NewResolvedTable(t1),
+				expression.NewEquals(a1, b),
+			)),
+		},
+		{
+			name: "join with a subquery is not pushed down",
+			node: plan.NewInnerJoin(
+				plan.NewResolvedTable(t1),
+				plan.NewResolvedTable(t2),
+				expression.NewEquals(
+					a1,
+					plan.NewSubquery(plan.NewProject([]sql.Expression{c}, plan.NewResolvedTable(t3)), "select c from t3"),
+				),
+			),
+		},
+		{
+			name: "table that isn't joined is left alone",
+			node: plan.NewProject(
+				[]sql.Expression{a1},
+				plan.NewResolvedTable(t1),
+			),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_joins"))
+}
+
+func TestPushdownJoinsInvalidSchema(t *testing.T) {
+	t1 := memory.NewTable("t1", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t1"},
+	})
+	t2 := memory.NewTable("t2", sql.Schema{
+		{Name: "b", Type: sql.Int64, Source: "t2"},
+	})
+
+	remote := &joinPushdownDatabase{
+		Database: memory.NewDatabase("remote"),
+		schema:   sql.Schema{{Name: "a", Type: sql.Int64, Source: "t1"}},
+	}
+	remote.AddTable("t1", t1)
+	remote.AddTable("t2", t2)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(remote)
+	a := NewDefault(catalog)
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "table returned for a join must have the schema of the join",
+			node: plan.NewCrossJoin(
+				plan.NewResolvedTable(t1),
+				plan.NewResolvedTable(t2),
+			),
+			err: ErrInvalidPushedDownJoin,
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_joins"))
+}
@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// pushdownSortAndLimit pushes sorts down to tables that implement sql.OrderedTable and can return their rows in that
+// order, removing the sort, and limits down to tables that implement sql.LimitedTable. Limits are only pushed down
+// when there's nothing between them and the table that could discard rows or change their order, and the limit node
+// is kept, since tables are allowed to return more rows than their limit.
+func pushdownSortAndLimit(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, ctx := ctx.Span("pushdown_sort_and_limit")
+	defer span.Finish()
+
+	if !n.Resolved() || len(scope.Schema()) > 0 {
+		return n, nil
+	}
+
+	// Data modification statements need to find the table they modify among their children, so leave them alone.
+	switch describedNode(n).(type) {
+	case *plan.InsertInto, *plan.Update, *plan.DeleteFrom, *plan.CreateIndex, *plan.CreateTrigger:
+		return n, nil
+	}
+
+	return plan.TransformUp(n, func(node sql.Node) (sql.Node, error) {
+		switch node := node.(type) {
+		case *plan.Sort:
+			// Tables don't need to keep the order of equal rows, the sort node does
+			if a.DeterministicOrder {
+				return node, nil
+			}
+			return pushdownSortToTable(a, mergeNullOrderingFields(node))
+		case *plan.Limit:
+			return pushdownLimitToTable(a, node)
+		default:
+			return node, nil
+		}
+	})
+}
+
+// pushdownSortToTable returns the child of the sort given with its order pushed down to its table, or the sort itself
+// if it can't be pushed down.
+func pushdownSortToTable(a *Analyzer, sort *plan.Sort) (sql.Node, error) {
+	// Filters and projections don't change the order of their rows
+	rt, name := findOrderableTable(sort.Child, true)
+	if rt == nil {
+		return sort, nil
+	}
+
+	ot, ok := rt.Table.(sql.OrderedTable)
+	if !ok {
+		return sort, nil
+	}
+
+	order, ok := sortFieldsToSortColumns(name, sort.SortFields)
+	if !ok || !ot.CanOrder(order) {
+		return sort, nil
+	}
+
+	a.Log("sort pushed down to table %q", name)
+
+	var orderStrs []string
+	for _, o := range order {
+		orderStrs = append(orderStrs, o.String())
+	}
+
+	return replaceResolvedTable(sort.Child, plan.NewDecoratedNode(
+		fmt.Sprintf("Ordered table access on [%s]", strings.Join(orderStrs, ", ")),
+		plan.NewResolvedTableInDatabase(ot.WithOrder(order), rt.Database),
+	))
+}
+
+// pushdownLimitToTable returns the limit given with its limit pushed down to its table, if possible. An offset below
+// the limit is added to the limit pushed down. Limits that calculate the found rows read all the rows of their table,
+// so they aren't pushed down.
+func pushdownLimitToTable(a *Analyzer, limit *plan.Limit) (sql.Node, error) {
+	if limit.CalcFoundRows {
+		return limit, nil
+	}
+
+	n := limit.Limit
+	child := limit.Child
+	offset, hasOffset := child.(*plan.Offset)
+	if hasOffset {
+		n += offset.Offset
+		child = offset.Child
+	}
+
+	// Unlike sorts, limits can't be pushed down below filters, which discard rows
+	rt, name := findOrderableTable(child, false)
+	if rt == nil {
+		return limit, nil
+	}
+
+	lt, ok := rt.Table.(sql.LimitedTable)
+	if !ok {
+		return limit, nil
+	}
+
+	a.Log("limit pushed down to table %q", name)
+
+	child, err := replaceResolvedTable(child, plan.NewDecoratedNode(
+		fmt.Sprintf("Limited table access on %d", n),
+		plan.NewResolvedTableInDatabase(lt.WithLimit(n), rt.Database),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	if hasOffset {
+		child, err = offset.WithChildren(child)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return limit.WithChildren(child)
+}
+
+// findOrderableTable returns the table at the bottom of the node given, and the name it's referenced by, if the rows
+// of the node are the rows of the table in the same order. If filters is true, filters are allowed between the node
+// and the table. Returns nil if there's no such table.
+func findOrderableTable(node sql.Node, filters bool) (*plan.ResolvedTable, string) {
+	var alias string
+	for {
+		switch n := node.(type) {
+		case *plan.ResolvedTable:
+			if alias != "" {
+				return n, alias
+			}
+			return n, n.Name()
+		case *plan.TableAlias:
+			alias = n.Name()
+			node = n.Child
+		case *plan.Filter:
+			if !filters {
+				return nil, ""
+			}
+			node = n.Child
+		case *plan.Project:
+			node = n.Child
+		case *plan.DecoratedNode:
+			node = n.Child
+		default:
+			return nil, ""
+		}
+	}
+}
+
+// sortFieldsToSortColumns returns the order of the columns of the table with the name given that the sort fields
+// given describe, and whether all of them are columns of that table sorted in an order tables can report.
+func sortFieldsToSortColumns(table string, sortFields []plan.SortField) ([]sql.SortColumn, bool) {
+	order := make([]sql.SortColumn, len(sortFields))
+	for i, sf := range sortFields {
+		gf, ok := sf.Column.(*expression.GetField)
+		if !ok || !strings.EqualFold(gf.Table(), table) {
+			return nil, false
+		}
+
+		// The null ordering of columns without null values doesn't matter
+		order[i] = sql.SortColumn{
+			Column:     gf.Name(),
+			Descending: sf.Order == plan.Descending,
+			NullsLast:  sf.NullOrdering == plan.NullsLast && gf.IsNullable(),
+		}
+	}
+
+	return order, true
+}
+
+// mergeNullOrderingFields returns the sort given with the fields that sort by whether a column is null followed by the
+// column merged into a single field with the same order, as in the ORDER BY col IS NULL, col idiom to sort nulls last.
+// A single field can be pushed down to the table.
+func mergeNullOrderingFields(sort *plan.Sort) *plan.Sort {
+	var fields []plan.SortField
+	var merged bool
+	for i := 0; i < len(sort.SortFields); i++ {
+		f := sort.SortFields[i]
+		if i+1 == len(sort.SortFields) {
+			fields = append(fields, f)
+			break
+		}
+
+		// IS NOT NULL is the negation of IS NULL, so it sorts nulls the other way
+		nullsBefore := f.Order == plan.Descending
+		e := f.Column
+		if not, ok := e.(*expression.Not); ok {
+			e = not.Child
+			nullsBefore = !nullsBefore
+		}
+
+		isNull, ok := e.(*expression.IsNull)
+		next := sort.SortFields[i+1]
+		if !ok || !reflect.DeepEqual(isNull.Child, next.Column) {
+			fields = append(fields, f)
+			continue
+		}
+
+		// Nulls sorted last come last in ascending order and first in descending order
+		nullOrdering := plan.NullsFirst
+		if nullsBefore == (next.Order == plan.Descending) {
+			nullOrdering = plan.NullsLast
+		}
+
+		fields = append(fields, plan.SortField{Column: next.Column, Order: next.Order, NullOrdering: nullOrdering})
+		merged = true
+		i++
+	}
+
+	if !merged {
+		return sort
+	}
+	return plan.NewSort(fields, sort.Child)
+}
+
+// replaceResolvedTable replaces the only ResolvedTable in the node given with the node given.
+func replaceResolvedTable(node sql.Node, table sql.Node) (sql.Node, error) {
+	return plan.TransformUp(node, func(n sql.Node) (sql.Node, error) {
+		if _, ok := n.(*plan.ResolvedTable); ok {
+			return table, nil
+		}
+		return n, nil
+	})
+}
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestPushdownSortAndLimit(t *testing.T) {
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int32, Source: "mytable"},
+		{Name: "t", Type: sql.Text, Source: "mytable"},
+	}
+	table := memory.NewPushdownTable("mytable", schema)
+	plainTable := memory.NewTable("mytable", schema)
+
+	db := memory.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := NewDefault(catalog)
+
+	i := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)
+	tf := expression.NewGetFieldWithTable(1, sql.Text, "mytable", "t", false)
+	nullableI := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true)
+	filter := expression.NewGreaterThan(i, expression.NewLiteral(1, sql.Int32))
+
+	order := []sql.SortColumn{{Column: "i", Descending: true}, {Column: "t"}}
+	ordered := table.WithOrder(order)
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "sort over a filter and a projection is pushed down",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: i, Order: plan.Descending, NullOrdering: plan.NullsFirst},
+					{Column: tf, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewProject(
+					[]sql.Expression{i, tf},
+					plan.NewFilter(filter, plan.NewResolvedTable(table)),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{i, tf},
+				plan.NewFilter(
+					filter,
+					plan.NewDecoratedNode("Ordered table access on [i DESC, t ASC]", plan.NewResolvedTable(ordered)),
+				),
+			),
+		},
+		{
+			name: "sort through an alias is pushed down",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewGetFieldWithTable(0, sql.Int32, "a", "i", false), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewTableAlias("a", plan.NewResolvedTable(table)),
+			),
+			expected: plan.NewTableAlias(
+				"a",
+				plan.NewDecoratedNode(
+					"Ordered table access on [i ASC]",
+					plan.NewResolvedTable(table.WithOrder([]sql.SortColumn{{Column: "i"}})),
+				),
+			),
+		},
+		{
+			name: "sort on an expression is not pushed down",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewPlus(i, expression.NewLiteral(1, sql.Int32)), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewPlus(i, expression.NewLiteral(1, sql.Int32)), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(table),
+			),
+		},
+		{
+			name: "sort with nulls last of a column without nulls is pushed down",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: i, Order: plan.Ascending, NullOrdering: plan.NullsLast},
+				},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewDecoratedNode(
+				"Ordered table access on [i ASC]",
+				plan.NewResolvedTable(table.WithOrder([]sql.SortColumn{{Column: "i"}})),
+			),
+		},
+		{
+			name: "sort with nulls last is pushed down with its null ordering",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: nullableI, Order: plan.Descending, NullOrdering: plan.NullsLast},
+				},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewDecoratedNode(
+				"Ordered table access on [i DESC NULLS FIRST]",
+				plan.NewResolvedTable(table.WithOrder([]sql.SortColumn{{Column: "i", Descending: true, NullsLast: true}})),
+			),
+		},
+		{
+			name: "sort by whether a column is null and the column is pushed down",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewIsNull(nullableI), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+					{Column: nullableI, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+					{Column: tf, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(table),
+			),
+			expected: plan.NewDecoratedNode(
+				"Ordered table access on [i ASC NULLS LAST, t ASC]",
+				plan.NewResolvedTable(table.WithOrder([]sql.SortColumn{{Column: "i", NullsLast: true}, {Column: "t"}})),
+			),
+		},
+		{
+			name: "sort by whether a column is not null and the column is merged",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewNot(expression.NewIsNull(nullableI)), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+					{Column: nullableI, Order: plan.Descending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+			expected: plan.NewSort(
+				[]plan.SortField{
+					{Column: nullableI, Order: plan.Descending, NullOrdering: plan.NullsLast},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+		},
+		{
+			name: "sort by whether another column is null is kept",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewIsNull(tf), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+					{Column: nullableI, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+			expected: plan.NewSort(
+				[]plan.SortField{
+					{Column: expression.NewIsNull(tf), Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+					{Column: nullableI, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+		},
+		{
+			name: "sort over a table that can't be ordered is kept",
+			node: plan.NewSort(
+				[]plan.SortField{
+					{Column: i, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+			expected: plan.NewSort(
+				[]plan.SortField{
+					{Column: i, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+				},
+				plan.NewResolvedTable(plainTable),
+			),
+		},
+		{
+			name: "sort and limit are pushed down, keeping the limit and offset",
+			node: plan.NewLimit(
+				2,
+				plan.NewOffset(
+					3,
+					plan.NewSort(
+						[]plan.SortField{
+							{Column: i, Order: plan.Descending, NullOrdering: plan.NullsFirst},
+							{Column: tf, Order: plan.Ascending, NullOrdering: plan.NullsFirst},
+						},
+						plan.NewResolvedTable(table),
+					),
+				),
+			),
+			expected: plan.NewLimit(
+				2,
+				plan.NewOffset(
+					3,
+					plan.NewDecoratedNode(
+						"Ordered table access on [i DESC, t ASC]",
+						plan.NewDecoratedNode(
+							"Limited table access on 5",
+							plan.NewResolvedTable(ordered.(*memory.PushdownTable).WithLimit(5)),
+						),
+					),
+				),
+			),
+		},
+		{
+			name: "limit over a filter is not pushed down",
+			node: plan.NewLimit(
+				2,
+				plan.NewFilter(filter, plan.NewResolvedTable(table)),
+			),
+			expected: plan.NewLimit(
+				2,
+				plan.NewFilter(filter, plan.NewResolvedTable(table)),
+			),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_sort_and_limit"))
+}
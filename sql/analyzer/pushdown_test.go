@@ -265,6 +265,88 @@ func TestPushdownFilterToTables(t *testing.T) {
 	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_filters"))
 }
 
+func TestPushdownFilterToTablesWithCapabilities(t *testing.T) {
+	table := memory.NewPushdownTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int32, Source: "mytable"},
+		{Name: "f", Type: sql.Float64, Source: "mytable"},
+		{Name: "t", Type: sql.Text, Source: "mytable"},
+	})
+	table.SetFilterCapabilities(sql.FilterCapabilities{
+		Columns:         []string{"i", "t"},
+		ComparisonsOnly: true,
+	})
+
+	db := memory.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.AddDatabase(db)
+	a := NewDefault(catalog)
+
+	i := expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", false)
+	f := expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", false)
+	tf := expression.NewGetFieldWithTable(2, sql.Text, "mytable", "t", false)
+
+	tests := []analyzerFnTestCase{
+		{
+			name: "only supported filters are pushed down to the table",
+			node: plan.NewProject(
+				[]sql.Expression{tf},
+				plan.NewFilter(
+					and(
+						expression.NewGreaterThan(i, expression.NewLiteral(1, sql.Int32)),
+						and(
+							expression.NewEquals(f, expression.NewLiteral(3.14, sql.Float64)),
+							and(
+								expression.NewEquals(
+									expression.NewPlus(i, expression.NewLiteral(1, sql.Int32)),
+									expression.NewLiteral(3, sql.Int32),
+								),
+								expression.NewOr(
+									expression.NewIsNull(tf),
+									expression.NewInTuple(tf, expression.NewTuple(
+										expression.NewLiteral("a", sql.LongText),
+										expression.NewLiteral("b", sql.LongText),
+									)),
+								),
+							),
+						),
+					),
+					plan.NewResolvedTable(table),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{tf},
+				plan.NewFilter(
+					and(
+						expression.NewEquals(f, expression.NewLiteral(3.14, sql.Float64)),
+						expression.NewEquals(
+							expression.NewPlus(i, expression.NewLiteral(1, sql.Int32)),
+							expression.NewLiteral(3, sql.Int32),
+						),
+					),
+					plan.NewDecoratedNode("Filtered table access on [mytable.i > 1 mytable.t IS NULL OR mytable.t IN (\"a\", \"b\")]",
+						plan.NewResolvedTable(
+							table.WithFilters([]sql.Expression{
+								expression.NewGreaterThan(i, expression.NewLiteral(1, sql.Int32)),
+								expression.NewOr(
+									expression.NewIsNull(tf),
+									expression.NewInTuple(tf, expression.NewTuple(
+										expression.NewLiteral("a", sql.LongText),
+										expression.NewLiteral("b", sql.LongText),
+									)),
+								),
+							}),
+						),
+					),
+				),
+			),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), tests, a, getRule("pushdown_filters"))
+}
+
 func TestPushdownFiltersAboveTables(t *testing.T) {
 	table := memory.NewTable("mytable", sql.Schema{
 		{Name: "i", Type: sql.Int32, Source: "mytable"},
@@ -500,8 +582,7 @@ func TestPushdownFiltersAboveTables(t *testing.T) {
 			),
 		},
 		{
-			// TODO: we could push down only the non-join predicates, but we currently just pass entirely
-			name: "filter contains join condition (no pushdown currently possible, but see TODO)",
+			name: "filter contains join condition, which is kept as a residual predicate",
 			node: plan.NewProject(
 				[]sql.Expression{
 					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
@@ -514,11 +595,11 @@ func TestPushdownFiltersAboveTables(t *testing.T) {
 						),
 						and(
 							expression.NewEquals(
-								expression.NewGetFieldWithTable(2, sql.Int32, "mytable", "i", true),
-								expression.NewGetFieldWithTable(5, sql.Int32, "mytable2", "i2", true),
+								expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
+								expression.NewGetFieldWithTable(3, sql.Int32, "mytable2", "i2", true),
 							),
 							expression.NewEquals(
-								expression.NewGetFieldWithTable(5, sql.Int32, "mytable2", "i2", true),
+								expression.NewGetFieldWithTable(3, sql.Int32, "mytable2", "i2", true),
 								expression.NewLiteral(20, sql.Int32),
 							),
 						),
@@ -529,6 +610,33 @@ func TestPushdownFiltersAboveTables(t *testing.T) {
 					),
 				),
 			),
+			expected: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
+				},
+				plan.NewFilter(
+					expression.NewEquals(
+						expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
+						expression.NewGetFieldWithTable(3, sql.Int32, "mytable2", "i2", true),
+					),
+					plan.NewCrossJoin(
+						plan.NewFilter(
+							expression.NewEquals(
+								expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", true),
+								expression.NewLiteral(3.14, sql.Float64),
+							),
+							plan.NewResolvedTable(table),
+						),
+						plan.NewFilter(
+							expression.NewEquals(
+								expression.NewGetFieldWithTable(0, sql.Int32, "mytable2", "i2", true),
+								expression.NewLiteral(20, sql.Int32),
+							),
+							plan.NewResolvedTable(table2),
+						),
+					),
+				),
+			),
 		},
 	}
 
@@ -615,6 +723,39 @@ func TestPushdownIndex(t *testing.T) {
 				),
 			),
 		},
+		{
+			name: "range index with multi-range read",
+			node: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
+				},
+				plan.NewFilter(
+					expression.NewGreaterThan(
+						expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", true),
+						expression.NewLiteral(3.14, sql.Float64),
+					),
+					plan.NewResolvedTable(table),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetFieldWithTable(0, sql.Int32, "mytable", "i", true),
+				},
+				plan.NewDecoratedNode("Indexed table access on index [mytable.f], with multi-range read",
+					plan.NewFilter(
+						expression.NewGreaterThan(
+							expression.NewGetFieldWithTable(1, sql.Float64, "mytable", "f", true),
+							expression.NewLiteral(3.14, sql.Float64),
+						),
+						plan.NewResolvedTable(
+							table.WithMultiRangeRead(
+								mustIndexLookup(idxTable1F.(sql.DescendIndex).DescendGreater(3.14)),
+							),
+						),
+					),
+				),
+			),
+		},
 		{
 			name: "single index with extra predicate",
 			node: plan.NewProject(
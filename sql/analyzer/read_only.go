@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// checkReadOnly rejects the statements that modify databases, their tables, indexes, views or triggers while the
+// engine is in read_only or super_read_only mode, before any of them executes. Blocks are rejected if any of their
+// statements modifies a database. Explained statements don't execute, so they're left as they are.
+func checkReadOnly(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil {
+		return n, nil
+	}
+	if _, ok := n.(*plan.DescribeQuery); ok {
+		return n, nil
+	}
+
+	var err error
+	plan.Inspect(n, func(node sql.Node) bool {
+		if err != nil {
+			return false
+		}
+		if isWriteNode(node) {
+			err = a.Catalog.CheckWritable(ctx)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// isWriteNode returns whether the node given is a statement that modifies a database.
+func isWriteNode(n sql.Node) bool {
+	switch n.(type) {
+	case *plan.InsertInto, *plan.Update, *plan.DeleteFrom, *plan.Truncate,
+		*plan.CreateTable, *plan.DropTable, *plan.RenameTable,
+		*plan.AddColumn, *plan.DropColumn, *plan.RenameColumn, *plan.ModifyColumn,
+		*plan.CreateIndex, *plan.DropIndex, *plan.AlterIndex,
+		*plan.CreateForeignKey, *plan.DropForeignKey,
+		*plan.CreateCheck, *plan.DropCheck, *plan.DropConstraint,
+		*plan.CreateView, *plan.DropView,
+		*plan.CreateTrigger, *plan.DropTrigger,
+		*plan.OptimizeTable:
+		return true
+	default:
+		return false
+	}
+}
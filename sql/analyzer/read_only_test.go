@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	f := getRule("check_read_only")
+
+	catalog := sql.NewCatalog()
+	catalog.SetReadOnlyAuthorizer(func(ctx *sql.Context) bool {
+		return ctx.Client().User == "root"
+	})
+	a := NewDefault(catalog)
+
+	insert := plan.NewInsertInto(
+		plan.NewUnresolvedTable("mytable", ""),
+		plan.NewValues([][]sql.Expression{{expression.NewLiteral(int64(1), sql.Int64)}}),
+		false,
+		nil,
+		nil,
+	)
+	read := plan.NewProject(
+		[]sql.Expression{expression.NewStar()},
+		plan.NewUnresolvedTable("mytable", ""),
+	)
+
+	testCases := []struct {
+		name          string
+		readOnly      int
+		superReadOnly int
+		user          string
+		node          sql.Node
+		err           bool
+	}{
+		{"write", 0, 0, "alice", insert, false},
+		{"read only write", 1, 0, "alice", insert, true},
+		{"read only read", 1, 0, "alice", read, false},
+		{"read only explain", 1, 0, "alice", plan.NewDescribeQuery("tree", insert), false},
+		{"read only block", 1, 0, "alice", plan.NewBeginEndBlock([]sql.Node{read, insert}), true},
+		{"read only privileged write", 1, 0, "root", insert, false},
+		{"super read only write", 0, 1, "alice", insert, true},
+		{"super read only privileged write", 0, 1, "root", insert, true},
+		{"super read only read", 0, 1, "root", read, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			require.NoError(catalog.GlobalVariables.Set(sql.ReadOnlyVar, tt.readOnly))
+			require.NoError(catalog.GlobalVariables.Set(sql.SuperReadOnlyVar, tt.superReadOnly))
+
+			ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("", "", tt.user, 1)))
+			result, err := f.Apply(ctx, a, tt.node, nil)
+			if tt.err {
+				require.True(sql.ErrReadOnly.Is(err))
+			} else {
+				require.NoError(err)
+				require.Equal(tt.node, result)
+			}
+		})
+	}
+}
@@ -38,6 +38,11 @@ func checkAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 
 		aliases := lookForAliasDeclarations(p)
 		for alias := range aliases {
+			// A name that is both an alias and a column of the child refers to the column.
+			if hasColumnNamed(availableColumns(p.Child), alias) {
+				continue
+			}
+
 			if isAliasUsed(p, alias) {
 				err = sql.ErrMisusedAlias.New(alias)
 			}
@@ -96,6 +101,30 @@ func isAliasUsed(node sql.Expressioner, alias string) bool {
 	return found
 }
 
+// availableColumns returns the columns of the node given, or the ones of its children that are already resolved if it
+// isn't resolved yet.
+func availableColumns(n sql.Node) sql.Schema {
+	if n.Resolved() {
+		return n.Schema()
+	}
+
+	var schema sql.Schema
+	for _, child := range n.Children() {
+		schema = append(schema, availableColumns(child)...)
+	}
+	return schema
+}
+
+// hasColumnNamed returns whether the schema given has a column with the name given, from any table.
+func hasColumnNamed(schema sql.Schema, name string) bool {
+	for _, col := range schema {
+		if strings.EqualFold(col.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // deferredColumn is a wrapper on UnresolvedColumn used to defer the resolution of the column because it may require
 // some work done by other analyzer phases.
 type deferredColumn struct {
@@ -264,6 +293,10 @@ func getNodeAvailableNames(n sql.Node, scope *Scope) availableNames {
 					names.indexTable(alias, name, i)
 				}
 				return false
+			case *plan.HandlerRead:
+				name := strings.ToLower(n.Name)
+				names.indexTable(name, name, i)
+				return false
 			}
 
 			return true
@@ -391,7 +424,7 @@ func getColumnsInNodes(nodes []sql.Node, names availableNames, nestingLevel int)
 
 	for _, node := range nodes {
 		switch n := node.(type) {
-		case *plan.TableAlias, *plan.ResolvedTable, *plan.SubqueryAlias:
+		case *plan.TableAlias, *plan.ResolvedTable, *plan.SubqueryAlias, *plan.HandlerRead:
 			for _, col := range n.Schema() {
 				names.indexColumn(col.Source, col.Name, nestingLevel)
 			}
@@ -455,6 +488,13 @@ func resolveColumns(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sq
 		}
 
 		columns := indexColumns(ctx, a, n, scope)
+
+		// Columns are only resolved by names of the same case if names are case sensitive
+		var caseInsensitiveTables map[string]bool
+		if a != nil && a.Catalog != nil && a.Catalog.CaseSensitiveNames() {
+			caseInsensitiveTables = informationSchemaTables(n)
+		}
+
 		return plan.TransformExpressions(n, func(e sql.Expression) (sql.Expression, error) {
 			uc, ok := e.(column)
 			if !ok || e.Resolved() {
@@ -467,11 +507,31 @@ func resolveColumns(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sq
 				return resolveUserVariable(ctx, a, uc)
 			}
 
-			return resolveColumnExpression(ctx, a, uc, columns)
+			return resolveColumnExpression(ctx, a, uc, columns, caseInsensitiveTables)
 		})
 	})
 }
 
+// informationSchemaTables returns the lower case names and aliases of the tables of information_schema in the node
+// given, whose columns are case insensitive even if names are case sensitive.
+func informationSchemaTables(n sql.Node) map[string]bool {
+	tables := make(map[string]bool)
+	plan.Inspect(n, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.TableAlias:
+			if rt, ok := n.Child.(*plan.ResolvedTable); ok && strings.EqualFold(rt.Database, sql.InformationSchemaDatabaseName) {
+				tables[strings.ToLower(n.Name())] = true
+			}
+		case *plan.ResolvedTable:
+			if strings.EqualFold(n.Database, sql.InformationSchemaDatabaseName) {
+				tables[strings.ToLower(n.Name())] = true
+			}
+		}
+		return true
+	})
+	return tables
+}
+
 // indexColumns returns a map of column identifiers to their index in the node's schema. Columns from outer scopes are
 // included as well, with lower indexes (prepended to node schema) but lower precedence (overwritten by inner nodes in
 // map)
@@ -572,7 +632,7 @@ func resolveSystemVariable(ctx *sql.Context, a *Analyzer, col column) (sql.Expre
 	typ, _ := ctx.Get(name)
 
 	a.Log("resolved column %s to system variable (type %s)", col, typ)
-	return expression.NewSystemVar(name, typ), nil
+	return newSystemVar(col.Name(), name, typ), nil
 }
 
 func trimVarName(name string) string {
@@ -582,6 +642,16 @@ func trimVarName(name string) string {
 	return name
 }
 
+// newSystemVar returns the expression for the system variable referenced with the name given, as in @@global.x or
+// @@x, which is the global value of the variable if the name has the global prefix, and the value of the session
+// otherwise. The varName is the name of the variable without prefixes.
+func newSystemVar(name, varName string, typ sql.Type) *expression.SystemVar {
+	if strings.HasPrefix(strings.TrimLeft(strings.ToLower(name), "@"), globalPrefix) {
+		return expression.NewGlobalSystemVar(varName, typ)
+	}
+	return expression.NewSystemVar(varName, typ)
+}
+
 func resolveUserVariable(ctx *sql.Context, a *Analyzer, col column) (sql.Expression, error) {
 	// user vars can have . in them, and just get treated as a unified string name
 	colStr := col.String()
@@ -592,10 +662,15 @@ func resolveUserVariable(ctx *sql.Context, a *Analyzer, col column) (sql.Express
 	return expression.NewUserVar(name), nil
 }
 
-func resolveColumnExpression(ctx *sql.Context, a *Analyzer, e column, columns map[tableCol]indexedCol) (sql.Expression, error) {
+// resolveColumnExpression resolves the column given to the column of the node it names. If caseInsensitiveTables isn't
+// nil, names are case sensitive, and only the columns of the tables in it are resolved by names of a different case.
+func resolveColumnExpression(ctx *sql.Context, a *Analyzer, e column, columns map[tableCol]indexedCol, caseInsensitiveTables map[string]bool) (sql.Expression, error) {
 	name := strings.ToLower(e.Name())
 	table := strings.ToLower(e.Table())
 	col, ok := columns[tableCol{table, name}]
+	if ok && caseInsensitiveTables != nil && col.Name != e.Name() && !caseInsensitiveTables[strings.ToLower(col.Source)] {
+		ok = false
+	}
 	if !ok {
 		switch uc := e.(type) {
 		case *expression.UnresolvedColumn:
@@ -630,6 +705,10 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 		return n, nil
 	}
 
+	// Aggregations in a HAVING or an ORDER BY are computed by the group by
+	// below them too, so their columns must be projected as well.
+	outerColumns := findOuterAggregationColumns(n)
+
 	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		g, ok := n.(*plan.GroupBy)
 		if n.Resolved() || !ok || len(g.GroupByExprs) == 0 {
@@ -638,15 +717,24 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 
 		// The reason we have two sets of columns, one for grouping and
 		// one for aggregate is because an alias can redefine a column name
-		// of the child schema. In the grouping, if that column is referenced
-		// it refers to the alias, and not the one in the child. However,
-		// in the aggregate, aliases in that same aggregate cannot be used,
-		// so it refers to the column in the child node.
+		// of the child schema. In the grouping, as in MySQL, that name refers
+		// to the column in the child, so only the names that aren't columns
+		// of the child refer to aliases. In the aggregate, aliases in that
+		// same aggregate cannot be used, so it refers to the column in the
+		// child node.
 		var groupingColumns = make(map[string]struct{})
+		var aliasColumns = make(map[string]struct{})
+		childSchema := availableColumns(g.Child)
 		for _, g := range g.GroupByExprs {
-			for _, n := range findAllColumns(g) {
-				groupingColumns[strings.ToLower(n)] = struct{}{}
-			}
+			sql.Inspect(g, func(e sql.Expression) bool {
+				if col, ok := e.(*expression.UnresolvedColumn); ok {
+					groupingColumns[strings.ToLower(col.Name())] = struct{}{}
+					if col.Table() == "" && !hasColumnNamed(childSchema, col.Name()) {
+						aliasColumns[strings.ToLower(col.Name())] = struct{}{}
+					}
+				}
+				return true
+			})
 		}
 
 		var aggregateColumns = make(map[string]struct{})
@@ -654,7 +742,7 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 			// This alias is going to be pushed down, so don't bother gathering
 			// its requirements.
 			if alias, ok := agg.(*expression.Alias); ok {
-				if _, ok := groupingColumns[strings.ToLower(alias.Name())]; ok {
+				if _, ok := aliasColumns[strings.ToLower(alias.Name())]; ok {
 					continue
 				}
 			}
@@ -663,6 +751,11 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 				aggregateColumns[strings.ToLower(n)] = struct{}{}
 			}
 		}
+		for _, n := range outerColumns {
+			if hasColumnNamed(childSchema, n) {
+				aggregateColumns[strings.ToLower(n)] = struct{}{}
+			}
+		}
 
 		var newAggregate []sql.Expression
 		var projection []sql.Expression
@@ -684,7 +777,7 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 			// Only if the alias is required in the grouping set needsReorder
 			// to true. If it's not required, there's no need for a reorder if
 			// no other alias is required.
-			_, ok = groupingColumns[name]
+			_, ok = aliasColumns[name]
 			if ok {
 				aliases[name] = len(newAggregate)
 				needsReorder = true
@@ -768,6 +861,48 @@ func pushdownGroupByAliases(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 	})
 }
 
+// findOuterAggregationColumns returns the names of the columns in the aggregations of the HAVING and ORDER BY nodes
+// of the node given.
+func findOuterAggregationColumns(n sql.Node) []string {
+	var exprs []sql.Expression
+	plan.Inspect(n, func(n sql.Node) bool {
+		switch n := n.(type) {
+		case *plan.Having:
+			exprs = append(exprs, n.Cond)
+		case *plan.Sort:
+			for _, f := range n.SortFields {
+				exprs = append(exprs, f.Column)
+			}
+		}
+		return true
+	})
+
+	var cols []string
+	for _, e := range exprs {
+		sql.Inspect(e, func(e sql.Expression) bool {
+			// Functions may not be resolved yet
+			switch e := e.(type) {
+			case *expression.UnresolvedFunction:
+				if !e.IsAggregate {
+					return true
+				}
+			case sql.Aggregation:
+			default:
+				return true
+			}
+
+			sql.Inspect(e, func(e sql.Expression) bool {
+				if col, ok := e.(column); ok {
+					cols = append(cols, col.Name())
+				}
+				return true
+			})
+			return false
+		})
+	}
+	return cols
+}
+
 func findAllColumns(e sql.Expression) []string {
 	var cols []string
 	sql.Inspect(e, func(e sql.Expression) bool {
@@ -73,6 +73,18 @@ func TestMisusedAlias(t *testing.T) {
 
 	_, err := f.Apply(sql.NewEmptyContext(), nil, node, nil)
 	require.EqualError(err, sql.ErrMisusedAlias.New("alias_i").Error())
+
+	// An alias with the name of a column doesn't hide the column in its projection.
+	node = plan.NewProject(
+		[]sql.Expression{
+			expression.NewAlias("i", expression.NewLiteral(int64(1), sql.Int64)),
+			uc("i"),
+		},
+		plan.NewResolvedTable(table),
+	)
+
+	_, err = f.Apply(sql.NewEmptyContext(), nil, node, nil)
+	require.NoError(err)
 }
 
 func TestQualifyVariables(t *testing.T) {
@@ -684,3 +696,77 @@ func TestPushdownGroupByAliases(t *testing.T) {
 
 	require.Equal(expected, result)
 }
+
+func TestPushdownGroupByAliasesColumnPrecedence(t *testing.T) {
+	require := require.New(t)
+
+	table := plan.NewResolvedTable(memory.NewTable("table", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "table"},
+		{Name: "b", Type: sql.Int64, Source: "table"},
+	}))
+
+	// The grouping refers to the column a, not to the alias with its name.
+	node := plan.NewGroupBy(
+		[]sql.Expression{
+			expression.NewAlias("a", uc("b")),
+		},
+		[]sql.Expression{
+			uc("a"),
+		},
+		table,
+	)
+
+	result, err := pushdownGroupByAliases(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(node, result)
+}
+
+func TestPushdownGroupByAliasesHavingColumns(t *testing.T) {
+	require := require.New(t)
+
+	table := plan.NewResolvedTable(memory.NewTable("table", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "table"},
+		{Name: "c", Type: sql.Int64, Source: "table"},
+	}))
+	cond := expression.NewGreaterThan(
+		expression.NewUnresolvedFunction("sum", true, uc("c")),
+		expression.NewLiteral(int64(1), sql.Int64),
+	)
+
+	node := plan.NewHaving(
+		cond,
+		plan.NewGroupBy(
+			[]sql.Expression{
+				expression.NewAlias("x", uc("a")),
+			},
+			[]sql.Expression{
+				uc("x"),
+			},
+			table,
+		),
+	)
+
+	// The columns of the aggregations in the HAVING are projected with the aliases.
+	expected := plan.NewHaving(
+		cond,
+		plan.NewGroupBy(
+			[]sql.Expression{
+				uc("x"),
+			},
+			[]sql.Expression{
+				uc("x"),
+			},
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewAlias("x", uc("a")),
+					uc("c"),
+				},
+				table,
+			),
+		),
+	)
+
+	result, err := pushdownGroupByAliases(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(expected, result)
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// resolveCommonTableExpressions inlines the common table expressions of the WITH clauses of the node given into the
+// tables that refer to them, as resolve_views does with views: every table named like a common table expression of
+// a WITH clause it's in, without a database, is replaced with the subquery of the common table expression, which is
+// analyzed on its own like any other. A common table expression can refer to those before it in its WITH clause, and
+// shadows the tables, views and common table expressions of outer WITH clauses with its name.
+func resolveCommonTableExpressions(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("resolve_ctes")
+	defer span.Finish()
+
+	if !hasWith(n) {
+		return n, nil
+	}
+	return inlineCommonTableExpressions(a, n, nil)
+}
+
+// hasWith returns whether the node given, or any of its subqueries, has a WITH clause.
+func hasWith(n sql.Node) bool {
+	found := false
+	plan.Inspect(n, func(n sql.Node) bool {
+		if _, ok := n.(*plan.With); ok {
+			found = true
+		}
+		return !found
+	})
+	if found {
+		return true
+	}
+
+	plan.InspectExpressions(n, func(e sql.Expression) bool {
+		if sq, ok := e.(*plan.Subquery); ok && hasWith(sq.Query) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// inlineCommonTableExpressions returns the node given with the tables that refer to the common table expressions
+// given, keyed by their lower case names, and to those of the WITH clauses in the node, replaced with their subqueries.
+// Subqueries and derived tables are walked too, since they can refer to the common table expressions of the
+// statements they're in.
+func inlineCommonTableExpressions(a *Analyzer, n sql.Node, ctes map[string]*plan.SubqueryAlias) (sql.Node, error) {
+	switch n := n.(type) {
+	case *plan.With:
+		scoped := make(map[string]*plan.SubqueryAlias, len(ctes)+len(n.CTEs))
+		for name, cte := range ctes {
+			scoped[name] = cte
+		}
+
+		own := make(map[string]bool, len(n.CTEs))
+		for _, cte := range n.CTEs {
+			name := strings.ToLower(cte.Subquery.Name())
+			if own[name] {
+				return nil, sql.ErrDuplicateAliasOrTable.New(cte.Subquery.Name())
+			}
+			own[name] = true
+
+			// A common table expression can't refer to itself, so its name only shadows others after its subquery
+			subquery, err := inlineCommonTableExpressions(a, cte.Subquery, scoped)
+			if err != nil {
+				return nil, err
+			}
+			scoped[name] = subquery.(*plan.SubqueryAlias).WithColumns(cte.Columns)
+		}
+
+		return inlineCommonTableExpressions(a, n.Child, scoped)
+	case *plan.UnresolvedTable:
+		if n.Database != "" || n.AsOf != nil {
+			return n, nil
+		}
+		if cte, ok := ctes[strings.ToLower(n.Name())]; ok {
+			a.Log("common table expression resolved: %q", n.Name())
+			return cte, nil
+		}
+		return n, nil
+	}
+
+	n, err := plan.TransformExpressions(n, func(e sql.Expression) (sql.Expression, error) {
+		sq, ok := e.(*plan.Subquery)
+		if !ok {
+			return e, nil
+		}
+		query, err := inlineCommonTableExpressions(a, sq.Query, ctes)
+		if err != nil {
+			return nil, err
+		}
+		return sq.WithQuery(query), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		return n, nil
+	}
+	newChildren := make([]sql.Node, len(children))
+	for i, child := range children {
+		newChildren[i], err = inlineCommonTableExpressions(a, child, ctes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n.WithChildren(newChildren...)
+}
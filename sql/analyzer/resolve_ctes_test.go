@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestResolveCommonTableExpressions(t *testing.T) {
+	require := require.New(t)
+	f := getRule("resolve_ctes")
+
+	selectFrom := func(table sql.Node) sql.Node {
+		return plan.NewProject([]sql.Expression{expression.NewStar()}, table)
+	}
+	a := plan.NewSubqueryAlias("a", "SELECT * FROM t", selectFrom(plan.NewUnresolvedTable("t", "")))
+	// b refers to a, named in another case, and the subquery expression of the statement refers to b
+	b := plan.NewSubqueryAlias("b", "SELECT * FROM a", selectFrom(plan.NewUnresolvedTable("A", "")))
+	subquery := plan.NewSubquery(selectFrom(plan.NewUnresolvedTable("b", "")), "SELECT * FROM b")
+
+	node := plan.NewWith(
+		plan.NewFilter(
+			expression.NewEquals(expression.NewUnresolvedColumn("x"), subquery),
+			plan.NewCrossJoin(plan.NewTableAlias("c", plan.NewUnresolvedTable("b", "")), plan.NewUnresolvedTable("a", "db")),
+		),
+		[]*plan.CommonTableExpression{
+			plan.NewCommonTableExpression(a, []string{"x"}),
+			plan.NewCommonTableExpression(b, nil),
+		},
+	)
+
+	inlinedA := a.WithColumns([]string{"x"})
+	inlinedB, err := b.WithChildren(selectFrom(inlinedA))
+	require.NoError(err)
+	expected := plan.NewFilter(
+		expression.NewEquals(expression.NewUnresolvedColumn("x"), subquery.WithQuery(selectFrom(inlinedB))),
+		plan.NewCrossJoin(plan.NewTableAlias("c", inlinedB), plan.NewUnresolvedTable("a", "db")),
+	)
+
+	result, err := f.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	// Nodes without WITH clauses are left as they are
+	result, err = f.Apply(sql.NewEmptyContext(), NewDefault(nil), expected, nil)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	_, err = f.Apply(sql.NewEmptyContext(), NewDefault(nil), plan.NewWith(selectFrom(plan.NewUnresolvedTable("a", "")), []*plan.CommonTableExpression{
+		plan.NewCommonTableExpression(a, nil),
+		plan.NewCommonTableExpression(plan.NewSubqueryAlias("A", "SELECT * FROM t", a.Child), nil),
+	}), nil)
+	require.True(sql.ErrDuplicateAliasOrTable.Is(err))
+}
@@ -28,7 +28,7 @@ func resolveDatabase(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (s
 			}
 		}
 
-		db, err := a.Catalog.Database(dbName)
+		db, err := a.Catalog.SessionDatabase(ctx, dbName)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,39 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// resolveHandlers resolves the tables read by HANDLER READ to the tables their handlers opened in the session.
+func resolveHandlers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	span, _ := ctx.Span("resolve_handlers")
+	defer span.Finish()
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		hr, ok := n.(*plan.HandlerRead)
+		if !ok || hr.Table() != nil {
+			return n, nil
+		}
+
+		handler := ctx.GetHandler(strings.ToLower(hr.Name))
+		if handler == nil {
+			return nil, plan.ErrUnknownHandler.New(hr.Name)
+		}
+
+		table, err := resolveTable(ctx, a, plan.NewUnresolvedTable(handler.Table, handler.Database))
+		if err != nil {
+			return nil, err
+		}
+
+		rt, ok := table.(*plan.ResolvedTable)
+		if !ok {
+			return nil, plan.ErrUnknownHandler.New(hr.Name)
+		}
+
+		a.Log("handler resolved: %q", hr.Name)
+		return hr.WithTable(rt), nil
+	})
+}
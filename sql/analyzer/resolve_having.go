@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
@@ -55,6 +56,91 @@ func resolveHaving(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (
 	})
 }
 
+// resolveHavingGroupingColumns makes the names in a HAVING that are both aliases in the select and columns of the
+// grouping refer to the grouping columns, as they do in MySQL. To do so, those columns are added to the aggregate of
+// the group by with a unique name, which the HAVING refers to instead.
+func resolveHavingGroupingColumns(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (sql.Node, error) {
+	return plan.TransformUp(node, func(node sql.Node) (sql.Node, error) {
+		having, ok := node.(*plan.Having)
+		if !ok || having.Resolved() {
+			return node, nil
+		}
+
+		groupBy, ok := having.Child.(*plan.GroupBy)
+		if !ok || !groupBy.Resolved() {
+			return node, nil
+		}
+
+		var names []string
+		var aliases = make(map[string]sql.Expression)
+		for _, e := range groupBy.SelectedExprs {
+			names = append(names, strings.ToLower(e.String()))
+			if alias, ok := e.(*expression.Alias); ok {
+				aliases[strings.ToLower(alias.Name())] = alias.Child
+				names = append(names, strings.ToLower(alias.Name()))
+			}
+		}
+
+		var groupingColumns = make(map[string]*expression.GetField)
+		for _, e := range groupBy.GroupByExprs {
+			if f, ok := e.(*expression.GetField); ok {
+				groupingColumns[strings.ToLower(f.Name())] = f
+			}
+		}
+
+		var selected = groupBy.SelectedExprs
+		var renames = make(map[string]string)
+		cond, err := expression.TransformUp(having.Cond, func(e sql.Expression) (sql.Expression, error) {
+			col, ok := e.(*expression.UnresolvedColumn)
+			if !ok || col.Table() != "" {
+				return e, nil
+			}
+
+			name := strings.ToLower(col.Name())
+			aliased, ok := aliases[name]
+			if !ok {
+				return e, nil
+			}
+			grouping, ok := groupingColumns[name]
+			if !ok {
+				return e, nil
+			}
+			// An alias of the grouping column itself refers to the same values.
+			if f, ok := aliased.(*expression.GetField); ok && f.Index() == grouping.Index() {
+				return e, nil
+			}
+
+			to, ok := renames[name]
+			if !ok {
+				for i := 1; ; i++ {
+					to = fmt.Sprintf("%s_%02d", name, i)
+					if !stringContains(names, to) {
+						break
+					}
+				}
+				renames[name] = to
+				names = append(names, to)
+				selected = append(selected, expression.NewAlias(to, grouping))
+			}
+
+			return expression.NewUnresolvedColumn(to), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(renames) == 0 {
+			return node, nil
+		}
+
+		a.Log("renamed grouping columns %v referenced in HAVING", renames)
+		return projectOriginalAggregation(
+			plan.NewHaving(cond, plan.NewGroupBy(selected, groupBy.GroupByExprs, groupBy.Child)),
+			having.Schema(),
+		), nil
+	})
+}
+
 func findMissingColumns(node sql.Node, expr sql.Expression) []string {
 	var schemaCols []string
 	for _, col := range node.Schema() {
@@ -72,7 +158,7 @@ func findMissingColumns(node sql.Node, expr sql.Expression) []string {
 	return missingCols
 }
 
-func projectOriginalAggregation(having *plan.Having, schema sql.Schema) *plan.Project {
+func projectOriginalAggregation(node sql.Node, schema sql.Schema) *plan.Project {
 	var projection []sql.Expression
 	for i, col := range schema {
 		projection = append(
@@ -81,14 +167,17 @@ func projectOriginalAggregation(having *plan.Having, schema sql.Schema) *plan.Pr
 		)
 	}
 
-	return plan.NewProject(projection, having)
+	return plan.NewProject(projection, node)
 }
 
 var errHavingChildMissingRef = errors.NewKind("cannot find column %s referenced in HAVING clause in either GROUP BY or its child")
 
 func pullMissingColumnsUp(having *plan.Having, missingCols []string) (*plan.Having, error) {
 	groupBy, err := findGroupBy(having)
-	if err != nil {
+	if errHavingNeedsGroupBy.Is(err) {
+		// Without a group by, only the columns of the select can be referenced.
+		return nil, errHavingChildMissingRef.New(missingCols[0])
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -245,7 +334,7 @@ func pushColumnsUp(node sql.Node, columns []int) (sql.Node, []int, error) {
 		if err != nil {
 			return nil, nil, err
 		}
-		return plan.NewLimit(node.Limit, child), columns, nil
+		return plan.NewLimit(node.Limit, child).WithCalcFoundRows(node.CalcFoundRows), columns, nil
 	case *plan.Offset:
 		child, columns, err := pushColumnsUp(node.Child, columns)
 		if err != nil {
@@ -314,12 +403,19 @@ func replaceAggregations(having *plan.Having) (*plan.Having, bool, error) {
 			}
 		}
 
-		newAggregate = append(newAggregate, agg)
+		// The aggregation is evaluated by the group by, so its columns are the
+		// ones of the group by child.
+		resolved, err := resolveAggregationColumns(agg, groupBy.Child.Schema())
+		if err != nil {
+			return nil, err
+		}
+
+		newAggregate = append(newAggregate, resolved)
 		return expression.NewGetField(
 			len(having.Child.Schema())+len(newAggregate)-1,
-			agg.Type(),
-			agg.String(),
-			agg.IsNullable(),
+			resolved.Type(),
+			resolved.String(),
+			resolved.IsNullable(),
 		), nil
 	})
 	if err != nil {
@@ -369,6 +465,27 @@ func replaceAggregations(having *plan.Having) (*plan.Having, bool, error) {
 	return plan.NewHaving(cond, having.Child), requiresProjection, nil
 }
 
+// resolveAggregationColumns returns the aggregation given with its columns resolved in the schema given, both the
+// unresolved ones and the ones resolved in another schema.
+func resolveAggregationColumns(agg sql.Expression, schema sql.Schema) (sql.Expression, error) {
+	return expression.TransformUp(agg, func(e sql.Expression) (sql.Expression, error) {
+		col, ok := e.(column)
+		if !ok {
+			return e, nil
+		}
+		if _, ok := e.(*expression.GetField); !ok && e.Resolved() {
+			return e, nil
+		}
+
+		for i, c := range schema {
+			if strings.EqualFold(c.Name, col.Name()) && (col.Table() == "" || strings.EqualFold(c.Source, col.Table())) {
+				return expression.NewGetFieldWithTable(i, c.Type, c.Source, c.Name, c.Nullable), nil
+			}
+		}
+		return nil, sql.ErrColumnNotFound.New(col.Name())
+	})
+}
+
 func aggregationEquals(a, b sql.Expression) bool {
 	// First unwrap aliases
 	if alias, ok := b.(*expression.Alias); ok {
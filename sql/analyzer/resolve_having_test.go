@@ -89,6 +89,47 @@ func TestResolveHaving(t *testing.T) {
 				),
 			),
 		},
+		{
+			name: "push down aggregation of a column missing from group by",
+			input: plan.NewHaving(
+				expression.NewGreaterThan(
+					aggregation.NewMax(expression.NewUnresolvedColumn("bar")),
+					expression.NewLiteral(int64(5), sql.Int64),
+				),
+				plan.NewGroupBy(
+					[]sql.Expression{
+						expression.NewGetFieldWithTable(0, sql.Int64, "t", "foo", false),
+					},
+					[]sql.Expression{expression.NewGetField(0, sql.Int64, "foo", false)},
+					plan.NewResolvedTable(memory.NewTable("t", sql.Schema{
+						{Name: "foo", Type: sql.Int64, Source: "t"},
+						{Name: "bar", Type: sql.Int64, Source: "t"},
+					})),
+				),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{
+					expression.NewGetFieldWithTable(0, sql.Int64, "t", "foo", false),
+				},
+				plan.NewHaving(
+					expression.NewGreaterThan(
+						expression.NewGetField(1, sql.Int64, "MAX(t.bar)", false),
+						expression.NewLiteral(int64(5), sql.Int64),
+					),
+					plan.NewGroupBy(
+						[]sql.Expression{
+							expression.NewGetFieldWithTable(0, sql.Int64, "t", "foo", false),
+							aggregation.NewMax(expression.NewGetFieldWithTable(1, sql.Int64, "t", "bar", false)),
+						},
+						[]sql.Expression{expression.NewGetField(0, sql.Int64, "foo", false)},
+						plan.NewResolvedTable(memory.NewTable("t", sql.Schema{
+							{Name: "foo", Type: sql.Int64, Source: "t"},
+							{Name: "bar", Type: sql.Int64, Source: "t"},
+						})),
+					),
+				),
+			),
+		},
 		// TODO: this should be an error in most cases -- the having clause must only reference columns in the select clause.
 		{
 			name: "pull up missing column",
@@ -304,3 +345,55 @@ func TestResolveHaving(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveHavingGroupingColumns(t *testing.T) {
+	require := require.New(t)
+
+	table := plan.NewResolvedTable(memory.NewTable("t", sql.Schema{
+		{Name: "foo", Type: sql.Int64, Source: "t"},
+	}))
+	count := expression.NewAlias("foo", aggregation.NewCount(expression.NewStar()))
+	foo := expression.NewGetFieldWithTable(0, sql.Int64, "t", "foo", false)
+
+	node := plan.NewHaving(
+		expression.NewGreaterThan(
+			expression.NewUnresolvedColumn("foo"),
+			expression.NewLiteral(int64(1), sql.Int64),
+		),
+		plan.NewGroupBy([]sql.Expression{count}, []sql.Expression{foo}, table),
+	)
+
+	expected := plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetField(0, sql.Int64, "foo", false),
+		},
+		plan.NewHaving(
+			expression.NewGreaterThan(
+				expression.NewUnresolvedColumn("foo_01"),
+				expression.NewLiteral(int64(1), sql.Int64),
+			),
+			plan.NewGroupBy(
+				[]sql.Expression{count, expression.NewAlias("foo_01", foo)},
+				[]sql.Expression{foo},
+				table,
+			),
+		),
+	)
+
+	result, err := resolveHavingGroupingColumns(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	// An alias of the grouping column itself is left alone.
+	node = plan.NewHaving(
+		expression.NewGreaterThan(
+			expression.NewUnresolvedColumn("foo"),
+			expression.NewLiteral(int64(1), sql.Int64),
+		),
+		plan.NewGroupBy([]sql.Expression{expression.NewAlias("foo", foo)}, []sql.Expression{foo}, table),
+	)
+
+	result, err = resolveHavingGroupingColumns(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(node, result)
+}
@@ -35,7 +35,9 @@ func pushdownSort(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.
 		var colsFromChild []string
 		var missingCols []string
 		for _, f := range sort.SortFields {
-			ns := findExprNameables(f.Column)
+			// The columns of aggregations are the ones of the group by child, which are added with the
+			// aggregations themselves.
+			ns := findExprNameablesOutsideAggregations(f.Column)
 
 			for _, n := range ns {
 				name := strings.ToLower(n.Name())
@@ -182,6 +184,75 @@ func pushSortDown(sort *plan.Sort) (sql.Node, error) {
 	}
 }
 
+// resolveOrderByAggregations adds the aggregations of an ORDER BY that the group by below it doesn't compute to the
+// group by, and sorts by the columns they are in. Those columns are projected away after sorting.
+func resolveOrderByAggregations(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		sort, ok := n.(*plan.Sort)
+		if !ok || !sort.Child.Resolved() {
+			return n, nil
+		}
+
+		schema := sort.Child.Schema()
+		var groupBy *plan.GroupBy
+		var newAggregate []sql.Expression
+		var fields = make([]plan.SortField, len(sort.SortFields))
+		for i, f := range sort.SortFields {
+			col, err := expression.TransformUp(f.Column, func(e sql.Expression) (sql.Expression, error) {
+				agg, ok := e.(sql.Aggregation)
+				if !ok {
+					return e, nil
+				}
+
+				// Aggregations already computed by the child are replaced with their columns
+				for i, c := range schema {
+					if strings.EqualFold(c.Name, agg.String()) {
+						return expression.NewGetFieldWithTable(i, c.Type, c.Source, c.Name, c.Nullable), nil
+					}
+				}
+
+				if groupBy == nil {
+					var err error
+					groupBy, err = findGroupBy(sort)
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				resolved, err := resolveAggregationColumns(agg, groupBy.Child.Schema())
+				if err != nil {
+					return nil, err
+				}
+
+				newAggregate = append(newAggregate, resolved)
+				return expression.NewGetField(
+					len(schema)+len(newAggregate)-1,
+					resolved.Type(),
+					resolved.String(),
+					resolved.IsNullable(),
+				), nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			fields[i] = plan.SortField{Column: col, Order: f.Order, NullOrdering: f.NullOrdering}
+		}
+
+		if len(newAggregate) == 0 {
+			return n, nil
+		}
+
+		a.Log("adding order by aggregations %v to the group by", newAggregate)
+		child, err := addColumnsToGroupBy(sort.Child, newAggregate)
+		if err != nil {
+			return nil, err
+		}
+
+		return projectOriginalAggregation(plan.NewSort(fields, child), schema), nil
+	})
+}
+
 func resolveOrderByLiterals(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		sort, ok := n.(*plan.Sort)
@@ -229,11 +300,9 @@ func resolveOrderByLiterals(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Sc
 
 				a.Log("replaced order by column %d with %v", idx+1, schemaCols[idx])
 			} else {
-				if agg, ok := f.Column.(sql.Aggregation); ok {
+				// Aggregations that aren't in the child are added to it later
+				if agg, ok := f.Column.(sql.Aggregation); ok && hasColumnNamed(schema, agg.String()) {
 					name := agg.String()
-					if nameable, ok := f.Column.(sql.Nameable); ok {
-						name = nameable.Name()
-					}
 
 					fields[i] = plan.SortField{
 						Column:       expression.NewUnresolvedColumn(name),
@@ -262,3 +331,19 @@ func findExprNameables(e sql.Expression) []sql.Nameable {
 	})
 	return result
 }
+
+func findExprNameablesOutsideAggregations(e sql.Expression) []sql.Nameable {
+	var result []sql.Nameable
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if _, ok := e.(sql.Aggregation); ok {
+			return false
+		}
+		n, ok := e.(sql.Nameable)
+		if ok {
+			result = append(result, n)
+			return false
+		}
+		return true
+	})
+	return result
+}
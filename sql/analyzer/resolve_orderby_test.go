@@ -8,6 +8,7 @@ import (
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
 	"github.com/dolthub/go-mysql-server/sql/plan"
 )
 
@@ -259,3 +260,47 @@ func TestResolveOrderByLiterals(t *testing.T) {
 	require.Error(err)
 	require.True(ErrOrderByColumnIndex.Is(err))
 }
+
+func TestResolveOrderByAggregations(t *testing.T) {
+	require := require.New(t)
+	f := getRule("resolve_orderby_aggregations")
+
+	table := plan.NewResolvedTable(memory.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	}))
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "t", "a", false)
+	sum := aggregation.NewSum(expression.NewGetFieldWithTable(1, sql.Int64, "t", "b", false))
+
+	node := plan.NewSort(
+		[]plan.SortField{
+			{Column: aggregation.NewSum(expression.NewUnresolvedColumn("b")), Order: plan.Descending},
+		},
+		plan.NewGroupBy([]sql.Expression{a}, []sql.Expression{a}, table),
+	)
+
+	expected := plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewSort(
+			[]plan.SortField{
+				{Column: expression.NewGetField(1, sum.Type(), sum.String(), sum.IsNullable()), Order: plan.Descending},
+			},
+			plan.NewGroupBy([]sql.Expression{a, sum}, []sql.Expression{a}, table),
+		),
+	)
+
+	result, err := f.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	node = plan.NewSort(
+		[]plan.SortField{
+			{Column: aggregation.NewSum(expression.NewUnresolvedColumn("c"))},
+		},
+		plan.NewGroupBy([]sql.Expression{a}, []sql.Expression{a}, table),
+	)
+
+	_, err = f.Apply(sql.NewEmptyContext(), NewDefault(nil), node, nil)
+	require.Error(err)
+	require.True(sql.ErrColumnNotFound.Is(err))
+}
@@ -13,12 +13,27 @@ func resolveSubqueries(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
 		switch n := n.(type) {
 		case *plan.SubqueryAlias:
+			// Subquery expressions are analyzed again on every pass of the analyzer over the query they're in, and so
+			// are their derived tables, which need no further analysis then
+			if n.Child.Resolved() {
+				return n, nil
+			}
+
 			a.Log("found subquery %q with child of type %T", n.Name(), n.Child)
-			child, err := a.Analyze(ctx, n.Child, scope)
+			// Derived tables can't refer to the columns of outer queries, and they're run without their rows, so
+			// they're analyzed without the scope of the subquery expressions they're in
+			child, err := a.Analyze(ctx, n.Child, nil)
 			if err != nil {
 				return nil, err
 			}
 
+			if len(n.Columns) > 0 {
+				child, err = renameSubqueryColumns(child, n.Columns)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			return n.WithChildren(child)
 		default:
 			return n, nil
@@ -26,6 +41,42 @@ func resolveSubqueries(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope)
 	})
 }
 
+// renameSubqueryColumns returns the subquery given, already analyzed, with its columns renamed to those given.
+func renameSubqueryColumns(n sql.Node, columns []string) (sql.Node, error) {
+	if qp, ok := n.(*plan.QueryProcess); ok {
+		n = qp.Child
+	}
+
+	schema := n.Schema()
+	if len(schema) != len(columns) {
+		return nil, sql.ErrColumnNamesCount.New()
+	}
+
+	projections := make([]sql.Expression, len(schema))
+	for i, col := range schema {
+		projections[i] = expression.NewAlias(columns[i],
+			expression.NewGetFieldWithTable(i, col.Type, col.Source, col.Name, col.Nullable))
+	}
+	return plan.NewProject(projections, n), nil
+}
+
+// limitExistsSubqueries limits the subqueries of EXISTS expressions to a single row, since only whether they return
+// any row matters. The limit is pushed down by the analysis of the subquery, like any other.
+func limitExistsSubqueries(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	return plan.TransformExpressionsUp(n, func(e sql.Expression) (sql.Expression, error) {
+		exists, ok := e.(*plan.ExistsSubquery)
+		if !ok {
+			return e, nil
+		}
+
+		if limit, ok := exists.Query.Query.(*plan.Limit); ok && limit.Limit <= 1 {
+			return e, nil
+		}
+
+		return plan.NewExistsSubquery(exists.Query.WithQuery(plan.NewLimit(1, exists.Query.Query))), nil
+	})
+}
+
 func resolveSubqueryExpressions(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 	return plan.TransformExpressionsUpWithNode(n, func(n sql.Node, e sql.Expression) (sql.Expression, error) {
 		s, ok := e.(*plan.Subquery)
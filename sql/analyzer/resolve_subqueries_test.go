@@ -73,14 +73,14 @@ func TestResolveSubqueries(t *testing.T) {
 						plan.NewSubqueryAlias(
 							"t1", "",
 							plan.NewDecoratedNode("Projected table access on [a]",
-								plan.NewResolvedTable(foo.WithProjection([]string{"a"}))),
+								plan.NewResolvedTableInDatabase(foo.WithProjection([]string{"a"}), "mydb")),
 						),
 						plan.NewSubqueryAlias(
 							"t2", "",
 							plan.NewSubqueryAlias(
 								"t2alias", "",
 								plan.NewDecoratedNode("Projected table access on [b]",
-									plan.NewResolvedTable(bar.WithProjection([]string{"b"}))),
+									plan.NewResolvedTableInDatabase(bar.WithProjection([]string{"b"}), "mydb")),
 							),
 						),
 					),
@@ -151,7 +151,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -194,7 +194,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(0, "mytable", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -285,7 +285,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(0, "mytable", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -308,7 +308,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -328,7 +328,7 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 									gf(1, "mytable", "x"),
 									gf(2, "mytable2", "i"),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -392,12 +392,12 @@ func TestResolveSubqueryExpressions(t *testing.T) {
 													gf(1, "mytable", "x"),
 													gf(4, "mytable2", "i"),
 												),
-												plan.NewResolvedTable(table2),
+												plan.NewResolvedTableInDatabase(table2, "mydb"),
 											),
 										),
 										""),
 								),
-								plan.NewResolvedTable(table2),
+								plan.NewResolvedTableInDatabase(table2, "mydb"),
 							),
 						),
 						""),
@@ -547,3 +547,46 @@ func mustExpr(e sql.Expression, err error) sql.Expression {
 	}
 	return e
 }
+
+func TestLimitExistsSubqueries(t *testing.T) {
+	table := memory.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "mytable"},
+	})
+
+	exists := func(query sql.Node) sql.Node {
+		return plan.NewFilter(
+			plan.NewExistsSubquery(plan.NewSubquery(query, "")),
+			plan.NewUnresolvedTable("foo", ""),
+		)
+	}
+
+	testCases := []analyzerFnTestCase{
+		{
+			name: "subquery",
+			node: exists(plan.NewResolvedTable(table)),
+			expected: exists(
+				plan.NewLimit(1, plan.NewResolvedTable(table)),
+			),
+		},
+		{
+			name: "subquery with a limit",
+			node: exists(plan.NewLimit(10, plan.NewResolvedTable(table))),
+			expected: exists(
+				plan.NewLimit(1, plan.NewLimit(10, plan.NewResolvedTable(table))),
+			),
+		},
+		{
+			name: "already limited subquery",
+			node: exists(plan.NewLimit(0, plan.NewResolvedTable(table))),
+		},
+		{
+			name: "scalar subquery",
+			node: plan.NewProject(
+				[]sql.Expression{plan.NewSubquery(plan.NewResolvedTable(table), "")},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+		},
+	}
+
+	runTestCases(t, sql.NewEmptyContext(), testCases, nil, getRule("limit_exists_subqueries"))
+}
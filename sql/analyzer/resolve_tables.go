@@ -9,11 +9,11 @@ import (
 
 const dualTableName = "dual"
 
+// dualTable is the table of the queries without tables, like SELECT 1 or SELECT 1 FROM DUAL. It has no columns, so
+// nothing can be selected from it, and a single row, so the expressions of the query are evaluated once.
 var dualTable = func() sql.Table {
-	t := memory.NewTable(dualTableName, sql.Schema{
-		{Name: "dummy", Source: dualTableName, Type: sql.LongText, Nullable: false},
-	})
-	_ = t.Insert(sql.NewEmptyContext(), sql.NewRow("x"))
+	t := memory.NewTable(dualTableName, nil)
+	_ = t.Insert(sql.NewEmptyContext(), sql.NewRow())
 	return t
 }()
 
@@ -26,52 +26,101 @@ func resolveTables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 			return n, nil
 		}
 
+		if tf, ok := n.(*plan.UnresolvedTableFunction); ok {
+			return resolveTableFunction(ctx, a, tf)
+		}
+
 		t, ok := n.(*plan.UnresolvedTable)
 		if !ok {
 			return n, nil
 		}
 
-		name := t.Name()
-		db := t.Database
-		if db == "" {
-			db = ctx.GetCurrentDatabase()
+		return resolveTable(ctx, a, t)
+	})
+}
+
+// resolveTable resolves an unresolved table to the table of the catalog it names.
+func resolveTable(ctx *sql.Context, a *Analyzer, t *plan.UnresolvedTable) (sql.Node, error) {
+	name := t.Name()
+	db := t.Database
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+
+	if t.AsOf != nil {
+		// This is necessary to use functions in AS OF expressions. Because function resolution happens after table
+		// resolution, we resolve any functions in the AsOf here in order to evaluate them immediately. A better solution
+		// might be to defer evaluating the expression until later in the analysis, but that requires bigger changes.
+		asOfExpr, err := expression.TransformUp(t.AsOf, resolveFunctionsInExpr(a))
+		if err != nil {
+			return nil, err
+		}
+
+		if !asOfExpr.Resolved() {
+			return nil, sql.ErrInvalidAsOfExpression.New(asOfExpr.String())
 		}
 
-		if t.AsOf != nil {
-			// This is necessary to use functions in AS OF expressions. Because function resolution happens after table
-			// resolution, we resolve any functions in the AsOf here in order to evaluate them immediately. A better solution
-			// might be to defer evaluating the expression until later in the analysis, but that requires bigger changes.
-			asOfExpr, err := expression.TransformUp(t.AsOf, resolveFunctionsInExpr(a))
-			if err != nil {
-				return nil, err
-			}
-
-			if !asOfExpr.Resolved() {
-				return nil, sql.ErrInvalidAsOfExpression.New(asOfExpr.String())
-			}
-
-			asOf, err := asOfExpr.Eval(ctx, nil)
-			if err != nil {
-				return nil, err
-			}
-
-			rt, err := a.Catalog.TableAsOf(ctx, db, name, asOf)
-			if err != nil {
-				return handleTableLookupFailure(err, name, db, a, t)
-			}
-
-			a.Log("table resolved: %q as of %s", rt.Name(), asOf)
-			return plan.NewResolvedTable(rt), nil
+		asOf, err := asOfExpr.Eval(ctx, nil)
+		if err != nil {
+			return nil, err
 		}
 
-		rt, err := a.Catalog.Table(ctx, db, name)
+		rt, err := a.Catalog.TableAsOf(ctx, db, name, asOf)
 		if err != nil {
 			return handleTableLookupFailure(err, name, db, a, t)
 		}
 
-		a.Log("table resolved: %s", t.Name())
-		return plan.NewResolvedTable(rt), nil
-	})
+		a.Log("table resolved: %q as of %s", rt.Name(), asOf)
+		return plan.NewResolvedTableInDatabase(rt, databaseName(ctx, a, db)), nil
+	}
+
+	rt, err := a.Catalog.Table(ctx, db, name)
+	if err != nil {
+		return handleTableLookupFailure(err, name, db, a, t)
+	}
+
+	a.Log("table resolved: %s", t.Name())
+	return plan.NewResolvedTableInDatabase(rt, databaseName(ctx, a, db)), nil
+}
+
+// databaseName returns the name of the database given as it's named in the catalog, which may differ in case from
+// the name used by the query.
+func databaseName(ctx *sql.Context, a *Analyzer, db string) string {
+	database, err := a.Catalog.SessionDatabase(ctx, db)
+	if err != nil {
+		return db
+	}
+	return database.Name()
+}
+
+// resolveTableFunction resolves the call of a table function to the table returned by the function. Like AS OF
+// expressions, the arguments must be evaluated before the rest of the query is analyzed, because the schema of the
+// table can depend on them, so functions in the arguments are resolved here.
+func resolveTableFunction(ctx *sql.Context, a *Analyzer, t *plan.UnresolvedTableFunction) (sql.Node, error) {
+	fn, err := a.Catalog.TableFunction(t.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]sql.Expression, len(t.Arguments))
+	for i, arg := range t.Arguments {
+		args[i], err = expression.TransformUp(arg, resolveFunctionsInExpr(a))
+		if err != nil {
+			return nil, err
+		}
+
+		if !args[i].Resolved() {
+			return nil, sql.ErrInvalidTableFunctionArgument.New(t.Name(), args[i].String())
+		}
+	}
+
+	rt, err := fn.NewTable(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Log("table function resolved: %s", rt.Name())
+	return plan.NewResolvedTable(rt), nil
 }
 
 func handleTableLookupFailure(err error, tableName string, dbName string, a *Analyzer, t *plan.UnresolvedTable) (sql.Node, error) {
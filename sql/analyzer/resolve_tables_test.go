@@ -8,7 +8,9 @@ import (
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function"
 	"github.com/dolthub/go-mysql-server/sql/plan"
+	"github.com/dolthub/go-mysql-server/sql/tablefunction"
 )
 
 func TestResolveTables(t *testing.T) {
@@ -28,12 +30,12 @@ func TestResolveTables(t *testing.T) {
 	var notAnalyzed sql.Node = plan.NewUnresolvedTable("mytable", "")
 	analyzed, err := f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTableInDatabase(table, "mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTable("MyTable", "")
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTableInDatabase(table, "mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTable("nonexistant", "")
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
@@ -57,13 +59,43 @@ func TestResolveTables(t *testing.T) {
 	notAnalyzed = plan.NewUnresolvedTableAsOf("myTable", "", expression.NewLiteral("2019-01-01", sql.LongText))
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
 	require.NoError(err)
-	require.Equal(plan.NewResolvedTable(table), analyzed)
+	require.Equal(plan.NewResolvedTableInDatabase(table, "mydb"), analyzed)
 
 	notAnalyzed = plan.NewUnresolvedTableAsOf("myTable", "", expression.NewLiteral("2019-01-02", sql.LongText))
 	analyzed, err = f.Apply(ctx, a, notAnalyzed, nil)
 	require.Error(err)
 }
 
+func TestResolveTableFunctions(t *testing.T) {
+	require := require.New(t)
+	f := getRule("resolve_tables")
+
+	catalog := sql.NewCatalog()
+	catalog.MustRegister(function.Defaults...)
+	catalog.MustRegisterTableFunction(tablefunction.Numbers)
+
+	a := NewBuilder(catalog).AddPostAnalyzeRule(f.Name, f.Apply).Build()
+	ctx := sql.NewEmptyContext()
+
+	var notAnalyzed sql.Node = plan.NewTableAlias("n", plan.NewUnresolvedTableFunction("numbers", []sql.Expression{
+		expression.NewUnresolvedFunction("abs", false, expression.NewLiteral(int8(-3), sql.Int8)),
+	}))
+	analyzed, err := f.Apply(ctx, a, notAnalyzed, nil)
+	require.NoError(err)
+
+	expected, err := tablefunction.Numbers.NewTable(ctx, []sql.Expression{expression.NewLiteral(int8(3), sql.Int8)})
+	require.NoError(err)
+	require.Equal(plan.NewTableAlias("n", plan.NewResolvedTable(expected)), analyzed)
+
+	notAnalyzed = plan.NewUnresolvedTableFunction("numbers", []sql.Expression{expression.NewUnresolvedColumn("i")})
+	_, err = f.Apply(ctx, a, notAnalyzed, nil)
+	require.True(sql.ErrInvalidTableFunctionArgument.Is(err))
+
+	notAnalyzed = plan.NewUnresolvedTableFunction("nonexistent", nil)
+	_, err = f.Apply(ctx, a, notAnalyzed, nil)
+	require.True(sql.ErrTableFunctionNotFound.Is(err))
+}
+
 func TestResolveTablesNoCurrentDB(t *testing.T) {
 	require := require.New(t)
 	f := getRule("resolve_tables")
@@ -121,7 +153,7 @@ func TestResolveTablesNested(t *testing.T) {
 	require.NoError(err)
 	expected := plan.NewProject(
 		[]sql.Expression{expression.NewGetField(0, sql.Int32, "i", true)},
-		plan.NewResolvedTable(table),
+		plan.NewResolvedTableInDatabase(table, "mydb"),
 	)
 	require.Equal(expected, analyzed)
 
@@ -133,7 +165,7 @@ func TestResolveTablesNested(t *testing.T) {
 	require.NoError(err)
 	expected = plan.NewProject(
 		[]sql.Expression{expression.NewGetField(0, sql.Int32, "i", true)},
-		plan.NewResolvedTable(table2),
+		plan.NewResolvedTableInDatabase(table2, "my_other_db"),
 	)
 	require.Equal(expected, analyzed)
 }
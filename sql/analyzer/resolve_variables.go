@@ -39,6 +39,9 @@ func resolveSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 		if !ok {
 			return e, nil
 		}
+		if _, ok := sf.Left.(*plan.LocalVariable); ok {
+			return e, nil
+		}
 
 		varName := trimVarName(sf.Left.String())
 		setVal, err := getSetVal(ctx, varName, sf.Right)
@@ -76,7 +79,7 @@ func resolveSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope
 					}
 				}
 
-				return sf.WithChildren(expression.NewSystemVar(varName, typ), setVal)
+				return sf.WithChildren(newSystemVar(uc.String(), varName, typ), setVal)
 			}
 
 			if isUserVariable(uc) {
@@ -102,6 +105,9 @@ func resolveBarewordSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scop
 		if !ok {
 			return e, nil
 		}
+		if _, ok := sf.Left.(*plan.LocalVariable); ok {
+			return e, nil
+		}
 
 		varName := trimVarName(sf.Left.String())
 		setVal, err := getSetVal(ctx, varName, sf.Right)
@@ -139,15 +145,14 @@ func resolveBarewordSetVariables(ctx *sql.Context, a *Analyzer, n sql.Node, scop
 // getSetVal evaluates the right hand side of a SetField expression and returns an evaluated value as appropriate
 func getSetVal(ctx *sql.Context, varName string, e sql.Expression) (sql.Expression, error) {
 	if _, ok := e.(*expression.DefaultColumn); ok {
-		valtyp, ok := sql.DefaultSessionConfig()[varName]
+		v, ok := ctx.GlobalVariables.Get(varName)
 		if !ok {
 			return nil, sql.ErrUnknownSystemVariable.New(varName)
 		}
-		value, typ := valtyp.Value, valtyp.Typ
-		return expression.NewLiteral(value, typ), nil
+		return expression.NewLiteral(v.Default, v.Type), nil
 	}
 
-	if !e.Resolved() {
+	if !e.Resolved() || hasLocalVariables(e) {
 		return e, nil
 	}
 
@@ -193,3 +198,16 @@ func getSetVal(ctx *sql.Context, varName string, e sql.Expression) (sql.Expressi
 
 	return e, nil
 }
+
+// hasLocalVariables returns whether the expression given uses the local variables of a BEGIN ... END block, which only
+// have a value while the block runs.
+func hasLocalVariables(e sql.Expression) bool {
+	found := false
+	sql.Inspect(e, func(e sql.Expression) bool {
+		if _, ok := e.(*plan.LocalVariable); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// resolveWindows turns the projections computing window functions into Window nodes, which compute them over all the
+// rows of their child. Window functions can only be computed in the expressions selected by a query, so using them
+// anywhere else, or in the arguments or the window of another window function, is an error.
+func resolveWindows(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		// Window nodes of queries analyzed again have been resolved already
+		e, ok := n.(sql.Expressioner)
+		if _, isWindow := n.(*plan.Window); !ok || isWindow {
+			return n, nil
+		}
+
+		p, ok := n.(*plan.Project)
+		if !ok {
+			if f := plan.FindWindowFunction(e.Expressions()...); f != nil {
+				return nil, plan.ErrInvalidWindowFunctionUse.New(f)
+			}
+			return n, nil
+		}
+
+		var found bool
+		var err error
+		for _, expr := range p.Projections {
+			sql.Inspect(expr, func(e sql.Expression) bool {
+				f, ok := e.(*plan.WindowFunction)
+				if !ok || err != nil {
+					return err == nil
+				}
+
+				// The first of the children of a window function is the function it computes
+				found = true
+				args := append(f.Function.Children(), f.Children()[1:]...)
+				if nested := plan.FindWindowFunction(args...); nested != nil {
+					err = plan.ErrInvalidWindowFunctionUse.New(nested)
+				}
+				return false
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return n, nil
+		}
+
+		a.Log("computing window functions of projection")
+		return plan.NewWindow(p.Projections, p.Child), nil
+	})
+}
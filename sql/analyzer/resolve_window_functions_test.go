@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestResolveWindows(t *testing.T) {
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "foo", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.Int64, "foo", "b", false)
+	rowNumber := plan.NewWindowFunction(plan.NewRowNumber(), []sql.Expression{a}, []plan.SortField{{Column: b, Order: plan.Ascending}})
+	sum := plan.NewWindowFunction(aggregation.NewSum(b), nil, nil)
+
+	testCases := []struct {
+		name     string
+		node     sql.Node
+		expected sql.Node
+		err      *errors.Kind
+	}{
+		{
+			name: "projection without window functions",
+			node: plan.NewProject(
+				[]sql.Expression{a, aggregation.NewSum(b)},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+			expected: plan.NewProject(
+				[]sql.Expression{a, aggregation.NewSum(b)},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+		},
+		{
+			name: "window functions",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: a, Order: plan.Ascending}},
+				plan.NewProject(
+					[]sql.Expression{
+						a,
+						expression.NewAlias("n", rowNumber),
+						expression.NewArithmetic(sum, expression.NewLiteral(int64(1), sql.Int64), "+"),
+					},
+					plan.NewFilter(expression.NewGreaterThan(a, b), plan.NewUnresolvedTable("foo", "")),
+				),
+			),
+			expected: plan.NewSort(
+				[]plan.SortField{{Column: a, Order: plan.Ascending}},
+				plan.NewWindow(
+					[]sql.Expression{
+						a,
+						expression.NewAlias("n", rowNumber),
+						expression.NewArithmetic(sum, expression.NewLiteral(int64(1), sql.Int64), "+"),
+					},
+					plan.NewFilter(expression.NewGreaterThan(a, b), plan.NewUnresolvedTable("foo", "")),
+				),
+			),
+		},
+		{
+			name: "window resolved already",
+			node: plan.NewWindow(
+				[]sql.Expression{rowNumber},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+			expected: plan.NewWindow(
+				[]sql.Expression{rowNumber},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+		},
+		{
+			name: "window function in filter",
+			node: plan.NewProject(
+				[]sql.Expression{a},
+				plan.NewFilter(expression.NewEquals(rowNumber, b), plan.NewUnresolvedTable("foo", "")),
+			),
+			err: plan.ErrInvalidWindowFunctionUse,
+		},
+		{
+			name: "window function in sort",
+			node: plan.NewSort(
+				[]plan.SortField{{Column: sum, Order: plan.Ascending}},
+				plan.NewProject([]sql.Expression{a}, plan.NewUnresolvedTable("foo", "")),
+			),
+			err: plan.ErrInvalidWindowFunctionUse,
+		},
+		{
+			name: "window function in the argument of a window function",
+			node: plan.NewProject(
+				[]sql.Expression{plan.NewWindowFunction(aggregation.NewSum(rowNumber), nil, nil)},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+			err: plan.ErrInvalidWindowFunctionUse,
+		},
+		{
+			name: "window function in the window of a window function",
+			node: plan.NewProject(
+				[]sql.Expression{plan.NewWindowFunction(plan.NewRank(), []sql.Expression{sum}, nil)},
+				plan.NewUnresolvedTable("foo", ""),
+			),
+			err: plan.ErrInvalidWindowFunctionUse,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			result, err := resolveWindows(sql.NewEmptyContext(), nil, tt.node, nil)
+			if tt.err != nil {
+				require.Error(err)
+				require.True(tt.err.Is(err))
+			} else {
+				require.NoError(err)
+				require.Equal(tt.expected, result)
+			}
+		})
+	}
+}
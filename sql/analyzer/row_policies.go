@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// applyRowPolicies filters every read of a table with row policies with the predicates of its policies for the user
+// of the session. Tables, or their aliases, are wrapped before they're resolved, so the columns of the predicates are
+// resolved against them by the default rules, and views and subqueries get their policies when they're analyzed.
+// Tables that are the target of an INSERT or the subject of DDL and SHOW statements aren't read, so they're left as
+// they are.
+func applyRowPolicies(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	if a.Catalog == nil || a.Catalog.RowPolicyRegistry == nil || !a.Catalog.HasRowPolicies() {
+		return n, nil
+	}
+
+	span, _ := ctx.Span("apply_row_policies")
+	defer span.Finish()
+
+	return plan.TransformUpWithParent(n, func(n sql.Node, parent sql.Node, childNum int) (sql.Node, error) {
+		// The rest of the analyzer expects aliased tables to be the child of their alias, so the filter goes on top of
+		// the alias instead, and its columns are resolved against the alias.
+		var t *plan.UnresolvedTable
+		switch n := n.(type) {
+		case *plan.TableAlias:
+			t, _ = n.Child.(*plan.UnresolvedTable)
+		case *plan.UnresolvedTable:
+			if _, ok := parent.(*plan.TableAlias); !ok {
+				t = n
+			}
+		}
+		if t == nil || !isReadOfTable(parent, childNum) {
+			return n, nil
+		}
+
+		db := t.Database
+		if db == "" {
+			db = ctx.GetCurrentDatabase()
+		}
+
+		predicates, err := a.Catalog.RowPolicyPredicates(ctx, db, t.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(predicates) == 0 {
+			return n, nil
+		}
+
+		a.Log("applying %d row policies to table %s", len(predicates), t.Name())
+		return plan.NewFilter(expression.JoinAnd(predicates...), n), nil
+	})
+}
+
+// isReadOfTable returns whether a table that's the child of the node given at the index given has its rows read.
+func isReadOfTable(parent sql.Node, childNum int) bool {
+	switch parent.(type) {
+	case *plan.InsertInto:
+		return childNum != 0
+	case *plan.CreateTable, *plan.CreateTrigger, *plan.CreateIndex, *plan.AlterIndex, *plan.CreateForeignKey,
+		*plan.DropForeignKey, *plan.LockTables, *plan.ShowColumns, *plan.ShowIndexes, *plan.ShowCreateTable,
+		*plan.CheckTable, *plan.OptimizeTable, *plan.AnalyzeTable, *plan.CreateCheck, *plan.DropCheck,
+		*plan.DropConstraint:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestApplyRowPolicies(t *testing.T) {
+	f := getRule("apply_row_policies")
+
+	catalog := sql.NewCatalog()
+	catalog.AddRowPolicy("mydb", "mytable", func(ctx *sql.Context, user string) (sql.Expression, error) {
+		if user == "root" {
+			return nil, nil
+		}
+		return expression.NewEquals(
+			expression.NewUnresolvedColumn("owner"),
+			expression.NewLiteral(user, sql.LongText),
+		), nil
+	})
+	a := NewDefault(catalog)
+
+	predicate := expression.NewEquals(
+		expression.NewUnresolvedColumn("owner"),
+		expression.NewLiteral("alice", sql.LongText),
+	)
+
+	testCases := []struct {
+		name     string
+		user     string
+		node     sql.Node
+		expected sql.Node
+	}{
+		{
+			name:     "table",
+			user:     "alice",
+			node:     plan.NewUnresolvedTable("mytable", ""),
+			expected: plan.NewFilter(predicate, plan.NewUnresolvedTable("mytable", "")),
+		},
+		{
+			name:     "table without policies",
+			user:     "alice",
+			node:     plan.NewUnresolvedTable("othertable", ""),
+			expected: plan.NewUnresolvedTable("othertable", ""),
+		},
+		{
+			name:     "table of other database",
+			user:     "alice",
+			node:     plan.NewUnresolvedTable("mytable", "otherdb"),
+			expected: plan.NewUnresolvedTable("mytable", "otherdb"),
+		},
+		{
+			name:     "user without restrictions",
+			user:     "root",
+			node:     plan.NewUnresolvedTable("mytable", ""),
+			expected: plan.NewUnresolvedTable("mytable", ""),
+		},
+		{
+			name:     "aliased table",
+			user:     "alice",
+			node:     plan.NewTableAlias("t", plan.NewUnresolvedTable("mytable", "")),
+			expected: plan.NewFilter(predicate, plan.NewTableAlias("t", plan.NewUnresolvedTable("mytable", ""))),
+		},
+		{
+			name: "insert into table",
+			user: "alice",
+			node: plan.NewInsertInto(
+				plan.NewUnresolvedTable("mytable", ""),
+				plan.NewUnresolvedTable("mytable", ""),
+				false, nil, nil,
+			),
+			expected: plan.NewInsertInto(
+				plan.NewUnresolvedTable("mytable", ""),
+				plan.NewFilter(predicate, plan.NewUnresolvedTable("mytable", "")),
+				false, nil, nil,
+			),
+		},
+		{
+			name:     "show columns",
+			user:     "alice",
+			node:     plan.NewShowColumns(false, plan.NewUnresolvedTable("mytable", "")),
+			expected: plan.NewShowColumns(false, plan.NewUnresolvedTable("mytable", "")),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := sql.NewContext(
+				context.Background(),
+				sql.WithSession(sql.NewSession("server", "client", tt.user, 1)),
+			).WithCurrentDB("mydb")
+
+			result, err := f.Apply(ctx, a, tt.node, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -7,11 +7,21 @@ import (
 // OnceBeforeDefault contains the rules to be applied just once before the
 // DefaultRules.
 var OnceBeforeDefault = []Rule{
+	{"check_read_only", checkReadOnly},
+	{"resolve_ctes", resolveCommonTableExpressions},
 	{"resolve_views", resolveViews},
+	{"apply_row_policies", applyRowPolicies},
+	{"apply_column_masks", applyColumnMasks},
 	{"resolve_tables", resolveTables},
+	{"resolve_handlers", resolveHandlers},
 	{"resolve_set_variables", resolveSetVariables},
 	{"resolve_create_like", resolveCreateLike},
+	{"resolve_hints", resolveHints},
+	{"capture_plan_baselines", capturePlanBaselines},
+	{"merge_derived_tables", mergeDerivedTables},
+	{"propagate_equalities", propagateEqualities},
 	{"resolve_subqueries", resolveSubqueries},
+	{"limit_exists_subqueries", limitExistsSubqueries},
 	{"check_aliases", checkAliases},
 }
 
@@ -21,6 +31,7 @@ var DefaultRules = []Rule{
 	{"resolve_orderby_literals", resolveOrderByLiterals},
 	{"pushdown_sort", pushdownSort},
 	{"pushdown_groupby_aliases", pushdownGroupByAliases},
+	{"resolve_having_grouping_columns", resolveHavingGroupingColumns},
 	{"resolve_new_and_old_in_triggers", resolveNewAndOldReferences},
 	{"qualify_columns", qualifyColumns},
 	{"resolve_columns", resolveColumns},
@@ -29,6 +40,7 @@ var DefaultRules = []Rule{
 	{"expand_stars", expandStars},
 	{"resolve_functions", resolveFunctions},
 	{"resolve_having", resolveHaving},
+	{"resolve_orderby_aggregations", resolveOrderByAggregations},
 	{"merge_union_schemas", mergeUnionSchemas},
 	{"flatten_group_by_aggregations", flattenGroupByAggregations},
 	{"reorder_projection", reorderProjection},
@@ -41,16 +53,22 @@ var DefaultRules = []Rule{
 // OnceAfterDefault contains the rules to be applied just once after the
 // DefaultRules.
 var OnceAfterDefault = []Rule{
+	{"check_column_privileges", checkColumnPrivileges},
 	{"load_triggers", loadTriggers},
 	{"resolve_column_defaults", resolveColumnDefaults},
 	{"resolve_generators", resolveGenerators},
+	{"resolve_windows", resolveWindows},
 	{"remove_unnecessary_converts", removeUnnecessaryConverts},
 	{"assign_catalog", assignCatalog},
 	{"assign_info_schema", assignInfoSchema},
 	{"prune_columns", pruneColumns},
+	{"decorrelate_subqueries", decorrelateSubqueries},
 	{"optimize_joins", optimizeJoins},
+	{"replace_point_lookups", replacePointLookups},
 	{"pushdown_filters", pushdownFilters},
 	{"pushdown_projections", pushdownProjections},
+	{"pushdown_joins", pushdownJoins},
+	{"pushdown_sort_and_limit", pushdownSortAndLimit},
 	{"erase_projection", eraseProjection},
 	// One final pass at analyzing subqueries to handle rewriting field indexes after changes to outer scope by
 	// previous rules.
@@ -58,6 +76,8 @@ var OnceAfterDefault = []Rule{
 	{"cache_subquery_results", cacheSubqueryResults},
 	{"resolve_insert_rows", resolveInsertRows},
 	{"apply_triggers", applyTriggers},
+	{"apply_foreign_keys", applyForeignKeys},
+	{"apply_checks", applyChecks},
 	{"apply_row_update_accumulators", applyUpdateAccumulators},
 }
 
@@ -66,6 +86,8 @@ var OnceAfterDefault = []Rule{
 var OnceAfterAll = []Rule{
 	{"track_process", trackProcess},
 	{"parallelize", parallelize},
+	{"sort_unordered_results", sortUnorderedResults},
+	{"estimate_described_costs", estimateDescribedCosts},
 	{"clear_warnings", clearWarnings},
 }
 
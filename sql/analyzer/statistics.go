@@ -0,0 +1,534 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+const (
+	// defaultRowCount is the number of rows estimated for tables without statistics.
+	defaultRowCount = 1000
+	// defaultEqualitySelectivity is the fraction of the rows of a table estimated to have a given value in a column
+	// without statistics.
+	defaultEqualitySelectivity = 0.1
+	// defaultSelectivity is the fraction of rows estimated to match a condition that isn't an equality.
+	defaultSelectivity = 1.0 / 3
+)
+
+// CostModel has the costs the analyzer estimates the costs of plans with, to choose between them. Costs don't have a
+// unit, and only the ratios between them matter: the default costs are relative to reading a row in a scan.
+type CostModel struct {
+	// AccessCost is the cost of starting to read rows of a table, with a scan or an index lookup.
+	AccessCost float64
+	// ScanRowCost is the cost of reading a row in a scan.
+	ScanRowCost float64
+	// LookupRowCost is the cost of reading a row found in an index, which costs more than reading the next row of a
+	// scan.
+	LookupRowCost float64
+	// HashBuildRowCost is the cost of adding a row to the hash table of a hash join, on top of the cost of reading it.
+	HashBuildRowCost float64
+	// RemoteRowCost is the cost of sending a row of a sql.RemoteTable over the network, on top of the cost of reading
+	// it.
+	RemoteRowCost float64
+}
+
+// DefaultCostModel returns the costs the analyzer uses if it's not given others.
+func DefaultCostModel() CostModel {
+	return CostModel{
+		AccessCost:       1.0,
+		ScanRowCost:      1.0,
+		LookupRowCost:    1.5,
+		HashBuildRowCost: 1.0,
+		RemoteRowCost:    2.0,
+	}
+}
+
+// costEstimator estimates the number of rows nodes return and the cost of reading them, from the statistics of the
+// tables that implement sql.StatisticsTable and the cost model of the analyzer. Tables without statistics get default
+// estimates.
+type costEstimator struct {
+	ctx   *sql.Context
+	a     *Analyzer
+	model CostModel
+	// tables are the tables of the node the estimator was created for, keyed by their lower case names and aliases,
+	// which is how the columns of expressions name them.
+	tables map[string]*plan.ResolvedTable
+	stats  map[*plan.ResolvedTable]*sql.TableStatistics
+}
+
+func newCostEstimator(ctx *sql.Context, a *Analyzer, n sql.Node) *costEstimator {
+	tables := make(map[string]*plan.ResolvedTable)
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.TableAlias:
+			if rt := getResolvedTable(node); rt != nil {
+				tables[strings.ToLower(node.Name())] = rt
+			}
+		case *plan.ResolvedTable:
+			if _, ok := tables[strings.ToLower(node.Name())]; !ok {
+				tables[strings.ToLower(node.Name())] = node
+			}
+		}
+		return true
+	})
+
+	model := DefaultCostModel()
+	if a != nil && a.CostModel != nil {
+		model = *a.CostModel
+	}
+
+	return &costEstimator{
+		ctx:    ctx,
+		a:      a,
+		model:  model,
+		tables: tables,
+		stats:  make(map[*plan.ResolvedTable]*sql.TableStatistics),
+	}
+}
+
+// statistics returns the statistics of the table given, or nil if it has none.
+func (e *costEstimator) statistics(rt *plan.ResolvedTable) *sql.TableStatistics {
+	if rt == nil {
+		return nil
+	}
+
+	if stats, ok := e.stats[rt]; ok {
+		return stats
+	}
+
+	var stats *sql.TableStatistics
+	table := rt.Table
+	for table != nil {
+		if st, ok := table.(sql.StatisticsTable); ok {
+			var err error
+			stats, err = st.Statistics(e.ctx)
+			if err != nil {
+				// Statistics only make plans better, so queries don't fail without them
+				e.a.Log("cannot get the statistics of table %s: %s", rt.Name(), err)
+				stats = nil
+			}
+			break
+		}
+
+		if tw, ok := table.(sql.TableWrapper); ok {
+			table = tw.Underlying()
+		} else {
+			table = nil
+		}
+	}
+
+	e.stats[rt] = stats
+	return stats
+}
+
+// tableRows returns the number of rows of the table given.
+func (e *costEstimator) tableRows(rt *plan.ResolvedTable) float64 {
+	if stats := e.statistics(rt); stats != nil {
+		return float64(stats.RowCount)
+	}
+	return defaultRowCount
+}
+
+// rows returns the estimated number of rows the node given returns.
+func (e *costEstimator) rows(n sql.Node) float64 {
+	switch n := n.(type) {
+	case *plan.ResolvedTable:
+		return e.tableRows(n)
+	case *plan.IndexedTableAccess:
+		return e.tableRows(n.ResolvedTable)
+	case *plan.Filter:
+		return e.rows(n.Child) * e.selectivity(splitConjunction(n.Expression))
+	case *plan.CrossJoin:
+		return e.rows(n.Left) * e.rows(n.Right)
+	case *plan.InnerJoin:
+		return e.rows(n.Left) * e.rows(n.Right) * e.selectivity(splitConjunction(n.Cond))
+	case *plan.LeftJoin:
+		return e.outerJoinRows(n.Left, n.Right, n.Cond)
+	case *plan.RightJoin:
+		return e.outerJoinRows(n.Right, n.Left, n.Cond)
+	case *plan.SemiJoin:
+		return e.semiJoinRows(n.Left, n.Right, n.Cond, false)
+	case *plan.AntiJoin:
+		return e.semiJoinRows(n.Left, n.Right, n.Cond, true)
+	case *plan.IndexedJoin:
+		return e.joinRows(n.JoinType(), n.Left, n.Right, n.Cond)
+	case *plan.HashJoin:
+		return e.joinRows(n.JoinType(), n.Left, n.Right, n.Cond)
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		return defaultRowCount
+	}
+	if len(children) == 1 {
+		return e.rows(children[0])
+	}
+
+	rows := 1.0
+	for _, child := range children {
+		rows *= e.rows(child)
+	}
+	return rows
+}
+
+// outerJoinRows returns the estimated number of rows of an outer join, which returns every row of the outer side
+// at least once.
+func (e *costEstimator) outerJoinRows(outer, inner sql.Node, cond sql.Expression) float64 {
+	outerRows := e.rows(outer)
+	rows := outerRows * e.rows(inner) * e.selectivity(splitConjunction(cond))
+	if rows < outerRows {
+		return outerRows
+	}
+	return rows
+}
+
+// joinRows returns the estimated number of rows of a join of the type given of the primary and secondary nodes given,
+// for the joins whose Left node is always the primary one.
+func (e *costEstimator) joinRows(joinType plan.JoinType, primary, secondary sql.Node, cond sql.Expression) float64 {
+	switch joinType {
+	case plan.JoinTypeInner:
+		return e.rows(primary) * e.rows(secondary) * e.selectivity(splitConjunction(cond))
+	case plan.JoinTypeSemi:
+		return e.semiJoinRows(primary, secondary, cond, false)
+	case plan.JoinTypeAnti:
+		return e.semiJoinRows(primary, secondary, cond, true)
+	}
+	return e.outerJoinRows(primary, secondary, cond)
+}
+
+// semiJoinRows returns the estimated number of rows of a semi join, which returns the rows of the outer side with a
+// matching row of the inner side once, or of an anti join, which returns the rest of them.
+func (e *costEstimator) semiJoinRows(outer, inner sql.Node, cond sql.Expression, anti bool) float64 {
+	outerRows := e.rows(outer)
+	rows := math.Min(outerRows, outerRows*e.rows(inner)*e.selectivity(splitConjunction(cond)))
+	if anti {
+		return outerRows - rows
+	}
+	return rows
+}
+
+// selectivity returns the estimated fraction of rows that match all of the conditions given.
+func (e *costEstimator) selectivity(conds []sql.Expression) float64 {
+	selectivity := 1.0
+	for _, cond := range conds {
+		selectivity *= e.conditionSelectivity(cond)
+	}
+	return selectivity
+}
+
+// conditionSelectivity returns the estimated fraction of rows that match the condition given. Comparisons of columns
+// with values are estimated from the histograms of the columns, if they have them. Without histograms, a row has a
+// given value in a column for one of the distinct values of the column, and equal values in two columns for one of
+// the distinct values of the column with the most of them.
+func (e *costEstimator) conditionSelectivity(cond sql.Expression) float64 {
+	if s, ok := e.histogramSelectivity(cond); ok {
+		return s
+	}
+
+	switch cond := cond.(type) {
+	case *expression.IsNull:
+		if gf, ok := cond.Child.(*expression.GetField); ok {
+			stats := e.statistics(e.tables[strings.ToLower(gf.Table())])
+			if c, ok := stats.Column(gf.Name()); ok && stats.RowCount > 0 {
+				return float64(c.NullCount) / float64(stats.RowCount)
+			}
+		}
+		return defaultSelectivity
+	case *expression.Equals:
+		selectivity := 1.0
+		for _, side := range []sql.Expression{cond.Left(), cond.Right()} {
+			if isEvaluable(side) {
+				continue
+			}
+
+			s := defaultEqualitySelectivity
+			if gf, ok := side.(*expression.GetField); ok {
+				s = e.columnSelectivity(gf.Table(), gf.Name())
+			}
+			if s < selectivity {
+				selectivity = s
+			}
+		}
+
+		if selectivity < 1.0 {
+			return selectivity
+		}
+	}
+	return defaultSelectivity
+}
+
+// histogramSelectivity returns the fraction of rows that match the condition given estimated from the histogram of a
+// column, for comparisons of the column with values, BETWEEN and IN. It returns false for other conditions and columns
+// without histograms.
+func (e *costEstimator) histogramSelectivity(cond sql.Expression) (float64, bool) {
+	var column sql.Expression
+	var fraction func(h *sql.Histogram, values []interface{}) (float64, error)
+	var valueExprs []sql.Expression
+	switch c := cond.(type) {
+	case *expression.Between:
+		column, valueExprs = c.Val, []sql.Expression{c.Lower, c.Upper}
+		fraction = func(h *sql.Histogram, values []interface{}) (float64, error) {
+			return h.RangeFraction(values[0], true, values[1], true)
+		}
+	case *expression.InTuple:
+		tuple, ok := c.Right().(expression.Tuple)
+		if !ok {
+			return 0, false
+		}
+		column, valueExprs = c.Left(), tuple
+		fraction = func(h *sql.Histogram, values []interface{}) (float64, error) {
+			var sum float64
+			for _, v := range values {
+				if v == nil {
+					continue
+				}
+				f, err := h.EqualFraction(v)
+				if err != nil {
+					return 0, err
+				}
+				sum += f
+			}
+			return math.Min(sum, 1), nil
+		}
+	case *expression.Equals, *expression.LessThan, *expression.LessThanOrEqual, *expression.GreaterThan,
+		*expression.GreaterThanOrEqual:
+		cmp := c.(expression.Comparer)
+		left, right := cmp.Left(), cmp.Right()
+		if !isEvaluable(right) {
+			left, right, cmp = swapTermsOfExpression(cmp)
+		}
+		column, valueExprs = left, []sql.Expression{right}
+		fraction = func(h *sql.Histogram, values []interface{}) (float64, error) {
+			switch cmp.(type) {
+			case *expression.Equals:
+				return h.EqualFraction(values[0])
+			case *expression.LessThan:
+				return h.LessFraction(values[0], false)
+			case *expression.LessThanOrEqual:
+				return h.LessFraction(values[0], true)
+			case *expression.GreaterThan:
+				f, err := h.LessFraction(values[0], true)
+				return 1 - f, err
+			default:
+				f, err := h.LessFraction(values[0], false)
+				return 1 - f, err
+			}
+		}
+	default:
+		return 0, false
+	}
+
+	gf, ok := column.(*expression.GetField)
+	if !ok {
+		return 0, false
+	}
+	stats := e.statistics(e.tables[strings.ToLower(gf.Table())])
+	c, ok := stats.Column(gf.Name())
+	if !ok || c.Histogram == nil {
+		return 0, false
+	}
+
+	values := make([]interface{}, len(valueExprs))
+	for i, expr := range valueExprs {
+		if !isEvaluable(expr) {
+			return 0, false
+		}
+		v, err := expr.Eval(e.ctx, nil)
+		if err != nil {
+			return 0, false
+		}
+		// Comparisons with NULL match no row, and neither do the NULL values of IN
+		if _, ok := cond.(*expression.InTuple); v == nil && !ok {
+			return 0, true
+		}
+		values[i] = v
+	}
+
+	f, err := fraction(c.Histogram, values)
+	if err != nil {
+		e.a.Log("cannot estimate the selectivity of %s from the histogram of column %s: %s", cond, gf, err)
+		return 0, false
+	}
+
+	// Histograms only have the rows with values
+	if stats.RowCount > 0 {
+		f *= float64(stats.RowCount-c.NullCount) / float64(stats.RowCount)
+	}
+	return f, true
+}
+
+// columnSelectivity returns the estimated fraction of the rows of the table given with a given value in the column
+// named.
+func (e *costEstimator) columnSelectivity(table, column string) float64 {
+	stats := e.statistics(e.tables[strings.ToLower(table)])
+	c, ok := stats.Column(column)
+	if !ok {
+		return defaultEqualitySelectivity
+	}
+
+	if c.DistinctCount == 0 {
+		// Only NULL values, which are equal to no value
+		return 0
+	}
+	return 1 / float64(c.DistinctCount)
+}
+
+// lookupRows returns the estimated number of rows of the node given, a table that might be under nodes like filters,
+// each lookup of the index given returns, for keys of the number of the first expressions of the index given.
+func (e *costEstimator) lookupRows(n sql.Node, idx sql.Index, columns int) float64 {
+	table := getTableName(n)
+	selectivity := 1.0
+	for _, expr := range idx.Expressions()[:columns] {
+		selectivity *= e.columnSelectivity(table, expr[strings.Index(expr, ".")+1:])
+	}
+
+	// Lookups of all the columns of unique indexes return a row at most
+	if idx.IsUnique() && columns == len(idx.Expressions()) {
+		if tableRows := e.tableRows(getResolvedTable(n)); tableRows > 0 && 1/tableRows < selectivity {
+			selectivity = 1 / tableRows
+		}
+	}
+	return e.rows(n) * selectivity
+}
+
+// scanCost returns the estimated cost of reading all of the rows of the node given.
+func (e *costEstimator) scanCost(n sql.Node) float64 {
+	return e.model.AccessCost + e.rows(n)*e.rowCost(n, e.model.ScanRowCost)
+}
+
+// lookupCost returns the estimated cost of looking up the rows of the node given in the index given, with keys of the
+// number of its first expressions given.
+func (e *costEstimator) lookupCost(n sql.Node, idx sql.Index, columns int) float64 {
+	return e.model.AccessCost + e.lookupRows(n, idx, columns)*e.rowCost(n, e.model.LookupRowCost)
+}
+
+// rangeLookupCost returns the estimated cost of looking up the rows of the node given that match the conditions given
+// in an index, like the range of keys they bound.
+func (e *costEstimator) rangeLookupCost(n sql.Node, conds []sql.Expression) float64 {
+	return e.model.AccessCost + e.rows(n)*e.selectivity(conds)*e.rowCost(n, e.model.LookupRowCost)
+}
+
+// rowCost returns the cost given of reading a row of the node given, with the cost of sending it over the network if
+// it's read from a remote table.
+func (e *costEstimator) rowCost(n sql.Node, cost float64) float64 {
+	if isRemote(getResolvedTable(n)) {
+		return cost + e.model.RemoteRowCost
+	}
+	return cost
+}
+
+// hashJoinCost returns the estimated cost of joining the number of rows given of a primary side to the node given with
+// a hash join on the key conditions given, which reads the node once to hash its rows and then looks up the rows with
+// the same keys as each primary row in the hash table.
+func (e *costEstimator) hashJoinCost(rows float64, n sql.Node, keys []sql.Expression) float64 {
+	return e.scanCost(n) + e.hashCost(rows, n, keys)
+}
+
+// hashCost returns the estimated cost of hashing the rows of the node given, once they're read, and of looking up the
+// number of rows given in the hash table by the key conditions given.
+func (e *costEstimator) hashCost(rows float64, n sql.Node, keys []sql.Expression) float64 {
+	secondaryRows := e.rows(n)
+	build := secondaryRows * e.model.HashBuildRowCost
+	return build + rows*(e.model.AccessCost+secondaryRows*e.selectivity(keys)*e.model.ScanRowCost)
+}
+
+// cost returns the estimated cost of reading all of the rows of the node given, as it's executed: joins other than
+// hash joins read their secondary node once for every row of their primary node, and indexed joins look them up in an
+// index.
+func (e *costEstimator) cost(n sql.Node) float64 {
+	switch n := n.(type) {
+	case *plan.ResolvedTable, *plan.IndexedTableAccess:
+		return e.scanCost(n)
+	case *plan.CrossJoin, *plan.InnerJoin, *plan.LeftJoin, *plan.SemiJoin, *plan.AntiJoin:
+		children := n.Children()
+		return e.cost(children[0]) + e.rows(children[0])*e.cost(children[1])
+	case *plan.RightJoin:
+		return e.cost(n.Right) + e.rows(n.Right)*e.cost(n.Left)
+	case *plan.IndexedJoin:
+		return e.cost(n.Left) + e.rows(n.Left)*e.rangeLookupCost(n.Right, splitConjunction(n.Cond))
+	case *plan.HashJoin:
+		return e.cost(n.Left) + e.cost(n.Right) + e.hashCost(e.rows(n.Left), n.Right, splitConjunction(n.Cond))
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		return e.scanCost(n)
+	}
+
+	var cost float64
+	for _, child := range children {
+		cost += e.cost(child)
+	}
+	return cost
+}
+
+// estimate returns the estimated cost and number of rows of the node given and of all of its descendants.
+func (e *costEstimator) estimate(n sql.Node) *plan.CostEstimate {
+	estimate := &plan.CostEstimate{Cost: e.cost(n), Rows: e.rows(n)}
+	for _, child := range n.Children() {
+		estimate.Children = append(estimate.Children, e.estimate(child))
+	}
+	return estimate
+}
+
+// isRemote returns whether the table given, or a table it wraps, is a sql.RemoteTable whose rows are read from a
+// remote server.
+func isRemote(rt *plan.ResolvedTable) bool {
+	if rt == nil {
+		return false
+	}
+
+	table := rt.Table
+	for table != nil {
+		if remote, ok := table.(sql.RemoteTable); ok {
+			return remote.IsRemote()
+		}
+
+		if tw, ok := table.(sql.TableWrapper); ok {
+			table = tw.Underlying()
+		} else {
+			table = nil
+		}
+	}
+	return false
+}
+
+// filterLookupCost returns the estimated cost of looking up the rows of the table node given that match the filters
+// given in the indexes given, from the filters that only have columns of the indexes. It returns false if none of the
+// filters does.
+func (e *costEstimator) filterLookupCost(n sql.Node, filters []sql.Expression, indexes []sql.Index) (float64, bool) {
+	columns := make(map[string]bool)
+	for _, idx := range indexes {
+		for _, expr := range idx.Expressions() {
+			columns[strings.ToLower(expr[strings.Index(expr, ".")+1:])] = true
+		}
+	}
+
+	var indexed []sql.Expression
+	for _, filter := range filters {
+		onlyIndexed := true
+		sql.Inspect(filter, func(expr sql.Expression) bool {
+			if gf, ok := expr.(*expression.GetField); ok && !columns[strings.ToLower(gf.Name())] {
+				onlyIndexed = false
+			}
+			return onlyIndexed
+		})
+		if onlyIndexed && containsColumns(filter) {
+			indexed = append(indexed, filter)
+		}
+	}
+
+	if len(indexed) == 0 {
+		return 0, false
+	}
+	return e.model.AccessCost + e.rows(n)*e.selectivity(indexed)*e.rowCost(n, e.model.LookupRowCost), true
+}
+
+// sameCost returns whether the costs given are equal, but for the errors of floating point arithmetic.
+func sameCost(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
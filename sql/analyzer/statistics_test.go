@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestCostEstimator(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+		{Name: "c", Type: sql.Text, Source: "t", Nullable: true},
+	})
+	table.EnablePrimaryKeyIndexes()
+	require.NoError(table.CreateIndex(ctx, "c", sql.IndexUsing_BTree, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "c"}}, ""))
+	for i := 0; i < 10; i++ {
+		var c interface{} = "same"
+		if i%5 == 0 {
+			c = nil
+		}
+		require.NoError(table.Insert(ctx, sql.NewRow(int64(i), c)))
+	}
+
+	idxes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+	pkIdx, cIdx := idxes[0], idxes[1]
+
+	noStats := plan.NewResolvedTable(noStatisticsTable{memory.NewTable("u", nil)})
+	rt := plan.NewResolvedTable(table)
+	n := plan.NewCrossJoin(rt, plan.NewTableAlias("a", noStats))
+	e := newCostEstimator(ctx, NewDefault(sql.NewCatalog()), n)
+
+	require.Equal(10.0, e.rows(rt))
+	require.Equal(float64(defaultRowCount), e.rows(noStats))
+	require.Equal(10.0*defaultRowCount, e.rows(n))
+
+	i := expression.NewGetFieldWithTable(0, sql.Int64, "t", "i", false)
+	c := expression.NewGetFieldWithTable(1, sql.Text, "t", "c", true)
+	filter := plan.NewFilter(expression.NewEquals(i, expression.NewLiteral(int64(1), sql.Int64)), rt)
+	require.Equal(1.0, e.rows(filter))
+	filter = plan.NewFilter(expression.NewEquals(c, expression.NewGetFieldWithTable(2, sql.Int64, "a", "x", false)), rt)
+	require.Equal(defaultEqualitySelectivity*10, e.rows(filter))
+
+	// Semi joins return the rows of their left side once at most, and anti joins the ones they don't return
+	a := plan.NewTableAlias("a", noStats)
+	x := expression.NewGetFieldWithTable(2, sql.Int64, "a", "x", false)
+	require.Equal(10.0, e.rows(plan.NewSemiJoin(rt, a, expression.NewEquals(i, x))))
+	require.Equal(0.0, e.rows(plan.NewAntiJoin(rt, a, expression.NewEquals(i, x))))
+	one := plan.NewFilter(expression.NewEquals(i, expression.NewLiteral(int64(1), sql.Int64)), rt)
+	semi, anti := e.rows(plan.NewSemiJoin(a, one, expression.NewEquals(i, x))), e.rows(plan.NewAntiJoin(a, one, expression.NewEquals(i, x)))
+	require.True(semi < e.rows(a))
+	require.Equal(e.rows(a), semi+anti)
+
+	// Looking up a row of the primary key beats a scan, but lookups of a value all rows have don't
+	require.Equal(1.0, e.lookupRows(rt, pkIdx, 1))
+	require.True(e.lookupCost(rt, pkIdx, 1) < e.scanCost(rt))
+	require.Equal(10.0, e.lookupRows(rt, cIdx, 1))
+	require.True(e.lookupCost(rt, cIdx, 1) > e.scanCost(rt))
+
+	// Hashing the rows of a table beats reading them for every one of many rows, but not for a single one
+	keys := []sql.Expression{expression.NewEquals(i, expression.NewGetFieldWithTable(2, sql.Int64, "a", "x", false))}
+	require.True(e.hashJoinCost(defaultRowCount, rt, keys) < defaultRowCount*e.scanCost(rt))
+	require.True(e.hashJoinCost(1, rt, keys) > e.scanCost(rt))
+
+	// Histograms estimate comparisons with values, and only have the rows with values
+	require.Equal(defaultSelectivity, e.conditionSelectivity(expression.NewLessThan(i, expression.NewLiteral(int64(3), sql.Int64))))
+	require.NoError(table.Analyze(ctx))
+	e = newCostEstimator(ctx, NewDefault(sql.NewCatalog()), n)
+	three := expression.NewLiteral(int64(3), sql.Int64)
+	require.Equal(0.3, e.conditionSelectivity(expression.NewLessThan(i, three)))
+	require.Equal(0.6, e.conditionSelectivity(expression.NewLessThan(three, i)))
+	require.Equal(0.8, e.conditionSelectivity(expression.NewEquals(c, expression.NewLiteral("same", sql.LongText))))
+	require.Equal(0.0, e.conditionSelectivity(expression.NewEquals(c, expression.NewLiteral("other", sql.LongText))))
+	require.Equal(0.2, e.conditionSelectivity(expression.NewIsNull(c)))
+	require.Equal(0.0, e.conditionSelectivity(expression.NewEquals(i, expression.NewLiteral(nil, sql.Null))))
+	require.InDelta(0.2, e.conditionSelectivity(expression.NewInTuple(i, expression.NewTuple(
+		expression.NewLiteral(int64(1), sql.Int64),
+		expression.NewLiteral(int64(20), sql.Int64),
+		expression.NewLiteral(int64(2), sql.Int64),
+	))), 1e-9)
+	require.InDelta(0.5, e.conditionSelectivity(expression.NewBetween(i, three, expression.NewLiteral(int64(7), sql.Int64))), 1e-9)
+
+	// Lookups of most rows cost more than a scan
+	cost, ok := e.filterLookupCost(rt, []sql.Expression{expression.NewLessThan(i, expression.NewLiteral(int64(8), sql.Int64))}, []sql.Index{pkIdx})
+	require.True(ok)
+	require.True(cost > e.scanCost(rt))
+	cost, ok = e.filterLookupCost(rt, []sql.Expression{expression.NewLessThan(i, three)}, []sql.Index{pkIdx})
+	require.True(ok)
+	require.True(cost < e.scanCost(rt))
+	_, ok = e.filterLookupCost(rt, []sql.Expression{expression.NewEquals(c, expression.NewLiteral("same", sql.LongText))}, []sql.Index{pkIdx})
+	require.False(ok)
+}
+
+func TestCostModel(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true},
+	})
+	table.EnablePrimaryKeyIndexes()
+	for i := 0; i < 10; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(int64(i))))
+	}
+	idxes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+
+	rt := plan.NewResolvedTable(table)
+	remote := plan.NewResolvedTable(remoteTable{memory.NewTable("r", nil)})
+	a := NewDefault(sql.NewCatalog())
+	e := newCostEstimator(ctx, a, rt)
+
+	// Rows of remote tables cost more, since they're sent over the network
+	require.Equal(11.0, e.scanCost(rt))
+	require.Equal(1.0+defaultRowCount*3, e.scanCost(remote))
+
+	// Joins read their secondary node for every row of their primary node, unless they hash it
+	join := plan.NewCrossJoin(rt, rt)
+	require.Equal(11.0+10*11.0, e.cost(join))
+	require.Equal(&plan.CostEstimate{Cost: 121, Rows: 100, Children: []*plan.CostEstimate{
+		{Cost: 11, Rows: 10},
+		{Cost: 11, Rows: 10},
+	}}, e.estimate(join))
+
+	// The costs of the analyzer's cost model are used instead of the default ones
+	a.CostModel = &CostModel{AccessCost: 1, ScanRowCost: 1, LookupRowCost: 20, HashBuildRowCost: 1}
+	e = newCostEstimator(ctx, a, rt)
+	require.Equal(21.0, e.lookupCost(rt, idxes[0], 1))
+	require.True(e.lookupCost(rt, idxes[0], 1) > e.scanCost(rt))
+	require.Equal(1.0+defaultRowCount, e.scanCost(remote))
+}
+
+// remoteTable is a table whose rows are read from a remote server.
+type remoteTable struct {
+	sql.Table
+}
+
+func (remoteTable) IsRemote() bool {
+	return true
+}
+
+// noStatisticsTable hides the statistics of the table it wraps.
+type noStatisticsTable struct {
+	sql.Table
+}
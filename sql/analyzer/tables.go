@@ -41,6 +41,25 @@ func getTableName(node sql.Node) string {
 	return tableName
 }
 
+// getDatabaseName returns the name of the database of the first table in the node given, or an empty string if the
+// database of the table isn't known.
+func getDatabaseName(node sql.Node) string {
+	var dbName string
+	plan.Inspect(node, func(node sql.Node) bool {
+		switch node := node.(type) {
+		case *plan.ResolvedTable:
+			dbName = node.Database
+			return false
+		case *plan.UnresolvedTable:
+			dbName = node.Database
+			return false
+		}
+		return true
+	})
+
+	return dbName
+}
+
 // Returns the underlying table name for the node given, ignoring table aliases
 func getUnaliasedTableName(node sql.Node) string {
 	var tableName string
@@ -131,13 +150,13 @@ func withTable(node sql.Node, table sql.Table) (sql.Node, error) {
 				return nil, ErrInAnalysis.New("attempted to set more than one table in withTable()")
 			}
 			foundTable = true
-			return plan.NewResolvedTable(table), nil
+			return plan.NewResolvedTableInDatabase(table, n.Database), nil
 		case *plan.IndexedTableAccess:
 			if foundTable {
 				return nil, ErrInAnalysis.New("attempted to set more than one table in withTable()")
 			}
 			foundTable = true
-			return plan.NewIndexedTable(plan.NewResolvedTable(table)), nil
+			return plan.NewIndexedTable(plan.NewResolvedTableInDatabase(table, n.Database)), nil
 		default:
 			return n, nil
 		}
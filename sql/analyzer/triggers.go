@@ -118,17 +118,21 @@ func applyTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 	}
 
 	var affectedTables []string
+	var affectedDb string
 	var triggerEvent plan.TriggerEvent
 	plan.Inspect(n, func(n sql.Node) bool {
 		switch n := n.(type) {
 		case *plan.InsertInto:
 			affectedTables = append(affectedTables, getTableName(n))
+			affectedDb = getDatabaseName(n)
 			triggerEvent = plan.InsertTrigger
 		case *plan.Update:
 			affectedTables = append(affectedTables, getTableName(n))
+			affectedDb = getDatabaseName(n)
 			triggerEvent = plan.UpdateTrigger
 		case *plan.DeleteFrom:
 			affectedTables = append(affectedTables, getTableName(n))
+			affectedDb = getDatabaseName(n)
 			triggerEvent = plan.DeleteTrigger
 		}
 		return true
@@ -138,10 +142,11 @@ func applyTriggers(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql
 		return n, nil
 	}
 
-	// TODO: database should be dependent on the table being inserted / updated, but we don't have that info available
-	//  from the table object yet.
-	db := ctx.GetCurrentDatabase()
-	database, err := a.Catalog.Database(db)
+	db := affectedDb
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+	database, err := a.Catalog.SessionDatabase(ctx, db)
 	if err != nil {
 		return nil, err
 	}
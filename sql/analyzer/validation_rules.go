@@ -23,6 +23,7 @@ const (
 	validateCaseResultTypesRule   = "validate_case_result_types"
 	validateIntervalUsageRule     = "validate_interval_usage"
 	validateExplodeUsageRule      = "validate_explode_usage"
+	validateInsertValueUsageRule  = "validate_insert_value_usage"
 	validateSubqueryColumnsRule   = "validate_subquery_columns"
 	validateUnionSchemasMatchRule = "validate_union_schemas_match"
 )
@@ -63,6 +64,12 @@ var (
 		"using EXPLODE is not supported outside a Project node",
 	)
 
+	// ErrInsertValueInvalidUse is returned when the VALUES function is used outside the ON DUPLICATE KEY UPDATE clause
+	// of INSERT.
+	ErrInsertValueInvalidUse = errors.NewKind(
+		"VALUES can only be used in the ON DUPLICATE KEY UPDATE clause of INSERT",
+	)
+
 	// ErrSubqueryMultipleColumns is returned when an expression subquery returns
 	// more than a single column.
 	ErrSubqueryMultipleColumns = errors.NewKind(
@@ -93,6 +100,7 @@ var DefaultValidationRules = []Rule{
 	{validateCaseResultTypesRule, validateCaseResultTypes},
 	{validateIntervalUsageRule, validateIntervalUsage},
 	{validateExplodeUsageRule, validateExplodeUsage},
+	{validateInsertValueUsageRule, validateInsertValueUsage},
 	{validateSubqueryColumnsRule, validateSubqueryColumns},
 	{validateUnionSchemasMatchRule, validateUnionSchemasMatch},
 }
@@ -410,11 +418,36 @@ func validateExplodeUsage(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scop
 	return n, nil
 }
 
+func validateInsertValueUsage(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
+	var invalid bool
+	plan.InspectExpressionsWithNode(n, func(node sql.Node, e sql.Expression) bool {
+		// The expressions of inserts are the ones of their ON DUPLICATE KEY UPDATE clause
+		if _, ok := node.(*plan.InsertInto); ok || invalid {
+			return false
+		}
+		if _, ok := e.(*expression.InsertValue); ok {
+			invalid = true
+		}
+		return true
+	})
+
+	if invalid {
+		return nil, ErrInsertValueInvalidUse.New()
+	}
+
+	return n, nil
+}
+
 func validateSubqueryColumns(ctx *sql.Context, a *Analyzer, n sql.Node, scope *Scope) (sql.Node, error) {
 
-	// First validate that every subquery expression returns a single column
+	// First validate that every subquery expression returns a single column. The columns of EXISTS subqueries don't
+	// matter, since only whether they return any row does.
 	valid := true
 	plan.InspectExpressions(n, func(e sql.Expression) bool {
+		if _, ok := e.(*plan.ExistsSubquery); ok {
+			return false
+		}
+
 		s, ok := e.(*plan.Subquery)
 		if ok && len(s.Query.Schema()) != 1 {
 			valid = false
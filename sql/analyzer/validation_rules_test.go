@@ -821,6 +821,62 @@ func TestValidateExplodeUsage(t *testing.T) {
 	}
 }
 
+func TestValidateInsertValueUsage(t *testing.T) {
+	value := expression.NewInsertValue(expression.NewGetField(1, sql.Text, "s", false))
+	values := plan.NewValues([][]sql.Expression{{
+		expression.NewLiteral(int64(1), sql.Int64),
+		expression.NewLiteral("a", sql.LongText),
+	}})
+
+	testCases := []struct {
+		name string
+		node sql.Node
+		ok   bool
+	}{
+		{
+			"on duplicate key update",
+			plan.NewInsertInto(
+				plan.NewUnresolvedTable("t", ""),
+				values,
+				false,
+				[]string{"i", "s"},
+				[]sql.Expression{expression.NewSetField(expression.NewGetField(1, sql.Text, "s", false), value)},
+			),
+			true,
+		},
+		{
+			"inserted values",
+			plan.NewInsertInto(
+				plan.NewUnresolvedTable("t", ""),
+				plan.NewProject([]sql.Expression{value}, plan.NewUnresolvedTable("u", "")),
+				false,
+				[]string{"s"},
+				nil,
+			),
+			false,
+		},
+		{
+			"select",
+			plan.NewProject([]sql.Expression{value}, plan.NewUnresolvedTable("u", "")),
+			false,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			_, err := validateInsertValueUsage(sql.NewEmptyContext(), nil, tt.node, nil)
+			if tt.ok {
+				require.NoError(err)
+			} else {
+				require.Error(err)
+				require.True(ErrInsertValueInvalidUse.Is(err))
+			}
+		})
+	}
+}
+
 func TestValidateSubqueryColumns(t *testing.T) {
 	require := require.New(t)
 	ctx := sql.NewEmptyContext()
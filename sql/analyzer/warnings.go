@@ -12,8 +12,13 @@ func clearWarnings(ctx *sql.Context, a *Analyzer, node sql.Node, scope *Scope) (
 	}
 
 	switch ch := children[0].(type) {
-	case plan.ShowWarnings:
+	case plan.ShowWarnings, *plan.GetDiagnostics:
 		return node, nil
+	case *plan.Signal:
+		// RESIGNAL passes on the last condition
+		if ch.Resignal {
+			return node, nil
+		}
 	case *plan.Offset:
 		clearWarnings(ctx, a, ch, scope)
 		return node, nil
@@ -0,0 +1,75 @@
+package sql
+
+import (
+	"io"
+)
+
+// BatchSize is the number of rows in the batches requested by iterators that read their children in batches.
+const BatchSize = 256
+
+// BatchRowIter is a RowIter that can also return its rows in batches. Reading a batch at a time amortizes the cost
+// of going through the iterators of a tree for every row, which dominates simple scans over large tables. Next and
+// NextBatch read from the same rows, so a row returned by either won't be returned again.
+type BatchRowIter interface {
+	RowIter
+	// NextBatch fills rows with the next rows of the iterator and returns how many were filled, which may be fewer
+	// than len(rows) even if it's not the last batch. It returns io.EOF once there are no more rows. No rows are
+	// filled when an error is returned.
+	NextBatch(rows []Row) (int, error)
+}
+
+// NewBatchRowIter returns a BatchRowIter over the rows of iter. If iter doesn't support batches, its batches are
+// read a row at a time.
+func NewBatchRowIter(iter RowIter) BatchRowIter {
+	if b, ok := iter.(BatchRowIter); ok {
+		return b
+	}
+	return &rowAtATimeIter{RowIter: iter}
+}
+
+type rowAtATimeIter struct {
+	RowIter
+	done bool
+}
+
+func (i *rowAtATimeIter) NextBatch(rows []Row) (int, error) {
+	if i.done {
+		return 0, io.EOF
+	}
+
+	for n := range rows {
+		row, err := i.Next()
+		if err == io.EOF {
+			i.done = true
+			if n > 0 {
+				return n, nil
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		rows[n] = row
+	}
+
+	return len(rows), nil
+}
+
+// BatchRowIterToRows converts a batch row iterator to a slice of rows.
+func BatchRowIterToRows(i BatchRowIter) ([]Row, error) {
+	var rows []Row
+	batch := make([]Row, BatchSize)
+	for {
+		n, err := i.NextBatch(batch)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, batch[:n]...)
+	}
+
+	return rows, i.Close()
+}
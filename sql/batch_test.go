@@ -0,0 +1,51 @@
+package sql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBatchRowIter(t *testing.T) {
+	require := require.New(t)
+
+	iter := NewBatchRowIter(RowsToRowIter(NewRow(1), NewRow(2), NewRow(3)))
+
+	batch := make([]Row, 2)
+	n, err := iter.NextBatch(batch)
+	require.NoError(err)
+	require.Equal([]Row{NewRow(1), NewRow(2)}, batch[:n])
+
+	row, err := iter.Next()
+	require.NoError(err)
+	require.Equal(NewRow(3), row)
+
+	n, err = iter.NextBatch(batch)
+	require.Equal(io.EOF, err)
+	require.Equal(0, n)
+
+	n, err = iter.NextBatch(batch)
+	require.Equal(io.EOF, err)
+	require.Equal(0, n)
+
+	require.NoError(iter.Close())
+	require.Equal(iter, NewBatchRowIter(iter))
+}
+
+func TestBatchRowIterToRows(t *testing.T) {
+	require := require.New(t)
+
+	var expected []Row
+	for i := 0; i < BatchSize*2+3; i++ {
+		expected = append(expected, NewRow(i))
+	}
+
+	rows, err := RowIterToRows(NewBatchRowIter(RowsToRowIter(expected...)))
+	require.NoError(err)
+	require.Equal(expected, rows)
+
+	rows, err = BatchRowIterToRows(NewBatchRowIter(RowsToRowIter()))
+	require.NoError(err)
+	require.Empty(rows)
+}
@@ -0,0 +1,23 @@
+package sql
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// BinaryLiteral is the value of a hexadecimal literal, such as X'41' or 0x41, or of a bit-value literal, such as
+// b'1000001'. These literals are binary strings, except in numeric contexts, where they are the unsigned integer of
+// their bytes in big-endian order. Number types convert them to that integer and string types to their bytes.
+type BinaryLiteral []byte
+
+// Uint64 returns the value of the literal in numeric contexts. Literals longer than 8 bytes saturate to the maximum
+// uint64, as they do in MySQL.
+func (b BinaryLiteral) Uint64() uint64 {
+	for len(b) > 8 {
+		if b[0] != 0 {
+			return math.MaxUint64
+		}
+		b = b[1:]
+	}
+	return binary.BigEndian.Uint64(append(make([]byte, 8-len(b)), b...))
+}
@@ -0,0 +1,41 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryLiteral(t *testing.T) {
+	testCases := []struct {
+		val      BinaryLiteral
+		expected uint64
+	}{
+		{BinaryLiteral{}, 0},
+		{BinaryLiteral{0x41}, 65},
+		{BinaryLiteral{0x01, 0xAF}, 431},
+		{BinaryLiteral{0, 0, 0x01, 0, 0, 0, 0, 0, 0, 0}, 1 << 56},
+		{BinaryLiteral{0x01, 0, 0, 0, 0, 0, 0, 0, 0}, math.MaxUint64},
+	}
+
+	for _, tt := range testCases {
+		t.Run(fmt.Sprintf("%X", []byte(tt.val)), func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.val.Uint64())
+		})
+	}
+}
+
+func TestBinaryLiteralConvert(t *testing.T) {
+	require := require.New(t)
+	val := BinaryLiteral("AB")
+
+	require.Equal(uint64(16706), Uint64.MustConvert(val))
+	require.Equal(int64(16706), Int64.MustConvert(val))
+	require.Equal(float64(16706), Float64.MustConvert(val))
+	require.Equal("16706", MustCreateDecimalType(10, 0).MustConvert(val))
+	require.Equal(uint64(16706), MustCreateBitType(16).MustConvert(val))
+	require.Equal("AB", LongText.MustConvert(val))
+	require.Equal("AB", LongBlob.MustConvert(val))
+}
@@ -117,6 +117,8 @@ func (t bitType) Convert(v interface{}) (interface{}, error) {
 		value = uint64(val)
 	case string:
 		return t.Convert([]byte(val))
+	case BinaryLiteral:
+		return t.Convert([]byte(val))
 	case []byte:
 		if len(val) > 8 {
 			return nil, fmt.Errorf("%v is beyond the maximum value that can be held by %v bits", value, t.numOfBits)
@@ -0,0 +1,54 @@
+package sql
+
+// Interrupted returns the error of the context if it's done, which it is once its query has been killed, it has timed
+// out or its client has disconnected, or nil otherwise. It's cheap enough to be called for every row, so iterators
+// that may go through many rows, or do a lot of work, before returning one check it in their loops, and stop with its
+// error as soon as it isn't nil. See NewCancelableRowIter.
+func (c *Context) Interrupted() error {
+	select {
+	case <-c.Done():
+		return c.Err()
+	default:
+		return nil
+	}
+}
+
+// NewCancelableRowIter returns an iterator over the rows of the iterator given that fails with the error of the
+// context given once it's interrupted, before reading any more rows from it. Rows are read in batches from the iterator
+// given if it's a BatchRowIter.
+func NewCancelableRowIter(ctx *Context, iter RowIter) RowIter {
+	if _, ok := iter.(*cancelableIter); ok {
+		return iter
+	}
+	return &cancelableIter{ctx: ctx, iter: iter}
+}
+
+type cancelableIter struct {
+	ctx   *Context
+	iter  RowIter
+	batch BatchRowIter
+}
+
+var _ BatchRowIter = (*cancelableIter)(nil)
+
+func (i *cancelableIter) Next() (Row, error) {
+	if err := i.ctx.Interrupted(); err != nil {
+		return nil, err
+	}
+	return i.iter.Next()
+}
+
+// NextBatch implements the BatchRowIter interface.
+func (i *cancelableIter) NextBatch(rows []Row) (int, error) {
+	if err := i.ctx.Interrupted(); err != nil {
+		return 0, err
+	}
+	if i.batch == nil {
+		i.batch = NewBatchRowIter(i.iter)
+	}
+	return i.batch.NextBatch(rows)
+}
+
+func (i *cancelableIter) Close() error {
+	return i.iter.Close()
+}
@@ -0,0 +1,37 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelableRowIter(t *testing.T) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sqlCtx := NewContext(ctx)
+	require.NoError(sqlCtx.Interrupted())
+
+	iter := NewCancelableRowIter(sqlCtx, RowsToRowIter(NewRow(1), NewRow(2), NewRow(3)))
+	require.Equal(iter, NewCancelableRowIter(sqlCtx, iter))
+
+	row, err := iter.Next()
+	require.NoError(err)
+	require.Equal(NewRow(1), row)
+
+	batch := make([]Row, 1)
+	n, err := iter.(BatchRowIter).NextBatch(batch)
+	require.NoError(err)
+	require.Equal([]Row{NewRow(2)}, batch[:n])
+
+	cancel()
+	require.Equal(context.Canceled, sqlCtx.Interrupted())
+
+	_, err = iter.Next()
+	require.Equal(context.Canceled, err)
+	_, err = iter.(BatchRowIter).NextBatch(batch)
+	require.Equal(context.Canceled, err)
+	require.NoError(iter.Close())
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
 
 	"github.com/dolthub/go-mysql-server/internal/similartext"
 
@@ -23,15 +26,64 @@ var ErrAsOfNotSupported = errors.NewKind("AS OF not supported for database %s")
 // expression with a view when the view definition has its own AS OF expressions.
 var ErrIncompatibleAsOf = errors.NewKind("incompatible use of AS OF: %s")
 
-// Catalog holds databases, tables and functions.
+// Catalog holds databases, tables, functions, table functions, row policies, column masks, column privileges,
+// resource groups, plan baselines and schema versions.
 type Catalog struct {
 	FunctionRegistry
+	TableFunctionRegistry
+	*RowPolicyRegistry
+	*ColumnMaskRegistry
+	*ColumnPrivilegeRegistry
+	*ResourceGroupRegistry
+	*PlanBaselineRegistry
 	*ProcessList
 	*MemoryManager
+	*TableLockManager
+	*WaitsForGraph
+	*SchemaVersionRegistry
+	// TwoPhaseCommitter commits the changes of each session to the TwoPhaseCommitDatabases it writes together, and
+	// keeps the XA transactions.
+	TwoPhaseCommitter *TwoPhaseCommitter
+	// GlobalVariables are the global values of the system variables, which SET GLOBAL sets for the engine of the
+	// catalog only.
+	GlobalVariables *GlobalVariables
+
+	provider DatabaseProvider
+	// sessionDatabases caches the databases resolved by the provider for each session. Its fills and invalidations
+	// are guarded by sessionDatabasesMu, and invalidations counts the invalidations, so a database resolved before
+	// an invalidation is never cached after it.
+	sessionDatabases   *lru.Cache
+	sessionDatabasesMu sync.Mutex
+	invalidations      uint64
+	// caseSensitiveNames is 1 if the names of databases, tables and columns are case sensitive.
+	caseSensitiveNames int32
+
+	mu        sync.RWMutex
+	locks     sessionLocks
+	listeners []CatalogChangeListener
+	// readOnlyAuthorizer is the authorizer of the users who can modify databases in read_only mode.
+	readOnlyAuthorizer ReadOnlyAuthorizer
+	// accounts lists the accounts of the users of the server.
+	accounts AccountLister
+}
+
+// sessionDatabaseCacheSize is the number of databases the catalog caches for all sessions.
+const sessionDatabaseCacheSize = 4096
+
+// sessionDatabaseKey is the key of a database cached for a session.
+type sessionDatabaseKey struct {
+	session uint32
+	name    string
+}
 
-	mu    sync.RWMutex
-	dbs   Databases
-	locks sessionLocks
+// CatalogChangeListener is notified whenever a database is added to or removed from a catalog, so it can include the
+// database in, or invalidate anything it keeps that references, the database. Listeners are called after the change,
+// and may be called concurrently with queries.
+type CatalogChangeListener interface {
+	// DatabaseAdded is called after the database given has been added to the catalog.
+	DatabaseAdded(db Database)
+	// DatabaseRemoved is called after the database given has been removed from the catalog.
+	DatabaseRemoved(db Database)
 }
 
 type tableLocks map[string]struct{}
@@ -40,61 +92,273 @@ type dbLocks map[string]tableLocks
 
 type sessionLocks map[uint32]dbLocks
 
-// NewCatalog returns a new empty Catalog.
+// NewCatalog returns a new empty Catalog, whose databases are kept in memory.
 func NewCatalog() *Catalog {
+	return NewCatalogWithProvider(NewDatabaseProvider())
+}
+
+// NewCatalogWithProvider returns a new Catalog whose databases are resolved by the provider given. Databases can only
+// be added to and removed from the catalog if the provider is a MutableDatabaseProvider.
+func NewCatalogWithProvider(provider DatabaseProvider) *Catalog {
+	cache, err := lru.New(sessionDatabaseCacheSize)
+	if err != nil {
+		panic(err)
+	}
+
+	graph := NewWaitsForGraph()
 	return &Catalog{
-		FunctionRegistry: NewFunctionRegistry(),
-		MemoryManager:    NewMemoryManager(ProcessMemory),
-		ProcessList:      NewProcessList(),
-		locks:            make(sessionLocks),
+		FunctionRegistry:        NewFunctionRegistry(),
+		TableFunctionRegistry:   NewTableFunctionRegistry(),
+		RowPolicyRegistry:       NewRowPolicyRegistry(),
+		ColumnMaskRegistry:      NewColumnMaskRegistry(),
+		ColumnPrivilegeRegistry: NewColumnPrivilegeRegistry(),
+		ResourceGroupRegistry:   NewResourceGroupRegistry(0),
+		PlanBaselineRegistry:    NewPlanBaselineRegistry(),
+		MemoryManager:           NewMemoryManager(ProcessMemory),
+		ProcessList:             NewProcessList(),
+		TableLockManager:        NewTableLockManager(graph),
+		WaitsForGraph:           graph,
+		SchemaVersionRegistry:   NewSchemaVersionRegistry(),
+		TwoPhaseCommitter:       NewTwoPhaseCommitter(),
+		GlobalVariables:         NewGlobalVariables(),
+		provider:                provider,
+		sessionDatabases:        cache,
+		locks:                   make(sessionLocks),
 	}
 }
 
+// Provider returns the DatabaseProvider of the catalog.
+func (c *Catalog) Provider() DatabaseProvider {
+	return c.provider
+}
+
 // AllDatabases returns all databases in the catalog.
 func (c *Catalog) AllDatabases() Databases {
+	return Databases(c.provider.AllDatabases())
+}
+
+// AddDatabase adds a new database to the catalog. Databases can be added while queries are running, and are
+// available to the queries analyzed after this call. It returns ErrImmutableDatabaseProvider if the provider of the
+// catalog isn't a MutableDatabaseProvider.
+func (c *Catalog) AddDatabase(db Database) error {
+	provider, ok := c.provider.(MutableDatabaseProvider)
+	if !ok {
+		return ErrImmutableDatabaseProvider.New()
+	}
+
+	provider.AddDatabase(db)
+	c.InvalidateDatabase(db.Name())
+	c.ResetSchemaVersion(db.Name())
+
 	c.mu.RLock()
-	defer c.mu.RUnlock()
+	listeners := c.listeners
+	c.mu.RUnlock()
 
-	var result = make(Databases, len(c.dbs))
-	copy(result, c.dbs)
-	return result
+	for _, l := range listeners {
+		l.DatabaseAdded(db)
+	}
+	return nil
 }
 
-// AddDatabase adds a new database to the catalog.
-func (c *Catalog) AddDatabase(db Database) {
+// RemoveDatabase removes the database with the name given from the catalog, along with the table locks held on its
+// tables. Databases can be removed while queries are running: queries already running keep reading the tables they
+// resolved, and the queries analyzed after this call can't find the database. It returns
+// ErrImmutableDatabaseProvider if the provider of the catalog isn't a MutableDatabaseProvider.
+func (c *Catalog) RemoveDatabase(name string) error {
+	provider, ok := c.provider.(MutableDatabaseProvider)
+	if !ok {
+		return ErrImmutableDatabaseProvider.New()
+	}
+
+	db, err := provider.Database(name)
+	if err != nil {
+		return err
+	}
+
+	if err := provider.DropDatabase(db.Name()); err != nil {
+		return err
+	}
+	c.InvalidateDatabase(db.Name())
+	c.ResetSchemaVersion(db.Name())
+
 	c.mu.Lock()
-	c.dbs.Add(db)
+	for _, locks := range c.locks {
+		for lockedDB := range locks {
+			if strings.EqualFold(lockedDB, db.Name()) {
+				delete(locks, lockedDB)
+			}
+		}
+	}
+	listeners := c.listeners
 	c.mu.Unlock()
+
+	for _, l := range listeners {
+		l.DatabaseRemoved(db)
+	}
+	return nil
+}
+
+// InvalidateDatabase removes the database with the name given from the databases cached for every session, so the
+// next use of the database resolves it again from the provider. Providers whose databases are added, removed or
+// replaced other than through the catalog must call it when they change a database.
+func (c *Catalog) InvalidateDatabase(name string) {
+	c.sessionDatabasesMu.Lock()
+	defer c.sessionDatabasesMu.Unlock()
+
+	c.invalidations++
+	name = strings.ToLower(name)
+	for _, k := range c.sessionDatabases.Keys() {
+		if k.(sessionDatabaseKey).name == name {
+			c.sessionDatabases.Remove(k)
+		}
+	}
+}
+
+// AddCatalogChangeListener registers a listener to be notified of every database added to or removed from the
+// catalog.
+func (c *Catalog) AddCatalogChangeListener(listener CatalogChangeListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, listener)
+}
+
+// LowerCaseTableNamesSessionVar is the session variable reporting the case sensitivity of names, with the values of
+// MySQL's lower_case_table_names. The engine sets it to the value of Catalog.LowerCaseTableNames.
+const LowerCaseTableNamesSessionVar = "lower_case_table_names"
+
+// InformationSchemaDatabaseName is the name of the information_schema database. Its name and the names of its tables
+// and columns are case insensitive even if names are case sensitive, as in MySQL.
+const InformationSchemaDatabaseName = "information_schema"
+
+// SetCaseSensitiveNames sets whether the names of databases, tables and columns used by queries must match the
+// case of their names, like MySQL with lower_case_table_names set to 0, or are case insensitive, which is the default.
+// Names are always stored with the case they're created with in both modes, and the names of views, and of
+// information_schema and its tables, are always case insensitive. It must be set before the catalog is used by queries.
+func (c *Catalog) SetCaseSensitiveNames(sensitive bool) {
+	var v int32
+	if sensitive {
+		v = 1
+	}
+	atomic.StoreInt32(&c.caseSensitiveNames, v)
+}
+
+// CaseSensitiveNames returns whether the names of databases, tables and columns are case sensitive.
+func (c *Catalog) CaseSensitiveNames() bool {
+	return atomic.LoadInt32(&c.caseSensitiveNames) == 1
+}
+
+// LowerCaseTableNames returns the value of the lower_case_table_names system variable for the case sensitivity of
+// the names of the catalog: 0 if names are case sensitive, or 2 if names keep their case but are case insensitive.
+func (c *Catalog) LowerCaseTableNames() int32 {
+	if c.CaseSensitiveNames() {
+		return 0
+	}
+	return 2
+}
+
+// NameMatches returns whether the name of a database, table or column, as it was created, is matched by the
+// name used by a query, which must be the same name, ignoring case unless names are case sensitive.
+func (c *Catalog) NameMatches(name, queried string) bool {
+	if c.CaseSensitiveNames() {
+		return name == queried
+	}
+	return strings.EqualFold(name, queried)
 }
 
+// exactDatabase returns the database given if its name matches the name queried, or ErrDatabaseNotFound otherwise.
+func (c *Catalog) exactDatabase(db Database, queried string) (Database, error) {
+	if strings.EqualFold(queried, InformationSchemaDatabaseName) || c.NameMatches(db.Name(), queried) {
+		return db, nil
+	}
+	return nil, ErrDatabaseNotFound.New(queried)
+}
+
+// exactTable returns the table given, of the database named, if its name matches the name queried, or
+// ErrTableNotFound otherwise.
+func (c *Catalog) exactTable(db string, table Table, queried string) (Table, error) {
+	if strings.EqualFold(db, InformationSchemaDatabaseName) || c.NameMatches(table.Name(), queried) {
+		return table, nil
+	}
+	return nil, ErrTableNotFound.New(queried)
+}
+
+// HasDB returns whether the database with the given name exists.
 func (c *Catalog) HasDB(db string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	_, err := c.dbs.Database(db)
+	if !c.CaseSensitiveNames() {
+		return c.provider.HasDatabase(db)
+	}
 
+	_, err := c.Database(db)
 	return err == nil
 }
 
 // Database returns the database with the given name.
 func (c *Catalog) Database(db string) (Database, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.dbs.Database(db)
+	database, err := c.provider.Database(db)
+	if err != nil {
+		return nil, err
+	}
+	return c.exactDatabase(database, db)
+}
+
+// SessionDatabase returns the database with the given name for the session of the context given, which caches the
+// database so the provider only resolves it the first time the session uses it.
+func (c *Catalog) SessionDatabase(ctx *Context, db string) (Database, error) {
+	if ctx == nil || ctx.Session == nil {
+		return c.provider.Database(db)
+	}
+
+	key := sessionDatabaseKey{session: ctx.ID(), name: strings.ToLower(db)}
+	if cached, ok := c.sessionDatabases.Get(key); ok {
+		return c.exactDatabase(cached.(Database), db)
+	}
+
+	c.sessionDatabasesMu.Lock()
+	invalidations := c.invalidations
+	c.sessionDatabasesMu.Unlock()
+
+	database, err := c.provider.Database(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// The database isn't cached if it was removed or replaced while it was resolved
+	c.sessionDatabasesMu.Lock()
+	if c.invalidations == invalidations {
+		c.sessionDatabases.Add(key, database)
+	}
+	c.sessionDatabasesMu.Unlock()
+	return c.exactDatabase(database, db)
 }
 
 // Table returns the table in the given database with the given name.
 func (c *Catalog) Table(ctx *Context, db, table string) (Table, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.dbs.Table(ctx, db, table)
+	database, err := c.SessionDatabase(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := databaseTable(ctx, database, table)
+	if err != nil {
+		return nil, err
+	}
+	return c.exactTable(db, t, table)
 }
 
 // TableAsOf returns the table in the given database with the given name, as it existed at the time given. The database
 // named must support timed queries.
 func (c *Catalog) TableAsOf(ctx *Context, db, table string, time interface{}) (Table, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.dbs.TableAsOf(ctx, db, table, time)
+	database, err := c.SessionDatabase(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := databaseTableAsOf(ctx, database, table, time)
+	if err != nil {
+		return nil, err
+	}
+	return c.exactTable(db, t, table)
 }
 
 // Databases is a collection of Database.
@@ -129,7 +393,10 @@ func (d Databases) Table(ctx *Context, dbName string, tableName string) (Table,
 	if err != nil {
 		return nil, err
 	}
+	return databaseTable(ctx, db, tableName)
+}
 
+func databaseTable(ctx *Context, db Database, tableName string) (Table, error) {
 	tbl, ok, err := db.GetTableInsensitive(ctx, tableName)
 
 	if err != nil {
@@ -158,10 +425,13 @@ func (d Databases) TableAsOf(ctx *Context, dbName string, tableName string, asOf
 	if err != nil {
 		return nil, err
 	}
+	return databaseTableAsOf(ctx, db, tableName, asOf)
+}
 
+func databaseTableAsOf(ctx *Context, db Database, tableName string, asOf interface{}) (Table, error) {
 	versionedDb, ok := db.(VersionedDatabase)
 	if !ok {
-		return nil, ErrAsOfNotSupported.New(tableName)
+		return versionedTableAsOf(ctx, db, tableName, asOf)
 	}
 
 	tbl, ok, err := versionedDb.GetTableInsensitiveAsOf(ctx, tableName, asOf)
@@ -175,6 +445,28 @@ func (d Databases) TableAsOf(ctx *Context, dbName string, tableName string, asOf
 	return tbl, nil
 }
 
+// versionedTableAsOf returns the table of a database that isn't versioned as it was at the time given, if the table
+// is a VersionedTable.
+func versionedTableAsOf(ctx *Context, db Database, tableName string, asOf interface{}) (Table, error) {
+	tbl, err := databaseTable(ctx, db, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	versionedTbl, ok := tbl.(VersionedTable)
+	if !ok {
+		return nil, ErrAsOfNotSupported.New(db.Name())
+	}
+
+	tbl, ok, err = versionedTbl.AsOf(ctx, asOf)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrTableNotFound.New(tableName)
+	}
+	return tbl, nil
+}
+
 func suggestSimilarTablesAsOf(db VersionedDatabase, ctx *Context, tableName string, time interface{}) error {
 	tableNames, err := db.GetTableNamesAsOf(ctx, time)
 	if err != nil {
@@ -206,15 +498,17 @@ func (c *Catalog) LockTable(ctx *Context, table string) {
 }
 
 // UnlockTables unlocks all tables for which the given session client has a
-// lock.
+// lock, and releases the locks it acquired with LOCK TABLES.
 func (c *Catalog) UnlockTables(ctx *Context, id uint32) error {
+	c.ReleaseTableLocks(id, true)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	var errors []string
 	for db, tables := range c.locks[id] {
 		for t := range tables {
-			table, err := c.dbs.Table(ctx, db, t)
+			table, err := c.Table(ctx, db, t)
 			if err == nil {
 				if lockable, ok := table.(Lockable); ok {
 					if e := lockable.Unlock(ctx, id); e != nil {
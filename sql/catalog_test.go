@@ -2,6 +2,8 @@ package sql_test
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -103,6 +105,184 @@ func TestCatalogUnlockTables(t *testing.T) {
 	require.Equal(1, t2.unlocks)
 }
 
+func TestCatalogRemoveDatabase(t *testing.T) {
+	require := require.New(t)
+
+	a := memory.NewDatabase("a")
+	b := memory.NewDatabase("b")
+	b.AddTable("t", memory.NewTable("t", nil))
+
+	c := sql.NewCatalog()
+	listener := new(catalogListener)
+	c.AddCatalogChangeListener(listener)
+	c.AddDatabase(a)
+	c.AddDatabase(b)
+
+	ctx := sql.NewContext(context.Background())
+	ctx.SetCurrentDatabase("b")
+	c.LockTable(ctx, "t")
+
+	err := c.RemoveDatabase("c")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+
+	require.NoError(c.RemoveDatabase("B"))
+	require.Equal(sql.Databases{a}, c.AllDatabases())
+	require.False(c.HasDB("b"))
+
+	// Locks on the tables of the database are released with it
+	require.NoError(c.UnlockTables(ctx, ctx.ID()))
+
+	require.Equal([]string{"a", "b"}, listener.added)
+	require.Equal([]string{"b"}, listener.removed)
+}
+
+func TestCatalogCaseSensitiveNames(t *testing.T) {
+	require := require.New(t)
+
+	db := memory.NewDatabase("Shop")
+	db.AddTable("Orders", memory.NewTable("Orders", nil))
+
+	c := sql.NewCatalog()
+	c.AddDatabase(db)
+	ctx := sql.NewContext(context.Background())
+
+	require.False(c.CaseSensitiveNames())
+	require.Equal(int32(2), c.LowerCaseTableNames())
+	require.True(c.NameMatches("Orders", "orders"))
+	require.True(c.HasDB("shop"))
+	_, err := c.Table(ctx, "shop", "orders")
+	require.NoError(err)
+
+	c.SetCaseSensitiveNames(true)
+	require.True(c.CaseSensitiveNames())
+	require.Equal(int32(0), c.LowerCaseTableNames())
+	require.False(c.NameMatches("Orders", "orders"))
+	require.True(c.NameMatches("Orders", "Orders"))
+
+	require.False(c.HasDB("shop"))
+	require.True(c.HasDB("Shop"))
+
+	_, err = c.Database("shop")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+	_, err = c.Database("Shop")
+	require.NoError(err)
+
+	_, err = c.Table(ctx, "Shop", "orders")
+	require.True(sql.ErrTableNotFound.Is(err))
+	table, err := c.Table(ctx, "Shop", "Orders")
+	require.NoError(err)
+	require.Equal("Orders", table.Name())
+}
+
+func TestCatalogWithProvider(t *testing.T) {
+	require := require.New(t)
+
+	provider := &tenantProvider{}
+	c := sql.NewCatalogWithProvider(provider)
+
+	require.True(c.HasDB("tenant_42"))
+	require.False(c.HasDB("other"))
+	require.Equal(0, provider.resolved)
+
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+	table, err := c.Table(ctx, "tenant_42", "t")
+	require.NoError(err)
+	require.Equal("t", table.Name())
+	require.Equal(1, provider.resolved)
+
+	_, err = c.Table(ctx, "TENANT_42", "t")
+	require.NoError(err)
+	require.Equal(1, provider.resolved)
+
+	other := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+	_, err = c.Table(other, "tenant_42", "t")
+	require.NoError(err)
+	require.Equal(2, provider.resolved)
+
+	c.InvalidateDatabase("tenant_42")
+	_, err = c.Table(ctx, "tenant_42", "t")
+	require.NoError(err)
+	require.Equal(3, provider.resolved)
+
+	_, err = c.Table(ctx, "other", "t")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+
+	err = c.RemoveDatabase("tenant_42")
+	require.True(sql.ErrImmutableDatabaseProvider.Is(err))
+	err = c.AddDatabase(memory.NewDatabase("other"))
+	require.True(sql.ErrImmutableDatabaseProvider.Is(err))
+}
+
+func TestCatalogRemoveDatabaseWhileResolved(t *testing.T) {
+	require := require.New(t)
+
+	provider := &hookedProvider{MutableDatabaseProvider: sql.NewDatabaseProvider(memory.NewDatabase("db"))}
+	c := sql.NewCatalogWithProvider(provider)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(sql.NewBaseSession()))
+
+	// The database is dropped after the provider resolved it for the session, but before the catalog cached it
+	provider.resolved = func() {
+		provider.resolved = nil
+		require.NoError(c.RemoveDatabase("db"))
+	}
+	_, err := c.SessionDatabase(ctx, "db")
+	require.NoError(err)
+
+	_, err = c.SessionDatabase(ctx, "db")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+}
+
+// hookedProvider is a sql.MutableDatabaseProvider that calls resolved, if set, after resolving a database.
+type hookedProvider struct {
+	sql.MutableDatabaseProvider
+	resolved func()
+}
+
+func (p *hookedProvider) Database(name string) (sql.Database, error) {
+	db, err := p.MutableDatabaseProvider.Database(name)
+	if p.resolved != nil {
+		p.resolved()
+	}
+	return db, err
+}
+
+// tenantProvider is an immutable sql.DatabaseProvider with a database named tenant_N, with a table t, for every N.
+type tenantProvider struct {
+	resolved int
+}
+
+func (p *tenantProvider) Database(name string) (sql.Database, error) {
+	if !p.HasDatabase(name) {
+		return nil, sql.ErrDatabaseNotFound.New(name)
+	}
+
+	p.resolved++
+	db := memory.NewDatabase(strings.ToLower(name))
+	db.AddTable("t", memory.NewTable("t", nil))
+	return db, nil
+}
+
+func (p *tenantProvider) HasDatabase(name string) bool {
+	_, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(name), "tenant_"))
+	return strings.HasPrefix(strings.ToLower(name), "tenant_") && err == nil
+}
+
+func (p *tenantProvider) AllDatabases() []sql.Database {
+	return nil
+}
+
+type catalogListener struct {
+	added, removed []string
+}
+
+func (l *catalogListener) DatabaseAdded(db sql.Database) {
+	l.added = append(l.added, db.Name())
+}
+
+func (l *catalogListener) DatabaseRemoved(db sql.Database) {
+	l.removed = append(l.removed, db.Name())
+}
+
 type lockableTable struct {
 	sql.Table
 	unlocks int
@@ -122,3 +302,38 @@ func (l *lockableTable) Unlock(ctx *sql.Context, id uint32) error {
 	l.unlocks++
 	return nil
 }
+
+type versionedTable struct {
+	*memory.Table
+	revisions map[interface{}]sql.Table
+}
+
+func (t versionedTable) AsOf(ctx *sql.Context, asOf interface{}) (sql.Table, bool, error) {
+	tbl, ok := t.revisions[asOf]
+	return tbl, ok, nil
+}
+
+func TestCatalogTableAsOf(t *testing.T) {
+	require := require.New(t)
+
+	ctx := sql.NewEmptyContext()
+	old := memory.NewTable("bar", nil)
+	mytable := versionedTable{memory.NewTable("bar", nil), map[interface{}]sql.Table{"v1": old}}
+
+	// The tables of databases that aren't versioned are read AS OF revisions by the tables themselves
+	db := memory.NewDatabase("foo")
+	db.AddTable("bar", mytable)
+	db.AddTable("baz", memory.NewTable("baz", nil))
+	c := sql.NewCatalog()
+	c.AddDatabase(struct{ sql.Database }{db})
+
+	table, err := c.TableAsOf(ctx, "foo", "BAR", "v1")
+	require.NoError(err)
+	require.Equal(old, table)
+
+	_, err = c.TableAsOf(ctx, "foo", "bar", "v2")
+	require.True(sql.ErrTableNotFound.Is(err))
+
+	_, err = c.TableAsOf(ctx, "foo", "baz", "v1")
+	require.True(sql.ErrAsOfNotSupported.Is(err))
+}
@@ -1183,10 +1183,55 @@ type mysqlCollationRow struct {
 	PadSpace   string
 }
 
+// CollationToMySQLVals are the ids and the attributes of the collations MySQL shows in SHOW COLLATION. They include the
+// default collations of the character sets, and the ones clients commonly declare in their handshake.
 var CollationToMySQLVals = map[Collation]mysqlCollationRow{
-	Collation_binary:             {63, Y, Y, 0, NoPad},
-	Collation_utf8_general_ci:    {33, Y, Y, 1, PadSpace},
-	Collation_utf8mb4_0900_ai_ci: {255, Y, Y, 0, NoPad},
+	Collation_armscii8_general_ci: {32, Y, Y, 1, PadSpace},
+	Collation_ascii_general_ci:    {11, Y, Y, 1, PadSpace},
+	Collation_big5_chinese_ci:     {1, Y, Y, 1, PadSpace},
+	Collation_binary:              {63, Y, Y, 0, NoPad},
+	Collation_cp1250_general_ci:   {26, Y, Y, 1, PadSpace},
+	Collation_cp1251_general_ci:   {51, Y, Y, 1, PadSpace},
+	Collation_cp1256_general_ci:   {57, Y, Y, 1, PadSpace},
+	Collation_cp1257_general_ci:   {59, Y, Y, 1, PadSpace},
+	Collation_cp850_general_ci:    {4, Y, Y, 1, PadSpace},
+	Collation_cp852_general_ci:    {40, Y, Y, 1, PadSpace},
+	Collation_cp866_general_ci:    {36, Y, Y, 1, PadSpace},
+	Collation_cp932_japanese_ci:   {95, Y, Y, 1, PadSpace},
+	Collation_dec8_swedish_ci:     {3, Y, Y, 1, PadSpace},
+	Collation_eucjpms_japanese_ci: {97, Y, Y, 1, PadSpace},
+	Collation_euckr_korean_ci:     {19, Y, Y, 1, PadSpace},
+	Collation_gb18030_chinese_ci:  {248, Y, Y, 2, PadSpace},
+	Collation_gb2312_chinese_ci:   {24, Y, Y, 1, PadSpace},
+	Collation_gbk_chinese_ci:      {28, Y, Y, 1, PadSpace},
+	Collation_geostd8_general_ci:  {92, Y, Y, 1, PadSpace},
+	Collation_greek_general_ci:    {25, Y, Y, 1, PadSpace},
+	Collation_hebrew_general_ci:   {16, Y, Y, 1, PadSpace},
+	Collation_hp8_english_ci:      {6, Y, Y, 1, PadSpace},
+	Collation_keybcs2_general_ci:  {37, Y, Y, 1, PadSpace},
+	Collation_koi8r_general_ci:    {7, Y, Y, 1, PadSpace},
+	Collation_koi8u_general_ci:    {22, Y, Y, 1, PadSpace},
+	Collation_latin1_bin:          {47, "", Y, 1, PadSpace},
+	Collation_latin1_swedish_ci:   {8, Y, Y, 1, PadSpace},
+	Collation_latin2_general_ci:   {9, Y, Y, 1, PadSpace},
+	Collation_latin5_turkish_ci:   {30, Y, Y, 1, PadSpace},
+	Collation_latin7_general_ci:   {41, Y, Y, 1, PadSpace},
+	Collation_macce_general_ci:    {38, Y, Y, 1, PadSpace},
+	Collation_macroman_general_ci: {39, Y, Y, 1, PadSpace},
+	Collation_sjis_japanese_ci:    {13, Y, Y, 1, PadSpace},
+	Collation_swe7_swedish_ci:     {10, Y, Y, 1, PadSpace},
+	Collation_tis620_thai_ci:      {18, Y, Y, 4, PadSpace},
+	Collation_ucs2_general_ci:     {35, Y, Y, 1, PadSpace},
+	Collation_ujis_japanese_ci:    {12, Y, Y, 1, PadSpace},
+	Collation_utf16_general_ci:    {54, Y, Y, 1, PadSpace},
+	Collation_utf16le_general_ci:  {56, Y, Y, 1, PadSpace},
+	Collation_utf32_general_ci:    {60, Y, Y, 1, PadSpace},
+	Collation_utf8mb3_bin:         {83, "", Y, 1, PadSpace},
+	Collation_utf8mb3_general_ci:  {33, Y, Y, 1, PadSpace},
+	Collation_utf8mb4_0900_ai_ci:  {255, Y, Y, 0, NoPad},
+	Collation_utf8mb4_bin:         {46, "", Y, 1, PadSpace},
+	Collation_utf8mb4_general_ci:  {45, "", Y, 1, PadSpace},
+	Collation_utf8mb4_unicode_ci:  {224, "", Y, 8, PadSpace},
 }
 
 // ParseCharacterSet takes in a string representing a CharacterSet and
@@ -1296,14 +1341,30 @@ func (c Collation) String() string {
 	return string(c)
 }
 
+// CollationFromID returns the Collation of CollationToMySQLVals with the id given, which is how clients declare the
+// character set of their connection in their handshake.
+func CollationFromID(id int64) (Collation, bool) {
+	for c, vals := range CollationToMySQLVals {
+		if vals.ID == id {
+			return c, true
+		}
+	}
+	return Collation_Default, false
+}
+
 // ID returns the id of the Collation.
 func (c Collation) ID() int64 {
-	s, ok := CollationToMySQLVals[c]
-	if !ok {
-		s := CollationToMySQLVals[Collation_Default]
+	if s, ok := CollationToMySQLVals[c]; ok {
 		return s.ID
 	}
-	return s.ID
+	// Collations without an id of their own take the one of the default collation of their character set, which
+	// encodes strings the same way
+	if collation, ok := characterSetDefaults[collationToCharacterSet[c]]; ok {
+		if s, ok := CollationToMySQLVals[collation]; ok {
+			return s.ID
+		}
+	}
+	return CollationToMySQLVals[Collation_Default].ID
 }
 
 // IsDefault returns string specifying id collation is default.
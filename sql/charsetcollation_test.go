@@ -55,3 +55,17 @@ func testParseCollation(t *testing.T, charset *string, collation *string, binary
 		}
 	})
 }
+
+func TestCollationFromID(t *testing.T) {
+	require := require.New(t)
+
+	collation, ok := CollationFromID(8)
+	require.True(ok)
+	require.Equal(Collation_latin1_swedish_ci, collation)
+
+	_, ok = CollationFromID(2)
+	require.False(ok)
+
+	// Collations without an id of their own have the one of the default collation of their character set
+	require.Equal(int64(8), Collation_latin1_german1_ci.ID())
+}
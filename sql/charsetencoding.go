@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// characterSetEncodings are the encodings of the character sets strings can be converted to. Strings are kept in
+// UTF-8, so the Unicode character sets stored as UTF-8 and binary don't change them. MySQL's latin1 is Windows-1252.
+var characterSetEncodings = map[CharacterSet]encoding.Encoding{
+	CharacterSet_big5:     traditionalchinese.Big5,
+	CharacterSet_binary:   encoding.Nop,
+	CharacterSet_cp1250:   charmap.Windows1250,
+	CharacterSet_cp1251:   charmap.Windows1251,
+	CharacterSet_cp1256:   charmap.Windows1256,
+	CharacterSet_cp1257:   charmap.Windows1257,
+	CharacterSet_cp850:    charmap.CodePage850,
+	CharacterSet_cp852:    charmap.CodePage852,
+	CharacterSet_cp866:    charmap.CodePage866,
+	CharacterSet_cp932:    japanese.ShiftJIS,
+	CharacterSet_eucjpms:  japanese.EUCJP,
+	CharacterSet_euckr:    korean.EUCKR,
+	CharacterSet_gb18030:  simplifiedchinese.GB18030,
+	CharacterSet_gb2312:   simplifiedchinese.GBK,
+	CharacterSet_gbk:      simplifiedchinese.GBK,
+	CharacterSet_greek:    charmap.ISO8859_7,
+	CharacterSet_hebrew:   charmap.ISO8859_8,
+	CharacterSet_koi8r:    charmap.KOI8R,
+	CharacterSet_koi8u:    charmap.KOI8U,
+	CharacterSet_latin1:   charmap.Windows1252,
+	CharacterSet_latin2:   charmap.ISO8859_2,
+	CharacterSet_latin5:   charmap.ISO8859_9,
+	CharacterSet_latin7:   charmap.ISO8859_13,
+	CharacterSet_macroman: charmap.Macintosh,
+	CharacterSet_sjis:     japanese.ShiftJIS,
+	CharacterSet_ucs2:     unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	CharacterSet_ujis:     japanese.EUCJP,
+	CharacterSet_utf16:    unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM),
+	CharacterSet_utf16le:  unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM),
+	CharacterSet_utf32:    utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM),
+	CharacterSet_utf8:     encoding.Nop,
+	CharacterSet_utf8mb3:  encoding.Nop,
+	CharacterSet_utf8mb4:  encoding.Nop,
+}
+
+// CanEncode returns whether strings can be converted to the CharacterSet.
+func (cs CharacterSet) CanEncode() bool {
+	_, ok := characterSetEncodings[cs]
+	return ok
+}
+
+// EncodeString returns the UTF-8 string given converted to the CharacterSet, with a '?' for every character the
+// CharacterSet has no encoding of, as MySQL converts the strings it sends to clients. Strings are returned as they are
+// if they can't be converted to the CharacterSet.
+func (cs CharacterSet) EncodeString(s string) []byte {
+	enc, ok := characterSetEncodings[cs]
+	if !ok {
+		return []byte(s)
+	}
+
+	encoder := enc.NewEncoder()
+	if encoded, err := encoder.Bytes([]byte(s)); err == nil {
+		return encoded
+	}
+
+	unknown, _ := encoder.Bytes([]byte("?"))
+	var encoded []byte
+	for _, r := range s {
+		b, err := encoder.Bytes([]byte(string(r)))
+		if err != nil {
+			b = unknown
+		}
+		encoded = append(encoded, b...)
+	}
+	return encoded
+}
@@ -0,0 +1,32 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharacterSetEncodeString(t *testing.T) {
+	tests := []struct {
+		charset  CharacterSet
+		str      string
+		expected []byte
+	}{
+		{CharacterSet_utf8mb4, "é✓", []byte("é✓")},
+		{CharacterSet_binary, "é", []byte("é")},
+		{CharacterSet_latin1, "é€", []byte{0xe9, 0x80}},
+		{CharacterSet_latin1, "a✓b", []byte("a?b")},
+		{CharacterSet_sjis, "日本", []byte{0x93, 0xfa, 0x96, 0x7b}},
+		{CharacterSet_utf16, "a✓", []byte{0x00, 'a', 0x27, 0x13}},
+		{CharacterSet_ucs2, "aÿ", []byte{0x00, 'a', 0x00, 0xff}},
+		{CharacterSet_swe7, "é", []byte("é")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.charset.String()+" "+tt.str, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(tt.expected, tt.charset.EncodeString(tt.str))
+			require.Equal(tt.charset != CharacterSet_swe7, tt.charset.CanEncode())
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrCheckConstraintViolated is returned when a row inserted or updated makes the expression of an enforced check
+	// constraint of its table false, with the name of the constraint. Servers report it to clients as the MySQL error
+	// ER_CHECK_CONSTRAINT_VIOLATED (3819).
+	ErrCheckConstraintViolated = errors.NewKind("Check constraint '%s' is violated.")
+	// ErrCheckConstraintDuplicateName is returned when a table declares two check constraints with the same name.
+	// Servers report it to clients as the MySQL error ER_CHECK_CONSTRAINT_DUP_NAME (3822).
+	ErrCheckConstraintDuplicateName = errors.NewKind("Duplicate check constraint name '%s'.")
+	// ErrCheckConstraintUnknownColumn is returned when the expression of a check constraint references a column its
+	// table doesn't have, with the column and the constraint. Servers report it to clients as the MySQL error
+	// ER_BAD_FIELD_ERROR (1054).
+	ErrCheckConstraintUnknownColumn = errors.NewKind("Unknown column '%s' in 'check constraint %s expression'")
+	// ErrCheckConstraintOtherColumn is returned when a check constraint declared with a column references any other
+	// column. Servers report it to clients as the MySQL error ER_COLUMN_CHECK_CONSTRAINT_REFERENCES_OTHER_COLUMN
+	// (3813).
+	ErrCheckConstraintOtherColumn = errors.NewKind("Column check constraint '%s' references other column.")
+	// ErrCheckConstraintNotFound is returned when a check constraint dropped from a table isn't one of its constraints.
+	// Servers report it to clients as the MySQL error ER_CHECK_CONSTRAINT_NOT_FOUND (3821).
+	ErrCheckConstraintNotFound = errors.NewKind("Check constraint '%s' is not found in the table.")
+	// ErrCheckConstraintSubquery is returned when the expression of a check constraint has a subquery.
+	ErrCheckConstraintSubquery = errors.NewKind("An expression of check constraint '%s' contains disallowed subquery.")
+)
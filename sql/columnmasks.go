@@ -0,0 +1,137 @@
+package sql
+
+import (
+	"strings"
+	"sync"
+)
+
+// ColumnMask returns the masked form of a value of a column, such as `jXXX@XXXX.com` for an email. The masked value
+// must be NULL or a value of the type of the column.
+type ColumnMask func(ctx *Context, value interface{}) (interface{}, error)
+
+// UnmaskAuthorizer returns whether the user of the session of a context can see the unmasked values of masked
+// columns.
+type UnmaskAuthorizer func(ctx *Context) bool
+
+// ColumnMaskRegistry holds the masks of columns of tables. The values of masked columns are masked as they're read
+// from their tables for users that aren't authorized to unmask them, so queries, including their filters, joins and
+// aggregations, only ever see masked values. Masks aren't applied to the tables modified by UPDATE and DELETE
+// statements, since their rows must be written back with the unmasked values.
+type ColumnMaskRegistry struct {
+	mu    sync.RWMutex
+	masks map[string]map[string]ColumnMask
+	// unmask is the authorizer of the users who can see unmasked values.
+	unmask UnmaskAuthorizer
+}
+
+// NewColumnMaskRegistry creates a new empty ColumnMaskRegistry, whose masks apply to all users until an
+// UnmaskAuthorizer is set.
+func NewColumnMaskRegistry() *ColumnMaskRegistry {
+	return &ColumnMaskRegistry{masks: make(map[string]map[string]ColumnMask)}
+}
+
+// AddColumnMask masks the column of the table of the database given, whose names are case insensitive, with the mask
+// given, replacing any previous mask of the column.
+func (r *ColumnMaskRegistry) AddColumnMask(db, table, column string, mask ColumnMask) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tableKey(db, table)
+	if r.masks[key] == nil {
+		r.masks[key] = make(map[string]ColumnMask)
+	}
+	r.masks[key][strings.ToLower(column)] = mask
+}
+
+// RemoveColumnMask removes the mask of the column of the table of the database given.
+func (r *ColumnMaskRegistry) RemoveColumnMask(db, table, column string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tableKey(db, table)
+	delete(r.masks[key], strings.ToLower(column))
+	if len(r.masks[key]) == 0 {
+		delete(r.masks, key)
+	}
+}
+
+// SetUnmaskAuthorizer sets the authorizer of the users who can see unmasked values.
+func (r *ColumnMaskRegistry) SetUnmaskAuthorizer(unmask UnmaskAuthorizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unmask = unmask
+}
+
+// HasColumnMasks returns whether any column is masked.
+func (r *ColumnMaskRegistry) HasColumnMasks() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.masks) > 0
+}
+
+// ColumnMasks returns the masks of the columns of the table of the database given for the user of the session of the
+// context given, keyed by the lower case names of the columns. Users authorized to unmask values don't have any.
+func (r *ColumnMaskRegistry) ColumnMasks(ctx *Context, db, table string) map[string]ColumnMask {
+	r.mu.RLock()
+	masks := r.masks[tableKey(db, table)]
+	unmask := r.unmask
+	result := make(map[string]ColumnMask, len(masks))
+	for column, mask := range masks {
+		result[column] = mask
+	}
+	r.mu.RUnlock()
+
+	if len(result) == 0 || (unmask != nil && unmask(ctx)) {
+		return nil
+	}
+	return result
+}
+
+// MaskEmail is a ColumnMask for emails, which keeps the first letter of the email and the top level domain, like
+// `jXXX@XXXX.com`.
+func MaskEmail(_ *Context, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	s, err := LongText.Convert(value)
+	if err != nil {
+		return nil, err
+	}
+	email := s.(string)
+
+	var first string
+	if email != "" {
+		first = string([]rune(email)[0])
+	}
+
+	tld := "com"
+	if i := strings.LastIndex(email, "."); i >= 0 && i > strings.LastIndex(email, "@") {
+		tld = email[i+1:]
+	}
+	return first + "XXX@XXXX." + tld, nil
+}
+
+// MaskPartial returns a ColumnMask for strings that keeps their first prefix and last suffix characters, and replaces
+// the rest with padding, like MaskPartial(1, "XXXX", 2) masks `555-0123` as `5XXXX23`. Strings that are too short to
+// keep any character are replaced with padding.
+func MaskPartial(prefix int, padding string, suffix int) ColumnMask {
+	return func(_ *Context, value interface{}) (interface{}, error) {
+		if value == nil {
+			return nil, nil
+		}
+
+		s, err := LongText.Convert(value)
+		if err != nil {
+			return nil, err
+		}
+
+		runes := []rune(s.(string))
+		if len(runes) <= prefix+suffix {
+			return padding, nil
+		}
+		return string(runes[:prefix]) + padding + string(runes[len(runes)-suffix:]), nil
+	}
+}
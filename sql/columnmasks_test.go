@@ -0,0 +1,76 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestColumnMaskRegistry(t *testing.T) {
+	require := require.New(t)
+
+	r := sql.NewColumnMaskRegistry()
+	require.False(r.HasColumnMasks())
+
+	r.AddColumnMask("DB", "Users", "Email", sql.MaskEmail)
+	require.True(r.HasColumnMasks())
+
+	ctx := func(user string) *sql.Context {
+		return sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("server", "client", user, 1)))
+	}
+
+	masks := r.ColumnMasks(ctx("joe"), "db", "users")
+	require.Len(masks, 1)
+	require.Contains(masks, "email")
+	require.Nil(r.ColumnMasks(ctx("joe"), "db", "other"))
+
+	r.SetUnmaskAuthorizer(func(ctx *sql.Context) bool {
+		return ctx.Client().User == "root"
+	})
+	require.Nil(r.ColumnMasks(ctx("root"), "db", "users"))
+	require.Len(r.ColumnMasks(ctx("joe"), "db", "users"), 1)
+
+	r.RemoveColumnMask("db", "users", "EMAIL")
+	require.False(r.HasColumnMasks())
+}
+
+func TestMaskEmail(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{"jane@example.org", "jXXX@XXXX.org"},
+		{"jane@localhost", "jXXX@XXXX.com"},
+		{"", "XXX@XXXX.com"},
+		{nil, nil},
+	}
+
+	for _, tt := range testCases {
+		masked, err := sql.MaskEmail(sql.NewEmptyContext(), tt.value)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, masked)
+	}
+}
+
+func TestMaskPartial(t *testing.T) {
+	mask := sql.MaskPartial(1, "XXXX", 2)
+
+	testCases := []struct {
+		value    interface{}
+		expected interface{}
+	}{
+		{"555-0123", "5XXXX23"},
+		{"abc", "XXXX"},
+		{int64(123456), "1XXXX56"},
+		{nil, nil},
+	}
+
+	for _, tt := range testCases {
+		masked, err := mask(sql.NewEmptyContext(), tt.value)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, masked)
+	}
+}
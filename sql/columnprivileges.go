@@ -0,0 +1,166 @@
+package sql
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrColumnAccessDenied is returned when a query reads a column the user of its session isn't granted.
+var ErrColumnAccessDenied = errors.NewKind("SELECT command denied to user '%s'@'%s' for column '%s' in table '%s'")
+
+// AllColumnsAuthorizer returns whether the user of the session of a context can read all the columns of the tables
+// with column privileges, whatever columns they're granted.
+type AllColumnsAuthorizer func(ctx *Context) bool
+
+// ColumnPrivilegeRegistry holds the columns of tables that each user is granted to read. Once any column of a table is
+// granted to some user, users can only read the columns of the table they're granted, unless they're authorized to
+// read all of them. Tables without grants can be read by everyone.
+type ColumnPrivilegeRegistry struct {
+	mu     sync.RWMutex
+	grants map[string]*tableColumnGrants
+	// allColumns is the authorizer of the users who can read all the columns.
+	allColumns AllColumnsAuthorizer
+}
+
+// tableColumnGrants are the granted columns of a table, by user.
+type tableColumnGrants struct {
+	db, table string
+	users     map[string]map[string]bool
+}
+
+// NewColumnPrivilegeRegistry creates a new empty ColumnPrivilegeRegistry, whose grants apply to all users until an
+// AllColumnsAuthorizer is set.
+func NewColumnPrivilegeRegistry() *ColumnPrivilegeRegistry {
+	return &ColumnPrivilegeRegistry{grants: make(map[string]*tableColumnGrants)}
+}
+
+// GrantColumns grants the user given to read the columns of the table of the database given, whose names are case
+// insensitive.
+func (r *ColumnPrivilegeRegistry) GrantColumns(db, table, user string, columns ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tableKey(db, table)
+	if r.grants[key] == nil {
+		r.grants[key] = &tableColumnGrants{db: db, table: table, users: make(map[string]map[string]bool)}
+	}
+	t := r.grants[key]
+	if t.users[user] == nil {
+		t.users[user] = make(map[string]bool)
+	}
+	for _, c := range columns {
+		t.users[user][strings.ToLower(c)] = true
+	}
+}
+
+// RevokeColumns revokes the grants of the user given to read the columns of the table of the database given. Once no
+// column of a table is granted to any user, everyone can read it again.
+func (r *ColumnPrivilegeRegistry) RevokeColumns(db, table, user string, columns ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tableKey(db, table)
+	t, ok := r.grants[key]
+	if !ok {
+		return
+	}
+	for _, c := range columns {
+		delete(t.users[user], strings.ToLower(c))
+	}
+	if len(t.users[user]) == 0 {
+		delete(t.users, user)
+	}
+	if len(t.users) == 0 {
+		delete(r.grants, key)
+	}
+}
+
+// SetAllColumnsAuthorizer sets the authorizer of the users who can read all the columns, whatever their grants.
+func (r *ColumnPrivilegeRegistry) SetAllColumnsAuthorizer(allColumns AllColumnsAuthorizer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.allColumns = allColumns
+}
+
+// HasColumnPrivileges returns whether any column is granted.
+func (r *ColumnPrivilegeRegistry) HasColumnPrivileges() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.grants) > 0
+}
+
+// GrantedColumns returns the lower case names of the columns of the table of the database given that the user of the
+// session of the context given can read, and whether the user can only read those. It returns false for the tables
+// without grants, and for users authorized to read all the columns.
+func (r *ColumnPrivilegeRegistry) GrantedColumns(ctx *Context, db, table string) (map[string]bool, bool) {
+	r.mu.RLock()
+	t, ok := r.grants[tableKey(db, table)]
+	granted := make(map[string]bool)
+	if ok {
+		for c := range t.users[ctx.Client().User] {
+			granted[c] = true
+		}
+	}
+	allColumns := r.allColumns
+	r.mu.RUnlock()
+
+	if !ok || (allColumns != nil && allColumns(ctx)) {
+		return nil, false
+	}
+	return granted, true
+}
+
+// ColumnGrant is the grant of the columns of a table to a user.
+type ColumnGrant struct {
+	Database string
+	Table    string
+	User     string
+	// Columns are the lower case names of the columns granted, sorted.
+	Columns []string
+}
+
+// ColumnGrants returns the column grants of all the users, sorted by user, database and table.
+func (r *ColumnPrivilegeRegistry) ColumnGrants() []ColumnGrant {
+	r.mu.RLock()
+	var grants []ColumnGrant
+	for _, t := range r.grants {
+		for user, columns := range t.users {
+			g := ColumnGrant{Database: t.db, Table: t.table, User: user}
+			for c := range columns {
+				g.Columns = append(g.Columns, c)
+			}
+			sort.Strings(g.Columns)
+			grants = append(grants, g)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Table < b.Table
+	})
+	return grants
+}
+
+// NewColumnAccessDenied returns an ErrColumnAccessDenied for the user of the session of the context given reading
+// the column of the table given.
+func NewColumnAccessDenied(ctx *Context, table, column string) error {
+	client := ctx.Client()
+	host := client.Address
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return ErrColumnAccessDenied.New(client.User, host, column, table)
+}
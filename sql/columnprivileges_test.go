@@ -0,0 +1,50 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestColumnPrivilegeRegistry(t *testing.T) {
+	require := require.New(t)
+
+	r := sql.NewColumnPrivilegeRegistry()
+	require.False(r.HasColumnPrivileges())
+
+	r.GrantColumns("DB", "Employees", "joe", "ID", "name")
+	require.True(r.HasColumnPrivileges())
+
+	ctx := func(user string) *sql.Context {
+		return sql.NewContext(context.Background(), sql.WithSession(sql.NewSession("server", "client:3306", user, 1)))
+	}
+
+	granted, restricted := r.GrantedColumns(ctx("joe"), "db", "employees")
+	require.True(restricted)
+	require.Equal(map[string]bool{"id": true, "name": true}, granted)
+	granted, restricted = r.GrantedColumns(ctx("jane"), "db", "employees")
+	require.True(restricted)
+	require.Empty(granted)
+	_, restricted = r.GrantedColumns(ctx("joe"), "db", "other")
+	require.False(restricted)
+
+	r.SetAllColumnsAuthorizer(func(ctx *sql.Context) bool {
+		return ctx.Client().User == "root"
+	})
+	_, restricted = r.GrantedColumns(ctx("root"), "db", "employees")
+	require.False(restricted)
+
+	require.EqualError(
+		sql.NewColumnAccessDenied(ctx("jane"), "employees", "salary"),
+		"SELECT command denied to user 'jane'@'client' for column 'salary' in table 'employees'",
+	)
+
+	r.RevokeColumns("db", "employees", "joe", "id")
+	granted, _ = r.GrantedColumns(ctx("joe"), "db", "employees")
+	require.Equal(map[string]bool{"name": true}, granted)
+	r.RevokeColumns("db", "employees", "joe", "NAME")
+	require.False(r.HasColumnPrivileges())
+}
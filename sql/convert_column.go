@@ -0,0 +1,302 @@
+package sql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cast"
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+const (
+	// warnDataOutOfRange is the code of the warnings of numbers clamped to the range of their column, and of zero dates
+	// written when NO_ZERO_DATE is enabled.
+	warnDataOutOfRange = 1264
+	// warnDataTruncated is the code of the warnings of strings truncated to the length of their column, and of invalid
+	// temporal values replaced by the zero date.
+	warnDataTruncated = 1265
+	// warnTruncatedWrongValueForField is the code of the warnings of invalid numbers replaced by zero.
+	warnTruncatedWrongValueForField = 1366
+)
+
+var (
+	// ErrIncorrectTemporalValue is returned in strict mode when a value written to a column of a temporal type isn't a
+	// valid value of the type, with the type, the value, the column and the number of the row written, from 1. Servers
+	// report it to clients as the MySQL error ER_TRUNCATED_WRONG_VALUE (1292).
+	ErrIncorrectTemporalValue = errors.NewKind("Incorrect %s value: '%v' for column '%s' at row %d")
+	// ErrIncorrectValue is returned in strict mode when a value written to a column of a number type isn't a number,
+	// with the type, the value, the column and the number of the row written. Servers report it to clients as the MySQL
+	// error ER_TRUNCATED_WRONG_VALUE_FOR_FIELD (1366).
+	ErrIncorrectValue = errors.NewKind("Incorrect %s value: '%v' for column '%s' at row %d")
+	// ErrValueOutOfRange is returned in strict mode when a number written to a column is outside of the range of the
+	// column's type, with the column and the number of the row written. Servers report it to clients as the MySQL
+	// error ER_WARN_DATA_OUT_OF_RANGE (1264).
+	ErrValueOutOfRange = errors.NewKind("Out of range value for column '%s' at row %d")
+	// ErrDataTruncated is returned in strict mode when a string written to a column of a number type starts with a
+	// number followed by other characters, with the column and the number of the row written. Servers report it to
+	// clients as the MySQL error WARN_DATA_TRUNCATED (1265).
+	ErrDataTruncated = errors.NewKind("Data truncated for column '%s' at row %d")
+	// ErrDataTooLong is returned in strict mode when a string written to a column is longer than the column's type
+	// allows, with the column and the number of the row written. Servers report it to clients as the MySQL error
+	// ER_DATA_TOO_LONG (1406).
+	ErrDataTooLong = errors.NewKind("Data too long for column '%s' at row %d")
+)
+
+// ConvertToColumn converts the value given to the type of the column given, with the name given, to write it to the
+// row with the number given, from 1, of the column's table. In strict mode, per the SQL mode of the context, values
+// the type can't hold are errors. Otherwise they're coerced with a warning: numbers out of range are clamped to the
+// range of the type, strings too long are truncated, strings starting with numbers are truncated to them, and values
+// that aren't numbers or temporal values of the type are zero. The zero date is valid unless NO_ZERO_DATE is enabled, in which case strict mode makes it an error, and it's
+// written with a warning otherwise. Values of other types are converted as they are by their type.
+func ConvertToColumn(ctx *Context, typ Type, column string, v interface{}, row int) (interface{}, error) {
+	if v == nil {
+		return typ.Convert(v)
+	}
+
+	if dt, ok := typ.(DatetimeType); ok {
+		return convertTemporalToColumn(ctx, dt, column, v, row)
+	}
+
+	converted, err := typ.Convert(v)
+	if err == nil {
+		return converted, nil
+	}
+
+	switch t := typ.(type) {
+	case numberTypeImpl:
+		return convertNumberToColumn(ctx, t, column, v, row)
+	case decimalType:
+		return convertDecimalToColumn(ctx, t, column, v, row, err)
+	case stringType:
+		if ErrLengthBeyondLimit.Is(err) {
+			return convertStringToColumn(ctx, t, column, v, row)
+		}
+	}
+	return nil, err
+}
+
+// convertTemporalToColumn converts the value given to the temporal type given for ConvertToColumn.
+func convertTemporalToColumn(ctx *Context, typ DatetimeType, column string, v interface{}, row int) (interface{}, error) {
+	converted, err := typ.Convert(v)
+	if err != nil {
+		if LoadSqlMode(ctx).Strict() {
+			return nil, ErrIncorrectTemporalValue.New(temporalTypeName(typ), temporalString(v), column, row)
+		}
+		ctx.Warn(warnDataTruncated, "Data truncated for column '%s' at row %d", column, row)
+		return zeroTime, nil
+	}
+
+	if IsZeroTime(converted.(time.Time)) {
+		if mode := LoadSqlMode(ctx); mode.ModeEnabled(NoZeroDateMode) {
+			if mode.Strict() {
+				return nil, ErrIncorrectTemporalValue.New(temporalTypeName(typ), temporalString(v), column, row)
+			}
+			ctx.Warn(warnDataOutOfRange, "Out of range value for column '%s' at row %d", column, row)
+		}
+	}
+	return converted, nil
+}
+
+// convertNumberToColumn converts the value given, which the number type given couldn't convert, for ConvertToColumn.
+// Numbers in the range of integer types that aren't integers are rounded.
+func convertNumberToColumn(ctx *Context, typ numberTypeImpl, column string, v interface{}, row int) (interface{}, error) {
+	f, ok := numericValue(v)
+	if !ok {
+		prefix, ok := numericPrefix(v)
+		if !ok {
+			typeName := "integer"
+			if typ.IsFloat() {
+				typeName = "double"
+			}
+			if LoadSqlMode(ctx).Strict() {
+				return nil, ErrIncorrectValue.New(typeName, v, column, row)
+			}
+			ctx.Warn(warnTruncatedWrongValueForField, "Incorrect %s value: '%v' for column '%s' at row %d", typeName, v, column, row)
+			return typ.Zero(), nil
+		}
+		if err := truncateNumber(ctx, column, row); err != nil {
+			return nil, err
+		}
+		f = prefix
+	}
+
+	min, max := typ.limits()
+	minF, maxF := cast.ToFloat64(min), cast.ToFloat64(max)
+	// The maximums of 64-bit integers round up to the first float out of their range
+	exactMax := typ.baseType != sqltypes.Int64 && typ.baseType != sqltypes.Uint64
+	if f >= minF && (f < maxF || f == maxF && exactMax) {
+		if typ.IsFloat() {
+			return typ.Convert(f)
+		}
+		return typ.Convert(math.Round(f))
+	}
+
+	if LoadSqlMode(ctx).Strict() {
+		return nil, ErrValueOutOfRange.New(column, row)
+	}
+	ctx.Warn(warnDataOutOfRange, "Out of range value for column '%s' at row %d", column, row)
+	if f < 0 {
+		return min, nil
+	}
+	return max, nil
+}
+
+// convertDecimalToColumn converts the value given, which the decimal type given couldn't convert with the error given,
+// for ConvertToColumn.
+func convertDecimalToColumn(ctx *Context, typ decimalType, column string, v interface{}, row int, err error) (interface{}, error) {
+	if prefix, ok := numericPrefix(v); ok && !ErrConvertToDecimalLimit.Is(err) {
+		if err := truncateNumber(ctx, column, row); err != nil {
+			return nil, err
+		}
+		converted, err := typ.Convert(prefix)
+		if err != nil {
+			return convertDecimalToColumn(ctx, typ, column, prefix, row, err)
+		}
+		return converted, nil
+	}
+
+	if !ErrConvertToDecimalLimit.Is(err) {
+		if LoadSqlMode(ctx).Strict() {
+			return nil, ErrIncorrectValue.New("decimal", v, column, row)
+		}
+		ctx.Warn(warnTruncatedWrongValueForField, "Incorrect decimal value: '%v' for column '%s' at row %d", v, column, row)
+		return typ.Zero(), nil
+	}
+
+	if LoadSqlMode(ctx).Strict() {
+		return nil, ErrValueOutOfRange.New(column, row)
+	}
+	ctx.Warn(warnDataOutOfRange, "Out of range value for column '%s' at row %d", column, row)
+	max := typ.exclusiveUpperBound.Sub(decimal.New(1, -int32(typ.scale)))
+	if f, _ := numericValue(v); f < 0 {
+		max = max.Neg()
+	}
+	return max.StringFixed(int32(typ.scale)), nil
+}
+
+// convertStringToColumn converts the value given, which is too long for the string type given, for ConvertToColumn.
+// Like the type, the value is truncated to the length of the type in bytes, without splitting the characters of text.
+func convertStringToColumn(ctx *Context, typ stringType, column string, v interface{}, row int) (interface{}, error) {
+	if LoadSqlMode(ctx).Strict() {
+		return nil, ErrDataTooLong.New(column, row)
+	}
+
+	converted, err := LongText.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+	s := converted.(string)
+
+	length := typ.charLength
+	if typ.baseType == sqltypes.Text {
+		length = typ.MaxByteLength()
+	}
+	if int64(len(s)) > length {
+		s = s[:length]
+	}
+	if IsTextOnly(typ) {
+		for len(s) > 0 {
+			if r, size := utf8.DecodeLastRuneInString(s); r != utf8.RuneError || size != 1 {
+				break
+			}
+			s = s[:len(s)-1]
+		}
+	}
+
+	ctx.Warn(warnDataTruncated, "Data truncated for column '%s' at row %d", column, row)
+	return typ.Convert(s)
+}
+
+// truncateNumber returns ErrDataTruncated in strict mode for ConvertToColumn, or adds its warning otherwise.
+func truncateNumber(ctx *Context, column string, row int) error {
+	if LoadSqlMode(ctx).Strict() {
+		return ErrDataTruncated.New(column, row)
+	}
+	ctx.Warn(warnDataTruncated, "Data truncated for column '%s' at row %d", column, row)
+	return nil
+}
+
+// numericValue returns the value given as a float64, and whether it's a number or a string of a number. Strings of
+// numbers too large for a float64 are infinite.
+func numericValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case string:
+		return parseNumber(v)
+	case []byte:
+		return parseNumber(string(v))
+	case decimal.Decimal:
+		f, _ := v.Float64()
+		return f, true
+	default:
+		f, err := cast.ToFloat64E(v)
+		return f, err == nil
+	}
+}
+
+// parseNumber returns the number of the string given, and whether it's a number.
+func parseNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return f, true
+		}
+		return 0, false
+	}
+	return f, true
+}
+
+// numericPrefix returns the number the string given starts with, like MySQL reads the strings written to number
+// columns, and whether it starts with one. Leading spaces are skipped.
+func numericPrefix(v interface{}) (float64, bool) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return 0, false
+	}
+
+	s = strings.TrimLeft(s, " \t\n\r")
+	end := 0
+	if end < len(s) && (s[end] == '+' || s[end] == '-') {
+		end++
+	}
+	digits := 0
+	for ; end < len(s) && isDigit(s[end]); end++ {
+		digits++
+	}
+	if end < len(s) && s[end] == '.' {
+		end++
+		for ; end < len(s) && isDigit(s[end]); end++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return 0, false
+	}
+	if end < len(s) && (s[end] == 'e' || s[end] == 'E') {
+		exp := end + 1
+		if exp < len(s) && (s[exp] == '+' || s[exp] == '-') {
+			exp++
+		}
+		if exp < len(s) && isDigit(s[exp]) {
+			end = exp
+			for end < len(s) && isDigit(s[end]) {
+				end++
+			}
+		}
+	}
+	return parseNumber(s[:end])
+}
+
+// isDigit returns whether the byte given is a decimal digit.
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
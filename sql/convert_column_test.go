@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+func TestConvertToColumn(t *testing.T) {
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		typ      Type
+		mode     string
+		value    interface{}
+		expected interface{}
+		err      *errors.Kind
+		warning  int
+	}{
+		{"date", Date, "", "2020-01-01", date, nil, 0},
+		{"zero date", Date, "", zeroDateStr, zeroTime, nil, 0},
+		{"invalid date", Date, "", "garbage", zeroTime, nil, warnDataTruncated},
+		{"invalid day", Date, "", "2020-02-30", zeroTime, nil, warnDataTruncated},
+		{"strict zero date", Date, StrictTransTablesMode, zeroDateStr, zeroTime, nil, 0},
+		{"strict invalid date", Date, StrictTransTablesMode, "garbage", nil, ErrIncorrectTemporalValue, 0},
+		{"zero date with NO_ZERO_DATE", Date, NoZeroDateMode, zeroDateStr, zeroTime, nil, warnDataOutOfRange},
+		{"strict zero date with NO_ZERO_DATE", Date, TraditionalMode, zeroDateStr, nil, ErrIncorrectTemporalValue, 0},
+		{"number", Int8, StrictTransTablesMode, "12", int8(12), nil, 0},
+		{"rounded number", Int8, StrictTransTablesMode, "1.5", int8(2), nil, 0},
+		{"number over range", Int8, "", 300, int8(127), nil, warnDataOutOfRange},
+		{"number under range", Int8, "", "-300", int8(-128), nil, warnDataOutOfRange},
+		{"negative unsigned number", Uint32, "", -1, uint32(0), nil, warnDataOutOfRange},
+		{"unsigned number over range", Uint64, "", "1e30", uint64(18446744073709551615), nil, warnDataOutOfRange},
+		{"float over range", Float32, "", 1e300, float32(3.4028234663852886e+38), nil, warnDataOutOfRange},
+		{"strict number out of range", Int8, StrictTransTablesMode, 300, nil, ErrValueOutOfRange, 0},
+		{"invalid number", Int32, "", "one", int32(0), nil, warnTruncatedWrongValueForField},
+		{"strict invalid number", Int32, StrictAllTablesMode, "one", nil, ErrIncorrectValue, 0},
+		{"number prefix", Int8, "", "12abc", int8(12), nil, warnDataTruncated},
+		{"number prefix with exponent", Int32, "", " -1.5e2 apples", int32(-150), nil, warnDataTruncated},
+		{"float prefix", Float64, "", "2.5x", 2.5, nil, warnDataTruncated},
+		{"strict number prefix", Int8, StrictTransTablesMode, "12abc", nil, ErrDataTruncated, 0},
+		{"decimal prefix", MustCreateDecimalType(4, 2), "", "1.5abc", "1.50", nil, warnDataTruncated},
+		{"strict decimal prefix", MustCreateDecimalType(4, 2), StrictTransTablesMode, "1.5abc", nil, ErrDataTruncated, 0},
+		{"decimal over range", MustCreateDecimalType(4, 2), "", 1000, "99.99", nil, warnDataOutOfRange},
+		{"decimal under range", MustCreateDecimalType(4, 2), "", "-1000", "-99.99", nil, warnDataOutOfRange},
+		{"strict decimal out of range", MustCreateDecimalType(4, 2), StrictTransTablesMode, 1000, nil, ErrValueOutOfRange, 0},
+		{"invalid decimal", MustCreateDecimalType(4, 2), "", "one", "0.00", nil, warnTruncatedWrongValueForField},
+		{"string", MustCreateStringWithDefaults(sqltypes.VarChar, 3), StrictTransTablesMode, "abc", "abc", nil, 0},
+		{"string too long", MustCreateStringWithDefaults(sqltypes.VarChar, 3), "", "abcd", "abc", nil, warnDataTruncated},
+		{"binary too long", MustCreateBinary(sqltypes.Binary, 3), "", "abcd", "abc", nil, warnDataTruncated},
+		{"strict string too long", MustCreateStringWithDefaults(sqltypes.VarChar, 3), StrictTransTablesMode, "abcd", nil, ErrDataTooLong, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := NewEmptyContext()
+			require.NoError(ctx.Set(ctx, SqlModeVar, LongText, tt.mode))
+
+			v, err := ConvertToColumn(ctx, tt.typ, "c", tt.value, 1)
+			if tt.err != nil {
+				require.True(tt.err.Is(err), "unexpected error %v", err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(tt.expected, v)
+			if tt.warning == 0 {
+				require.Empty(ctx.Warnings())
+			} else {
+				require.Len(ctx.Warnings(), 1)
+				require.Equal(tt.warning, ctx.Warnings()[0].Code)
+			}
+		})
+	}
+}
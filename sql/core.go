@@ -35,6 +35,25 @@ type TransformNodeFunc func(Node) (Node, error)
 // expression as is or transformed along with an error, if any.
 type TransformExprFunc func(Expression) (Expression, error)
 
+// TreeIdentity tells whether a transformation returned the same tree it was given (SameTree) or a new one (NewTree).
+// Transformations that report SameTree allow the nodes and expressions above them to be reused instead of rebuilt.
+type TreeIdentity bool
+
+const (
+	// SameTree means the tree returned by a transformation is the same one it was given.
+	SameTree TreeIdentity = true
+	// NewTree means the tree returned by a transformation is different from the one it was given.
+	NewTree TreeIdentity = false
+)
+
+// TransformNodeWithIdentityFunc is a function that given a node will return that node as is or transformed, whether
+// it was transformed, and an error, if any.
+type TransformNodeWithIdentityFunc func(Node) (Node, TreeIdentity, error)
+
+// TransformExprWithIdentityFunc is a function that given an expression will return that expression as is or
+// transformed, whether it was transformed, and an error, if any.
+type TransformExprWithIdentityFunc func(Expression) (Expression, TreeIdentity, error)
+
 // Expression is a combination of one or more SQL expressions.
 type Expression interface {
 	Resolvable
@@ -160,10 +179,11 @@ type Partition interface {
 	Key() []byte
 }
 
-// PartitionIter is an iterator that retrieves partitions.
+// PartitionIter is an iterator that retrieves partitions. Next receives the context of the query on every call, so
+// iterators that have to make calls to retrieve partitions can abort them when the query is interrupted.
 type PartitionIter interface {
 	io.Closer
-	Next() (Partition, error)
+	Next(*Context) (Partition, error)
 }
 
 // Table represents the backend of a SQL table.
@@ -172,6 +192,9 @@ type Table interface {
 	String() string
 	Schema() Schema
 	Partitions(*Context) (PartitionIter, error)
+	// PartitionRows returns an iterator over the rows of the partition given. The iterator must stop with the error of
+	// the context given once it's done, see Context.Interrupted, and the calls it makes to retrieve rows, like network
+	// calls, must be aborted when it's done, or its deadline is exceeded.
 	PartitionRows(*Context, Partition) (RowIter, error)
 }
 
@@ -207,6 +230,77 @@ type FilteredTable interface {
 	Filters() []Expression
 }
 
+// FilterCapabilities describes the filters that a table can evaluate itself. The zero value supports any filter.
+type FilterCapabilities struct {
+	// Columns are the names of the columns that supported filters can reference. If empty, filters can reference any
+	// column.
+	Columns []string
+	// ComparisonsOnly is whether only comparisons of columns and constant values are supported, possibly combined
+	// with AND, OR and NOT. Comparisons include =, <, <=, >, >=, IN with a tuple of values and IS NULL.
+	ComparisonsOnly bool
+}
+
+// FilterCapableTable is a FilteredTable that reports the filters it can evaluate. Only the parts of a filter that match
+// the capabilities of the table are offered to HandledFilters: the rest, as well as any filters not handled, are
+// evaluated by the engine on the rows returned by the table.
+type FilterCapableTable interface {
+	FilteredTable
+	// FilterCapabilities returns the filters this table can evaluate.
+	FilterCapabilities() FilterCapabilities
+}
+
+// SortColumn is a column by which the rows of an OrderedTable are sorted. Unless NullsLast is set, NULL values sort
+// before any other value, like MySQL does: they come first in ascending order and last in descending order.
+type SortColumn struct {
+	// Column is the name of the column.
+	Column string
+	// Descending is whether the rows are sorted in descending order of the column.
+	Descending bool
+	// NullsLast is whether NULL values sort after any other value instead, so they come last in ascending order and
+	// first in descending order. Tables that can't sort them that way must not accept it in CanOrder.
+	NullsLast bool
+}
+
+// String returns the column followed by its direction, and the position of NULL values if they're sorted last.
+func (o SortColumn) String() string {
+	switch {
+	case o.Descending && o.NullsLast:
+		return o.Column + " DESC NULLS FIRST"
+	case o.Descending:
+		return o.Column + " DESC"
+	case o.NullsLast:
+		return o.Column + " ASC NULLS LAST"
+	default:
+		return o.Column + " ASC"
+	}
+}
+
+// OrderedTable is a table that can return its rows sorted, such as one that keeps its data in key order, so the
+// engine doesn't need to sort them itself.
+type OrderedTable interface {
+	Table
+	// CanOrder returns whether the table can return its rows in the order given.
+	CanOrder(order []SortColumn) bool
+	// WithOrder returns a version of the table that returns its rows in the order given, which must have been
+	// accepted by CanOrder. The order applies to all the rows of the table: reading its partitions one after
+	// another, in the order they are returned by Partitions, must return the rows sorted.
+	WithOrder(order []SortColumn) Table
+	// Order returns the order of the rows of the table, or nil if they aren't sorted by the table.
+	Order() []SortColumn
+}
+
+// LimitedTable is a table that can stop returning rows once it has returned a number of them, such as a remote table
+// that can do its own top-k. The engine still applies the limit to the rows it reads from the table, so a table can
+// return more rows than its limit, like up to the limit from every partition, but never fewer. If the table is
+// also ordered, the rows returned must include the first ones in that order.
+type LimitedTable interface {
+	Table
+	// WithLimit returns a version of the table that only needs to return the number of rows given.
+	WithLimit(limit int64) Table
+	// Limit returns the limit of the rows of the table, and whether it has one.
+	Limit() (int64, bool)
+}
+
 // ProjectedTable is a table that can produce a specific RowIter
 // that's more optimized given the columns that are projected.
 type ProjectedTable interface {
@@ -259,6 +353,18 @@ type IndexAddressableTable interface {
 	WithIndexLookup(IndexLookup) Table
 }
 
+// MultiRangeReadTable is an IndexAddressableTable that can do a multi-range read of range and union index lookups:
+// rather than fetching the rows of the locations the lookup returns in the order of the index, it sorts the locations
+// in the order the rows are stored in, by their physical position or primary key, before fetching them, so that
+// disk-backed tables read their rows sequentially instead of randomly. The rows are returned in that order too.
+// SortIndexValues does the sorting for tables whose locations can be sorted by a key.
+type MultiRangeReadTable interface {
+	IndexAddressableTable
+	// WithMultiRangeRead returns a version of the table that will return only the rows specified by the given range or
+	// union IndexLookup, fetched in the order they're stored in.
+	WithMultiRangeRead(IndexLookup) Table
+}
+
 // IndexAlterableTable represents a table that supports index modification operations.
 type IndexAlterableTable interface {
 	Table
@@ -303,6 +409,33 @@ type ForeignKeyAlterableTable interface {
 	DropForeignKey(ctx *Context, fkName string) error
 }
 
+// CheckConstraint declares a constraint on the rows of a table, which can't be written if its expression is false for
+// them.
+type CheckConstraint struct {
+	Name string
+	// Expression is the text of the expression of the constraint, as it was declared.
+	Expression string
+	// Enforced is whether the constraint is checked on the rows written to the table.
+	Enforced bool
+}
+
+// CheckTable is a table that can declare its check constraints.
+type CheckTable interface {
+	Table
+	// GetChecks returns the check constraints on this table.
+	GetChecks(ctx *Context) ([]CheckConstraint, error)
+}
+
+// CheckAlterableTable represents a table that supports check constraint modification operations.
+type CheckAlterableTable interface {
+	Table
+	// CreateCheck creates a check constraint on this table. Returns an error if the check constraint name already
+	// exists.
+	CreateCheck(ctx *Context, check CheckConstraint) error
+	// DropCheck removes a check constraint from this table.
+	DropCheck(ctx *Context, name string) error
+}
+
 // InsertableTable is a table that can process insertion of new rows.
 type InsertableTable interface {
 	Table
@@ -321,6 +454,16 @@ type RowInserter interface {
 	Closer
 }
 
+// BulkInsertableTable is an InsertableTable that can insert many rows at once with the checks of its unique keys and
+// other constraints deferred until all of them are inserted, which is faster than checking every row as it's
+// inserted. Imports of dumps insert the rows of tables in bulk.
+type BulkInsertableTable interface {
+	InsertableTable
+	// BulkInserter returns an inserter that checks the constraints of the table when it's closed. If any of them is
+	// violated, Close returns the error and none of the rows given to the inserter are kept.
+	BulkInserter(*Context) RowInserter
+}
+
 // DeleteableTable is a table that can process the deletion of rows
 type DeletableTable interface {
 	Table
@@ -397,6 +540,19 @@ type Database interface {
 	GetTableNames(ctx *Context) ([]string, error)
 }
 
+// JoinPushdownDatabase is a Database that can execute joins between its own tables natively, such as one whose
+// tables all live in the same remote server, instead of having the engine join the rows of each table.
+type JoinPushdownDatabase interface {
+	Database
+
+	// PushdownJoin returns a table with the rows of the join given, or false if the database can't execute it, like
+	// when it involves tables of other databases. The join is made of join nodes, table aliases, filters,
+	// projections, decorated nodes and resolved tables, which may already have filters and projections pushed down
+	// to them, and its expressions don't have subqueries. The schema of the table returned must have the same
+	// columns, from the same sources, as the schema of the join.
+	PushdownJoin(ctx *Context, join Node) (Table, bool, error)
+}
+
 // VersionedDatabase is a Database that can return tables as they existed at different points in time. The engine
 // supports queries on historical table data via the AS OF construct introduced in SQL 2011.
 type VersionedDatabase interface {
@@ -412,6 +568,47 @@ type VersionedDatabase interface {
 	GetTableNamesAsOf(ctx *Context, asOf interface{}) ([]string, error)
 }
 
+// VersionedTable is a Table that can return its rows as they were at different points in time, for backends that keep
+// the history of some of their tables but not of their databases. Tables of VersionedDatabases are read AS OF a
+// revision by their database instead.
+type VersionedTable interface {
+	Table
+
+	// AsOf returns the table as it was at the revision given, or false if it didn't exist then. Implementors must
+	// choose which types of expressions to accept as revision names.
+	AsOf(ctx *Context, asOf interface{}) (Table, bool, error)
+}
+
+// SnapshotDatabase is a Database that can start read-only transactions with a consistent snapshot of its tables, like
+// the ones START TRANSACTION WITH CONSISTENT SNAPSHOT starts, so all of its tables can be read as they were at the same
+// point in time while they're being written. Dumps of databases read them in these transactions.
+type SnapshotDatabase interface {
+	Database
+
+	// StartSnapshotTransaction starts a read-only transaction, returning the database with its tables as they are when
+	// the transaction starts.
+	StartSnapshotTransaction(ctx *Context) (SnapshotTransaction, error)
+}
+
+// SnapshotTransaction is a read-only transaction of a SnapshotDatabase, whose tables are the ones of the database when
+// the transaction started.
+type SnapshotTransaction interface {
+	Database
+
+	// Commit finishes the transaction, after which its tables aren't read anymore.
+	Commit(ctx *Context) error
+}
+
+// SingleWriterDatabase is a Database whose backend only allows one writer at a time. The engine runs the statements
+// writing to it one at a time, in the order they come, letting the others wait in its WriteQueue, so integrators don't
+// have to lock around the inserts, updates, deletes and schema changes of its tables.
+type SingleWriterDatabase interface {
+	Database
+
+	// SingleWriter returns whether the statements writing to the database must run one at a time.
+	SingleWriter() bool
+}
+
 // TriggerDefinition defines a trigger. Integrators are not expected to parse or understand the trigger definitions,
 // but must store and return them when asked.
 type TriggerDefinition struct {
@@ -577,6 +774,39 @@ type Lockable interface {
 	Unlock(ctx *Context, id uint32) error
 }
 
+// ChecksumTable should be implemented by tables that can compute the checksum of their rows for CHECKSUM TABLE
+// statements without reading all of them. Tables that don't implement it have their rows read to compute it.
+type ChecksumTable interface {
+	Table
+	// Checksum returns the checksum of the rows of the table, which must be the same for the same rows.
+	Checksum(ctx *Context) (uint64, error)
+}
+
+// CheckableTable should be implemented by tables that can check their data for errors for CHECK TABLE statements.
+// Tables that don't implement it are reported to have no errors.
+type CheckableTable interface {
+	Table
+	// Check checks the data of the table and returns the errors found, if any. The error returned is only for failures
+	// to check the table.
+	Check(ctx *Context) ([]string, error)
+}
+
+// OptimizableTable should be implemented by tables that can reorganize their data for OPTIMIZE TABLE statements.
+// Tables that don't implement it are left as they are.
+type OptimizableTable interface {
+	Table
+	// Optimize reorganizes the data of the table to reduce its size and speed up its reads.
+	Optimize(ctx *Context) error
+}
+
+// AnalyzableTable should be implemented by tables that can update their statistics for ANALYZE TABLE statements, such
+// as by building the histograms of their columns. Tables that don't implement it are left as they are.
+type AnalyzableTable interface {
+	StatisticsTable
+	// Analyze updates the statistics of the table from its rows.
+	Analyze(ctx *Context) error
+}
+
 // EvaluateCondition evaluates a condition, which is an expression whose value
 // will be coerced to boolean.
 func EvaluateCondition(ctx *Context, cond Expression, row Row) (bool, error) {
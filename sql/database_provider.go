@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrImmutableDatabaseProvider is returned when databases are added to or removed from a catalog whose
+// DatabaseProvider doesn't implement MutableDatabaseProvider.
+var ErrImmutableDatabaseProvider = errors.NewKind("the database provider of the catalog can't add or remove databases")
+
+// DatabaseProvider gives a catalog its databases. Providers resolve databases by name on demand, so a catalog can hold
+// thousands of databases, such as one for each tenant of a server, without loading all of them up front. Databases
+// resolved by a provider are cached by the catalog for each session, so providers are only asked for a database the
+// first time a session uses it.
+type DatabaseProvider interface {
+	// Database returns the database with the name given, which is case insensitive, or ErrDatabaseNotFound if it
+	// doesn't exist.
+	Database(name string) (Database, error)
+	// HasDatabase returns whether the database with the name given exists. It's called much more often than
+	// Database, so it should be cheaper than resolving the database.
+	HasDatabase(name string) bool
+	// AllDatabases returns all the databases of the provider. It's only called by the statements that need every
+	// database, such as SHOW DATABASES and queries of information_schema.
+	AllDatabases() []Database
+}
+
+// MutableDatabaseProvider is a DatabaseProvider whose databases can be added and removed through its catalog.
+type MutableDatabaseProvider interface {
+	DatabaseProvider
+	// AddDatabase adds the database given.
+	AddDatabase(db Database)
+	// DropDatabase removes the database with the name given, which is case insensitive, or returns
+	// ErrDatabaseNotFound if it doesn't exist.
+	DropDatabase(name string) error
+}
+
+// databaseProvider is the MutableDatabaseProvider of the databases added to it.
+type databaseProvider struct {
+	mu  sync.RWMutex
+	dbs Databases
+}
+
+var _ MutableDatabaseProvider = (*databaseProvider)(nil)
+
+// NewDatabaseProvider returns a new MutableDatabaseProvider with the databases given, which keeps all its databases
+// in memory.
+func NewDatabaseProvider(dbs ...Database) MutableDatabaseProvider {
+	return &databaseProvider{dbs: append(Databases(nil), dbs...)}
+}
+
+// Database implements the DatabaseProvider interface.
+func (p *databaseProvider) Database(name string) (Database, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.dbs.Database(name)
+}
+
+// HasDatabase implements the DatabaseProvider interface.
+func (p *databaseProvider) HasDatabase(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, db := range p.dbs {
+		if strings.EqualFold(db.Name(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllDatabases implements the DatabaseProvider interface.
+func (p *databaseProvider) AllDatabases() []Database {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result := make([]Database, len(p.dbs))
+	copy(result, p.dbs)
+	return result
+}
+
+// AddDatabase implements the MutableDatabaseProvider interface.
+func (p *databaseProvider) AddDatabase(db Database) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dbs.Add(db)
+}
+
+// DropDatabase implements the MutableDatabaseProvider interface.
+func (p *databaseProvider) DropDatabase(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	db, err := p.dbs.Database(name)
+	if err != nil {
+		return err
+	}
+
+	dbs := make(Databases, 0, len(p.dbs)-1)
+	for _, d := range p.dbs {
+		if d != db {
+			dbs = append(dbs, d)
+		}
+	}
+	p.dbs = dbs
+	return nil
+}
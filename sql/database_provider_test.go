@@ -0,0 +1,37 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestDatabaseProvider(t *testing.T) {
+	require := require.New(t)
+
+	a := memory.NewDatabase("a")
+	b := memory.NewDatabase("b")
+	p := sql.NewDatabaseProvider(a)
+	p.AddDatabase(b)
+
+	require.Equal([]sql.Database{a, b}, p.AllDatabases())
+	require.True(p.HasDatabase("B"))
+	require.False(p.HasDatabase("c"))
+
+	db, err := p.Database("A")
+	require.NoError(err)
+	require.Equal(a, db)
+
+	_, err = p.Database("c")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+
+	require.NoError(p.DropDatabase("a"))
+	require.Equal([]sql.Database{b}, p.AllDatabases())
+	require.False(p.HasDatabase("a"))
+
+	err = p.DropDatabase("a")
+	require.True(sql.ErrDatabaseNotFound.Is(err))
+}
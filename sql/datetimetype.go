@@ -31,8 +31,9 @@ var (
 	// datetimeTypeMaxDatetime is the maximum representable Datetime/Date value.
 	datetimeTypeMaxDatetime = time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC)
 
-	// datetimeTypeMinDatetime is the minimum representable Datetime/Date value.
-	datetimeTypeMinDatetime = time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)
+	// datetimeTypeMinDatetime is the minimum representable Datetime/Date value. MySQL only supports values from
+	// 1000-01-01, but accepts earlier ones down to the first day of year 0.
+	datetimeTypeMinDatetime = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// datetimeTypeMaxTimestamp is the maximum representable Timestamp value, which is the maximum 32-bit integer as a Unix time.
 	datetimeTypeMaxTimestamp = time.Unix(math.MaxInt32, 999999000)
@@ -54,8 +55,10 @@ var (
 		"20060102",
 	}
 
-	// zeroTime is 0000-01-01 00:00:00 UTC which is the closest Go can get to 0000-00-00 00:00:00
-	zeroTime = time.Unix(-62167219200, 0).UTC()
+	// zeroTime is the zero date, 0000-00-00 00:00:00, which has no time.Time of its own. It's the first day of the
+	// year before year 0, which isn't a value of any temporal type, so it isn't mistaken for 0000-01-01 and it sorts
+	// before every other value.
+	zeroTime = time.Date(-1, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	// Date is a date with day, month and year.
 	Date = MustCreateDatetimeType(sqltypes.Date)
@@ -151,11 +154,11 @@ func (t datetimeType) Convert(v interface{}) (interface{}, error) {
 
 	switch t.baseType {
 	case sqltypes.Date:
-		if res.Year() < 1000 || res.Year() > 9999 {
+		if res.Before(datetimeTypeMinDatetime) || res.After(datetimeTypeMaxDatetime) {
 			return nil, ErrConvertingToTimeOutOfRange.New(res.Format(DateLayout), t.String())
 		}
 	case sqltypes.Datetime:
-		if res.Year() < 1000 || res.Year() > 9999 {
+		if res.Before(datetimeTypeMinDatetime) || res.After(datetimeTypeMaxDatetime) {
 			return nil, ErrConvertingToTimeOutOfRange.New(res.Format(TimestampDatetimeLayout), t.String())
 		}
 	case sqltypes.Timestamp:
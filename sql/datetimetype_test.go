@@ -164,8 +164,13 @@ func TestDatetimeConvert(t *testing.T) {
 		{Timestamp, "2010-06-03T12:12:12.000012Z", time.Date(2010, 6, 3, 12, 12, 12, 12000, time.UTC), false},
 		{Timestamp, "20100603", time.Date(2010, 6, 3, 0, 0, 0, 0, time.UTC), false},
 		{Timestamp, "20100603121212", time.Date(2010, 6, 3, 12, 12, 12, 0, time.UTC), false},
+		{Date, "0000-00-00", zeroTime, false},
+		{Date, "0000-01-01", time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{Date, time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC), time.Date(500, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{Datetime, "0000-00-00 00:00:00", zeroTime, false},
+		{Datetime, "0000-01-01 00:00:00", time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), false},
 
-		{Date, time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
+		{Date, time.Date(-500, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Date, time.Date(10000, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Date, "", nil, true},
 		{Date, "500-01-01", nil, true},
@@ -184,7 +189,7 @@ func TestDatetimeConvert(t *testing.T) {
 		{Date, float64(0), nil, true},
 		{Date, []byte{0}, nil, true},
 
-		{Datetime, time.Date(500, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
+		{Datetime, time.Date(-500, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Datetime, time.Date(10000, 1, 1, 1, 1, 1, 1, time.UTC), nil, true},
 		{Datetime, int(0), nil, true},
 		{Datetime, int8(0), nil, true},
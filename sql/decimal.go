@@ -158,6 +158,8 @@ func (t decimalType) ConvertToDecimal(v interface{}) (decimal.NullDecimal, error
 		return t.ConvertToDecimal(value.Text(10))
 	case *big.Rat:
 		return t.ConvertToDecimal(new(big.Float).SetRat(value))
+	case BinaryLiteral:
+		return t.ConvertToDecimal(value.Uint64())
 	case decimal.Decimal:
 		res = value
 	case decimal.NullDecimal:
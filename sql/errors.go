@@ -57,8 +57,12 @@ var (
 	// ErrDuplicateAlias should be returned when a query contains a duplicate alias / table name.
 	ErrDuplicateAliasOrTable = errors.NewKind("Not unique table/alias: %s")
 
-	// ErrUniqueKeyViolation is returned when a unique key constraint is violated
-	ErrUniqueKeyViolation = errors.NewKind("duplicate unique key for %s")
+	// ErrColumnNamesCount is returned when the column names given for a subquery aren't as many as its columns.
+	ErrColumnNamesCount = errors.NewKind("In definition of view, derived table or common table expression, SELECT list and column names list have different column counts")
+
+	// ErrUniqueKeyViolation is returned when a unique key constraint is violated, with the duplicated values and the
+	// name of the key. Servers report it to clients as the MySQL error ER_DUP_ENTRY (1062).
+	ErrUniqueKeyViolation = errors.NewKind("Duplicate entry '%s' for key '%s'")
 
 	// ErrMisusedAlias is returned when a alias is defined and used in the same projection.
 	ErrMisusedAlias = errors.NewKind("column %q does not exist in scope, but there is an alias defined in" +
@@ -109,6 +113,9 @@ var (
 	// ErrUnknownSystemVariable is returned when a query references a system variable that doesn't exist
 	ErrUnknownSystemVariable = errors.NewKind(`Unknown system variable '%s'`)
 
+	// ErrGlobalVariable is returned when the session value of a system variable that only has a global value is set
+	ErrGlobalVariable = errors.NewKind(`Variable '%s' is a GLOBAL variable and should be set with SET GLOBAL`)
+
 	// ErrInvalidUseOfOldNew is returned when a trigger attempts to make use of OLD or NEW references when they don't exist
 	ErrInvalidUseOfOldNew = errors.NewKind("There is no %s row in on %s trigger")
 
@@ -117,4 +124,66 @@ var (
 
 	// ErrInvalidUpdateInAfterTrigger is returned when a trigger attempts to assign to a new row in an AFTER trigger
 	ErrInvalidUpdateInAfterTrigger = errors.NewKind("Updating of new row is not allowed in after trigger")
+
+	// ErrNoTablesUsed is returned when a query selects all the columns with * but has no tables, such as SELECT * FROM DUAL
+	ErrNoTablesUsed = errors.NewKind("No tables used")
+
+	// ErrTooManyConcurrentQueries is returned when a query isn't admitted because the engine is running the maximum
+	// number of queries set in max_concurrent_queries
+	ErrTooManyConcurrentQueries = errors.NewKind("Too many concurrent queries, the maximum is %d")
+
+	// ErrTooManyUserQueries is returned when a query isn't admitted because its user is running the maximum number of
+	// queries set in max_user_concurrent_queries
+	ErrTooManyUserQueries = errors.NewKind("User %s already has more than 'max_user_concurrent_queries' active queries (%d)")
+
+	// ErrTooManyDigestQueries is returned when a query isn't admitted because the engine is running the maximum number
+	// of queries with its digest set in max_digest_concurrent_queries
+	ErrTooManyDigestQueries = errors.NewKind("Too many concurrent queries like this one, the maximum is %d")
+
+	// ErrResourceGroupExists is returned when creating a resource group with the name of an existing one
+	ErrResourceGroupExists = errors.NewKind("Resource Group '%s' exists")
+
+	// ErrResourceGroupNotExists is returned when a statement references a resource group that doesn't exist
+	ErrResourceGroupNotExists = errors.NewKind("Resource Group '%s' does not exist.")
+
+	// ErrResourceGroupBusy is returned when dropping a resource group assigned to sessions without FORCE
+	ErrResourceGroupBusy = errors.NewKind("Resource group %s is busy.")
+
+	// ErrResourceGroupDisabled is returned when assigning a disabled resource group to a session
+	ErrResourceGroupDisabled = errors.NewKind("Resource group %s is disabled.")
+
+	// ErrResourceGroupBind is returned when a resource group can't be assigned to a session
+	ErrResourceGroupBind = errors.NewKind("Unable to bind resource group %s with thread id (%d).(%s).")
+
+	// ErrDefaultResourceGroup is returned when altering or dropping one of the default resource groups
+	ErrDefaultResourceGroup = errors.NewKind("Operation %s is disallowed on %s")
+
+	// ErrInvalidThreadPriority is returned when the thread priority of a resource group is out of the range of its type
+	ErrInvalidThreadPriority = errors.NewKind("Invalid thread priority value %d for %s resource group %s. Allowed range is [%d, %d].")
+
+	// ErrInvalidVCPUID is returned when a resource group has the id of a VCPU the machine doesn't have
+	ErrInvalidVCPUID = errors.NewKind("Invalid cpu id %d")
+
+	// ErrPlanBaselineStatement is returned when pinning the plan of a statement that isn't a single SELECT
+	ErrPlanBaselineStatement = errors.NewKind("Plans can only be pinned for SELECT statements without UNION, not %s")
+
+	// ErrInvalidVCPURange is returned when a range of VCPU ids of a resource group ends before it starts
+	ErrInvalidVCPURange = errors.NewKind("Invalid VCPU range %d-%d")
+
+	// ErrReadOnly is returned when a statement that modifies databases is run while read_only or super_read_only is set
+	ErrReadOnly = errors.NewKind("The MySQL server is running with the %s option so it cannot execute this statement")
+
+	// ErrImportStatement is returned when a statement of an import fails, with the error of the statement as its cause.
+	ErrImportStatement = errors.NewKind("statement on line %d of the import failed")
 )
+
+// ConditionError is the error of an exception condition raised by SIGNAL or RESIGNAL. Servers return it to clients
+// with the MySQL error number and SQLSTATE of its condition.
+type ConditionError struct {
+	*Warning
+}
+
+// Error implements the error interface.
+func (e *ConditionError) Error() string {
+	return e.Message
+}
@@ -27,6 +27,11 @@ func (e *Alias) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	return e.Child.Eval(ctx, row)
 }
 
+// Eval2 implements the sql.Expression2 interface.
+func (e *Alias) Eval2(ctx *sql.Context, row sql.Row2) (sql.Value, error) {
+	return e.Child.(sql.Expression2).Eval2(ctx, row)
+}
+
 func (e *Alias) String() string {
 	return fmt.Sprintf("%s as %s", e.Child, e.name)
 }
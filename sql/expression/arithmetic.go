@@ -105,18 +105,20 @@ func (a *Arithmetic) IsNullable() bool {
 
 // Type returns the greatest type for given operation.
 func (a *Arithmetic) Type() sql.Type {
+	leftType, rightType := operandType(a.Left), operandType(a.Right)
+
 	switch strings.ToLower(a.Op) {
 	case sqlparser.PlusStr, sqlparser.MinusStr, sqlparser.MultStr, sqlparser.DivStr:
 		if isInterval(a.Left) || isInterval(a.Right) {
 			return sql.Datetime
 		}
 
-		if sql.IsTime(a.Left.Type()) && sql.IsTime(a.Right.Type()) {
+		if sql.IsTime(leftType) && sql.IsTime(rightType) {
 			return sql.Int64
 		}
 
-		if sql.IsInteger(a.Left.Type()) && sql.IsInteger(a.Right.Type()) {
-			if sql.IsUnsigned(a.Left.Type()) && sql.IsUnsigned(a.Right.Type()) {
+		if sql.IsInteger(leftType) && sql.IsInteger(rightType) {
+			if sql.IsUnsigned(leftType) && sql.IsUnsigned(rightType) {
 				return sql.Uint64
 			}
 			return sql.Int64
@@ -128,7 +130,7 @@ func (a *Arithmetic) Type() sql.Type {
 		return sql.Uint64
 
 	case sqlparser.BitAndStr, sqlparser.BitOrStr, sqlparser.BitXorStr, sqlparser.IntDivStr, sqlparser.ModStr:
-		if sql.IsUnsigned(a.Left.Type()) && sql.IsUnsigned(a.Right.Type()) {
+		if sql.IsUnsigned(leftType) && sql.IsUnsigned(rightType) {
 			return sql.Uint64
 		}
 		return sql.Int64
@@ -137,6 +139,24 @@ func (a *Arithmetic) Type() sql.Type {
 	return sql.Float64
 }
 
+// operandType returns the type of an operand of an arithmetic operation, which is an unsigned integer for hexadecimal
+// and bit-value literals, since they are numbers in numeric contexts.
+func operandType(expr sql.Expression) sql.Type {
+	if isBinaryLiteral(expr) {
+		return sql.Uint64
+	}
+	return expr.Type()
+}
+
+func isBinaryLiteral(expr sql.Expression) bool {
+	l, ok := expr.(*Literal)
+	if !ok {
+		return false
+	}
+	_, ok = l.Value().(sql.BinaryLiteral)
+	return ok
+}
+
 func isInterval(expr sql.Expression) bool {
 	_, ok := expr.(*Interval)
 	return ok
@@ -515,7 +535,15 @@ func (e *UnaryMinus) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, nil
 	}
 
-	if !sql.IsNumber(e.Child.Type()) {
+	if isBinaryLiteral(e.Child) {
+		child = sql.Uint64.MustConvert(child)
+	} else if sql.IsDecimal(e.Child.Type()) {
+		dec, err := e.Child.Type().(sql.DecimalType).ConvertToDecimal(child)
+		if err != nil {
+			return nil, err
+		}
+		return dec.Decimal.Neg().String(), nil
+	} else if !sql.IsNumber(e.Child.Type()) {
 		child, err = sql.Float64.Convert(child)
 		if err != nil {
 			child = 0.0
@@ -554,7 +582,7 @@ func (e *UnaryMinus) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 
 // Type implements the sql.Expression interface.
 func (e *UnaryMinus) Type() sql.Type {
-	typ := e.Child.Type()
+	typ := operandType(e.Child)
 	if !sql.IsNumber(typ) {
 		return sql.Float64
 	}
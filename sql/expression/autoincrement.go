@@ -11,7 +11,8 @@ import (
 // AutoIncrement represents a literal expression (string, number, bool, ...).
 type AutoIncrement struct {
 	BinaryExpression
-	lastInsertId *Literal
+	lastInsertId   *Literal
+	firstGenerated interface{}
 	sync.Once
 }
 
@@ -23,9 +24,7 @@ func NewAutoIncrement(lastInsertId, given sql.Expression) (*AutoIncrement, error
 	}
 
 	return &AutoIncrement{
-		BinaryExpression{Left: lastInsertId, Right: given},
-		nil,
-		sync.Once{},
+		BinaryExpression: BinaryExpression{Left: lastInsertId, Right: given},
 	}, nil
 }
 
@@ -80,6 +79,9 @@ func (i *AutoIncrement) Eval(ctx *sql.Context, row sql.Row) (interface{}, error)
 			return nil, err
 		}
 		i.lastInsertId = NewLiteral(id, i.Type())
+		if i.firstGenerated == nil {
+			i.firstGenerated = id
+		}
 	} else {
 		// last_insert_id = max(given, last_insert_id)
 		cmp, err := i.Type().Compare(val, i.lastInsertId.value)
@@ -105,12 +107,17 @@ func (i *AutoIncrement) WithChildren(children ...sql.Expression) (sql.Expression
 		return nil, sql.ErrInvalidChildrenNumber.New(i, len(children), 1)
 	}
 	return &AutoIncrement{
-		BinaryExpression{Left: children[0], Right: children[1]},
-		i.lastInsertId,
-		sync.Once{},
+		BinaryExpression: BinaryExpression{Left: children[0], Right: children[1]},
+		lastInsertId:     i.lastInsertId,
 	}, nil
 }
 
+// FirstGenerated returns the first value this expression generated because the value given for its column was NULL
+// or 0, and whether it generated any.
+func (i *AutoIncrement) FirstGenerated() (interface{}, bool) {
+	return i.firstGenerated, i.firstGenerated != nil
+}
+
 // Children implements the Expression interface.
 func (i *AutoIncrement) Children() []sql.Expression {
 	return []sql.Expression{i.Left, i.Right}
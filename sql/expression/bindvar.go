@@ -0,0 +1,81 @@
+package expression
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrUnboundBindVar is returned when a bind variable is evaluated, which happens when a prepared statement is executed
+// without a value for one of its parameters.
+var ErrUnboundBindVar = errors.NewKind("no value bound to the bind variable %s")
+
+// BindVar is a parameter of a prepared statement, written as ? or :name in the statement, in place of a value that's
+// bound to it every time the statement is executed. Its Name is the one its value is bound with, which for the
+// parameters written as ? is the letter v followed by their position, starting from 1.
+type BindVar struct {
+	Name string
+}
+
+var _ sql.Expression = (*BindVar)(nil)
+
+// NewBindVar creates a new bind variable with the name given.
+func NewBindVar(name string) *BindVar {
+	return &BindVar{Name: name}
+}
+
+// Resolved implements the sql.Expression interface.
+func (*BindVar) Resolved() bool {
+	return true
+}
+
+// IsNullable implements the sql.Expression interface.
+func (*BindVar) IsNullable() bool {
+	return true
+}
+
+// Type implements the sql.Expression interface. The type of a bind variable is unknown until its value is bound.
+func (*BindVar) Type() sql.Type {
+	return sql.LongText
+}
+
+// Eval implements the sql.Expression interface. Bind variables are replaced by their values before evaluating any
+// expression.
+func (b *BindVar) Eval(*sql.Context, sql.Row) (interface{}, error) {
+	return nil, ErrUnboundBindVar.New(b.Name)
+}
+
+// Children implements the sql.Expression interface.
+func (*BindVar) Children() []sql.Expression {
+	return nil
+}
+
+// WithChildren implements the sql.Expression interface.
+func (b *BindVar) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(b, len(children), 0)
+	}
+	return b, nil
+}
+
+// String returns the bind variable as it's written in a statement: ? for the parameters written as ?, and :name for the
+// others.
+func (b *BindVar) String() string {
+	if isPositional(b.Name) {
+		return "?"
+	}
+	return ":" + b.Name
+}
+
+// isPositional returns whether the name given is the name of a parameter written as ?.
+func isPositional(name string) bool {
+	if len(name) < 2 || name[0] != 'v' {
+		return false
+	}
+	for _, r := range name[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -48,6 +48,10 @@ func (c *comparison) Compare(ctx *sql.Context, row sql.Row) (int, error) {
 		return c.Left().Type().Compare(left, right)
 	}
 
+	if typ, ok := temporalComparisonType(c.Left().Type(), c.Right().Type()); ok {
+		return compareTemporal(ctx, typ, left, right)
+	}
+
 	var compareType sql.Type
 	left, right, compareType, err = c.castLeftAndRight(left, right)
 	if err != nil {
@@ -72,55 +76,74 @@ func (c *comparison) evalLeftAndRight(ctx *sql.Context, row sql.Row) (interface{
 }
 
 func (c *comparison) castLeftAndRight(left, right interface{}) (interface{}, interface{}, sql.Type, error) {
-	leftType := c.Left().Type()
-	rightType := c.Right().Type()
+	convertTo, compareType := comparisonType(c.Left().Type(), c.Right().Type())
+	l, r, err := convertLeftAndRight(left, right, convertTo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return l, r, compareType, nil
+}
+
+// comparisonType returns the type the operands of a comparison between values of the types given must be converted
+// to, and the type used to compare them once converted.
+func comparisonType(leftType, rightType sql.Type) (string, sql.Type) {
 	if sql.IsNumber(leftType) || sql.IsNumber(rightType) {
 		if sql.IsDecimal(leftType) || sql.IsDecimal(rightType) {
 			//TODO: We need to set to the actual DECIMAL type
-			l, r, err := convertLeftAndRight(left, right, ConvertToDecimal)
-			if err != nil {
-				return nil, nil, nil, err
-			}
-
 			if sql.IsDecimal(leftType) {
-				return l, r, leftType, nil
+				return ConvertToDecimal, leftType
 			} else {
-				return l, r, rightType, nil
+				return ConvertToDecimal, rightType
 			}
 		}
 
 		if sql.IsFloat(leftType) || sql.IsFloat(rightType) {
-			l, r, err := convertLeftAndRight(left, right, ConvertToDouble)
-			if err != nil {
-				return nil, nil, nil, err
-			}
-
-			return l, r, sql.Float64, nil
+			return ConvertToDouble, sql.Float64
 		}
 
 		if sql.IsSigned(leftType) || sql.IsSigned(rightType) {
-			l, r, err := convertLeftAndRight(left, right, ConvertToSigned)
-			if err != nil {
-				return nil, nil, nil, err
-			}
-
-			return l, r, sql.Int64, nil
+			return ConvertToSigned, sql.Int64
 		}
 
-		l, r, err := convertLeftAndRight(left, right, ConvertToUnsigned)
-		if err != nil {
-			return nil, nil, nil, err
-		}
+		return ConvertToUnsigned, sql.Uint64
+	}
+
+	return ConvertToChar, sql.LongText
+}
+
+// temporalComparisonType returns the temporal type of the operands of a comparison between values of the types given,
+// when one of them is a temporal type and the other isn't a number.
+func temporalComparisonType(leftType, rightType sql.Type) (sql.DatetimeType, bool) {
+	if sql.IsNumber(leftType) || sql.IsNumber(rightType) {
+		return nil, false
+	}
+	if dt, ok := leftType.(sql.DatetimeType); ok {
+		return dt, true
+	}
+	dt, ok := rightType.(sql.DatetimeType)
+	return dt, ok
+}
 
-		return l, r, sql.Uint64, nil
+// compareTemporal compares the values given as datetimes, so the time of the values of a DATE operand isn't lost. When
+// one of them isn't a valid value of the temporal type given, they're compared as strings instead, with a warning.
+func compareTemporal(ctx *sql.Context, typ sql.DatetimeType, left, right interface{}) (int, error) {
+	l, lerr := sql.Datetime.Convert(left)
+	r, rerr := sql.Datetime.Convert(right)
+	if lerr == nil && rerr == nil {
+		return sql.Datetime.Compare(l, r)
 	}
 
-	left, right, err := convertLeftAndRight(left, right, ConvertToChar)
+	if lerr != nil {
+		sql.WarnIncorrectTemporal(ctx, typ, left)
+	} else {
+		sql.WarnIncorrectTemporal(ctx, typ, right)
+	}
+	l, r, err := convertLeftAndRight(left, right, ConvertToChar)
 	if err != nil {
-		return nil, nil, nil, err
+		return 0, err
 	}
-
-	return left, right, sql.LongText, nil
+	return sql.LongText.Compare(l, r)
 }
 
 func convertLeftAndRight(left, right interface{}, convertTo string) (interface{}, interface{}, error) {
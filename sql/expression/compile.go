@@ -0,0 +1,269 @@
+package expression
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// evalFunc is the evaluation of a compiled expression.
+type evalFunc func(ctx *sql.Context, row sql.Row) (interface{}, error)
+
+// CompiledExpression is an expression whose evaluation was compiled into a tree of closures. Every closure is built
+// for one kind of expression, with its operands and any decisions that only depend on the types involved resolved
+// beforehand, so evaluating it doesn't dispatch through the Expression interface at every node. Expressions that
+// can't be compiled are evaluated as usual, as part of the program of their parent.
+type CompiledExpression struct {
+	sql.Expression
+	eval evalFunc
+}
+
+var _ sql.Expression = (*CompiledExpression)(nil)
+
+// Compile returns the compiled form of the expression given. If the expression can't be compiled it's returned
+// unchanged. Expressions are meant to be compiled once they're fully analyzed, right before being evaluated over
+// many rows.
+func Compile(e sql.Expression) sql.Expression {
+	if _, ok := e.(*CompiledExpression); ok {
+		return e
+	}
+
+	eval, ok := compile(e)
+	if !ok {
+		return e
+	}
+
+	return &CompiledExpression{Expression: e, eval: eval}
+}
+
+// Eval implements the Expression interface.
+func (c *CompiledExpression) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return c.eval(ctx, row)
+}
+
+func (c *CompiledExpression) DebugString() string {
+	return sql.DebugString(c.Expression)
+}
+
+// compile returns the evaluation of the expression given, and whether it was compiled or is just its Eval method.
+func compile(e sql.Expression) (evalFunc, bool) {
+	switch e := e.(type) {
+	case *CompiledExpression:
+		return e.eval, true
+	case *GetField:
+		return compileGetField(e), true
+	case *Literal:
+		return compileLiteral(e), true
+	case *Alias:
+		return compileExpr(e.Child), true
+	case *And:
+		return compileAnd(e), true
+	case *Or:
+		return compileOr(e), true
+	case *Not:
+		return compileNot(e), true
+	case *IsNull:
+		return compileIsNull(e), true
+	case *Equals:
+		return compileComparison(&e.comparison, func(n int) bool { return n == 0 }), true
+	case *GreaterThan:
+		return compileComparison(&e.comparison, func(n int) bool { return n == 1 }), true
+	case *LessThan:
+		return compileComparison(&e.comparison, func(n int) bool { return n == -1 }), true
+	case *GreaterThanOrEqual:
+		return compileComparison(&e.comparison, func(n int) bool { return n > -1 }), true
+	case *LessThanOrEqual:
+		return compileComparison(&e.comparison, func(n int) bool { return n < 1 }), true
+	default:
+		return e.Eval, false
+	}
+}
+
+func compileExpr(e sql.Expression) evalFunc {
+	eval, _ := compile(e)
+	return eval
+}
+
+func compileGetField(e *GetField) evalFunc {
+	idx := e.fieldIndex
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		if idx < 0 || idx >= len(row) {
+			return nil, ErrIndexOutOfBounds.New(idx, len(row))
+		}
+		return row[idx], nil
+	}
+}
+
+func compileLiteral(e *Literal) evalFunc {
+	val := e.value
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		return val, nil
+	}
+}
+
+func compileAnd(e *And) evalFunc {
+	left, right := compileExpr(e.Left), compileExpr(e.Right)
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		lval, err := left(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if lval != nil {
+			lvalBool, err := sql.ConvertToBool(lval)
+			if err == nil && lvalBool == false {
+				return false, nil
+			}
+		}
+
+		rval, err := right(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if rval != nil {
+			rvalBool, err := sql.ConvertToBool(rval)
+			if err == nil && rvalBool == false {
+				return false, nil
+			}
+		}
+
+		if lval == nil || rval == nil {
+			return nil, nil
+		}
+
+		return true, nil
+	}
+}
+
+func compileOr(e *Or) evalFunc {
+	left, right := compileExpr(e.Left), compileExpr(e.Right)
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		lval, err := left(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if lval != nil {
+			lvalBool, err := sql.ConvertToBool(lval)
+			if err == nil && lvalBool {
+				return true, nil
+			}
+		}
+
+		rval, err := right(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if rval != nil {
+			rvalBool, err := sql.ConvertToBool(rval)
+			if err == nil && rvalBool {
+				return true, nil
+			}
+		}
+
+		if lval == nil && rval == nil {
+			return nil, nil
+		}
+
+		return rval == true, nil
+	}
+}
+
+func compileNot(e *Not) evalFunc {
+	child := compileExpr(e.Child)
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		v, err := child(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if v == nil {
+			return nil, nil
+		}
+
+		b, ok := v.(bool)
+		if !ok {
+			b, err = sql.ConvertToBool(v)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return !b, nil
+	}
+}
+
+func compileIsNull(e *IsNull) evalFunc {
+	child := compileExpr(e.Child)
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		v, err := child(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		return v == nil, nil
+	}
+}
+
+// compileComparison compiles a comparison whose result is given by test on the result of comparing both operands.
+// The type used to compare the operands, and the conversion they need, are decided once from their types.
+func compileComparison(c *comparison, test func(int) bool) evalFunc {
+	left, right := compileExpr(c.Left()), compileExpr(c.Right())
+	compare := compileCompare(c.Left().Type(), c.Right().Type())
+
+	return func(ctx *sql.Context, row sql.Row) (interface{}, error) {
+		lval, err := left(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		rval, err := right(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if lval == nil || rval == nil {
+			return nil, nil
+		}
+
+		n, err := compare(lval, rval)
+		if err != nil {
+			return nil, err
+		}
+
+		return test(n), nil
+	}
+}
+
+func compileCompare(leftType, rightType sql.Type) func(left, right interface{}) (int, error) {
+	if leftType != rightType {
+		convertTo, compareType := comparisonType(leftType, rightType)
+		return func(left, right interface{}) (int, error) {
+			l, r, err := convertLeftAndRight(left, right, convertTo)
+			if err != nil {
+				return 0, err
+			}
+			return compareType.Compare(l, r)
+		}
+	}
+
+	if leftType == sql.Int64 {
+		return func(left, right interface{}) (int, error) {
+			l, lok := left.(int64)
+			r, rok := right.(int64)
+			if !lok || !rok {
+				return sql.Int64.Compare(left, right)
+			}
+
+			switch {
+			case l < r:
+				return -1, nil
+			case l > r:
+				return 1, nil
+			default:
+				return 0, nil
+			}
+		}
+	}
+
+	return leftType.Compare
+}
@@ -0,0 +1,110 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestCompile(t *testing.T) {
+	i := NewGetField(0, sql.Int64, "i", true)
+	f := NewGetField(1, sql.Float64, "f", true)
+	s := NewGetField(2, sql.LongText, "s", true)
+	b := NewGetField(3, sql.Boolean, "b", true)
+
+	rows := []sql.Row{
+		sql.NewRow(int64(1), 1.5, "foo", true),
+		sql.NewRow(int64(2), 2.0, "bar", false),
+		sql.NewRow(int64(-3), nil, "", nil),
+		sql.NewRow(nil, 0.0, nil, true),
+	}
+
+	testCases := []struct {
+		name  string
+		expr  sql.Expression
+		nodes bool
+	}{
+		{"get field", i, true},
+		{"literal", NewLiteral(int64(2), sql.Int64), true},
+		{"alias", NewAlias("a", s), true},
+		{"equals int", NewEquals(i, NewLiteral(int64(2), sql.Int64)), true},
+		{"equals mixed types", NewEquals(i, NewLiteral(2.0, sql.Float64)), true},
+		{"equals text and number", NewEquals(s, NewLiteral(int8(0), sql.Int8)), true},
+		{"greater than", NewGreaterThan(f, NewLiteral(1.5, sql.Float64)), true},
+		{"less than", NewLessThan(i, f), true},
+		{"greater than or equal", NewGreaterThanOrEqual(s, NewLiteral("bar", sql.LongText)), true},
+		{"less than or equal", NewLessThanOrEqual(i, NewLiteral(int32(1), sql.Int32)), true},
+		{"and", NewAnd(b, NewGreaterThan(i, NewLiteral(int64(0), sql.Int64))), true},
+		{"or", NewOr(NewIsNull(s), NewLessThan(f, NewLiteral(1.0, sql.Float64))), true},
+		{"not", NewNot(b), true},
+		{"is null", NewIsNull(f), true},
+		{"uncompiled child", NewNot(NewIsTrue(b)), true},
+		{"uncompiled root", NewArithmetic(i, NewLiteral(int64(1), sql.Int64), "+"), false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := sql.NewEmptyContext()
+
+			compiled := Compile(tt.expr)
+			if !tt.nodes {
+				require.Equal(tt.expr, compiled)
+				return
+			}
+
+			require.IsType(&CompiledExpression{}, compiled)
+			require.Equal(tt.expr.String(), compiled.String())
+			require.Equal(tt.expr.Type(), compiled.Type())
+			require.Equal(compiled, Compile(compiled))
+
+			for _, row := range rows {
+				expected, err := tt.expr.Eval(ctx, row)
+				require.NoError(err)
+
+				result, err := compiled.Eval(ctx, row)
+				require.NoError(err)
+				require.Equal(expected, result, "row %v", row)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	require := require.New(t)
+
+	compiled := Compile(NewEquals(NewGetField(3, sql.Int64, "i", false), NewLiteral(int64(1), sql.Int64)))
+	_, err := compiled.Eval(sql.NewEmptyContext(), sql.NewRow(int64(1)))
+	require.True(ErrIndexOutOfBounds.Is(err))
+}
+
+func BenchmarkCompile(b *testing.B) {
+	ctx := sql.NewEmptyContext()
+	expr := NewAnd(
+		NewGreaterThan(NewGetField(0, sql.Int64, "i", false), NewLiteral(int64(10), sql.Int64)),
+		NewOr(
+			NewIsNull(NewGetField(1, sql.LongText, "s", true)),
+			NewLessThanOrEqual(NewGetField(0, sql.Int64, "i", false), NewLiteral(int64(1000), sql.Int64)),
+		),
+	)
+	row := sql.NewRow(int64(500), "foo")
+
+	b.Run("interpreted", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := expr.Eval(ctx, row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	compiled := Compile(expr)
+	b.Run("compiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := compiled.Eval(ctx, row); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
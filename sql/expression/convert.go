@@ -126,6 +126,11 @@ func (c *Convert) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, ErrConvertExpression.Wrap(err, c.String(), c.castToType)
 	}
 
+	// Values that can't be cast to temporal types are NULL, with a warning
+	if dt, ok := c.Type().(sql.DatetimeType); ok {
+		return sql.ValidateTemporal(ctx, dt, val, casted), nil
+	}
+
 	return casted, nil
 }
 
@@ -256,6 +256,10 @@ func Crc32Func(_ *sql.Context, arg interface{}) (interface{}, error) {
 	switch val := arg.(type) {
 	case string:
 		bytes = []byte(val)
+	case []byte:
+		bytes = val
+	case sql.BinaryLiteral:
+		bytes = val
 	case int8, int16, int32, int64, int:
 		val, err := sql.Int64.Convert(arg)
 
@@ -0,0 +1,105 @@
+package function
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func foundRowsFuncLogic(ctx *sql.Context, _ sql.Row) (interface{}, error) {
+	return ctx.GetLastQueryInfo(sql.FoundRows), nil
+}
+
+func rowCountFuncLogic(ctx *sql.Context, _ sql.Row) (interface{}, error) {
+	return ctx.GetLastQueryInfo(sql.RowCount), nil
+}
+
+// LastInsertId returns the first value generated for an AUTO_INCREMENT column by the last INSERT of the session that
+// generated any. If it has an argument, it returns the value of the argument instead, which LAST_INSERT_ID() without
+// arguments returns from then on.
+type LastInsertId struct {
+	Child sql.Expression
+}
+
+var _ sql.Expression = (*LastInsertId)(nil)
+var _ sql.NonDeterministicExpression = (*LastInsertId)(nil)
+var _ sql.FunctionExpression = (*LastInsertId)(nil)
+
+// NewLastInsertId creates a new LastInsertId expression.
+func NewLastInsertId(exprs ...sql.Expression) (sql.Expression, error) {
+	if len(exprs) > 1 {
+		return nil, sql.ErrInvalidArgumentNumber.New("last_insert_id", "0 or 1", len(exprs))
+	}
+	if len(exprs) > 0 {
+		return &LastInsertId{Child: exprs[0]}, nil
+	}
+	return &LastInsertId{}, nil
+}
+
+// FunctionName implements sql.FunctionExpression
+func (l *LastInsertId) FunctionName() string {
+	return "last_insert_id"
+}
+
+// Type implements sql.Expression.
+func (l *LastInsertId) Type() sql.Type {
+	return sql.Uint64
+}
+
+// IsNonDeterministic implements sql.NonDeterministicExpression
+func (l *LastInsertId) IsNonDeterministic() bool {
+	return true
+}
+
+// IsNullable implements sql.Expression
+func (l *LastInsertId) IsNullable() bool {
+	return l.Child != nil && l.Child.IsNullable()
+}
+
+// Resolved implements sql.Expression
+func (l *LastInsertId) Resolved() bool {
+	return l.Child == nil || l.Child.Resolved()
+}
+
+func (l *LastInsertId) String() string {
+	if l.Child != nil {
+		return fmt.Sprintf("LAST_INSERT_ID(%s)", l.Child)
+	}
+	return "LAST_INSERT_ID()"
+}
+
+// WithChildren implements sql.Expression.
+func (l *LastInsertId) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) > 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 1)
+	}
+	return NewLastInsertId(children...)
+}
+
+// Children implements sql.Expression
+func (l *LastInsertId) Children() []sql.Expression {
+	if l.Child == nil {
+		return nil
+	}
+	return []sql.Expression{l.Child}
+}
+
+// Eval implements sql.Expression.
+func (l *LastInsertId) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	if l.Child == nil {
+		return uint64(ctx.GetLastQueryInfo(sql.LastInsertId)), nil
+	}
+
+	v, err := l.Child.Eval(ctx, row)
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	id, err := sql.Uint64.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.SetLastQueryInfo(sql.LastInsertId, int64(id.(uint64)))
+	return id, nil
+}
@@ -0,0 +1,72 @@
+package function
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestFoundRowsAndRowCount(t *testing.T) {
+	require := require.New(t)
+
+	session := sql.NewSession("", "", "", 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+
+	foundRows := sql.NewFunction0("found_rows", sql.Int64, foundRowsFuncLogic).Fn()
+	rowCount := sql.NewFunction0("row_count", sql.Int64, rowCountFuncLogic).Fn()
+
+	result, err := foundRows.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(1), result)
+	result, err = rowCount.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(0), result)
+
+	session.SetLastQueryInfo(sql.FoundRows, 42)
+	session.SetLastQueryInfo(sql.RowCount, -1)
+	result, err = foundRows.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(42), result)
+	result, err = rowCount.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(int64(-1), result)
+}
+
+func TestLastInsertId(t *testing.T) {
+	require := require.New(t)
+
+	session := sql.NewSession("", "", "", 1)
+	ctx := sql.NewContext(context.Background(), sql.WithSession(session))
+
+	get, err := NewLastInsertId()
+	require.NoError(err)
+	result, err := get.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(0), result)
+
+	session.SetLastQueryInfo(sql.LastInsertId, 10)
+	result, err = get.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(10), result)
+
+	set, err := NewLastInsertId(expression.NewLiteral(int8(42), sql.Int8))
+	require.NoError(err)
+	result, err = set.Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(uint64(42), result)
+	require.Equal(int64(42), session.GetLastQueryInfo(sql.LastInsertId))
+
+	null, err := NewLastInsertId(expression.NewLiteral(nil, sql.Null))
+	require.NoError(err)
+	result, err = null.Eval(ctx, nil)
+	require.NoError(err)
+	require.Nil(result)
+	require.Equal(int64(42), session.GetLastQueryInfo(sql.LastInsertId))
+
+	_, err = NewLastInsertId(expression.NewLiteral(1, sql.Int8), expression.NewLiteral(2, sql.Int8))
+	require.Error(err)
+}
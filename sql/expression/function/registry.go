@@ -60,6 +60,7 @@ var Defaults = []sql.Function{
 	sql.Function1{Name: "first", Fn: func(e sql.Expression) sql.Expression { return aggregation.NewFirst(e) }},
 	sql.Function1{Name: "floor", Fn: NewFloor},
 	sql.Function1{Name: "from_base64", Fn: NewFromBase64},
+	sql.NewFunction0("found_rows", sql.Int64, foundRowsFuncLogic),
 	sql.FunctionN{Name: "greatest", Fn: NewGreatest},
 	NewUnaryFunc("hex", sql.Text, HexFunc),
 	sql.Function1{Name: "hour", Fn: NewHour},
@@ -70,6 +71,7 @@ var Defaults = []sql.Function{
 	sql.FunctionN{Name: "json_extract", Fn: NewJSONExtract},
 	sql.Function1{Name: "json_unquote", Fn: NewJSONUnquote},
 	sql.Function1{Name: "last", Fn: func(e sql.Expression) sql.Expression { return aggregation.NewLast(e) }},
+	sql.FunctionN{Name: "last_insert_id", Fn: NewLastInsertId},
 	sql.Function1{Name: "lcase", Fn: NewLower},
 	sql.FunctionN{Name: "least", Fn: NewLeast},
 	sql.Function2{Name: "left", Fn: NewLeft},
@@ -99,6 +101,7 @@ var Defaults = []sql.Function{
 	sql.Function3{Name: "replace", Fn: NewReplace},
 	sql.Function1{Name: "reverse", Fn: NewReverse},
 	sql.FunctionN{Name: "round", Fn: NewRound},
+	sql.NewFunction0("row_count", sql.Int64, rowCountFuncLogic),
 	sql.FunctionN{Name: "rpad", Fn: NewPadFunc(rPadType)},
 	sql.Function1{Name: "rtrim", Fn: NewTrimFunc(rTrimType)},
 	sql.Function1{Name: "second", Fn: NewSecond},
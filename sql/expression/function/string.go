@@ -78,6 +78,12 @@ func HexFunc(_ *sql.Context, arg interface{}) (interface{}, error) {
 	case string:
 		return hexForString(val), nil
 
+	case []byte:
+		return fmt.Sprintf("%X", val), nil
+
+	case sql.BinaryLiteral:
+		return fmt.Sprintf("%X", []byte(val)), nil
+
 	case uint8, uint16, uint32, uint, int, int8, int16, int32, int64:
 		n, err := sql.Int64.Convert(arg)
 
@@ -69,6 +69,8 @@ func (s *Substring) Eval(
 		text = []rune(str)
 	case []byte:
 		text = []rune(string(str))
+	case sql.BinaryLiteral:
+		text = []rune(string(str))
 	case nil:
 		return nil, nil
 	default:
@@ -308,6 +310,8 @@ func (l Left) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		text = []rune(str)
 	case []byte:
 		text = []rune(string(str))
+	case sql.BinaryLiteral:
+		text = []rune(string(str))
 	case nil:
 		return nil, nil
 	default:
@@ -402,6 +406,8 @@ func (i Instr) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		text = []rune(str)
 	case []byte:
 		text = []rune(string(str))
+	case sql.BinaryLiteral:
+		text = []rune(string(str))
 	case nil:
 		return nil, nil
 	default:
@@ -418,7 +424,9 @@ func (i Instr) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	case string:
 		subtext = []rune(substr)
 	case []byte:
-		subtext = []rune(string(subtext))
+		subtext = []rune(string(substr))
+	case sql.BinaryLiteral:
+		subtext = []rune(string(substr))
 	case nil:
 		return nil, nil
 	default:
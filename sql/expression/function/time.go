@@ -373,16 +373,33 @@ func (d *DayOfYear) WithChildren(children ...sql.Expression) (sql.Expression, er
 	return NewDayOfYear(children[0]), nil
 }
 
+// datePartFunc returns a function returning the part of a date fn returns, which is 0 for all the parts of the zero
+// date, 0000-00-00 00:00:00.
 func datePartFunc(fn func(time.Time) int) func(interface{}) interface{} {
 	return func(v interface{}) interface{} {
 		if v == nil {
 			return nil
 		}
+		if sql.IsZeroTime(v.(time.Time)) {
+			return int32(0)
+		}
 
 		return int32(fn(v.(time.Time)))
 	}
 }
 
+// calendarDayPartFunc works like datePartFunc for the parts of a date that depend on its day of the week or of the
+// year, which the zero date doesn't have, so they're NULL.
+func calendarDayPartFunc(fn func(time.Time) int) func(interface{}) interface{} {
+	part := datePartFunc(fn)
+	return func(v interface{}) interface{} {
+		if t, ok := v.(time.Time); ok && sql.IsZeroTime(t) {
+			return nil
+		}
+		return part(v)
+	}
+}
+
 // YearWeek is a function that returns year and week for a date.
 // The year in the result may be different from the year in the date argument for the first and the last week of the year.
 // Details: https://dev.mysql.com/doc/refman/5.5/en/date-and-time-functions.html#function_yearweek
@@ -675,12 +692,12 @@ var (
 	year      = datePartFunc((time.Time).Year)
 	month     = datePartFunc(func(t time.Time) int { return int(t.Month()) })
 	day       = datePartFunc((time.Time).Day)
-	weekday   = datePartFunc(func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 })
+	weekday   = calendarDayPartFunc(func(t time.Time) int { return (int(t.Weekday()) + 6) % 7 })
 	hour      = datePartFunc((time.Time).Hour)
 	minute    = datePartFunc((time.Time).Minute)
 	second    = datePartFunc((time.Time).Second)
-	dayOfWeek = datePartFunc(func(t time.Time) int { return int(t.Weekday()) + 1 })
-	dayOfYear = datePartFunc((time.Time).YearDay)
+	dayOfWeek = calendarDayPartFunc(func(t time.Time) int { return int(t.Weekday()) + 1 })
+	dayOfYear = calendarDayPartFunc((time.Time).YearDay)
 )
 
 // Now is a function that returns the current time.
@@ -915,6 +932,9 @@ func (d *Date) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		if v == nil {
 			return nil
 		}
+		if sql.IsZeroTime(v.(time.Time)) {
+			return "0000-00-00"
+		}
 
 		return v.(time.Time).Format("2006-01-02")
 	})
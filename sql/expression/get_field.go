@@ -87,6 +87,14 @@ func (p *GetField) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	return row[p.fieldIndex], nil
 }
 
+// Eval2 implements the sql.Expression2 interface.
+func (p *GetField) Eval2(ctx *sql.Context, row sql.Row2) (sql.Value, error) {
+	if p.fieldIndex < 0 || p.fieldIndex >= row.Len() {
+		return sql.Value{}, ErrIndexOutOfBounds.New(p.fieldIndex, row.Len())
+	}
+	return row.GetField(p.fieldIndex), nil
+}
+
 // WithChildren implements the Expression interface.
 func (p *GetField) WithChildren(children ...sql.Expression) (sql.Expression, error) {
 	if len(children) != 0 {
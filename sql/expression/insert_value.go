@@ -0,0 +1,49 @@
+package expression
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// InsertValue is the VALUES(column) function of the ON DUPLICATE KEY UPDATE clause of INSERT: the value the row that
+// conflicted with an existing row would have inserted into the column. Its child is the column of the table the row is
+// inserted into.
+type InsertValue struct {
+	UnaryExpression
+}
+
+var _ sql.Expression = (*InsertValue)(nil)
+
+// NewInsertValue creates a new VALUES function of the column given.
+func NewInsertValue(column sql.Expression) *InsertValue {
+	return &InsertValue{UnaryExpression{Child: column}}
+}
+
+// Type implements the sql.Expression interface.
+func (v *InsertValue) Type() sql.Type {
+	return v.Child.Type()
+}
+
+// IsNullable implements the sql.Expression interface. The row inserted may have no value for the column.
+func (*InsertValue) IsNullable() bool {
+	return true
+}
+
+// Eval implements the sql.Expression interface. The expressions of the ON DUPLICATE KEY UPDATE clause are evaluated on
+// the existing row followed by the row inserted, whose column is evaluated.
+func (v *InsertValue) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return v.Child.Eval(ctx, row[len(row)/2:])
+}
+
+// WithChildren implements the sql.Expression interface.
+func (v *InsertValue) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(v, len(children), 1)
+	}
+	return NewInsertValue(children[0]), nil
+}
+
+func (v *InsertValue) String() string {
+	return fmt.Sprintf("VALUES(%s)", v.Child)
+}
@@ -42,12 +42,19 @@ func (p *Literal) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 	return p.value, nil
 }
 
+// Eval2 implements the sql.Expression2 interface.
+func (p *Literal) Eval2(ctx *sql.Context, row sql.Row2) (sql.Value, error) {
+	return sql.EncodeValue(p.fieldType, p.value)
+}
+
 func (p *Literal) String() string {
 	switch v := p.value.(type) {
 	case string:
 		return fmt.Sprintf("%q", v)
 	case []byte:
 		return "BLOB"
+	case sql.BinaryLiteral:
+		return fmt.Sprintf("0x%X", []byte(v))
 	case nil:
 		return "NULL"
 	default:
@@ -62,6 +69,8 @@ func (p *Literal) DebugString() string {
 		return fmt.Sprintf("%s (%s)", v, typeStr)
 	case []byte:
 		return fmt.Sprintf("BLOB(%s)", string(v))
+	case sql.BinaryLiteral:
+		return fmt.Sprintf("0x%X (%s)", []byte(v), typeStr)
 	case nil:
 		return fmt.Sprintf("NULL (%s)", typeStr)
 	case int, uint, int8, uint8, int16, uint16, int32, uint32, int64, uint64:
@@ -49,7 +49,8 @@ func (s *SetField) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		return nil, err
 	}
 	if val != nil {
-		val, err = getField.fieldType.Convert(val)
+		// The row set isn't known to the expression, so values are reported as the first row of their statement
+		val, err = sql.ConvertToColumn(ctx, getField.fieldType, getField.Name(), val, 1)
 		if err != nil {
 			return nil, err
 		}
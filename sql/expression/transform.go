@@ -1,6 +1,8 @@
 package expression
 
 import (
+	"reflect"
+
 	"github.com/dolthub/go-mysql-server/sql"
 )
 
@@ -9,52 +11,80 @@ import (
 type TransformExprWithNodeFunc func(sql.Node, sql.Expression) (sql.Expression, error)
 
 // TransformUp applies a transformation function to the given expression from the
-// bottom up.
+// bottom up. Expressions whose children are all returned unchanged by f are not rebuilt.
 func TransformUp(e sql.Expression, f sql.TransformExprFunc) (sql.Expression, error) {
+	e, _, err := TransformUpWithIdentity(e, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		ne, err := f(e)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+		return ne, ExpressionIdentity(e, ne), nil
+	})
+	return e, err
+}
+
+// TransformUpWithIdentity applies a transformation function to the given expression from the bottom up, and returns
+// whether the result is the same expression given. Expressions whose children are all unchanged are not rebuilt.
+func TransformUpWithIdentity(e sql.Expression, f sql.TransformExprWithIdentityFunc) (sql.Expression, sql.TreeIdentity, error) {
 	children := e.Children()
 	if len(children) == 0 {
 		return f(e)
 	}
 
-	newChildren := make([]sql.Expression, len(children))
+	var newChildren []sql.Expression
 	for i, c := range children {
-		c, err := TransformUp(c, f)
+		nc, same, err := TransformUpWithIdentity(c, f)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+		if !same {
+			if newChildren == nil {
+				newChildren = make([]sql.Expression, len(children))
+				copy(newChildren, children)
+			}
+			newChildren[i] = nc
+		}
+	}
+
+	identity := sql.SameTree
+	if newChildren != nil {
+		var err error
+		e, err = e.WithChildren(newChildren...)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
-		newChildren[i] = c
+		identity = sql.NewTree
 	}
 
-	e, err := e.WithChildren(newChildren...)
+	e, same, err := f(e)
 	if err != nil {
-		return nil, err
+		return nil, sql.SameTree, err
 	}
 
-	return f(e)
+	return e, identity && same, nil
 }
 
-// TransformUpWithNode applies a transformation function to the given expression from the bottom up.
+// TransformUpWithNode applies a transformation function to the given expression from the bottom up. Expressions
+// whose children are all returned unchanged by f are not rebuilt.
 func TransformUpWithNode(n sql.Node, e sql.Expression, f TransformExprWithNodeFunc) (sql.Expression, error) {
-	children := e.Children()
-	if len(children) == 0 {
+	return TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
 		return f(n, e)
-	}
+	})
+}
 
-	newChildren := make([]sql.Expression, len(children))
-	for i, c := range children {
-		c, err := TransformUpWithNode(n, c, f)
-		if err != nil {
-			return nil, err
-		}
-		newChildren[i] = c
+// ExpressionIdentity returns SameTree if the expressions given are the same instance, and NewTree otherwise. Only
+// expressions implemented by pointer types can be told apart this way; any others are always considered new.
+func ExpressionIdentity(a, b sql.Expression) sql.TreeIdentity {
+	if a == nil || b == nil {
+		return a == b
 	}
 
-	e, err := e.WithChildren(newChildren...)
-	if err != nil {
-		return nil, err
+	t := reflect.TypeOf(a)
+	if t.Kind() != reflect.Ptr || t != reflect.TypeOf(b) {
+		return sql.NewTree
 	}
 
-	return f(n, e)
+	return a == b
 }
 
 // ExpressionToColumn converts the expression to the form that should be used in a Schema. Expressions that have Name()
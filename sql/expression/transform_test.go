@@ -0,0 +1,37 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestTransformUpWithIdentity(t *testing.T) {
+	require := require.New(t)
+
+	a := NewGetField(0, sql.Int64, "a", false)
+	b := NewGetField(1, sql.Int64, "b", false)
+	e := NewAnd(NewEquals(a, NewLiteral(int64(1), sql.Int64)), NewEquals(b, NewLiteral(int64(2), sql.Int64)))
+
+	result, same, err := TransformUpWithIdentity(e, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		return e, sql.SameTree, nil
+	})
+	require.NoError(err)
+	require.Equal(sql.SameTree, same)
+	require.True(result == sql.Expression(e))
+
+	result, err = TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+		if e == sql.Expression(b) {
+			return NewGetField(2, sql.Int64, "b", false), nil
+		}
+		return e, nil
+	})
+	require.NoError(err)
+	require.False(result == sql.Expression(e))
+
+	and := result.(*And)
+	require.True(and.Left == e.(*And).Left)
+	require.Equal(NewEquals(NewGetField(2, sql.Int64, "b", false), NewLiteral(int64(2), sql.Int64)), and.Right)
+}
@@ -0,0 +1,41 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ForeignKeyChecksVar is the system variable that turns off the enforcement of foreign keys for the statements of a
+// session, as dumps do while they're loaded.
+const ForeignKeyChecksVar = "foreign_key_checks"
+
+var (
+	// ErrForeignKeyChildViolation is returned when a row inserted or updated references no row of the table referenced
+	// by a foreign key, with the database, the table and the foreign key. Servers report it to clients as the MySQL
+	// error ER_NO_REFERENCED_ROW_2 (1452).
+	ErrForeignKeyChildViolation = errors.NewKind("Cannot add or update a child row: a foreign key constraint fails (`%s`.`%s`, %s)")
+	// ErrForeignKeyParentViolation is returned when a row deleted or updated is referenced by rows of a table whose
+	// foreign key restricts it, with the database, the referencing table and the foreign key. Servers report it to
+	// clients as the MySQL error ER_ROW_IS_REFERENCED_2 (1451).
+	ErrForeignKeyParentViolation = errors.NewKind("Cannot delete or update a parent row: a foreign key constraint fails (`%s`.`%s`, %s)")
+	// ErrTruncateReferencedTable is returned when a table referenced by the foreign key of another table is truncated,
+	// with the database, the referencing table and the foreign key. Servers report it to clients as the MySQL error
+	// ER_TRUNCATE_ILLEGAL_FK (1701).
+	ErrTruncateReferencedTable = errors.NewKind("Cannot truncate a table referenced in a foreign key constraint (`%s`.`%s`, %s)")
+	// ErrDropReferencedTable is returned when a table referenced by the foreign key of another table that isn't dropped
+	// with it is dropped, with the table, the foreign key and the referencing table. Servers report it to clients as the
+	// MySQL error ER_FK_CANNOT_DROP_PARENT (3730).
+	ErrDropReferencedTable = errors.NewKind("Cannot drop table '%s' referenced by a foreign key constraint '%s' on table '%s'.")
+)
+
+// ForeignKeyChecks returns whether the foreign keys of tables are enforced on the rows the statements of the session of
+// the context given write, which is the default.
+func ForeignKeyChecks(ctx *Context) bool {
+	if ctx.Session == nil {
+		return true
+	}
+	if _, v := ctx.Get(ForeignKeyChecksVar); v != nil {
+		checks, _ := ConvertToBool(v)
+		return checks
+	}
+	return true
+}
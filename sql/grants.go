@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrNonExistingGrant is returned by SHOW GRANTS for the users that don't have an account.
+var ErrNonExistingGrant = errors.NewKind("There is no such grant defined for user '%s' on host '%s'")
+
+// AllPrivileges is the privilege of the accounts that have every privilege.
+const AllPrivileges = "ALL PRIVILEGES"
+
+// Account is a user that can connect to the server, with the privileges granted to it on all the databases.
+type Account struct {
+	User string
+	Host string
+	// Privileges are the names of the privileges of the account, such as SELECT or SUPER, or AllPrivileges.
+	Privileges []string
+	// Plugin is the name of the authentication plugin of the account, such as mysql_native_password.
+	Plugin string
+	// AuthenticationString is the hash of the password of the account, if it has one.
+	AuthenticationString string
+	// PasswordExpired is whether the password of the account has expired, so that its user must change it.
+	PasswordExpired bool
+	// Locked is whether the account is locked, either with ALTER USER or after too many failed logins.
+	Locked bool
+}
+
+// HasPrivilege returns whether the account has the privilege given, whose name is case insensitive.
+func (a Account) HasPrivilege(privilege string) bool {
+	for _, p := range a.Privileges {
+		if p == AllPrivileges || strings.EqualFold(p, privilege) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountLister lists the accounts of the users of the server, for SHOW GRANTS and the tables of the mysql database.
+// It's implemented by the auth methods that know their users.
+type AccountLister interface {
+	// Accounts returns the accounts of all the users, sorted by user.
+	Accounts() []Account
+	// Account returns the account of the user given, and whether the user has one.
+	Account(user string) (Account, bool)
+}
+
+// SetAccountLister sets the lister of the accounts of the users of the server.
+func (c *Catalog) SetAccountLister(accounts AccountLister) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.accounts = accounts
+}
+
+// Accounts returns the accounts of the users of the server, sorted by user, or none if there's no AccountLister.
+func (c *Catalog) Accounts() []Account {
+	c.mu.RLock()
+	accounts := c.accounts
+	c.mu.RUnlock()
+
+	if accounts == nil {
+		return nil
+	}
+	return accounts.Accounts()
+}
+
+// Account returns the account of the user given, and whether the user has one.
+func (c *Catalog) Account(user string) (Account, bool) {
+	c.mu.RLock()
+	accounts := c.accounts
+	c.mu.RUnlock()
+
+	if accounts == nil {
+		return Account{}, false
+	}
+	return accounts.Account(user)
+}
+
+// Grants returns the GRANT statements of the privileges of the user given, as SHOW GRANTS shows them: the privileges
+// on all the databases, followed by the columns granted. It returns ErrNonExistingGrant if the user has no account.
+func (c *Catalog) Grants(user, host string) ([]string, error) {
+	account, ok := c.Account(user)
+	if !ok {
+		return nil, ErrNonExistingGrant.New(user, host)
+	}
+	to := fmt.Sprintf("%s@%s", quoteIdentifier(account.User), quoteIdentifier(account.Host))
+
+	privileges := "USAGE"
+	if len(account.Privileges) > 0 {
+		privileges = strings.Join(account.Privileges, ", ")
+	}
+	grants := []string{fmt.Sprintf("GRANT %s ON *.* TO %s", privileges, to)}
+
+	for _, g := range c.ColumnGrants() {
+		if g.User != user {
+			continue
+		}
+		columns := make([]string, len(g.Columns))
+		for i, col := range g.Columns {
+			columns[i] = quoteIdentifier(col)
+		}
+		grants = append(grants, fmt.Sprintf(
+			"GRANT SELECT (%s) ON %s.%s TO %s",
+			strings.Join(columns, ", "),
+			quoteIdentifier(g.Database),
+			quoteIdentifier(g.Table),
+			to,
+		))
+	}
+	return grants, nil
+}
+
+func quoteIdentifier(id string) string {
+	return "`" + strings.ReplaceAll(id, "`", "``") + "`"
+}
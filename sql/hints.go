@@ -0,0 +1,162 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrMaxExecutionTimeExceeded is returned by queries that run for longer than their MAX_EXECUTION_TIME hint allows.
+var ErrMaxExecutionTimeExceeded = errors.NewKind("Query execution was interrupted, maximum statement execution time exceeded")
+
+// The optimizer hints the analyzer supports.
+const (
+	// JoinOrderHint joins the tables given in the order given, before or after the other tables of the query block.
+	JoinOrderHint = "JOIN_ORDER"
+	// HashJoinHint joins the tables given with hash joins whenever their join conditions have equalities that can be
+	// hashed, without comparing their costs with the costs of other joins.
+	HashJoinHint = "HASH_JOIN"
+	// NoHashJoinHint never joins the tables given with hash joins.
+	NoHashJoinHint = "NO_HASH_JOIN"
+	// MergeHint merges the derived tables given into the query block they're selected from, so their tables are
+	// joined to the other tables of the query block like any other table.
+	MergeHint = "MERGE"
+	// NoMergeHint never merges the derived tables given.
+	NoMergeHint = "NO_MERGE"
+	// IndexHint only looks up the rows of the table given in the indexes given, whenever one of them can be used,
+	// without comparing their costs with the costs of scanning the table.
+	IndexHint = "INDEX"
+	// NoIndexHint never looks up the rows of the table given in the indexes given.
+	NoIndexHint = "NO_INDEX"
+	// MaxExecutionTimeHint interrupts the query once it has run for the number of milliseconds given.
+	MaxExecutionTimeHint = "MAX_EXECUTION_TIME"
+)
+
+// HintArguments are the kinds of arguments of optimizer hints.
+type HintArguments byte
+
+const (
+	// HintTables are the names of tables, or none for all the tables of the query block.
+	HintTables HintArguments = iota
+	// HintTableIndexes are the name of a table and the names of some of its indexes, or none for all of them.
+	HintTableIndexes
+	// HintNumber is a number.
+	HintNumber
+)
+
+// A HintDefinition defines an optimizer hint, with the kind of arguments it takes.
+type HintDefinition struct {
+	Name      string
+	Arguments HintArguments
+}
+
+var (
+	hintsMu sync.RWMutex
+	hints   = map[string]HintDefinition{
+		JoinOrderHint:        {Name: JoinOrderHint, Arguments: HintTables},
+		HashJoinHint:         {Name: HashJoinHint, Arguments: HintTables},
+		NoHashJoinHint:       {Name: NoHashJoinHint, Arguments: HintTables},
+		MergeHint:            {Name: MergeHint, Arguments: HintTables},
+		NoMergeHint:          {Name: NoMergeHint, Arguments: HintTables},
+		IndexHint:            {Name: IndexHint, Arguments: HintTableIndexes},
+		NoIndexHint:          {Name: NoIndexHint, Arguments: HintTableIndexes},
+		MaxExecutionTimeHint: {Name: MaxExecutionTimeHint, Arguments: HintNumber},
+	}
+)
+
+// RegisterHint registers an optimizer hint, so it's parsed in the hints of queries, for analyzer rules added by
+// integrators to consume. Hints registered with the name of another hint replace it.
+func RegisterHint(def HintDefinition) {
+	hintsMu.Lock()
+	defer hintsMu.Unlock()
+	def.Name = strings.ToUpper(def.Name)
+	hints[def.Name] = def
+}
+
+// LookupHint returns the definition of the optimizer hint named, if it's registered. Names are case insensitive.
+func LookupHint(name string) (HintDefinition, bool) {
+	hintsMu.RLock()
+	defer hintsMu.RUnlock()
+	def, ok := hints[strings.ToUpper(name)]
+	return def, ok
+}
+
+// A Hint is an optimizer hint of a query block, given in a /*+ ... */ comment after its SELECT keyword.
+type Hint struct {
+	// Name is the upper case name of the hint.
+	Name string
+	// Args are the arguments of the hint, with the names of tables and indexes in lower case.
+	Args []string
+}
+
+func (h Hint) String() string {
+	return fmt.Sprintf("%s(%s)", h.Name, strings.Join(h.Args, ", "))
+}
+
+// Tables returns the tables whose names are the arguments of the hint, for hints of tables, or the table of its
+// indexes, or nil if the hint applies to all of the tables of its query block.
+func (h Hint) Tables() []string {
+	if def, ok := LookupHint(h.Name); ok && def.Arguments == HintTableIndexes && len(h.Args) > 0 {
+		return h.Args[:1]
+	}
+	return h.Args
+}
+
+// Indexes returns the indexes whose names are the arguments of the hint after its table, or nil if it applies to all
+// of the indexes of the table.
+func (h Hint) Indexes() []string {
+	if len(h.Args) < 2 {
+		return nil
+	}
+	return h.Args[1:]
+}
+
+// AppliesTo returns whether the hint applies to the table named, because it names it or no table.
+func (h Hint) AppliesTo(table string) bool {
+	tables := h.Tables()
+	if len(tables) == 0 {
+		return true
+	}
+	for _, t := range tables {
+		if strings.EqualFold(t, table) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hints are the optimizer hints of a query block, in the order they're given.
+type Hints []Hint
+
+// Get returns the first hint named, if there is one, which takes precedence over any other hint with the same name.
+func (h Hints) Get(name string) (Hint, bool) {
+	for _, hint := range h {
+		if hint.Name == name {
+			return hint, true
+		}
+	}
+	return Hint{}, false
+}
+
+// ForTable returns the first of the hints named that applies to the table named, if there is one. Hints with
+// opposite effects, like HASH_JOIN and NO_HASH_JOIN, are given together, so the first one given takes precedence.
+func (h Hints) ForTable(table string, names ...string) (Hint, bool) {
+	for _, hint := range h {
+		for _, name := range names {
+			if hint.Name == name && hint.AppliesTo(table) {
+				return hint, true
+			}
+		}
+	}
+	return Hint{}, false
+}
+
+func (h Hints) String() string {
+	strs := make([]string, len(h))
+	for i, hint := range h {
+		strs[i] = hint.String()
+	}
+	return strings.Join(strs, " ")
+}
@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHints(t *testing.T) {
+	require := require.New(t)
+
+	hints := Hints{
+		{Name: NoHashJoinHint, Args: []string{"a"}},
+		{Name: HashJoinHint},
+		{Name: IndexHint, Args: []string{"b", "primary", "b_idx"}},
+		{Name: JoinOrderHint, Args: []string{"b", "a"}},
+	}
+	require.Equal("NO_HASH_JOIN(a) HASH_JOIN() INDEX(b, primary, b_idx) JOIN_ORDER(b, a)", hints.String())
+
+	hint, ok := hints.ForTable("a", HashJoinHint, NoHashJoinHint)
+	require.True(ok)
+	require.Equal(NoHashJoinHint, hint.Name)
+
+	hint, ok = hints.ForTable("B", HashJoinHint, NoHashJoinHint)
+	require.True(ok)
+	require.Equal(HashJoinHint, hint.Name)
+
+	_, ok = hints.ForTable("a", IndexHint, NoIndexHint)
+	require.False(ok)
+
+	hint, ok = hints.Get(IndexHint)
+	require.True(ok)
+	require.Equal([]string{"b"}, hint.Tables())
+	require.Equal([]string{"primary", "b_idx"}, hint.Indexes())
+
+	hint, ok = hints.Get(JoinOrderHint)
+	require.True(ok)
+	require.Equal([]string{"b", "a"}, hint.Tables())
+	require.True(hint.AppliesTo("A"))
+	require.False(hint.AppliesTo("c"))
+
+	_, ok = hints.Get(MaxExecutionTimeHint)
+	require.False(ok)
+}
+
+func TestRegisterHint(t *testing.T) {
+	require := require.New(t)
+
+	_, ok := LookupHint("my_hint")
+	require.False(ok)
+
+	RegisterHint(HintDefinition{Name: "my_hint", Arguments: HintTableIndexes})
+	defer func() {
+		hintsMu.Lock()
+		delete(hints, "MY_HINT")
+		hintsMu.Unlock()
+	}()
+
+	def, ok := LookupHint("My_Hint")
+	require.True(ok)
+	require.Equal(HintDefinition{Name: "MY_HINT", Arguments: HintTableIndexes}, def)
+
+	hint := Hint{Name: "MY_HINT", Args: []string{"a", "a_idx"}}
+	require.Equal([]string{"a"}, hint.Tables())
+}
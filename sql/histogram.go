@@ -0,0 +1,219 @@
+package sql
+
+import (
+	"sort"
+	"time"
+)
+
+// Histogram is an equi-depth histogram of the values of a column other than NULL: its buckets are ranges of values
+// with about the same number of rows each, so the most frequent values are in narrower buckets. All the rows with a
+// value are in the same bucket.
+type Histogram struct {
+	// Type is the type of the values of the histogram, which compares them.
+	Type Type
+	// Buckets are the buckets of the histogram, sorted by their bounds.
+	Buckets []HistogramBucket
+}
+
+// HistogramBucket is a bucket of a Histogram.
+type HistogramBucket struct {
+	// LowerBound and UpperBound are the lowest and highest values of the bucket.
+	LowerBound, UpperBound interface{}
+	// RowCount is the number of rows with values of the bucket.
+	RowCount uint64
+	// DistinctCount is the number of distinct values of the bucket.
+	DistinctCount uint64
+}
+
+// NewHistogram returns an equi-depth histogram of the values given, which must not be NULL, with the number of
+// buckets given at most. The values are sorted in place.
+func NewHistogram(typ Type, values []interface{}, buckets int) (*Histogram, error) {
+	var err error
+	sort.SliceStable(values, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var cmp int
+		cmp, err = typ.Compare(values[i], values[j])
+		return cmp < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Histogram{Type: typ}
+	if len(values) == 0 {
+		return h, nil
+	}
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	depth := (len(values) + buckets - 1) / buckets
+	var bucket *HistogramBucket
+	for i, v := range values {
+		same := false
+		if i > 0 {
+			cmp, err := typ.Compare(values[i-1], v)
+			if err != nil {
+				return nil, err
+			}
+			same = cmp == 0
+		}
+
+		// Buckets are closed once they're deep enough, but never between equal values
+		if bucket == nil || (!same && bucket.RowCount >= uint64(depth)) {
+			h.Buckets = append(h.Buckets, HistogramBucket{LowerBound: v})
+			bucket = &h.Buckets[len(h.Buckets)-1]
+		}
+
+		bucket.UpperBound = v
+		bucket.RowCount++
+		if !same {
+			bucket.DistinctCount++
+		}
+	}
+	return h, nil
+}
+
+// RowCount returns the number of rows of the histogram.
+func (h *Histogram) RowCount() uint64 {
+	var rows uint64
+	for _, b := range h.Buckets {
+		rows += b.RowCount
+	}
+	return rows
+}
+
+// EqualFraction returns the estimated fraction of the rows of the histogram with the value given, which is the
+// fraction of a distinct value of the bucket of the value.
+func (h *Histogram) EqualFraction(v interface{}) (float64, error) {
+	rows := h.RowCount()
+	if rows == 0 {
+		return 0, nil
+	}
+
+	for _, b := range h.Buckets {
+		in, err := h.inBucket(b, v)
+		if err != nil {
+			return 0, err
+		}
+		if in {
+			return float64(b.RowCount) / float64(b.DistinctCount) / float64(rows), nil
+		}
+	}
+	return 0, nil
+}
+
+// LessFraction returns the estimated fraction of the rows of the histogram with values less than the one given, or
+// equal to it if inclusive is true. Within a bucket, the values of numbers and times are assumed to be evenly
+// distributed, and half the rows of any other bucket are assumed to be less than a value between its bounds.
+func (h *Histogram) LessFraction(v interface{}, inclusive bool) (float64, error) {
+	rows := h.RowCount()
+	if rows == 0 {
+		return 0, nil
+	}
+
+	var less float64
+	for _, b := range h.Buckets {
+		upper, err := h.Type.Compare(v, b.UpperBound)
+		if err != nil {
+			return 0, err
+		}
+		if upper > 0 {
+			less += float64(b.RowCount)
+			continue
+		}
+
+		lower, err := h.Type.Compare(v, b.LowerBound)
+		if err != nil {
+			return 0, err
+		}
+		if lower < 0 {
+			break
+		}
+
+		valueRows := float64(b.RowCount) / float64(b.DistinctCount)
+		switch {
+		case lower == 0:
+			// Only the rows of the lower bound can be equal, and none is less
+		case upper == 0:
+			less += float64(b.RowCount) - valueRows
+		default:
+			// The rows with the lowest and highest values aren't between them
+			less += valueRows + (float64(b.RowCount)-2*valueRows)*h.position(b, v)
+		}
+		if inclusive {
+			less += valueRows
+		}
+		break
+	}
+	return less / float64(rows), nil
+}
+
+// RangeFraction returns the estimated fraction of the rows of the histogram with values between the ones given,
+// which are included if their inclusive arguments are true. A nil value leaves its side of the range unbounded.
+func (h *Histogram) RangeFraction(lower interface{}, lowerInclusive bool, upper interface{}, upperInclusive bool) (float64, error) {
+	fraction := 1.0
+	if upper != nil {
+		var err error
+		if fraction, err = h.LessFraction(upper, upperInclusive); err != nil {
+			return 0, err
+		}
+	}
+
+	if lower != nil {
+		less, err := h.LessFraction(lower, !lowerInclusive)
+		if err != nil {
+			return 0, err
+		}
+		fraction -= less
+	}
+
+	if fraction < 0 {
+		return 0, nil
+	}
+	return fraction, nil
+}
+
+// inBucket returns whether the value given is between the bounds of the bucket given.
+func (h *Histogram) inBucket(b HistogramBucket, v interface{}) (bool, error) {
+	cmp, err := h.Type.Compare(v, b.LowerBound)
+	if err != nil || cmp < 0 {
+		return false, err
+	}
+	cmp, err = h.Type.Compare(v, b.UpperBound)
+	return cmp <= 0, err
+}
+
+// position returns the position of a value between the bounds of the bucket given, from 0 at its lower bound to 1 at
+// its upper bound, or 1/2 for values that can't be interpolated.
+func (h *Histogram) position(b HistogramBucket, v interface{}) float64 {
+	lower, lok := histogramValue(h.Type, b.LowerBound)
+	upper, uok := histogramValue(h.Type, b.UpperBound)
+	value, vok := histogramValue(h.Type, v)
+	if !lok || !uok || !vok || upper <= lower {
+		return 0.5
+	}
+	return (value - lower) / (upper - lower)
+}
+
+// histogramValue returns the value given as a float, if it's a number or a time.
+func histogramValue(typ Type, v interface{}) (float64, bool) {
+	switch {
+	case IsNumber(typ):
+		f, err := Float64.Convert(v)
+		if err != nil {
+			return 0, false
+		}
+		return f.(float64), true
+	case IsTime(typ):
+		t, err := Datetime.Convert(v)
+		if err != nil {
+			return 0, false
+		}
+		return float64(t.(time.Time).UnixNano()), true
+	default:
+		return 0, false
+	}
+}
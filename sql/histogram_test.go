@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestHistogram(t *testing.T) {
+	require := require.New(t)
+
+	// 1 has 4 rows, and 2 to 9 one row each
+	values := []interface{}{int64(9), int64(1), int64(2), int64(1), int64(3), int64(4), int64(1), int64(5), int64(6),
+		int64(7), int64(1), int64(8)}
+	h, err := sql.NewHistogram(sql.Int64, values, 3)
+	require.NoError(err)
+	require.Equal([]sql.HistogramBucket{
+		{LowerBound: int64(1), UpperBound: int64(1), RowCount: 4, DistinctCount: 1},
+		{LowerBound: int64(2), UpperBound: int64(5), RowCount: 4, DistinctCount: 4},
+		{LowerBound: int64(6), UpperBound: int64(9), RowCount: 4, DistinctCount: 4},
+	}, h.Buckets)
+	require.Equal(uint64(12), h.RowCount())
+
+	f, err := h.EqualFraction(int64(1))
+	require.NoError(err)
+	require.Equal(4.0/12, f)
+	f, err = h.EqualFraction(int64(3))
+	require.NoError(err)
+	require.Equal(1.0/12, f)
+	f, err = h.EqualFraction(int64(10))
+	require.NoError(err)
+	require.Equal(0.0, f)
+
+	f, err = h.LessFraction(int64(1), false)
+	require.NoError(err)
+	require.Equal(0.0, f)
+	f, err = h.LessFraction(int64(1), true)
+	require.NoError(err)
+	require.Equal(4.0/12, f)
+	f, err = h.LessFraction(int64(5), false)
+	require.NoError(err)
+	require.Equal(7.0/12, f)
+	f, err = h.LessFraction(int64(20), false)
+	require.NoError(err)
+	require.Equal(1.0, f)
+
+	// Values between the bounds of a bucket are interpolated
+	f, err = h.LessFraction(7.5, true)
+	require.NoError(err)
+	require.InDelta(11.0/12, f, 1e-9)
+
+	f, err = h.RangeFraction(int64(2), true, int64(5), true)
+	require.NoError(err)
+	require.InDelta(4.0/12, f, 1e-9)
+	f, err = h.RangeFraction(int64(5), false, nil, false)
+	require.NoError(err)
+	require.InDelta(4.0/12, f, 1e-9)
+	f, err = h.RangeFraction(int64(5), false, int64(2), false)
+	require.NoError(err)
+	require.Equal(0.0, f)
+
+	// Histograms of values that can't be interpolated take the middle of buckets
+	h, err = sql.NewHistogram(sql.LongText, []interface{}{"a", "b", "c", "d"}, 1)
+	require.NoError(err)
+	f, err = h.LessFraction("bb", false)
+	require.NoError(err)
+	require.Equal(0.5, f)
+
+	h, err = sql.NewHistogram(sql.Int64, nil, 10)
+	require.NoError(err)
+	require.Empty(h.Buckets)
+	f, err = h.EqualFraction(int64(1))
+	require.NoError(err)
+	require.Equal(0.0, f)
+}
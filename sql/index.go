@@ -53,6 +53,16 @@ type DescendIndex interface {
 	DescendRange(lessOrEqual, greaterThan []interface{}) (IndexLookup, error)
 }
 
+// PrefixIndex is an index of several expressions that can look up the rows with a key of a prefix of its expressions,
+// which have the values of the key for the first expressions of the index and any values for the others. Joins on
+// the first columns of an index are looked up in it if it's a PrefixIndex.
+type PrefixIndex interface {
+	Index
+	// GetPrefix returns an IndexLookup for the given key of the first expressions of the index, which has fewer values
+	// than the index has expressions.
+	GetPrefix(key ...interface{}) (IndexLookup, error)
+}
+
 // NegateIndex is an index that supports retrieving negated values.
 type NegateIndex interface {
 	// Not returns an IndexLookup for keys that are not equal
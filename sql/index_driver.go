@@ -13,20 +13,22 @@ const IndexBatchSize = uint64(10000)
 const ChecksumKey = "checksum"
 
 // IndexDriver manages the coordination between the indexes and their
-// representation on disk.
+// representation on disk. Its methods, and the iterators they're given,
+// receive the context of the query, which drivers storing indexes remotely
+// use to abort their calls once it's canceled or its deadline is exceeded.
 type IndexDriver interface {
 	// ID returns the unique name of the driver.
 	ID() string
 	// Create a new index. If exprs is more than one expression, it means the
 	// index has multiple columns indexed. If it's just one, it means it may
 	// be an expression or a column.
-	Create(db, table, id string, expressions []Expression, config map[string]string) (DriverIndex, error)
+	Create(ctx *Context, db, table, id string, expressions []Expression, config map[string]string) (DriverIndex, error)
 	// LoadAll loads all indexes for given db and table.
 	LoadAll(ctx *Context, db, table string) ([]DriverIndex, error)
 	// Save the given index for all partitions.
 	Save(*Context, DriverIndex, PartitionIndexKeyValueIter) error
 	// Delete the given index for all partitions in the iterator.
-	Delete(DriverIndex, PartitionIter) error
+	Delete(*Context, DriverIndex, PartitionIter) error
 }
 
 // DriverIndexableTable represents a table that supports being indexed and receiving indexes to be able to speed up its
@@ -51,7 +53,7 @@ type DriverIndexLookup interface {
 	IndexLookup
 
 	// Values returns the values in the subset of the index. These are used to populate the index via the driver.
-	Values(Partition) (IndexValueIter, error)
+	Values(*Context, Partition) (IndexValueIter, error)
 
 	// Indexes returns the IDs of all indexes involved in this lookup.
 	Indexes() []string
@@ -69,7 +71,7 @@ type Checksumable interface {
 type PartitionIndexKeyValueIter interface {
 	// Next returns the next partition and the IndexKeyValueIter for that
 	// partition.
-	Next() (Partition, IndexKeyValueIter, error)
+	Next(*Context) (Partition, IndexKeyValueIter, error)
 	io.Closer
 }
 
@@ -79,14 +81,14 @@ type IndexKeyValueIter interface {
 	// Next returns the next tuple of index key values. The length of the
 	// returned slice will be the same as the number of columns used to
 	// create this iterator. The second returned parameter is a repo's location.
-	Next() ([]interface{}, []byte, error)
+	Next(*Context) ([]interface{}, []byte, error)
 	io.Closer
 }
 
 // IndexValueIter is an iterator of index values.
 type IndexValueIter interface {
 	// Next returns the next value (repo's location) - see IndexKeyValueIter.
-	Next() ([]byte, error)
+	Next(*Context) ([]byte, error)
 	io.Closer
 }
 
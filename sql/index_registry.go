@@ -326,7 +326,7 @@ func (r *IndexRegistry) ExpressionsWithIndexes(
 	var results [][]Expression
 Indexes:
 	for _, idx := range r.indexes {
-		if !r.canUseIndex(idx) {
+		if idx.Database() != db || !r.canUseIndex(idx) {
 			continue
 		}
 
@@ -527,6 +527,36 @@ func (r *IndexRegistry) DeleteIndex(db, id string, force bool) (<-chan struct{},
 	return done, nil
 }
 
+// DeleteDatabaseIndexes removes all the indexes of the database given from the registry, along with the functions
+// that load indexes of its tables, such as when the database is removed from the catalog. The data of the indexes
+// isn't deleted from their drivers, so the indexes are loaded again if the database is added back. Queries already
+// using the indexes can keep using them.
+func (r *IndexRegistry) DeleteDatabaseIndexes(db string) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	var order []indexKey
+	for _, k := range r.indexOrder {
+		if !strings.EqualFold(k.db, db) {
+			order = append(order, k)
+		}
+	}
+	r.indexOrder = order
+
+	for k := range r.indexes {
+		if strings.EqualFold(k.db, db) {
+			delete(r.indexes, k)
+			delete(r.statuses, k)
+		}
+	}
+
+	for k := range r.indexLoaders {
+		if strings.EqualFold(k.db, db) {
+			delete(r.indexLoaders, k)
+		}
+	}
+}
+
 type indexKey struct {
 	db, id string
 }
@@ -163,6 +163,28 @@ func TestDeleteIndex(t *testing.T) {
 	require.Len(r.indexes, 0)
 }
 
+func TestDeleteDatabaseIndexes(t *testing.T) {
+	require := require.New(t)
+	r := NewIndexRegistry()
+
+	idx := &dummyIdx{"idx1", nil, "Foo", "t"}
+	idx2 := &dummyIdx{"idx2", nil, "bar", "t"}
+	for _, i := range []*dummyIdx{idx, idx2} {
+		k := indexKey{i.database, i.id}
+		r.indexes[k] = i
+		r.indexOrder = append(r.indexOrder, k)
+		r.setStatus(i, IndexReady)
+	}
+	r.indexLoaders[dbTableTuple{"Foo", "t"}] = []func(*Context) error{nil}
+
+	r.DeleteDatabaseIndexes("foo")
+
+	require.Equal(map[indexKey]DriverIndex{{"bar", "idx2"}: idx2}, r.indexes)
+	require.Equal([]indexKey{{"bar", "idx2"}}, r.indexOrder)
+	require.Len(r.statuses, 1)
+	require.Len(r.indexLoaders, 0)
+}
+
 func TestDeleteIndex_InUse(t *testing.T) {
 	require := require.New(t)
 	r := NewIndexRegistry()
@@ -377,7 +399,7 @@ type loadDriver struct {
 }
 
 func (d loadDriver) ID() string { return d.id }
-func (loadDriver) Create(db, table, id string, expressions []Expression, config map[string]string) (DriverIndex, error) {
+func (loadDriver) Create(ctx *Context, db, table, id string, expressions []Expression, config map[string]string) (DriverIndex, error) {
 	panic("create is a placeholder")
 }
 func (d loadDriver) LoadAll(ctx *Context, db, table string) ([]DriverIndex, error) {
@@ -392,7 +414,7 @@ func (d loadDriver) LoadAll(ctx *Context, db, table string) ([]DriverIndex, erro
 func (loadDriver) Save(ctx *Context, index DriverIndex, iter PartitionIndexKeyValueIter) error {
 	return nil
 }
-func (loadDriver) Delete(DriverIndex, PartitionIter) error { return nil }
+func (loadDriver) Delete(*Context, DriverIndex, PartitionIter) error { return nil }
 
 type dummyIdx struct {
 	id       string
@@ -16,8 +16,6 @@ import (
 )
 
 const (
-	// InformationSchemaDatabaseName is the name of the information schema database.
-	InformationSchemaDatabaseName = "information_schema"
 	// FilesTableName is the name of the files table.
 	FilesTableName = "files"
 	// ColumnStatisticsTableName is the name of the column statistics table.
@@ -34,6 +32,8 @@ const (
 	StatisticsTableName = "statistics"
 	// TableConstraintsTableName is the name of the table_constraints table.
 	TableConstraintsTableName = "table_constraints"
+	// CheckConstraintsTableName is the name of the check_constraints table.
+	CheckConstraintsTableName = "check_constraints"
 	// ReferentialConstraintsTableName is the name of the table_constraints table.
 	ReferentialConstraintsTableName = "referential_constraints"
 	// KeyColumnUsageTableName is the name of the key_column_usage table.
@@ -48,6 +48,10 @@ const (
 	ViewsTableName = "views"
 	// UserPrivilegesTableName is the name of the user_privileges table
 	UserPrivilegesTableName = "user_privileges"
+	// ResourceGroupsTableName is the name of the resource_groups table
+	ResourceGroupsTableName = "resource_groups"
+	// SchemaVersionsTableName is the name of the schema_versions table, which isn't in MySQL.
+	SchemaVersionsTableName = "schema_versions"
 )
 
 var _ Database = (*informationSchemaDatabase)(nil)
@@ -225,6 +229,13 @@ var tableConstraintsSchema = Schema{
 	{Name: "enforced", Type: LongText, Default: nil, Nullable: false, Source: TableConstraintsTableName},
 }
 
+var checkConstraintsSchema = Schema{
+	{Name: "constraint_catalog", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "constraint_schema", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "constraint_name", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+	{Name: "check_clause", Type: LongText, Default: nil, Nullable: false, Source: CheckConstraintsTableName},
+}
+
 var referentialConstraintsSchema = Schema{
 	{Name: "constraint_catalog", Type: LongText, Default: nil, Nullable: false, Source: ReferentialConstraintsTableName},
 	{Name: "constraint_schema", Type: LongText, Default: nil, Nullable: false, Source: ReferentialConstraintsTableName},
@@ -360,6 +371,20 @@ var userPrivilegesSchema = Schema{
 	{Name: "is_grantable", Type: LongText, Default: nil, Nullable: false, Source: UserPrivilegesTableName},
 }
 
+var resourceGroupsSchema = Schema{
+	{Name: "resource_group_name", Type: LongText, Default: nil, Nullable: false, Source: ResourceGroupsTableName},
+	{Name: "resource_group_type", Type: LongText, Default: nil, Nullable: false, Source: ResourceGroupsTableName},
+	{Name: "resource_group_enabled", Type: Int8, Default: nil, Nullable: false, Source: ResourceGroupsTableName},
+	{Name: "vcpu_ids", Type: LongText, Default: nil, Nullable: true, Source: ResourceGroupsTableName},
+	{Name: "thread_priority", Type: Int32, Default: nil, Nullable: false, Source: ResourceGroupsTableName},
+}
+
+var schemaVersionsSchema = Schema{
+	{Name: "table_schema", Type: LongText, Default: nil, Nullable: false, Source: SchemaVersionsTableName},
+	{Name: "table_name", Type: LongText, Default: nil, Nullable: true, Source: SchemaVersionsTableName},
+	{Name: "schema_version", Type: Uint64, Default: nil, Nullable: false, Source: SchemaVersionsTableName},
+}
+
 func tablesRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 	var rows []Row
 	for _, db := range cat.AllDatabases() {
@@ -469,7 +494,7 @@ func columnsRowIter(ctx *Context, cat *Catalog) (RowIter, error) {
 					nil,                              // datetime_precision
 					charName,                         // character_set_name
 					collName,                         // collation_name
-					strings.ToLower(c.Type.String()), // column_type
+					ColumnTypeString(c.Type),         // column_type
 					"",                               // column_key
 					c.Extra,                          // extra
 					"select",                         // privileges
@@ -610,6 +635,104 @@ func triggersRowIter(ctx *Context, c *Catalog) (RowIter, error) {
 	return RowsToRowIter(rows...), nil
 }
 
+func resourceGroupsRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, g := range c.ResourceGroups() {
+		var enabled int8
+		if g.Enabled {
+			enabled = 1
+		}
+		rows = append(rows, Row{
+			g.Name,
+			g.Type.String(),
+			enabled,
+			g.VCPUString(c.CPUs()),
+			int32(g.ThreadPriority),
+		})
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+// schemaVersionsRowIter returns the schema versions of the databases and tables that have changed. The rows of the
+// databases have no table_name.
+func schemaVersionsRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, v := range c.SchemaVersions() {
+		var table interface{}
+		if v.Table != "" {
+			table = v.Table
+		}
+		rows = append(rows, Row{v.Database, table, v.Version})
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func userPrivilegesRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, a := range c.Accounts() {
+		grantee := fmt.Sprintf("'%s'@'%s'", a.User, a.Host)
+		privileges := a.Privileges
+		if len(privileges) == 0 {
+			privileges = []string{"USAGE"}
+		}
+		for _, p := range privileges {
+			rows = append(rows, Row{grantee, "def", p, "NO"})
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+// tableConstraintsRowIter returns the check constraints of the tables. The other constraints aren't listed yet.
+func tableConstraintsRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	err := checkConstraintsIter(ctx, c, func(db Database, t Table, check CheckConstraint) {
+		enforced := "YES"
+		if !check.Enforced {
+			enforced = "NO"
+		}
+		rows = append(rows, Row{"def", db.Name(), check.Name, db.Name(), t.Name(), "CHECK", enforced})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func checkConstraintsRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	err := checkConstraintsIter(ctx, c, func(db Database, t Table, check CheckConstraint) {
+		rows = append(rows, Row{"def", db.Name(), check.Name, check.Expression})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+// checkConstraintsIter calls the function given with every check constraint of the tables of the databases.
+func checkConstraintsIter(ctx *Context, c *Catalog, f func(db Database, t Table, check CheckConstraint)) error {
+	for _, db := range c.AllDatabases() {
+		err := DBTableIter(ctx, db, func(t Table) (cont bool, err error) {
+			ct := plan.GetCheckTable(t)
+			if ct == nil {
+				return true, nil
+			}
+			checks, err := ct.GetChecks(ctx)
+			if err != nil {
+				return false, err
+			}
+			for _, check := range checks {
+				f(db, t, check)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func emptyRowIter(ctx *Context, c *Catalog) (RowIter, error) {
 	return RowsToRowIter(), nil
 }
@@ -663,7 +786,13 @@ func NewInformationSchemaDatabase(cat *Catalog) Database {
 				name:    TableConstraintsTableName,
 				schema:  tableConstraintsSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: tableConstraintsRowIter,
+			},
+			CheckConstraintsTableName: &informationSchemaTable{
+				name:    CheckConstraintsTableName,
+				schema:  checkConstraintsSchema,
+				catalog: cat,
+				rowIter: checkConstraintsRowIter,
 			},
 			ReferentialConstraintsTableName: &informationSchemaTable{
 				name:    ReferentialConstraintsTableName,
@@ -705,7 +834,19 @@ func NewInformationSchemaDatabase(cat *Catalog) Database {
 				name:    UserPrivilegesTableName,
 				schema:  userPrivilegesSchema,
 				catalog: cat,
-				rowIter: emptyRowIter,
+				rowIter: userPrivilegesRowIter,
+			},
+			ResourceGroupsTableName: &informationSchemaTable{
+				name:    ResourceGroupsTableName,
+				schema:  resourceGroupsSchema,
+				catalog: cat,
+				rowIter: resourceGroupsRowIter,
+			},
+			SchemaVersionsTableName: &informationSchemaTable{
+				name:    SchemaVersionsTableName,
+				schema:  schemaVersionsSchema,
+				catalog: cat,
+				rowIter: schemaVersionsRowIter,
 			},
 		},
 	}
@@ -791,7 +932,7 @@ func (t *informationSchemaTable) String() string {
 func (p *informationSchemaPartition) Key() []byte { return p.key }
 
 // Next implements single PartitionIter interface
-func (pit *informationSchemaPartitionIter) Next() (Partition, error) {
+func (pit *informationSchemaPartitionIter) Next(*Context) (Partition, error) {
 	if pit.pos == 0 {
 		pit.pos++
 		return pit, nil
@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"bytes"
+	"io"
+	"sort"
+)
+
+// SortIndexValues reads all the row locations of the iterator given, which it closes, and returns an iterator over
+// them sorted by the keys the function given returns for them, compared as bytes, for the multi-range reads of a
+// MultiRangeReadTable. Tables whose locations are already in the order their rows are stored in, like big-endian
+// positions or encoded primary keys, can give a nil function to sort the locations themselves. Locations with the same
+// key keep the order of the index.
+func SortIndexValues(ctx *Context, iter IndexValueIter, key func(location []byte) ([]byte, error)) (IndexValueIter, error) {
+	var locations, keys [][]byte
+	for {
+		location, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = iter.Close()
+			return nil, err
+		}
+
+		k := location
+		if key != nil {
+			k, err = key(location)
+			if err != nil {
+				_ = iter.Close()
+				return nil, err
+			}
+		}
+		locations = append(locations, location)
+		keys = append(keys, k)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	sort.Stable(&sortedLocations{locations, keys})
+	return &sortedIndexValueIter{locations: locations}, nil
+}
+
+// sortedLocations sorts row locations by their keys.
+type sortedLocations struct {
+	locations [][]byte
+	keys      [][]byte
+}
+
+func (s *sortedLocations) Len() int {
+	return len(s.locations)
+}
+
+func (s *sortedLocations) Less(i, j int) bool {
+	return bytes.Compare(s.keys[i], s.keys[j]) < 0
+}
+
+func (s *sortedLocations) Swap(i, j int) {
+	s.locations[i], s.locations[j] = s.locations[j], s.locations[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// sortedIndexValueIter is an IndexValueIter over row locations read and sorted beforehand.
+type sortedIndexValueIter struct {
+	locations [][]byte
+	pos       int
+}
+
+func (i *sortedIndexValueIter) Next(*Context) ([]byte, error) {
+	if i.pos >= len(i.locations) {
+		return nil, io.EOF
+	}
+	location := i.locations[i.pos]
+	i.pos++
+	return location, nil
+}
+
+func (i *sortedIndexValueIter) Close() error {
+	i.locations = nil
+	return nil
+}
@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type locationsIter struct {
+	locations [][]byte
+	err       error
+	closed    bool
+}
+
+func (i *locationsIter) Next(*Context) ([]byte, error) {
+	if len(i.locations) == 0 {
+		if i.err != nil {
+			return nil, i.err
+		}
+		return nil, io.EOF
+	}
+	location := i.locations[0]
+	i.locations = i.locations[1:]
+	return location, nil
+}
+
+func (i *locationsIter) Close() error {
+	i.closed = true
+	return nil
+}
+
+func readLocations(t *testing.T, iter IndexValueIter) []string {
+	var locations []string
+	for {
+		location, err := iter.Next(NewEmptyContext())
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		locations = append(locations, string(location))
+	}
+	require.NoError(t, iter.Close())
+	return locations
+}
+
+func TestSortIndexValues(t *testing.T) {
+	ctx := NewEmptyContext()
+
+	t.Run("by location", func(t *testing.T) {
+		require := require.New(t)
+		values := &locationsIter{locations: [][]byte{[]byte("c"), []byte("a"), []byte("d"), []byte("b")}}
+
+		sorted, err := SortIndexValues(ctx, values, nil)
+		require.NoError(err)
+		require.True(values.closed)
+		require.Equal([]string{"a", "b", "c", "d"}, readLocations(t, sorted))
+	})
+
+	t.Run("by key", func(t *testing.T) {
+		require := require.New(t)
+		values := &locationsIter{locations: [][]byte{[]byte("x2"), []byte("y1"), []byte("z2"), []byte("w0")}}
+
+		sorted, err := SortIndexValues(ctx, values, func(location []byte) ([]byte, error) {
+			return location[1:], nil
+		})
+		require.NoError(err)
+		require.Equal([]string{"w0", "y1", "x2", "z2"}, readLocations(t, sorted))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		sorted, err := SortIndexValues(ctx, &locationsIter{}, nil)
+		require.NoError(t, err)
+		require.Empty(t, readLocations(t, sorted))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require := require.New(t)
+		values := &locationsIter{locations: [][]byte{[]byte("a")}, err: errors.New("read error")}
+
+		_, err := SortIndexValues(ctx, values, nil)
+		require.EqualError(err, "read error")
+		require.True(values.closed)
+
+		values = &locationsIter{locations: [][]byte{[]byte("a")}}
+		_, err = SortIndexValues(ctx, values, func([]byte) ([]byte, error) {
+			return nil, errors.New("key error")
+		})
+		require.EqualError(err, "key error")
+		require.True(values.closed)
+	})
+}
@@ -0,0 +1,246 @@
+package mysql_db
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	. "github.com/dolthub/go-mysql-server/sql"
+)
+
+const (
+	// MySQLDatabaseName is the name of the mysql database.
+	MySQLDatabaseName = "mysql"
+	// UserTableName is the name of the user table, with the accounts and their privileges on all the databases.
+	UserTableName = "user"
+	// DbTableName is the name of the db table, with the privileges of the accounts on each database.
+	DbTableName = "db"
+	// ColumnsPrivTableName is the name of the columns_priv table, with the columns granted to each account.
+	ColumnsPrivTableName = "columns_priv"
+)
+
+// userPrivilegeColumns are the columns of the privileges of the user table, and the privileges they show.
+var userPrivilegeColumns = []struct {
+	column    string
+	privilege string
+}{
+	{"Select_priv", "SELECT"},
+	{"Insert_priv", "INSERT"},
+	{"Update_priv", "UPDATE"},
+	{"Delete_priv", "DELETE"},
+	{"Create_priv", "CREATE"},
+	{"Drop_priv", "DROP"},
+	{"Index_priv", "INDEX"},
+	{"Alter_priv", "ALTER"},
+	{"Super_priv", "SUPER"},
+	{"Lock_tables_priv", "LOCK TABLES"},
+	{"Create_view_priv", "CREATE VIEW"},
+	{"Trigger_priv", "TRIGGER"},
+}
+
+// dbPrivilegeColumns are the columns of the privileges of the db table.
+var dbPrivilegeColumns = []string{
+	"Select_priv", "Insert_priv", "Update_priv", "Delete_priv", "Create_priv", "Drop_priv", "Index_priv",
+	"Alter_priv", "Lock_tables_priv", "Create_view_priv", "Trigger_priv",
+}
+
+func userSchema() Schema {
+	schema := Schema{
+		{Name: "Host", Type: LongText, Source: UserTableName},
+		{Name: "User", Type: LongText, Source: UserTableName},
+	}
+	for _, c := range userPrivilegeColumns {
+		schema = append(schema, &Column{Name: c.column, Type: LongText, Source: UserTableName})
+	}
+	return append(schema,
+		&Column{Name: "plugin", Type: LongText, Source: UserTableName},
+		&Column{Name: "authentication_string", Type: LongText, Source: UserTableName, Nullable: true},
+		&Column{Name: "password_expired", Type: LongText, Source: UserTableName},
+		&Column{Name: "account_locked", Type: LongText, Source: UserTableName},
+	)
+}
+
+func dbSchema() Schema {
+	schema := Schema{
+		{Name: "Host", Type: LongText, Source: DbTableName},
+		{Name: "Db", Type: LongText, Source: DbTableName},
+		{Name: "User", Type: LongText, Source: DbTableName},
+	}
+	for _, c := range dbPrivilegeColumns {
+		schema = append(schema, &Column{Name: c, Type: LongText, Source: DbTableName})
+	}
+	return schema
+}
+
+var columnsPrivSchema = Schema{
+	{Name: "Host", Type: LongText, Source: ColumnsPrivTableName},
+	{Name: "Db", Type: LongText, Source: ColumnsPrivTableName},
+	{Name: "User", Type: LongText, Source: ColumnsPrivTableName},
+	{Name: "Table_name", Type: LongText, Source: ColumnsPrivTableName},
+	{Name: "Column_name", Type: LongText, Source: ColumnsPrivTableName},
+	{Name: "Column_priv", Type: LongText, Source: ColumnsPrivTableName},
+}
+
+func userRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, a := range c.Accounts() {
+		row := Row{a.Host, a.User}
+		for _, p := range userPrivilegeColumns {
+			row = append(row, yesOrNo(a.HasPrivilege(p.privilege)))
+		}
+		var authenticationString interface{}
+		if a.AuthenticationString != "" {
+			authenticationString = a.AuthenticationString
+		}
+		rows = append(rows, append(row, a.Plugin, authenticationString, yesOrNo(a.PasswordExpired), yesOrNo(a.Locked)))
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func columnsPrivRowIter(ctx *Context, c *Catalog) (RowIter, error) {
+	var rows []Row
+	for _, g := range c.ColumnGrants() {
+		host := "%"
+		if a, ok := c.Account(g.User); ok {
+			host = a.Host
+		}
+		for _, col := range g.Columns {
+			rows = append(rows, Row{host, g.Database, g.User, g.Table, col, "Select"})
+		}
+	}
+	return RowsToRowIter(rows...), nil
+}
+
+func yesOrNo(b bool) string {
+	if b {
+		return "Y"
+	}
+	return "N"
+}
+
+type mysqlDatabase struct {
+	tables map[string]Table
+}
+
+type mysqlTable struct {
+	name    string
+	schema  Schema
+	catalog *Catalog
+	rowIter func(*Context, *Catalog) (RowIter, error)
+}
+
+type mysqlPartition struct {
+	key []byte
+}
+
+type mysqlPartitionIter struct {
+	mysqlPartition
+	pos int
+}
+
+var (
+	_ Database      = (*mysqlDatabase)(nil)
+	_ Table         = (*mysqlTable)(nil)
+	_ Partition     = (*mysqlPartition)(nil)
+	_ PartitionIter = (*mysqlPartitionIter)(nil)
+)
+
+// NewMySQLDatabase creates a new read-only mysql Database, whose tables show the accounts of the catalog and their
+// privileges, for the administration tools that read them instead of running SHOW GRANTS. Privileges are only
+// granted on all the databases and on columns, so the db table is always empty.
+func NewMySQLDatabase(cat *Catalog) Database {
+	return &mysqlDatabase{
+		tables: map[string]Table{
+			UserTableName: &mysqlTable{
+				name:    UserTableName,
+				schema:  userSchema(),
+				catalog: cat,
+				rowIter: userRowIter,
+			},
+			DbTableName: &mysqlTable{
+				name:    DbTableName,
+				schema:  dbSchema(),
+				catalog: cat,
+			},
+			ColumnsPrivTableName: &mysqlTable{
+				name:    ColumnsPrivTableName,
+				schema:  columnsPrivSchema,
+				catalog: cat,
+				rowIter: columnsPrivRowIter,
+			},
+		},
+	}
+}
+
+// Name implements the sql.Database interface.
+func (db *mysqlDatabase) Name() string { return MySQLDatabaseName }
+
+// GetTableInsensitive implements the sql.Database interface.
+func (db *mysqlDatabase) GetTableInsensitive(ctx *Context, tblName string) (Table, bool, error) {
+	tbl, ok := GetTableInsensitive(tblName, db.tables)
+	return tbl, ok, nil
+}
+
+// GetTableNames implements the sql.Database interface.
+func (db *mysqlDatabase) GetTableNames(ctx *Context) ([]string, error) {
+	tblNames := make([]string, 0, len(db.tables))
+	for k := range db.tables {
+		tblNames = append(tblNames, k)
+	}
+	return tblNames, nil
+}
+
+// Name implements the sql.Table interface.
+func (t *mysqlTable) Name() string { return t.name }
+
+// Schema implements the sql.Table interface.
+func (t *mysqlTable) Schema() Schema { return t.schema }
+
+// Partitions implements the sql.Table interface.
+func (t *mysqlTable) Partitions(ctx *Context) (PartitionIter, error) {
+	return &mysqlPartitionIter{mysqlPartition: mysqlPartition{partitionKey(t.Name())}}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *mysqlTable) PartitionRows(ctx *Context, partition Partition) (RowIter, error) {
+	if !bytes.Equal(partition.Key(), partitionKey(t.Name())) {
+		return nil, fmt.Errorf("partition not found: %q", partition.Key())
+	}
+	if t.rowIter == nil {
+		return RowsToRowIter(), nil
+	}
+	return t.rowIter(ctx, t.catalog)
+}
+
+func (t *mysqlTable) String() string {
+	p := NewTreePrinter()
+	_ = p.WriteNode("Table(%s)", t.name)
+	children := make([]string, len(t.schema))
+	for i, col := range t.schema {
+		children[i] = fmt.Sprintf("Column(%s, %s, nullable=%v)", col.Name, col.Type.String(), col.Nullable)
+	}
+	_ = p.WriteChildren(children...)
+	return p.String()
+}
+
+// Key implements the sql.Partition interface.
+func (p *mysqlPartition) Key() []byte { return p.key }
+
+// Next implements the sql.PartitionIter interface.
+func (pit *mysqlPartitionIter) Next(*Context) (Partition, error) {
+	if pit.pos == 0 {
+		pit.pos++
+		return pit, nil
+	}
+	return nil, io.EOF
+}
+
+// Close implements the sql.PartitionIter interface.
+func (pit *mysqlPartitionIter) Close() error {
+	pit.pos = 0
+	return nil
+}
+
+func partitionKey(tableName string) []byte {
+	return []byte(MySQLDatabaseName + "." + tableName)
+}
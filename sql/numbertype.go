@@ -140,6 +140,10 @@ func (t numberTypeImpl) Convert(v interface{}) (interface{}, error) {
 		v = ti.UTC().Unix()
 	}
 
+	if b, ok := v.(BinaryLiteral); ok {
+		v = b.Uint64()
+	}
+
 	switch t.baseType {
 	case sqltypes.Int8:
 		if dec, ok := v.(decimal.Decimal); ok {
@@ -328,7 +332,7 @@ func (t numberTypeImpl) SQL(v interface{}) (sqltypes.Value, error) {
 	case sqltypes.Uint16:
 		return sqltypes.MakeTrusted(sqltypes.Uint16, strconv.AppendUint(nil, cast.ToUint64(v), 10)), nil
 	case sqltypes.Int24:
-		return sqltypes.MakeTrusted(sqltypes.Int24, strconv.AppendUint(nil, cast.ToUint64(v), 10)), nil
+		return sqltypes.MakeTrusted(sqltypes.Int24, strconv.AppendInt(nil, cast.ToInt64(v), 10)), nil
 	case sqltypes.Uint24:
 		return sqltypes.MakeTrusted(sqltypes.Uint24, strconv.AppendUint(nil, cast.ToUint64(v), 10)), nil
 	case sqltypes.Int32:
@@ -417,6 +421,38 @@ func (t numberTypeImpl) Zero() interface{} {
 	}
 }
 
+// limits returns the minimum and maximum values of the type.
+func (t numberTypeImpl) limits() (interface{}, interface{}) {
+	switch t.baseType {
+	case sqltypes.Int8:
+		return int8(math.MinInt8), int8(math.MaxInt8)
+	case sqltypes.Uint8:
+		return uint8(0), uint8(math.MaxUint8)
+	case sqltypes.Int16:
+		return int16(math.MinInt16), int16(math.MaxInt16)
+	case sqltypes.Uint16:
+		return uint16(0), uint16(math.MaxUint16)
+	case sqltypes.Int24:
+		return int32(-1 << 23), int32(1<<23 - 1)
+	case sqltypes.Uint24:
+		return uint32(0), uint32(1<<24 - 1)
+	case sqltypes.Int32:
+		return int32(math.MinInt32), int32(math.MaxInt32)
+	case sqltypes.Uint32:
+		return uint32(0), uint32(math.MaxUint32)
+	case sqltypes.Int64:
+		return int64(math.MinInt64), int64(math.MaxInt64)
+	case sqltypes.Uint64:
+		return uint64(0), uint64(math.MaxUint64)
+	case sqltypes.Float32:
+		return float32(-math.MaxFloat32), float32(math.MaxFloat32)
+	case sqltypes.Float64:
+		return -math.MaxFloat64, math.MaxFloat64
+	default:
+		panic(fmt.Sprintf("%v is not a valid number base type", t.baseType.String()))
+	}
+}
+
 // IsFloat implements NumberType interface.
 func (t numberTypeImpl) IsFloat() bool {
 	switch t.baseType {
@@ -0,0 +1,284 @@
+package parse
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var createTableRegex = regexp.MustCompile(`^create\s+(temporary\s+)?table\s`)
+
+var alterTableRegex = regexp.MustCompile(`^alter\s+table\s`)
+
+// checkDefinition is a check constraint declared in a CREATE TABLE statement.
+type checkDefinition struct {
+	// name is the name of the constraint, empty if it wasn't given one.
+	name string
+	// expr is the text of the expression of the constraint.
+	expr     string
+	enforced bool
+	// column is the column the constraint was declared with, empty if it was declared with the table.
+	column string
+}
+
+// rewriteCheckConstraints returns the CREATE TABLE statement given without its check constraints, which the parser
+// doesn't support, and the check constraints removed from it. If there are none, or they can't be told apart, the
+// statement is returned as is, so the parser reports any error.
+func rewriteCheckConstraints(query string) (string, []checkDefinition) {
+	tokens := tokenize(query)
+	var checks []checkDefinition
+	// removed are the ranges of offsets of the query taken by the check constraints, which are left out
+	var removed [][2]int
+	depth := 0
+	// element is the index of the first token of the current column or constraint of the table, and separator the index
+	// of the comma before it, or -1 if it's the first one or the comma is removed
+	element, separator := -1, -1
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i].typ {
+		case '(':
+			depth++
+			if depth == 1 && element < 0 {
+				element = i + 1
+			}
+			continue
+		case ')':
+			depth--
+			if depth == 0 && element >= 0 {
+				i = len(tokens)
+			}
+			continue
+		case ',':
+			if depth == 1 {
+				element, separator = i+1, i
+			}
+			continue
+		}
+
+		if depth != 1 || tokens[i].typ != sqlparser.CHECK {
+			continue
+		}
+
+		check := checkDefinition{enforced: true}
+		first := i
+		if i-1 >= element && tokens[i-1].typ == sqlparser.CONSTRAINT {
+			first = i - 1
+		} else if i-2 >= element && tokens[i-2].typ == sqlparser.CONSTRAINT {
+			first = i - 2
+			check.name = tokens[i-1].val
+		}
+
+		if i+1 >= len(tokens) || tokens[i+1].typ != '(' {
+			return query, nil
+		}
+		open := i + 1
+		last := open
+		for nested := 0; ; last++ {
+			if last == len(tokens) {
+				return query, nil
+			}
+			if tokens[last].typ == '(' {
+				nested++
+			} else if tokens[last].typ == ')' {
+				nested--
+				if nested == 0 {
+					break
+				}
+			}
+		}
+		check.expr = strings.TrimSpace(query[tokens[open].End:tokens[last].Start])
+
+		if last+1 < len(tokens) && isEnforced(tokens[last+1]) {
+			last++
+		} else if last+2 < len(tokens) && tokens[last+1].typ == sqlparser.NOT && isEnforced(tokens[last+2]) {
+			check.enforced = false
+			last += 2
+		}
+
+		start, end := tokens[first].Start, tokens[last].End
+		if first == element {
+			// Constraints of the table are removed with the comma separating them from the previous column or
+			// constraint, or the next one if they're the first
+			if separator >= 0 {
+				start = tokens[separator].Start
+			} else if last+1 < len(tokens) && tokens[last+1].typ == ',' {
+				end = tokens[last+1].End
+				last++
+				element = last + 1
+			} else {
+				return query, nil
+			}
+		} else {
+			check.column = tokens[element].val
+		}
+
+		checks = append(checks, check)
+		removed = append(removed, [2]int{start, end})
+		i = last
+	}
+
+	if len(checks) == 0 {
+		return query, nil
+	}
+
+	var sb strings.Builder
+	written := 0
+	for _, r := range removed {
+		sb.WriteString(query[written:r[0]])
+		written = r[1]
+	}
+	sb.WriteString(query[written:])
+	return sb.String(), checks
+}
+
+// parseAlterCheck returns the node of the ALTER TABLE statement given if it adds a check constraint, with ADD
+// [CONSTRAINT [name]] CHECK (expr) [[NOT] ENFORCED], or drops one, with DROP CHECK name, which the parser doesn't
+// support, and whether it's one of them. Other ALTER TABLE statements are left to the parser.
+func parseAlterCheck(query string) (sql.Node, bool, error) {
+	tokens := tokenize(query)
+	if len(tokens) < 5 || tokens[0].typ != sqlparser.ALTER || tokens[1].typ != sqlparser.TABLE ||
+		tokens[2].typ != sqlparser.ID {
+		return nil, false, nil
+	}
+
+	i := 3
+	db, table := "", tokens[2].val
+	if len(tokens) > 5 && tokens[3].typ == '.' && tokens[4].typ == sqlparser.ID {
+		db, table = table, tokens[4].val
+		i = 5
+	}
+	node := plan.NewUnresolvedTable(table, db)
+
+	switch tokens[i].typ {
+	case sqlparser.DROP:
+		if len(tokens) != i+3 || tokens[i+1].typ != sqlparser.CHECK || tokens[i+2].typ != sqlparser.ID {
+			return nil, false, nil
+		}
+		return plan.NewAlterDropCheck(node, tokens[i+2].val), true, nil
+	case sqlparser.ADD:
+		i++
+	default:
+		return nil, false, nil
+	}
+
+	check := &sql.CheckConstraint{Enforced: true}
+	if i < len(tokens) && tokens[i].typ == sqlparser.CONSTRAINT {
+		i++
+		if i < len(tokens) && tokens[i].typ == sqlparser.ID {
+			check.Name = tokens[i].val
+			i++
+		}
+	}
+	if i+1 >= len(tokens) || tokens[i].typ != sqlparser.CHECK || tokens[i+1].typ != '(' {
+		return nil, false, nil
+	}
+
+	open := i + 1
+	last := open
+	for nested := 0; ; last++ {
+		if last == len(tokens) {
+			return nil, false, nil
+		}
+		if tokens[last].typ == '(' {
+			nested++
+		} else if tokens[last].typ == ')' {
+			nested--
+			if nested == 0 {
+				break
+			}
+		}
+	}
+	check.Expression = strings.TrimSpace(query[tokens[open].End:tokens[last].Start])
+
+	rest := tokens[last+1:]
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 1 && isEnforced(rest[0]):
+	case len(rest) == 2 && rest[0].typ == sqlparser.NOT && isEnforced(rest[1]):
+		check.Enforced = false
+	default:
+		return nil, false, nil
+	}
+
+	return plan.NewAlterAddCheck(node, check), true, nil
+}
+
+// isEnforced returns whether the token given is the ENFORCED keyword, which the parser doesn't know.
+func isEnforced(t token) bool {
+	return t.typ == sqlparser.ID && strings.ToLower(t.val) == "enforced"
+}
+
+// convertCheckConstraints returns the CREATE TABLE node given with the check constraints given, validated on the
+// schema of the table. The check constraints given no name are named after the table like MySQL names them.
+func convertCheckConstraints(ctx *sql.Context, node sql.Node, checks []checkDefinition) (sql.Node, error) {
+	ct, ok := node.(*plan.CreateTable)
+	if !ok {
+		return nil, ErrUnsupportedSyntax.New("CHECK")
+	}
+
+	chDefs := make([]*sql.CheckConstraint, len(checks))
+	names := make(map[string]bool)
+	generated := 0
+	for i, check := range checks {
+		name := check.name
+		if name == "" {
+			generated++
+			name = fmt.Sprintf("%s_chk_%d", ct.Name(), generated)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, sql.ErrCheckConstraintDuplicateName.New(name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if err := validateCheckExpression(ctx, ct.Schema(), ct.Name(), name, check); err != nil {
+			return nil, err
+		}
+
+		chDefs[i] = &sql.CheckConstraint{
+			Name:       name,
+			Expression: check.expr,
+			Enforced:   check.enforced,
+		}
+	}
+
+	return ct.WithChecks(chDefs), nil
+}
+
+// validateCheckExpression returns an error if the expression of the check constraint given doesn't parse, has
+// subqueries, or references columns the schema of the table given doesn't have. The check constraints of columns can
+// only reference their column.
+func validateCheckExpression(ctx *sql.Context, schema sql.Schema, table, name string, check checkDefinition) error {
+	expr, err := StringToExpression(ctx, check.expr)
+	if err != nil {
+		return err
+	}
+
+	sql.Inspect(expr, func(e sql.Expression) bool {
+		if err != nil {
+			return false
+		}
+		switch e := e.(type) {
+		case *plan.Subquery:
+			err = sql.ErrCheckConstraintSubquery.New(name)
+		case *expression.UnresolvedColumn:
+			if check.column != "" && !strings.EqualFold(e.Name(), check.column) {
+				err = sql.ErrCheckConstraintOtherColumn.New(name)
+			} else if !schema.Contains(e.Name(), table) {
+				err = sql.ErrCheckConstraintUnknownColumn.New(e.Name(), name)
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// StringToExpression parses the text of an expression, like the expressions of check constraints, and returns the
+// equivalent Expression, which isn't resolved.
+func StringToExpression(ctx *sql.Context, exprStr string) (sql.Expression, error) {
+	return parseExpr(ctx, exprStr)
+}
@@ -0,0 +1,112 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseCheckConstraints(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected []*sql.CheckConstraint
+	}{
+		{
+			"CREATE TABLE t (a INT CHECK (a > 0), b INT, CONSTRAINT b_lt_a CHECK (b < a) NOT ENFORCED)",
+			[]*sql.CheckConstraint{
+				{Name: "t_chk_1", Expression: "a > 0", Enforced: true},
+				{Name: "b_lt_a", Expression: "b < a", Enforced: false},
+			},
+		},
+		{
+			"create table t (check (a in (1, 2)), a int primary key, check (a <> 2) enforced)",
+			[]*sql.CheckConstraint{
+				{Name: "t_chk_1", Expression: "a in (1, 2)", Enforced: true},
+				{Name: "t_chk_2", Expression: "a <> 2", Enforced: true},
+			},
+		},
+		{
+			"create table t (check (a > 0), check (a < 10), a int)",
+			[]*sql.CheckConstraint{
+				{Name: "t_chk_1", Expression: "a > 0", Enforced: true},
+				{Name: "t_chk_2", Expression: "a < 10", Enforced: true},
+			},
+		},
+		{
+			"create table t (`check` int constraint `c c` check (`check` > 0))",
+			[]*sql.CheckConstraint{
+				{Name: "c c", Expression: "`check` > 0", Enforced: true},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			require := require.New(t)
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(err)
+			ct, ok := node.(*plan.CreateTable)
+			require.True(ok)
+			require.Equal(tt.expected, ct.Checks())
+		})
+	}
+}
+
+func TestParseCheckConstraintErrors(t *testing.T) {
+	testCases := []struct {
+		query string
+		err   error
+	}{
+		{"create table t (a int, check (b > 0))", sql.ErrCheckConstraintUnknownColumn.New("b", "t_chk_1")},
+		{"create table t (a int check (b > 0), b int)", sql.ErrCheckConstraintOtherColumn.New("t_chk_1")},
+		{"create table t (a int, constraint c check (a > 0), constraint C check (a > 1))", sql.ErrCheckConstraintDuplicateName.New("C")},
+		{"create table t (a int, check ((select 1) > a))", sql.ErrCheckConstraintSubquery.New("t_chk_1")},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Equal(t, tt.err.Error(), err.Error())
+		})
+	}
+}
+
+func TestParseAlterCheckConstraints(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"ALTER TABLE t ADD CONSTRAINT c CHECK (a > 0)",
+			plan.NewAlterAddCheck(plan.NewUnresolvedTable("t", ""), &sql.CheckConstraint{Name: "c", Expression: "a > 0", Enforced: true}),
+		},
+		{
+			"alter table mydb.t add check (a in (1, 2)) not enforced",
+			plan.NewAlterAddCheck(plan.NewUnresolvedTable("t", "mydb"), &sql.CheckConstraint{Expression: "a in (1, 2)", Enforced: false}),
+		},
+		{
+			"alter table t add constraint check (a < 10) enforced",
+			plan.NewAlterAddCheck(plan.NewUnresolvedTable("t", ""), &sql.CheckConstraint{Expression: "a < 10", Enforced: true}),
+		},
+		{
+			"alter table `t` drop check `c c`",
+			plan.NewAlterDropCheck(plan.NewUnresolvedTable("t", ""), "c c"),
+		},
+		{
+			"alter table t drop constraint c",
+			plan.NewAlterDropConstraint(plan.NewUnresolvedTable("t", ""), "c"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			require := require.New(t)
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(err)
+			require.Equal(tt.expected, node)
+		})
+	}
+}
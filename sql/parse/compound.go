@@ -0,0 +1,845 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	// ErrUndeclaredVariable is returned when FETCH sets a variable that isn't declared.
+	ErrUndeclaredVariable = errors.NewKind("Undeclared variable: %s")
+	// ErrUndefinedCursor is returned when OPEN, FETCH or CLOSE use a cursor that isn't declared.
+	ErrUndefinedCursor = errors.NewKind("Undefined CURSOR: %s")
+	// ErrNoMatchingLabel is returned when LEAVE or ITERATE use a label that isn't the one of a block or loop around
+	// them. ITERATE can only use the labels of loops.
+	ErrNoMatchingLabel = errors.NewKind("%s with no matching label: %s")
+	// ErrEndLabel is returned when the label after the END of a block or loop isn't its label.
+	ErrEndLabel = errors.NewKind("End-label %s without match")
+	// ErrDuplicateVariable is returned when a block declares a variable twice.
+	ErrDuplicateVariable = errors.NewKind("Duplicate variable: %s")
+	// ErrDuplicateCursor is returned when a block declares a cursor twice.
+	ErrDuplicateCursor = errors.NewKind("Duplicate cursor: %s")
+	// ErrVariableAfter is returned when a block declares a variable after a cursor or a handler.
+	ErrVariableAfter = errors.NewKind("Variable or condition declaration after cursor or handler declaration")
+	// ErrCursorAfter is returned when a block declares a cursor after a handler.
+	ErrCursorAfter = errors.NewKind("Cursor declaration after handler declaration")
+	// ErrBadSQLState is returned when a handler is declared for a SQLSTATE that isn't valid.
+	ErrBadSQLState = errors.NewKind("Bad SQLSTATE: '%s'")
+)
+
+var createTriggerRegex = regexp.MustCompile("(?is)^(create\\s+(definer\\s*=\\s*\\S+\\s+)?trigger\\s.*?\\sfor\\s+each\\s+row(\\s+(follows|precedes)\\s+(`[^`]+`|\\S+))?)\\s+(.*)$")
+
+// parseCompoundTrigger parses the CREATE TRIGGER statements whose body the vitess parser rejects, which are the ones
+// using the compound statements it doesn't support: the local variables, cursors and condition handlers declared by
+// BEGIN ... END blocks, labeled blocks, LOOP, WHILE, REPEAT, LEAVE, ITERATE and IF. The other statements of the body
+// are parsed as usual. parseErr is returned if the statement isn't a CREATE TRIGGER statement.
+func parseCompoundTrigger(ctx *sql.Context, query string, parseErr error) (sql.Node, error) {
+	match := createTriggerRegex.FindStringSubmatchIndex(query)
+	if match == nil {
+		return nil, parseErr
+	}
+
+	header := query[:match[3]]
+	bodyStr := strings.TrimSpace(query[match[12]:])
+
+	// The header is parsed with a body the vitess parser supports
+	stmt, err := sqlparser.ParseStrictDDL(header + " SET @__trigger_body = 0")
+	if err != nil {
+		return nil, parseErr
+	}
+	ddl, ok := stmt.(*sqlparser.DDL)
+	if !ok || ddl.TriggerSpec == nil {
+		return nil, parseErr
+	}
+
+	p := &compoundParser{ctx: ctx, query: bodyStr, tokens: tokenizeCompound(bodyStr)}
+	body, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != compoundEOF && !(tok.is(";") && p.pos == len(p.tokens)-1) {
+		return nil, errUnexpectedSyntax.New("EOF", tok.text)
+	}
+
+	var triggerOrder *plan.TriggerOrder
+	if ddl.TriggerSpec.Order != nil {
+		triggerOrder = &plan.TriggerOrder{
+			PrecedesOrFollows: ddl.TriggerSpec.Order.PrecedesOrFollows,
+			OtherTriggerName:  ddl.TriggerSpec.Order.OtherTriggerName,
+		}
+	}
+
+	return plan.NewCreateTrigger(ddl.TriggerSpec.Name, ddl.TriggerSpec.Time, ddl.TriggerSpec.Event, triggerOrder, tableNameToUnresolvedTable(ddl.Table), body, restoreRewrites(query), restoreRewrites(bodyStr)), nil
+}
+
+type compoundTokenKind byte
+
+const (
+	compoundEOF compoundTokenKind = iota
+	compoundWord
+	compoundString
+	compoundPunct
+)
+
+// compoundToken is a token of a compound statement. Words are identifiers, keywords, variables and numbers, which can
+// be quoted with backticks, strings are quoted with single or double quotes, and punctuation tokens are the other
+// characters, one at a time.
+type compoundToken struct {
+	kind compoundTokenKind
+	text string
+	// start and end are the offsets of the token in the statement.
+	start, end int
+}
+
+// is returns whether the token is the unquoted keyword or punctuation given, ignoring case.
+func (t compoundToken) is(s string) bool {
+	return (t.kind == compoundWord || t.kind == compoundPunct) && strings.EqualFold(t.text, s)
+}
+
+// name returns the identifier of a word token, without its backticks.
+func (t compoundToken) name() string {
+	if strings.HasPrefix(t.text, "`") && strings.HasSuffix(t.text, "`") && len(t.text) > 1 {
+		return strings.Replace(t.text[1:len(t.text)-1], "``", "`", -1)
+	}
+	return t.text
+}
+
+func tokenizeCompound(s string) []compoundToken {
+	var tokens []compoundToken
+	isWordRune := func(r byte) bool {
+		return r == '_' || r == '$' || r == '@' || r == '.' || r >= 0x80 || unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r))
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(s) {
+				if s[j] == '\\' && c != '`' {
+					j += 2
+					continue
+				}
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(s) {
+				j = len(s) - 1
+			}
+			kind := compoundString
+			if c == '`' {
+				kind = compoundWord
+			}
+			tokens = append(tokens, compoundToken{kind: kind, text: s[i : j+1], start: i, end: j + 1})
+			i = j + 1
+		case isWordRune(c):
+			j := i
+			for j < len(s) && isWordRune(s[j]) {
+				j++
+			}
+			tokens = append(tokens, compoundToken{kind: compoundWord, text: s[i:j], start: i, end: j})
+			i = j
+		default:
+			tokens = append(tokens, compoundToken{kind: compoundPunct, text: s[i : i+1], start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens
+}
+
+// compoundScope holds the local variables and cursors declared by a BEGIN ... END block while it's parsed.
+type compoundScope struct {
+	parent    *compoundScope
+	frame     *plan.BlockFrame
+	variables map[string]*plan.LocalVariable
+	cursors   map[string]bool
+}
+
+func (s *compoundScope) variable(name string) *plan.LocalVariable {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.variables[strings.ToLower(name)]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// cursorFrame returns the frame of the block that declared the cursor with the name given, or nil if there's none.
+func (s *compoundScope) cursorFrame(name string) *plan.BlockFrame {
+	for ; s != nil; s = s.parent {
+		if s.cursors[strings.ToLower(name)] {
+			return s.frame
+		}
+	}
+	return nil
+}
+
+type compoundLabel struct {
+	name string
+	loop bool
+}
+
+// compoundParser parses compound statements, which are the body of triggers.
+type compoundParser struct {
+	ctx    *sql.Context
+	query  string
+	tokens []compoundToken
+	pos    int
+	scope  *compoundScope
+	labels []compoundLabel
+}
+
+func (p *compoundParser) peek() compoundToken {
+	return p.peekAt(0)
+}
+
+func (p *compoundParser) peekAt(n int) compoundToken {
+	if p.pos+n >= len(p.tokens) {
+		return compoundToken{kind: compoundEOF, text: "EOF", start: len(p.query), end: len(p.query)}
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *compoundParser) next() compoundToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *compoundParser) expect(keywords ...string) error {
+	for _, k := range keywords {
+		if tok := p.next(); !tok.is(k) {
+			return errUnexpectedSyntax.New(strings.ToUpper(k), tok.text)
+		}
+	}
+	return nil
+}
+
+func (p *compoundParser) accept(keyword string) bool {
+	if p.peek().is(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *compoundParser) ident() (string, error) {
+	tok := p.next()
+	if tok.kind != compoundWord {
+		return "", errUnexpectedSyntax.New("an identifier", tok.text)
+	}
+	return tok.name(), nil
+}
+
+func (p *compoundParser) frame() *plan.BlockFrame {
+	if p.scope == nil {
+		return nil
+	}
+	return p.scope.frame
+}
+
+// parseStatement parses the statement starting at the current token, without the semicolon that ends it.
+func (p *compoundParser) parseStatement() (sql.Node, error) {
+	label := ""
+	if p.peek().kind == compoundWord && p.peekAt(1).is(":") {
+		label = p.next().name()
+		p.next()
+		if tok := p.peek(); !tok.is("begin") && !tok.is("loop") && !tok.is("while") && !tok.is("repeat") {
+			return nil, errUnexpectedSyntax.New("BEGIN, LOOP, WHILE or REPEAT", tok.text)
+		}
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.is("begin"):
+		return p.parseBlock(label)
+	case tok.is("loop"), tok.is("while"), tok.is("repeat"):
+		return p.parseLoop(label)
+	case tok.is("if"):
+		return p.parseIf()
+	case tok.is("leave"), tok.is("iterate"):
+		return p.parseLeaveOrIterate()
+	case tok.is("open"), tok.is("close"), tok.is("fetch"):
+		return p.parseCursorStatement()
+	case tok.is("declare"):
+		return nil, errUnexpectedSyntax.New("a statement", tok.text)
+	case tok.is("case"):
+		return nil, ErrUnsupportedFeature.New("CASE statement")
+	default:
+		return p.parseSimpleStatement()
+	}
+}
+
+// parseStatements parses statements ended by semicolons until one of the keywords given.
+func (p *compoundParser) parseStatements(until ...string) ([]sql.Node, error) {
+	var statements []sql.Node
+	for {
+		for _, k := range until {
+			if p.peek().is(k) {
+				return statements, nil
+			}
+		}
+
+		s, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+}
+
+func (p *compoundParser) parseBlock(label string) (sql.Node, error) {
+	if err := p.expect("begin"); err != nil {
+		return nil, err
+	}
+
+	p.scope = &compoundScope{
+		parent:    p.scope,
+		frame:     plan.NewBlockFrame(p.frame()),
+		variables: make(map[string]*plan.LocalVariable),
+		cursors:   make(map[string]bool),
+	}
+	p.labels = append(p.labels, compoundLabel{name: label})
+	defer func() {
+		p.scope = p.scope.parent
+		p.labels = p.labels[:len(p.labels)-1]
+	}()
+
+	// Variables are declared first, then cursors, then handlers
+	const (
+		declaringVariables = iota
+		declaringCursors
+		declaringHandlers
+	)
+	declaring := declaringVariables
+
+	var statements []sql.Node
+	for p.peek().is("declare") {
+		var (
+			s   sql.Node
+			err error
+		)
+		switch tok := p.peekAt(2); {
+		case p.peekAt(1).is("continue"), p.peekAt(1).is("exit"), p.peekAt(1).is("undo"):
+			declaring = declaringHandlers
+			s, err = p.parseDeclareHandler()
+		case tok.is("cursor"):
+			if declaring > declaringCursors {
+				return nil, ErrCursorAfter.New()
+			}
+			declaring = declaringCursors
+			s, err = p.parseDeclareCursor()
+		case tok.is("condition"):
+			return nil, ErrUnsupportedFeature.New("DECLARE ... CONDITION")
+		default:
+			if declaring > declaringVariables {
+				return nil, ErrVariableAfter.New()
+			}
+			s, err = p.parseDeclareVariables()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(";"); err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+
+	rest, err := p.parseStatements("end")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("end"); err != nil {
+		return nil, err
+	}
+	if err := p.parseEndLabel(label); err != nil {
+		return nil, err
+	}
+
+	return plan.NewLabeledBeginEndBlock(label, p.scope.frame, append(statements, rest...)), nil
+}
+
+// parseEndLabel parses the optional label after the END of a block or loop, which must be its label.
+func (p *compoundParser) parseEndLabel(label string) error {
+	tok := p.peek()
+	if tok.kind != compoundWord {
+		return nil
+	}
+	p.next()
+	if label == "" || !strings.EqualFold(tok.name(), label) {
+		return ErrEndLabel.New(tok.name())
+	}
+	return nil
+}
+
+func (p *compoundParser) parseDeclareVariables() (sql.Node, error) {
+	if err := p.expect("declare"); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.scope.variables[strings.ToLower(name)]; ok {
+			return nil, ErrDuplicateVariable.New(name)
+		}
+		names = append(names, name)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	typeStr := p.readUntil(false, "default", ";")
+	if typeStr == "" {
+		return nil, errUnexpectedSyntax.New("a type", p.peek().text)
+	}
+	typ, err := parseVariableType(typeStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var def sql.Expression
+	if p.accept("default") {
+		def, err = p.parseExpr(p.readUntil(false, ";"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	variables := make([]*plan.LocalVariable, len(names))
+	for i, name := range names {
+		variables[i] = plan.NewLocalVariable(name, typ, p.scope.frame)
+		p.scope.variables[strings.ToLower(name)] = variables[i]
+	}
+	return plan.NewDeclareVariables(variables, def), nil
+}
+
+// parseVariableType parses the type of a local variable, which is written like the type of a column.
+func parseVariableType(typeStr string) (sql.Type, error) {
+	stmt, err := sqlparser.ParseStrictDDL("CREATE TABLE t (x " + typeStr + ")")
+	if err != nil {
+		return nil, err
+	}
+	ddl := stmt.(*sqlparser.DDL)
+	if ddl.TableSpec == nil || len(ddl.TableSpec.Columns) != 1 {
+		return nil, ErrUnsupportedSyntax.New(typeStr)
+	}
+	return sql.ColumnTypeToType(&ddl.TableSpec.Columns[0].Type)
+}
+
+func (p *compoundParser) parseDeclareCursor() (sql.Node, error) {
+	if err := p.expect("declare"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("cursor", "for"); err != nil {
+		return nil, err
+	}
+	if p.scope.cursors[strings.ToLower(name)] {
+		return nil, ErrDuplicateCursor.New(name)
+	}
+	if tok := p.peek(); !tok.is("select") && !tok.is("(") {
+		return nil, errUnexpectedSyntax.New("SELECT", tok.text)
+	}
+
+	query, err := p.parseQuery(p.readUntil(true, ";"))
+	if err != nil {
+		return nil, err
+	}
+	p.scope.cursors[strings.ToLower(name)] = true
+	return plan.NewDeclareCursor(name, query, p.scope.frame), nil
+}
+
+func (p *compoundParser) parseDeclareHandler() (sql.Node, error) {
+	if err := p.expect("declare"); err != nil {
+		return nil, err
+	}
+
+	var action plan.HandlerAction
+	switch tok := p.next(); {
+	case tok.is("continue"):
+		action = plan.ContinueHandler
+	case tok.is("exit"):
+		action = plan.ExitHandler
+	default:
+		return nil, ErrUnsupportedFeature.New("UNDO handlers")
+	}
+	if err := p.expect("handler", "for"); err != nil {
+		return nil, err
+	}
+
+	var conditions []plan.HandlerCondition
+	for {
+		tok := p.next()
+		switch {
+		case tok.is("not"):
+			if err := p.expect("found"); err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, plan.HandlerCondition{Type: plan.HandlerNotFound})
+		case tok.is("sqlexception"):
+			conditions = append(conditions, plan.HandlerCondition{Type: plan.HandlerSQLException})
+		case tok.is("sqlwarning"):
+			conditions = append(conditions, plan.HandlerCondition{Type: plan.HandlerSQLWarning})
+		case tok.is("sqlstate"):
+			p.accept("value")
+			state := p.next()
+			if state.kind != compoundString {
+				return nil, errUnexpectedSyntax.New("a SQLSTATE", state.text)
+			}
+			sqlState := state.text[1 : len(state.text)-1]
+			if len(sqlState) != 5 || strings.HasPrefix(sqlState, "00") {
+				return nil, ErrBadSQLState.New(sqlState)
+			}
+			conditions = append(conditions, plan.HandlerCondition{Type: plan.HandlerSQLState, SQLState: sqlState})
+		case tok.kind == compoundWord:
+			code, err := strconv.Atoi(tok.text)
+			if err != nil {
+				return nil, ErrUnsupportedFeature.New("named conditions")
+			}
+			conditions = append(conditions, plan.HandlerCondition{Type: plan.HandlerErrorCode, Code: code})
+		default:
+			return nil, errUnexpectedSyntax.New("a condition", tok.text)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	// The statement of a handler can't leave or iterate the blocks and loops around its declaration
+	labels := p.labels
+	p.labels = nil
+	statement, err := p.parseStatement()
+	p.labels = labels
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewDeclareHandler(action, conditions, statement, p.scope.frame), nil
+}
+
+func (p *compoundParser) parseLoop(label string) (sql.Node, error) {
+	p.labels = append(p.labels, compoundLabel{name: label, loop: true})
+	defer func() {
+		p.labels = p.labels[:len(p.labels)-1]
+	}()
+
+	var (
+		keyword      string
+		while, until sql.Expression
+		statements   []sql.Node
+		err          error
+	)
+	switch tok := p.next(); {
+	case tok.is("loop"):
+		keyword = "loop"
+		if statements, err = p.parseStatements("end"); err != nil {
+			return nil, err
+		}
+	case tok.is("while"):
+		keyword = "while"
+		if while, err = p.parseExpr(p.readUntil(false, "do")); err != nil {
+			return nil, err
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		if statements, err = p.parseStatements("end"); err != nil {
+			return nil, err
+		}
+	default:
+		keyword = "repeat"
+		if statements, err = p.parseStatements("until"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("until"); err != nil {
+			return nil, err
+		}
+		if until, err = p.parseExpr(p.readUntil(false, "end")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect("end", keyword); err != nil {
+		return nil, err
+	}
+	if err := p.parseEndLabel(label); err != nil {
+		return nil, err
+	}
+	return plan.NewLoop(label, while, until, statements, p.frame()), nil
+}
+
+func (p *compoundParser) parseIf() (sql.Node, error) {
+	if err := p.expect("if"); err != nil {
+		return nil, err
+	}
+
+	var conditions []sql.Expression
+	var branches [][]sql.Node
+	for {
+		cond, err := p.parseExpr(p.readUntil(false, "then"))
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("then"); err != nil {
+			return nil, err
+		}
+		statements, err := p.parseStatements("elseif", "else", "end")
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+		branches = append(branches, statements)
+
+		if !p.accept("elseif") {
+			break
+		}
+	}
+
+	if p.accept("else") {
+		statements, err := p.parseStatements("end")
+		if err != nil {
+			return nil, err
+		}
+		branches = append(branches, statements)
+	}
+
+	if err := p.expect("end", "if"); err != nil {
+		return nil, err
+	}
+	return plan.NewIfElse(conditions, branches, p.frame()), nil
+}
+
+func (p *compoundParser) parseLeaveOrIterate() (sql.Node, error) {
+	keyword := strings.ToUpper(p.next().text)
+	label, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(p.labels) - 1; i >= 0; i-- {
+		l := p.labels[i]
+		if l.name == "" || !strings.EqualFold(l.name, label) {
+			continue
+		}
+		if keyword == "LEAVE" {
+			return plan.NewLeave(label), nil
+		}
+		if l.loop {
+			return plan.NewIterate(label), nil
+		}
+		break
+	}
+	return nil, ErrNoMatchingLabel.New(keyword, label)
+}
+
+func (p *compoundParser) parseCursorStatement() (sql.Node, error) {
+	keyword := p.next()
+	if keyword.is("fetch") {
+		if p.accept("next") {
+			if err := p.expect("from"); err != nil {
+				return nil, err
+			}
+		} else {
+			p.accept("from")
+		}
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	frame := p.scope.cursorFrame(name)
+	if frame == nil {
+		return nil, ErrUndefinedCursor.New(name)
+	}
+
+	switch {
+	case keyword.is("open"):
+		return plan.NewOpenCursor(name, frame), nil
+	case keyword.is("close"):
+		return plan.NewCloseCursor(name, frame), nil
+	}
+
+	if err := p.expect("into"); err != nil {
+		return nil, err
+	}
+	var variables []*plan.LocalVariable
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		v := p.scope.variable(name)
+		if v == nil {
+			return nil, ErrUndeclaredVariable.New(name)
+		}
+		variables = append(variables, v)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return plan.NewFetchCursor(name, variables, frame), nil
+}
+
+// parseSimpleStatement parses a statement that isn't a compound statement with the statement parser.
+func (p *compoundParser) parseSimpleStatement() (sql.Node, error) {
+	str := p.readUntil(true, ";")
+	if str == "" {
+		return nil, errUnexpectedSyntax.New("a statement", p.peek().text)
+	}
+	return p.parseQuery(str)
+}
+
+// readUntil returns the text of the tokens from the current one to the first of the keywords given outside of
+// parentheses, or to the end of the statement. Unless untilSemicolon is set, the keywords aren't looked for in CASE
+// expressions, which end with END.
+func (p *compoundParser) readUntil(untilSemicolon bool, keywords ...string) string {
+	start := p.peek().start
+	end := start
+	depth, cases := 0, 0
+	for {
+		tok := p.peek()
+		if tok.kind == compoundEOF {
+			break
+		}
+		if depth == 0 && cases == 0 {
+			found := false
+			for _, k := range keywords {
+				if tok.is(k) {
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		case tok.is("case") && !untilSemicolon:
+			cases++
+		case tok.is("end") && cases > 0:
+			cases--
+		}
+		end = tok.end
+		p.next()
+	}
+	return strings.TrimSpace(p.query[start:end])
+}
+
+func (p *compoundParser) parseExpr(str string) (sql.Expression, error) {
+	if str == "" {
+		return nil, errUnexpectedSyntax.New("an expression", p.peek().text)
+	}
+	e, err := parseExpr(p.ctx, str)
+	if err != nil {
+		return nil, err
+	}
+	return p.useVariablesInExpression(e)
+}
+
+func (p *compoundParser) parseQuery(str string) (sql.Node, error) {
+	n, err := Parse(p.ctx, str)
+	if err != nil {
+		return nil, err
+	}
+	return p.useVariables(n)
+}
+
+// useVariables replaces the columns of the node given that have the name of a local variable declared by the blocks
+// being parsed with the variable, since local variables take precedence over columns.
+func (p *compoundParser) useVariables(n sql.Node) (sql.Node, error) {
+	if p.scope == nil {
+		return n, nil
+	}
+
+	return plan.TransformUp(n, func(n sql.Node) (sql.Node, error) {
+		e, ok := n.(sql.Expressioner)
+		if !ok {
+			return n, nil
+		}
+
+		_, isSet := n.(*plan.Set)
+		exprs := e.Expressions()
+		newExprs := make([]sql.Expression, len(exprs))
+		changed := false
+		for i, e := range exprs {
+			var err error
+			// The left side of the assignments of other statements than SET, like UPDATE, are always columns
+			if sf, ok := e.(*expression.SetField); ok && !isSet {
+				var right sql.Expression
+				if right, err = p.useVariablesInExpression(sf.Right); err == nil {
+					newExprs[i] = expression.NewSetField(sf.Left, right)
+				}
+			} else {
+				newExprs[i], err = p.useVariablesInExpression(e)
+			}
+			if err != nil {
+				return nil, err
+			}
+			changed = changed || newExprs[i] != e
+		}
+		if !changed {
+			return n, nil
+		}
+		return e.WithExpressions(newExprs...)
+	})
+}
+
+func (p *compoundParser) useVariablesInExpression(e sql.Expression) (sql.Expression, error) {
+	if p.scope == nil {
+		return e, nil
+	}
+
+	return expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.UnresolvedColumn:
+			if e.Table() == "" {
+				if v := p.scope.variable(e.Name()); v != nil {
+					return v, nil
+				}
+			}
+		case *plan.Subquery:
+			query, err := p.useVariables(e.Query)
+			if err != nil {
+				return nil, err
+			}
+			return e.WithQuery(query), nil
+		}
+		return e, nil
+	})
+}
@@ -0,0 +1,34 @@
+package parse
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+)
+
+// Digest returns the digest of a query, which is the same for all the queries that only differ in their literals,
+// comments, whitespace and the case of their keywords, along with the normalized text of the query the digest is
+// the SHA-256 hash of. In the normalized text literals are replaced by ? and keywords are in upper case.
+func Digest(query string) (digest string, text string) {
+	var sb strings.Builder
+	for i, t := range tokenize(query) {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+
+		switch {
+		case isLiteral(t.typ):
+			sb.WriteByte('?')
+		case sqlparser.KeywordString(t.typ) != "":
+			sb.WriteString(strings.ToUpper(query[t.Start:t.End]))
+		default:
+			sb.WriteString(query[t.Start:t.End])
+		}
+	}
+
+	text = sb.String()
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:]), text
+}
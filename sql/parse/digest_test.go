@@ -0,0 +1,22 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	require := require.New(t)
+
+	digest, text := Digest("select a, 'x' from t where b = 1 and c in (2.5, NULL) -- comment")
+	require.Equal("SELECT a , ? FROM t WHERE b = ? AND c IN ( ? , ? )", text)
+	require.Len(digest, 64)
+
+	other, otherText := Digest("SELECT  a, \"y\"\nFROM t /* other */ WHERE b = 0x1F AND c IN (3, 4)")
+	require.Equal(text, otherText)
+	require.Equal(digest, other)
+
+	different, _ := Digest("SELECT a, 'x' FROM u WHERE b = 1 AND c IN (2.5, NULL)")
+	require.NotEqual(digest, different)
+}
@@ -0,0 +1,36 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	dumpDatabaseRegex = regexp.MustCompile(`(?is)^dump\s+databases?(?:\s+(.*))?$`)
+	dumpNameRegex     = regexp.MustCompile("^(?:`([^`]+)`|(\\w+))$")
+)
+
+// parseDumpDatabase parses the DUMP DATABASE statement, which dumps the databases of a list of names, or the current
+// database without one.
+func parseDumpDatabase(ctx *sql.Context, query string) (sql.Node, error) {
+	match := dumpDatabaseRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+
+	var databases []string
+	if list := strings.TrimSpace(match[1]); list != "" {
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			m := dumpNameRegex.FindStringSubmatch(name)
+			if m == nil {
+				return nil, errUnexpectedSyntax.New("database name", name)
+			}
+			databases = append(databases, m[1]+m[2])
+		}
+	}
+	return plan.NewDumpDatabase(databases), nil
+}
@@ -0,0 +1,34 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseDumpDatabase(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{"DUMP DATABASE", plan.NewDumpDatabase(nil)},
+		{"dump database mydb;", plan.NewDumpDatabase([]string{"mydb"})},
+		{"DUMP DATABASES a, `b c`", plan.NewDumpDatabase([]string{"a", "b c"})},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	_, err := Parse(sql.NewEmptyContext(), "DUMP DATABASE a b")
+	require.True(t, errUnexpectedSyntax.Is(err))
+	_, err = Parse(sql.NewEmptyContext(), "DUMP DATABASE a,")
+	require.True(t, errUnexpectedSyntax.Is(err))
+}
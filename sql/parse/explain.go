@@ -0,0 +1,27 @@
+package parse
+
+import (
+	"bufio"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseExplainTable parses EXPLAIN with a table, which is a synonym of DESCRIBE with a table that the vitess parser
+// doesn't support.
+func parseExplainTable(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var tables []sql.Node
+	err := parseFuncs{
+		expect("explain"),
+		skipSpaces,
+		readMaintainedTables(&tables),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewShowColumns(false, tables[0]), nil
+}
@@ -0,0 +1,105 @@
+package parse
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// StatementParser parses statements the bundled parser doesn't know about. It's given the text of the statement,
+// without comments and the trailing semicolon, and returns the node for it, or false if the statement isn't one of
+// the ones it handles.
+type StatementParser func(ctx *sql.Context, query string) (node sql.Node, ok bool, err error)
+
+type prefixedStatementParser struct {
+	prefix string
+	parse  StatementParser
+}
+
+var (
+	statementParsersMu sync.RWMutex
+	prefixedParsers    []prefixedStatementParser
+	fallbackParsers    []StatementParser
+)
+
+// RegisterStatementParser registers a parser for the statements that start with the words given, matched case
+// insensitively, such as "dolt checkout". These parsers are given the statements before the bundled parser, so they
+// can add custom syntax and also replace how existing statements are parsed. Parsers registered for the same
+// statement are tried in the order they were registered, and statements none of them handle go to the bundled parser.
+func RegisterStatementParser(prefix string, parse StatementParser) {
+	statementParsersMu.Lock()
+	defer statementParsersMu.Unlock()
+
+	prefix = strings.Join(strings.Fields(strings.ToLower(prefix)), " ")
+	prefixedParsers = append(prefixedParsers, prefixedStatementParser{prefix, parse})
+}
+
+// RegisterFallbackParser registers a parser for the statements the bundled parser rejects as invalid or
+// unsupported. Fallback parsers are tried in the order they were registered, and when none of them handles the
+// statement the error of the bundled parser is returned.
+func RegisterFallbackParser(parse StatementParser) {
+	statementParsersMu.Lock()
+	defer statementParsersMu.Unlock()
+
+	fallbackParsers = append(fallbackParsers, parse)
+}
+
+// parsePrefixed parses the query with the parsers registered for its first words, if any of them handles it.
+func parsePrefixed(ctx *sql.Context, query string) (sql.Node, bool, error) {
+	statementParsersMu.RLock()
+	parsers := prefixedParsers
+	statementParsersMu.RUnlock()
+
+	if len(parsers) == 0 {
+		return nil, false, nil
+	}
+
+	words := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	for _, p := range parsers {
+		if !hasWordPrefix(words, p.prefix) {
+			continue
+		}
+
+		node, ok, err := p.parse(ctx, query)
+		if err != nil || ok {
+			return node, ok, err
+		}
+	}
+
+	return nil, false, nil
+}
+
+// parseFallback parses the query the bundled parser rejected with the error given with the fallback parsers,
+// returning that error if none of them handles it.
+func parseFallback(ctx *sql.Context, query string, parseErr error) (sql.Node, error) {
+	statementParsersMu.RLock()
+	parsers := fallbackParsers
+	statementParsersMu.RUnlock()
+
+	for _, parse := range parsers {
+		node, ok, err := parse(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return node, nil
+		}
+	}
+
+	return nil, parseErr
+}
+
+// hasWordPrefix returns whether s starts with the words in prefix, and not just with a part of a longer word.
+func hasWordPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	if len(s) == len(prefix) || prefix == "" {
+		return true
+	}
+
+	next := rune(s[len(prefix)])
+	return !unicode.IsLetter(next) && !unicode.IsDigit(next) && next != '_'
+}
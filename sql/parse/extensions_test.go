@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestStatementParsers(t *testing.T) {
+	defer func() {
+		statementParsersMu.Lock()
+		prefixedParsers, fallbackParsers = nil, nil
+		statementParsersMu.Unlock()
+	}()
+
+	var queries []string
+	RegisterStatementParser("Custom  Command", func(ctx *sql.Context, query string) (sql.Node, bool, error) {
+		queries = append(queries, query)
+		return plan.NewShowTables(plan.NewDummyResolvedDB("custom"), false, nil), true, nil
+	})
+	RegisterStatementParser("select", func(ctx *sql.Context, query string) (sql.Node, bool, error) {
+		if query != "SELECT 'custom'" {
+			return nil, false, nil
+		}
+		return plan.NewShowTables(plan.NewDummyResolvedDB("select"), false, nil), true, nil
+	})
+	RegisterFallbackParser(func(ctx *sql.Context, query string) (sql.Node, bool, error) {
+		if query != "frobnicate everything" {
+			return nil, false, nil
+		}
+		return plan.NewShowTables(plan.NewDummyResolvedDB("fallback"), false, nil), true, nil
+	})
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"CUSTOM command foo; -- comment",
+			plan.NewShowTables(plan.NewDummyResolvedDB("custom"), false, nil),
+		},
+		{
+			"custom\tcommand(1)",
+			plan.NewShowTables(plan.NewDummyResolvedDB("custom"), false, nil),
+		},
+		{
+			"SELECT 'custom'",
+			plan.NewShowTables(plan.NewDummyResolvedDB("select"), false, nil),
+		},
+		{
+			"SELECT 'other'",
+			plan.NewProject(
+				[]sql.Expression{expression.NewLiteral("other", sql.LongText)},
+				plan.NewUnresolvedTable("dual", ""),
+			),
+		},
+		{
+			"frobnicate everything;",
+			plan.NewShowTables(plan.NewDummyResolvedDB("fallback"), false, nil),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	require.Equal(t, []string{"CUSTOM command foo", "custom\tcommand(1)"}, queries)
+
+	_, err := Parse(sql.NewEmptyContext(), "customcommand foo")
+	require.Error(t, err)
+	_, err = Parse(sql.NewEmptyContext(), "frobnicate nothing")
+	require.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package parse
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseCalcFoundRows parses a SELECT with SQL_CALC_FOUND_ROWS, which the vitess parser doesn't support, as the same
+// SELECT without it, whose limit calculates the found rows. The match given is the match of calcFoundRowsRegex in the
+// query.
+func parseCalcFoundRows(ctx *sql.Context, query string, match []int) (sql.Node, error) {
+	node, err := Parse(ctx, query[:match[6]]+query[match[7]:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a limit, the found rows are the rows the query returns, which are recorded anyway
+	if limit, ok := node.(*plan.Limit); ok {
+		return limit.WithCalcFoundRows(true), nil
+	}
+	return node, nil
+}
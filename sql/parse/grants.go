@@ -0,0 +1,40 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// accountNamePart is the user or the host of an account name, quoted or not.
+const accountNamePart = "('[^']*'|\"[^\"]*\"|`[^`]*`|[\\w$.%-]+)"
+
+var showGrantsStatementRegex = regexp.MustCompile(`(?is)^show\s+grants(\s+for\s+(current_user(\s*\(\s*\))?|` + accountNamePart + `(\s*@\s*` + accountNamePart + `)?))?$`)
+
+// parseShowGrants parses SHOW GRANTS, whose FOR clause the vitess parser ignores. USING isn't supported, since there
+// are no roles.
+func parseShowGrants(ctx *sql.Context, query string) (sql.Node, error) {
+	match := showGrantsStatementRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+
+	if match[1] == "" || match[3] != "" || strings.EqualFold(match[2], "current_user") {
+		return plan.NewShowGrants("", ""), nil
+	}
+
+	host := "%"
+	if match[6] != "" {
+		host = unquoteAccountNamePart(match[6])
+	}
+	return plan.NewShowGrants(unquoteAccountNamePart(match[4]), host), nil
+}
+
+func unquoteAccountNamePart(s string) string {
+	if len(s) >= 2 && strings.ContainsAny(s[:1], "'\"`") && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+package parse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// handlerReadModes are the modes of HANDLER READ that read from a position of the index.
+var handlerReadModes = []string{plan.HandlerReadFirst, plan.HandlerReadNext, plan.HandlerReadPrev, plan.HandlerReadLast}
+
+// handlerReadOperators are the modes of HANDLER READ that compare the keys of the index, longest first.
+var handlerReadOperators = []string{
+	plan.HandlerReadGreaterOrEqual,
+	plan.HandlerReadLessOrEqual,
+	plan.HandlerReadEqual,
+	plan.HandlerReadGreater,
+	plan.HandlerReadLess,
+}
+
+// parseHandler parses the HANDLER OPEN, READ and CLOSE statements, which the vitess parser doesn't support.
+func parseHandler(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var tables []sql.Node
+	var action string
+	err := parseFuncs{
+		expect("handler"),
+		skipSpaces,
+		readMaintainedTables(&tables),
+		readIdent(&action),
+		skipSpaces,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) != 1 {
+		return nil, errUnexpectedSyntax.New("OPEN, READ or CLOSE", ",")
+	}
+	table := tables[0].(*plan.UnresolvedTable)
+
+	switch action {
+	case "open":
+		return parseHandlerOpen(r, table)
+	case "close":
+		if err := checkEOF(r); err != nil {
+			return nil, err
+		}
+		return plan.NewHandlerClose(table.Name()), nil
+	case "read":
+		return parseHandlerRead(ctx, r, table.Name())
+	default:
+		return nil, errUnexpectedSyntax.New("OPEN, READ or CLOSE", action)
+	}
+}
+
+func parseHandlerOpen(r *bufio.Reader, table *plan.UnresolvedTable) (sql.Node, error) {
+	var alias string
+	if _, err := r.Peek(1); err == io.EOF {
+		return plan.NewHandlerOpen(table, alias), nil
+	}
+
+	if err := readQuotableIdent(&alias)(r); err != nil {
+		return nil, err
+	}
+	if alias == "as" {
+		if err := (parseFuncs{skipSpaces, readQuotableIdent(&alias)}).exec(r); err != nil {
+			return nil, err
+		}
+	}
+
+	err := parseFuncs{
+		skipSpaces,
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewHandlerOpen(table, alias), nil
+}
+
+func parseHandlerRead(ctx *sql.Context, r *bufio.Reader, name string) (sql.Node, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	quoted := b[0] == '`'
+
+	var index, mode string
+	if err := readQuotableIdent(&index)(r); err != nil {
+		return nil, err
+	}
+
+	// Without an index, rows are read in their natural order
+	if !quoted && (index == plan.HandlerReadFirst || index == plan.HandlerReadNext) {
+		mode, index = index, ""
+	}
+
+	var rest string
+	if err := (parseFuncs{skipSpaces, readRemaining(&rest)}).exec(r); err != nil {
+		return nil, err
+	}
+
+	var keys []sql.Expression
+	if mode == "" {
+		mode, keys, rest, err = parseHandlerReadMode(ctx, rest)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return handlerReadClauses(ctx, plan.NewHandlerRead(name, index, mode, keys), rest)
+}
+
+// parseHandlerReadMode parses the mode of a HANDLER READ of an index and its keys, returning the clauses after them.
+func parseHandlerReadMode(ctx *sql.Context, str string) (string, []sql.Expression, string, error) {
+	for _, op := range handlerReadOperators {
+		if strings.HasPrefix(str, op) {
+			keys, rest, err := parseHandlerReadKeys(ctx, strings.TrimSpace(str[len(op):]))
+			return op, keys, rest, err
+		}
+	}
+
+	var mode string
+	r := bufio.NewReader(strings.NewReader(str))
+	if err := readIdent(&mode)(r); err != nil {
+		return "", nil, "", err
+	}
+	if !stringContains(handlerReadModes, mode) {
+		return "", nil, "", errUnexpectedSyntax.New("one of: FIRST, NEXT, PREV, LAST, =, <=, >=, <, >", mode)
+	}
+
+	var rest string
+	if err := readRemaining(&rest)(r); err != nil {
+		return "", nil, "", err
+	}
+	return mode, nil, rest, nil
+}
+
+// parseHandlerReadKeys parses the parenthesized list of keys a HANDLER READ compares the keys of an index with,
+// returning the clauses after them.
+func parseHandlerReadKeys(ctx *sql.Context, str string) ([]sql.Expression, string, error) {
+	if !strings.HasPrefix(str, "(") {
+		return nil, "", errUnexpectedSyntax.New("(", str)
+	}
+
+	end := closingParen(str)
+	if end < 0 {
+		return nil, "", errUnexpectedSyntax.New(")", "EOF")
+	}
+
+	stmt, err := sqlparser.Parse("SELECT " + str[1:end])
+	if err != nil {
+		return nil, "", err
+	}
+	keys, err := selectExprsToExpressions(ctx, stmt.(*sqlparser.Select).SelectExprs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return keys, str[end+1:], nil
+}
+
+// closingParen returns the position of the parenthesis closing the one the string given starts with, skipping the
+// ones in quoted strings and identifiers, or -1 if it isn't closed.
+func closingParen(str string) int {
+	var depth int
+	var quote rune
+	for i, r := range str {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// handlerReadClauses returns the HANDLER READ given with its WHERE and LIMIT clauses, which are the same as the ones of
+// a SELECT. A HANDLER READ without a LIMIT reads a single row.
+func handlerReadClauses(ctx *sql.Context, read *plan.HandlerRead, clauses string) (sql.Node, error) {
+	stmt, err := sqlparser.Parse("SELECT * FROM t " + clauses)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := stmt.(*sqlparser.Select)
+	if !ok || len(s.GroupBy) > 0 || s.Having != nil || len(s.OrderBy) > 0 || s.Lock != "" {
+		return nil, ErrUnsupportedSyntax.New(clauses)
+	}
+
+	var node sql.Node = read
+	if s.Where != nil {
+		node, err = whereToFilter(ctx, s.Where, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.Limit == nil {
+		return plan.NewLimit(1, node), nil
+	}
+
+	if s.Limit.Offset != nil {
+		node, err = offsetToOffset(ctx, s.Limit.Offset, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return limitToLimit(ctx, s.Limit.Rowcount, node)
+}
@@ -0,0 +1,140 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// warnHintSyntax is the code of the warnings of hints with syntax errors, which are ignored.
+const warnHintSyntax = 1064
+
+// parseHints returns the optimizer hints of the /*+ ... */ comments given, the comments after the SELECT keyword of a
+// query block. As in MySQL, hints with syntax errors or unknown names are ignored with a warning, together with the
+// hints after them in the same comment.
+func parseHints(ctx *sql.Context, comments sqlparser.Comments) sql.Hints {
+	var hints sql.Hints
+	for _, c := range comments {
+		comment := string(c)
+		if !strings.HasPrefix(comment, "/*+") || !strings.HasSuffix(comment, "*/") {
+			continue
+		}
+
+		p := &hintParser{text: comment[3 : len(comment)-2]}
+		for {
+			hint, ok, err := p.next()
+			if err != "" {
+				ctx.Warn(warnHintSyntax, "Optimizer hint syntax error near '%s'", strings.TrimSpace(err))
+				break
+			}
+			if !ok {
+				break
+			}
+			hints = append(hints, hint)
+		}
+	}
+	return hints
+}
+
+// hintParser parses the hints of a hint comment one at a time.
+type hintParser struct {
+	text string
+	pos  int
+}
+
+// next returns the next hint of the comment, or false once there are no more. If the next hint has a syntax error or
+// an unknown name, it returns the text from the error on.
+func (p *hintParser) next() (sql.Hint, bool, string) {
+	p.skipSpaces()
+	if p.pos == len(p.text) {
+		return sql.Hint{}, false, ""
+	}
+
+	start := p.pos
+	name := p.identifier()
+	def, ok := sql.LookupHint(name)
+	if !ok {
+		return sql.Hint{}, false, p.text[start:]
+	}
+
+	p.skipSpaces()
+	if !p.consume('(') {
+		return sql.Hint{}, false, p.text[start:]
+	}
+
+	hint := sql.Hint{Name: def.Name}
+	for {
+		p.skipSpaces()
+		if p.consume(')') {
+			break
+		}
+		// Indexes are separated from their table by a space, and from each other by commas
+		if len(hint.Args) > 0 && !p.consume(',') && !(def.Arguments == sql.HintTableIndexes && len(hint.Args) == 1) {
+			return sql.Hint{}, false, p.text[start:]
+		}
+		p.skipSpaces()
+
+		arg := p.identifier()
+		if arg == "" {
+			return sql.Hint{}, false, p.text[start:]
+		}
+		hint.Args = append(hint.Args, strings.ToLower(arg))
+	}
+
+	switch def.Arguments {
+	case sql.HintTableIndexes:
+		if len(hint.Args) == 0 {
+			return sql.Hint{}, false, p.text[start:]
+		}
+	case sql.HintNumber:
+		if len(hint.Args) != 1 {
+			return sql.Hint{}, false, p.text[start:]
+		}
+		if _, err := strconv.ParseUint(hint.Args[0], 10, 64); err != nil {
+			return sql.Hint{}, false, p.text[start:]
+		}
+	}
+	return hint, true, ""
+}
+
+// identifier returns the identifier or number at the position of the parser, which may be quoted with backticks, or
+// an empty string if there is none.
+func (p *hintParser) identifier() string {
+	if p.consume('`') {
+		end := strings.IndexByte(p.text[p.pos:], '`')
+		if end < 0 {
+			return ""
+		}
+		id := p.text[p.pos : p.pos+end]
+		p.pos += end + 1
+		return id
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) {
+		r := rune(p.text[p.pos])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *hintParser) consume(c byte) bool {
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *hintParser) skipSpaces() {
+	for p.pos < len(p.text) && unicode.IsSpace(rune(p.text[p.pos])) {
+		p.pos++
+	}
+}
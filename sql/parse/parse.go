@@ -2,6 +2,7 @@ package parse
 
 import (
 	"bufio"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -44,6 +45,12 @@ var (
 	ErrInvalidAutoIncCols = errors.NewKind("there can be only one auto_increment column and it must be defined as a key")
 
 	ErrUnknownConstraintDefinition = errors.NewKind("unknown constraint definition: %s, %T")
+
+	// ErrGroupByColumnIndex is returned when a GROUP BY index doesn't refer to an expression of the select.
+	ErrGroupByColumnIndex = errors.NewKind("unknown column %d in group by clause")
+
+	// ErrGroupByAggregate is returned when a GROUP BY index refers to an aggregation.
+	ErrGroupByAggregate = errors.NewKind("can't group on '%s'")
 )
 
 var (
@@ -53,9 +60,25 @@ var (
 	unlockTablesRegex    = regexp.MustCompile(`^unlock\s+tables$`)
 	lockTablesRegex      = regexp.MustCompile(`^lock\s+tables\s`)
 	setRegex             = regexp.MustCompile(`^set\s+`)
+	checksumTableRegex   = regexp.MustCompile(`^checksum\s+table\s`)
+	checkTableRegex      = regexp.MustCompile(`^check\s+table\s`)
+	optimizeTableRegex   = regexp.MustCompile(`^optimize\s+((no_write_to_binlog|local)\s+)?tables?\s`)
+	analyzeTableRegex    = regexp.MustCompile(`^analyze\s+((no_write_to_binlog|local)\s+)?tables?\s`)
+	calcFoundRowsRegex   = regexp.MustCompile(`^select\s+((all|distinct|distinctrow|high_priority|straight_join|sql_small_result|sql_big_result|sql_buffer_result|sql_cache|sql_no_cache)\s+)*(sql_calc_found_rows)\s`)
+	explainTableRegex    = regexp.MustCompile("^explain\\s+(`[^`]+`|\\w+)(\\.(`[^`]+`|\\w+))?$")
+	handlerRegex         = regexp.MustCompile(`^handler\s`)
+	signalRegex          = regexp.MustCompile(`^(signal|resignal)(\s|$)`)
+	getDiagnosticsRegex  = regexp.MustCompile(`^get\s+((current|stacked)\s+)?diagnostics\s`)
+	resourceGroupRegex   = regexp.MustCompile(`^(create|alter|drop|set)\s+resource\s+group\s`)
+	dumpRegex            = regexp.MustCompile(`^dump\s+databases?(\s|$)`)
+	xaRegex              = regexp.MustCompile(`^xa\s`)
+	showGrantsRegex      = regexp.MustCompile(`^show\s+grants(\s|$)`)
+	alterUserRegex       = regexp.MustCompile(`^alter\s+user\s`)
+	planBaselineRegex    = regexp.MustCompile(`^(pin|show|purge)\s+plan\s+baselines?(\s|$)`)
+	withRegex            = regexp.MustCompile(`^((explain|describe|desc)(\s+format\s*=\s*(\w+))?\s+)?with\s`)
 )
 
-var describeSupportedFormats = []string{"tree"}
+var describeSupportedFormats = []string{"tree", plan.DescribeFormatCost}
 
 // These constants aren't exported from vitess for some reason. This could be removed if we changed this.
 const (
@@ -108,6 +131,11 @@ func Parse(ctx *sql.Context, query string) (sql.Node, error) {
 		return plan.Nothing, nil
 	}
 
+	if node, ok, err := parsePrefixed(ctx, s); err != nil || ok {
+		return node, err
+	}
+
+	query = s
 	lowerQuery := strings.ToLower(s)
 
 	switch true {
@@ -121,16 +149,74 @@ func Parse(ctx *sql.Context, query string) (sql.Node, error) {
 		return plan.NewUnlockTables(), nil
 	case lockTablesRegex.MatchString(lowerQuery):
 		return parseLockTables(ctx, s)
+	case checksumTableRegex.MatchString(lowerQuery):
+		return parseChecksumTable(ctx, s)
+	case checkTableRegex.MatchString(lowerQuery):
+		return parseCheckTable(ctx, s)
+	case optimizeTableRegex.MatchString(lowerQuery):
+		return parseOptimizeTable(ctx, s)
+	case analyzeTableRegex.MatchString(lowerQuery):
+		return parseAnalyzeTable(ctx, s)
+	case explainTableRegex.MatchString(lowerQuery):
+		return parseExplainTable(ctx, s)
+	case handlerRegex.MatchString(lowerQuery):
+		return parseHandler(ctx, s)
+	case signalRegex.MatchString(lowerQuery):
+		return parseSignal(ctx, s)
+	case getDiagnosticsRegex.MatchString(lowerQuery):
+		return parseGetDiagnostics(ctx, s)
+	case resourceGroupRegex.MatchString(lowerQuery):
+		return parseResourceGroup(ctx, s)
+	case dumpRegex.MatchString(lowerQuery):
+		return parseDumpDatabase(ctx, s)
+	case xaRegex.MatchString(lowerQuery):
+		return parseXA(ctx, s)
+	case showGrantsRegex.MatchString(lowerQuery):
+		return parseShowGrants(ctx, s)
+	case alterUserRegex.MatchString(lowerQuery):
+		return parseAlterUser(ctx, s)
+	case planBaselineRegex.MatchString(lowerQuery):
+		return parsePlanBaseline(ctx, s)
+	case withRegex.MatchString(lowerQuery):
+		return parseWith(ctx, s, withRegex.FindStringSubmatchIndex(lowerQuery))
+	case calcFoundRowsRegex.MatchString(lowerQuery):
+		return parseCalcFoundRows(ctx, s, calcFoundRowsRegex.FindStringSubmatchIndex(lowerQuery))
 	case setRegex.MatchString(lowerQuery):
 		s = fixSetQuery(s)
 	}
 
+	if strings.Contains(lowerQuery, "over") {
+		s = rewriteWindowFunctions(s)
+	}
+	if strings.Contains(lowerQuery, "from") {
+		s = rewriteTableFunctions(s)
+	}
+	if alterTableRegex.MatchString(lowerQuery) && strings.Contains(lowerQuery, "check") {
+		if node, ok, err := parseAlterCheck(s); err != nil || ok {
+			return node, err
+		}
+	}
+	var checks []checkDefinition
+	if createTableRegex.MatchString(lowerQuery) && strings.Contains(lowerQuery, "check") {
+		s, checks = rewriteCheckConstraints(s)
+	}
+
 	stmt, err := sqlparser.Parse(s)
 	if err != nil {
-		return nil, err
+		if createTriggerRegex.MatchString(query) {
+			return parseCompoundTrigger(ctx, query, err)
+		}
+		return parseFallback(ctx, query, err)
 	}
 
-	return convert(ctx, stmt, s)
+	node, err := convert(ctx, stmt, s)
+	if ErrUnsupportedSyntax.Is(err) {
+		return parseFallback(ctx, query, err)
+	}
+	if err == nil && len(checks) > 0 {
+		return convertCheckConstraints(ctx, node, checks)
+	}
+	return node, err
 }
 
 func convert(ctx *sql.Context, stmt sqlparser.Statement, query string) (sql.Node, error) {
@@ -165,6 +251,8 @@ func convert(ctx *sql.Context, stmt sqlparser.Statement, query string) (sql.Node
 		return convertSet(ctx, n)
 	case *sqlparser.Use:
 		return convertUse(n)
+	case *sqlparser.Begin:
+		return plan.NewStartTransaction(), nil
 	case *sqlparser.Commit:
 		return plan.NewCommit(), nil
 	case *sqlparser.Rollback:
@@ -207,18 +295,27 @@ func convertExplain(ctx *sql.Context, n *sqlparser.Explain) (sql.Node, error) {
 		return nil, err
 	}
 
-	explainFmt := sqlparser.TreeStr
-	switch strings.ToLower(n.ExplainFormat) {
+	explainFmt, err := describeFormat(n.ExplainFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewDescribeQuery(explainFmt, child), nil
+}
+
+// describeFormat returns the format of a DESCRIBE statement given its FORMAT option, which may be empty.
+func describeFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
 	case "", sqlparser.TreeStr:
-	// tree format, do nothing
+		return sqlparser.TreeStr, nil
+	case plan.DescribeFormatCost:
+		return plan.DescribeFormatCost, nil
 	default:
-		return nil, errInvalidDescribeFormat.New(
-			n.ExplainFormat,
+		return "", errInvalidDescribeFormat.New(
+			format,
 			strings.Join(describeSupportedFormats, ", "),
 		)
 	}
-
-	return plan.NewDescribeQuery(explainFmt, child), nil
 }
 
 func convertUse(n *sqlparser.Use) (sql.Node, error) {
@@ -231,35 +328,54 @@ func convertSet(ctx *sql.Context, n *sqlparser.Set) (sql.Node, error) {
 		return nil, ErrUnsupportedFeature.New("SET global variables")
 	}
 
-	// Special case: SET NAMES expands to 3 different system variables. The parser doesn't yet support the optional
-	// collation string, which is fine since our support for it is mostly fake anyway.
-	// See https://dev.mysql.com/doc/refman/8.0/en/set-names.html
+	// Special case: SET NAMES expands to 3 different system variables, and the collation of the connection. The parser
+	// doesn't yet support the optional collation string, so the collation is always the default one of the
+	// character set. See https://dev.mysql.com/doc/refman/8.0/en/set-names.html
 	if isSetNames(n.Exprs) {
+		exprs := sqlparser.SetExprs{
+			newSetExpr("character_set_client", n.Exprs[0].Expr),
+			newSetExpr("character_set_connection", n.Exprs[0].Expr),
+			newSetExpr("character_set_results", n.Exprs[0].Expr),
+		}
+		if collation, ok := defaultCollationOf(n.Exprs[0].Expr); ok {
+			exprs = append(exprs, newSetExpr("collation_connection", sqlparser.NewStrVal([]byte(collation.String()))))
+		}
+		return convertSet(ctx, &sqlparser.Set{Exprs: exprs})
+	}
+
+	// Special case: SET CHARACTER SET sets the character sets of the client and the results, and sets the ones of the
+	// connection to the ones of the database.
+	if isSetCharset(n.Exprs) {
 		return convertSet(ctx, &sqlparser.Set{
 			Exprs: sqlparser.SetExprs{
-				&sqlparser.SetExpr{
-					Name: sqlparser.NewColName("character_set_client"),
-					Expr: n.Exprs[0].Expr,
-				},
-				&sqlparser.SetExpr{
-					Name: sqlparser.NewColName("character_set_connection"),
-					Expr: n.Exprs[0].Expr,
-				},
-				&sqlparser.SetExpr{
-					Name: sqlparser.NewColName("character_set_results"),
-					Expr: n.Exprs[0].Expr,
-				},
-				// TODO: this should also set the collation_connection to the default collation for the character set named
+				newSetExpr("character_set_client", n.Exprs[0].Expr),
+				newSetExpr("character_set_results", n.Exprs[0].Expr),
+				newSetExpr("character_set_connection", sqlparser.NewColName("@@character_set_database")),
+				newSetExpr("collation_connection", sqlparser.NewColName("@@collation_database")),
 			},
 		})
 	}
 
-	exprs, err := setExprsToExpressions(ctx, n.Exprs)
+	// Special case: SET TRANSACTION sets the isolation level and the access mode of transactions, which are kept in
+	// the transaction_isolation and transaction_read_only variables of the session.
+	exprs := make(sqlparser.SetExprs, len(n.Exprs))
+	for i, e := range n.Exprs {
+		exprs[i] = e
+		if strings.ToLower(e.Name.Name.String()) == "transaction" && e.Name.Qualifier.IsEmpty() {
+			var err error
+			exprs[i], err = transactionCharacteristicToSetExpr(e.Expr)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	setExprs, err := setExprsToExpressions(ctx, exprs)
 	if err != nil {
 		return nil, err
 	}
 
-	return plan.NewSet(exprs), nil
+	return plan.NewSet(setExprs), nil
 }
 
 func isSetNames(exprs sqlparser.SetExprs) bool {
@@ -270,6 +386,65 @@ func isSetNames(exprs sqlparser.SetExprs) bool {
 	return strings.ToLower(exprs[0].Name.String()) == "names"
 }
 
+func isSetCharset(exprs sqlparser.SetExprs) bool {
+	if len(exprs) != 1 {
+		return false
+	}
+
+	return strings.ToLower(exprs[0].Name.String()) == "charset"
+}
+
+func newSetExpr(name string, expr sqlparser.Expr) *sqlparser.SetExpr {
+	return &sqlparser.SetExpr{Name: sqlparser.NewColName(name), Expr: expr}
+}
+
+// defaultCollationOf returns the default collation of the character set named by the expression given, if it's a
+// known one.
+func defaultCollationOf(expr sqlparser.Expr) (sql.Collation, bool) {
+	val, ok := expr.(*sqlparser.SQLVal)
+	if !ok || val.Type != sqlparser.StrVal {
+		return "", false
+	}
+
+	charset, err := sql.ParseCharacterSet(string(val.Val))
+	if err != nil {
+		return "", false
+	}
+	return charset.DefaultCollation(), true
+}
+
+// transactionIsolationLevels are the isolation levels of SET TRANSACTION ISOLATION LEVEL, by the values of the
+// transaction_isolation variable.
+var transactionIsolationLevels = map[string]string{
+	"read uncommitted": "READ-UNCOMMITTED",
+	"read committed":   "READ-COMMITTED",
+	"repeatable read":  "REPEATABLE-READ",
+	"serializable":     "SERIALIZABLE",
+}
+
+// transactionCharacteristicToSetExpr returns the assignment of the system variable a characteristic of SET
+// TRANSACTION sets, which the parser gives as a string, like "isolation level read committed" or "read only".
+func transactionCharacteristicToSetExpr(expr sqlparser.Expr) (*sqlparser.SetExpr, error) {
+	val, ok := expr.(*sqlparser.SQLVal)
+	if !ok {
+		return nil, ErrUnsupportedSyntax.New(sqlparser.String(expr))
+	}
+
+	characteristic := strings.ToLower(string(val.Val))
+	switch characteristic {
+	case "read only":
+		return newSetExpr("transaction_read_only", sqlparser.NewIntVal([]byte("1"))), nil
+	case "read write":
+		return newSetExpr("transaction_read_only", sqlparser.NewIntVal([]byte("0"))), nil
+	}
+
+	level, ok := transactionIsolationLevels[strings.TrimPrefix(characteristic, "isolation level ")]
+	if !ok || !strings.HasPrefix(characteristic, "isolation level ") {
+		return nil, ErrUnsupportedSyntax.New(characteristic)
+	}
+	return newSetExpr("transaction_isolation", sqlparser.NewStrVal([]byte(level))), nil
+}
+
 func convertShow(ctx *sql.Context, s *sqlparser.Show, query string) (sql.Node, error) {
 	showType := strings.ToLower(s.Type)
 	switch showType {
@@ -448,7 +623,9 @@ func convertSelect(ctx *sql.Context, s *sqlparser.Select) (sql.Node, error) {
 		}
 	}
 
-	node, err = selectToProjectOrGroupBy(ctx, s.SelectExprs, s.GroupBy, node)
+	// Aggregations in the HAVING or ORDER BY clauses make the query aggregated even without a GROUP BY
+	aggregated := containsAggregateFunc(s.Having) || containsAggregateFunc(s.OrderBy)
+	node, err = selectToProjectOrGroupBy(ctx, s.SelectExprs, s.GroupBy, aggregated, node)
 	if err != nil {
 		return nil, err
 	}
@@ -489,6 +666,10 @@ func convertSelect(ctx *sql.Context, s *sqlparser.Select) (sql.Node, error) {
 		node = plan.NewLimit(limit, node)
 	}
 
+	if hints := parseHints(ctx, s.Comments); len(hints) > 0 {
+		node = plan.NewQueryHints(hints, node)
+	}
+
 	return node, nil
 }
 
@@ -514,6 +695,8 @@ func convertDDL(ctx *sql.Context, query string, c *sqlparser.DDL) (sql.Node, err
 		return convertAlterTable(ctx, c)
 	case sqlparser.RenameStr:
 		return convertRenameTable(ctx, c)
+	case sqlparser.TruncateStr:
+		return plan.NewTruncate(plan.NewUnresolvedTable(c.Table.Name.String(), c.Table.Qualifier.String())), nil
 	default:
 		return nil, ErrUnsupportedSyntax.New(sqlparser.String(c))
 	}
@@ -540,7 +723,7 @@ func convertCreateTrigger(ctx *sql.Context, query string, c *sqlparser.DDL) (sql
 		return nil, err
 	}
 
-	return plan.NewCreateTrigger(c.TriggerSpec.Name, c.TriggerSpec.Time, c.TriggerSpec.Event, triggerOrder, tableNameToUnresolvedTable(c.Table), body, query, bodyStr), nil
+	return plan.NewCreateTrigger(c.TriggerSpec.Name, c.TriggerSpec.Time, c.TriggerSpec.Event, triggerOrder, tableNameToUnresolvedTable(c.Table), body, restoreRewrites(query), restoreRewrites(bodyStr)), nil
 }
 
 func convertRenameTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
@@ -548,6 +731,11 @@ func convertRenameTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error)
 		panic("Expected from tables and to tables of equal length")
 	}
 
+	db, err := tablesDatabase(append(ddl.FromTables, ddl.ToTables...))
+	if err != nil {
+		return nil, err
+	}
+
 	var fromTables, toTables []string
 	for _, table := range ddl.FromTables {
 		fromTables = append(fromTables, table.Name.String())
@@ -556,7 +744,7 @@ func convertRenameTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error)
 		toTables = append(toTables, table.Name.String())
 	}
 
-	return plan.NewRenameTable(sql.UnresolvedDatabase(""), fromTables, toTables), nil
+	return plan.NewRenameTable(sql.UnresolvedDatabase(db), fromTables, toTables), nil
 }
 
 func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
@@ -585,11 +773,8 @@ func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 			case *sql.ForeignKeyConstraint:
 				return plan.NewAlterDropForeignKey(table, c), nil
 			case namedConstraint:
-				// For simple named constraint drops, fill in a partial foreign key constraint. This will need to be changed if
-				// we ever support other kinds of constraints than foreign keys (e.g. CHECK)
-				return plan.NewAlterDropForeignKey(table, &sql.ForeignKeyConstraint{
-					Name: c.name,
-				}), nil
+				// Constraints dropped by name are check constraints or foreign keys, whichever the table has
+				return plan.NewAlterDropConstraint(table, c.name), nil
 			default:
 				return nil, ErrUnsupportedFeature.New(sqlparser.String(ddl))
 			}
@@ -601,17 +786,17 @@ func convertAlterTable(ctx *sql.Context, ddl *sqlparser.DDL) (sql.Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		return plan.NewAddColumn(sql.UnresolvedDatabase(""), ddl.Table.Name.String(), sch[0], columnOrderToColumnOrder(ddl.ColumnOrder)), nil
+		return plan.NewAddColumn(sql.UnresolvedDatabase(ddl.Table.Qualifier.String()), ddl.Table.Name.String(), sch[0], columnOrderToColumnOrder(ddl.ColumnOrder)), nil
 	case sqlparser.DropStr:
-		return plan.NewDropColumn(sql.UnresolvedDatabase(""), ddl.Table.Name.String(), ddl.Column.String()), nil
+		return plan.NewDropColumn(sql.UnresolvedDatabase(ddl.Table.Qualifier.String()), ddl.Table.Name.String(), ddl.Column.String()), nil
 	case sqlparser.RenameStr:
-		return plan.NewRenameColumn(sql.UnresolvedDatabase(""), ddl.Table.Name.String(), ddl.Column.String(), ddl.ToColumn.String()), nil
+		return plan.NewRenameColumn(sql.UnresolvedDatabase(ddl.Table.Qualifier.String()), ddl.Table.Name.String(), ddl.Column.String(), ddl.ToColumn.String()), nil
 	case sqlparser.ModifyStr, sqlparser.ChangeStr:
 		sch, err := TableSpecToSchema(nil, ddl.TableSpec)
 		if err != nil {
 			return nil, err
 		}
-		return plan.NewModifyColumn(sql.UnresolvedDatabase(""), ddl.Table.Name.String(), ddl.Column.String(), sch[0], columnOrderToColumnOrder(ddl.ColumnOrder)), nil
+		return plan.NewModifyColumn(sql.UnresolvedDatabase(ddl.Table.Qualifier.String()), ddl.Table.Name.String(), ddl.Column.String(), sch[0], columnOrderToColumnOrder(ddl.ColumnOrder)), nil
 	default:
 		return nil, ErrUnsupportedFeature.New(sqlparser.String(ddl))
 	}
@@ -717,17 +902,35 @@ func columnOrderToColumnOrder(order *sqlparser.ColumnOrder) *sql.ColumnOrder {
 }
 
 func convertDropTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
+	db, err := tablesDatabase(c.FromTables)
+	if err != nil {
+		return nil, err
+	}
+
 	tableNames := make([]string, len(c.FromTables))
 	for i, t := range c.FromTables {
 		tableNames[i] = t.Name.String()
 	}
-	return plan.NewDropTable(sql.UnresolvedDatabase(""), c.IfExists, tableNames...), nil
+	return plan.NewDropTable(sql.UnresolvedDatabase(db), c.IfExists, tableNames...), nil
+}
+
+// tablesDatabase returns the database qualifying the names of the tables given, which must all be of the same
+// database. Tables without a qualifier are of the current database.
+func tablesDatabase(tables sqlparser.TableNames) (string, error) {
+	var db string
+	for i, t := range tables {
+		if i > 0 && !strings.EqualFold(t.Qualifier.String(), db) {
+			return "", ErrUnsupportedFeature.New("tables of different databases in the same statement")
+		}
+		db = t.Qualifier.String()
+	}
+	return db, nil
 }
 
 func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 	if c.OptLike != nil {
 		return plan.NewCreateTableLike(
-			sql.UnresolvedDatabase(""),
+			sql.UnresolvedDatabase(c.Table.Qualifier.String()),
 			c.Table.Name.String(),
 			plan.NewUnresolvedTable(c.OptLike.LikeTable.Name.String(), c.OptLike.LikeTable.Qualifier.String()),
 			c.IfNotExists,
@@ -817,7 +1020,7 @@ func convertCreateTable(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 	}
 
 	return plan.NewCreateTable(
-		sql.UnresolvedDatabase(""), c.Table.Name.String(), schema, c.IfNotExists, idxDefs, fkDefs), nil
+		sql.UnresolvedDatabase(c.Table.Qualifier.String()), c.Table.Name.String(), schema, c.IfNotExists, idxDefs, fkDefs), nil
 }
 
 type namedConstraint struct {
@@ -876,17 +1079,17 @@ func convertCreateView(ctx *sql.Context, query string, c *sqlparser.DDL) (sql.No
 		return nil, err
 	}
 
-	selectStr := query[c.SubStatementPositionStart:c.SubStatementPositionEnd]
+	selectStr := restoreRewrites(query[c.SubStatementPositionStart:c.SubStatementPositionEnd])
 	queryAlias := plan.NewSubqueryAlias(c.View.Name.String(), selectStr, queryNode)
 
 	return plan.NewCreateView(
-		sql.UnresolvedDatabase(""), c.View.Name.String(), []string{}, queryAlias, c.OrReplace), nil
+		sql.UnresolvedDatabase(c.View.Qualifier.String()), c.View.Name.String(), []string{}, queryAlias, c.OrReplace), nil
 }
 
 func convertDropView(ctx *sql.Context, c *sqlparser.DDL) (sql.Node, error) {
 	plans := make([]sql.Node, len(c.FromViews))
 	for i, v := range c.FromViews {
-		plans[i] = plan.NewSingleDropView(sql.UnresolvedDatabase(""), v.Name.String())
+		plans[i] = plan.NewSingleDropView(sql.UnresolvedDatabase(v.Qualifier.String()), v.Name.String())
 	}
 	return plan.NewDropView(plans, c.IfExists), nil
 }
@@ -961,6 +1164,18 @@ func convertUpdate(ctx *sql.Context, d *sqlparser.Update) (sql.Node, error) {
 		return nil, err
 	}
 
+	// Tables read AS OF a revision are the history of the table, which can't be changed
+	var asOf bool
+	plan.Inspect(node, func(n sql.Node) bool {
+		if t, ok := n.(*plan.UnresolvedTable); ok && t.AsOf != nil {
+			asOf = true
+		}
+		return !asOf
+	})
+	if asOf {
+		return nil, sql.ErrIncompatibleAsOf.New("tables read AS OF a revision can't be updated")
+	}
+
 	updateExprs, err := setExprsToExpressions(ctx, d.Exprs)
 	if err != nil {
 		return nil, err
@@ -1111,6 +1326,12 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 		}
 	}
 
+	// ON UPDATE is only recorded to be shown, the values of the column aren't updated
+	var extra string
+	if cd.Type.OnUpdate != nil {
+		extra = "on update " + onUpdateString(cd.Type.OnUpdate)
+	}
+
 	return &sql.Column{
 		Nullable:      !isPkey && !bool(cd.Type.NotNull),
 		Type:          internalTyp,
@@ -1119,9 +1340,39 @@ func columnDefinitionToColumn(ctx *sql.Context, cd *sqlparser.ColumnDefinition,
 		Default:       defaultVal,
 		AutoIncrement: bool(cd.Type.Autoincrement),
 		Comment:       comment,
+		Extra:         extra,
 	}, nil
 }
 
+// onUpdateString returns the ON UPDATE expression of a column definition as MySQL shows it, which is
+// CURRENT_TIMESTAMP for it and its synonyms.
+func onUpdateString(expr sqlparser.Expr) string {
+	var name string
+	var fsp sqlparser.Expr
+	switch e := expr.(type) {
+	case *sqlparser.FuncExpr:
+		if len(e.Exprs) != 0 {
+			return sqlparser.String(expr)
+		}
+		name = e.Name.Lowered()
+	case *sqlparser.CurTimeFuncExpr:
+		name = e.Name.Lowered()
+		fsp = e.Fsp
+	default:
+		return sqlparser.String(expr)
+	}
+
+	switch name {
+	case "current_timestamp", "now", "localtime", "localtimestamp":
+		if fsp != nil {
+			return fmt.Sprintf("CURRENT_TIMESTAMP(%s)", sqlparser.String(fsp))
+		}
+		return "CURRENT_TIMESTAMP"
+	default:
+		return sqlparser.String(expr)
+	}
+}
+
 func columnsToStrings(cols sqlparser.Columns) []string {
 	res := make([]string, len(cols))
 	for i, c := range cols {
@@ -1203,6 +1454,23 @@ func tableExprToTable(
 		// TODO: Add support for qualifier.
 		switch e := t.Expr.(type) {
 		case sqlparser.TableName:
+			if isTableFunctionName(e.Name.String()) {
+				if t.AsOf != nil || !e.Qualifier.IsEmpty() {
+					return nil, ErrUnsupportedSyntax.New(sqlparser.String(te))
+				}
+
+				node, err := tableFunctionToUnresolvedTableFunction(ctx, e.Name.String())
+				if err != nil {
+					return nil, err
+				}
+
+				if !t.As.IsEmpty() {
+					return plan.NewTableAlias(t.As.String(), node), nil
+				}
+
+				return node, nil
+			}
+
 			var node *plan.UnresolvedTable
 			if t.AsOf != nil {
 				asOfExpr, err := exprToExpression(ctx, t.AsOf.Time)
@@ -1286,6 +1554,15 @@ func whereToFilter(ctx *sql.Context, w *sqlparser.Where, child sql.Node) (*plan.
 }
 
 func orderByToSort(ctx *sql.Context, ob sqlparser.OrderBy, child sql.Node) (*plan.Sort, error) {
+	sortFields, err := orderByToSortFields(ctx, ob)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewSort(sortFields, child), nil
+}
+
+func orderByToSortFields(ctx *sql.Context, ob sqlparser.OrderBy) ([]plan.SortField, error) {
 	var sortFields []plan.SortField
 	for _, o := range ob {
 		e, err := exprToExpression(ctx, o.Expr)
@@ -1307,7 +1584,7 @@ func orderByToSort(ctx *sql.Context, ob sqlparser.OrderBy, child sql.Node) (*pla
 		sortFields = append(sortFields, sf)
 	}
 
-	return plan.NewSort(sortFields, child), nil
+	return sortFields, nil
 }
 
 func limitToLimit(
@@ -1399,6 +1676,9 @@ func isAggregate(e sql.Expression) bool {
 			isAgg = isAgg || e.IsAggregate
 		case *aggregation.CountDistinct:
 			isAgg = true
+		case *plan.WindowFunction:
+			// Aggregations computed over windows don't group the rows
+			return false
 		}
 
 		return true
@@ -1406,10 +1686,31 @@ func isAggregate(e sql.Expression) bool {
 	return isAgg
 }
 
+// containsAggregateFunc returns whether the node given calls an aggregate function outside of its subqueries and
+// window functions.
+func containsAggregateFunc(node sqlparser.SQLNode) bool {
+	var found bool
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			return false, nil
+		case *sqlparser.CollateExpr:
+			if isWindowCollation(node.Charset) {
+				return false, nil
+			}
+		case *sqlparser.FuncExpr:
+			found = found || isAggregateFunc(node)
+		}
+		return !found, nil
+	}, node)
+	return found
+}
+
 func selectToProjectOrGroupBy(
 	ctx *sql.Context,
 	se sqlparser.SelectExprs,
 	g sqlparser.GroupBy,
+	aggregated bool,
 	child sql.Node,
 ) (sql.Node, error) {
 	selectExprs, err := selectExprsToExpressions(ctx, se)
@@ -1417,7 +1718,7 @@ func selectToProjectOrGroupBy(
 		return nil, err
 	}
 
-	isAgg := len(g) > 0
+	isAgg := aggregated || len(g) > 0
 	if !isAgg {
 		for _, e := range selectExprs {
 			if isAggregate(e) {
@@ -1428,6 +1729,10 @@ func selectToProjectOrGroupBy(
 	}
 
 	if isAgg {
+		if plan.FindWindowFunction(selectExprs...) != nil {
+			return nil, ErrUnsupportedFeature.New("window functions in aggregated queries")
+		}
+
 		groupingExprs, err := groupByToExpressions(ctx, g)
 		if err != nil {
 			return nil, err
@@ -1438,10 +1743,19 @@ func selectToProjectOrGroupBy(
 			// if GROUP BY index
 			if l, ok := ge.(*expression.Literal); ok && sql.IsNumber(l.Type()) {
 				if i64, err := sql.Int64.Convert(l.Value()); err == nil {
-					if idx, ok := i64.(int64); ok && idx > 0 && idx <= agglen {
+					if idx, ok := i64.(int64); ok {
+						if idx <= 0 || idx > agglen {
+							return nil, ErrGroupByColumnIndex.New(idx)
+						}
+
+						// An index refers to the expression of the select, even when
+						// its alias is also the name of a column.
 						aggexpr := selectExprs[idx-1]
 						if alias, ok := aggexpr.(*expression.Alias); ok {
-							aggexpr = expression.NewUnresolvedColumn(alias.Name())
+							aggexpr = alias.Child
+						}
+						if isAggregate(aggexpr) {
+							return nil, ErrGroupByAggregate.New(aggexpr)
 						}
 						groupingExprs[i] = aggexpr
 					}
@@ -1574,6 +1888,12 @@ func exprToExpression(ctx *sql.Context, e sqlparser.Expr) (sql.Expression, error
 			), nil
 		}
 		return expression.NewUnresolvedColumn(v.Name.String()), nil
+	case *sqlparser.ValuesFuncExpr:
+		col, err := exprToExpression(ctx, v.Name)
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewInsertValue(col), nil
 	case *sqlparser.FuncExpr:
 		exprs, err := selectExprsToExpressions(ctx, v.Exprs)
 		if err != nil {
@@ -1675,11 +1995,21 @@ func exprToExpression(ctx *sql.Context, e sqlparser.Expr) (sql.Expression, error
 		// TODO: get the original select statement, not the reconstruction
 		selectString := sqlparser.String(v.Select)
 		return plan.NewSubquery(node, selectString), nil
+	case *sqlparser.ExistsExpr:
+		node, err := convert(ctx, v.Subquery.Select, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return plan.NewExistsSubquery(plan.NewSubquery(node, sqlparser.String(v.Subquery.Select))), nil
 	case *sqlparser.CaseExpr:
 		return caseExprToExpression(ctx, v)
 	case *sqlparser.IntervalExpr:
 		return intervalExprToExpression(ctx, v)
 	case *sqlparser.CollateExpr:
+		if isWindowCollation(v.Charset) {
+			return windowFunctionToExpression(ctx, v)
+		}
 		// TODO: handle collation
 		return exprToExpression(ctx, v.Expr)
 	}
@@ -1716,16 +2046,54 @@ func convertInt(value string, base int) (sql.Expression, error) {
 	if ui32, err := strconv.ParseUint(value, base, 32); err == nil {
 		return expression.NewLiteral(uint32(ui32), sql.Uint32), nil
 	}
-	if i64, err := strconv.ParseInt(value, base, 64); err == nil {
+	i64, err := strconv.ParseInt(value, base, 64)
+	if err == nil {
 		return expression.NewLiteral(int64(i64), sql.Int64), nil
 	}
+	if ui64, err := strconv.ParseUint(value, base, 64); err == nil {
+		return expression.NewLiteral(uint64(ui64), sql.Uint64), nil
+	}
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange && base == 10 {
+		return convertLargeInt(value)
+	}
 
-	ui64, err := strconv.ParseUint(value, base, 64)
+	return nil, err
+}
+
+// convertLargeInt converts an integer out of the range of int64 and uint64 to a DECIMAL with as many digits as the
+// integer, or to a DOUBLE if it has more digits than a DECIMAL can hold, as MySQL does.
+func convertLargeInt(value string) (sql.Expression, error) {
+	sign := ""
+	if strings.HasPrefix(value, "-") {
+		sign = "-"
+	}
+	digits := strings.TrimLeft(strings.TrimPrefix(value, sign), "0")
+	if len(digits) <= sql.DecimalTypeMaxPrecision {
+		return expression.NewLiteral(sign+digits, sql.MustCreateDecimalType(uint8(len(digits)), 0)), nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
 	if err != nil {
 		return nil, err
 	}
+	return expression.NewLiteral(f, sql.Float64), nil
+}
+
+// convertBits converts the digits of a bit-value literal to its bytes, padding the most significant one with zeros.
+func convertBits(bits string) (sql.BinaryLiteral, error) {
+	if len(bits)%8 != 0 {
+		bits = strings.Repeat("0", 8-len(bits)%8) + bits
+	}
 
-	return expression.NewLiteral(uint64(ui64), sql.Uint64), nil
+	val := make(sql.BinaryLiteral, len(bits)/8)
+	for i := range val {
+		b, err := strconv.ParseUint(bits[i*8:i*8+8], 2, 8)
+		if err != nil {
+			return nil, err
+		}
+		val[i] = byte(b)
+	}
+	return val, nil
 }
 
 func convertVal(v *sqlparser.SQLVal) (sql.Expression, error) {
@@ -1747,18 +2115,29 @@ func convertVal(v *sqlparser.SQLVal) (sql.Expression, error) {
 		} else if strings.HasPrefix(v, "x") {
 			v = strings.Trim(v[1:], "'")
 		}
+		if len(v)%2 != 0 {
+			v = "0" + v
+		}
 
-		return convertInt(v, 16)
+		val, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewLiteral(sql.BinaryLiteral(val), sql.LongBlob), nil
 	case sqlparser.HexVal:
 		val, err := v.HexDecode()
 		if err != nil {
 			return nil, err
 		}
-		return expression.NewLiteral(val, sql.LongBlob), nil
+		return expression.NewLiteral(sql.BinaryLiteral(val), sql.LongBlob), nil
 	case sqlparser.ValArg:
-		return expression.NewLiteral(string(v.Val), sql.LongText), nil
+		return expression.NewBindVar(strings.TrimPrefix(string(v.Val), ":")), nil
 	case sqlparser.BitVal:
-		return expression.NewLiteral(v.Val[0] == '1', sql.Boolean), nil
+		val, err := convertBits(string(v.Val))
+		if err != nil {
+			return nil, err
+		}
+		return expression.NewLiteral(val, sql.LongBlob), nil
 	}
 
 	return nil, ErrInvalidSQLValType.New(v.Type)
@@ -1894,6 +2273,19 @@ func unaryExprToExpression(ctx *sql.Context, e *sqlparser.UnaryExpr) (sql.Expres
 	case sqlparser.PlusStr:
 		// Unary plus expressions do nothing (do not turn the expression positive). Just return the underlying expression.
 		return exprToExpression(ctx, e.Expr)
+	case sqlparser.BinaryStr, sqlparser.UBinaryStr:
+		expr, err := exprToExpression(ctx, e.Expr)
+		if err != nil {
+			return nil, err
+		}
+
+		// _binary introduces binary strings, such as the ones of the dumps of mysqldump
+		if lit, ok := expr.(*expression.Literal); ok && e.Operator == sqlparser.UBinaryStr {
+			if s, ok := lit.Value().(string); ok {
+				return expression.NewLiteral(sql.BinaryLiteral(s), sql.LongBlob), nil
+			}
+		}
+		return expression.NewConvert(expr, expression.ConvertToBinary), nil
 
 	default:
 		return nil, ErrUnsupportedFeature.New("unary operator: " + e.Operator)
@@ -2025,20 +2417,22 @@ func removeComments(s string) string {
 			result = append(result, ru)
 			result = append(result, readString(r, ru == '\'')...)
 		case '-':
-			peeked, err := r.Peek(2)
-			if err == nil &&
-				len(peeked) == 2 &&
+			// -- starts a comment if it's followed by a whitespace or control character, or by the end of the query
+			peeked, _ := r.Peek(2)
+			if len(peeked) >= 1 &&
 				rune(peeked[0]) == '-' &&
-				rune(peeked[1]) == ' ' {
+				(len(peeked) == 1 || peeked[1] <= ' ') {
 				discardUntilEOL(r)
 			} else {
 				result = append(result, ru)
 			}
 		case '/':
-			peeked, err := r.Peek(1)
-			if err == nil &&
-				len(peeked) == 1 &&
-				rune(peeked[0]) == '*' {
+			peeked, _ := r.Peek(2)
+			if string(peeked) == "*+" {
+				// Optimizer hints are kept for the parser
+				result = append(result, ru)
+				result = append(result, readHintComment(r)...)
+			} else if len(peeked) >= 1 && rune(peeked[0]) == '*' {
 				// read the char we peeked
 				_, _, _ = r.ReadRune()
 				discardMultilineComment(r)
@@ -2065,6 +2459,29 @@ func discardUntilEOL(r *bufio.Reader) {
 		}
 	}
 }
+func readHintComment(r *bufio.Reader) []rune {
+	var result []rune
+	for {
+		ru, _, err := r.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+		result = append(result, ru)
+		if ru == '*' {
+			peeked, err := r.Peek(1)
+			if err == nil && len(peeked) == 1 && rune(peeked[0]) == '/' {
+				// read the rune we just peeked
+				_, _, _ = r.ReadRune()
+				result = append(result, '/')
+				break
+			}
+		}
+	}
+	return result
+}
 func discardMultilineComment(r *bufio.Reader) {
 	for {
 		ru, _, err := r.ReadRune()
@@ -71,6 +71,24 @@ var fixtures = map[string]sql.Node{
 		nil,
 		nil,
 	),
+	`CREATE TABLE t1(a TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, b DATETIME ON UPDATE LOCALTIMESTAMP)`: plan.NewCreateTable(
+		sql.UnresolvedDatabase(""),
+		"t1",
+		sql.Schema{{
+			Name:     "a",
+			Type:     sql.Timestamp,
+			Nullable: true,
+			Extra:    "on update CURRENT_TIMESTAMP",
+		}, {
+			Name:     "b",
+			Type:     sql.Datetime,
+			Nullable: true,
+			Extra:    "on update CURRENT_TIMESTAMP",
+		}},
+		false,
+		nil,
+		nil,
+	),
 	`CREATE TABLE t1(a INTEGER NOT NULL PRIMARY KEY, b TEXT)`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -89,6 +107,24 @@ var fixtures = map[string]sql.Node{
 		nil,
 		nil,
 	),
+	`CREATE TABLE mydb.t1(a INTEGER NOT NULL PRIMARY KEY, b TEXT)`: plan.NewCreateTable(
+		sql.UnresolvedDatabase("mydb"),
+		"t1",
+		sql.Schema{{
+			Name:       "a",
+			Type:       sql.Int32,
+			Nullable:   false,
+			PrimaryKey: true,
+		}, {
+			Name:       "b",
+			Type:       sql.Text,
+			Nullable:   true,
+			PrimaryKey: false,
+		}},
+		false,
+		nil,
+		nil,
+	),
 	`CREATE TABLE t1(a INTEGER NOT NULL PRIMARY KEY COMMENT "hello", b TEXT COMMENT "goodbye")`: plan.NewCreateTable(
 		sql.UnresolvedDatabase(""),
 		"t1",
@@ -531,6 +567,15 @@ var fixtures = map[string]sql.Node{
 	`DROP TABLE IF EXISTS foo, bar, baz;`: plan.NewDropTable(
 		sql.UnresolvedDatabase(""), true, "foo", "bar", "baz",
 	),
+	`DROP TABLE mydb.foo, mydb.bar;`: plan.NewDropTable(
+		sql.UnresolvedDatabase("mydb"), false, "foo", "bar",
+	),
+	`RENAME TABLE mydb.foo TO mydb.bar`: plan.NewRenameTable(
+		sql.UnresolvedDatabase("mydb"), []string{"foo"}, []string{"bar"},
+	),
+	`ALTER TABLE mydb.foo DROP COLUMN bar`: plan.NewDropColumn(
+		sql.UnresolvedDatabase("mydb"), "foo", "bar",
+	),
 	`RENAME TABLE foo TO bar`: plan.NewRenameTable(
 		sql.UnresolvedDatabase(""), []string{"foo"}, []string{"bar"},
 	),
@@ -730,11 +775,9 @@ var fixtures = map[string]sql.Node{
 			OnDelete:          sql.ForeignKeyReferenceOption_DefaultAction,
 		},
 	),
-	`ALTER TABLE t1 DROP CONSTRAINT fk_name`: plan.NewAlterDropForeignKey(
+	`ALTER TABLE t1 DROP CONSTRAINT fk_name`: plan.NewAlterDropConstraint(
 		plan.NewUnresolvedTable("t1", ""),
-		&sql.ForeignKeyConstraint{
-			Name: "fk_name",
-		},
+		"fk_name",
 	),
 	`DESCRIBE foo;`: plan.NewShowColumns(false,
 		plan.NewUnresolvedTable("foo", ""),
@@ -752,6 +795,11 @@ var fixtures = map[string]sql.Node{
 			[]sql.Expression{expression.NewStar()},
 			plan.NewUnresolvedTable("foo", ""),
 		)),
+	"EXPLAIN FORMAT=cost SELECT * FROM foo": plan.NewDescribeQuery(
+		"cost", plan.NewProject(
+			[]sql.Expression{expression.NewStar()},
+			plan.NewUnresolvedTable("foo", ""),
+		)),
 	"EXPLAIN FORMAT=tree SELECT * FROM foo": plan.NewDescribeQuery(
 		"tree", plan.NewProject(
 			[]sql.Expression{expression.NewStar()},
@@ -813,6 +861,109 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTableAsOf("foo", "",
 				expression.NewLiteral("2019-01-01", sql.LongText))),
 	),
+	`SELECT number FROM numbers(1, 10) AS n;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("number"),
+		},
+		plan.NewTableAlias("n",
+			plan.NewUnresolvedTableFunction("numbers", []sql.Expression{
+				expression.NewLiteral(int8(1), sql.Int8),
+				expression.NewLiteral(int8(10), sql.Int8),
+			})),
+	),
+	`SELECT * FROM foo JOIN string_split(concat('a', ','), ',') ON foo.a = value;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewStar(),
+		},
+		plan.NewInnerJoin(
+			plan.NewUnresolvedTable("foo", ""),
+			plan.NewUnresolvedTableFunction("string_split", []sql.Expression{
+				expression.NewUnresolvedFunction("concat", false,
+					expression.NewLiteral("a", sql.LongText),
+					expression.NewLiteral(",", sql.LongText),
+				),
+				expression.NewLiteral(",", sql.LongText),
+			}),
+			expression.NewEquals(
+				expression.NewUnresolvedQualifiedColumn("foo", "a"),
+				expression.NewUnresolvedColumn("value"),
+			),
+		),
+	),
+	`SELECT a FROM foo WHERE b = ? AND c IN (?, :name)`: plan.NewProject(
+		[]sql.Expression{expression.NewUnresolvedColumn("a")},
+		plan.NewFilter(
+			expression.NewAnd(
+				expression.NewEquals(expression.NewUnresolvedColumn("b"), expression.NewBindVar("v1")),
+				expression.NewInTuple(
+					expression.NewUnresolvedColumn("c"),
+					expression.NewTuple(expression.NewBindVar("v2"), expression.NewBindVar("name")),
+				),
+			),
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
+	`SELECT a, ROW_NUMBER() OVER (PARTITION BY b, c ORDER BY d DESC) AS n FROM foo`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("a"),
+			expression.NewAlias("n", plan.NewWindowFunction(
+				plan.NewRowNumber(),
+				[]sql.Expression{
+					expression.NewUnresolvedColumn("b"),
+					expression.NewUnresolvedColumn("c"),
+				},
+				[]plan.SortField{
+					{Column: expression.NewUnresolvedColumn("d"), Order: plan.Descending},
+				},
+			)),
+		},
+		plan.NewUnresolvedTable("foo", ""),
+	),
+	`SELECT rank() over (ORDER BY a), DENSE_RANK() OVER(), SUM(a) OVER (PARTITION BY (b)) + 1 FROM foo`: plan.NewProject(
+		[]sql.Expression{
+			plan.NewWindowFunction(
+				plan.NewRank(),
+				nil,
+				[]plan.SortField{
+					{Column: expression.NewUnresolvedColumn("a"), Order: plan.Ascending},
+				},
+			),
+			plan.NewWindowFunction(plan.NewDenseRank(), nil, nil),
+			expression.NewArithmetic(
+				plan.NewWindowFunction(
+					expression.NewUnresolvedFunction("sum", true, expression.NewUnresolvedColumn("a")),
+					[]sql.Expression{expression.NewUnresolvedColumn("b")},
+					nil,
+				),
+				expression.NewLiteral(int8(1), sql.Int8),
+				"+",
+			),
+		},
+		plan.NewUnresolvedTable("foo", ""),
+	),
+	`SELECT a, COUNT(*) OVER (ORDER BY a) FROM foo ORDER BY COUNT(*) OVER (ORDER BY a)`: plan.NewSort(
+		[]plan.SortField{
+			{
+				Column: plan.NewWindowFunction(
+					expression.NewUnresolvedFunction("count", true, expression.NewStar()),
+					nil,
+					[]plan.SortField{{Column: expression.NewUnresolvedColumn("a"), Order: plan.Ascending}},
+				),
+				Order: plan.Ascending,
+			},
+		},
+		plan.NewProject(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("a"),
+				plan.NewWindowFunction(
+					expression.NewUnresolvedFunction("count", true, expression.NewStar()),
+					nil,
+					[]plan.SortField{{Column: expression.NewUnresolvedColumn("a"), Order: plan.Ascending}},
+				),
+			},
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
 	`SELECT foo, bar FROM foo WHERE foo = bar;`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewUnresolvedColumn("foo"),
@@ -957,6 +1108,17 @@ var fixtures = map[string]sql.Node{
 		},
 		plan.NewUnresolvedTable("t1", ""),
 	),
+	`SELECT foo AS bar, COUNT(*) FROM t1 GROUP BY 1;`: plan.NewGroupBy(
+		[]sql.Expression{
+			expression.NewAlias("bar", expression.NewUnresolvedColumn("foo")),
+			expression.NewUnresolvedFunction("count", true,
+				expression.NewStar()),
+		},
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+		},
+		plan.NewUnresolvedTable("t1", ""),
+	),
 	`SELECT COUNT(*) FROM t1;`: plan.NewGroupBy(
 		[]sql.Expression{
 			expression.NewUnresolvedFunction("count", true,
@@ -1023,6 +1185,25 @@ var fixtures = map[string]sql.Node{
 		[]string{"col1", "col2"},
 		[]sql.Expression{},
 	),
+	`INSERT INTO t1 (col1, col2) VALUES ('a', 1) ON DUPLICATE KEY UPDATE col2 = VALUES(col2) + 1`: plan.NewInsertInto(
+		plan.NewUnresolvedTable("t1", ""),
+		plan.NewValues([][]sql.Expression{{
+			expression.NewLiteral("a", sql.LongText),
+			expression.NewLiteral(int8(1), sql.Int8),
+		}}),
+		false,
+		[]string{"col1", "col2"},
+		[]sql.Expression{
+			expression.NewSetField(
+				expression.NewUnresolvedColumn("col2"),
+				expression.NewArithmetic(
+					expression.NewInsertValue(expression.NewUnresolvedColumn("col2")),
+					expression.NewLiteral(int8(1), sql.Int8),
+					"+",
+				),
+			),
+		},
+	),
 	`SHOW TABLES`:                           plan.NewShowTables(sql.UnresolvedDatabase(""), false, nil),
 	`SHOW FULL TABLES`:                      plan.NewShowTables(sql.UnresolvedDatabase(""), true, nil),
 	`SHOW TABLES FROM foo`:                  plan.NewShowTables(sql.UnresolvedDatabase("foo"), false, nil),
@@ -1123,6 +1304,21 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("foo", ""),
 		)),
 	),
+	`SELECT SQL_CALC_FOUND_ROWS foo, bar FROM foo LIMIT 5,2;`: plan.NewLimit(2,
+		plan.NewOffset(5, plan.NewProject(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewUnresolvedColumn("bar"),
+			},
+			plan.NewUnresolvedTable("foo", ""),
+		)),
+	).WithCalcFoundRows(true),
+	`SELECT DISTINCT sql_calc_found_rows foo FROM foo`: plan.NewDistinct(plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+		},
+		plan.NewUnresolvedTable("foo", ""),
+	)),
 	`SELECT * FROM foo WHERE (a = 1)`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewStar(),
@@ -1236,13 +1432,55 @@ var fixtures = map[string]sql.Node{
 	),
 	`SELECT 0x01AF`: plan.NewProject(
 		[]sql.Expression{
-			expression.NewLiteral(int16(431), sql.Int16),
+			expression.NewLiteral(sql.BinaryLiteral{0x01, 0xAF}, sql.LongBlob),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT 0x1AF`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(sql.BinaryLiteral{0x01, 0xAF}, sql.LongBlob),
 		},
 		plan.NewUnresolvedTable("dual", ""),
 	),
 	`SELECT X'41'`: plan.NewProject(
 		[]sql.Expression{
-			expression.NewLiteral([]byte{'A'}, sql.LongBlob),
+			expression.NewLiteral(sql.BinaryLiteral{'A'}, sql.LongBlob),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT b'1000001'`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(sql.BinaryLiteral{'A'}, sql.LongBlob),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT B'100000101000010'`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(sql.BinaryLiteral{'A', 'B'}, sql.LongBlob),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT 1e3`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(float64(1000), sql.Float64),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT 2.5E-1`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(float64(0.25), sql.Float64),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT 18446744073709551615`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(uint64(18446744073709551615), sql.Uint64),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
+	`SELECT 18446744073709551616`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral("18446744073709551616", sql.MustCreateDecimalType(20, 0)),
 		},
 		plan.NewUnresolvedTable("dual", ""),
 	),
@@ -1268,7 +1506,7 @@ var fixtures = map[string]sql.Node{
 		[]sql.Expression{expression.NewStar()},
 		plan.NewFilter(
 			expression.NewEquals(
-				expression.NewLiteral(":foo_id", sql.LongText),
+				expression.NewBindVar("foo_id"),
 				expression.NewLiteral(int8(2), sql.Int8),
 			),
 			plan.NewUnresolvedTable("foo", ""),
@@ -1391,6 +1629,35 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("foo", ""),
 		),
 	),
+	`SELECT * FROM foo WHERE EXISTS (SELECT * FROM baz WHERE baz.j = foo.i)`: plan.NewProject(
+		[]sql.Expression{expression.NewStar()},
+		plan.NewFilter(
+			plan.NewExistsSubquery(
+				plan.NewSubquery(plan.NewProject(
+					[]sql.Expression{expression.NewStar()},
+					plan.NewFilter(
+						expression.NewEquals(
+							expression.NewUnresolvedQualifiedColumn("baz", "j"),
+							expression.NewUnresolvedQualifiedColumn("foo", "i"),
+						),
+						plan.NewUnresolvedTable("baz", ""),
+					),
+				), "select * from baz where baz.j = foo.i"),
+			),
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
+	`SELECT NOT EXISTS (SELECT 1)`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewNot(plan.NewExistsSubquery(
+				plan.NewSubquery(plan.NewProject(
+					[]sql.Expression{expression.NewLiteral(int8(1), sql.Int8)},
+					plan.NewUnresolvedTable("dual", ""),
+				), "select 1 from dual"),
+			)),
+		},
+		plan.NewUnresolvedTable("dual", ""),
+	),
 	`SELECT a, b FROM t ORDER BY 2, 1`: plan.NewSort(
 		[]plan.SortField{
 			{
@@ -1420,6 +1687,13 @@ var fixtures = map[string]sql.Node{
 		},
 		plan.NewUnresolvedTable("mytable", ""),
 	),
+	`SELECT _binary 'abc', BINARY i FROM mytable`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewLiteral(sql.BinaryLiteral("abc"), sql.LongBlob),
+			expression.NewConvert(expression.NewUnresolvedColumn("i"), expression.ConvertToBinary),
+		},
+		plan.NewUnresolvedTable("mytable", ""),
+	),
 	`SELECT +i FROM mytable`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewUnresolvedColumn("i"),
@@ -1575,14 +1849,20 @@ var fixtures = map[string]sql.Node{
 		[]sql.Expression{},
 		plan.NewUnresolvedTable("foo", ""),
 	),
-	`SHOW INDEXES FROM foo`: plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW INDEX FROM foo`:   plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW KEYS FROM foo`:    plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW INDEXES IN foo`:   plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW INDEX IN foo`:     plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW KEYS IN foo`:      plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
-	`SHOW FULL PROCESSLIST`: plan.NewShowProcessList(),
-	`SHOW PROCESSLIST`:      plan.NewShowProcessList(),
+	`SHOW INDEXES FROM foo`:              plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW INDEX FROM foo`:                plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW KEYS FROM foo`:                 plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW INDEXES IN foo`:                plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW INDEX IN foo`:                  plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW KEYS IN foo`:                   plan.NewShowIndexes(plan.NewUnresolvedTable("foo", "")),
+	`SHOW FULL PROCESSLIST`:              plan.NewShowProcessList(),
+	`SHOW PROCESSLIST`:                   plan.NewShowProcessList(),
+	`SHOW GRANTS`:                        plan.NewShowGrants("", ""),
+	`SHOW GRANTS FOR CURRENT_USER()`:     plan.NewShowGrants("", ""),
+	`SHOW GRANTS FOR 'joe'@'localhost'`:  plan.NewShowGrants("joe", "localhost"),
+	"SHOW GRANTS FOR `joe`":              plan.NewShowGrants("joe", "%"),
+	`show grants for support @ "%"`:      plan.NewShowGrants("support", "%"),
+	`SHOW GRANTS FOR 'current_user'@'%'`: plan.NewShowGrants("current_user", "%"),
 	`SELECT @@allowed_max_packet`: plan.NewProject([]sql.Expression{
 		expression.NewUnresolvedColumn("@@allowed_max_packet"),
 	}, plan.NewUnresolvedTable("dual", "")),
@@ -1616,6 +1896,33 @@ var fixtures = map[string]sql.Node{
 			expression.NewSetField(expression.NewUnresolvedColumn("@@sql_select_limit"), expression.NewDefaultColumn("")),
 		},
 	),
+	`SET NAMES utf8mb4`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_client"), expression.NewLiteral("utf8mb4", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_connection"), expression.NewLiteral("utf8mb4", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_results"), expression.NewLiteral("utf8mb4", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("collation_connection"), expression.NewLiteral("utf8mb4_0900_ai_ci", sql.LongText)),
+		},
+	),
+	`SET CHARACTER SET utf8`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_client"), expression.NewLiteral("utf8", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_results"), expression.NewLiteral("utf8", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("character_set_connection"), expression.NewUnresolvedColumn("@@character_set_database")),
+			expression.NewSetField(expression.NewUnresolvedColumn("collation_connection"), expression.NewUnresolvedColumn("@@collation_database")),
+		},
+	),
+	`SET SESSION TRANSACTION ISOLATION LEVEL READ COMMITTED, READ ONLY`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("transaction_isolation"), expression.NewLiteral("READ-COMMITTED", sql.LongText)),
+			expression.NewSetField(expression.NewUnresolvedColumn("transaction_read_only"), expression.NewLiteral(int8(1), sql.Int8)),
+		},
+	),
+	`SET TRANSACTION READ WRITE`: plan.NewSet(
+		[]sql.Expression{
+			expression.NewSetField(expression.NewUnresolvedColumn("transaction_read_only"), expression.NewLiteral(int8(0), sql.Int8)),
+		},
+	),
 	`/*!40101 SET NAMES utf8 */`: plan.Nothing,
 	`SELECT /*!40101 SET NAMES utf8 */ * FROM foo`: plan.NewProject(
 		[]sql.Expression{
@@ -1623,6 +1930,43 @@ var fixtures = map[string]sql.Node{
 		},
 		plan.NewUnresolvedTable("foo", ""),
 	),
+	`SELECT /*+ JOIN_ORDER(b, A) HASH_JOIN(b) */ * FROM a JOIN b`: plan.NewQueryHints(
+		sql.Hints{
+			{Name: sql.JoinOrderHint, Args: []string{"b", "a"}},
+			{Name: sql.HashJoinHint, Args: []string{"b"}},
+		},
+		plan.NewProject(
+			[]sql.Expression{expression.NewStar()},
+			plan.NewCrossJoin(
+				plan.NewUnresolvedTable("a", ""),
+				plan.NewUnresolvedTable("b", ""),
+			),
+		),
+	),
+	"SELECT /*+ index(foo `PRIMARY`, foo_idx) no_merge() */ * FROM foo": plan.NewQueryHints(
+		sql.Hints{
+			{Name: sql.IndexHint, Args: []string{"foo", "primary", "foo_idx"}},
+			{Name: sql.NoMergeHint},
+		},
+		plan.NewProject(
+			[]sql.Expression{expression.NewStar()},
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
+	`SELECT /*+ MAX_EXECUTION_TIME(1000) BOGUS(foo) NO_INDEX(foo) */ /*+ NO_HASH_JOIN(foo) */ * FROM foo`: plan.NewQueryHints(
+		sql.Hints{
+			{Name: sql.MaxExecutionTimeHint, Args: []string{"1000"}},
+			{Name: sql.NoHashJoinHint, Args: []string{"foo"}},
+		},
+		plan.NewProject(
+			[]sql.Expression{expression.NewStar()},
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
+	`SELECT /*+ MAX_EXECUTION_TIME(foo) */ * FROM foo`: plan.NewProject(
+		[]sql.Expression{expression.NewStar()},
+		plan.NewUnresolvedTable("foo", ""),
+	),
 	`SHOW DATABASES`: plan.NewShowDatabases(),
 	`SELECT * FROM foo WHERE i LIKE 'foo'`: plan.NewProject(
 		[]sql.Expression{expression.NewStar()},
@@ -1684,6 +2028,12 @@ var fixtures = map[string]sql.Node{
 	`DESC foo.bar`: plan.NewShowColumns(false,
 		plan.NewUnresolvedTable("bar", "foo"),
 	),
+	`EXPLAIN foo.bar`: plan.NewShowColumns(false,
+		plan.NewUnresolvedTable("bar", "foo"),
+	),
+	"EXPLAIN `foo`;": plan.NewShowColumns(false,
+		plan.NewUnresolvedTable("foo", ""),
+	),
 	`SELECT * FROM foo.bar`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewStar(),
@@ -1696,6 +2046,10 @@ var fixtures = map[string]sql.Node{
 	`SHOW VARIABLES LIKE 'gtid_mode'`:          plan.NewShowVariables(sql.NewEmptyContext().GetAll(), "gtid_mode"),
 	`SHOW SESSION VARIABLES LIKE 'autocommit'`: plan.NewShowVariables(sql.NewEmptyContext().GetAll(), "autocommit"),
 	`UNLOCK TABLES`:                            plan.NewUnlockTables(),
+	`SHOW VARIABLES WHERE Variable_name = 'autocommit'`: plan.NewFilter(
+		expression.NewEquals(expression.NewUnresolvedColumn("Variable_name"), expression.NewLiteral("autocommit", sql.LongText)),
+		plan.NewShowVariables(sql.NewEmptyContext().GetAll(), ""),
+	),
 	`LOCK TABLES foo READ`: plan.NewLockTables([]*plan.TableLock{
 		{Table: plan.NewUnresolvedTable("foo", "")},
 	}),
@@ -1730,6 +2084,65 @@ var fixtures = map[string]sql.Node{
 		{Table: plan.NewUnresolvedTable("bar", ""), Write: true},
 		{Table: plan.NewUnresolvedTable("baz", "")},
 	}),
+	`CHECKSUM TABLE foo`: plan.NewChecksumTable([]sql.Node{plan.NewUnresolvedTable("foo", "")}, false, false),
+	"CHECKSUM TABLE `mydb`.`foo`, bar QUICK": plan.NewChecksumTable([]sql.Node{
+		plan.NewUnresolvedTable("foo", "mydb"),
+		plan.NewUnresolvedTable("bar", ""),
+	}, true, false),
+	`checksum table foo extended;`:      plan.NewChecksumTable([]sql.Node{plan.NewUnresolvedTable("foo", "")}, false, true),
+	`CHECK TABLE foo, mydb.bar`:         plan.NewCheckTable([]sql.Node{plan.NewUnresolvedTable("foo", ""), plan.NewUnresolvedTable("bar", "mydb")}),
+	`CHECK TABLE foo FOR UPGRADE QUICK`: plan.NewCheckTable([]sql.Node{plan.NewUnresolvedTable("foo", "")}),
+	`OPTIMIZE TABLE foo`:                plan.NewOptimizeTable([]sql.Node{plan.NewUnresolvedTable("foo", "")}),
+	`OPTIMIZE NO_WRITE_TO_BINLOG TABLES foo, bar`: plan.NewOptimizeTable([]sql.Node{
+		plan.NewUnresolvedTable("foo", ""),
+		plan.NewUnresolvedTable("bar", ""),
+	}),
+	`ANALYZE TABLE foo`: plan.NewAnalyzeTable([]sql.Node{plan.NewUnresolvedTable("foo", "")}),
+	"ANALYZE LOCAL TABLE `mydb`.foo, bar": plan.NewAnalyzeTable([]sql.Node{
+		plan.NewUnresolvedTable("foo", "mydb"),
+		plan.NewUnresolvedTable("bar", ""),
+	}),
+	`SIGNAL SQLSTATE '45000'`: plan.NewSignal("45000", nil),
+	`SIGNAL SQLSTATE VALUE "01000" SET MESSAGE_TEXT = 'oops', MYSQL_ERRNO = 1001`: plan.NewSignal("01000", []plan.SignalInfo{
+		{Name: plan.ConditionMessageText, Value: expression.NewLiteral("oops", sql.LongText)},
+		{Name: plan.ConditionMysqlErrno, Value: expression.NewLiteral(int16(1001), sql.Int16)},
+	}),
+	`RESIGNAL`: plan.NewResignal("", nil),
+	`resignal set table_name = @t`: plan.NewResignal("", []plan.SignalInfo{
+		{Name: plan.ConditionTableName, Value: expression.NewUnresolvedColumn("@t")},
+	}),
+	`GET DIAGNOSTICS @n = NUMBER, @r = row_count`: plan.NewGetDiagnostics(nil,
+		[]*expression.UserVar{expression.NewUserVar("n"), expression.NewUserVar("r")},
+		[]string{plan.DiagnosticsNumber, plan.DiagnosticsRowCount},
+	),
+	`GET CURRENT DIAGNOSTICS CONDITION 1 @s = RETURNED_SQLSTATE, @m = MESSAGE_TEXT`: plan.NewGetDiagnostics(
+		expression.NewLiteral(int8(1), sql.Int8),
+		[]*expression.UserVar{expression.NewUserVar("s"), expression.NewUserVar("m")},
+		[]string{plan.ConditionReturnedSQLState, plan.ConditionMessageText},
+	),
+	`HANDLER foo OPEN`:             plan.NewHandlerOpen(plan.NewUnresolvedTable("foo", ""), ""),
+	"HANDLER mydb.foo OPEN AS `f`": plan.NewHandlerOpen(plan.NewUnresolvedTable("foo", "mydb"), "f"),
+	`HANDLER foo OPEN f`:           plan.NewHandlerOpen(plan.NewUnresolvedTable("foo", ""), "f"),
+	`HANDLER foo CLOSE`:            plan.NewHandlerClose("foo"),
+	`HANDLER foo READ FIRST`:       plan.NewLimit(1, plan.NewHandlerRead("foo", "", "first", nil)),
+	`HANDLER foo READ idx NEXT WHERE a > 1 LIMIT 2, 3`: plan.NewLimit(3, plan.NewOffset(2, plan.NewFilter(
+		expression.NewGreaterThan(
+			expression.NewUnresolvedColumn("a"),
+			expression.NewLiteral(int8(1), sql.Int8),
+		),
+		plan.NewHandlerRead("foo", "idx", "next", nil),
+	))),
+	"HANDLER foo READ `first` <= ('a', 1) LIMIT 10": plan.NewLimit(10, plan.NewHandlerRead("foo", "first", "<=", []sql.Expression{
+		expression.NewLiteral("a", sql.LongText),
+		expression.NewLiteral(int8(1), sql.Int8),
+	})),
+	`HANDLER foo READ idx=((1 + 1))`: plan.NewLimit(1, plan.NewHandlerRead("foo", "idx", "=", []sql.Expression{
+		expression.NewArithmetic(
+			expression.NewLiteral(int8(1), sql.Int8),
+			expression.NewLiteral(int8(1), sql.Int8),
+			"+",
+		),
+	})),
 	`SHOW CREATE DATABASE foo`:                 plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
 	`SHOW CREATE SCHEMA foo`:                   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), false),
 	`SHOW CREATE DATABASE IF NOT EXISTS foo`:   plan.NewShowCreateDatabase(sql.UnresolvedDatabase("foo"), true),
@@ -1814,6 +2227,11 @@ var fixtures = map[string]sql.Node{
 		showCollationProjection,
 	),
 	`ROLLBACK`:                               plan.NewRollback(),
+	`COMMIT`:                                 plan.NewCommit(),
+	`BEGIN`:                                  plan.NewStartTransaction(),
+	`START TRANSACTION`:                      plan.NewStartTransaction(),
+	`TRUNCATE TABLE t`:                       plan.NewTruncate(plan.NewUnresolvedTable("t", "")),
+	`TRUNCATE mydb.t`:                        plan.NewTruncate(plan.NewUnresolvedTable("t", "mydb")),
 	"SHOW CREATE TABLE `mytable`":            plan.NewShowCreateTable(plan.NewUnresolvedTable("mytable", ""), false),
 	"SHOW CREATE TABLE mytable":              plan.NewShowCreateTable(plan.NewUnresolvedTable("mytable", ""), false),
 	"SHOW CREATE TABLE mydb.`mytable`":       plan.NewShowCreateTable(plan.NewUnresolvedTable("mytable", "mydb"), false),
@@ -1911,6 +2329,31 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("t", ""),
 		),
 	),
+	`SELECT a FROM foo HAVING COUNT(*) > 5`: plan.NewHaving(
+		expression.NewGreaterThan(
+			expression.NewUnresolvedFunction("count", true, expression.NewStar()),
+			expression.NewLiteral(int8(5), sql.Int8),
+		),
+		plan.NewGroupBy(
+			[]sql.Expression{expression.NewUnresolvedColumn("a")},
+			[]sql.Expression{},
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
+	`SELECT a FROM foo HAVING a > (SELECT COUNT(*) FROM bar)`: plan.NewHaving(
+		expression.NewGreaterThan(
+			expression.NewUnresolvedColumn("a"),
+			plan.NewSubquery(plan.NewGroupBy(
+				[]sql.Expression{expression.NewUnresolvedFunction("count", true, expression.NewStar())},
+				[]sql.Expression{},
+				plan.NewUnresolvedTable("bar", ""),
+			), "select COUNT(*) from bar"),
+		),
+		plan.NewProject(
+			[]sql.Expression{expression.NewUnresolvedColumn("a")},
+			plan.NewUnresolvedTable("foo", ""),
+		),
+	),
 	`SELECT COUNT(*) FROM foo GROUP BY a HAVING COUNT(*) > 5`: plan.NewHaving(
 		expression.NewGreaterThan(
 			expression.NewUnresolvedFunction("count", true, expression.NewStar()),
@@ -2253,21 +2696,53 @@ func assertNodesEqualWithDiff(t *testing.T, expected, actual sql.Node) {
 }
 
 var fixturesErrors = map[string]*errors.Kind{
-	`SHOW METHEMONEY`:                                         ErrUnsupportedFeature,
-	`LOCK TABLES foo AS READ`:                                 errUnexpectedSyntax,
-	`LOCK TABLES foo LOW_PRIORITY READ`:                       errUnexpectedSyntax,
-	`SELECT * FROM mytable LIMIT -100`:                        ErrUnsupportedSyntax,
-	`SELECT * FROM mytable LIMIT 100 OFFSET -1`:               ErrUnsupportedSyntax,
-	`SELECT INTERVAL 1 DAY - '2018-05-01'`:                    ErrUnsupportedSyntax,
-	`SELECT INTERVAL 1 DAY * '2018-05-01'`:                    ErrUnsupportedSyntax,
-	`SELECT '2018-05-01' * INTERVAL 1 DAY`:                    ErrUnsupportedSyntax,
-	`SELECT '2018-05-01' / INTERVAL 1 DAY`:                    ErrUnsupportedSyntax,
-	`SELECT INTERVAL 1 DAY + INTERVAL 1 DAY`:                  ErrUnsupportedSyntax,
-	`SELECT '2018-05-01' + (INTERVAL 1 DAY + INTERVAL 1 DAY)`: ErrUnsupportedSyntax,
-	`SELECT AVG(DISTINCT foo) FROM b`:                         ErrUnsupportedSyntax,
-	`CREATE VIEW myview AS SELECT AVG(DISTINCT foo) FROM b`:   ErrUnsupportedSyntax,
-	"DESCRIBE FORMAT=pretty SELECT * FROM foo":                errInvalidDescribeFormat,
-	`CREATE TABLE test (pk int, primary key(pk, noexist))`:    ErrUnknownIndexColumn,
+	`SHOW GRANTS FOR 'joe'@'%' USING r`:                                                        ErrUnsupportedSyntax,
+	`SHOW METHEMONEY`:                                                                          ErrUnsupportedFeature,
+	`DROP TABLE mydb.foo, otherdb.bar`:                                                         ErrUnsupportedFeature,
+	`RENAME TABLE mydb.foo TO otherdb.foo`:                                                     ErrUnsupportedFeature,
+	`LOCK TABLES foo AS READ`:                                                                  errUnexpectedSyntax,
+	`LOCK TABLES foo LOW_PRIORITY READ`:                                                        errUnexpectedSyntax,
+	`CHECKSUM TABLE foo FAST`:                                                                  errUnexpectedSyntax,
+	`OPTIMIZE LOCAL TABLE foo bar`:                                                             errUnexpectedSyntax,
+	`SIGNAL SET MESSAGE_TEXT = 'oops'`:                                                         errUnexpectedSyntax,
+	`SIGNAL SQLSTATE '45000' SET FOO = 1`:                                                      errUnexpectedSyntax,
+	`SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'a', message_text = 'b'`:                       errDuplicateSignalInfo,
+	`GET STACKED DIAGNOSTICS @n = NUMBER`:                                                      errGetStackedDiagnostics,
+	`GET DIAGNOSTICS n = NUMBER`:                                                               errUnexpectedSyntax,
+	`GET DIAGNOSTICS @n = MESSAGE_TEXT`:                                                        errUnexpectedSyntax,
+	`SELECT * FROM mytable LIMIT -100`:                                                         ErrUnsupportedSyntax,
+	`SELECT * FROM mytable LIMIT 100 OFFSET -1`:                                                ErrUnsupportedSyntax,
+	`SELECT INTERVAL 1 DAY - '2018-05-01'`:                                                     ErrUnsupportedSyntax,
+	`SELECT INTERVAL 1 DAY * '2018-05-01'`:                                                     ErrUnsupportedSyntax,
+	`SELECT '2018-05-01' * INTERVAL 1 DAY`:                                                     ErrUnsupportedSyntax,
+	`SELECT '2018-05-01' / INTERVAL 1 DAY`:                                                     ErrUnsupportedSyntax,
+	`SELECT INTERVAL 1 DAY + INTERVAL 1 DAY`:                                                   ErrUnsupportedSyntax,
+	`SELECT '2018-05-01' + (INTERVAL 1 DAY + INTERVAL 1 DAY)`:                                  ErrUnsupportedSyntax,
+	`SELECT AVG(DISTINCT foo) FROM b`:                                                          ErrUnsupportedSyntax,
+	`CREATE VIEW myview AS SELECT AVG(DISTINCT foo) FROM b`:                                    ErrUnsupportedSyntax,
+	"DESCRIBE FORMAT=pretty SELECT * FROM foo":                                                 errInvalidDescribeFormat,
+	`CREATE TABLE test (pk int, primary key(pk, noexist))`:                                     ErrUnknownIndexColumn,
+	`SELECT SUM(a) OVER (w) FROM foo`:                                                          ErrUnsupportedFeature,
+	`SELECT SUM(a) OVER (ORDER BY a ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM foo`:        ErrUnsupportedFeature,
+	`SELECT LAG(a) OVER (ORDER BY a) FROM foo`:                                                 ErrUnsupportedFeature,
+	`SELECT a, COUNT(*), ROW_NUMBER() OVER () FROM foo GROUP BY a`:                             ErrUnsupportedFeature,
+	`SELECT ROW_NUMBER(a) OVER () FROM foo`:                                                    ErrUnsupportedSyntax,
+	`SELECT foo FROM t1 GROUP BY 0`:                                                            ErrGroupByColumnIndex,
+	`SELECT foo FROM t1 GROUP BY 2`:                                                            ErrGroupByColumnIndex,
+	`SELECT foo, COUNT(*) FROM t1 GROUP BY 2`:                                                  ErrGroupByAggregate,
+	`UPDATE foo AS OF '2019-01-01' SET bar = 1`:                                                sql.ErrIncompatibleAsOf,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE v INT; FETCH c INTO v; END`: ErrUndefinedCursor,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE c CURSOR FOR SELECT x FROM b; FETCH c INTO v; END`:                                    ErrUndeclaredVariable,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE v INT; DECLARE v INT; END`:                                                            ErrDuplicateVariable,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE c CURSOR FOR SELECT x FROM b; DECLARE c CURSOR FOR SELECT x FROM b; END`:              ErrDuplicateCursor,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE c CURSOR FOR SELECT x FROM b; DECLARE v INT; END`:                                     ErrVariableAfter,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE CONTINUE HANDLER FOR NOT FOUND SET @x = 1; DECLARE c CURSOR FOR SELECT x FROM b; END`: ErrCursorAfter,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE EXIT HANDLER FOR SQLSTATE '00000' SET @x = 1; END`:                                    ErrBadSQLState,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN l: LOOP LEAVE m; END LOOP; END`:                                                               ErrNoMatchingLabel,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW l: BEGIN ITERATE l; END`:                                                                            ErrNoMatchingLabel,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW l: BEGIN DECLARE EXIT HANDLER FOR SQLEXCEPTION LEAVE l; END`:                                        ErrNoMatchingLabel,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW l: LOOP SET @x = 1; END LOOP m`:                                                                     ErrEndLabel,
+	`CREATE TRIGGER t AFTER INSERT ON a FOR EACH ROW BEGIN DECLARE e CONDITION FOR SQLSTATE '45000'; END`:                                                ErrUnsupportedFeature,
 }
 
 func TestParseErrors(t *testing.T) {
@@ -2303,6 +2778,14 @@ func TestRemoveComments(t *testing.T) {
 			`SELECT ' -- something'`,
 			`SELECT ' -- something'`,
 		},
+		{
+			"--\n-- Table structure\n--\nSELECT 1 --",
+			"SELECT 1 ",
+		},
+		{
+			"SELECT 1 --\tsomething",
+			"SELECT 1 ",
+		},
 		{
 			`SELECT /* FOO */ 1;`,
 			`SELECT  1;`,
@@ -2319,6 +2802,14 @@ func TestRemoveComments(t *testing.T) {
 			`SELECT '\'/* FOO */ 1\'';`,
 			`SELECT '\'/* FOO */ 1\'';`,
 		},
+		{
+			`SELECT /*+ JOIN_ORDER(a, b) */ /* FOO */ 1;`,
+			`SELECT /*+ JOIN_ORDER(a, b) */  1;`,
+		},
+		{
+			`SELECT /*+ MAX_EXECUTION_TIME(1) */`,
+			`SELECT /*+ MAX_EXECUTION_TIME(1) */`,
+		},
 	}
 	for _, tt := range testCases {
 		t.Run(tt.input, func(t *testing.T) {
@@ -2331,6 +2822,19 @@ func TestRemoveComments(t *testing.T) {
 	}
 }
 
+func TestParseHintWarnings(t *testing.T) {
+	require := require.New(t)
+
+	ctx := sql.NewEmptyContext()
+	_, err := Parse(ctx, `SELECT /*+ JOIN_ORDER(a, b) INDEX() MERGE(a) */ * FROM a JOIN b`)
+	require.NoError(err)
+
+	warnings := ctx.Warnings()
+	require.Len(warnings, 1)
+	require.Equal(1064, warnings[0].Code)
+	require.Equal("Optimizer hint syntax error near 'INDEX() MERGE(a)'", warnings[0].Message)
+}
+
 func TestFixSetQuery(t *testing.T) {
 	testCases := []struct {
 		in, out string
@@ -2347,6 +2851,53 @@ func TestFixSetQuery(t *testing.T) {
 	}
 }
 
+func TestRewriteTableFunctions(t *testing.T) {
+	testCases := []struct {
+		in, out string
+	}{
+		{"SELECT * FROM foo", "SELECT * FROM foo"},
+		{"SELECT * FROM numbers(3)", "SELECT * FROM `__table_function__numbers(3)`"},
+		{"SELECT * FROM numbers(f(1), (2)) AS n", "SELECT * FROM `__table_function__numbers(f(1), (2))` AS n"},
+		{"SELECT * FROM a, `numbers`(3)", "SELECT * FROM a, `__table_function__``numbers``(3)`"},
+		{"SELECT * FROM a JOIN numbers(3) ON a.i = abs(number)", "SELECT * FROM a JOIN `__table_function__numbers(3)` ON a.i = abs(number)"},
+		{"SELECT * FROM (a JOIN numbers(3))", "SELECT * FROM (a JOIN `__table_function__numbers(3)`)"},
+		{"SELECT * FROM (SELECT abs(1) FROM numbers(3)) t", "SELECT * FROM (SELECT abs(1) FROM `__table_function__numbers(3)`) t"},
+		{"SELECT * FROM a WHERE i IN (SELECT i FROM numbers(3))", "SELECT * FROM a WHERE i IN (SELECT i FROM `__table_function__numbers(3)`)"},
+		{"SELECT * FROM a ORDER BY i, abs(i)", "SELECT * FROM a ORDER BY i, abs(i)"},
+		{"SELECT 'FROM numbers(3)'", "SELECT 'FROM numbers(3)'"},
+		{"SELECT * FROM numbers(3", "SELECT * FROM numbers(3"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.out, rewriteTableFunctions(tt.in))
+			require.Equal(t, tt.in, restoreTableFunctions(tt.out))
+		})
+	}
+}
+
+func TestRewriteWindowFunctions(t *testing.T) {
+	testCases := []struct {
+		in, out string
+	}{
+		{"SELECT a FROM foo", "SELECT a FROM foo"},
+		{"SELECT ROW_NUMBER() OVER () FROM foo", "SELECT ROW_NUMBER() COLLATE `__window__OVER ()` FROM foo"},
+		{"SELECT sum(a) over (PARTITION BY (b) ORDER BY `c`), a", "SELECT sum(a) COLLATE `__window__over (PARTITION BY (b) ORDER BY ``c``)`, a"},
+		{"SELECT a + RANK() OVER(ORDER BY f(a)) FROM foo", "SELECT a + RANK() COLLATE `__window__OVER(ORDER BY f(a))` FROM foo"},
+		{"SELECT (SELECT COUNT(*) OVER () FROM foo) AS over", "SELECT (SELECT COUNT(*) COLLATE `__window__OVER ()` FROM foo) AS over"},
+		{"SELECT over FROM foo ORDER BY f(over)", "SELECT over FROM foo ORDER BY f(over)"},
+		{"SELECT 'SUM(a) OVER ()'", "SELECT 'SUM(a) OVER ()'"},
+		{"SELECT SUM(a) OVER (ORDER BY a", "SELECT SUM(a) OVER (ORDER BY a"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.out, rewriteWindowFunctions(tt.in))
+			require.Equal(t, tt.in, restoreWindowFunctions(tt.out))
+		})
+	}
+}
+
 func TestPrintTree(t *testing.T) {
 	require := require.New(t)
 	node, err := Parse(sql.NewEmptyContext(), `
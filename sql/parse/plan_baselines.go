@@ -0,0 +1,77 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	pinPlanBaselineRegex    = regexp.MustCompile(`(?is)^pin\s+plan\s+baseline\s+for\s+(.+)$`)
+	showPlanBaselinesRegex  = regexp.MustCompile(`(?is)^show\s+plan\s+baselines$`)
+	purgePlanBaselineRegex  = regexp.MustCompile(`(?is)^purge\s+plan\s+baseline\s+(?:for\s+(.+)|'([0-9a-f]+)'|"([0-9a-f]+)")$`)
+	purgePlanBaselinesRegex = regexp.MustCompile(`(?is)^purge\s+plan\s+baselines$`)
+	selectStatementRegex    = regexp.MustCompile(`(?is)^select\b`)
+)
+
+// parsePlanBaseline parses the plan baseline statements, which MySQL doesn't have: PIN PLAN BASELINE FOR with a
+// SELECT statement, SHOW PLAN BASELINES, PURGE PLAN BASELINE FOR with a statement or with the digest of one as a
+// string, and PURGE PLAN BASELINES, which purges all of them.
+func parsePlanBaseline(ctx *sql.Context, query string) (sql.Node, error) {
+	if match := pinPlanBaselineRegex.FindStringSubmatch(query); match != nil {
+		statement, err := parsePinnedStatement(ctx, match[1])
+		if err != nil {
+			return nil, err
+		}
+		digest, text := Digest(match[1])
+		return plan.NewPinPlanBaseline(digest, text, statement), nil
+	}
+
+	if showPlanBaselinesRegex.MatchString(query) {
+		return plan.NewShowPlanBaselines(), nil
+	}
+
+	if purgePlanBaselinesRegex.MatchString(query) {
+		return plan.NewPurgePlanBaselines(""), nil
+	}
+
+	if match := purgePlanBaselineRegex.FindStringSubmatch(query); match != nil {
+		if match[1] == "" {
+			return plan.NewPurgePlanBaselines(strings.ToLower(match[2] + match[3])), nil
+		}
+		if _, err := parsePinnedStatement(ctx, match[1]); err != nil {
+			return nil, err
+		}
+		digest, _ := Digest(match[1])
+		return plan.NewPurgePlanBaselines(digest), nil
+	}
+
+	return nil, ErrUnsupportedSyntax.New(query)
+}
+
+// parsePinnedStatement parses a statement whose plan is pinned, which must be a single SELECT statement, whose hints
+// are those of its only outermost query block.
+func parsePinnedStatement(ctx *sql.Context, query string) (sql.Node, error) {
+	if !selectStatementRegex.MatchString(query) {
+		return nil, sql.ErrPlanBaselineStatement.New(firstWord(query))
+	}
+
+	n, err := Parse(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := n.(*plan.Union); ok {
+		return nil, sql.ErrPlanBaselineStatement.New("UNION")
+	}
+	return n, nil
+}
+
+// firstWord returns the first word of the query given in upper case, which is the statement it is.
+func firstWord(query string) string {
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return query
+}
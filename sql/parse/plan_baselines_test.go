@@ -0,0 +1,79 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParsePlanBaseline(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	statement, err := Parse(ctx, "SELECT * FROM t WHERE i = 1")
+	require.NoError(t, err)
+	digest, text := Digest("SELECT * FROM t WHERE i = 1")
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"PIN PLAN BASELINE FOR SELECT * FROM t WHERE i = 1",
+			plan.NewPinPlanBaseline(digest, text, statement),
+		},
+		{
+			"pin plan baseline for\n  SELECT * FROM t WHERE i = 1",
+			plan.NewPinPlanBaseline(digest, text, statement),
+		},
+		{
+			"SHOW PLAN BASELINES",
+			plan.NewShowPlanBaselines(),
+		},
+		{
+			"PURGE PLAN BASELINE FOR SELECT * FROM t WHERE i = 2",
+			plan.NewPurgePlanBaselines(digest),
+		},
+		{
+			"PURGE PLAN BASELINE '0A1B'",
+			plan.NewPurgePlanBaselines("0a1b"),
+		},
+		{
+			`purge plan baseline "0a1b"`,
+			plan.NewPurgePlanBaselines("0a1b"),
+		},
+		{
+			"PURGE PLAN BASELINES",
+			plan.NewPurgePlanBaselines(""),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	errorCases := []struct {
+		query string
+		err   *errors.Kind
+	}{
+		{"PIN PLAN BASELINE FOR DELETE FROM t", sql.ErrPlanBaselineStatement},
+		{"PIN PLAN BASELINE FOR SELECT * FROM t UNION SELECT * FROM u", sql.ErrPlanBaselineStatement},
+		{"PURGE PLAN BASELINE FOR INSERT INTO t VALUES (1)", sql.ErrPlanBaselineStatement},
+		{"PURGE PLAN BASELINE 'xyz'", ErrUnsupportedSyntax},
+		{"SHOW PLAN BASELINE", ErrUnsupportedSyntax},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Error(t, err)
+			require.True(t, tt.err.Is(err), err.Error())
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+const resourceGroupName = "(`[^`]+`|\\w+)"
+
+// maxVCPUID is the highest VCPU id that's parsed, so that ranges of ids don't take unbounded memory. The ids of the
+// CPUs the machine doesn't have are rejected when groups are created.
+const maxVCPUID = 1 << 16
+
+var (
+	createResourceGroupRegex = regexp.MustCompile(`(?is)^create\s+resource\s+group\s+` + resourceGroupName + `\s+type\s*=?\s*(system|user)\b(.*)$`)
+	alterResourceGroupRegex  = regexp.MustCompile(`(?is)^alter\s+resource\s+group\s+` + resourceGroupName + `(.*)$`)
+	dropResourceGroupRegex   = regexp.MustCompile(`(?is)^drop\s+resource\s+group\s+` + resourceGroupName + `(\s+force)?$`)
+	setResourceGroupRegex    = regexp.MustCompile(`(?is)^set\s+resource\s+group\s+` + resourceGroupName + `(\s+for\s+(\d+(\s*,\s*\d+)*))?$`)
+
+	resourceGroupAttributeRegex = regexp.MustCompile(`(?is)^\s*(?:vcpu\s*=?\s*(\d+(?:\s*-\s*\d+)?(?:\s*,?\s*\d+(?:\s*-\s*\d+)?)*)|thread_priority\s*=?\s*([-+]?\d+)|(enable|disable)\b|(force)\b)`)
+	vcpuSpecRegex               = regexp.MustCompile(`(\d+)(?:\s*-\s*(\d+))?`)
+)
+
+// resourceGroupAttributes are the attributes of CREATE RESOURCE GROUP and ALTER RESOURCE GROUP after the type.
+type resourceGroupAttributes struct {
+	vcpus          []int
+	threadPriority *int
+	enabled        *bool
+	force          bool
+}
+
+// parseResourceGroup parses the resource group statements, which the vitess parser doesn't support: CREATE, ALTER and
+// DROP RESOURCE GROUP, and SET RESOURCE GROUP.
+func parseResourceGroup(ctx *sql.Context, query string) (sql.Node, error) {
+	if match := createResourceGroupRegex.FindStringSubmatch(query); match != nil {
+		attrs, err := parseResourceGroupAttributes(match[3])
+		if err != nil {
+			return nil, err
+		}
+		if attrs.force {
+			return nil, errUnexpectedSyntax.New("VCPU, THREAD_PRIORITY, ENABLE or DISABLE", "FORCE")
+		}
+
+		group := sql.ResourceGroup{Name: unquoteResourceGroupName(match[1]), VCPUs: attrs.vcpus, Enabled: true}
+		if strings.EqualFold(match[2], "system") {
+			group.Type = sql.ResourceGroupSystem
+		}
+		if attrs.threadPriority != nil {
+			group.ThreadPriority = *attrs.threadPriority
+		}
+		if attrs.enabled != nil {
+			group.Enabled = *attrs.enabled
+		}
+		return plan.NewCreateResourceGroup(group), nil
+	}
+
+	if match := alterResourceGroupRegex.FindStringSubmatch(query); match != nil {
+		attrs, err := parseResourceGroupAttributes(match[2])
+		if err != nil {
+			return nil, err
+		}
+		return plan.NewAlterResourceGroup(
+			unquoteResourceGroupName(match[1]),
+			attrs.vcpus,
+			attrs.threadPriority,
+			attrs.enabled,
+			attrs.force,
+		), nil
+	}
+
+	if match := dropResourceGroupRegex.FindStringSubmatch(query); match != nil {
+		return plan.NewDropResourceGroup(unquoteResourceGroupName(match[1]), match[2] != ""), nil
+	}
+
+	if match := setResourceGroupRegex.FindStringSubmatch(query); match != nil {
+		var ids []uint32
+		if match[3] != "" {
+			for _, s := range strings.Split(match[3], ",") {
+				id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, uint32(id))
+			}
+		}
+		return plan.NewSetResourceGroup(unquoteResourceGroupName(match[1]), ids), nil
+	}
+
+	return nil, ErrUnsupportedSyntax.New(query)
+}
+
+func parseResourceGroupAttributes(s string) (resourceGroupAttributes, error) {
+	var attrs resourceGroupAttributes
+	for strings.TrimSpace(s) != "" {
+		match := resourceGroupAttributeRegex.FindStringSubmatch(s)
+		if match == nil {
+			return attrs, errUnexpectedSyntax.New("VCPU, THREAD_PRIORITY, ENABLE, DISABLE or FORCE", strings.TrimSpace(s))
+		}
+		s = s[len(match[0]):]
+
+		switch {
+		case match[1] != "":
+			vcpus, err := parseVCPUs(match[1])
+			if err != nil {
+				return attrs, err
+			}
+			attrs.vcpus = vcpus
+		case match[2] != "":
+			priority, err := strconv.Atoi(match[2])
+			if err != nil {
+				return attrs, err
+			}
+			attrs.threadPriority = &priority
+		case match[3] != "":
+			enabled := strings.EqualFold(match[3], "enable")
+			attrs.enabled = &enabled
+		default:
+			attrs.force = true
+		}
+	}
+	return attrs, nil
+}
+
+// parseVCPUs parses a list of VCPU ids and ranges of ids, such as 0-3,6, returning the sorted ids without
+// duplicates.
+func parseVCPUs(s string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, spec := range vcpuSpecRegex.FindAllStringSubmatch(s, -1) {
+		start, err := strconv.Atoi(spec[1])
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if spec[2] != "" {
+			if end, err = strconv.Atoi(spec[2]); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			return nil, sql.ErrInvalidVCPURange.New(start, end)
+		}
+		if end > maxVCPUID {
+			return nil, sql.ErrInvalidVCPUID.New(end)
+		}
+		for id := start; id <= end; id++ {
+			seen[id] = true
+		}
+	}
+
+	var ids []int
+	for id := 0; len(ids) < len(seen); id++ {
+		if seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func unquoteResourceGroupName(name string) string {
+	return strings.Trim(name, "`")
+}
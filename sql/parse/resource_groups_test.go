@@ -0,0 +1,89 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseResourceGroup(t *testing.T) {
+	priority, enabled, disabled := 5, true, false
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"CREATE RESOURCE GROUP batch TYPE = USER",
+			plan.NewCreateResourceGroup(sql.ResourceGroup{Name: "batch", Type: sql.ResourceGroupUser, Enabled: true}),
+		},
+		{
+			"create resource group `Batch Jobs` type system vcpu = 3, 0-1 thread_priority = -5 disable",
+			plan.NewCreateResourceGroup(sql.ResourceGroup{
+				Name:           "Batch Jobs",
+				Type:           sql.ResourceGroupSystem,
+				VCPUs:          []int{0, 1, 3},
+				ThreadPriority: -5,
+			}),
+		},
+		{
+			"ALTER RESOURCE GROUP batch VCPU = 2 3 THREAD_PRIORITY = 5",
+			plan.NewAlterResourceGroup("batch", []int{2, 3}, &priority, nil, false),
+		},
+		{
+			"ALTER RESOURCE GROUP batch ENABLE",
+			plan.NewAlterResourceGroup("batch", nil, nil, &enabled, false),
+		},
+		{
+			"ALTER RESOURCE GROUP batch DISABLE FORCE",
+			plan.NewAlterResourceGroup("batch", nil, nil, &disabled, true),
+		},
+		{
+			"DROP RESOURCE GROUP batch",
+			plan.NewDropResourceGroup("batch", false),
+		},
+		{
+			"DROP RESOURCE GROUP batch FORCE",
+			plan.NewDropResourceGroup("batch", true),
+		},
+		{
+			"SET RESOURCE GROUP batch",
+			plan.NewSetResourceGroup("batch", nil),
+		},
+		{
+			"SET RESOURCE GROUP batch FOR 1, 12",
+			plan.NewSetResourceGroup("batch", []uint32{1, 12}),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	errorCases := []struct {
+		query string
+		err   *errors.Kind
+	}{
+		{"CREATE RESOURCE GROUP batch TYPE = USER FORCE", errUnexpectedSyntax},
+		{"CREATE RESOURCE GROUP batch TYPE = USER PRIORITY = 1", errUnexpectedSyntax},
+		{"CREATE RESOURCE GROUP batch TYPE = USER VCPU = 3-1", sql.ErrInvalidVCPURange},
+		{"CREATE RESOURCE GROUP batch", ErrUnsupportedSyntax},
+		{"SET RESOURCE GROUP batch FOR", ErrUnsupportedSyntax},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Error(t, err)
+			require.True(t, tt.err.Is(err), err.Error())
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package parse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// scriptVersion is the version of MySQL the conditional comments of scripts are run for, which is the one VERSION()
+// returns, as a number like the ones of the comments.
+const scriptVersion = 80011
+
+// ScriptScanner reads the statements of a script in the format the mysql client runs, such as a dump of mysqldump,
+// one at a time. Comments are left out of the statements, except for the conditional comments, such as
+// /*!40101 SET NAMES utf8mb4 */, whose text is part of the statements if their version isn't newer than the one of
+// the engine. DELIMITER lines change the delimiter of the statements that follow, which is ; at first.
+//
+// Like bufio.Scanner, Scan reads the next statement, which Statement returns, until there are no more statements or
+// reading fails, in which case Err returns the error.
+type ScriptScanner struct {
+	r         *bufio.Reader
+	delimiter string
+	line      int
+	stmt      string
+	stmtLine  int
+	err       error
+}
+
+// NewScriptScanner returns a scanner of the statements of the script read from the reader given.
+func NewScriptScanner(r io.Reader) *ScriptScanner {
+	return &ScriptScanner{r: bufio.NewReaderSize(r, 64*1024), delimiter: ";", line: 1}
+}
+
+// Statement returns the statement read by the last call to Scan, without its delimiter.
+func (s *ScriptScanner) Statement() string {
+	return s.stmt
+}
+
+// Line returns the line of the script the statement read by the last call to Scan starts on.
+func (s *ScriptScanner) Line() int {
+	return s.stmtLine
+}
+
+// Err returns the error reading the script, if Scan stopped because it failed.
+func (s *ScriptScanner) Err() error {
+	return s.err
+}
+
+// Scan reads the next statement of the script, returning false when there are no more statements.
+func (s *ScriptScanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	var quote byte
+	// conditional is whether the scanner is in a conditional comment whose text is part of the statement.
+	var conditional bool
+	s.stmtLine = 0
+
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			s.stmt = strings.TrimSpace(buf.String())
+			return s.stmt != ""
+		}
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		if quote != 0 {
+			buf.WriteByte(c)
+			if c == '\\' && quote != '`' {
+				if c, err = s.readByte(); err == nil {
+					buf.WriteByte(c)
+				}
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		if s.stmtLine == 0 {
+			if isSpace(c) {
+				continue
+			}
+			if (c == 'd' || c == 'D') && s.peekDelimiterCommand() {
+				line, err := s.readLine()
+				if err != nil && err != io.EOF {
+					s.err = err
+					return false
+				}
+				if fields := strings.Fields(line); len(fields) > 1 {
+					s.delimiter = fields[1]
+				}
+				continue
+			}
+		}
+
+		switch {
+		case c == '#' || (c == '-' && s.peekLineComment()):
+			if _, err := s.readLine(); err != nil && err != io.EOF {
+				s.err = err
+				return false
+			}
+			buf.WriteByte('\n')
+			continue
+		case c == '/' && s.peek("*"):
+			_, _ = s.readByte()
+			if s.peek("!") {
+				_, _ = s.readByte()
+				if s.readVersion() <= scriptVersion {
+					conditional = true
+					buf.WriteByte(' ')
+					continue
+				}
+			}
+			if err := s.skipComment(); err != nil {
+				s.err = err
+				return false
+			}
+			buf.WriteByte(' ')
+			continue
+		case conditional && c == '*' && s.peek("/"):
+			_, _ = s.readByte()
+			conditional = false
+			buf.WriteByte(' ')
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		}
+
+		if s.stmtLine == 0 {
+			s.stmtLine = s.line
+		}
+		buf.WriteByte(c)
+
+		if c == s.delimiter[len(s.delimiter)-1] && bytes.HasSuffix(buf.Bytes(), []byte(s.delimiter)) {
+			s.stmt = strings.TrimSpace(string(buf.Bytes()[:buf.Len()-len(s.delimiter)]))
+			if s.stmt != "" {
+				return true
+			}
+			buf.Reset()
+			s.stmtLine = 0
+		}
+	}
+}
+
+func (s *ScriptScanner) readByte() (byte, error) {
+	c, err := s.r.ReadByte()
+	if c == '\n' && err == nil {
+		s.line++
+	}
+	return c, err
+}
+
+// readLine reads the rest of the current line, including its line break.
+func (s *ScriptScanner) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if strings.HasSuffix(line, "\n") {
+		s.line++
+	}
+	return line, err
+}
+
+func (s *ScriptScanner) peek(prefix string) bool {
+	peeked, _ := s.r.Peek(len(prefix))
+	return string(peeked) == prefix
+}
+
+// peekLineComment returns whether the - just read starts a comment, which -- followed by a whitespace or control
+// character, or by the end of the script, does.
+func (s *ScriptScanner) peekLineComment() bool {
+	peeked, _ := s.r.Peek(2)
+	return len(peeked) >= 1 && peeked[0] == '-' && (len(peeked) == 1 || peeked[1] <= ' ')
+}
+
+// peekDelimiterCommand returns whether the d or D just read starts a DELIMITER command.
+func (s *ScriptScanner) peekDelimiterCommand() bool {
+	peeked, _ := s.r.Peek(len("ELIMITER "))
+	return len(peeked) == len("ELIMITER ") && strings.EqualFold(string(peeked[:8]), "ELIMITER") && isSpace(peeked[8])
+}
+
+// readVersion reads the version of a conditional comment, returning 0 if it has none.
+func (s *ScriptScanner) readVersion() int {
+	var version int
+	for i := 0; i < 6; i++ {
+		peeked, _ := s.r.Peek(1)
+		if len(peeked) == 0 || peeked[0] < '0' || peeked[0] > '9' {
+			break
+		}
+		version = version*10 + int(peeked[0]-'0')
+		_, _ = s.readByte()
+	}
+	return version
+}
+
+// skipComment reads the rest of a comment, up to and including the */ that ends it.
+func (s *ScriptScanner) skipComment() error {
+	var prev byte
+	for {
+		c, err := s.readByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if prev == '*' && c == '/' {
+			return nil
+		}
+		prev = c
+	}
+}
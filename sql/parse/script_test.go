@@ -0,0 +1,42 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptScanner(t *testing.T) {
+	script := "-- MySQL dump 10.13\n" +
+		"--\n" +
+		"/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;\n" +
+		"/*!90000 SET future = 1 */;\n" +
+		"/* a comment; with a delimiter */\n" +
+		"# another comment\n" +
+		"CREATE TABLE `t;` (\n  `i` int -- a column\n);\n" +
+		"INSERT INTO `t;` VALUES (1,'a;\\'b'),(2,\"c;\"\"d\");;\n" +
+		"DELIMITER ;;\n" +
+		"/*!50003 CREATE*/ /*!50017 DEFINER=`root`@`localhost`*/ /*!50003 TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.i = 1; END */;;\n" +
+		"delimiter ;\n" +
+		"SELECT 1--1;\n" +
+		"SELECT 2"
+
+	scanner := NewScriptScanner(strings.NewReader(script))
+	var stmts []string
+	var lines []int
+	for scanner.Scan() {
+		stmts = append(stmts, scanner.Statement())
+		lines = append(lines, scanner.Line())
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{
+		"SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT",
+		"CREATE TABLE `t;` (\n  `i` int \n)",
+		"INSERT INTO `t;` VALUES (1,'a;\\'b'),(2,\"c;\"\"d\")",
+		"CREATE    DEFINER=`root`@`localhost`    TRIGGER trg BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.i = 1; END",
+		"SELECT 1--1",
+		"SELECT 2",
+	}, stmts)
+	require.Equal(t, []int{3, 7, 10, 12, 14, 15}, lines)
+}
@@ -0,0 +1,133 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	errDuplicateSignalInfo   = errors.NewKind("Duplicate condition information item '%s'")
+	errGetStackedDiagnostics = errors.NewKind("GET STACKED DIAGNOSTICS when handler not active")
+)
+
+var (
+	signalStatementRegex         = regexp.MustCompile(`(?is)^(signal|resignal)(\s+sqlstate(\s+value)?\s*('([^']*)'|"([^"]*)"))?(\s+set\s+(.*))?$`)
+	getDiagnosticsStatementRegex = regexp.MustCompile(`(?is)^get\s+((current|stacked)\s+)?diagnostics\s+(condition\s+(\S+)\s+)?(.*)$`)
+)
+
+// diagnosticsStatementItems are the statement information items of GET DIAGNOSTICS.
+var diagnosticsStatementItems = []string{plan.DiagnosticsNumber, plan.DiagnosticsRowCount}
+
+// parseSignal parses the SIGNAL and RESIGNAL statements, which the vitess parser doesn't support. Conditions can only
+// be given by their SQLSTATE, since named conditions can't be declared.
+func parseSignal(ctx *sql.Context, query string) (sql.Node, error) {
+	match := signalStatementRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+
+	resignal := strings.ToLower(match[1]) == "resignal"
+	sqlState := match[5] + match[6]
+	if match[2] == "" && !resignal {
+		return nil, errUnexpectedSyntax.New("SQLSTATE", "EOF")
+	}
+
+	var info []plan.SignalInfo
+	if match[7] != "" {
+		exprs, err := parseAssignments(match[8])
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		for _, e := range exprs {
+			name := strings.ToUpper(e.Name.Name.String())
+			if e.Name.Qualifier.Name.String() != "" || !stringContains(plan.SignalInfoItems, name) {
+				return nil, errUnexpectedSyntax.New("a condition information item", sqlparser.String(e.Name))
+			}
+			if seen[name] {
+				return nil, errDuplicateSignalInfo.New(name)
+			}
+			seen[name] = true
+
+			value, err := exprToExpression(ctx, e.Expr)
+			if err != nil {
+				return nil, err
+			}
+			info = append(info, plan.SignalInfo{Name: name, Value: value})
+		}
+	}
+
+	if resignal {
+		return plan.NewResignal(sqlState, info), nil
+	}
+	return plan.NewSignal(sqlState, info), nil
+}
+
+// parseGetDiagnostics parses the GET DIAGNOSTICS statement, which the vitess parser doesn't support. Since there are
+// no condition handlers, GET STACKED DIAGNOSTICS is always an error.
+func parseGetDiagnostics(ctx *sql.Context, query string) (sql.Node, error) {
+	match := getDiagnosticsStatementRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+	if strings.ToLower(match[2]) == "stacked" {
+		return nil, errGetStackedDiagnostics.New()
+	}
+
+	var condition sql.Expression
+	allowed := diagnosticsStatementItems
+	if match[3] != "" {
+		var err error
+		condition, err = parseExpr(ctx, match[4])
+		if err != nil {
+			return nil, err
+		}
+		allowed = append(append([]string(nil), plan.SignalInfoItems...), plan.ConditionReturnedSQLState)
+	}
+
+	exprs, err := parseAssignments(match[5])
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*expression.UserVar
+	var items []string
+	for _, e := range exprs {
+		target := e.Name.Name.String()
+		if e.Name.Qualifier.Name.String() != "" || !strings.HasPrefix(target, "@") || strings.HasPrefix(target, "@@") {
+			return nil, errUnexpectedSyntax.New("a user variable", sqlparser.String(e.Name))
+		}
+
+		col, ok := e.Expr.(*sqlparser.ColName)
+		if !ok || col.Qualifier.Name.String() != "" || !stringContains(allowed, strings.ToUpper(col.Name.String())) {
+			return nil, errUnexpectedSyntax.New("one of: "+strings.Join(allowed, ", "), sqlparser.String(e.Expr))
+		}
+
+		targets = append(targets, expression.NewUserVar(strings.TrimPrefix(target, "@")))
+		items = append(items, strings.ToUpper(col.Name.String()))
+	}
+
+	return plan.NewGetDiagnostics(condition, targets, items), nil
+}
+
+// parseAssignments parses a comma-separated list of assignments of expressions to names, like the ones of SET.
+func parseAssignments(str string) (sqlparser.SetExprs, error) {
+	stmt, err := sqlparser.Parse("SET " + str)
+	if err != nil {
+		return nil, err
+	}
+
+	set, ok := stmt.(*sqlparser.Set)
+	if !ok || set.Scope != "" {
+		return nil, ErrUnsupportedSyntax.New(str)
+	}
+	return set.Exprs, nil
+}
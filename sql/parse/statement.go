@@ -0,0 +1,267 @@
+package parse
+
+import (
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Statement is a query parsed but not analyzed yet, with the positions of its literals and table references. It
+// gives linters, access control checks and rewriters the same view of the query the engine has, without analyzing
+// it against a catalog.
+type Statement struct {
+	// Query is the text of the statement, as given to ParseStatement.
+	Query string
+	// Node is the plan of the statement before analysis, so tables, columns and functions are unresolved.
+	Node sql.Node
+	// Literals are the literals in the query, in the order they appear.
+	Literals []Literal
+	// Tables are the references to tables in the query, in the order they appear.
+	Tables []TableReference
+}
+
+// Span is a range of bytes of a query, from Start up to, but not including, End.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Literal is a literal in a query, such as a string, a number, or NULL.
+type Literal struct {
+	Span
+	// Text is the literal as written in the query.
+	Text string
+}
+
+// TableReference is a reference to a table in a query, such as the tables in FROM and JOIN clauses, and the targets of
+// INSERT, UPDATE and DELETE statements and of table statements like DROP TABLE.
+type TableReference struct {
+	// Span covers the name of the table, including its database if the reference is qualified.
+	Span
+	// Database is the database of the reference, or empty if it isn't qualified.
+	Database string
+	// Name is the name of the table, without the quotes it may have in the query.
+	Name string
+}
+
+// ParseStatement parses the query given like Parse does, and returns it along with the positions of its literals and
+// table references.
+func ParseStatement(ctx *sql.Context, query string) (*Statement, error) {
+	node, err := Parse(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := tokenize(query)
+	return &Statement{
+		Query:    query,
+		Node:     node,
+		Literals: literals(query, tokens),
+		Tables:   tableReferences(tokens),
+	}, nil
+}
+
+type token struct {
+	Span
+	typ int
+	val string
+}
+
+// tokenize splits the query in its tokens, leaving out comments. It stops at the first token the tokenizer can't
+// read, which only happens for queries handled by custom statement parsers.
+func tokenize(query string) []token {
+	tokenizer := sqlparser.NewStringTokenizer(query)
+	tokenizer.SkipSpecialComments = true
+
+	var tokens []token
+	end := 0
+	for {
+		typ, val := tokenizer.Scan()
+		if typ == 0 || typ == sqlparser.LEX_ERROR {
+			return tokens
+		}
+
+		start := end
+		for start < len(query) && strings.IndexByte(" \t\r\n", query[start]) >= 0 {
+			start++
+		}
+		end = tokenizer.Position - 1
+		if end > len(query) {
+			end = len(query)
+		}
+
+		if typ != sqlparser.COMMENT {
+			tokens = append(tokens, token{Span{start, end}, typ, string(val)})
+		}
+	}
+}
+
+func literals(query string, tokens []token) []Literal {
+	var result []Literal
+	for _, t := range tokens {
+		if isLiteral(t.typ) {
+			result = append(result, Literal{t.Span, query[t.Start:t.End]})
+		}
+	}
+	return result
+}
+
+func isLiteral(typ int) bool {
+	switch typ {
+	case sqlparser.STRING, sqlparser.INTEGRAL, sqlparser.FLOAT, sqlparser.HEXNUM, sqlparser.HEX,
+		sqlparser.BIT_LITERAL, sqlparser.NULL, sqlparser.TRUE, sqlparser.FALSE:
+		return true
+	default:
+		return false
+	}
+}
+
+// tableReferences finds the references to tables in the tokens of a query: the names that follow the keywords that
+// introduce tables, like FROM, JOIN and INTO, and the rest of the names in lists of tables.
+func tableReferences(tokens []token) []TableReference {
+	var refs []TableReference
+	for i := 0; i < len(tokens); i++ {
+		if !introducesTables(tokens, i) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(tokens) && (tokens[j].typ == sqlparser.IGNORE || strings.EqualFold(tokens[j].val, "low_priority")) {
+			j++
+		}
+		if j < len(tokens) && tokens[j].typ == sqlparser.IF {
+			j++
+			if j < len(tokens) && tokens[j].typ == sqlparser.NOT {
+				j++
+			}
+			if j < len(tokens) && tokens[j].typ == sqlparser.EXISTS {
+				j++
+			}
+		}
+
+		for {
+			ref, next, ok := readTableName(tokens, j)
+			if !ok || isTableFunction(tokens, i, next) {
+				break
+			}
+			refs = append(refs, ref)
+			j = next
+
+			if !isTableList(tokens, i) {
+				break
+			}
+			j = skipTableModifiers(tokens, j)
+			if j >= len(tokens) || tokens[j].typ != ',' {
+				break
+			}
+			j++
+		}
+		i = j - 1
+	}
+	return refs
+}
+
+// introducesTables returns whether the token at i is a keyword followed by table names.
+func introducesTables(tokens []token, i int) bool {
+	switch tokens[i].typ {
+	case sqlparser.JOIN, sqlparser.STRAIGHT_JOIN, sqlparser.INTO, sqlparser.UPDATE, sqlparser.TABLE,
+		sqlparser.TRUNCATE:
+		return true
+	case sqlparser.FROM, sqlparser.IN:
+		if tokens[0].typ != sqlparser.SHOW {
+			return tokens[i].typ == sqlparser.FROM
+		}
+		// Only in SHOW COLUMNS and SHOW INDEX these keywords are followed by a table, and not by a database.
+		return i > 0 && isShowOfTable(tokens[i-1].typ)
+	case sqlparser.TABLES:
+		return tokens[0].typ == sqlparser.LOCK
+	case sqlparser.DESCRIBE, sqlparser.DESC, sqlparser.EXPLAIN:
+		return i == 0
+	default:
+		return false
+	}
+}
+
+func isShowOfTable(typ int) bool {
+	switch typ {
+	case sqlparser.COLUMNS, sqlparser.FIELDS, sqlparser.INDEX, sqlparser.INDEXES, sqlparser.KEYS:
+		return true
+	default:
+		return false
+	}
+}
+
+// isTableList returns whether the keyword at i is followed by a list of tables separated by commas.
+func isTableList(tokens []token, i int) bool {
+	switch tokens[i].typ {
+	case sqlparser.FROM, sqlparser.TABLE, sqlparser.TABLES:
+		return tokens[0].typ != sqlparser.SHOW
+	default:
+		return false
+	}
+}
+
+// skipTableModifiers skips the alias and the lock type that may follow a table in a list of tables.
+func skipTableModifiers(tokens []token, j int) int {
+	if j < len(tokens) && tokens[j].typ == sqlparser.AS {
+		j++
+	}
+	if j < len(tokens) && tokens[j].typ == sqlparser.ID {
+		j++
+	}
+	for j < len(tokens) && (tokens[j].typ == sqlparser.READ || tokens[j].typ == sqlparser.WRITE ||
+		strings.EqualFold(tokens[j].val, "local") || strings.EqualFold(tokens[j].val, "low_priority")) {
+		j++
+	}
+	return j
+}
+
+// readTableName reads the possibly qualified table name at j, returning the index of the token after it.
+func readTableName(tokens []token, j int) (TableReference, int, bool) {
+	if j >= len(tokens) || !isTableName(tokens[j]) {
+		return TableReference{}, j, false
+	}
+
+	ref := TableReference{Span: tokens[j].Span, Name: tokens[j].val}
+	j++
+	if j+1 < len(tokens) && tokens[j].typ == '.' && isTableName(tokens[j+1]) {
+		ref.Database, ref.Name = ref.Name, tokens[j+1].val
+		ref.End = tokens[j+1].End
+		j += 2
+	}
+	return ref, j, true
+}
+
+// isTableFunction returns whether the name before the token at j, introduced by the keyword at i, is a call to a
+// table function rather than a table.
+func isTableFunction(tokens []token, i, j int) bool {
+	switch tokens[i].typ {
+	case sqlparser.FROM, sqlparser.JOIN, sqlparser.STRAIGHT_JOIN:
+		return j < len(tokens) && tokens[j].typ == '('
+	default:
+		return false
+	}
+}
+
+// isTableName returns whether the token can be the name of a table: an identifier or a non-structural keyword, since
+// tables can be named like many keywords. Variables and the DUAL table aren't tables.
+func isTableName(t token) bool {
+	if t.val == "" || strings.HasPrefix(t.val, "@") || strings.EqualFold(t.val, "dual") {
+		return false
+	}
+	if t.typ == sqlparser.ID {
+		return true
+	}
+
+	switch t.typ {
+	case sqlparser.SELECT, sqlparser.IF, sqlparser.STATUS, sqlparser.IGNORE, sqlparser.TABLE, sqlparser.NOT,
+		sqlparser.EXISTS, sqlparser.AS, sqlparser.FROM, sqlparser.IN, sqlparser.JOIN, sqlparser.STRAIGHT_JOIN,
+		sqlparser.READ, sqlparser.WRITE, sqlparser.VALUE_ARG, sqlparser.STRING, sqlparser.INTEGRAL, sqlparser.FLOAT,
+		sqlparser.HEXNUM, sqlparser.HEX, sqlparser.BIT_LITERAL, sqlparser.NULL, sqlparser.TRUE, sqlparser.FALSE:
+		return false
+	default:
+		return sqlparser.KeywordString(t.typ) != ""
+	}
+}
@@ -0,0 +1,105 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseStatement(t *testing.T) {
+	testCases := []struct {
+		query    string
+		literals []string
+		tables   []string
+	}{
+		{
+			"SELECT a, 'x', 1.5 FROM db.t1 AS a JOIN `my table` b ON a.i = b.i WHERE c = 0x1F AND d IS NULL -- 2",
+			[]string{"'x'", "1.5", "0x1F", "NULL"},
+			[]string{"db.t1", "`my table`"},
+		},
+		{
+			"SELECT * FROM t1, t2 x, (SELECT 1e3 FROM t3) s, numbers(3) n",
+			[]string{"1e3", "3"},
+			[]string{"t1", "t2", "t3"},
+		},
+		{
+			`INSERT INTO t (a, b) VALUES (1, "two"), (X'03', TRUE)`,
+			[]string{"1", `"two"`, "X'03'", "TRUE"},
+			[]string{"t"},
+		},
+		{
+			"UPDATE IGNORE t SET a = b'01' WHERE /* 2 */ b = 3",
+			[]string{"b'01'", "3"},
+			[]string{"t"},
+		},
+		{
+			"DELETE FROM user WHERE status = 'x'",
+			[]string{"'x'"},
+			[]string{"user"},
+		},
+		{
+			"DROP TABLE IF EXISTS db.a, db.b",
+			nil,
+			[]string{"db.a", "db.b"},
+		},
+		{
+			"CREATE TABLE IF NOT EXISTS t (a int primary key)",
+			nil,
+			[]string{"t"},
+		},
+		{
+			"LOCK TABLES a READ, b AS x WRITE",
+			nil,
+			[]string{"a", "b"},
+		},
+		{"SHOW TABLES FROM db", nil, nil},
+		{"SHOW FULL COLUMNS FROM t IN db", nil, []string{"t"}},
+		{"DESCRIBE t", nil, []string{"t"}},
+		{"SELECT @@autocommit, 'a' FROM dual", []string{"'a'"}, nil},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			require := require.New(t)
+			stmt, err := ParseStatement(sql.NewEmptyContext(), tt.query)
+			require.NoError(err)
+
+			expected, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(err)
+			require.Equal(expected, stmt.Node)
+
+			var literals []string
+			for _, l := range stmt.Literals {
+				require.Equal(l.Text, tt.query[l.Start:l.End])
+				literals = append(literals, l.Text)
+			}
+			require.Equal(tt.literals, literals)
+
+			var tables []string
+			for _, r := range stmt.Tables {
+				tables = append(tables, tt.query[r.Start:r.End])
+			}
+			require.Equal(tt.tables, tables)
+		})
+	}
+}
+
+func TestParseStatementTableReference(t *testing.T) {
+	require := require.New(t)
+	stmt, err := ParseStatement(sql.NewEmptyContext(), "SELECT i FROM `my db`.`my table` WHERE i = 1")
+	require.NoError(err)
+
+	require.Equal(plan.NewProject(
+		[]sql.Expression{expression.NewUnresolvedColumn("i")},
+		plan.NewFilter(
+			expression.NewEquals(expression.NewUnresolvedColumn("i"), expression.NewLiteral(int8(1), sql.Int8)),
+			plan.NewUnresolvedTable("my table", "my db"),
+		),
+	), stmt.Node)
+	require.Equal([]TableReference{{Span: Span{14, 32}, Database: "my db", Name: "my table"}}, stmt.Tables)
+	require.Equal([]Literal{{Span: Span{43, 44}, Text: "1"}}, stmt.Literals)
+}
@@ -0,0 +1,215 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// tableFunctionPrefix is the prefix of the table names that stand for calls of table functions. The parser doesn't
+// support function calls in the FROM clause, so before parsing a query every call in a table position is replaced
+// with a quoted table name made of this prefix and the text of the call, which is parsed again when converting the
+// table.
+const tableFunctionPrefix = "__table_function__"
+
+// fromClauseEnd has the keywords after which the tables in a FROM clause are over.
+var fromClauseEnd = map[int]bool{
+	sqlparser.WHERE:     true,
+	sqlparser.GROUP:     true,
+	sqlparser.HAVING:    true,
+	sqlparser.ORDER:     true,
+	sqlparser.LIMIT:     true,
+	sqlparser.UNION:     true,
+	sqlparser.WINDOW:    true,
+	sqlparser.INTO:      true,
+	sqlparser.FOR:       true,
+	sqlparser.LOCK:      true,
+	sqlparser.PROCEDURE: true,
+	sqlparser.DUPLICATE: true,
+	sqlparser.SET:       true,
+}
+
+// tableScope is the state of the tokens of a query at one level of parentheses.
+type tableScope struct {
+	// inFrom is whether the tokens are in the tables of a FROM clause, where a comma is followed by a table.
+	inFrom bool
+	// expectTable is whether the next token is the start of a table.
+	expectTable bool
+}
+
+// rewriteTableFunctions returns the query given with every call of a function in the place of a table replaced
+// with a table name that stands for it. If there are no such calls, or the query can't be tokenized, the query is
+// returned as is, so the parser reports any error.
+func rewriteTableFunctions(query string) string {
+	tkn := newTokenScanner(query)
+	scopes := []tableScope{{}}
+
+	var sb strings.Builder
+	// written is the offset of the query up to which it's been written to sb
+	written := 0
+	scan := func() (int, int) {
+		typ, _, start := tkn.scan()
+		return typ, start
+	}
+
+	typ, start := scan()
+	for typ != 0 {
+		if typ == sqlparser.LEX_ERROR {
+			return query
+		}
+
+		scope := &scopes[len(scopes)-1]
+		switch {
+		case typ == sqlparser.ID && scope.expectTable:
+			scope.expectTable = false
+
+			next, nextStart := scan()
+			if next != '(' {
+				typ, start = next, nextStart
+				continue
+			}
+
+			depth := 1
+			for depth > 0 {
+				typ, _ = scan()
+				switch typ {
+				case 0, sqlparser.LEX_ERROR:
+					return query
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+			}
+
+			sb.WriteString(query[written:start])
+			sb.WriteString(tableFunctionName(query[start:tkn.end]))
+			written = tkn.end
+		case typ == sqlparser.SELECT:
+			*scope = tableScope{}
+		case typ == sqlparser.FROM:
+			*scope = tableScope{inFrom: true, expectTable: true}
+		case typ == sqlparser.JOIN || typ == sqlparser.STRAIGHT_JOIN:
+			scope.expectTable = true
+		case typ == ',':
+			scope.expectTable = scope.inFrom
+		case typ == '(':
+			// A parenthesized table can be a join of tables or a subquery, whose SELECT starts a new scope.
+			scopes = append(scopes, tableScope{inFrom: scope.expectTable, expectTable: scope.expectTable})
+			scope.expectTable = false
+		case typ == ')':
+			if len(scopes) > 1 {
+				scopes = scopes[:len(scopes)-1]
+			}
+		case fromClauseEnd[typ]:
+			*scope = tableScope{}
+		default:
+			scope.expectTable = false
+		}
+
+		typ, start = scan()
+	}
+
+	if written == 0 {
+		return query
+	}
+
+	sb.WriteString(query[written:])
+	return sb.String()
+}
+
+// tokenScanner scans the tokens of a query keeping track of their offsets.
+type tokenScanner struct {
+	tkn   *sqlparser.Tokenizer
+	query string
+	// end is the offset of the query right after the last token scanned
+	end int
+}
+
+func newTokenScanner(query string) *tokenScanner {
+	return &tokenScanner{tkn: sqlparser.NewStringTokenizer(query), query: query}
+}
+
+// scan returns the type and the value of the next token, and the offset of the query where it starts.
+func (s *tokenScanner) scan() (int, string, int) {
+	start := s.end
+	for start < len(s.query) && isSpace(s.query[start]) {
+		start++
+	}
+
+	typ, val := s.tkn.Scan()
+	s.end = s.tkn.Position - 1
+	if s.end > len(s.query) {
+		s.end = len(s.query)
+	}
+	return typ, string(val), start
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// tableFunctionName returns the quoted table name that stands for the call of a table function given.
+func tableFunctionName(call string) string {
+	return "`" + strings.Replace(tableFunctionPrefix+call, "`", "``", -1) + "`"
+}
+
+// tableFunctionNameRegex matches the quoted table names that stand for calls of table functions.
+var tableFunctionNameRegex = regexp.MustCompile("`" + tableFunctionPrefix + "((?:[^`]|``)*)`")
+
+// restoreTableFunctions returns the query given with the table names that stand for calls of table functions
+// replaced back with the calls, so the query reads as it was written.
+func restoreTableFunctions(query string) string {
+	return tableFunctionNameRegex.ReplaceAllStringFunc(query, func(name string) string {
+		return strings.Replace(strings.TrimPrefix(name[1:len(name)-1], tableFunctionPrefix), "``", "`", -1)
+	})
+}
+
+// isTableFunctionName returns whether the table name given stands for the call of a table function.
+func isTableFunctionName(name string) bool {
+	return strings.HasPrefix(name, tableFunctionPrefix)
+}
+
+// tableFunctionToUnresolvedTableFunction converts the table name that stands for the call of a table function to
+// the node of the call.
+func tableFunctionToUnresolvedTableFunction(ctx *sql.Context, name string) (*plan.UnresolvedTableFunction, error) {
+	call := strings.TrimPrefix(name, tableFunctionPrefix)
+	stmt, err := sqlparser.Parse("SELECT " + call)
+	if err != nil {
+		return nil, err
+	}
+
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return nil, ErrUnsupportedSyntax.New(call)
+	}
+
+	aliased, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil, ErrUnsupportedSyntax.New(call)
+	}
+
+	fn, ok := aliased.Expr.(*sqlparser.FuncExpr)
+	if !ok || fn.Distinct || !fn.Qualifier.IsEmpty() {
+		return nil, ErrUnsupportedSyntax.New(call)
+	}
+
+	args := make([]sql.Expression, len(fn.Exprs))
+	for i, e := range fn.Exprs {
+		arg, ok := e.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, ErrUnsupportedSyntax.New(call)
+		}
+
+		args[i], err = exprToExpression(ctx, arg.Expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.NewUnresolvedTableFunction(fn.Name.Lowered(), args), nil
+}
@@ -0,0 +1,182 @@
+package parse
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func parseChecksumTable(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var tables []sql.Node
+	var options []string
+	err := parseFuncs{
+		expect("checksum"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readMaintainedTables(&tables),
+		readMaintenanceOptions(&options, "quick", "extended"),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewChecksumTable(tables, stringContains(options, "quick"), stringContains(options, "extended")), nil
+}
+
+func parseCheckTable(ctx *sql.Context, query string) (sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var tables []sql.Node
+	var options []string
+	err := parseFuncs{
+		expect("check"),
+		skipSpaces,
+		expect("table"),
+		skipSpaces,
+		readMaintainedTables(&tables),
+		// The options only choose how thoroughly tables are checked, which is up to the tables
+		readMaintenanceOptions(&options, "for", "upgrade", "quick", "fast", "medium", "extended", "changed"),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewCheckTable(tables), nil
+}
+
+func parseOptimizeTable(ctx *sql.Context, query string) (sql.Node, error) {
+	tables, err := parseBinlogMaintenance("optimize", query)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewOptimizeTable(tables), nil
+}
+
+func parseAnalyzeTable(ctx *sql.Context, query string) (sql.Node, error) {
+	tables, err := parseBinlogMaintenance("analyze", query)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewAnalyzeTable(tables), nil
+}
+
+// parseBinlogMaintenance parses the tables of a table maintenance statement starting with the keyword given, which
+// can be followed by NO_WRITE_TO_BINLOG or LOCAL, like OPTIMIZE TABLE and ANALYZE TABLE.
+func parseBinlogMaintenance(keyword, query string) ([]sql.Node, error) {
+	var r = bufio.NewReader(strings.NewReader(query))
+	var tables []sql.Node
+	var ident string
+	err := parseFuncs{
+		expect(keyword),
+		skipSpaces,
+		readIdent(&ident),
+		skipSpaces,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// The statements aren't written to a binary log, so NO_WRITE_TO_BINLOG and LOCAL are ignored
+	if ident == "no_write_to_binlog" || ident == "local" {
+		if err := (parseFuncs{readIdent(&ident), skipSpaces}).exec(r); err != nil {
+			return nil, err
+		}
+	}
+	if ident != "table" && ident != "tables" {
+		return nil, errUnexpectedSyntax.New("table", ident)
+	}
+
+	err = parseFuncs{
+		readMaintainedTables(&tables),
+		checkEOF,
+	}.exec(r)
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// readMaintainedTables reads the comma-separated list of the tables of a table maintenance statement, whose names
+// can be qualified by the name of their database and quoted.
+func readMaintainedTables(tables *[]sql.Node) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			var db, name string
+			if err := readQuotableIdent(&name)(rd); err != nil {
+				return err
+			}
+
+			b, err := rd.Peek(1)
+			if err == nil && b[0] == '.' {
+				db = name
+				if err := (parseFuncs{expectRune('.'), readQuotableIdent(&name)}).exec(rd); err != nil {
+					return err
+				}
+			} else if err != nil && err != io.EOF {
+				return err
+			}
+
+			*tables = append(*tables, plan.NewUnresolvedTable(name, db))
+
+			if err := skipSpaces(rd); err != nil {
+				return err
+			}
+
+			r, _, err := rd.ReadRune()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if r != ',' {
+				return rd.UnreadRune()
+			}
+
+			if err := skipSpaces(rd); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readMaintenanceOptions reads the options of a table maintenance statement after its tables, which must be some of
+// the options given.
+func readMaintenanceOptions(options *[]string, allowed ...string) parseFunc {
+	return func(rd *bufio.Reader) error {
+		for {
+			if _, err := rd.Peek(1); err == io.EOF {
+				return nil
+			}
+
+			var option string
+			if err := readIdent(&option)(rd); err != nil {
+				return err
+			}
+			if !stringContains(allowed, option) {
+				return errUnexpectedSyntax.New(fmt.Sprintf("one of: %s", strings.Join(allowed, ", ")), option)
+			}
+			*options = append(*options, option)
+
+			if err := skipSpaces(rd); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func stringContains(strs []string, target string) bool {
+	for _, s := range strs {
+		if s == target {
+			return true
+		}
+	}
+	return false
+}
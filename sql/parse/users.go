@@ -0,0 +1,120 @@
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// maxPasswordOption is the highest value of FAILED_LOGIN_ATTEMPTS and PASSWORD_LOCK_TIME.
+const maxPasswordOption = 32767
+
+var (
+	alterUserStatementRegex = regexp.MustCompile(`(?is)^alter\s+user\s+(if\s+exists\s+)?(current_user(\s*\(\s*\))?|user\s*\(\s*\)|` + accountNamePart + `(\s*@\s*` + accountNamePart + `)?)(.*)$`)
+
+	alterUserOptionRegex = regexp.MustCompile(`(?is)^\s*(?:identified\s+(?:with\s+(\w+)\s+)?by\s+('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*")|(password\s+expire)\b|failed_login_attempts\s+(\d+)|password_lock_time\s+(\d+|unbounded)\b|account\s+(lock|unlock)\b)`)
+)
+
+// parseAlterUser parses ALTER USER for a single account, which the vitess parser doesn't support. Its options are
+// the new password, PASSWORD EXPIRE, the locking of the account after failed logins, and ACCOUNT LOCK and UNLOCK.
+// Passwords can only be checked with mysql_native_password.
+func parseAlterUser(ctx *sql.Context, query string) (sql.Node, error) {
+	match := alterUserStatementRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+
+	alteration, err := parseAlterUserOptions(match[7])
+	if err != nil {
+		return nil, err
+	}
+
+	ifExists := match[1] != ""
+	if match[4] == "" {
+		return plan.NewAlterUser("", "", ifExists, alteration), nil
+	}
+
+	host := "%"
+	if match[6] != "" {
+		host = unquoteAccountNamePart(match[6])
+	}
+	return plan.NewAlterUser(unquoteAccountNamePart(match[4]), host, ifExists, alteration), nil
+}
+
+func parseAlterUserOptions(s string) (sql.AccountAlteration, error) {
+	var alteration sql.AccountAlteration
+	for strings.TrimSpace(s) != "" {
+		match := alterUserOptionRegex.FindStringSubmatch(s)
+		if match == nil {
+			return alteration, errUnexpectedSyntax.New("IDENTIFIED, PASSWORD EXPIRE, FAILED_LOGIN_ATTEMPTS, PASSWORD_LOCK_TIME or ACCOUNT", strings.TrimSpace(s))
+		}
+		s = s[len(match[0]):]
+
+		switch {
+		case match[2] != "":
+			if match[1] != "" && !strings.EqualFold(match[1], "mysql_native_password") {
+				return alteration, ErrUnsupportedFeature.New("authentication plugin " + match[1])
+			}
+			password, err := parseStringLiteral(match[2])
+			if err != nil {
+				return alteration, err
+			}
+			alteration.Password = &password
+		case match[3] != "":
+			alteration.ExpirePassword = true
+		case match[4] != "":
+			attempts, err := parsePasswordOption(match[4])
+			if err != nil {
+				return alteration, err
+			}
+			alteration.FailedLoginAttempts = &attempts
+		case match[5] != "":
+			days := sql.UnboundedPasswordLockTime
+			if !strings.EqualFold(match[5], "unbounded") {
+				var err error
+				if days, err = parsePasswordOption(match[5]); err != nil {
+					return alteration, err
+				}
+			}
+			alteration.PasswordLockTime = &days
+		default:
+			locked := strings.EqualFold(match[6], "lock")
+			alteration.Locked = &locked
+		}
+	}
+	return alteration, nil
+}
+
+func parsePasswordOption(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n > maxPasswordOption {
+		return 0, errUnexpectedSyntax.New("a number from 0 to 32767", s)
+	}
+	return n, nil
+}
+
+// parseStringLiteral returns the value of a quoted string literal, with its escape sequences replaced.
+func parseStringLiteral(s string) (string, error) {
+	stmt, err := sqlparser.Parse("SELECT " + s)
+	if err != nil {
+		return "", errUnexpectedSyntax.New("a string", s)
+	}
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return "", errUnexpectedSyntax.New("a string", s)
+	}
+	ae, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return "", errUnexpectedSyntax.New("a string", s)
+	}
+	val, ok := ae.Expr.(*sqlparser.SQLVal)
+	if !ok || val.Type != sqlparser.StrVal {
+		return "", errUnexpectedSyntax.New("a string", s)
+	}
+	return string(val.Val), nil
+}
@@ -0,0 +1,86 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseAlterUser(t *testing.T) {
+	password, quoted := "s3cret", "it's"
+	locked, unlocked := true, false
+	attempts, days, unbounded := 3, 2, sql.UnboundedPasswordLockTime
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"ALTER USER 'joe'@'localhost' IDENTIFIED BY 's3cret'",
+			plan.NewAlterUser("joe", "localhost", false, sql.AccountAlteration{Password: &password}),
+		},
+		{
+			`alter user joe identified with mysql_native_password by "s3cret"`,
+			plan.NewAlterUser("joe", "%", false, sql.AccountAlteration{Password: &password}),
+		},
+		{
+			`ALTER USER USER() IDENTIFIED BY 'it\'s'`,
+			plan.NewAlterUser("", "", false, sql.AccountAlteration{Password: &quoted}),
+		},
+		{
+			"ALTER USER CURRENT_USER() IDENTIFIED BY 'it''s'",
+			plan.NewAlterUser("", "", false, sql.AccountAlteration{Password: &quoted}),
+		},
+		{
+			"ALTER USER IF EXISTS `joe` PASSWORD EXPIRE",
+			plan.NewAlterUser("joe", "%", true, sql.AccountAlteration{ExpirePassword: true}),
+		},
+		{
+			"ALTER USER joe ACCOUNT LOCK",
+			plan.NewAlterUser("joe", "%", false, sql.AccountAlteration{Locked: &locked}),
+		},
+		{
+			"ALTER USER joe FAILED_LOGIN_ATTEMPTS 3 PASSWORD_LOCK_TIME 2 ACCOUNT UNLOCK",
+			plan.NewAlterUser("joe", "%", false, sql.AccountAlteration{
+				FailedLoginAttempts: &attempts,
+				PasswordLockTime:    &days,
+				Locked:              &unlocked,
+			}),
+		},
+		{
+			"ALTER USER joe PASSWORD_LOCK_TIME UNBOUNDED",
+			plan.NewAlterUser("joe", "%", false, sql.AccountAlteration{PasswordLockTime: &unbounded}),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	errorCases := []struct {
+		query string
+		err   *errors.Kind
+	}{
+		{"ALTER USER joe IDENTIFIED BY s3cret", errUnexpectedSyntax},
+		{"ALTER USER joe IDENTIFIED WITH caching_sha2_password BY 's3cret'", ErrUnsupportedFeature},
+		{"ALTER USER joe PASSWORD EXPIRE NEVER", errUnexpectedSyntax},
+		{"ALTER USER joe FAILED_LOGIN_ATTEMPTS 40000", errUnexpectedSyntax},
+		{"ALTER USER joe, ann ACCOUNT LOCK", errUnexpectedSyntax},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Error(t, err)
+			require.True(t, tt.err.Is(err), "%v", err)
+		})
+	}
+}
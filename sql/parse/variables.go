@@ -9,7 +9,8 @@ import (
 )
 
 func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
-	var pattern string
+	var pattern, where string
+	var global bool
 
 	r := bufio.NewReader(strings.NewReader(s))
 	for _, fn := range []parseFunc{
@@ -23,6 +24,7 @@ func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 
 			switch s {
 			case "global", "session":
+				global = s == "global"
 				if err := skipSpaces(in); err != nil {
 					return err
 				}
@@ -35,14 +37,19 @@ func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 		},
 		skipSpaces,
 		func(in *bufio.Reader) error {
-			if expect("like")(in) == nil {
-				if err := skipSpaces(in); err != nil {
-					return err
-				}
+			var s string
+			if err := readIdent(&s)(in); err != nil {
+				return err
+			}
 
-				if err := readValue(&pattern)(in); err != nil {
+			switch s {
+			case "like":
+				if err := skipSpaces(in); err != nil {
 					return err
 				}
+				return readValue(&pattern)(in)
+			case "where":
+				return readRemaining(&where)(in)
 			}
 			return nil
 		},
@@ -54,5 +61,25 @@ func parseShowVariables(ctx *sql.Context, s string) (sql.Node, error) {
 		}
 	}
 
-	return plan.NewShowVariables(ctx.Session.GetAll(), pattern), nil
+	vars := ctx.GlobalVariables.All()
+	if !global {
+		// Sessions show their own values, except for the variables that only have a global value
+		for name, v := range ctx.Session.GetAll() {
+			if sysVar, ok := sql.GetSystemVariable(name); !ok || !sysVar.GlobalOnly {
+				vars[name] = v
+			}
+		}
+	}
+
+	show := plan.NewShowVariables(vars, pattern)
+	if where == "" {
+		return show, nil
+	}
+
+	// SHOW VARIABLES WHERE filters the rows by the Variable_name and Value columns, like a WHERE of a SELECT
+	filter, err := parseExpr(ctx, where)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewFilter(filter, show), nil
 }
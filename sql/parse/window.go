@@ -0,0 +1,229 @@
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// windowPrefix is the prefix of the collations that stand for the windows of window functions. The parser doesn't
+// support OVER clauses, so before parsing a query every OVER clause following a function call is replaced with a
+// COLLATE clause of a quoted collation made of this prefix and the text of the clause, which is parsed again when
+// converting the call.
+const windowPrefix = "__window__"
+
+// rewriteWindowFunctions returns the query given with the OVER clause of every call of a window function replaced
+// with a COLLATE clause that stands for it. If there are no such calls, or the query can't be tokenized, the query is
+// returned as is, so the parser reports any error.
+func rewriteWindowFunctions(query string) string {
+	tkn := newTokenScanner(query)
+
+	var sb strings.Builder
+	// written is the offset of the query up to which it's been written to sb
+	written := 0
+	prev := 0
+	typ, val, start := tkn.scan()
+	for typ != 0 {
+		if typ == sqlparser.LEX_ERROR {
+			return query
+		}
+
+		if prev != ')' || typ != sqlparser.ID || strings.ToLower(val) != "over" {
+			prev = typ
+			typ, val, start = tkn.scan()
+			continue
+		}
+
+		next, nextVal, nextStart := tkn.scan()
+		if next != '(' {
+			prev, typ, val, start = typ, next, nextVal, nextStart
+			continue
+		}
+
+		depth := 1
+		for depth > 0 {
+			typ, _, _ = tkn.scan()
+			switch typ {
+			case 0, sqlparser.LEX_ERROR:
+				return query
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+		}
+
+		sb.WriteString(query[written:start])
+		sb.WriteString(windowCollation(query[start:tkn.end]))
+		written = tkn.end
+
+		prev = typ
+		typ, val, start = tkn.scan()
+	}
+
+	if written == 0 {
+		return query
+	}
+
+	sb.WriteString(query[written:])
+	return sb.String()
+}
+
+// windowCollation returns the COLLATE clause that stands for the OVER clause given.
+func windowCollation(over string) string {
+	return "COLLATE `" + strings.Replace(windowPrefix+over, "`", "``", -1) + "`"
+}
+
+// windowCollationRegex matches the COLLATE clauses that stand for OVER clauses.
+var windowCollationRegex = regexp.MustCompile("COLLATE `" + windowPrefix + "((?:[^`]|``)*)`")
+
+// restoreWindowFunctions returns the query given with the COLLATE clauses that stand for OVER clauses replaced back
+// with the OVER clauses, so the query reads as it was written.
+func restoreWindowFunctions(query string) string {
+	return windowCollationRegex.ReplaceAllStringFunc(query, func(collation string) string {
+		over := strings.TrimPrefix(collation, "COLLATE `"+windowPrefix)
+		return strings.Replace(over[:len(over)-1], "``", "`", -1)
+	})
+}
+
+// restoreRewrites returns the query given with the table functions and the window functions rewritten before parsing
+// replaced back with their original text.
+func restoreRewrites(query string) string {
+	return restoreWindowFunctions(restoreTableFunctions(query))
+}
+
+// isWindowCollation returns whether the collation given stands for the OVER clause of a window function.
+func isWindowCollation(collation string) bool {
+	return strings.HasPrefix(collation, windowPrefix)
+}
+
+// windowFunctionToExpression converts the call of a function collated with the OVER clause of a window function to
+// the window function.
+func windowFunctionToExpression(ctx *sql.Context, e *sqlparser.CollateExpr) (sql.Expression, error) {
+	over := strings.TrimPrefix(e.Charset, windowPrefix)
+	fn, ok := e.Expr.(*sqlparser.FuncExpr)
+	if !ok || !fn.Qualifier.IsEmpty() {
+		return nil, ErrUnsupportedSyntax.New(sqlparser.String(e.Expr) + " " + over)
+	}
+
+	var function sql.Expression
+	switch name := fn.Name.Lowered(); name {
+	case "row_number", "rank", "dense_rank":
+		if len(fn.Exprs) != 0 || fn.Distinct {
+			return nil, ErrUnsupportedSyntax.New(sqlparser.String(fn))
+		}
+
+		switch name {
+		case "row_number":
+			function = plan.NewRowNumber()
+		case "rank":
+			function = plan.NewRank()
+		default:
+			function = plan.NewDenseRank()
+		}
+	default:
+		if !isAggregateFunc(fn) {
+			return nil, ErrUnsupportedFeature.New("window function " + strings.ToUpper(name))
+		}
+
+		var err error
+		function, err = exprToExpression(ctx, fn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	partitionBy, orderBy, err := windowToExpressions(ctx, over)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewWindowFunction(function, partitionBy, orderBy), nil
+}
+
+// windowFrameUnits are the units of the frames of windows.
+var windowFrameUnits = map[string]bool{
+	"rows":   true,
+	"range":  true,
+	"groups": true,
+}
+
+// windowToExpressions converts the OVER clause given to the expressions of its PARTITION BY clause and the fields of
+// its ORDER BY clause, which are parsed as the clauses of a query. Named windows and frames aren't supported.
+func windowToExpressions(ctx *sql.Context, over string) ([]sql.Expression, []plan.SortField, error) {
+	spec := over[strings.Index(over, "(")+1 : len(over)-1]
+	tkn := newTokenScanner(spec)
+
+	// clauses are the texts of the PARTITION BY and ORDER BY clauses, keyed by their first token
+	clauses := make(map[int]string)
+	clause, clauseStart := 0, 0
+	depth := 0
+	typ, val, start := tkn.scan()
+	for typ != 0 {
+		switch {
+		case typ == sqlparser.LEX_ERROR:
+			return nil, nil, ErrUnsupportedSyntax.New(over)
+		case typ == '(':
+			depth++
+		case typ == ')':
+			depth--
+		case depth > 0:
+		case (typ == sqlparser.PARTITION && clause == 0) || (typ == sqlparser.ORDER && clause != sqlparser.ORDER):
+			if clause != 0 {
+				clauses[clause] = spec[clauseStart:start]
+			}
+
+			clause = typ
+			if by, _, _ := tkn.scan(); by != sqlparser.BY {
+				return nil, nil, ErrUnsupportedSyntax.New(over)
+			}
+			clauseStart = tkn.end
+		case windowFrameUnits[strings.ToLower(val)] && clause != 0:
+			return nil, nil, ErrUnsupportedFeature.New("window frames")
+		case clause == 0:
+			return nil, nil, ErrUnsupportedFeature.New("named windows")
+		}
+
+		typ, val, start = tkn.scan()
+	}
+	if clause != 0 {
+		clauses[clause] = spec[clauseStart:]
+	}
+
+	query := "SELECT 1 FROM dual"
+	if partitionBy, ok := clauses[sqlparser.PARTITION]; ok {
+		query += " GROUP BY " + partitionBy
+	}
+	if orderBy, ok := clauses[sqlparser.ORDER]; ok {
+		query += " ORDER BY " + orderBy
+	}
+
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, nil, ErrUnsupportedSyntax.New(over)
+	}
+
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, nil, ErrUnsupportedSyntax.New(over)
+	}
+
+	var partitionBy []sql.Expression
+	if len(sel.GroupBy) > 0 {
+		partitionBy, err = groupByToExpressions(ctx, sel.GroupBy)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	orderBy, err := orderByToSortFields(ctx, sel.OrderBy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return partitionBy, orderBy, nil
+}
@@ -0,0 +1,139 @@
+package parse
+
+import (
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// parseWith parses a SELECT statement with a WITH clause, optionally described by EXPLAIN, which the vitess parser
+// doesn't support. The match given is that of withRegex in the query. Every common table expression is parsed as a
+// statement of its own, so it can have a WITH clause too.
+func parseWith(ctx *sql.Context, query string, match []int) (sql.Node, error) {
+	start := 0
+	if match[2] >= 0 {
+		start = match[3]
+	}
+
+	tkn := sqlparser.NewStringTokenizer(query[start:])
+	// end is the offset of the query right after the last token scanned
+	end := start
+	scan := func() (int, string, int) {
+		for {
+			tokenStart := end
+			for tokenStart < len(query) && isSpace(query[tokenStart]) {
+				tokenStart++
+			}
+
+			typ, val := tkn.Scan()
+			end = start + tkn.Position - 1
+			if end > len(query) {
+				end = len(query)
+			}
+			if typ != sqlparser.COMMENT {
+				return typ, string(val), tokenStart
+			}
+		}
+	}
+	unexpected := func(pos int) error {
+		return ErrUnsupportedSyntax.New(query[pos:])
+	}
+
+	if typ, _, pos := scan(); typ != sqlparser.WITH {
+		return nil, unexpected(pos)
+	}
+
+	var ctes []*plan.CommonTableExpression
+	typ, val, pos := scan()
+	if typ == sqlparser.ID && strings.EqualFold(val, "recursive") {
+		return nil, ErrUnsupportedFeature.New("WITH RECURSIVE")
+	}
+
+	for {
+		if typ != sqlparser.ID {
+			return nil, unexpected(pos)
+		}
+		name := val
+
+		var columns []string
+		typ, val, pos = scan()
+		if typ == '(' {
+			for {
+				typ, val, pos = scan()
+				if typ != sqlparser.ID {
+					return nil, unexpected(pos)
+				}
+				columns = append(columns, val)
+
+				typ, _, pos = scan()
+				if typ == ')' {
+					break
+				}
+				if typ != ',' {
+					return nil, unexpected(pos)
+				}
+			}
+			typ, _, pos = scan()
+		}
+
+		if typ != sqlparser.AS {
+			return nil, unexpected(pos)
+		}
+		if typ, _, pos = scan(); typ != '(' {
+			return nil, unexpected(pos)
+		}
+
+		definitionStart := end
+		definitionEnd := end
+		for depth := 1; depth > 0; {
+			typ, _, pos = scan()
+			switch typ {
+			case 0, sqlparser.LEX_ERROR:
+				return nil, unexpected(pos)
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			definitionEnd = pos
+		}
+
+		definition := strings.TrimSpace(query[definitionStart:definitionEnd])
+		node, err := Parse(ctx, definition)
+		if err != nil {
+			return nil, err
+		}
+		ctes = append(ctes, plan.NewCommonTableExpression(plan.NewSubqueryAlias(name, definition, node), columns))
+
+		typ, val, pos = scan()
+		if typ != ',' {
+			break
+		}
+		typ, val, pos = scan()
+	}
+
+	if typ != sqlparser.SELECT && typ != '(' {
+		return nil, unexpected(pos)
+	}
+	node, err := Parse(ctx, query[pos:])
+	if err != nil {
+		return nil, err
+	}
+	node = plan.NewWith(node, ctes)
+
+	if match[2] < 0 {
+		return node, nil
+	}
+	format := ""
+	if match[8] >= 0 {
+		format = query[match[8]:match[9]]
+	}
+	explainFmt, err := describeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewDescribeQuery(explainFmt, node), nil
+}
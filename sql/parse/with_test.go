@@ -0,0 +1,85 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseWith(t *testing.T) {
+	selectFrom := func(table string) sql.Node {
+		return plan.NewProject(
+			[]sql.Expression{expression.NewStar()},
+			plan.NewUnresolvedTable(table, ""),
+		)
+	}
+	cte := func(name, definition string, node sql.Node, columns ...string) *plan.CommonTableExpression {
+		return plan.NewCommonTableExpression(plan.NewSubqueryAlias(name, definition, node), columns)
+	}
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{
+			"WITH a AS (SELECT * FROM t) SELECT * FROM a",
+			plan.NewWith(selectFrom("a"), []*plan.CommonTableExpression{
+				cte("a", "SELECT * FROM t", selectFrom("t")),
+			}),
+		},
+		{
+			"with `a b` (x, `y`) as ( select * from t ), c as (select * from `a b`)\nselect * from c",
+			plan.NewWith(selectFrom("c"), []*plan.CommonTableExpression{
+				cte("a b", "select * from t", selectFrom("t"), "x", "y"),
+				cte("c", "select * from `a b`", selectFrom("a b")),
+			}),
+		},
+		{
+			"WITH a AS (WITH b AS (SELECT * FROM t) SELECT * FROM b) SELECT * FROM a",
+			plan.NewWith(selectFrom("a"), []*plan.CommonTableExpression{
+				cte("a", "WITH b AS (SELECT * FROM t) SELECT * FROM b", plan.NewWith(selectFrom("b"), []*plan.CommonTableExpression{
+					cte("b", "SELECT * FROM t", selectFrom("t")),
+				})),
+			}),
+		},
+		{
+			"EXPLAIN FORMAT=cost WITH a AS (SELECT * FROM t) SELECT * FROM a",
+			plan.NewDescribeQuery(plan.DescribeFormatCost, plan.NewWith(selectFrom("a"), []*plan.CommonTableExpression{
+				cte("a", "SELECT * FROM t", selectFrom("t")),
+			})),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	errorCases := []struct {
+		query string
+		err   *errors.Kind
+	}{
+		{"WITH RECURSIVE a AS (SELECT 1) SELECT * FROM a", ErrUnsupportedFeature},
+		{"WITH a SELECT * FROM t", ErrUnsupportedSyntax},
+		{"WITH a AS (SELECT * FROM t SELECT * FROM a", ErrUnsupportedSyntax},
+		{"WITH a (x, AS (SELECT * FROM t) SELECT * FROM a", ErrUnsupportedSyntax},
+		{"WITH a AS (SELECT * FROM t) DELETE FROM a", ErrUnsupportedSyntax},
+		{"EXPLAIN FORMAT=json WITH a AS (SELECT * FROM t) SELECT * FROM a", errInvalidDescribeFormat},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Error(t, err)
+			require.True(t, tt.err.Is(err), err.Error())
+		})
+	}
+}
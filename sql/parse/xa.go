@@ -0,0 +1,123 @@
+package parse
+
+import (
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dolthub/vitess/go/vt/sqlparser"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+var (
+	xaTransactionRegex = regexp.MustCompile(`(?is)^xa\s+(start|begin|end|prepare|commit|rollback)\s+(.+?)(\s+(join|resume|suspend(\s+for\s+migrate)?|one\s+phase))?$`)
+	xaRecoverRegex     = regexp.MustCompile(`(?is)^xa\s+recover(\s+convert\s+xid)?$`)
+)
+
+// xaStatements are the XA statements by their keyword.
+var xaStatements = map[string]plan.XAStatement{
+	"start":    plan.XAStart,
+	"begin":    plan.XAStart,
+	"end":      plan.XAEnd,
+	"prepare":  plan.XAPrepare,
+	"commit":   plan.XACommit,
+	"rollback": plan.XARollback,
+}
+
+// parseXA parses the XA statements, which the vitess parser doesn't support. JOIN and RESUME in XA START and SUSPEND
+// in XA END are accepted, and have no effect.
+func parseXA(ctx *sql.Context, query string) (sql.Node, error) {
+	if match := xaRecoverRegex.FindStringSubmatch(query); match != nil {
+		return plan.NewXARecover(match[1] != ""), nil
+	}
+
+	match := xaTransactionRegex.FindStringSubmatch(query)
+	if match == nil {
+		return nil, ErrUnsupportedSyntax.New(query)
+	}
+
+	statement := xaStatements[strings.ToLower(match[1])]
+	option := strings.ToLower(strings.Join(strings.Fields(match[4]), " "))
+	switch {
+	case option == "":
+	case option == "one phase" && statement == plan.XACommit:
+	case (option == "join" || option == "resume") && statement == plan.XAStart:
+	case strings.HasPrefix(option, "suspend") && statement == plan.XAEnd:
+	default:
+		return nil, errUnexpectedSyntax.New("EOF", strings.TrimSpace(match[3]))
+	}
+
+	xid, err := parseXID(match[2])
+	if err != nil {
+		return nil, err
+	}
+	return plan.NewXATransaction(statement, xid, option == "one phase"), nil
+}
+
+// parseXID parses an xid: a global transaction id, and optionally a branch qualifier and a format id, which is 1 if
+// it's not given. The ids are strings or hexadecimal literals, and the format an unsigned integer.
+func parseXID(s string) (sql.XID, error) {
+	stmt, err := sqlparser.Parse("SELECT " + s)
+	if err != nil {
+		return sql.XID{}, errUnexpectedSyntax.New("an xid", s)
+	}
+	// The xid must be the whole select, with no other clauses
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) > 3 || sqlparser.String(sel) != "select "+sqlparser.String(sel.SelectExprs)+" from dual" {
+		return sql.XID{}, errUnexpectedSyntax.New("an xid", s)
+	}
+
+	var parts []string
+	formatID := int64(1)
+	for i, e := range sel.SelectExprs {
+		ae, ok := e.(*sqlparser.AliasedExpr)
+		if !ok || !ae.As.IsEmpty() {
+			return sql.XID{}, errUnexpectedSyntax.New("an xid", s)
+		}
+		val, ok := ae.Expr.(*sqlparser.SQLVal)
+		if !ok {
+			return sql.XID{}, errUnexpectedSyntax.New("an xid", s)
+		}
+
+		if i == 2 {
+			if val.Type != sqlparser.IntVal {
+				return sql.XID{}, errUnexpectedSyntax.New("a format id", sqlparser.String(val))
+			}
+			if formatID, err = strconv.ParseInt(string(val.Val), 10, 64); err != nil {
+				return sql.XID{}, sql.ErrXAInval.New(err)
+			}
+			continue
+		}
+
+		switch val.Type {
+		case sqlparser.StrVal:
+			parts = append(parts, string(val.Val))
+		case sqlparser.HexVal:
+			b, err := val.HexDecode()
+			if err != nil {
+				return sql.XID{}, sql.ErrXAInval.New(err)
+			}
+			parts = append(parts, string(b))
+		case sqlparser.HexNum:
+			digits := string(val.Val[2:])
+			if len(digits)%2 != 0 {
+				digits = "0" + digits
+			}
+			b, err := hex.DecodeString(digits)
+			if err != nil {
+				return sql.XID{}, sql.ErrXAInval.New(err)
+			}
+			parts = append(parts, string(b))
+		default:
+			return sql.XID{}, errUnexpectedSyntax.New("a string", sqlparser.String(val))
+		}
+	}
+
+	if len(parts) < 2 {
+		parts = append(parts, "")
+	}
+	return sql.NewXID(parts[0], parts[1], formatID)
+}
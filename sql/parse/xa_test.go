@@ -0,0 +1,63 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestParseXA(t *testing.T) {
+	xid := sql.XID{GTRID: "trx", FormatID: 1}
+
+	testCases := []struct {
+		query    string
+		expected sql.Node
+	}{
+		{"XA START 'trx'", plan.NewXATransaction(plan.XAStart, xid, false)},
+		{"xa begin 'trx' join", plan.NewXATransaction(plan.XAStart, xid, false)},
+		{"XA START 'trx', 'b1', 42", plan.NewXATransaction(plan.XAStart, sql.XID{GTRID: "trx", BQUAL: "b1", FormatID: 42}, false)},
+		{"XA START X'747278', 0x6231", plan.NewXATransaction(plan.XAStart, sql.XID{GTRID: "trx", BQUAL: "b1", FormatID: 1}, false)},
+		{"XA END 'trx' SUSPEND FOR MIGRATE", plan.NewXATransaction(plan.XAEnd, xid, false)},
+		{"XA PREPARE \"trx\";", plan.NewXATransaction(plan.XAPrepare, xid, false)},
+		{"XA COMMIT 'trx'", plan.NewXATransaction(plan.XACommit, xid, false)},
+		{"XA COMMIT 'trx' ONE  PHASE", plan.NewXATransaction(plan.XACommit, xid, true)},
+		{"XA ROLLBACK 'trx'", plan.NewXATransaction(plan.XARollback, xid, false)},
+		{"XA RECOVER", plan.NewXARecover(false)},
+		{"xa recover convert xid", plan.NewXARecover(true)},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, node)
+		})
+	}
+
+	errorCases := []struct {
+		query string
+		err   *errors.Kind
+	}{
+		{"XA START 'trx' ONE PHASE", errUnexpectedSyntax},
+		{"XA COMMIT 'trx' JOIN", errUnexpectedSyntax},
+		{"XA START trx", errUnexpectedSyntax},
+		{"XA START 'trx', 'b1', 'f'", errUnexpectedSyntax},
+		{"XA START 'a', 'b', 1, 2", errUnexpectedSyntax},
+		{"XA START 'trx' FROM t", errUnexpectedSyntax},
+		{"XA START ''", sql.ErrXAInval},
+		{"XA RECOVER 'trx'", ErrUnsupportedSyntax},
+		{"XA FORGET 'trx'", ErrUnsupportedSyntax},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(sql.NewEmptyContext(), tt.query)
+			require.Error(t, err)
+			require.True(t, tt.err.Is(err), err.Error())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrUserOperationFailed is returned by the account statements that fail for an account, such as ALTER USER for
+	// the users that don't have an account.
+	ErrUserOperationFailed = errors.NewKind("Operation %s failed for '%s'@'%s'")
+	// ErrNotValidPassword is returned when a new password doesn't satisfy the password policy of the server.
+	ErrNotValidPassword = errors.NewKind("Your password does not satisfy the current policy requirements")
+	// ErrMustChangePassword is returned for the statements of the users whose password has expired, until they change
+	// it.
+	ErrMustChangePassword = errors.NewKind("You must reset your password using ALTER USER statement before executing this statement.")
+)
+
+// UnboundedPasswordLockTime is the PasswordLockTime of the accounts that stay locked after too many failed logins
+// until they're unlocked.
+const UnboundedPasswordLockTime = -1
+
+// AccountAlteration is the change ALTER USER makes to an account. Its nil fields are left as they are.
+type AccountAlteration struct {
+	// Password is the new password of the account, in clear text. Changing it makes it not expired.
+	Password *string
+	// ExpirePassword expires the password of the account, so that its user must change it before running any other
+	// statement.
+	ExpirePassword bool
+	// Locked locks or unlocks the account. Unlocking it also unlocks it after too many failed logins.
+	Locked *bool
+	// FailedLoginAttempts is the number of consecutive failed logins that lock the account, or zero to never lock it.
+	FailedLoginAttempts *int
+	// PasswordLockTime is the number of days the account is locked for after too many failed logins, zero to never
+	// lock it, or UnboundedPasswordLockTime.
+	PasswordLockTime *int
+}
+
+// ChangesPasswordOnly returns whether the alteration only changes the password, which users can do to their own
+// accounts.
+func (a AccountAlteration) ChangesPasswordOnly() bool {
+	return a.Password != nil && !a.ExpirePassword && a.Locked == nil && a.FailedLoginAttempts == nil &&
+		a.PasswordLockTime == nil
+}
+
+// AccountManager is an AccountLister that can also alter the accounts, for ALTER USER. It's implemented by the auth
+// methods that manage the passwords of their users.
+type AccountManager interface {
+	AccountLister
+	// AlterAccount alters the account of the user given. It returns ErrNotValidPassword if the new password doesn't
+	// satisfy the password policy.
+	AlterAccount(user string, alteration AccountAlteration) error
+}
+
+// AlterAccount alters the account of the user given with ALTER USER. It returns ErrUserOperationFailed if the user has
+// no account, or if the accounts can't be altered.
+func (c *Catalog) AlterAccount(user, host string, alteration AccountAlteration) error {
+	c.mu.RLock()
+	manager, ok := c.accounts.(AccountManager)
+	c.mu.RUnlock()
+
+	if !ok {
+		return ErrUserOperationFailed.New("ALTER USER", user, host)
+	}
+	if _, ok := manager.Account(user); !ok {
+		return ErrUserOperationFailed.New("ALTER USER", user, host)
+	}
+	return manager.AlterAccount(user, alteration)
+}
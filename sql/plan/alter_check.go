@@ -0,0 +1,239 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// CreateCheck is an ALTER TABLE ... ADD CHECK statement, which adds a check constraint to a table. Enforced constraints
+// can't be added to tables with rows that violate them.
+type CreateCheck struct {
+	UnaryNode
+	Check *sql.CheckConstraint
+	// Expr is the expression of the constraint resolved on the rows of the table, which the analyzer sets for enforced
+	// constraints.
+	Expr sql.Expression
+}
+
+// DropCheck is an ALTER TABLE ... DROP CHECK statement, which drops a check constraint of a table.
+type DropCheck struct {
+	UnaryNode
+	Name string
+}
+
+// DropConstraint is an ALTER TABLE ... DROP CONSTRAINT statement, which drops the check constraint of a table with its
+// name, or its foreign key if it has no check constraint with the name.
+type DropConstraint struct {
+	UnaryNode
+	Name string
+}
+
+var _ sql.Node = (*CreateCheck)(nil)
+var _ sql.Node = (*DropCheck)(nil)
+var _ sql.Node = (*DropConstraint)(nil)
+
+// NewAlterAddCheck creates a new CreateCheck node adding the check constraint given to the table given. Constraints
+// without a name are given one when they're analyzed.
+func NewAlterAddCheck(table sql.Node, check *sql.CheckConstraint) *CreateCheck {
+	return &CreateCheck{
+		UnaryNode: UnaryNode{Child: table},
+		Check:     check,
+	}
+}
+
+// NewAlterDropCheck creates a new DropCheck node dropping the check constraint with the name given of the table given.
+func NewAlterDropCheck(table sql.Node, name string) *DropCheck {
+	return &DropCheck{
+		UnaryNode: UnaryNode{Child: table},
+		Name:      name,
+	}
+}
+
+// NewAlterDropConstraint creates a new DropConstraint node dropping the constraint with the name given of the table
+// given.
+func NewAlterDropConstraint(table sql.Node, name string) *DropConstraint {
+	return &DropConstraint{
+		UnaryNode: UnaryNode{Child: table},
+		Name:      name,
+	}
+}
+
+// getCheckAlterable returns the table of the node given that can alter its check constraints.
+func getCheckAlterable(node sql.Node) (sql.CheckAlterableTable, error) {
+	var t sql.Table
+	switch node := node.(type) {
+	case *ResolvedTable:
+		t = node.Table
+	case sql.Table:
+		t = node
+	default:
+		return nil, ErrNoCheckConstraintSupport.New(node.String())
+	}
+
+	for {
+		switch tt := t.(type) {
+		case sql.CheckAlterableTable:
+			return tt, nil
+		case sql.TableWrapper:
+			t = tt.Underlying()
+		default:
+			return nil, ErrNoCheckConstraintSupport.New(t.Name())
+		}
+	}
+}
+
+// hasCheck returns whether the table given has a check constraint with the name given.
+func hasCheck(ctx *sql.Context, t sql.Table, name string) (bool, error) {
+	ct := GetCheckTable(t)
+	if ct == nil {
+		return false, nil
+	}
+	checks, err := ct.GetChecks(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range checks {
+		if strings.EqualFold(c.Name, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RowIter implements the Node interface. The rows of the table are checked before the constraint is added.
+func (p *CreateCheck) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	t, err := getCheckAlterable(p.Child)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Check.Enforced && p.Expr != nil {
+		if err := p.checkRows(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.CreateCheck(ctx, *p.Check); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// checkRows returns sql.ErrCheckConstraintViolated if one of the rows of the table violates the constraint.
+func (p *CreateCheck) checkRows(ctx *sql.Context) (err error) {
+	iter, err := p.Child.RowIter(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := iter.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	checks := CheckConstraints{{Name: p.Check.Name, Expr: p.Expr}}
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := checks.check(ctx, row); err != nil {
+			return err
+		}
+	}
+}
+
+// RowIter implements the Node interface.
+func (p *DropCheck) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	t, err := getCheckAlterable(p.Child)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := hasCheck(ctx, t, p.Name); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrCheckConstraintNotFound.New(p.Name)
+	}
+
+	if err := t.DropCheck(ctx, p.Name); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// RowIter implements the Node interface.
+func (p *DropConstraint) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if t, err := getCheckAlterable(p.Child); err == nil {
+		if ok, err := hasCheck(ctx, t, p.Name); err != nil {
+			return nil, err
+		} else if ok {
+			if err := t.DropCheck(ctx, p.Name); err != nil {
+				return nil, err
+			}
+			return sql.RowsToRowIter(), nil
+		}
+	}
+
+	return NewAlterDropForeignKey(p.Child, &sql.ForeignKeyConstraint{Name: p.Name}).RowIter(ctx, row)
+}
+
+// WithChildren implements the Node interface.
+func (p *CreateCheck) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	np := *p
+	np.Child = children[0]
+	return &np, nil
+}
+
+// WithChildren implements the Node interface.
+func (p *DropCheck) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	return NewAlterDropCheck(children[0], p.Name), nil
+}
+
+// WithChildren implements the Node interface.
+func (p *DropConstraint) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+	return NewAlterDropConstraint(children[0], p.Name), nil
+}
+
+func (p *CreateCheck) Schema() sql.Schema    { return nil }
+func (p *DropCheck) Schema() sql.Schema      { return nil }
+func (p *DropConstraint) Schema() sql.Schema { return nil }
+
+func (p CreateCheck) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("AddCheck(%s)", p.Check.Name)
+	_ = pr.WriteChildren(
+		fmt.Sprintf("Table(%s)", p.Child.String()),
+		fmt.Sprintf("Expr(%s)", p.Check.Expression),
+		fmt.Sprintf("Enforced(%t)", p.Check.Enforced))
+	return pr.String()
+}
+
+func (p DropCheck) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DropCheck(%s)", p.Name)
+	_ = pr.WriteChildren(fmt.Sprintf("Table(%s)", p.Child.String()))
+	return pr.String()
+}
+
+func (p DropConstraint) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DropConstraint(%s)", p.Name)
+	_ = pr.WriteChildren(fmt.Sprintf("Table(%s)", p.Child.String()))
+	return pr.String()
+}
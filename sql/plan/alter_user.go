@@ -0,0 +1,103 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// erUserDoesNotExist is the code of the warning of ALTER USER IF EXISTS for the users that don't have an account.
+const erUserDoesNotExist = 3162
+
+// AlterUser is the ALTER USER statement, which changes the password of an account, expires it, or locks the account.
+type AlterUser struct {
+	// User is the user whose account is altered, or empty for the user of the session, which is set by the analyzer.
+	User string
+	Host string
+	// IfExists only warns about the users that don't have an account, instead of failing.
+	IfExists   bool
+	Alteration sql.AccountAlteration
+	Catalog    *sql.Catalog
+}
+
+var _ sql.Node = (*AlterUser)(nil)
+
+// NewAlterUser creates a new AlterUser node for the account given, or for the user of the session if the user is
+// empty.
+func NewAlterUser(user, host string, ifExists bool, alteration sql.AccountAlteration) *AlterUser {
+	return &AlterUser{User: user, Host: host, IfExists: ifExists, Alteration: alteration}
+}
+
+// ChangesOwnPassword returns whether the statement only changes the password of the user given, which users can do
+// without any privilege, even when their password has expired.
+func (a *AlterUser) ChangesOwnPassword(user string) bool {
+	return (a.User == "" || a.User == user) && a.Alteration.ChangesPasswordOnly()
+}
+
+// Resolved implements the sql.Node interface.
+func (a *AlterUser) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (a *AlterUser) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (a *AlterUser) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (a *AlterUser) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(a, len(children), 0)
+	}
+	return a, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (a *AlterUser) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if _, ok := a.Catalog.Account(a.User); !ok && a.IfExists {
+		ctx.Warn(erUserDoesNotExist, "Authorization ID '%s'@'%s' does not exist.", a.User, a.Host)
+		return sql.RowsToRowIter(), nil
+	}
+
+	if err := a.Catalog.AlterAccount(a.User, a.Host, a.Alteration); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (a *AlterUser) String() string {
+	str := "ALTER USER "
+	if a.IfExists {
+		str += "IF EXISTS "
+	}
+	if a.User == "" {
+		str += "CURRENT_USER"
+	} else {
+		str += fmt.Sprintf("%s@%s", a.User, a.Host)
+	}
+
+	// The password isn't shown, since the plans end up in logs and in EXPLAIN
+	if a.Alteration.Password != nil {
+		str += " IDENTIFIED BY <secret>"
+	}
+	if a.Alteration.ExpirePassword {
+		str += " PASSWORD EXPIRE"
+	}
+	if a.Alteration.FailedLoginAttempts != nil {
+		str += fmt.Sprintf(" FAILED_LOGIN_ATTEMPTS %d", *a.Alteration.FailedLoginAttempts)
+	}
+	if a.Alteration.PasswordLockTime != nil {
+		if *a.Alteration.PasswordLockTime == sql.UnboundedPasswordLockTime {
+			str += " PASSWORD_LOCK_TIME UNBOUNDED"
+		} else {
+			str += fmt.Sprintf(" PASSWORD_LOCK_TIME %d", *a.Alteration.PasswordLockTime)
+		}
+	}
+	if a.Alteration.Locked != nil {
+		if *a.Alteration.Locked {
+			str += " ACCOUNT LOCK"
+		} else {
+			str += " ACCOUNT UNLOCK"
+		}
+	}
+	return str
+}
@@ -21,14 +21,24 @@ import (
 	"github.com/dolthub/go-mysql-server/sql"
 )
 
+// BeginEndBlock is a BEGIN ... END block of statements. Blocks parsed with a frame can declare local variables,
+// cursors and condition handlers, and can be left with LEAVE if they have a label.
 type BeginEndBlock struct {
+	Label      string
 	statements []sql.Node
+	frame      *BlockFrame
 }
 
 func NewBeginEndBlock(statements []sql.Node) *BeginEndBlock {
 	return &BeginEndBlock{statements: statements}
 }
 
+// NewLabeledBeginEndBlock returns a block with the label, which may be empty, and statements given, whose declarations
+// are held by the frame given.
+func NewLabeledBeginEndBlock(label string, frame *BlockFrame, statements []sql.Node) *BeginEndBlock {
+	return &BeginEndBlock{Label: label, statements: statements, frame: frame}
+}
+
 func (b *BeginEndBlock) Resolved() bool {
 	for _, s := range b.statements {
 		if !s.Resolved() {
@@ -40,7 +50,7 @@ func (b *BeginEndBlock) Resolved() bool {
 
 func (b *BeginEndBlock) String() string {
 	p := sql.NewTreePrinter()
-	_ = p.WriteNode("BEGIN .. END")
+	_ = p.WriteNode(b.header())
 	var children []string
 	for _, s := range b.statements {
 		children = append(children, s.String())
@@ -51,7 +61,7 @@ func (b *BeginEndBlock) String() string {
 
 func (b *BeginEndBlock) DebugString() string {
 	p := sql.NewTreePrinter()
-	_ = p.WriteNode("BEGIN .. END")
+	_ = p.WriteNode(b.header())
 	var children []string
 	for _, s := range b.statements {
 		children = append(children, sql.DebugString(s))
@@ -60,6 +70,13 @@ func (b *BeginEndBlock) DebugString() string {
 	return p.String()
 }
 
+func (b *BeginEndBlock) header() string {
+	if b.Label != "" {
+		return b.Label + ": BEGIN .. END"
+	}
+	return "BEGIN .. END"
+}
+
 func (b *BeginEndBlock) Schema() sql.Schema {
 	// TODO: some of these actually do return a result (like for stored procedures)
 	return nil
@@ -69,11 +86,16 @@ func (b *BeginEndBlock) Children() []sql.Node {
 	return b.statements
 }
 
+// blockIter runs its statements on the first call to Next, and returns no rows.
 type blockIter struct {
-	statements []sql.Node
-	ctx        *sql.Context
-	row        sql.Row
-	once       *sync.Once
+	ctx  *sql.Context
+	row  sql.Row
+	run  func(ctx *sql.Context, row sql.Row) error
+	once *sync.Once
+}
+
+func newBlockIter(ctx *sql.Context, row sql.Row, run func(ctx *sql.Context, row sql.Row) error) *blockIter {
+	return &blockIter{ctx: ctx, row: row, run: run, once: &sync.Once{}}
 }
 
 func (i *blockIter) Next() (sql.Row, error) {
@@ -86,26 +108,9 @@ func (i *blockIter) Next() (sql.Row, error) {
 		return nil, io.EOF
 	}
 
-	for _, s := range i.statements {
-		subIter, err := s.RowIter(i.ctx, i.row)
-		if err != nil {
-			return nil, err
-		}
-
-		for {
-			_, err := subIter.Next()
-			if err == io.EOF {
-				err := subIter.Close()
-				if err != nil {
-					return nil, err
-				}
-				break
-			} else if err != nil {
-				return nil, err
-			}
-		}
+	if err := i.run(i.ctx, i.row); err != nil {
+		return nil, err
 	}
-
 	return nil, io.EOF
 }
 
@@ -114,13 +119,25 @@ func (i *blockIter) Close() error {
 }
 
 func (b *BeginEndBlock) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	return &blockIter{
-		statements: b.statements,
-		row:        row,
-		once:       &sync.Once{},
-	}, nil
+	return newBlockIter(ctx, row, b.run), nil
+}
+
+func (b *BeginEndBlock) run(ctx *sql.Context, row sql.Row) error {
+	if b.frame == nil {
+		return runStatements(ctx, row, nil, b.statements)
+	}
+
+	b.frame.enter()
+	err := runStatements(ctx, row, b.frame, b.statements)
+	if c, ok := err.(*blockControl); ok && (c.frame == b.frame || leaves(err, b.Label)) {
+		err = nil
+	}
+	if cerr := b.frame.exit(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 func (b *BeginEndBlock) WithChildren(node ...sql.Node) (sql.Node, error) {
-	return NewBeginEndBlock(node), nil
+	return NewLabeledBeginEndBlock(b.Label, b.frame, node), nil
 }
@@ -0,0 +1,173 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// ApplyBindings returns the node given with every bind variable in its expressions, including the ones of its
+// subqueries, replaced with the expression bound to its name. The selected expressions with bind variables keep the
+// names they had before, as their columns are named from the text of the statement. It returns
+// expression.ErrUnboundBindVar if a bind variable has no expression bound to it.
+func ApplyBindings(node sql.Node, bindings map[string]sql.Expression) (sql.Node, error) {
+	node, err := TransformUp(node, func(n sql.Node) (sql.Node, error) {
+		switch n := n.(type) {
+		case *Project:
+			return NewProject(nameBindVarExpressions(n.Projections), n.Child), nil
+		case *GroupBy:
+			return NewGroupBy(nameBindVarExpressions(n.SelectedExprs), n.GroupByExprs, n.Child), nil
+		default:
+			return n, nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return TransformExpressionsUp(node, func(e sql.Expression) (sql.Expression, error) {
+		switch e := e.(type) {
+		case *expression.BindVar:
+			value, ok := bindings[e.Name]
+			if !ok {
+				return nil, expression.ErrUnboundBindVar.New(e.Name)
+			}
+			return value, nil
+		case *Subquery:
+			query, err := ApplyBindings(e.Query, bindings)
+			if err != nil {
+				return nil, err
+			}
+			return e.WithQuery(query), nil
+		default:
+			return e, nil
+		}
+	})
+}
+
+// nameBindVarExpressions returns the selected expressions given with the ones with bind variables that aren't aliased
+// given aliases of their names.
+func nameBindVarExpressions(exprs []sql.Expression) []sql.Expression {
+	named := make([]sql.Expression, len(exprs))
+	for i, e := range exprs {
+		named[i] = e
+		if _, ok := e.(*expression.Alias); ok {
+			continue
+		}
+		sql.Inspect(e, func(child sql.Expression) bool {
+			if _, ok := child.(*expression.BindVar); ok {
+				named[i] = expression.NewAlias(e.String(), e)
+				return false
+			}
+			return true
+		})
+	}
+	return named
+}
+
+// BindVarTypes returns the types of the bind variables of the analyzed node given, including the ones of its
+// subqueries, keyed by their names. The type of a bind variable is inferred from the first expression it's compared or
+// matched to, or the column of the table its value is inserted into or updated in. Bind variables whose type can't be
+// inferred are of the type of expression.BindVar.
+func BindVarTypes(node sql.Node) map[string]sql.Type {
+	types := make(map[string]sql.Type)
+	infer := func(e, partner sql.Expression) {
+		bv, ok := e.(*expression.BindVar)
+		if !ok {
+			return
+		}
+		if _, ok := types[bv.Name]; ok {
+			return
+		}
+		if _, ok := partner.(*expression.BindVar); !ok {
+			types[bv.Name] = partner.Type()
+		}
+	}
+
+	var inspect func(sql.Node)
+	inspect = func(node sql.Node) {
+		Inspect(node, func(n sql.Node) bool {
+			insert, ok := n.(*InsertInto)
+			if !ok {
+				return true
+			}
+			schema := insert.Left.Schema()
+			Inspect(insert.Right, func(n sql.Node) bool {
+				values, ok := n.(*Values)
+				if !ok {
+					return true
+				}
+				for _, tuple := range values.ExpressionTuples {
+					for i, e := range tuple {
+						if idx := insertColumnIndex(schema, insert.ColumnNames, i); idx >= 0 {
+							infer(e, expression.NewGetField(idx, schema[idx].Type, schema[idx].Name, schema[idx].Nullable))
+						}
+					}
+				}
+				return false
+			})
+			return true
+		})
+
+		InspectExpressions(node, func(e sql.Expression) bool {
+			switch e := e.(type) {
+			case *expression.BindVar:
+				if _, ok := types[e.Name]; !ok {
+					types[e.Name] = e.Type()
+				}
+			case *expression.SetField:
+				infer(e.Right, e.Left)
+			case *expression.Like:
+				infer(e.Right, e.Left)
+			case *expression.Between:
+				infer(e.Lower, e.Val)
+				infer(e.Upper, e.Val)
+			case expression.Comparer:
+				left, leftOk := e.Left().(expression.Tuple)
+				right, rightOk := e.Right().(expression.Tuple)
+				switch {
+				case leftOk && rightOk && len(left) == len(right):
+					for i := range left {
+						infer(left[i], right[i])
+						infer(right[i], left[i])
+					}
+					return true
+				case rightOk && !leftOk:
+					for _, re := range right {
+						infer(re, e.Left())
+						infer(e.Left(), re)
+					}
+					return true
+				}
+				infer(e.Left(), e.Right())
+				infer(e.Right(), e.Left())
+			case *Subquery:
+				inspect(e.Query)
+			}
+			return true
+		})
+	}
+	inspect(node)
+	return types
+}
+
+// insertColumnIndex returns the index in the schema given of the column the i-th value of the rows inserted into the
+// columns of the names given is inserted into, or -1 if there's none.
+func insertColumnIndex(schema sql.Schema, columns []string, i int) int {
+	if len(columns) == 0 {
+		if i < len(schema) {
+			return i
+		}
+		return -1
+	}
+	if i >= len(columns) {
+		return -1
+	}
+	for idx, col := range schema {
+		if strings.EqualFold(col.Name, columns[i]) {
+			return idx
+		}
+	}
+	return -1
+}
@@ -0,0 +1,108 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestApplyBindings(t *testing.T) {
+	a := expression.NewUnresolvedColumn("a")
+	one := expression.NewLiteral(int64(1), sql.Int64)
+	foo := expression.NewLiteral("foo", sql.LongText)
+
+	node := NewProject(
+		[]sql.Expression{a, expression.NewBindVar("v1")},
+		NewFilter(
+			NewInSubquery(
+				a,
+				NewSubquery(NewFilter(
+					expression.NewEquals(a, expression.NewBindVar("name")),
+					NewUnresolvedTable("bar", ""),
+				), "SELECT a FROM bar WHERE a = :name"),
+			),
+			NewUnresolvedTable("foo", ""),
+		),
+	)
+
+	t.Run("bound", func(t *testing.T) {
+		require := require.New(t)
+		result, err := ApplyBindings(node, map[string]sql.Expression{"v1": one, "name": foo})
+		require.NoError(err)
+		require.Equal(NewProject(
+			[]sql.Expression{a, expression.NewAlias("?", one)},
+			NewFilter(
+				NewInSubquery(
+					a,
+					NewSubquery(NewFilter(
+						expression.NewEquals(a, foo),
+						NewUnresolvedTable("bar", ""),
+					), "SELECT a FROM bar WHERE a = :name"),
+				),
+				NewUnresolvedTable("foo", ""),
+			),
+		), result)
+	})
+
+	t.Run("unbound", func(t *testing.T) {
+		require := require.New(t)
+		_, err := ApplyBindings(node, map[string]sql.Expression{"v1": one})
+		require.Error(err)
+		require.True(expression.ErrUnboundBindVar.Is(err))
+	})
+}
+
+func TestBindVarTypes(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("foo", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "foo"},
+		{Name: "b", Type: sql.Text, Source: "foo"},
+	})
+	a := expression.NewGetFieldWithTable(0, sql.Int64, "foo", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.Text, "foo", "b", false)
+	bv := expression.NewBindVar
+
+	insert := NewInsertInto(NewResolvedTable(table), NewValues([][]sql.Expression{{bv("v2"), bv("v1")}}), false, []string{"b", "a"}, nil)
+	require.Equal(map[string]sql.Type{"v1": sql.Int64, "v2": sql.Text}, BindVarTypes(insert))
+
+	update := NewUpdate(NewFilter(
+		expression.NewBetween(a, bv("v2"), bv("v3")),
+		NewResolvedTable(table),
+	), []sql.Expression{expression.NewSetField(b, bv("v1"))})
+	require.Equal(map[string]sql.Type{"v1": sql.Text, "v2": sql.Int64, "v3": sql.Int64}, BindVarTypes(update))
+
+	// The first expression a bind variable is compared to gives its type, and bind variables compared to each other only
+	// have the type of bind variables
+	query := NewProject(
+		[]sql.Expression{expression.NewArithmetic(a, bv("v1"), "+")},
+		NewFilter(
+			expression.NewAnd(
+				expression.NewAnd(
+					expression.NewLessThan(bv("v2"), a),
+					expression.NewInTuple(bv("v3"), expression.NewTuple(b, a)),
+				),
+				expression.NewAnd(
+					expression.NewEquals(bv("v4"), bv("v5")),
+					NewInSubquery(a, NewSubquery(NewFilter(
+						expression.NewLike(b, bv("v6")),
+						NewResolvedTable(table),
+					), "")),
+				),
+			),
+			NewResolvedTable(table),
+		),
+	)
+	require.Equal(map[string]sql.Type{
+		"v1": sql.LongText,
+		"v2": sql.Int64,
+		"v3": sql.Text,
+		"v4": sql.LongText,
+		"v5": sql.LongText,
+		"v6": sql.Text,
+	}, BindVarTypes(query))
+}
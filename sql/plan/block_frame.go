@@ -0,0 +1,296 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var (
+	// ErrCursorAlreadyOpen is returned by OPEN for cursors that are already open.
+	ErrCursorAlreadyOpen = errors.NewKind("Cursor is already open")
+	// ErrCursorNotOpen is returned by FETCH and CLOSE for cursors that aren't open.
+	ErrCursorNotOpen = errors.NewKind("Cursor is not open")
+	// ErrFetchVariables is returned by FETCH when the number of variables isn't the number of columns of its cursor.
+	ErrFetchVariables = errors.NewKind("Incorrect number of FETCH variables")
+)
+
+// BlockFrame holds the local variables, cursors and condition handlers of a BEGIN ... END block of a trigger while it
+// runs. Frames are created when the block is parsed, and shared by all the statements of the block that use its
+// variables and cursors, and by the blocks nested in it, which can see them too. Their contents are cleared every time
+// the block starts running.
+type BlockFrame struct {
+	parent *BlockFrame
+
+	mu        sync.Mutex
+	variables map[string]interface{}
+	cursors   map[string]*cursor
+	handlers  []*DeclareHandler
+}
+
+// cursor is a cursor declared by a block, which is open while it has an iterator.
+type cursor struct {
+	query sql.Node
+	iter  sql.RowIter
+}
+
+// NewBlockFrame returns the frame of a block nested in the block of the frame given, which is nil for the outermost
+// block.
+func NewBlockFrame(parent *BlockFrame) *BlockFrame {
+	return &BlockFrame{parent: parent}
+}
+
+// Parent returns the frame of the block this frame's block is nested in, or nil if it's the outermost block.
+func (f *BlockFrame) Parent() *BlockFrame {
+	return f.parent
+}
+
+// enter clears the variables, cursors and handlers of the frame before its block runs.
+func (f *BlockFrame) enter() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.variables = make(map[string]interface{})
+	f.cursors = make(map[string]*cursor)
+	f.handlers = nil
+}
+
+// exit closes the cursors left open once the frame's block has finished.
+func (f *BlockFrame) exit() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var err error
+	for _, c := range f.cursors {
+		if c.iter != nil {
+			if cerr := c.iter.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+			c.iter = nil
+		}
+	}
+	f.handlers = nil
+	return err
+}
+
+func (f *BlockFrame) get(name string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.variables[strings.ToLower(name)]
+}
+
+func (f *BlockFrame) set(name string, value interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.variables[strings.ToLower(name)] = value
+}
+
+func (f *BlockFrame) declareCursor(name string, query sql.Node) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cursors[strings.ToLower(name)] = &cursor{query: query}
+}
+
+func (f *BlockFrame) cursor(name string) *cursor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cursors[strings.ToLower(name)]
+}
+
+func (f *BlockFrame) declareHandler(h *DeclareHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append(f.handlers, h)
+}
+
+// handlerFor returns the handler of the condition of the error given declared by this frame's block, or by the blocks
+// it's nested in, which are searched from the innermost one, along with the frame that declared it. Within a block, a
+// handler of the error code of the condition takes precedence over a handler of its SQLSTATE, which takes precedence
+// over a handler of its class.
+func (f *BlockFrame) handlerFor(err error) (*DeclareHandler, *BlockFrame) {
+	sqlState, code := conditionOf(err)
+	for frame := f; frame != nil; frame = frame.parent {
+		frame.mu.Lock()
+		var best *DeclareHandler
+		bestRank := 0
+		for _, h := range frame.handlers {
+			if rank := h.matches(sqlState, code); rank > bestRank {
+				best, bestRank = h, rank
+			}
+		}
+		frame.mu.Unlock()
+
+		if best != nil {
+			return best, frame
+		}
+	}
+	return nil, nil
+}
+
+// conditionOf returns the SQLSTATE and error code of the condition of the error given.
+func conditionOf(err error) (string, int) {
+	if ce, ok := err.(*sql.ConditionError); ok {
+		return ce.SQLState, ce.Code
+	}
+	if sql.ErrUniqueKeyViolation.Is(err) {
+		return "23000", 1062
+	}
+	return "HY000", 1105
+}
+
+// errNoData is the condition raised by FETCH once there are no more rows.
+func errNoData() error {
+	return &sql.ConditionError{Warning: &sql.Warning{
+		Level:    "Error",
+		Code:     1329,
+		SQLState: "02000",
+		Message:  "No data - zero rows fetched, selected, or processed",
+	}}
+}
+
+// blockControl is returned by the statements of blocks that jump out of the statements around them: LEAVE and ITERATE,
+// and the EXIT handlers, which end the block that declared them. It's never returned by the block it jumps to.
+type blockControl struct {
+	// label is the label of the block or loop left or iterated by LEAVE or ITERATE.
+	label   string
+	iterate bool
+	// frame is the frame of the block ended by an EXIT handler.
+	frame *BlockFrame
+}
+
+func (c *blockControl) Error() string {
+	if c.frame != nil {
+		return "EXIT handler outside of its block"
+	}
+	if c.iterate {
+		return fmt.Sprintf("ITERATE with no matching label: %s", c.label)
+	}
+	return fmt.Sprintf("LEAVE with no matching label: %s", c.label)
+}
+
+// leaves returns whether err leaves the block or loop with the label given.
+func leaves(err error, label string) bool {
+	c, ok := err.(*blockControl)
+	return ok && label != "" && !c.iterate && c.frame == nil && strings.EqualFold(c.label, label)
+}
+
+// runStatements runs the statements given, which are in the block whose frame is given, with the row given. The
+// conditions raised by a statement are handled by the handlers declared for them, which may let the statements after
+// it run. It returns the first condition that isn't handled.
+func runStatements(ctx *sql.Context, row sql.Row, frame *BlockFrame, statements []sql.Node) error {
+	for _, s := range statements {
+		err := runStatement(ctx, row, s)
+		if err == nil {
+			continue
+		}
+		if _, ok := err.(*blockControl); ok || frame == nil {
+			return err
+		}
+
+		handler, handlerFrame := frame.handlerFor(err)
+		if handler == nil {
+			return err
+		}
+		// The conditions raised by the handler are handled by the blocks around the one that declared it
+		if err := runStatements(ctx, row, handlerFrame.parent, []sql.Node{handler.Statement()}); err != nil {
+			return err
+		}
+		if handler.Action == ExitHandler {
+			return &blockControl{frame: handlerFrame}
+		}
+	}
+	return nil
+}
+
+// runStatement runs the statement given with the row given to completion.
+func runStatement(ctx *sql.Context, row sql.Row, statement sql.Node) error {
+	iter, err := statement.RowIter(ctx, row)
+	if err != nil {
+		return err
+	}
+
+	for {
+		_, err := iter.Next()
+		if err == io.EOF {
+			return iter.Close()
+		}
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+}
+
+// LocalVariable is a local variable declared by a BEGIN ... END block, which holds a value of its type while the block
+// runs.
+type LocalVariable struct {
+	name  string
+	typ   sql.Type
+	frame *BlockFrame
+}
+
+var _ sql.Expression = (*LocalVariable)(nil)
+
+// NewLocalVariable returns the local variable with the name and type given declared by the block of the frame given.
+func NewLocalVariable(name string, typ sql.Type, frame *BlockFrame) *LocalVariable {
+	return &LocalVariable{name: name, typ: typ, frame: frame}
+}
+
+// Name returns the name of the variable.
+func (v *LocalVariable) Name() string {
+	return v.name
+}
+
+// Resolved implements the sql.Expression interface.
+func (v *LocalVariable) Resolved() bool {
+	return true
+}
+
+// Type implements the sql.Expression interface.
+func (v *LocalVariable) Type() sql.Type {
+	return v.typ
+}
+
+// IsNullable implements the sql.Expression interface.
+func (v *LocalVariable) IsNullable() bool {
+	return true
+}
+
+// Children implements the sql.Expression interface.
+func (v *LocalVariable) Children() []sql.Expression {
+	return nil
+}
+
+// WithChildren implements the sql.Expression interface.
+func (v *LocalVariable) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(v, len(children), 0)
+	}
+	return v, nil
+}
+
+// Eval implements the sql.Expression interface.
+func (v *LocalVariable) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return v.frame.get(v.name), nil
+}
+
+// Assign sets the variable to the value given, converted to its type.
+func (v *LocalVariable) Assign(value interface{}) error {
+	if value != nil {
+		var err error
+		if value, err = v.typ.Convert(value); err != nil {
+			return err
+		}
+	}
+	v.frame.set(v.name, value)
+	return nil
+}
+
+func (v *LocalVariable) String() string {
+	return v.name
+}
@@ -0,0 +1,84 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestHandlerPrecedence(t *testing.T) {
+	require := require.New(t)
+
+	outer := NewBlockFrame(nil)
+	inner := NewBlockFrame(outer)
+	outer.enter()
+	inner.enter()
+
+	exception := NewDeclareHandler(ExitHandler, []HandlerCondition{{Type: HandlerSQLException}}, Nothing, outer)
+	sqlState := NewDeclareHandler(ExitHandler, []HandlerCondition{{Type: HandlerSQLState, SQLState: "23000"}}, Nothing, outer)
+	code := NewDeclareHandler(ExitHandler, []HandlerCondition{{Type: HandlerErrorCode, Code: 1062}}, Nothing, outer)
+	notFound := NewDeclareHandler(ContinueHandler, []HandlerCondition{{Type: HandlerNotFound}}, Nothing, inner)
+	for _, h := range []*DeclareHandler{exception, sqlState, code} {
+		outer.declareHandler(h)
+	}
+	inner.declareHandler(notFound)
+
+	h, frame := inner.handlerFor(sql.ErrUniqueKeyViolation.New("1"))
+	require.Equal(code, h)
+	require.Equal(outer, frame)
+
+	h, _ = inner.handlerFor(&sql.ConditionError{Warning: &sql.Warning{SQLState: "23000", Code: 1452}})
+	require.Equal(sqlState, h)
+
+	h, _ = inner.handlerFor(&sql.ConditionError{Warning: &sql.Warning{SQLState: "45000", Code: 1644}})
+	require.Equal(exception, h)
+
+	h, frame = inner.handlerFor(errNoData())
+	require.Equal(notFound, h)
+	require.Equal(inner, frame)
+
+	h, _ = outer.handlerFor(errNoData())
+	require.Nil(h)
+}
+
+func TestBlockExitHandler(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	outer := NewBlockFrame(nil)
+	inner := NewBlockFrame(outer)
+	v := NewLocalVariable("v", sql.Int64, outer)
+	set := func(value int64) sql.Node {
+		return NewSet([]sql.Expression{expression.NewSetField(v, expression.NewLiteral(value, sql.Int64))})
+	}
+	fail := NewSignal("45000", nil)
+
+	block := NewLabeledBeginEndBlock("", outer, []sql.Node{
+		NewDeclareVariables([]*LocalVariable{v}, expression.NewLiteral(int64(1), sql.Int64)),
+		NewLabeledBeginEndBlock("", inner, []sql.Node{
+			NewDeclareHandler(ExitHandler, []HandlerCondition{{Type: HandlerSQLState, SQLState: "45000"}}, set(2), inner),
+			fail,
+			set(3),
+		}),
+		NewIfElse([]sql.Expression{expression.NewEquals(v, expression.NewLiteral(int64(2), sql.Int64))}, [][]sql.Node{{set(4)}}, outer),
+	})
+
+	iter, err := block.RowIter(ctx, nil)
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+
+	// The exit handler ends the inner block, and the outer one goes on
+	outer.mu.Lock()
+	defer outer.mu.Unlock()
+	require.Equal(int64(4), outer.variables["v"])
+
+	// Conditions not handled by any block fail the statement
+	iter, err = NewLabeledBeginEndBlock("", NewBlockFrame(nil), []sql.Node{fail}).RowIter(ctx, nil)
+	require.NoError(err)
+	_, err = sql.RowIterToRows(iter)
+	require.Error(err)
+}
@@ -0,0 +1,118 @@
+package plan
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// GetCheckTable returns the underlying CheckTable for the table given, or nil if it isn't a CheckTable.
+func GetCheckTable(t sql.Table) sql.CheckTable {
+	switch t := t.(type) {
+	case sql.CheckTable:
+		return t
+	case sql.TableWrapper:
+		return GetCheckTable(t.Underlying())
+	default:
+		return nil
+	}
+}
+
+// CheckConstraint is an enforced check constraint of a table, with its expression resolved on the rows of the table.
+type CheckConstraint struct {
+	Name string
+	Expr sql.Expression
+}
+
+// CheckConstraints are the check constraints enforced on the rows that InsertInto and Update write to a table: the rows
+// inserted and updated can't make the expression of any of them false.
+type CheckConstraints []*CheckConstraint
+
+// check returns an error if the row given makes the expression of one of the check constraints false. Expressions that
+// are NULL for the row don't violate their constraint.
+func (cs CheckConstraints) check(ctx *sql.Context, row sql.Row) error {
+	for _, c := range cs {
+		v, err := c.Expr.Eval(ctx, row)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			continue
+		}
+		if ok, err := sql.ConvertToBool(v); err != nil {
+			return err
+		} else if !ok {
+			return sql.ErrCheckConstraintViolated.New(c.Name)
+		}
+	}
+	return nil
+}
+
+// inserter returns an inserter that checks the rows inserted by the inserter given.
+func (cs CheckConstraints) inserter(inserter sql.RowInserter) sql.RowInserter {
+	if len(cs) == 0 {
+		return inserter
+	}
+	return &checkEditor{checks: cs, inserter: inserter, closer: inserter}
+}
+
+// replacer returns a replacer that checks the rows replaced by the replacer given.
+func (cs CheckConstraints) replacer(replacer sql.RowReplacer) sql.RowReplacer {
+	if len(cs) == 0 {
+		return replacer
+	}
+	return &checkEditor{checks: cs, inserter: replacer, deleter: replacer, closer: replacer, replacing: true}
+}
+
+// updater returns an updater that checks the rows updated by the updater given.
+func (cs CheckConstraints) updater(updater sql.RowUpdater) sql.RowUpdater {
+	if len(cs) == 0 {
+		return updater
+	}
+	return &checkEditor{checks: cs, updater: updater, closer: updater}
+}
+
+// checkEditor enforces the check constraints on the rows its editors write to their table, before they're written.
+type checkEditor struct {
+	checks   CheckConstraints
+	inserter sql.RowInserter
+	updater  sql.RowUpdater
+	deleter  sql.RowDeleter
+	closer   sql.Closer
+	// replacing is whether the editor is a replacer, which is given the row it inserts to delete the row it replaces
+	replacing bool
+}
+
+var _ sql.RowReplacer = (*checkEditor)(nil)
+var _ sql.RowUpdater = (*checkEditor)(nil)
+
+// Insert implements sql.RowInserter. Replacers check the rows they insert before they delete the rows they replace.
+func (e *checkEditor) Insert(ctx *sql.Context, row sql.Row) error {
+	if !e.replacing {
+		if err := e.checks.check(ctx, row); err != nil {
+			return err
+		}
+	}
+	return e.inserter.Insert(ctx, row)
+}
+
+// Update implements sql.RowUpdater.
+func (e *checkEditor) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := e.checks.check(ctx, new); err != nil {
+		return err
+	}
+	return e.updater.Update(ctx, old, new)
+}
+
+// Delete implements sql.RowDeleter.
+func (e *checkEditor) Delete(ctx *sql.Context, row sql.Row) error {
+	if e.replacing {
+		if err := e.checks.check(ctx, row); err != nil {
+			return err
+		}
+	}
+	return e.deleter.Delete(ctx, row)
+}
+
+// Close implements sql.Closer.
+func (e *checkEditor) Close(ctx *sql.Context) error {
+	return e.closer.Close(ctx)
+}
@@ -136,6 +136,7 @@ func (c *CreateIndex) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 	}
 
 	index, err := driver.Create(
+		ctx,
 		c.CurrentDatabase,
 		table.Name(),
 		c.Name,
@@ -337,8 +338,8 @@ func NewEvalPartitionKeyValueIter(ctx *sql.Context, iter sql.PartitionIndexKeyVa
 	}
 }
 
-func (i *EvalPartitionKeyValueIter) Next() (sql.Partition, sql.IndexKeyValueIter, error) {
-	p, iter, err := i.iter.Next()
+func (i *EvalPartitionKeyValueIter) Next(ctx *sql.Context) (sql.Partition, sql.IndexKeyValueIter, error) {
+	p, iter, err := i.iter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -362,8 +363,8 @@ type evalKeyValueIter struct {
 	exprs   []sql.Expression
 }
 
-func (i *evalKeyValueIter) Next() ([]interface{}, []byte, error) {
-	vals, loc, err := i.iter.Next()
+func (i *evalKeyValueIter) Next(ctx *sql.Context) ([]interface{}, []byte, error) {
+	vals, loc, err := i.iter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -405,8 +406,8 @@ func newLoggingPartitionKeyValueIter(
 	}
 }
 
-func (i *loggingPartitionKeyValueIter) Next() (sql.Partition, sql.IndexKeyValueIter, error) {
-	p, iter, err := i.iter.Next()
+func (i *loggingPartitionKeyValueIter) Next(ctx *sql.Context) (sql.Partition, sql.IndexKeyValueIter, error) {
+	p, iter, err := i.iter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -442,7 +443,7 @@ func newLoggingKeyValueIter(
 	}
 }
 
-func (i *loggingKeyValueIter) Next() ([]interface{}, []byte, error) {
+func (i *loggingKeyValueIter) Next(ctx *sql.Context) ([]interface{}, []byte, error) {
 	if i.span == nil {
 		i.span, _ = i.ctx.Span("plan.createIndex.iterator",
 			opentracing.Tags{
@@ -469,7 +470,7 @@ func (i *loggingKeyValueIter) Next() ([]interface{}, []byte, error) {
 		i.start = time.Now()
 	}
 
-	val, loc, err := i.iter.Next()
+	val, loc, err := i.iter.Next(ctx)
 	if err != nil {
 		i.span.LogKV("error", err)
 		i.span.Finish()
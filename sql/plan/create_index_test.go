@@ -310,7 +310,7 @@ func TestCreateIndexWithIter(t *testing.T) {
 	)
 
 	for {
-		_, kviter, err := iter.Next()
+		_, kviter, err := iter.Next(ctx)
 		if err == io.EOF {
 			break
 		}
@@ -319,7 +319,7 @@ func TestCreateIndexWithIter(t *testing.T) {
 		vals = append(vals, nil)
 
 		for {
-			values, _, err := kviter.Next()
+			values, _, err := kviter.Next(ctx)
 			if err == io.EOF {
 				break
 			}
@@ -383,7 +383,7 @@ type mockDriver struct {
 var _ sql.IndexDriver = (*mockDriver)(nil)
 
 func (*mockDriver) ID() string { return "mock" }
-func (d *mockDriver) Create(db, table, id string, exprs []sql.Expression, config map[string]string) (sql.DriverIndex, error) {
+func (d *mockDriver) Create(ctx *sql.Context, db, table, id string, exprs []sql.Expression, config map[string]string) (sql.DriverIndex, error) {
 	if d.config == nil {
 		d.config = make(map[string]map[string]string)
 	}
@@ -399,7 +399,7 @@ func (d *mockDriver) Save(ctx *sql.Context, index sql.DriverIndex, iter sql.Part
 	d.saved = append(d.saved, index.ID())
 	return nil
 }
-func (d *mockDriver) Delete(index sql.DriverIndex, _ sql.PartitionIter) error {
+func (d *mockDriver) Delete(_ *sql.Context, index sql.DriverIndex, _ sql.PartitionIter) error {
 	d.deleted = append(d.deleted, index.ID())
 	return nil
 }
@@ -61,9 +61,10 @@ func (p *CrossJoin) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 	}
 
 	return sql.NewSpanIter(span, &crossJoinIterator{
-		l:  li,
-		rp: p.Right,
-		s:  ctx,
+		l:        li,
+		rp:       p.Right,
+		s:        ctx,
+		rightLen: len(p.Right.Schema()),
 	}), nil
 }
 
@@ -99,12 +100,19 @@ type crossJoinIterator struct {
 	rp rowIterProvider
 	r  sql.RowIter
 	s  *sql.Context
+	// rightLen is the length of the rows of the right side, without the row of the outer scope they start with in
+	// subqueries.
+	rightLen int
 
 	leftRow sql.Row
 }
 
 func (i *crossJoinIterator) Next() (sql.Row, error) {
 	for {
+		if err := i.s.Interrupted(); err != nil {
+			return nil, err
+		}
+
 		if i.leftRow == nil {
 			r, err := i.l.Next()
 			if err != nil {
@@ -134,11 +142,7 @@ func (i *crossJoinIterator) Next() (sql.Row, error) {
 			return nil, err
 		}
 
-		var row sql.Row
-		row = append(row, i.leftRow...)
-		row = append(row, rightRow...)
-
-		return row, nil
+		return buildJoinRow(i.leftRow, rightRow, i.rightLen), nil
 	}
 }
 
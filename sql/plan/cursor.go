@@ -0,0 +1,184 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// OpenCursor is the OPEN statement of a cursor declared by a BEGIN ... END block, which runs the query of the cursor.
+type OpenCursor struct {
+	Name  string
+	frame *BlockFrame
+}
+
+var _ sql.Node = (*OpenCursor)(nil)
+
+// NewOpenCursor creates a new OpenCursor node of the cursor with the name given declared by the block of the frame
+// given.
+func NewOpenCursor(name string, frame *BlockFrame) *OpenCursor {
+	return &OpenCursor{Name: name, frame: frame}
+}
+
+// Resolved implements the sql.Node interface.
+func (*OpenCursor) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*OpenCursor) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*OpenCursor) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (o *OpenCursor) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(o, len(children), 0)
+	}
+	return o, nil
+}
+
+func (o *OpenCursor) String() string {
+	return fmt.Sprintf("OPEN %s", o.Name)
+}
+
+// RowIter implements the sql.Node interface.
+func (o *OpenCursor) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	c := o.frame.cursor(o.Name)
+	if c == nil {
+		return nil, ErrCursorNotOpen.New()
+	}
+	if c.iter != nil {
+		return nil, ErrCursorAlreadyOpen.New()
+	}
+
+	iter, err := c.query.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	c.iter = iter
+	return sql.RowsToRowIter(), nil
+}
+
+// FetchCursor is the FETCH statement of a cursor declared by a BEGIN ... END block, which sets its variables to the
+// next row of the cursor. It raises the NOT FOUND condition once there are no more rows.
+type FetchCursor struct {
+	Name      string
+	Variables []*LocalVariable
+	frame     *BlockFrame
+}
+
+var _ sql.Node = (*FetchCursor)(nil)
+
+// NewFetchCursor creates a new FetchCursor node of the cursor with the name given declared by the block of the frame
+// given, which sets the variables given.
+func NewFetchCursor(name string, variables []*LocalVariable, frame *BlockFrame) *FetchCursor {
+	return &FetchCursor{Name: name, Variables: variables, frame: frame}
+}
+
+// Resolved implements the sql.Node interface.
+func (*FetchCursor) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*FetchCursor) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*FetchCursor) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (f *FetchCursor) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(f, len(children), 0)
+	}
+	return f, nil
+}
+
+func (f *FetchCursor) String() string {
+	names := make([]string, len(f.Variables))
+	for i, v := range f.Variables {
+		names[i] = v.Name()
+	}
+	return fmt.Sprintf("FETCH %s INTO %s", f.Name, strings.Join(names, ", "))
+}
+
+// RowIter implements the sql.Node interface.
+func (f *FetchCursor) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	c := f.frame.cursor(f.Name)
+	if c == nil || c.iter == nil {
+		return nil, ErrCursorNotOpen.New()
+	}
+
+	columns := len(c.query.Schema())
+	if columns != len(f.Variables) {
+		return nil, ErrFetchVariables.New()
+	}
+
+	next, err := c.iter.Next()
+	if err == io.EOF {
+		return nil, errNoData()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows of the queries run by triggers are prepended with the row of the trigger
+	next = next[len(next)-columns:]
+	for i, v := range f.Variables {
+		if err := v.Assign(next[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// CloseCursor is the CLOSE statement of a cursor declared by a BEGIN ... END block. Cursors left open are closed when
+// their block ends.
+type CloseCursor struct {
+	Name  string
+	frame *BlockFrame
+}
+
+var _ sql.Node = (*CloseCursor)(nil)
+
+// NewCloseCursor creates a new CloseCursor node of the cursor with the name given declared by the block of the frame
+// given.
+func NewCloseCursor(name string, frame *BlockFrame) *CloseCursor {
+	return &CloseCursor{Name: name, frame: frame}
+}
+
+// Resolved implements the sql.Node interface.
+func (*CloseCursor) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*CloseCursor) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*CloseCursor) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (c *CloseCursor) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), 0)
+	}
+	return c, nil
+}
+
+func (c *CloseCursor) String() string {
+	return fmt.Sprintf("CLOSE %s", c.Name)
+}
+
+// RowIter implements the sql.Node interface.
+func (c *CloseCursor) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	cur := c.frame.cursor(c.Name)
+	if cur == nil || cur.iter == nil {
+		return nil, ErrCursorNotOpen.New()
+	}
+
+	err := cur.iter.Close()
+	cur.iter = nil
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
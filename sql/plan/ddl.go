@@ -28,6 +28,9 @@ var ErrNullDefault = errors.NewKind("column declared not null must have a non-nu
 // ErrTableCreatedNotFound is thrown when a table is created from CREATE TABLE but cannot be found immediately afterward
 var ErrTableCreatedNotFound = errors.NewKind("table was created but could not be found")
 
+// ErrNoCheckConstraintSupport is thrown when check constraints are declared on a table that doesn't support them
+var ErrNoCheckConstraintSupport = errors.NewKind("the table does not support check constraints: %s")
+
 // ErrUnsupportedFeature is thrown when a feature is not already supported
 var ErrUnsupportedFeature = errors.NewKind("unsupported feature: %s")
 
@@ -69,6 +72,7 @@ type CreateTable struct {
 	ifNotExists bool
 	fkDefs      []*sql.ForeignKeyConstraint
 	idxDefs     []*IndexDefinition
+	chDefs      []*sql.CheckConstraint
 	like        sql.Node
 }
 
@@ -102,6 +106,18 @@ func NewCreateTableLike(db sql.Database, name string, likeTable sql.Node, ifNotE
 	}
 }
 
+// WithChecks returns this node with the check constraints given, which are created on the table once it's created.
+func (c *CreateTable) WithChecks(chDefs []*sql.CheckConstraint) *CreateTable {
+	nc := *c
+	nc.chDefs = chDefs
+	return &nc
+}
+
+// Checks returns the check constraints created on the table.
+func (c *CreateTable) Checks() []*sql.CheckConstraint {
+	return c.chDefs
+}
+
 // WithDatabase implements the sql.Databaser interface.
 func (c *CreateTable) WithDatabase(db sql.Database) (sql.Node, error) {
 	nc := *c
@@ -137,7 +153,7 @@ func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 		}
 		//TODO: in the event that foreign keys or indexes aren't supported, you'll be left with a created table and no foreign keys/indexes
 		//this also means that if a foreign key or index fails, you'll only have what was declared up to the failure
-		if len(c.idxDefs) > 0 || len(c.fkDefs) > 0 {
+		if len(c.idxDefs) > 0 || len(c.fkDefs) > 0 || len(c.chDefs) > 0 {
 			tableNode, ok, err := c.db.GetTableInsensitive(ctx, c.name)
 			if err != nil {
 				return sql.RowsToRowIter(), err
@@ -169,6 +185,17 @@ func (c *CreateTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 					}
 				}
 			}
+			if len(c.chDefs) > 0 {
+				checkAlterable, ok := tableNode.(sql.CheckAlterableTable)
+				if !ok {
+					return sql.RowsToRowIter(), ErrNoCheckConstraintSupport.New(c.name)
+				}
+				for _, chDef := range c.chDefs {
+					if err = checkAlterable.CreateCheck(ctx, *chDef); err != nil {
+						return sql.RowsToRowIter(), err
+					}
+				}
+			}
 		}
 		return sql.RowsToRowIter(), nil
 	}
@@ -301,6 +328,7 @@ func (d *DropTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 	}
 
 	var err error
+	var tables []sql.Table
 	for _, tableName := range d.names {
 		tbl, ok, err := d.db.GetTableInsensitive(ctx, tableName)
 
@@ -315,6 +343,15 @@ func (d *DropTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 
 			return nil, sql.ErrTableNotFound.New(tableName)
 		}
+
+		// Tables referenced by the foreign keys of tables that aren't dropped with them can't be dropped
+		if err := NewForeignKeys(d.db).checkDrop(ctx, tbl, d.names); err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+
+	for _, tbl := range tables {
 		err = droppable.DropTable(ctx, tbl.Name())
 		if err != nil {
 			return nil, err
@@ -370,6 +407,16 @@ func NewRenameTable(db sql.Database, oldNames, newNames []string) *RenameTable {
 	}
 }
 
+// OldNames returns the names of the tables to rename.
+func (r *RenameTable) OldNames() []string {
+	return r.oldNames
+}
+
+// NewNames returns the names the tables are renamed to.
+func (r *RenameTable) NewNames() []string {
+	return r.newNames
+}
+
 func (r *RenameTable) WithDatabase(db sql.Database) (sql.Node, error) {
 	nr := *r
 	nr.db = db
@@ -558,6 +605,10 @@ func NewDropColumn(db sql.Database, tableName string, column string) *DropColumn
 	}
 }
 
+func (d *DropColumn) TableName() string {
+	return d.tableName
+}
+
 func (d *DropColumn) WithDatabase(db sql.Database) (sql.Node, error) {
 	nd := *d
 	nd.db = db
@@ -633,6 +684,10 @@ func NewRenameColumn(db sql.Database, tableName string, columnName string, newCo
 	}
 }
 
+func (r *RenameColumn) TableName() string {
+	return r.tableName
+}
+
 func (r *RenameColumn) WithDatabase(db sql.Database) (sql.Node, error) {
 	nr := *r
 	nr.db = db
@@ -0,0 +1,275 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// DeclareVariables is the DECLARE statement of the local variables of a BEGIN ... END block, which sets them to their
+// default value, or to NULL if they don't have one.
+type DeclareVariables struct {
+	Variables []*LocalVariable
+	// Default is the default value of the variables, or nil if they don't have one.
+	Default sql.Expression
+}
+
+var _ sql.Node = (*DeclareVariables)(nil)
+var _ sql.Expressioner = (*DeclareVariables)(nil)
+
+// NewDeclareVariables creates a new DeclareVariables node.
+func NewDeclareVariables(variables []*LocalVariable, def sql.Expression) *DeclareVariables {
+	return &DeclareVariables{Variables: variables, Default: def}
+}
+
+// Resolved implements the sql.Node interface.
+func (d *DeclareVariables) Resolved() bool {
+	return d.Default == nil || d.Default.Resolved()
+}
+
+// Children implements the sql.Node interface.
+func (*DeclareVariables) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*DeclareVariables) Schema() sql.Schema { return nil }
+
+// Expressions implements the sql.Expressioner interface.
+func (d *DeclareVariables) Expressions() []sql.Expression {
+	if d.Default == nil {
+		return nil
+	}
+	return []sql.Expression{d.Default}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (d *DeclareVariables) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(d.Expressions()) {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(exprs), len(d.Expressions()))
+	}
+
+	nd := *d
+	if len(exprs) > 0 {
+		nd.Default = exprs[0]
+	}
+	return &nd, nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (d *DeclareVariables) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 0)
+	}
+	return d, nil
+}
+
+func (d *DeclareVariables) String() string {
+	names := make([]string, len(d.Variables))
+	for i, v := range d.Variables {
+		names[i] = v.Name()
+	}
+
+	str := fmt.Sprintf("DECLARE %s %s", strings.Join(names, ", "), d.Variables[0].Type())
+	if d.Default != nil {
+		str += fmt.Sprintf(" DEFAULT %s", d.Default)
+	}
+	return str
+}
+
+// RowIter implements the sql.Node interface.
+func (d *DeclareVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var value interface{}
+	if d.Default != nil {
+		var err error
+		if value, err = d.Default.Eval(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range d.Variables {
+		if err := v.Assign(value); err != nil {
+			return nil, err
+		}
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// DeclareCursor is the DECLARE statement of a cursor of a BEGIN ... END block, which the statements of the block can
+// OPEN to FETCH the rows of its query one by one.
+type DeclareCursor struct {
+	UnaryNode
+	Name  string
+	frame *BlockFrame
+}
+
+var _ sql.Node = (*DeclareCursor)(nil)
+
+// NewDeclareCursor creates a new DeclareCursor node of the cursor with the name and query given, declared by the block
+// of the frame given.
+func NewDeclareCursor(name string, query sql.Node, frame *BlockFrame) *DeclareCursor {
+	return &DeclareCursor{UnaryNode: UnaryNode{Child: query}, Name: name, frame: frame}
+}
+
+// Schema implements the sql.Node interface.
+func (*DeclareCursor) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (d *DeclareCursor) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 1)
+	}
+	return NewDeclareCursor(d.Name, children[0], d.frame), nil
+}
+
+func (d *DeclareCursor) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DECLARE %s CURSOR", d.Name)
+	_ = pr.WriteChildren(d.Child.String())
+	return pr.String()
+}
+
+// RowIter implements the sql.Node interface.
+func (d *DeclareCursor) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	d.frame.declareCursor(d.Name, d.Child)
+	return sql.RowsToRowIter(), nil
+}
+
+// HandlerAction is what a block does after a condition handler of the block has run.
+type HandlerAction string
+
+const (
+	// ContinueHandler handlers let the block go on with the statement after the one that raised the condition.
+	ContinueHandler HandlerAction = "CONTINUE"
+	// ExitHandler handlers end the block that declared them.
+	ExitHandler HandlerAction = "EXIT"
+)
+
+// HandlerConditionType is the type of the conditions handled by a HandlerCondition.
+type HandlerConditionType byte
+
+const (
+	// HandlerNotFound handles the conditions of the class 02, such as the one raised by FETCH once there are no more
+	// rows.
+	HandlerNotFound HandlerConditionType = iota
+	// HandlerSQLException handles the conditions of every class but 00, 01 and 02, which are the errors.
+	HandlerSQLException
+	// HandlerSQLWarning handles the conditions of the class 01. Warnings don't stop the statements that raise them,
+	// so they don't run their handlers.
+	HandlerSQLWarning
+	// HandlerSQLState handles the conditions of a SQLSTATE.
+	HandlerSQLState
+	// HandlerErrorCode handles the conditions of a MySQL error code.
+	HandlerErrorCode
+)
+
+// HandlerCondition is one of the conditions handled by a condition handler.
+type HandlerCondition struct {
+	Type HandlerConditionType
+	// SQLState is the SQLSTATE of HandlerSQLState conditions.
+	SQLState string
+	// Code is the error code of HandlerErrorCode conditions.
+	Code int
+}
+
+func (c HandlerCondition) String() string {
+	switch c.Type {
+	case HandlerNotFound:
+		return "NOT FOUND"
+	case HandlerSQLException:
+		return "SQLEXCEPTION"
+	case HandlerSQLWarning:
+		return "SQLWARNING"
+	case HandlerSQLState:
+		return fmt.Sprintf("SQLSTATE '%s'", c.SQLState)
+	default:
+		return fmt.Sprint(c.Code)
+	}
+}
+
+// DeclareHandler is the DECLARE statement of a condition handler of a BEGIN ... END block, which runs its statement
+// when a statement of the block, or of a block nested in it without a handler of its own, raises one of its
+// conditions. ContinueHandler handlers then let the block go on, and ExitHandler handlers end it.
+type DeclareHandler struct {
+	UnaryNode
+	Action     HandlerAction
+	Conditions []HandlerCondition
+	frame      *BlockFrame
+}
+
+var _ sql.Node = (*DeclareHandler)(nil)
+
+// NewDeclareHandler creates a new DeclareHandler node of the condition handler with the action, conditions and
+// statement given, declared by the block of the frame given.
+func NewDeclareHandler(action HandlerAction, conditions []HandlerCondition, statement sql.Node, frame *BlockFrame) *DeclareHandler {
+	return &DeclareHandler{UnaryNode: UnaryNode{Child: statement}, Action: action, Conditions: conditions, frame: frame}
+}
+
+// Statement returns the statement run by the handler.
+func (d *DeclareHandler) Statement() sql.Node {
+	return d.Child
+}
+
+// Schema implements the sql.Node interface.
+func (*DeclareHandler) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (d *DeclareHandler) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 1)
+	}
+	return NewDeclareHandler(d.Action, d.Conditions, children[0], d.frame), nil
+}
+
+func (d *DeclareHandler) String() string {
+	conditions := make([]string, len(d.Conditions))
+	for i, c := range d.Conditions {
+		conditions[i] = c.String()
+	}
+
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("DECLARE %s HANDLER FOR %s", d.Action, strings.Join(conditions, ", "))
+	_ = pr.WriteChildren(d.Child.String())
+	return pr.String()
+}
+
+// RowIter implements the sql.Node interface.
+func (d *DeclareHandler) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	d.frame.declareHandler(d)
+	return sql.RowsToRowIter(), nil
+}
+
+// matches returns how specifically the handler handles the condition with the SQLSTATE and error code given: 3 for
+// its error code, 2 for its SQLSTATE, 1 for its class, and 0 if it doesn't handle it.
+func (d *DeclareHandler) matches(sqlState string, code int) int {
+	class := sqlState
+	if len(class) > 2 {
+		class = class[:2]
+	}
+
+	rank := 0
+	for _, c := range d.Conditions {
+		r := 0
+		switch c.Type {
+		case HandlerErrorCode:
+			if c.Code == code {
+				r = 3
+			}
+		case HandlerSQLState:
+			if c.SQLState == sqlState {
+				r = 2
+			}
+		case HandlerNotFound:
+			if class == "02" {
+				r = 1
+			}
+		case HandlerSQLException:
+			if class != "00" && class != "01" && class != "02" {
+				r = 1
+			}
+		}
+		if r > rank {
+			rank = r
+		}
+	}
+	return rank
+}
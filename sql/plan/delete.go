@@ -11,11 +11,13 @@ var ErrDeleteFromNotSupported = errors.NewKind("table doesn't support DELETE FRO
 // DeleteFrom is a node describing a deletion from some table.
 type DeleteFrom struct {
 	UnaryNode
+	// ForeignKeys are the foreign keys enforced on the rows deleted, if any.
+	ForeignKeys *ForeignKeys
 }
 
 // NewDeleteFrom creates a DeleteFrom node.
 func NewDeleteFrom(n sql.Node) *DeleteFrom {
-	return &DeleteFrom{UnaryNode{n}}
+	return &DeleteFrom{UnaryNode: UnaryNode{n}}
 }
 
 func getDeletable(node sql.Node) (sql.DeletableTable, error) {
@@ -57,7 +59,7 @@ func (p *DeleteFrom) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 		return nil, err
 	}
 
-	deleter := deletable.Deleter(ctx)
+	deleter := p.ForeignKeys.deleter(ctx, deletable, deletable.Deleter(ctx))
 
 	return newDeleteIter(iter, deleter, deletable.Schema(), ctx), nil
 }
@@ -106,7 +108,9 @@ func (p *DeleteFrom) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 1 {
 		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
 	}
-	return NewDeleteFrom(children[0]), nil
+	np := *p
+	np.Child = children[0]
+	return &np, nil
 }
 
 func (p DeleteFrom) String() string {
@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"fmt"
 	"io"
 	"strings"
 
@@ -68,10 +69,24 @@ func (i *describeIter) Close() error {
 	return nil
 }
 
+// DescribeFormatCost is the format of a DescribeQuery that describes the estimated cost and number of rows of every
+// node of the query plan, next to the node.
+const DescribeFormatCost = "cost"
+
 // DescribeQuery returns the description of the query plan.
 type DescribeQuery struct {
 	UnaryNode
 	Format string
+	// Estimate is the estimate of the costs of the query plan described in the cost format, set by the analyzer.
+	Estimate *CostEstimate
+}
+
+// CostEstimate is the estimated cost of reading all of the rows of a node and the estimated number of rows it returns,
+// along with the estimates of its children.
+type CostEstimate struct {
+	Cost     float64
+	Rows     float64
+	Children []*CostEstimate
 }
 
 // DescribeSchema is the schema returned by a DescribeQuery node.
@@ -81,7 +96,14 @@ var DescribeSchema = sql.Schema{
 
 // NewDescribeQuery creates a new DescribeQuery node.
 func NewDescribeQuery(format string, child sql.Node) *DescribeQuery {
-	return &DescribeQuery{UnaryNode{Child: child}, format}
+	return &DescribeQuery{UnaryNode: UnaryNode{Child: child}, Format: format}
+}
+
+// WithEstimate returns a copy of this node with the estimate of the costs of the plan given.
+func (d *DescribeQuery) WithEstimate(estimate *CostEstimate) *DescribeQuery {
+	nd := *d
+	nd.Estimate = estimate
+	return &nd
 }
 
 // Schema implements the Node interface.
@@ -91,8 +113,13 @@ func (d *DescribeQuery) Schema() sql.Schema {
 
 // RowIter implements the Node interface.
 func (d *DescribeQuery) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	description := d.Child.String()
+	if d.Format == DescribeFormatCost && d.Estimate != nil {
+		description = describeCosts(d.Child, d.Estimate)
+	}
+
 	var rows []sql.Row
-	for _, l := range strings.Split(d.Child.String(), "\n") {
+	for _, l := range strings.Split(description, "\n") {
 		if strings.TrimSpace(l) != "" {
 			rows = append(rows, sql.NewRow(l))
 		}
@@ -100,6 +127,37 @@ func (d *DescribeQuery) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, err
 	return sql.RowsToRowIter(rows...), nil
 }
 
+// describeCosts returns the description of the node given like its String method, with the estimated cost and number
+// of rows of every node next to it. Nodes without children are described by their String method entirely, and nodes
+// with children by the first line of it, which describes the node itself.
+func describeCosts(n sql.Node, estimate *CostEstimate) string {
+	children := n.Children()
+	description := n.String()
+	if len(children) > 0 {
+		description = strings.SplitN(description, "\n", 2)[0]
+	}
+
+	lines := strings.SplitN(description, "\n", 2)
+	lines[0] = fmt.Sprintf("%s (cost=%.2f rows=%.2f)", lines[0], estimate.Cost, estimate.Rows)
+	if len(children) == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	childDescriptions := make([]string, len(children))
+	for i, child := range children {
+		if i < len(estimate.Children) {
+			childDescriptions[i] = describeCosts(child, estimate.Children[i])
+		} else {
+			childDescriptions[i] = child.String()
+		}
+	}
+
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("%s", lines[0])
+	_ = pr.WriteChildren(childDescriptions...)
+	return pr.String()
+}
+
 func (d *DescribeQuery) String() string {
 	pr := sql.NewTreePrinter()
 	_ = pr.WriteNode("DescribeQuery(format=%s)", d.Format)
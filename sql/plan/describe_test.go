@@ -89,3 +89,41 @@ func TestDescribeQuery(t *testing.T) {
 
 	require.Equal(expected, rows)
 }
+
+func TestDescribeQueryCosts(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("foo", sql.Schema{
+		{Source: "foo", Name: "a", Type: sql.Text},
+	})
+
+	node := NewDescribeQuery(DescribeFormatCost, NewCrossJoin(
+		NewFilter(
+			expression.NewEquals(
+				expression.NewGetFieldWithTable(0, sql.Text, "foo", "a", false),
+				expression.NewLiteral("foo", sql.LongText),
+			),
+			NewResolvedTable(table),
+		),
+		NewTableAlias("bar", NewResolvedTable(table)),
+	)).WithEstimate(&CostEstimate{Cost: 22, Rows: 15, Children: []*CostEstimate{
+		{Cost: 11, Rows: 1.5, Children: []*CostEstimate{{Cost: 11, Rows: 10}}},
+		{Cost: 7.5, Rows: 10, Children: []*CostEstimate{{Cost: 7.5, Rows: 10}}},
+	}})
+
+	iter, err := node.RowIter(sql.NewEmptyContext(), nil)
+	require.NoError(err)
+
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+
+	expected := []sql.Row{
+		{"CrossJoin (cost=22.00 rows=15.00)"},
+		{" ├─ Filter(foo.a = \"foo\") (cost=11.00 rows=1.50)"},
+		{" │   └─ Table(foo) (cost=11.00 rows=10.00)"},
+		{" └─ TableAlias(bar) (cost=7.50 rows=10.00)"},
+		{"     └─ Table(foo) (cost=7.50 rows=10.00)"},
+	}
+
+	require.Equal(expected, rows)
+}
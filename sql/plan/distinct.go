@@ -58,11 +58,14 @@ func (d Distinct) String() string {
 // Even though they are just 64-bit integers, this could be a problem in large
 // result sets.
 type distinctIter struct {
-	childIter sql.RowIter
-	seen      sql.KeyValueCache
-	dispose   sql.DisposeFunc
+	childIter  sql.RowIter
+	childBatch sql.BatchRowIter
+	seen       sql.KeyValueCache
+	dispose    sql.DisposeFunc
 }
 
+var _ sql.BatchRowIter = (*distinctIter)(nil)
+
 func newDistinctIter(ctx *sql.Context, child sql.RowIter) *distinctIter {
 	cache, dispose := ctx.Memory.NewHistoryCache()
 	return &distinctIter{
@@ -82,17 +85,64 @@ func (di *distinctIter) Next() (sql.Row, error) {
 			return nil, err
 		}
 
-		hash := sql.CacheKey(row)
-		if _, err := di.seen.Get(hash); err == nil {
-			continue
+		ok, err := di.isNew(row)
+		if err != nil {
+			return nil, err
 		}
 
-		if err := di.seen.Put(hash, struct{}{}); err != nil {
-			return nil, err
+		if ok {
+			return row, nil
 		}
+	}
+}
 
-		return row, nil
+// NextBatch implements the sql.BatchRowIter interface. The rows of every batch of the child that have been seen
+// already are removed from it; batches without any new row are skipped.
+func (di *distinctIter) NextBatch(rows []sql.Row) (int, error) {
+	if di.childBatch == nil {
+		di.childBatch = sql.NewBatchRowIter(di.childIter)
 	}
+
+	for {
+		n, err := di.childBatch.NextBatch(rows)
+		if err != nil {
+			if err == io.EOF {
+				di.Dispose()
+			}
+			return 0, err
+		}
+
+		var distinct int
+		for _, row := range rows[:n] {
+			ok, err := di.isNew(row)
+			if err != nil {
+				return 0, err
+			}
+
+			if ok {
+				rows[distinct] = row
+				distinct++
+			}
+		}
+
+		if distinct > 0 {
+			return distinct, nil
+		}
+	}
+}
+
+// isNew returns whether the row given hasn't been seen yet, and records it as seen.
+func (di *distinctIter) isNew(row sql.Row) (bool, error) {
+	hash := sql.CacheKey(row)
+	if _, err := di.seen.Get(hash); err == nil {
+		return false, nil
+	}
+
+	if err := di.seen.Put(hash, struct{}{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (di *distinctIter) Close() error {
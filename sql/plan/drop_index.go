@@ -97,7 +97,7 @@ func (d *DropIndex) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 		return nil, err
 	}
 
-	if err := driver.Delete(index, partitions); err != nil {
+	if err := driver.Delete(ctx, index, partitions); err != nil {
 		return nil, err
 	}
 
@@ -21,6 +21,11 @@ func NewSingleDropView(
 	return &SingleDropView{database, viewName}
 }
 
+// ViewName returns the name of the view dropped.
+func (dv *SingleDropView) ViewName() string {
+	return dv.viewName
+}
+
 // Children implements the Node interface. It always returns nil.
 func (dv *SingleDropView) Children() []sql.Node {
 	return nil
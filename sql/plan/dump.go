@@ -0,0 +1,399 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// DefaultDumpRowsPerInsert is the number of rows of each INSERT statement of dumps that don't set it.
+const DefaultDumpRowsPerInsert = 1000
+
+// DumpOptions are the options of a dump of databases.
+type DumpOptions struct {
+	// Databases are the names of the databases to dump. Dumps of no databases dump the current database.
+	Databases []string
+	// RowsPerInsert is the maximum number of rows of each INSERT statement, or DefaultDumpRowsPerInsert if it's 0.
+	RowsPerInsert int
+	// CreateDatabases adds CREATE DATABASE and USE statements for each database to the dump, which dumps of more than
+	// one database always have.
+	CreateDatabases bool
+}
+
+// DumpDatabase is the DUMP DATABASE statement, which returns a dump of databases with the statements that recreate
+// them in rows of one column, in the format of mysqldump.
+type DumpDatabase struct {
+	Options DumpOptions
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*DumpDatabase)(nil)
+
+// DumpSchema is the schema of the rows of dumps.
+var DumpSchema = sql.Schema{{Name: "Dump", Type: sql.LongText}}
+
+// NewDumpDatabase creates a new DumpDatabase node of the databases with the names given.
+func NewDumpDatabase(databases []string) *DumpDatabase {
+	return &DumpDatabase{Options: DumpOptions{Databases: databases}}
+}
+
+// Resolved implements the sql.Node interface.
+func (d *DumpDatabase) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (d *DumpDatabase) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (d *DumpDatabase) Schema() sql.Schema { return DumpSchema }
+
+// WithChildren implements the sql.Node interface.
+func (d *DumpDatabase) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 0)
+	}
+	return d, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (d *DumpDatabase) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return NewDumpIter(ctx, d.Catalog, d.Options)
+}
+
+func (d *DumpDatabase) String() string {
+	if len(d.Options.Databases) == 0 {
+		return "DUMP DATABASE"
+	}
+	return "DUMP DATABASE " + strings.Join(d.Options.Databases, ", ")
+}
+
+// dumpedDatabase is a database being dumped.
+type dumpedDatabase struct {
+	db sql.Database
+	// tables is the database the tables are read from, which is the transaction of the dump if the database is a
+	// sql.SnapshotDatabase.
+	tables sql.Database
+	tx     sql.SnapshotTransaction
+}
+
+// dumpIter returns the statements of a dump, one for each row, with the comments that go before them.
+type dumpIter struct {
+	ctx  *sql.Context
+	dbs  []dumpedDatabase
+	opts DumpOptions
+
+	started bool
+	done    bool
+	// pending are the statements to return before dumping anything else.
+	pending []string
+	// db is the index of the database being dumped, and tableNames and table are its tables and the index of the next
+	// one to dump, or nil before the database is started.
+	db         int
+	tableNames []string
+	table      int
+	// rows are the rows of the table whose rows are being dumped.
+	rows      sql.RowIter
+	rowsTable sql.Table
+}
+
+// NewDumpIter returns an iterator over the statements of a dump of the databases of the catalog with the options
+// given, in rows of one column. The tables of databases that are sql.SnapshotDatabases are read in transactions started
+// before any of them is read, so they are dumped as they were at the same point in time, and the transactions are
+// committed when the iterator is closed. Tables are read directly, regardless of the row policies and column masks of
+// the catalog.
+func NewDumpIter(ctx *sql.Context, catalog *sql.Catalog, opts DumpOptions) (sql.RowIter, error) {
+	names := opts.Databases
+	if len(names) == 0 {
+		if ctx.GetCurrentDatabase() == "" {
+			return nil, sql.ErrNoDatabaseSelected.New()
+		}
+		names = []string{ctx.GetCurrentDatabase()}
+	}
+	if len(names) > 1 {
+		opts.CreateDatabases = true
+	}
+	if opts.RowsPerInsert <= 0 {
+		opts.RowsPerInsert = DefaultDumpRowsPerInsert
+	}
+
+	iter := &dumpIter{ctx: ctx, opts: opts}
+	for _, name := range names {
+		db, err := catalog.SessionDatabase(ctx, name)
+		if err != nil {
+			_ = iter.Close()
+			return nil, err
+		}
+
+		dumped := dumpedDatabase{db: db, tables: db}
+		if sdb, ok := db.(sql.SnapshotDatabase); ok {
+			tx, err := sdb.StartSnapshotTransaction(ctx)
+			if err != nil {
+				_ = iter.Close()
+				return nil, err
+			}
+			dumped.tables = tx
+			dumped.tx = tx
+		}
+		iter.dbs = append(iter.dbs, dumped)
+	}
+	return iter, nil
+}
+
+// Next implements the sql.RowIter interface.
+func (i *dumpIter) Next() (sql.Row, error) {
+	if err := i.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stmt, err := i.next()
+	if err != nil {
+		return nil, err
+	}
+	return sql.NewRow(stmt), nil
+}
+
+func (i *dumpIter) next() (string, error) {
+	if !i.started {
+		i.started = true
+		// Tables are dumped in the order of their names, so their rows may reference the rows of tables dumped later
+		i.pending = []string{"SET @old_foreign_key_checks=@@foreign_key_checks, foreign_key_checks=0;"}
+		return "-- go-mysql-server dump\n" +
+			"-- ------------------------------------------------------\n\n" +
+			"/*!40101 SET NAMES utf8mb4 */;", nil
+	}
+
+	for {
+		if len(i.pending) > 0 {
+			stmt := i.pending[0]
+			i.pending = i.pending[1:]
+			return stmt, nil
+		}
+
+		if i.rows != nil {
+			stmt, err := i.insert()
+			if err != nil {
+				return "", err
+			}
+			if stmt != "" {
+				return stmt, nil
+			}
+			continue
+		}
+
+		if i.db >= len(i.dbs) {
+			if i.done {
+				return "", io.EOF
+			}
+			i.done = true
+			i.pending = []string{"\n-- Dump completed"}
+			return "\nSET foreign_key_checks=@old_foreign_key_checks;", nil
+		}
+		db := i.dbs[i.db]
+
+		if i.tableNames == nil {
+			names, err := db.tables.GetTableNames(i.ctx)
+			if err != nil {
+				return "", err
+			}
+			sort.Strings(names)
+			i.tableNames = append([]string{}, names...)
+
+			if i.opts.CreateDatabases {
+				name := quoteIdentifier(db.db.Name())
+				i.pending = []string{
+					fmt.Sprintf("\n--\n-- Current Database: %s\n--\n\nCREATE DATABASE /*!32312 IF NOT EXISTS*/ %s;", name, name),
+					fmt.Sprintf("\nUSE %s;", name),
+				}
+			}
+			continue
+		}
+
+		if i.table < len(i.tableNames) {
+			name := i.tableNames[i.table]
+			i.table++
+			stmts, err := i.createTable(db, name)
+			if err != nil {
+				return "", err
+			}
+			i.pending = stmts
+			continue
+		}
+
+		// Views and triggers go after the tables, since they reference them
+		stmts, err := i.databaseObjects(db)
+		if err != nil {
+			return "", err
+		}
+		i.pending = stmts
+		i.db++
+		i.tableNames = nil
+		i.table = 0
+	}
+}
+
+// createTable returns the statements that recreate the table given, and starts reading its rows. The comment before
+// its rows is returned as a statement of its own, which is empty.
+func (i *dumpIter) createTable(db dumpedDatabase, name string) ([]string, error) {
+	table, ok, err := db.tables.GetTableInsensitive(i.ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, sql.ErrTableNotFound.New(name)
+	}
+
+	var indexes []sql.Index
+	if it, ok := table.(sql.IndexedTable); ok {
+		if indexes, err = it.GetIndexes(i.ctx); err != nil {
+			return nil, err
+		}
+	}
+	create, err := CreateTableStatement(i.ctx, table, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	partitions, err := table.Partitions(i.ctx)
+	if err != nil {
+		return nil, err
+	}
+	i.rows = sql.NewTableRowIter(i.ctx, table, partitions)
+	i.rowsTable = table
+
+	quoted := quoteIdentifier(table.Name())
+	return []string{
+		fmt.Sprintf("\n--\n-- Table structure for table %s\n--\n\nDROP TABLE IF EXISTS %s;", quoted, quoted),
+		create + ";",
+		fmt.Sprintf("\n--\n-- Dumping data for table %s\n--\n", quoted),
+	}, nil
+}
+
+// insert returns an INSERT statement with the next rows of the table being dumped, or an empty statement once all of
+// its rows have been dumped.
+func (i *dumpIter) insert() (string, error) {
+	schema := i.rowsTable.Schema()
+
+	var buf bytes.Buffer
+	var n int
+	for ; n < i.opts.RowsPerInsert; n++ {
+		row, err := i.rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if n == 0 {
+			fmt.Fprintf(&buf, "INSERT INTO %s VALUES ", quoteIdentifier(i.rowsTable.Name()))
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('(')
+		for j, v := range row {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeDumpValue(&buf, schema[j].Type, v); err != nil {
+				return "", err
+			}
+		}
+		buf.WriteByte(')')
+	}
+
+	if n < i.opts.RowsPerInsert {
+		err := i.rows.Close()
+		i.rows = nil
+		i.rowsTable = nil
+		if err != nil {
+			return "", err
+		}
+	}
+	if n == 0 {
+		return "", nil
+	}
+	buf.WriteByte(';')
+	return buf.String(), nil
+}
+
+// databaseObjects returns the statements that recreate the views and triggers of the database given. Triggers are
+// created with ;; as the delimiter, like mysqldump does, since their bodies may have many statements.
+func (i *dumpIter) databaseObjects(db dumpedDatabase) ([]string, error) {
+	var stmts []string
+
+	var views []sql.View
+	if i.ctx.ViewRegistry != nil {
+		views = i.ctx.ViewRegistry.ViewsInDatabase(strings.ToLower(db.db.Name()))
+	}
+	sort.Slice(views, func(a, b int) bool { return views[a].Name() < views[b].Name() })
+	for _, view := range views {
+		quoted := quoteIdentifier(view.Name())
+		stmts = append(stmts,
+			fmt.Sprintf("\n--\n-- View structure for view %s\n--\n\nDROP VIEW IF EXISTS %s;", quoted, quoted),
+			fmt.Sprintf("CREATE VIEW %s AS %s;", quoted, view.TextDefinition()),
+		)
+	}
+
+	if tdb, ok := db.db.(sql.TriggerDatabase); ok {
+		triggers, err := tdb.GetTriggers(i.ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, trigger := range triggers {
+			stmts = append(stmts, fmt.Sprintf("\nDELIMITER ;;\n%s ;;\nDELIMITER ;", trigger.CreateStatement))
+		}
+	}
+
+	return stmts, nil
+}
+
+// Close implements the sql.RowIter interface. It commits the transactions of the dump.
+func (i *dumpIter) Close() error {
+	var err error
+	if i.rows != nil {
+		err = i.rows.Close()
+		i.rows = nil
+	}
+	for _, db := range i.dbs {
+		if db.tx != nil {
+			if e := db.tx.Commit(i.ctx); err == nil {
+				err = e
+			}
+		}
+	}
+	i.dbs = nil
+	return err
+}
+
+// writeDumpValue writes the value given, of the type given, as an SQL literal. Binary values are written in
+// hexadecimal, like mysqldump --hex-blob does, so they stay the same regardless of the character set of the client.
+func writeDumpValue(buf *bytes.Buffer, typ sql.Type, v interface{}) error {
+	if v == nil {
+		buf.WriteString("NULL")
+		return nil
+	}
+
+	value, err := typ.SQL(v)
+	if err != nil {
+		return err
+	}
+	if sql.IsBlob(typ) {
+		if len(value.Raw()) == 0 {
+			buf.WriteString("''")
+		} else {
+			buf.WriteString("0x")
+			buf.WriteString(hex.EncodeToString(value.Raw()))
+		}
+		return nil
+	}
+	value.EncodeSQL(buf)
+	return nil
+}
+
+func quoteIdentifier(id string) string {
+	return "`" + strings.Replace(id, "`", "``", -1) + "`"
+}
@@ -20,8 +20,13 @@ var ErrNoPartitionable = errors.NewKind("no partitionable node found in exchange
 type Exchange struct {
 	UnaryNode
 	Parallelism int
+	// ResourceGroups schedules the reads of the partitions among the resource groups of the sessions, if set.
+	ResourceGroups *sql.ResourceGroupRegistry
 }
 
+// exchangeBatchSize is the number of rows of a partition read while holding an execution slot.
+const exchangeBatchSize = 64
+
 // NewExchange creates a new Exchange node.
 func NewExchange(
 	parallelism int,
@@ -52,7 +57,9 @@ func (e *Exchange) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 		return nil, err
 	}
 
-	return newExchangeRowIter(ctx, e.Parallelism, partitions, row, e.Child), nil
+	iter := newExchangeRowIter(ctx, e.Parallelism, partitions, row, e.Child)
+	iter.resourceGroups = e.ResourceGroups
+	return iter, nil
 }
 
 func (e *Exchange) String() string {
@@ -75,7 +82,9 @@ func (e *Exchange) WithChildren(children ...sql.Node) (sql.Node, error) {
 		return nil, sql.ErrInvalidChildrenNumber.New(e, len(children), 1)
 	}
 
-	return NewExchange(e.Parallelism, children[0]), nil
+	ne := *e
+	ne.Child = children[0]
+	return &ne, nil
 }
 
 type exchangeRowIter struct {
@@ -90,6 +99,8 @@ type exchangeRowIter struct {
 	rows        chan sql.Row
 	err         chan error
 
+	resourceGroups *sql.ResourceGroupRegistry
+
 	quitMut  sync.RWMutex
 	quitChan chan struct{}
 }
@@ -203,7 +214,7 @@ func (it *exchangeRowIter) iterPartitions(ch chan<- sql.Partition) {
 		case <-it.tokens():
 		}
 
-		p, err := it.partitions.Next()
+		p, err := it.partitions.Next(it.ctx)
 		if err != nil {
 			if err != io.EOF {
 				it.err <- err
@@ -250,7 +261,11 @@ func (it *exchangeRowIter) iterPartition(p sql.Partition) {
 		default:
 		}
 
-		row, err := rows.Next()
+		batch, err := it.nextBatch(rows)
+		for _, row := range batch {
+			it.rows <- row
+		}
+
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -259,9 +274,36 @@ func (it *exchangeRowIter) iterPartition(p sql.Partition) {
 			it.err <- err
 			return
 		}
+	}
+}
 
-		it.rows <- row
+// nextBatch reads the next rows of a partition. With resource groups, rows are read in batches while holding an
+// execution slot of the resource group of the session, so partitions are read at the pace the weight of the group
+// allows. The slot is released before the rows are sent, so queries whose rows aren't consumed don't hold slots.
+func (it *exchangeRowIter) nextBatch(rows sql.RowIter) ([]sql.Row, error) {
+	if it.resourceGroups == nil {
+		row, err := rows.Next()
+		if err != nil {
+			return nil, err
+		}
+		return []sql.Row{row}, nil
+	}
+
+	release, err := it.resourceGroups.AcquireSlot(it.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	batch := make([]sql.Row, 0, exchangeBatchSize)
+	for len(batch) < exchangeBatchSize {
+		row, err := rows.Next()
+		if err != nil {
+			return batch, err
+		}
+		batch = append(batch, row)
 	}
+	return batch, nil
 }
 
 func (it *exchangeRowIter) Next() (sql.Row, error) {
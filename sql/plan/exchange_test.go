@@ -145,7 +145,7 @@ type exchangePartitionIter struct {
 	num int
 }
 
-func (i *exchangePartitionIter) Next() (sql.Partition, error) {
+func (i *exchangePartitionIter) Next(*sql.Context) (sql.Partition, error) {
 	if i.num <= 0 {
 		return nil, io.EOF
 	}
@@ -183,7 +183,7 @@ type partitionPanic struct {
 	closed bool
 }
 
-func (*partitionPanic) Next() (sql.Partition, error) {
+func (*partitionPanic) Next(*sql.Context) (sql.Partition, error) {
 	panic("partitionPanic.Next")
 }
 
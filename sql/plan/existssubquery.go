@@ -0,0 +1,66 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ExistsSubquery is an expression that checks whether a subquery returns any row. Like InSubquery, it's in the plan
+// package because Subquery is.
+type ExistsSubquery struct {
+	Query *Subquery
+}
+
+var _ sql.Expression = (*ExistsSubquery)(nil)
+
+// NewExistsSubquery creates an ExistsSubquery expression.
+func NewExistsSubquery(query *Subquery) *ExistsSubquery {
+	return &ExistsSubquery{query}
+}
+
+// Resolved implements the Expression interface.
+func (e *ExistsSubquery) Resolved() bool {
+	return e.Query.Resolved()
+}
+
+// Type implements the Expression interface.
+func (e *ExistsSubquery) Type() sql.Type {
+	return sql.Boolean
+}
+
+// IsNullable implements the Expression interface.
+func (e *ExistsSubquery) IsNullable() bool {
+	return false
+}
+
+// Eval implements the Expression interface.
+func (e *ExistsSubquery) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	return e.Query.HasResultRow(ctx, row)
+}
+
+// Children implements the Expression interface.
+func (e *ExistsSubquery) Children() []sql.Expression {
+	return []sql.Expression{e.Query}
+}
+
+// WithChildren implements the Expression interface.
+func (e *ExistsSubquery) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(e, len(children), 1)
+	}
+
+	query, ok := children[0].(*Subquery)
+	if !ok {
+		return nil, sql.ErrInvalidChildType.New(e, children[0], (*Subquery)(nil))
+	}
+	return NewExistsSubquery(query), nil
+}
+
+func (e *ExistsSubquery) String() string {
+	return fmt.Sprintf("EXISTS %s", e.Query)
+}
+
+func (e *ExistsSubquery) DebugString() string {
+	return fmt.Sprintf("EXISTS %s", sql.DebugString(e.Query))
+}
@@ -0,0 +1,68 @@
+package plan_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+func TestExistsSubquery(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table := memory.NewTable("foo", sql.Schema{
+		{Name: "t", Source: "foo", Type: sql.Text},
+	})
+	require.NoError(table.Insert(ctx, sql.Row{"one"}))
+	require.NoError(table.Insert(ctx, sql.Row{"two"}))
+	require.NoError(table.Insert(ctx, sql.Row{"three"}))
+
+	// The rows after the first one aren't read
+	var evaluated int
+	project := plan.NewProject([]sql.Expression{
+		&countingExpression{expression.NewGetField(1, sql.Text, "t", false), &evaluated},
+	}, plan.NewResolvedTable(table))
+
+	result, err := plan.NewExistsSubquery(plan.NewSubquery(project, "")).Eval(ctx, sql.Row{int64(1)})
+	require.NoError(err)
+	require.Equal(true, result)
+	require.Equal(1, evaluated)
+
+	empty := plan.NewFilter(expression.NewLiteral(false, sql.Boolean), plan.NewResolvedTable(table))
+	result, err = plan.NewExistsSubquery(plan.NewSubquery(empty, "")).Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(false, result)
+
+	result, err = expression.NewNot(plan.NewExistsSubquery(plan.NewSubquery(empty, ""))).Eval(ctx, nil)
+	require.NoError(err)
+	require.Equal(true, result)
+
+	// The results of subqueries that can be cached are only evaluated once
+	evaluated = 0
+	exists := plan.NewExistsSubquery(plan.NewSubquery(project, "").WithCachedResults())
+	for i := 0; i < 3; i++ {
+		result, err = exists.Eval(ctx, sql.Row{int64(1)})
+		require.NoError(err)
+		require.Equal(true, result)
+	}
+	require.Equal(1, evaluated)
+}
+
+// countingExpression counts the times the expression it wraps is evaluated.
+type countingExpression struct {
+	sql.Expression
+	count *int
+}
+
+func (e *countingExpression) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	*e.count++
+	return e.Expression.Eval(ctx, row)
+}
+
+func (e *countingExpression) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return e, nil
+}
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 // Filter skips rows that don't match a certain expression.
@@ -73,15 +74,23 @@ func (f *Filter) Expressions() []sql.Expression {
 	return []sql.Expression{f.Expression}
 }
 
+// compileThreshold is the number of rows Filter and Project iterators evaluate their expressions on before compiling
+// them. Compiling takes some allocations, which are only worth it for iterators over large inputs.
+const compileThreshold = 1024
+
 // FilterIter is an iterator that filters another iterator and skips rows that
-// don't match the given condition.
+// don't match the given condition. Once it has evaluated the condition on enough rows it compiles it.
 type FilterIter struct {
-	cond      sql.Expression
-	childIter sql.RowIter
-	ctx       *sql.Context
-	row       sql.Row
+	cond       sql.Expression
+	childIter  sql.RowIter
+	childBatch sql.BatchRowIter
+	ctx        *sql.Context
+	row        sql.Row
+	evaluated  int
 }
 
+var _ sql.BatchRowIter = (*FilterIter)(nil)
+
 // NewFilterIter creates a new FilterIter.
 func NewFilterIter(
 	ctx *sql.Context,
@@ -100,7 +109,7 @@ func (i *FilterIter) Next() (sql.Row, error) {
 			return nil, err
 		}
 
-		ok, err := sql.EvaluateCondition(i.ctx, i.cond, row)
+		ok, err := i.matches(row)
 		if err != nil {
 			return nil, err
 		}
@@ -111,6 +120,49 @@ func (i *FilterIter) Next() (sql.Row, error) {
 	}
 }
 
+// NextBatch implements the sql.BatchRowIter interface. The rows of every batch of the child are filtered in place;
+// batches without any matching row are skipped.
+func (i *FilterIter) NextBatch(rows []sql.Row) (int, error) {
+	if i.childBatch == nil {
+		i.childBatch = sql.NewBatchRowIter(i.childIter)
+	}
+
+	for {
+		n, err := i.childBatch.NextBatch(rows)
+		if err != nil {
+			return 0, err
+		}
+
+		var matched int
+		for _, row := range rows[:n] {
+			ok, err := i.matches(row)
+			if err != nil {
+				return 0, err
+			}
+
+			if ok {
+				rows[matched] = row
+				matched++
+			}
+		}
+
+		if matched > 0 {
+			return matched, nil
+		}
+	}
+}
+
+func (i *FilterIter) matches(row sql.Row) (bool, error) {
+	if i.evaluated < compileThreshold {
+		i.evaluated++
+		if i.evaluated == compileThreshold {
+			i.cond = expression.Compile(i.cond)
+		}
+	}
+
+	return sql.EvaluateCondition(i.ctx, i.cond, row)
+}
+
 // Close implements the RowIter interface.
 func (i *FilterIter) Close() error {
 	return i.childIter.Close()
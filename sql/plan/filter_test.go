@@ -1,6 +1,8 @@
 package plan
 
 import (
+	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -87,3 +89,95 @@ func TestFilter(t *testing.T) {
 	require.Equal(int32(3333), row[2])
 	require.Equal(int64(4444), row[3])
 }
+
+func TestFilterAndProjectCompiled(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	child := memory.NewTable("test", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "test"},
+		{Name: "s", Type: sql.Text, Source: "test", Nullable: true},
+	})
+
+	const numRows = 3 * compileThreshold
+	for i := int64(0); i < numRows; i++ {
+		var s interface{}
+		if i%3 != 0 {
+			s = "foo"
+		}
+		require.NoError(child.Insert(ctx, sql.NewRow(i, s)))
+	}
+
+	i := expression.NewGetFieldWithTable(0, sql.Int64, "test", "i", false)
+	s := expression.NewGetFieldWithTable(1, sql.Text, "test", "s", true)
+
+	node := NewProject(
+		[]sql.Expression{expression.NewAlias("n", expression.NewIsNull(s)), i},
+		NewFilter(
+			expression.NewAnd(
+				expression.NewGreaterThanOrEqual(i, expression.NewLiteral(int64(100), sql.Int64)),
+				expression.NewNot(expression.NewIsNull(s)),
+			),
+			NewResolvedTable(child),
+		),
+	)
+
+	rows, err := sql.NodeToRows(ctx, node)
+	require.NoError(err)
+
+	var expected []sql.Row
+	for i := int64(100); i < numRows; i++ {
+		if i%3 != 0 {
+			expected = append(expected, sql.NewRow(false, i))
+		}
+	}
+	require.Equal(expected, rows)
+}
+
+func TestFilterNextBatch(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	child := memory.NewPartitionedTable("test", sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "test"},
+		{Name: "s", Type: sql.Text, Source: "test"},
+	}, 3)
+	for i := int64(0); i < 100; i++ {
+		require.NoError(child.Insert(ctx, sql.NewRow(i, fmt.Sprint(i%4))))
+	}
+
+	i := expression.NewGetFieldWithTable(0, sql.Int64, "test", "i", false)
+	s := expression.NewGetFieldWithTable(1, sql.Text, "test", "s", false)
+
+	node := NewDistinct(NewProject(
+		[]sql.Expression{s},
+		NewFilter(
+			expression.NewGreaterThan(i, expression.NewLiteral(int64(50), sql.Int64)),
+			NewResolvedTable(child),
+		),
+	))
+
+	expected, err := sql.NodeToRows(ctx, node)
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{{"0"}, {"1"}, {"2"}, {"3"}}, expected)
+
+	iter, err := node.RowIter(ctx, nil)
+	require.NoError(err)
+	batchIter, ok := iter.(sql.BatchRowIter)
+	require.True(ok)
+
+	var rows []sql.Row
+	batch := make([]sql.Row, 3)
+	for {
+		n, err := batchIter.NextBatch(batch)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+		require.NotZero(n)
+		rows = append(rows, batch[:n]...)
+	}
+	require.NoError(iter.Close())
+
+	require.Equal(expected, rows)
+}
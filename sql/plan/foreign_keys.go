@@ -0,0 +1,707 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// maxForeignKeyCascadeDepth is how deep the actions of foreign keys can cascade through the tables referencing the rows
+// they delete and update, as in MySQL.
+const maxForeignKeyCascadeDepth = 15
+
+// ErrForeignKeyCascadeDepth is returned when the actions of foreign keys cascade deeper than they can.
+var ErrForeignKeyCascadeDepth = errors.NewKind("Foreign key cascade delete/update exceeds max depth of %d.")
+
+// ForeignKeys are the foreign keys enforced on the rows that InsertInto, Update and DeleteFrom write to a table of a
+// database: the rows inserted and updated must reference existing rows of the tables its foreign keys reference, and
+// the rows deleted and updated that are referenced by the foreign keys of tables of the database are restricted, or
+// have their referencing rows deleted or updated as the actions of the foreign keys say.
+type ForeignKeys struct {
+	Database sql.Database
+	// Checks are the enforced check constraints of the tables of the database, keyed by their lower case names, which
+	// the rows the actions of foreign keys update must satisfy.
+	Checks map[string]CheckConstraints
+}
+
+// NewForeignKeys returns the foreign keys of the tables of the database given.
+func NewForeignKeys(db sql.Database) *ForeignKeys {
+	return &ForeignKeys{Database: db}
+}
+
+// tableForeignKeys returns the foreign keys the table given declares, if it's a sql.ForeignKeyTable.
+func tableForeignKeys(ctx *sql.Context, table sql.Table) ([]sql.ForeignKeyConstraint, error) {
+	fkt := getForeignKeyTable(table)
+	if fkt == nil {
+		return nil, nil
+	}
+	return fkt.GetForeignKeys(ctx)
+}
+
+// Enforced returns whether there are foreign keys to enforce on the rows written to the table given: the ones it
+// declares, and the ones of the tables of the database that reference it.
+func (fks *ForeignKeys) Enforced(ctx *sql.Context, table sql.Table) (bool, error) {
+	tableFks, err := tableForeignKeys(ctx, table)
+	if err != nil || len(tableFks) > 0 {
+		return len(tableFks) > 0, err
+	}
+
+	refs, err := fks.references(ctx, table.Name())
+	return len(refs) > 0, err
+}
+
+// foreignKeyReference is a foreign key of the table of a database that declares it.
+type foreignKeyReference struct {
+	table sql.Table
+	fk    sql.ForeignKeyConstraint
+}
+
+// references returns the foreign keys of the tables of the database that reference the table with the name given.
+func (fks *ForeignKeys) references(ctx *sql.Context, table string) ([]foreignKeyReference, error) {
+	names, err := fks.Database.GetTableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []foreignKeyReference
+	for _, name := range names {
+		t, ok, err := fks.Database.GetTableInsensitive(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		tableFks, err := tableForeignKeys(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range tableFks {
+			if strings.EqualFold(fk.ReferencedTable, table) {
+				refs = append(refs, foreignKeyReference{table: t, fk: fk})
+			}
+		}
+	}
+	return refs, nil
+}
+
+// inserter returns an inserter of the table given that enforces the foreign keys on the rows inserted by the inserter
+// given, unless the session of the context given turned them off.
+func (fks *ForeignKeys) inserter(ctx *sql.Context, table sql.Table, inserter sql.RowInserter) sql.RowInserter {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return inserter
+	}
+	return &foreignKeyEditor{fks: fks, table: table, inserter: inserter, closer: inserter}
+}
+
+// replacer returns a replacer of the table given that enforces the foreign keys on the rows replaced by the replacer
+// given, unless the session of the context given turned them off.
+func (fks *ForeignKeys) replacer(ctx *sql.Context, table sql.Table, replacer sql.RowReplacer) sql.RowReplacer {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return replacer
+	}
+	return &foreignKeyEditor{fks: fks, table: table, inserter: replacer, deleter: replacer, closer: replacer, replacing: true}
+}
+
+// updater returns an updater of the table given that enforces the foreign keys on the rows updated by the updater
+// given, unless the session of the context given turned them off.
+func (fks *ForeignKeys) updater(ctx *sql.Context, table sql.Table, updater sql.RowUpdater) sql.RowUpdater {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return updater
+	}
+	return &foreignKeyEditor{fks: fks, table: table, updater: updater, closer: updater}
+}
+
+// deleter returns a deleter of the table given that enforces the foreign keys on the rows deleted by the deleter
+// given, unless the session of the context given turned them off.
+func (fks *ForeignKeys) deleter(ctx *sql.Context, table sql.Table, deleter sql.RowDeleter) sql.RowDeleter {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return deleter
+	}
+	return &foreignKeyEditor{fks: fks, table: table, deleter: deleter, closer: deleter}
+}
+
+// checkTruncate returns an error if the table given is referenced by the foreign keys of other tables, unless the
+// session of the context given turned them off.
+func (fks *ForeignKeys) checkTruncate(ctx *sql.Context, table sql.Table) error {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return nil
+	}
+
+	refs, err := fks.references(ctx, table.Name())
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if !strings.EqualFold(ref.table.Name(), table.Name()) {
+			return sql.ErrTruncateReferencedTable.New(fks.Database.Name(), ref.table.Name(), foreignKeyString(ref.fk))
+		}
+	}
+	return nil
+}
+
+// checkDrop returns an error if the table given is referenced by the foreign keys of tables other than the ones with
+// the names given, which are dropped with it, unless the session of the context given turned them off.
+func (fks *ForeignKeys) checkDrop(ctx *sql.Context, table sql.Table, dropped []string) error {
+	if fks == nil || !sql.ForeignKeyChecks(ctx) {
+		return nil
+	}
+
+	refs, err := fks.references(ctx, table.Name())
+	if err != nil {
+		return err
+	}
+References:
+	for _, ref := range refs {
+		for _, name := range dropped {
+			if strings.EqualFold(ref.table.Name(), name) {
+				continue References
+			}
+		}
+		return sql.ErrDropReferencedTable.New(table.Name(), ref.fk.Name, ref.table.Name())
+	}
+	return nil
+}
+
+// foreignKeyEditor enforces the foreign keys on the rows its editors write to their table. The rows are checked, and
+// the referencing rows deleted and updated, before they're written.
+type foreignKeyEditor struct {
+	fks      *ForeignKeys
+	table    sql.Table
+	inserter sql.RowInserter
+	updater  sql.RowUpdater
+	deleter  sql.RowDeleter
+	closer   sql.Closer
+	// cascaded are the rows of the table that were deleted by the actions of its foreign keys that reference it,
+	// which the deleter may be given afterwards
+	cascaded []sql.Row
+	// replacing is whether the editor is a replacer, which is given the row it inserts to delete the row it replaces
+	replacing bool
+}
+
+var _ sql.RowReplacer = (*foreignKeyEditor)(nil)
+var _ sql.RowUpdater = (*foreignKeyEditor)(nil)
+
+// Insert implements sql.RowInserter. Replacers check the rows they insert before they delete the rows they replace.
+func (e *foreignKeyEditor) Insert(ctx *sql.Context, row sql.Row) error {
+	if !e.replacing {
+		if err := e.fks.checkReferences(ctx, e.table, nil, row, nil); err != nil {
+			return err
+		}
+	}
+	return e.inserter.Insert(ctx, row)
+}
+
+// Update implements sql.RowUpdater.
+func (e *foreignKeyEditor) Update(ctx *sql.Context, old sql.Row, new sql.Row) error {
+	if err := e.fks.checkReferences(ctx, e.table, old, new, nil); err != nil {
+		return err
+	}
+	if err := e.fks.applyReferences(ctx, e, e.table, old, new); err != nil {
+		return err
+	}
+	return e.updater.Update(ctx, old, new)
+}
+
+// Delete implements sql.RowDeleter.
+func (e *foreignKeyEditor) Delete(ctx *sql.Context, row sql.Row) error {
+	for _, deleted := range e.cascaded {
+		if equals, err := deleted.Equals(row, e.table.Schema()); err != nil {
+			return err
+		} else if equals {
+			return nil
+		}
+	}
+
+	old := row
+	if e.replacing {
+		if err := e.fks.checkReferences(ctx, e.table, nil, row, nil); err != nil {
+			return err
+		}
+		var err error
+		if old, err = replacedRow(ctx, e.table, row); err != nil {
+			return err
+		}
+		if old == nil {
+			return e.deleter.Delete(ctx, row)
+		}
+	}
+
+	if err := e.fks.applyReferences(ctx, e, e.table, old, nil); err != nil {
+		return err
+	}
+	return e.deleter.Delete(ctx, row)
+}
+
+// replacedRow returns the row of the table given with the primary key of the row given, which REPLACE replaces with
+// it, or nil if there's none. The rows of tables without a primary key are only replaced by equal rows.
+func replacedRow(ctx *sql.Context, table sql.Table, row sql.Row) (sql.Row, error) {
+	var idxs []int
+	for i, col := range table.Schema() {
+		if col.PrimaryKey {
+			idxs = append(idxs, i)
+		}
+	}
+	if len(idxs) == 0 {
+		return row, nil
+	}
+
+	rows, err := matchingRows(ctx, table, idxs, columnTypes(table, idxs), rowValues(row, idxs), true)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// Close implements sql.Closer.
+func (e *foreignKeyEditor) Close(ctx *sql.Context) error {
+	return e.closer.Close(ctx)
+}
+
+// checkReferences returns an error if the new row given of the table given references no row of the table referenced
+// by one of its foreign keys, other than the one given, if any. Rows with a NULL value in the columns of a foreign key
+// don't reference any row, and updated rows are only checked on the foreign keys whose columns they change.
+func (fks *ForeignKeys) checkReferences(ctx *sql.Context, table sql.Table, old, new sql.Row, except *sql.ForeignKeyConstraint) error {
+	tableFks, err := tableForeignKeys(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	for _, fk := range tableFks {
+		if except != nil && reflect.DeepEqual(fk, *except) {
+			continue
+		}
+		idxs, err := columnIndexes(table, fk.Columns)
+		if err != nil {
+			return err
+		}
+		values := rowValues(new, idxs)
+		if hasNull(values) {
+			continue
+		}
+
+		parent, ok, err := fks.Database.GetTableInsensitive(ctx, fk.ReferencedTable)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return sql.ErrForeignKeyChildViolation.New(fks.Database.Name(), table.Name(), foreignKeyString(fk))
+		}
+		parentIdxs, err := columnIndexes(parent, fk.ReferencedColumns)
+		if err != nil {
+			return err
+		}
+		types := columnTypes(parent, parentIdxs)
+
+		if old != nil {
+			if equal, err := valuesEqual(types, rowValues(old, idxs), values); err != nil {
+				return err
+			} else if equal {
+				continue
+			}
+		}
+
+		// Rows can reference themselves
+		if strings.EqualFold(parent.Name(), table.Name()) {
+			if equal, err := valuesEqual(types, rowValues(new, parentIdxs), values); err != nil {
+				return err
+			} else if equal {
+				continue
+			}
+		}
+
+		rows, err := matchingRows(ctx, parent, parentIdxs, types, values, true)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return sql.ErrForeignKeyChildViolation.New(fks.Database.Name(), table.Name(), foreignKeyString(fk))
+		}
+	}
+	return nil
+}
+
+// applyReferences applies the actions of the foreign keys that reference the row of the table given deleted, when the
+// new row is nil, or updated to the new row, to the rows that reference it: the rows are deleted or updated with the
+// new values of the columns they reference with CASCADE, and their columns are set to NULL with SET NULL. Any other
+// action restricts the row from being deleted or updated, so an error is returned. The actions are checked all the way
+// down the rows they cascade to before any row is written: no row may be restricted, and the rows updated must satisfy
+// the check constraints and the other foreign keys of their tables. The rows deleted and updated from the table of the
+// editor given are written by it.
+func (fks *ForeignKeys) applyReferences(ctx *sql.Context, e *foreignKeyEditor, table sql.Table, old, new sql.Row) error {
+	if err := fks.cascade(ctx, e, table, old, new, 0, false); err != nil {
+		return err
+	}
+	return fks.cascade(ctx, e, table, old, new, 0, true)
+}
+
+// referenceAction is the action of a foreign key on the rows of its table that reference a row deleted or updated.
+type referenceAction struct {
+	ref    foreignKeyReference
+	action sql.ForeignKeyReferenceOption
+	// rows are the rows referencing the row, with the indexes of the columns of the foreign key
+	rows []sql.Row
+	idxs []int
+	// values are the new values of the columns the foreign key references, for updated rows
+	values []interface{}
+}
+
+// referenceActions returns the actions of the foreign keys that reference the row of the table given deleted or
+// updated on the rows referencing it, or an error if any of them restricts the row from being deleted or updated.
+func (fks *ForeignKeys) referenceActions(ctx *sql.Context, table sql.Table, old, new sql.Row) ([]referenceAction, error) {
+	refs, err := fks.references(ctx, table.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []referenceAction
+	for _, ref := range refs {
+		idxs, err := columnIndexes(table, ref.fk.ReferencedColumns)
+		if err != nil {
+			return nil, err
+		}
+		types := columnTypes(table, idxs)
+		values := rowValues(old, idxs)
+		if hasNull(values) {
+			continue
+		}
+
+		var newValues []interface{}
+		action := ref.fk.OnDelete
+		if new != nil {
+			newValues = rowValues(new, idxs)
+			if equal, err := valuesEqual(types, values, newValues); err != nil {
+				return nil, err
+			} else if equal {
+				continue
+			}
+			action = ref.fk.OnUpdate
+		}
+
+		childIdxs, err := columnIndexes(ref.table, ref.fk.Columns)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := matchingRows(ctx, ref.table, childIdxs, types, values, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// A row that references itself is deleted or updated with it
+		sameTable := strings.EqualFold(ref.table.Name(), table.Name())
+		var children []sql.Row
+		for _, row := range rows {
+			if sameTable {
+				if equal, err := row.Equals(old, table.Schema()); err != nil {
+					return nil, err
+				} else if equal {
+					continue
+				}
+			}
+			children = append(children, row)
+		}
+		if len(children) == 0 {
+			continue
+		}
+
+		switch action {
+		case sql.ForeignKeyReferenceOption_Cascade, sql.ForeignKeyReferenceOption_SetNull:
+			actions = append(actions, referenceAction{ref: ref, action: action, rows: children, idxs: childIdxs, values: newValues})
+		default:
+			return nil, sql.ErrForeignKeyParentViolation.New(fks.Database.Name(), ref.table.Name(), foreignKeyString(ref.fk))
+		}
+	}
+	return actions, nil
+}
+
+// cascade applies the actions of the foreign keys that reference the row of the table given deleted or updated, at
+// the depth given, when apply is true, and only checks them otherwise.
+func (fks *ForeignKeys) cascade(ctx *sql.Context, e *foreignKeyEditor, table sql.Table, old, new sql.Row, depth int, apply bool) error {
+	actions, err := fks.referenceActions(ctx, table, old, new)
+	if err != nil {
+		return err
+	}
+	if len(actions) > 0 && depth >= maxForeignKeyCascadeDepth {
+		return ErrForeignKeyCascadeDepth.New(maxForeignKeyCascadeDepth)
+	}
+
+	for _, a := range actions {
+		switch {
+		case a.action == sql.ForeignKeyReferenceOption_SetNull:
+			err = fks.updateRows(ctx, e, a.ref, a.rows, a.idxs, make([]interface{}, len(a.idxs)), depth+1, apply)
+		case new == nil:
+			err = fks.deleteRows(ctx, e, a.ref.table, a.rows, depth+1, apply)
+		default:
+			err = fks.updateRows(ctx, e, a.ref, a.rows, a.idxs, a.values, depth+1, apply)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRows deletes the rows given of the table given, applying the actions of the foreign keys that reference them,
+// when apply is true, and only checks those actions otherwise.
+func (fks *ForeignKeys) deleteRows(ctx *sql.Context, e *foreignKeyEditor, table sql.Table, rows []sql.Row, depth int, apply bool) error {
+	for _, row := range rows {
+		if err := fks.cascade(ctx, e, table, row, nil, depth, apply); err != nil {
+			return err
+		}
+	}
+	if !apply {
+		return nil
+	}
+
+	if strings.EqualFold(table.Name(), e.table.Name()) && e.deleter != nil {
+		for _, row := range rows {
+			if err := e.deleter.Delete(ctx, row); err != nil {
+				return err
+			}
+			e.cascaded = append(e.cascaded, row)
+		}
+		return nil
+	}
+
+	deletable, err := getDeletableTable(table)
+	if err != nil {
+		return err
+	}
+	deleter := deletable.Deleter(ctx)
+	for _, row := range rows {
+		if err := deleter.Delete(ctx, row); err != nil {
+			_ = deleter.Close(ctx)
+			return err
+		}
+	}
+	return deleter.Close(ctx)
+}
+
+// updateRows sets the columns with the indexes given of the rows given of the table of the foreign key given to the
+// values given, applying the actions of the foreign keys that reference them, when apply is true. Otherwise, it only
+// checks those actions, and that the rows updated satisfy the check constraints of their table and its foreign keys
+// other than the one given, whose rows referenced aren't updated yet.
+func (fks *ForeignKeys) updateRows(ctx *sql.Context, e *foreignKeyEditor, ref foreignKeyReference, rows []sql.Row, idxs []int, values []interface{}, depth int, apply bool) error {
+	table := ref.table
+	updated := make([]sql.Row, len(rows))
+	for i, row := range rows {
+		updated[i] = row.Copy()
+		for j, idx := range idxs {
+			updated[i][idx] = values[j]
+		}
+		if !apply {
+			if err := fks.Checks[strings.ToLower(table.Name())].check(ctx, updated[i]); err != nil {
+				return err
+			}
+			if err := fks.checkReferences(ctx, table, row, updated[i], &ref.fk); err != nil {
+				return err
+			}
+		}
+		if err := fks.cascade(ctx, e, table, row, updated[i], depth, apply); err != nil {
+			return err
+		}
+	}
+	if !apply {
+		return nil
+	}
+
+	if strings.EqualFold(table.Name(), e.table.Name()) && e.updater != nil {
+		for i, row := range rows {
+			if err := e.updater.Update(ctx, row, updated[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	updatable, err := getUpdatableTable(table)
+	if err != nil {
+		return err
+	}
+	updater := updatable.Updater(ctx)
+	for i, row := range rows {
+		if err := updater.Update(ctx, row, updated[i]); err != nil {
+			_ = updater.Close(ctx)
+			return err
+		}
+	}
+	return updater.Close(ctx)
+}
+
+// matchingRows returns the rows of the table given whose columns with the indexes given are equal to the values given,
+// compared as values of the types given, or only the first one if first is true. The rows are looked up in an index of
+// the table on those columns if it has one, and read from the whole table otherwise.
+func matchingRows(ctx *sql.Context, table sql.Table, idxs []int, types []sql.Type, values []interface{}, first bool) ([]sql.Row, error) {
+	lookedUp, err := lookupTable(ctx, table, idxs, values)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := NewResolvedTable(lookedUp).RowIter(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = iter.Close()
+			return nil, err
+		}
+
+		equal, err := valuesEqual(types, rowValues(row, idxs), values)
+		if err != nil {
+			_ = iter.Close()
+			return nil, err
+		}
+		if equal {
+			rows = append(rows, row)
+			if first {
+				break
+			}
+		}
+	}
+	return rows, iter.Close()
+}
+
+// lookupTable returns the table given restricted to the rows of an index of it whose columns are the columns with the
+// indexes given, in any order, or that start with them, with the values given. The table given is returned if it has
+// no such index, or if the values can't be converted to the types of the columns.
+func lookupTable(ctx *sql.Context, table sql.Table, idxs []int, values []interface{}) (sql.Table, error) {
+	it := indexedTable(table)
+	if it == nil {
+		return table, nil
+	}
+	indexes, err := it.GetIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := table.Schema()
+	key := make([]interface{}, len(values))
+	for i, v := range values {
+		if key[i], err = schema[idxs[i]].Type.Convert(v); err != nil {
+			return table, nil
+		}
+	}
+
+Indexes:
+	for _, index := range indexes {
+		exprs := index.Expressions()
+		prefix, isPrefix := index.(sql.PrefixIndex)
+		if len(exprs) < len(idxs) || (len(exprs) > len(idxs) && !isPrefix) {
+			continue
+		}
+
+		indexKey := make([]interface{}, len(idxs))
+		for i, expr := range exprs[:len(idxs)] {
+			column := strings.ToLower(expr[strings.Index(expr, ".")+1:])
+			j := -1
+			for k, idx := range idxs {
+				if strings.ToLower(schema[idx].Name) == column {
+					j = k
+				}
+			}
+			if j < 0 {
+				continue Indexes
+			}
+			indexKey[i] = key[j]
+		}
+
+		var lookup sql.IndexLookup
+		if len(exprs) > len(idxs) {
+			lookup, err = prefix.GetPrefix(indexKey...)
+		} else {
+			lookup, err = index.Get(indexKey...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return it.WithIndexLookup(lookup), nil
+	}
+	return table, nil
+}
+
+// indexedTable returns the table given, or the first table it wraps, that has indexes.
+func indexedTable(t sql.Table) sql.IndexedTable {
+	for {
+		if it, ok := t.(sql.IndexedTable); ok {
+			return it
+		}
+		w, ok := t.(sql.TableWrapper)
+		if !ok {
+			return nil
+		}
+		t = w.Underlying()
+	}
+}
+
+// columnIndexes returns the indexes of the columns with the names given in the schema of the table given.
+func columnIndexes(table sql.Table, columns []string) ([]int, error) {
+	idxs := make([]int, len(columns))
+	for i, column := range columns {
+		idxs[i] = table.Schema().IndexOf(column, table.Name())
+		if idxs[i] < 0 {
+			return nil, sql.ErrTableColumnNotFound.New(column)
+		}
+	}
+	return idxs, nil
+}
+
+func columnTypes(table sql.Table, idxs []int) []sql.Type {
+	types := make([]sql.Type, len(idxs))
+	for i, idx := range idxs {
+		types[i] = table.Schema()[idx].Type
+	}
+	return types
+}
+
+func rowValues(row sql.Row, idxs []int) []interface{} {
+	values := make([]interface{}, len(idxs))
+	for i, idx := range idxs {
+		values[i] = row[idx]
+	}
+	return values
+}
+
+// valuesEqual returns whether the values given are equal, compared as values of the types given. NULL values are equal
+// to no value.
+func valuesEqual(types []sql.Type, left, right []interface{}) (bool, error) {
+	for i, typ := range types {
+		if left[i] == nil || right[i] == nil {
+			return false, nil
+		}
+		cmp, err := typ.Compare(left[i], right[i])
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// foreignKeyString returns the definition of the foreign key given, as errors of MySQL describe it.
+func foreignKeyString(fk sql.ForeignKeyConstraint) string {
+	s := fmt.Sprintf("CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES `%s` (%s)", fk.Name,
+		strings.Join(quoteIdentifiers(fk.Columns), ", "), fk.ReferencedTable,
+		strings.Join(quoteIdentifiers(fk.ReferencedColumns), ", "))
+	if len(fk.OnDelete) > 0 && fk.OnDelete != sql.ForeignKeyReferenceOption_DefaultAction {
+		s += " ON DELETE " + string(fk.OnDelete)
+	}
+	if len(fk.OnUpdate) > 0 && fk.OnUpdate != sql.ForeignKeyReferenceOption_DefaultAction {
+		s += " ON UPDATE " + string(fk.OnUpdate)
+	}
+	return s
+}
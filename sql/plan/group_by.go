@@ -202,8 +202,10 @@ func (i *groupByIter) Next() (sql.Row, error) {
 		i.buf[j] = fillBuffer(a)
 	}
 
+	child := sql.NewBatchRowIter(i.child)
+	rows := make([]sql.Row, sql.BatchSize)
 	for {
-		row, err := i.child.Next()
+		n, err := child.NextBatch(rows)
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -211,8 +213,10 @@ func (i *groupByIter) Next() (sql.Row, error) {
 			return nil, err
 		}
 
-		if err := updateBuffers(i.ctx, i.buf, i.selectedExprs, row); err != nil {
-			return nil, err
+		for _, row := range rows[:n] {
+			if err := updateBuffers(i.ctx, i.buf, i.selectedExprs, row); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -269,8 +273,10 @@ func (i *groupByGroupingIter) Next() (sql.Row, error) {
 }
 
 func (i *groupByGroupingIter) compute() error {
+	child := sql.NewBatchRowIter(i.child)
+	rows := make([]sql.Row, sql.BatchSize)
 	for {
-		row, err := i.child.Next()
+		n, err := child.NextBatch(rows)
 		if err != nil {
 			if err == io.EOF {
 				break
@@ -278,36 +284,41 @@ func (i *groupByGroupingIter) compute() error {
 			return err
 		}
 
-		key, err := groupingKey(i.ctx, i.groupByExprs, row)
-		if err != nil {
-			return err
+		for _, row := range rows[:n] {
+			if err := i.update(row); err != nil {
+				return err
+			}
 		}
+	}
 
-		if _, err := i.aggregations.Get(key); err != nil {
-			var buf = make([]sql.Row, len(i.selectedExprs))
-			for j, a := range i.selectedExprs {
-				buf[j] = fillBuffer(a)
-			}
+	return nil
+}
 
-			if err := i.aggregations.Put(key, buf); err != nil {
-				return err
-			}
+func (i *groupByGroupingIter) update(row sql.Row) error {
+	key, err := groupingKey(i.ctx, i.groupByExprs, row)
+	if err != nil {
+		return err
+	}
 
-			i.keys = append(i.keys, key)
+	if _, err := i.aggregations.Get(key); err != nil {
+		var buf = make([]sql.Row, len(i.selectedExprs))
+		for j, a := range i.selectedExprs {
+			buf[j] = fillBuffer(a)
 		}
 
-		b, err := i.aggregations.Get(key)
-		if err != nil {
+		if err := i.aggregations.Put(key, buf); err != nil {
 			return err
 		}
 
-		err = updateBuffers(i.ctx, b.([]sql.Row), i.selectedExprs, row)
-		if err != nil {
-			return err
-		}
+		i.keys = append(i.keys, key)
 	}
 
-	return nil
+	b, err := i.aggregations.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return updateBuffers(i.ctx, b.([]sql.Row), i.selectedExprs, row)
 }
 
 func (i *groupByGroupingIter) Close() error {
@@ -0,0 +1,552 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+var (
+	// ErrUnknownHandler is returned when a HANDLER statement names a handler that isn't open in the session.
+	ErrUnknownHandler = errors.NewKind("Unknown table '%s' in HANDLER")
+	// ErrHandlerIndexNotFound is returned when HANDLER READ names an index its table doesn't have.
+	ErrHandlerIndexNotFound = errors.NewKind("Key '%s' doesn't exist in table '%s'")
+	// ErrHandlerTooManyKeyParts is returned when HANDLER READ compares an index with more values than it has columns.
+	ErrHandlerTooManyKeyParts = errors.NewKind("Too many key parts specified; max %d parts allowed")
+)
+
+// HandlerOpen is the HANDLER OPEN statement, which opens a table in the session for HANDLER READ to read its rows,
+// under its alias if it has one and under its name otherwise.
+type HandlerOpen struct {
+	UnaryNode
+	Alias string
+}
+
+var _ sql.Node = (*HandlerOpen)(nil)
+
+// NewHandlerOpen creates a new HandlerOpen node.
+func NewHandlerOpen(table sql.Node, alias string) *HandlerOpen {
+	return &HandlerOpen{UnaryNode{Child: table}, alias}
+}
+
+// Name returns the name the table is opened under.
+func (h *HandlerOpen) Name() string {
+	if h.Alias != "" {
+		return h.Alias
+	}
+	return h.Child.(sql.Nameable).Name()
+}
+
+// Schema implements the sql.Node interface.
+func (*HandlerOpen) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (h *HandlerOpen) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	rt, ok := h.Child.(*ResolvedTable)
+	if !ok {
+		return nil, ErrTableNotValid.New()
+	}
+
+	name := h.Name()
+	if ctx.GetHandler(strings.ToLower(name)) != nil {
+		return nil, sql.ErrDuplicateAliasOrTable.New(name)
+	}
+
+	db := rt.Database
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+	ctx.AddHandler(strings.ToLower(name), &sql.Handler{Database: db, Table: rt.Name()})
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (h *HandlerOpen) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(h, len(children), 1)
+	}
+	return NewHandlerOpen(children[0], h.Alias), nil
+}
+
+func (h *HandlerOpen) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("HandlerOpen(%s)", h.Name())
+	_ = pr.WriteChildren(h.Child.String())
+	return pr.String()
+}
+
+// HandlerClose is the HANDLER CLOSE statement, which closes a table opened by HANDLER OPEN.
+type HandlerClose struct {
+	Name string
+}
+
+var _ sql.Node = (*HandlerClose)(nil)
+
+// NewHandlerClose creates a new HandlerClose node.
+func NewHandlerClose(name string) *HandlerClose {
+	return &HandlerClose{name}
+}
+
+// Resolved implements the sql.Node interface.
+func (*HandlerClose) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*HandlerClose) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*HandlerClose) Schema() sql.Schema { return nil }
+
+// RowIter implements the sql.Node interface.
+func (h *HandlerClose) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	name := strings.ToLower(h.Name)
+	if ctx.GetHandler(name) == nil {
+		return nil, ErrUnknownHandler.New(h.Name)
+	}
+	ctx.DelHandler(name)
+	return sql.RowsToRowIter(), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (h *HandlerClose) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(h, len(children), 0)
+	}
+	return h, nil
+}
+
+func (h *HandlerClose) String() string {
+	return fmt.Sprintf("HandlerClose(%s)", h.Name)
+}
+
+// The modes of HANDLER READ, which are the positions it reads from, or the comparisons of index keys with the keys of
+// the statement that the rows it reads must match.
+const (
+	HandlerReadFirst          = "first"
+	HandlerReadNext           = "next"
+	HandlerReadPrev           = "prev"
+	HandlerReadLast           = "last"
+	HandlerReadEqual          = "="
+	HandlerReadGreater        = ">"
+	HandlerReadGreaterOrEqual = ">="
+	HandlerReadLess           = "<"
+	HandlerReadLessOrEqual    = "<="
+)
+
+// HandlerRead is the HANDLER READ statement, which reads the rows of a table opened by HANDLER OPEN, starting where
+// the last read of the handler stopped. Rows are read in the order of an index, or in their natural order if no index
+// is given. Reads of an index use the lookups of the Ascend and Descend index interfaces to find the rows they read
+// when the index implements them. LAST, PREV, < and <= read the rows backwards.
+//
+// HandlerRead returns every row from its start position, and the parser puts its WHERE and LIMIT on top of it. Only
+// the rows that are consumed move the position of the handler.
+type HandlerRead struct {
+	Name string
+	// Index is the name of the index whose order rows are read in, or empty to read rows in their natural order.
+	Index string
+	// Mode is one of the HandlerRead modes.
+	Mode string
+	// Keys are the values compared with the index keys for comparison modes.
+	Keys []sql.Expression
+
+	table  *ResolvedTable
+	schema sql.Schema
+}
+
+var _ sql.Node = (*HandlerRead)(nil)
+var _ sql.Expressioner = (*HandlerRead)(nil)
+
+// NewHandlerRead creates a new HandlerRead node, which isn't resolved until its table is set with WithTable.
+func NewHandlerRead(name, index, mode string, keys []sql.Expression) *HandlerRead {
+	return &HandlerRead{Name: name, Index: index, Mode: mode, Keys: keys}
+}
+
+// WithTable returns a copy of the node that reads the table given, which must be the table the handler opened.
+func (h *HandlerRead) WithTable(table *ResolvedTable) *HandlerRead {
+	nh := *h
+	nh.table = table
+	nh.schema = make(sql.Schema, len(table.Schema()))
+	for i, col := range table.Schema() {
+		c := *col
+		c.Source = h.Name
+		nh.schema[i] = &c
+	}
+	return &nh
+}
+
+// Table returns the table the node reads, or nil if it isn't resolved yet.
+func (h *HandlerRead) Table() *ResolvedTable {
+	return h.table
+}
+
+// Resolved implements the sql.Node interface.
+func (h *HandlerRead) Resolved() bool {
+	return h.table != nil && expressionsResolved(h.Keys...)
+}
+
+// Children implements the sql.Node interface.
+func (*HandlerRead) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (h *HandlerRead) Schema() sql.Schema { return h.schema }
+
+// Expressions implements the sql.Expressioner interface.
+func (h *HandlerRead) Expressions() []sql.Expression { return h.Keys }
+
+// WithExpressions implements the sql.Expressioner interface.
+func (h *HandlerRead) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(h.Keys) {
+		return nil, sql.ErrInvalidChildrenNumber.New(h, len(exprs), len(h.Keys))
+	}
+	nh := *h
+	nh.Keys = exprs
+	return &nh, nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (h *HandlerRead) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(h, len(children), 0)
+	}
+	return h, nil
+}
+
+func (h *HandlerRead) String() string {
+	var keys = make([]string, len(h.Keys))
+	for i, k := range h.Keys {
+		keys[i] = k.String()
+	}
+
+	mode := strings.ToUpper(h.Mode)
+	if len(keys) > 0 {
+		mode = fmt.Sprintf("%s (%s)", mode, strings.Join(keys, ", "))
+	}
+	if h.Index != "" {
+		mode = h.Index + " " + mode
+	}
+	return fmt.Sprintf("HandlerRead(%s, %s)", h.Name, mode)
+}
+
+// RowIter implements the sql.Node interface.
+func (h *HandlerRead) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.HandlerRead")
+
+	handler := ctx.GetHandler(strings.ToLower(h.Name))
+	if handler == nil {
+		span.Finish()
+		return nil, ErrUnknownHandler.New(h.Name)
+	}
+
+	var iter sql.RowIter
+	var err error
+	if h.Index == "" {
+		iter, err = h.naturalOrderIter(ctx, handler)
+	} else {
+		iter, err = h.indexOrderIter(ctx, handler, row)
+	}
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return sql.NewSpanIter(span, iter), nil
+}
+
+// naturalOrderIter returns an iterator of the rows of the table in their natural order, skipping the rows the handler
+// already read in that order unless the read starts from the first row.
+func (h *HandlerRead) naturalOrderIter(ctx *sql.Context, handler *sql.Handler) (sql.RowIter, error) {
+	if h.Mode == HandlerReadFirst || handler.Index != "" {
+		handler.Scanned = 0
+	}
+	handler.Index = ""
+
+	iter, err := h.table.RowIter(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := int64(0); i < handler.Scanned; i++ {
+		if _, err := iter.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			iter.Close()
+			return nil, err
+		}
+	}
+
+	return &handlerNaturalOrderIter{iter, handler}, nil
+}
+
+// indexOrderIter returns an iterator of the rows the read returns in the order of its index.
+func (h *HandlerRead) indexOrderIter(ctx *sql.Context, handler *sql.Handler, row sql.Row) (sql.RowIter, error) {
+	table := underlyingTable(h.table)
+	index, err := h.findIndex(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var cols []int
+	for _, expr := range index.Expressions() {
+		col := GetColumnFromIndexExpr(expr, table)
+		if col == nil {
+			return nil, ErrHandlerIndexNotFound.New(h.Index, h.table.Name())
+		}
+		cols = append(cols, table.Schema().IndexOf(col.Name, col.Source))
+	}
+
+	if len(h.Keys) > len(cols) {
+		return nil, ErrHandlerTooManyKeyParts.New(len(cols))
+	}
+	keys := make([]interface{}, len(h.Keys))
+	for i, k := range h.Keys {
+		v, err := k.Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			if v, err = table.Schema()[cols[i]].Type.Convert(v); err != nil {
+				return nil, err
+			}
+		}
+		keys[i] = v
+	}
+
+	cmp := &handlerRowComparer{schema: table.Schema(), cols: cols}
+
+	mode := h.Mode
+	var position sql.Row
+	if strings.EqualFold(handler.Index, index.ID()) {
+		position = handler.Position
+	}
+	switch {
+	case mode == HandlerReadNext && position == nil:
+		mode = HandlerReadFirst
+	case mode == HandlerReadPrev && position == nil:
+		mode = HandlerReadLast
+	case mode == HandlerReadNext || mode == HandlerReadPrev:
+		keys = cmp.key(position)
+	}
+
+	rows, err := h.readIndex(ctx, table, index, cols, mode, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []sql.Row
+	for _, r := range rows {
+		var c int
+		switch mode {
+		case HandlerReadNext, HandlerReadPrev:
+			c, err = cmp.compare(r, position)
+		case HandlerReadFirst, HandlerReadLast:
+		default:
+			c, err = cmp.compareKey(r, keys)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var matches bool
+		switch mode {
+		case HandlerReadFirst, HandlerReadLast:
+			matches = true
+		case HandlerReadNext, HandlerReadGreater:
+			matches = c > 0
+		case HandlerReadPrev, HandlerReadLess:
+			matches = c < 0
+		case HandlerReadEqual:
+			matches = c == 0
+		case HandlerReadGreaterOrEqual:
+			matches = c >= 0
+		case HandlerReadLessOrEqual:
+			matches = c <= 0
+		}
+		if matches {
+			matching = append(matching, r)
+		}
+	}
+
+	descending := mode == HandlerReadLast || mode == HandlerReadPrev || mode == HandlerReadLess ||
+		mode == HandlerReadLessOrEqual
+	sort.SliceStable(matching, func(i, j int) bool {
+		c, err := cmp.compare(matching[i], matching[j])
+		if err != nil && cmp.err == nil {
+			cmp.err = err
+		}
+		if descending {
+			return c > 0
+		}
+		return c < 0
+	})
+	if cmp.err != nil {
+		return nil, cmp.err
+	}
+
+	return &handlerIndexOrderIter{rows: matching, handler: handler, index: index.ID()}, nil
+}
+
+// findIndex returns the index of the table with the name of the index the node reads.
+func (h *HandlerRead) findIndex(ctx *sql.Context, table sql.Table) (sql.Index, error) {
+	if it, ok := table.(sql.IndexedTable); ok {
+		indexes, err := it.GetIndexes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range indexes {
+			if strings.EqualFold(index.ID(), h.Index) {
+				return index, nil
+			}
+		}
+	}
+	return nil, ErrHandlerIndexNotFound.New(h.Index, h.table.Name())
+}
+
+// readIndex returns the rows of the table that can match the read of the index given, using an index lookup when
+// there's one for the mode given. The rows returned aren't in any particular order, and may include rows that don't
+// match. For NEXT and PREV, the keys given are the keys of the position of the handler.
+func (h *HandlerRead) readIndex(ctx *sql.Context, table sql.Table, index sql.Index, cols []int, mode string, keys []interface{}) ([]sql.Row, error) {
+	lookup, err := handlerLookup(index, mode, keys, table.Schema()[cols[0]].Nullable)
+	if err != nil {
+		return nil, err
+	}
+
+	if at, ok := table.(sql.IndexAddressableTable); ok && lookup != nil {
+		table = at.WithIndexLookup(lookup)
+	}
+
+	partitions, err := table.Partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowIterToRows(sql.NewTableRowIter(ctx, table, partitions))
+}
+
+// handlerLookup returns the lookup of the index given for the rows of the read with the mode and keys given, or nil if
+// the index has no such lookup. Lookups of ranges of keys are only used for indexes with a single column, since the
+// comparisons of keys of several columns by lookups are up to each index. Lookups don't match NULL keys, which are
+// read before the rest, so they aren't used for NULL keys, nor for reads of smaller keys of nullable columns.
+func handlerLookup(index sql.Index, mode string, keys []interface{}, nullable bool) (sql.IndexLookup, error) {
+	numColumns := len(index.Expressions())
+	if len(keys) != numColumns || (numColumns > 1 && mode != HandlerReadEqual) {
+		return nil, nil
+	}
+	if nullable && (mode == HandlerReadLess || mode == HandlerReadLessOrEqual || mode == HandlerReadPrev) {
+		return nil, nil
+	}
+	for _, k := range keys {
+		if k == nil {
+			return nil, nil
+		}
+	}
+
+	ai, isAscend := index.(sql.AscendIndex)
+	di, isDescend := index.(sql.DescendIndex)
+	switch {
+	case mode == HandlerReadEqual:
+		return index.Get(keys...)
+	case (mode == HandlerReadGreaterOrEqual || mode == HandlerReadNext) && isAscend:
+		return ai.AscendGreaterOrEqual(keys...)
+	case mode == HandlerReadLess && isAscend:
+		return ai.AscendLessThan(keys...)
+	case mode == HandlerReadGreater && isDescend:
+		return di.DescendGreater(keys...)
+	case (mode == HandlerReadLessOrEqual || mode == HandlerReadPrev) && isDescend:
+		return di.DescendLessOrEqual(keys...)
+	default:
+		return nil, nil
+	}
+}
+
+// handlerRowComparer compares rows in the order HANDLER READ reads them in an index: by their keys in the index, with
+// NULL keys first, and then by the rest of their values, so rows with the same keys are still read in the same order.
+type handlerRowComparer struct {
+	schema sql.Schema
+	cols   []int
+	err    error
+}
+
+func (c *handlerRowComparer) key(row sql.Row) []interface{} {
+	key := make([]interface{}, len(c.cols))
+	for i, col := range c.cols {
+		key[i] = row[col]
+	}
+	return key
+}
+
+// compareKey compares the key of the row given with the keys given, which can be a prefix of the columns of the index.
+func (c *handlerRowComparer) compareKey(row sql.Row, keys []interface{}) (int, error) {
+	for i, k := range keys {
+		col := c.cols[i]
+		if n, err := compareNullsFirst(c.schema[col].Type, row[col], k); err != nil || n != 0 {
+			return n, err
+		}
+	}
+	return 0, nil
+}
+
+func (c *handlerRowComparer) compare(a, b sql.Row) (int, error) {
+	if n, err := c.compareKey(a, c.key(b)); err != nil || n != 0 {
+		return n, err
+	}
+	for i, col := range c.schema {
+		if n, err := compareNullsFirst(col.Type, a[i], b[i]); err != nil || n != 0 {
+			return n, err
+		}
+	}
+	return 0, nil
+}
+
+func compareNullsFirst(typ sql.Type, a, b interface{}) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	default:
+		return typ.Compare(a, b)
+	}
+}
+
+// handlerNaturalOrderIter counts the rows consumed from it as read by the handler.
+type handlerNaturalOrderIter struct {
+	sql.RowIter
+	handler *sql.Handler
+}
+
+func (i *handlerNaturalOrderIter) Next() (sql.Row, error) {
+	row, err := i.RowIter.Next()
+	if err != nil {
+		return nil, err
+	}
+	i.handler.Scanned++
+	return row, nil
+}
+
+// handlerIndexOrderIter moves the position of the handler to each row consumed from it.
+type handlerIndexOrderIter struct {
+	rows    []sql.Row
+	handler *sql.Handler
+	index   string
+}
+
+func (i *handlerIndexOrderIter) Next() (sql.Row, error) {
+	if len(i.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := i.rows[0]
+	i.rows = i.rows[1:]
+	i.handler.Index = i.index
+	i.handler.Position = row
+	return row, nil
+}
+
+func (i *handlerIndexOrderIter) Close() error {
+	return nil
+}
@@ -0,0 +1,46 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestHandlerReadMultipleColumns(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Int64, Source: "t"},
+		{Name: "b", Type: sql.Int64, Source: "t"},
+	})
+	require.NoError(table.CreateIndex(ctx, "idx", sql.IndexUsing_Default, sql.IndexConstraint_None, []sql.IndexColumn{{Name: "a"}, {Name: "b"}}, ""))
+	for _, row := range []sql.Row{{int64(1), int64(5)}, {int64(2), int64(1)}, {int64(1), int64(3)}, {int64(3), int64(2)}} {
+		require.NoError(table.Insert(ctx, row))
+	}
+	ctx.AddHandler("t", &sql.Handler{Table: "t"})
+
+	read := func(mode string, limit int64, keys ...int64) []sql.Row {
+		var exprs []sql.Expression
+		for _, k := range keys {
+			exprs = append(exprs, expression.NewLiteral(k, sql.Int64))
+		}
+		node := NewLimit(limit, NewHandlerRead("t", "idx", mode, exprs).WithTable(NewResolvedTable(table)))
+		iter, err := node.RowIter(ctx, nil)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		return rows
+	}
+
+	// Keys of several columns are compared one column after the other, and can be a prefix of the columns
+	require.Equal([]sql.Row{{int64(1), int64(5)}, {int64(2), int64(1)}}, read(HandlerReadGreater, 2, 1, 3))
+	require.Equal([]sql.Row{{int64(3), int64(2)}}, read(HandlerReadNext, 2))
+	require.Equal([]sql.Row{{int64(1), int64(3)}, {int64(1), int64(5)}}, read(HandlerReadEqual, 5, 1))
+	require.Equal([]sql.Row{{int64(2), int64(1)}, {int64(1), int64(5)}}, read(HandlerReadLessOrEqual, 2, 2))
+	require.Equal([]sql.Row{{int64(1), int64(3)}}, read(HandlerReadPrev, 2))
+}
@@ -0,0 +1,431 @@
+package plan
+
+import (
+	"io"
+	"reflect"
+
+	"github.com/opentracing/opentracing-go"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// A HashJoin is a join of two nodes on equalities between their columns that reads the secondary node once to build a
+// hash table of its rows by the values of their keys, and then looks up the rows of the secondary node with the same
+// keys as every row of the primary node in it. It's used for equalities no index can be used for, which would
+// otherwise have the secondary node read once for every row of the primary node.
+type HashJoin struct {
+	// The primary and secondary nodes. As for an IndexedJoin, the Left node is always the primary one, whose rows are
+	// looked up, and the Right node is always the secondary one, whose rows are hashed.
+	BinaryNode
+	// The join condition, which is evaluated on the rows with the same keys too, since different keys may have the
+	// same hash.
+	Cond sql.Expression
+	// The expressions evaluated on the rows of the primary and secondary nodes to get their keys. Keys of both sides
+	// must have the same types.
+	primaryKeys   []sql.Expression
+	secondaryKeys []sql.Expression
+	// The type of join. For left and right joins, the primary node is always the one whose rows are all returned, and
+	// for semi and anti joins the one whose rows are filtered.
+	joinType JoinType
+}
+
+// NewHashJoin returns a new HashJoin of the nodes given, joining them on the join condition given and hashing the rows
+// of the secondary node by the key expressions given.
+func NewHashJoin(primary, secondary sql.Node, joinType JoinType, cond sql.Expression, primaryKeys, secondaryKeys []sql.Expression) *HashJoin {
+	return &HashJoin{
+		BinaryNode:    BinaryNode{primary, secondary},
+		Cond:          cond,
+		primaryKeys:   primaryKeys,
+		secondaryKeys: secondaryKeys,
+		joinType:      joinType,
+	}
+}
+
+var _ sql.Expressioner = (*HashJoin)(nil)
+
+// JoinType returns the join type for this hash join
+func (j *HashJoin) JoinType() JoinType {
+	return j.joinType
+}
+
+// PrimaryKeys returns the expressions evaluated on the rows of the primary node to get the keys to look up.
+func (j *HashJoin) PrimaryKeys() []sql.Expression {
+	return j.primaryKeys
+}
+
+// SecondaryKeys returns the expressions evaluated on the rows of the secondary node to get the keys they're hashed by.
+func (j *HashJoin) SecondaryKeys() []sql.Expression {
+	return j.secondaryKeys
+}
+
+// Expressions implements the sql.Expressioner interface. The join condition comes first, followed by the primary
+// keys and the secondary keys.
+func (j *HashJoin) Expressions() []sql.Expression {
+	exprs := append([]sql.Expression{j.Cond}, j.primaryKeys...)
+	return append(exprs, j.secondaryKeys...)
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (j *HashJoin) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	expected := 1 + len(j.primaryKeys) + len(j.secondaryKeys)
+	if len(exprs) != expected {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(exprs), expected)
+	}
+	keys := len(j.primaryKeys)
+	return NewHashJoin(j.Left, j.Right, j.joinType, exprs[0], exprs[1:1+keys], exprs[1+keys:]), nil
+}
+
+// Resolved implements the Resolvable interface.
+func (j *HashJoin) Resolved() bool {
+	return j.Left.Resolved() && j.Right.Resolved() && j.Cond.Resolved()
+}
+
+func (j *HashJoin) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("%sHashJoin(%s)", j.joinTypeName(), j.Cond)
+	_ = pr.WriteChildren(j.Left.String(), j.Right.String())
+	return pr.String()
+}
+
+func (j *HashJoin) DebugString() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("%sHashJoin(%s), keys(%s = %s)", j.joinTypeName(), sql.DebugString(j.Cond),
+		debugStrings(j.primaryKeys), debugStrings(j.secondaryKeys))
+	_ = pr.WriteChildren(sql.DebugString(j.Left), sql.DebugString(j.Right))
+	return pr.String()
+}
+
+func (j *HashJoin) joinTypeName() string {
+	switch j.joinType {
+	case JoinTypeLeft:
+		return "Left"
+	case JoinTypeRight:
+		return "Right"
+	case JoinTypeSemi:
+		return "Semi"
+	case JoinTypeAnti:
+		return "Anti"
+	}
+	return ""
+}
+
+func debugStrings(exprs []sql.Expression) string {
+	var s string
+	for i, e := range exprs {
+		if i > 0 {
+			s += ", "
+		}
+		s += sql.DebugString(e)
+	}
+	return s
+}
+
+// Schema implements the Node interface.
+func (j *HashJoin) Schema() sql.Schema {
+	if j.joinType.filtersPrimary() {
+		return j.Left.Schema()
+	}
+	if j.joinType == JoinTypeLeft || j.joinType == JoinTypeRight {
+		return append(j.Left.Schema(), makeNullable(j.Right.Schema())...)
+	}
+	return append(j.Left.Schema(), j.Right.Schema()...)
+}
+
+// RowIter implements the Node interface.
+func (j *HashJoin) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var leftName, rightName string
+	if leftTable, ok := j.Left.(sql.Nameable); ok {
+		leftName = leftTable.Name()
+	} else {
+		leftName = reflect.TypeOf(j.Left).String()
+	}
+
+	if rightTable, ok := j.Right.(sql.Nameable); ok {
+		rightName = rightTable.Name()
+	} else {
+		rightName = reflect.TypeOf(j.Right).String()
+	}
+
+	span, ctx := ctx.Span("plan.hashJoin", opentracing.Tags{
+		"left":  leftName,
+		"right": rightName,
+	})
+
+	l, err := j.Left.RowIter(ctx, nil)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return sql.NewSpanIter(span, &hashJoinIter{
+		ctx:               ctx,
+		joinType:          j.joinType,
+		primary:           l,
+		secondaryProvider: j.Right,
+		primaryKeys:       j.primaryKeys,
+		secondaryKeys:     j.secondaryKeys,
+		cond:              j.Cond,
+		secondaryLen:      len(j.Right.Schema()),
+	}), nil
+}
+
+// WithChildren implements the Node interface.
+func (j *HashJoin) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 2 {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(children), 2)
+	}
+	return NewHashJoin(children[0], children[1], j.joinType, j.Cond, j.primaryKeys, j.secondaryKeys), nil
+}
+
+// hashJoinIter is an iterator that hashes the rows of the secondary node by their keys before its first row, and then
+// iterates over every row of the primary node and the rows of the secondary node with the same key. If the rows of the
+// secondary node don't fit in memory, it's read again for every row of the primary node instead, like for other joins.
+type hashJoinIter struct {
+	ctx               *sql.Context
+	joinType          JoinType
+	primary           sql.RowIter
+	secondaryProvider sql.Node
+	primaryKeys       []sql.Expression
+	secondaryKeys     []sql.Expression
+	cond              sql.Expression
+	// secondaryLen is the length of the rows of the secondary node, without the row of the outer scope they start with
+	// in subqueries.
+	secondaryLen int
+
+	built     bool
+	rows      sql.KeyValueCache
+	dispose   sql.DisposeFunc
+	multipass bool
+
+	primaryRow sql.Row
+	foundMatch bool
+	// candidates are the rows of the secondary node with the same key hash as the primary row, and secondary the
+	// iterator of all of them when the join is computed in multiple passes.
+	candidates []sql.Row
+	secondary  sql.RowIter
+}
+
+func (i *hashJoinIter) Dispose() {
+	if i.dispose != nil {
+		i.dispose()
+		i.dispose = nil
+	}
+}
+
+// build hashes the rows of the secondary node by their keys. Rows with null keys are left out, since they aren't equal
+// to any key.
+func (i *hashJoinIter) build() error {
+	i.built = true
+	i.rows, i.dispose = i.ctx.Memory.NewHistoryCache()
+
+	iter, err := i.secondaryProvider.RowIter(i.ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+
+		hash, ok, err := hashJoinKey(i.ctx, i.secondaryKeys, row)
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		var rows []sql.Row
+		if v, err := i.rows.Get(hash); err == nil {
+			rows = v.([]sql.Row)
+		}
+		if err := i.rows.Put(hash, append(rows, row)); err != nil {
+			if sql.ErrNoMemoryAvailable.Is(err) {
+				i.Dispose()
+				i.rows = nil
+				i.multipass = true
+				break
+			}
+			_ = iter.Close()
+			return err
+		}
+	}
+
+	return iter.Close()
+}
+
+func (i *hashJoinIter) loadPrimary() error {
+	r, err := i.primary.Next()
+	if err != nil {
+		if err == io.EOF {
+			i.Dispose()
+		}
+		return err
+	}
+
+	i.primaryRow = r
+	i.foundMatch = false
+
+	if i.multipass {
+		i.secondary, err = i.secondaryProvider.RowIter(i.ctx, nil)
+		return err
+	}
+
+	i.candidates = nil
+	hash, ok, err := hashJoinKey(i.ctx, i.primaryKeys, r)
+	if err != nil || !ok {
+		return err
+	}
+	if v, err := i.rows.Get(hash); err == nil {
+		i.candidates = v.([]sql.Row)
+	}
+	return nil
+}
+
+func (i *hashJoinIter) loadSecondary() (sql.Row, error) {
+	if i.multipass {
+		row, err := i.secondary.Next()
+		if err == io.EOF {
+			err = i.secondary.Close()
+			i.secondary = nil
+			if err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		return row, err
+	}
+
+	if len(i.candidates) == 0 {
+		return nil, io.EOF
+	}
+	row := i.candidates[0]
+	i.candidates = i.candidates[1:]
+	return row, nil
+}
+
+func (i *hashJoinIter) Next() (sql.Row, error) {
+	for {
+		// The rows with the same key are in memory, so the query may be interrupted between any two of them
+		if err := i.ctx.Interrupted(); err != nil {
+			return nil, err
+		}
+
+		if !i.built {
+			if err := i.build(); err != nil {
+				return nil, err
+			}
+		}
+
+		if i.primaryRow == nil {
+			if err := i.loadPrimary(); err != nil {
+				return nil, err
+			}
+		}
+
+		primary := i.primaryRow
+		secondary, err := i.loadSecondary()
+		if err != nil {
+			if err == io.EOF {
+				i.primaryRow = nil
+				if !i.foundMatch && (i.joinType == JoinTypeLeft || i.joinType == JoinTypeRight) {
+					return i.buildRow(primary, nil), nil
+				}
+				if i.joinType == JoinTypeAnti {
+					return primary, nil
+				}
+				continue
+			}
+			return nil, err
+		}
+
+		row := i.buildRow(primary, secondary)
+		matches, err := conditionIsTrue(i.ctx, row, i.cond)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if i.joinType.filtersPrimary() {
+			if err := i.skipSecondary(); err != nil {
+				return nil, err
+			}
+			if i.joinType == JoinTypeAnti {
+				continue
+			}
+			return primary, nil
+		}
+
+		i.foundMatch = true
+		return row, nil
+	}
+}
+
+// skipSecondary moves on to the next primary row without going through the rest of the candidate rows for it.
+func (i *hashJoinIter) skipSecondary() error {
+	i.primaryRow = nil
+	i.candidates = nil
+	if i.secondary != nil {
+		err := i.secondary.Close()
+		i.secondary = nil
+		return err
+	}
+	return nil
+}
+
+// buildRow builds the result set row using the rows from the primary and secondary nodes
+func (i *hashJoinIter) buildRow(primary, secondary sql.Row) sql.Row {
+	return buildJoinRow(primary, secondary, i.secondaryLen)
+}
+
+func (i *hashJoinIter) Close() (err error) {
+	i.Dispose()
+	i.candidates = nil
+
+	if i.primary != nil {
+		if err = i.primary.Close(); err != nil {
+			if i.secondary != nil {
+				_ = i.secondary.Close()
+			}
+			return err
+		}
+	}
+
+	if i.secondary != nil {
+		err = i.secondary.Close()
+		i.secondary = nil
+	}
+
+	return err
+}
+
+// hashJoinKey returns the hash of the values of the key expressions given on the row given, converted to the types of
+// the expressions so equal values of different go types have the same hash, and whether the key has no null values.
+func hashJoinKey(ctx *sql.Context, keys []sql.Expression, row sql.Row) (uint64, bool, error) {
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		v, err := key.Eval(ctx, row)
+		if err != nil {
+			return 0, false, err
+		}
+		if v == nil {
+			return 0, false, nil
+		}
+
+		values[i], err = key.Type().Convert(v)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	return sql.CacheKey(values), true, nil
+}
@@ -0,0 +1,137 @@
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestHashJoin(t *testing.T) {
+	testHashJoin(t, sql.NewEmptyContext())
+}
+
+func TestMultiPassHashJoin(t *testing.T) {
+	ctx := sql.NewContext(context.TODO(), sql.WithMemoryManager(
+		sql.NewMemoryManager(mockReporter{2, 1}),
+	))
+	testHashJoin(t, ctx)
+}
+
+func testHashJoin(t *testing.T, ctx *sql.Context) {
+	t.Helper()
+
+	require := require.New(t)
+	ltable := memory.NewTable("left", lSchema)
+	rtable := memory.NewTable("right", makeNullable(rSchema))
+	insertData(t, ltable)
+	insertData(t, rtable)
+	require.NoError(rtable.Insert(ctx, sql.NewRow("col1_1", "col2_3", int32(5), int64(6))))
+	require.NoError(rtable.Insert(ctx, sql.NewRow(nil, "col2_4", int32(7), int64(8))))
+
+	lcol1 := expression.NewGetField(0, sql.Text, "lcol1", true)
+	rcol1 := expression.NewGetField(0, sql.Text, "rcol1", true)
+	cond := expression.NewEquals(lcol1, expression.NewGetField(4, sql.Text, "rcol1", true))
+
+	j := NewHashJoin(NewResolvedTable(ltable), NewResolvedTable(rtable), JoinTypeInner, cond,
+		[]sql.Expression{lcol1}, []sql.Expression{rcol1})
+
+	iter, err := j.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{
+		{"col1_1", "col2_1", int32(1), int64(2), "col1_1", "col2_1", int32(1), int64(2)},
+		{"col1_1", "col2_1", int32(1), int64(2), "col1_1", "col2_3", int32(5), int64(6)},
+		{"col1_2", "col2_2", int32(3), int64(4), "col1_2", "col2_2", int32(3), int64(4)},
+	}, rows)
+}
+
+func TestLeftHashJoin(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	ltable := memory.NewTable("left", makeNullable(lSchema))
+	rtable := memory.NewTable("right", rSchema)
+	insertData(t, ltable)
+	insertData(t, rtable)
+	require.NoError(ltable.Insert(ctx, sql.NewRow("col1_3", "col2_3", nil, int64(6))))
+
+	// Keys of different go types with the same value are hashed the same
+	lcol3 := expression.NewGetField(2, sql.Int32, "lcol3", true)
+	rcol4 := expression.NewGetField(3, sql.Int64, "rcol4", true)
+	cond := expression.NewEquals(
+		expression.NewPlus(lcol3, expression.NewLiteral(int32(1), sql.Int32)),
+		expression.NewGetField(7, sql.Int64, "rcol4", true),
+	)
+
+	j := NewHashJoin(NewResolvedTable(ltable), NewResolvedTable(rtable), JoinTypeLeft, cond,
+		[]sql.Expression{expression.NewConvert(
+			expression.NewPlus(lcol3, expression.NewLiteral(int32(1), sql.Int32)), expression.ConvertToSigned)},
+		[]sql.Expression{expression.NewConvert(rcol4, expression.ConvertToSigned)})
+
+	require.Equal(sql.Schema{
+		{Name: "lcol1", Type: sql.Text, Nullable: true},
+		{Name: "lcol2", Type: sql.Text, Nullable: true},
+		{Name: "lcol3", Type: sql.Int32, Nullable: true},
+		{Name: "lcol4", Type: sql.Int64, Nullable: true},
+		{Name: "rcol1", Type: sql.Text, Nullable: true},
+		{Name: "rcol2", Type: sql.Text, Nullable: true},
+		{Name: "rcol3", Type: sql.Int32, Nullable: true},
+		{Name: "rcol4", Type: sql.Int64, Nullable: true},
+	}, j.Schema())
+
+	iter, err := j.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{
+		{"col1_1", "col2_1", int32(1), int64(2), "col1_1", "col2_1", int32(1), int64(2)},
+		{"col1_2", "col2_2", int32(3), int64(4), "col1_2", "col2_2", int32(3), int64(4)},
+		{"col1_3", "col2_3", nil, int64(6), nil, nil, nil, nil},
+	}, rows)
+}
+
+func TestSemiHashJoin(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	ltable := memory.NewTable("left", makeNullable(lSchema))
+	rtable := memory.NewTable("right", rSchema)
+	insertData(t, ltable)
+	insertData(t, rtable)
+	require.NoError(ltable.Insert(ctx, sql.NewRow(nil, "col2_3", int32(5), int64(6))))
+	require.NoError(rtable.Insert(ctx, sql.NewRow("col1_1", "col2_3", int32(5), int64(6))))
+
+	lcol1 := expression.NewGetField(0, sql.Text, "lcol1", true)
+	rcol1 := expression.NewGetField(0, sql.Text, "rcol1", false)
+	cond := expression.NewEquals(lcol1, expression.NewGetField(4, sql.Text, "rcol1", false))
+
+	// Rows with several matches in the hash table are returned once, and rows with NULL keys match none
+	semi := NewHashJoin(NewResolvedTable(ltable), NewResolvedTable(rtable), JoinTypeSemi,
+		cond, []sql.Expression{lcol1}, []sql.Expression{rcol1})
+	anti := NewHashJoin(NewResolvedTable(ltable), NewResolvedTable(rtable), JoinTypeAnti,
+		cond, []sql.Expression{lcol1}, []sql.Expression{rcol1})
+	require.Equal(sql.Schema(makeNullable(lSchema)), semi.Schema())
+
+	iter, err := semi.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{
+		{"col1_1", "col2_1", int32(1), int64(2)},
+		{"col1_2", "col2_2", int32(3), int64(4)},
+	}, rows)
+
+	iter, err = anti.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err = sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.ElementsMatch([]sql.Row{
+		{nil, "col2_3", int32(5), int64(6)},
+	}, rows)
+}
@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// IfElse is the IF statement of a BEGIN ... END block, which runs the statements of the first branch whose condition
+// is true, or the statements of its ELSE branch if none is.
+type IfElse struct {
+	Conditions []sql.Expression
+
+	// branches has the statements of each condition, followed by the ones of the ELSE branch if there's one.
+	branches [][]sql.Node
+	frame    *BlockFrame
+}
+
+var _ sql.Node = (*IfElse)(nil)
+var _ sql.Expressioner = (*IfElse)(nil)
+
+// NewIfElse creates a new IfElse node with the conditions and branches given, in the block of the frame given. There's
+// a branch for each condition, and an optional last one for ELSE.
+func NewIfElse(conditions []sql.Expression, branches [][]sql.Node, frame *BlockFrame) *IfElse {
+	return &IfElse{Conditions: conditions, branches: branches, frame: frame}
+}
+
+// Resolved implements the sql.Node interface.
+func (i *IfElse) Resolved() bool {
+	for _, c := range i.Conditions {
+		if !c.Resolved() {
+			return false
+		}
+	}
+	for _, s := range i.Children() {
+		if !s.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Children implements the sql.Node interface.
+func (i *IfElse) Children() []sql.Node {
+	var children []sql.Node
+	for _, b := range i.branches {
+		children = append(children, b...)
+	}
+	return children
+}
+
+// Schema implements the sql.Node interface.
+func (*IfElse) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (i *IfElse) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != len(i.Children()) {
+		return nil, sql.ErrInvalidChildrenNumber.New(i, len(children), len(i.Children()))
+	}
+
+	branches := make([][]sql.Node, len(i.branches))
+	for j, b := range i.branches {
+		branches[j], children = children[:len(b)], children[len(b):]
+	}
+	return NewIfElse(i.Conditions, branches, i.frame), nil
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (i *IfElse) Expressions() []sql.Expression {
+	return i.Conditions
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (i *IfElse) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(i.Conditions) {
+		return nil, sql.ErrInvalidChildrenNumber.New(i, len(exprs), len(i.Conditions))
+	}
+	return NewIfElse(exprs, i.branches, i.frame), nil
+}
+
+func (i *IfElse) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("IF")
+	var children []string
+	for j, b := range i.branches {
+		bp := sql.NewTreePrinter()
+		if j < len(i.Conditions) {
+			_ = bp.WriteNode("THEN %s", i.Conditions[j])
+		} else {
+			_ = bp.WriteNode("ELSE")
+		}
+		var statements []string
+		for _, s := range b {
+			statements = append(statements, s.String())
+		}
+		_ = bp.WriteChildren(statements...)
+		children = append(children, bp.String())
+	}
+	_ = pr.WriteChildren(children...)
+	return pr.String()
+}
+
+// RowIter implements the sql.Node interface.
+func (i *IfElse) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return newBlockIter(ctx, row, i.run), nil
+}
+
+func (i *IfElse) run(ctx *sql.Context, row sql.Row) error {
+	for j, c := range i.Conditions {
+		ok, err := sql.EvaluateCondition(ctx, c, row)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return runStatements(ctx, row, i.frame, i.branches[j])
+		}
+	}
+
+	if len(i.branches) > len(i.Conditions) {
+		return runStatements(ctx, row, i.frame, i.branches[len(i.Conditions)])
+	}
+	return nil
+}
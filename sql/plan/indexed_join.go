@@ -17,15 +17,81 @@ type IndexedJoin struct {
 	BinaryNode
 	// The join condition.
 	Cond sql.Expression
+	// The part of the join condition evaluated on the rows looked up, without the equalities of the key, which the rows
+	// looked up always satisfy. Nil if there's nothing left to evaluate.
+	residual sql.Expression
 	// The index to use when looking up rows in the secondary table.
 	Index sql.Index
 	// The expression to evaluate to extract a key value from a row in the primary table.
 	primaryTableExpr []sql.Expression
+	// The range of keys to look up in the index for a row in the primary table, for joins on comparisons instead of
+	// equalities. The primary table expressions are empty if it's set.
+	keyRange *IndexedJoinRange
 	// The type of join. Left and right refer to the lexical position in the written query, not primary / secondary. In
-	// the case of a right join, the right table will always be the primary.
+	// the case of a right join, the right table will always be the primary. Semi and anti joins return the rows of the
+	// primary table only.
 	joinType JoinType
 }
 
+// An IndexedJoinRange is a range of values of the column of a single column index that an IndexedJoin looks up for
+// every row of its primary table, with bounds evaluated on the row. A missing bound leaves its side of the range
+// unbounded.
+type IndexedJoinRange struct {
+	Lower          sql.Expression
+	LowerInclusive bool
+	Upper          sql.Expression
+	UpperInclusive bool
+}
+
+// expressions returns the bounds of the range, lower first.
+func (r *IndexedJoinRange) expressions() []sql.Expression {
+	var exprs []sql.Expression
+	if r.Lower != nil {
+		exprs = append(exprs, r.Lower)
+	}
+	if r.Upper != nil {
+		exprs = append(exprs, r.Upper)
+	}
+	return exprs
+}
+
+// withExpressions returns a copy of the range with the bounds given, in the order of expressions.
+func (r *IndexedJoinRange) withExpressions(exprs []sql.Expression) *IndexedJoinRange {
+	nr := *r
+	if nr.Lower != nil {
+		nr.Lower, exprs = exprs[0], exprs[1:]
+	}
+	if nr.Upper != nil {
+		nr.Upper = exprs[0]
+	}
+	return &nr
+}
+
+func (r *IndexedJoinRange) String() string {
+	var lower, upper string
+	if r.Lower != nil {
+		lower = r.Lower.String()
+		if r.LowerInclusive {
+			lower = "[" + lower
+		} else {
+			lower = "(" + lower
+		}
+	} else {
+		lower = "(-inf"
+	}
+	if r.Upper != nil {
+		upper = r.Upper.String()
+		if r.UpperInclusive {
+			upper += "]"
+		} else {
+			upper += ")"
+		}
+	} else {
+		upper = "inf)"
+	}
+	return lower + ", " + upper
+}
+
 // JoinType returns the join type for this indexed join
 func (ij *IndexedJoin) JoinType() JoinType {
 	return ij.joinType
@@ -36,40 +102,133 @@ func NewIndexedJoin(primaryTable, indexedTable sql.Node, joinType JoinType, cond
 		BinaryNode:       BinaryNode{primaryTable, indexedTable},
 		joinType:         joinType,
 		Cond:             cond,
+		residual:         cond,
 		Index:            index,
 		primaryTableExpr: primaryTableExpr,
 	}
 }
 
+// NewRangeIndexedJoin returns a new IndexedJoin that looks up the range given of the single column index given for
+// every row of the primary table.
+func NewRangeIndexedJoin(primaryTable, indexedTable sql.Node, joinType JoinType, cond sql.Expression, keyRange *IndexedJoinRange, index sql.Index) *IndexedJoin {
+	ij := NewIndexedJoin(primaryTable, indexedTable, joinType, cond, nil, index)
+	ij.keyRange = keyRange
+	return ij
+}
+
+// WithResidual returns a copy of the join that evaluates the residual condition given on the rows it looks up instead
+// of its whole condition, or nothing if it's nil. The residual must leave out only equalities of the key whose lookups
+// return exactly the rows that satisfy them.
+func (ij *IndexedJoin) WithResidual(residual sql.Expression) *IndexedJoin {
+	nij := *ij
+	nij.residual = residual
+	return &nij
+}
+
+// Residual returns the part of the join condition evaluated on the rows looked up, or nil if there's none.
+func (ij *IndexedJoin) Residual() sql.Expression {
+	return ij.residual
+}
+
+var _ sql.Expressioner = (*IndexedJoin)(nil)
+
+// Expressions implements the sql.Expressioner interface. The join condition comes first, followed by the residual
+// condition if there's one, which are evaluated on the rows of both tables, and then by the primary table expressions
+// or the bounds of the key range, all of which are evaluated on the rows of the primary table.
+func (ij *IndexedJoin) Expressions() []sql.Expression {
+	exprs := append(ij.conditions(), ij.primaryTableExpr...)
+	if ij.keyRange != nil {
+		exprs = append(exprs, ij.keyRange.expressions()...)
+	}
+	return exprs
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (ij *IndexedJoin) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	conds := len(ij.conditions())
+	expected := conds + len(ij.primaryTableExpr)
+	if ij.keyRange != nil {
+		expected += len(ij.keyRange.expressions())
+	}
+	if len(exprs) != expected {
+		return nil, sql.ErrInvalidChildrenNumber.New(ij, len(exprs), expected)
+	}
+
+	nij := *ij
+	nij.Cond = exprs[0]
+	if ij.residual != nil {
+		nij.residual = exprs[1]
+	}
+	nij.primaryTableExpr = exprs[conds : conds+len(ij.primaryTableExpr)]
+	if ij.keyRange != nil {
+		nij.keyRange = ij.keyRange.withExpressions(exprs[conds+len(ij.primaryTableExpr):])
+	}
+	return &nij, nil
+}
+
+// conditions returns the join condition and the residual condition, if there's one.
+func (ij *IndexedJoin) conditions() []sql.Expression {
+	if ij.residual == nil {
+		return []sql.Expression{ij.Cond}
+	}
+	return []sql.Expression{ij.Cond, ij.residual}
+}
+
+// KeyRange returns the range of keys looked up in the index for every row of the primary table, or nil if the join
+// looks up the key of its primary table expressions.
+func (ij *IndexedJoin) KeyRange() *IndexedJoinRange {
+	return ij.keyRange
+}
+
+// PrimaryTableExpressions returns the expressions evaluated on the rows of the primary table to get the key to look
+// up in the secondary table.
+func (ij *IndexedJoin) PrimaryTableExpressions() []sql.Expression {
+	return ij.primaryTableExpr
+}
+
 func (ij *IndexedJoin) String() string {
 	pr := sql.NewTreePrinter()
-	joinType := ""
-	switch ij.joinType {
-	case JoinTypeLeft:
-		joinType = "Left"
-	case JoinTypeRight:
-		joinType = "Right"
-	}
-	_ = pr.WriteNode("%sIndexedJoin(%s)", joinType, ij.Cond)
+	_ = pr.WriteNode("%sIndexedJoin(%s)", ij.joinTypeName(), ij.Cond)
 	_ = pr.WriteChildren(ij.Left.String(), ij.Right.String())
 	return pr.String()
 }
 
 func (ij *IndexedJoin) DebugString() string {
 	pr := sql.NewTreePrinter()
-	joinType := ""
+	var residual string
+	switch {
+	case ij.residual == nil:
+		residual = ", residual none"
+	case ij.residual.String() != ij.Cond.String():
+		residual = ", residual " + sql.DebugString(ij.residual)
+	}
+	if ij.keyRange != nil {
+		_ = pr.WriteNode("%sIndexedJoin(%s), using index(%s), range %s%s", ij.joinTypeName(), sql.DebugString(ij.Cond), ij.Index.ID(), ij.keyRange, residual)
+	} else {
+		_ = pr.WriteNode("%sIndexedJoin(%s), using index(%s)%s", ij.joinTypeName(), sql.DebugString(ij.Cond), ij.Index.ID(), residual)
+	}
+	_ = pr.WriteChildren(sql.DebugString(ij.Left), sql.DebugString(ij.Right))
+	return pr.String()
+}
+
+func (ij *IndexedJoin) joinTypeName() string {
 	switch ij.joinType {
 	case JoinTypeLeft:
-		joinType = "Left"
+		return "Left"
 	case JoinTypeRight:
-		joinType = "Right"
+		return "Right"
+	case JoinTypeSemi:
+		return "Semi"
+	case JoinTypeAnti:
+		return "Anti"
 	}
-	_ = pr.WriteNode("%sIndexedJoin(%s), using index(%s)", joinType, sql.DebugString(ij.Cond), ij.Index.ID())
-	_ = pr.WriteChildren(sql.DebugString(ij.Left), sql.DebugString(ij.Right))
-	return pr.String()
+	return ""
 }
 
 func (ij *IndexedJoin) Schema() sql.Schema {
+	if ij.joinType.filtersPrimary() {
+		return ij.Left.Schema()
+	}
 	return append(ij.Left.Schema(), ij.Right.Schema()...)
 }
 
@@ -87,17 +246,19 @@ func (ij *IndexedJoin) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, erro
 		return nil, ErrNoIndexedTableAccess.New(ij.Right)
 	}
 
-	return indexedJoinRowIter(ctx, ij.Left, ij.Right, indexedTable, ij.primaryTableExpr, ij.Cond, ij.Index, ij.joinType)
+	return indexedJoinRowIter(ctx, ij.Left, ij.Right, indexedTable, ij.primaryTableExpr, ij.keyRange, ij.residual, ij.Index, ij.joinType)
 }
 
 func (ij *IndexedJoin) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 2 {
 		return nil, sql.ErrInvalidChildrenNumber.New(ij, len(children), 2)
 	}
-	return NewIndexedJoin(children[0], children[1], ij.joinType, ij.Cond, ij.primaryTableExpr, ij.Index), nil
+	nij := *ij
+	nij.BinaryNode = BinaryNode{children[0], children[1]}
+	return &nij, nil
 }
 
-func indexedJoinRowIter(ctx *sql.Context, left sql.Node, right sql.Node, indexAccess *IndexedTableAccess, primaryTableExpr []sql.Expression, cond sql.Expression, index sql.Index, joinType JoinType) (sql.RowIter, error) {
+func indexedJoinRowIter(ctx *sql.Context, left sql.Node, right sql.Node, indexAccess *IndexedTableAccess, primaryTableExpr []sql.Expression, keyRange *IndexedJoinRange, cond sql.Expression, index sql.Index, joinType JoinType) (sql.RowIter, error) {
 	var leftName, rightName string
 	if leftTable, ok := left.(sql.Nameable); ok {
 		leftName = leftTable.Name()
@@ -128,12 +289,40 @@ func indexedJoinRowIter(ctx *sql.Context, left sql.Node, right sql.Node, indexAc
 		ctx:                  ctx,
 		cond:                 cond,
 		primaryTableExpr:     primaryTableExpr,
+		keyRange:             keyRange,
 		index:                index,
 		joinType:             joinType,
-		rowSize:              len(left.Schema()) + len(right.Schema()),
+		secondaryLen:         len(right.Schema()),
+		cacheable:            keyRange == nil && isDeterministic(right),
 	}), nil
 }
 
+// indexedJoinCacheSize is the number of the last keys looked up in the secondary table whose rows an IndexedJoin
+// keeps, so that primary rows with keys that repeat, like those of foreign keys of few distinct values, don't look
+// them up in the index again. The keys are kept in an LRU cache of the memory manager of the query, which doesn't
+// keep them when memory runs out, and frees them along with its other caches when needed.
+const indexedJoinCacheSize = 128
+
+// indexedJoinMissingCacheSize is the number of the last keys looked up in the secondary table without any rows that
+// an IndexedJoin keeps, apart from the keys with rows, so that the primary rows of hot keys without a match, like
+// those of outer joins on optional references, skip their lookups without pushing the keys with rows out of the
+// cache. Only the keys are kept, in an LRU cache of the memory manager of the query too. The keys are kept whole, not
+// in a bloom filter, since its false positives would leave out rows that match.
+const indexedJoinMissingCacheSize = 1024
+
+// isDeterministic returns whether the expressions of the node given and its children always evaluate the same on
+// the same rows, so that the rows the node returns can be cached.
+func isDeterministic(n sql.Node) bool {
+	deterministic := true
+	InspectExpressions(n, func(e sql.Expression) bool {
+		if nd, ok := e.(sql.NonDeterministicExpression); ok && nd.IsNonDeterministic() {
+			deterministic = false
+		}
+		return deterministic
+	})
+	return deterministic
+}
+
 // indexedJoinIter is an iterator that iterates over every row in the primary table and performs an index lookup in
 // the secondary table for each value
 type indexedJoinIter struct {
@@ -143,13 +332,38 @@ type indexedJoinIter struct {
 	secondaryIndexAccess *IndexedTableAccess
 	secondaryProvider    sql.Node
 	secondary            sql.RowIter
-	primaryTableExpr     []sql.Expression
-	cond                 sql.Expression
-	joinType             JoinType
+	// secondaryRows are the rows of the secondary table cached for the key of the primary row that are left to read,
+	// which are read instead of the secondary iterator.
+	secondaryRows    []sql.Row
+	primaryTableExpr []sql.Expression
+	keyRange         *IndexedJoinRange
+	// cond is the residual condition of the join, nil if the rows looked up always match.
+	cond     sql.Expression
+	joinType JoinType
 
 	ctx        *sql.Context
 	foundMatch bool
-	rowSize    int
+	// secondaryLen is the length of the rows of the secondary table, without the row of the outer scope they start
+	// with in subqueries.
+	secondaryLen int
+
+	// cache has the rows of the secondary table of the last keys looked up, if the join is cacheable: it looks up keys,
+	// rather than ranges, in a secondary node without non-deterministic expressions. While caching, the rows looked up
+	// for the key of the primary row are collected in the entry given, to be cached once they're all read. The last
+	// keys looked up without rows are cached in missing instead.
+	cacheable      bool
+	cache          sql.KeyValueCache
+	disposeCache   sql.DisposeFunc
+	missing        sql.KeyValueCache
+	disposeMissing sql.DisposeFunc
+	caching        *indexedJoinCacheEntry
+}
+
+// indexedJoinCacheEntry is the key of the primary table expressions of an IndexedJoin and the rows of the secondary
+// table looked up for it.
+type indexedJoinCacheEntry struct {
+	key  []interface{}
+	rows []sql.Row
 }
 
 func (i *indexedJoinIter) loadPrimary() error {
@@ -167,52 +381,244 @@ func (i *indexedJoinIter) loadPrimary() error {
 }
 
 func (i *indexedJoinIter) loadSecondary() (sql.Row, error) {
-	if i.secondary == nil {
-		// evaluate the primary row against the primary table expression to get the secondary table lookup key
-		var key []interface{}
-		for _, expr := range i.primaryTableExpr {
-			col, err := expr.Eval(i.ctx, i.primaryRow)
-			if err != nil {
+	if i.secondary == nil && i.secondaryRows == nil {
+		ok, err := i.openSecondary()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// No row of the secondary table is in a range with a NULL bound, or has a key with a NULL or cached as missing
+			i.primaryRow = nil
+			return nil, io.EOF
+		}
+	}
+
+	if i.secondaryRows != nil {
+		if len(i.secondaryRows) == 0 {
+			i.secondaryRows = nil
+			i.primaryRow = nil
+			return nil, io.EOF
+		}
+		row := i.secondaryRows[0]
+		i.secondaryRows = i.secondaryRows[1:]
+		return row, nil
+	}
+
+	secondaryRow, err := i.secondary.Next()
+	if err != nil {
+		if err == io.EOF {
+			i.secondary = nil
+			i.primaryRow = nil
+			if err := i.cacheRows(); err != nil {
 				return nil, err
 			}
-			key = append(key, col)
+			return nil, io.EOF
 		}
+		return nil, err
+	}
+
+	if i.caching != nil {
+		i.caching.rows = append(i.caching.rows, secondaryRow)
+	}
+	return secondaryRow, nil
+}
 
-		lookup, err := i.index.Get(key...)
+// openSecondary opens the iterator of the rows of the secondary table for the primary row, or starts reading the rows
+// cached for its key if it was looked up lately. It returns false if there are no rows to read: for ranges with a NULL
+// bound, keys with a NULL, which no equality matches, and the keys cached as missing, without looking them up.
+func (i *indexedJoinIter) openSecondary() (bool, error) {
+	i.caching = nil
+
+	var lookup sql.IndexLookup
+	var err error
+	if i.keyRange != nil {
+		var ok bool
+		lookup, ok, err = i.rangeLookup()
+		if err != nil || !ok {
+			return false, err
+		}
+	} else {
+		var key []interface{}
+		key, err = i.primaryKey()
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+
+		if hasNull(key) || i.isMissing(key) {
+			return false, nil
+		}
+		if rows, ok := i.cachedRows(key); ok {
+			i.secondaryRows = rows
+			return true, nil
+		}
+		if i.cacheable {
+			i.caching = &indexedJoinCacheEntry{key: key}
 		}
 
-		err = i.secondaryIndexAccess.SetIndexLookup(i.ctx, lookup)
+		lookup, err = i.keyLookup(key)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+	}
 
-		span, ctx := i.ctx.Span("plan.IndexedJoin indexed lookup")
-		rowIter, err := i.secondaryProvider.RowIter(ctx, nil)
+	err = i.secondaryIndexAccess.SetIndexLookup(i.ctx, lookup)
+	if err != nil {
+		return false, err
+	}
+
+	span, ctx := i.ctx.Span("plan.IndexedJoin indexed lookup")
+	rowIter, err := i.secondaryProvider.RowIter(ctx, nil)
+	if err != nil {
+		span.Finish()
+		return false, err
+	}
+
+	i.secondary = sql.NewSpanIter(span, rowIter)
+	return true, nil
+}
+
+// primaryKey evaluates the primary table expressions on the primary row to get the key to look up in the secondary
+// table.
+func (i *indexedJoinIter) primaryKey() ([]interface{}, error) {
+	var key []interface{}
+	for _, expr := range i.primaryTableExpr {
+		col, err := expr.Eval(i.ctx, i.primaryRow)
 		if err != nil {
-			span.Finish()
 			return nil, err
 		}
+		key = append(key, col)
+	}
+	return key, nil
+}
+
+// hasNull returns whether any value of the key given is NULL.
+func hasNull(key []interface{}) bool {
+	for _, v := range key {
+		if v == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// keyLookup returns the lookup of the rows of the secondary table with the key given.
+func (i *indexedJoinIter) keyLookup(key []interface{}) (sql.IndexLookup, error) {
+	// Keys of fewer columns than the index has are keys of a prefix of its columns
+	if pi, ok := i.index.(sql.PrefixIndex); ok && len(key) < len(i.index.Expressions()) {
+		return pi.GetPrefix(key...)
+	}
+	return i.index.Get(key...)
+}
 
-		i.secondary = sql.NewSpanIter(span, rowIter)
+// cachedRows returns the rows of the secondary table cached for the key given, if any.
+func (i *indexedJoinIter) cachedRows(key []interface{}) ([]sql.Row, bool) {
+	if i.cache == nil {
+		return nil, false
 	}
 
-	secondaryRow, err := i.secondary.Next()
+	v, err := i.cache.Get(sql.CacheKey(key))
 	if err != nil {
-		if err == io.EOF {
-			i.secondary = nil
-			i.primaryRow = nil
-			return nil, io.EOF
+		return nil, false
+	}
+	// Keys with the same hash aren't the same key
+	entry := v.(*indexedJoinCacheEntry)
+	if !reflect.DeepEqual(entry.key, key) {
+		return nil, false
+	}
+	return entry.rows, true
+}
+
+// isMissing returns whether the key given is cached as a key without rows in the secondary table.
+func (i *indexedJoinIter) isMissing(key []interface{}) bool {
+	if i.missing == nil {
+		return false
+	}
+
+	v, err := i.missing.Get(sql.CacheKey(key))
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(v, key)
+}
+
+// cacheRows caches the rows of the secondary table looked up for the key of the last primary row, once they're all
+// read, or the key as missing if there were none.
+func (i *indexedJoinIter) cacheRows() error {
+	entry := i.caching
+	if entry == nil {
+		return nil
+	}
+	i.caching = nil
+
+	if len(entry.rows) == 0 {
+		if i.missing == nil {
+			i.missing, i.disposeMissing = i.ctx.Memory.NewLRUCache(indexedJoinMissingCacheSize)
 		}
-		return nil, err
+		return i.missing.Put(sql.CacheKey(entry.key), entry.key)
 	}
 
-	return secondaryRow, nil
+	if i.cache == nil {
+		i.cache, i.disposeCache = i.ctx.Memory.NewLRUCache(indexedJoinCacheSize)
+	}
+	return i.cache.Put(sql.CacheKey(entry.key), entry)
+}
+
+// rangeLookup returns the lookup of the key range of the join for the primary row, with the ascending and descending
+// lookups of the index. Ranges the index has no lookup for, with both bounds inclusive or exclusive, are looked up
+// with a larger range unless the lookups can be merged, and the join condition leaves out the rows outside the range.
+func (i *indexedJoinIter) rangeLookup() (sql.IndexLookup, bool, error) {
+	var lower, upper interface{}
+	var err error
+	if i.keyRange.Lower != nil {
+		if lower, err = i.keyRange.Lower.Eval(i.ctx, i.primaryRow); err != nil || lower == nil {
+			return nil, false, err
+		}
+	}
+	if i.keyRange.Upper != nil {
+		if upper, err = i.keyRange.Upper.Eval(i.ctx, i.primaryRow); err != nil || upper == nil {
+			return nil, false, err
+		}
+	}
+
+	ai := i.index.(sql.AscendIndex)
+	di := i.index.(sql.DescendIndex)
+	var lookup sql.IndexLookup
+	switch r := i.keyRange; {
+	case r.Upper == nil && r.LowerInclusive:
+		lookup, err = ai.AscendGreaterOrEqual(lower)
+	case r.Upper == nil:
+		lookup, err = di.DescendGreater(lower)
+	case r.Lower == nil && r.UpperInclusive:
+		lookup, err = di.DescendLessOrEqual(upper)
+	case r.Lower == nil:
+		lookup, err = ai.AscendLessThan(upper)
+	case !r.UpperInclusive:
+		lookup, err = ai.AscendRange([]interface{}{lower}, []interface{}{upper})
+	case !r.LowerInclusive:
+		lookup, err = di.DescendRange([]interface{}{upper}, []interface{}{lower})
+	default:
+		// [lower, inf) and (-inf, upper] together, if they can be merged, or else [lower, inf)
+		lookup, err = ai.AscendGreaterOrEqual(lower)
+		if err != nil {
+			return nil, false, err
+		}
+		descend, err := di.DescendLessOrEqual(upper)
+		if err != nil {
+			return nil, false, err
+		}
+		if m, ok := lookup.(sql.MergeableIndexLookup); ok && m.IsMergeable(descend) {
+			lookup, err = m.Intersection(descend)
+		}
+	}
+	return lookup, err == nil, err
 }
 
 func (i *indexedJoinIter) Next() (sql.Row, error) {
 	for {
+		if err := i.ctx.Interrupted(); err != nil {
+			return nil, err
+		}
+
 		if err := i.loadPrimary(); err != nil {
 			return nil, err
 		}
@@ -224,19 +630,34 @@ func (i *indexedJoinIter) Next() (sql.Row, error) {
 				if !i.foundMatch && (i.joinType == JoinTypeLeft || i.joinType == JoinTypeRight) {
 					return i.buildRow(primary, nil), nil
 				}
+				if i.joinType == JoinTypeAnti {
+					return primary, nil
+				}
 				continue
 			}
 			return nil, err
 		}
 
 		row := i.buildRow(primary, secondary)
-		matches, err := conditionIsTrue(i.ctx, row, i.cond)
-		if err != nil {
-			return nil, err
+		if i.cond != nil {
+			matches, err := conditionIsTrue(i.ctx, row, i.cond)
+			if err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
 		}
 
-		if !matches {
-			continue
+		if i.joinType.filtersPrimary() {
+			if err := i.skipSecondary(); err != nil {
+				return nil, err
+			}
+			if i.joinType == JoinTypeAnti {
+				continue
+			}
+			return primary, nil
 		}
 
 		i.foundMatch = true
@@ -244,6 +665,20 @@ func (i *indexedJoinIter) Next() (sql.Row, error) {
 	}
 }
 
+// skipSecondary moves on to the next primary row without going through the rest of the rows looked up for it.
+func (i *indexedJoinIter) skipSecondary() error {
+	i.primaryRow = nil
+	i.secondaryRows = nil
+	// The rows looked up for the key aren't all read, so they aren't cached
+	i.caching = nil
+	if i.secondary != nil {
+		err := i.secondary.Close()
+		i.secondary = nil
+		return err
+	}
+	return nil
+}
+
 func conditionIsTrue(ctx *sql.Context, row sql.Row, cond sql.Expression) (bool, error) {
 	v, err := cond.Eval(ctx, row)
 	if err != nil {
@@ -256,15 +691,21 @@ func conditionIsTrue(ctx *sql.Context, row sql.Row, cond sql.Expression) (bool,
 
 // buildRow builds the result set row using the rows from the primary and secondary tables
 func (i *indexedJoinIter) buildRow(primary, secondary sql.Row) sql.Row {
-	row := make(sql.Row, i.rowSize)
-
-	copy(row, primary)
-	copy(row[len(primary):], secondary)
-
-	return row
+	return buildJoinRow(primary, secondary, i.secondaryLen)
 }
 
 func (i *indexedJoinIter) Close() (err error) {
+	if i.disposeCache != nil {
+		i.disposeCache()
+		i.disposeCache = nil
+		i.cache = nil
+	}
+	if i.disposeMissing != nil {
+		i.disposeMissing()
+		i.disposeMissing = nil
+		i.missing = nil
+	}
+
 	if i.primary != nil {
 		if err = i.primary.Close(); err != nil {
 			if i.secondary != nil {
@@ -0,0 +1,260 @@
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// countingIndex is an index counting the keys looked up in it.
+type countingIndex struct {
+	sql.Index
+	gets int
+}
+
+func (i *countingIndex) Get(key ...interface{}) (sql.IndexLookup, error) {
+	i.gets++
+	return i.Index.Get(key...)
+}
+
+// nonDeterministicTrue is a TRUE literal that pretends to be non-deterministic.
+type nonDeterministicTrue struct {
+	*expression.Literal
+}
+
+func (nonDeterministicTrue) IsNonDeterministic() bool {
+	return true
+}
+
+func joinRows(ctx *sql.Context, n sql.Node) ([]sql.Row, error) {
+	iter, err := n.RowIter(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowIterToRows(iter)
+}
+
+func TestIndexedJoinCache(t *testing.T) {
+	primary := memory.NewTable("orders", sql.Schema{
+		{Name: "customer", Type: sql.Int64, Source: "orders", Nullable: true},
+	})
+	secondary := memory.NewTable("customers", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "customers", PrimaryKey: true},
+		{Name: "name", Type: sql.Text, Source: "customers"},
+	})
+	secondary.EnablePrimaryKeyIndexes()
+
+	ctx := sql.NewEmptyContext()
+	for _, customer := range []interface{}{int64(1), int64(2), int64(1), int64(1), int64(3), int64(2), nil, int64(3)} {
+		require.NoError(t, primary.Insert(ctx, sql.NewRow(customer)))
+	}
+	require.NoError(t, secondary.Insert(ctx, sql.NewRow(int64(1), "one")))
+	require.NoError(t, secondary.Insert(ctx, sql.NewRow(int64(2), "two")))
+
+	indexes, err := secondary.GetIndexes(ctx)
+	require.NoError(t, err)
+
+	customer := expression.NewGetFieldWithTable(0, sql.Int64, "orders", "customer", true)
+	cond := expression.NewEquals(customer, expression.NewGetFieldWithTable(1, sql.Int64, "customers", "id", false))
+	join := func(index sql.Index, joinType JoinType, right sql.Node) sql.Node {
+		return NewIndexedJoin(NewResolvedTable(primary), right, joinType, cond, []sql.Expression{customer}, index)
+	}
+	indexed := func() sql.Node {
+		return NewIndexedTable(NewResolvedTable(secondary))
+	}
+
+	joined := []sql.Row{
+		{int64(1), int64(1), "one"},
+		{int64(2), int64(2), "two"},
+		{int64(1), int64(1), "one"},
+		{int64(1), int64(1), "one"},
+		{int64(3), nil, nil},
+		{int64(2), int64(2), "two"},
+		{nil, nil, nil},
+		{int64(3), nil, nil},
+	}
+
+	t.Run("repeated keys are looked up once", func(t *testing.T) {
+		require := require.New(t)
+		index := &countingIndex{Index: indexes[0]}
+		rows, err := joinRows(ctx, join(index, JoinTypeLeft, indexed()))
+		require.NoError(err)
+		require.Equal(joined, rows)
+		// The NULL key isn't looked up at all
+		require.Equal(3, index.gets)
+	})
+
+	t.Run("keys with rows left unread aren't cached", func(t *testing.T) {
+		require := require.New(t)
+		index := &countingIndex{Index: indexes[0]}
+		rows, err := joinRows(ctx, join(index, JoinTypeAnti, indexed()))
+		require.NoError(err)
+		require.Equal([]sql.Row{{int64(3)}, {nil}, {int64(3)}}, rows)
+		require.Equal(6, index.gets)
+	})
+
+	t.Run("non-deterministic secondary nodes aren't cached", func(t *testing.T) {
+		require := require.New(t)
+		index := &countingIndex{Index: indexes[0]}
+		filtered := NewFilter(nonDeterministicTrue{expression.NewLiteral(true, sql.Boolean)}, indexed())
+		rows, err := joinRows(ctx, join(index, JoinTypeLeft, filtered))
+		require.NoError(err)
+		require.Equal(joined, rows)
+		require.Equal(7, index.gets)
+	})
+
+	t.Run("keys without rows don't push keys with rows out of the cache", func(t *testing.T) {
+		require := require.New(t)
+		orders := memory.NewTable("orders", primary.Schema())
+		var expected []sql.Row
+		for round := 0; round < 2; round++ {
+			require.NoError(orders.Insert(ctx, sql.NewRow(int64(1))))
+			expected = append(expected, sql.Row{int64(1), int64(1), "one"})
+			for i := 0; i < 2*indexedJoinCacheSize; i++ {
+				require.NoError(orders.Insert(ctx, sql.NewRow(int64(100+i))))
+				expected = append(expected, sql.Row{int64(100 + i), nil, nil})
+			}
+		}
+
+		index := &countingIndex{Index: indexes[0]}
+		join := NewIndexedJoin(NewResolvedTable(orders), indexed(), JoinTypeLeft, cond, []sql.Expression{customer}, index)
+		rows, err := joinRows(ctx, join)
+		require.NoError(err)
+		require.Equal(expected, rows)
+		require.Equal(1+2*indexedJoinCacheSize, index.gets)
+	})
+
+	t.Run("nothing is cached without memory", func(t *testing.T) {
+		require := require.New(t)
+		ctx := sql.NewContext(context.TODO(), sql.WithMemoryManager(
+			sql.NewMemoryManager(mockReporter{2, 1}),
+		))
+		index := &countingIndex{Index: indexes[0]}
+		rows, err := joinRows(ctx, join(index, JoinTypeLeft, indexed()))
+		require.NoError(err)
+		require.Equal(joined, rows)
+		require.Equal(7, index.gets)
+	})
+}
+
+func TestIndexedJoinResidual(t *testing.T) {
+	primary := memory.NewTable("orders", sql.Schema{
+		{Name: "customer", Type: sql.Int64, Source: "orders", Nullable: true},
+	})
+	secondary := memory.NewTable("customers", sql.Schema{
+		{Name: "id", Type: sql.Int64, Source: "customers", PrimaryKey: true},
+		{Name: "name", Type: sql.Text, Source: "customers"},
+	})
+	secondary.EnablePrimaryKeyIndexes()
+
+	ctx := sql.NewEmptyContext()
+	for _, customer := range []interface{}{int64(1), int64(2), nil, int64(3)} {
+		require.NoError(t, primary.Insert(ctx, sql.NewRow(customer)))
+	}
+	require.NoError(t, secondary.Insert(ctx, sql.NewRow(int64(1), "one")))
+	require.NoError(t, secondary.Insert(ctx, sql.NewRow(int64(2), "two")))
+
+	indexes, err := secondary.GetIndexes(ctx)
+	require.NoError(t, err)
+
+	customer := expression.NewGetFieldWithTable(0, sql.Int64, "orders", "customer", true)
+	key := expression.NewEquals(customer, expression.NewGetFieldWithTable(1, sql.Int64, "customers", "id", false))
+	residual := expression.NewNot(expression.NewEquals(
+		expression.NewGetFieldWithTable(2, sql.Text, "customers", "name", false),
+		expression.NewLiteral("two", sql.LongText),
+	))
+	join := func(joinType JoinType) *IndexedJoin {
+		return NewIndexedJoin(
+			NewResolvedTable(primary),
+			NewIndexedTable(NewResolvedTable(secondary)),
+			joinType,
+			expression.NewAnd(key, residual),
+			[]sql.Expression{customer},
+			indexes[0],
+		)
+	}
+
+	testCases := []struct {
+		name     string
+		join     sql.Node
+		expected []sql.Row
+	}{
+		{
+			name: "whole condition",
+			join: join(JoinTypeLeft),
+			expected: []sql.Row{
+				{int64(1), int64(1), "one"},
+				{int64(2), nil, nil},
+				{nil, nil, nil},
+				{int64(3), nil, nil},
+			},
+		},
+		{
+			name: "residual",
+			join: join(JoinTypeLeft).WithResidual(residual),
+			expected: []sql.Row{
+				{int64(1), int64(1), "one"},
+				{int64(2), nil, nil},
+				{nil, nil, nil},
+				{int64(3), nil, nil},
+			},
+		},
+		{
+			name: "no residual",
+			join: join(JoinTypeLeft).WithResidual(nil),
+			expected: []sql.Row{
+				{int64(1), int64(1), "one"},
+				{int64(2), int64(2), "two"},
+				{nil, nil, nil},
+				{int64(3), nil, nil},
+			},
+		},
+		{
+			name:     "no residual, anti join",
+			join:     join(JoinTypeAnti).WithResidual(nil),
+			expected: []sql.Row{{nil}, {int64(3)}},
+		},
+		{
+			// An equality that isn't part of the residual isn't evaluated on the rows looked up
+			name: "residual without the key",
+			join: NewIndexedJoin(
+				NewResolvedTable(primary),
+				NewIndexedTable(NewResolvedTable(secondary)),
+				JoinTypeInner,
+				expression.NewLiteral(false, sql.Boolean),
+				[]sql.Expression{customer},
+				indexes[0],
+			).WithResidual(residual),
+			expected: []sql.Row{{int64(1), int64(1), "one"}},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			rows, err := joinRows(ctx, tt.join)
+			require.NoError(err)
+			require.Equal(tt.expected, rows)
+		})
+	}
+
+	t.Run("expressions", func(t *testing.T) {
+		require := require.New(t)
+		j := join(JoinTypeLeft).WithResidual(residual)
+		require.Equal([]sql.Expression{expression.NewAnd(key, residual), residual, customer}, j.Expressions())
+
+		n, err := j.WithExpressions(key, key, customer)
+		require.NoError(err)
+		require.Equal(key, n.(*IndexedJoin).Residual())
+
+		j = join(JoinTypeLeft).WithResidual(nil)
+		require.Equal([]sql.Expression{expression.NewAnd(key, residual), customer}, j.Expressions())
+		_, err = j.WithExpressions(key, key, customer)
+		require.Error(err)
+	})
+}
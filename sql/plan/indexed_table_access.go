@@ -44,6 +44,12 @@ func (i *IndexedTableAccess) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter
 	return sql.NewTableRowIter(ctx, i.indexedTable, partIter), nil
 }
 
+// CanRowIter2 implements the sql.Node2 interface. The rows of an IndexedTableAccess depend on the lookup it was last
+// initialized with, so it only produces Rows.
+func (i *IndexedTableAccess) CanRowIter2() bool {
+	return false
+}
+
 func (i *IndexedTableAccess) DebugString() string {
 	return fmt.Sprintf("IndexedTableAccess(%s)", i.Name())
 }
@@ -29,6 +29,10 @@ type InsertInto struct {
 	ColumnNames []string
 	IsReplace   bool
 	OnDupExprs  []sql.Expression
+	// ForeignKeys are the foreign keys enforced on the rows inserted, if any.
+	ForeignKeys *ForeignKeys
+	// Checks are the check constraints enforced on the rows inserted.
+	Checks CheckConstraints
 }
 
 // NewInsertInto creates an InsertInto node.
@@ -60,7 +64,9 @@ type insertIter struct {
 	ctx         *sql.Context
 	updateExprs []sql.Expression
 	tableNode   sql.Node
-	closed      bool
+	// rowNumber is the number of the rows given to the iterator, from 1, which messages report
+	rowNumber int
+	closed    bool
 }
 
 func GetInsertable(node sql.Node) (sql.InsertableTable, error) {
@@ -95,6 +101,8 @@ func newInsertIter(
 	values sql.Node,
 	isReplace bool,
 	onDupUpdateExpr []sql.Expression,
+	fks *ForeignKeys,
+	checks CheckConstraints,
 	row sql.Row,
 ) (*insertIter, error) {
 	dstSchema := table.Schema()
@@ -110,11 +118,11 @@ func newInsertIter(
 	var updater sql.RowUpdater
 	// These type casts have already been asserted in the analyzer
 	if isReplace {
-		replacer = insertable.(sql.ReplaceableTable).Replacer(ctx)
+		replacer = checks.replacer(fks.replacer(ctx, insertable, insertable.(sql.ReplaceableTable).Replacer(ctx)))
 	} else {
-		inserter = insertable.Inserter(ctx)
+		inserter = checks.inserter(fks.inserter(ctx, insertable, insertable.Inserter(ctx)))
 		if len(onDupUpdateExpr) > 0 {
-			updater = insertable.(sql.UpdatableTable).Updater(ctx)
+			updater = checks.updater(fks.updater(ctx, insertable, insertable.(sql.UpdatableTable).Updater(ctx)))
 		}
 	}
 
@@ -135,7 +143,7 @@ func newInsertIter(
 	}, nil
 }
 
-func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
+func (i *insertIter) Next() (returnRow sql.Row, returnErr error) {
 	row, err := i.rowSource.Next()
 	if err == io.EOF {
 		return nil, err
@@ -159,10 +167,12 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 	}
 
 	// Do any necessary type conversions to the target schema
-	for i, col := range i.schema {
-		if row[i] != nil {
-			row[i], err = col.Type.Convert(row[i])
+	i.rowNumber++
+	for idx, col := range i.schema {
+		if row[idx] != nil {
+			row[idx], err = sql.ConvertToColumn(i.ctx, col.Type, col.Name, row[idx], i.rowNumber)
 			if err != nil {
+				_ = i.rowSource.Close()
 				return nil, err
 			}
 		}
@@ -228,10 +238,13 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 				return nil, err
 			}
 
-			newRow, err := applyUpdateExpressions(i.ctx, i.updateExprs, rowToUpdate)
+			// The update expressions are evaluated on the existing row followed by the row inserted, which the VALUES
+			// function reads
+			newRow, err := applyUpdateExpressions(i.ctx, i.updateExprs, rowToUpdate.Append(row))
 			if err != nil {
 				return nil, err
 			}
+			newRow = newRow[:len(rowToUpdate)]
 
 			err = i.updater.Update(i.ctx, rowToUpdate, newRow)
 			if err != nil {
@@ -246,7 +259,7 @@ func (i insertIter) Next() (returnRow sql.Row, returnErr error) {
 	return row, nil
 }
 
-func (i insertIter) Close() error {
+func (i *insertIter) Close() error {
 	if !i.closed {
 		i.closed = true
 		if i.inserter != nil {
@@ -275,7 +288,7 @@ func (i insertIter) Close() error {
 
 // RowIter implements the Node interface.
 func (p *InsertInto) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
-	return newInsertIter(ctx, p.Left, p.Right, p.IsReplace, p.OnDupExprs, row)
+	return newInsertIter(ctx, p.Left, p.Right, p.IsReplace, p.OnDupExprs, p.ForeignKeys, p.Checks, row)
 }
 
 // WithChildren implements the Node interface.
@@ -330,7 +343,9 @@ func (p *InsertInto) WithExpressions(newExprs ...sql.Expression) (sql.Node, erro
 		return nil, sql.ErrInvalidChildrenNumber.New(p, len(p.OnDupExprs), 1)
 	}
 
-	return NewInsertInto(p.Left, p.Right, p.IsReplace, p.ColumnNames, newExprs), nil
+	np := *p
+	np.OnDupExprs = newExprs
+	return &np, nil
 }
 
 // Resolved implements the Resolvable interface.
@@ -69,6 +69,18 @@ func (in *InSubquery) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 		}
 
 		typ := right.Type()
+
+		// The results of subqueries that can be cached are looked up in a set of them
+		set, err := right.valueSet(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+		if set != nil {
+			if result, ok := set.contains(typ, left); ok {
+				return result, nil
+			}
+		}
+
 		values, err := right.EvalMultiple(ctx, row)
 		if err != nil {
 			return nil, err
@@ -15,7 +15,9 @@
 package plan_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"gopkg.in/src-d/go-errors.v1"
@@ -114,6 +116,130 @@ func TestInSubquery(t *testing.T) {
 	}
 }
 
+func TestInSubqueryCachedResults(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	table := memory.NewTable("foo", sql.Schema{
+		{Name: "i", Source: "foo", Type: sql.Int64, Nullable: true},
+	})
+
+	require.NoError(t, table.Insert(ctx, sql.Row{int64(1)}))
+	require.NoError(t, table.Insert(ctx, sql.Row{int64(2)}))
+
+	in := func(ctx *sql.Context, left interface{}) interface{} {
+		result, err := plan.NewInSubquery(
+			expression.NewLiteral(left, sql.Int64),
+			plan.NewSubquery(plan.NewResolvedTable(table), "").WithCachedResults(),
+		).Eval(ctx, nil)
+		require.NoError(t, err)
+		return result
+	}
+
+	empty := plan.NewInSubquery(
+		expression.NewLiteral(nil, sql.Int64),
+		plan.NewSubquery(plan.NewFilter(expression.NewLiteral(false, sql.Boolean), plan.NewResolvedTable(table)), "").WithCachedResults(),
+	)
+	result, err := empty.Eval(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, false, result)
+
+	require.Equal(t, true, in(ctx, int64(2)))
+	require.Equal(t, false, in(ctx, int64(3)))
+	require.Equal(t, nil, in(ctx, nil))
+
+	require.NoError(t, table.Insert(ctx, sql.Row{nil}))
+	require.Equal(t, nil, in(ctx, int64(3)))
+	require.Equal(t, true, in(ctx, int64(1)))
+
+	// The results are cached after the first evaluation, as long as there's memory available for them
+	for _, tt := range []struct {
+		name     string
+		reporter sql.Reporter
+		result   interface{}
+	}{
+		{"memory available", fixedReporter{0, 10}, nil},
+		{"no memory available", fixedReporter{10, 1}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := sql.NewContext(context.Background(), sql.WithMemoryManager(sql.NewMemoryManager(tt.reporter)))
+			table := memory.NewTable("foo", sql.Schema{
+				{Name: "i", Source: "foo", Type: sql.Int64, Nullable: true},
+			})
+			require.NoError(table.Insert(ctx, sql.Row{nil}))
+
+			in := plan.NewInSubquery(
+				expression.NewGetField(0, sql.Int64, "i", true),
+				plan.NewSubquery(plan.NewResolvedTable(table), "").WithCachedResults(),
+			)
+			result, err := in.Eval(ctx, sql.Row{int64(1)})
+			require.NoError(err)
+			require.Equal(nil, result)
+
+			require.NoError(table.Insert(ctx, sql.Row{int64(1)}))
+			result, err = in.Eval(ctx, sql.Row{int64(1)})
+			require.NoError(err)
+			require.Equal(tt.result, result)
+		})
+	}
+}
+
+func TestInSubqueryCachedResultsTypes(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	testCases := []struct {
+		typ    sql.Type
+		values []interface{}
+		left   []interface{}
+	}{
+		{sql.Int32, []interface{}{int32(1), int32(-2)}, []interface{}{int64(1), "-2", 1.0, int8(3)}},
+		{sql.Uint24, []interface{}{uint32(1), uint32(7)}, []interface{}{int64(7), uint8(1), "2"}},
+		{sql.Float64, []interface{}{1.5, 0.0}, []interface{}{float32(1.5), -0.0, int64(1), "1.5"}},
+		{sql.MustCreateDecimalType(10, 2), []interface{}{"1.10", "2"}, []interface{}{"1.1", 2, "2.00", 1.11}},
+		{sql.Datetime, []interface{}{"2020-01-01 10:00:00"}, []interface{}{"2020-01-01 10:00:00", time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), "2020-01-01"}},
+		{sql.Date, []interface{}{"2020-01-01"}, []interface{}{"2020-01-01 10:00:00", time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC), "2020-01-02"}},
+		{sql.Time, []interface{}{"10:00:00", "-01:30:00"}, []interface{}{"10:00", "-01:30:00", "01:30:00"}},
+		{sql.Year, []interface{}{int16(2020)}, []interface{}{"2020", int64(20), 2021}},
+		{sql.MustCreateBitType(8), []interface{}{uint64(3)}, []interface{}{int64(3), "4"}},
+		{sql.MustCreateEnumType([]string{"a", "b"}, sql.Collation_Default), []interface{}{"b"}, []interface{}{"b", 2, "a"}},
+		{sql.MustCreateSetType([]string{"a", "b"}, sql.Collation_Default), []interface{}{"a,b"}, []interface{}{"b,a", uint64(3), "a"}},
+		{sql.LongText, []interface{}{"one", "two"}, []interface{}{"two", "TWO", 1}},
+		{sql.JSON, []interface{}{`{"a": 1}`}, []interface{}{`{"a": 1}`, `{"a": 2}`}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.typ.String(), func(t *testing.T) {
+			require := require.New(t)
+			table := memory.NewTable("foo", sql.Schema{
+				{Name: "v", Source: "foo", Type: tt.typ, Nullable: true},
+			})
+			for _, v := range tt.values {
+				require.NoError(table.Insert(ctx, sql.Row{v}))
+			}
+
+			for _, left := range tt.left {
+				expected, err := plan.NewInSubquery(
+					expression.NewLiteral(left, tt.typ),
+					plan.NewSubquery(plan.NewResolvedTable(table), ""),
+				).Eval(ctx, nil)
+				require.NoError(err)
+
+				result, err := plan.NewInSubquery(
+					expression.NewLiteral(left, tt.typ),
+					plan.NewSubquery(plan.NewResolvedTable(table), "").WithCachedResults(),
+				).Eval(ctx, nil)
+				require.NoError(err)
+				require.Equal(expected, result, "%v", left)
+			}
+		})
+	}
+}
+
+type fixedReporter struct {
+	used, max uint64
+}
+
+func (r fixedReporter) UsedMemory() uint64 { return r.used }
+func (r fixedReporter) MaxMemory() uint64  { return r.max }
+
 func TestNotInSubquery(t *testing.T) {
 	ctx := sql.NewEmptyContext()
 	table := memory.NewTable("foo", sql.Schema{
@@ -209,12 +209,140 @@ func (j *RightJoin) Expressions() []sql.Expression {
 	return []sql.Expression{j.Cond}
 }
 
+// SemiJoin is a semi join between two nodes, which returns the rows of the left node that have a row of the right node
+// matching the join condition, once no matter how many rows match. It has the schema of the left node.
+type SemiJoin struct {
+	BinaryNode
+	Cond sql.Expression
+}
+
+// NewSemiJoin creates a new semi join node from two nodes.
+func NewSemiJoin(left, right sql.Node, cond sql.Expression) *SemiJoin {
+	return &SemiJoin{
+		BinaryNode: BinaryNode{
+			Left:  left,
+			Right: right,
+		},
+		Cond: cond,
+	}
+}
+
+// Schema implements the Node interface.
+func (j *SemiJoin) Schema() sql.Schema {
+	return j.Left.Schema()
+}
+
+// Resolved implements the Resolvable interface.
+func (j *SemiJoin) Resolved() bool {
+	return j.Left.Resolved() && j.Right.Resolved() && j.Cond.Resolved()
+}
+
+// RowIter implements the Node interface.
+func (j *SemiJoin) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return joinRowIter(ctx, JoinTypeSemi, j.Left, j.Right, j.Cond)
+}
+
+// WithChildren implements the Node interface.
+func (j *SemiJoin) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 2 {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(children), 2)
+	}
+
+	return NewSemiJoin(children[0], children[1], j.Cond), nil
+}
+
+// WithExpressions implements the Expressioner interface.
+func (j *SemiJoin) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(exprs), 1)
+	}
+
+	return NewSemiJoin(j.Left, j.Right, exprs[0]), nil
+}
+
+func (j *SemiJoin) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("SemiJoin(%s)", j.Cond)
+	_ = pr.WriteChildren(j.Left.String(), j.Right.String())
+	return pr.String()
+}
+
+// Expressions implements the Expressioner interface.
+func (j *SemiJoin) Expressions() []sql.Expression {
+	return []sql.Expression{j.Cond}
+}
+
+// AntiJoin is an anti join between two nodes, which returns the rows of the left node that have no row of the right
+// node matching the join condition. It has the schema of the left node.
+type AntiJoin struct {
+	BinaryNode
+	Cond sql.Expression
+}
+
+// NewAntiJoin creates a new anti join node from two nodes.
+func NewAntiJoin(left, right sql.Node, cond sql.Expression) *AntiJoin {
+	return &AntiJoin{
+		BinaryNode: BinaryNode{
+			Left:  left,
+			Right: right,
+		},
+		Cond: cond,
+	}
+}
+
+// Schema implements the Node interface.
+func (j *AntiJoin) Schema() sql.Schema {
+	return j.Left.Schema()
+}
+
+// Resolved implements the Resolvable interface.
+func (j *AntiJoin) Resolved() bool {
+	return j.Left.Resolved() && j.Right.Resolved() && j.Cond.Resolved()
+}
+
+// RowIter implements the Node interface.
+func (j *AntiJoin) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return joinRowIter(ctx, JoinTypeAnti, j.Left, j.Right, j.Cond)
+}
+
+// WithChildren implements the Node interface.
+func (j *AntiJoin) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 2 {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(children), 2)
+	}
+
+	return NewAntiJoin(children[0], children[1], j.Cond), nil
+}
+
+// WithExpressions implements the Expressioner interface.
+func (j *AntiJoin) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(j, len(exprs), 1)
+	}
+
+	return NewAntiJoin(j.Left, j.Right, exprs[0]), nil
+}
+
+func (j *AntiJoin) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("AntiJoin(%s)", j.Cond)
+	_ = pr.WriteChildren(j.Left.String(), j.Right.String())
+	return pr.String()
+}
+
+// Expressions implements the Expressioner interface.
+func (j *AntiJoin) Expressions() []sql.Expression {
+	return []sql.Expression{j.Cond}
+}
+
 type JoinType byte
 
 const (
 	JoinTypeInner JoinType = iota
 	JoinTypeLeft
 	JoinTypeRight
+	JoinTypeSemi
+	JoinTypeAnti
 )
 
 func (t JoinType) String() string {
@@ -225,11 +353,21 @@ func (t JoinType) String() string {
 		return "LeftJoin"
 	case JoinTypeRight:
 		return "RightJoin"
+	case JoinTypeSemi:
+		return "SemiJoin"
+	case JoinTypeAnti:
+		return "AntiJoin"
 	default:
 		return "INVALID"
 	}
 }
 
+// filtersPrimary returns whether joins of this type return the rows of their primary side that have (semi joins) or
+// don't have (anti joins) a matching row of their secondary side, instead of the rows of both sides joined.
+func (t JoinType) filtersPrimary() bool {
+	return t == JoinTypeSemi || t == JoinTypeAnti
+}
+
 func joinRowIter(
 	ctx *sql.Context,
 	typ JoinType,
@@ -281,6 +419,7 @@ func joinRowIter(
 			mode:              mode,
 			secondaryRows:     cache,
 			rowSize:           len(left.Schema()) + len(right.Schema()),
+			secondaryLen:      len(left.Schema()),
 			dispose:           dispose,
 		}), nil
 	}
@@ -300,6 +439,7 @@ func joinRowIter(
 		mode:              mode,
 		secondaryRows:     cache,
 		rowSize:           len(left.Schema()) + len(right.Schema()),
+		secondaryLen:      len(right.Schema()),
 		dispose:           dispose,
 	}), nil
 }
@@ -334,7 +474,10 @@ type joinIter struct {
 
 	primaryRow sql.Row
 	foundMatch bool
-	rowSize    int
+	// rowSize is the length of the rows of both sides, and secondaryLen the length of the rows of the secondary side,
+	// without the row of the outer scope they start with in subqueries.
+	rowSize      int
+	secondaryLen int
 
 	// used to compute in-memory
 	mode          joinMode
@@ -398,6 +541,9 @@ func (i *joinIter) loadSecondary() (row sql.Row, err error) {
 	if i.mode == memoryMode {
 		if len(i.secondaryRows.Get()) == 0 {
 			if err = i.loadSecondaryInMemory(); err != nil {
+				if err == io.EOF {
+					i.primaryRow = nil
+				}
 				return nil, err
 			}
 		}
@@ -462,8 +608,37 @@ func (i *joinIter) loadSecondary() (row sql.Row, err error) {
 	return rightRow, nil
 }
 
+// skipSecondary moves on to the next primary row without going through the rest of the rows of the secondary side.
+// Until the join knows whether they fit in memory, they're still all read, so they're kept in memory for the other
+// primary rows.
+func (i *joinIter) skipSecondary() error {
+	for i.mode == unknownMode {
+		if _, err := i.loadSecondary(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+	}
+
+	i.pos = 0
+	i.primaryRow = nil
+	if i.secondary != nil {
+		err := i.secondary.Close()
+		i.secondary = nil
+		return err
+	}
+	return nil
+}
+
 func (i *joinIter) Next() (sql.Row, error) {
 	for {
+		// Joins in memory go through the rows of the secondary side without reading them from its iterator, so the
+		// query may be interrupted between any two of them
+		if err := i.ctx.Interrupted(); err != nil {
+			return nil, err
+		}
+
 		if err := i.loadPrimary(); err != nil {
 			return nil, err
 		}
@@ -475,6 +650,9 @@ func (i *joinIter) Next() (sql.Row, error) {
 				if !i.foundMatch && (i.typ == JoinTypeLeft || i.typ == JoinTypeRight) {
 					return i.buildRow(primary, nil), nil
 				}
+				if i.typ == JoinTypeAnti {
+					return primary, nil
+				}
 				continue
 			}
 			return nil, err
@@ -490,25 +668,56 @@ func (i *joinIter) Next() (sql.Row, error) {
 			continue
 		}
 
+		if i.typ.filtersPrimary() {
+			if err := i.skipSecondary(); err != nil {
+				return nil, err
+			}
+			if i.typ == JoinTypeAnti {
+				continue
+			}
+			return primary, nil
+		}
+
 		i.foundMatch = true
 		return row, nil
 	}
 }
 
 // buildRow builds the resulting row using the rows from the primary and
-// secondary branches depending on the join type.
+// secondary branches depending on the join type. In subqueries, the row of the
+// outer scope the rows of both branches start with starts the resulting row.
 func (i *joinIter) buildRow(primary, secondary sql.Row) sql.Row {
-	row := make(sql.Row, i.rowSize)
-
 	switch i.typ {
 	case JoinTypeRight:
-		copy(row, secondary)
-		copy(row[i.rowSize-len(primary):], primary)
+		primaryLen := i.rowSize - i.secondaryLen
+		scopeLen := len(primary) - len(withoutScope(primary, primaryLen))
+		row := make(sql.Row, scopeLen+i.rowSize)
+		copy(row, primary[:scopeLen])
+		copy(row[scopeLen:], withoutScope(secondary, i.secondaryLen))
+		copy(row[scopeLen+i.secondaryLen:], primary[scopeLen:])
+		return row
 	default:
-		copy(row, primary)
-		copy(row[len(primary):], secondary)
+		return buildJoinRow(primary, secondary, i.secondaryLen)
 	}
+}
 
+// buildJoinRow returns the row of a join of the row given of its primary side and the row given of its secondary side,
+// whose schema has the length given, or nulls for the secondary side if its row is nil. In subqueries, the tables
+// prepend the row of the outer scope to their rows, so the rows of both sides start with it, and the joined row only
+// starts with it once.
+func buildJoinRow(primary, secondary sql.Row, secondaryLen int) sql.Row {
+	row := make(sql.Row, len(primary)+secondaryLen)
+	copy(row, primary)
+	copy(row[len(primary):], withoutScope(secondary, secondaryLen))
+	return row
+}
+
+// withoutScope returns the row given of a side of a join whose schema has the length given without the row of the
+// outer scope it starts with in subqueries.
+func withoutScope(row sql.Row, schemaLen int) sql.Row {
+	if len(row) > schemaLen {
+		return row[len(row)-schemaLen:]
+	}
 	return row
 }
 
@@ -50,6 +50,20 @@ func TestJoinSchema(t *testing.T) {
 			{Name: "b", Source: "bar", Type: sql.Int64},
 		}, result)
 	})
+
+	t.Run("semi", func(t *testing.T) {
+		j := NewSemiJoin(t1, t2, nil)
+		require.Equal(t, sql.Schema{
+			{Name: "a", Source: "foo", Type: sql.Int64},
+		}, j.Schema())
+	})
+
+	t.Run("anti", func(t *testing.T) {
+		j := NewAntiJoin(t1, t2, nil)
+		require.Equal(t, sql.Schema{
+			{Name: "a", Source: "foo", Type: sql.Int64},
+		}, j.Schema())
+	})
 }
 
 func TestInnerJoin(t *testing.T) {
@@ -281,6 +295,65 @@ func TestRightJoin(t *testing.T) {
 	}, rows)
 }
 
+func TestSemiJoin(t *testing.T) {
+	testSemiJoin(t, sql.NewEmptyContext())
+}
+
+func TestInMemorySemiJoin(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+	err := ctx.Set(ctx, inMemoryJoinSessionVar, sql.LongText, "true")
+	require.NoError(t, err)
+	testSemiJoin(t, ctx)
+}
+
+func TestMultiPassSemiJoin(t *testing.T) {
+	ctx := sql.NewContext(context.TODO(), sql.WithMemoryManager(
+		sql.NewMemoryManager(mockReporter{2, 1}),
+	))
+	testSemiJoin(t, ctx)
+}
+
+func testSemiJoin(t *testing.T, ctx *sql.Context) {
+	t.Helper()
+
+	require := require.New(t)
+	ltable := memory.NewTable("left", lSchema)
+	rtable := memory.NewTable("right", rSchema)
+	emptyTable := memory.NewTable("empty", rSchema)
+	insertData(t, ltable)
+	for i := 0; i < 2; i++ {
+		require.NoError(rtable.Insert(ctx, sql.NewRow("col1_1", "col2_1", int32(1), int64(2))))
+	}
+
+	cond := expression.NewEquals(
+		expression.NewGetField(0, sql.Text, "lcol1", false),
+		expression.NewGetField(4, sql.Text, "rcol1", false),
+	)
+	left, right, empty := NewResolvedTable(ltable), NewResolvedTable(rtable), NewResolvedTable(emptyTable)
+	first := sql.Row{"col1_1", "col2_1", int32(1), int64(2)}
+	second := sql.Row{"col1_2", "col2_2", int32(3), int64(4)}
+
+	// Rows with several matches are returned once
+	for _, tt := range []struct {
+		name     string
+		join     sql.Node
+		expected []sql.Row
+	}{
+		{"semi", NewSemiJoin(left, right, cond), []sql.Row{first}},
+		{"anti", NewAntiJoin(left, right, cond), []sql.Row{second}},
+		{"semi with no rows", NewSemiJoin(left, empty, cond), nil},
+		{"anti with no rows", NewAntiJoin(left, empty, cond), []sql.Row{first, second}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			iter, err := tt.join.RowIter(ctx, nil)
+			require.NoError(err)
+			rows, err := sql.RowIterToRows(iter)
+			require.NoError(err)
+			require.ElementsMatch(tt.expected, rows)
+		})
+	}
+}
+
 type mockReporter struct {
 	val uint64
 	max uint64
@@ -12,6 +12,9 @@ import (
 type Limit struct {
 	UnaryNode
 	Limit int64
+	// CalcFoundRows is whether the limit reads all the rows of its child, to record how many rows it'd have returned
+	// without the limit as the found rows of the query, as SQL_CALC_FOUND_ROWS does.
+	CalcFoundRows bool
 }
 
 // NewLimit creates a new Limit node with the given size.
@@ -27,16 +30,32 @@ func (l *Limit) Resolved() bool {
 	return l.UnaryNode.Child.Resolved()
 }
 
+// WithCalcFoundRows returns a copy of the limit that records the rows its child returns as the found rows of the
+// query.
+func (l *Limit) WithCalcFoundRows(calcFoundRows bool) *Limit {
+	nl := *l
+	nl.CalcFoundRows = calcFoundRows
+	return &nl
+}
+
 // RowIter implements the Node interface.
 func (l *Limit) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	span, ctx := ctx.Span("plan.Limit", opentracing.Tag{Key: "limit", Value: l.Limit})
 
-	li, err := l.Child.RowIter(ctx, row)
+	// The rows skipped by an offset are found rows too, so the limit skips them itself to count them
+	child := l.Child
+	var skip int64
+	if offset, ok := child.(*Offset); ok && l.CalcFoundRows {
+		child = offset.Child
+		skip = offset.Offset
+	}
+
+	li, err := child.RowIter(ctx, row)
 	if err != nil {
 		span.Finish()
 		return nil, err
 	}
-	return sql.NewSpanIter(span, &limitIter{l, 0, li}), nil
+	return sql.NewSpanIter(span, &limitIter{l: l, ctx: ctx, skip: skip, childIter: li}), nil
 }
 
 // WithChildren implements the Node interface.
@@ -44,7 +63,7 @@ func (l *Limit) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 1 {
 		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 1)
 	}
-	return NewLimit(l.Limit, children[0]), nil
+	return NewLimit(l.Limit, children[0]).WithCalcFoundRows(l.CalcFoundRows), nil
 }
 
 func (l Limit) String() string {
@@ -56,23 +75,48 @@ func (l Limit) String() string {
 
 func (l Limit) DebugString() string {
 	pr := sql.NewTreePrinter()
-	_ = pr.WriteNode("Limit(%d)", l.Limit)
+	if l.CalcFoundRows {
+		_ = pr.WriteNode("Limit(%d, calc found rows)", l.Limit)
+	} else {
+		_ = pr.WriteNode("Limit(%d)", l.Limit)
+	}
 	_ = pr.WriteChildren(sql.DebugString(l.Child))
 	return pr.String()
 }
 
 type limitIter struct {
 	l          *Limit
+	ctx        *sql.Context
+	skip       int64
 	currentPos int64
+	found      int64
+	done       bool
 	childIter  sql.RowIter
 }
 
 func (li *limitIter) Next() (sql.Row, error) {
-	if li.currentPos >= li.l.Limit {
+	if li.done {
 		return nil, io.EOF
 	}
 
-	childRow, err := li.childIter.Next()
+	for ; li.skip > 0; li.skip-- {
+		if _, err := li.nextChildRow(); err != nil {
+			return nil, err
+		}
+	}
+
+	if li.currentPos >= li.l.Limit {
+		if !li.l.CalcFoundRows {
+			return nil, io.EOF
+		}
+		for {
+			if _, err := li.nextChildRow(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	childRow, err := li.nextChildRow()
 	li.currentPos++
 	if err != nil {
 		return nil, err
@@ -81,6 +125,22 @@ func (li *limitIter) Next() (sql.Row, error) {
 	return childRow, nil
 }
 
+// nextChildRow returns the next row of the child, counting it as a found row, and recording the found rows once the
+// child has no more rows if the limit calculates them.
+func (li *limitIter) nextChildRow() (sql.Row, error) {
+	row, err := li.childIter.Next()
+	if err == io.EOF && li.l.CalcFoundRows {
+		li.done = true
+		li.ctx.SetLastQueryInfo(sql.FoundRows, li.found)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	li.found++
+	return row, nil
+}
+
 func (li *limitIter) Close() error {
 	return li.childIter.Close()
 }
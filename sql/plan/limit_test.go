@@ -65,6 +65,26 @@ func TestLimitGreaterThanTotal(t *testing.T) {
 	testLimitOverflow(t, iterator, testingLimit, size)
 }
 
+func TestLimitCalcFoundRows(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+	table, size := getTestingTable(t)
+
+	for _, offset := range []int64{0, 1, int64(size) + 1} {
+		limit := NewLimit(1, NewOffset(offset, NewResolvedTable(table))).WithCalcFoundRows(true)
+		iter, err := limit.RowIter(ctx, nil)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		if offset < int64(size) {
+			require.Len(rows, 1)
+		} else {
+			require.Len(rows, 0)
+		}
+		require.Equal(int64(size), ctx.GetLastQueryInfo(sql.FoundRows))
+	}
+}
+
 func testLimitOverflow(t *testing.T, iter sql.RowIter, limit int, dataSize int) {
 	require := require.New(t)
 	for i := 0; i < limit+1; i++ {
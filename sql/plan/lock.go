@@ -53,6 +53,31 @@ func (t *LockTables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error)
 	span, ctx := ctx.Span("plan.LockTables")
 	defer span.Finish()
 
+	// Locking tables releases the ones the session locked before
+	if err := t.Catalog.UnlockTables(ctx, ctx.ID()); err != nil {
+		return nil, err
+	}
+
+	var requests []sql.TableLockRequest
+	for _, l := range t.Locks {
+		rt, ok := l.Table.(*ResolvedTable)
+		if !ok {
+			continue
+		}
+
+		r := sql.TableLockRequest{Database: rt.Database, Table: rt.Name(), Mode: sql.ReadTablesLock}
+		if r.Database == "" {
+			r.Database = ctx.GetCurrentDatabase()
+		}
+		if l.Write {
+			r.Mode = sql.WriteTablesLock
+		}
+		requests = append(requests, r)
+	}
+	if err := t.Catalog.AcquireTableLocks(ctx, ctx.ID(), requests, true, sql.LockWaitTimeout(ctx)); err != nil {
+		return nil, err
+	}
+
 	for _, l := range t.Locks {
 		lockable, err := getLockable(l.Table)
 		if err != nil {
@@ -0,0 +1,230 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Loop is a LOOP, WHILE or REPEAT statement of a BEGIN ... END block, which runs its statements until a LEAVE
+// statement leaves it. WHILE loops also stop once their While condition is false before an iteration, and REPEAT loops
+// once their Until condition is true after an iteration.
+type Loop struct {
+	Label string
+	While sql.Expression
+	Until sql.Expression
+
+	statements []sql.Node
+	frame      *BlockFrame
+}
+
+var _ sql.Node = (*Loop)(nil)
+var _ sql.Expressioner = (*Loop)(nil)
+
+// NewLoop creates a new Loop node with the label, conditions and statements given, in the block of the frame given.
+// Either condition may be nil.
+func NewLoop(label string, while, until sql.Expression, statements []sql.Node, frame *BlockFrame) *Loop {
+	return &Loop{Label: label, While: while, Until: until, statements: statements, frame: frame}
+}
+
+// Resolved implements the sql.Node interface.
+func (l *Loop) Resolved() bool {
+	for _, e := range l.Expressions() {
+		if !e.Resolved() {
+			return false
+		}
+	}
+	for _, s := range l.statements {
+		if !s.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Children implements the sql.Node interface.
+func (l *Loop) Children() []sql.Node {
+	return l.statements
+}
+
+// Schema implements the sql.Node interface.
+func (*Loop) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (l *Loop) WithChildren(children ...sql.Node) (sql.Node, error) {
+	nl := *l
+	nl.statements = children
+	return &nl, nil
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (l *Loop) Expressions() []sql.Expression {
+	var exprs []sql.Expression
+	if l.While != nil {
+		exprs = append(exprs, l.While)
+	}
+	if l.Until != nil {
+		exprs = append(exprs, l.Until)
+	}
+	return exprs
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (l *Loop) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(l.Expressions()) {
+		return nil, sql.ErrInvalidChildrenNumber.New(l, len(exprs), len(l.Expressions()))
+	}
+
+	nl := *l
+	if nl.While != nil {
+		nl.While, exprs = exprs[0], exprs[1:]
+	}
+	if nl.Until != nil {
+		nl.Until = exprs[0]
+	}
+	return &nl, nil
+}
+
+func (l *Loop) String() string {
+	var node string
+	switch {
+	case l.While != nil:
+		node = fmt.Sprintf("WHILE %s", l.While)
+	case l.Until != nil:
+		node = fmt.Sprintf("REPEAT UNTIL %s", l.Until)
+	default:
+		node = "LOOP"
+	}
+	if l.Label != "" {
+		node = fmt.Sprintf("%s: %s", l.Label, node)
+	}
+
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode(node)
+	var children []string
+	for _, s := range l.statements {
+		children = append(children, s.String())
+	}
+	_ = pr.WriteChildren(children...)
+	return pr.String()
+}
+
+// RowIter implements the sql.Node interface.
+func (l *Loop) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return newBlockIter(ctx, row, l.run), nil
+}
+
+func (l *Loop) run(ctx *sql.Context, row sql.Row) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if l.While != nil {
+			ok, err := sql.EvaluateCondition(ctx, l.While, row)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+
+		if err := runStatements(ctx, row, l.frame, l.statements); err != nil {
+			if leaves(err, l.Label) {
+				return nil
+			}
+			if c, ok := err.(*blockControl); ok && c.iterate && strings.EqualFold(c.label, l.Label) {
+				continue
+			}
+			return err
+		}
+
+		if l.Until != nil {
+			ok, err := sql.EvaluateCondition(ctx, l.Until, row)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+		}
+	}
+}
+
+// Leave is the LEAVE statement, which ends the loop or block with its label.
+type Leave struct {
+	Label string
+}
+
+var _ sql.Node = (*Leave)(nil)
+
+// NewLeave creates a new Leave node of the loop or block with the label given.
+func NewLeave(label string) *Leave {
+	return &Leave{Label: label}
+}
+
+// Resolved implements the sql.Node interface.
+func (*Leave) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*Leave) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*Leave) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (l *Leave) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(l, len(children), 0)
+	}
+	return l, nil
+}
+
+func (l *Leave) String() string {
+	return fmt.Sprintf("LEAVE %s", l.Label)
+}
+
+// RowIter implements the sql.Node interface.
+func (l *Leave) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return nil, &blockControl{label: l.Label}
+}
+
+// Iterate is the ITERATE statement, which starts the next iteration of the loop with its label.
+type Iterate struct {
+	Label string
+}
+
+var _ sql.Node = (*Iterate)(nil)
+
+// NewIterate creates a new Iterate node of the loop with the label given.
+func NewIterate(label string) *Iterate {
+	return &Iterate{Label: label}
+}
+
+// Resolved implements the sql.Node interface.
+func (*Iterate) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*Iterate) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*Iterate) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (i *Iterate) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(i, len(children), 0)
+	}
+	return i, nil
+}
+
+func (i *Iterate) String() string {
+	return fmt.Sprintf("ITERATE %s", i.Label)
+}
+
+// RowIter implements the sql.Node interface.
+func (i *Iterate) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return nil, &blockControl{label: i.Label, iterate: true}
+}
@@ -0,0 +1,91 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// MaskedTable is a wrapper for sql.Tables whose rows have the values of some columns masked. It doesn't expose the
+// optional interfaces of the table it wraps, such as sql.FilteredTable or sql.IndexedTable, so filters and index
+// lookups are never evaluated by the table on the unmasked values, and it's not a sql.TableWrapper, so its rows are
+// never written back to the table.
+type MaskedTable struct {
+	sql.Table
+	masks map[int]sql.ColumnMask
+}
+
+// NewMaskedTable returns a new MaskedTable with the masks of the columns of the table given, keyed by the lower case
+// names of the columns. Masks of columns that aren't in the schema of the table are ignored.
+func NewMaskedTable(t sql.Table, masks map[string]sql.ColumnMask) *MaskedTable {
+	m := make(map[int]sql.ColumnMask)
+	for i, col := range t.Schema() {
+		if mask, ok := masks[strings.ToLower(col.Name)]; ok {
+			m[i] = mask
+		}
+	}
+	return &MaskedTable{Table: t, masks: m}
+}
+
+// MaskedColumns returns the names of the masked columns of the table.
+func (t *MaskedTable) MaskedColumns() []string {
+	var idxs []int
+	for i := range t.masks {
+		idxs = append(idxs, i)
+	}
+	sort.Ints(idxs)
+
+	schema := t.Table.Schema()
+	names := make([]string, len(idxs))
+	for i, idx := range idxs {
+		names[i] = schema[idx].Name
+	}
+	return names
+}
+
+// DebugString implements the sql.DebugStringer interface.
+func (t *MaskedTable) DebugString() string {
+	return fmt.Sprintf("%s(masked: %s)", sql.DebugString(t.Table), strings.Join(t.MaskedColumns(), ", "))
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *MaskedTable) PartitionRows(ctx *sql.Context, p sql.Partition) (sql.RowIter, error) {
+	iter, err := t.Table.PartitionRows(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &maskedRowIter{ctx: ctx, iter: iter, masks: t.masks}, nil
+}
+
+type maskedRowIter struct {
+	ctx   *sql.Context
+	iter  sql.RowIter
+	masks map[int]sql.ColumnMask
+}
+
+func (i *maskedRowIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	masked := row.Copy()
+	for idx, mask := range i.masks {
+		if idx >= len(masked) {
+			continue
+		}
+
+		masked[idx], err = mask(i.ctx, masked[idx])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return masked, nil
+}
+
+func (i *maskedRowIter) Close() error {
+	return i.iter.Close()
+}
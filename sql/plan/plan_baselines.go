@@ -0,0 +1,160 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// PinPlanBaseline is the PIN PLAN BASELINE FOR statement, which pins the plan the analyzer chooses for a SELECT
+// statement to its digest, so every statement with the digest uses the same plan, whatever the analyzer would choose
+// for it later. The statement isn't a child of the node: the analyzer analyzes it on its own, as if it were run, to
+// capture its plan.
+type PinPlanBaseline struct {
+	// Digest and DigestText are the digest of the statement and its normalized text.
+	Digest     string
+	DigestText string
+	// Statement is the SELECT statement whose plan is pinned, as it's parsed.
+	Statement sql.Node
+	// Baseline is the baseline captured from the plan of the statement, set by the analyzer.
+	Baseline  *sql.PlanBaseline
+	Baselines *sql.PlanBaselineRegistry
+}
+
+var _ sql.Node = (*PinPlanBaseline)(nil)
+
+// NewPinPlanBaseline creates a new PinPlanBaseline node for the statement given, with the digest given.
+func NewPinPlanBaseline(digest, digestText string, statement sql.Node) *PinPlanBaseline {
+	return &PinPlanBaseline{Digest: digest, DigestText: digestText, Statement: statement}
+}
+
+// Resolved implements the sql.Node interface.
+func (p *PinPlanBaseline) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (p *PinPlanBaseline) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (p *PinPlanBaseline) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (p *PinPlanBaseline) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 0)
+	}
+	return p, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (p *PinPlanBaseline) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if p.Baseline == nil {
+		return nil, fmt.Errorf("the plan of %s was not captured", p.DigestText)
+	}
+	p.Baselines.PinPlanBaseline(*p.Baseline)
+	return sql.RowsToRowIter(), nil
+}
+
+func (p *PinPlanBaseline) String() string {
+	return fmt.Sprintf("PIN PLAN BASELINE FOR %s", p.DigestText)
+}
+
+// ShowPlanBaselines is the SHOW PLAN BASELINES statement, which lists the plans pinned.
+type ShowPlanBaselines struct {
+	Baselines *sql.PlanBaselineRegistry
+}
+
+var _ sql.Node = (*ShowPlanBaselines)(nil)
+
+var planBaselinesSchema = sql.Schema{
+	{Name: "Digest", Type: sql.LongText},
+	{Name: "Digest_text", Type: sql.LongText},
+	{Name: "Hints", Type: sql.LongText},
+	{Name: "Plan", Type: sql.LongText},
+	{Name: "Uses", Type: sql.Int64},
+}
+
+// NewShowPlanBaselines creates a new ShowPlanBaselines node.
+func NewShowPlanBaselines() *ShowPlanBaselines {
+	return &ShowPlanBaselines{}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *ShowPlanBaselines) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (s *ShowPlanBaselines) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (s *ShowPlanBaselines) Schema() sql.Schema { return planBaselinesSchema }
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowPlanBaselines) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (s *ShowPlanBaselines) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var rows []sql.Row
+	for _, b := range s.Baselines.PlanBaselines() {
+		rows = append(rows, sql.NewRow(b.Digest, b.DigestText, b.Hints.String(), b.Plan, b.Uses))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (s *ShowPlanBaselines) String() string {
+	return "SHOW PLAN BASELINES"
+}
+
+// PurgePlanBaselines is the PURGE PLAN BASELINE statement, which unpins the plan pinned for a digest, or the PURGE
+// PLAN BASELINES statement, which unpins all of them. The statements with the digests purged use the plans the
+// analyzer chooses again.
+type PurgePlanBaselines struct {
+	// Digest is the digest whose plan is unpinned, or empty to unpin all of them.
+	Digest    string
+	Baselines *sql.PlanBaselineRegistry
+}
+
+var _ sql.Node = (*PurgePlanBaselines)(nil)
+
+// NewPurgePlanBaselines creates a new PurgePlanBaselines node unpinning the plan of the digest given, or all of them
+// if it's empty.
+func NewPurgePlanBaselines(digest string) *PurgePlanBaselines {
+	return &PurgePlanBaselines{Digest: digest}
+}
+
+// Resolved implements the sql.Node interface.
+func (p *PurgePlanBaselines) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (p *PurgePlanBaselines) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (p *PurgePlanBaselines) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (p *PurgePlanBaselines) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 0)
+	}
+	return p, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (p *PurgePlanBaselines) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if p.Digest == "" {
+		p.Baselines.PurgePlanBaselines()
+	} else {
+		p.Baselines.PurgePlanBaseline(p.Digest)
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (p *PurgePlanBaselines) String() string {
+	if p.Digest == "" {
+		return "PURGE PLAN BASELINES"
+	}
+	return fmt.Sprintf("PURGE PLAN BASELINE '%s'", p.Digest)
+}
@@ -0,0 +1,163 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// PointLookup returns the single row of a table matching a complete key of one of its unique indexes. It replaces a
+// Filter directly over a ResolvedTable when the filter fixes every column of the index to a constant, and skips the
+// usual filter and exchange iterators: it performs one index lookup and stops as soon as a matching row is found.
+// The filter is still evaluated on the candidate rows, since an index lookup may return more rows than strictly match.
+type PointLookup struct {
+	Table  *ResolvedTable
+	Index  sql.Index
+	Key    []interface{}
+	Filter sql.Expression
+}
+
+var _ sql.Node = (*PointLookup)(nil)
+var _ sql.Expressioner = (*PointLookup)(nil)
+
+// NewPointLookup returns a new PointLookup of the key given on the index given, returning the row of the table that
+// also matches filter.
+func NewPointLookup(table *ResolvedTable, index sql.Index, key []interface{}, filter sql.Expression) *PointLookup {
+	return &PointLookup{
+		Table:  table,
+		Index:  index,
+		Key:    key,
+		Filter: filter,
+	}
+}
+
+// Resolved implements the sql.Node interface.
+func (p *PointLookup) Resolved() bool {
+	return p.Table.Resolved() && p.Filter.Resolved()
+}
+
+// Schema implements the sql.Node interface.
+func (p *PointLookup) Schema() sql.Schema {
+	return p.Table.Schema()
+}
+
+// Children implements the sql.Node interface. The table is the only child, so the rules and inspections of the tables
+// of a query find it like any other table.
+func (p *PointLookup) Children() []sql.Node {
+	return []sql.Node{p.Table}
+}
+
+// WithChildren implements the sql.Node interface.
+func (p *PointLookup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(children), 1)
+	}
+
+	table, ok := children[0].(*ResolvedTable)
+	if !ok {
+		return nil, sql.ErrInvalidChildType.New(p, children[0], (*ResolvedTable)(nil))
+	}
+
+	return NewPointLookup(table, p.Index, p.Key, p.Filter), nil
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (p *PointLookup) Expressions() []sql.Expression {
+	return []sql.Expression{p.Filter}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (p *PointLookup) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(p, len(exprs), 1)
+	}
+	return NewPointLookup(p.Table, p.Index, p.Key, exprs[0]), nil
+}
+
+// RowIter implements the sql.Node interface.
+func (p *PointLookup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.PointLookup")
+
+	table := indexAddressableTable(p.Table.Table)
+	if table == nil {
+		span.Finish()
+		return nil, ErrNoIndexableTable.New(p.Table)
+	}
+
+	lookup, err := p.Index.Get(p.Key...)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	indexed := table.WithIndexLookup(lookup)
+	partitions, err := indexed.Partitions(ctx)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	iter := sql.NewTableRowIter(ctx, indexed, partitions)
+	defer iter.Close()
+
+	for {
+		r, err := iter.Next()
+		if err == io.EOF {
+			return sql.NewSpanIter(span, sql.RowsToRowIter()), nil
+		}
+		if err != nil {
+			span.Finish()
+			return nil, err
+		}
+
+		ok, err := sql.EvaluateCondition(ctx, p.Filter, r)
+		if err != nil {
+			span.Finish()
+			return nil, err
+		}
+
+		if ok {
+			return sql.NewSpanIter(span, sql.RowsToRowIter(r)), nil
+		}
+	}
+}
+
+// indexAddressableTable returns the table given, or the first table it wraps, that can be looked up by an index, such
+// as the table of a lookup tracked by its process.
+func indexAddressableTable(t sql.Table) sql.IndexAddressableTable {
+	for {
+		if it, ok := t.(sql.IndexAddressableTable); ok {
+			return it
+		}
+		w, ok := t.(sql.TableWrapper)
+		if !ok {
+			return nil
+		}
+		t = w.Underlying()
+	}
+}
+
+func (p *PointLookup) String() string {
+	return p.format(p.Table.String(), p.Filter.String())
+}
+
+func (p *PointLookup) DebugString() string {
+	return p.format(sql.DebugString(p.Table), sql.DebugString(p.Filter))
+}
+
+func (p *PointLookup) format(table, filter string) string {
+	key := make([]string, len(p.Key))
+	for i, k := range p.Key {
+		key[i] = fmt.Sprint(k)
+	}
+
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("PointLookup([%s] = (%s))", strings.Join(p.Index.Expressions(), ","), strings.Join(key, ", "))
+	_ = pr.WriteChildren(
+		fmt.Sprintf("Filter(%s)", filter),
+		table,
+	)
+	return pr.String()
+}
@@ -0,0 +1,95 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func TestPointLookup(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewPartitionedTable("test", sql.Schema{
+		{Name: "pk", Type: sql.Int64, Source: "test", PrimaryKey: true},
+		{Name: "v", Type: sql.Text, Source: "test"},
+	}, 3)
+	table.EnablePrimaryKeyIndexes()
+	for i := int64(0); i < 10; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "v")))
+	}
+
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+
+	pk := expression.NewGetFieldWithTable(0, sql.Int64, "test", "pk", false)
+	v := expression.NewGetFieldWithTable(1, sql.Text, "test", "v", false)
+
+	lookup := NewPointLookup(NewResolvedTable(table), indexes[0], []interface{}{int64(7)},
+		expression.NewEquals(pk, expression.NewLiteral(int64(7), sql.Int64)))
+	require.Equal(table.Schema(), lookup.Schema())
+	require.Equal([]sql.Node{lookup.Table}, lookup.Children())
+
+	rows, err := sql.NodeToRows(ctx, lookup)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(7), "v")}, rows)
+
+	lookup = NewPointLookup(NewResolvedTable(table), indexes[0], []interface{}{int64(7)},
+		expression.NewAnd(
+			expression.NewEquals(pk, expression.NewLiteral(int64(7), sql.Int64)),
+			expression.NewEquals(v, expression.NewLiteral("other", sql.LongText)),
+		))
+	rows, err = sql.NodeToRows(ctx, lookup)
+	require.NoError(err)
+	require.Empty(rows)
+
+	lookup = NewPointLookup(NewResolvedTable(table), indexes[0], []interface{}{int64(11)},
+		expression.NewEquals(pk, expression.NewLiteral(int64(11), sql.Int64)))
+	rows, err = sql.NodeToRows(ctx, lookup)
+	require.NoError(err)
+	require.Empty(rows)
+}
+
+func BenchmarkPointLookup(b *testing.B) {
+	require := require.New(b)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewPartitionedTable("test", sql.Schema{
+		{Name: "pk", Type: sql.Int64, Source: "test", PrimaryKey: true},
+		{Name: "v", Type: sql.Text, Source: "test"},
+	}, 4)
+	table.EnablePrimaryKeyIndexes()
+	for i := int64(0); i < 1000; i++ {
+		require.NoError(table.Insert(ctx, sql.NewRow(i, "v")))
+	}
+
+	indexes, err := table.GetIndexes(ctx)
+	require.NoError(err)
+
+	filter := expression.NewEquals(
+		expression.NewGetFieldWithTable(0, sql.Int64, "test", "pk", false),
+		expression.NewLiteral(int64(10), sql.Int64),
+	)
+
+	b.Run("filter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			node := NewExchange(2, NewFilter(filter, NewResolvedTable(table)))
+			rows, err := sql.NodeToRows(ctx, node)
+			require.NoError(err)
+			require.Len(rows, 1)
+		}
+	})
+
+	b.Run("point lookup", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			node := NewPointLookup(NewResolvedTable(table), indexes[0], []interface{}{int64(10)}, filter)
+			rows, err := sql.NodeToRows(ctx, node)
+			require.NoError(err)
+			require.Len(rows, 1)
+		}
+	})
+}
@@ -161,10 +161,13 @@ func (t *ProcessTable) PartitionRows(ctx *sql.Context, p sql.Partition) (sql.Row
 
 type trackedRowIter struct {
 	iter   sql.RowIter
+	batch  sql.BatchRowIter
 	onDone NotifyFunc
 	onNext NotifyFunc
 }
 
+var _ sql.BatchRowIter = (*trackedRowIter)(nil)
+
 func (i *trackedRowIter) done() {
 	if i.onDone != nil {
 		i.onDone()
@@ -185,6 +188,26 @@ func (i *trackedRowIter) Next() (sql.Row, error) {
 	return row, nil
 }
 
+// NextBatch implements the sql.BatchRowIter interface. Every row of the batch is notified.
+func (i *trackedRowIter) NextBatch(rows []sql.Row) (int, error) {
+	if i.batch == nil {
+		i.batch = sql.NewBatchRowIter(i.iter)
+	}
+
+	n, err := i.batch.NextBatch(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	if i.onNext != nil {
+		for j := 0; j < n; j++ {
+			i.onNext()
+		}
+	}
+
+	return n, nil
+}
+
 func (i *trackedRowIter) Close() error {
 	err := i.iter.Close()
 	i.done()
@@ -198,8 +221,8 @@ type trackedPartitionIndexKeyValueIter struct {
 	OnRowNext        NamedNotifyFunc
 }
 
-func (i *trackedPartitionIndexKeyValueIter) Next() (sql.Partition, sql.IndexKeyValueIter, error) {
-	p, iter, err := i.PartitionIndexKeyValueIter.Next()
+func (i *trackedPartitionIndexKeyValueIter) Next(ctx *sql.Context) (sql.Partition, sql.IndexKeyValueIter, error) {
+	p, iter, err := i.PartitionIndexKeyValueIter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -247,8 +270,8 @@ func (i *trackedIndexKeyValueIter) Close() (err error) {
 	return err
 }
 
-func (i *trackedIndexKeyValueIter) Next() ([]interface{}, []byte, error) {
-	v, k, err := i.iter.Next()
+func (i *trackedIndexKeyValueIter) Next(ctx *sql.Context) ([]interface{}, []byte, error) {
+	v, k, err := i.iter.Next(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
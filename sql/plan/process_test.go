@@ -134,19 +134,20 @@ func TestProcessIndexableTable(t *testing.T) {
 		},
 	)
 
-	iter, err := pt.IndexKeyValues(sql.NewEmptyContext(), []string{"a"})
+	ctx := sql.NewEmptyContext()
+	iter, err := pt.IndexKeyValues(ctx, []string{"a"})
 	require.NoError(err)
 
 	var values [][]interface{}
 	for {
-		_, kviter, err := iter.Next()
+		_, kviter, err := iter.Next(ctx)
 		if err == io.EOF {
 			break
 		}
 		require.NoError(err)
 
 		for {
-			v, _, err := kviter.Next()
+			v, _, err := kviter.Next(ctx)
 			if err == io.EOF {
 				kviter.Close()
 				break
@@ -16,6 +16,8 @@ type Project struct {
 	Projections []sql.Expression
 }
 
+var _ sql.Node2 = (*Project)(nil)
+
 // NewProject creates a new projection.
 func NewProject(expressions []sql.Expression, child sql.Node) *Project {
 	return &Project{
@@ -60,6 +62,40 @@ func (p *Project) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	}), nil
 }
 
+// CanRowIter2 implements the sql.Node2 interface.
+func (p *Project) CanRowIter2() bool {
+	if !sql.CanRowIter2(p.Child) {
+		return false
+	}
+	for _, e := range p.Projections {
+		if !sql.CanEval2(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// RowIter2 implements the sql.Node2 interface.
+func (p *Project) RowIter2(ctx *sql.Context) (sql.RowIter2, error) {
+	span, ctx := ctx.Span("plan.Project", opentracing.Tag{
+		Key:   "projections",
+		Value: len(p.Projections),
+	})
+
+	i, err := p.Child.(sql.Node2).RowIter2(ctx)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return sql.NewSpanIter2(span, &iter{
+		p:          p,
+		childIter:  i,
+		childIter2: i,
+		ctx:        ctx,
+	}), nil
+}
+
 func (p *Project) String() string {
 	pr := sql.NewTreePrinter()
 	var exprs = make([]string, len(p.Projections))
@@ -105,11 +141,18 @@ func (p *Project) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
 	return NewProject(exprs, p.Child), nil
 }
 
+var _ sql.BatchRowIter = (*iter)(nil)
+
 type iter struct {
-	p         *Project
-	childIter sql.RowIter
-	row       sql.Row
-	ctx       *sql.Context
+	p           *Project
+	childIter   sql.RowIter
+	childIter2  sql.RowIter2
+	childFrame  *sql.RowFrame
+	childBatch  sql.BatchRowIter
+	row         sql.Row
+	ctx         *sql.Context
+	projections []sql.Expression
+	evaluated   int
 }
 
 func (i *iter) Next() (sql.Row, error) {
@@ -118,10 +161,77 @@ func (i *iter) Next() (sql.Row, error) {
 		return nil, err
 	}
 
-	return ProjectRow(i.ctx, i.p.Projections, childRow)
+	return ProjectRow(i.ctx, i.getProjections(), childRow)
+}
+
+// NextBatch implements the sql.BatchRowIter interface. The rows of every batch of the child are replaced by their
+// projections.
+func (i *iter) NextBatch(rows []sql.Row) (int, error) {
+	if i.childBatch == nil {
+		i.childBatch = sql.NewBatchRowIter(i.childIter)
+	}
+
+	n, err := i.childBatch.NextBatch(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	for j, row := range rows[:n] {
+		rows[j], err = ProjectRow(i.ctx, i.getProjections(), row)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// getProjections returns the projections to evaluate on the next row, which are compiled once they have been
+// evaluated on enough rows.
+func (i *iter) getProjections() []sql.Expression {
+	if i.projections != nil {
+		return i.projections
+	}
+
+	i.evaluated++
+	if i.evaluated < compileThreshold {
+		return i.p.Projections
+	}
+
+	i.projections = make([]sql.Expression, len(i.p.Projections))
+	for j, e := range i.p.Projections {
+		i.projections[j] = expression.Compile(e)
+	}
+	return i.projections
+}
+
+func (i *iter) Next2(frame *sql.RowFrame) error {
+	if i.childFrame == nil {
+		i.childFrame = sql.NewRowFrame()
+	}
+
+	if err := i.childIter2.Next2(i.childFrame); err != nil {
+		return err
+	}
+
+	frame.Clear()
+	row := i.childFrame.Row2()
+	for _, e := range i.p.Projections {
+		v, err := e.(sql.Expression2).Eval2(i.ctx, row)
+		if err != nil {
+			return err
+		}
+		frame.Append(v)
+	}
+
+	return nil
 }
 
 func (i *iter) Close() error {
+	if i.childFrame != nil {
+		i.childFrame.Recycle()
+		i.childFrame = nil
+	}
 	return i.childIter.Close()
 }
 
@@ -87,3 +87,79 @@ func BenchmarkProject(b *testing.B) {
 		}
 	}
 }
+
+func TestProjectRowIter2(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	child := memory.NewPartitionedTable("test", sql.Schema{
+		{Name: "col1", Type: sql.Int64, Source: "test"},
+		{Name: "col2", Type: sql.Text, Source: "test", Nullable: true},
+	}, 2)
+	require.NoError(child.Insert(ctx, sql.NewRow(int64(1), "a")))
+	require.NoError(child.Insert(ctx, sql.NewRow(int64(2), nil)))
+	require.NoError(child.Insert(ctx, sql.NewRow(int64(3), "c")))
+
+	p := NewProject([]sql.Expression{
+		expression.NewAlias("foo", expression.NewGetField(1, sql.Text, "col2", true)),
+		expression.NewLiteral(int64(10), sql.Int64),
+		expression.NewGetField(0, sql.Int64, "col1", false),
+	}, NewResolvedTable(child))
+	require.True(p.CanRowIter2())
+
+	rows2, err := sql.NodeToRows2(ctx, p)
+	require.NoError(err)
+
+	var rows []sql.Row
+	for _, r := range rows2 {
+		row, err := sql.Row2ToRow(p.Schema(), r)
+		require.NoError(err)
+		rows = append(rows, row)
+	}
+
+	expected, err := sql.NodeToRows(ctx, p)
+	require.NoError(err)
+	require.ElementsMatch(expected, rows)
+	require.Len(rows, 3)
+
+	p = NewProject([]sql.Expression{
+		expression.NewArithmetic(
+			expression.NewGetField(0, sql.Int64, "col1", false),
+			expression.NewLiteral(int64(1), sql.Int64),
+			"+",
+		),
+	}, NewResolvedTable(child))
+	require.False(p.CanRowIter2())
+}
+
+func BenchmarkProjectRowIter2(b *testing.B) {
+	require := require.New(b)
+	ctx := sql.NewEmptyContext()
+	frame := sql.NewRowFrame()
+	defer frame.Recycle()
+
+	for i := 0; i < b.N; i++ {
+		d := NewProject([]sql.Expression{
+			expression.NewGetField(0, sql.Text, "strfield", true),
+			expression.NewGetField(1, sql.Float64, "floatfield", true),
+			expression.NewGetField(2, sql.Boolean, "boolfield", false),
+			expression.NewGetField(3, sql.Int32, "intfield", false),
+			expression.NewGetField(4, sql.Int64, "bigintfield", false),
+			expression.NewGetField(5, sql.Blob, "blobfield", false),
+		}, NewResolvedTable(benchtable))
+		require.True(d.CanRowIter2())
+
+		iter, err := d.RowIter2(ctx)
+		require.NoError(err)
+
+		for {
+			err := iter.Next2(frame)
+			if err == io.EOF {
+				break
+			}
+
+			require.NoError(err)
+		}
+		require.NoError(iter.Close())
+	}
+}
@@ -0,0 +1,100 @@
+package plan
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// QueryHints are the optimizer hints of a query block, given in a /*+ ... */ comment after its SELECT keyword. The
+// analyzer rules they change the plans of read them from the first QueryHints node above the nodes they analyze, so
+// the hints of a query block apply to the tables of the query block alone, not to those of its subqueries and derived
+// tables. A MAX_EXECUTION_TIME hint interrupts the query once it has run for longer than it allows.
+type QueryHints struct {
+	UnaryNode
+	Hints sql.Hints
+}
+
+var _ sql.Node = (*QueryHints)(nil)
+
+// NewQueryHints returns a new QueryHints node with the hints given of the query block given.
+func NewQueryHints(hints sql.Hints, child sql.Node) *QueryHints {
+	return &QueryHints{UnaryNode: UnaryNode{child}, Hints: hints}
+}
+
+// MaxExecutionTime returns how long the query may run, given by its MAX_EXECUTION_TIME hint, or zero if it may run
+// for as long as needed.
+func (h *QueryHints) MaxExecutionTime() time.Duration {
+	hint, ok := h.Hints.Get(sql.MaxExecutionTimeHint)
+	if !ok || len(hint.Args) != 1 {
+		return 0
+	}
+	ms, err := strconv.ParseUint(hint.Args[0], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// RowIter implements the sql.Node interface.
+func (h *QueryHints) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	timeout := h.MaxExecutionTime()
+	if timeout == 0 {
+		return h.Child.RowIter(ctx, row)
+	}
+
+	newCtx, cancel := context.WithTimeout(ctx, timeout)
+	ctx = ctx.WithContext(newCtx)
+	iter, err := h.Child.RowIter(ctx, row)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &maxExecutionTimeIter{ctx: ctx, iter: sql.NewCancelableRowIter(ctx, iter), cancel: cancel}, nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (h *QueryHints) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(h, len(children), 1)
+	}
+	return NewQueryHints(h.Hints, children[0]), nil
+}
+
+func (h *QueryHints) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("QueryHints(%s)", h.Hints)
+	_ = pr.WriteChildren(h.Child.String())
+	return pr.String()
+}
+
+func (h *QueryHints) DebugString() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("QueryHints(%s)", h.Hints)
+	_ = pr.WriteChildren(sql.DebugString(h.Child))
+	return pr.String()
+}
+
+// maxExecutionTimeIter is the iterator of a query with a MAX_EXECUTION_TIME hint, which fails with
+// sql.ErrMaxExecutionTimeExceeded once its context times out.
+type maxExecutionTimeIter struct {
+	ctx    *sql.Context
+	iter   sql.RowIter
+	cancel context.CancelFunc
+}
+
+func (i *maxExecutionTimeIter) Next() (sql.Row, error) {
+	row, err := i.iter.Next()
+	if err != nil && err != io.EOF && i.ctx.Err() == context.DeadlineExceeded {
+		return nil, sql.ErrMaxExecutionTimeExceeded.New()
+	}
+	return row, err
+}
+
+func (i *maxExecutionTimeIter) Close() error {
+	defer i.cancel()
+	return i.iter.Close()
+}
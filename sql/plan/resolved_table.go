@@ -9,13 +9,21 @@ import (
 // ResolvedTable represents a resolved SQL Table.
 type ResolvedTable struct {
 	sql.Table
+	// Database is the name of the database of the table, if it's known.
+	Database string
 }
 
 var _ sql.Node = (*ResolvedTable)(nil)
+var _ sql.Node2 = (*ResolvedTable)(nil)
 
 // NewResolvedTable creates a new instance of ResolvedTable.
 func NewResolvedTable(table sql.Table) *ResolvedTable {
-	return &ResolvedTable{table}
+	return &ResolvedTable{Table: table}
+}
+
+// NewResolvedTableInDatabase creates a new instance of ResolvedTable for the table of the database given.
+func NewResolvedTableInDatabase(table sql.Table, db string) *ResolvedTable {
+	return &ResolvedTable{Table: table, Database: db}
 }
 
 // Resolved implements the Resolvable interface.
@@ -47,6 +55,25 @@ func (t *ResolvedTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, err
 	return sql.NewSpanIter(span, sql.NewTableRowIter(ctx, t.Table, partitions)), nil
 }
 
+// CanRowIter2 implements the sql.Node2 interface.
+func (t *ResolvedTable) CanRowIter2() bool {
+	_, ok := t.Table.(sql.Table2)
+	return ok
+}
+
+// RowIter2 implements the sql.Node2 interface.
+func (t *ResolvedTable) RowIter2(ctx *sql.Context) (sql.RowIter2, error) {
+	span, ctx := ctx.Span("plan.ResolvedTable")
+
+	partitions, err := t.Table.Partitions(ctx)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return sql.NewSpanIter2(span, sql.NewTableRowIter(ctx, t.Table, partitions)), nil
+}
+
 // WithChildren implements the Node interface.
 func (t *ResolvedTable) WithChildren(children ...sql.Node) (sql.Node, error) {
 	if len(children) != 0 {
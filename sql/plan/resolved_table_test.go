@@ -132,7 +132,7 @@ type partitionIter struct {
 	pos  int
 }
 
-func (p *partitionIter) Next() (sql.Partition, error) {
+func (p *partitionIter) Next(*sql.Context) (sql.Partition, error) {
 	if p.pos >= len(p.keys) {
 		return nil, io.EOF
 	}
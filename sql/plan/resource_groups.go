@@ -0,0 +1,253 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// CreateResourceGroup is the CREATE RESOURCE GROUP statement.
+type CreateResourceGroup struct {
+	Group          sql.ResourceGroup
+	ResourceGroups *sql.ResourceGroupRegistry
+}
+
+var _ sql.Node = (*CreateResourceGroup)(nil)
+
+// NewCreateResourceGroup creates a new CreateResourceGroup node.
+func NewCreateResourceGroup(group sql.ResourceGroup) *CreateResourceGroup {
+	return &CreateResourceGroup{Group: group}
+}
+
+// Resolved implements the sql.Node interface.
+func (c *CreateResourceGroup) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (c *CreateResourceGroup) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (c *CreateResourceGroup) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (c *CreateResourceGroup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), 0)
+	}
+	return c, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (c *CreateResourceGroup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if err := c.ResourceGroups.CreateResourceGroup(c.Group); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (c *CreateResourceGroup) String() string {
+	str := fmt.Sprintf("CREATE RESOURCE GROUP %s TYPE = %s", c.Group.Name, c.Group.Type)
+	if len(c.Group.VCPUs) > 0 {
+		str += " VCPU = " + c.Group.VCPUString(0)
+	}
+	str += fmt.Sprintf(" THREAD_PRIORITY = %d", c.Group.ThreadPriority)
+	if !c.Group.Enabled {
+		str += " DISABLE"
+	}
+	return str
+}
+
+// AlterResourceGroup is the ALTER RESOURCE GROUP statement. Attributes that aren't set keep their values.
+type AlterResourceGroup struct {
+	Name string
+	// VCPUs are the new VCPU ids of the group, or nil to keep them.
+	VCPUs []int
+	// ThreadPriority is the new priority of the group, or nil to keep it.
+	ThreadPriority *int
+	// Enabled is whether the group is enabled or disabled, or nil to keep it as it is.
+	Enabled *bool
+	// Force moves the sessions of a group that is disabled to the default group.
+	Force          bool
+	ResourceGroups *sql.ResourceGroupRegistry
+}
+
+var _ sql.Node = (*AlterResourceGroup)(nil)
+
+// NewAlterResourceGroup creates a new AlterResourceGroup node.
+func NewAlterResourceGroup(name string, vcpus []int, threadPriority *int, enabled *bool, force bool) *AlterResourceGroup {
+	return &AlterResourceGroup{
+		Name:           name,
+		VCPUs:          vcpus,
+		ThreadPriority: threadPriority,
+		Enabled:        enabled,
+		Force:          force,
+	}
+}
+
+// Resolved implements the sql.Node interface.
+func (a *AlterResourceGroup) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (a *AlterResourceGroup) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (a *AlterResourceGroup) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (a *AlterResourceGroup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(a, len(children), 0)
+	}
+	return a, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (a *AlterResourceGroup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	g, ok := a.ResourceGroups.ResourceGroup(a.Name)
+	if !ok {
+		return nil, sql.ErrResourceGroupNotExists.New(a.Name)
+	}
+
+	if a.VCPUs != nil {
+		g.VCPUs = a.VCPUs
+	}
+	if a.ThreadPriority != nil {
+		g.ThreadPriority = *a.ThreadPriority
+	}
+	if a.Enabled != nil {
+		g.Enabled = *a.Enabled
+	}
+
+	if err := a.ResourceGroups.AlterResourceGroup(g, a.Force); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (a *AlterResourceGroup) String() string {
+	str := "ALTER RESOURCE GROUP " + a.Name
+	if a.VCPUs != nil {
+		str += " VCPU = " + sql.ResourceGroup{VCPUs: a.VCPUs}.VCPUString(0)
+	}
+	if a.ThreadPriority != nil {
+		str += fmt.Sprintf(" THREAD_PRIORITY = %d", *a.ThreadPriority)
+	}
+	if a.Enabled != nil {
+		if *a.Enabled {
+			str += " ENABLE"
+		} else {
+			str += " DISABLE"
+		}
+	}
+	if a.Force {
+		str += " FORCE"
+	}
+	return str
+}
+
+// DropResourceGroup is the DROP RESOURCE GROUP statement.
+type DropResourceGroup struct {
+	Name string
+	// Force drops the group even if sessions are assigned to it, moving them to the default group.
+	Force          bool
+	ResourceGroups *sql.ResourceGroupRegistry
+}
+
+var _ sql.Node = (*DropResourceGroup)(nil)
+
+// NewDropResourceGroup creates a new DropResourceGroup node.
+func NewDropResourceGroup(name string, force bool) *DropResourceGroup {
+	return &DropResourceGroup{Name: name, Force: force}
+}
+
+// Resolved implements the sql.Node interface.
+func (d *DropResourceGroup) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (d *DropResourceGroup) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (d *DropResourceGroup) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (d *DropResourceGroup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(d, len(children), 0)
+	}
+	return d, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (d *DropResourceGroup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	if err := d.ResourceGroups.DropResourceGroup(d.Name, d.Force); err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (d *DropResourceGroup) String() string {
+	str := "DROP RESOURCE GROUP " + d.Name
+	if d.Force {
+		str += " FORCE"
+	}
+	return str
+}
+
+// SetResourceGroup is the SET RESOURCE GROUP statement, which assigns sessions to a resource group.
+type SetResourceGroup struct {
+	Name string
+	// ThreadIDs are the ids of the connections of the sessions to assign, or empty for the session of the statement.
+	ThreadIDs      []uint32
+	ResourceGroups *sql.ResourceGroupRegistry
+}
+
+var _ sql.Node = (*SetResourceGroup)(nil)
+
+// NewSetResourceGroup creates a new SetResourceGroup node.
+func NewSetResourceGroup(name string, threadIDs []uint32) *SetResourceGroup {
+	return &SetResourceGroup{Name: name, ThreadIDs: threadIDs}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *SetResourceGroup) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (s *SetResourceGroup) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (s *SetResourceGroup) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (s *SetResourceGroup) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (s *SetResourceGroup) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	ids := s.ThreadIDs
+	if len(ids) == 0 {
+		ids = []uint32{ctx.Session.ID()}
+	}
+
+	for _, id := range ids {
+		if err := s.ResourceGroups.SetSessionResourceGroup(id, s.Name); err != nil {
+			return nil, err
+		}
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (s *SetResourceGroup) String() string {
+	str := "SET RESOURCE GROUP " + s.Name
+	if len(s.ThreadIDs) > 0 {
+		ids := make([]string, len(s.ThreadIDs))
+		for i, id := range s.ThreadIDs {
+			ids[i] = fmt.Sprint(id)
+		}
+		str += " FOR " + strings.Join(ids, ", ")
+	}
+	return str
+}
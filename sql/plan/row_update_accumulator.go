@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 type RowUpdateType int
@@ -188,9 +189,11 @@ func (u *deleteRowHandler) okResult() sql.OkResult {
 }
 
 type accumulatorIter struct {
+	ctx              *sql.Context
 	iter             sql.RowIter
 	once             sync.Once
 	updateRowHandler accumulatorRowHandler
+	autoIncrements   []*expression.AutoIncrement
 }
 
 func (a *accumulatorIter) Next() (sql.Row, error) {
@@ -206,7 +209,16 @@ func (a *accumulatorIter) Next() (sql.Row, error) {
 	for {
 		row, err := a.iter.Next()
 		if err == io.EOF {
-			return sql.NewRow(a.updateRowHandler.okResult()), nil
+			res := a.updateRowHandler.okResult()
+			insertID, err := a.insertID()
+			if err != nil {
+				return nil, err
+			}
+			if insertID != 0 {
+				res.InsertID = insertID
+				a.ctx.SetLastQueryInfo(sql.LastInsertId, int64(insertID))
+			}
+			return sql.NewRow(res), nil
 		}
 
 		if err != nil {
@@ -220,6 +232,20 @@ func (a *accumulatorIter) Next() (sql.Row, error) {
 	}
 }
 
+// insertID returns the first value generated for an AUTO_INCREMENT column by the statement, or 0 if it generated none.
+func (a *accumulatorIter) insertID() (uint64, error) {
+	for _, ai := range a.autoIncrements {
+		if id, ok := ai.FirstGenerated(); ok {
+			v, err := sql.Uint64.Convert(id)
+			if err != nil {
+				return 0, err
+			}
+			return v.(uint64), nil
+		}
+	}
+	return 0, nil
+}
+
 func (a *accumulatorIter) Close() error {
 	return a.iter.Close()
 }
@@ -249,8 +275,19 @@ func (r RowUpdateAccumulator) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIte
 		panic(fmt.Sprintf("Unrecognized RowUpdateType %d", r.RowUpdateType))
 	}
 
+	// The values generated for AUTO_INCREMENT columns are the insert ID of the statement
+	var autoIncrements []*expression.AutoIncrement
+	InspectExpressions(r.Child, func(e sql.Expression) bool {
+		if ai, ok := e.(*expression.AutoIncrement); ok {
+			autoIncrements = append(autoIncrements, ai)
+		}
+		return true
+	})
+
 	return &accumulatorIter{
+		ctx:              ctx,
 		iter:             rowIter,
 		updateRowHandler: rowHandler,
+		autoIncrements:   autoIncrements,
 	}, nil
 }
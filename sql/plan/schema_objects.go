@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// SchemaObjects returns the tables and views the nodes given read, with no duplicates, for the schema snapshots of
+// the caches of analyzed plans. Parsed nodes name the tables and views of the query, and analyzed ones the tables the
+// views resolved to, so caches take the objects of both. Tables of no database are in the current database.
+func SchemaObjects(ctx *sql.Context, nodes ...sql.Node) []sql.SchemaObject {
+	var objects []sql.SchemaObject
+	seen := make(map[sql.SchemaObject]bool)
+	add := func(db, table string) {
+		if db == "" {
+			db = ctx.GetCurrentDatabase()
+		}
+		o := sql.SchemaObject{Database: db, Table: table}
+		key := sql.SchemaObject{Database: strings.ToLower(db), Table: strings.ToLower(table)}
+		if !seen[key] {
+			seen[key] = true
+			objects = append(objects, o)
+		}
+	}
+
+	var inspect func(sql.Node) bool
+	inspect = func(node sql.Node) bool {
+		switch n := node.(type) {
+		case *UnresolvedTable:
+			add(n.Database, n.Name())
+		case *ResolvedTable:
+			add(n.Database, n.Name())
+		}
+		if n, ok := node.(sql.Expressioner); ok {
+			for _, e := range n.Expressions() {
+				sql.Inspect(e, func(e sql.Expression) bool {
+					if sq, ok := e.(*Subquery); ok {
+						Inspect(sq.Query, inspect)
+					}
+					return true
+				})
+			}
+		}
+		return true
+	}
+	for _, n := range nodes {
+		Inspect(n, inspect)
+	}
+	return objects
+}
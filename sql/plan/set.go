@@ -79,6 +79,14 @@ func (s *Set) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 			}
 		case *expression.GetField:
 			updateExprs = append(updateExprs, setField)
+		case *LocalVariable:
+			value, err := setField.Right.Eval(ctx, row)
+			if err != nil {
+				return nil, err
+			}
+			if err := left.Assign(value); err != nil {
+				return nil, err
+			}
 		default:
 			return nil, fmt.Errorf("unsupported type for set: %T", left)
 		}
@@ -135,6 +143,13 @@ func setSystemVar(ctx *sql.Context, sysVar *expression.SystemVar, right sql.Expr
 	}
 	typ = sysVar.Type()
 
+	if sysVar.Global {
+		return value, ctx.GlobalVariables.Set(varName, value)
+	}
+	if v, ok := sql.GetSystemVariable(varName); ok && v.GlobalOnly {
+		return nil, sql.ErrGlobalVariable.New(varName)
+	}
+
 	// TODO: differentiate between system and user vars here
 	err = ctx.Set(ctx, varName, typ, value)
 	if err != nil {
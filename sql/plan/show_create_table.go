@@ -142,6 +142,12 @@ type NameAndSchema interface {
 }
 
 func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (string, error) {
+	return CreateTableStatement(i.ctx, table, i.indexes)
+}
+
+// CreateTableStatement returns the CREATE TABLE statement of the table given, with its columns, primary key, the
+// indexes given and its foreign keys.
+func CreateTableStatement(ctx *sql.Context, table sql.Table, indexes []sql.Index) (string, error) {
 	schema := table.Schema()
 	colStmts := make([]string, len(schema))
 	var primaryKeyCols []string
@@ -181,7 +187,7 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 		colStmts = append(colStmts, primaryKey)
 	}
 
-	for _, index := range i.indexes {
+	for _, index := range indexes {
 		// The primary key may or may not be declared as an index by the table. Don't print it twice if it's here.
 		if isPrimaryKeyIndex(index, table) {
 			continue
@@ -210,7 +216,7 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 
 	fkt := getForeignKeyTable(table)
 	if fkt != nil {
-		fks, err := fkt.GetForeignKeys(i.ctx)
+		fks, err := fkt.GetForeignKeys(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -229,6 +235,20 @@ func (i *showCreateTablesIter) produceCreateTableStatement(table sql.Table) (str
 		}
 	}
 
+	if ct := GetCheckTable(table); ct != nil {
+		checks, err := ct.GetChecks(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, check := range checks {
+			stmt := fmt.Sprintf("  CONSTRAINT `%s` CHECK (%s)", check.Name, check.Expression)
+			if !check.Enforced {
+				stmt = fmt.Sprintf("%s NOT ENFORCED", stmt)
+			}
+			colStmts = append(colStmts, stmt)
+		}
+	}
+
 	return fmt.Sprintf(
 		"CREATE TABLE `%s` (\n%s\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
 		table.Name(),
@@ -0,0 +1,63 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ShowGrants is the SHOW GRANTS statement, which shows the privileges of a user as GRANT statements.
+type ShowGrants struct {
+	// User is the user whose grants are shown, or empty for the user of the session, which is set by the analyzer.
+	User    string
+	Host    string
+	Catalog *sql.Catalog
+}
+
+var _ sql.Node = (*ShowGrants)(nil)
+
+// NewShowGrants creates a new ShowGrants node for the account given, or for the user of the session if the user is
+// empty.
+func NewShowGrants(user, host string) *ShowGrants {
+	return &ShowGrants{User: user, Host: host}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *ShowGrants) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (s *ShowGrants) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (s *ShowGrants) Schema() sql.Schema {
+	return sql.Schema{{Name: fmt.Sprintf("Grants for %s@%s", s.User, s.Host), Type: sql.LongText}}
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *ShowGrants) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (s *ShowGrants) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	grants, err := s.Catalog.Grants(s.User, s.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, len(grants))
+	for i, g := range grants {
+		rows[i] = sql.NewRow(g)
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (s *ShowGrants) String() string {
+	if s.User == "" {
+		return "SHOW GRANTS"
+	}
+	return fmt.Sprintf("SHOW GRANTS FOR %s@%s", s.User, s.Host)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 // ShowColumns shows the columns details of a table.
@@ -56,13 +57,39 @@ func (s *ShowColumns) Schema() sql.Schema {
 func (s *ShowColumns) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	span, _ := ctx.Span("plan.ShowColumns")
 
+	node := s.Child
+	if exchange, ok := node.(*Exchange); ok {
+		node = exchange.Child
+	}
+	var keys map[string]string
+	switch table := node.(type) {
+	case *ResolvedTable:
+		var err error
+		keys, err = s.columnKeys(ctx, table)
+		if err != nil {
+			span.Finish()
+			return nil, err
+		}
+	case *SubqueryAlias:
+		// no key info for views
+	default:
+		panic(fmt.Sprintf("unexpected type %T", s.Child))
+	}
+
 	schema := s.Child.Schema()
 	var rows = make([]sql.Row, len(schema))
 	for i, col := range schema {
 		var row sql.Row
 		var collation interface{}
-		if sql.IsTextOnly(col.Type) {
-			collation = sql.Collation_Default.String()
+		switch t := col.Type.(type) {
+		case sql.StringType:
+			if t.CharacterSet() != sql.CharacterSet_binary {
+				collation = t.Collation().String()
+			}
+		case sql.EnumType:
+			collation = t.Collation().String()
+		case sql.SetType:
+			collation = t.Collation().String()
 		}
 
 		var null = "NO"
@@ -70,52 +97,32 @@ func (s *ShowColumns) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error
 			null = "YES"
 		}
 
-		node := s.Child
-		if exchange, ok := node.(*Exchange); ok {
-			node = exchange.Child
-		}
-		key := ""
-		switch table := node.(type) {
-		case *ResolvedTable:
-			if col.PrimaryKey {
-				key = "PRI"
-			} else if s.isFirstColInUniqueKey(col, table) {
-				key = "UNI"
-			} else if s.isFirstColInNonUniqueKey(col, table) {
-				key = "MUL"
-			}
-		case *SubqueryAlias:
-			// no key info for views
-		default:
-			panic(fmt.Sprintf("unexpected type %T", s.Child))
-		}
-
-		var defaultVal string
-		if col.Default != nil {
-			defaultVal = col.Default.String()
+		defaultVal, err := columnDefaultString(ctx, col)
+		if err != nil {
+			span.Finish()
+			return nil, err
 		}
 
-		// TODO: rather than lower-casing here, we should lower-case the String() method of types
 		if s.Full {
 			row = sql.Row{
 				col.Name,
-				strings.ToLower(col.Type.String()),
+				sql.ColumnTypeString(col.Type),
 				collation,
 				null,
-				key, // Key
+				keys[col.Name],
 				defaultVal,
-				"",          // Extra
+				columnExtra(col),
 				"",          // Privileges
 				col.Comment, // Comment
 			}
 		} else {
 			row = sql.Row{
 				col.Name,
-				strings.ToLower(col.Type.String()),
+				sql.ColumnTypeString(col.Type),
 				null,
-				key,
+				keys[col.Name],
 				defaultVal,
-				"", // Extra
+				columnExtra(col),
 			}
 		}
 
@@ -147,32 +154,137 @@ func (s *ShowColumns) String() string {
 	return tp.String()
 }
 
-func (s *ShowColumns) isFirstColInUniqueKey(col *sql.Column, table sql.Table) bool {
+// columnKeys returns the Key column of the columns of the table given, by the name of the columns. As in MySQL, the
+// columns of the primary key are PRI, or the columns of the first unique index whose columns can't be NULL if there's
+// no primary key. The only column of other unique indexes is UNI, and the first column of other indexes and foreign
+// keys is MUL.
+func (s *ShowColumns) columnKeys(ctx *sql.Context, table *ResolvedTable) (map[string]string, error) {
+	keys := make(map[string]string)
+	hasPrimaryKey := false
+	for _, col := range table.Schema() {
+		if col.PrimaryKey {
+			keys[col.Name] = "PRI"
+			hasPrimaryKey = true
+		}
+	}
+
+	setKey := func(col *sql.Column, key string) {
+		if keys[col.Name] == "PRI" || (keys[col.Name] == "UNI" && key == "MUL") {
+			return
+		}
+		keys[col.Name] = key
+	}
+
 	for _, idx := range s.Indexes {
-		if !idx.IsUnique() {
+		var cols []*sql.Column
+		for _, expr := range idx.Expressions() {
+			cols = append(cols, GetColumnFromIndexExpr(expr, table))
+		}
+
+		if idx.IsUnique() && !hasPrimaryKey && allNotNull(cols) {
+			hasPrimaryKey = true
+			for _, col := range cols {
+				keys[col.Name] = "PRI"
+			}
 			continue
 		}
 
-		firstIndexCol := GetColumnFromIndexExpr(idx.Expressions()[0], table)
-		if firstIndexCol != nil && firstIndexCol.Name == col.Name {
-			return true
+		if len(cols) == 0 || cols[0] == nil {
+			continue
+		}
+		if idx.IsUnique() && len(cols) == 1 {
+			setKey(cols[0], "UNI")
+		} else {
+			setKey(cols[0], "MUL")
+		}
+	}
+
+	if fkTable, ok := underlyingTable(table).(sql.ForeignKeyTable); ok {
+		fks, err := fkTable.GetForeignKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range fks {
+			if len(fk.Columns) == 0 {
+				continue
+			}
+			if i := table.Schema().IndexOf(fk.Columns[0], table.Name()); i >= 0 {
+				setKey(table.Schema()[i], "MUL")
+			}
 		}
 	}
 
-	return false
+	return keys, nil
 }
 
-func (s *ShowColumns) isFirstColInNonUniqueKey(col *sql.Column, table sql.Table) bool {
-	for _, idx := range s.Indexes {
-		if idx.IsUnique() {
-			continue
+// allNotNull returns whether the columns given are columns that can't be NULL.
+func allNotNull(cols []*sql.Column) bool {
+	if len(cols) == 0 {
+		return false
+	}
+	for _, col := range cols {
+		if col == nil || col.Nullable {
+			return false
 		}
+	}
+	return true
+}
+
+// columnDefaultString returns the Default column of the column given. As in MySQL, literal defaults are shown as the
+// value they give the column, without quotes, and CURRENT_TIMESTAMP and its synonyms are shown as CURRENT_TIMESTAMP.
+func columnDefaultString(ctx *sql.Context, col *sql.Column) (interface{}, error) {
+	if col.Default == nil {
+		return nil, nil
+	}
 
-		firstIndexCol := GetColumnFromIndexExpr(idx.Expressions()[0], table)
-		if firstIndexCol != nil && firstIndexCol.Name == col.Name {
-			return true
+	if _, ok := col.Default.Expression.(*expression.Literal); !ok {
+		if isCurrentTimestamp(col.Default.Expression.String()) {
+			return "CURRENT_TIMESTAMP", nil
 		}
+		return col.Default.String(), nil
+	}
+
+	val, err := col.Default.Eval(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
+	if val == nil {
+		return nil, nil
+	}
+	if _, ok := col.Type.(sql.BitType); ok {
+		return fmt.Sprintf("b'%b'", val), nil
+	}
+
+	sqlVal, err := col.Type.SQL(val)
+	if err != nil {
+		return nil, err
+	}
+	return sqlVal.ToString(), nil
+}
+
+// isCurrentTimestamp returns whether the default expression given is CURRENT_TIMESTAMP or one of its synonyms.
+func isCurrentTimestamp(expr string) bool {
+	switch strings.ToLower(expr) {
+	case "now()", "current_timestamp()", "localtime()", "localtimestamp()":
+		return true
+	default:
+		return false
+	}
+}
 
-	return false
+// columnExtra returns the Extra column of the column given.
+func columnExtra(col *sql.Column) string {
+	var extra []string
+	if col.AutoIncrement {
+		extra = append(extra, "auto_increment")
+	}
+	if col.Default != nil {
+		if _, ok := col.Default.Expression.(*expression.Literal); !ok {
+			extra = append(extra, "DEFAULT_GENERATED")
+		}
+	}
+	if col.Extra != "" {
+		extra = append(extra, col.Extra)
+	}
+	return strings.Join(extra, " ")
 }
@@ -29,9 +29,9 @@ func TestShowColumns(t *testing.T) {
 	require.NoError(err)
 
 	expected := []sql.Row{
-		{"a", "text", "NO", "PRI", "", ""},
-		{"b", "bigint", "YES", "", "", ""},
-		{"c", "bigint", "NO", "", "1", ""},
+		{"a", "text", "NO", "PRI", nil, ""},
+		{"b", "bigint(20)", "YES", "", nil, ""},
+		{"c", "bigint(20)", "NO", "", "1", ""},
 	}
 
 	require.Equal(expected, rows)
@@ -82,11 +82,11 @@ func TestShowColumnsWithIndexes(t *testing.T) {
 	require.NoError(err)
 
 	expected := []sql.Row{
-		{"a", "text", "NO", "PRI", "", ""},
-		{"b", "bigint", "YES", "UNI", "", ""},
-		{"c", "bigint", "NO", "", "1", ""},
-		{"d", "bigint", "YES", "MUL", "", ""},
-		{"e", "bigint", "NO", "", "1", ""},
+		{"a", "text", "NO", "PRI", nil, ""},
+		{"b", "bigint(20)", "YES", "MUL", nil, ""},
+		{"c", "bigint(20)", "NO", "", "1", ""},
+		{"d", "bigint(20)", "YES", "MUL", nil, ""},
+		{"e", "bigint(20)", "NO", "", "1", ""},
 	}
 
 	require.Equal(expected, rows)
@@ -141,9 +141,9 @@ func TestShowColumnsFull(t *testing.T) {
 	require.NoError(err)
 
 	expected := []sql.Row{
-		{"a", "text", "utf8mb4_0900_ai_ci", "NO", "PRI", "", "", "", ""},
-		{"b", "bigint", nil, "YES", "", "", "", "", ""},
-		{"c", "bigint", nil, "NO", "", "1", "", "", "a comment"},
+		{"a", "text", "utf8mb4_0900_ai_ci", "NO", "PRI", nil, "", "", ""},
+		{"b", "bigint(20)", nil, "YES", "", nil, "", "", ""},
+		{"c", "bigint(20)", nil, "NO", "", "1", "", "", "a comment"},
 	}
 
 	require.Equal(expected, rows)
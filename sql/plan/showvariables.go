@@ -2,6 +2,7 @@ package plan
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
@@ -58,7 +59,7 @@ func (*ShowVariables) Schema() sql.Schema {
 func (*ShowVariables) Children() []sql.Node { return nil }
 
 // RowIter implements the sql.Node interface.
-// The function returns an iterator for filtered variables (based on like pattern)
+// The function returns an iterator for filtered variables (based on like pattern), sorted by name
 func (sv *ShowVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	var (
 		rows []sql.Row
@@ -71,7 +72,14 @@ func (sv *ShowVariables) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, er
 		)
 	}
 
-	for k, v := range sv.config {
+	names := make([]string, 0, len(sv.config))
+	for k := range sv.config {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		v := sv.config[k]
 		if like != nil {
 			b, err := like.Eval(ctx, sql.NewRow(k, sv.pattern))
 			if err != nil {
@@ -0,0 +1,386 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	errors "gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+var (
+	// ErrSignalBadSQLState is returned when SIGNAL or RESIGNAL raise a condition with an invalid SQLSTATE.
+	ErrSignalBadSQLState = errors.NewKind("Bad SQLSTATE: '%s'")
+	// ErrSignalInvalidValue is returned when SIGNAL or RESIGNAL set a condition information item to an invalid value.
+	ErrSignalInvalidValue = errors.NewKind("Variable '%s' can't be set to the value of '%v'")
+	// ErrResignalWithoutCondition is returned by RESIGNAL when there's no condition to pass on.
+	ErrResignalWithoutCondition = errors.NewKind("RESIGNAL when handler not active")
+	// ErrInvalidConditionNumber is returned by GET DIAGNOSTICS for conditions that aren't in the diagnostics area.
+	ErrInvalidConditionNumber = errors.NewKind("Invalid condition number")
+)
+
+// The condition information items that SIGNAL and RESIGNAL can set, and GET DIAGNOSTICS read along with
+// ConditionReturnedSQLState.
+const (
+	ConditionClassOrigin       = "CLASS_ORIGIN"
+	ConditionSubclassOrigin    = "SUBCLASS_ORIGIN"
+	ConditionMessageText       = "MESSAGE_TEXT"
+	ConditionMysqlErrno        = "MYSQL_ERRNO"
+	ConditionConstraintCatalog = "CONSTRAINT_CATALOG"
+	ConditionConstraintSchema  = "CONSTRAINT_SCHEMA"
+	ConditionConstraintName    = "CONSTRAINT_NAME"
+	ConditionCatalogName       = "CATALOG_NAME"
+	ConditionSchemaName        = "SCHEMA_NAME"
+	ConditionTableName         = "TABLE_NAME"
+	ConditionColumnName        = "COLUMN_NAME"
+	ConditionCursorName        = "CURSOR_NAME"
+	ConditionReturnedSQLState  = "RETURNED_SQLSTATE"
+)
+
+// SignalInfoItems are the condition information items that SIGNAL and RESIGNAL can set.
+var SignalInfoItems = []string{
+	ConditionClassOrigin,
+	ConditionSubclassOrigin,
+	ConditionMessageText,
+	ConditionMysqlErrno,
+	ConditionConstraintCatalog,
+	ConditionConstraintSchema,
+	ConditionConstraintName,
+	ConditionCatalogName,
+	ConditionSchemaName,
+	ConditionTableName,
+	ConditionColumnName,
+	ConditionCursorName,
+}
+
+// The statement information items that GET DIAGNOSTICS can read.
+const (
+	DiagnosticsNumber   = "NUMBER"
+	DiagnosticsRowCount = "ROW_COUNT"
+)
+
+// SignalInfo is a condition information item set by SIGNAL or RESIGNAL.
+type SignalInfo struct {
+	// Name is the name of the item, one of SignalInfoItems.
+	Name  string
+	Value sql.Expression
+}
+
+// Signal is the SIGNAL or RESIGNAL statement, which raises a condition with an SQLSTATE and information items. The
+// condition is added to the conditions of the session, which SHOW WARNINGS and GET DIAGNOSTICS read. Conditions of
+// the warning class, whose SQLSTATE starts with 01, are just added, and the rest are also returned as errors.
+//
+// RESIGNAL passes on the condition being handled after modifying it. Since there are no condition handlers, that's
+// the last condition of the session, which is the first one of SHOW WARNINGS.
+type Signal struct {
+	// SQLState is the SQLSTATE of the condition, which can only be empty for RESIGNAL to keep the one of the
+	// condition it passes on.
+	SQLState string
+	Info     []SignalInfo
+	Resignal bool
+}
+
+var _ sql.Node = (*Signal)(nil)
+var _ sql.Expressioner = (*Signal)(nil)
+
+// NewSignal creates a new Signal node for SIGNAL.
+func NewSignal(sqlState string, info []SignalInfo) *Signal {
+	return &Signal{SQLState: sqlState, Info: info}
+}
+
+// NewResignal creates a new Signal node for RESIGNAL.
+func NewResignal(sqlState string, info []SignalInfo) *Signal {
+	return &Signal{SQLState: sqlState, Info: info, Resignal: true}
+}
+
+// Resolved implements the sql.Node interface.
+func (s *Signal) Resolved() bool {
+	return expressionsResolved(s.Expressions()...)
+}
+
+// Children implements the sql.Node interface.
+func (*Signal) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*Signal) Schema() sql.Schema { return nil }
+
+// Expressions implements the sql.Expressioner interface.
+func (s *Signal) Expressions() []sql.Expression {
+	exprs := make([]sql.Expression, len(s.Info))
+	for i, info := range s.Info {
+		exprs[i] = info.Value
+	}
+	return exprs
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (s *Signal) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(s.Info) {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(exprs), len(s.Info))
+	}
+
+	ns := *s
+	ns.Info = make([]SignalInfo, len(s.Info))
+	for i, info := range s.Info {
+		ns.Info[i] = SignalInfo{info.Name, exprs[i]}
+	}
+	return &ns, nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (s *Signal) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+	return s, nil
+}
+
+func (s *Signal) String() string {
+	str := "SIGNAL"
+	if s.Resignal {
+		str = "RESIGNAL"
+	}
+	if s.SQLState != "" {
+		str += fmt.Sprintf(" SQLSTATE '%s'", s.SQLState)
+	}
+
+	if len(s.Info) > 0 {
+		var items = make([]string, len(s.Info))
+		for i, info := range s.Info {
+			items[i] = fmt.Sprintf("%s = %s", info.Name, info.Value)
+		}
+		str += " SET " + strings.Join(items, ", ")
+	}
+	return str
+}
+
+// RowIter implements the sql.Node interface.
+func (s *Signal) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var condition sql.Warning
+	if s.Resignal {
+		warnings := ctx.Warnings()
+		if len(warnings) == 0 {
+			return nil, ErrResignalWithoutCondition.New()
+		}
+
+		condition = *warnings[0]
+		condition.Info = make(map[string]string)
+		for name, value := range warnings[0].Info {
+			condition.Info[name] = value
+		}
+	}
+
+	if s.SQLState != "" {
+		if !isValidSignalSQLState(s.SQLState) {
+			return nil, ErrSignalBadSQLState.New(s.SQLState)
+		}
+		condition.SQLState = s.SQLState
+	}
+
+	class := condition.SQLState
+	if len(class) > 2 {
+		class = class[:2]
+	}
+	condition.Level = "Error"
+	if class == "01" {
+		condition.Level = "Warning"
+	}
+
+	if !s.Resignal {
+		condition.Info = make(map[string]string)
+		switch class {
+		case "01":
+			condition.Code, condition.Message = 1642, "Unhandled user-defined warning condition"
+		case "02":
+			condition.Code, condition.Message = 1643, "Unhandled user-defined not found condition"
+		default:
+			condition.Code, condition.Message = 1644, "Unhandled user-defined exception condition"
+		}
+	}
+
+	for _, info := range s.Info {
+		if err := setConditionItem(ctx, &condition, info, row); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx.Session.Warn(&condition)
+	if condition.Level == "Error" {
+		return nil, &sql.ConditionError{Warning: &condition}
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+// isValidSignalSQLState returns whether the SQLSTATE given can be raised, which requires it to be five digits or
+// upper case letters that don't start with the class 00 of successful completion.
+func isValidSignalSQLState(state string) bool {
+	if len(state) != 5 || strings.HasPrefix(state, "00") {
+		return false
+	}
+	for _, r := range state {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func setConditionItem(ctx *sql.Context, condition *sql.Warning, info SignalInfo, row sql.Row) error {
+	v, err := info.Value.Eval(ctx, row)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return ErrSignalInvalidValue.New(info.Name, "NULL")
+	}
+
+	if info.Name == ConditionMysqlErrno {
+		code, err := sql.Int64.Convert(v)
+		if err != nil || code.(int64) < 1 || code.(int64) > 65534 {
+			return ErrSignalInvalidValue.New(info.Name, v)
+		}
+		condition.Code = int(code.(int64))
+		return nil
+	}
+
+	str, err := sql.LongText.Convert(v)
+	if err != nil {
+		return err
+	}
+	if info.Name == ConditionMessageText {
+		condition.Message = str.(string)
+	} else {
+		condition.Info[info.Name] = str.(string)
+	}
+	return nil
+}
+
+// GetDiagnostics is the GET DIAGNOSTICS statement, which sets user variables to the information items of the
+// statement, or of one of the conditions of the session, which are numbered in the order SHOW WARNINGS shows them.
+// It doesn't clear the conditions of the session.
+type GetDiagnostics struct {
+	// Condition is the number of the condition whose items are read, or nil to read the items of the statement.
+	Condition sql.Expression
+	// Targets are the user variables set to the values of the items.
+	Targets []*expression.UserVar
+	// Items are the names of the items read, which are DiagnosticsNumber and DiagnosticsRowCount for statements.
+	Items []string
+}
+
+var _ sql.Node = (*GetDiagnostics)(nil)
+var _ sql.Expressioner = (*GetDiagnostics)(nil)
+
+// NewGetDiagnostics creates a new GetDiagnostics node.
+func NewGetDiagnostics(condition sql.Expression, targets []*expression.UserVar, items []string) *GetDiagnostics {
+	return &GetDiagnostics{Condition: condition, Targets: targets, Items: items}
+}
+
+// Resolved implements the sql.Node interface.
+func (g *GetDiagnostics) Resolved() bool {
+	return expressionsResolved(g.Expressions()...)
+}
+
+// Children implements the sql.Node interface.
+func (*GetDiagnostics) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*GetDiagnostics) Schema() sql.Schema { return nil }
+
+// Expressions implements the sql.Expressioner interface.
+func (g *GetDiagnostics) Expressions() []sql.Expression {
+	if g.Condition == nil {
+		return nil
+	}
+	return []sql.Expression{g.Condition}
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (g *GetDiagnostics) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(g.Expressions()) {
+		return nil, sql.ErrInvalidChildrenNumber.New(g, len(exprs), len(g.Expressions()))
+	}
+
+	ng := *g
+	if len(exprs) > 0 {
+		ng.Condition = exprs[0]
+	}
+	return &ng, nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (g *GetDiagnostics) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(g, len(children), 0)
+	}
+	return g, nil
+}
+
+func (g *GetDiagnostics) String() string {
+	var items = make([]string, len(g.Items))
+	for i, item := range g.Items {
+		items[i] = fmt.Sprintf("%s = %s", g.Targets[i], item)
+	}
+
+	str := "GET DIAGNOSTICS "
+	if g.Condition != nil {
+		str += fmt.Sprintf("CONDITION %s ", g.Condition)
+	}
+	return str + strings.Join(items, ", ")
+}
+
+// RowIter implements the sql.Node interface.
+func (g *GetDiagnostics) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	warnings := ctx.Warnings()
+
+	var condition *sql.Warning
+	if g.Condition != nil {
+		v, err := g.Condition.Eval(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+
+		var n int64
+		if v != nil {
+			i, err := sql.Int64.Convert(v)
+			if err != nil {
+				return nil, ErrInvalidConditionNumber.New()
+			}
+			n = i.(int64)
+		}
+		if n < 1 || n > int64(len(warnings)) {
+			return nil, ErrInvalidConditionNumber.New()
+		}
+		condition = warnings[n-1]
+	}
+
+	for i, item := range g.Items {
+		var value interface{}
+		var typ sql.Type = sql.LongText
+		switch item {
+		case DiagnosticsNumber:
+			value, typ = int64(len(warnings)), sql.Int64
+		case DiagnosticsRowCount:
+			value, typ = ctx.GetLastQueryInfo(sql.RowCount), sql.Int64
+		case ConditionMysqlErrno:
+			value, typ = int64(condition.Code), sql.Int64
+		case ConditionMessageText:
+			value = condition.Message
+		case ConditionReturnedSQLState:
+			// Conditions without an SQLSTATE have the general error one
+			value = condition.SQLState
+			if value == "" {
+				value = "HY000"
+			}
+		default:
+			value = condition.Info[item]
+		}
+
+		if err := ctx.Set(ctx, g.Targets[i].Name, typ, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return sql.RowsToRowIter(), nil
+}
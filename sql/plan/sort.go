@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"container/heap"
 	"fmt"
 	"io"
 	"sort"
@@ -41,13 +42,16 @@ func (s SortOrder) String() string {
 	}
 }
 
-// NullOrdering represents how to order based on null values.
+// NullOrdering represents how to order based on null values. Like values, null values are sorted in the opposite
+// order when the order is descending.
 type NullOrdering byte
 
 const (
-	// NullsFirst puts the null values before any other values.
+	// NullsFirst sorts the null values before any other values, so they come first in ascending order and last in
+	// descending order, like MySQL does.
 	NullsFirst NullOrdering = iota
-	// NullsLast puts the null values after all other values.
+	// NullsLast sorts the null values after all other values, so they come last in ascending order and first in
+	// descending order.
 	NullsLast NullOrdering = 2
 )
 
@@ -61,6 +65,18 @@ type SortField struct {
 	NullOrdering NullOrdering
 }
 
+// String returns the column and order of the field, and the position of the null values if they aren't sorted before
+// any other values.
+func (s SortField) String() string {
+	if s.NullOrdering != NullsLast {
+		return fmt.Sprintf("%s %s", s.Column, s.Order)
+	}
+	if s.Order == Descending {
+		return fmt.Sprintf("%s %s NULLS FIRST", s.Column, s.Order)
+	}
+	return fmt.Sprintf("%s %s NULLS LAST", s.Column, s.Order)
+}
+
 func (s SortField) DebugString() string {
 	nullOrdering := "nullsFirst"
 	if s.NullOrdering == NullsLast {
@@ -104,7 +120,7 @@ func (s *Sort) String() string {
 	pr := sql.NewTreePrinter()
 	var fields = make([]string, len(s.SortFields))
 	for i, f := range s.SortFields {
-		fields[i] = fmt.Sprintf("%s %s", f.Column, f.Order)
+		fields[i] = f.String()
 	}
 	_ = pr.WriteNode("Sort(%s)", strings.Join(fields, ", "))
 	_ = pr.WriteChildren(s.Child.String())
@@ -158,6 +174,10 @@ func (s *Sort) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
 	return NewSort(fields, s.Child), nil
 }
 
+// minSpilledRunRows is the least number of rows sorts spill at a time once memory is exhausted, so they don't write a
+// run for every row while memory is being freed.
+const minSpilledRunRows = 1024
+
 type sortIter struct {
 	ctx        *sql.Context
 	s          *Sort
@@ -165,6 +185,10 @@ type sortIter struct {
 	childIter  sql.RowIter
 	sortedRows []sql.Row
 	idx        int
+	// runs are the sorted runs of rows spilled to the temporary store of the session, which are merged with the sorted
+	// rows left in memory.
+	runs   []*sql.TempRows
+	merged *mergeIter
 }
 
 func newSortIter(ctx *sql.Context, s *Sort, child sql.RowIter, row sql.Row) *sortIter {
@@ -186,6 +210,10 @@ func (i *sortIter) Next() (sql.Row, error) {
 		i.idx = 0
 	}
 
+	if i.merged != nil {
+		return i.merged.Next()
+	}
+
 	if i.idx >= len(i.sortedRows) {
 		return nil, io.EOF
 	}
@@ -196,13 +224,18 @@ func (i *sortIter) Next() (sql.Row, error) {
 
 func (i *sortIter) Close() error {
 	i.sortedRows = nil
-	return i.childIter.Close()
+	err := i.childIter.Close()
+	for _, run := range i.runs {
+		if e := run.Close(); err == nil {
+			err = e
+		}
+	}
+	i.runs = nil
+	return err
 }
 
 func (i *sortIter) computeSortedRows() error {
-	cache, dispose := i.ctx.Memory.NewRowsCache()
-	defer dispose()
-
+	var rows []sql.Row
 	for {
 		row, err := i.childIter.Next()
 
@@ -213,12 +246,46 @@ func (i *sortIter) computeSortedRows() error {
 			return err
 		}
 
-		if err := cache.Add(row); err != nil {
+		rows = append(rows, row)
+
+		// Once memory is exhausted, the rows read so far are sorted and spilled to the temporary store, and merged with
+		// the rest of the rows once all of them are read
+		if len(rows) >= minSpilledRunRows && !i.ctx.Memory.HasAvailable() {
+			if err := i.spill(rows); err != nil {
+				return err
+			}
+			rows = nil
+		}
+	}
+
+	if err := i.sortRows(rows); err != nil {
+		return err
+	}
+
+	if len(i.runs) == 0 {
+		i.sortedRows = rows
+		return nil
+	}
+
+	runs := make([]sql.RowIter, 0, len(i.runs)+1)
+	for _, run := range i.runs {
+		iter, err := run.RowIter()
+		if err != nil {
 			return err
 		}
+		runs = append(runs, iter)
 	}
+	runs = append(runs, sql.RowsToRowIter(rows...))
 
-	rows := cache.Get()
+	merged, err := newMergeIter(i.ctx, i.s.SortFields, runs)
+	if err != nil {
+		return err
+	}
+	i.merged = merged
+	return nil
+}
+
+func (i *sortIter) sortRows(rows []sql.Row) error {
 	sorter := &Sorter{
 		SortFields: i.s.SortFields,
 		Rows:       rows,
@@ -226,13 +293,114 @@ func (i *sortIter) computeSortedRows() error {
 		Ctx:        i.ctx,
 	}
 	sort.Stable(sorter)
-	if sorter.LastError != nil {
-		return sorter.LastError
+	return sorter.LastError
+}
+
+// spill sorts the rows given and writes them to a new run in the temporary store.
+func (i *sortIter) spill(rows []sql.Row) error {
+	if err := i.sortRows(rows); err != nil {
+		return err
+	}
+
+	run, err := sql.NewTempRows(i.ctx, i.s.Child.Schema())
+	if err != nil {
+		return err
+	}
+	i.runs = append(i.runs, run)
+
+	for _, row := range rows {
+		if err := run.Add(row); err != nil {
+			return err
+		}
 	}
-	i.sortedRows = rows
 	return nil
 }
 
+// mergeIter merges sorted runs of rows, keeping the next row of every run in a heap. Rows that sort the same are
+// returned in the order of their runs, so merging the runs of a stable sort is stable too.
+type mergeIter struct {
+	sorter *Sorter
+	runs   []sql.RowIter
+	order  []int
+}
+
+func newMergeIter(ctx *sql.Context, sortFields []SortField, runs []sql.RowIter) (*mergeIter, error) {
+	m := &mergeIter{sorter: &Sorter{SortFields: sortFields, Ctx: ctx}}
+	for n, run := range runs {
+		row, err := run.Next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m.sorter.Rows = append(m.sorter.Rows, row)
+		m.runs = append(m.runs, run)
+		m.order = append(m.order, n)
+	}
+
+	heap.Init(m)
+	return m, m.sorter.LastError
+}
+
+func (m *mergeIter) Next() (sql.Row, error) {
+	if len(m.runs) == 0 {
+		return nil, io.EOF
+	}
+
+	row := m.sorter.Rows[0]
+	next, err := m.runs[0].Next()
+	if err == io.EOF {
+		heap.Pop(m)
+	} else if err != nil {
+		return nil, err
+	} else {
+		m.sorter.Rows[0] = next
+		heap.Fix(m, 0)
+	}
+
+	if m.sorter.LastError != nil {
+		return nil, m.sorter.LastError
+	}
+	return row, nil
+}
+
+func (m *mergeIter) Len() int {
+	return len(m.runs)
+}
+
+func (m *mergeIter) Less(i, j int) bool {
+	if m.sorter.Less(i, j) {
+		return true
+	}
+	if m.sorter.Less(j, i) {
+		return false
+	}
+	return m.order[i] < m.order[j]
+}
+
+func (m *mergeIter) Swap(i, j int) {
+	m.sorter.Swap(i, j)
+	m.runs[i], m.runs[j] = m.runs[j], m.runs[i]
+	m.order[i], m.order[j] = m.order[j], m.order[i]
+}
+
+// Push implements the heap.Interface interface. Runs are only removed from the heap once they're done.
+func (m *mergeIter) Push(interface{}) {
+	panic("runs can't be added to a merge")
+}
+
+// Pop implements the heap.Interface interface.
+func (m *mergeIter) Pop() interface{} {
+	last := len(m.runs) - 1
+	run := m.runs[last]
+	m.sorter.Rows = m.sorter.Rows[:last]
+	m.runs = m.runs[:last]
+	m.order = m.order[:last]
+	return run
+}
+
 type Sorter struct {
 	SortFields []SortField
 	Rows       []sql.Row
@@ -253,6 +421,15 @@ func (s *Sorter) Less(i, j int) bool {
 		return false
 	}
 
+	// Sorts of many rows take a while after all of them have been read, so they may be interrupted while rows are
+	// compared
+	if s.Ctx != nil {
+		if err := s.Ctx.Interrupted(); err != nil {
+			s.LastError = err
+			return false
+		}
+	}
+
 	a := s.Rows[i]
 	b := s.Rows[j]
 	for _, sf := range s.SortFields {
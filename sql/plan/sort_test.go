@@ -1,8 +1,13 @@
 package plan
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/dolthub/go-mysql-server/memory"
 	"github.com/dolthub/go-mysql-server/sql"
@@ -280,3 +285,97 @@ func TestSortDescending(t *testing.T) {
 	require.NoError(err)
 	require.Equal(expected, actual)
 }
+
+func TestSorterInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	var rows []sql.Row
+	for i := 0; i < 100; i++ {
+		rows = append(rows, sql.NewRow(int64(100-i)))
+	}
+
+	// The query is interrupted after its rows have been read, while they're sorted
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sorter := &Sorter{
+		SortFields: []SortField{{Column: expression.NewGetField(0, sql.Int64, "i", false)}},
+		Rows:       rows,
+		Ctx:        sql.NewContext(ctx),
+	}
+	sort.Stable(sorter)
+	require.Equal(context.Canceled, sorter.LastError)
+}
+
+// exhaustedMemory is a memory reporter for which memory is always exhausted.
+type exhaustedMemory struct{}
+
+func (exhaustedMemory) MaxMemory() uint64  { return 1 }
+func (exhaustedMemory) UsedMemory() uint64 { return 2 }
+
+type countingTempStore struct {
+	sql.TempStore
+	created int
+}
+
+func (s *countingTempStore) Create(ctx *sql.Context) (sql.TempFile, error) {
+	s.created++
+	return s.TempStore.Create(ctx)
+}
+
+func TestSortSpilled(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "sort")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	store := &countingTempStore{TempStore: sql.NewFileTempStore(dir)}
+	ctx := sql.NewContext(context.Background(), sql.WithMemoryManager(sql.NewMemoryManager(exhaustedMemory{})))
+	ctx.SetTempStore(store)
+
+	schema := sql.Schema{
+		{Name: "k", Type: sql.Int64, Nullable: true},
+		{Name: "seq", Type: sql.Int64},
+		{Name: "s", Type: sql.Text},
+		// Values of these types aren't converted back from their text encoding as they are
+		{Name: "b", Type: sql.MustCreateBitType(16)},
+		{Name: "d", Type: sql.Datetime},
+		{Name: "j", Type: sql.JSON},
+	}
+	child := memory.NewTable("test", schema)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []sql.Row
+	for i := 0; i < 2*minSpilledRunRows+100; i++ {
+		var k interface{} = int64(i * 7919 % 100)
+		if i%50 == 0 {
+			k = nil
+		}
+		row := sql.NewRow(k, int64(i), fmt.Sprintf("row %d", i), uint64(i), start.Add(time.Duration(i)*time.Second),
+			[]byte(fmt.Sprintf(`{"i":%d}`, i)))
+		rows = append(rows, row)
+		require.NoError(child.Insert(ctx, row))
+	}
+
+	s := NewSort([]SortField{
+		{Column: expression.NewGetField(0, sql.Int64, "k", true), Order: Descending, NullOrdering: NullsFirst},
+	}, NewResolvedTable(child))
+
+	// Rows with the same key keep the order they were read in, even when they're in different runs
+	expected := append([]sql.Row(nil), rows...)
+	sort.SliceStable(expected, func(i, j int) bool {
+		a, b := expected[i][0], expected[j][0]
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.(int64) > b.(int64)
+	})
+
+	actual, err := sql.NodeToRows(ctx, s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+	require.Equal(2, store.created)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(err)
+	require.Empty(files)
+}
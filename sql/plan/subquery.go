@@ -16,8 +16,12 @@ package plan
 
 import (
 	"fmt"
+	"io"
 	"sync"
+	"time"
 
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/spf13/cast"
 	errors "gopkg.in/src-d/go-errors.v1"
 
 	"github.com/dolthub/go-mysql-server/sql"
@@ -39,6 +43,8 @@ type Subquery struct {
 	resultsCached bool
 	// Cached results, if any
 	cache interface{}
+	// Cached set of the results for IN expressions, if any
+	cachedSet *subqueryValueSet
 
 	cacheMu sync.Mutex
 }
@@ -168,7 +174,7 @@ func (s *Subquery) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
 func prependRowInPlan(row sql.Row) func(n sql.Node) (sql.Node, error) {
 	return func(n sql.Node) (sql.Node, error) {
 		switch n := n.(type) {
-		case *Project, sql.Table:
+		case *Project, sql.Table, *SubqueryAlias:
 			return &prependNode{
 				UnaryNode: UnaryNode{Child: n},
 				row:       row,
@@ -179,7 +185,8 @@ func prependRowInPlan(row sql.Row) func(n sql.Node) (sql.Node, error) {
 	}
 }
 
-// EvalMultiple returns all rows returned by a subquery.
+// EvalMultiple returns all rows returned by a subquery. The results of subqueries that can be cached are only kept if
+// there's memory available for them.
 func (s *Subquery) EvalMultiple(ctx *sql.Context, row sql.Row) ([]interface{}, error) {
 	s.cacheMu.Lock()
 	cached := s.resultsCached
@@ -188,6 +195,23 @@ func (s *Subquery) EvalMultiple(ctx *sql.Context, row sql.Row) ([]interface{}, e
 		return s.cache.([]interface{}), nil
 	}
 
+	result, err := s.evalMultiple(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.canCacheResults && ctx.Memory.HasAvailable() {
+		s.cacheMu.Lock()
+		if s.resultsCached == false {
+			s.cache, s.resultsCached = result, true
+		}
+		s.cacheMu.Unlock()
+	}
+
+	return result, nil
+}
+
+func (s *Subquery) evalMultiple(ctx *sql.Context, row sql.Row) ([]interface{}, error) {
 	q, err := TransformUp(s.Query, prependRowInPlan(row))
 	if err != nil {
 		return nil, err
@@ -219,9 +243,42 @@ func (s *Subquery) EvalMultiple(ctx *sql.Context, row sql.Row) ([]interface{}, e
 		result[i] = row[col]
 	}
 
+	return result, nil
+}
+
+// HasResultRow returns whether the subquery returns any row. The rows of the subquery aren't read after the first one,
+// and the iterator is closed as soon as it's found.
+func (s *Subquery) HasResultRow(ctx *sql.Context, row sql.Row) (bool, error) {
+	s.cacheMu.Lock()
+	cached := s.resultsCached
+	s.cacheMu.Unlock()
+	if cached {
+		return s.cache.(bool), nil
+	}
+
+	q, err := TransformUp(s.Query, prependRowInPlan(row))
+	if err != nil {
+		return false, err
+	}
+
+	iter, err := q.RowIter(ctx, row)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = iter.Next()
+	if err != nil && err != io.EOF {
+		_ = iter.Close()
+		return false, err
+	}
+	if err := iter.Close(); err != nil {
+		return false, err
+	}
+
+	result := err == nil
 	if s.canCacheResults {
 		s.cacheMu.Lock()
-		if s.resultsCached == false {
+		if !s.resultsCached {
 			s.cache, s.resultsCached = result, true
 		}
 		s.cacheMu.Unlock()
@@ -230,6 +287,152 @@ func (s *Subquery) EvalMultiple(ctx *sql.Context, row sql.Row) ([]interface{}, e
 	return result, nil
 }
 
+// subqueryValueSet is the set of the values returned by a subquery, keyed by the values they are compared as.
+type subqueryValueSet struct {
+	keys    map[interface{}]struct{}
+	hasNull bool
+	empty   bool
+}
+
+// contains returns whether the set contains the value given, with the result of IN for it, or false if the value
+// can't be compared as a key of the subquery type.
+func (vs *subqueryValueSet) contains(typ sql.Type, v interface{}) (interface{}, bool) {
+	if vs.empty {
+		return false, true
+	}
+	if v == nil {
+		return nil, true
+	}
+
+	key, err := subqueryValueKey(typ, v)
+	if err != nil {
+		return nil, false
+	}
+	if _, ok := vs.keys[key]; ok {
+		return true, true
+	}
+	if vs.hasNull {
+		return nil, true
+	}
+	return false, true
+}
+
+// valueSet returns the results of the subquery as a set of values, or nil if they can't be cached or compared by key.
+// The set is only kept if there's memory available for it, otherwise it's built again for every row.
+func (s *Subquery) valueSet(ctx *sql.Context, row sql.Row) (*subqueryValueSet, error) {
+	typ := s.Type()
+	if !s.canCacheResults || !isSubqueryKeyType(typ) {
+		return nil, nil
+	}
+
+	s.cacheMu.Lock()
+	set := s.cachedSet
+	s.cacheMu.Unlock()
+	if set != nil {
+		return set, nil
+	}
+
+	values, err := s.evalMultiple(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	set = &subqueryValueSet{keys: make(map[interface{}]struct{}, len(values)), empty: len(values) == 0}
+	for _, val := range values {
+		val, err = typ.Convert(val)
+		if err != nil {
+			return nil, err
+		}
+		if val == nil {
+			set.hasNull = true
+			continue
+		}
+
+		key, err := subqueryValueKey(typ, val)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[key] = struct{}{}
+	}
+
+	if ctx.Memory.HasAvailable() {
+		s.cacheMu.Lock()
+		if s.cachedSet == nil {
+			s.cachedSet = set
+		}
+		s.cacheMu.Unlock()
+	}
+
+	return set, nil
+}
+
+// isSubqueryKeyType returns whether the values of the type given are equal exactly when their keys, as returned by
+// subqueryValueKey, are.
+func isSubqueryKeyType(typ sql.Type) bool {
+	switch typ.(type) {
+	case sql.DecimalType, sql.DatetimeType, sql.TimeType, sql.BitType, sql.EnumType, sql.SetType:
+		return true
+	default:
+		return sql.IsNumber(typ) || typ == sql.Year || (sql.IsText(typ) && typ != sql.JSON)
+	}
+}
+
+// subqueryInstant is the key of time values, which are equal when they are the same instant in any location.
+type subqueryInstant struct {
+	sec  int64
+	nsec int
+}
+
+// subqueryValueKey returns the value given as the key it's compared by with the type given, which is one of the types
+// isSubqueryKeyType returns true for.
+func subqueryValueKey(typ sql.Type, v interface{}) (interface{}, error) {
+	switch t := typ.(type) {
+	case sql.DecimalType:
+		dec, err := t.ConvertToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		// Decimals are printed without trailing zeros, so equal decimals have the same representation
+		return dec.Decimal.String(), nil
+	case sql.DatetimeType:
+		tm, ok := v.(time.Time)
+		if !ok {
+			converted, err := t.Convert(v)
+			if err != nil {
+				return nil, err
+			}
+			tm = converted.(time.Time)
+		} else if t.Type() == sqltypes.Date {
+			tm = tm.Truncate(24 * time.Hour)
+		}
+		return subqueryInstant{tm.Unix(), tm.Nanosecond()}, nil
+	case sql.TimeType:
+		return t.Marshal(v)
+	case sql.EnumType:
+		return t.ConvertToIndex(v)
+	case sql.SetType:
+		return t.Marshal(v)
+	case sql.BitType:
+		return t.Convert(v)
+	}
+
+	switch {
+	case typ == sql.Year:
+		return typ.Convert(v)
+	case sqltypes.IsFloat(typ.Type()):
+		return cast.ToFloat64E(v)
+	case sqltypes.IsUnsigned(typ.Type()):
+		return cast.ToUint64E(v)
+	case sqltypes.IsSigned(typ.Type()):
+		return cast.ToInt64E(v)
+	default:
+		if str, ok := v.(string); ok {
+			return str, nil
+		}
+		return typ.Convert(v)
+	}
+}
+
 // IsNullable implements the Expression interface.
 func (s *Subquery) IsNullable() bool {
 	return s.Query.Schema()[0].Nullable
@@ -10,11 +10,21 @@ type SubqueryAlias struct {
 	name           string
 	schema         sql.Schema
 	TextDefinition string
+	// Columns are the names the columns of the subquery are renamed to, if given, as they are for common table
+	// expressions with column names. The analyzer renames them once it has analyzed the subquery.
+	Columns []string
 }
 
 // NewSubqueryAlias creates a new SubqueryAlias node.
 func NewSubqueryAlias(name, textDefinition string, node sql.Node) *SubqueryAlias {
-	return &SubqueryAlias{UnaryNode{Child: node}, name, nil, textDefinition}
+	return &SubqueryAlias{UnaryNode: UnaryNode{Child: node}, name: name, TextDefinition: textDefinition}
+}
+
+// WithColumns returns a copy of the node whose columns are renamed to those given.
+func (n *SubqueryAlias) WithColumns(columns []string) *SubqueryAlias {
+	nn := *n
+	nn.Columns = columns
+	return &nn
 }
 
 // Returns the view wrapper for this subquery
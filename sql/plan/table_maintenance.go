@@ -0,0 +1,390 @@
+package plan
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ChecksumTable is the CHECKSUM TABLE statement, which returns a checksum of the rows of each of its tables. Tables
+// that implement sql.ChecksumTable compute their own checksum, and the rows of the rest are read to compute it, which
+// is the sum of the CRC-32 checksums of the values of every row, so it doesn't depend on the order of the rows.
+type ChecksumTable struct {
+	Tables []sql.Node
+	// Quick is whether only the checksums computed by the tables are returned, and NULL for the rest of them.
+	Quick bool
+	// Extended is whether the rows of every table are read, even if it can compute its own checksum.
+	Extended bool
+}
+
+var _ sql.Node = (*ChecksumTable)(nil)
+
+// NewChecksumTable creates a new ChecksumTable node.
+func NewChecksumTable(tables []sql.Node, quick, extended bool) *ChecksumTable {
+	return &ChecksumTable{Tables: tables, Quick: quick, Extended: extended}
+}
+
+var checksumTableSchema = sql.Schema{
+	{Name: "Table", Type: sql.LongText},
+	{Name: "Checksum", Type: sql.Uint64, Nullable: true},
+}
+
+// Children implements the sql.Node interface.
+func (c *ChecksumTable) Children() []sql.Node { return c.Tables }
+
+// Resolved implements the sql.Node interface.
+func (c *ChecksumTable) Resolved() bool { return nodesResolved(c.Tables) }
+
+// Schema implements the sql.Node interface.
+func (c *ChecksumTable) Schema() sql.Schema { return checksumTableSchema }
+
+// RowIter implements the sql.Node interface.
+func (c *ChecksumTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.ChecksumTable")
+	defer span.Finish()
+
+	var rows []sql.Row
+	for _, n := range c.Tables {
+		rt := maintainedTable(n)
+		var checksum interface{}
+		if t, ok := n.(*ResolvedTable); ok && !c.Extended {
+			if ct, ok := t.Table.(sql.ChecksumTable); ok {
+				sum, err := ct.Checksum(ctx)
+				if err != nil {
+					return nil, err
+				}
+				checksum = sum
+			}
+		}
+
+		if checksum == nil && !c.Quick {
+			sum, err := checksumRows(ctx, n)
+			if err != nil {
+				return nil, err
+			}
+			checksum = sum
+		}
+		rows = append(rows, sql.NewRow(maintainedTableName(ctx, rt), checksum))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+// checksumRows returns the sum of the CRC-32 checksums of the rows of the node given.
+func checksumRows(ctx *sql.Context, n sql.Node) (uint64, error) {
+	iter, err := n.RowIter(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	schema := n.Schema()
+	var sum uint64
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = iter.Close()
+			return 0, err
+		}
+
+		h := crc32.NewIEEE()
+		for i, v := range row {
+			// NULL values are hashed as a marker that no value starts with, since the length of values comes first
+			if v == nil {
+				_, _ = h.Write([]byte{'-'})
+				continue
+			}
+
+			value, err := schema[i].Type.SQL(v)
+			if err != nil {
+				_ = iter.Close()
+				return 0, err
+			}
+			raw := value.Raw()
+			_, _ = h.Write(strconv.AppendInt(nil, int64(len(raw)), 10))
+			_, _ = h.Write([]byte{':'})
+			_, _ = h.Write(raw)
+		}
+		sum += uint64(h.Sum32())
+	}
+	return sum, iter.Close()
+}
+
+func (c *ChecksumTable) String() string {
+	p := sql.NewTreePrinter()
+	_ = p.WriteNode("ChecksumTable")
+	_ = p.WriteChildren(nodeStrings(c.Tables)...)
+	return p.String()
+}
+
+// WithChildren implements the sql.Node interface.
+func (c *ChecksumTable) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != len(c.Tables) {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), len(c.Tables))
+	}
+
+	nc := *c
+	nc.Tables = children
+	return &nc, nil
+}
+
+// CheckTable is the CHECK TABLE statement, which checks the data of each of its tables for errors. Tables that don't
+// implement sql.CheckableTable are reported to have no errors.
+type CheckTable struct {
+	Tables []sql.Node
+}
+
+var _ sql.Node = (*CheckTable)(nil)
+
+// NewCheckTable creates a new CheckTable node.
+func NewCheckTable(tables []sql.Node) *CheckTable {
+	return &CheckTable{Tables: tables}
+}
+
+// tableMaintenanceSchema is the schema of the results of CHECK TABLE, OPTIMIZE TABLE and ANALYZE TABLE statements.
+var tableMaintenanceSchema = sql.Schema{
+	{Name: "Table", Type: sql.LongText},
+	{Name: "Op", Type: sql.LongText},
+	{Name: "Msg_type", Type: sql.LongText},
+	{Name: "Msg_text", Type: sql.LongText},
+}
+
+// Children implements the sql.Node interface.
+func (c *CheckTable) Children() []sql.Node { return c.Tables }
+
+// Resolved implements the sql.Node interface.
+func (c *CheckTable) Resolved() bool { return nodesResolved(c.Tables) }
+
+// Schema implements the sql.Node interface.
+func (c *CheckTable) Schema() sql.Schema { return tableMaintenanceSchema }
+
+// RowIter implements the sql.Node interface.
+func (c *CheckTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.CheckTable")
+	defer span.Finish()
+
+	var rows []sql.Row
+	for _, n := range c.Tables {
+		rt := maintainedTable(n)
+		name := maintainedTableName(ctx, rt)
+
+		var problems []string
+		if t, ok := underlyingTable(rt).(sql.CheckableTable); ok {
+			var err error
+			if problems, err = t.Check(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, problem := range problems {
+			rows = append(rows, sql.NewRow(name, "check", "error", problem))
+		}
+		if len(problems) > 0 {
+			rows = append(rows, sql.NewRow(name, "check", "error", "Corrupt"))
+		} else {
+			rows = append(rows, sql.NewRow(name, "check", "status", "OK"))
+		}
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (c *CheckTable) String() string {
+	p := sql.NewTreePrinter()
+	_ = p.WriteNode("CheckTable")
+	_ = p.WriteChildren(nodeStrings(c.Tables)...)
+	return p.String()
+}
+
+// WithChildren implements the sql.Node interface.
+func (c *CheckTable) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != len(c.Tables) {
+		return nil, sql.ErrInvalidChildrenNumber.New(c, len(children), len(c.Tables))
+	}
+	return NewCheckTable(children), nil
+}
+
+// OptimizeTable is the OPTIMIZE TABLE statement, which reorganizes the data of each of its tables. Tables that don't
+// implement sql.OptimizableTable are left as they are, with a note in the results.
+type OptimizeTable struct {
+	Tables []sql.Node
+}
+
+var _ sql.Node = (*OptimizeTable)(nil)
+
+// NewOptimizeTable creates a new OptimizeTable node.
+func NewOptimizeTable(tables []sql.Node) *OptimizeTable {
+	return &OptimizeTable{Tables: tables}
+}
+
+// Children implements the sql.Node interface.
+func (o *OptimizeTable) Children() []sql.Node { return o.Tables }
+
+// Resolved implements the sql.Node interface.
+func (o *OptimizeTable) Resolved() bool { return nodesResolved(o.Tables) }
+
+// Schema implements the sql.Node interface.
+func (o *OptimizeTable) Schema() sql.Schema { return tableMaintenanceSchema }
+
+// RowIter implements the sql.Node interface.
+func (o *OptimizeTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.OptimizeTable")
+	defer span.Finish()
+
+	var rows []sql.Row
+	for _, n := range o.Tables {
+		rt := maintainedTable(n)
+		name := maintainedTableName(ctx, rt)
+
+		t, ok := underlyingTable(rt).(sql.OptimizableTable)
+		if !ok {
+			rows = append(rows, sql.NewRow(name, "optimize", "note", "The storage engine for the table doesn't support optimize"))
+			continue
+		}
+
+		if err := t.Optimize(ctx); err != nil {
+			return nil, err
+		}
+		rows = append(rows, sql.NewRow(name, "optimize", "status", "OK"))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (o *OptimizeTable) String() string {
+	p := sql.NewTreePrinter()
+	_ = p.WriteNode("OptimizeTable")
+	_ = p.WriteChildren(nodeStrings(o.Tables)...)
+	return p.String()
+}
+
+// WithChildren implements the sql.Node interface.
+func (o *OptimizeTable) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != len(o.Tables) {
+		return nil, sql.ErrInvalidChildrenNumber.New(o, len(children), len(o.Tables))
+	}
+	return NewOptimizeTable(children), nil
+}
+
+// AnalyzeTable is the ANALYZE TABLE statement, which updates the statistics of each of its tables. Tables that don't
+// implement sql.AnalyzableTable are left as they are, with a note in the results.
+type AnalyzeTable struct {
+	Tables []sql.Node
+}
+
+var _ sql.Node = (*AnalyzeTable)(nil)
+
+// NewAnalyzeTable creates a new AnalyzeTable node.
+func NewAnalyzeTable(tables []sql.Node) *AnalyzeTable {
+	return &AnalyzeTable{Tables: tables}
+}
+
+// Children implements the sql.Node interface.
+func (a *AnalyzeTable) Children() []sql.Node { return a.Tables }
+
+// Resolved implements the sql.Node interface.
+func (a *AnalyzeTable) Resolved() bool { return nodesResolved(a.Tables) }
+
+// Schema implements the sql.Node interface.
+func (a *AnalyzeTable) Schema() sql.Schema { return tableMaintenanceSchema }
+
+// RowIter implements the sql.Node interface.
+func (a *AnalyzeTable) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.AnalyzeTable")
+	defer span.Finish()
+
+	var rows []sql.Row
+	for _, n := range a.Tables {
+		rt := maintainedTable(n)
+		name := maintainedTableName(ctx, rt)
+
+		t, ok := underlyingTable(rt).(sql.AnalyzableTable)
+		if !ok {
+			rows = append(rows, sql.NewRow(name, "analyze", "note", "The storage engine for the table doesn't support analyze"))
+			continue
+		}
+
+		if err := t.Analyze(ctx); err != nil {
+			return nil, err
+		}
+		rows = append(rows, sql.NewRow(name, "analyze", "status", "OK"))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (a *AnalyzeTable) String() string {
+	p := sql.NewTreePrinter()
+	_ = p.WriteNode("AnalyzeTable")
+	_ = p.WriteChildren(nodeStrings(a.Tables)...)
+	return p.String()
+}
+
+// WithChildren implements the sql.Node interface.
+func (a *AnalyzeTable) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != len(a.Tables) {
+		return nil, sql.ErrInvalidChildrenNumber.New(a, len(children), len(a.Tables))
+	}
+	return NewAnalyzeTable(children), nil
+}
+
+func nodesResolved(nodes []sql.Node) bool {
+	for _, n := range nodes {
+		if !n.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeStrings(nodes []sql.Node) []string {
+	result := make([]string, len(nodes))
+	for i, n := range nodes {
+		result[i] = n.String()
+	}
+	return result
+}
+
+// maintainedTable returns the table of a table maintenance statement in the node given, which is the table itself or
+// the table wrapped by the nodes added by the analyzer, such as the filters of row policies.
+func maintainedTable(n sql.Node) *ResolvedTable {
+	var rt *ResolvedTable
+	Inspect(n, func(n sql.Node) bool {
+		if t, ok := n.(*ResolvedTable); ok && rt == nil {
+			rt = t
+		}
+		return rt == nil
+	})
+	return rt
+}
+
+// maintainedTableName returns the name of the table given as it's shown in the results of table maintenance
+// statements, qualified by the name of its database.
+func maintainedTableName(ctx *sql.Context, rt *ResolvedTable) string {
+	if rt == nil {
+		return ""
+	}
+
+	db := rt.Database
+	if db == "" {
+		db = ctx.GetCurrentDatabase()
+	}
+	return fmt.Sprintf("%s.%s", db, rt.Name())
+}
+
+// underlyingTable returns the table given, or the table wrapped by it if it's a sql.TableWrapper.
+func underlyingTable(rt *ResolvedTable) sql.Table {
+	if rt == nil {
+		return nil
+	}
+
+	t := rt.Table
+	for {
+		w, ok := t.(sql.TableWrapper)
+		if !ok {
+			return t
+		}
+		t = w.Underlying()
+	}
+}
@@ -0,0 +1,128 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestChecksumTable(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	schema := sql.Schema{
+		{Name: "i", Type: sql.Int64, Source: "t", Nullable: true},
+		{Name: "s", Type: sql.Text, Source: "t", Nullable: true},
+	}
+	newTable := func(numPartitions int, rows ...sql.Row) *memory.Table {
+		table := memory.NewPartitionedTable("t", schema, numPartitions)
+		for _, row := range rows {
+			require.NoError(table.Insert(ctx, row))
+		}
+		return table
+	}
+
+	checksum := func(node *ChecksumTable) sql.Row {
+		iter, err := node.RowIter(ctx, nil)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		require.Len(rows, 1)
+		return rows[0]
+	}
+
+	// The checksum doesn't depend on the order of the rows or their partitions
+	a := newTable(1, sql.NewRow(int64(1), "a"), sql.NewRow(nil, "b"), sql.NewRow(int64(2), nil))
+	b := newTable(2, sql.NewRow(int64(2), nil), sql.NewRow(int64(1), "a"), sql.NewRow(nil, "b"))
+	c := newTable(1, sql.NewRow(int64(1), "a"), sql.NewRow(int64(2), "b"), sql.NewRow(nil, nil))
+	sumA := checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(a, "mydb")}, false, false))
+	require.Equal("mydb.t", sumA[0])
+	require.Equal(sumA, checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(b, "mydb")}, false, false)))
+	require.NotEqual(sumA, checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(c, "mydb")}, false, false)))
+
+	// Tables that compute their own checksum are only read for extended checksums
+	own := &checksumTable{a}
+	require.Equal(sql.NewRow("mydb.t", uint64(42)), checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(own, "mydb")}, false, false)))
+	require.Equal(sumA, checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(own, "mydb")}, false, true)))
+	require.Equal(sql.NewRow("mydb.t", nil), checksum(NewChecksumTable([]sql.Node{NewResolvedTableInDatabase(a, "mydb")}, true, false)))
+}
+
+func TestCheckTable(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext().WithCurrentDB("mydb")
+
+	node := NewCheckTable([]sql.Node{
+		NewResolvedTable(&checkableTable{memory.NewTable("a", nil), []string{"bad row"}}),
+		NewResolvedTable(plainTable{memory.NewTable("b", nil)}),
+	})
+	iter, err := node.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"mydb.a", "check", "error", "bad row"},
+		{"mydb.a", "check", "error", "Corrupt"},
+		{"mydb.b", "check", "status", "OK"},
+	}, rows)
+}
+
+func TestOptimizeTable(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext().WithCurrentDB("mydb")
+
+	node := NewOptimizeTable([]sql.Node{
+		NewResolvedTable(memory.NewTable("a", nil)),
+		NewResolvedTable(plainTable{memory.NewTable("b", nil)}),
+	})
+	iter, err := node.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"mydb.a", "optimize", "status", "OK"},
+		{"mydb.b", "optimize", "note", "The storage engine for the table doesn't support optimize"},
+	}, rows)
+}
+
+func TestAnalyzeTable(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext().WithCurrentDB("mydb")
+
+	node := NewAnalyzeTable([]sql.Node{
+		NewResolvedTable(memory.NewTable("a", nil)),
+		NewResolvedTable(plainTable{memory.NewTable("b", nil)}),
+	})
+	iter, err := node.RowIter(ctx, nil)
+	require.NoError(err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		{"mydb.a", "analyze", "status", "OK"},
+		{"mydb.b", "analyze", "note", "The storage engine for the table doesn't support analyze"},
+	}, rows)
+}
+
+// plainTable is a table that implements none of the optional interfaces of tables.
+type plainTable struct {
+	sql.Table
+}
+
+type checksumTable struct {
+	sql.Table
+}
+
+func (*checksumTable) Checksum(*sql.Context) (uint64, error) {
+	return 42, nil
+}
+
+type checkableTable struct {
+	sql.Table
+	problems []string
+}
+
+func (t *checkableTable) Check(*sql.Context) ([]string, error) {
+	return t.problems, nil
+}
@@ -2,11 +2,44 @@ package plan
 
 import "github.com/dolthub/go-mysql-server/sql"
 
-// Commit commits the changes performed in a transaction. This is provided just for compatibility with SQL clients and
-// is a no-op.
+// StartTransaction is BEGIN or START TRANSACTION, which starts a transaction in the session, after committing the one
+// it was in. The engine starts and ends the transactions of sessions once their statements finish, so the node itself
+// does nothing.
+type StartTransaction struct{}
+
+// NewStartTransaction creates a new StartTransaction node.
+func NewStartTransaction() *StartTransaction { return new(StartTransaction) }
+
+// RowIter implements the sql.Node interface.
+func (*StartTransaction) RowIter(*sql.Context, sql.Row) (sql.RowIter, error) {
+	return sql.RowsToRowIter(), nil
+}
+
+func (*StartTransaction) String() string { return "START TRANSACTION" }
+
+// WithChildren implements the Node interface.
+func (s *StartTransaction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(s, len(children), 0)
+	}
+
+	return s, nil
+}
+
+// Resolved implements the sql.Node interface.
+func (*StartTransaction) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (*StartTransaction) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (*StartTransaction) Schema() sql.Schema { return nil }
+
+// Commit commits the changes performed in the transaction of the session, and ends it. The engine commits it once the
+// statement finishes, so the node itself does nothing.
 type Commit struct{}
 
-// NewRollback creates a new Rollback node.
+// NewCommit creates a new Commit node.
 func NewCommit() *Commit { return new(Commit) }
 
 // RowIter implements the sql.Node interface.
@@ -14,7 +47,7 @@ func (*Commit) RowIter(*sql.Context, sql.Row) (sql.RowIter, error) {
 	return sql.RowsToRowIter(), nil
 }
 
-func (*Commit) String() string { return "ROLLBACK" }
+func (*Commit) String() string { return "COMMIT" }
 
 // WithChildren implements the Node interface.
 func (r *Commit) WithChildren(children ...sql.Node) (sql.Node, error) {
@@ -34,8 +67,8 @@ func (*Commit) Children() []sql.Node { return nil }
 // Schema implements the sql.Node interface.
 func (*Commit) Schema() sql.Schema { return nil }
 
-// Rollback undoes the changes performed in a transaction. This is provided just for compatibility with SQL clients and
-// is a no-op.
+// Rollback undoes the changes performed in the transaction of the session, and ends it. The engine rolls it back once
+// the statement finishes, so the node itself does nothing.
 type Rollback struct{}
 
 // NewRollback creates a new Rollback node.
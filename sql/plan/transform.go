@@ -1,37 +1,49 @@
 package plan
 
 import (
+	"reflect"
+
 	"github.com/dolthub/go-mysql-server/sql"
 	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 // TransformUp applies a transformation function to the given tree from the
-// bottom up.
+// bottom up. Nodes whose children are all returned unchanged by f are not rebuilt.
 func TransformUp(node sql.Node, f sql.TransformNodeFunc) (sql.Node, error) {
-	if o, ok := node.(sql.OpaqueNode); ok && o.Opaque() {
-		return f(node)
+	node, _, err := TransformUpWithIdentity(node, withNodeIdentity(f))
+	return node, err
+}
+
+// TransformUpWithIdentity applies a transformation function to the given tree from the bottom up, and returns whether
+// the result is the same tree given. Nodes whose children are all unchanged are not rebuilt.
+func TransformUpWithIdentity(node sql.Node, f sql.TransformNodeWithIdentityFunc) (sql.Node, sql.TreeIdentity, error) {
+	return TransformUpWithSelectorAndIdentity(node, nil, f)
+}
+
+// NodeIdentity returns SameTree if the nodes given are the same instance, and NewTree otherwise. Only nodes
+// implemented by pointer types can be told apart this way; any others are always considered new.
+func NodeIdentity(a, b sql.Node) sql.TreeIdentity {
+	if a == nil || b == nil {
+		return a == b
 	}
 
-	children := node.Children()
-	if len(children) == 0 {
-		return f(node)
+	t := reflect.TypeOf(a)
+	if t.Kind() != reflect.Ptr || t != reflect.TypeOf(b) {
+		return sql.NewTree
 	}
 
-	newChildren := make([]sql.Node, len(children))
-	for i, c := range children {
-		c, err := TransformUp(c, f)
+	return a == b
+}
+
+// withNodeIdentity adapts a sql.TransformNodeFunc to report whether it returned the node it was given.
+func withNodeIdentity(f sql.TransformNodeFunc) sql.TransformNodeWithIdentityFunc {
+	return func(n sql.Node) (sql.Node, sql.TreeIdentity, error) {
+		nn, err := f(n)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
-		newChildren[i] = c
-	}
-
-	node, err := node.WithChildren(newChildren...)
-	if err != nil {
-		return nil, err
+		return nn, NodeIdentity(n, nn), nil
 	}
-
-	return f(node)
 }
 
 // TransformNodeWithParentFunc is an analog to sql.TransformNodeFunc that also includes the parent of the node being
@@ -41,35 +53,39 @@ type TransformNodeWithParentFunc func(n sql.Node, parent sql.Node, childNum int)
 // TransformUp applies a transformation function to the given tree from the bottom up, with the additional context of
 // the parent node of the node under inspection.
 func TransformUpWithParent(node sql.Node, f TransformNodeWithParentFunc) (sql.Node, error) {
-	return transformUpWithParent(node, nil, -1, f)
+	node, _, err := transformUpWithParent(node, nil, -1, f)
+	return node, err
 }
 
 // transformUpWithParent is the internal implementation of TransformUpWithParent that allows passing a parent node.
-func transformUpWithParent(node sql.Node, parent sql.Node, childNum int, f TransformNodeWithParentFunc) (sql.Node, error) {
+func transformUpWithParent(node sql.Node, parent sql.Node, childNum int, f TransformNodeWithParentFunc) (sql.Node, sql.TreeIdentity, error) {
+	apply := func(n sql.Node) (sql.Node, sql.TreeIdentity, error) {
+		nn, err := f(n, parent, childNum)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+		return nn, NodeIdentity(n, nn), nil
+	}
+
 	if o, ok := node.(sql.OpaqueNode); ok && o.Opaque() {
-		return f(node, parent, childNum)
+		return apply(node)
 	}
 
 	children := node.Children()
 	if len(children) == 0 {
-		return f(node, parent, childNum)
+		return apply(node)
 	}
 
-	newChildren := make([]sql.Node, len(children))
+	var newChildren []sql.Node
 	for i, c := range children {
-		c, err := transformUpWithParent(c, node, i, f)
+		nc, same, err := transformUpWithParent(c, node, i, f)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
-		newChildren[i] = c
+		newChildren = replaceChild(newChildren, children, i, nc, same)
 	}
 
-	node, err := node.WithChildren(newChildren...)
-	if err != nil {
-		return nil, err
-	}
-
-	return f(node, parent, childNum)
+	return withNewChildren(node, newChildren, apply)
 }
 
 // ChildSelector is a func that returns whether the child of a parent node should be walked as part of a transformation.
@@ -78,6 +94,13 @@ type ChildSelector func(parent sql.Node, child sql.Node, childNum int) bool
 
 // TransformUpWithSelector works like TransformUp, but allows the caller to decide which children of a node are walked.
 func TransformUpWithSelector(node sql.Node, selector ChildSelector, f sql.TransformNodeFunc) (sql.Node, error) {
+	node, _, err := TransformUpWithSelectorAndIdentity(node, selector, withNodeIdentity(f))
+	return node, err
+}
+
+// TransformUpWithSelectorAndIdentity works like TransformUpWithIdentity, but allows the caller to decide which
+// children of a node are walked. A nil selector walks all of them.
+func TransformUpWithSelectorAndIdentity(node sql.Node, selector ChildSelector, f sql.TransformNodeWithIdentityFunc) (sql.Node, sql.TreeIdentity, error) {
 	if o, ok := node.(sql.OpaqueNode); ok && o.Opaque() {
 		return f(node)
 	}
@@ -87,131 +110,143 @@ func TransformUpWithSelector(node sql.Node, selector ChildSelector, f sql.Transf
 		return f(node)
 	}
 
-	newChildren := make([]sql.Node, len(children))
+	var newChildren []sql.Node
 	for i, c := range children {
-		if selector(node, c, i) {
-			c, err := TransformUpWithSelector(c, selector, f)
-			if err != nil {
-				return nil, err
-			}
-			newChildren[i] = c
-		} else {
-			newChildren[i] = c
+		if selector != nil && !selector(node, c, i) {
+			continue
 		}
-	}
 
-	node, err := node.WithChildren(newChildren...)
-	if err != nil {
-		return nil, err
+		nc, same, err := TransformUpWithSelectorAndIdentity(c, selector, f)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+		newChildren = replaceChild(newChildren, children, i, nc, same)
 	}
 
-	return f(node)
+	return withNewChildren(node, newChildren, f)
 }
 
-// TransformExpressionsUp applies a transformation function to all expressions
-// on the given tree from the bottom up.
-func TransformExpressionsUpWithNode(node sql.Node, f expression.TransformExprWithNodeFunc) (sql.Node, error) {
-	if o, ok := node.(sql.OpaqueNode); ok && o.Opaque() {
-		return TransformExpressionsWithNode(node, f)
+// replaceChild records the transformed child at index i of children, and returns the new children of the node. The
+// new children slice is only allocated once a child has changed; it stays nil while all of them are the same.
+func replaceChild(newChildren, children []sql.Node, i int, child sql.Node, same sql.TreeIdentity) []sql.Node {
+	if same {
+		return newChildren
 	}
 
-	children := node.Children()
-	if len(children) == 0 {
-		return TransformExpressionsWithNode(node, f)
+	if newChildren == nil {
+		newChildren = make([]sql.Node, len(children))
+		copy(newChildren, children)
 	}
+	newChildren[i] = child
+	return newChildren
+}
 
-	newChildren := make([]sql.Node, len(children))
-	for i, c := range children {
-		c, err := TransformExpressionsUpWithNode(c, f)
+// withNewChildren rebuilds the node given with its new children, if any changed, and then applies f to it.
+func withNewChildren(node sql.Node, newChildren []sql.Node, f sql.TransformNodeWithIdentityFunc) (sql.Node, sql.TreeIdentity, error) {
+	identity := sql.SameTree
+	if newChildren != nil {
+		var err error
+		node, err = node.WithChildren(newChildren...)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
-		newChildren[i] = c
+		identity = sql.NewTree
 	}
 
-	node, err := node.WithChildren(newChildren...)
+	node, same, err := f(node)
 	if err != nil {
-		return nil, err
+		return nil, sql.SameTree, err
 	}
 
-	return TransformExpressionsWithNode(node, f)
+	return node, identity && same, nil
 }
 
 // TransformExpressionsUp applies a transformation function to all expressions
 // on the given tree from the bottom up.
-func TransformExpressionsUp(node sql.Node, f sql.TransformExprFunc) (sql.Node, error) {
-	if o, ok := node.(sql.OpaqueNode); ok && o.Opaque() {
-		return TransformExpressions(node, f)
-	}
-
-	children := node.Children()
-	if len(children) == 0 {
-		return TransformExpressions(node, f)
-	}
+func TransformExpressionsUpWithNode(node sql.Node, f expression.TransformExprWithNodeFunc) (sql.Node, error) {
+	return TransformUp(node, func(n sql.Node) (sql.Node, error) {
+		return TransformExpressionsWithNode(n, f)
+	})
+}
 
-	newChildren := make([]sql.Node, len(children))
-	for i, c := range children {
-		c, err := TransformExpressionsUp(c, f)
+// TransformExpressionsUp applies a transformation function to all expressions
+// on the given tree from the bottom up.
+func TransformExpressionsUp(node sql.Node, f sql.TransformExprFunc) (sql.Node, error) {
+	node, _, err := TransformExpressionsUpWithIdentity(node, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		ne, err := f(e)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
 		}
-		newChildren[i] = c
-	}
-
-	node, err := node.WithChildren(newChildren...)
-	if err != nil {
-		return nil, err
-	}
+		return ne, expression.ExpressionIdentity(e, ne), nil
+	})
+	return node, err
+}
 
-	return TransformExpressions(node, f)
+// TransformExpressionsUpWithIdentity applies a transformation function to all expressions on the given tree from the
+// bottom up, and returns whether the result is the same tree given.
+func TransformExpressionsUpWithIdentity(node sql.Node, f sql.TransformExprWithIdentityFunc) (sql.Node, sql.TreeIdentity, error) {
+	return TransformUpWithIdentity(node, func(n sql.Node) (sql.Node, sql.TreeIdentity, error) {
+		return TransformExpressionsWithIdentity(n, f)
+	})
 }
 
 // TransformExpressions applies a transformation function to all expressions
 // on the given node.
 func TransformExpressions(node sql.Node, f sql.TransformExprFunc) (sql.Node, error) {
+	node, _, err := TransformExpressionsWithIdentity(node, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		ne, err := f(e)
+		if err != nil {
+			return nil, sql.SameTree, err
+		}
+		return ne, expression.ExpressionIdentity(e, ne), nil
+	})
+	return node, err
+}
+
+// TransformExpressionsWithIdentity applies a transformation function to all expressions on the given node, and
+// returns whether the result is the same node given. The node is only rebuilt if one of its expressions changed.
+func TransformExpressionsWithIdentity(node sql.Node, f sql.TransformExprWithIdentityFunc) (sql.Node, sql.TreeIdentity, error) {
 	e, ok := node.(sql.Expressioner)
 	if !ok {
-		return node, nil
+		return node, sql.SameTree, nil
 	}
 
 	exprs := e.Expressions()
 	if len(exprs) == 0 {
-		return node, nil
+		return node, sql.SameTree, nil
 	}
 
-	newExprs := make([]sql.Expression, len(exprs))
+	var newExprs []sql.Expression
 	for i, e := range exprs {
-		e, err := expression.TransformUp(e, f)
+		ne, same, err := expression.TransformUpWithIdentity(e, f)
 		if err != nil {
-			return nil, err
+			return nil, sql.SameTree, err
+		}
+		if !same {
+			if newExprs == nil {
+				newExprs = make([]sql.Expression, len(exprs))
+				copy(newExprs, exprs)
+			}
+			newExprs[i] = ne
 		}
-		newExprs[i] = e
 	}
 
-	return e.WithExpressions(newExprs...)
-}
-
-// TransformExpressions applies a transformation function to all expressions
-// on the given node.
-func TransformExpressionsWithNode(n sql.Node, f expression.TransformExprWithNodeFunc) (sql.Node, error) {
-	e, ok := n.(sql.Expressioner)
-	if !ok {
-		return n, nil
+	if newExprs == nil {
+		return node, sql.SameTree, nil
 	}
 
-	exprs := e.Expressions()
-	if len(exprs) == 0 {
-		return n, nil
+	node, err := e.WithExpressions(newExprs...)
+	if err != nil {
+		return nil, sql.SameTree, err
 	}
 
-	newExprs := make([]sql.Expression, len(exprs))
-	for i, e := range exprs {
-		e, err := expression.TransformUpWithNode(n, e, f)
-		if err != nil {
-			return nil, err
-		}
-		newExprs[i] = e
-	}
+	return node, sql.NewTree, nil
+}
 
-	return e.WithExpressions(newExprs...)
+// TransformExpressions applies a transformation function to all expressions
+// on the given node.
+func TransformExpressionsWithNode(n sql.Node, f expression.TransformExprWithNodeFunc) (sql.Node, error) {
+	return TransformExpressions(n, func(e sql.Expression) (sql.Expression, error) {
+		return f(n, e)
+	})
 }
@@ -42,3 +42,75 @@ func TestTransformUp(t *testing.T) {
 	)
 	require.Equal(ep, pt)
 }
+
+func TestTransformUpWithIdentity(t *testing.T) {
+	require := require.New(t)
+
+	table := memory.NewTable("resolved", sql.Schema{
+		{Name: "a", Type: sql.Text},
+		{Name: "b", Type: sql.Text},
+	})
+
+	aCol := expression.NewGetField(0, sql.Text, "a", false)
+	bCol := expression.NewGetField(1, sql.Text, "b", false)
+	filter := NewFilter(expression.NewEquals(aCol, bCol), NewResolvedTable(table))
+	left := NewProject([]sql.Expression{aCol}, filter)
+	right := NewResolvedTable(table)
+	join := NewCrossJoin(left, right)
+
+	result, same, err := TransformUpWithIdentity(join, func(n sql.Node) (sql.Node, sql.TreeIdentity, error) {
+		return n, sql.SameTree, nil
+	})
+	require.NoError(err)
+	require.Equal(sql.SameTree, same)
+	require.True(result == sql.Node(join))
+
+	result, err = TransformUp(join, func(n sql.Node) (sql.Node, error) {
+		return n, nil
+	})
+	require.NoError(err)
+	require.True(result == sql.Node(join))
+
+	// Only the nodes above the changed one are rebuilt
+	result, same, err = TransformUpWithIdentity(join, func(n sql.Node) (sql.Node, sql.TreeIdentity, error) {
+		if n == sql.Node(filter) {
+			return filter.Child, sql.NewTree, nil
+		}
+		return n, sql.SameTree, nil
+	})
+	require.NoError(err)
+	require.Equal(sql.NewTree, same)
+	require.False(result == sql.Node(join))
+
+	newJoin := result.(*CrossJoin)
+	require.True(newJoin.Right == sql.Node(right))
+	require.False(newJoin.Left == sql.Node(left))
+	require.Equal(NewProject([]sql.Expression{aCol}, filter.Child), newJoin.Left)
+	require.Equal(NewCrossJoin(left, right), join)
+}
+
+func TestTransformExpressionsWithIdentity(t *testing.T) {
+	require := require.New(t)
+
+	aCol := expression.NewGetField(0, sql.Text, "a", false)
+	bCol := expression.NewGetField(1, sql.Text, "b", false)
+	p := NewProject([]sql.Expression{aCol, bCol}, NewUnresolvedTable("t", ""))
+
+	result, same, err := TransformExpressionsUpWithIdentity(p, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		return e, sql.SameTree, nil
+	})
+	require.NoError(err)
+	require.Equal(sql.SameTree, same)
+	require.True(result == sql.Node(p))
+
+	result, same, err = TransformExpressionsUpWithIdentity(p, func(e sql.Expression) (sql.Expression, sql.TreeIdentity, error) {
+		if e == sql.Expression(bCol) {
+			return expression.NewLiteral("b", sql.Text), sql.NewTree, nil
+		}
+		return e, sql.SameTree, nil
+	})
+	require.NoError(err)
+	require.Equal(sql.NewTree, same)
+	require.Equal(NewProject([]sql.Expression{aCol, expression.NewLiteral("b", sql.Text)}, p.Child), result)
+	require.Equal([]sql.Expression{aCol, bCol}, p.Projections)
+}
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Truncate is a node describing the removal of all the rows of a table. Unlike DeleteFrom, it doesn't fire the
+// triggers of the table, and it doesn't report the rows it removed.
+type Truncate struct {
+	UnaryNode
+	// ForeignKeys are the foreign keys of the tables that reference the table, if any, which can't be truncated if
+	// they're enforced.
+	ForeignKeys *ForeignKeys
+}
+
+// NewTruncate creates a Truncate node of the table given.
+func NewTruncate(table sql.Node) *Truncate {
+	return &Truncate{UnaryNode: UnaryNode{table}}
+}
+
+// Schema implements the sql.Node interface.
+func (t *Truncate) Schema() sql.Schema {
+	return sql.OkResultSchema
+}
+
+// RowIter implements the sql.Node interface.
+func (t *Truncate) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	deletable, err := getDeletable(t.Child)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ForeignKeys.checkTruncate(ctx, deletable); err != nil {
+		return nil, err
+	}
+
+	iter, err := t.Child.RowIter(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	deleter := newDeleteIter(iter, deletable.Deleter(ctx), deletable.Schema(), ctx)
+	for {
+		if _, err = deleter.Next(); err != nil {
+			break
+		}
+	}
+	if err != io.EOF {
+		_ = deleter.Close()
+		return nil, err
+	}
+	if err := deleter.Close(); err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(sql.NewRow(sql.NewOkResult(0))), nil
+}
+
+// WithChildren implements the sql.Node interface.
+func (t *Truncate) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(t, len(children), 1)
+	}
+	nt := *t
+	nt.Child = children[0]
+	return &nt, nil
+}
+
+func (t *Truncate) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("Truncate")
+	_ = pr.WriteChildren(t.Child.String())
+	return pr.String()
+}
+
+func (t *Truncate) DebugString() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("Truncate")
+	_ = pr.WriteChildren(sql.DebugString(t.Child))
+	return pr.String()
+}
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"fmt"
+	"strings"
 
 	errors "gopkg.in/src-d/go-errors.v1"
 
@@ -77,3 +78,63 @@ func (t *UnresolvedTable) WithDatabase(database string) (*UnresolvedTable, error
 func (t UnresolvedTable) String() string {
 	return fmt.Sprintf("UnresolvedTable(%s)", t.name)
 }
+
+// UnresolvedTableFunction is a call of a table function in the FROM clause of a query, which has not been resolved
+// yet. It's resolved to the table returned by the table function with that name.
+type UnresolvedTableFunction struct {
+	name      string
+	Arguments []sql.Expression
+}
+
+// NewUnresolvedTableFunction creates a new UnresolvedTableFunction with the name of the function called and the
+// arguments of the call.
+func NewUnresolvedTableFunction(name string, args []sql.Expression) *UnresolvedTableFunction {
+	return &UnresolvedTableFunction{name, args}
+}
+
+// Name implements the Nameable interface. Like tables, a call of a table function without an alias is named after
+// the function.
+func (t *UnresolvedTableFunction) Name() string {
+	return t.name
+}
+
+// Resolved implements the Resolvable interface.
+func (*UnresolvedTableFunction) Resolved() bool {
+	return false
+}
+
+// Children implements the Node interface.
+func (*UnresolvedTableFunction) Children() []sql.Node { return nil }
+
+// Schema implements the Node interface.
+func (*UnresolvedTableFunction) Schema() sql.Schema { return nil }
+
+// RowIter implements the RowIter interface.
+func (*UnresolvedTableFunction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return nil, ErrUnresolvedTable.New()
+}
+
+// WithChildren implements the Node interface.
+func (t *UnresolvedTableFunction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(t, len(children), 0)
+	}
+
+	return t, nil
+}
+
+// WithArguments returns a copy of this unresolved table function with its Arguments field set to the given value.
+// Analagous to WithChildren.
+func (t *UnresolvedTableFunction) WithArguments(args []sql.Expression) *UnresolvedTableFunction {
+	t2 := *t
+	t2.Arguments = args
+	return &t2
+}
+
+func (t UnresolvedTableFunction) String() string {
+	args := make([]string, len(t.Arguments))
+	for i, arg := range t.Arguments {
+		args[i] = arg.String()
+	}
+	return fmt.Sprintf("UnresolvedTableFunction(%s(%s))", t.name, strings.Join(args, ", "))
+}
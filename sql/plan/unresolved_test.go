@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
 )
 
 func TestUnresolvedTable(t *testing.T) {
@@ -13,3 +14,14 @@ func TestUnresolvedTable(t *testing.T) {
 	var n sql.Node = NewUnresolvedTable("test_table", "")
 	require.NotNil(n)
 }
+
+func TestUnresolvedTableFunction(t *testing.T) {
+	require := require.New(t)
+	n := NewUnresolvedTableFunction("numbers", []sql.Expression{
+		expression.NewLiteral(int8(1), sql.Int8),
+		expression.NewLiteral(int8(10), sql.Int8),
+	})
+	require.Equal("numbers", n.Name())
+	require.False(n.Resolved())
+	require.Equal("UnresolvedTableFunction(numbers(1, 10))", n.String())
+}
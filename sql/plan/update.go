@@ -14,11 +14,15 @@ var ErrUpdateUnexpectedSetResult = errors.NewKind("attempted to set field but ex
 // Update is a node for updating rows on tables.
 type Update struct {
 	UnaryNode
+	// ForeignKeys are the foreign keys enforced on the rows updated, if any.
+	ForeignKeys *ForeignKeys
+	// Checks are the check constraints enforced on the rows updated.
+	Checks CheckConstraints
 }
 
 // NewUpdate creates an Update node.
 func NewUpdate(n sql.Node, updateExprs []sql.Expression) *Update {
-	return &Update{UnaryNode{NewUpdateSource(n, updateExprs)}}
+	return &Update{UnaryNode: UnaryNode{NewUpdateSource(n, updateExprs)}}
 }
 
 func getUpdatable(node sql.Node) (sql.UpdatableTable, error) {
@@ -131,7 +135,7 @@ func (u *Update) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
 	if err != nil {
 		return nil, err
 	}
-	updater := updatable.Updater(ctx)
+	updater := u.Checks.updater(u.ForeignKeys.updater(ctx, updatable, updatable.Updater(ctx)))
 
 	iter, err := u.Child.RowIter(ctx, row)
 	if err != nil {
@@ -0,0 +1,343 @@
+package plan
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// Window is a projection of expressions computing window functions. The values of the window functions are computed
+// over all the rows of its child, which it reads before returning any row, and its rows are returned in the order of
+// the rows of its child.
+type Window struct {
+	UnaryNode
+	SelectExprs []sql.Expression
+}
+
+var _ sql.Expressioner = (*Window)(nil)
+
+// NewWindow creates a new Window node projecting the expressions given, which may compute window functions.
+func NewWindow(selectExprs []sql.Expression, child sql.Node) *Window {
+	return &Window{
+		UnaryNode:   UnaryNode{child},
+		SelectExprs: selectExprs,
+	}
+}
+
+// Schema implements the sql.Node interface.
+func (w *Window) Schema() sql.Schema {
+	var s = make(sql.Schema, len(w.SelectExprs))
+	for i, e := range w.SelectExprs {
+		s[i] = expression.ExpressionToColumn(e)
+	}
+	return s
+}
+
+// Resolved implements the sql.Resolvable interface.
+func (w *Window) Resolved() bool {
+	return w.UnaryNode.Child.Resolved() &&
+		expressionsResolved(w.SelectExprs...)
+}
+
+// RowIter implements the sql.Node interface.
+func (w *Window) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	span, ctx := ctx.Span("plan.Window")
+
+	i, err := w.Child.RowIter(ctx, row)
+	if err != nil {
+		span.Finish()
+		return nil, err
+	}
+
+	return sql.NewSpanIter(span, &windowIter{
+		ctx:       ctx,
+		w:         w,
+		childIter: i,
+	}), nil
+}
+
+func (w *Window) String() string {
+	pr := sql.NewTreePrinter()
+	var exprs = make([]string, len(w.SelectExprs))
+	for i, expr := range w.SelectExprs {
+		exprs[i] = expr.String()
+	}
+	_ = pr.WriteNode("Window(%s)", strings.Join(exprs, ", "))
+	_ = pr.WriteChildren(w.Child.String())
+	return pr.String()
+}
+
+func (w *Window) DebugString() string {
+	pr := sql.NewTreePrinter()
+	var exprs = make([]string, len(w.SelectExprs))
+	for i, expr := range w.SelectExprs {
+		exprs[i] = sql.DebugString(expr)
+	}
+	_ = pr.WriteNode("Window(%s)", strings.Join(exprs, ", "))
+	_ = pr.WriteChildren(sql.DebugString(w.Child))
+	return pr.String()
+}
+
+// Expressions implements the sql.Expressioner interface.
+func (w *Window) Expressions() []sql.Expression {
+	return w.SelectExprs
+}
+
+// WithChildren implements the sql.Node interface.
+func (w *Window) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(w, len(children), 1)
+	}
+
+	return NewWindow(w.SelectExprs, children[0]), nil
+}
+
+// WithExpressions implements the sql.Expressioner interface.
+func (w *Window) WithExpressions(exprs ...sql.Expression) (sql.Node, error) {
+	if len(exprs) != len(w.SelectExprs) {
+		return nil, sql.ErrInvalidChildrenNumber.New(w, len(exprs), len(w.SelectExprs))
+	}
+
+	return NewWindow(exprs, w.Child), nil
+}
+
+type windowIter struct {
+	ctx       *sql.Context
+	w         *Window
+	childIter sql.RowIter
+	rows      []sql.Row
+	idx       int
+	computed  bool
+}
+
+func (i *windowIter) Next() (sql.Row, error) {
+	if !i.computed {
+		if err := i.computeRows(); err != nil {
+			return nil, err
+		}
+		i.computed = true
+	}
+
+	if i.idx >= len(i.rows) {
+		return nil, io.EOF
+	}
+	row := i.rows[i.idx]
+	i.idx++
+	return row, nil
+}
+
+func (i *windowIter) Close() error {
+	i.rows = nil
+	return i.childIter.Close()
+}
+
+// computeRows reads all the rows of the child and projects them, with the window functions of the projections
+// replaced by fields holding their values, which are appended to every row.
+func (i *windowIter) computeRows() error {
+	var rows []sql.Row
+	for {
+		row, err := i.childIter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	width := len(rows[0])
+	var functions []*WindowFunction
+	projections := make([]sql.Expression, len(i.w.SelectExprs))
+	for j, e := range i.w.SelectExprs {
+		var err error
+		projections[j], err = expression.TransformUp(e, func(e sql.Expression) (sql.Expression, error) {
+			f, ok := e.(*WindowFunction)
+			if !ok {
+				return e, nil
+			}
+			functions = append(functions, f)
+			return expression.NewGetField(width+len(functions)-1, f.Type(), f.String(), f.IsNullable()), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	values := make([][]interface{}, len(functions))
+	for j, f := range functions {
+		var err error
+		values[j], err = computeWindowFunction(i.ctx, f, rows)
+		if err != nil {
+			return err
+		}
+	}
+
+	i.rows = make([]sql.Row, len(rows))
+	for j, row := range rows {
+		row = row[:width:width]
+		for _, v := range values {
+			row = append(row, v[j])
+		}
+
+		var err error
+		i.rows[j], err = ProjectRow(i.ctx, projections, row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeWindowFunction returns the values of the window function given for every row given, in the same order. The
+// rows are sorted by their partition and then by the order of the window, and the function is computed over every
+// partition.
+func computeWindowFunction(ctx *sql.Context, f *WindowFunction, rows []sql.Row) ([]interface{}, error) {
+	// Every row is sorted with its position appended, so its value ends up in the same position
+	sorted := make([]sql.Row, len(rows))
+	for i, row := range rows {
+		sorted[i] = append(row[:len(row):len(row)], i)
+	}
+
+	sortFields := make([]SortField, 0, len(f.PartitionBy)+len(f.OrderBy))
+	for _, e := range f.PartitionBy {
+		sortFields = append(sortFields, SortField{Column: e, Order: Ascending})
+	}
+	sortFields = append(sortFields, f.OrderBy...)
+
+	sorter := &Sorter{SortFields: sortFields, Rows: sorted, Ctx: ctx}
+	sort.Stable(sorter)
+	if sorter.LastError != nil {
+		return nil, sorter.LastError
+	}
+
+	values := make([]interface{}, len(rows))
+	for start := 0; start < len(sorted); {
+		end, err := peersEnd(ctx, f.PartitionBy, sorted, start)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := computeWindowPartition(ctx, f, sorted[start:end], values); err != nil {
+			return nil, err
+		}
+		start = end
+	}
+	return values, nil
+}
+
+// computeWindowPartition sets the values of the window function given for the sorted rows of a partition.
+func computeWindowPartition(ctx *sql.Context, f *WindowFunction, rows []sql.Row, values []interface{}) error {
+	agg, isAggregation := f.Function.(sql.Aggregation)
+	ranking, isRanking := f.Function.(*RankingFunction)
+	if !isAggregation && !isRanking {
+		return ErrInvalidWindowFunctionUse.New(f.Function)
+	}
+
+	var buffer sql.Row
+	if isAggregation {
+		buffer = agg.NewBuffer()
+	}
+
+	order := make([]sql.Expression, len(f.OrderBy))
+	for i, sf := range f.OrderBy {
+		order[i] = sf.Column
+	}
+
+	// Without an order all the rows of the partition are peers
+	peers := 0
+	for start := 0; start < len(rows); {
+		end, err := peersEnd(ctx, order, rows, start)
+		if err != nil {
+			return err
+		}
+		peers++
+
+		// Aggregations are computed over the rows up to the last of the peers of every row
+		var value interface{}
+		if isAggregation {
+			for _, row := range rows[start:end] {
+				if err := agg.Update(ctx, buffer, row); err != nil {
+					return err
+				}
+			}
+
+			value, err = agg.Eval(ctx, buffer)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i := start; i < end; i++ {
+			if isRanking {
+				switch ranking.kind {
+				case rankKind:
+					value = int64(start + 1)
+				case denseRankKind:
+					value = int64(peers)
+				default:
+					value = int64(i + 1)
+				}
+			}
+
+			row := rows[i]
+			values[row[len(row)-1].(int)] = value
+		}
+		start = end
+	}
+	return nil
+}
+
+// peersEnd returns the position of the first of the sorted rows given after the one at start that has different
+// values of the expressions given.
+func peersEnd(ctx *sql.Context, exprs []sql.Expression, rows []sql.Row, start int) (int, error) {
+	end := start + 1
+	for ; end < len(rows); end++ {
+		same, err := sameValues(ctx, exprs, rows[start], rows[end])
+		if err != nil {
+			return 0, err
+		}
+		if !same {
+			break
+		}
+	}
+	return end, nil
+}
+
+// sameValues returns whether the expressions given have the same values for both rows, taking null values as equal.
+func sameValues(ctx *sql.Context, exprs []sql.Expression, a, b sql.Row) (bool, error) {
+	for _, e := range exprs {
+		av, err := e.Eval(ctx, a)
+		if err != nil {
+			return false, err
+		}
+
+		bv, err := e.Eval(ctx, b)
+		if err != nil {
+			return false, err
+		}
+
+		if av == nil || bv == nil {
+			if av != nil || bv != nil {
+				return false, nil
+			}
+			continue
+		}
+
+		cmp, err := e.Type().Compare(av, bv)
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
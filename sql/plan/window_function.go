@@ -0,0 +1,200 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrInvalidWindowFunctionUse is returned when a window function is used anywhere but in the expressions selected by
+// a query, the only place where it's computed.
+var ErrInvalidWindowFunctionUse = errors.NewKind("you cannot use the window function '%s' in this context")
+
+// WindowFunction is a function computed over the window of rows of a query sharing the partition of every row, like
+// ROW_NUMBER() OVER (PARTITION BY a ORDER BY b) or SUM(c) OVER (PARTITION BY a). Its value can't be evaluated from a
+// single row, so it's computed by the Window node the projections of its query are turned into. It's in the plan
+// package instead of the expression package because the order of its window is given with sort fields.
+type WindowFunction struct {
+	// Function is the function computed, a RankingFunction or an aggregation.
+	Function sql.Expression
+	// PartitionBy are the expressions whose values split the rows in partitions.
+	PartitionBy []sql.Expression
+	// OrderBy is the order of the rows of every partition. Rows sorting the same are peers, which share the same rank
+	// and the same value of an aggregation.
+	OrderBy []SortField
+}
+
+var _ sql.Expression = (*WindowFunction)(nil)
+
+// NewWindowFunction creates a new window function computing the function given over the window given.
+func NewWindowFunction(function sql.Expression, partitionBy []sql.Expression, orderBy []SortField) *WindowFunction {
+	return &WindowFunction{Function: function, PartitionBy: partitionBy, OrderBy: orderBy}
+}
+
+// Resolved implements the sql.Expression interface.
+func (w *WindowFunction) Resolved() bool {
+	for _, e := range w.Children() {
+		if !e.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// Type implements the sql.Expression interface.
+func (w *WindowFunction) Type() sql.Type {
+	return w.Function.Type()
+}
+
+// IsNullable implements the sql.Expression interface.
+func (w *WindowFunction) IsNullable() bool {
+	return w.Function.IsNullable()
+}
+
+// Eval implements the sql.Expression interface. Window functions are only computed by the Window node.
+func (w *WindowFunction) Eval(*sql.Context, sql.Row) (interface{}, error) {
+	return nil, ErrInvalidWindowFunctionUse.New(w)
+}
+
+// Children implements the sql.Expression interface. They are the function followed by the expressions of the
+// partition and the order.
+func (w *WindowFunction) Children() []sql.Expression {
+	children := make([]sql.Expression, 0, 1+len(w.PartitionBy)+len(w.OrderBy))
+	children = append(children, w.Function)
+	children = append(children, w.PartitionBy...)
+	for _, f := range w.OrderBy {
+		children = append(children, f.Column)
+	}
+	return children
+}
+
+// WithChildren implements the sql.Expression interface.
+func (w *WindowFunction) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 1+len(w.PartitionBy)+len(w.OrderBy) {
+		return nil, sql.ErrInvalidChildrenNumber.New(w, len(children), 1+len(w.PartitionBy)+len(w.OrderBy))
+	}
+
+	partitionBy := children[1 : 1+len(w.PartitionBy)]
+	orderBy := make([]SortField, len(w.OrderBy))
+	for i, f := range w.OrderBy {
+		f.Column = children[1+len(w.PartitionBy)+i]
+		orderBy[i] = f
+	}
+	return NewWindowFunction(children[0], partitionBy, orderBy), nil
+}
+
+func (w *WindowFunction) String() string {
+	var clauses []string
+	if len(w.PartitionBy) > 0 {
+		exprs := make([]string, len(w.PartitionBy))
+		for i, e := range w.PartitionBy {
+			exprs[i] = e.String()
+		}
+		clauses = append(clauses, "PARTITION BY "+strings.Join(exprs, ", "))
+	}
+	if len(w.OrderBy) > 0 {
+		fields := make([]string, len(w.OrderBy))
+		for i, f := range w.OrderBy {
+			fields[i] = f.String()
+		}
+		clauses = append(clauses, "ORDER BY "+strings.Join(fields, ", "))
+	}
+	return fmt.Sprintf("%s OVER (%s)", w.Function, strings.Join(clauses, " "))
+}
+
+// rankingKind is the kind of ranking done by a ranking function.
+type rankingKind byte
+
+const (
+	rowNumberKind rankingKind = iota
+	rankKind
+	denseRankKind
+)
+
+// RankingFunction is a window function numbering the rows of every partition: ROW_NUMBER numbers every row, RANK
+// gives peers the row number of the first of them, and DENSE_RANK numbers the groups of peers.
+type RankingFunction struct {
+	kind rankingKind
+}
+
+var _ sql.Expression = (*RankingFunction)(nil)
+
+// NewRowNumber creates a new ROW_NUMBER function.
+func NewRowNumber() *RankingFunction {
+	return &RankingFunction{kind: rowNumberKind}
+}
+
+// NewRank creates a new RANK function.
+func NewRank() *RankingFunction {
+	return &RankingFunction{kind: rankKind}
+}
+
+// NewDenseRank creates a new DENSE_RANK function.
+func NewDenseRank() *RankingFunction {
+	return &RankingFunction{kind: denseRankKind}
+}
+
+// Resolved implements the sql.Expression interface.
+func (*RankingFunction) Resolved() bool {
+	return true
+}
+
+// Type implements the sql.Expression interface.
+func (*RankingFunction) Type() sql.Type {
+	return sql.Int64
+}
+
+// IsNullable implements the sql.Expression interface.
+func (*RankingFunction) IsNullable() bool {
+	return false
+}
+
+// Eval implements the sql.Expression interface. Ranking functions are only computed over the rows of a window.
+func (r *RankingFunction) Eval(*sql.Context, sql.Row) (interface{}, error) {
+	return nil, ErrInvalidWindowFunctionUse.New(r)
+}
+
+// Children implements the sql.Expression interface.
+func (*RankingFunction) Children() []sql.Expression {
+	return nil
+}
+
+// WithChildren implements the sql.Expression interface.
+func (r *RankingFunction) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(r, len(children), 0)
+	}
+	return r, nil
+}
+
+func (r *RankingFunction) String() string {
+	switch r.kind {
+	case rankKind:
+		return "RANK()"
+	case denseRankKind:
+		return "DENSE_RANK()"
+	default:
+		return "ROW_NUMBER()"
+	}
+}
+
+// FindWindowFunction returns the first window function computed by the expressions given, or nil if there's none.
+func FindWindowFunction(exprs ...sql.Expression) sql.Expression {
+	var found sql.Expression
+	for _, e := range exprs {
+		sql.Inspect(e, func(e sql.Expression) bool {
+			switch e.(type) {
+			case *WindowFunction, *RankingFunction:
+				if found == nil {
+					found = e
+				}
+				return false
+			}
+			return found == nil
+		})
+	}
+	return found
+}
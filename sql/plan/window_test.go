@@ -0,0 +1,134 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+	"github.com/dolthub/go-mysql-server/sql/expression/function/aggregation"
+)
+
+func TestWindow(t *testing.T) {
+	child := memory.NewTable("test", sql.Schema{
+		{Name: "team", Type: sql.Text, Source: "test"},
+		{Name: "name", Type: sql.Text, Source: "test"},
+		{Name: "score", Type: sql.Int64, Source: "test", Nullable: true},
+	})
+
+	ctx := sql.NewEmptyContext()
+	for _, row := range []sql.Row{
+		{"b", "carol", int64(5)},
+		{"a", "alice", int64(10)},
+		{"b", "dave", nil},
+		{"a", "bob", int64(7)},
+		{"b", "erin", int64(5)},
+		{"a", "frank", int64(10)},
+	} {
+		require.NoError(t, child.Insert(ctx, row))
+	}
+
+	team := expression.NewGetFieldWithTable(0, sql.Text, "test", "team", false)
+	name := expression.NewGetFieldWithTable(1, sql.Text, "test", "name", false)
+	score := expression.NewGetFieldWithTable(2, sql.Int64, "test", "score", true)
+	byTeam := []sql.Expression{team}
+	byScore := []SortField{{Column: score, Order: Descending}}
+
+	testCases := []struct {
+		name     string
+		exprs    []sql.Expression
+		expected []sql.Row
+	}{
+		{
+			name: "ranking in partitions",
+			exprs: []sql.Expression{
+				name,
+				NewWindowFunction(NewRowNumber(), byTeam, byScore),
+				NewWindowFunction(NewRank(), byTeam, byScore),
+				NewWindowFunction(NewDenseRank(), byTeam, byScore),
+			},
+			expected: []sql.Row{
+				{"carol", int64(1), int64(1), int64(1)},
+				{"alice", int64(1), int64(1), int64(1)},
+				{"dave", int64(3), int64(3), int64(2)},
+				{"bob", int64(3), int64(3), int64(2)},
+				{"erin", int64(2), int64(1), int64(1)},
+				{"frank", int64(2), int64(1), int64(1)},
+			},
+		},
+		{
+			name: "ranking without partitions",
+			exprs: []sql.Expression{
+				name,
+				NewWindowFunction(NewRowNumber(), nil, []SortField{{Column: name, Order: Ascending}}),
+				NewWindowFunction(NewRank(), nil, nil),
+			},
+			expected: []sql.Row{
+				{"carol", int64(3), int64(1)},
+				{"alice", int64(1), int64(1)},
+				{"dave", int64(4), int64(1)},
+				{"bob", int64(2), int64(1)},
+				{"erin", int64(5), int64(1)},
+				{"frank", int64(6), int64(1)},
+			},
+		},
+		{
+			name: "aggregations over partitions and running aggregations",
+			exprs: []sql.Expression{
+				name,
+				NewWindowFunction(aggregation.NewSum(score), byTeam, nil),
+				NewWindowFunction(aggregation.NewCount(score), byTeam, byScore),
+				expression.NewEquals(
+					NewWindowFunction(aggregation.NewMax(name), nil, []SortField{{Column: name, Order: Ascending}}),
+					name,
+				),
+			},
+			expected: []sql.Row{
+				{"carol", float64(10), int64(2), true},
+				{"alice", float64(27), int64(2), true},
+				{"dave", float64(10), int64(2), true},
+				{"bob", float64(27), int64(3), true},
+				{"erin", float64(10), int64(2), true},
+				{"frank", float64(27), int64(2), true},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			iter, err := NewWindow(tt.exprs, NewResolvedTable(child)).RowIter(ctx, nil)
+			require.NoError(err)
+			rows, err := sql.RowIterToRows(iter)
+			require.NoError(err)
+			require.Equal(tt.expected, rows)
+		})
+	}
+
+	t.Run("no rows", func(t *testing.T) {
+		require := require.New(t)
+		empty := memory.NewTable("empty", child.Schema())
+		iter, err := NewWindow([]sql.Expression{
+			NewWindowFunction(NewRowNumber(), byTeam, byScore),
+		}, NewResolvedTable(empty)).RowIter(ctx, nil)
+		require.NoError(err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(err)
+		require.Empty(rows)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		w := NewWindow([]sql.Expression{
+			name,
+			expression.NewAlias("n", NewWindowFunction(NewRowNumber(), byTeam, byScore)),
+			NewWindowFunction(aggregation.NewSum(score), byTeam, nil),
+		}, NewResolvedTable(child))
+		require.Equal(t, sql.Schema{
+			{Name: "name", Type: sql.Text, Source: "test"},
+			{Name: "n", Type: sql.Int64},
+			{Name: "SUM(test.score) OVER (PARTITION BY test.team)", Type: sql.Float64, Nullable: true},
+		}, w.Schema())
+	})
+}
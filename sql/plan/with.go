@@ -0,0 +1,88 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// With is a statement with a WITH clause, which names the common table expressions its tables can refer to. The node
+// never runs: the analyzer inlines every common table expression into the tables that refer to it, as it does with
+// views, and replaces the node with its child.
+type With struct {
+	UnaryNode
+	CTEs []*CommonTableExpression
+}
+
+// CommonTableExpression is one of the common table expressions of a WITH clause. Its subquery is named after the
+// common table expression; its columns, if given, rename those of the subquery.
+type CommonTableExpression struct {
+	Subquery *SubqueryAlias
+	Columns  []string
+}
+
+var _ sql.Node = (*With)(nil)
+
+// NewWith returns a new With node of the statement given, with the common table expressions given in the order they
+// are in its WITH clause, which is the order they can refer to each other in.
+func NewWith(child sql.Node, ctes []*CommonTableExpression) *With {
+	return &With{UnaryNode{child}, ctes}
+}
+
+// NewCommonTableExpression returns a new common table expression with the subquery and column names given.
+func NewCommonTableExpression(subquery *SubqueryAlias, columns []string) *CommonTableExpression {
+	return &CommonTableExpression{Subquery: subquery, Columns: columns}
+}
+
+// Resolved implements the sql.Node interface. A With node is never resolved, since the analyzer replaces it.
+func (w *With) Resolved() bool {
+	return false
+}
+
+// RowIter implements the sql.Node interface.
+func (w *With) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	return nil, fmt.Errorf("the common table expressions of %s were not resolved", w.Child)
+}
+
+// WithChildren implements the sql.Node interface.
+func (w *With) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 1 {
+		return nil, sql.ErrInvalidChildrenNumber.New(w, len(children), 1)
+	}
+	return NewWith(children[0], w.CTEs), nil
+}
+
+func (w *With) String() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("With(%s)", w.names())
+	children := []string{w.Child.String()}
+	for _, cte := range w.CTEs {
+		children = append(children, cte.Subquery.String())
+	}
+	_ = pr.WriteChildren(children...)
+	return pr.String()
+}
+
+func (w *With) DebugString() string {
+	pr := sql.NewTreePrinter()
+	_ = pr.WriteNode("With(%s)", w.names())
+	children := []string{sql.DebugString(w.Child)}
+	for _, cte := range w.CTEs {
+		children = append(children, sql.DebugString(cte.Subquery))
+	}
+	_ = pr.WriteChildren(children...)
+	return pr.String()
+}
+
+// names returns the names of the common table expressions of the node, with their columns.
+func (w *With) names() string {
+	names := make([]string, len(w.CTEs))
+	for i, cte := range w.CTEs {
+		names[i] = cte.Subquery.Name()
+		if len(cte.Columns) > 0 {
+			names[i] += "(" + strings.Join(cte.Columns, ", ") + ")"
+		}
+	}
+	return strings.Join(names, ", ")
+}
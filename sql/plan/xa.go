@@ -0,0 +1,158 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// XAStatement is the kind of statement of an XATransaction.
+type XAStatement byte
+
+const (
+	// XAStart is XA START, which starts an XA transaction in the session.
+	XAStart XAStatement = iota
+	// XAEnd is XA END, which ends the active XA transaction of the session.
+	XAEnd
+	// XAPrepare is XA PREPARE, which prepares the changes of the ended XA transaction of the session.
+	XAPrepare
+	// XACommit is XA COMMIT, which commits a prepared XA transaction, or the ended one of the session with ONE PHASE.
+	XACommit
+	// XARollback is XA ROLLBACK, which rolls back a prepared XA transaction, or the ended one of the session.
+	XARollback
+)
+
+func (s XAStatement) String() string {
+	switch s {
+	case XAStart:
+		return "START"
+	case XAEnd:
+		return "END"
+	case XAPrepare:
+		return "PREPARE"
+	case XACommit:
+		return "COMMIT"
+	default:
+		return "ROLLBACK"
+	}
+}
+
+// XATransaction is any of the XA statements that change the state of an XA transaction.
+type XATransaction struct {
+	Statement XAStatement
+	XID       sql.XID
+	// OnePhase is set for XA COMMIT ... ONE PHASE.
+	OnePhase  bool
+	Committer *sql.TwoPhaseCommitter
+}
+
+var _ sql.Node = (*XATransaction)(nil)
+
+// NewXATransaction creates a new XATransaction node.
+func NewXATransaction(statement XAStatement, xid sql.XID, onePhase bool) *XATransaction {
+	return &XATransaction{Statement: statement, XID: xid, OnePhase: onePhase}
+}
+
+// Resolved implements the sql.Node interface.
+func (x *XATransaction) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (x *XATransaction) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (x *XATransaction) Schema() sql.Schema { return nil }
+
+// WithChildren implements the sql.Node interface.
+func (x *XATransaction) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(x, len(children), 0)
+	}
+	return x, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (x *XATransaction) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var err error
+	switch x.Statement {
+	case XAStart:
+		err = x.Committer.XAStart(ctx.Session.ID(), x.XID)
+	case XAEnd:
+		err = x.Committer.XAEnd(ctx.Session.ID(), x.XID)
+	case XAPrepare:
+		err = x.Committer.XAPrepare(ctx, x.XID)
+	case XACommit:
+		err = x.Committer.XACommit(ctx, x.XID, x.OnePhase)
+	case XARollback:
+		err = x.Committer.XARollback(ctx, x.XID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sql.RowsToRowIter(), nil
+}
+
+func (x *XATransaction) String() string {
+	str := fmt.Sprintf("XA %s %s", x.Statement, x.XID)
+	if x.OnePhase {
+		str += " ONE PHASE"
+	}
+	return str
+}
+
+// XARecover is the XA RECOVER statement, which lists the prepared XA transactions.
+type XARecover struct {
+	// ConvertXID is set for XA RECOVER CONVERT XID, which shows the data of the xids in hexadecimal.
+	ConvertXID bool
+	Committer  *sql.TwoPhaseCommitter
+}
+
+var _ sql.Node = (*XARecover)(nil)
+
+// NewXARecover creates a new XARecover node.
+func NewXARecover(convertXID bool) *XARecover {
+	return &XARecover{ConvertXID: convertXID}
+}
+
+// Resolved implements the sql.Node interface.
+func (x *XARecover) Resolved() bool { return true }
+
+// Children implements the sql.Node interface.
+func (x *XARecover) Children() []sql.Node { return nil }
+
+// Schema implements the sql.Node interface.
+func (x *XARecover) Schema() sql.Schema {
+	return sql.Schema{
+		{Name: "formatID", Type: sql.Int64},
+		{Name: "gtrid_length", Type: sql.Int64},
+		{Name: "bqual_length", Type: sql.Int64},
+		{Name: "data", Type: sql.LongText},
+	}
+}
+
+// WithChildren implements the sql.Node interface.
+func (x *XARecover) WithChildren(children ...sql.Node) (sql.Node, error) {
+	if len(children) != 0 {
+		return nil, sql.ErrInvalidChildrenNumber.New(x, len(children), 0)
+	}
+	return x, nil
+}
+
+// RowIter implements the sql.Node interface.
+func (x *XARecover) RowIter(ctx *sql.Context, row sql.Row) (sql.RowIter, error) {
+	var rows []sql.Row
+	for _, xid := range x.Committer.XARecover() {
+		data := xid.Data()
+		if x.ConvertXID {
+			data = xid.HexData()
+		}
+		rows = append(rows, sql.NewRow(xid.FormatID, int64(len(xid.GTRID)), int64(len(xid.BQUAL)), data))
+	}
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (x *XARecover) String() string {
+	if x.ConvertXID {
+		return "XA RECOVER CONVERT XID"
+	}
+	return "XA RECOVER"
+}
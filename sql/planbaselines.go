@@ -0,0 +1,113 @@
+package sql
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// PlanBaseline is the plan pinned for the statements with a digest, as pinned with PIN PLAN BASELINE. The plan is
+// kept as the optimizer hints that make the analyzer choose it again, which are added before the hints of the
+// outermost query block of every statement with the digest, so they take precedence over them.
+type PlanBaseline struct {
+	// Digest is the digest of the statements the plan is pinned for.
+	Digest string
+	// DigestText is the normalized text of the statements the digest is the hash of.
+	DigestText string
+	// Hints are the optimizer hints that choose the plan.
+	Hints Hints
+	// Plan is the plan as EXPLAIN described it when it was pinned.
+	Plan string
+	// Uses is the number of statements that have used the plan since it was pinned.
+	Uses int64
+}
+
+// PlanBaselineRegistry holds the plan baselines of a catalog, keyed by digest. Baselines are only kept in memory:
+// integrators that persist them can list them with PlanBaselines and pin them again with PinPlanBaseline.
+type PlanBaselineRegistry struct {
+	mu        sync.RWMutex
+	baselines map[string]*pinnedPlan
+	// count is the number of baselines, so statements are only digested when there are any.
+	count int64
+}
+
+// pinnedPlan is a plan baseline of a registry, whose uses are counted apart from it, since statements count them
+// concurrently.
+type pinnedPlan struct {
+	baseline PlanBaseline
+	uses     int64
+}
+
+func (p *pinnedPlan) get() PlanBaseline {
+	b := p.baseline
+	b.Uses = atomic.LoadInt64(&p.uses)
+	return b
+}
+
+// NewPlanBaselineRegistry returns a new empty registry.
+func NewPlanBaselineRegistry() *PlanBaselineRegistry {
+	return &PlanBaselineRegistry{baselines: make(map[string]*pinnedPlan)}
+}
+
+// HasPlanBaselines returns whether any plan is pinned.
+func (r *PlanBaselineRegistry) HasPlanBaselines() bool {
+	return atomic.LoadInt64(&r.count) > 0
+}
+
+// PinPlanBaseline pins the plan of the baseline given for its digest, replacing the plan pinned for it before, if any.
+func (r *PlanBaselineRegistry) PinPlanBaseline(b PlanBaseline) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.baselines[b.Digest] = &pinnedPlan{baseline: b, uses: b.Uses}
+	atomic.StoreInt64(&r.count, int64(len(r.baselines)))
+}
+
+// UsePlanBaseline returns the baseline pinned for the digest given, if any, and counts a use of it.
+func (r *PlanBaselineRegistry) UsePlanBaseline(digest string) (PlanBaseline, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.baselines[digest]
+	if !ok {
+		return PlanBaseline{}, false
+	}
+	atomic.AddInt64(&p.uses, 1)
+	return p.get(), true
+}
+
+// PlanBaselines returns all the plan baselines, sorted by their digest texts.
+func (r *PlanBaselineRegistry) PlanBaselines() []PlanBaseline {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	baselines := make([]PlanBaseline, 0, len(r.baselines))
+	for _, p := range r.baselines {
+		baselines = append(baselines, p.get())
+	}
+	sort.Slice(baselines, func(i, j int) bool {
+		if baselines[i].DigestText != baselines[j].DigestText {
+			return baselines[i].DigestText < baselines[j].DigestText
+		}
+		return baselines[i].Digest < baselines[j].Digest
+	})
+	return baselines
+}
+
+// PurgePlanBaseline removes the baseline of the digest given, and returns whether there was one.
+func (r *PlanBaselineRegistry) PurgePlanBaseline(digest string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.baselines[digest]
+	delete(r.baselines, digest)
+	atomic.StoreInt64(&r.count, int64(len(r.baselines)))
+	return ok
+}
+
+// PurgePlanBaselines removes all the baselines, and returns how many there were.
+func (r *PlanBaselineRegistry) PurgePlanBaselines() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.baselines)
+	r.baselines = make(map[string]*pinnedPlan)
+	atomic.StoreInt64(&r.count, 0)
+	return n
+}
@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanBaselineRegistry(t *testing.T) {
+	require := require.New(t)
+	r := NewPlanBaselineRegistry()
+	require.False(r.HasPlanBaselines())
+
+	r.PinPlanBaseline(PlanBaseline{Digest: "b", DigestText: "SELECT * FROM t2", Hints: Hints{{Name: NoIndexHint, Args: []string{"t2"}}}})
+	r.PinPlanBaseline(PlanBaseline{Digest: "a", DigestText: "SELECT * FROM t1"})
+	require.True(r.HasPlanBaselines())
+
+	_, ok := r.UsePlanBaseline("c")
+	require.False(ok)
+	b, ok := r.UsePlanBaseline("b")
+	require.True(ok)
+	require.Equal(int64(1), b.Uses)
+	b, _ = r.UsePlanBaseline("b")
+	require.Equal(int64(2), b.Uses)
+	require.Equal("NO_INDEX(t2)", b.Hints.String())
+
+	var digests []string
+	for _, b := range r.PlanBaselines() {
+		digests = append(digests, b.Digest)
+	}
+	require.Equal([]string{"a", "b"}, digests)
+
+	// Pinning a plan again for a digest replaces it, and restarts its uses
+	r.PinPlanBaseline(PlanBaseline{Digest: "b", DigestText: "SELECT * FROM t2"})
+	b, _ = r.UsePlanBaseline("b")
+	require.Equal(int64(1), b.Uses)
+	require.Empty(b.Hints)
+
+	require.True(r.PurgePlanBaseline("a"))
+	require.False(r.PurgePlanBaseline("a"))
+	require.Len(r.PlanBaselines(), 1)
+	require.Equal(1, r.PurgePlanBaselines())
+	require.False(r.HasPlanBaselines())
+	require.Empty(r.PlanBaselines())
+}
@@ -0,0 +1,57 @@
+package sql
+
+const (
+	// ReadOnlyVar is the system variable that makes the engine reject the statements that modify databases, except
+	// for the sessions of privileged users.
+	ReadOnlyVar = "read_only"
+	// SuperReadOnlyVar is the system variable that makes the engine reject the statements that modify databases for
+	// all sessions, including the ones of privileged users. It implies read_only.
+	SuperReadOnlyVar = "super_read_only"
+)
+
+// impliedReadOnly returns the read-only variable that setting the one given to the value given also sets, and its
+// value: enabling super_read_only enables read_only, and disabling read_only disables super_read_only.
+func impliedReadOnly(name string, value interface{}) (string, interface{}, bool) {
+	enabled := value != int8(0)
+	switch {
+	case name == SuperReadOnlyVar && enabled:
+		return ReadOnlyVar, value, true
+	case name == ReadOnlyVar && !enabled:
+		return SuperReadOnlyVar, value, true
+	default:
+		return "", nil, false
+	}
+}
+
+// ReadOnlyAuthorizer returns whether the user of the session of a context is privileged, so it can modify databases
+// while the engine is in read_only mode.
+type ReadOnlyAuthorizer func(ctx *Context) bool
+
+// SetReadOnlyAuthorizer sets the authorizer of the users who can modify databases in read_only mode. With no
+// authorizer, read_only applies to all users.
+func (c *Catalog) SetReadOnlyAuthorizer(authorizer ReadOnlyAuthorizer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readOnlyAuthorizer = authorizer
+}
+
+// CheckWritable returns an ErrReadOnly error if the session of the context given can't modify databases because of
+// the global values of the read_only and super_read_only system variables of the catalog.
+func (c *Catalog) CheckWritable(ctx *Context) error {
+	if c.GlobalVariables.Int64(SuperReadOnlyVar) != 0 {
+		return ErrReadOnly.New("--super-read-only")
+	}
+	if c.GlobalVariables.Int64(ReadOnlyVar) == 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	authorizer := c.readOnlyAuthorizer
+	c.mu.RUnlock()
+
+	if authorizer != nil && authorizer(ctx) {
+		return nil
+	}
+	return ErrReadOnly.New("--read-only")
+}
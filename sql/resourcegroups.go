@@ -0,0 +1,440 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultUserResourceGroup is the resource group of the sessions that aren't assigned to other groups.
+	DefaultUserResourceGroup = "USR_default"
+	// DefaultSystemResourceGroup is the default resource group for system threads.
+	DefaultSystemResourceGroup = "SYS_default"
+)
+
+// ResourceGroupType is the type of a resource group, which sets the range of thread priorities of the group and the
+// threads it can be assigned to.
+type ResourceGroupType byte
+
+const (
+	// ResourceGroupUser is the type of the groups for the sessions of users, with priorities from 0 to 19.
+	ResourceGroupUser ResourceGroupType = iota
+	// ResourceGroupSystem is the type of the groups for system threads, with priorities from -20 to 0.
+	ResourceGroupSystem
+)
+
+func (t ResourceGroupType) String() string {
+	if t == ResourceGroupSystem {
+		return "SYSTEM"
+	}
+	return "USER"
+}
+
+// priorityRange returns the lowest and highest thread priorities of the groups of the type.
+func (t ResourceGroupType) priorityRange() (int, int) {
+	if t == ResourceGroupSystem {
+		return -20, 0
+	}
+	return 0, 19
+}
+
+// ResourceGroup is a resource group, as created with CREATE RESOURCE GROUP. The queries of the sessions assigned to a
+// group share the execution slots of the parallel execution of the engine with the other groups according to the
+// weight of their group.
+type ResourceGroup struct {
+	// Name of the group.
+	Name string
+	// Type of the group.
+	Type ResourceGroupType
+	// VCPUs are the ids of the virtual CPUs of the group, or empty for all of them. The queries of the group can't
+	// hold more execution slots at the same time than the number of ids.
+	VCPUs []int
+	// ThreadPriority is the priority of the group, where lower numbers are higher priorities.
+	ThreadPriority int
+	// Enabled is whether the group can be assigned to sessions. The sessions assigned to a disabled group run in the
+	// default group.
+	Enabled bool
+}
+
+// Weight returns the scheduling weight of the group, which is the share of the execution slots its queries get when
+// they wait for slots along with the queries of other groups. Weights follow the nice values of Linux: each level of
+// priority gets 1.25 times the slots of the next one, and a priority of 0 has a weight of 1024.
+func (g ResourceGroup) Weight() float64 {
+	return 1024 / math.Pow(1.25, float64(g.ThreadPriority))
+}
+
+// VCPUString returns the VCPU ids of the group as written in CREATE RESOURCE GROUP, with ranges of consecutive ids.
+func (g ResourceGroup) VCPUString(cpus int) string {
+	ids := g.VCPUs
+	if len(ids) == 0 {
+		for i := 0; i < cpus; i++ {
+			ids = append(ids, i)
+		}
+	}
+
+	var ranges []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] == ids[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprint(ids[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", ids[i], ids[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// ResourceGroupRegistry holds the resource groups of a catalog and the groups sessions are assigned to, and schedules
+// the execution slots of the parallel execution of queries among the groups. There's one slot per CPU: partitions of
+// tables are read while holding a slot, and when the queries of several groups wait for slots, each group gets slots
+// in proportion to its weight, so queries of groups with low priorities can't starve the rest.
+type ResourceGroupRegistry struct {
+	mu       sync.Mutex
+	cpus     int
+	groups   map[string]ResourceGroup
+	sessions map[uint32]string
+
+	free      int
+	holders   map[uint64]int
+	schedules map[string]*groupSchedule
+	// clock is the virtual time of the last slot granted, which groups that have been idle start from.
+	clock float64
+}
+
+// groupSchedule is the scheduling state of a resource group: the slots its queries hold, the queries waiting for
+// slots, and its virtual time, which advances by the inverse of its weight with every slot granted. Slots go to the
+// waiting group with the lowest virtual time.
+type groupSchedule struct {
+	inUse   int
+	vtime   float64
+	waiters []*slotWaiter
+}
+
+type slotWaiter struct {
+	pid     uint64
+	ready   chan struct{}
+	granted bool
+}
+
+// NewResourceGroupRegistry returns a new registry with the default resource groups, scheduling as many execution slots
+// as the CPUs given, or as the CPUs of the machine if zero.
+func NewResourceGroupRegistry(cpus int) *ResourceGroupRegistry {
+	if cpus <= 0 {
+		cpus = runtime.NumCPU()
+	}
+
+	r := &ResourceGroupRegistry{
+		cpus:      cpus,
+		groups:    make(map[string]ResourceGroup),
+		sessions:  make(map[uint32]string),
+		free:      cpus,
+		holders:   make(map[uint64]int),
+		schedules: make(map[string]*groupSchedule),
+	}
+	for _, g := range []ResourceGroup{
+		{Name: DefaultUserResourceGroup, Type: ResourceGroupUser, Enabled: true},
+		{Name: DefaultSystemResourceGroup, Type: ResourceGroupSystem, Enabled: true},
+	} {
+		r.groups[strings.ToLower(g.Name)] = g
+	}
+	return r
+}
+
+// CPUs returns the number of CPUs of the registry, which is the number of execution slots it schedules.
+func (r *ResourceGroupRegistry) CPUs() int {
+	return r.cpus
+}
+
+// ResourceGroup returns the resource group with the name given, case insensitively, and whether it exists.
+func (r *ResourceGroupRegistry) ResourceGroup(name string) (ResourceGroup, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.groups[strings.ToLower(name)]
+	return g, ok
+}
+
+// ResourceGroups returns all the resource groups, sorted by name.
+func (r *ResourceGroupRegistry) ResourceGroups() []ResourceGroup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	groups := make([]ResourceGroup, 0, len(r.groups))
+	for _, g := range r.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Name) < strings.ToLower(groups[j].Name)
+	})
+	return groups
+}
+
+// CreateResourceGroup adds a new resource group.
+func (r *ResourceGroupRegistry) CreateResourceGroup(g ResourceGroup) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(g.Name)
+	if _, ok := r.groups[key]; ok {
+		return ErrResourceGroupExists.New(g.Name)
+	}
+	if err := r.validate(g); err != nil {
+		return err
+	}
+
+	r.groups[key] = g
+	return nil
+}
+
+// AlterResourceGroup replaces the attributes of the existing resource group with the name of the one given. The type
+// of a group can't change, and the default groups can't be altered. The sessions assigned to a group that is disabled
+// run in the default group until it's enabled again, or are moved to the default group with force.
+func (r *ResourceGroupRegistry) AlterResourceGroup(g ResourceGroup, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(g.Name)
+	existing, ok := r.groups[key]
+	if !ok {
+		return ErrResourceGroupNotExists.New(g.Name)
+	}
+	if isDefaultResourceGroup(existing.Name) {
+		return ErrDefaultResourceGroup.New("ALTER", existing.Name)
+	}
+
+	g.Name, g.Type = existing.Name, existing.Type
+	if err := r.validate(g); err != nil {
+		return err
+	}
+
+	r.groups[key] = g
+	if !g.Enabled && force {
+		r.unassign(key)
+	}
+	r.dispatch()
+	return nil
+}
+
+// DropResourceGroup removes the resource group with the name given. Groups assigned to sessions can only be dropped
+// with force, which moves the sessions to the default group.
+func (r *ResourceGroupRegistry) DropResourceGroup(name string, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(name)
+	g, ok := r.groups[key]
+	if !ok {
+		return ErrResourceGroupNotExists.New(name)
+	}
+	if isDefaultResourceGroup(g.Name) {
+		return ErrDefaultResourceGroup.New("DROP", g.Name)
+	}
+
+	if !force {
+		for _, assigned := range r.sessions {
+			if assigned == key {
+				return ErrResourceGroupBusy.New(g.Name)
+			}
+		}
+	}
+
+	r.unassign(key)
+	delete(r.groups, key)
+	return nil
+}
+
+// unassign moves the sessions assigned to the group with the key given to the default group.
+func (r *ResourceGroupRegistry) unassign(key string) {
+	for id, assigned := range r.sessions {
+		if assigned == key {
+			delete(r.sessions, id)
+		}
+	}
+}
+
+// SetSessionResourceGroup assigns the session with the id given to the resource group with the name given, which must
+// be an enabled group of type USER.
+func (r *ResourceGroupRegistry) SetSessionResourceGroup(sessionID uint32, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(name)
+	g, ok := r.groups[key]
+	if !ok {
+		return ErrResourceGroupNotExists.New(name)
+	}
+	if !g.Enabled {
+		return ErrResourceGroupDisabled.New(g.Name)
+	}
+	if g.Type != ResourceGroupUser {
+		return ErrResourceGroupBind.New(g.Name, sessionID, "SYSTEM resource groups can only be assigned to system threads")
+	}
+
+	if key == strings.ToLower(DefaultUserResourceGroup) {
+		delete(r.sessions, sessionID)
+	} else {
+		r.sessions[sessionID] = key
+	}
+	return nil
+}
+
+// UnsetSessionResourceGroup moves the session with the id given back to the default group. Sessions are unset when
+// they are closed.
+func (r *ResourceGroupRegistry) UnsetSessionResourceGroup(sessionID uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, sessionID)
+}
+
+// SessionResourceGroup returns the resource group the queries of the session with the id given run in: the group the
+// session is assigned to, or the default group if it isn't assigned to any or its group is disabled.
+func (r *ResourceGroupRegistry) SessionResourceGroup(sessionID uint32) ResourceGroup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessionGroup(sessionID)
+}
+
+func (r *ResourceGroupRegistry) sessionGroup(sessionID uint32) ResourceGroup {
+	if g, ok := r.groups[r.sessions[sessionID]]; ok && g.Enabled {
+		return g
+	}
+	return r.groups[strings.ToLower(DefaultUserResourceGroup)]
+}
+
+func isDefaultResourceGroup(name string) bool {
+	return strings.EqualFold(name, DefaultUserResourceGroup) || strings.EqualFold(name, DefaultSystemResourceGroup)
+}
+
+func (r *ResourceGroupRegistry) validate(g ResourceGroup) error {
+	low, high := g.Type.priorityRange()
+	if g.ThreadPriority < low || g.ThreadPriority > high {
+		return ErrInvalidThreadPriority.New(g.ThreadPriority, g.Type, g.Name, low, high)
+	}
+	for _, id := range g.VCPUs {
+		if id < 0 || id >= r.cpus {
+			return ErrInvalidVCPUID.New(id)
+		}
+	}
+	return nil
+}
+
+// AcquireSlot waits for an execution slot for the query of the context given in the resource group of its session, and
+// returns the function that releases it, which must be called once. Queries that already hold a slot get more right
+// away, since they may be needed to finish the work of the slots they hold, such as the partitions of subqueries. It
+// returns the error of the context if it's done before a slot is granted.
+func (r *ResourceGroupRegistry) AcquireSlot(ctx *Context) (func(), error) {
+	var sessionID uint32
+	if ctx.Session != nil {
+		sessionID = ctx.Session.ID()
+	}
+	pid := ctx.Pid()
+
+	r.mu.Lock()
+	name := strings.ToLower(r.sessionGroup(sessionID).Name)
+	s, ok := r.schedules[name]
+	if !ok {
+		s = &groupSchedule{vtime: r.clock}
+		r.schedules[name] = s
+	}
+
+	if r.holders[pid] > 0 {
+		r.grant(name, s, pid)
+		r.mu.Unlock()
+		return r.releaser(name, pid), nil
+	}
+
+	if s.inUse == 0 && len(s.waiters) == 0 && s.vtime < r.clock {
+		s.vtime = r.clock
+	}
+	w := &slotWaiter{pid: pid, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	r.dispatch()
+	r.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return r.releaser(name, pid), nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if w.granted {
+			r.release(name, pid)
+		} else {
+			for i, waiter := range s.waiters {
+				if waiter == w {
+					s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (r *ResourceGroupRegistry) releaser(name string, pid uint64) func() {
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.release(name, pid)
+	}
+}
+
+func (r *ResourceGroupRegistry) grant(name string, s *groupSchedule, pid uint64) {
+	r.free--
+	r.holders[pid]++
+	s.inUse++
+	if s.vtime > r.clock {
+		r.clock = s.vtime
+	}
+
+	weight := ResourceGroup{}.Weight()
+	if g, ok := r.groups[name]; ok {
+		weight = g.Weight()
+	}
+	s.vtime += 1 / weight
+}
+
+func (r *ResourceGroupRegistry) release(name string, pid uint64) {
+	r.free++
+	if r.holders[pid]--; r.holders[pid] <= 0 {
+		delete(r.holders, pid)
+	}
+	r.schedules[name].inUse--
+	r.dispatch()
+}
+
+// dispatch grants the free slots to the waiting queries of the groups with the lowest virtual times that haven't
+// reached the limit of slots of their VCPUs.
+func (r *ResourceGroupRegistry) dispatch() {
+	for r.free > 0 {
+		var next *groupSchedule
+		var nextName string
+		for name, s := range r.schedules {
+			if len(s.waiters) == 0 {
+				continue
+			}
+			if g, ok := r.groups[name]; ok && len(g.VCPUs) > 0 && s.inUse >= len(g.VCPUs) {
+				continue
+			}
+			if next == nil || s.vtime < next.vtime {
+				next, nextName = s, name
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		w := next.waiters[0]
+		next.waiters = next.waiters[1:]
+		r.grant(nextName, next, w.pid)
+		w.granted = true
+		close(w.ready)
+	}
+}
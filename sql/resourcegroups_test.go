@@ -0,0 +1,176 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceGroupRegistry(t *testing.T) {
+	require := require.New(t)
+	r := NewResourceGroupRegistry(4)
+
+	require.NoError(r.CreateResourceGroup(ResourceGroup{Name: "Batch", Type: ResourceGroupUser, VCPUs: []int{2, 3}, ThreadPriority: 19, Enabled: true}))
+	require.True(ErrResourceGroupExists.Is(r.CreateResourceGroup(ResourceGroup{Name: "batch"})))
+	require.True(ErrInvalidThreadPriority.Is(r.CreateResourceGroup(ResourceGroup{Name: "rg", Type: ResourceGroupUser, ThreadPriority: -1})))
+	require.True(ErrInvalidThreadPriority.Is(r.CreateResourceGroup(ResourceGroup{Name: "rg", Type: ResourceGroupSystem, ThreadPriority: 1})))
+	require.True(ErrInvalidVCPUID.Is(r.CreateResourceGroup(ResourceGroup{Name: "rg", VCPUs: []int{4}})))
+	require.NoError(r.CreateResourceGroup(ResourceGroup{Name: "sys", Type: ResourceGroupSystem, ThreadPriority: -5, Enabled: true}))
+
+	var names []string
+	for _, g := range r.ResourceGroups() {
+		names = append(names, g.Name)
+	}
+	require.Equal([]string{"Batch", "sys", DefaultSystemResourceGroup, DefaultUserResourceGroup}, names)
+
+	g, ok := r.ResourceGroup("BATCH")
+	require.True(ok)
+	require.Equal("2-3", g.VCPUString(4))
+	require.Equal("0-3", ResourceGroup{}.VCPUString(4))
+	require.Equal("0,2-3,5", ResourceGroup{VCPUs: []int{0, 2, 3, 5}}.VCPUString(4))
+
+	// Sessions run in the default group unless they are assigned to an enabled group
+	require.Equal(DefaultUserResourceGroup, r.SessionResourceGroup(1).Name)
+	require.NoError(r.SetSessionResourceGroup(1, "batch"))
+	require.Equal("Batch", r.SessionResourceGroup(1).Name)
+	require.True(ErrResourceGroupBind.Is(r.SetSessionResourceGroup(1, "sys")))
+	require.True(ErrResourceGroupNotExists.Is(r.SetSessionResourceGroup(1, "nope")))
+
+	g.Enabled = false
+	require.NoError(r.AlterResourceGroup(g, false))
+	require.Equal(DefaultUserResourceGroup, r.SessionResourceGroup(1).Name)
+	require.True(ErrResourceGroupDisabled.Is(r.SetSessionResourceGroup(2, "batch")))
+	g.Enabled = true
+	require.NoError(r.AlterResourceGroup(g, false))
+	require.Equal("Batch", r.SessionResourceGroup(1).Name)
+
+	require.True(ErrDefaultResourceGroup.Is(r.AlterResourceGroup(ResourceGroup{Name: DefaultUserResourceGroup}, false)))
+	require.True(ErrDefaultResourceGroup.Is(r.DropResourceGroup(DefaultSystemResourceGroup, true)))
+
+	require.True(ErrResourceGroupBusy.Is(r.DropResourceGroup("batch", false)))
+	require.NoError(r.DropResourceGroup("batch", true))
+	require.Equal(DefaultUserResourceGroup, r.SessionResourceGroup(1).Name)
+	require.True(ErrResourceGroupNotExists.Is(r.DropResourceGroup("batch", false)))
+}
+
+func newResourceGroupContext(r *ResourceGroupRegistry, group string, id uint32) *Context {
+	ctx := NewContext(context.Background(), WithSession(NewSession("", "", "", id)), WithPid(uint64(id)))
+	if group != "" {
+		if err := r.SetSessionResourceGroup(id, group); err != nil {
+			panic(err)
+		}
+	}
+	return ctx
+}
+
+// waitForWaiters waits until the number of queries waiting for slots in the registry is the one given.
+func waitForWaiters(t *testing.T, r *ResourceGroupRegistry, n int) {
+	require.Eventually(t, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		var waiting int
+		for _, s := range r.schedules {
+			waiting += len(s.waiters)
+		}
+		return waiting == n
+	}, time.Second, time.Millisecond)
+}
+
+func TestResourceGroupScheduling(t *testing.T) {
+	require := require.New(t)
+	r := NewResourceGroupRegistry(1)
+	require.NoError(r.CreateResourceGroup(ResourceGroup{Name: "batch", Type: ResourceGroupUser, ThreadPriority: 19, Enabled: true}))
+
+	release, err := r.AcquireSlot(newResourceGroupContext(r, "", 1))
+	require.NoError(err)
+
+	var mu sync.Mutex
+	var grants []string
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		group := "batch"
+		if i%2 == 0 {
+			group = DefaultUserResourceGroup
+		}
+		ctx := newResourceGroupContext(r, group, uint32(i+2))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := r.AcquireSlot(ctx)
+			if err != nil {
+				panic(err)
+			}
+			mu.Lock()
+			grants = append(grants, group)
+			mu.Unlock()
+			release()
+		}()
+	}
+
+	waitForWaiters(t, r, 20)
+	release()
+	wg.Wait()
+
+	// The queries of the default group have 70 times the weight of the batch ones, so they get the slot first
+	var batch int
+	for _, g := range grants[:10] {
+		if g == "batch" {
+			batch++
+		}
+	}
+	require.True(batch <= 1, fmt.Sprint(grants))
+	require.Equal(1, r.free)
+}
+
+func TestResourceGroupSchedulingLimits(t *testing.T) {
+	require := require.New(t)
+	r := NewResourceGroupRegistry(2)
+	require.NoError(r.CreateResourceGroup(ResourceGroup{Name: "batch", Type: ResourceGroupUser, VCPUs: []int{1}, Enabled: true}))
+
+	// Groups can't hold more slots than their VCPUs
+	release1, err := r.AcquireSlot(newResourceGroupContext(r, "batch", 1))
+	require.NoError(err)
+	acquired := make(chan func())
+	go func() {
+		release, err := r.AcquireSlot(newResourceGroupContext(r, "batch", 2))
+		if err != nil {
+			panic(err)
+		}
+		acquired <- release
+	}()
+	waitForWaiters(t, r, 1)
+	ctx := newResourceGroupContext(r, "", 3)
+	release2, err := r.AcquireSlot(ctx)
+	require.NoError(err)
+
+	// Queries that hold slots get more right away
+	release3, err := r.AcquireSlot(ctx)
+	require.NoError(err)
+	require.Equal(-1, r.free)
+	release3()
+	release2()
+
+	release1()
+	(<-acquired)()
+
+	// Queries stop waiting when their context is done
+	release1, err = r.AcquireSlot(newResourceGroupContext(r, "batch", 1))
+	require.NoError(err)
+	cancelled, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := r.AcquireSlot(newResourceGroupContext(r, "batch", 2).WithContext(cancelled))
+		done <- err
+	}()
+	waitForWaiters(t, r, 1)
+	cancel()
+	require.Equal(context.Canceled, <-done)
+	waitForWaiters(t, r, 0)
+	release1()
+	require.Equal(2, r.free)
+}
@@ -0,0 +1,249 @@
+package sql
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// QueryCacheTypeSessionVar is the session variable controlling whether query results are served from and stored in
+// the engine's result cache. It takes the same values as MySQL's query_cache_type: OFF (0) never caches, ON (1)
+// caches every eligible query not marked SQL_NO_CACHE, and DEMAND (2) only caches queries marked SQL_CACHE.
+const QueryCacheTypeSessionVar = "query_cache_type"
+
+// QueryCacheType is the caching mode selected by the query_cache_type session variable.
+type QueryCacheType byte
+
+const (
+	QueryCacheOff QueryCacheType = iota
+	QueryCacheOn
+	QueryCacheDemand
+)
+
+// QueryCacheTypeFromValue interprets the value of the query_cache_type session variable. Unknown values disable the
+// cache.
+func QueryCacheTypeFromValue(v interface{}) QueryCacheType {
+	switch strings.ToLower(fmt.Sprintf("%v", v)) {
+	case "1", "on", "true":
+		return QueryCacheOn
+	case "2", "demand":
+		return QueryCacheDemand
+	default:
+		return QueryCacheOff
+	}
+}
+
+// TableChangeListener is notified whenever the data or definition of a table changes.
+type TableChangeListener interface {
+	// TableChanged is called after the named table has been modified.
+	TableChanged(database, table string)
+}
+
+// ChangeNotifyingDatabase is a Database whose tables can change outside of the statements run by the engine (for
+// example, through replication or direct writes by the integrator), and that can notify listeners of such changes.
+type ChangeNotifyingDatabase interface {
+	Database
+	// AddTableChangeListener registers a listener to be notified of every change to a table in this database.
+	AddTableChangeListener(listener TableChangeListener)
+}
+
+// ResultCache caches the complete results of deterministic, read-only queries. Entries expire after a fixed TTL
+// and are invalidated whenever one of the tables they read from changes. Tables are tracked by name only, so a
+// change to a table invalidates cached results of same-named tables in other databases as well.
+type ResultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries *lru.Cache
+	tables  map[string]map[string]struct{}
+	version uint64
+}
+
+var _ TableChangeListener = (*ResultCache)(nil)
+var _ CatalogChangeListener = (*ResultCache)(nil)
+
+type cachedResult struct {
+	schema  Schema
+	rows    []Row
+	tables  []string
+	expires time.Time
+}
+
+// NewResultCache returns a new result cache holding at most size entries, each of which expires after the given ttl.
+// A ttl of zero means entries only leave the cache when invalidated or evicted.
+func NewResultCache(size int, ttl time.Duration) *ResultCache {
+	c := &ResultCache{
+		ttl:    ttl,
+		tables: make(map[string]map[string]struct{}),
+	}
+	c.entries, _ = lru.NewWithEvict(size, c.onEvict)
+	return c
+}
+
+// Version returns the current invalidation version of the cache. Callers should read it before executing a query
+// and pass it to Put, so that results computed concurrently with a change are never stored.
+func (c *ResultCache) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Get returns the cached result for the key given, if there is one that hasn't expired. The rows returned are copies
+// of the cached ones, so callers may modify them.
+func (c *ResultCache) Get(key string) (Schema, []Row, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.entries.Get(key)
+	if !ok {
+		return nil, nil, false
+	}
+
+	result := v.(*cachedResult)
+	if c.ttl > 0 && time.Now().After(result.expires) {
+		c.entries.Remove(key)
+		return nil, nil, false
+	}
+
+	rows := make([]Row, len(result.rows))
+	for i, row := range result.rows {
+		rows[i] = row.Copy()
+	}
+	return result.schema, rows, true
+}
+
+// Put stores the result of a query reading from the tables given. The result is discarded if the cache has been
+// invalidated since version was obtained.
+func (c *ResultCache) Put(key string, version uint64, tables []string, schema Schema, rows []Row) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		return
+	}
+
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = strings.ToLower(t)
+	}
+
+	c.entries.Add(key, &cachedResult{
+		schema:  schema,
+		rows:    rows,
+		tables:  names,
+		expires: time.Now().Add(c.ttl),
+	})
+
+	for _, t := range names {
+		keys, ok := c.tables[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tables[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// TableChanged implements TableChangeListener by invalidating every entry that read from the table given.
+func (c *ResultCache) TableChanged(database, table string) {
+	c.InvalidateTable(table)
+}
+
+// DatabaseAdded implements CatalogChangeListener by subscribing to the changes made to the tables of the database
+// given outside the engine, if it notifies them.
+func (c *ResultCache) DatabaseAdded(db Database) {
+	if notifier, ok := db.(ChangeNotifyingDatabase); ok {
+		notifier.AddTableChangeListener(c)
+	}
+}
+
+// DatabaseRemoved implements CatalogChangeListener by removing all entries, since tables are tracked by name only.
+func (c *ResultCache) DatabaseRemoved(db Database) {
+	c.Clear()
+}
+
+// InvalidateTable removes every entry that read from the table with the name given.
+func (c *ResultCache) InvalidateTable(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	for key := range c.tables[strings.ToLower(table)] {
+		c.entries.Remove(key)
+	}
+}
+
+// Clear removes all entries from the cache.
+func (c *ResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.entries.Purge()
+}
+
+// Len returns the number of entries in the cache, including any that have expired but not yet been removed.
+func (c *ResultCache) Len() int {
+	return c.entries.Len()
+}
+
+// onEvict keeps the table index in sync with the entries. It's always called with the lock held.
+func (c *ResultCache) onEvict(key interface{}, value interface{}) {
+	for _, t := range value.(*cachedResult).tables {
+		keys := c.tables[t]
+		delete(keys, key.(string))
+		if len(keys) == 0 {
+			delete(c.tables, t)
+		}
+	}
+}
+
+// NewCachingRowIter returns an iterator that passes through the rows of iter, and stores them in the cache under the
+// key given once the iterator has been completely consumed.
+func NewCachingRowIter(c *ResultCache, key string, version uint64, tables []string, schema Schema, iter RowIter) RowIter {
+	return &cachingRowIter{
+		cache:   c,
+		key:     key,
+		version: version,
+		tables:  tables,
+		schema:  schema,
+		iter:    iter,
+	}
+}
+
+type cachingRowIter struct {
+	cache   *ResultCache
+	key     string
+	version uint64
+	tables  []string
+	schema  Schema
+	iter    RowIter
+	rows    []Row
+	failed  bool
+}
+
+func (i *cachingRowIter) Next() (Row, error) {
+	row, err := i.iter.Next()
+	if err == io.EOF {
+		if !i.failed {
+			i.cache.Put(i.key, i.version, i.tables, i.schema, i.rows)
+		}
+		i.rows = nil
+		return nil, err
+	} else if err != nil {
+		i.failed = true
+		i.rows = nil
+		return nil, err
+	}
+
+	// Rows are copied, so the nodes reading them don't change the cached ones
+	i.rows = append(i.rows, row.Copy())
+	return row, nil
+}
+
+func (i *cachingRowIter) Close() error {
+	return i.iter.Close()
+}
@@ -0,0 +1,109 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache(t *testing.T) {
+	schema := Schema{{Name: "a", Type: Int64}}
+	rows := []Row{NewRow(int64(1)), NewRow(int64(2))}
+
+	t.Run("get and put", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, 0)
+
+		_, _, ok := c.Get("q")
+		require.False(ok)
+
+		c.Put("q", c.Version(), []string{"t"}, schema, rows)
+		sch, result, ok := c.Get("q")
+		require.True(ok)
+		require.Equal(schema, sch)
+		require.Equal(rows, result)
+	})
+
+	t.Run("remove database", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, 0)
+
+		version := c.Version()
+		c.Put("q", version, []string{"t"}, schema, rows)
+
+		c.DatabaseRemoved(nil)
+		_, _, ok := c.Get("q")
+		require.False(ok)
+		require.NotEqual(version, c.Version())
+	})
+
+	t.Run("invalidate table", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, 0)
+
+		c.Put("q1", c.Version(), []string{"t1", "t2"}, schema, rows)
+		c.Put("q2", c.Version(), []string{"T3"}, schema, rows)
+
+		c.TableChanged("db", "T2")
+		_, _, ok := c.Get("q1")
+		require.False(ok)
+		_, _, ok = c.Get("q2")
+		require.True(ok)
+
+		c.InvalidateTable("t3")
+		_, _, ok = c.Get("q2")
+		require.False(ok)
+		require.Equal(0, c.Len())
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, 0)
+
+		v := c.Version()
+		c.InvalidateTable("t")
+		c.Put("q", v, []string{"t"}, schema, rows)
+		_, _, ok := c.Get("q")
+		require.False(ok)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, time.Millisecond)
+
+		c.Put("q", c.Version(), []string{"t"}, schema, rows)
+		time.Sleep(5 * time.Millisecond)
+		_, _, ok := c.Get("q")
+		require.False(ok)
+	})
+
+	t.Run("caching iter", func(t *testing.T) {
+		require := require.New(t)
+		c := NewResultCache(10, 0)
+
+		iter := NewCachingRowIter(c, "q", c.Version(), []string{"t"}, schema, RowsToRowIter(rows...))
+		row, err := iter.Next()
+		require.NoError(err)
+		require.Equal(rows[0], row)
+
+		_, _, ok := c.Get("q")
+		require.False(ok)
+
+		result, err := RowIterToRows(iter)
+		require.NoError(err)
+		require.Equal(rows[1:], result)
+
+		_, cached, ok := c.Get("q")
+		require.True(ok)
+		require.Equal(rows, cached)
+	})
+}
+
+func TestQueryCacheTypeFromValue(t *testing.T) {
+	require.Equal(t, QueryCacheOn, QueryCacheTypeFromValue("ON"))
+	require.Equal(t, QueryCacheOn, QueryCacheTypeFromValue(int8(1)))
+	require.Equal(t, QueryCacheDemand, QueryCacheTypeFromValue("demand"))
+	require.Equal(t, QueryCacheOff, QueryCacheTypeFromValue("OFF"))
+	require.Equal(t, QueryCacheOff, QueryCacheTypeFromValue(nil))
+}
@@ -76,8 +76,12 @@ type RowIter interface {
 	Close() error
 }
 
-// RowIterToRows converts a row iterator to a slice of rows.
+// RowIterToRows converts a row iterator to a slice of rows. Iterators that support batches are read in batches.
 func RowIterToRows(i RowIter) ([]Row, error) {
+	if b, ok := i.(BatchRowIter); ok {
+		return BatchRowIterToRows(b)
+	}
+
 	var rows []Row
 	for {
 		row, err := i.Next()
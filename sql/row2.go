@@ -0,0 +1,301 @@
+package sql
+
+import (
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+	"github.com/dolthub/vitess/go/vt/proto/query"
+)
+
+// Value is a single encoded value of a Row2: its wire type and its bytes in the encoding of the MySQL text protocol,
+// which is the same one produced by Type.SQL. A nil Val is a NULL value.
+type Value struct {
+	Typ query.Type
+	Val []byte
+}
+
+// NullValue is the Value of SQL NULL.
+var NullValue = Value{Typ: query.Type_NULL_TYPE}
+
+// IsNull returns whether this value is NULL.
+func (v Value) IsNull() bool {
+	return v.Val == nil
+}
+
+// ToSQL returns this value as a sqltypes.Value, without copying its bytes.
+func (v Value) ToSQL() sqltypes.Value {
+	if v.IsNull() {
+		return sqltypes.NULL
+	}
+	return sqltypes.MakeTrusted(v.Typ, v.Val)
+}
+
+// EncodeValue returns the Value of v, which must be a value of the type given.
+func EncodeValue(typ Type, v interface{}) (Value, error) {
+	if v == nil {
+		return NullValue, nil
+	}
+
+	sqlVal, err := typ.SQL(v)
+	if err != nil {
+		return Value{}, err
+	}
+
+	if sqlVal.IsNull() {
+		return NullValue, nil
+	}
+
+	val := sqlVal.Raw()
+	if val == nil {
+		val = []byte{}
+	}
+
+	return Value{Typ: sqlVal.Type(), Val: val}, nil
+}
+
+// DecodeValue returns the value of the type given encoded in v, as it would be found in a Row.
+func DecodeValue(typ Type, v Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+
+	// The text encoding of bits is their number, which would be converted from a string as the bytes of the bits
+	// instead.
+	if _, ok := typ.(BitType); ok {
+		n, err := strconv.ParseUint(string(v.Val), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return typ.Convert(n)
+	}
+
+	return typ.Convert(string(v.Val))
+}
+
+// Row2 is a row of encoded values. Unlike Row, iterating over Row2 values doesn't require boxing every value of every
+// row in an interface{}, so tables that store their rows encoded can pass them through the operators that support it
+// without per-row allocations. Tables that encode their rows as they're read, like memory tables, are slower to read
+// as Row2 values than as Rows. The engine doesn't run queries with Row2 values yet: only integrators calling RowIter2
+// on a Node2 do. Row2 values are usually backed by a RowFrame, and are only valid until the frame is next cleared.
+type Row2 []Value
+
+// GetField returns the value at the index given.
+func (r Row2) GetField(i int) Value {
+	return r[i]
+}
+
+// Len returns the number of values in this row.
+func (r Row2) Len() int {
+	return len(r)
+}
+
+// Copy returns a copy of this row that doesn't share any memory with it.
+func (r Row2) Copy() Row2 {
+	var size int
+	for _, v := range r {
+		size += len(v.Val)
+	}
+
+	buf := make([]byte, 0, size)
+	row := make(Row2, len(r))
+	for i, v := range r {
+		row[i] = v
+		if !v.IsNull() {
+			start := len(buf)
+			buf = append(buf, v.Val...)
+			row[i].Val = buf[start:len(buf):len(buf)]
+		}
+	}
+
+	return row
+}
+
+// RowToRow2 encodes a row with the schema given.
+func RowToRow2(sch Schema, row Row) (Row2, error) {
+	row2 := make(Row2, len(row))
+	for i, v := range row {
+		var err error
+		row2[i], err = EncodeValue(sch[i].Type, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return row2, nil
+}
+
+// Row2ToRow decodes a row with the schema given.
+func Row2ToRow(sch Schema, row2 Row2) (Row, error) {
+	row := make(Row, len(row2))
+	for i, v := range row2 {
+		var err error
+		row[i], err = DecodeValue(sch[i].Type, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return row, nil
+}
+
+// RowFrame holds the values of the Row2 being built by a RowIter2. The bytes of the values appended to it are copied
+// into a buffer owned by the frame, which is reused every time the frame is cleared, so iterating over many rows with
+// the same frame doesn't need to allocate for every one. Frames should be obtained with NewRowFrame and returned with
+// Recycle once they're no longer used.
+type RowFrame struct {
+	values Row2
+	buf    []byte
+}
+
+var rowFramePool = sync.Pool{
+	New: func() interface{} {
+		return &RowFrame{}
+	},
+}
+
+// NewRowFrame returns an empty frame from the pool.
+func NewRowFrame() *RowFrame {
+	return rowFramePool.Get().(*RowFrame)
+}
+
+// Recycle clears this frame and returns it to the pool. The frame must not be used afterwards.
+func (f *RowFrame) Recycle() {
+	f.Clear()
+	rowFramePool.Put(f)
+}
+
+// Clear removes all the values from this frame. Any Row2 previously returned by Row2 becomes invalid.
+func (f *RowFrame) Clear() {
+	f.values = f.values[:0]
+	f.buf = f.buf[:0]
+}
+
+// Append appends the values given to this frame, copying their bytes.
+func (f *RowFrame) Append(vals ...Value) {
+	for _, v := range vals {
+		if !v.IsNull() {
+			start := len(f.buf)
+			f.buf = append(f.buf, v.Val...)
+			v.Val = f.buf[start:len(f.buf):len(f.buf)]
+		}
+		f.values = append(f.values, v)
+	}
+}
+
+// Row2 returns the values of this frame. The result is only valid until the frame is next cleared.
+func (f *RowFrame) Row2() Row2 {
+	return f.values
+}
+
+// Row2Copy returns a copy of the values of this frame, which remains valid after the frame is cleared.
+func (f *RowFrame) Row2Copy() Row2 {
+	return f.values.Copy()
+}
+
+// RowIter2 is a RowIter that can also produce its rows as Row2 values.
+type RowIter2 interface {
+	RowIter
+	// Next2 clears the frame given and fills it with the values of the next row. It returns io.EOF after the last row.
+	Next2(frame *RowFrame) error
+}
+
+// Table2 is a Table that can return its rows as Row2 values.
+type Table2 interface {
+	Table
+	// PartitionRows2 returns an iterator over the rows of the partition given.
+	PartitionRows2(ctx *Context, partition Partition) (RowIter2, error)
+}
+
+// Node2 is a Node that can produce its rows as Row2 values. Whether a node can do so often depends on its children
+// and expressions, which is reported by CanRowIter2.
+type Node2 interface {
+	Node
+	// CanRowIter2 returns whether RowIter2 can be called on this node.
+	CanRowIter2() bool
+	// RowIter2 returns an iterator over the rows of this node.
+	RowIter2(ctx *Context) (RowIter2, error)
+}
+
+// Expression2 is an Expression that can be evaluated directly on a Row2.
+type Expression2 interface {
+	Expression
+	// Eval2 evaluates the expression on the row given.
+	Eval2(ctx *Context, row Row2) (Value, error)
+}
+
+// CanRowIter2 returns whether the node given can produce its rows as Row2 values.
+func CanRowIter2(n Node) bool {
+	n2, ok := n.(Node2)
+	return ok && n2.CanRowIter2()
+}
+
+// CanEval2 returns whether the expression given, and all of its children, can be evaluated on a Row2.
+func CanEval2(e Expression) bool {
+	if _, ok := e.(Expression2); !ok {
+		return false
+	}
+	for _, c := range e.Children() {
+		if !CanEval2(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeToRows2 returns all the rows of the node given, which must be able to produce Row2 values.
+func NodeToRows2(ctx *Context, n Node2) ([]Row2, error) {
+	iter, err := n.RowIter2(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := NewRowFrame()
+	defer frame.Recycle()
+
+	var rows []Row2
+	for {
+		err := iter.Next2(frame)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		rows = append(rows, frame.Row2Copy())
+	}
+
+	return rows, iter.Close()
+}
+
+// NewRowIter2 returns a RowIter2 over the rows of iter, which have the schema given. It lets tables and nodes that
+// only produce Rows take part in Row2 iteration.
+func NewRowIter2(sch Schema, iter RowIter) RowIter2 {
+	if iter2, ok := iter.(RowIter2); ok {
+		return iter2
+	}
+	return &encodingRowIter{sch: sch, RowIter: iter}
+}
+
+type encodingRowIter struct {
+	RowIter
+	sch Schema
+}
+
+func (i *encodingRowIter) Next2(frame *RowFrame) error {
+	row, err := i.Next()
+	if err != nil {
+		return err
+	}
+
+	frame.Clear()
+	for j, v := range row {
+		val, err := EncodeValue(i.sch[j].Type, v)
+		if err != nil {
+			return err
+		}
+		frame.Append(val)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/dolthub/vitess/go/vt/proto/query"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeValue(t *testing.T) {
+	testCases := []struct {
+		typ      Type
+		val      interface{}
+		expected Value
+	}{
+		{Int64, int64(-42), Value{Typ: query.Type_INT64, Val: []byte("-42")}},
+		{Uint8, uint8(7), Value{Typ: query.Type_UINT8, Val: []byte("7")}},
+		{Float64, 2.5, Value{Typ: query.Type_FLOAT64, Val: []byte("2.5")}},
+		{LongText, "foo", Value{Typ: query.Type_TEXT, Val: []byte("foo")}},
+		{LongText, "", Value{Typ: query.Type_TEXT, Val: []byte{}}},
+		{Int64, nil, NullValue},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.typ.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			v, err := EncodeValue(tt.typ, tt.val)
+			require.NoError(err)
+			require.Equal(tt.expected, v)
+			require.Equal(tt.val == nil, v.IsNull())
+
+			decoded, err := DecodeValue(tt.typ, v)
+			require.NoError(err)
+			require.Equal(tt.val, decoded)
+		})
+	}
+}
+
+func TestRowToRow2(t *testing.T) {
+	require := require.New(t)
+
+	sch := Schema{
+		{Name: "a", Type: Int32},
+		{Name: "b", Type: LongText, Nullable: true},
+		{Name: "c", Type: Float64},
+	}
+	row := NewRow(int32(1), nil, 3.5)
+
+	row2, err := RowToRow2(sch, row)
+	require.NoError(err)
+	require.Equal(3, row2.Len())
+	require.True(row2.GetField(1).IsNull())
+	require.Equal("1", string(row2.GetField(0).Val))
+
+	decoded, err := Row2ToRow(sch, row2)
+	require.NoError(err)
+	require.Equal(row, decoded)
+}
+
+func TestRowFrame(t *testing.T) {
+	require := require.New(t)
+
+	frame := NewRowFrame()
+	defer frame.Recycle()
+
+	buf := []byte("foo")
+	frame.Append(Value{Typ: query.Type_TEXT, Val: buf}, NullValue)
+	buf[0] = 'b'
+
+	row := frame.Row2()
+	require.Equal(Row2{{Typ: query.Type_TEXT, Val: []byte("foo")}, NullValue}, row)
+
+	cp := frame.Row2Copy()
+	frame.Clear()
+	require.Equal(0, frame.Row2().Len())
+
+	frame.Append(Value{Typ: query.Type_TEXT, Val: []byte("bar")})
+	require.Equal(Row2{{Typ: query.Type_TEXT, Val: []byte("bar")}}, frame.Row2())
+	require.Equal(Row2{{Typ: query.Type_TEXT, Val: []byte("foo")}, NullValue}, cp)
+}
+
+func TestNewRowIter2(t *testing.T) {
+	require := require.New(t)
+
+	sch := Schema{
+		{Name: "a", Type: Int64},
+		{Name: "b", Type: LongText, Nullable: true},
+	}
+	iter := NewRowIter2(sch, RowsToRowIter(NewRow(int64(1), "a"), NewRow(int64(2), nil)))
+
+	frame := NewRowFrame()
+	defer frame.Recycle()
+
+	var rows []Row
+	for {
+		err := iter.Next2(frame)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(err)
+
+		row, err := Row2ToRow(sch, frame.Row2())
+		require.NoError(err)
+		rows = append(rows, row)
+	}
+	require.NoError(iter.Close())
+
+	require.Equal([]Row{NewRow(int64(1), "a"), NewRow(int64(2), nil)}, rows)
+	require.Equal(iter, NewRowIter2(sch, iter))
+}
+
+func TestSpanIter2(t *testing.T) {
+	require := require.New(t)
+
+	tracer := mocktracer.New()
+	sch := Schema{{Name: "a", Type: Int64}}
+	iter := NewSpanIter2(tracer.StartSpan("test"), NewRowIter2(sch, RowsToRowIter(NewRow(int64(1)))))
+
+	frame := NewRowFrame()
+	defer frame.Recycle()
+	require.NoError(iter.Next2(frame))
+	require.Equal(io.EOF, iter.Next2(frame))
+	require.Len(tracer.FinishedSpans(), 0)
+
+	require.NoError(iter.Close())
+	require.NoError(iter.Close())
+	require.Len(tracer.FinishedSpans(), 1)
+}
@@ -0,0 +1,84 @@
+package sql
+
+import (
+	"strings"
+	"sync"
+)
+
+// RowPolicy generates the predicate that the rows of a table must match to be visible to the user of a session, such
+// as `tenant = 'acme'`. The predicate is used to filter every read of the table, by queries as well as by UPDATE and
+// DELETE statements, so users never see or modify other rows. Columns in the predicate should be unresolved and
+// unqualified, like the ones of expression.NewUnresolvedColumn, so they're resolved against the table. A nil
+// predicate means the user can see all the rows of the table.
+type RowPolicy func(ctx *Context, user string) (Expression, error)
+
+// RowPolicyRegistry holds the row policies of tables.
+type RowPolicyRegistry struct {
+	mu       sync.RWMutex
+	policies map[string][]RowPolicy
+}
+
+// NewRowPolicyRegistry creates a new empty RowPolicyRegistry.
+func NewRowPolicyRegistry() *RowPolicyRegistry {
+	return &RowPolicyRegistry{policies: make(map[string][]RowPolicy)}
+}
+
+// tableKey returns the case insensitive key of the table of the database given in registries of tables.
+func tableKey(db, table string) string {
+	return strings.ToLower(db) + "." + strings.ToLower(table)
+}
+
+// AddRowPolicy adds a row policy to the table of the database given, whose names are case insensitive. Rows of a
+// table with several policies must match all of them.
+func (r *RowPolicyRegistry) AddRowPolicy(db, table string, policy RowPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := tableKey(db, table)
+	r.policies[key] = append(r.policies[key], policy)
+}
+
+// ClearRowPolicies removes all the row policies of the table of the database given.
+func (r *RowPolicyRegistry) ClearRowPolicies(db, table string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.policies, tableKey(db, table))
+}
+
+// HasRowPolicies returns whether any table has row policies.
+func (r *RowPolicyRegistry) HasRowPolicies() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.policies) > 0
+}
+
+// RowPolicyPredicates returns the predicates generated by the row policies of the table of the database given for
+// the user of the session of the context given. Policies that return a nil predicate are skipped.
+func (r *RowPolicyRegistry) RowPolicyPredicates(ctx *Context, db, table string) ([]Expression, error) {
+	r.mu.RLock()
+	policies := r.policies[tableKey(db, table)]
+	r.mu.RUnlock()
+
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	var user string
+	if ctx.Session != nil {
+		user = ctx.Client().User
+	}
+
+	var predicates []Expression
+	for _, policy := range policies {
+		p, err := policy(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			predicates = append(predicates, p)
+		}
+	}
+	return predicates, nil
+}
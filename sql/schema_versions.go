@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SchemaObject names a database, or a table of a database if Table isn't empty. Names are case insensitive.
+type SchemaObject struct {
+	Database string
+	Table    string
+}
+
+// SchemaVersion is the schema version of a database or a table.
+type SchemaVersion struct {
+	SchemaObject
+	Version uint64
+}
+
+// SchemaSnapshot are the schema versions of some databases and tables when a plan was analyzed.
+type SchemaSnapshot []SchemaVersion
+
+// SchemaVersionRegistry holds a schema version for each database and table, which the engine bumps after every
+// statement that changes their definition. Versions only increase, even across tables that are dropped and created
+// again, so caches of analyzed plans take a SchemaSnapshot of the objects their plans use, and analyze them again once
+// the snapshot isn't current. Objects that never changed have version zero.
+type SchemaVersionRegistry struct {
+	mu sync.RWMutex
+	// last is the last version given to any object.
+	last     uint64
+	versions map[SchemaObject]uint64
+	// resets are the versions of the databases when they were last added or removed, which are the versions of
+	// their tables that haven't changed since.
+	resets map[string]uint64
+}
+
+// NewSchemaVersionRegistry creates a new SchemaVersionRegistry, where every object has version zero.
+func NewSchemaVersionRegistry() *SchemaVersionRegistry {
+	return &SchemaVersionRegistry{
+		versions: make(map[SchemaObject]uint64),
+		resets:   make(map[string]uint64),
+	}
+}
+
+// BumpSchemaVersion gives a new version to the database given and to its tables given, after a change to their
+// definition. Changes to the objects of a database that aren't tables, such as triggers, only bump the database.
+func (r *SchemaVersionRegistry) BumpSchemaVersion(db string, tables ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last++
+	r.versions[SchemaObject{Database: db}.key()] = r.last
+	for _, t := range tables {
+		r.versions[SchemaObject{Database: db, Table: t}.key()] = r.last
+	}
+}
+
+// ResetSchemaVersion gives a new version to the database given and to all its tables, after the database is added or
+// removed.
+func (r *SchemaVersionRegistry) ResetSchemaVersion(db string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.last++
+	r.versions[SchemaObject{Database: db}.key()] = r.last
+	r.resets[strings.ToLower(db)] = r.last
+}
+
+// SchemaVersion returns the schema version of the database given, which changes with the definition of any of its
+// objects.
+func (r *SchemaVersionRegistry) SchemaVersion(db string) uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.version(SchemaObject{Database: db})
+}
+
+// TableSchemaVersion returns the schema version of the table of the database given.
+func (r *SchemaVersionRegistry) TableSchemaVersion(db, table string) uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.version(SchemaObject{Database: db, Table: table})
+}
+
+// SchemaSnapshot returns the current schema versions of the objects given.
+func (r *SchemaVersionRegistry) SchemaSnapshot(objects ...SchemaObject) SchemaSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := make(SchemaSnapshot, len(objects))
+	for i, o := range objects {
+		snapshot[i] = SchemaVersion{SchemaObject: o, Version: r.version(o)}
+	}
+	return snapshot
+}
+
+// IsCurrent returns whether none of the objects of the snapshot given changed since it was taken.
+func (r *SchemaVersionRegistry) IsCurrent(snapshot SchemaSnapshot) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range snapshot {
+		if r.version(v.SchemaObject) != v.Version {
+			return false
+		}
+	}
+	return true
+}
+
+// SchemaVersions returns the versions of all the databases and tables that have changed, with lower case names,
+// sorted by database and table, with each database before its tables.
+func (r *SchemaVersionRegistry) SchemaVersions() []SchemaVersion {
+	r.mu.RLock()
+	versions := make([]SchemaVersion, 0, len(r.versions))
+	for o := range r.versions {
+		versions = append(versions, SchemaVersion{SchemaObject: o, Version: r.version(o)})
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(versions, func(i, j int) bool {
+		a, b := versions[i], versions[j]
+		if a.Database != b.Database {
+			return a.Database < b.Database
+		}
+		return a.Table < b.Table
+	})
+	return versions
+}
+
+func (r *SchemaVersionRegistry) version(o SchemaObject) uint64 {
+	o = o.key()
+	v := r.versions[o]
+	if reset := r.resets[o.Database]; reset > v {
+		return reset
+	}
+	return v
+}
+
+// key returns the object with lower case names, which is how the registry keeps it.
+func (o SchemaObject) key() SchemaObject {
+	return SchemaObject{Database: strings.ToLower(o.Database), Table: strings.ToLower(o.Table)}
+}
@@ -0,0 +1,49 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestSchemaVersionRegistry(t *testing.T) {
+	require := require.New(t)
+
+	r := sql.NewSchemaVersionRegistry()
+	require.Equal(uint64(0), r.SchemaVersion("db"))
+	require.Equal(uint64(0), r.TableSchemaVersion("db", "t"))
+
+	objects := []sql.SchemaObject{{Database: "db", Table: "T"}, {Database: "db", Table: "u"}}
+	snapshot := r.SchemaSnapshot(objects...)
+	require.True(r.IsCurrent(snapshot))
+
+	r.BumpSchemaVersion("DB", "t")
+	require.Equal(uint64(1), r.SchemaVersion("db"))
+	require.Equal(uint64(1), r.TableSchemaVersion("db", "T"))
+	require.Equal(uint64(0), r.TableSchemaVersion("db", "u"))
+	require.False(r.IsCurrent(snapshot))
+
+	// Changes to other tables, or to the database only, leave the snapshots of the tables current
+	snapshot = r.SchemaSnapshot(objects...)
+	r.BumpSchemaVersion("db", "v")
+	r.BumpSchemaVersion("db")
+	require.Equal(uint64(3), r.SchemaVersion("db"))
+	require.True(r.IsCurrent(snapshot))
+
+	// Adding or removing a database changes all its tables
+	r.ResetSchemaVersion("db")
+	require.False(r.IsCurrent(snapshot))
+	require.Equal(uint64(4), r.TableSchemaVersion("db", "t"))
+	require.Equal(uint64(4), r.TableSchemaVersion("db", "u"))
+
+	r.BumpSchemaVersion("other", "a")
+	require.Equal([]sql.SchemaVersion{
+		{SchemaObject: sql.SchemaObject{Database: "db"}, Version: 4},
+		{SchemaObject: sql.SchemaObject{Database: "db", Table: "t"}, Version: 4},
+		{SchemaObject: sql.SchemaObject{Database: "db", Table: "v"}, Version: 4},
+		{SchemaObject: sql.SchemaObject{Database: "other"}, Version: 5},
+		{SchemaObject: sql.SchemaObject{Database: "other", Table: "a"}, Version: 5},
+	}, r.SchemaVersions())
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -23,6 +22,8 @@ const (
 const (
 	CurrentDBSessionVar  = "current_database"
 	AutoCommitSessionVar = "autocommit"
+	// GeneralLogSessionVar is the variable that turns on the general query log of the server for a session.
+	GeneralLogSessionVar = "general_log"
 )
 
 // Client holds session user information.
@@ -67,19 +68,92 @@ type Session interface {
 	DelLock(lockName string) error
 	// IterLocks iterates through all locks owned by this user
 	IterLocks(cb func(name string) error) error
+	// SetLastQueryInfo sets information about the last query run in this session, such as its found rows
+	SetLastQueryInfo(key string, value int64)
+	// GetLastQueryInfo returns information about the last query run in this session
+	GetLastQueryInfo(key string) int64
+	// AddHandler adds a table opened by HANDLER OPEN under the name given
+	AddHandler(name string, handler *Handler)
+	// GetHandler returns the table opened by HANDLER OPEN under the name given, or nil if there's none
+	GetHandler(name string) *Handler
+	// DelHandler removes the table opened by HANDLER OPEN under the name given
+	DelHandler(name string)
+	// TempStore returns the store of the intermediate results of the queries of the session that are too large to be
+	// kept in memory
+	TempStore() TempStore
+	// SetTempStore sets the store of the intermediate results of the queries of the session
+	SetTempStore(store TempStore)
+	// Transaction returns the transaction the session is in, or nil if it isn't in one
+	Transaction() *Transaction
+	// SetTransaction sets the transaction the session is in, which is nil once it ends
+	SetTransaction(tx *Transaction)
+}
+
+// GlobalVariablesSession is a Session that starts with the global values of the system variables of the engine it's
+// used with, instead of their registered defaults. The engine initializes it before each of its queries.
+type GlobalVariablesSession interface {
+	Session
+	// InitGlobalVariables sets the variables of the session to the global values given the first time it's called.
+	InitGlobalVariables(globals *GlobalVariables)
+}
+
+// Handler is a table opened by HANDLER OPEN, which HANDLER READ reads the rows of one batch at a time, starting where
+// the last read of the handler stopped.
+type Handler struct {
+	// Database is the name of the database of the table.
+	Database string
+	// Table is the name of the table.
+	Table string
+	// Index is the name of the index the last read of the handler used, or empty if it read the rows in their natural
+	// order.
+	Index string
+	// Position is the last row read in the order of the index, or nil if no rows were read in that order.
+	Position Row
+	// Scanned is the number of rows read in their natural order since the last READ FIRST.
+	Scanned int64
+}
+
+const (
+	// RowCount is the key of the last query info with the number of rows affected by the last statement, as returned
+	// by ROW_COUNT(). It's -1 for statements that return rows, and 0 for statements that neither return nor affect rows.
+	RowCount = "row_count"
+	// FoundRows is the key of the last query info with the number of rows found by the last statement that returned
+	// rows, as returned by FOUND_ROWS(). For statements with SQL_CALC_FOUND_ROWS, it's the number of rows they'd
+	// have returned without their LIMIT.
+	FoundRows = "found_rows"
+	// LastInsertId is the key of the last query info with the first value generated for an AUTO_INCREMENT column by
+	// the last INSERT that generated any, or the value last given to LAST_INSERT_ID(expr), as returned by
+	// LAST_INSERT_ID().
+	LastInsertId = "last_insert_id"
+)
+
+// defaultLastQueryInfo returns the last query info of a session that hasn't run any queries.
+func defaultLastQueryInfo() map[string]int64 {
+	return map[string]int64{
+		RowCount:     0,
+		FoundRows:    1,
+		LastInsertId: 0,
+	}
 }
 
 // BaseSession is the basic session type.
 type BaseSession struct {
-	id        uint32
-	addr      string
-	currentDB string
-	client    Client
-	mu        *sync.RWMutex
-	config    map[string]TypedValue
-	warnings  []*Warning
-	warncnt   uint16
-	locks     map[string]bool
+	id          uint32
+	addr        string
+	currentDB   string
+	client      Client
+	mu          *sync.RWMutex
+	config      map[string]TypedValue
+	warnings    []*Warning
+	warncnt     uint16
+	locks       map[string]bool
+	lastQuery   map[string]int64
+	handlers    map[string]*Handler
+	tempStore   TempStore
+	transaction *Transaction
+	// globals are the global variables the session started with, and set are the names of the variables it set.
+	globals *GlobalVariables
+	set     map[string]bool
 }
 
 // CommitTransaction commits the current transaction for the current database.
@@ -98,10 +172,35 @@ func (s *BaseSession) Client() Client { return s.client }
 func (s *BaseSession) Set(ctx context.Context, key string, typ Type, value interface{}) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.set == nil {
+		s.set = make(map[string]bool)
+	}
 	s.config[key] = TypedValue{typ, value}
+	s.set[key] = true
+	if v, ok := GetSystemVariable(key); ok && v.Alias != "" {
+		s.config[v.Alias] = TypedValue{typ, value}
+		s.set[v.Alias] = true
+	}
 	return nil
 }
 
+// InitGlobalVariables implements the GlobalVariablesSession interface. The variables the session already set keep
+// their values, and the session keeps the values it started with when it's used with other global variables.
+func (s *BaseSession) InitGlobalVariables(globals *GlobalVariables) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.globals != nil {
+		return
+	}
+	s.globals = globals
+
+	for name, value := range globals.changed() {
+		if v, ok := s.config[name]; ok && !s.set[name] {
+			s.config[name] = TypedValue{v.Typ, value}
+		}
+	}
+}
+
 // Get implements the Session interface.
 func (s *BaseSession) Get(key string) (Type, interface{}) {
 	s.mu.RLock()
@@ -218,6 +317,28 @@ func (s *BaseSession) IterLocks(cb func(name string) error) error {
 	return nil
 }
 
+// SetLastQueryInfo implements the sql.Session interface.
+func (s *BaseSession) SetLastQueryInfo(key string, value int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastQuery == nil {
+		s.lastQuery = defaultLastQueryInfo()
+	}
+	s.lastQuery[key] = value
+}
+
+// GetLastQueryInfo implements the sql.Session interface.
+func (s *BaseSession) GetLastQueryInfo(key string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.lastQuery == nil {
+		return defaultLastQueryInfo()[key]
+	}
+	return s.lastQuery[key]
+}
+
 type (
 	// TypedValue is a value along with its type.
 	TypedValue struct {
@@ -230,31 +351,23 @@ type (
 		Level   string
 		Message string
 		Code    int
+		// SQLState is the SQLSTATE of the condition, or empty if it has none.
+		SQLState string
+		// Info is the rest of the information items of the condition, such as the TABLE_NAME set by SIGNAL, by their
+		// names in upper case.
+		Info map[string]string
 	}
 )
 
-// DefaultSessionConfig returns default values for session variables
-// TODO: allow integrators to specify defaults for their system variables
+// DefaultSessionConfig returns default values for session variables, which are the defaults of the registered
+// system variables.
 func DefaultSessionConfig() map[string]TypedValue {
-	return map[string]TypedValue{
-		"auto_increment_increment": TypedValue{Int64, int64(1)},
-		"time_zone":                TypedValue{LongText, "SYSTEM"},
-		"system_time_zone":         TypedValue{LongText, time.Now().UTC().Location().String()},
-		"max_allowed_packet":       TypedValue{Int32, math.MaxInt32},
-		"sql_mode":                 TypedValue{LongText, ""},
-		"gtid_mode":                TypedValue{Int32, int32(0)},
-		"collation_database":       TypedValue{LongText, Collation_Default.String()},
-		"ndbinfo_version":          TypedValue{LongText, ""},
-		"sql_select_limit":         TypedValue{Int32, math.MaxInt32},
-		"transaction_isolation":    TypedValue{LongText, "READ UNCOMMITTED"},
-		"version":                  TypedValue{LongText, ""},
-		"version_comment":          TypedValue{LongText, ""},
-		"autocommit":               TypedValue{Int8, 0},
-		"character_set_client":     TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"character_set_connection": TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"character_set_results":    TypedValue{LongText, Collation_Default.CharacterSet().String()},
-		"collation_connection":     TypedValue{LongText, Collation_Default.String()},
+	vars := SystemVariables()
+	config := make(map[string]TypedValue, len(vars))
+	for _, v := range vars {
+		config[v.Name] = TypedValue{v.Type, v.Default}
 	}
+	return config
 }
 
 // HasDefaultValue checks if session variable value is the default one.
@@ -266,6 +379,69 @@ func HasDefaultValue(s Session, key string) (bool, interface{}) {
 	return false, val
 }
 
+// AddHandler implements the sql.Session interface.
+func (s *BaseSession) AddHandler(name string, handler *Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handlers == nil {
+		s.handlers = make(map[string]*Handler)
+	}
+	s.handlers[name] = handler
+}
+
+// GetHandler implements the sql.Session interface.
+func (s *BaseSession) GetHandler(name string) *Handler {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.handlers[name]
+}
+
+// DelHandler implements the sql.Session interface.
+func (s *BaseSession) DelHandler(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.handlers, name)
+}
+
+// TempStore implements the sql.Session interface. Sessions write temporary files to the temporary directory of the
+// system unless they're given another store.
+func (s *BaseSession) TempStore() TempStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.tempStore == nil {
+		return defaultTempStore
+	}
+	return s.tempStore
+}
+
+// SetTempStore implements the sql.Session interface.
+func (s *BaseSession) SetTempStore(store TempStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tempStore = store
+}
+
+// Transaction implements the sql.Session interface.
+func (s *BaseSession) Transaction() *Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.transaction
+}
+
+// SetTransaction implements the sql.Session interface.
+func (s *BaseSession) SetTransaction(tx *Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transaction = tx
+}
+
 // NewSession creates a new session with data.
 func NewSession(server, client, user string, id uint32) Session {
 	return &BaseSession{
@@ -275,9 +451,10 @@ func NewSession(server, client, user string, id uint32) Session {
 			Address: client,
 			User:    user,
 		},
-		config: DefaultSessionConfig(),
-		mu:     &sync.RWMutex{},
-		locks:  make(map[string]bool),
+		config:    DefaultSessionConfig(),
+		mu:        &sync.RWMutex{},
+		locks:     make(map[string]bool),
+		lastQuery: defaultLastQueryInfo(),
 	}
 }
 
@@ -286,7 +463,7 @@ var autoSessionIDs uint32 = 1
 
 // NewBaseSession creates a new empty session.
 func NewBaseSession() Session {
-	return &BaseSession{id: atomic.AddUint32(&autoSessionIDs, 1), config: DefaultSessionConfig(), mu: &sync.RWMutex{}, locks: make(map[string]bool)}
+	return &BaseSession{id: atomic.AddUint32(&autoSessionIDs, 1), config: DefaultSessionConfig(), mu: &sync.RWMutex{}, locks: make(map[string]bool), lastQuery: defaultLastQueryInfo()}
 }
 
 // Context of the query execution.
@@ -295,12 +472,15 @@ type Context struct {
 	Session
 	*IndexRegistry
 	*ViewRegistry
-	Memory    *MemoryManager
-	pid       uint64
-	query     string
-	queryTime time.Time
-	tracer    opentracing.Tracer
-	rootSpan  opentracing.Span
+	Memory *MemoryManager
+	// GlobalVariables are the global values of the system variables the query reads and sets, which are the ones of
+	// the catalog of the engine running it.
+	GlobalVariables *GlobalVariables
+	pid             uint64
+	query           string
+	queryTime       time.Time
+	tracer          opentracing.Tracer
+	rootSpan        opentracing.Span
 }
 
 // ContextOption is a function to configure the context.
@@ -353,6 +533,13 @@ func WithMemoryManager(m *MemoryManager) ContextOption {
 	}
 }
 
+// WithGlobalVariables sets the global values of the system variables of the context.
+func WithGlobalVariables(g *GlobalVariables) ContextOption {
+	return func(ctx *Context) {
+		ctx.GlobalVariables = g
+	}
+}
+
 // WithRootSpan sets the root span of the context.
 func WithRootSpan(s opentracing.Span) ContextOption {
 	return func(ctx *Context) {
@@ -385,7 +572,7 @@ func NewContext(
 	ctx context.Context,
 	opts ...ContextOption,
 ) *Context {
-	c := &Context{ctx, NewBaseSession(), nil, nil, nil, 0, "", ctxNowFunc(), opentracing.NoopTracer{}, nil}
+	c := &Context{ctx, NewBaseSession(), nil, nil, nil, nil, 0, "", ctxNowFunc(), opentracing.NoopTracer{}, nil}
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -401,6 +588,10 @@ func NewContext(
 	if c.Memory == nil {
 		c.Memory = NewMemoryManager(ProcessMemory)
 	}
+
+	if c.GlobalVariables == nil {
+		c.GlobalVariables = NewGlobalVariables()
+	}
 	return c
 }
 
@@ -440,16 +631,17 @@ func (c *Context) Span(
 	ctx := opentracing.ContextWithSpan(c.Context, span)
 
 	return span, &Context{
-		Context:       ctx,
-		Session:       c.Session,
-		IndexRegistry: c.IndexRegistry,
-		ViewRegistry:  c.ViewRegistry,
-		Memory:        c.Memory,
-		pid:           c.Pid(),
-		query:         c.Query(),
-		queryTime:     c.queryTime,
-		tracer:        c.tracer,
-		rootSpan:      c.rootSpan,
+		Context:         ctx,
+		Session:         c.Session,
+		IndexRegistry:   c.IndexRegistry,
+		ViewRegistry:    c.ViewRegistry,
+		Memory:          c.Memory,
+		GlobalVariables: c.GlobalVariables,
+		pid:             c.Pid(),
+		query:           c.Query(),
+		queryTime:       c.queryTime,
+		tracer:          c.tracer,
+		rootSpan:        c.rootSpan,
 	}
 }
 
@@ -458,16 +650,17 @@ func (c *Context) Span(
 func (c *Context) NewSubContext() (*Context, context.CancelFunc) {
 	ctx, cancelFunc := context.WithCancel(c.Context)
 	return &Context{
-		Context:       ctx,
-		Session:       c.Session,
-		IndexRegistry: c.IndexRegistry,
-		ViewRegistry:  c.ViewRegistry,
-		Memory:        c.Memory,
-		pid:           c.Pid(),
-		query:         c.Query(),
-		queryTime:     c.queryTime,
-		tracer:        c.tracer,
-		rootSpan:      c.rootSpan,
+		Context:         ctx,
+		Session:         c.Session,
+		IndexRegistry:   c.IndexRegistry,
+		ViewRegistry:    c.ViewRegistry,
+		Memory:          c.Memory,
+		GlobalVariables: c.GlobalVariables,
+		pid:             c.Pid(),
+		query:           c.Query(),
+		queryTime:       c.queryTime,
+		tracer:          c.tracer,
+		rootSpan:        c.rootSpan,
 	}, cancelFunc
 }
 
@@ -479,16 +672,17 @@ func (c *Context) WithCurrentDB(db string) *Context {
 // WithContext returns a new context with the given underlying context.
 func (c *Context) WithContext(ctx context.Context) *Context {
 	return &Context{
-		Context:       ctx,
-		Session:       c.Session,
-		IndexRegistry: c.IndexRegistry,
-		ViewRegistry:  c.ViewRegistry,
-		Memory:        c.Memory,
-		pid:           c.Pid(),
-		query:         c.Query(),
-		queryTime:     c.queryTime,
-		tracer:        c.tracer,
-		rootSpan:      c.rootSpan,
+		Context:         ctx,
+		Session:         c.Session,
+		IndexRegistry:   c.IndexRegistry,
+		ViewRegistry:    c.ViewRegistry,
+		Memory:          c.Memory,
+		GlobalVariables: c.GlobalVariables,
+		pid:             c.Pid(),
+		query:           c.Query(),
+		queryTime:       c.queryTime,
+		tracer:          c.tracer,
+		rootSpan:        c.rootSpan,
 	}
 }
 
@@ -526,6 +720,25 @@ func NewSpanIter(span opentracing.Span, iter RowIter) RowIter {
 	// }
 }
 
+// NewSpanIter2 returns a RowIter2 that finishes the given span once it's closed.
+func NewSpanIter2(span opentracing.Span, iter RowIter2) RowIter2 {
+	return &spanIter2{RowIter2: iter, span: span}
+}
+
+type spanIter2 struct {
+	RowIter2
+	span opentracing.Span
+	done bool
+}
+
+func (i *spanIter2) Close() error {
+	if !i.done {
+		i.span.Finish()
+		i.done = true
+	}
+	return i.RowIter2.Close()
+}
+
 type spanIter struct {
 	span  opentracing.Span
 	iter  RowIter
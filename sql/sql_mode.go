@@ -0,0 +1,125 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dolthub/vitess/go/sqltypes"
+)
+
+// SqlModeVar is the system variable with the SQL modes of a session, as a comma-separated list of their names.
+const SqlModeVar = "sql_mode"
+
+// DefaultSqlMode is the default value of the sql_mode system variable. Like the default of MySQL it's strict, but zero
+// dates are valid values.
+const DefaultSqlMode = StrictTransTablesMode
+
+const (
+	// StrictTransTablesMode makes the invalid values written to tables errors instead of warnings.
+	StrictTransTablesMode = "STRICT_TRANS_TABLES"
+	// StrictAllTablesMode makes the invalid values written to tables errors instead of warnings. Tables aren't told
+	// apart by whether they're transactional, so it's the same as STRICT_TRANS_TABLES.
+	StrictAllTablesMode = "STRICT_ALL_TABLES"
+	// NoZeroDateMode makes the zero date, 0000-00-00, an invalid value.
+	NoZeroDateMode = "NO_ZERO_DATE"
+	// TraditionalMode stands for the modes of a traditional database, which are strict about the values written.
+	TraditionalMode = "TRADITIONAL"
+)
+
+// warnTruncatedWrongValue is the code of the warnings of invalid temporal values cast or compared.
+const warnTruncatedWrongValue = 1292
+
+// combinationSqlModes are the SQL modes that stand for a set of other modes.
+var combinationSqlModes = map[string][]string{
+	TraditionalMode: {
+		StrictTransTablesMode,
+		StrictAllTablesMode,
+		"NO_ZERO_IN_DATE",
+		NoZeroDateMode,
+		"ERROR_FOR_DIVISION_BY_ZERO",
+		"NO_ENGINE_SUBSTITUTION",
+	},
+	"ANSI": {"REAL_AS_FLOAT", "PIPES_AS_CONCAT", "ANSI_QUOTES", "IGNORE_SPACE", "ONLY_FULL_GROUP_BY"},
+}
+
+// SqlMode is the set of SQL modes of a session, by their names in upper case.
+type SqlMode map[string]bool
+
+// LoadSqlMode returns the SQL modes of the session of the context given.
+func LoadSqlMode(ctx *Context) SqlMode {
+	_, v := ctx.Get(SqlModeVar)
+	s, _ := v.(string)
+	return ParseSqlMode(s)
+}
+
+// ParseSqlMode returns the SQL modes of the comma-separated list of names given, with the modes that combinations of
+// modes stand for. Names are case-insensitive.
+func ParseSqlMode(s string) SqlMode {
+	mode := make(SqlMode)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		mode[name] = true
+		for _, m := range combinationSqlModes[name] {
+			mode[m] = true
+		}
+	}
+	return mode
+}
+
+// ModeEnabled returns whether the mode with the name given is enabled.
+func (m SqlMode) ModeEnabled(name string) bool {
+	return m[strings.ToUpper(name)]
+}
+
+// Strict returns whether strict mode is enabled, with STRICT_TRANS_TABLES or STRICT_ALL_TABLES.
+func (m SqlMode) Strict() bool {
+	return m[StrictTransTablesMode] || m[StrictAllTablesMode]
+}
+
+// IsZeroTime returns whether the time given is the zero date, 0000-00-00 00:00:00, of the temporal types.
+func IsZeroTime(t time.Time) bool {
+	return t.Equal(zeroTime)
+}
+
+// ValidateTemporal returns the value of the temporal type given that a CAST converted, or nil with a warning if the
+// original value given couldn't be converted, or is the zero date and NO_ZERO_DATE is enabled in the SQL mode of the
+// context given.
+func ValidateTemporal(ctx *Context, typ DatetimeType, original, converted interface{}) interface{} {
+	if original == nil {
+		return nil
+	}
+	if t, ok := converted.(time.Time); ok && !(IsZeroTime(t) && LoadSqlMode(ctx).ModeEnabled(NoZeroDateMode)) {
+		return converted
+	}
+	WarnIncorrectTemporal(ctx, typ, original)
+	return nil
+}
+
+// WarnIncorrectTemporal adds the warning of an invalid value of the temporal type given to the session of the context
+// given.
+func WarnIncorrectTemporal(ctx *Context, typ DatetimeType, v interface{}) {
+	ctx.Warn(warnTruncatedWrongValue, "Incorrect %s value: '%s'", temporalTypeName(typ), temporalString(v))
+}
+
+// temporalTypeName returns the name of the temporal type given in messages, which is datetime for timestamps too.
+func temporalTypeName(typ DatetimeType) string {
+	if typ.Type() == sqltypes.Date {
+		return "date"
+	}
+	return "datetime"
+}
+
+// temporalString returns the value given as it's shown in messages.
+func temporalString(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		if IsZeroTime(t) {
+			return zeroTimestampDatetimeStr
+		}
+		return t.Format(TimestampDatetimeLayout)
+	}
+	return fmt.Sprint(v)
+}
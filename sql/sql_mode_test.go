@@ -0,0 +1,42 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSqlMode(t *testing.T) {
+	require := require.New(t)
+
+	mode := ParseSqlMode("")
+	require.False(mode.Strict())
+	require.False(mode.ModeEnabled(NoZeroDateMode))
+
+	mode = ParseSqlMode("strict_all_tables, no_zero_date")
+	require.True(mode.Strict())
+	require.True(mode.ModeEnabled(NoZeroDateMode))
+	require.True(mode.ModeEnabled("no_zero_date"))
+
+	mode = ParseSqlMode("Traditional")
+	require.True(mode.ModeEnabled(TraditionalMode))
+	require.True(mode.ModeEnabled(StrictTransTablesMode))
+	require.True(mode.ModeEnabled(NoZeroDateMode))
+	require.True(mode.Strict())
+}
+
+func TestValidateTemporal(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+
+	require.Equal(zeroTime, ValidateTemporal(ctx, Date, zeroDateStr, zeroTime))
+	require.Empty(ctx.Warnings())
+	require.Nil(ValidateTemporal(ctx, Date, "garbage", nil))
+	require.Len(ctx.Warnings(), 1)
+	require.Equal("Incorrect date value: 'garbage'", ctx.Warnings()[0].Message)
+
+	ctx = NewEmptyContext()
+	require.NoError(ctx.Set(ctx, SqlModeVar, LongText, NoZeroDateMode))
+	require.Nil(ValidateTemporal(ctx, Datetime, zeroDateStr, zeroTime))
+	require.Equal("Incorrect datetime value: '0000-00-00'", ctx.Warnings()[0].Message)
+}
@@ -0,0 +1,51 @@
+package sql
+
+import "strings"
+
+// TableStatistics are statistics of the rows of a table. The analyzer uses them to estimate how many rows the nodes of
+// a plan return, to choose the order of the tables of joins and whether looking up rows in an index is cheaper than
+// scanning the table.
+type TableStatistics struct {
+	// RowCount is the number of rows of the table.
+	RowCount uint64
+	// Columns are the statistics of the columns of the table, keyed by their lower case names. Columns without
+	// statistics are missing.
+	Columns map[string]ColumnStatistics
+}
+
+// ColumnStatistics are statistics of the values of a column.
+type ColumnStatistics struct {
+	// DistinctCount is the number of distinct values of the column other than NULL, its cardinality.
+	DistinctCount uint64
+	// NullCount is the number of rows where the column is NULL.
+	NullCount uint64
+	// Histogram is the histogram of the values of the column other than NULL, or nil if it has none. Histograms can be
+	// older than the rest of the statistics.
+	Histogram *Histogram
+}
+
+// StatisticsTable is a table that can return statistics of its rows. Statistics can be estimates, and don't need to
+// reflect the latest changes to the table.
+type StatisticsTable interface {
+	Table
+	// Statistics returns the statistics of the table, or nil if it has none.
+	Statistics(ctx *Context) (*TableStatistics, error)
+}
+
+// RemoteTable is a table whose rows are read from a remote server, which costs more than reading the rows of a local
+// table because they're sent over the network. The analyzer adds the network cost of its cost model to the costs of
+// reading their rows.
+type RemoteTable interface {
+	Table
+	// IsRemote returns whether the rows of the table are read from a remote server.
+	IsRemote() bool
+}
+
+// Column returns the statistics of the column named, if it has any. Names are case insensitive.
+func (s *TableStatistics) Column(name string) (ColumnStatistics, bool) {
+	if s == nil {
+		return ColumnStatistics{}, false
+	}
+	c, ok := s.Columns[strings.ToLower(name)]
+	return c, ok
+}
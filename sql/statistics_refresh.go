@@ -0,0 +1,162 @@
+package sql
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StatisticsRefresher analyzes tables again in the background once the rows changed since their last analysis are
+// more than a fraction of their rows, so the statistics queries are planned with don't get stale as tables grow and
+// change. Only the tables that implement AnalyzableTable are refreshed.
+type StatisticsRefresher struct {
+	// threshold is the fraction of the rows of a table that must change for it to be analyzed again.
+	threshold float64
+
+	mu     sync.Mutex
+	tables map[string]*refreshedTable
+	wg     sync.WaitGroup
+}
+
+// refreshedTable is the state of a table tracked by a StatisticsRefresher.
+type refreshedTable struct {
+	// changes are the number of rows changed since the last analysis of the table.
+	changes uint64
+	// rows is the number of rows of the table at its last analysis, if known.
+	rows  uint64
+	known bool
+	// analyzing is whether the table is being analyzed or checked in the background.
+	analyzing bool
+	// analyses are the number of times the table was analyzed.
+	analyses uint64
+}
+
+// NewStatisticsRefresher returns a StatisticsRefresher that analyzes tables again once more than the fraction given of
+// their rows changed.
+func NewStatisticsRefresher(threshold float64) *StatisticsRefresher {
+	return &StatisticsRefresher{threshold: threshold, tables: make(map[string]*refreshedTable)}
+}
+
+// RowsChanged records that the number of rows given of the table given were inserted, updated or deleted, and starts
+// analyzing the table in the background if the rows changed since it was last analyzed are more than the threshold
+// fraction of its rows. The first changes of a table only have its number of rows counted in the background, since
+// the number it had at its last analysis isn't known. Each table is analyzed once at a time.
+func (r *StatisticsRefresher) RowsChanged(db string, table AnalyzableTable, rows uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := refreshedTableKey(db, table.Name())
+	t, ok := r.tables[key]
+	if !ok {
+		t = &refreshedTable{}
+		r.tables[key] = t
+	}
+	t.changes += rows
+
+	if t.analyzing || (t.known && !t.stale(r.threshold)) {
+		return
+	}
+
+	t.analyzing = true
+	r.wg.Add(1)
+	go r.refresh(db, t, table)
+}
+
+// refresh analyzes the table given, if it's stale, and counts its rows.
+func (r *StatisticsRefresher) refresh(db string, t *refreshedTable, table AnalyzableTable) {
+	defer r.wg.Done()
+
+	ctx := NewEmptyContext()
+	log := logrus.WithField("table", db+"."+table.Name())
+	done := func(stats *TableStatistics, analyzed bool) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		t.analyzing = false
+		if stats != nil {
+			t.rows, t.known = stats.RowCount, true
+		}
+		if analyzed {
+			t.analyses++
+		}
+	}
+
+	r.mu.Lock()
+	known := t.known
+	r.mu.Unlock()
+	if !known {
+		stats, err := table.Statistics(ctx)
+		if err != nil {
+			log.Errorf("cannot count the rows of the table to refresh its statistics: %s", err)
+			done(nil, false)
+			return
+		}
+
+		// Tables without statistics have no rows to count
+		r.mu.Lock()
+		t.rows, t.known = 0, true
+		if stats != nil {
+			t.rows = stats.RowCount
+		}
+		stale := t.stale(r.threshold)
+		r.mu.Unlock()
+		if !stale {
+			done(stats, false)
+			return
+		}
+	}
+
+	// Changes made while the table is analyzed count for its next analysis
+	r.mu.Lock()
+	t.changes = 0
+	r.mu.Unlock()
+
+	if err := table.Analyze(ctx); err != nil {
+		log.Errorf("cannot refresh the statistics of the table: %s", err)
+		done(nil, false)
+		return
+	}
+
+	stats, err := table.Statistics(ctx)
+	if err != nil {
+		log.Errorf("cannot count the rows of the table after refreshing its statistics: %s", err)
+		stats = nil
+	}
+	done(stats, true)
+}
+
+// stale returns whether more than the threshold fraction given of the rows of the table changed.
+func (t *refreshedTable) stale(threshold float64) bool {
+	return float64(t.changes) > threshold*float64(t.rows)
+}
+
+// Changes returns the number of rows of the table given changed since its last analysis by the refresher.
+func (r *StatisticsRefresher) Changes(db, table string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.tables[refreshedTableKey(db, table)]; ok {
+		return t.changes
+	}
+	return 0
+}
+
+// Analyses returns the number of times the refresher analyzed the table given.
+func (r *StatisticsRefresher) Analyses(db, table string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.tables[refreshedTableKey(db, table)]; ok {
+		return t.analyses
+	}
+	return 0
+}
+
+// Wait waits for the tables being analyzed in the background.
+func (r *StatisticsRefresher) Wait() {
+	r.wg.Wait()
+}
+
+func refreshedTableKey(db, table string) string {
+	return strings.ToLower(db) + "." + strings.ToLower(table)
+}
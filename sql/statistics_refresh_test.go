@@ -0,0 +1,58 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestStatisticsRefresher(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table := memory.NewTable("t", sql.Schema{{Name: "i", Type: sql.Int64, Source: "t", PrimaryKey: true}})
+	insert := func(from, to int) {
+		for i := from; i < to; i++ {
+			require.NoError(table.Insert(ctx, sql.NewRow(int64(i))))
+		}
+	}
+	histogram := func() *sql.Histogram {
+		stats, err := table.Statistics(ctx)
+		require.NoError(err)
+		return stats.Columns["i"].Histogram
+	}
+
+	r := sql.NewStatisticsRefresher(0.5)
+
+	// The first changes of a table count its rows, and analyze it if they're enough
+	insert(0, 10)
+	r.RowsChanged("db", table, 10)
+	r.Wait()
+	require.Equal(uint64(1), r.Analyses("db", "T"))
+	require.Equal(uint64(0), r.Changes("db", "t"))
+	require.Equal(uint64(10), histogram().RowCount())
+
+	insert(10, 15)
+	r.RowsChanged("db", table, 5)
+	r.Wait()
+	require.Equal(uint64(1), r.Analyses("db", "t"))
+	require.Equal(uint64(5), r.Changes("db", "t"))
+	require.Equal(uint64(10), histogram().RowCount())
+
+	// More than half the rows of the last analysis changed
+	insert(15, 16)
+	r.RowsChanged("db", table, 1)
+	r.Wait()
+	require.Equal(uint64(2), r.Analyses("db", "t"))
+	require.Equal(uint64(0), r.Changes("db", "t"))
+	require.Equal(uint64(16), histogram().RowCount())
+
+	// Tables are tracked by database
+	r.RowsChanged("other", table, 1)
+	r.Wait()
+	require.Equal(uint64(0), r.Analyses("other", "t"))
+	require.Equal(uint64(1), r.Changes("other", "t"))
+}
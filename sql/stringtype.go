@@ -205,7 +205,15 @@ func (t stringType) Convert(v interface{}) (interface{}, error) {
 	}
 
 	if ti, ok := v.(time.Time); ok {
-		v = ti.Format(TimestampDatetimeLayout)
+		if IsZeroTime(ti) {
+			v = zeroTimestampDatetimeStr
+		} else {
+			v = ti.Format(TimestampDatetimeLayout)
+		}
+	}
+
+	if b, ok := v.(BinaryLiteral); ok {
+		v = []byte(b)
 	}
 
 	val, err := cast.ToStringE(v)
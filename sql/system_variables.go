@@ -0,0 +1,224 @@
+package sql
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SystemVariable is a system variable known to the engine, with the type and the value of the variable in new
+// sessions.
+type SystemVariable struct {
+	// Name of the variable, in lower case.
+	Name string
+	// Type of the values of the variable.
+	Type Type
+	// Default is the global value of the variable, and the value of the variable in new sessions, until SET GLOBAL
+	// sets another one in the GlobalVariables of a catalog.
+	Default interface{}
+	// Alias is the name of another variable that always has the same value as this one, such as the deprecated
+	// tx_isolation for transaction_isolation. Setting either variable sets both.
+	Alias string
+	// GlobalOnly is whether the variable only has a global value, which sessions read as their own and can only set
+	// with SET GLOBAL.
+	GlobalOnly bool
+}
+
+var (
+	systemVariablesMu sync.RWMutex
+	systemVariables   = make(map[string]SystemVariable)
+)
+
+func init() {
+	RegisterSystemVariables(defaultSystemVariables()...)
+}
+
+// defaultSystemVariables returns the variables the engine knows about, which include the ones connectors and ORMs
+// read and set when they connect.
+func defaultSystemVariables() []SystemVariable {
+	charset := Collation_Default.CharacterSet().String()
+	return []SystemVariable{
+		{Name: AdmissionQueueTimeoutVar, Type: Int64, Default: int64(0)},
+		{Name: "auto_increment_increment", Type: Int64, Default: int64(1)},
+		{Name: "autocommit", Type: Int8, Default: 0},
+		{Name: "character_set_client", Type: LongText, Default: charset},
+		{Name: "character_set_connection", Type: LongText, Default: charset},
+		{Name: "character_set_database", Type: LongText, Default: charset},
+		{Name: "character_set_filesystem", Type: LongText, Default: CharacterSet_binary.String()},
+		{Name: "character_set_results", Type: LongText, Default: charset},
+		{Name: "character_set_server", Type: LongText, Default: charset},
+		{Name: "character_set_system", Type: LongText, Default: CharacterSet_utf8.String()},
+		{Name: "collation_connection", Type: LongText, Default: Collation_Default.String()},
+		{Name: "collation_database", Type: LongText, Default: Collation_Default.String()},
+		{Name: "collation_server", Type: LongText, Default: Collation_Default.String()},
+		{Name: "default_storage_engine", Type: LongText, Default: "InnoDB"},
+		{Name: ForeignKeyChecksVar, Type: Int8, Default: int8(1)},
+		{Name: GeneralLogSessionVar, Type: Int8, Default: int8(0)},
+		{Name: "gtid_mode", Type: Int32, Default: int32(0)},
+		{Name: "init_connect", Type: LongText, Default: ""},
+		{Name: InnodbLockWaitTimeoutVar, Type: Int64, Default: int64(50)},
+		{Name: "interactive_timeout", Type: Int64, Default: int64(28800)},
+		{Name: "license", Type: LongText, Default: "GPL"},
+		{Name: LockWaitTimeoutVar, Type: Int64, Default: int64(31536000)},
+		{Name: LowPriorityUpdatesVar, Type: Int8, Default: int8(0)},
+		{Name: LowerCaseTableNamesSessionVar, Type: Int32, Default: int32(2)},
+		{Name: "max_allowed_packet", Type: Int32, Default: math.MaxInt32},
+		{Name: MaxConcurrentQueriesVar, Type: Int64, Default: int64(0)},
+		{Name: MaxDigestConcurrentQueriesVar, Type: Int64, Default: int64(0)},
+		{Name: MaxUserConcurrentQueriesVar, Type: Int64, Default: int64(0)},
+		{Name: "ndbinfo_version", Type: LongText, Default: ""},
+		{Name: "net_buffer_length", Type: Int64, Default: int64(16384)},
+		{Name: "net_read_timeout", Type: Int64, Default: int64(30)},
+		{Name: "net_write_timeout", Type: Int64, Default: int64(60)},
+		{Name: "performance_schema", Type: Int8, Default: int8(0)},
+		{Name: "query_cache_size", Type: Int64, Default: int64(1048576)},
+		{Name: QueryCacheTypeSessionVar, Type: LongText, Default: "DEMAND"},
+		{Name: ReadOnlyVar, Type: Int8, Default: int8(0), GlobalOnly: true},
+		{Name: "sql_auto_is_null", Type: Int8, Default: int8(0)},
+		{Name: SqlModeVar, Type: LongText, Default: DefaultSqlMode},
+		{Name: "sql_select_limit", Type: Int32, Default: math.MaxInt32},
+		{Name: SuperReadOnlyVar, Type: Int8, Default: int8(0), GlobalOnly: true},
+		{Name: "system_time_zone", Type: LongText, Default: time.Now().UTC().Location().String()},
+		{Name: "time_zone", Type: LongText, Default: "SYSTEM"},
+		{Name: "transaction_isolation", Type: LongText, Default: "READ-UNCOMMITTED", Alias: "tx_isolation"},
+		{Name: "transaction_read_only", Type: Int8, Default: int8(0), Alias: "tx_read_only"},
+		{Name: "tx_isolation", Type: LongText, Default: "READ-UNCOMMITTED", Alias: "transaction_isolation"},
+		{Name: "tx_read_only", Type: Int8, Default: int8(0), Alias: "transaction_read_only"},
+		{Name: "version", Type: LongText, Default: ""},
+		{Name: "version_comment", Type: LongText, Default: ""},
+		{Name: "wait_timeout", Type: Int64, Default: int64(28800)},
+	}
+}
+
+// RegisterSystemVariables adds the variables given to the ones known to the engine, replacing the ones with the same
+// names, so integrators can add their own variables or change the defaults of the engine. Sessions created afterwards
+// start with the new defaults.
+func RegisterSystemVariables(vars ...SystemVariable) {
+	systemVariablesMu.Lock()
+	defer systemVariablesMu.Unlock()
+
+	for _, v := range vars {
+		v.Name = strings.ToLower(v.Name)
+		systemVariables[v.Name] = v
+	}
+}
+
+// GetSystemVariable returns the system variable with the name given, case insensitively, and whether it exists.
+func GetSystemVariable(name string) (SystemVariable, bool) {
+	systemVariablesMu.RLock()
+	defer systemVariablesMu.RUnlock()
+
+	v, ok := systemVariables[strings.ToLower(name)]
+	return v, ok
+}
+
+// SystemVariables returns all the system variables known to the engine, sorted by name.
+func SystemVariables() []SystemVariable {
+	systemVariablesMu.RLock()
+	defer systemVariablesMu.RUnlock()
+
+	vars := make([]SystemVariable, 0, len(systemVariables))
+	for _, v := range systemVariables {
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// GlobalVariables are the global values of the system variables of a Catalog, which SET GLOBAL sets. They are the
+// values the engine reads for the settings that apply to all sessions, and the ones sessions start with. Variables
+// whose global value was never set have the default they were registered with.
+type GlobalVariables struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// NewGlobalVariables returns new GlobalVariables with the registered defaults of all the system variables.
+func NewGlobalVariables() *GlobalVariables {
+	return &GlobalVariables{values: make(map[string]interface{})}
+}
+
+// Get returns the system variable with the name given, case insensitively, with its global value as its Default,
+// and whether it exists.
+func (g *GlobalVariables) Get(name string) (SystemVariable, bool) {
+	v, ok := GetSystemVariable(name)
+	if !ok {
+		return v, false
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if value, ok := g.values[v.Name]; ok {
+		v.Default = value
+	}
+	return v, true
+}
+
+// Set sets the global value of the system variable with the name given, and of its alias, converted to the type of
+// the variable, along with the variables it implies. It returns ErrUnknownSystemVariable if there's no such variable.
+func (g *GlobalVariables) Set(name string, value interface{}) error {
+	v, ok := GetSystemVariable(name)
+	if !ok {
+		return ErrUnknownSystemVariable.New(name)
+	}
+
+	value, err := v.Type.Convert(value)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[v.Name] = value
+	if v.Alias != "" {
+		g.values[v.Alias] = value
+	}
+	if name, value, ok := impliedReadOnly(v.Name, value); ok {
+		g.values[name] = value
+	}
+	return nil
+}
+
+// Int64 returns the global value of the system variable with the name given as an int64, or zero if there's no such
+// variable or its value isn't a number.
+func (g *GlobalVariables) Int64(name string) int64 {
+	v, ok := g.Get(name)
+	if !ok {
+		return 0
+	}
+	n, err := Int64.Convert(v.Default)
+	if err != nil {
+		return 0
+	}
+	return n.(int64)
+}
+
+// All returns the global values of all the system variables, keyed by their names.
+func (g *GlobalVariables) All() map[string]TypedValue {
+	config := DefaultSessionConfig()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for name, value := range g.values {
+		if v, ok := config[name]; ok {
+			config[name] = TypedValue{v.Typ, value}
+		}
+	}
+	return config
+}
+
+// changed returns the global values that were set, keyed by the names of their variables.
+func (g *GlobalVariables) changed() map[string]interface{} {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	values := make(map[string]interface{}, len(g.values))
+	for name, value := range g.values {
+		values[name] = value
+	}
+	return values
+}
@@ -0,0 +1,89 @@
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSystemVariables(t *testing.T) {
+	require := require.New(t)
+
+	v, ok := GetSystemVariable("WAIT_TIMEOUT")
+	require.True(ok)
+	require.Equal(SystemVariable{Name: "wait_timeout", Type: Int64, Default: int64(28800)}, v)
+
+	_, ok = GetSystemVariable("my_var")
+	require.False(ok)
+
+	defer func() {
+		systemVariablesMu.Lock()
+		delete(systemVariables, "my_var")
+		systemVariablesMu.Unlock()
+		RegisterSystemVariables(v)
+	}()
+	RegisterSystemVariables(
+		SystemVariable{Name: "My_Var", Type: LongText, Default: "foo"},
+		SystemVariable{Name: "wait_timeout", Type: Int64, Default: int64(60)},
+	)
+
+	typ, val := NewBaseSession().Get("my_var")
+	require.Equal(LongText, typ)
+	require.Equal("foo", val)
+	require.Equal(int64(60), DefaultSessionConfig()["wait_timeout"].Value)
+
+	vars := SystemVariables()
+	for i := 1; i < len(vars); i++ {
+		require.True(vars[i-1].Name < vars[i].Name)
+	}
+}
+
+func TestSystemVariableAliases(t *testing.T) {
+	require := require.New(t)
+
+	sess := NewBaseSession()
+	_, val := sess.Get("tx_isolation")
+	require.Equal("READ-UNCOMMITTED", val)
+
+	require.NoError(sess.Set(context.Background(), "transaction_isolation", LongText, "SERIALIZABLE"))
+	_, val = sess.Get("tx_isolation")
+	require.Equal("SERIALIZABLE", val)
+
+	require.NoError(sess.Set(context.Background(), "tx_read_only", Int8, int8(1)))
+	_, val = sess.Get("transaction_read_only")
+	require.Equal(int8(1), val)
+}
+
+func TestGlobalVariables(t *testing.T) {
+	require := require.New(t)
+
+	globals, other := NewGlobalVariables(), NewGlobalVariables()
+	sess := NewBaseSession()
+	require.NoError(sess.Set(context.Background(), "wait_timeout", Int64, int64(10)))
+	require.NoError(globals.Set("TRANSACTION_ISOLATION", "SERIALIZABLE"))
+	require.NoError(globals.Set("wait_timeout", 20))
+
+	v, _ := globals.Get("tx_isolation")
+	require.Equal("SERIALIZABLE", v.Default)
+	require.Equal(int64(20), globals.Int64("wait_timeout"))
+	require.Equal("SERIALIZABLE", globals.All()["transaction_isolation"].Value)
+	v, _ = other.Get("tx_isolation")
+	require.Equal("READ-UNCOMMITTED", v.Default)
+	v, _ = GetSystemVariable("tx_isolation")
+	require.Equal("READ-UNCOMMITTED", v.Default)
+
+	// Sessions start with the global values, except for the variables they already set
+	sess.(GlobalVariablesSession).InitGlobalVariables(globals)
+	_, val := sess.Get("transaction_isolation")
+	require.Equal("SERIALIZABLE", val)
+	_, val = sess.Get("wait_timeout")
+	require.Equal(int64(10), val)
+
+	require.NoError(globals.Set("transaction_isolation", "READ-COMMITTED"))
+	sess.(GlobalVariablesSession).InitGlobalVariables(globals)
+	_, val = sess.Get("transaction_isolation")
+	require.Equal("SERIALIZABLE", val)
+
+	require.True(ErrUnknownSystemVariable.Is(globals.Set("my_var", 1)))
+}
@@ -11,6 +11,8 @@ type TableRowIter struct {
 	partitions PartitionIter
 	partition  Partition
 	rows       RowIter
+	rows2      RowIter2
+	batch      BatchRowIter
 }
 
 // NewTableRowIter returns a new iterator over the rows in the partitions of the table given.
@@ -19,12 +21,12 @@ func NewTableRowIter(ctx *Context, table Table, partitions PartitionIter) *Table
 }
 
 func (i *TableRowIter) Next() (Row, error) {
-	if i.ctx.Err() != nil {
-		return nil, i.ctx.Err()
+	if err := i.ctx.Interrupted(); err != nil {
+		return nil, err
 	}
 
 	if i.partition == nil {
-		partition, err := i.partitions.Next()
+		partition, err := i.partitions.Next(i.ctx)
 		if err != nil {
 			if err == io.EOF {
 				if e := i.partitions.Close(); e != nil {
@@ -55,12 +57,124 @@ func (i *TableRowIter) Next() (Row, error) {
 
 		i.partition = nil
 		i.rows = nil
+		i.rows2 = nil
+		i.batch = nil
 		return i.Next()
 	}
 
 	return row, err
 }
 
+// Next2 implements the RowIter2 interface. Tables that implement Table2 produce their Row2 values directly; for any
+// others, the rows of the table are encoded.
+func (i *TableRowIter) Next2(frame *RowFrame) error {
+	for {
+		if err := i.ctx.Interrupted(); err != nil {
+			return err
+		}
+
+		if err := i.nextPartition(); err != nil {
+			return err
+		}
+
+		if i.rows == nil {
+			var rows RowIter
+			var err error
+			if t, ok := i.table.(Table2); ok {
+				rows, err = t.PartitionRows2(i.ctx, i.partition)
+			} else {
+				rows, err = i.table.PartitionRows(i.ctx, i.partition)
+			}
+			if err != nil {
+				return err
+			}
+
+			i.rows = rows
+		}
+
+		if i.rows2 == nil {
+			i.rows2 = NewRowIter2(i.table.Schema(), i.rows)
+		}
+
+		err := i.rows2.Next2(frame)
+		if err == io.EOF {
+			if err = i.rows.Close(); err != nil {
+				return err
+			}
+
+			i.partition = nil
+			i.rows = nil
+			i.rows2 = nil
+			i.batch = nil
+			continue
+		}
+
+		return err
+	}
+}
+
+// NextBatch implements the BatchRowIter interface. Batches never span more than one partition.
+func (i *TableRowIter) NextBatch(rows []Row) (int, error) {
+	for {
+		if err := i.ctx.Interrupted(); err != nil {
+			return 0, err
+		}
+
+		if err := i.nextPartition(); err != nil {
+			return 0, err
+		}
+
+		if i.rows == nil {
+			rows, err := i.table.PartitionRows(i.ctx, i.partition)
+			if err != nil {
+				return 0, err
+			}
+
+			i.rows = rows
+		}
+
+		if i.batch == nil {
+			i.batch = NewBatchRowIter(i.rows)
+		}
+
+		n, err := i.batch.NextBatch(rows)
+		if err == io.EOF {
+			if err = i.rows.Close(); err != nil {
+				return 0, err
+			}
+
+			i.partition = nil
+			i.rows = nil
+			i.rows2 = nil
+			i.batch = nil
+			continue
+		}
+
+		return n, err
+	}
+}
+
+// nextPartition moves the iterator to the next partition if it's done with the current one.
+func (i *TableRowIter) nextPartition() error {
+	if i.partition != nil {
+		return nil
+	}
+
+	partition, err := i.partitions.Next(i.ctx)
+	if err != nil {
+		if err == io.EOF {
+			if e := i.partitions.Close(); e != nil {
+				return e
+			}
+		}
+
+		return err
+	}
+
+	i.partition = partition
+	return nil
+}
+
 func (i *TableRowIter) Close() error {
 	if i.rows != nil {
 		if err := i.rows.Close(); err != nil {
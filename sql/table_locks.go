@@ -0,0 +1,379 @@
+package sql
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+const (
+	// LockWaitTimeoutVar is the system variable with how many seconds statements wait for table locks before they
+	// fail.
+	LockWaitTimeoutVar = "lock_wait_timeout"
+	// InnodbLockWaitTimeoutVar is the system variable with how many seconds statements wait to write rows, for their
+	// turn to write to single-writer databases or for the row locks of integrators, before they fail.
+	InnodbLockWaitTimeoutVar = "innodb_lock_wait_timeout"
+)
+
+var (
+	// ErrTableNotLocked is returned when a session that locked tables with LOCK TABLES uses a table it didn't lock.
+	ErrTableNotLocked = errors.NewKind("Table '%s' was not locked with LOCK TABLES")
+	// ErrTableNotLockedForWrite is returned when a session that locked a table with LOCK TABLES ... READ writes to it.
+	ErrTableNotLockedForWrite = errors.NewKind("Table '%s' was locked with a READ lock and can't be updated")
+)
+
+// TableLockMode is the mode of a lock on a table. Locks of different sessions on the same table are only held at the
+// same time if their modes are compatible.
+type TableLockMode byte
+
+const (
+	// SharedReadLock is the lock statements take on the tables they read, so they aren't dropped or altered while
+	// they're read.
+	SharedReadLock TableLockMode = iota
+	// SharedWriteLock is the lock statements take on the tables they insert, update or delete rows of.
+	SharedWriteLock
+	// ReadTablesLock is the lock of LOCK TABLES ... READ, which lets other sessions read the table, but not write to
+	// it.
+	ReadTablesLock
+	// WriteTablesLock is the lock of LOCK TABLES ... WRITE, which keeps other sessions from using the table.
+	WriteTablesLock
+	// ExclusiveLock is the lock statements take on the tables they create, drop or alter, which keeps other sessions
+	// from using the table.
+	ExclusiveLock
+)
+
+// tableLockConflicts are the modes of the locks that can't be held at the same time by different sessions.
+var tableLockConflicts = [...][5]bool{
+	SharedReadLock:  {WriteTablesLock: true, ExclusiveLock: true},
+	SharedWriteLock: {ReadTablesLock: true, WriteTablesLock: true, ExclusiveLock: true},
+	ReadTablesLock:  {SharedWriteLock: true, WriteTablesLock: true, ExclusiveLock: true},
+	WriteTablesLock: {true, true, true, true, true},
+	ExclusiveLock:   {true, true, true, true, true},
+}
+
+func (m TableLockMode) conflicts(other TableLockMode) bool {
+	return tableLockConflicts[m][other]
+}
+
+// TableLockRequest is a lock on a table to acquire.
+type TableLockRequest struct {
+	Database string
+	Table    string
+	Mode     TableLockMode
+}
+
+type tableLockKey struct {
+	db, table string
+}
+
+type tableLock struct {
+	session  uint32
+	key      tableLockKey
+	mode     TableLockMode
+	explicit bool
+}
+
+type tableLockWait struct {
+	session uint32
+	locks   []*tableLock
+	rank    int
+	seq     uint64
+	granted chan struct{}
+	done    bool
+}
+
+// TableLockManager keeps the locks of the tables of a catalog: the metadata locks statements take on the tables they
+// use, which they release when they finish, and the locks of LOCK TABLES, which sessions hold until they run UNLOCK
+// TABLES or disconnect. Sessions in a transaction keep the locks of their statements until it ends.
+//
+// Sessions waiting for locks get them in the order they asked for them, so a session waiting to alter a table isn't
+// kept waiting forever by sessions reading it, except for the sessions that already have locks, which go first.
+// Sessions are never made to wait on sessions that wait for their own locks, here or in the other lock managers of the
+// WaitsForGraph: they fail with an ErrLockDeadlock instead.
+type TableLockManager struct {
+	mu      sync.Mutex
+	graph   *WaitsForGraph
+	granted map[tableLockKey][]*tableLock
+	held    map[uint32][]*tableLock
+	waiting []*tableLockWait
+	// waits are the sessions whose waits are set in the graph.
+	waits map[uint32]bool
+	seq   uint64
+}
+
+// NewTableLockManager returns a new TableLockManager with no locks, which sets the waits of its sessions in the graph
+// given.
+func NewTableLockManager(graph *WaitsForGraph) *TableLockManager {
+	return &TableLockManager{
+		graph:   graph,
+		granted: make(map[tableLockKey][]*tableLock),
+		held:    make(map[uint32][]*tableLock),
+		waits:   make(map[uint32]bool),
+	}
+}
+
+// AcquireTableLocks acquires the locks given for the session given, waiting for the sessions with locks that conflict
+// with them, or ahead in the queue, to release them. All the locks are acquired at once. Explicit locks are the ones
+// of LOCK TABLES, and the rest are the ones of statements. Sessions that wait longer than the timeout given fail with
+// ErrLockWaitTimeout, and sessions whose context is done first fail with its error. Names are case insensitive.
+func (m *TableLockManager) AcquireTableLocks(ctx *Context, session uint32, requests []TableLockRequest, explicit bool, timeout time.Duration) error {
+	m.mu.Lock()
+
+	var locks []*tableLock
+	for _, r := range requests {
+		l := &tableLock{
+			session:  session,
+			key:      tableLockKey{strings.ToLower(r.Database), strings.ToLower(r.Table)},
+			mode:     r.Mode,
+			explicit: explicit,
+		}
+		if !m.holds(l) {
+			locks = append(locks, l)
+		}
+	}
+	if len(locks) == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+
+	m.seq++
+	w := &tableLockWait{session: session, locks: locks, rank: 1, seq: m.seq, granted: make(chan struct{})}
+	if len(m.held[session]) > 0 {
+		w.rank = 0
+	}
+	m.waiting = append(m.waiting, w)
+	sort.SliceStable(m.waiting, func(i, j int) bool {
+		a, b := m.waiting[i], m.waiting[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		return a.seq < b.seq
+	})
+
+	m.grant()
+	if w.done {
+		m.mu.Unlock()
+		return nil
+	}
+
+	var err error
+	if m.graph.Deadlocked(session) {
+		err = ErrLockDeadlock.New()
+	} else if timeout <= 0 {
+		err = ErrLockWaitTimeout.New()
+	}
+	if err != nil {
+		m.remove(w)
+		m.grant()
+		m.mu.Unlock()
+		return err
+	}
+	m.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.granted:
+		return nil
+	case <-timer.C:
+		err = ErrLockWaitTimeout.New()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// The locks may have been granted right as the wait ended
+	if w.done {
+		return nil
+	}
+	m.remove(w)
+	m.grant()
+	return err
+}
+
+// ReleaseTableLocks releases the explicit locks of the session given, or the locks of its statements.
+func (m *TableLockManager) ReleaseTableLocks(session uint32, explicit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var kept []*tableLock
+	for _, l := range m.held[session] {
+		if l.explicit != explicit {
+			kept = append(kept, l)
+			continue
+		}
+
+		granted := m.granted[l.key]
+		for i, g := range granted {
+			if g == l {
+				granted = append(granted[:i], granted[i+1:]...)
+				break
+			}
+		}
+		if len(granted) == 0 {
+			delete(m.granted, l.key)
+		} else {
+			m.granted[l.key] = granted
+		}
+	}
+
+	if len(kept) == 0 {
+		delete(m.held, session)
+	} else {
+		m.held[session] = kept
+	}
+	m.grant()
+}
+
+// ExplicitTableLocks returns the locks the session given acquired with LOCK TABLES.
+func (m *TableLockManager) ExplicitTableLocks(session uint32) []TableLockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var locks []TableLockRequest
+	for _, l := range m.held[session] {
+		if l.explicit {
+			locks = append(locks, TableLockRequest{Database: l.key.db, Table: l.key.table, Mode: l.mode})
+		}
+	}
+	return locks
+}
+
+// CheckLockedTables returns an ErrTableNotLocked error if any of the locks given is on a table that isn't in the locks
+// of LOCK TABLES given, or an ErrTableNotLockedForWrite error if any of them is a lock to write to, or alter, a table
+// locked to be read.
+func CheckLockedTables(locked, requests []TableLockRequest) error {
+	for _, r := range requests {
+		var lock *TableLockRequest
+		for i, l := range locked {
+			if strings.EqualFold(l.Database, r.Database) && strings.EqualFold(l.Table, r.Table) {
+				lock = &locked[i]
+				if l.Mode == WriteTablesLock {
+					break
+				}
+			}
+		}
+
+		if lock == nil {
+			return ErrTableNotLocked.New(r.Table)
+		}
+		if lock.Mode == ReadTablesLock && r.Mode != SharedReadLock {
+			return ErrTableNotLockedForWrite.New(r.Table)
+		}
+	}
+	return nil
+}
+
+// LockWaitTimeout returns the lock_wait_timeout of the session of the context given.
+func LockWaitTimeout(ctx *Context) time.Duration {
+	return timeoutVar(ctx, LockWaitTimeoutVar)
+}
+
+// InnodbLockWaitTimeout returns the innodb_lock_wait_timeout of the session of the context given.
+func InnodbLockWaitTimeout(ctx *Context) time.Duration {
+	return timeoutVar(ctx, InnodbLockWaitTimeoutVar)
+}
+
+func timeoutVar(ctx *Context, name string) time.Duration {
+	if ctx.Session == nil {
+		return 0
+	}
+	_, v := ctx.Get(name)
+	n, err := Int64.Convert(v)
+	if err != nil || n == nil {
+		return 0
+	}
+	return time.Duration(n.(int64)) * time.Second
+}
+
+// holds returns whether the session of the lock given already holds a lock like it.
+func (m *TableLockManager) holds(l *tableLock) bool {
+	for _, h := range m.held[l.session] {
+		if h.key == l.key && h.mode == l.mode && h.explicit == l.explicit {
+			return true
+		}
+	}
+	return false
+}
+
+// blockers returns the sessions the wait given waits for: the ones with locks that conflict with the ones it wants,
+// and the ones ahead of it in the queue that want any of them.
+func (m *TableLockManager) blockers(w *tableLockWait) []uint32 {
+	var sessions []uint32
+	for _, l := range w.locks {
+		for _, g := range m.granted[l.key] {
+			if g.session != w.session && l.mode.conflicts(g.mode) {
+				sessions = append(sessions, g.session)
+			}
+		}
+	}
+	for _, ahead := range m.waiting {
+		if ahead == w {
+			break
+		}
+		if ahead.session != w.session && ahead.conflicts(w) {
+			sessions = append(sessions, ahead.session)
+		}
+	}
+	return sessions
+}
+
+func (w *tableLockWait) conflicts(other *tableLockWait) bool {
+	for _, a := range w.locks {
+		for _, b := range other.locks {
+			if a.key == b.key && a.mode.conflicts(b.mode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// grant grants their locks to the waits that don't wait for anyone anymore, in the order of the queue, and sets the
+// waits of the rest in the graph.
+func (m *TableLockManager) grant() {
+	for i := 0; i < len(m.waiting); {
+		w := m.waiting[i]
+		if len(m.blockers(w)) > 0 {
+			i++
+			continue
+		}
+
+		for _, l := range w.locks {
+			m.granted[l.key] = append(m.granted[l.key], l)
+			m.held[w.session] = append(m.held[w.session], l)
+		}
+		m.waiting = append(m.waiting[:i], m.waiting[i+1:]...)
+		w.done = true
+		close(w.granted)
+	}
+
+	waits := make(map[uint32][]uint32)
+	for _, w := range m.waiting {
+		waits[w.session] = append(waits[w.session], m.blockers(w)...)
+	}
+	for session := range m.waits {
+		if _, ok := waits[session]; !ok {
+			m.graph.SetWaits(session, m, nil)
+			delete(m.waits, session)
+		}
+	}
+	for session, blockers := range waits {
+		m.graph.SetWaits(session, m, blockers)
+		m.waits[session] = true
+	}
+}
+
+func (m *TableLockManager) remove(w *tableLockWait) {
+	for i, other := range m.waiting {
+		if other == w {
+			m.waiting = append(m.waiting[:i], m.waiting[i+1:]...)
+			return
+		}
+	}
+}
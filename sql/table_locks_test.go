@@ -0,0 +1,111 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableLockManager(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	m := NewTableLockManager(NewWaitsForGraph())
+
+	lock := func(session uint32, mode TableLockMode, explicit bool) error {
+		return m.AcquireTableLocks(ctx, session, []TableLockRequest{{"db", "t", mode}}, explicit, 0)
+	}
+
+	require.NoError(lock(1, SharedReadLock, false))
+	require.NoError(lock(2, SharedWriteLock, false))
+	require.True(ErrLockWaitTimeout.Is(lock(3, ReadTablesLock, true)))
+	require.True(ErrLockWaitTimeout.Is(lock(3, ExclusiveLock, false)))
+	// Sessions never conflict with their own locks, and names are case insensitive
+	require.NoError(m.AcquireTableLocks(ctx, 2, []TableLockRequest{{"DB", "T", ReadTablesLock}}, true, 0))
+	m.ReleaseTableLocks(2, true)
+
+	m.ReleaseTableLocks(2, false)
+	require.NoError(lock(3, ReadTablesLock, true))
+	require.Equal([]TableLockRequest{{"db", "t", ReadTablesLock}}, m.ExplicitTableLocks(3))
+	require.True(ErrLockWaitTimeout.Is(lock(2, SharedWriteLock, false)))
+	require.NoError(lock(2, SharedReadLock, false))
+
+	// Explicit locks are kept when the locks of statements are released
+	m.ReleaseTableLocks(3, false)
+	require.Len(m.ExplicitTableLocks(3), 1)
+	m.ReleaseTableLocks(3, true)
+	m.ReleaseTableLocks(2, false)
+	m.ReleaseTableLocks(1, false)
+	require.Empty(m.ExplicitTableLocks(3))
+	require.Empty(m.granted)
+	require.Empty(m.held)
+}
+
+func TestTableLockManagerQueue(t *testing.T) {
+	require := require.New(t)
+	m := NewTableLockManager(NewWaitsForGraph())
+
+	require.NoError(m.AcquireTableLocks(NewEmptyContext(), 1, []TableLockRequest{{"db", "t", SharedReadLock}}, false, time.Minute))
+
+	altered := make(chan error)
+	go func() {
+		altered <- m.AcquireTableLocks(NewEmptyContext(), 2, []TableLockRequest{{"db", "t", ExclusiveLock}}, false, time.Minute)
+	}()
+	require.Eventually(func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.waiting) == 1
+	}, time.Second, time.Millisecond)
+
+	// Readers coming after the statement waiting to alter the table wait behind it
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.AcquireTableLocks(NewContext(ctx), 3, []TableLockRequest{{"db", "t", SharedReadLock}}, false, time.Minute)
+	require.Equal(context.DeadlineExceeded, err)
+
+	// Unless they already have locks, like the ones of a transaction
+	require.NoError(m.AcquireTableLocks(NewEmptyContext(), 1, []TableLockRequest{{"db", "t", SharedWriteLock}}, false, time.Minute))
+
+	m.ReleaseTableLocks(1, false)
+	require.NoError(<-altered)
+	m.ReleaseTableLocks(2, false)
+	require.Empty(m.granted)
+	require.Empty(m.waiting)
+}
+
+func TestTableLockManagerDeadlock(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	m := NewTableLockManager(NewWaitsForGraph())
+
+	require.NoError(m.AcquireTableLocks(ctx, 1, []TableLockRequest{{"db", "a", SharedReadLock}}, false, time.Minute))
+	require.NoError(m.AcquireTableLocks(ctx, 2, []TableLockRequest{{"db", "b", SharedReadLock}}, false, time.Minute))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- m.AcquireTableLocks(NewEmptyContext(), 1, []TableLockRequest{{"db", "b", ExclusiveLock}}, false, time.Minute)
+	}()
+	require.Eventually(func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return len(m.waiting) == 1
+	}, time.Second, time.Millisecond)
+
+	err := m.AcquireTableLocks(ctx, 2, []TableLockRequest{{"db", "a", ExclusiveLock}}, false, time.Minute)
+	require.True(ErrLockDeadlock.Is(err))
+
+	m.ReleaseTableLocks(2, false)
+	require.NoError(<-acquired)
+	m.ReleaseTableLocks(1, false)
+	require.Empty(m.granted)
+}
+
+func TestCheckLockedTables(t *testing.T) {
+	require := require.New(t)
+
+	locked := []TableLockRequest{{"db", "r", ReadTablesLock}, {"db", "w", WriteTablesLock}}
+	require.NoError(CheckLockedTables(locked, []TableLockRequest{{"db", "R", SharedReadLock}, {"db", "w", ExclusiveLock}}))
+	require.True(ErrTableNotLockedForWrite.Is(CheckLockedTables(locked, []TableLockRequest{{"db", "r", SharedWriteLock}})))
+	require.True(ErrTableNotLocked.Is(CheckLockedTables(locked, []TableLockRequest{{"other", "r", SharedReadLock}})))
+}
@@ -0,0 +1,154 @@
+package tablefunction
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// numbersPartitionSize is the number of rows of each partition of a numbers table.
+const numbersPartitionSize = 64 * 1024
+
+// Numbers is the table function numbers([start,] count), whose table has a row for each of count consecutive
+// integers from start, or from 0 if no start is given, in a column named number. Rows are generated as they're read,
+// in partitions that can be read in parallel, so large sequences don't take memory.
+var Numbers sql.TableFunction = numbersFunction{}
+
+type numbersFunction struct{}
+
+func (numbersFunction) Name() string {
+	return "numbers"
+}
+
+func (f numbersFunction) NewTable(ctx *sql.Context, args []sql.Expression) (sql.Table, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, sql.ErrInvalidArgumentNumber.New(f.Name(), "1 or 2", len(args))
+	}
+
+	values, err := evalArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	ints := make([]int64, len(values))
+	for i, v := range values {
+		ints[i], err = int64Arg(f.Name(), args[i], v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t := &NumbersTable{count: ints[0]}
+	if len(ints) == 2 {
+		t.start, t.count = ints[0], ints[1]
+	}
+
+	if t.count < 0 {
+		return nil, sql.ErrInvalidTableFunctionArgument.New(f.Name(), args[len(args)-1].String())
+	}
+
+	return t, nil
+}
+
+// NumbersTable is the table of a call of the numbers table function.
+type NumbersTable struct {
+	start int64
+	count int64
+}
+
+var _ sql.Table = (*NumbersTable)(nil)
+var _ sql.PartitionCounter = (*NumbersTable)(nil)
+
+// Name implements the sql.Nameable interface.
+func (t *NumbersTable) Name() string {
+	return Numbers.Name()
+}
+
+func (t *NumbersTable) String() string {
+	return Numbers.Name()
+}
+
+// DebugString implements the sql.DebugStringer interface.
+func (t *NumbersTable) DebugString() string {
+	return fmt.Sprintf("%s(%d, %d)", Numbers.Name(), t.start, t.count)
+}
+
+// Schema implements the sql.Table interface.
+func (t *NumbersTable) Schema() sql.Schema {
+	return sql.Schema{
+		{Name: "number", Type: sql.Int64, Nullable: false, Source: Numbers.Name()},
+	}
+}
+
+// Partitions implements the sql.Table interface.
+func (t *NumbersTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &numbersPartitionIter{count: t.count}, nil
+}
+
+// PartitionCount implements the sql.PartitionCounter interface.
+func (t *NumbersTable) PartitionCount(*sql.Context) (int64, error) {
+	return (t.count + numbersPartitionSize - 1) / numbersPartitionSize, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *NumbersTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	key := partition.Key()
+	if len(key) != 8 {
+		return nil, fmt.Errorf("invalid partition of %s: %q", t.Name(), key)
+	}
+
+	offset := int64(binary.BigEndian.Uint64(key))
+	end := offset + numbersPartitionSize
+	if end > t.count {
+		end = t.count
+	}
+
+	return &numbersIter{next: t.start + offset, end: t.start + end}, nil
+}
+
+// numbersPartition is the partition of the numbers from an offset of the sequence.
+type numbersPartition []byte
+
+func (p numbersPartition) Key() []byte {
+	return p
+}
+
+type numbersPartitionIter struct {
+	count  int64
+	offset int64
+}
+
+func (i *numbersPartitionIter) Next(*sql.Context) (sql.Partition, error) {
+	if i.offset >= i.count {
+		return nil, io.EOF
+	}
+
+	key := make(numbersPartition, 8)
+	binary.BigEndian.PutUint64(key, uint64(i.offset))
+	i.offset += numbersPartitionSize
+	return key, nil
+}
+
+func (i *numbersPartitionIter) Close() error {
+	return nil
+}
+
+type numbersIter struct {
+	next int64
+	end  int64
+}
+
+func (i *numbersIter) Next() (sql.Row, error) {
+	if i.next >= i.end {
+		return nil, io.EOF
+	}
+
+	i.next++
+	return sql.NewRow(i.next - 1), nil
+}
+
+func (i *numbersIter) Close() error {
+	return nil
+}
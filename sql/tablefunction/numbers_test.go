@@ -0,0 +1,99 @@
+package tablefunction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+func tableRows(t *testing.T, ctx *sql.Context, table sql.Table) []sql.Row {
+	t.Helper()
+
+	iter, err := table.Partitions(ctx)
+	require.NoError(t, err)
+
+	var rows []sql.Row
+	for {
+		p, err := iter.Next(ctx)
+		if err != nil {
+			break
+		}
+
+		partition, err := table.PartitionRows(ctx, p)
+		require.NoError(t, err)
+		r, err := sql.RowIterToRows(partition)
+		require.NoError(t, err)
+		rows = append(rows, r...)
+	}
+	require.NoError(t, iter.Close())
+
+	return rows
+}
+
+func literals(values ...interface{}) []sql.Expression {
+	args := make([]sql.Expression, len(values))
+	for i, v := range values {
+		switch v.(type) {
+		case nil:
+			args[i] = expression.NewLiteral(nil, sql.Null)
+		case string:
+			args[i] = expression.NewLiteral(v, sql.LongText)
+		default:
+			args[i] = expression.NewLiteral(v, sql.Int64)
+		}
+	}
+	return args
+}
+
+func TestNumbers(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+
+	testCases := []struct {
+		name     string
+		args     []sql.Expression
+		expected []sql.Row
+		err      bool
+	}{
+		{"count", literals(int64(3)), []sql.Row{{int64(0)}, {int64(1)}, {int64(2)}}, false},
+		{"start and count", literals(int64(-1), int64(2)), []sql.Row{{int64(-1)}, {int64(0)}}, false},
+		{"string count", literals("2"), []sql.Row{{int64(0)}, {int64(1)}}, false},
+		{"empty", literals(int64(0)), nil, false},
+		{"negative count", literals(int64(-1)), nil, true},
+		{"null count", literals(nil), nil, true},
+		{"no arguments", nil, nil, true},
+		{"too many arguments", literals(int64(1), int64(2), int64(3)), nil, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := Numbers.NewTable(ctx, tt.args)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tableRows(t, ctx, table))
+		})
+	}
+}
+
+func TestNumbersPartitions(t *testing.T) {
+	require := require.New(t)
+	ctx := sql.NewEmptyContext()
+
+	table, err := Numbers.NewTable(ctx, literals(int64(10), int64(2*numbersPartitionSize+1)))
+	require.NoError(err)
+
+	count, err := table.(sql.PartitionCounter).PartitionCount(ctx)
+	require.NoError(err)
+	require.Equal(int64(3), count)
+
+	rows := tableRows(t, ctx, table)
+	require.Len(rows, 2*numbersPartitionSize+1)
+	for i, row := range rows {
+		require.Equal(sql.NewRow(int64(10+i)), row)
+	}
+}
@@ -0,0 +1,37 @@
+package tablefunction
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// Defaults has all the default table functions.
+var Defaults = []sql.TableFunction{
+	Numbers,
+	StringSplit,
+}
+
+// evalArgs evaluates the arguments of a table function call, which don't depend on any row.
+func evalArgs(ctx *sql.Context, args []sql.Expression) ([]interface{}, error) {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := arg.Eval(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// int64Arg converts the value of an argument of a call of the table function with the name given to an int64.
+func int64Arg(name string, arg sql.Expression, v interface{}) (int64, error) {
+	if v == nil {
+		return 0, sql.ErrInvalidTableFunctionArgument.New(name, arg.String())
+	}
+
+	i, err := sql.Int64.Convert(v)
+	if err != nil {
+		return 0, sql.ErrInvalidTableFunctionArgument.New(name, arg.String())
+	}
+	return i.(int64), nil
+}
@@ -0,0 +1,149 @@
+package tablefunction
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// StringSplit is the table function string_split(str, separator), whose table has a row for each of the substrings
+// of str between separators, with the substring in a column named value and its position, starting at 1, in a column
+// named ordinal. An empty separator splits the string into its characters. If any argument is NULL the table has no
+// rows.
+var StringSplit sql.TableFunction = stringSplitFunction{}
+
+type stringSplitFunction struct{}
+
+func (stringSplitFunction) Name() string {
+	return "string_split"
+}
+
+func (f stringSplitFunction) NewTable(ctx *sql.Context, args []sql.Expression) (sql.Table, error) {
+	if len(args) != 2 {
+		return nil, sql.ErrInvalidArgumentNumber.New(f.Name(), 2, len(args))
+	}
+
+	values, err := evalArgs(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &StringSplitTable{}
+	if values[0] == nil || values[1] == nil {
+		t.null = true
+		return t, nil
+	}
+
+	for i, v := range values {
+		s, err := sql.LongText.Convert(v)
+		if err != nil {
+			return nil, sql.ErrInvalidTableFunctionArgument.New(f.Name(), args[i].String())
+		}
+		values[i] = s
+	}
+
+	t.str, t.separator = values[0].(string), values[1].(string)
+	return t, nil
+}
+
+// StringSplitTable is the table of a call of the string_split table function.
+type StringSplitTable struct {
+	str       string
+	separator string
+	null      bool
+}
+
+var _ sql.Table = (*StringSplitTable)(nil)
+
+// Name implements the sql.Nameable interface.
+func (t *StringSplitTable) Name() string {
+	return StringSplit.Name()
+}
+
+func (t *StringSplitTable) String() string {
+	return StringSplit.Name()
+}
+
+// Schema implements the sql.Table interface.
+func (t *StringSplitTable) Schema() sql.Schema {
+	return sql.Schema{
+		{Name: "value", Type: sql.LongText, Nullable: false, Source: StringSplit.Name()},
+		{Name: "ordinal", Type: sql.Int64, Nullable: false, Source: StringSplit.Name()},
+	}
+}
+
+// Partitions implements the sql.Table interface.
+func (t *StringSplitTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &stringSplitPartitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *StringSplitTable) PartitionRows(ctx *sql.Context, partition sql.Partition) (sql.RowIter, error) {
+	if t.null {
+		return sql.RowsToRowIter(), nil
+	}
+	return &stringSplitIter{rest: t.str, separator: t.separator}, nil
+}
+
+type stringSplitPartition struct{}
+
+func (stringSplitPartition) Key() []byte {
+	return []byte(StringSplit.Name())
+}
+
+type stringSplitPartitionIter struct {
+	done bool
+}
+
+func (i *stringSplitPartitionIter) Next(*sql.Context) (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+
+	i.done = true
+	return stringSplitPartition{}, nil
+}
+
+func (i *stringSplitPartitionIter) Close() error {
+	return nil
+}
+
+// stringSplitIter finds the next substring of the string as rows are read, instead of splitting it all at once.
+type stringSplitIter struct {
+	rest      string
+	separator string
+	ordinal   int64
+	done      bool
+}
+
+func (i *stringSplitIter) Next() (sql.Row, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+
+	var value string
+	if i.separator == "" {
+		if len(i.rest) == 0 {
+			return nil, io.EOF
+		}
+		_, size := utf8.DecodeRuneInString(i.rest)
+		value = i.rest[:size]
+		i.rest = i.rest[size:]
+		i.done = len(i.rest) == 0
+	} else if n := strings.Index(i.rest, i.separator); n >= 0 {
+		value = i.rest[:n]
+		i.rest = i.rest[n+len(i.separator):]
+	} else {
+		value = i.rest
+		i.done = true
+	}
+
+	i.ordinal++
+	return sql.NewRow(value, i.ordinal), nil
+}
+
+func (i *stringSplitIter) Close() error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package tablefunction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+func TestStringSplit(t *testing.T) {
+	ctx := sql.NewEmptyContext()
+
+	testCases := []struct {
+		name     string
+		args     []interface{}
+		expected []sql.Row
+		err      bool
+	}{
+		{"separator", []interface{}{"a,b,,c", ","}, []sql.Row{{"a", int64(1)}, {"b", int64(2)}, {"", int64(3)}, {"c", int64(4)}}, false},
+		{"long separator", []interface{}{"a::b", "::"}, []sql.Row{{"a", int64(1)}, {"b", int64(2)}}, false},
+		{"no separator in string", []interface{}{"abc", ","}, []sql.Row{{"abc", int64(1)}}, false},
+		{"empty string", []interface{}{"", ","}, []sql.Row{{"", int64(1)}}, false},
+		{"empty separator", []interface{}{"hé", ""}, []sql.Row{{"h", int64(1)}, {"é", int64(2)}}, false},
+		{"number", []interface{}{int64(102), "0"}, []sql.Row{{"1", int64(1)}, {"2", int64(2)}}, false},
+		{"null string", []interface{}{nil, ","}, nil, false},
+		{"null separator", []interface{}{"a", nil}, nil, false},
+		{"one argument", []interface{}{"a"}, nil, true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := StringSplit.NewTable(ctx, literals(tt.args...))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tableRows(t, ctx, table))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"strings"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/internal/similartext"
+)
+
+// ErrTableFunctionAlreadyRegistered is thrown when a table function is already registered
+var ErrTableFunctionAlreadyRegistered = errors.NewKind("table function '%s' is already registered")
+
+// ErrTableFunctionNotFound is thrown when a table function is not found
+var ErrTableFunctionNotFound = errors.NewKind("table function: '%s' not found")
+
+// ErrInvalidTableFunctionArgument is thrown when an argument of a table function call can't be evaluated before the
+// query is run, such as when it references a column.
+var ErrInvalidTableFunctionArgument = errors.NewKind("invalid argument of table function '%s': %s")
+
+// TableFunction is a function that's called in the FROM clause of a query, like a table, and whose result is a table.
+type TableFunction interface {
+	// Name returns the name the function is called with.
+	Name() string
+	// NewTable returns the table with the result of calling the function with the arguments given. Arguments are
+	// resolved and don't depend on any row, so they can be evaluated with a nil row. The rows of the table should be
+	// produced as they're read, partition by partition, rather than when the table is created.
+	NewTable(ctx *Context, args []Expression) (Table, error)
+}
+
+// TableFunctionRegistry is used to register table functions, both builtin and user-defined.
+type TableFunctionRegistry map[string]TableFunction
+
+// NewTableFunctionRegistry creates a new TableFunctionRegistry.
+func NewTableFunctionRegistry() TableFunctionRegistry {
+	return make(TableFunctionRegistry)
+}
+
+// RegisterTableFunction registers table functions. If a table function with the same name is already registered,
+// ErrTableFunctionAlreadyRegistered is returned.
+func (r TableFunctionRegistry) RegisterTableFunction(fn ...TableFunction) error {
+	for _, f := range fn {
+		name := strings.ToLower(f.Name())
+		if _, ok := r[name]; ok {
+			return ErrTableFunctionAlreadyRegistered.New(f.Name())
+		}
+		r[name] = f
+	}
+	return nil
+}
+
+// MustRegisterTableFunction registers table functions. If a table function with the same name is already registered,
+// it will panic!
+func (r TableFunctionRegistry) MustRegisterTableFunction(fn ...TableFunction) {
+	if err := r.RegisterTableFunction(fn...); err != nil {
+		panic(err)
+	}
+}
+
+// TableFunction returns the table function with the given name, case insensitively.
+func (r TableFunctionRegistry) TableFunction(name string) (TableFunction, error) {
+	if len(r) == 0 {
+		return nil, ErrTableFunctionNotFound.New(name)
+	}
+
+	if fn, ok := r[strings.ToLower(name)]; ok {
+		return fn, nil
+	}
+	similar := similartext.FindFromMap(r, name)
+	return nil, ErrTableFunctionNotFound.New(name + similar)
+}
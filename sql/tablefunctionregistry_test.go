@@ -0,0 +1,38 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+type testTableFunction struct {
+	name string
+}
+
+func (f testTableFunction) Name() string { return f.name }
+
+func (f testTableFunction) NewTable(*sql.Context, []sql.Expression) (sql.Table, error) {
+	return memory.NewTable(f.name, nil), nil
+}
+
+func TestTableFunctionRegistry(t *testing.T) {
+	require := require.New(t)
+
+	c := sql.NewCatalog()
+	c.MustRegisterTableFunction(testTableFunction{"Numbers"})
+
+	f, err := c.TableFunction("numbers")
+	require.NoError(err)
+	require.Equal("Numbers", f.Name())
+
+	err = c.RegisterTableFunction(testTableFunction{"numbers"})
+	require.True(sql.ErrTableFunctionAlreadyRegistered.Is(err))
+
+	_, err = c.TableFunction("number")
+	require.True(sql.ErrTableFunctionNotFound.Is(err))
+	require.Contains(err.Error(), "maybe you mean")
+}
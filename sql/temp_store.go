@@ -0,0 +1,210 @@
+package sql
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// TempStore is where queries keep the intermediate results that are too large to be kept in memory, like the runs of
+// sorts that spill their rows. Queries use the store of their session, which is a FileTempStore writing files to the
+// temporary directory of the system unless another one is set with Session.SetTempStore, so embedders can keep
+// intermediate data on specific disks, or encrypt it.
+type TempStore interface {
+	// Create returns a new empty temporary file, which is removed once it's closed.
+	Create(ctx *Context) (TempFile, error)
+}
+
+// TempFile is a temporary file of a TempStore. Files are written sequentially, and read back from the start once
+// they're written.
+type TempFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+}
+
+// FileTempStore is a TempStore of files in a directory.
+type FileTempStore struct {
+	// Dir is the directory of the files, or empty for the temporary directory of the system.
+	Dir string
+}
+
+var _ TempStore = (*FileTempStore)(nil)
+
+// NewFileTempStore returns a TempStore of files in the directory given, or in the temporary directory of the system if
+// it's empty.
+func NewFileTempStore(dir string) *FileTempStore {
+	return &FileTempStore{Dir: dir}
+}
+
+// Create implements the TempStore interface.
+func (s *FileTempStore) Create(*Context) (TempFile, error) {
+	f, err := ioutil.TempFile(s.Dir, "gms-tmp-")
+	if err != nil {
+		return nil, err
+	}
+	return &tempFile{f}, nil
+}
+
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if rmErr := os.Remove(f.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// defaultTempStore is the store of sessions that haven't been given another one.
+var defaultTempStore TempStore = NewFileTempStore("")
+
+// TempRows are rows written to a file of the TempStore of a session, to be read back in the order they were written.
+// Values are encoded as they are in Row2 values, in the text encoding of their types, and decoded with the types of
+// the schema of the rows.
+type TempRows struct {
+	schema Schema
+	file   TempFile
+	w      *bufio.Writer
+	buf    []byte
+	len    int
+}
+
+// NewTempRows returns empty temporary rows of the schema given, written to a new file of the TempStore of the session
+// of the context given. They must be closed to remove the file.
+func NewTempRows(ctx *Context, schema Schema) (*TempRows, error) {
+	store := defaultTempStore
+	if ctx.Session != nil {
+		store = ctx.Session.TempStore()
+	}
+
+	file, err := store.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &TempRows{schema: schema, file: file, w: bufio.NewWriter(file)}, nil
+}
+
+// Add writes a row at the end of the rows. Rows can't be added once they've been read.
+func (r *TempRows) Add(row Row) error {
+	if len(row) != len(r.schema) {
+		return ErrUnexpectedRowLength.New(len(r.schema), len(row))
+	}
+
+	buf := appendUvarint(r.buf[:0], uint64(len(row)))
+	for i, v := range row {
+		val, err := EncodeValue(r.schema[i].Type, v)
+		if err != nil {
+			return err
+		}
+
+		if val.IsNull() {
+			buf = append(buf, 0)
+			continue
+		}
+		buf = append(buf, 1)
+		buf = appendUvarint(buf, uint64(len(val.Val)))
+		buf = append(buf, val.Val...)
+	}
+
+	r.buf = buf
+	if _, err := r.w.Write(buf); err != nil {
+		return err
+	}
+	r.len++
+	return nil
+}
+
+// Len returns the number of rows written.
+func (r *TempRows) Len() int {
+	return r.len
+}
+
+// RowIter returns an iterator over the rows, from the first one. Only one iterator can be used at a time, and closing
+// it doesn't close the rows.
+func (r *TempRows) RowIter() (RowIter, error) {
+	if err := r.w.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return &tempRowsIter{rows: r, r: bufio.NewReader(r.file)}, nil
+}
+
+// Close removes the file of the rows.
+func (r *TempRows) Close() error {
+	return r.file.Close()
+}
+
+type tempRowsIter struct {
+	rows *TempRows
+	r    *bufio.Reader
+	read int
+}
+
+func (i *tempRowsIter) Next() (Row, error) {
+	if i.read >= i.rows.len {
+		return nil, io.EOF
+	}
+
+	n, err := binary.ReadUvarint(i.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	schema := i.rows.schema
+	if int(n) != len(schema) {
+		return nil, ErrUnexpectedRowLength.New(len(schema), n)
+	}
+
+	row := make(Row, n)
+	for j := range row {
+		null, err := i.r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if null == 0 {
+			continue
+		}
+
+		size, err := binary.ReadUvarint(i.r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		val := make([]byte, size)
+		if _, err := io.ReadFull(i.r, val); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		row[j], err = DecodeValue(schema[j].Type, Value{Typ: schema[j].Type.Type(), Val: val})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i.read++
+	return row, nil
+}
+
+func (i *tempRowsIter) Close() error {
+	return nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	return append(buf, b[:n]...)
+}
+
+// unexpectedEOF returns io.ErrUnexpectedEOF for io.EOF, since the rows written to a temporary file are always read
+// before the end of the file is reached.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package sql
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTempRows(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "temp_store")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	ctx := NewEmptyContext()
+	require.Equal(defaultTempStore, ctx.TempStore())
+	ctx.SetTempStore(NewFileTempStore(dir))
+
+	schema := Schema{
+		{Name: "i", Type: Int64, Nullable: true},
+		{Name: "s", Type: LongText, Nullable: true},
+		{Name: "f", Type: Float64},
+		{Name: "d", Type: MustCreateDecimalType(10, 2)},
+		{Name: "t", Type: Datetime},
+	}
+	rows := []Row{
+		{int64(1), "a", 1.5, "1.25", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{nil, "", -0.25, "-3.00", time.Date(1999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{int64(-3), nil, 0.0, "0.00", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	temp, err := NewTempRows(ctx, schema)
+	require.NoError(err)
+	for _, row := range rows {
+		require.NoError(temp.Add(row))
+	}
+	require.Error(temp.Add(Row{int64(1)}))
+	require.Equal(len(rows), temp.Len())
+
+	// The rows can be read more than once
+	for n := 0; n < 2; n++ {
+		iter, err := temp.RowIter()
+		require.NoError(err)
+		for _, expected := range rows {
+			row, err := iter.Next()
+			require.NoError(err)
+			require.Equal(expected, row)
+		}
+		_, err = iter.Next()
+		require.Equal(io.EOF, err)
+		require.NoError(iter.Close())
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(err)
+	require.Len(files, 1)
+
+	require.NoError(temp.Close())
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(err)
+	require.Empty(files)
+}
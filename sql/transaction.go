@@ -0,0 +1,43 @@
+package sql
+
+// TransactionalDatabase is a Database whose backend supports transactions, which the engine starts and ends in it for
+// the sessions that use it. The transaction of a session is started in the database by the first of its statements
+// that reads or writes the tables of the database. Sessions with autocommit have a transaction for every statement
+// outside of the transactions started with BEGIN or START TRANSACTION, which is committed once the statement succeeds
+// and rolled back if it fails. Other transactions are committed by COMMIT, and by the statements that end them
+// implicitly, like BEGIN or enabling autocommit, and rolled back by ROLLBACK, or when their session ends.
+type TransactionalDatabase interface {
+	Database
+	// StartTransaction starts the transaction of the session of the context given in the database.
+	StartTransaction(ctx *Context) error
+	// CommitTransaction commits the changes the transaction of the session of the context given made to the database,
+	// and ends it.
+	CommitTransaction(ctx *Context) error
+	// RollbackTransaction discards the changes the transaction of the session of the context given made to the
+	// database, and ends it.
+	RollbackTransaction(ctx *Context) error
+}
+
+// Transaction is the transaction a session is in.
+type Transaction struct {
+	// Explicit is set for the transactions started with BEGIN or START TRANSACTION, which last until COMMIT or ROLLBACK
+	// even with autocommit, and keep the locks and the turns to write of their session until they end. Other
+	// transactions are started by the statements of the session.
+	Explicit bool
+	// Databases are the TransactionalDatabases the transaction was started in, in the order it was started in them.
+	Databases []TransactionalDatabase
+}
+
+// Started returns whether the transaction was started in the database with the name given. A nil transaction wasn't
+// started in any database.
+func (t *Transaction) Started(name string) bool {
+	if t == nil {
+		return false
+	}
+	for _, db := range t.Databases {
+		if db.Name() == name {
+			return true
+		}
+	}
+	return false
+}
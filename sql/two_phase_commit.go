@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"sync"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// ErrPrepareCommit is returned when a TwoPhaseCommitDatabase fails to prepare the changes of a commit, which are rolled
+// back in all the databases written.
+var ErrPrepareCommit = errors.NewKind("could not prepare the commit of database %s: %s")
+
+// TwoPhaseCommitDatabase is a Database whose changes are committed in two phases, so the changes of a statement or a
+// transaction writing to several of them are committed in all or in none: first every database prepares its changes,
+// and only once all of them have, every database commits them. If any database fails to prepare its changes, they're
+// rolled back in all of them.
+type TwoPhaseCommitDatabase interface {
+	Database
+	// PrepareCommit makes sure the changes the session of the context has made to the database since its last commit
+	// can be committed, so that CommitPrepared doesn't fail. Returning an error rolls back the changes of the session
+	// in all the databases.
+	PrepareCommit(ctx *Context) error
+	// CommitPrepared commits the changes prepared by PrepareCommit.
+	CommitPrepared(ctx *Context) error
+	// RollbackPrepared discards the changes the session of the context has made to the database since its last commit,
+	// whether they were prepared or not.
+	RollbackPrepared(ctx *Context) error
+}
+
+// TwoPhaseCommitter keeps the TwoPhaseCommitDatabases written by each session since its last commit, and commits or
+// rolls back their changes together. It also keeps the XA transactions of the sessions, and the prepared ones.
+type TwoPhaseCommitter struct {
+	mu       sync.Mutex
+	written  map[uint32][]TwoPhaseCommitDatabase
+	xa       map[uint32]*xaTransaction
+	prepared map[XID]*xaTransaction
+}
+
+// NewTwoPhaseCommitter returns a new TwoPhaseCommitter with no databases written.
+func NewTwoPhaseCommitter() *TwoPhaseCommitter {
+	return &TwoPhaseCommitter{
+		written:  make(map[uint32][]TwoPhaseCommitDatabase),
+		xa:       make(map[uint32]*xaTransaction),
+		prepared: make(map[XID]*xaTransaction),
+	}
+}
+
+// Track adds the databases given to the ones written by the session given since its last commit.
+func (c *TwoPhaseCommitter) Track(session uint32, dbs ...TwoPhaseCommitDatabase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, db := range dbs {
+		tracked := false
+		for _, w := range c.written[session] {
+			if w.Name() == db.Name() {
+				tracked = true
+				break
+			}
+		}
+		if !tracked {
+			c.written[session] = append(c.written[session], db)
+		}
+	}
+}
+
+// Written returns the names of the databases written by the session given since its last commit, in the order it first
+// wrote to them.
+func (c *TwoPhaseCommitter) Written(session uint32) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var names []string
+	for _, db := range c.written[session] {
+		names = append(names, db.Name())
+	}
+	return names
+}
+
+// Commit commits the changes of the session of the context given to all the databases it has written since its last
+// commit. Their changes are prepared in the order the session first wrote to them, and if any of them fails, the
+// changes are rolled back in all of them and an ErrPrepareCommit is returned. Otherwise they're committed in all of
+// them, and the first error committing them is returned.
+func (c *TwoPhaseCommitter) Commit(ctx *Context) error {
+	dbs := c.take(ctx.Session.ID())
+
+	for _, db := range dbs {
+		if err := db.PrepareCommit(ctx); err != nil {
+			_ = rollbackPrepared(ctx, dbs)
+			return ErrPrepareCommit.New(db.Name(), err)
+		}
+	}
+
+	var err error
+	for _, db := range dbs {
+		if cerr := db.CommitPrepared(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Rollback rolls back the changes of the session of the context given to all the databases it has written since its
+// last commit, returning the first error rolling them back. The XA transaction of the session, if it has one that
+// isn't prepared, is rolled back too.
+func (c *TwoPhaseCommitter) Rollback(ctx *Context) error {
+	return rollbackPrepared(ctx, c.take(ctx.Session.ID()))
+}
+
+func (c *TwoPhaseCommitter) take(session uint32) []TwoPhaseCommitDatabase {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dbs := c.written[session]
+	delete(c.written, session)
+	delete(c.xa, session)
+	return dbs
+}
+
+func rollbackPrepared(ctx *Context, dbs []TwoPhaseCommitDatabase) error {
+	var err error
+	for _, db := range dbs {
+		if rerr := db.RollbackPrepared(ctx); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type twoPhaseDatabase struct {
+	name   string
+	log    *[]string
+	result map[string]error
+}
+
+func (d *twoPhaseDatabase) Name() string { return d.name }
+
+func (d *twoPhaseDatabase) GetTableInsensitive(*Context, string) (Table, bool, error) {
+	return nil, false, nil
+}
+
+func (d *twoPhaseDatabase) GetTableNames(*Context) ([]string, error) { return nil, nil }
+
+func (d *twoPhaseDatabase) phase(name string) error {
+	*d.log = append(*d.log, name+" "+d.name)
+	return d.result[name]
+}
+
+func (d *twoPhaseDatabase) PrepareCommit(*Context) error    { return d.phase("prepare") }
+func (d *twoPhaseDatabase) CommitPrepared(*Context) error   { return d.phase("commit") }
+func (d *twoPhaseDatabase) RollbackPrepared(*Context) error { return d.phase("rollback") }
+
+func TestTwoPhaseCommitter(t *testing.T) {
+	require := require.New(t)
+
+	var log []string
+	a := &twoPhaseDatabase{name: "a", log: &log}
+	b := &twoPhaseDatabase{name: "b", log: &log, result: make(map[string]error)}
+	c := NewTwoPhaseCommitter()
+	ctx := NewContext(NewEmptyContext(), WithSession(NewSession("", "", "", 1)))
+
+	c.Track(1, b, a)
+	c.Track(1, b)
+	c.Track(2, a)
+	require.Equal([]string{"b", "a"}, c.Written(1))
+
+	require.NoError(c.Commit(ctx))
+	require.Equal([]string{"prepare b", "prepare a", "commit b", "commit a"}, log)
+	require.Empty(c.Written(1))
+	require.Equal([]string{"a"}, c.Written(2))
+
+	// Databases that fail to prepare roll back all of them
+	log = nil
+	b.result["prepare"] = fmt.Errorf("disk full")
+	c.Track(1, a, b)
+	require.True(ErrPrepareCommit.Is(c.Commit(ctx)))
+	require.Equal([]string{"prepare a", "prepare b", "rollback a", "rollback b"}, log)
+
+	// All the databases are committed even if some fail
+	log = nil
+	delete(b.result, "prepare")
+	b.result["commit"] = fmt.Errorf("lost connection")
+	c.Track(1, b, a)
+	require.EqualError(c.Commit(ctx), "lost connection")
+	require.Equal([]string{"prepare b", "prepare a", "commit b", "commit a"}, log)
+
+	log = nil
+	require.NoError(c.Rollback(ctx))
+	require.Empty(log)
+}
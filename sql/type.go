@@ -477,6 +477,63 @@ func NumColumns(t Type) int {
 	return len(v)
 }
 
+// ColumnTypeString returns the type given as MySQL shows it in the Type column of DESCRIBE and SHOW COLUMNS, and in
+// the column_type column of information_schema.columns: lower-cased, with the display widths of integers, with the
+// values of enums and sets as they were defined, and without character sets and collations.
+func ColumnTypeString(t Type) string {
+	switch t {
+	case Int8:
+		return "tinyint(4)"
+	case Uint8:
+		return "tinyint(3) unsigned"
+	case Int16:
+		return "smallint(6)"
+	case Uint16:
+		return "smallint(5) unsigned"
+	case Int24:
+		return "mediumint(9)"
+	case Uint24:
+		return "mediumint(8) unsigned"
+	case Int32:
+		return "int(11)"
+	case Uint32:
+		return "int(10) unsigned"
+	case Int64:
+		return "bigint(20)"
+	case Uint64:
+		return "bigint(20) unsigned"
+	case Year:
+		return "year(4)"
+	}
+
+	switch t := t.(type) {
+	case EnumType:
+		return "enum(" + quoteTypeValues(t.Values()) + ")"
+	case SetType:
+		return "set(" + quoteTypeValues(t.Values()) + ")"
+	case StringType:
+		s := strings.ToLower(t.String())
+		if i := strings.Index(s, " character set "); i >= 0 {
+			s = s[:i]
+		}
+		if i := strings.Index(s, " collate "); i >= 0 {
+			s = s[:i]
+		}
+		return s
+	default:
+		return strings.ToLower(t.String())
+	}
+}
+
+// quoteTypeValues returns the values of an enum or set type as a comma-separated list of quoted strings.
+func quoteTypeValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.Replace(v, "'", "''", -1) + "'"
+	}
+	return strings.Join(quoted, ",")
+}
+
 // UnderlyingType returns the underlying type of an array if the type is an
 // array, or the type itself in any other case.
 func UnderlyingType(t Type) Type {
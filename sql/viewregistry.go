@@ -119,6 +119,19 @@ func (r *ViewRegistry) DeleteList(keys []ViewKey, errIfNotExists bool) error {
 	return nil
 }
 
+// DeleteDatabase deletes all the views registered under the database given.
+func (r *ViewRegistry) DeleteDatabase(databaseName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	databaseName = strings.ToLower(databaseName)
+	for key := range r.views {
+		if key.dbName == databaseName {
+			delete(r.views, key)
+		}
+	}
+}
+
 // View returns a pointer to the view specified by the pair {databaseName,
 // viewName}, returning an error if it does not exist.
 func (r *ViewRegistry) View(databaseName, viewName string) (*View, error) {
@@ -166,6 +166,17 @@ func TestDeleteExistingList(t *testing.T) {
 	test(false)
 }
 
+func TestDeleteDatabaseViews(t *testing.T) {
+	require := require.New(t)
+
+	registry := NewViewRegistry()
+	registerKeys(registry, require)
+
+	registry.DeleteDatabase("DB1")
+	require.Len(registry.AllViews(), 1)
+	require.True(registry.Exists("db2", "view1"))
+}
+
 func TestDeleteNonExistingList(t *testing.T) {
 	require := require.New(t)
 
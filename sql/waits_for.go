@@ -0,0 +1,66 @@
+package sql
+
+import "sync"
+
+// WaitsForGraph is the graph of the sessions waiting for the locks of other sessions, in all the lock managers of a
+// catalog: the TableLockManager, the WriteQueue of the engine, and the managers of the row locks of integrators.
+// Sessions that would wait, through the sessions they wait for, on themselves are deadlocked, and their statement
+// fails with an ErrLockDeadlock instead of waiting in a cycle that never ends.
+type WaitsForGraph struct {
+	mu sync.Mutex
+	// waits are the sessions each session waits for, by the lock manager it waits in.
+	waits map[uint32]map[interface{}][]uint32
+}
+
+// NewWaitsForGraph returns a new WaitsForGraph with no sessions waiting.
+func NewWaitsForGraph() *WaitsForGraph {
+	return &WaitsForGraph{waits: make(map[uint32]map[interface{}][]uint32)}
+}
+
+// SetWaits sets the sessions the session given waits for in the lock manager given, which is any comparable value
+// that identifies it, replacing the ones set before. Sessions that don't wait anymore, because they got their locks or
+// gave up, are set to wait for no sessions. Lock managers set the waits of their sessions whenever they change, and
+// check whether the sessions they make wait are Deadlocked.
+func (g *WaitsForGraph) SetWaits(session uint32, manager interface{}, blockers []uint32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(blockers) == 0 {
+		delete(g.waits[session], manager)
+		if len(g.waits[session]) == 0 {
+			delete(g.waits, session)
+		}
+		return
+	}
+
+	if g.waits[session] == nil {
+		g.waits[session] = make(map[interface{}][]uint32)
+	}
+	g.waits[session][manager] = blockers
+}
+
+// Deadlocked returns whether the session given would wait, through the sessions it waits for, on itself.
+func (g *WaitsForGraph) Deadlocked(session uint32) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	visited := make(map[uint32]bool)
+	var waitsOn func(s uint32) bool
+	waitsOn = func(s uint32) bool {
+		for _, blockers := range g.waits[s] {
+			for _, b := range blockers {
+				if b == session {
+					return true
+				}
+				if !visited[b] {
+					visited[b] = true
+					if waitsOn(b) {
+						return true
+					}
+				}
+			}
+		}
+		return false
+	}
+	return waitsOn(session)
+}
@@ -0,0 +1,61 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitsForGraph(t *testing.T) {
+	require := require.New(t)
+	g := NewWaitsForGraph()
+
+	g.SetWaits(1, "a", []uint32{2})
+	g.SetWaits(2, "b", []uint32{3})
+	require.False(g.Deadlocked(1))
+	require.False(g.Deadlocked(3))
+
+	// Cycles through the waits of different lock managers are deadlocks too
+	g.SetWaits(3, "a", []uint32{1})
+	require.True(g.Deadlocked(1))
+	require.True(g.Deadlocked(3))
+
+	g.SetWaits(2, "b", nil)
+	require.False(g.Deadlocked(1))
+	g.SetWaits(1, "a", nil)
+	g.SetWaits(3, "a", nil)
+	require.Empty(g.waits)
+}
+
+func TestWaitsForGraphLockManagers(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	g := NewWaitsForGraph()
+	m := NewTableLockManager(g)
+	q := NewWriteQueue(g)
+
+	require.NoError(q.Acquire(ctx, 1, []string{"db"}, NormalWritePriority, time.Minute))
+	require.NoError(m.AcquireTableLocks(ctx, 2, []TableLockRequest{{"db", "t", SharedReadLock}}, false, time.Minute))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- q.Acquire(NewEmptyContext(), 2, []string{"db"}, NormalWritePriority, time.Minute)
+	}()
+	require.Eventually(func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return len(g.waits) == 1
+	}, time.Second, time.Millisecond)
+
+	// Session 1 would wait for the table lock of session 2, which waits for the turn of session 1
+	err := m.AcquireTableLocks(ctx, 1, []TableLockRequest{{"db", "t", ExclusiveLock}}, false, time.Minute)
+	require.True(ErrLockDeadlock.Is(err))
+	require.Empty(m.waiting)
+
+	q.Release(1)
+	require.NoError(<-acquired)
+	q.Release(2)
+	m.ReleaseTableLocks(2, false)
+	require.Empty(g.waits)
+}
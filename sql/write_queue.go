@@ -0,0 +1,271 @@
+package sql
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+// LowPriorityUpdatesVar is the system variable that makes the statements of a session wait in the WriteQueue behind
+// the statements of the sessions without it.
+const LowPriorityUpdatesVar = "low_priority_updates"
+
+var (
+	// ErrLockWaitTimeout is returned when a statement waits longer than the lock wait timeout of its session for a
+	// lock, or for its turn to write.
+	ErrLockWaitTimeout = errors.NewKind("Lock wait timeout exceeded; try restarting transaction")
+	// ErrLockDeadlock is returned when a statement would wait for a lock, or for its turn to write, on sessions that
+	// are waiting, directly or through other sessions, for the locks of its own session.
+	ErrLockDeadlock = errors.NewKind("Deadlock found when trying to get lock; try restarting transaction")
+)
+
+// TransactionSession is a Session that can be in a transaction. Sessions in a transaction keep their turns to write
+// to single-writer databases until it ends, so no other session writes to them in the middle of the transaction.
+type TransactionSession interface {
+	Session
+	// InTransaction returns whether the session is in a transaction.
+	InTransaction() bool
+	// RollbackTransaction undoes the changes of the current transaction of the session and ends it. It's called when
+	// a statement of the transaction fails with an ErrLockDeadlock, so its locks can be given to the sessions it was
+	// deadlocked with.
+	RollbackTransaction(*Context) error
+}
+
+// WritePriority is the priority of a statement waiting in a WriteQueue.
+type WritePriority byte
+
+const (
+	// NormalWritePriority is the priority of the statements of most sessions.
+	NormalWritePriority WritePriority = iota
+	// LowWritePriority is the priority of the statements of sessions with low_priority_updates set, which wait behind
+	// all the statements of normal priority.
+	LowWritePriority
+)
+
+// WriteQueue gives sessions their turns to write to databases that only allow one writer at a time. A session has the
+// turn of a database until it releases it, and other sessions writing to the database wait in the queue meanwhile.
+//
+// Sessions get their turns in the order they asked for them, first the ones of normal priority, then the ones of low
+// priority, so no statement waits forever while others get ahead of it. Sessions that already have turns, because
+// they're in a transaction, go before the rest, and they're never made to wait on sessions waiting for the turns they
+// have, here or in the other lock managers of the WaitsForGraph: their statements fail with an ErrLockDeadlock instead.
+type WriteQueue struct {
+	mu    sync.Mutex
+	graph *WaitsForGraph
+	// holders are the sessions with the turns of the databases.
+	holders map[string]uint32
+	// held are the databases each session has the turns of.
+	held    map[uint32]map[string]struct{}
+	waiting []*writeRequest
+	// waits are the sessions whose waits are set in the graph.
+	waits map[uint32]bool
+	seq   uint64
+}
+
+type writeRequest struct {
+	session   uint32
+	databases []string
+	// rank is 0 for the sessions that already have turns, and one more than the priority for the rest.
+	rank    int
+	seq     uint64
+	granted chan struct{}
+	done    bool
+}
+
+// NewWriteQueue returns a new WriteQueue with no turns taken, which sets the waits of its sessions in the graph given.
+func NewWriteQueue(graph *WaitsForGraph) *WriteQueue {
+	return &WriteQueue{
+		graph:   graph,
+		holders: make(map[string]uint32),
+		held:    make(map[uint32]map[string]struct{}),
+		waits:   make(map[uint32]bool),
+	}
+}
+
+// Acquire takes the turns of the databases given for the session given, waiting for the sessions that have them, or
+// are ahead in the queue, to release them. All the turns are taken at once, and the ones the session already has are
+// kept. Sessions that wait longer than the timeout given fail with ErrLockWaitTimeout, and sessions whose context is
+// done first fail with its error.
+func (q *WriteQueue) Acquire(ctx *Context, session uint32, databases []string, priority WritePriority, timeout time.Duration) error {
+	q.mu.Lock()
+
+	var wanted []string
+	for _, db := range databases {
+		if _, ok := q.held[session][db]; !ok {
+			wanted = append(wanted, db)
+		}
+	}
+	if len(wanted) == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+
+	q.seq++
+	r := &writeRequest{
+		session:   session,
+		databases: wanted,
+		rank:      int(priority) + 1,
+		seq:       q.seq,
+		granted:   make(chan struct{}),
+	}
+	if len(q.held[session]) > 0 {
+		r.rank = 0
+	}
+	q.waiting = append(q.waiting, r)
+	sort.SliceStable(q.waiting, func(i, j int) bool {
+		return q.waiting[i].ahead(q.waiting[j])
+	})
+
+	q.grant()
+	if r.done {
+		q.mu.Unlock()
+		return nil
+	}
+	if q.graph.Deadlocked(session) {
+		q.remove(r)
+		q.grant()
+		q.mu.Unlock()
+		return ErrLockDeadlock.New()
+	}
+	if timeout <= 0 {
+		q.remove(r)
+		q.grant()
+		q.mu.Unlock()
+		return ErrLockWaitTimeout.New()
+	}
+	q.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-r.granted:
+		return nil
+	case <-timer.C:
+		err = ErrLockWaitTimeout.New()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// The turns may have been given right as the wait ended
+	if r.done {
+		return nil
+	}
+	q.remove(r)
+	q.grant()
+	return err
+}
+
+// Release gives up all the turns of the session given, after its statement ends when it isn't in a transaction, when
+// its transaction ends, or when it disconnects.
+func (q *WriteQueue) Release(session uint32) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.held[session]) == 0 {
+		return
+	}
+	for db := range q.held[session] {
+		delete(q.holders, db)
+	}
+	delete(q.held, session)
+	q.grant()
+}
+
+// Holds returns whether the session given has any turns.
+func (q *WriteQueue) Holds(session uint32) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.held[session]) > 0
+}
+
+// ahead returns whether the request goes before the other one in the queue.
+func (r *writeRequest) ahead(other *writeRequest) bool {
+	if r.rank != other.rank {
+		return r.rank < other.rank
+	}
+	return r.seq < other.seq
+}
+
+func (r *writeRequest) overlaps(other *writeRequest) bool {
+	for _, a := range r.databases {
+		for _, b := range other.databases {
+			if a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// blockers returns the sessions the request given waits for: the ones with the turns it wants, and the ones ahead
+// of it in the queue that want any of them.
+func (q *WriteQueue) blockers(r *writeRequest) []uint32 {
+	var sessions []uint32
+	for _, db := range r.databases {
+		if holder, ok := q.holders[db]; ok && holder != r.session {
+			sessions = append(sessions, holder)
+		}
+	}
+	for _, w := range q.waiting {
+		if w == r {
+			break
+		}
+		if w.session != r.session && w.overlaps(r) {
+			sessions = append(sessions, w.session)
+		}
+	}
+	return sessions
+}
+
+// grant gives their turns to the waiting requests that don't wait for anyone anymore, in the order of the queue, and
+// sets the waits of the rest in the graph.
+func (q *WriteQueue) grant() {
+	for i := 0; i < len(q.waiting); {
+		r := q.waiting[i]
+		if len(q.blockers(r)) > 0 {
+			i++
+			continue
+		}
+
+		if q.held[r.session] == nil {
+			q.held[r.session] = make(map[string]struct{})
+		}
+		for _, db := range r.databases {
+			q.holders[db] = r.session
+			q.held[r.session][db] = struct{}{}
+		}
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		r.done = true
+		close(r.granted)
+	}
+
+	waits := make(map[uint32][]uint32)
+	for _, r := range q.waiting {
+		waits[r.session] = append(waits[r.session], q.blockers(r)...)
+	}
+	for session := range q.waits {
+		if _, ok := waits[session]; !ok {
+			q.graph.SetWaits(session, q, nil)
+			delete(q.waits, session)
+		}
+	}
+	for session, blockers := range waits {
+		q.graph.SetWaits(session, q, blockers)
+		q.waits[session] = true
+	}
+}
+
+func (q *WriteQueue) remove(r *writeRequest) {
+	for i, w := range q.waiting {
+		if w == r {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return
+		}
+	}
+}
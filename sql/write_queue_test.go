@@ -0,0 +1,109 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteQueue(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	q := NewWriteQueue(NewWaitsForGraph())
+
+	require.NoError(q.Acquire(ctx, 1, []string{"a"}, NormalWritePriority, time.Minute))
+	// Sessions never wait for themselves
+	require.NoError(q.Acquire(ctx, 1, []string{"a", "a"}, NormalWritePriority, time.Minute))
+	require.NoError(q.Acquire(ctx, 2, []string{"b"}, NormalWritePriority, time.Minute))
+	require.True(q.Holds(1))
+
+	// Sessions waiting for the turns of other sessions are given them in the order they asked for them, except for
+	// the ones of low priority, which go last
+	order := make(chan uint32, 3)
+	acquire := func(session uint32, priority WritePriority) {
+		go func() {
+			if err := q.Acquire(NewEmptyContext(), session, []string{"a"}, priority, time.Minute); err != nil {
+				order <- 0
+				return
+			}
+			order <- session
+			q.Release(session)
+		}()
+		require.Eventually(func() bool {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			for _, r := range q.waiting {
+				if r.session == session {
+					return true
+				}
+			}
+			return false
+		}, time.Second, time.Millisecond)
+	}
+	acquire(3, LowWritePriority)
+	acquire(4, NormalWritePriority)
+	acquire(5, NormalWritePriority)
+
+	err := q.Acquire(ctx, 6, []string{"a"}, NormalWritePriority, 0)
+	require.True(ErrLockWaitTimeout.Is(err))
+
+	q.Release(1)
+	require.Equal([]uint32{4, 5, 3}, []uint32{<-order, <-order, <-order})
+	require.False(q.Holds(1))
+	require.True(q.Holds(2))
+
+	q.Release(2)
+	require.Empty(q.holders)
+	require.Empty(q.held)
+	require.Empty(q.waiting)
+}
+
+func TestWriteQueueDeadlock(t *testing.T) {
+	require := require.New(t)
+	ctx := NewEmptyContext()
+	q := NewWriteQueue(NewWaitsForGraph())
+
+	require.NoError(q.Acquire(ctx, 1, []string{"a"}, NormalWritePriority, time.Minute))
+	require.NoError(q.Acquire(ctx, 2, []string{"b"}, NormalWritePriority, time.Minute))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- q.Acquire(NewEmptyContext(), 1, []string{"b"}, NormalWritePriority, time.Minute)
+	}()
+	require.Eventually(func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return len(q.waiting) == 1
+	}, time.Second, time.Millisecond)
+
+	// Session 2 would wait for session 1, which waits for it
+	err := q.Acquire(ctx, 2, []string{"a"}, NormalWritePriority, time.Minute)
+	require.True(ErrLockDeadlock.Is(err))
+
+	q.Release(2)
+	require.NoError(<-acquired)
+	q.Release(1)
+	require.Empty(q.holders)
+}
+
+func TestWriteQueueCanceled(t *testing.T) {
+	require := require.New(t)
+	q := NewWriteQueue(NewWaitsForGraph())
+
+	require.NoError(q.Acquire(NewEmptyContext(), 1, []string{"a"}, NormalWritePriority, time.Minute))
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	ctx := NewContext(cancelCtx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err := q.Acquire(ctx, 2, []string{"a", "b"}, NormalWritePriority, time.Minute)
+	require.Equal(context.Canceled, err)
+	require.Empty(q.waiting)
+
+	// Turns are given all at once, so the one of b wasn't taken
+	require.NoError(q.Acquire(NewEmptyContext(), 3, []string{"b"}, NormalWritePriority, time.Minute))
+	q.Release(1)
+	q.Release(3)
+	require.Empty(q.holders)
+}
@@ -0,0 +1,276 @@
+package sql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"gopkg.in/src-d/go-errors.v1"
+)
+
+var (
+	// ErrXANotA is returned when an XA statement names a transaction that doesn't exist, or isn't the one of the session.
+	ErrXANotA = errors.NewKind("XAER_NOTA: Unknown XID")
+	// ErrXAInval is returned when an XA statement is given an invalid xid.
+	ErrXAInval = errors.NewKind("XAER_INVAL: Invalid arguments (or unsupported command): %s")
+	// ErrXARMFail is returned when an XA statement, or a COMMIT or ROLLBACK, can't be run in the state of the XA
+	// transaction of the session.
+	ErrXARMFail = errors.NewKind("XAER_RMFAIL: The command cannot be executed when global transaction is in the %s state")
+	// ErrXAOutside is returned when XA START is run by a session with uncommitted changes outside of an XA transaction.
+	ErrXAOutside = errors.NewKind("XAER_OUTSIDE: Some work is done outside global transaction")
+	// ErrXARBRollback is returned when the changes of an XA transaction can't be prepared, and are rolled back.
+	ErrXARBRollback = errors.NewKind("XA_RBROLLBACK: Transaction branch was rolled back: %s")
+	// ErrXADupID is returned when XA START is given the xid of a transaction that already exists.
+	ErrXADupID = errors.NewKind("XAER_DUPID: The XID already exists")
+)
+
+// maxXIDPartLength is the most bytes the global transaction id and the branch qualifier of an xid can have.
+const maxXIDPartLength = 64
+
+// XID identifies an XA transaction: the global transaction it's a branch of, the branch, and the format of both.
+type XID struct {
+	GTRID    string
+	BQUAL    string
+	FormatID int64
+}
+
+// NewXID returns the xid with the parts given, or ErrXAInval if they're too long.
+func NewXID(gtrid, bqual string, formatID int64) (XID, error) {
+	if gtrid == "" || len(gtrid) > maxXIDPartLength || len(bqual) > maxXIDPartLength {
+		return XID{}, ErrXAInval.New("the ids of an XA transaction must have between 1 and 64 bytes")
+	}
+	return XID{GTRID: gtrid, BQUAL: bqual, FormatID: formatID}, nil
+}
+
+// Data returns the global transaction id followed by the branch qualifier, as XA RECOVER shows them.
+func (x XID) Data() string {
+	return x.GTRID + x.BQUAL
+}
+
+// HexData returns the data of the xid in hexadecimal, as XA RECOVER CONVERT XID shows it.
+func (x XID) HexData() string {
+	return "0x" + hex.EncodeToString([]byte(x.Data()))
+}
+
+func (x XID) String() string {
+	return fmt.Sprintf("%q,%q,%d", x.GTRID, x.BQUAL, x.FormatID)
+}
+
+// XAState is the state of an XA transaction.
+type XAState byte
+
+const (
+	// XAActive is the state of the XA transactions started, whose session can still change data in them.
+	XAActive XAState = iota
+	// XAIdle is the state of the XA transactions ended by their session, which can be prepared or committed in one
+	// phase.
+	XAIdle
+	// XAPrepared is the state of the XA transactions whose changes are prepared, and can be committed or rolled back by
+	// any session.
+	XAPrepared
+)
+
+func (s XAState) String() string {
+	switch s {
+	case XAActive:
+		return "ACTIVE"
+	case XAIdle:
+		return "IDLE"
+	default:
+		return "PREPARED"
+	}
+}
+
+// xaTransaction is started by a session, and once it's prepared, it's kept with the databases it wrote until some
+// session commits it or rolls it back.
+type xaTransaction struct {
+	xid   XID
+	state XAState
+	dbs   []TwoPhaseCommitDatabase
+}
+
+// XAStart starts an XA transaction with the xid given in the session given. Its changes aren't committed until the
+// transaction is, whatever the autocommit of the session.
+func (c *TwoPhaseCommitter) XAStart(session uint32, xid XID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t, ok := c.xa[session]; ok {
+		return ErrXARMFail.New(t.state)
+	}
+	if len(c.written[session]) > 0 {
+		return ErrXAOutside.New()
+	}
+	if c.xidInUse(xid) {
+		return ErrXADupID.New()
+	}
+	c.xa[session] = &xaTransaction{xid: xid, state: XAActive}
+	return nil
+}
+
+// XAEnd ends the active XA transaction of the session given, so it can be prepared.
+func (c *TwoPhaseCommitter) XAEnd(session uint32, xid XID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.sessionXA(session, xid)
+	if err != nil {
+		return err
+	}
+	if t.state != XAActive {
+		return ErrXARMFail.New(t.state)
+	}
+	t.state = XAIdle
+	return nil
+}
+
+// XAPrepare prepares the changes of the ended XA transaction of the session of the context given in all the databases
+// it wrote, and detaches the transaction from the session, which can start another one. If any database fails to
+// prepare them, they're rolled back in all of them and ErrXARBRollback is returned.
+func (c *TwoPhaseCommitter) XAPrepare(ctx *Context, xid XID) error {
+	c.mu.Lock()
+	t, err := c.sessionXA(ctx.Session.ID(), xid)
+	if err == nil && t.state != XAIdle {
+		err = ErrXARMFail.New(t.state)
+	}
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	delete(c.xa, ctx.Session.ID())
+	dbs := c.written[ctx.Session.ID()]
+	delete(c.written, ctx.Session.ID())
+	c.mu.Unlock()
+
+	for _, db := range dbs {
+		if err := db.PrepareCommit(ctx); err != nil {
+			_ = rollbackPrepared(ctx, dbs)
+			return ErrXARBRollback.New(ErrPrepareCommit.New(db.Name(), err))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t.state, t.dbs = XAPrepared, dbs
+	c.prepared[xid] = t
+	return nil
+}
+
+// XACommit commits the XA transaction with the xid given. Prepared transactions can be committed by any session, with
+// the context of which their databases commit them. With onePhase, it prepares and commits the changes of the ended
+// XA transaction of the session of the context given instead, as Commit does.
+func (c *TwoPhaseCommitter) XACommit(ctx *Context, xid XID, onePhase bool) error {
+	c.mu.Lock()
+	t, ok := c.xa[ctx.Session.ID()]
+	switch {
+	case ok && t.xid == xid && onePhase && t.state == XAIdle:
+		delete(c.xa, ctx.Session.ID())
+		c.mu.Unlock()
+		return c.Commit(ctx)
+	case ok:
+		c.mu.Unlock()
+		return ErrXARMFail.New(t.state)
+	}
+
+	t, ok = c.prepared[xid]
+	if !ok {
+		c.mu.Unlock()
+		return ErrXANotA.New()
+	}
+	if onePhase {
+		c.mu.Unlock()
+		return ErrXARMFail.New(t.state)
+	}
+	delete(c.prepared, xid)
+	c.mu.Unlock()
+
+	var err error
+	for _, db := range t.dbs {
+		if cerr := db.CommitPrepared(ctx); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// XARollback rolls back the XA transaction with the xid given, which must be either the ended XA transaction of the
+// session of the context given, or a prepared one.
+func (c *TwoPhaseCommitter) XARollback(ctx *Context, xid XID) error {
+	c.mu.Lock()
+	t, ok := c.xa[ctx.Session.ID()]
+	switch {
+	case ok && t.xid == xid && t.state == XAIdle:
+		delete(c.xa, ctx.Session.ID())
+		dbs := c.written[ctx.Session.ID()]
+		delete(c.written, ctx.Session.ID())
+		c.mu.Unlock()
+		return rollbackPrepared(ctx, dbs)
+	case ok:
+		c.mu.Unlock()
+		return ErrXARMFail.New(t.state)
+	}
+
+	t, ok = c.prepared[xid]
+	if !ok {
+		c.mu.Unlock()
+		return ErrXANotA.New()
+	}
+	delete(c.prepared, xid)
+	c.mu.Unlock()
+	return rollbackPrepared(ctx, t.dbs)
+}
+
+// XARecover returns the xids of the prepared XA transactions, sorted by their format, global transaction id and branch
+// qualifier.
+func (c *TwoPhaseCommitter) XARecover() []XID {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var xids []XID
+	for xid := range c.prepared {
+		xids = append(xids, xid)
+	}
+	sort.Slice(xids, func(i, j int) bool {
+		if xids[i].FormatID != xids[j].FormatID {
+			return xids[i].FormatID < xids[j].FormatID
+		}
+		if xids[i].GTRID != xids[j].GTRID {
+			return xids[i].GTRID < xids[j].GTRID
+		}
+		return xids[i].BQUAL < xids[j].BQUAL
+	})
+	return xids
+}
+
+// XAState returns the state of the XA transaction of the session given, and whether it has one. Prepared transactions
+// don't belong to any session.
+func (c *TwoPhaseCommitter) XAState(session uint32) (XAState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.xa[session]
+	if !ok {
+		return 0, false
+	}
+	return t.state, true
+}
+
+func (c *TwoPhaseCommitter) sessionXA(session uint32, xid XID) (*xaTransaction, error) {
+	t, ok := c.xa[session]
+	if !ok || t.xid != xid {
+		return nil, ErrXANotA.New()
+	}
+	return t, nil
+}
+
+func (c *TwoPhaseCommitter) xidInUse(xid XID) bool {
+	if _, ok := c.prepared[xid]; ok {
+		return true
+	}
+	for _, t := range c.xa {
+		if t.xid == xid {
+			return true
+		}
+	}
+	return false
+}
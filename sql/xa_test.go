@@ -0,0 +1,49 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestXATransactions(t *testing.T) {
+	require := require.New(t)
+
+	var log []string
+	a := &twoPhaseDatabase{name: "a", log: &log}
+	c := NewTwoPhaseCommitter()
+	ctx1 := NewContext(NewEmptyContext(), WithSession(NewSession("", "", "", 1)))
+	ctx2 := NewContext(NewEmptyContext(), WithSession(NewSession("", "", "", 2)))
+
+	_, err := NewXID(strings.Repeat("x", 65), "", 1)
+	require.True(ErrXAInval.Is(err))
+
+	prepare := func(ctx *Context, xid XID) {
+		require.NoError(c.XAStart(ctx.Session.ID(), xid))
+		c.Track(ctx.Session.ID(), a)
+		require.NoError(c.XAEnd(ctx.Session.ID(), xid))
+		require.NoError(c.XAPrepare(ctx, xid))
+	}
+	x, y, z := XID{GTRID: "x", FormatID: 2}, XID{GTRID: "y", BQUAL: "b", FormatID: 1}, XID{GTRID: "y", FormatID: 1}
+	prepare(ctx1, x)
+	prepare(ctx1, y)
+	prepare(ctx2, z)
+	require.Equal([]XID{z, y, x}, c.XARecover())
+	require.Equal("0x7962", y.HexData())
+
+	// Sessions that disconnect roll back their XA transaction, but not the prepared ones
+	require.NoError(c.XAStart(1, XID{GTRID: "w"}))
+	_, ok := c.XAState(1)
+	require.True(ok)
+	require.NoError(c.Rollback(ctx1))
+	_, ok = c.XAState(1)
+	require.False(ok)
+	require.Len(c.XARecover(), 3)
+
+	log = nil
+	require.NoError(c.XARollback(ctx2, y))
+	require.NoError(c.XACommit(ctx1, z, false))
+	require.Equal([]string{"rollback a", "commit a"}, log)
+	require.Equal([]XID{x}, c.XARecover())
+}
@@ -0,0 +1,107 @@
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// trackRowChanges returns the iterator given recording the rows changed by the statement with the analyzed plan given
+// in the StatisticsRefresher of the engine once it's closed, or the iterator itself if there's no refresher or the
+// statement doesn't change the rows of a table that implements sql.AnalyzableTable.
+func (e *Engine) trackRowChanges(ctx *sql.Context, analyzed sql.Node, iter sql.RowIter) sql.RowIter {
+	if e.StatisticsRefresher == nil {
+		return iter
+	}
+
+	rt := changedTable(analyzed)
+	if rt == nil {
+		return iter
+	}
+
+	var table sql.Table = rt.Table
+	for {
+		if at, ok := table.(sql.AnalyzableTable); ok {
+			db := rt.Database
+			if db == "" {
+				db = ctx.GetCurrentDatabase()
+			}
+			return &rowChangesIter{RowIter: iter, refresher: e.StatisticsRefresher, db: db, table: at}
+		}
+
+		w, ok := table.(sql.TableWrapper)
+		if !ok {
+			return iter
+		}
+		table = w.Underlying()
+	}
+}
+
+// changedTable returns the table whose rows the analyzed statement given inserts, updates or deletes, or nil if it
+// doesn't change rows.
+func changedTable(n sql.Node) *plan.ResolvedTable {
+	for {
+		if qp, ok := n.(*plan.QueryProcess); ok {
+			n = qp.Child
+		} else if acc, ok := n.(*plan.RowUpdateAccumulator); ok {
+			n = acc.Child
+		} else if te, ok := n.(*plan.TriggerExecutor); ok {
+			n = te.Left
+		} else {
+			break
+		}
+	}
+
+	switch node := n.(type) {
+	case *plan.InsertInto:
+		n = node.Left
+	case *plan.Update:
+		n = node.Child
+	case *plan.DeleteFrom:
+		n = node.Child
+	case *plan.Truncate:
+		n = node.Child
+	default:
+		return nil
+	}
+
+	var rt *plan.ResolvedTable
+	plan.Inspect(n, func(node sql.Node) bool {
+		if t, ok := node.(*plan.ResolvedTable); ok && rt == nil {
+			rt = t
+		}
+		return rt == nil
+	})
+	return rt
+}
+
+// rowChangesIter records the rows affected by its statement, as reported by its OkResult, as changed rows of its table
+// once it's closed.
+type rowChangesIter struct {
+	sql.RowIter
+	refresher *sql.StatisticsRefresher
+	db        string
+	table     sql.AnalyzableTable
+	rows      uint64
+}
+
+func (i *rowChangesIter) Next() (sql.Row, error) {
+	row, err := i.RowIter.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(row) == 1 {
+		if ok, isOk := row[0].(sql.OkResult); isOk {
+			i.rows += ok.RowsAffected
+		}
+	}
+	return row, nil
+}
+
+func (i *rowChangesIter) Close() error {
+	err := i.RowIter.Close()
+	if i.rows > 0 {
+		i.refresher.RowsChanged(i.db, i.table, i.rows)
+	}
+	return err
+}
@@ -0,0 +1,137 @@
+package sqle
+
+import (
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// lockTables acquires the metadata locks of the tables the statement given uses, waiting for at most the
+// lock_wait_timeout of the session. Sessions that locked tables with LOCK TABLES only use the tables they locked, in
+// the modes they locked them, instead. Statements keep their locks until endTableLocks is called.
+func (e *Engine) lockTables(ctx *sql.Context, analyzed sql.Node) error {
+	if ctx.Session == nil {
+		return nil
+	}
+	switch analyzed.(type) {
+	case *plan.LockTables, *plan.UnlockTables:
+		return nil
+	}
+
+	requests := tableLockRequests(ctx, analyzed)
+	if len(requests) == 0 {
+		return nil
+	}
+
+	if locked := e.Catalog.ExplicitTableLocks(ctx.Session.ID()); len(locked) > 0 {
+		// New tables can be created while tables are locked
+		if _, ok := analyzed.(*plan.CreateTable); ok {
+			return nil
+		}
+		return sql.CheckLockedTables(locked, requests)
+	}
+
+	return e.Catalog.AcquireTableLocks(ctx, ctx.Session.ID(), requests, false, sql.LockWaitTimeout(ctx))
+}
+
+// endTableLocks releases the metadata locks of the session of the context given once its statement has finished,
+// unless it's in a transaction, whose locks are kept until it ends.
+func (e *Engine) endTableLocks(ctx *sql.Context) {
+	if ctx.Session == nil || e.inExplicitTransaction(ctx) {
+		return
+	}
+	e.Catalog.ReleaseTableLocks(ctx.Session.ID(), false)
+}
+
+// tableLockRequests returns the metadata locks of the tables the statement given reads, writes to, or changes the
+// schema of, including the tables of its subqueries and of the triggers it fires.
+func tableLockRequests(ctx *sql.Context, n sql.Node) []sql.TableLockRequest {
+	var requests []sql.TableLockRequest
+	add := func(db sql.Database, table string, mode sql.TableLockMode) {
+		name := ctx.GetCurrentDatabase()
+		if db != nil && db.Name() != "" {
+			name = db.Name()
+		}
+		requests = append(requests, sql.TableLockRequest{Database: name, Table: table, Mode: mode})
+	}
+	tables := func(n sql.Node, mode sql.TableLockMode) {
+		plan.Inspect(n, func(node sql.Node) bool {
+			rt, ok := node.(*plan.ResolvedTable)
+			if ita, isIndexed := node.(*plan.IndexedTableAccess); isIndexed {
+				rt, ok = ita.ResolvedTable, true
+			}
+			if !ok || rt.Database == "" || strings.EqualFold(rt.Database, sql.InformationSchemaDatabaseName) {
+				return true
+			}
+			requests = append(requests, sql.TableLockRequest{Database: rt.Database, Table: rt.Name(), Mode: mode})
+			return true
+		})
+	}
+
+	var inspect func(sql.Node) bool
+	inspect = func(node sql.Node) bool {
+		switch n := node.(type) {
+		case *plan.ResolvedTable, *plan.IndexedTableAccess:
+			tables(n, sql.SharedReadLock)
+		case *plan.InsertInto:
+			tables(n.Left, sql.SharedWriteLock)
+		case *plan.Update:
+			tables(n.Child, sql.SharedWriteLock)
+		case *plan.DeleteFrom:
+			tables(n.Child, sql.SharedWriteLock)
+		case *plan.Truncate:
+			tables(n.Child, sql.ExclusiveLock)
+		case *plan.CreateTable:
+			add(n.Database(), n.Name(), sql.ExclusiveLock)
+		case *plan.DropTable:
+			for _, name := range n.TableNames() {
+				add(n.Database(), name, sql.ExclusiveLock)
+			}
+		case *plan.RenameTable:
+			for _, name := range append(n.OldNames(), n.NewNames()...) {
+				add(n.Database(), name, sql.ExclusiveLock)
+			}
+		case *plan.AddColumn:
+			add(n.Database(), n.TableName(), sql.ExclusiveLock)
+		case *plan.DropColumn:
+			add(n.Database(), n.TableName(), sql.ExclusiveLock)
+		case *plan.RenameColumn:
+			add(n.Database(), n.TableName(), sql.ExclusiveLock)
+		case *plan.ModifyColumn:
+			add(n.Database(), n.TableName(), sql.ExclusiveLock)
+		case *plan.AlterIndex:
+			tables(n.Table, sql.ExclusiveLock)
+		case *plan.CreateIndex:
+			tables(n.Table, sql.ExclusiveLock)
+		case *plan.DropIndex:
+			tables(n.Table, sql.ExclusiveLock)
+		case *plan.CreateForeignKey:
+			tables(n.Left, sql.ExclusiveLock)
+		case *plan.DropForeignKey:
+			tables(n.Child, sql.ExclusiveLock)
+		case *plan.CreateCheck:
+			tables(n.Child, sql.ExclusiveLock)
+		case *plan.DropCheck:
+			tables(n.Child, sql.ExclusiveLock)
+		case *plan.DropConstraint:
+			tables(n.Child, sql.ExclusiveLock)
+		case *plan.CreateTrigger:
+			tables(n.Table, sql.ExclusiveLock)
+		}
+
+		if n, ok := node.(sql.Expressioner); ok {
+			for _, e := range n.Expressions() {
+				sql.Inspect(e, func(e sql.Expression) bool {
+					if sq, ok := e.(*plan.Subquery); ok {
+						plan.Inspect(sq.Query, inspect)
+					}
+					return true
+				})
+			}
+		}
+		return true
+	}
+	plan.Inspect(n, inspect)
+	return requests
+}
@@ -0,0 +1,163 @@
+package sqle
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// startTransactions starts the transaction of the session of the context given in the TransactionalDatabases the
+// statement given reads or writes the tables of, including the tables of its subqueries and of the triggers it fires,
+// that it wasn't started in yet. Sessions that aren't in a transaction start one.
+func (e *Engine) startTransactions(ctx *sql.Context, analyzed sql.Node) error {
+	if ctx.Session == nil {
+		return nil
+	}
+
+	names := writtenDatabases(analyzed)
+	for _, r := range tableLockRequests(ctx, analyzed) {
+		names = append(names, r.Database)
+	}
+
+	tx := ctx.Session.Transaction()
+	for _, name := range names {
+		if name == "" {
+			name = ctx.GetCurrentDatabase()
+		}
+		db, err := e.Catalog.SessionDatabase(ctx, name)
+		if err != nil {
+			continue
+		}
+		tdb, ok := db.(sql.TransactionalDatabase)
+		if !ok || tx.Started(tdb.Name()) {
+			continue
+		}
+
+		if tx == nil {
+			tx = &sql.Transaction{}
+			ctx.Session.SetTransaction(tx)
+		}
+		if err := tdb.StartTransaction(ctx); err != nil {
+			return err
+		}
+		tx.Databases = append(tx.Databases, tdb)
+	}
+	return nil
+}
+
+// abortTransactions rolls back the transaction of the session of the context given when its statement fails before it
+// runs, unless it's in a transaction that lasts longer than the statement.
+func (e *Engine) abortTransactions(ctx *sql.Context) {
+	if ctx.Session != nil && !e.inTransaction(ctx) {
+		_ = e.endTransaction(ctx, false)
+	}
+}
+
+// RollbackTransaction rolls back the transaction the session of the context given is in, if any, which must be called
+// when the session ends.
+func (e *Engine) RollbackTransaction(ctx *sql.Context) error {
+	if ctx.Session == nil {
+		return nil
+	}
+	return e.endTransaction(ctx, false)
+}
+
+// endTransaction commits or rolls back the transaction of the session of the context given in all the databases it
+// was started in, returning the first error, and ends it, giving up the locks and the turns to write it kept.
+func (e *Engine) endTransaction(ctx *sql.Context, commit bool) error {
+	tx := ctx.Session.Transaction()
+	ctx.Session.SetTransaction(nil)
+	e.WriteQueue.Release(ctx.Session.ID())
+	e.Catalog.ReleaseTableLocks(ctx.Session.ID(), false)
+	if tx == nil {
+		return nil
+	}
+
+	var err error
+	for _, db := range tx.Databases {
+		var derr error
+		if commit {
+			derr = db.CommitTransaction(ctx)
+		} else {
+			derr = db.RollbackTransaction(ctx)
+		}
+		if derr != nil && err == nil {
+			err = derr
+		}
+	}
+	return err
+}
+
+// inExplicitTransaction returns whether the session of the context given is in a transaction it started with BEGIN or
+// START TRANSACTION, or in one of its own if it's a sql.TransactionSession. These transactions keep the locks and the
+// turns to write of their session until they end.
+func (e *Engine) inExplicitTransaction(ctx *sql.Context) bool {
+	if ts, ok := ctx.Session.(sql.TransactionSession); ok && ts.InTransaction() {
+		return true
+	}
+	tx := ctx.Session.Transaction()
+	return tx != nil && tx.Explicit
+}
+
+// endTransactions returns an iterator of the rows of the statement given that, once closed, ends the transaction of its
+// session if the statement ends it: COMMIT commits it and ROLLBACK rolls it back, and BEGIN commits it before starting
+// a new one, as do XA COMMIT and XA ROLLBACK for the sessions of XA transactions. Any other statement of a session that
+// isn't in a transaction after it finishes, like the statements of sessions with autocommit or the ones enabling it,
+// commits the transaction if it succeeds and rolls it back if it fails.
+func (e *Engine) endTransactions(ctx *sql.Context, parsed sql.Node, iter sql.RowIter) sql.RowIter {
+	if ctx.Session == nil {
+		return iter
+	}
+	return &endTransactionIter{RowIter: iter, ctx: ctx, e: e, parsed: parsed}
+}
+
+type endTransactionIter struct {
+	sql.RowIter
+	ctx    *sql.Context
+	e      *Engine
+	parsed sql.Node
+	failed bool
+}
+
+func (i *endTransactionIter) Next() (sql.Row, error) {
+	row, err := i.RowIter.Next()
+	if err != nil && err != io.EOF {
+		i.failed = true
+	}
+	return row, err
+}
+
+func (i *endTransactionIter) Close() error {
+	err := i.RowIter.Close()
+	failed := i.failed || err != nil
+
+	var terr error
+	switch n := i.parsed.(type) {
+	case *plan.StartTransaction, *plan.Commit, *plan.Rollback:
+		// These statements can't end XA transactions, which are left as they are
+		if err != nil && sql.ErrXARMFail.Is(err) {
+			return err
+		}
+		_, rollback := n.(*plan.Rollback)
+		terr = i.e.endTransaction(i.ctx, !rollback && !failed)
+		if _, ok := n.(*plan.StartTransaction); ok && !failed && terr == nil {
+			i.ctx.Session.SetTransaction(&sql.Transaction{Explicit: true})
+		}
+	default:
+		if !i.e.inTransaction(i.ctx) {
+			terr = i.e.endTransaction(i.ctx, !failed && !isXARollback(n))
+		}
+	}
+
+	if err == nil {
+		err = terr
+	}
+	return err
+}
+
+// isXARollback returns whether the node given is XA ROLLBACK.
+func isXARollback(n sql.Node) bool {
+	xa, ok := n.(*plan.XATransaction)
+	return ok && xa.Statement == plan.XARollback
+}
@@ -0,0 +1,120 @@
+package sqle
+
+import (
+	"io"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// trackCommits adds the TwoPhaseCommitDatabases the statement given writes to, including the ones written by the
+// triggers it fires, to the ones its session has to commit.
+func (e *Engine) trackCommits(ctx *sql.Context, analyzed sql.Node) {
+	if ctx.Session == nil {
+		return
+	}
+
+	for _, name := range writtenDatabases(analyzed) {
+		if name == "" {
+			name = ctx.GetCurrentDatabase()
+		}
+		db, err := e.Catalog.Database(name)
+		if err != nil {
+			continue
+		}
+		if tdb, ok := db.(sql.TwoPhaseCommitDatabase); ok {
+			e.Catalog.TwoPhaseCommitter.Track(ctx.Session.ID(), tdb)
+		}
+	}
+}
+
+// abortCommits rolls back the changes of the session of the context given to the TwoPhaseCommitDatabases it has
+// written when its statement fails before it runs, unless it's in a transaction.
+func (e *Engine) abortCommits(ctx *sql.Context) {
+	if ctx.Session != nil && !e.inTransaction(ctx) {
+		_ = e.Catalog.TwoPhaseCommitter.Rollback(ctx)
+	}
+}
+
+// endCommits returns an iterator of the rows of the statement given that, once closed, commits the changes of its
+// session to the TwoPhaseCommitDatabases it has written if the statement is a COMMIT or a BEGIN, or if it's any other
+// statement that succeeds outside of a transaction, and rolls them back if it's a ROLLBACK, or any other statement that
+// fails outside of a transaction.
+func (e *Engine) endCommits(ctx *sql.Context, parsed sql.Node, iter sql.RowIter) sql.RowIter {
+	if ctx.Session == nil {
+		return iter
+	}
+
+	switch parsed.(type) {
+	case *plan.StartTransaction, *plan.Commit, *plan.Rollback:
+	default:
+		if len(e.Catalog.TwoPhaseCommitter.Written(ctx.Session.ID())) == 0 || e.inTransaction(ctx) {
+			return iter
+		}
+	}
+	return &endCommitsIter{RowIter: iter, ctx: ctx, committer: e.Catalog.TwoPhaseCommitter, parsed: parsed}
+}
+
+// inTransaction returns whether the session of the context given is in a transaction, either because it started one
+// or an XA transaction, or because autocommit is disabled.
+func (e *Engine) inTransaction(ctx *sql.Context) bool {
+	if e.inExplicitTransaction(ctx) {
+		return true
+	}
+	if _, ok := e.Catalog.TwoPhaseCommitter.XAState(ctx.Session.ID()); ok {
+		return true
+	}
+	if _, v := ctx.Get(sql.AutoCommitSessionVar); v != nil {
+		autocommit, err := sql.ConvertToBool(v)
+		return err == nil && !autocommit
+	}
+	return false
+}
+
+type endCommitsIter struct {
+	sql.RowIter
+	ctx       *sql.Context
+	committer *sql.TwoPhaseCommitter
+	parsed    sql.Node
+	failed    bool
+}
+
+func (i *endCommitsIter) Next() (sql.Row, error) {
+	row, err := i.RowIter.Next()
+	if err != nil && err != io.EOF {
+		i.failed = true
+	}
+	return row, err
+}
+
+func (i *endCommitsIter) Close() error {
+	err := i.RowIter.Close()
+
+	var cerr error
+	switch i.parsed.(type) {
+	case *plan.StartTransaction, *plan.Commit:
+		cerr = i.end(i.committer.Commit)
+	case *plan.Rollback:
+		cerr = i.end(i.committer.Rollback)
+	default:
+		if i.failed || err != nil {
+			cerr = i.committer.Rollback(i.ctx)
+		} else {
+			cerr = i.committer.Commit(i.ctx)
+		}
+	}
+
+	if err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// end ends the transaction of the session with the function given, unless the session is in an XA transaction, which
+// can only be ended with the XA statements.
+func (i *endCommitsIter) end(f func(*sql.Context) error) error {
+	if state, ok := i.committer.XAState(i.ctx.Session.ID()); ok {
+		return sql.ErrXARMFail.New(state)
+	}
+	return f(i.ctx)
+}
@@ -0,0 +1,211 @@
+package virtual
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// LookupFunc returns the rows of a virtual table whose indexed column has the value given, which has the type of
+// the column.
+type LookupFunc func(ctx *sql.Context, value interface{}) (sql.RowIter, error)
+
+// Index is an index on a column of a virtual table, for queries that look up rows by their value on the column, like
+// WHERE name = 'x' or WHERE name IN ('x', 'y').
+type Index struct {
+	table  *Table
+	column *sql.Column
+	unique bool
+	lookup LookupFunc
+}
+
+var _ sql.Index = (*Index)(nil)
+
+// Get implements the sql.Index interface.
+func (i *Index) Get(key ...interface{}) (sql.IndexLookup, error) {
+	if len(key) != 1 {
+		return nil, fmt.Errorf("index %s expected 1 key, got %d", i.ID(), len(key))
+	}
+
+	value, err := i.column.Type.Convert(key[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexLookup{index: i, values: []interface{}{value}}, nil
+}
+
+// Has implements the sql.Index interface.
+func (i *Index) Has(_ sql.Partition, key ...interface{}) (bool, error) {
+	lookup, err := i.Get(key...)
+	if err != nil {
+		return false, err
+	}
+
+	iter, err := lookup.(*indexLookup).rows(sql.NewEmptyContext())
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+
+	_, err = iter.Next()
+	if err == io.EOF {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ID implements the sql.Index interface.
+func (i *Index) ID() string {
+	return i.column.Name
+}
+
+// Database implements the sql.Index interface. Virtual tables can be added to any database, so indexes don't have
+// one.
+func (i *Index) Database() string {
+	return ""
+}
+
+// Table implements the sql.Index interface.
+func (i *Index) Table() string {
+	return i.table.name
+}
+
+// Expressions implements the sql.Index interface.
+func (i *Index) Expressions() []string {
+	return []string{i.table.name + "." + i.column.Name}
+}
+
+// IsUnique implements the sql.Index interface.
+func (i *Index) IsUnique() bool {
+	return i.unique
+}
+
+// Comment implements the sql.Index interface.
+func (i *Index) Comment() string {
+	return ""
+}
+
+// IndexType implements the sql.Index interface.
+func (i *Index) IndexType() string {
+	return "HASH"
+}
+
+// indexLookup is the lookup of the rows of a virtual table with any of some values of an indexed column.
+type indexLookup struct {
+	index  *Index
+	values []interface{}
+}
+
+var _ sql.MergeableIndexLookup = (*indexLookup)(nil)
+
+func (l *indexLookup) String() string {
+	values := make([]string, len(l.values))
+	for i, v := range l.values {
+		values[i] = fmt.Sprint(v)
+	}
+	return fmt.Sprintf("%s IN (%s)", l.index.ID(), strings.Join(values, ", "))
+}
+
+// IsMergeable implements the sql.MergeableIndexLookup interface. Only lookups on the same index can be merged.
+func (l *indexLookup) IsMergeable(lookup sql.IndexLookup) bool {
+	other, ok := lookup.(*indexLookup)
+	return ok && other.index == l.index
+}
+
+// Union implements the sql.MergeableIndexLookup interface.
+func (l *indexLookup) Union(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
+	values := l.values
+	for _, lookup := range lookups {
+		for _, v := range lookup.(*indexLookup).values {
+			if ok, err := l.contains(values, v); err != nil {
+				return nil, err
+			} else if !ok {
+				values = append(values, v)
+			}
+		}
+	}
+	return &indexLookup{index: l.index, values: values}, nil
+}
+
+// Intersection implements the sql.MergeableIndexLookup interface.
+func (l *indexLookup) Intersection(lookups ...sql.IndexLookup) (sql.IndexLookup, error) {
+	var values []interface{}
+	for _, v := range l.values {
+		inAll := true
+		for _, lookup := range lookups {
+			ok, err := l.contains(lookup.(*indexLookup).values, v)
+			if err != nil {
+				return nil, err
+			}
+			inAll = inAll && ok
+		}
+
+		if inAll {
+			values = append(values, v)
+		}
+	}
+	return &indexLookup{index: l.index, values: values}, nil
+}
+
+func (l *indexLookup) contains(values []interface{}, value interface{}) (bool, error) {
+	for _, v := range values {
+		cmp, err := l.index.column.Type.Compare(v, value)
+		if err != nil {
+			return false, err
+		}
+		if cmp == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// rows returns the rows with any of the values of the lookup.
+func (l *indexLookup) rows(ctx *sql.Context) (sql.RowIter, error) {
+	return &lookupIter{ctx: ctx, lookup: l}, nil
+}
+
+// lookupIter returns the rows of the lookup function called with each of the values of a lookup in turn.
+type lookupIter struct {
+	ctx    *sql.Context
+	lookup *indexLookup
+	pos    int
+	iter   sql.RowIter
+}
+
+func (i *lookupIter) Next() (sql.Row, error) {
+	for {
+		if i.iter == nil {
+			if i.pos >= len(i.lookup.values) {
+				return nil, io.EOF
+			}
+
+			iter, err := i.lookup.index.lookup(i.ctx, i.lookup.values[i.pos])
+			if err != nil {
+				return nil, err
+			}
+			i.iter = iter
+			i.pos++
+		}
+
+		row, err := i.iter.Next()
+		if err == io.EOF {
+			if err := i.iter.Close(); err != nil {
+				return nil, err
+			}
+			i.iter = nil
+			continue
+		}
+		return row, err
+	}
+}
+
+func (i *lookupIter) Close() error {
+	if i.iter != nil {
+		return i.iter.Close()
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package virtual
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/src-d/go-errors.v1"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+// ErrInvalidStructSource is returned when the source of a struct table isn't one of the supported kinds.
+var ErrInvalidStructSource = errors.NewKind("invalid source of struct table %s: %s")
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+	contextType = reflect.TypeOf((*sql.Context)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// NewStructTable creates a new virtual table with the name given whose rows are the structs of a source, which can
+// be:
+//   - a slice of structs or pointers to structs, whose structs are read every time the table is read;
+//   - a pointer to such a slice, so elements appended to it are also read;
+//   - a function returning such a slice, with signature func() []T or func(*sql.Context) ([]T, error), which is
+//     called every time the table is read.
+//
+// Every exported field of the struct is a column of the table, named like the field in snake case, or like the
+// value of its `sql` tag. Fields with the tag `sql:"-"` are skipped. Booleans, numbers, strings, byte slices and
+// times have the matching SQL type, and any other value is a JSON column. Pointer fields are nullable, and so are
+// times, whose zero value is NULL.
+func NewStructTable(name string, source interface{}) (*Table, error) {
+	v := reflect.ValueOf(source)
+	if !v.IsValid() {
+		return nil, ErrInvalidStructSource.New(name, "nil")
+	}
+
+	var slice func(ctx *sql.Context) (reflect.Value, error)
+	var sliceType reflect.Type
+	switch t := v.Type(); {
+	case t.Kind() == reflect.Slice:
+		sliceType = t
+		slice = func(*sql.Context) (reflect.Value, error) { return v, nil }
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice:
+		sliceType = t.Elem()
+		slice = func(*sql.Context) (reflect.Value, error) { return v.Elem(), nil }
+	case t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 1 && t.Out(0).Kind() == reflect.Slice:
+		sliceType = t.Out(0)
+		slice = func(*sql.Context) (reflect.Value, error) { return v.Call(nil)[0], nil }
+	case t.Kind() == reflect.Func && t.NumIn() == 1 && t.In(0) == contextType &&
+		t.NumOut() == 2 && t.Out(0).Kind() == reflect.Slice && t.Out(1) == errorType:
+		sliceType = t.Out(0)
+		slice = func(ctx *sql.Context) (reflect.Value, error) {
+			out := v.Call([]reflect.Value{reflect.ValueOf(ctx)})
+			if err, _ := out[1].Interface().(error); err != nil {
+				return reflect.Value{}, err
+			}
+			return out[0], nil
+		}
+	default:
+		return nil, ErrInvalidStructSource.New(name, t.String())
+	}
+
+	structType := sliceType.Elem()
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, ErrInvalidStructSource.New(name, sliceType.String())
+	}
+
+	fields, schema := structSchema(name, structType)
+	if len(schema) == 0 {
+		return nil, ErrInvalidStructSource.New(name, structType.String()+" has no exported fields")
+	}
+
+	rows := func(ctx *sql.Context, _ []sql.Expression) (sql.RowIter, error) {
+		s, err := slice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &structIter{slice: s, fields: fields, schema: schema}, nil
+	}
+
+	return NewTable(name, schema, rows), nil
+}
+
+// structSchema returns the indexes of the fields of the struct type given that are columns, and their schema.
+func structSchema(table string, t reflect.Type) ([]int, sql.Schema) {
+	var fields []int
+	var schema sql.Schema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := f.Tag.Get("sql")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(f.Name)
+		}
+
+		typ, nullable := columnType(f.Type)
+		fields = append(fields, i)
+		schema = append(schema, &sql.Column{Name: name, Type: typ, Nullable: nullable, Source: table})
+	}
+	return fields, schema
+}
+
+// columnType returns the SQL type of the values of a field with the type given, and whether they can be NULL.
+func columnType(t reflect.Type) (sql.Type, bool) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		typ, _ := columnType(t.Elem())
+		return typ, true
+	case reflect.Bool:
+		return sql.Boolean, false
+	case reflect.Int8:
+		return sql.Int8, false
+	case reflect.Int16:
+		return sql.Int16, false
+	case reflect.Int32:
+		return sql.Int32, false
+	case reflect.Int, reflect.Int64:
+		return sql.Int64, false
+	case reflect.Uint8:
+		return sql.Uint8, false
+	case reflect.Uint16:
+		return sql.Uint16, false
+	case reflect.Uint32:
+		return sql.Uint32, false
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return sql.Uint64, false
+	case reflect.Float32:
+		return sql.Float32, false
+	case reflect.Float64:
+		return sql.Float64, false
+	case reflect.String:
+		return sql.LongText, false
+	}
+
+	switch {
+	case t == timeType:
+		return sql.Datetime, true
+	case t.ConvertibleTo(bytesType) && t.Kind() == reflect.Slice:
+		return sql.LongBlob, true
+	default:
+		return sql.JSON, true
+	}
+}
+
+// columnValue returns the value of a field for a column with the type given.
+func columnValue(typ sql.Type, v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return typ.Convert(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return typ.Convert(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return typ.Convert(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return typ.Convert(v.Float())
+	case reflect.String:
+		return typ.Convert(v.String())
+	}
+
+	if t, ok := v.Interface().(time.Time); ok && t.IsZero() {
+		return nil, nil
+	}
+
+	if typ == sql.LongBlob {
+		return typ.Convert(v.Convert(bytesType).Interface())
+	}
+	return typ.Convert(v.Interface())
+}
+
+// snakeCase returns the name given in snake case, keeping acronyms together, so QueueLength is queue_length and
+// HTTPStatus is http_status.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := !unicode.IsUpper(runes[i-1]) && runes[i-1] != '_'
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				sb.WriteRune('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// structIter returns the structs of a slice as rows.
+type structIter struct {
+	slice  reflect.Value
+	fields []int
+	schema sql.Schema
+	pos    int
+}
+
+func (i *structIter) Next() (sql.Row, error) {
+	for {
+		if i.pos >= i.slice.Len() {
+			return nil, io.EOF
+		}
+
+		v := i.slice.Index(i.pos)
+		i.pos++
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+
+		row := make(sql.Row, len(i.fields))
+		for j, field := range i.fields {
+			value, err := columnValue(i.schema[j].Type, v.Field(field))
+			if err != nil {
+				return nil, err
+			}
+			row[j] = value
+		}
+		return row, nil
+	}
+}
+
+func (i *structIter) Close() error {
+	return nil
+}
@@ -0,0 +1,116 @@
+package virtual
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dolthub/go-mysql-server/sql"
+)
+
+type queue struct {
+	Name        string
+	QueueLength int
+	HTTPStatus  *uint16
+	Paused      bool
+	Rate        float64
+	LastSeen    time.Time
+	Labels      map[string]string
+	Payload     []byte
+	Renamed     int8 `sql:"alias"`
+	Skipped     int  `sql:"-"`
+	unexported  int
+}
+
+func structRows(t *testing.T, table *Table) []sql.Row {
+	t.Helper()
+	ctx := sql.NewEmptyContext()
+
+	iter, err := table.PartitionRows(ctx, partition{})
+	require.NoError(t, err)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(t, err)
+	return rows
+}
+
+func TestStructTable(t *testing.T) {
+	require := require.New(t)
+
+	status := uint16(200)
+	seen := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	queues := []queue{
+		{Name: "a", QueueLength: 3, HTTPStatus: &status, Paused: true, Rate: 1.5, LastSeen: seen,
+			Labels: map[string]string{"env": "prod"}, Payload: []byte("x"), Renamed: 7, Skipped: 1, unexported: 1},
+		{Name: "b"},
+	}
+
+	table, err := NewStructTable("queues", &queues)
+	require.NoError(err)
+
+	require.Equal(sql.Schema{
+		{Name: "name", Type: sql.LongText, Source: "queues"},
+		{Name: "queue_length", Type: sql.Int64, Source: "queues"},
+		{Name: "http_status", Type: sql.Uint16, Nullable: true, Source: "queues"},
+		{Name: "paused", Type: sql.Boolean, Source: "queues"},
+		{Name: "rate", Type: sql.Float64, Source: "queues"},
+		{Name: "last_seen", Type: sql.Datetime, Nullable: true, Source: "queues"},
+		{Name: "labels", Type: sql.JSON, Nullable: true, Source: "queues"},
+		{Name: "payload", Type: sql.LongBlob, Nullable: true, Source: "queues"},
+		{Name: "alias", Type: sql.Int8, Source: "queues"},
+	}, table.Schema())
+
+	require.Equal([]sql.Row{
+		{"a", int64(3), uint16(200), int8(1), 1.5, seen, []byte(`{"env":"prod"}`), "x", int8(7)},
+		{"b", int64(0), nil, int8(0), float64(0), nil, nil, nil, int8(0)},
+	}, structRows(t, table))
+
+	queues = append(queues, queue{Name: "c"})
+	require.Len(structRows(t, table), 3)
+}
+
+func TestStructTableSources(t *testing.T) {
+	require := require.New(t)
+
+	type item struct{ ID int }
+	items := []*item{{1}, nil, {2}}
+
+	table, err := NewStructTable("items", items)
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}, {int64(2)}}, structRows(t, table))
+
+	calls := 0
+	table, err = NewStructTable("items", func() []item {
+		calls++
+		return []item{{calls}}
+	})
+	require.NoError(err)
+	require.Equal([]sql.Row{{int64(1)}}, structRows(t, table))
+	require.Equal([]sql.Row{{int64(2)}}, structRows(t, table))
+
+	table, err = NewStructTable("items", func(*sql.Context) ([]item, error) {
+		return nil, errors.New("unavailable")
+	})
+	require.NoError(err)
+	_, err = table.PartitionRows(sql.NewEmptyContext(), partition{})
+	require.EqualError(err, "unavailable")
+
+	for _, source := range []interface{}{nil, item{}, []int{1}, func(int) []item { return nil }, []struct{ a int }{}} {
+		_, err = NewStructTable("items", source)
+		require.True(ErrInvalidStructSource.Is(err), "%T", source)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Name":        "name",
+		"ID":          "id",
+		"QueueLength": "queue_length",
+		"HTTPStatus":  "http_status",
+		"UserID":      "user_id",
+		"Snake_Case":  "snake_case",
+	} {
+		require.Equal(t, expected, snakeCase(name))
+	}
+}
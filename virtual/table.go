@@ -0,0 +1,238 @@
+package virtual
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/expression"
+)
+
+// RowsFunc returns the rows of a virtual table every time it's read. The filters of the query on the table are
+// given, so the function can skip rows that don't match them, but it doesn't need to: the table evaluates the filters
+// on every row returned anyway.
+type RowsFunc func(ctx *sql.Context, filters []sql.Expression) (sql.RowIter, error)
+
+// Table is a read-only table whose rows are returned by a Go function every time the table is read, so embedders can
+// expose the state of their application, such as metrics, configuration or queues, as a table. Columns can be
+// indexed with a function that returns the rows with a value, such as a map lookup, so queries for those values don't
+// read every row.
+type Table struct {
+	name    string
+	schema  sql.Schema
+	rows    RowsFunc
+	indexes []*Index
+	filters []sql.Expression
+	lookup  *indexLookup
+}
+
+var _ sql.Table = (*Table)(nil)
+var _ sql.FilteredTable = (*Table)(nil)
+var _ sql.IndexedTable = (*Table)(nil)
+
+// NewTable creates a new virtual table with the name and schema given, whose rows are returned by the function given.
+// The sources of the columns of the schema are set to the name of the table.
+func NewTable(name string, schema sql.Schema, rows RowsFunc) *Table {
+	s := make(sql.Schema, len(schema))
+	for i, col := range schema {
+		c := *col
+		c.Source = name
+		s[i] = &c
+	}
+
+	return &Table{name: name, schema: s, rows: rows}
+}
+
+// AddIndex adds an index on the column given, whose rows with a value are returned by the lookup function given.
+// Values are converted to the type of the column before calling the function. Tables returned by WithFilters or
+// WithIndexLookup don't have the index, so indexes should be added before the table is added to a database.
+func (t *Table) AddIndex(column string, unique bool, lookup LookupFunc) error {
+	idx := t.schema.IndexOf(column, t.name)
+	if idx < 0 {
+		return sql.ErrTableColumnNotFound.New(t.name, column)
+	}
+
+	t.indexes = append(t.indexes, &Index{table: t, column: t.schema[idx], unique: unique, lookup: lookup})
+	return nil
+}
+
+// Name implements the sql.Nameable interface.
+func (t *Table) Name() string {
+	return t.name
+}
+
+func (t *Table) String() string {
+	return t.name
+}
+
+// DebugString implements the sql.DebugStringer interface.
+func (t *Table) DebugString() string {
+	var parts []string
+	if len(t.filters) > 0 {
+		var filters []string
+		for _, f := range t.filters {
+			filters = append(filters, f.String())
+		}
+		parts = append(parts, fmt.Sprintf("filters: [%s]", strings.Join(filters, ", ")))
+	}
+	if t.lookup != nil {
+		parts = append(parts, fmt.Sprintf("lookup: %s", t.lookup))
+	}
+
+	if len(parts) == 0 {
+		return t.name
+	}
+	return fmt.Sprintf("%s(%s)", t.name, strings.Join(parts, ", "))
+}
+
+// Schema implements the sql.Table interface.
+func (t *Table) Schema() sql.Schema {
+	return t.schema
+}
+
+// Partitions implements the sql.Table interface. Virtual tables have a single partition.
+func (t *Table) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &partitionIter{}, nil
+}
+
+// PartitionRows implements the sql.Table interface.
+func (t *Table) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	var iter sql.RowIter
+	var err error
+	if t.lookup != nil {
+		iter, err = t.lookup.rows(ctx)
+	} else {
+		iter, err = t.rows(ctx, t.filters)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(t.filters) == 0 {
+		return iter, nil
+	}
+	return &filterIter{ctx: ctx, iter: iter, filters: t.filters}, nil
+}
+
+// HandledFilters implements the sql.FilteredTable interface. All the filters that only reference columns of this
+// table are handled.
+func (t *Table) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var handled []sql.Expression
+	for _, f := range filters {
+		var hasOtherFields bool
+		sql.Inspect(f, func(e sql.Expression) bool {
+			if e, ok := e.(*expression.GetField); ok {
+				if e.Table() != t.name || !t.schema.Contains(e.Name(), t.name) {
+					hasOtherFields = true
+					return false
+				}
+			}
+			return true
+		})
+
+		if !hasOtherFields {
+			handled = append(handled, f)
+		}
+	}
+
+	return handled
+}
+
+// WithFilters implements the sql.FilteredTable interface.
+func (t *Table) WithFilters(filters []sql.Expression) sql.Table {
+	if len(filters) == 0 {
+		return t
+	}
+
+	nt := *t
+	nt.filters = filters
+	return &nt
+}
+
+// Filters implements the sql.FilteredTable interface.
+func (t *Table) Filters() []sql.Expression {
+	return t.filters
+}
+
+// GetIndexes implements the sql.IndexedTable interface.
+func (t *Table) GetIndexes(*sql.Context) ([]sql.Index, error) {
+	indexes := make([]sql.Index, len(t.indexes))
+	for i, idx := range t.indexes {
+		indexes[i] = idx
+	}
+	return indexes, nil
+}
+
+// WithIndexLookup implements the sql.IndexAddressableTable interface.
+func (t *Table) WithIndexLookup(lookup sql.IndexLookup) sql.Table {
+	l, ok := lookup.(*indexLookup)
+	if !ok {
+		panic(fmt.Sprintf("unexpected index lookup for virtual table %s: %T", t.name, lookup))
+	}
+
+	nt := *t
+	nt.lookup = l
+	return &nt
+}
+
+type partition struct{}
+
+func (partition) Key() []byte {
+	return []byte("virtual")
+}
+
+type partitionIter struct {
+	done bool
+}
+
+func (i *partitionIter) Next(*sql.Context) (sql.Partition, error) {
+	if i.done {
+		return nil, io.EOF
+	}
+
+	i.done = true
+	return partition{}, nil
+}
+
+func (i *partitionIter) Close() error {
+	return nil
+}
+
+// filterIter returns the rows of an iterator that match all the filters given.
+type filterIter struct {
+	ctx     *sql.Context
+	iter    sql.RowIter
+	filters []sql.Expression
+}
+
+func (i *filterIter) Next() (sql.Row, error) {
+	for {
+		row, err := i.iter.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := matches(i.ctx, i.filters, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return row, nil
+		}
+	}
+}
+
+func (i *filterIter) Close() error {
+	return i.iter.Close()
+}
+
+func matches(ctx *sql.Context, filters []sql.Expression, row sql.Row) (bool, error) {
+	for _, f := range filters {
+		ok, err := sql.EvaluateCondition(ctx, f, row)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,100 @@
+package virtual_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sqle "github.com/dolthub/go-mysql-server"
+	"github.com/dolthub/go-mysql-server/memory"
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/virtual"
+)
+
+// settings is application state exposed as a virtual table, which counts how it's read.
+type settings struct {
+	values  map[string]string
+	scans   int
+	lookups []string
+	filters []string
+}
+
+func (s *settings) table(t *testing.T) *virtual.Table {
+	table := virtual.NewTable("settings", sql.Schema{
+		{Name: "name", Type: sql.LongText},
+		{Name: "value", Type: sql.LongText},
+	}, func(ctx *sql.Context, filters []sql.Expression) (sql.RowIter, error) {
+		s.scans++
+		for _, f := range filters {
+			s.filters = append(s.filters, f.String())
+		}
+
+		var names []string
+		for name := range s.values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var rows []sql.Row
+		for _, name := range names {
+			rows = append(rows, sql.NewRow(name, s.values[name]))
+		}
+		return sql.RowsToRowIter(rows...), nil
+	})
+
+	require.NoError(t, table.AddIndex("name", true, func(ctx *sql.Context, value interface{}) (sql.RowIter, error) {
+		name := value.(string)
+		s.lookups = append(s.lookups, name)
+		if v, ok := s.values[name]; ok {
+			return sql.RowsToRowIter(sql.NewRow(name, v)), nil
+		}
+		return sql.RowsToRowIter(), nil
+	}))
+
+	return table
+}
+
+func TestTable(t *testing.T) {
+	s := &settings{values: map[string]string{"a": "1", "b": "2", "c": "3"}}
+
+	db := memory.NewDatabase("app")
+	db.AddTable("settings", s.table(t))
+	e := sqle.NewDefault()
+	e.AddDatabase(db)
+
+	query := func(pid uint64, q string) []sql.Row {
+		ctx := sql.NewContext(context.Background(), sql.WithPid(pid)).WithCurrentDB("app")
+		_, iter, err := e.Query(ctx, q)
+		require.NoError(t, err)
+		rows, err := sql.RowIterToRows(iter)
+		require.NoError(t, err)
+		return rows
+	}
+
+	require := require.New(t)
+
+	require.Equal([]sql.Row{{"a", "1"}, {"b", "2"}, {"c", "3"}}, query(1, "SELECT * FROM settings"))
+	require.Equal(1, s.scans)
+
+	s.values["d"] = "4"
+	require.Equal([]sql.Row{{"c", "3"}, {"d", "4"}}, query(2, "SELECT name, value FROM settings WHERE value > '2'"))
+	require.Equal(2, s.scans)
+	require.Equal([]string{`settings.value > "2"`}, s.filters)
+
+	require.Equal([]sql.Row{{"1"}, {"4"}}, query(3, "SELECT value FROM settings WHERE name IN ('d', 'a', 'x') ORDER BY value"))
+	require.Equal(2, s.scans)
+	require.Equal([]string{"d", "a", "x"}, s.lookups)
+
+	s.lookups = nil
+	require.Equal([]sql.Row{{"2"}}, query(4, "SELECT value FROM settings WHERE name = 'b' AND value <> '3'"))
+	require.Equal(2, s.scans)
+	require.Equal([]string{"b"}, s.lookups)
+}
+
+func TestTableAddIndexMissingColumn(t *testing.T) {
+	table := virtual.NewTable("t", sql.Schema{{Name: "a", Type: sql.Int64}}, nil)
+	err := table.AddIndex("b", false, nil)
+	require.True(t, sql.ErrTableColumnNotFound.Is(err))
+}
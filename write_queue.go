@@ -0,0 +1,125 @@
+package sqle
+
+import (
+	"github.com/dolthub/go-mysql-server/sql"
+	"github.com/dolthub/go-mysql-server/sql/plan"
+)
+
+// queueWrites waits in the WriteQueue of the engine for the turns of the single-writer databases the statement given
+// writes to, for at most the innodb_lock_wait_timeout of the session. Sessions keep the turns until endWrites is called.
+func (e *Engine) queueWrites(ctx *sql.Context, analyzed sql.Node) error {
+	if ctx.Session == nil {
+		return nil
+	}
+
+	var databases []string
+	seen := make(map[string]bool)
+	for _, name := range writtenDatabases(analyzed) {
+		if name == "" {
+			name = ctx.GetCurrentDatabase()
+		}
+		db, err := e.Catalog.Database(name)
+		if err != nil {
+			continue
+		}
+		if sw, ok := db.(sql.SingleWriterDatabase); ok && sw.SingleWriter() && !seen[db.Name()] {
+			seen[db.Name()] = true
+			databases = append(databases, db.Name())
+		}
+	}
+	if len(databases) == 0 {
+		return nil
+	}
+
+	priority := sql.NormalWritePriority
+	if _, v := ctx.Get(sql.LowPriorityUpdatesVar); v != nil {
+		if low, _ := sql.ConvertToBool(v); low {
+			priority = sql.LowWritePriority
+		}
+	}
+
+	return e.WriteQueue.Acquire(ctx, ctx.Session.ID(), databases, priority, sql.InnodbLockWaitTimeout(ctx))
+}
+
+// endWrites gives up the turns of the session of the context given once its statement has finished, unless it's in a
+// transaction, whose turns are kept until it ends.
+func (e *Engine) endWrites(ctx *sql.Context) {
+	if ctx.Session == nil || e.inExplicitTransaction(ctx) {
+		return
+	}
+	e.WriteQueue.Release(ctx.Session.ID())
+}
+
+// lockFailed returns the error given, which a statement failed with while waiting for its locks or its turns, after
+// rolling back the transaction of its session if it was deadlocked, and giving up all its locks and turns, so the
+// sessions it was deadlocked with can go on. Only the statement fails on other errors, like lock wait timeouts.
+func (e *Engine) lockFailed(ctx *sql.Context, err error) error {
+	if ctx.Session == nil || !sql.ErrLockDeadlock.Is(err) {
+		return err
+	}
+	if ts, ok := ctx.Session.(sql.TransactionSession); ok && ts.InTransaction() {
+		if rerr := ts.RollbackTransaction(ctx); rerr != nil {
+			err = rerr
+		}
+	}
+	if rerr := e.Catalog.TwoPhaseCommitter.Rollback(ctx); rerr != nil {
+		err = rerr
+	}
+	if rerr := e.endTransaction(ctx, false); rerr != nil {
+		err = rerr
+	}
+	e.WriteQueue.Release(ctx.Session.ID())
+	e.Catalog.ReleaseTableLocks(ctx.Session.ID(), false)
+	return err
+}
+
+// writtenDatabases returns the names of the databases the statement given changes the data or the schema of, including
+// the ones written by the triggers it fires. Names are empty for the current database.
+func writtenDatabases(n sql.Node) []string {
+	var names []string
+	tables := func(n sql.Node) {
+		plan.Inspect(n, func(node sql.Node) bool {
+			if rt, ok := node.(*plan.ResolvedTable); ok {
+				names = append(names, rt.Database)
+			}
+			return true
+		})
+	}
+
+	plan.Inspect(n, func(node sql.Node) bool {
+		switch n := node.(type) {
+		case *plan.InsertInto:
+			tables(n.Left)
+		case *plan.Update:
+			tables(n.Child)
+		case *plan.DeleteFrom:
+			tables(n.Child)
+		case *plan.Truncate:
+			tables(n.Child)
+		case *plan.AlterIndex:
+			tables(n.Table)
+		case *plan.CreateIndex:
+			tables(n.Table)
+		case *plan.DropIndex:
+			tables(n.Table)
+		case *plan.CreateForeignKey:
+			tables(n.Left)
+		case *plan.DropForeignKey:
+			tables(n.Child)
+		case *plan.CreateCheck:
+			tables(n.Child)
+		case *plan.DropCheck:
+			tables(n.Child)
+		case *plan.DropConstraint:
+			tables(n.Child)
+		case *plan.CreateTable, *plan.DropTable, *plan.RenameTable, *plan.AddColumn, *plan.DropColumn,
+			*plan.RenameColumn, *plan.ModifyColumn, *plan.CreateView, *plan.DropView, *plan.CreateTrigger,
+			*plan.DropTrigger:
+			if db, ok := n.(sql.Databaser); ok && db.Database() != nil {
+				names = append(names, db.Database().Name())
+			}
+		}
+		return true
+	})
+	return names
+}